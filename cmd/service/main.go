@@ -2,11 +2,10 @@ package main
 
 import (
 	"context"
-	"net"
 	"os"
 
-	"github.com/kasuganosora/sqlexec/pkg/api"
 	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/security"
 	"github.com/kasuganosora/sqlexec/server"
 	"github.com/kasuganosora/sqlexec/server/httpapi"
@@ -14,23 +13,35 @@ import (
 )
 
 func main() {
-	logger := api.NewDefaultLogger(api.LogInfo)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	logger := logging.Module("main")
 
 	// 加载配置
 	cfg := config.LoadConfigOrDefault()
-	logger.Info("加载配置: Host=%s, Port=%d, Address=%s", cfg.Server.Host, cfg.Server.Port, cfg.GetListenAddress())
 
-	// 监听端口
-	listener, err := net.Listen("tcp4", cfg.GetListenAddress())
+	// 按配置设置日志级别、格式和输出目标，之后各模块的日志都按此输出
+	if err := logging.Configure(cfg.Log); err != nil {
+		logger.Error("日志配置无效，使用默认配置", "err", err)
+	}
+	logger.Info("加载配置", "host", cfg.Server.Host, "port", cfg.Server.Port, "address", cfg.GetListenAddress())
+
+	// 打开所有监听地址（未配置 listeners 时为 host:port，同时接受 IPv4 和 IPv6）
+	listeners, err := server.ListenAll(&cfg.Server)
 	if err != nil {
-		logger.Error("监听端口失败: %v", err)
+		logger.Error("监听端口失败", "err", err)
 		os.Exit(1)
 	}
 
 	ctx := context.Background()
 
 	// 创建服务器实例
-	srv := server.NewServer(ctx, listener, cfg)
+	srv := server.NewServer(ctx, nil, cfg)
+	for _, listener := range listeners {
+		srv.AddListener(listener)
+	}
 
 	// 创建审计日志并关联到服务器
 	auditLogger := security.NewAuditLogger(10000)
@@ -40,9 +51,10 @@ func main() {
 	if cfg.HTTPAPI.Enabled {
 		httpServer := httpapi.NewServer(srv.GetDB(), srv.GetConfigDir(), &cfg.HTTPAPI, auditLogger)
 		httpServer.SetVirtualDBRegistry(srv.GetVirtualDBRegistry())
+		httpServer.SetACLManager(srv.GetACLManager())
 		go func() {
 			if err := httpServer.Start(); err != nil {
-				logger.Error("HTTP API 服务器退出: %v", err)
+				logger.Error("HTTP API 服务器退出", "err", err)
 			}
 		}()
 	}
@@ -53,23 +65,26 @@ func main() {
 		mcpSrv.SetVirtualDBRegistry(srv.GetVirtualDBRegistry())
 		go func() {
 			if err := mcpSrv.Start(); err != nil {
-				logger.Error("MCP 服务器退出: %v", err)
+				logger.Error("MCP 服务器退出", "err", err)
 			}
 		}()
 	}
 
 	// 启动信息
-	logger.Info("启动 MySQL 服务器: %s", cfg.GetListenAddress())
+	for _, lc := range cfg.Server.ListenerConfigs() {
+		logger.Info("启动 MySQL 服务器", "network", lc.NetworkOrDefault(), "address", lc.Address,
+			"tls", lc.TLS.Enabled(), "read_only", lc.ReadOnly)
+	}
 	if cfg.HTTPAPI.Enabled {
-		logger.Info("HTTP API 服务器: %s:%d", cfg.HTTPAPI.Host, cfg.HTTPAPI.Port)
+		logger.Info("HTTP API 服务器", "host", cfg.HTTPAPI.Host, "port", cfg.HTTPAPI.Port)
 	}
 	if cfg.MCP.Enabled {
-		logger.Info("MCP 服务器: %s:%d", cfg.MCP.Host, cfg.MCP.Port)
+		logger.Info("MCP 服务器", "host", cfg.MCP.Host, "port", cfg.MCP.Port)
 	}
 
 	// 启动服务器
 	if err := srv.Start(); err != nil {
-		logger.Error("服务器启动失败: %v", err)
+		logger.Error("服务器启动失败", "err", err)
 		os.Exit(1)
 	}
 
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/sysdb"
+)
+
+const migrateUsage = `usage: service migrate <status|up> [flags]
+
+  status      show the schema version and pending migrations of the system database
+  up          apply pending migrations (-dry-run lists them without applying)`
+
+// runMigrate 执行 migrate 子命令：查看或执行系统数据库的迁移。
+// badger 存储同一时间只能被一个进程打开，需要在服务停止时运行
+func runMigrate(args []string) int {
+	if len(args) == 0 || (args[0] != "status" && args[0] != "up") {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	command := args[0]
+	flags := flag.NewFlagSet("service migrate "+command, flag.ContinueOnError)
+	configPath := flags.String("config", "", "config file (default: SQLEXEC_CONFIG or config.json)")
+	dryRun := flags.Bool("dry-run", false, "list pending migrations without applying them (up only)")
+	lockTimeout := flags.Duration("lock-timeout", sysdb.DefaultLockTimeout, "time to wait for another instance's migration lock")
+	if err := flags.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	cfg := config.LoadConfigOrDefault()
+	if *configPath != "" {
+		var err error
+		if cfg, err = config.LoadConfig(*configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+			return 2
+		}
+	}
+	if !cfg.SystemDB.Enabled {
+		fmt.Fprintln(os.Stderr, "系统数据库未启用（system_db.enabled）")
+		return 2
+	}
+
+	ctx := context.Background()
+	ds, err := sysdb.NewStorage(cfg.SystemDB.Storage, cfg.SystemDBDataDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sys, err := sysdb.Connect(ctx, ds)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer sys.Close(ctx)
+
+	if command == "up" {
+		migrated, err := sys.Migrate(ctx, sysdb.MigrateOptions{DryRun: *dryRun, LockTimeout: *lockTimeout})
+		verb := "applied"
+		if *dryRun {
+			verb = "would apply"
+		}
+		for _, m := range migrated {
+			fmt.Printf("%s %d: %s\n", verb, m.Version, m.Description)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(migrated) == 0 {
+			fmt.Println("system database is up to date")
+		}
+		return 0
+	}
+
+	st, err := sys.Status(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("current version: %d\nlatest version:  %d\n\n", st.Current, st.Latest)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tAPPLIED BY\tDESCRIPTION")
+	for _, m := range st.Applied {
+		fmt.Fprintf(w, "%d\tapplied\t%s\t%s\t%s\n", m.Version, m.AppliedAt, m.AppliedBy, m.Description)
+	}
+	for _, m := range st.Pending {
+		fmt.Fprintf(w, "%d\tpending\t\t\t%s\n", m.Version, m.Description)
+	}
+	w.Flush()
+	if st.TooNew() {
+		fmt.Fprintf(os.Stderr, "\n%v: schema version %d, this binary supports up to %d\n", sysdb.ErrSchemaTooNew, st.Current, st.Latest)
+		return 1
+	}
+	return 0
+}
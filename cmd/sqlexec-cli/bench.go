@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/bench"
+	"github.com/kasuganosora/sqlexec/pkg/cli"
+)
+
+// runBench 执行 bench 子命令：准备压测表，依次运行负载并输出统计
+func runBench(args []string) int {
+	defaults := bench.DefaultConfig()
+	flags := flag.NewFlagSet("sqlexec-cli bench", flag.ContinueOnError)
+	connOpts := registerConnFlags(flags)
+	workloads := flags.String("workload", strings.Join(defaults.Workloads, ","),
+		"comma-separated workloads: "+strings.Join(bench.WorkloadNames(), ", "))
+	concurrency := flags.Int("concurrency", defaults.Concurrency, "number of concurrent sessions")
+	duration := flags.Duration("duration", defaults.Duration, "run time of each workload")
+	requests := flags.Int("requests", 0, "total requests of each workload (overrides -duration)")
+	warmup := flags.Duration("warmup", 0, "untimed warm-up before each workload")
+	tableSize := flags.Int("table-size", defaults.TableSize, "rows loaded into bench_items")
+	rangeSize := flags.Int("range-size", defaults.RangeSize, "rows read by each range scan")
+	seed := flags.Int64("seed", defaults.Seed, "random seed")
+	prepare := flags.Bool("prepare", true, "create and load the bench tables before running")
+	cleanup := flags.Bool("cleanup", true, "drop the bench tables after running")
+	format := flags.String("format", "table", "report format: table, csv, json, vertical")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsageError
+	}
+
+	cfg := bench.Config{
+		Workloads:   strings.Split(*workloads, ","),
+		Concurrency: *concurrency,
+		Duration:    *duration,
+		Requests:    *requests,
+		Warmup:      *warmup,
+		TableSize:   *tableSize,
+		RangeSize:   *rangeSize,
+		Seed:        *seed,
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsageError
+	}
+	reportFormat, err := cli.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsageError
+	}
+
+	// Ctrl-C 结束当前负载并输出已完成的结果
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	conn, err := connOpts.open(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return exitUsageError
+	}
+	defer conn.Close()
+
+	if *prepare {
+		start := time.Now()
+		if err := bench.Prepare(ctx, conn, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: prepare: %v\n", err)
+			return exitStatementError
+		}
+		fmt.Fprintf(os.Stderr, "loaded %d rows in %.1fs\n", cfg.TableSize, time.Since(start).Seconds())
+	}
+	if *cleanup {
+		defer bench.Cleanup(context.Background(), conn)
+	}
+
+	reports, err := bench.Run(ctx, conn, cfg)
+	if len(reports) > 0 {
+		if werr := bench.WriteReports(os.Stdout, reportFormat, reports); werr != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", werr)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return exitStatementError
+	}
+	for _, r := range reports {
+		if r.Errors > 0 {
+			return exitStatementError
+		}
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/cli"
+)
+
+// lintFileResult 一个输入文件的检查结果，-format json 时按此输出
+type lintFileResult struct {
+	File       string            `json:"file"`
+	Statements []*api.LintReport `json:"statements"`
+}
+
+// runLint 执行 lint 子命令：在进程内数据库中执行 -schema 的 DDL，然后检查各文件（或标准输入）中的语句，
+// 不执行它们。有错误时退出码为 1，-strict 时警告也使退出码为 1
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("sqlexec-cli lint", flag.ContinueOnError)
+	schema := flags.String("schema", "", "file with the CREATE statements of the schema to check against (required)")
+	database := flags.String("D", "", "default database")
+	format := flags.String("format", "text", "output format: text, json")
+	strict := flags.Bool("strict", false, "exit with status 1 on warnings as well as errors")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: sqlexec-cli lint -schema schema.sql [flags] [file...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsageError
+	}
+	if *schema == "" || (*format != "text" && *format != "json") {
+		flags.Usage()
+		return exitUsageError
+	}
+
+	conn, err := cli.OpenLocal("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return exitUsageError
+	}
+	defer conn.Close()
+	if err := loadLintSchema(conn, *schema); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", *schema, err)
+		return exitUsageError
+	}
+	if *database != "" {
+		if err := conn.Use(context.Background(), *database); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return exitUsageError
+		}
+	}
+	linter := conn.(cli.Linter)
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	results := make([]lintFileResult, 0, len(files))
+	errors, warnings := 0, 0
+	for _, file := range files {
+		sql, err := readLintInput(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", file, err)
+			return exitUsageError
+		}
+		// 每个文件从 -D 指定的数据库开始检查
+		if *database != "" {
+			conn.Use(context.Background(), *database)
+		}
+		reports, err := linter.Lint(sql)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", file, err)
+			return exitUsageError
+		}
+		for _, report := range reports {
+			for _, issue := range report.Issues {
+				if issue.Severity == api.LintError {
+					errors++
+				} else {
+					warnings++
+				}
+			}
+		}
+		results = append(results, lintFileResult{File: file, Statements: reports})
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(results)
+	} else {
+		printLintText(os.Stdout, results)
+		fmt.Printf("%d errors, %d warnings\n", errors, warnings)
+	}
+
+	if errors > 0 || (*strict && warnings > 0) {
+		return exitStatementError
+	}
+	return 0
+}
+
+// loadLintSchema 在进程内数据库中执行 schema 文件
+func loadLintSchema(conn cli.Conn, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	client := cli.NewClient(conn, cli.Options{Out: io.Discard, Err: os.Stderr})
+	return client.RunScript(context.Background(), f)
+}
+
+// readLintInput 读取要检查的文件，"-" 为标准输入
+func readLintInput(file string) (string, error) {
+	if file == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(file)
+	return string(data), err
+}
+
+// printLintText 每个问题输出一行：文件、语句序号、级别、规则和说明，语法错误附带语句内的行列
+func printLintText(w io.Writer, results []lintFileResult) {
+	for _, result := range results {
+		for i, report := range result.Statements {
+			for _, issue := range report.Issues {
+				fmt.Fprintf(w, "%s: statement %d: %s [%s] %s", result.File, i+1, issue.Severity, issue.Rule, issue.Message)
+				if issue.Line > 0 {
+					fmt.Fprintf(w, " (line %d, column %d)", issue.Line, issue.Column)
+				}
+				fmt.Fprintln(w)
+			}
+			if report.Cost == api.LintCostHigh {
+				fmt.Fprintf(w, "%s: statement %d: cost high: %s\n", result.File, i+1, strings.TrimSpace(report.SQL))
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/cli"
+)
+
+// 退出码：语句执行失败为 1，参数或连接错误为 2
+const (
+	exitStatementError = 1
+	exitUsageError     = 2
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(runBench(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLint(os.Args[2:]))
+	}
+	os.Exit(run(os.Args[1:]))
+}
+
+// connFlags 连接参数，交互客户端与 bench 子命令共用
+type connFlags struct {
+	host     *string
+	port     *int
+	user     *string
+	password *string
+	database *string
+	local    *bool
+	timeout  *time.Duration
+}
+
+func registerConnFlags(flags *flag.FlagSet) *connFlags {
+	return &connFlags{
+		host:     flags.String("h", "127.0.0.1", "server host"),
+		port:     flags.Int("P", 3306, "server port"),
+		user:     flags.String("u", "root", "user name"),
+		password: flags.String("p", "", "password"),
+		database: flags.String("D", "", "default database"),
+		local:    flags.Bool("local", false, "run an in-process database instead of connecting to a server"),
+		timeout:  flags.Duration("timeout", 10*time.Second, "connect timeout"),
+	}
+}
+
+func (f *connFlags) open(ctx context.Context) (cli.Conn, error) {
+	if *f.local {
+		return cli.OpenLocal(*f.database)
+	}
+	return cli.DialMySQL(ctx, cli.MySQLOptions{
+		Host:     *f.host,
+		Port:     *f.port,
+		User:     *f.user,
+		Password: *f.password,
+		Database: *f.database,
+		Timeout:  *f.timeout,
+	})
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("sqlexec-cli", flag.ContinueOnError)
+	connOpts := registerConnFlags(flags)
+	execute := flags.String("e", "", "execute the statements and exit")
+	file := flags.String("f", "", "execute the statements in the file and exit")
+	format := flags.String("format", "table", "output format: table, csv, json, vertical")
+	force := flags.Bool("force", false, "continue after statement errors in -e/-f mode")
+	timing := flags.Bool("timing", false, "print statement timing")
+	history := flags.String("history", defaultHistoryFile(), "history file for interactive mode (empty to disable)")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsageError
+	}
+	if *execute != "" && *file != "" {
+		fmt.Fprintln(os.Stderr, "-e and -f cannot be used together")
+		return exitUsageError
+	}
+	outputFormat, err := cli.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsageError
+	}
+
+	ctx := context.Background()
+	conn, err := connOpts.open(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return exitUsageError
+	}
+	defer conn.Close()
+
+	client := cli.NewClient(conn, cli.Options{
+		Format:      outputFormat,
+		Force:       *force,
+		Timing:      *timing,
+		HistoryFile: *history,
+	})
+
+	switch {
+	case *execute != "":
+		err = client.RunScript(ctx, strings.NewReader(*execute))
+	case *file != "":
+		f, openErr := os.Open(*file)
+		if openErr != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", openErr)
+			return exitUsageError
+		}
+		defer f.Close()
+		err = client.RunScript(ctx, f)
+	case isTerminal(os.Stdin):
+		err = client.RunInteractive(ctx, os.Stdin)
+	default:
+		// 标准输入不是终端时按脚本执行
+		err = client.RunScript(ctx, os.Stdin)
+	}
+	if err != nil {
+		return exitStatementError
+	}
+	return 0
+}
+
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".sqlexec_history")
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/server/prototrace"
+)
+
+// runReplay 执行 replay 子命令：把协议跟踪文件中客户端的包重新发给服务器，比较服务器的响应。
+// 每个跟踪文件使用一个新连接，有不一致时退出码为 1
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("sqlexec-cli replay", flag.ContinueOnError)
+	host := flags.String("h", "127.0.0.1", "server host")
+	port := flags.Int("P", 3306, "server port")
+	connectTimeout := flags.Duration("connect-timeout", 10*time.Second, "connect timeout")
+	timeout := flags.Duration("timeout", 5*time.Second, "time to wait for each response packet")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: sqlexec-cli replay [flags] trace-file...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return exitUsageError
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsageError
+	}
+
+	addr := net.JoinHostPort(*host, strconv.Itoa(*port))
+	status := 0
+	for _, file := range flags.Args() {
+		records, err := readTraceFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", file, err)
+			return exitUsageError
+		}
+		conn, err := net.DialTimeout("tcp", addr, *connectTimeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			return exitUsageError
+		}
+		result, err := prototrace.Replay(conn, records, &prototrace.ReplayOptions{Timeout: *timeout})
+		conn.Close()
+		if result != nil {
+			for _, m := range result.Mismatches {
+				fmt.Printf("%s: %s\n", file, m)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", file, err)
+			status = exitStatementError
+			continue
+		}
+		fmt.Printf("%s: %d requests, %d response packets, %d mismatches\n",
+			file, result.Requests, result.Packets, len(result.Mismatches))
+		if len(result.Mismatches) > 0 {
+			status = exitStatementError
+		}
+	}
+	return status
+}
+
+// readTraceFile 读取协议跟踪文件
+func readTraceFile(file string) ([]prototrace.Record, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return prototrace.ReadTrace(f)
+}
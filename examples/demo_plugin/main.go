@@ -19,6 +19,8 @@ import (
 
 var (
 	instances = make(map[string]*Instance)
+	cursors   = make(map[string]*Cursor)
+	cursorSeq int
 	mu        sync.Mutex
 )
 
@@ -28,6 +30,13 @@ type Instance struct {
 	Connected bool
 	Writable  bool
 	Tables    map[string]*Table
+	Changes   []SchemaChange // schema change log, index+1 is the version
+}
+
+// SchemaChange records a table schema change for get_changes
+type SchemaChange struct {
+	Table string `json:"table"`
+	Type  string `json:"type"`
 }
 
 // Table represents a table with schema and rows
@@ -37,14 +46,20 @@ type Table struct {
 	Rows    []map[string]interface{} `json:"rows"`
 }
 
+// Cursor holds the remaining rows of a paged query
+type Cursor struct {
+	Rows []map[string]interface{}
+}
+
 // ── Exported C functions ──
 
 //export PluginGetInfo
 func PluginGetInfo() *C.char {
 	info := map[string]interface{}{
-		"type":        "demo",
-		"version":     "1.0.0",
-		"description": "Demo in-memory datasource plugin for testing",
+		"type":         "demo",
+		"version":      "1.0.0",
+		"description":  "Demo in-memory datasource plugin for testing",
+		"capabilities": []string{"get_changes"},
 	}
 	data, _ := json.Marshal(info)
 	return C.CString(string(data))
@@ -84,6 +99,12 @@ func PluginHandleRequest(reqC *C.char) *C.char {
 		return handleGetTableInfo(req.ID, table)
 	case "query":
 		return handleQuery(req.ID, req.Params)
+	case "fetch":
+		return handleFetch(req.Params)
+	case "close_cursor":
+		return handleCloseCursor(req.Params)
+	case "get_changes":
+		return handleGetChanges(req.ID, req.Params)
 	case "insert":
 		return handleInsert(req.ID, req.Params)
 	case "update":
@@ -231,13 +252,58 @@ func handleQuery(id string, params map[string]interface{}) *C.char {
 		rows = applyLimitOffset(rows, opts)
 	}
 
+	// Without page_size the whole result is returned at once
+	pageSize, _ := params["page_size"].(float64)
+	if pageSize <= 0 || len(rows) <= int(pageSize) {
+		return okResp(map[string]interface{}{
+			"columns": tbl.Columns,
+			"rows":    rows,
+			"total":   len(rows),
+			"done":    true,
+		})
+	}
+
+	cursorSeq++
+	cursorID := fmt.Sprintf("%s-%d", id, cursorSeq)
+	cursors[cursorID] = &Cursor{Rows: rows[int(pageSize):]}
 	return okResp(map[string]interface{}{
-		"columns": tbl.Columns,
-		"rows":    rows,
-		"total":   len(rows),
+		"cursor_id": cursorID,
+		"columns":   tbl.Columns,
+		"rows":      rows[:int(pageSize)],
+		"done":      false,
 	})
 }
 
+func handleFetch(params map[string]interface{}) *C.char {
+	cursorID, _ := params["cursor_id"].(string)
+	cur, ok := cursors[cursorID]
+	if !ok {
+		return errResp("cursor not found: " + cursorID)
+	}
+
+	pageSize := len(cur.Rows)
+	if ps, ok := params["page_size"].(float64); ok && ps > 0 && int(ps) < pageSize {
+		pageSize = int(ps)
+	}
+	page := cur.Rows[:pageSize]
+	cur.Rows = cur.Rows[pageSize:]
+
+	done := len(cur.Rows) == 0
+	if done {
+		delete(cursors, cursorID)
+	}
+	return okResp(map[string]interface{}{
+		"rows": page,
+		"done": done,
+	})
+}
+
+func handleCloseCursor(params map[string]interface{}) *C.char {
+	cursorID, _ := params["cursor_id"].(string)
+	delete(cursors, cursorID)
+	return okResp(map[string]interface{}{"success": true})
+}
+
 func handleInsert(id string, params map[string]interface{}) *C.char {
 	inst, e := getInstance(id)
 	if inst == nil {
@@ -342,6 +408,7 @@ func handleCreateTable(id string, params map[string]interface{}) *C.char {
 		Columns: columns,
 		Rows:    make([]map[string]interface{}, 0),
 	}
+	inst.Changes = append(inst.Changes, SchemaChange{Table: name, Type: "created"})
 	return okResp(map[string]interface{}{})
 }
 
@@ -354,9 +421,28 @@ func handleDropTable(id, tableName string) *C.char {
 		return errResp("table not found: " + tableName)
 	}
 	delete(inst.Tables, tableName)
+	inst.Changes = append(inst.Changes, SchemaChange{Table: tableName, Type: "dropped"})
 	return okResp(map[string]interface{}{})
 }
 
+func handleGetChanges(id string, params map[string]interface{}) *C.char {
+	inst, e := getInstance(id)
+	if inst == nil {
+		return e
+	}
+	since := 0
+	if v, ok := params["since"].(float64); ok && v > 0 {
+		since = int(v)
+	}
+	if since > len(inst.Changes) {
+		since = len(inst.Changes)
+	}
+	return okResp(map[string]interface{}{
+		"changes": inst.Changes[since:],
+		"version": len(inst.Changes),
+	})
+}
+
 func handleTruncateTable(id, tableName string) *C.char {
 	inst, e := getInstance(id)
 	if inst == nil {
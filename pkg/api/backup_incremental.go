@@ -0,0 +1,204 @@
+package api
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// 增量备份归档包含每个数据库在基础备份之后提交的变更：
+//
+//	<数据库>/changes.seg  LSN 在 (BaseLSN, LSN] 之间的变更，格式与变更归档的段文件相同
+//	manifest.json        BackupManifest，Type 为 incremental
+const backupChangesName = "changes.seg"
+
+// BackupIncrementalTo writes the changes each database committed after base
+// to w. base is the manifest of a full or incremental backup, so incremental
+// backups can be chained. The changes come from the change log, or from the
+// change archive when the log already dropped them; the database's change log
+// must have stayed enabled since base was taken.
+func (db *DB) BackupIncrementalTo(ctx context.Context, w io.Writer, base *BackupManifest, databases []string) (*BackupManifest, error) {
+	selected, err := selectBackupDatabases(base, databases)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &BackupManifest{Version: backupManifestVersion, Type: BackupIncremental, CreatedAt: time.Now().UTC()}
+	tw := tar.NewWriter(w)
+
+	tmp, err := os.CreateTemp("", "sqlexec-backup-*.seg")
+	if err != nil {
+		return nil, WrapError(err, ErrCodeInternal, "failed to create backup file")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	for _, baseDB := range selected {
+		seg, err := db.changesSinceBackup(ctx, baseDB)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := tmp.Truncate(0); err != nil {
+			return nil, err
+		}
+		if err := writeChangeSegment(tmp, seg); err != nil {
+			return nil, WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to back up changes of '%s'", baseDB.Name))
+		}
+		size, err := tmp.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		file := path.Join(baseDB.Name, backupChangesName)
+		if err := writeTarEntry(tw, file, size, tmp); err != nil {
+			return nil, WrapError(err, ErrCodeInternal, "failed to write backup")
+		}
+		manifest.Databases = append(manifest.Databases, BackupDatabase{
+			Name:        baseDB.Name,
+			ChangeLog:   seg.ChangeLog,
+			LSN:         seg.To,
+			BaseLSN:     seg.From,
+			Changes:     int64(len(seg.Changes)),
+			ChangesFile: file,
+		})
+	}
+
+	if err := finishBackup(tw, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// selectBackupDatabases 返回备份中被选中的数据库，databases 为空时返回全部
+func selectBackupDatabases(manifest *BackupManifest, databases []string) ([]BackupDatabase, error) {
+	if len(databases) == 0 {
+		return manifest.Databases, nil
+	}
+	var selected []BackupDatabase
+	seen := make(map[string]bool, len(databases))
+	for _, name := range databases {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		found := false
+		for _, database := range manifest.Databases {
+			if database.Name == name {
+				selected = append(selected, database)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf("database '%s' is not in the backup", name), nil)
+		}
+	}
+	return selected, nil
+}
+
+// changesSinceBackup 读取数据库在备份之后提交的变更：先从变更日志读取，
+// 变更日志已丢弃的部分从变更归档补齐
+func (db *DB) changesSinceBackup(ctx context.Context, base BackupDatabase) (*changeSegment, error) {
+	ds, err := db.GetDataSource(base.Name)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := ds.(domain.ChangeLogSource)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, fmt.Sprintf("database '%s' does not record changes", base.Name), nil)
+	}
+	logID := source.ChangeLogID()
+	if base.ChangeLog == "" || logID != base.ChangeLog {
+		return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf(
+			"the change log of database '%s' does not continue from the base backup: take a new full backup", base.Name), nil)
+	}
+
+	changes, latest, err := source.ChangesSince(ctx, base.LSN)
+	if err != nil {
+		archived, last, archiveErr := db.archivedChanges(base.Name, logID, base.LSN)
+		if archiveErr != nil {
+			return nil, WrapError(archiveErr, ErrCodeInternal, fmt.Sprintf("failed to read change archive of '%s'", base.Name))
+		}
+		tail, tailLatest, tailErr := source.ChangesSince(ctx, last)
+		if tailErr != nil {
+			return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf(
+				"changes of database '%s' after LSN %d are no longer in the change log or the change archive: take a new full backup",
+				base.Name, base.LSN), tailErr)
+		}
+		changes, latest = append(archived, tail...), tailLatest
+	}
+	if source.ChangeLogID() != logID {
+		return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf(
+			"the change log of database '%s' was reset during the backup: take a new full backup", base.Name), nil)
+	}
+	return &changeSegment{ChangeLog: logID, From: base.LSN, To: latest, Changes: changes}, nil
+}
+
+// scanBackup 依次读取备份归档中的条目
+func scanBackup(file string, fn func(name string, r io.Reader) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return WrapError(err, ErrCodeInvalidParam, fmt.Sprintf("failed to open backup '%s'", file))
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return WrapError(err, ErrCodeInvalidParam, fmt.Sprintf("invalid backup '%s'", file))
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// errManifestFound 找到 manifest.json 后停止读取
+var errManifestFound = errors.New("manifest found")
+
+// readBackupManifest 读取备份文件的 manifest.json
+func readBackupManifest(file string) (*BackupManifest, error) {
+	var manifest *BackupManifest
+	err := scanBackup(file, func(name string, r io.Reader) error {
+		if name != backupManifestName {
+			return nil
+		}
+		manifest = &BackupManifest{}
+		if err := json.NewDecoder(r).Decode(manifest); err != nil {
+			return WrapError(err, ErrCodeInvalidParam, fmt.Sprintf("invalid backup manifest in '%s'", file))
+		}
+		return errManifestFound
+	})
+	if err != nil && err != errManifestFound {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf("'%s' is not a backup: it has no %s", file, backupManifestName), nil)
+	}
+	if manifest.Version != backupManifestVersion {
+		return nil, NewError(ErrCodeNotSupported, fmt.Sprintf("backup '%s' has unsupported version %d", file, manifest.Version), nil)
+	}
+	if manifest.Type == "" {
+		manifest.Type = BackupFull
+	}
+	return manifest, nil
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/objstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBackupArchive 读取备份归档中的所有文件
+func readBackupArchive(t *testing.T, r io.Reader) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = data
+	}
+}
+
+// TestBackupDatabase 测试 BACKUP DATABASE 写出一致性快照和变更日志位置，备份可以用 IMPORT TABLE 恢复
+func TestBackupDatabase(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	require.NoError(t, s.db.SetChangeLogSize(100))
+	_, err := s.Execute(`INSERT INTO users (name, city) VALUES ('Dave', 'Rome')`)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "nightly.tar")
+	_, err = s.Execute(fmt.Sprintf(`BACKUP DATABASE test TO '%s'`, target))
+	assert.ErrorContains(t, err, "without outfile_dirs")
+
+	s.db.config.OutfileDirs = []string{dir}
+	res, err := s.Execute(fmt.Sprintf(`BACKUP DATABASE test TO '%s'`, target))
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, res.RowsAffected)
+	assert.Contains(t, res.Info, "at LSN test=1")
+
+	// 目标已存在时不覆盖
+	_, err = s.Execute(fmt.Sprintf(`BACKUP DATABASE * TO '%s'`, target))
+	assert.ErrorContains(t, err, "already exists")
+
+	f, err := os.Open(target)
+	require.NoError(t, err)
+	defer f.Close()
+	files := readBackupArchive(t, f)
+	var manifest BackupManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	require.Len(t, manifest.Databases, 1)
+	assert.Equal(t, "test", manifest.Databases[0].Name)
+	assert.EqualValues(t, 1, manifest.Databases[0].LSN)
+	require.Len(t, manifest.Databases[0].Tables, 1)
+	assert.Equal(t, BackupTable{Name: "users", File: "test/users.snap", Rows: 4, Bytes: int64(len(files["test/users.snap"]))},
+		manifest.Databases[0].Tables[0])
+
+	snapPath := filepath.Join(dir, "users.snap")
+	require.NoError(t, os.WriteFile(snapPath, files["test/users.snap"], 0o644))
+	_, err = s.Execute(fmt.Sprintf(`IMPORT TABLE users_restored FROM '%s'`, snapPath))
+	require.NoError(t, err)
+	rows, err := s.QueryAll(`SELECT name FROM users_restored WHERE city = 'Rome'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Dave", rows[0]["name"])
+
+	_, err = s.Execute(fmt.Sprintf(`BACKUP DATABASE missing TO '%s'`, filepath.Join(dir, "missing.tar")))
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "missing.tar"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestBackupDatabase_S3 测试备份上传到 S3 兼容的对象存储
+func TestBackupDatabase_S3(t *testing.T) {
+	var uploaded []byte
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		uploaded, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	s := newTestSession(t, usersFixture...)
+	s.db.config.BackupS3 = &objstore.S3Config{Endpoint: srv.URL, AccessKeyID: "AKID", SecretAccessKey: "SECRET", PathStyle: true}
+	res, err := s.Execute(`BACKUP DATABASE * TO 's3://backups/2024/full.tar'`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+	assert.Equal(t, "/backups/2024/full.tar", path)
+
+	files := readBackupArchive(t, bytes.NewReader(uploaded))
+	assert.Contains(t, files, "test/users.snap")
+	assert.Contains(t, files, "manifest.json")
+
+	_, err = s.Execute(`BACKUP DATABASE * TO 's3://backups'`)
+	assert.ErrorContains(t, err, "invalid S3 URL")
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// 变更日志归档：后台定期把数据源变更日志中新增的变更写入段文件，
+// 增量备份所需的变更已被内存中的变更日志丢弃时从段文件读取
+//
+//	<change_archive_dir>/<数据库>/<变更日志标识>/<起始LSN>-<结束LSN>.seg
+//
+// 段文件包含 LSN 在 (起始LSN, 结束LSN] 之间的变更，使用 gob 编码以保留行中值的类型
+const changeSegmentExt = ".seg"
+
+func init() {
+	// 行中的 interface{} 值需要注册具体类型，基本类型和切片由 gob 预先注册
+	gob.Register(time.Time{})
+}
+
+// changeSegment 一段连续的已提交变更
+type changeSegment struct {
+	ChangeLog string
+	From      int64 // 不包含
+	To        int64
+	Changes   []domain.ChangeEvent
+}
+
+func writeChangeSegment(w io.Writer, seg *changeSegment) error {
+	return gob.NewEncoder(w).Encode(seg)
+}
+
+func readChangeSegment(r io.Reader) (*changeSegment, error) {
+	var seg changeSegment
+	if err := gob.NewDecoder(r).Decode(&seg); err != nil {
+		return nil, fmt.Errorf("invalid change segment: %w", err)
+	}
+	return &seg, nil
+}
+
+// changeArchiver copies committed changes to segment files in the background
+type changeArchiver struct {
+	mu   sync.Mutex              // serializes archive passes; guards last, stop and done
+	last map[string]archivedUpTo // database -> position already archived
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// archivedUpTo is the last archived LSN of a change log
+type archivedUpTo struct {
+	changeLog string
+	lsn       int64
+}
+
+func newChangeArchiver() *changeArchiver {
+	return &changeArchiver{last: make(map[string]archivedUpTo)}
+}
+
+// ArchiveChanges writes the changes each database committed since the
+// previous pass to a segment file under DBConfig.ChangeArchiveDir and returns
+// the number of changes archived. Changes the change log already dropped are
+// lost to the archive, so change_log_size must cover the archive interval.
+func (db *DB) ArchiveChanges(ctx context.Context) (int64, error) {
+	dir := db.config.ChangeArchiveDir
+	if dir == "" {
+		return 0, NewError(ErrCodeInvalidParam, "change archiving requires change_archive_dir", nil)
+	}
+	a := db.archiver
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names := db.GetDataSourceNames()
+	sort.Strings(names)
+	var total int64
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		ds, err := db.GetDataSource(name)
+		if err != nil {
+			continue
+		}
+		source, ok := ds.(domain.ChangeLogSource)
+		if !ok {
+			continue
+		}
+		logID := source.ChangeLogID()
+		if logID == "" {
+			continue
+		}
+		segDir := filepath.Join(dir, name, logID)
+		pos, ok := a.last[name]
+		if !ok || pos.changeLog != logID {
+			segments, err := listChangeSegments(segDir)
+			if err != nil {
+				return total, WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to read change archive of '%s'", name))
+			}
+			pos = archivedUpTo{changeLog: logID}
+			if len(segments) > 0 {
+				pos.lsn = segments[len(segments)-1].to
+			}
+		}
+
+		changes, latest, err := source.ChangesSince(ctx, pos.lsn)
+		if err != nil {
+			// 变更已被丢弃，从最新位置继续，缺口之后的增量备份需要新的全量备份
+			db.logger.Warn("change archive of '%s' has a gap after LSN %d: %v", name, pos.lsn, err)
+			a.last[name] = archivedUpTo{changeLog: logID, lsn: latest}
+			continue
+		}
+		if len(changes) == 0 || source.ChangeLogID() != logID {
+			continue
+		}
+		seg := &changeSegment{ChangeLog: logID, From: pos.lsn, To: latest, Changes: changes}
+		if err := saveChangeSegment(segDir, seg); err != nil {
+			return total, WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to archive changes of '%s'", name))
+		}
+		a.last[name] = archivedUpTo{changeLog: logID, lsn: latest}
+		total += int64(len(changes))
+	}
+	return total, nil
+}
+
+// saveChangeSegment 先写临时文件再改名，目录中只会出现完整的段文件
+func saveChangeSegment(dir string, seg *changeSegment) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%020d-%020d%s", seg.From, seg.To, changeSegmentExt))
+	tmp := name + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := writeChangeSegment(file, seg); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// segmentFile 归档目录中的一个段文件
+type segmentFile struct {
+	path     string
+	from, to int64
+}
+
+// listChangeSegments 按起始 LSN 列出目录中的段文件，目录不存在时返回空
+func listChangeSegments(dir string) ([]segmentFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var segments []segmentFile
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), changeSegmentExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		var seg segmentFile
+		if n, err := fmt.Sscanf(name, "%d-%d", &seg.from, &seg.to); err != nil || n != 2 {
+			continue
+		}
+		seg.path = filepath.Join(dir, entry.Name())
+		segments = append(segments, seg)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].from < segments[j].from })
+	return segments, nil
+}
+
+// archivedChanges 从归档中读取 LSN 大于 since 的连续变更，返回读到的最后一个 LSN
+func (db *DB) archivedChanges(database, changeLog string, since int64) ([]domain.ChangeEvent, int64, error) {
+	if db.config.ChangeArchiveDir == "" {
+		return nil, since, nil
+	}
+	segments, err := listChangeSegments(filepath.Join(db.config.ChangeArchiveDir, database, changeLog))
+	if err != nil {
+		return nil, since, err
+	}
+	var changes []domain.ChangeEvent
+	last := since
+	for _, segFile := range segments {
+		if segFile.to <= last {
+			continue
+		}
+		if segFile.from > last {
+			break
+		}
+		file, err := os.Open(segFile.path)
+		if err != nil {
+			return nil, since, err
+		}
+		seg, err := readChangeSegment(file)
+		file.Close()
+		if err != nil {
+			return nil, since, fmt.Errorf("%s: %w", segFile.path, err)
+		}
+		for _, change := range seg.Changes {
+			if change.LSN > last {
+				changes = append(changes, change)
+			}
+		}
+		last = seg.To
+	}
+	return changes, last, nil
+}
+
+// StartChangeArchiver archives committed changes to DBConfig.ChangeArchiveDir
+// every interval until StopChangeArchiver or Close is called, which run a
+// final pass. Calling it again restarts the archiver.
+func (db *DB) StartChangeArchiver(interval time.Duration) {
+	if interval <= 0 || db.config.ChangeArchiveDir == "" {
+		return
+	}
+	db.StopChangeArchiver()
+
+	a := db.archiver
+	a.mu.Lock()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	a.stop, a.done = stop, done
+	a.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := db.ArchiveChanges(context.Background()); err != nil {
+					db.logger.Warn("change archive failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// StopChangeArchiver stops the background change archiver, if running, after
+// archiving the changes committed since its last pass
+func (db *DB) StopChangeArchiver() {
+	a := db.archiver
+	a.mu.Lock()
+	stop, done := a.stop, a.done
+	a.stop, a.done = nil, nil
+	a.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+		if _, err := db.ArchiveChanges(context.Background()); err != nil {
+			db.logger.Warn("change archive failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ChangeListenerOption configures a change listener
+type ChangeListenerOption func(*changeListenerConfig)
+
+type changeListenerConfig struct {
+	async  bool
+	buffer int
+}
+
+// AsyncChangeDelivery delivers events on a separate goroutine instead of the
+// writing one. Events are queued in a buffer of the given size and delivered
+// in order; writers block while the buffer is full. The listener must not
+// unregister itself.
+func AsyncChangeDelivery(buffer int) ChangeListenerOption {
+	return func(c *changeListenerConfig) {
+		c.async = true
+		c.buffer = max(buffer, 0)
+	}
+}
+
+// changeListeners tracks the registered listeners so Close can stop them
+type changeListeners struct {
+	mu    sync.Mutex
+	next  int64
+	stops map[int64]func()
+}
+
+func newChangeListeners() *changeListeners {
+	return &changeListeners{stops: make(map[int64]func())}
+}
+
+func (l *changeListeners) add(stop func()) func() {
+	l.mu.Lock()
+	l.next++
+	id := l.next
+	l.stops[id] = stop
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		_, ok := l.stops[id]
+		delete(l.stops, id)
+		l.mu.Unlock()
+		if ok {
+			stop()
+		}
+	}
+}
+
+func (l *changeListeners) closeAll() {
+	l.mu.Lock()
+	stops := l.stops
+	l.stops = make(map[int64]func())
+	l.mu.Unlock()
+
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// RegisterChangeListener calls fn for every row inserted, updated or deleted
+// in table once the statement or transaction has committed. The table may be
+// qualified with a datasource name ("ds.table"); an empty table listens to all
+// tables of the default datasource. By default fn runs synchronously on the
+// writing goroutine before the write returns, so it must not block for long.
+// The returned function unregisters the listener.
+func (db *DB) RegisterChangeListener(table string, fn func(domain.ChangeEvent), opts ...ChangeListenerOption) (func(), error) {
+	if fn == nil {
+		return nil, NewError(ErrCodeInvalidParam, "change listener function is nil", nil)
+	}
+	cfg := changeListenerConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ds, table, err := db.changeListenerSource(table)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := ds.(domain.ChangeLogSource)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, "datasource does not support change listeners", nil)
+	}
+
+	if !cfg.async {
+		return db.listeners.add(source.SubscribeChanges(table, fn)), nil
+	}
+
+	events := make(chan domain.ChangeEvent, cfg.buffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range events {
+			fn(event)
+		}
+	}()
+	var mu sync.RWMutex
+	closed := false
+	unsubscribe := source.SubscribeChanges(table, func(event domain.ChangeEvent) {
+		mu.RLock()
+		defer mu.RUnlock()
+		if !closed {
+			events <- event
+		}
+	})
+	return db.listeners.add(func() {
+		unsubscribe()
+		mu.Lock()
+		closed = true
+		close(events)
+		mu.Unlock()
+		<-done
+	}), nil
+}
+
+// changeListenerSource resolves an optionally datasource-qualified table name
+func (db *DB) changeListenerSource(table string) (domain.DataSource, string, error) {
+	if dsName, name, ok := strings.Cut(table, "."); ok {
+		if ds, err := db.GetDataSource(dsName); err == nil {
+			return ds, name, nil
+		}
+	}
+	ds, err := db.GetDefaultDataSource()
+	if err != nil {
+		return nil, "", err
+	}
+	return ds, table, nil
+}
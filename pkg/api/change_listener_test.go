@@ -0,0 +1,95 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChangeListener_Sync 测试同步监听器在语句返回前收到带前后镜像的行变更
+func TestChangeListener_Sync(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	var events []domain.ChangeEvent
+	unregister, err := s.db.RegisterChangeListener("test.users", func(e domain.ChangeEvent) {
+		events = append(events, e)
+	})
+	require.NoError(t, err)
+
+	_, err = s.Execute(`UPDATE users SET city = 'Rome' WHERE name = 'Alice'`)
+	require.NoError(t, err)
+	_, err = s.Execute(`DELETE FROM users WHERE name = 'bob'`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO users (name, city) VALUES ('Dave', 'Oslo')`)
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, domain.ChangeUpdate, events[0].Type)
+	assert.Equal(t, "users", events[0].Table)
+	assert.Equal(t, "Paris", events[0].Before["city"])
+	assert.Equal(t, "Rome", events[0].After["city"])
+	assert.Equal(t, domain.ChangeDelete, events[1].Type)
+	assert.Equal(t, "bob", events[1].Before["name"])
+	assert.Nil(t, events[1].After)
+	assert.Equal(t, domain.ChangeInsert, events[2].Type)
+	assert.Equal(t, "Dave", events[2].After["name"])
+
+	// 事务提交后才通知
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`UPDATE users SET city = 'Lima' WHERE name = 'Dave'`)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+	require.NoError(t, tx.Commit())
+	require.Len(t, events, 4)
+	assert.NotZero(t, events[3].TxnID)
+
+	unregister()
+	_, err = s.Execute(`DELETE FROM users`)
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+}
+
+// TestChangeListener_Async 测试异步监听器在独立的 goroutine 中按顺序收到变更
+func TestChangeListener_Async(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	received := make(chan domain.ChangeEvent, 10)
+	unregister, err := s.db.RegisterChangeListener("users", func(e domain.ChangeEvent) {
+		received <- e
+	}, AsyncChangeDelivery(1))
+	require.NoError(t, err)
+
+	_, err = s.Execute(`INSERT INTO users (name, city) VALUES ('Erin', 'Kyiv'), ('Finn', 'Cork')`)
+	require.NoError(t, err)
+	for _, name := range []string{"Erin", "Finn"} {
+		select {
+		case e := <-received:
+			assert.Equal(t, name, e.After["name"])
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+
+	unregister()
+	unregister()
+	_, err = s.Execute(`DELETE FROM users`)
+	require.NoError(t, err)
+	assert.Empty(t, received)
+}
+
+// TestChangeListener_Errors 测试无效参数与不支持变更日志的数据源
+func TestChangeListener_Errors(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	_, err := s.db.RegisterChangeListener("users", nil)
+	require.Error(t, err)
+
+	require.NoError(t, s.db.RegisterDataSource("mock", NewMockDataSourceWithTableInfo("users", []domain.ColumnInfo{{Name: "id", Type: "INT"}})))
+	_, err = s.db.RegisterChangeListener("mock.users", func(domain.ChangeEvent) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support change listeners")
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDatabaseRouting_UseSwitchesUnqualifiedTables 测试 USE 后未限定的表名解析到新的当前数据库
+func TestDatabaseRouting_UseSwitchesUnqualifiedTables(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+
+	_, err := s.Execute(`USE ledger`)
+	require.NoError(t, err)
+	assert.Equal(t, "ledger", s.GetCurrentDB())
+
+	_, err = s.Execute(`INSERT INTO entries (id, amount) VALUES (1, 10)`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM entries`))
+
+	_, err = s.Query(`SELECT * FROM accounts`)
+	assert.Error(t, err)
+
+	require.NoError(t, s.UseDatabase("test"))
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+}
+
+// TestDatabaseRouting_QualifiedTables 测试 db.table 访问当前数据库之外的表
+func TestDatabaseRouting_QualifiedTables(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 10), (2, 20)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE ledger.entries SET amount = 30 WHERE id = 2`)
+	require.NoError(t, err)
+	_, err = s.Execute(`DELETE FROM ledger.entries WHERE id = 1`)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM ledger.entries WHERE amount = 30`))
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM test.accounts`))
+
+	// 当前数据库不受影响
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+}
+
+// TestDatabaseRouting_UnknownDatabase 测试限定到未注册的数据库返回 1049
+func TestDatabaseRouting_UnknownDatabase(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Query(`SELECT * FROM nope.accounts`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrBadDB, code)
+
+	_, err = s.Execute(`INSERT INTO nope.accounts (id, balance) VALUES (9, 9)`)
+	require.Error(t, err)
+	code, _ = mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrBadDB, code)
+}
+
+// TestDatabaseRouting_TransactionFollowsCurrentDB 测试事务中未限定的表名使用当前数据库的分支
+func TestDatabaseRouting_TransactionFollowsCurrentDB(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+	require.NoError(t, s.UseDatabase("ledger"))
+
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`INSERT INTO entries (id, amount) VALUES (1, 10)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	ls := db.SessionWithOptions(&SessionOptions{DataSourceName: "ledger"})
+	defer ls.Close()
+	assert.Equal(t, 1, countRows(t, ls, `SELECT * FROM entries`))
+}
+
+// TestDatabaseRouting_CrossDatabaseJoin 测试 JOIN 的各表分别解析到所在数据库的数据源
+func TestDatabaseRouting_CrossDatabaseJoin(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 10), (2, 20), (3, 30)`)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`SELECT a.id, e.amount FROM accounts a JOIN ledger.entries e ON a.id = e.id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	amounts := map[interface{}]interface{}{}
+	for _, row := range rows {
+		amounts[row["id"]] = row["amount"]
+	}
+	assert.EqualValues(t, 10, amounts[int64(1)])
+	assert.EqualValues(t, 20, amounts[int64(2)])
+
+	// 两侧都限定库名，不带别名时以表名引用
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM test.accounts JOIN ledger.entries ON accounts.id = entries.id`))
+	assert.Equal(t, 3, countRows(t, s, `SELECT * FROM ledger.entries e LEFT JOIN test.accounts a ON e.id = a.id`))
+
+	// 两张表都有 id 列，未限定的 id 有歧义
+	_, err = s.QueryAll(`SELECT id, amount FROM accounts a JOIN ledger.entries e ON a.id = e.id`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrNonUniq, code)
+
+	_, err = s.Query(`SELECT * FROM accounts a JOIN nope.entries e ON a.id = e.id`)
+	require.Error(t, err)
+	code, _ = mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrBadDB, code)
+}
@@ -5,9 +5,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/objstore"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/quota"
 	"github.com/kasuganosora/sqlexec/pkg/resource/application"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/kasuganosora/sqlexec/pkg/tenant"
 )
 
 // DB is the main database object for managing datasources and creating sessions
@@ -19,6 +23,21 @@ type DB struct {
 	cache       *QueryCache
 	logger      Logger
 	config      *DBConfig
+
+	schemaWatcher *schemaWatcher
+	ttlPurger     *ttlPurger
+	archiver      *changeArchiver
+	healthProber  *healthProber
+	xa            *application.XACoordinator
+	writeGuard    *writeGuard
+	quotas        *quota.Manager
+	listeners     *changeListeners
+	rewriteHooks  *parser.RewriteHooks
+	globalVars    *session.GlobalVariableHooks
+	idempotency   *idempotencyStore // 未启用幂等键时为 nil
+
+	failoverMu sync.RWMutex
+	failover   map[string]*failoverState // 按数据库设置的故障切换策略
 }
 
 // DBConfig contains configuration options for the DB object
@@ -31,6 +50,38 @@ type DBConfig struct {
 	QueryTimeout         time.Duration // 全局查询超时, 0表示不限制
 	UseEnhancedOptimizer bool          // 是否使用增强优化器（默认true）
 	DatabaseDir          string        // 持久化存储根目录，默认 "./database"
+	SchemaPollInterval   time.Duration // 轮询数据源表结构变化的间隔, 0表示不轮询
+	TTLPurgeInterval     time.Duration // 后台清理过期行（表 TTL）的间隔, 0表示不清理
+	TTLPurgeBatchSize    int           // 清理过期行时每批扫描的行数, 默认1000
+	TransactionalDDL     bool          // 事务内允许执行 DDL，变更在提交时生效（需数据源支持）
+	XALogPath            string        // 跨数据源事务两阶段提交的恢复日志文件, 为空时仅保存在内存
+	ReadOnly             bool          // 全局只读（read_only），具有 SUPER 权限的会话不受限制
+	// WritePolicies 按数据库（数据源或虚拟数据库名）设置的写入策略，覆盖数据源自身的 IsWritable
+	WritePolicies map[string]domain.WritePolicy
+	// OutfileDirs SELECT ... INTO OUTFILE 可以写入的目录（类似 secure_file_priv），为空时禁止导出到文件
+	OutfileDirs []string
+	// Quotas 按表和按数据库的写入配额（行数、数据量），可以按用户分别设置
+	Quotas []quota.Quota
+	// Tenancy 多租户隔离：按用户的租户 ID 自动限定按租户隔离的表上的语句, nil表示不启用
+	Tenancy *tenant.Config
+	// ColumnEncryption 加密列的密钥来源和可以读取明文的用户, nil表示不能创建加密列
+	ColumnEncryption *ColumnEncryptionConfig
+	// BackupS3 备份到 s3:// 目标时使用的 S3 连接配置, nil表示只从 AWS_* 环境变量读取
+	BackupS3 *objstore.S3Config
+	// ChangeArchiveDir 定期归档变更日志的目录，增量备份在变更日志已丢弃所需变更时从这里读取, 为空表示不归档
+	ChangeArchiveDir string
+	// ChangeArchiveInterval 归档变更日志的间隔, 默认1分钟
+	ChangeArchiveInterval time.Duration
+	// HealthCheckInterval 后台探测数据源连通性的间隔, 0表示不探测
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout 单个数据源探测的超时时间, 默认5秒
+	HealthCheckTimeout time.Duration
+	// QuarantineAfter 连续探测失败多少次后隔离数据源（语句立即失败）, 0表示不隔离
+	QuarantineAfter int
+	// SchemaSnapshotInterval 定期为所有数据源的表结构拍摄快照以检测漂移（SHOW SCHEMA CHANGES）的间隔, 0表示不快照
+	SchemaSnapshotInterval time.Duration
+	// IdempotencyTTL 写入语句的幂等键（/* idempotency_key=... */）记录保留的时间, 0表示不启用
+	IdempotencyTTL time.Duration
 }
 
 // NewDB creates a new DB object with the given configuration
@@ -62,13 +113,62 @@ func NewDB(config *DBConfig) (*DB, error) {
 
 	dsManager := application.NewDataSourceManager()
 
-	return &DB{
-		dataSources: make(map[string]domain.DataSource),
-		dsManager:   dsManager,
-		cache:       cache,
-		logger:      config.DefaultLogger,
-		config:      config,
-	}, nil
+	var xaLog application.XALog
+	if config.XALogPath != "" {
+		fileLog, err := application.OpenFileXALog(config.XALogPath)
+		if err != nil {
+			return nil, WrapError(err, ErrCodeInternal, "failed to open xa log")
+		}
+		xaLog = fileLog
+	}
+
+	db := &DB{
+		dataSources:   make(map[string]domain.DataSource),
+		dsManager:     dsManager,
+		cache:         cache,
+		logger:        config.DefaultLogger,
+		config:        config,
+		schemaWatcher: newSchemaWatcher(),
+		ttlPurger:     newTTLPurger(),
+		archiver:      newChangeArchiver(),
+		healthProber:  &healthProber{},
+		xa:            application.NewXACoordinator(dsManager, xaLog),
+		writeGuard:    newWriteGuard(config.ReadOnly, config.WritePolicies),
+		quotas:        quota.NewManager(config.Quotas),
+		listeners:     newChangeListeners(),
+		rewriteHooks:  parser.NewRewriteHooks(),
+		globalVars:    session.NewGlobalVariableHooks(),
+	}
+	if config.IdempotencyTTL > 0 {
+		db.idempotency = newIdempotencyStore(config.IdempotencyTTL)
+	}
+	if config.Tenancy != nil {
+		if err := config.Tenancy.Validate(); err != nil {
+			return nil, WrapError(err, ErrCodeInvalidParam, "invalid tenancy config")
+		}
+		if err := db.rewriteHooks.Register(tenant.New(*config.Tenancy).Hook()); err != nil {
+			return nil, WrapError(err, ErrCodeInternal, "failed to enable tenant isolation")
+		}
+	}
+	if config.ColumnEncryption != nil {
+		if config.ColumnEncryption.Keys == nil {
+			return nil, NewError(ErrCodeInvalidParam, "column encryption requires a key provider", nil)
+		}
+		dsManager.SetKeyProvider(config.ColumnEncryption.Keys)
+	}
+	db.StartSchemaWatcher(config.SchemaPollInterval)
+	db.StartSchemaSnapshots(config.SchemaSnapshotInterval)
+	db.StartTTLPurger(config.TTLPurgeInterval)
+	if config.ChangeArchiveDir != "" {
+		interval := config.ChangeArchiveInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		db.StartChangeArchiver(interval)
+	}
+	dsManager.SetQuarantineThreshold(config.QuarantineAfter)
+	db.StartHealthProber(config.HealthCheckInterval)
+	return db, nil
 }
 
 // RegisterDataSource registers a datasource with the given name
@@ -103,6 +203,7 @@ func (db *DB) RegisterDataSource(name string, ds domain.DataSource) error {
 	}
 
 	db.logger.Debug("Registered datasource: %s", name)
+	db.recoverDataSource(name, ds)
 	return nil
 }
 
@@ -196,6 +297,13 @@ func (db *DB) SessionWithOptions(opts *SessionOptions) *Session {
 	}
 
 	coreSession := session.NewCoreSessionWithDSManagerAndEnhanced(ds, db.dsManager, true, useEnhanced)
+	// 会话的默认数据库即其数据源
+	coreSession.SetCurrentDB(dsName)
+	coreSession.SetRewriteHooks(db.rewriteHooks)
+	coreSession.SetGlobalVariableHooks(db.globalVars)
+	if db.config != nil {
+		coreSession.SetColumnDecryption(db.config.ColumnEncryption.decryptionPolicy())
+	}
 
 	// 设置查询超时 (Session级别覆盖DB级别)
 	queryTimeout := opts.QueryTimeout
@@ -209,6 +317,11 @@ func (db *DB) SessionWithOptions(opts *SessionOptions) *Session {
 		coreSession.SetDatabaseDir(db.config.DatabaseDir)
 	}
 
+	// 设置事务隔离级别；零值（READ UNCOMMITTED）视为未设置，使用默认的 REPEATABLE READ
+	if opts.Isolation == IsolationReadCommitted || opts.Isolation == IsolationSerializable {
+		_ = coreSession.SetIsolationLevel(opts.Isolation.String())
+	}
+
 	apiSession := &Session{
 		db:           db,
 		coreSession:  coreSession,
@@ -266,6 +379,13 @@ func (db *DB) GetCacheStats() CacheStats {
 
 // Close closes all datasources and releases resources
 func (db *DB) Close() error {
+	db.StopSchemaWatcher()
+	db.StopSchemaSnapshots()
+	db.StopTTLPurger()
+	db.StopChangeArchiver()
+	db.StopHealthProber()
+	db.listeners.closeAll()
+
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
@@ -278,6 +398,9 @@ func (db *DB) Close() error {
 	}
 
 	db.dataSources = make(map[string]domain.DataSource)
+	if err := db.xa.Log().Close(); err != nil {
+		lastErr = err
+	}
 	if db.cache != nil {
 		db.cache.Clear()
 	}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/session"
+)
+
+// beginDiagnostics 语句执行前清空会话的诊断区；SHOW WARNINGS / SHOW ERRORS 读取诊断区，
+// 既不清空也不记录，此时返回 false
+func (s *Session) beginDiagnostics(sql string) bool {
+	if s == nil || s.coreSession == nil || session.IsDiagnosticsStatement(sql) {
+		return false
+	}
+	s.coreSession.ResetDiagnostics()
+	return true
+}
+
+// recordDiagnostics 把语句产生的警告（如被忽略的 hint、结果集截断）和错误写入诊断区
+func (s *Session) recordDiagnostics(warnings []string, err error) {
+	for _, warning := range warnings {
+		s.coreSession.AddDiagnostic(session.DiagnosticWarning, mysqlerrors.ErrUnknown, warning)
+	}
+	if err != nil {
+		code, _ := mysqlerrors.Classify(err)
+		s.coreSession.AddDiagnostic(session.DiagnosticError, code, err.Error())
+	}
+}
+
+// Diagnostics 返回最近一条语句产生的警告和错误（SHOW WARNINGS 的内容）
+func (s *Session) Diagnostics() []session.Diagnostic {
+	if s.coreSession == nil {
+		return nil
+	}
+	return s.coreSession.Diagnostics()
+}
+
+// WarningCount 返回最近一条语句产生的诊断条数，写入 OK / EOF 包的 warnings 字段
+func (s *Session) WarningCount() int {
+	if s.coreSession == nil {
+		return 0
+	}
+	return s.coreSession.WarningCount()
+}
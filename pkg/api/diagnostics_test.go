@@ -0,0 +1,84 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diagnosticRows(t *testing.T, s *Session, sql string) []domain.Row {
+	t.Helper()
+	_, rows := queryRows(t, s, sql)
+	return rows
+}
+
+// TestDiagnostics_ShowWarnings 测试被忽略的 hint 产生警告，SHOW WARNINGS 不清空诊断区，下一条语句清空
+func TestDiagnostics_ShowWarnings(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	q, err := s.Query(`SELECT /*+ BOGUS_HINT(accounts) */ * FROM accounts`)
+	require.NoError(t, err)
+	q.Close()
+	assert.Equal(t, 1, s.WarningCount())
+
+	for i := 0; i < 2; i++ {
+		rows := diagnosticRows(t, s, `SHOW WARNINGS`)
+		require.Len(t, rows, 1)
+		assert.Equal(t, session.DiagnosticWarning, rows[0]["Level"])
+		assert.Contains(t, rows[0]["Message"], "BOGUS_HINT")
+	}
+
+	rows := diagnosticRows(t, s, `SHOW COUNT(*) WARNINGS`)
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(1), rows[0]["@@session.warning_count"])
+	assert.Empty(t, diagnosticRows(t, s, `SHOW ERRORS`))
+
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+	assert.Equal(t, 0, s.WarningCount())
+	assert.Empty(t, diagnosticRows(t, s, `SHOW WARNINGS`))
+}
+
+// TestDiagnostics_ShowErrors 测试失败的语句记录为 Error 级别，SHOW ERRORS 只返回错误
+func TestDiagnostics_ShowErrors(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Query(`SELECT * FROM missing_table`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+
+	rows := diagnosticRows(t, s, `SHOW ERRORS`)
+	require.Len(t, rows, 1)
+	assert.Equal(t, session.DiagnosticError, rows[0]["Level"])
+	assert.Equal(t, int64(code), rows[0]["Code"])
+	assert.Len(t, diagnosticRows(t, s, `SHOW WARNINGS`), 1)
+
+	rows = diagnosticRows(t, s, `SHOW COUNT(*) ERRORS`)
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(1), rows[0]["@@session.error_count"])
+}
+
+// TestDiagnostics_TruncationAndDeprecation 测试结果集截断和弃用语法产生的警告
+func TestDiagnostics_TruncationAndDeprecation(t *testing.T) {
+	s := newResultLimitTestSession(t)
+	s.SetResultLimits(ResultLimits{MaxRows: 2})
+
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM entries`))
+	rows := diagnosticRows(t, s, `SHOW WARNINGS`)
+	require.Len(t, rows, 1)
+	assert.Contains(t, rows[0]["Message"], "truncated")
+
+	_, err := s.Execute(`SET SESSION tx_isolation = 'READ-COMMITTED'`)
+	require.NoError(t, err)
+	diagnostics := s.Diagnostics()
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, mysqlerrors.ErrWarnDeprecatedSyntax, diagnostics[0].Code)
+	assert.Contains(t, diagnostics[0].Message, "'@@transaction_isolation'")
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// varSQLDialect 会话变量，SET sql_dialect = 'postgres' 切换本会话输入 SQL 的方言
+const varSQLDialect = "sql_dialect"
+
+// SetDialect 设置会话输入 SQL 的方言（通常来自连接属性），会话变量 sql_dialect 可以覆盖
+func (s *Session) SetDialect(dialect parser.Dialect) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dialect = dialect
+}
+
+// Dialect 返回会话生效的方言：会话变量优先，其次是 SetDialect 的设置；无效的会话变量被忽略
+func (s *Session) Dialect() parser.Dialect {
+	dialect, err := s.sessionDialect()
+	if err != nil {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.dialect
+	}
+	return dialect
+}
+
+// sessionDialect 返回会话生效的方言，会话变量的值无效时返回错误
+func (s *Session) sessionDialect() (parser.Dialect, error) {
+	if s.coreSession != nil {
+		if v, ok := s.coreSession.GetSessionVar(varSQLDialect); ok {
+			return parser.ParseDialect(unquoteVar(v))
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dialect, nil
+}
+
+// translateDialect 把会话方言的 SQL 改写为 MySQL 兼容的 SQL
+func (s *Session) translateDialect(sql string) (*parser.Translation, error) {
+	dialect, err := s.sessionDialect()
+	if err != nil {
+		return nil, WrapError(err, ErrCodeInvalidParam, "invalid sql_dialect")
+	}
+	translation, err := parser.TranslateDialect(dialect, sql)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeSyntax, fmt.Sprintf("failed to translate %s SQL", dialect))
+	}
+	return translation, nil
+}
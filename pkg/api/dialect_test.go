@@ -0,0 +1,71 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDialect_PostgresQuery 测试 Postgres 方言的查询被改写后执行
+func TestDialect_PostgresQuery(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	s.SetDialect(parser.DialectPostgres)
+	assert.Equal(t, parser.DialectPostgres, s.Dialect())
+
+	rows, err := s.QueryAll(`SELECT "id", "name" FROM "users" WHERE "city" = $1 ORDER BY "id" OFFSET 1 LIMIT 1`, "Paris")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Carol", rows[0]["name"])
+
+	rows, err = s.QueryAll(`SELECT id FROM users ORDER BY id FETCH FIRST 2 ROWS ONLY`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+}
+
+// TestDialect_SessionVariable 测试 SET sql_dialect 覆盖连接设置的方言
+func TestDialect_SessionVariable(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	// MySQL 方言中双引号是字符串，Postgres 方言中是标识符
+	sql := `SELECT id, name FROM users WHERE "name" = 'Alice'`
+	assert.Equal(t, 0, countRows(t, s, sql))
+
+	_, err := s.Execute(`SET sql_dialect = 'postgresql'`)
+	require.NoError(t, err)
+	assert.Equal(t, parser.DialectPostgres, s.Dialect())
+	assert.Equal(t, 1, countRows(t, s, sql))
+
+	_, err = s.Execute(`SET sql_dialect = 'oracle'`)
+	require.NoError(t, err)
+	_, err = s.Query(`SELECT 1`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeInvalidParam), "unexpected error: %v", err)
+}
+
+// TestDialect_Returning 测试 INSERT/DELETE ... RETURNING 以结果集返回受影响的行
+func TestDialect_Returning(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	s.SetDialect(parser.DialectPostgres)
+
+	rows, err := s.QueryAll(`INSERT INTO users (name, city) VALUES ('Dave', 'Rome'), ('Eve', 'Oslo') RETURNING "name" AS n, id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 4, rows[0]["id"])
+	assert.Equal(t, "Dave", rows[0]["n"])
+	assert.EqualValues(t, 5, rows[1]["id"])
+	assert.Equal(t, "Eve", rows[1]["n"])
+
+	rows, err = s.QueryAll(`DELETE FROM users WHERE city = 'Paris' RETURNING id, name`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Alice", rows[0]["name"])
+	assert.Equal(t, "Carol", rows[1]["name"])
+	assert.Equal(t, 3, countRows(t, s, `SELECT * FROM users`))
+
+	// Execute 忽略 RETURNING
+	result, err := s.Execute(`DELETE FROM users WHERE id = $1 RETURNING *`, 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.RowsAffected)
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ColumnEncryptionConfig 列加密（CREATE TABLE 中的 ENCRYPTED 列）的密钥和解密权限
+type ColumnEncryptionConfig struct {
+	// Keys 按密钥 ID 提供数据密钥，例如 security.KeyRing（环境变量或文件）或接入外部 KMS 的 security.KeyProviderFunc
+	Keys domain.KeyProvider
+	// DecryptUsers 可以读取加密列明文的用户，其他用户查询到的是密文；为空表示所有用户都可以
+	DecryptUsers []string
+}
+
+// decryptionPolicy 返回会话使用的解密权限判断，nil 表示所有用户都可以读取明文
+func (c *ColumnEncryptionConfig) decryptionPolicy() func(user string) bool {
+	if c == nil || len(c.DecryptUsers) == 0 {
+		return nil
+	}
+	users := make(map[string]bool, len(c.DecryptUsers))
+	for _, u := range c.DecryptUsers {
+		users[u] = true
+	}
+	return func(user string) bool { return users[user] }
+}
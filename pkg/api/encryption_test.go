@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestColumnEncryption_EncryptedColumns 测试 ENCRYPTED 列的透明加解密、等值查找和按用户解密
+func TestColumnEncryption_EncryptedColumns(t *testing.T) {
+	t.Setenv("SQLEXEC_TEST_COLUMN_KEY", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	db, err := NewDB(&DBConfig{
+		DefaultLogger: NewDefaultLogger(LogError),
+		ColumnEncryption: &ColumnEncryptionConfig{
+			Keys:         security.KeyRing{"default": {Env: "SQLEXEC_TEST_COLUMN_KEY"}},
+			DecryptUsers: []string{"root"},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "default", Writable: true})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+
+	root := db.Session()
+	t.Cleanup(func() { root.Close() })
+	root.SetUser("root")
+	_, err = root.Execute(`CREATE TABLE patients (id INT PRIMARY KEY, ssn VARCHAR(11) ENCRYPTED DETERMINISTIC, ` +
+		`email VARCHAR(100) ENCRYPTED WITH BLIND INDEX, diagnosis TEXT ENCRYPTED)`)
+	require.NoError(t, err)
+	_, err = root.Execute(`INSERT INTO patients VALUES (1, '111-22-3333', 'a@example.com', 'flu'), (2, '444-55-6666', 'b@example.com', 'cold')`)
+	require.NoError(t, err)
+
+	rows, err := root.QueryAll(`SELECT id, ssn, diagnosis FROM patients WHERE ssn = '444-55-6666'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "444-55-6666", rows[0]["ssn"])
+	assert.Equal(t, "cold", rows[0]["diagnosis"])
+
+	rows, err = root.QueryAll(`SELECT id FROM patients WHERE email = 'a@example.com'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 1, rows[0]["id"])
+
+	_, err = root.Execute(`UPDATE patients SET diagnosis = 'recovered' WHERE email = 'b@example.com'`)
+	require.NoError(t, err)
+
+	// 事务内读取同样解密
+	tx, err := root.Begin()
+	require.NoError(t, err)
+	txRows, err := tx.Query(`SELECT diagnosis FROM patients WHERE id = 2`)
+	require.NoError(t, err)
+	require.True(t, txRows.Next())
+	assert.Equal(t, "recovered", txRows.Row()["diagnosis"])
+	txRows.Close()
+	require.NoError(t, tx.Commit())
+
+	// 没有解密权限的用户只能看到密文
+	guest := db.Session()
+	t.Cleanup(func() { guest.Close() })
+	guest.SetUser("guest")
+	rows, err = guest.QueryAll(`SELECT ssn FROM patients WHERE id = 1`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.True(t, strings.HasPrefix(rows[0]["ssn"].(string), "enc:v1:"))
+
+	// 数据源中保存的是密文
+	_, stored, err := ds.GetLatestTableData("patients")
+	require.NoError(t, err)
+	for _, row := range stored {
+		assert.True(t, strings.HasPrefix(row["diagnosis"].(string), "enc:v1:"))
+	}
+}
+
+// TestColumnEncryption_RequiresKeys 测试未配置密钥时不能创建加密列
+func TestColumnEncryption_RequiresKeys(t *testing.T) {
+	db, err := NewDB(&DBConfig{DefaultLogger: NewDefaultLogger(LogError)})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "default", Writable: true})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+
+	s := db.Session()
+	t.Cleanup(func() { s.Close() })
+	_, err = s.Execute(`CREATE TABLE t (id INT PRIMARY KEY, v TEXT ENCRYPTED)`)
+	assert.ErrorContains(t, err, "no encryption keys")
+
+	_, err = NewDB(&DBConfig{ColumnEncryption: &ColumnEncryptionConfig{}})
+	assert.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 )
 
 // Error 错误类型（带堆栈）
@@ -30,6 +32,10 @@ const (
 	ErrCodeInvalidParam    ErrorCode = "INVALID_PARAM"
 	ErrCodeNotSupported    ErrorCode = "NOT_SUPPORTED"
 	ErrCodeClosed          ErrorCode = "CLOSED"
+	ErrCodeReadOnly        ErrorCode = "READ_ONLY"
+	ErrCodeResultTooLarge  ErrorCode = "RESULT_TOO_LARGE"
+	ErrCodeQuotaExceeded   ErrorCode = "QUOTA_EXCEEDED"
+	ErrCodeRetryable       ErrorCode = "RETRYABLE" // 后端数据源连接中断，幂等的读可以重试
 	ErrCodeInternal        ErrorCode = "INTERNAL"
 )
 
@@ -158,3 +164,34 @@ func GetErrorMessage(err error) string {
 
 	return err.Error()
 }
+
+// MySQLErrorCode 实现 mysqlerrors.Coder，返回对应的 MySQL 错误码
+// 包装了原始错误时返回 0，由原始错误决定错误码（查询失败统一包装为 SYNTAX_ERROR，不能据此判定）
+func (e *Error) MySQLErrorCode() uint16 {
+	if e.Cause != nil {
+		return 0
+	}
+	switch e.Code {
+	case ErrCodeTableNotFound:
+		return mysqlerrors.ErrNoSuchTable
+	case ErrCodeColumnNotFound:
+		return mysqlerrors.ErrBadFieldError
+	case ErrCodeSyntax:
+		return mysqlerrors.ErrParseError
+	case ErrCodeTimeout:
+		return mysqlerrors.ErrQueryTimeout
+	case ErrCodeQueryKilled:
+		return mysqlerrors.ErrQueryInterrupted
+	case ErrCodeNotSupported:
+		return mysqlerrors.ErrNotSupportedYet
+	case ErrCodeReadOnly:
+		return mysqlerrors.ErrReadOnly
+	case ErrCodeResultTooLarge:
+		return mysqlerrors.ErrTooBigSelect
+	case ErrCodeQuotaExceeded:
+		return mysqlerrors.ErrRecordFileFull
+	case ErrCodeRetryable:
+		return mysqlerrors.ErrNetReadError
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// FailoverPolicy configures statement-level retry for a database whose
+// datasource proxies a remote server (MySQL, PostgreSQL, plugins). When a
+// SELECT outside a transaction fails because the connection to the backing
+// server dropped, it is retried with jittered exponential backoff, against
+// Backup when one is set.
+type FailoverPolicy struct {
+	// Backup names a replica datasource that serves the retries; empty retries the primary
+	Backup string
+	// MaxRetries is the number of retries per statement (default 2)
+	MaxRetries int
+	// BaseBackoff is the wait before the first retry, doubled for every further retry (default 50ms)
+	BaseBackoff time.Duration
+	// MaxBackoff caps the wait between retries (default 2s)
+	MaxBackoff time.Duration
+	// RetryBudget caps the retries per minute for the database across all sessions; 0 means unlimited
+	RetryBudget int
+}
+
+const (
+	defaultFailoverRetries    = 2
+	defaultFailoverBackoff    = 50 * time.Millisecond
+	defaultFailoverMaxBackoff = 2 * time.Second
+	retryBudgetWindow         = time.Minute
+)
+
+// failoverState is the policy of one database and the retries spent in the
+// current budget window
+type failoverState struct {
+	policy FailoverPolicy
+
+	mu          sync.Mutex
+	windowStart time.Time
+	used        int
+}
+
+// allowRetry spends one retry from the budget and reports whether it was available
+func (st *failoverState) allowRetry(now time.Time) bool {
+	if st.policy.RetryBudget == 0 {
+		return true
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if now.Sub(st.windowStart) >= retryBudgetWindow {
+		st.windowStart = now
+		st.used = 0
+	}
+	if st.used >= st.policy.RetryBudget {
+		return false
+	}
+	st.used++
+	return true
+}
+
+// backoff returns the wait before the given retry (1-based): the base
+// backoff doubled per retry and capped, of which the upper half is random
+func (st *failoverState) backoff(attempt int) time.Duration {
+	d := st.policy.BaseBackoff
+	for i := 1; i < attempt && d < st.policy.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > st.policy.MaxBackoff {
+		d = st.policy.MaxBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// SetFailoverPolicy sets how SELECTs on dbName are retried when the
+// connection to its backing server drops. A nil policy turns retries off, so
+// such errors are returned to the caller with ErrCodeRetryable.
+func (db *DB) SetFailoverPolicy(dbName string, policy *FailoverPolicy) error {
+	if dbName == "" {
+		return NewError(ErrCodeInvalidParam, "database name cannot be empty", nil)
+	}
+	if policy == nil {
+		db.failoverMu.Lock()
+		delete(db.failover, dbName)
+		db.failoverMu.Unlock()
+		return nil
+	}
+	if policy.MaxRetries < 0 || policy.BaseBackoff < 0 || policy.MaxBackoff < 0 || policy.RetryBudget < 0 {
+		return NewError(ErrCodeInvalidParam, "failover retries, backoff and retry budget must not be negative", nil)
+	}
+	if policy.Backup == dbName {
+		return NewError(ErrCodeInvalidParam, "backup datasource must differ from the database it backs", nil)
+	}
+	if policy.Backup != "" {
+		if _, err := db.dsManager.Get(policy.Backup); err != nil {
+			return NewError(ErrCodeDSNotFound, "backup datasource '"+policy.Backup+"' not found", err)
+		}
+	}
+
+	p := *policy
+	if p.MaxRetries == 0 {
+		p.MaxRetries = defaultFailoverRetries
+	}
+	if p.BaseBackoff == 0 {
+		p.BaseBackoff = defaultFailoverBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = defaultFailoverMaxBackoff
+	}
+	if p.MaxBackoff < p.BaseBackoff {
+		p.MaxBackoff = p.BaseBackoff
+	}
+
+	db.failoverMu.Lock()
+	defer db.failoverMu.Unlock()
+	if db.failover == nil {
+		db.failover = make(map[string]*failoverState)
+	}
+	db.failover[dbName] = &failoverState{policy: p}
+	return nil
+}
+
+// FailoverPolicy returns the failover policy of dbName with defaults applied
+func (db *DB) FailoverPolicy(dbName string) (FailoverPolicy, bool) {
+	if st := db.failoverState(dbName); st != nil {
+		return st.policy, true
+	}
+	return FailoverPolicy{}, false
+}
+
+func (db *DB) failoverState(dbName string) *failoverState {
+	db.failoverMu.RLock()
+	defer db.failoverMu.RUnlock()
+	return db.failover[dbName]
+}
+
+// retrySelect retries a SELECT that failed with a lost connection according
+// to the failover policy of the database it reads from. Statements that are
+// not plain SELECTs, or that run inside a transaction, are never retried.
+func (s *Session) retrySelect(ctx context.Context, boundSQL string, err error) (*domain.QueryResult, error) {
+	dbName, ok := s.retryableSelectDB(boundSQL)
+	if !ok {
+		return nil, err
+	}
+	st := s.db.failoverState(dbName)
+	if st == nil {
+		return nil, err
+	}
+	if st.policy.Backup != "" {
+		ctx = optimizer.WithDataSourceFailover(ctx, dbName, st.policy.Backup)
+	}
+
+	for attempt := 1; attempt <= st.policy.MaxRetries && domain.IsRetryable(err); attempt++ {
+		if !st.allowRetry(time.Now()) {
+			s.logger.Debug("Retry budget of database %s exhausted", dbName)
+			break
+		}
+		timer := time.NewTimer(st.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+
+		var result *domain.QueryResult
+		result, err = s.coreSession.ExecuteQuery(ctx, boundSQL)
+		if err == nil {
+			warning := fmt.Sprintf("Statement retried %d time(s) after losing the connection to database '%s'", attempt, dbName)
+			if st.policy.Backup != "" {
+				warning += fmt.Sprintf(", served by '%s'", st.policy.Backup)
+			}
+			result.Warnings = append(result.Warnings, warning)
+			return result, nil
+		}
+	}
+	return nil, err
+}
+
+// retryableSelectDB returns the database a plain SELECT reads from, or false
+// when the statement must not be retried (locking reads, statements inside a
+// transaction, statements without a table).
+func (s *Session) retryableSelectDB(boundSQL string) (string, bool) {
+	if s.coreSession.InTx() {
+		return "", false
+	}
+	parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL)
+	if err != nil || !parseResult.Success {
+		return "", false
+	}
+	stmt := parseResult.Statement
+	if stmt.Type != parser.SQLTypeSelect || stmt.Select == nil || stmt.Select.Lock != "" || stmt.Select.From == "" {
+		return "", false
+	}
+	if i := strings.Index(stmt.Select.From, "."); i > 0 {
+		return stmt.Select.From[:i], true
+	}
+	return s.GetCurrentDB(), true
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyDataSource 模拟连接中断：前 failures 次读取返回可重试错误
+type flakyDataSource struct {
+	*memory.MVCCDataSource
+	failures atomic.Int32
+	reads    atomic.Int32
+}
+
+func (f *flakyDataSource) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	f.reads.Add(1)
+	if f.failures.Add(-1) >= 0 {
+		return nil, domain.NewErrRetryable("mysql", errors.New("driver: bad connection"))
+	}
+	return f.MVCCDataSource.Query(ctx, tableName, options)
+}
+
+// newFailoverTestDB 创建主数据源 primary（可注入连接中断）和备用数据源 replica，两者都有 orders 表
+func newFailoverTestDB(t *testing.T) (*DB, *flakyDataSource) {
+	ctx := context.Background()
+	db, err := NewDB(&DBConfig{DefaultLogger: NewDefaultLogger(LogError)})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	primary := &flakyDataSource{MVCCDataSource: memory.NewMVCCDataSource(nil)}
+	replica := memory.NewMVCCDataSource(nil)
+	for name, ds := range map[string]domain.DataSource{"primary": primary, "replica": replica} {
+		require.NoError(t, ds.Connect(ctx))
+		require.NoError(t, db.RegisterDataSource(name, ds))
+		require.NoError(t, ds.CreateTable(ctx, &domain.TableInfo{
+			Name:    "orders",
+			Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}, {Name: "source", Type: "VARCHAR"}},
+		}))
+		_, err := ds.Insert(ctx, "orders", []domain.Row{{"id": int64(1), "source": name}}, nil)
+		require.NoError(t, err)
+	}
+	return db, primary
+}
+
+// TestFailover_RetryPrimary 测试连接中断后在主数据源上重试 SELECT
+func TestFailover_RetryPrimary(t *testing.T) {
+	db, primary := newFailoverTestDB(t)
+	s := db.Session()
+	defer s.Close()
+	require.NoError(t, s.UseDatabase("primary"))
+
+	// 未配置策略时返回可重试错误
+	primary.failures.Store(1)
+	_, err := s.Query(`SELECT * FROM orders`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeRetryable))
+	assert.True(t, domain.IsRetryable(err))
+
+	require.NoError(t, db.SetFailoverPolicy("primary", &FailoverPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond}))
+	primary.failures.Store(2)
+	primary.reads.Store(0)
+	q, err := s.Query(`SELECT * FROM orders`)
+	require.NoError(t, err)
+	defer q.Close()
+	require.True(t, q.Next())
+	assert.Equal(t, "primary", q.Row()["source"])
+	assert.EqualValues(t, 3, primary.reads.Load())
+	require.NotEmpty(t, q.Warnings())
+	assert.Contains(t, q.Warnings()[len(q.Warnings())-1], "retried 2 time(s)")
+
+	// 重试次数用尽后返回最后一次的错误
+	primary.failures.Store(3)
+	_, err = s.Query(`SELECT * FROM orders`)
+	assert.True(t, IsErrorCode(err, ErrCodeRetryable))
+}
+
+// TestFailover_Backup 测试在备用数据源上重试，以及事务内和锁定读不重试
+func TestFailover_Backup(t *testing.T) {
+	db, primary := newFailoverTestDB(t)
+	require.NoError(t, db.SetFailoverPolicy("primary", &FailoverPolicy{Backup: "replica", BaseBackoff: time.Millisecond}))
+	s := db.Session()
+	defer s.Close()
+	require.NoError(t, s.UseDatabase("replica"))
+
+	primary.failures.Store(1)
+	rows, err := s.QueryAll(`SELECT * FROM primary.orders`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "replica", rows[0]["source"])
+
+	// 事务内的 SELECT 不重试
+	require.NoError(t, s.UseDatabase("primary"))
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	primary.failures.Store(1)
+	_, err = s.Query(`SELECT * FROM orders`)
+	assert.True(t, IsErrorCode(err, ErrCodeRetryable))
+	require.NoError(t, tx.Rollback())
+
+	// 锁定读不重试
+	primary.failures.Store(1)
+	_, err = s.Query(`SELECT * FROM orders FOR UPDATE`)
+	assert.Error(t, err)
+	primary.failures.Store(0)
+}
+
+// TestFailover_RetryBudget 测试重试预算用尽后不再重试
+func TestFailover_RetryBudget(t *testing.T) {
+	db, primary := newFailoverTestDB(t)
+	require.NoError(t, db.SetFailoverPolicy("primary", &FailoverPolicy{MaxRetries: 5, BaseBackoff: time.Millisecond, RetryBudget: 2}))
+	s := db.Session()
+	defer s.Close()
+	require.NoError(t, s.UseDatabase("primary"))
+
+	primary.failures.Store(10)
+	primary.reads.Store(0)
+	_, err := s.Query(`SELECT * FROM orders`)
+	assert.True(t, IsErrorCode(err, ErrCodeRetryable))
+	assert.EqualValues(t, 3, primary.reads.Load())
+}
+
+// TestSetFailoverPolicy 测试故障切换策略的校验和默认值
+func TestSetFailoverPolicy(t *testing.T) {
+	db, _ := newFailoverTestDB(t)
+
+	assert.True(t, IsErrorCode(db.SetFailoverPolicy("", &FailoverPolicy{}), ErrCodeInvalidParam))
+	assert.True(t, IsErrorCode(db.SetFailoverPolicy("primary", &FailoverPolicy{MaxRetries: -1}), ErrCodeInvalidParam))
+	assert.True(t, IsErrorCode(db.SetFailoverPolicy("primary", &FailoverPolicy{Backup: "primary"}), ErrCodeInvalidParam))
+	assert.True(t, IsErrorCode(db.SetFailoverPolicy("primary", &FailoverPolicy{Backup: "missing"}), ErrCodeDSNotFound))
+
+	require.NoError(t, db.SetFailoverPolicy("primary", &FailoverPolicy{}))
+	policy, ok := db.FailoverPolicy("primary")
+	require.True(t, ok)
+	assert.Equal(t, defaultFailoverRetries, policy.MaxRetries)
+	assert.Equal(t, defaultFailoverBackoff, policy.BaseBackoff)
+	assert.Equal(t, defaultFailoverMaxBackoff, policy.MaxBackoff)
+
+	st := db.failoverState("primary")
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := st.backoff(attempt)
+		assert.LessOrEqual(t, d, defaultFailoverMaxBackoff)
+		assert.GreaterOrEqual(t, d, defaultFailoverBackoff/2)
+	}
+
+	require.NoError(t, db.SetFailoverPolicy("primary", nil))
+	_, ok = db.FailoverPolicy("primary")
+	assert.False(t, ok)
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// maxForkChanges 一个分叉最多记录的行变更数，超出后只能丢弃，不能合并
+const maxForkChanges = 100000
+
+// ==================== 会话分叉 ====================
+// 分叉是一个数据库中所选表的快照副本，保存在私有的内存数据源中。绑定分叉的会话里，
+// 未限定库名的分叉表的读写都由副本提供，真实数据不受影响；其余表照常读取，但不能写入。
+// 结束时 Merge 把副本上的变更按顺序重放到原表（原表的行在分叉后被修改过时报冲突），
+// 或 Discard 丢弃副本
+
+// Fork 会话分叉：所选表的快照副本
+type Fork struct {
+	db        *DB
+	database  string
+	source    domain.DataSource
+	sandbox   *memory.MVCCDataSource
+	tables    []string
+	overrides map[string]domain.DataSource // 表名 -> 副本
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// ForkMergeResult 合并分叉的结果
+type ForkMergeResult struct {
+	Changes map[string]int64 // 表名 -> 重放的行变更数
+}
+
+// Fork 为数据库 database（为空时使用默认数据源）的表 tables 创建分叉。
+// 数据源支持事务时在一个只读事务中复制所有表，得到一致的快照。分区表不能分叉
+func (db *DB) Fork(database string, tables []string) (*Fork, error) {
+	if len(tables) == 0 {
+		return nil, NewError(ErrCodeInvalidParam, "no tables to fork", nil)
+	}
+	if database == "" {
+		database = db.dsManager.GetDefaultName()
+	}
+	source, err := db.dsManager.Get(database)
+	if err != nil {
+		return nil, NewError(ErrCodeDSNotFound, fmt.Sprintf("database '%s' not found", database), err)
+	}
+
+	ctx := context.Background()
+	sandbox := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     database,
+		Writable: true,
+	})
+	if err := sandbox.Connect(ctx); err != nil {
+		return nil, WrapError(err, ErrCodeInternal, "failed to create fork")
+	}
+	if db.config != nil && db.config.ColumnEncryption != nil {
+		sandbox.SetKeyProvider(db.config.ColumnEncryption.Keys)
+	}
+	f := &Fork{
+		db:        db,
+		database:  database,
+		source:    source,
+		sandbox:   sandbox,
+		overrides: make(map[string]domain.DataSource, len(tables)),
+	}
+
+	var txn domain.Transaction
+	if tds, ok := source.(domain.TransactionalDataSource); ok {
+		if txn, err = tds.BeginTransaction(ctx, &domain.TransactionOptions{ReadOnly: true}); err == nil {
+			defer txn.Rollback(ctx)
+		}
+	}
+	for _, table := range tables {
+		if _, ok := f.overrides[table]; ok {
+			continue
+		}
+		if err := f.copyTable(ctx, txn, table); err != nil {
+			sandbox.Close(ctx)
+			return nil, err
+		}
+		f.tables = append(f.tables, table)
+		f.overrides[table] = sandbox
+	}
+	// 复制完成后才开始记录变更，变更日志中只有分叉内的写入
+	sandbox.SetChangeLogSize(maxForkChanges)
+	return f, nil
+}
+
+// copyTable 把表的结构和所有行复制到副本。外键和 TTL 不复制：分叉内不检查外键，也不清理过期行
+func (f *Fork) copyTable(ctx context.Context, txn domain.Transaction, table string) error {
+	info, err := f.source.GetTableInfo(ctx, table)
+	if err != nil {
+		return NewError(ErrCodeTableNotFound, fmt.Sprintf("table '%s.%s' not found", f.database, table), err)
+	}
+	if info.IsPartitioned() {
+		return NewError(ErrCodeNotSupported, fmt.Sprintf("cannot fork partitioned table '%s.%s'", f.database, table), nil)
+	}
+	schema := *info
+	schema.Name = table
+	schema.TTL = nil
+	schema.Columns = append([]domain.ColumnInfo(nil), info.Columns...)
+	for i := range schema.Columns {
+		schema.Columns[i].ForeignKey = nil
+	}
+
+	var result *domain.QueryResult
+	if txn != nil {
+		result, err = txn.Query(ctx, table, &domain.QueryOptions{SelectAll: true})
+	} else {
+		result, err = f.source.Query(ctx, table, &domain.QueryOptions{SelectAll: true})
+	}
+	if err != nil {
+		return WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to read table '%s.%s'", f.database, table))
+	}
+	if err := f.sandbox.CreateTable(ctx, &schema); err != nil {
+		return WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to fork table '%s.%s'", f.database, table))
+	}
+	changes := make([]domain.ChangeEvent, len(result.Rows))
+	for i, row := range result.Rows {
+		changes[i] = domain.ChangeEvent{Table: table, Type: domain.ChangeInsert, After: row}
+	}
+	if _, err := f.sandbox.ApplyChanges(ctx, table, changes); err != nil {
+		return WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to fork table '%s.%s'", f.database, table))
+	}
+	return nil
+}
+
+// Database 返回分叉的数据库
+func (f *Fork) Database() string {
+	return f.database
+}
+
+// Tables 返回分叉的表
+func (f *Fork) Tables() []string {
+	return append([]string(nil), f.tables...)
+}
+
+// Closed 返回分叉是否已经合并或丢弃
+func (f *Fork) Closed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// Changes 返回分叉内尚未合并的行变更，按发生顺序排列
+func (f *Fork) Changes() ([]domain.ChangeEvent, error) {
+	changes, _, err := f.sandbox.ChangesSince(context.Background(), 0)
+	if err != nil {
+		return nil, NewError(ErrCodeNotSupported,
+			fmt.Sprintf("fork has more than %d changes and can only be discarded", maxForkChanges), err)
+	}
+	return changes, nil
+}
+
+// Merge 把分叉内的变更按顺序重放到原表，然后关闭分叉。原数据库需要支持重放变更（内存数据源），
+// 且允许写入。每张表的变更在一个新版本中生效；原表中找不到变更前的行（分叉后被其他会话修改）时
+// 该表的合并失败并返回冲突错误，分叉保持打开，之前已合并的表不回退
+func (f *Fork) Merge() (*ForkMergeResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, NewError(ErrCodeClosed, "fork has already been merged or discarded", nil)
+	}
+	applier, ok := f.source.(domain.ChangeApplier)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, fmt.Sprintf("database '%s' does not support merging forks", f.database), nil)
+	}
+	if f.db.IsReadOnly() || !f.db.WritePolicy(f.database).AllowsDML() {
+		return nil, NewError(ErrCodeReadOnly, fmt.Sprintf("Database '%s' is read-only so the fork cannot be merged", f.database), nil)
+	}
+	changes, err := f.Changes()
+	if err != nil {
+		return nil, err
+	}
+
+	byTable := make(map[string][]domain.ChangeEvent)
+	for _, change := range changes {
+		byTable[change.Table] = append(byTable[change.Table], change)
+	}
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	ctx := context.Background()
+	result := &ForkMergeResult{Changes: make(map[string]int64, len(tables))}
+	for _, table := range tables {
+		n, err := applier.ApplyChanges(ctx, table, byTable[table])
+		if err != nil {
+			return result, WrapError(err, ErrCodeConstraint, fmt.Sprintf("failed to merge fork into '%s.%s'", f.database, table))
+		}
+		result.Changes[table] = n
+		f.db.cache.ClearTable(table)
+	}
+	f.closeLocked()
+	return result, nil
+}
+
+// Discard 丢弃分叉，原表不受影响
+func (f *Fork) Discard() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closeLocked()
+}
+
+func (f *Fork) closeLocked() {
+	if !f.closed {
+		f.closed = true
+		f.sandbox.Close(context.Background())
+	}
+}
+
+// SetFork 把会话绑定到分叉（nil 时解除），绑定时当前数据库切换为分叉的数据库
+func (s *Session) SetFork(f *Fork) {
+	s.mu.Lock()
+	s.fork = f
+	s.mu.Unlock()
+	if f != nil {
+		s.SetCurrentDB(f.database)
+	}
+}
+
+// Fork 返回会话绑定的分叉，未绑定时返回 nil
+func (s *Session) Fork() *Fork {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fork
+}
+
+// checkFork 绑定的分叉已经合并或丢弃时返回错误
+func (s *Session) checkFork() error {
+	if f := s.Fork(); f != nil && f.Closed() {
+		return NewError(ErrCodeClosed, "fork has already been merged or discarded", nil)
+	}
+	return nil
+}
+
+// checkWrite 分叉会话中只允许对未限定库名的分叉表执行 INSERT/UPDATE/DELETE，
+// 这些写入只修改副本；其他写入语句都被拒绝
+func (f *Fork) checkWrite(stmt *parser.SQLStatement) error {
+	var database, table string
+	switch stmt.Type {
+	case parser.SQLTypeInsert:
+		if stmt.Insert != nil {
+			database, table = stmt.Insert.Database, stmt.Insert.Table
+		}
+	case parser.SQLTypeUpdate:
+		if stmt.Update != nil {
+			database, table = stmt.Update.Database, stmt.Update.Table
+		}
+	case parser.SQLTypeDelete:
+		if stmt.Delete != nil {
+			database, table = stmt.Delete.Database, stmt.Delete.Table
+		}
+	default:
+		return NewError(ErrCodeReadOnly, "Only INSERT, UPDATE and DELETE on forked tables are allowed in a forked session", nil)
+	}
+	if _, ok := f.overrides[table]; ok && database == "" {
+		return nil
+	}
+	return NewError(ErrCodeReadOnly, fmt.Sprintf("Table '%s' is not forked so it cannot be modified in a forked session (forked tables: %s)",
+		table, strings.Join(f.tables, ", ")), nil)
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryInt(t *testing.T, s *Session, sql string) int64 {
+	t.Helper()
+	row, err := s.QueryOne(sql)
+	require.NoError(t, err)
+	require.Len(t, row, 1)
+	for _, v := range row {
+		n, err := strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		require.NoError(t, err)
+		return n
+	}
+	return 0
+}
+
+// TestFork_Isolation 测试分叉会话的写入只修改副本，其他会话和原表不受影响
+func TestFork_Isolation(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s := db.Session()
+	defer s.Close()
+	_, err := s.Execute(`CREATE TABLE audit (id INT PRIMARY KEY)`)
+	require.NoError(t, err)
+
+	fork, err := db.Fork("test", []string{"accounts"})
+	require.NoError(t, err)
+	defer fork.Discard()
+	assert.Equal(t, "test", fork.Database())
+	assert.Equal(t, []string{"accounts"}, fork.Tables())
+
+	fs := db.Session()
+	defer fs.Close()
+	fs.SetFork(fork)
+
+	_, err = fs.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = fs.Execute(`INSERT INTO accounts VALUES (3, 50)`)
+	require.NoError(t, err)
+	_, err = fs.Execute(`DELETE FROM accounts WHERE id = 2`)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 50, queryInt(t, fs, `SELECT SUM(balance) AS total FROM accounts`))
+	assert.EqualValues(t, 200, queryInt(t, s, `SELECT SUM(balance) AS total FROM accounts`))
+
+	// 原表在分叉后的修改不影响副本
+	_, err = s.Execute(`INSERT INTO accounts VALUES (10, 1)`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, fs, `SELECT * FROM accounts`))
+
+	// 未分叉的表可以读取，不能写入；DDL 被拒绝
+	assert.Equal(t, 0, countRows(t, fs, `SELECT * FROM audit`))
+	for _, sql := range []string{
+		`INSERT INTO audit VALUES (1)`,
+		`INSERT INTO test.accounts VALUES (4, 1)`,
+		`CREATE TABLE t2 (id INT PRIMARY KEY)`,
+		`TRUNCATE TABLE accounts`,
+	} {
+		_, err = fs.Execute(sql)
+		assertReadOnlyError(t, err)
+	}
+	_, err = fs.Query(`DROP TABLE accounts`)
+	assertReadOnlyError(t, err)
+}
+
+// TestFork_Merge 测试合并把分叉内的变更重放到原表
+func TestFork_Merge(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	fork, err := db.Fork("", []string{"accounts"})
+	require.NoError(t, err)
+
+	fs := db.Session()
+	defer fs.Close()
+	fs.SetFork(fork)
+	_, err = fs.Execute(`UPDATE accounts SET balance = 70 WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = fs.Execute(`UPDATE accounts SET balance = 130 WHERE id = 2`)
+	require.NoError(t, err)
+	_, err = fs.Execute(`INSERT INTO accounts VALUES (3, 0)`)
+	require.NoError(t, err)
+
+	changes, err := fork.Changes()
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	result, err := fork.Merge()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"accounts": 3}, result.Changes)
+	assert.True(t, fork.Closed())
+
+	s := db.Session()
+	defer s.Close()
+	assert.EqualValues(t, 70, queryInt(t, s, `SELECT balance FROM accounts WHERE id = 1`))
+	assert.EqualValues(t, 130, queryInt(t, s, `SELECT balance FROM accounts WHERE id = 2`))
+	assert.Equal(t, 3, countRows(t, s, `SELECT * FROM accounts`))
+
+	// 合并后分叉会话不能继续使用
+	_, err = fs.Query(`SELECT * FROM accounts`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeClosed))
+	_, err = fork.Merge()
+	assert.True(t, IsErrorCode(err, ErrCodeClosed))
+}
+
+// TestFork_MergeConflict 测试原表的行在分叉后被修改时合并报冲突，分叉保持打开
+func TestFork_MergeConflict(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	fork, err := db.Fork("test", []string{"accounts"})
+	require.NoError(t, err)
+
+	fs := db.Session()
+	defer fs.Close()
+	fs.SetFork(fork)
+	_, err = fs.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	require.NoError(t, err)
+
+	s := db.Session()
+	defer s.Close()
+	_, err = s.Execute(`UPDATE accounts SET balance = 99 WHERE id = 1`)
+	require.NoError(t, err)
+
+	_, err = fork.Merge()
+	require.Error(t, err)
+	assert.False(t, fork.Closed())
+	assert.EqualValues(t, 99, queryInt(t, s, `SELECT balance FROM accounts WHERE id = 1`))
+
+	fork.Discard()
+	assert.True(t, fork.Closed())
+	assert.EqualValues(t, 99, queryInt(t, s, `SELECT balance FROM accounts WHERE id = 1`))
+}
+
+// TestFork_Errors 测试分叉不存在的表、空的表列表以及只读数据库的合并
+func TestFork_Errors(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+
+	_, err := db.Fork("test", nil)
+	assert.True(t, IsErrorCode(err, ErrCodeInvalidParam))
+	_, err = db.Fork("test", []string{"missing"})
+	assert.True(t, IsErrorCode(err, ErrCodeTableNotFound))
+	_, err = db.Fork("nope", []string{"accounts"})
+	assert.True(t, IsErrorCode(err, ErrCodeDSNotFound))
+
+	fork, err := db.Fork("test", []string{"accounts"})
+	require.NoError(t, err)
+	defer fork.Discard()
+	db.SetReadOnly(true)
+	_, err = fork.Merge()
+	assertReadOnlyError(t, err)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFunctionalIndex_QueryAndMaintenance 测试函数索引的创建、写入维护与查询
+func TestFunctionalIndex_QueryAndMaintenance(t *testing.T) {
+	db, err := NewDB(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "default",
+		Writable: true,
+	})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+	s := db.Session()
+	t.Cleanup(func() { s.Close() })
+
+	_, err = s.Execute(`CREATE TABLE accounts (id INT PRIMARY KEY, email VARCHAR(100))`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO accounts (id, email) VALUES (1, 'Alice@Example.com'), (2, 'bob@example.com')`)
+	require.NoError(t, err)
+
+	_, err = s.Execute(`CREATE UNIQUE INDEX idx_email_lower ON accounts ((LOWER(email)))`)
+	require.NoError(t, err)
+
+	indexes := ds.FunctionalIndexes(context.Background(), "accounts")
+	require.Len(t, indexes, 1)
+	assert.Equal(t, "idx_email_lower", indexes[0].Name)
+	assert.Equal(t, []string{"email"}, indexes[0].Depends)
+
+	// 已有行被回填，条件中的相同表达式命中索引
+	rows, err := s.QueryAll(`SELECT id FROM accounts WHERE LOWER(email) = 'alice@example.com'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 1, rows[0]["id"])
+
+	// 写入后索引键随之更新
+	_, err = s.Execute(`INSERT INTO accounts (id, email) VALUES (3, 'CAROL@example.com')`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE accounts SET email = 'Robert@Example.com' WHERE id = 2`)
+	require.NoError(t, err)
+
+	rows, err = s.QueryAll(`SELECT id FROM accounts WHERE LOWER(email) = 'carol@example.com'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 3, rows[0]["id"])
+	rows, err = s.QueryAll(`SELECT id FROM accounts WHERE LOWER(email) = 'bob@example.com'`)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+	rows, err = s.QueryAll(`SELECT id FROM accounts WHERE LOWER(email) = 'robert@example.com' AND id = 2`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	// 唯一函数索引按表达式的值检查重复
+	_, err = s.Execute(`INSERT INTO accounts (id, email) VALUES (4, 'ALICE@example.COM')`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "idx_email_lower")
+
+	// 保存索引键的隐藏列不出现在查询结果和表结构中
+	rows, err = s.QueryAll(`SELECT * FROM accounts WHERE id = 1`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Len(t, rows[0], 2)
+	info, err := ds.GetTableInfo(context.Background(), "accounts")
+	require.NoError(t, err)
+	assert.Len(t, info.Columns, 2)
+
+	_, err = s.Execute(`DROP INDEX idx_email_lower ON accounts`)
+	require.NoError(t, err)
+	assert.Empty(t, ds.FunctionalIndexes(context.Background(), "accounts"))
+	_, err = s.Execute(`INSERT INTO accounts (id, email) VALUES (4, 'ALICE@example.COM')`)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateTable 测试 CREATE TABLE ... AS GENERATE 创建表并写入生成的数据
+func TestGenerateTable(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	sql := `CREATE TABLE t AS GENERATE(rows=25000, seed=42, columns=(id SEQUENCE PRIMARY KEY, name FAKER('name'), amount RANDOM(0,1000)))`
+	res, err := s.Execute(sql)
+	require.NoError(t, err)
+	assert.EqualValues(t, 25000, res.RowsAffected)
+
+	rows, err := s.QueryAll(`SELECT COUNT(*) AS n, MIN(id) AS lo, MAX(id) AS hi FROM t WHERE amount >= 0 AND amount <= 1000`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 25000, rows[0]["n"])
+	assert.EqualValues(t, 1, rows[0]["lo"])
+	assert.EqualValues(t, 25000, rows[0]["hi"])
+
+	first, err := s.QueryAll(`SELECT name, amount FROM t WHERE id = 7`)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	assert.NotEmpty(t, first[0]["name"])
+
+	// 相同种子生成相同的数据
+	_, err = s.Execute(`CREATE TABLE t2 AS GENERATE(rows=25000, seed=42, columns=(id SEQUENCE PRIMARY KEY, name FAKER('name'), amount RANDOM(0,1000)))`)
+	require.NoError(t, err)
+	second, err := s.QueryAll(`SELECT name, amount FROM t2 WHERE id = 7`)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	_, err = s.Execute(sql)
+	assert.Error(t, err, "table already exists")
+	_, err = s.Execute(`CREATE TABLE t3 AS GENERATE(rows=10, columns=(x FAKER('planet')))`)
+	assert.Error(t, err)
+	_, err = s.Query(`SELECT * FROM t3`)
+	assert.Error(t, err, "failed statement must not leave a table behind")
+}
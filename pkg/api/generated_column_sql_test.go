@@ -378,3 +378,79 @@ func TestGeneratedColumns_SQLIntegration(t *testing.T) {
 		assert.Equal(t, int64(60), rows[1]["doubled"])
 	})
 }
+
+// TestGeneratedColumns_Evaluation 测试生成列的表达式求值、虚拟列过滤和存储列索引
+func TestGeneratedColumns_Evaluation(t *testing.T) {
+	ds := memory.NewMVCCDataSource(nil)
+	assert.NoError(t, ds.Connect(context.Background()))
+	defer ds.Close(context.Background())
+
+	db, _ := NewDB(nil)
+	_ = db.RegisterDataSource("test", ds)
+	_ = db.SetDefaultDataSource("test")
+
+	session := db.Session()
+	defer session.Close()
+
+	// v 依赖 s，tag 使用字符串函数；生成列声明顺序与依赖顺序无关
+	_, err := session.Execute(`
+		CREATE TABLE g (
+			id INT PRIMARY KEY,
+			a INT,
+			b INT,
+			v INT GENERATED ALWAYS AS (s * 2) VIRTUAL,
+			s INT GENERATED ALWAYS AS (a + b) STORED,
+			tag VARCHAR(20) GENERATED ALWAYS AS (CONCAT('x-', UPPER(name))) VIRTUAL,
+			name VARCHAR(20)
+		)
+	`)
+	assert.NoError(t, err)
+
+	_, err = session.Execute(`INSERT INTO g (id, a, b, name) VALUES (1, 1, 2, 'ab'), (2, 3, 4, 'cd')`)
+	assert.NoError(t, err)
+
+	// 在已有数据上创建索引，应回填已有行
+	_, err = session.Execute(`CREATE INDEX idx_s ON g (s)`)
+	assert.NoError(t, err)
+
+	row, err := session.QueryOne(`SELECT s, v, tag FROM g WHERE id = 1`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), row["s"])
+	assert.Equal(t, int64(6), row["v"])
+	assert.Equal(t, "x-AB", row["tag"])
+
+	t.Run("filter and order by virtual column", func(t *testing.T) {
+		row, err := session.QueryOne(`SELECT id FROM g WHERE v = 14`)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), row["id"])
+
+		query, err := session.Query(`SELECT id, v FROM g ORDER BY v DESC`)
+		assert.NoError(t, err)
+		defer query.Close()
+		var ids []interface{}
+		for query.Next() {
+			ids = append(ids, query.Row()["id"])
+		}
+		assert.Equal(t, []interface{}{int64(2), int64(1)}, ids)
+	})
+
+	t.Run("update recomputes stored column and index", func(t *testing.T) {
+		_, err := session.Execute(`UPDATE g SET a = 10 WHERE id = 1`)
+		assert.NoError(t, err)
+
+		row, err := session.QueryOne(`SELECT id, s, v FROM g WHERE s = 12`)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), row["id"])
+		assert.Equal(t, int64(24), row["v"])
+
+		query, err := session.Query(`SELECT id FROM g WHERE s = 3`)
+		assert.NoError(t, err)
+		defer query.Close()
+		assert.False(t, query.Next())
+	})
+
+	t.Run("index on virtual column is rejected", func(t *testing.T) {
+		_, err := session.Execute(`CREATE INDEX idx_v ON g (v)`)
+		assert.Error(t, err)
+	})
+}
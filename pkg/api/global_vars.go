@@ -0,0 +1,13 @@
+package api
+
+import "github.com/kasuganosora/sqlexec/pkg/session"
+
+// RegisterGlobalVariableHook registers a callback that runs when any session
+// of the DB executes SET GLOBAL name = value, including sessions created before
+// the call. The variable name is case-insensitive and a later registration for
+// the same name replaces the earlier one. Returning an error from the hook
+// fails the SET statement. The returned function unregisters the hook.
+func (db *DB) RegisterGlobalVariableHook(name string, hook session.GlobalVariableHook) func() {
+	db.globalVars.Register(name, hook)
+	return func() { db.globalVars.Unregister(name) }
+}
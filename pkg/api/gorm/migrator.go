@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/kasuganosora/sqlexec/pkg/security"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
@@ -55,7 +56,7 @@ func (m *Migrator) AutoMigrate(dst ...interface{}) error {
 func (m *Migrator) HasTable(value interface{}) bool {
 	tableName := m.getTableName(value)
 
-	sql := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = '" + escapeStringValue(tableName) + "'"
+	sql := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = " + quoteStringValue(tableName)
 
 	result, err := m.Dialector.Session.Query(sql)
 	if err != nil {
@@ -183,8 +184,8 @@ func (m *Migrator) RenameColumn(value interface{}, oldName, field string) error
 func (m *Migrator) HasColumn(value interface{}, name string) bool {
 	tableName := m.getTableName(value)
 
-	sql := "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = '" +
-		escapeStringValue(tableName) + "' AND column_name = '" + escapeStringValue(name) + "'"
+	sql := "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = " +
+		quoteStringValue(tableName) + " AND column_name = " + quoteStringValue(name)
 
 	result, err := m.Dialector.Session.Query(sql)
 	if err != nil {
@@ -223,8 +224,8 @@ func (m *Migrator) DropConstraint(value interface{}, name string) error {
 func (m *Migrator) HasConstraint(value interface{}, name string) bool {
 	tableName := m.getTableName(value)
 
-	sql := "SELECT COUNT(*) FROM information_schema.key_column_usage WHERE constraint_name = '" +
-		escapeStringValue(name) + "' AND table_name = '" + escapeStringValue(tableName) + "'"
+	sql := "SELECT COUNT(*) FROM information_schema.key_column_usage WHERE constraint_name = " +
+		quoteStringValue(name) + " AND table_name = " + quoteStringValue(tableName)
 
 	result, err := m.Dialector.Session.Query(sql)
 	if err != nil {
@@ -268,8 +269,8 @@ func (m *Migrator) DropIndex(value interface{}, name string) error {
 func (m *Migrator) HasIndex(value interface{}, name string) bool {
 	tableName := m.getTableName(value)
 
-	sql := "SELECT COUNT(*) FROM information_schema.statistics WHERE index_name = '" +
-		escapeStringValue(name) + "' AND table_name = '" + escapeStringValue(tableName) + "'"
+	sql := "SELECT COUNT(*) FROM information_schema.statistics WHERE index_name = " +
+		quoteStringValue(name) + " AND table_name = " + quoteStringValue(tableName)
 
 	result, err := m.Dialector.Session.Query(sql)
 	if err != nil {
@@ -366,14 +367,12 @@ func (m *Migrator) TableType(value interface{}) (tableType gorm.TableType, err e
 // quoteIdentifier quotes a SQL identifier with backticks, escaping any
 // embedded backticks to prevent SQL injection.
 func quoteIdentifier(name string) string {
-	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	return security.QuoteIdentifier(name)
 }
 
-// escapeStringValue escapes a string value for use in SQL string literals.
-func escapeStringValue(s string) string {
-	s = strings.ReplaceAll(s, `\`, `\\`)
-	s = strings.ReplaceAll(s, `'`, `''`)
-	return s
+// quoteStringValue quotes and escapes a string value as a SQL string literal.
+func quoteStringValue(s string) string {
+	return security.QuoteString(s)
 }
 
 // getTableName resolves the table name from various value types.
@@ -490,7 +489,7 @@ func (m *Migrator) generateCreateTableSQLFromSchema(s *schema.Schema) string {
 				switch field.DefaultValueInterface.(type) {
 				case string:
 					// String default values need to be quoted
-					defaultVal = "'" + escapeStringValue(defaultVal) + "'"
+					defaultVal = quoteStringValue(defaultVal)
 				default:
 					// Numeric and other types don't need quotes
 					defaultVal = fmt.Sprintf("%v", field.DefaultValueInterface)
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/graph"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// 图遍历表函数
+const (
+	graphReachableFunc    = "GRAPH_REACHABLE"     // GRAPH_REACHABLE('edges', start, max_depth[, 'out'|'in'|'both'])
+	graphShortestPathFunc = "GRAPH_SHORTEST_PATH" // GRAPH_SHORTEST_PATH('edges', from, to[, max_depth[, direction]])
+)
+
+// graphEdgeColumns 边表未指定列时按顺序尝试的起点/终点列名
+var graphEdgeColumns = [][2]string{{"src", "dst"}, {"source", "target"}, {"from", "to"}, {"from_id", "to_id"}}
+
+// graphWeightColumns 边表未指定列时作为权重的列名
+var graphWeightColumns = []string{"weight", "cost"}
+
+// traverseGraph 读取边表并执行一个图遍历表函数，返回结果集
+func (s *Session) traverseGraph(ctx context.Context, call parser.TableFunction) (*domain.QueryResult, error) {
+	maxArgs, usage := 4, "GRAPH_REACHABLE('edges', start, max_depth[, direction])"
+	if call.Name == graphShortestPathFunc {
+		maxArgs, usage = 5, "GRAPH_SHORTEST_PATH('edges', from, to[, max_depth[, direction]])"
+	}
+	if len(call.Args) < 3 || len(call.Args) > maxArgs {
+		return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf("%s: wrong number of arguments, usage: %s", call.Name, usage), nil)
+	}
+	spec, ok := call.Args[0].(string)
+	if !ok || strings.TrimSpace(spec) == "" {
+		return nil, NewError(ErrCodeInvalidParam, call.Name+": the edge table must be a string literal", nil)
+	}
+
+	// max_depth 为 NULL 或省略时不限深度
+	depthArg, dirArg := 2, 3
+	if call.Name == graphShortestPathFunc {
+		depthArg, dirArg = 3, 4
+	}
+	maxDepth := -1
+	if len(call.Args) > depthArg && call.Args[depthArg] != nil {
+		n, err := utils.ToInt(call.Args[depthArg])
+		if err != nil {
+			return nil, NewError(ErrCodeInvalidParam, call.Name+": max_depth must be an integer", err)
+		}
+		maxDepth = n
+	}
+	dir := graph.Outgoing
+	if len(call.Args) > dirArg {
+		name, _ := call.Args[dirArg].(string)
+		var err error
+		if dir, err = graph.ParseDirection(name); err != nil {
+			return nil, NewError(ErrCodeInvalidParam, call.Name+": "+err.Error(), nil)
+		}
+	}
+
+	edges, err := s.readGraphEdges(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	g, err := graph.New(edges, dir)
+	if err != nil {
+		return nil, NewError(ErrCodeInvalidParam, call.Name+": "+err.Error(), nil)
+	}
+
+	if call.Name == graphReachableFunc {
+		result := &domain.QueryResult{Columns: []domain.ColumnInfo{
+			{Name: "node"}, {Name: "depth", Type: "BIGINT"}, {Name: "parent"},
+		}}
+		for _, v := range g.Reachable(call.Args[1], maxDepth) {
+			result.Rows = append(result.Rows, domain.Row{"node": v.Node, "depth": int64(v.Depth), "parent": v.Parent})
+		}
+		return graphResultTypes(result, "node", "parent"), nil
+	}
+	result := &domain.QueryResult{Columns: []domain.ColumnInfo{
+		{Name: "step", Type: "BIGINT"}, {Name: "node"}, {Name: "cost", Type: "DOUBLE"},
+	}}
+	for i, step := range g.ShortestPath(call.Args[1], call.Args[2], maxDepth) {
+		result.Rows = append(result.Rows, domain.Row{"step": int64(i), "node": step.Node, "cost": step.Cost})
+	}
+	return graphResultTypes(result, "node"), nil
+}
+
+// graphResultTypes 节点列的类型与节点值一致（BIGINT 或 TEXT），同一结果中的多个节点列类型相同
+func graphResultTypes(result *domain.QueryResult, nodeColumns ...string) *domain.QueryResult {
+	colType := passthroughColumnType(result.Rows, nodeColumns[0])
+	for i := range result.Columns {
+		for _, name := range nodeColumns {
+			if result.Columns[i].Name == name {
+				result.Columns[i].Type = colType
+			}
+		}
+	}
+	return result
+}
+
+// readGraphEdges 读取边表 spec（'table'、'db.table' 或 'table(src, dst[, weight])'）中的所有边，
+// 未指定列时按常见的列名识别起点、终点与权重列，没有权重列时每条边的权重为 1
+func (s *Session) readGraphEdges(ctx context.Context, spec string) ([]graph.Edge, error) {
+	table, columns := strings.TrimSpace(spec), []string(nil)
+	if open := strings.Index(table, "("); open > 0 && strings.HasSuffix(table, ")") {
+		for _, col := range strings.Split(table[open+1:len(table)-1], ",") {
+			columns = append(columns, strings.Trim(strings.TrimSpace(col), "`"))
+		}
+		table = strings.TrimSpace(table[:open])
+		if len(columns) < 2 || len(columns) > 3 {
+			return nil, NewError(ErrCodeInvalidParam, "edge table columns must be (source, target[, weight]): "+spec, nil)
+		}
+	}
+	quoted := security.QuoteQualifiedIdentifier(strings.Split(strings.Trim(table, "`"), ".")...)
+
+	sql := "SELECT * FROM " + quoted
+	if columns != nil {
+		quotedCols := make([]string, len(columns))
+		for i, col := range columns {
+			quotedCols[i] = security.QuoteIdentifier(col)
+		}
+		sql = "SELECT " + strings.Join(quotedCols, ", ") + " FROM " + quoted
+	}
+	result, err := s.coreSession.ExecuteQuery(ctx, sql)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeSyntax, "failed to read edge table "+table)
+	}
+	if columns == nil {
+		if columns = detectEdgeColumns(result.Columns); columns == nil {
+			return nil, NewError(ErrCodeInvalidParam,
+				"cannot detect the source and target columns of edge table "+table+", specify them as '"+table+"(src, dst)'", nil)
+		}
+	}
+
+	edges := make([]graph.Edge, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		edge := graph.Edge{From: row[columns[0]], To: row[columns[1]], Weight: 1}
+		if len(columns) == 3 && row[columns[2]] != nil {
+			if edge.Weight, err = utils.ToFloat64(row[columns[2]]); err != nil {
+				return nil, NewError(ErrCodeInvalidParam, "edge weight column "+columns[2]+" must be numeric", err)
+			}
+		}
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+// detectEdgeColumns 按常见列名识别边表的起点、终点与可选的权重列，识别不出时返回 nil
+func detectEdgeColumns(cols []domain.ColumnInfo) []string {
+	find := func(name string) string {
+		for _, col := range cols {
+			if strings.EqualFold(col.Name, name) {
+				return col.Name
+			}
+		}
+		return ""
+	}
+	for _, pair := range graphEdgeColumns {
+		src, dst := find(pair[0]), find(pair[1])
+		if src == "" || dst == "" {
+			continue
+		}
+		columns := []string{src, dst}
+		for _, name := range graphWeightColumns {
+			if w := find(name); w != "" {
+				return append(columns, w)
+			}
+		}
+		return columns
+	}
+	return nil
+}
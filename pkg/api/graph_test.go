@@ -0,0 +1,65 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGraphTestSession 在 users 表之外建立边表 follows：1 -> 2 -> 3，1 -> 3 的直达边权重较大
+func newGraphTestSession(t *testing.T) *Session {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE follows (id INT PRIMARY KEY AUTO_INCREMENT, src INT, dst INT, weight DOUBLE)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO follows (src, dst, weight) VALUES (1, 2, 1), (2, 3, 1), (1, 3, 5)`)
+	require.NoError(t, err)
+	return s
+}
+
+// TestGraph_Reachable 测试 GRAPH_REACHABLE 的深度限制、方向以及与普通表的 JOIN
+func TestGraph_Reachable(t *testing.T) {
+	s := newGraphTestSession(t)
+
+	rows, err := s.QueryAll(`SELECT node, depth FROM GRAPH_REACHABLE('follows', 1, 1) ORDER BY node`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.EqualValues(t, 1, rows[0]["node"])
+	assert.EqualValues(t, 0, rows[0]["depth"])
+	assert.EqualValues(t, 1, rows[2]["depth"])
+
+	rows, err = s.QueryAll(`SELECT node FROM GRAPH_REACHABLE('follows(dst, src)', 3, NULL) WHERE depth > 0`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	rows, err = s.QueryAll(`SELECT u.name, r.depth FROM GRAPH_REACHABLE('follows', 2, 5, 'in') r JOIN users u ON u.id = r.node ORDER BY r.depth`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "bob", rows[0]["name"])
+	assert.Equal(t, "Alice", rows[1]["name"])
+}
+
+// TestGraph_ShortestPath 测试 GRAPH_SHORTEST_PATH 按权重与跳数限制选择路径
+func TestGraph_ShortestPath(t *testing.T) {
+	s := newGraphTestSession(t)
+
+	rows, err := s.QueryAll(`SELECT step, node, cost FROM GRAPH_SHORTEST_PATH('follows', 1, 3) ORDER BY step`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.EqualValues(t, 2, rows[1]["node"])
+	assert.EqualValues(t, 2, rows[2]["cost"])
+
+	rows, err = s.QueryAll(`SELECT node, cost FROM GRAPH_SHORTEST_PATH('follows', 1, 3, 1) ORDER BY step`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 5, rows[1]["cost"])
+
+	rows, err = s.QueryAll(`SELECT node FROM GRAPH_SHORTEST_PATH('follows', 3, 1)`)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	_, err = s.QueryAll(`SELECT * FROM GRAPH_REACHABLE('users', 1, 2)`)
+	assert.Error(t, err)
+	_, err = s.QueryAll(`SELECT * FROM GRAPH_REACHABLE('follows', 1)`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var groupByFixture = []string{
+	`CREATE TABLE emp (id INT, dept VARCHAR(20), salary INT)`,
+	// a: 100+200+300, b: 400+500, c: 600+...+900, d: 1000
+	`INSERT INTO emp (id, dept, salary) VALUES (0, 'a', 100), (1, 'a', 200), (2, 'a', 300), (3, 'b', 400), (4, 'b', 500),
+		(5, 'c', 600), (6, 'c', 700), (7, 'c', 800), (8, 'c', 900), (9, 'd', 1000)`,
+}
+
+func TestGroupBy_HavingAndOrderByAlias(t *testing.T) {
+	sess := newTestSession(t, groupByFixture...)
+
+	_, rows := queryRows(t, sess, "SELECT dept, COUNT(*) c FROM emp GROUP BY dept HAVING c > 1 ORDER BY c DESC")
+	assert.Equal(t, []interface{}{"c", "a", "b"}, columnValues(rows, "dept"))
+
+	_, rows = queryRows(t, sess, "SELECT dept, COUNT(*) c FROM emp GROUP BY dept HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC LIMIT 2")
+	assert.Equal(t, []interface{}{"c", "a"}, columnValues(rows, "dept"))
+
+	_, rows = queryRows(t, sess, "SELECT dept, SUM(salary) AS total FROM emp GROUP BY dept HAVING total >= 900 ORDER BY total DESC LIMIT 1 OFFSET 1")
+	require.Len(t, rows, 1)
+	assert.Equal(t, "d", rows[0]["dept"])
+
+	_, rows = queryRows(t, sess, "SELECT dept, COUNT(*) FROM emp GROUP BY dept ORDER BY 2, dept DESC")
+	assert.Equal(t, []interface{}{"d", "b", "a", "c"}, columnValues(rows, "dept"))
+}
+
+func TestGroupBy_HiddenAggregates(t *testing.T) {
+	sess := newTestSession(t, groupByFixture...)
+
+	// HAVING / ORDER BY 中未出现在 SELECT 列表的聚合函数不会出现在结果中
+	cols, rows := queryRows(t, sess, "SELECT dept, COUNT(*) AS c FROM emp GROUP BY dept HAVING MAX(salary) >= 500 ORDER BY SUM(salary) DESC")
+	assert.Equal(t, []interface{}{"c", "d", "b"}, columnValues(rows, "dept"))
+	require.Len(t, cols, 2)
+	assert.Equal(t, "dept", cols[0].Name)
+	assert.Equal(t, "c", cols[1].Name)
+	assert.Equal(t, "INTEGER", cols[1].Type)
+	for _, row := range rows {
+		assert.Len(t, row, 2)
+	}
+}
+
+func TestGroupBy_ApproxAggregates(t *testing.T) {
+	sess := newTestSession(t, groupByFixture...)
+
+	_, rows := queryRows(t, sess, "SELECT dept, APPROX_COUNT_DISTINCT(salary) AS n, APPROX_PERCENTILE(salary, 50) AS p50 "+
+		"FROM emp GROUP BY dept ORDER BY dept")
+	require.Len(t, rows, 4)
+	assert.EqualValues(t, 3, rows[0]["n"])
+	assert.EqualValues(t, 200, rows[0]["p50"])
+	assert.EqualValues(t, 1, rows[3]["n"])
+	assert.EqualValues(t, 1000, rows[3]["p50"])
+
+	_, rows = queryRows(t, sess, "SELECT APPROX_COUNT_DISTINCT(dept) AS depts, APPROX_PERCENTILE(salary, 100) AS top FROM emp")
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 4, rows[0]["depts"])
+	assert.EqualValues(t, 1000, rows[0]["top"])
+
+	_, err := sess.Query("SELECT APPROX_PERCENTILE(salary, 0) FROM emp")
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+)
+
+// DatasourceHealth is the health of a datasource as of its last probe
+type DatasourceHealth = application.DataSourceHealth
+
+// healthProber periodically probes every datasource
+type healthProber struct {
+	mu   sync.Mutex // guards stop and done
+	stop chan struct{}
+	done chan struct{}
+}
+
+// CheckDatasources probes every datasource now and returns their health.
+// A probe checks IsConnected and runs a lightweight query; datasources that
+// fail DBConfig.QuarantineAfter probes in a row are quarantined until a probe
+// succeeds again.
+func (db *DB) CheckDatasources(ctx context.Context) []DatasourceHealth {
+	health := db.dsManager.CheckHealth(ctx, db.config.HealthCheckTimeout)
+	for _, h := range health {
+		if h.Status != application.HealthUp {
+			db.logger.Warn("Datasource %s is %s: %s", h.Name, h.Status, h.LastError)
+		}
+	}
+	return health
+}
+
+// DatasourceHealth returns the health of every datasource as of its last
+// probe, without probing. Datasources never probed have the status "unknown".
+func (db *DB) DatasourceHealth() []DatasourceHealth {
+	return db.dsManager.Health()
+}
+
+// SetQuarantineThreshold sets after how many consecutive failed probes a
+// datasource is quarantined; 0 never quarantines.
+func (db *DB) SetQuarantineThreshold(failures int) error {
+	if failures < 0 {
+		return NewError(ErrCodeInvalidParam, "quarantine threshold must not be negative", nil)
+	}
+	db.dsManager.SetQuarantineThreshold(failures)
+	return nil
+}
+
+// StartHealthProber probes every datasource every interval until
+// StopHealthProber or Close is called. Calling it again restarts the prober.
+func (db *DB) StartHealthProber(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	db.StopHealthProber()
+
+	p := db.healthProber
+	p.mu.Lock()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	p.stop, p.done = stop, done
+	p.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				db.CheckDatasources(context.Background())
+			}
+		}
+	}()
+}
+
+// StopHealthProber stops the background health prober, if running
+func (db *DB) StopHealthProber() {
+	p := db.healthProber
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
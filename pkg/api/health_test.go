@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pingDataSource 可以模拟存活探测失败的数据源
+type pingDataSource struct {
+	*memory.MVCCDataSource
+	down  atomic.Bool
+	pings atomic.Int32
+}
+
+func (p *pingDataSource) Ping(ctx context.Context) error {
+	p.pings.Add(1)
+	if p.down.Load() {
+		return errors.New("dial tcp 10.0.0.1:3306: connection refused")
+	}
+	return nil
+}
+
+// TestHealth_Quarantine 测试连续探测失败后隔离数据源，探测成功后解除隔离
+func TestHealth_Quarantine(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB(&DBConfig{DefaultLogger: NewDefaultLogger(LogError), QuarantineAfter: 2})
+	require.NoError(t, err)
+	defer db.Close()
+
+	ds := &pingDataSource{MVCCDataSource: memory.NewMVCCDataSource(nil)}
+	require.NoError(t, ds.Connect(ctx))
+	require.NoError(t, db.RegisterDataSource("shop", ds))
+	require.NoError(t, ds.CreateTable(ctx, &domain.TableInfo{
+		Name:    "orders",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}},
+	}))
+
+	health := db.DatasourceHealth()
+	require.Len(t, health, 1)
+	assert.Equal(t, application.HealthUnknown, health[0].Status)
+
+	health = db.CheckDatasources(ctx)
+	assert.Equal(t, application.HealthUp, health[0].Status)
+	assert.False(t, health[0].LastCheck.IsZero())
+
+	s := db.Session()
+	defer s.Close()
+	require.NoError(t, s.UseDatabase("shop"))
+
+	// 第一次失败只标记为 down，语句仍然执行
+	ds.down.Store(true)
+	health = db.CheckDatasources(ctx)
+	assert.Equal(t, application.HealthDown, health[0].Status)
+	assert.Contains(t, health[0].LastError, "connection refused")
+	q, err := s.Query(`SELECT * FROM orders`)
+	require.NoError(t, err)
+	q.Close()
+
+	// 达到阈值后隔离，语句立即失败
+	health = db.CheckDatasources(ctx)
+	assert.Equal(t, application.HealthQuarantined, health[0].Status)
+	assert.Equal(t, 2, health[0].ConsecutiveFailures)
+	_, err = s.Query(`SELECT * FROM orders`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeRetryable))
+	assert.Contains(t, err.Error(), "is quarantined")
+
+	rows, err := s.QueryAll(`SHOW DATASOURCES`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "shop", rows[0]["Name"])
+	assert.Equal(t, "quarantined", rows[0]["Status"])
+	assert.EqualValues(t, 2, rows[0]["Consecutive_failures"])
+	assert.Contains(t, rows[0]["Last_error"], "connection refused")
+
+	// 探测成功后解除隔离
+	ds.down.Store(false)
+	health = db.CheckDatasources(ctx)
+	assert.Equal(t, application.HealthUp, health[0].Status)
+	q, err = s.Query(`SELECT * FROM orders`)
+	require.NoError(t, err)
+	q.Close()
+
+	assert.Error(t, db.SetQuarantineThreshold(-1))
+}
+
+// TestHealth_Prober 测试后台探测
+func TestHealth_Prober(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB(&DBConfig{DefaultLogger: NewDefaultLogger(LogError)})
+	require.NoError(t, err)
+	defer db.Close()
+
+	ds := &pingDataSource{MVCCDataSource: memory.NewMVCCDataSource(nil)}
+	require.NoError(t, ds.Connect(ctx))
+	require.NoError(t, db.RegisterDataSource("shop", ds))
+
+	db.StartHealthProber(5 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		return db.DatasourceHealth()[0].Status == application.HealthUp
+	}, time.Second, 5*time.Millisecond)
+
+	db.StopHealthProber()
+	pings := ds.pings.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, pings, ds.pings.Load())
+}
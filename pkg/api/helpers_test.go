@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// 多个测试文件共用的表和数据，作为 newTestDB / newTestSession 的 setup 传入
+var (
+	usersFixture = []string{
+		`CREATE TABLE users (id INT PRIMARY KEY AUTO_INCREMENT, name VARCHAR(50), city VARCHAR(50))`,
+		`INSERT INTO users (name, city) VALUES ('Alice', 'Paris'), ('bob', 'Berlin'), ('Carol', 'Paris')`,
+	}
+	accountsFixture = []string{
+		`CREATE TABLE accounts (id INT PRIMARY KEY, balance INT)`,
+		`INSERT INTO accounts VALUES (1, 100), (2, 100)`,
+	}
+)
+
+// newTestDB 创建只注册了一个内存数据源 test 的 DB，并在其上依次执行 setup 中的 SQL。
+// config 为 nil 时不启用查询缓存；未指定日志时只输出错误日志。DB 和数据源在测试结束时关闭
+func newTestDB(t *testing.T, config *DBConfig, setup ...string) *DB {
+	t.Helper()
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	t.Cleanup(func() { ds.Close(context.Background()) })
+
+	if config == nil {
+		config = &DBConfig{}
+	}
+	if config.DefaultLogger == nil {
+		config.DefaultLogger = NewDefaultLogger(LogError)
+	}
+	db, err := NewDB(config)
+	require.NoError(t, err)
+	require.NoError(t, db.RegisterDataSource("test", ds))
+	t.Cleanup(func() { db.Close() })
+
+	s := db.Session()
+	defer s.Close()
+	for _, sql := range setup {
+		_, err := s.Execute(sql)
+		require.NoError(t, err, sql)
+	}
+	return db
+}
+
+// newTestSession 在 newTestDB(t, nil, setup...) 上打开一个会话，测试结束时关闭
+func newTestSession(t *testing.T, setup ...string) *Session {
+	t.Helper()
+	s := newTestDB(t, nil, setup...).Session()
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// testDataSource 返回 newTestDB 注册的内存数据源，用于绕过 SQL 直接建索引、写入数据
+func testDataSource(t *testing.T, db *DB) *memory.MVCCDataSource {
+	t.Helper()
+	ds, err := db.GetDataSource("test")
+	require.NoError(t, err)
+	return ds.(*memory.MVCCDataSource)
+}
+
+// queryRows 执行查询并读出所有行
+func queryRows(t *testing.T, sess *Session, sql string) ([]domain.ColumnInfo, []domain.Row) {
+	t.Helper()
+	q, err := sess.Query(sql)
+	require.NoError(t, err, sql)
+	defer q.Close()
+
+	rows := []domain.Row{}
+	for q.Next() {
+		rows = append(rows, q.Row())
+	}
+	return q.Columns(), rows
+}
+
+// columnValues 按行顺序取出一列的值
+func columnValues(rows []domain.Row, name string) []interface{} {
+	values := make([]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = row[name]
+	}
+	return values
+}
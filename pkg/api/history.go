@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SetHistoryRetention sets how long replaced versions of a table are kept for
+// SELECT ... FOR SYSTEM_TIME AS OF queries. An empty tableName sets the
+// default of every table in the datasource, an empty dsName selects the
+// default datasource and a retention of 0 keeps no history.
+func (db *DB) SetHistoryRetention(ctx context.Context, dsName, tableName string, retention time.Duration) error {
+	if retention < 0 {
+		return NewError(ErrCodeInvalidParam, "history retention must not be negative", nil)
+	}
+	var ds domain.DataSource
+	var err error
+	if dsName == "" {
+		ds, err = db.GetDefaultDataSource()
+	} else {
+		ds, err = db.GetDataSource(dsName)
+	}
+	if err != nil {
+		return err
+	}
+
+	hm, ok := ds.(domain.HistoryManager)
+	if !ok {
+		return NewError(ErrCodeNotSupported, "datasource does not support history", nil)
+	}
+	if err := hm.SetHistoryRetention(ctx, tableName, retention); err != nil {
+		var notFound *domain.ErrTableNotFound
+		if errors.As(err, &notFound) {
+			return NewError(ErrCodeTableNotFound, "table '"+tableName+"' not found", err)
+		}
+		return NewError(ErrCodeInternal, "failed to set history retention", err)
+	}
+	return nil
+}
+
+// PurgeHistory removes table versions older than their history retention in
+// every datasource and returns the number of versions removed. The TTL purger
+// calls it on every pass.
+func (db *DB) PurgeHistory(ctx context.Context) (int64, error) {
+	var total int64
+	var errs []error
+	for _, ds := range db.dsManager.GetAllDataSources() {
+		hm, ok := ds.(domain.HistoryManager)
+		if !ok {
+			continue
+		}
+		purged, err := hm.PurgeHistory(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		total += purged
+	}
+	return total, errors.Join(errs...)
+}
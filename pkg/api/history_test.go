@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const asOfLayout = "2006-01-02 15:04:05.000000"
+
+// TestHistory_SelectAsOf 测试 FOR SYSTEM_TIME AS OF 读取表在过去时间点的数据
+func TestHistory_SelectAsOf(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	require.NoError(t, db.SetHistoryRetention(context.Background(), "test", "accounts", time.Hour))
+	s := db.Session()
+	defer s.Close()
+
+	before := time.Now().Format(asOfLayout)
+	time.Sleep(10 * time.Millisecond)
+	_, err := s.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, countRows(t, s, `SELECT * FROM accounts`))
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts FOR SYSTEM_TIME AS OF '`+before+`'`))
+	assert.Equal(t, 1, countRows(t, s,
+		`SELECT * FROM accounts FOR SYSTEM_TIME AS OF '`+before+`' AS a WHERE a.balance = 100 AND a.id = 1`))
+
+	// 超出保留时间和未保留历史的表报错
+	_, err = s.Query(`SELECT * FROM accounts FOR SYSTEM_TIME AS OF '2000-01-01 00:00:00'`)
+	assert.ErrorContains(t, err, "history retention")
+	_, err = s.Query(`SELECT * FROM ledger_missing FOR SYSTEM_TIME AS OF '` + before + `'`)
+	assert.Error(t, err)
+	_, err = s.Query(`SELECT * FROM accounts FOR SYSTEM_TIME AS OF 'yesterday'`)
+	assert.ErrorContains(t, err, "invalid AS OF timestamp")
+}
+
+// TestHistory_NotSupported 测试不保存历史版本的数据源
+func TestHistory_NotSupported(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Query(`SELECT * FROM information_schema.tables FOR SYSTEM_TIME AS OF '2024-01-01 00:00:00'`)
+	assert.ErrorContains(t, err, "not supported")
+
+	err = db.SetHistoryRetention(context.Background(), "test", "missing", time.Hour)
+	assert.True(t, IsErrorCode(err, ErrCodeTableNotFound), "unexpected error: %v", err)
+	err = db.SetHistoryRetention(context.Background(), "test", "", -time.Hour)
+	assert.True(t, IsErrorCode(err, ErrCodeInvalidParam), "unexpected error: %v", err)
+}
+
+// TestHistory_Purge 测试 PurgeHistory 清理超出保留时间的历史版本
+func TestHistory_Purge(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	require.NoError(t, db.SetHistoryRetention(context.Background(), "test", "", 50*time.Millisecond))
+	s := db.Session()
+	defer s.Close()
+
+	before := time.Now().Format(asOfLayout)
+	time.Sleep(10 * time.Millisecond)
+	_, err := s.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts FOR SYSTEM_TIME AS OF '`+before+`'`))
+
+	time.Sleep(100 * time.Millisecond)
+	purged, err := db.PurgeHistory(context.Background())
+	require.NoError(t, err)
+	assert.Positive(t, purged)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/session"
+)
+
+// IdempotencyKeyLabel 写入语句开头注释中的幂等键，如 /* idempotency_key=order-42 */ INSERT ...
+// 也可以和其他查询标签写在同一个注释里
+const IdempotencyKeyLabel = "idempotency_key"
+
+// idempotencyStore 记录已成功执行的带幂等键的写入语句及其结果，保留 ttl 时间。
+// 同一用户在同一数据库上重复提交相同的键时直接返回第一次执行的结果，不再执行
+type idempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry // 用户、数据库和幂等键 -> 记录
+	nextPurge time.Time                    // 下次清理过期记录的时间
+}
+
+// idempotencyEntry 一个幂等键的执行记录，done 关闭前语句仍在执行
+type idempotencyEntry struct {
+	sql     string
+	done    chan struct{}
+	result  *Result // 执行失败时为 nil
+	expires time.Time
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{ttl: ttl, entries: make(map[string]*idempotencyEntry)}
+}
+
+// execute 按幂等键执行语句：键没有记录时调用 run 并在成功后记录结果；键正在执行时等待其完成；
+// 已有成功的记录时返回记录的结果。同一个键用于不同的语句时返回错误。
+// 执行失败不留下记录，客户端可以用同一个键重试
+func (st *idempotencyStore) execute(key, sql string, run func() (*Result, error)) (*Result, error) {
+	for {
+		st.mu.Lock()
+		now := time.Now()
+		st.purgeLocked(now)
+		entry, ok := st.entries[key]
+		if ok && entry.result != nil && !now.Before(entry.expires) {
+			delete(st.entries, key)
+			ok = false
+		}
+		if !ok {
+			entry = &idempotencyEntry{sql: sql, done: make(chan struct{})}
+			st.entries[key] = entry
+			st.mu.Unlock()
+			return st.run(key, entry, run)
+		}
+		st.mu.Unlock()
+
+		if entry.sql != sql {
+			return nil, NewError(ErrCodeInvalidParam, "idempotency key was already used for a different statement", nil)
+		}
+		<-entry.done
+		if entry.result != nil {
+			return entry.result.clone(), nil
+		}
+		// 先执行的语句失败，由当前语句重新执行
+	}
+}
+
+// run 执行语句并记录结果
+func (st *idempotencyStore) run(key string, entry *idempotencyEntry, run func() (*Result, error)) (*Result, error) {
+	result, err := run()
+	st.mu.Lock()
+	if err != nil || result == nil {
+		delete(st.entries, key)
+	} else {
+		entry.result = result.clone()
+		entry.expires = time.Now().Add(st.ttl)
+	}
+	close(entry.done)
+	st.mu.Unlock()
+	return result, err
+}
+
+// purgeLocked 每隔 ttl 删除一次过期的记录，调用方持有 st.mu
+func (st *idempotencyStore) purgeLocked(now time.Time) {
+	if now.Before(st.nextPurge) {
+		return
+	}
+	st.nextPurge = now.Add(st.ttl)
+	for key, entry := range st.entries {
+		if entry.result != nil && !now.Before(entry.expires) {
+			delete(st.entries, key)
+		}
+	}
+}
+
+// clone 复制结果，附加信息的追加不影响记录的结果
+func (r *Result) clone() *Result {
+	c := *r
+	c.Warnings = append([]string(nil), r.Warnings...)
+	return &c
+}
+
+// idempotencyKey 返回语句的幂等键记录名（用户、当前数据库和键）以及去掉 trace_id 注释后用于比较的语句。
+// 没有键、未启用幂等键、在显式事务中（提交前结果可能被回滚）、在分叉会话中或不是 INSERT/UPDATE/DELETE 时返回空字符串
+func (s *Session) idempotencyKey(boundSQL string) (string, string) {
+	if s.db.idempotency == nil || s.coreSession == nil || s.Fork() != nil {
+		return "", ""
+	}
+	_, sql := session.ExtractTraceID(boundSQL)
+	key := session.ParseQueryLabels(sql)[IdempotencyKeyLabel]
+	if key == "" || s.InTransaction() {
+		return "", ""
+	}
+	parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL)
+	if err != nil || !parseResult.Success {
+		return "", ""
+	}
+	switch parseResult.Statement.Type {
+	case parser.SQLTypeInsert, parser.SQLTypeUpdate, parser.SQLTypeDelete:
+		return fmt.Sprintf("%s\x00%s\x00%s", s.GetUser(), s.GetCurrentDB(), key), sql
+	}
+	return "", ""
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var idempotencyFixture = []string{`CREATE TABLE orders (id INT PRIMARY KEY AUTO_INCREMENT, amount INT)`}
+
+// TestIdempotency_Duplicate 测试重复提交相同的幂等键时返回第一次执行的结果，不再执行
+func TestIdempotency_Duplicate(t *testing.T) {
+	db := newTestDB(t, &DBConfig{IdempotencyTTL: time.Minute}, idempotencyFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	const insert = `/* idempotency_key=order-1 */ INSERT INTO orders (amount) VALUES (10)`
+	first, err := s.Execute(insert)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, first.RowsAffected)
+
+	// 另一个连接重试（如网络中断后重连）
+	retry := db.Session()
+	defer retry.Close()
+	second, err := retry.Execute(insert)
+	require.NoError(t, err)
+	assert.Equal(t, first.RowsAffected, second.RowsAffected)
+	assert.Equal(t, first.LastInsertID, second.LastInsertID)
+
+	// 通过 Query 执行（MySQL 协议的 COM_QUERY）同样去重
+	q, err := s.Query(insert)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, q.ExecResult().RowsAffected)
+	q.Close()
+
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM orders`))
+
+	// 不同的键和不带键的语句照常执行
+	_, err = s.Execute(`/* app=shop, idempotency_key=order-2 */ INSERT INTO orders (amount) VALUES (10)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO orders (amount) VALUES (10)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO orders (amount) VALUES (10)`)
+	require.NoError(t, err)
+	assert.Equal(t, 4, countRows(t, s, `SELECT * FROM orders`))
+}
+
+// TestIdempotency_DifferentStatement 测试同一个键用于不同的语句时报错
+func TestIdempotency_DifferentStatement(t *testing.T) {
+	db := newTestDB(t, &DBConfig{IdempotencyTTL: time.Minute}, idempotencyFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`/* idempotency_key=k */ INSERT INTO orders (amount) VALUES (10)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`/* idempotency_key=k */ INSERT INTO orders (amount) VALUES (20)`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeInvalidParam))
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM orders`))
+}
+
+// TestIdempotency_FailureNotRecorded 测试执行失败的语句不留下记录，可以用同一个键重试
+func TestIdempotency_FailureNotRecorded(t *testing.T) {
+	db := newTestDB(t, &DBConfig{IdempotencyTTL: time.Minute}, idempotencyFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`INSERT INTO orders (id, amount) VALUES (1, 10)`)
+	require.NoError(t, err)
+
+	const insert = `/* idempotency_key=k */ INSERT INTO orders (id, amount) VALUES (1, 20)`
+	_, err = s.Execute(insert)
+	require.Error(t, err)
+	_, err = s.Execute(`DELETE FROM orders WHERE id = 1`)
+	require.NoError(t, err)
+	result, err := s.Execute(insert)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.RowsAffected)
+}
+
+// TestIdempotency_Expired 测试记录过期后相同的键重新执行
+func TestIdempotency_Expired(t *testing.T) {
+	db := newTestDB(t, &DBConfig{IdempotencyTTL: 20 * time.Millisecond}, idempotencyFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	const insert = `/* idempotency_key=k */ INSERT INTO orders (amount) VALUES (10)`
+	_, err := s.Execute(insert)
+	require.NoError(t, err)
+	time.Sleep(40 * time.Millisecond)
+	_, err = s.Execute(insert)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM orders`))
+}
+
+// TestIdempotency_Scope 测试幂等键按用户区分，事务中的键和未启用时的键被忽略
+func TestIdempotency_Scope(t *testing.T) {
+	db := newTestDB(t, &DBConfig{IdempotencyTTL: time.Minute}, idempotencyFixture...)
+	alice, bob := db.Session(), db.Session()
+	defer alice.Close()
+	defer bob.Close()
+	alice.SetUser("alice")
+	bob.SetUser("bob")
+
+	const insert = `/* idempotency_key=k */ INSERT INTO orders (amount) VALUES (10)`
+	_, err := alice.Execute(insert)
+	require.NoError(t, err)
+	_, err = bob.Execute(insert)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, alice, `SELECT * FROM orders`))
+
+	tx, err := alice.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`/* idempotency_key=tx */ INSERT INTO orders (amount) VALUES (10)`)
+	require.NoError(t, err)
+	_, err = tx.Execute(`/* idempotency_key=tx */ INSERT INTO orders (amount) VALUES (10)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	assert.Equal(t, 2, countRows(t, alice, `SELECT * FROM orders`))
+
+	disabled := newTestDB(t, &DBConfig{IdempotencyTTL: 0}, idempotencyFixture...)
+	s := disabled.Session()
+	defer s.Close()
+	for i := 0; i < 2; i++ {
+		_, err = s.Execute(insert)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM orders`))
+}
+
+// TestIdempotency_Concurrent 测试并发提交相同的键时只执行一次
+func TestIdempotency_Concurrent(t *testing.T) {
+	db := newTestDB(t, &DBConfig{IdempotencyTTL: time.Minute}, idempotencyFixture...)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := db.Session()
+			defer s.Close()
+			result, err := s.Execute(`/* idempotency_key=k */ INSERT INTO orders (amount) VALUES (10)`)
+			assert.NoError(t, err)
+			if err == nil {
+				assert.EqualValues(t, 1, result.RowsAffected)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := db.Session()
+	defer s.Close()
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM orders`))
+}
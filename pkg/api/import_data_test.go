@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportData 测试 IMPORT DATA INFILE 导入文件并返回导入信息和错误行警告
+func TestImportData(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	dir := t.TempDir()
+
+	_, err := s.Execute(`CREATE TABLE people (id INT PRIMARY KEY, name VARCHAR(50), age INT)`)
+	require.NoError(t, err)
+	csvPath := filepath.Join(dir, "people.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("id,name,age\n1,ann,30\n2,ben,x\n3,cid,41\n"), 0o644))
+	sql := fmt.Sprintf(`IMPORT DATA INFILE '%s' FORMAT CSV INTO TABLE people WITH (on_error = 'collect')`, strings.ReplaceAll(csvPath, "'", "''"))
+	res, err := s.Execute(sql)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.RowsAffected)
+	assert.Equal(t, "Records: 3  Deleted: 0  Skipped: 1  Warnings: 1", res.Info)
+	require.Len(t, res.Warnings, 1)
+	assert.Contains(t, res.Warnings[0], "line 3")
+
+	rows, err := s.QueryAll(`SELECT name FROM people WHERE age > 35`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "cid", rows[0]["name"])
+
+	// 导入已有的表，字段按名称映射到列
+	jsonPath := filepath.Join(dir, "users.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`[{"name": "dora", "city": "Oslo"}, {"name": "eli", "city": "Rome"}]`), 0o644))
+	res, err = s.Execute(fmt.Sprintf(`IMPORT DATA INFILE '%s' FORMAT JSON INTO TABLE users`, jsonPath))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.RowsAffected)
+	rows, err = s.QueryAll(`SELECT name FROM users WHERE city = 'Rome'`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	_, err = s.Execute(fmt.Sprintf(`IMPORT DATA INFILE '%s' FORMAT CSV INTO TABLE people (id, name, age) WITH (header = false)`, csvPath))
+	assert.Error(t, err, "abort is the default error mode, the header line is not an integer")
+	assert.Contains(t, err.Error(), "line 1: column id")
+	_, err = s.Execute(`IMPORT DATA INFILE '/nonexistent/file.csv' FORMAT CSV INTO TABLE people3`)
+	assert.Error(t, err)
+	_, err = s.Execute(fmt.Sprintf(`IMPORT DATA INFILE '%s' FORMAT CSV INTO TABLE people WITH (array_root = 'data')`, csvPath))
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryIsolation(t *testing.T, s *Session, sql string) string {
+	t.Helper()
+	q, err := s.Query(sql)
+	require.NoError(t, err)
+	defer q.Close()
+	require.True(t, q.Next())
+	row := q.Row()
+	for _, v := range row {
+		return v.(string)
+	}
+	return ""
+}
+
+// TestIsolation_SetTransaction 测试 SET TRANSACTION ISOLATION LEVEL 与 @@transaction_isolation
+func TestIsolation_SetTransaction(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	assert.Equal(t, IsolationRepeatableRead, s.IsolationLevel())
+	assert.Equal(t, "REPEATABLE-READ", queryIsolation(t, s, `SELECT @@transaction_isolation`))
+
+	_, err := s.Execute(`SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+	require.NoError(t, err)
+	assert.Equal(t, IsolationReadCommitted, s.IsolationLevel())
+	assert.Equal(t, "READ-COMMITTED", queryIsolation(t, s, `SELECT @@transaction_isolation`))
+	assert.Equal(t, "READ-COMMITTED", queryIsolation(t, s, `SELECT @@tx_isolation`))
+
+	_, err = s.Execute(`SET transaction_isolation = 'SERIALIZABLE'`)
+	require.NoError(t, err)
+	assert.Equal(t, IsolationSerializable, s.IsolationLevel())
+
+	s.SetIsolationLevel(IsolationRepeatableRead)
+	assert.Equal(t, "REPEATABLE-READ", queryIsolation(t, s, `SELECT @@transaction_isolation`))
+
+	// SET TRANSACTION 只影响下一个事务，不改变会话级别
+	_, err = s.Execute(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+	require.NoError(t, err)
+	assert.Equal(t, IsolationRepeatableRead, s.IsolationLevel())
+}
+
+// TestIsolation_Errors 测试非法隔离级别（1231）与事务中修改特性（1568）
+func TestIsolation_Errors(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`SET transaction_isolation = 'SNAPSHOT'`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrWrongValueForVar, code)
+
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	defer tx.Close()
+	_, err = s.Execute(`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`)
+	require.Error(t, err)
+	code, _ = mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrCantChangeTx, code)
+}
+
+// TestIsolation_ReadCommitted 测试 READ COMMITTED 事务中每条语句看到最新已提交数据
+func TestIsolation_ReadCommitted(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	reader, writer := db.Session(), db.Session()
+	defer reader.Close()
+	defer writer.Close()
+
+	countRows := func(tx *Transaction) int {
+		q, err := tx.Query(`SELECT * FROM accounts`)
+		require.NoError(t, err)
+		defer q.Close()
+		n := 0
+		for q.Next() {
+			n++
+		}
+		return n
+	}
+
+	reader.SetIsolationLevel(IsolationReadCommitted)
+	tx, err := reader.Begin()
+	require.NoError(t, err)
+	defer tx.Close()
+	assert.Equal(t, 2, countRows(tx))
+
+	_, err = writer.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	require.NoError(t, err)
+	assert.Equal(t, 3, countRows(tx))
+}
@@ -0,0 +1,746 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// ==================== 语句检查 ====================
+// Lint 解析语句而不执行，对照当前的表结构报告问题：语法错误及其位置、不存在的表和列、
+// 比较两侧类型不一致、没有 WHERE 的 UPDATE/DELETE，并按各表的访问方式和行数估计成本等级。
+// 只检查结构，不读取数据，也不检查权限
+
+// 检查问题的级别
+const (
+	LintError   = "error"   // 语句不能执行
+	LintWarning = "warning" // 语句可以执行，但结果或性能可能不符合预期
+)
+
+// 检查规则
+const (
+	LintRuleSyntax        = "syntax"         // 语法错误
+	LintRuleUnknownTable  = "unknown_table"  // 表不存在
+	LintRuleUnknownColumn = "unknown_column" // 列不存在
+	LintRuleTypeMismatch  = "type_mismatch"  // 比较两侧的类型不一致，会发生隐式转换
+	LintRuleMissingWhere  = "missing_where"  // UPDATE/DELETE 没有 WHERE，修改全表
+)
+
+// 表的访问方式
+const (
+	LintAccessPoint = "point" // 按主键或唯一键等值查找
+	LintAccessIndex = "index" // 按索引列查找
+	LintAccessScan  = "scan"  // 全表扫描
+)
+
+// 成本等级
+const (
+	LintCostLow    = "low"    // 只按键查找或扫描小表
+	LintCostMedium = "medium" // 扫描中等大小或行数未知的表
+	LintCostHigh   = "high"   // 扫描大表，或没有连接条件的 JOIN
+)
+
+// 成本等级的行数界限：全表扫描不超过 lintSmallTableRows 行为 low，超过 lintLargeTableRows 行为 high
+const (
+	lintSmallTableRows = 1000
+	lintLargeTableRows = 100000
+)
+
+// LintIssue 检查发现的一个问题
+type LintIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`   // 语法错误所在的行（从 1 开始）
+	Column   int    `json:"column,omitempty"` // 语法错误所在的列（从 1 开始）
+	Near     string `json:"near,omitempty"`   // 语法错误附近的文本
+}
+
+// LintTableAccess 语句对一张表的访问
+type LintTableAccess struct {
+	Table  string `json:"table"`
+	Access string `json:"access"` // point、index 或 scan
+	Rows   int64  `json:"rows"`   // 表的行数，未知时为 -1
+}
+
+// LintReport 一条语句的检查结果
+type LintReport struct {
+	SQL    string            `json:"sql"`
+	Type   string            `json:"type,omitempty"` // 语句类型，语法错误时为空
+	Issues []LintIssue       `json:"issues"`
+	Cost   string            `json:"cost,omitempty"` // 成本等级，只对 SELECT/INSERT/UPDATE/DELETE 估计
+	Tables []LintTableAccess `json:"tables,omitempty"`
+}
+
+// HasErrors 返回是否有 error 级别的问题
+func (r *LintReport) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint 检查 sql 中的每条语句（按分号拆分）而不执行，每条语句返回一个检查结果。
+// 语句中的 USE 切换后续语句的数据库，CREATE TABLE 创建的表对后续语句可见，但都不实际执行
+func (s *Session) Lint(sql string) ([]*LintReport, error) {
+	s.mu.RLock()
+	if s.err != nil {
+		s.mu.RUnlock()
+		return nil, s.err
+	}
+	s.mu.RUnlock()
+
+	l := &linter{session: s, database: s.GetCurrentDB(), created: make(map[string]*domain.TableInfo)}
+	statements := parser.SplitStatements(sql)
+	reports := make([]*LintReport, 0, len(statements))
+	for _, stmt := range statements {
+		reports = append(reports, l.lint(stmt))
+	}
+	return reports, nil
+}
+
+// syntaxErrorRe 匹配 TiDB 解析器错误中的位置：line 1 column 13 near "FORM t"
+var syntaxErrorRe = regexp.MustCompile(`line (\d+) column (\d+) near "((?s).*)"`)
+
+// linter 检查一批语句，记录批内 USE 和 CREATE TABLE 的效果
+type linter struct {
+	session  *Session
+	database string                       // 当前数据库
+	created  map[string]*domain.TableInfo // 批内创建的表：数据库 + "." + 表名 -> 结构
+
+	report *LintReport
+	access []LintTableAccess
+	cross  bool // 有没有连接条件的 JOIN
+}
+
+// lintTable 语句引用的一张表
+type lintTable struct {
+	database string
+	name     string
+	alias    string
+	info     *domain.TableInfo // nil 表示结构未知（如虚拟数据库中的表），不检查其列
+	ds       domain.DataSource
+	derived  bool // 派生表，没有表名，任何限定名都可能指向它
+}
+
+func (t *lintTable) String() string {
+	if t.database != "" {
+		return t.database + "." + t.name
+	}
+	return t.name
+}
+
+// matches 返回限定名 qualifier 是否指向该表
+func (t *lintTable) matches(qualifier string) bool {
+	if t.alias != "" {
+		return strings.EqualFold(t.alias, qualifier)
+	}
+	return strings.EqualFold(t.name, qualifier)
+}
+
+// column 在表中查找列，结构未知时返回 nil
+func (t *lintTable) column(name string) *domain.ColumnInfo {
+	if t.info == nil {
+		return nil
+	}
+	for i := range t.info.Columns {
+		if !t.info.Columns[i].Hidden && strings.EqualFold(t.info.Columns[i].Name, name) {
+			return &t.info.Columns[i]
+		}
+	}
+	return nil
+}
+
+// knownColumns 返回是否知道表的列
+func (t *lintTable) knownColumns() bool {
+	return t.info != nil && len(t.info.Columns) > 0
+}
+
+func (l *linter) issue(rule, severity, format string, args ...interface{}) {
+	l.report.Issues = append(l.report.Issues, LintIssue{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// lint 检查一条语句
+func (l *linter) lint(sql string) *LintReport {
+	l.report = &LintReport{SQL: sql, Issues: []LintIssue{}}
+	l.access, l.cross = nil, false
+
+	result, err := l.session.coreSession.GetAdapter().Parse(sql)
+	if err != nil || !result.Success {
+		message := ""
+		if result != nil {
+			message = result.Error
+		} else {
+			message = err.Error()
+		}
+		message = strings.TrimPrefix(message, "parse SQL failed: ")
+		issue := LintIssue{Rule: LintRuleSyntax, Severity: LintError, Message: message}
+		if m := syntaxErrorRe.FindStringSubmatch(message); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+			issue.Column, _ = strconv.Atoi(m[2])
+			issue.Near = m[3]
+		}
+		l.report.Issues = append(l.report.Issues, issue)
+		return l.report
+	}
+
+	stmt := result.Statement
+	l.report.Type = string(stmt.Type)
+	estimate := true
+	switch {
+	case stmt.Type == parser.SQLTypeSelect && stmt.Select != nil:
+		l.lintSelect(stmt.Select, nil)
+	case stmt.Type == parser.SQLTypeInsert && stmt.Insert != nil:
+		l.lintInsert(stmt.Insert)
+	case stmt.Type == parser.SQLTypeUpdate && stmt.Update != nil:
+		l.lintUpdate(stmt.Update)
+	case stmt.Type == parser.SQLTypeDelete && stmt.Delete != nil:
+		l.lintDelete(stmt.Delete)
+	case stmt.Type == parser.SQLTypeUse && stmt.Use != nil:
+		l.database = stmt.Use.Database
+		estimate = false
+	case stmt.Type == parser.SQLTypeCreate && stmt.Create != nil && strings.EqualFold(stmt.Create.Type, "TABLE"):
+		l.createTable(stmt.Create)
+		estimate = false
+	default:
+		estimate = false
+	}
+	if estimate {
+		l.report.Tables = l.access
+		l.report.Cost = l.cost()
+	}
+	return l.report
+}
+
+// createTable 记录批内创建的表，后续语句可以引用
+func (l *linter) createTable(create *parser.CreateStatement) {
+	info := &domain.TableInfo{Name: create.Name}
+	for _, col := range create.Columns {
+		info.Columns = append(info.Columns, domain.ColumnInfo{
+			Name:    col.Name,
+			Type:    col.Type,
+			Primary: col.Primary,
+			Unique:  col.Unique,
+		})
+	}
+	database := create.Database
+	if database == "" {
+		database = l.database
+	}
+	l.created[database+"."+create.Name] = info
+}
+
+// table 查找语句引用的表，不存在时报告 unknown_table 并返回结构未知的表
+func (l *linter) table(database, name, alias string) *lintTable {
+	// 解析器保留 FROM 中的限定名（db.table）
+	if database != "" {
+		name = strings.TrimPrefix(name, database+".")
+	}
+	t := &lintTable{database: database, name: name, alias: alias}
+	db := database
+	if db == "" {
+		db = l.database
+	}
+	if info, ok := l.created[db+"."+name]; ok {
+		t.info = info
+		return t
+	}
+
+	t.ds = l.dataSource(db)
+	if t.ds == nil {
+		return t
+	}
+	info, err := t.ds.GetTableInfo(context.Background(), name)
+	if err != nil {
+		var notFound *domain.ErrTableNotFound
+		if errors.As(err, &notFound) {
+			l.issue(LintRuleUnknownTable, LintError, "table '%s' doesn't exist", t)
+		}
+		t.ds = nil
+		return t
+	}
+	t.info = info
+	return t
+}
+
+// dataSource 返回数据库 database 的数据源；不是已注册的数据源（如虚拟数据库）时返回 nil
+func (l *linter) dataSource(database string) domain.DataSource {
+	if database == "" {
+		return l.session.coreSession.GetDataSource()
+	}
+	if l.session.db == nil {
+		return nil
+	}
+	ds, err := l.session.db.GetDataSource(database)
+	if err != nil {
+		return nil
+	}
+	return ds
+}
+
+// lintSelect 检查 SELECT，outer 为外层查询的表（关联子查询可以引用）
+func (l *linter) lintSelect(sel *parser.SelectStatement, outer []*lintTable) {
+	// 派生表（FROM (SELECT ...) t）在解析结果中没有表名，作为结构未知的表
+	var scope []*lintTable
+	var conds []*parser.Expression // 各表的连接条件
+	if sel.From != "" {
+		scope = append(scope, l.table(sel.Database, sel.From, sel.FromAlias))
+	} else {
+		scope = append(scope, &lintTable{derived: true})
+	}
+	conds = append(conds, nil)
+	for _, join := range sel.Joins {
+		if join.Table == "" {
+			scope = append(scope, &lintTable{derived: true})
+		} else {
+			scope = append(scope, l.table(join.Database, join.Table, join.Alias))
+		}
+		conds = append(conds, join.Condition)
+	}
+	columns := append(append([]*lintTable(nil), scope...), outer...)
+
+	aliases := make(map[string]bool)
+	for _, col := range sel.Columns {
+		if col.Alias != "" {
+			aliases[strings.ToLower(col.Alias)] = true
+		}
+	}
+	for _, col := range sel.Columns {
+		if col.IsWildcard {
+			if col.Table != "" && findTable(columns, col.Table) == nil {
+				l.issue(LintRuleUnknownColumn, LintError, "unknown table '%s' in '%s.*'", col.Table, col.Table)
+			}
+			continue
+		}
+		l.expr(columns, col.Expr, nil)
+	}
+	for _, join := range sel.Joins {
+		l.expr(columns, join.Condition, nil)
+	}
+	l.expr(columns, sel.Where, nil)
+	for _, name := range sel.GroupBy {
+		if expr, ok := sel.GroupByExprs[name]; ok {
+			l.expr(columns, expr, aliases)
+		} else if plainIdentifierRe.MatchString(name) {
+			l.expr(columns, &parser.Expression{Type: parser.ExprTypeColumn, Column: name}, aliases)
+		}
+	}
+	l.expr(columns, sel.Having, aliases)
+	for _, item := range sel.OrderBy {
+		if item.Expr == nil && plainIdentifierRe.MatchString(item.Column) {
+			l.expr(columns, &parser.Expression{Type: parser.ExprTypeColumn, Column: item.Column}, aliases)
+		}
+	}
+
+	for i, t := range scope {
+		if t.derived {
+			continue
+		}
+		l.addAccess(t, sel.Where, scope)
+		if i > 0 && conds[i] == nil && !joinedInWhere(t, scope, sel.Where) {
+			l.cross = true
+		}
+	}
+}
+
+// plainIdentifierRe 匹配 col 或 t.col 形式的列名（排序和分组项也可能是表达式文本）
+var plainIdentifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func (l *linter) lintInsert(insert *parser.InsertStatement) {
+	t := l.table(insert.Database, insert.Table, "")
+	if t.knownColumns() {
+		for _, name := range insert.Columns {
+			if t.column(name) == nil {
+				l.issue(LintRuleUnknownColumn, LintError, "unknown column '%s' in table '%s'", name, t)
+			}
+		}
+	}
+	if insert.OnDuplicate != nil {
+		l.setColumns(t, insert.OnDuplicate.Set)
+	}
+}
+
+func (l *linter) lintUpdate(update *parser.UpdateStatement) {
+	t := l.table(update.Database, update.Table, "")
+	scope := []*lintTable{t}
+	l.setColumns(t, update.Set)
+	l.expr(scope, update.Where, nil)
+	if update.Where == nil {
+		l.issue(LintRuleMissingWhere, LintWarning, "UPDATE without WHERE changes every row of '%s'", t)
+	}
+	l.addAccess(t, update.Where, scope)
+}
+
+func (l *linter) lintDelete(del *parser.DeleteStatement) {
+	t := l.table(del.Database, del.Table, "")
+	scope := []*lintTable{t}
+	l.expr(scope, del.Where, nil)
+	if del.Where == nil {
+		l.issue(LintRuleMissingWhere, LintWarning, "DELETE without WHERE deletes every row of '%s'", t)
+	}
+	l.addAccess(t, del.Where, scope)
+}
+
+// setColumns 检查 UPDATE SET 的列及其中的表达式
+func (l *linter) setColumns(t *lintTable, set map[string]interface{}) {
+	scope := []*lintTable{t}
+	for name, value := range set {
+		if t.knownColumns() && t.column(name) == nil {
+			l.issue(LintRuleUnknownColumn, LintError, "unknown column '%s' in table '%s'", name, t)
+		}
+		if expr, ok := value.(*parser.Expression); ok {
+			l.expr(scope, expr, nil)
+		}
+	}
+}
+
+// expr 检查表达式中的列引用和比较，aliases 为可以引用的 SELECT 别名
+func (l *linter) expr(scope []*lintTable, expr *parser.Expression, aliases map[string]bool) {
+	if expr == nil {
+		return
+	}
+	switch expr.Type {
+	case parser.ExprTypeColumn:
+		l.column(scope, expr.Column, aliases)
+		return
+	case parser.ExprTypeSubquery:
+		if expr.Subquery != nil {
+			l.lintSelect(expr.Subquery, scope)
+		}
+		return
+	case parser.ExprTypeOperator:
+		l.compare(scope, expr)
+	}
+	l.expr(scope, expr.Left, aliases)
+	l.expr(scope, expr.Right, aliases)
+	for i := range expr.Args {
+		l.expr(scope, &expr.Args[i], aliases)
+	}
+	// IN 列表和 BETWEEN 边界中的非常量项
+	if values, ok := expr.Value.([]interface{}); ok {
+		for _, v := range values {
+			if e, ok := v.(*parser.Expression); ok {
+				l.expr(scope, e, aliases)
+			}
+		}
+	}
+}
+
+// column 解析列引用 ref（col、t.col 或 db.t.col），不存在时报告 unknown_column。
+// 列可能属于结构未知的表时不报告，返回 nil
+func (l *linter) column(scope []*lintTable, ref string, aliases map[string]bool) (*lintTable, *domain.ColumnInfo) {
+	if strings.HasPrefix(ref, "@") {
+		return nil, nil
+	}
+	parts := strings.Split(ref, ".")
+	name := parts[len(parts)-1]
+	candidates := scope
+	if len(parts) > 1 {
+		qualifier := parts[len(parts)-2]
+		t := findTable(scope, qualifier)
+		if t == nil {
+			l.issue(LintRuleUnknownColumn, LintError, "unknown table '%s' in column '%s'", qualifier, ref)
+			return nil, nil
+		}
+		candidates = []*lintTable{t}
+	} else if aliases[strings.ToLower(name)] {
+		return nil, nil
+	}
+
+	for _, t := range candidates {
+		if col := t.column(name); col != nil {
+			return t, col
+		}
+	}
+	for _, t := range candidates {
+		if !t.knownColumns() {
+			return nil, nil
+		}
+	}
+	if len(candidates) == 1 {
+		l.issue(LintRuleUnknownColumn, LintError, "unknown column '%s' in table '%s'", name, candidates[0])
+	} else {
+		l.issue(LintRuleUnknownColumn, LintError, "unknown column '%s'", ref)
+	}
+	return nil, nil
+}
+
+// findTable 按别名或表名查找表，内层的表优先；没有匹配的表时返回派生表（如果有）
+func findTable(scope []*lintTable, qualifier string) *lintTable {
+	var derived *lintTable
+	for _, t := range scope {
+		if t.derived {
+			if derived == nil {
+				derived = t
+			}
+		} else if t.matches(qualifier) {
+			return t
+		}
+	}
+	return derived
+}
+
+// 比较运算符（解析器使用 TiDB 的运算符名）
+var lintComparisons = map[string]bool{
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true, "nulleq": true,
+	"IN": true, "NOT IN": true, "BETWEEN": true, "NOT BETWEEN": true,
+}
+
+// compare 检查比较两侧的类型：数值列与非数字字符串、字符串列与数字、
+// 日期时间列与无效的日期时间字符串、数值列与字符串列比较时报告 type_mismatch
+func (l *linter) compare(scope []*lintTable, expr *parser.Expression) {
+	if !lintComparisons[expr.Operator] || expr.Left == nil || expr.Right == nil {
+		return
+	}
+	left, right := expr.Left, expr.Right
+	if left.Type != parser.ExprTypeColumn {
+		left, right = right, left
+	}
+	if left.Type != parser.ExprTypeColumn {
+		return
+	}
+	_, col := l.resolveQuietly(scope, left.Column)
+	if col == nil {
+		return
+	}
+	family := typeFamily(col.Type)
+
+	switch right.Type {
+	case parser.ExprTypeColumn:
+		_, other := l.resolveQuietly(scope, right.Column)
+		if other == nil {
+			return
+		}
+		otherFamily := typeFamily(other.Type)
+		if (family == "numeric" && otherFamily == "string") || (family == "string" && otherFamily == "numeric") {
+			l.issue(LintRuleTypeMismatch, LintWarning, "'%s' (%s) is compared with '%s' (%s); the values are compared as numbers and indexes cannot be used",
+				left.Column, col.Type, right.Column, other.Type)
+		}
+	case parser.ExprTypeValue:
+		values, ok := right.Value.([]interface{})
+		if !ok {
+			values = []interface{}{right.Value}
+		}
+		for _, v := range values {
+			if message := literalMismatch(family, col.Type, v); message != "" {
+				l.issue(LintRuleTypeMismatch, LintWarning, "'%s' %s", left.Column, message)
+				return
+			}
+		}
+	}
+}
+
+// resolveQuietly 解析列引用，不报告问题（列引用本身由 expr 检查）
+func (l *linter) resolveQuietly(scope []*lintTable, ref string) (*lintTable, *domain.ColumnInfo) {
+	saved := len(l.report.Issues)
+	t, col := l.column(scope, ref, nil)
+	l.report.Issues = l.report.Issues[:saved]
+	return t, col
+}
+
+// typeFamily 返回列类型的类别：numeric、string、temporal，其他类型为空
+func typeFamily(columnType string) string {
+	t := strings.ToUpper(strings.TrimSpace(columnType))
+	if i := strings.IndexAny(t, "( "); i >= 0 {
+		t = t[:i]
+	}
+	switch t {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "DECIMAL", "NUMERIC",
+		"FLOAT", "DOUBLE", "REAL", "BIT", "BOOL", "BOOLEAN", "YEAR":
+		return "numeric"
+	case "CHAR", "VARCHAR", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET":
+		return "string"
+	case "DATE", "DATETIME", "TIMESTAMP", "TIME":
+		return "temporal"
+	}
+	return ""
+}
+
+// 日期时间字面量的格式
+var temporalLayouts = []string{
+	"2006-01-02", "2006-01-02 15:04:05", "2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05", "2006-01-02T15:04:05.999999999", time.RFC3339Nano, "15:04:05", "15:04:05.999999999",
+}
+
+// literalMismatch 返回列与常量 v 比较时的类型问题说明，没有问题时返回空字符串
+func literalMismatch(family, columnType string, v interface{}) string {
+	switch family {
+	case "numeric":
+		if s, ok := v.(string); ok {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+				return fmt.Sprintf("is %s but is compared with the string '%s', which converts to a number (usually 0)", columnType, s)
+			}
+		}
+	case "string":
+		switch v.(type) {
+		case int64, uint64, float64:
+			return fmt.Sprintf("is %s but is compared with the number %v; the values are compared as numbers, so unexpected rows can match and indexes cannot be used", columnType, v)
+		}
+	case "temporal":
+		if s, ok := v.(string); ok {
+			for _, layout := range temporalLayouts {
+				if _, err := time.Parse(layout, s); err == nil {
+					return ""
+				}
+			}
+			return fmt.Sprintf("is %s but is compared with '%s', which is not a valid %s value", columnType, s, strings.ToUpper(columnType))
+		}
+	}
+	return ""
+}
+
+// addAccess 按 WHERE 中与常量比较的条件估计对表 t 的访问方式，记录表的行数
+func (l *linter) addAccess(t *lintTable, where *parser.Expression, scope []*lintTable) {
+	access := LintAccessScan
+	if t.info != nil {
+		equal, ranged := make(map[string]bool), make(map[string]bool)
+		for _, cond := range conjuncts(where) {
+			if cond.Type != parser.ExprTypeOperator || cond.Left == nil || cond.Right == nil {
+				continue
+			}
+			column, value := cond.Left, cond.Right
+			if column.Type != parser.ExprTypeColumn {
+				column, value = value, column
+			}
+			if column.Type != parser.ExprTypeColumn || value.Type != parser.ExprTypeValue {
+				continue
+			}
+			owner, col := l.resolveQuietly(scope, column.Column)
+			if owner != t || col == nil {
+				continue
+			}
+			name := strings.ToLower(col.Name)
+			switch cond.Operator {
+			case "eq", "nulleq", "IN":
+				equal[name] = true
+			case "lt", "le", "gt", "ge", "BETWEEN":
+				ranged[name] = true
+			}
+		}
+		access = l.accessMethod(t, equal, ranged)
+	}
+
+	rows := int64(-1)
+	if t.ds == nil && t.info != nil {
+		rows = 0 // 批内创建的表
+	} else if counter, ok := t.ds.(domain.RowCountDataSource); ok {
+		if n, _, err := counter.RowCount(context.Background(), t.name, nil); err == nil {
+			rows = n
+		}
+	}
+	l.access = append(l.access, LintTableAccess{Table: t.String(), Access: access, Rows: rows})
+}
+
+// indexLister 能列出表上索引的数据源（内存数据源）
+type indexLister interface {
+	GetTableIndexes(tableName string) ([]*memory.IndexInfo, error)
+}
+
+// accessMethod 主键的所有列或某个唯一列等值时为 point，条件中有主键、唯一列或索引首列时为 index
+func (l *linter) accessMethod(t *lintTable, equal, ranged map[string]bool) string {
+	keyed := make(map[string]bool)
+	primary, primaryEqual := 0, 0
+	for _, col := range t.info.Columns {
+		name := strings.ToLower(col.Name)
+		if col.Unique && equal[name] {
+			return LintAccessPoint
+		}
+		if col.Primary {
+			primary++
+			if equal[name] {
+				primaryEqual++
+			}
+		}
+		if col.Primary || col.Unique {
+			keyed[name] = true
+		}
+	}
+	if primary > 0 && primaryEqual == primary {
+		return LintAccessPoint
+	}
+	if lister, ok := t.ds.(indexLister); ok {
+		if indexes, err := lister.GetTableIndexes(t.name); err == nil {
+			for _, idx := range indexes {
+				if len(idx.Columns) > 0 {
+					keyed[strings.ToLower(idx.Columns[0])] = true
+				}
+			}
+		}
+	}
+	for name := range keyed {
+		if equal[name] || ranged[name] {
+			return LintAccessIndex
+		}
+	}
+	return LintAccessScan
+}
+
+// conjuncts 把 WHERE 按 AND 拆分为条件列表
+func conjuncts(expr *parser.Expression) []*parser.Expression {
+	if expr == nil {
+		return nil
+	}
+	if expr.Type == parser.ExprTypeOperator && strings.EqualFold(expr.Operator, "and") {
+		return append(conjuncts(expr.Left), conjuncts(expr.Right)...)
+	}
+	return []*parser.Expression{expr}
+}
+
+// joinedInWhere 返回 WHERE 中是否有把表 t 与其他表的列相等比较的条件（FROM a, b WHERE a.id = b.a_id）
+func joinedInWhere(t *lintTable, scope []*lintTable, where *parser.Expression) bool {
+	for _, cond := range conjuncts(where) {
+		if cond.Operator != "eq" || cond.Left == nil || cond.Right == nil ||
+			cond.Left.Type != parser.ExprTypeColumn || cond.Right.Type != parser.ExprTypeColumn {
+			continue
+		}
+		left, right := columnTable(scope, cond.Left.Column), columnTable(scope, cond.Right.Column)
+		if (left == t) != (right == t) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnTable 返回列引用所属的表，无法确定时返回 nil
+func columnTable(scope []*lintTable, ref string) *lintTable {
+	parts := strings.Split(ref, ".")
+	if len(parts) > 1 {
+		return findTable(scope, parts[len(parts)-2])
+	}
+	for _, t := range scope {
+		if t.column(ref) != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// cost 按各表的访问方式和行数估计语句的成本等级
+func (l *linter) cost() string {
+	if l.cross {
+		return LintCostHigh
+	}
+	cost := LintCostLow
+	for _, a := range l.access {
+		if a.Access != LintAccessScan {
+			continue
+		}
+		switch {
+		case a.Rows > lintLargeTableRows:
+			return LintCostHigh
+		case a.Rows < 0 || a.Rows > lintSmallTableRows:
+			cost = LintCostMedium
+		}
+	}
+	return cost
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lintFixture = []string{
+	`CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(100) UNIQUE, name VARCHAR(50), age INT, created_at DATETIME)`,
+	`CREATE TABLE orders (id INT PRIMARY KEY, user_id INT, code VARCHAR(20), amount DECIMAL(10,2))`,
+	`CREATE INDEX idx_user ON orders (user_id)`,
+}
+
+func lintOne(t *testing.T, s *Session, sql string) *LintReport {
+	t.Helper()
+	reports, err := s.Lint(sql)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	return reports[0]
+}
+
+func lintRules(report *LintReport) []string {
+	rules := make([]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		rules[i] = issue.Rule
+	}
+	return rules
+}
+
+// TestLint_Syntax 测试语法错误报告行列位置
+func TestLint_Syntax(t *testing.T) {
+	s := newTestSession(t, lintFixture...)
+
+	report := lintOne(t, s, "SELECT id,\n  name FORM users")
+	require.Len(t, report.Issues, 1)
+	issue := report.Issues[0]
+	assert.Equal(t, LintRuleSyntax, issue.Rule)
+	assert.Equal(t, LintError, issue.Severity)
+	assert.Equal(t, 2, issue.Line)
+	assert.Positive(t, issue.Column)
+	assert.Contains(t, issue.Near, "users")
+	assert.True(t, report.HasErrors())
+	assert.Empty(t, report.Cost)
+
+	report = lintOne(t, s, "SELECT u.name, o.amount FROM users u JOIN orders o ON o.user_id = u.id WHERE u.id = 1 ORDER BY u.name")
+	assert.Empty(t, report.Issues)
+	assert.False(t, report.HasErrors())
+	assert.Equal(t, "SELECT", report.Type)
+}
+
+// TestLint_UnknownTablesAndColumns 测试对照表结构报告不存在的表和列
+func TestLint_UnknownTablesAndColumns(t *testing.T) {
+	s := newTestSession(t, lintFixture...)
+
+	for sql, rules := range map[string][]string{
+		"SELECT * FROM missing":                                                                         {LintRuleUnknownTable},
+		"SELECT nam FROM users":                                                                         {LintRuleUnknownColumn},
+		"SELECT name FROM users WHERE agee > 3":                                                         {LintRuleUnknownColumn},
+		"SELECT u.name FROM users u JOIN orders o ON o.uid = u.id":                                      {LintRuleUnknownColumn},
+		"SELECT x.name FROM users u":                                                                    {LintRuleUnknownColumn},
+		"SELECT name FROM users ORDER BY nme":                                                           {LintRuleUnknownColumn},
+		"INSERT INTO users (id, nick) VALUES (1, 'a')":                                                  {LintRuleUnknownColumn},
+		"UPDATE users SET nick = 'a' WHERE id = 1":                                                      {LintRuleUnknownColumn},
+		"DELETE FROM orders WHERE user_id IN (SELECT uid FROM users)":                                   {LintRuleUnknownColumn},
+		"DELETE FROM nothing WHERE id = 1":                                                              {LintRuleUnknownTable},
+		"SELECT name AS n FROM users ORDER BY n":                                                        {},
+		"SELECT COUNT(*) AS c, age FROM users GROUP BY age HAVING c > 1":                                {},
+		"SELECT id FROM orders WHERE user_id IN (SELECT id FROM users WHERE users.age > orders.amount)": {},
+		"SELECT x FROM (SELECT 1 AS x) t":                                                               {},
+		"SELECT @@version, NOW()":                                                                       {},
+	} {
+		assert.Equal(t, rules, lintRules(lintOne(t, s, sql)), sql)
+	}
+}
+
+// TestLint_TypeMismatch 测试比较两侧的类型不一致
+func TestLint_TypeMismatch(t *testing.T) {
+	s := newTestSession(t, lintFixture...)
+
+	for sql, mismatch := range map[string]bool{
+		"SELECT * FROM users WHERE age = 'abc'":                                               true,
+		"SELECT * FROM users WHERE age = '42'":                                                false,
+		"SELECT * FROM users WHERE name = 42":                                                 true,
+		"SELECT * FROM users WHERE 42 = name":                                                 true,
+		"SELECT * FROM users WHERE name IN ('a', 3)":                                          true,
+		"SELECT * FROM users WHERE created_at > 'yesterday'":                                  true,
+		"SELECT * FROM users WHERE created_at BETWEEN '2024-01-01' AND '2024-02-01 10:00:00'": false,
+		"SELECT * FROM users u JOIN orders o ON o.code = u.id":                                true,
+		"SELECT * FROM users u JOIN orders o ON o.user_id = u.id":                             false,
+		"SELECT * FROM users WHERE name LIKE 'a%'":                                            false,
+	} {
+		report := lintOne(t, s, sql)
+		if mismatch {
+			assert.Equal(t, []string{LintRuleTypeMismatch}, lintRules(report), sql)
+			assert.Equal(t, LintWarning, report.Issues[0].Severity)
+			assert.False(t, report.HasErrors())
+		} else {
+			assert.Empty(t, report.Issues, sql)
+		}
+	}
+}
+
+// TestLint_MissingWhere 测试没有 WHERE 的 UPDATE 和 DELETE
+func TestLint_MissingWhere(t *testing.T) {
+	s := newTestSession(t, lintFixture...)
+
+	for _, sql := range []string{"UPDATE users SET age = 1", "DELETE FROM orders"} {
+		report := lintOne(t, s, sql)
+		assert.Equal(t, []string{LintRuleMissingWhere}, lintRules(report), sql)
+		assert.Equal(t, LintWarning, report.Issues[0].Severity)
+	}
+	assert.Empty(t, lintOne(t, s, "DELETE FROM orders WHERE id = 3").Issues)
+}
+
+// TestLint_Cost 测试按访问方式和行数估计成本等级
+func TestLint_Cost(t *testing.T) {
+	s := newTestSession(t, lintFixture...)
+
+	report := lintOne(t, s, "SELECT * FROM users WHERE id = 1")
+	assert.Equal(t, []LintTableAccess{{Table: "users", Access: LintAccessPoint, Rows: 0}}, report.Tables)
+	assert.Equal(t, LintCostLow, report.Cost)
+	assert.Equal(t, LintAccessPoint, lintOne(t, s, "SELECT * FROM users WHERE email = 'a@b.c'").Tables[0].Access)
+	assert.Equal(t, LintAccessIndex, lintOne(t, s, "SELECT * FROM orders WHERE user_id = 7").Tables[0].Access)
+	assert.Equal(t, LintAccessIndex, lintOne(t, s, "UPDATE users SET age = 1 WHERE id > 10").Tables[0].Access)
+	assert.Equal(t, LintAccessScan, lintOne(t, s, "SELECT * FROM users WHERE name = 'x'").Tables[0].Access)
+	assert.Equal(t, LintCostLow, lintOne(t, s, "INSERT INTO users (id) VALUES (1)").Cost)
+
+	// 扫描的成本随表的行数上升
+	values := make([]string, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		values = append(values, fmt.Sprintf("(%d, 'n%d')", i, i))
+	}
+	_, err := s.Execute("INSERT INTO users (id, name) VALUES " + strings.Join(values, ", "))
+	require.NoError(t, err)
+	report = lintOne(t, s, "SELECT * FROM users WHERE name = 'x'")
+	assert.Equal(t, int64(2000), report.Tables[0].Rows)
+	assert.Equal(t, LintCostMedium, report.Cost)
+	assert.Equal(t, LintCostLow, lintOne(t, s, "SELECT * FROM users WHERE id = 5").Cost)
+
+	// 没有连接条件的 JOIN
+	assert.Equal(t, LintCostHigh, lintOne(t, s, "SELECT * FROM users, orders").Cost)
+	assert.Equal(t, LintCostMedium, lintOne(t, s, "SELECT * FROM users, orders WHERE users.id = orders.user_id").Cost)
+
+	// 其他语句不估计成本
+	report = lintOne(t, s, "SHOW TABLES")
+	assert.Empty(t, report.Cost)
+	assert.Empty(t, report.Issues)
+}
+
+// TestLint_Batch 测试批内的 USE 和 CREATE TABLE 对后续语句生效，但不执行
+func TestLint_Batch(t *testing.T) {
+	s := newTestSession(t, lintFixture...)
+
+	reports, err := s.Lint(`CREATE TABLE audit (id INT PRIMARY KEY, note TEXT);
+		INSERT INTO audit (id, note) VALUES (1, 'x');
+		SELECT nope FROM audit;
+		USE other;
+		SELECT * FROM users`)
+	require.NoError(t, err)
+	require.Len(t, reports, 5)
+	assert.Empty(t, reports[0].Issues)
+	assert.Empty(t, reports[1].Issues)
+	assert.Equal(t, []string{LintRuleUnknownColumn}, lintRules(reports[2]))
+	assert.Empty(t, reports[3].Issues)
+	// other 不是已注册的数据源，表结构未知时不报告
+	assert.Empty(t, reports[4].Issues)
+
+	_, err = s.TableInfo("audit")
+	assert.Error(t, err)
+}
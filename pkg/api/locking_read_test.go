@@ -0,0 +1,84 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSelectForUpdate_LockWaitTimeout 测试 FOR UPDATE 加锁及 innodb_lock_wait_timeout
+func TestSelectForUpdate_LockWaitTimeout(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s1, s2 := db.Session(), db.Session()
+	defer s1.Close()
+	defer s2.Close()
+
+	tx1, err := s1.Begin()
+	require.NoError(t, err)
+	defer tx1.Close()
+	tx2, err := s2.Begin()
+	require.NoError(t, err)
+	defer tx2.Close()
+
+	q, err := tx1.Query(`SELECT * FROM accounts WHERE id = 1 FOR UPDATE`)
+	require.NoError(t, err)
+	q.Close()
+
+	_, err = tx2.Query(`SELECT * FROM accounts WHERE id = 1 FOR UPDATE NOWAIT`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrLockWaitTimeout, code)
+
+	_, err = s2.Execute(`SET innodb_lock_wait_timeout = 0.05`)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = tx2.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	require.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	code, _ = mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrLockWaitTimeout, code)
+	assert.True(t, tx2.IsActive(), "lock wait timeout only fails the statement")
+
+	// LOCK IN SHARE MODE 与其他行的写入互不影响
+	q, err = tx2.Query(`SELECT * FROM accounts WHERE id = 2 LOCK IN SHARE MODE`)
+	require.NoError(t, err)
+	q.Close()
+}
+
+// TestSelectForUpdate_Deadlock 测试死锁检测返回 1213 并回滚牺牲者事务
+func TestSelectForUpdate_Deadlock(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s1, s2 := db.Session(), db.Session()
+	defer s1.Close()
+	defer s2.Close()
+
+	tx1, err := s1.Begin()
+	require.NoError(t, err)
+	defer tx1.Close()
+	tx2, err := s2.Begin()
+	require.NoError(t, err)
+
+	_, err = tx1.Execute(`UPDATE accounts SET balance = 90 WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = tx2.Execute(`UPDATE accounts SET balance = 90 WHERE id = 2`)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tx1.Execute(`UPDATE accounts SET balance = 80 WHERE id = 2`)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = tx2.Query(`SELECT * FROM accounts WHERE id = 1 FOR UPDATE`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrLockDeadlock, code)
+	assert.False(t, tx2.IsActive())
+
+	require.NoError(t, <-done)
+	require.NoError(t, tx1.Commit())
+}
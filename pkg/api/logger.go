@@ -5,6 +5,8 @@ import (
 	"io"
 	"os"
 	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 )
 
 // LogLevel 日志级别
@@ -128,6 +130,66 @@ func (l *DefaultLogger) log(level LogLevel, format string, args ...interface{})
 	fmt.Fprintf(l.output, "[%s] %s\n", level.String(), message)
 }
 
+// StructuredLogger 把 Logger 接口接到 logging 包的结构化日志，格式化后的消息写入 msg 字段；
+// 级别先按 SetLevel 过滤，再按 logging 的全局或模块级别过滤
+type StructuredLogger struct {
+	level  LogLevel
+	mu     sync.Mutex
+	logger *logging.Logger
+}
+
+// NewStructuredLogger 创建写入全局结构化日志、模块名为 module 的 Logger
+func NewStructuredLogger(level LogLevel, module string) *StructuredLogger {
+	return NewStructuredLoggerWith(level, logging.Module(module))
+}
+
+// NewStructuredLoggerWith 创建写入指定结构化日志的 Logger
+func NewStructuredLoggerWith(level LogLevel, logger *logging.Logger) *StructuredLogger {
+	return &StructuredLogger{level: level, logger: logger}
+}
+
+// SetLevel 设置日志级别
+func (l *StructuredLogger) SetLevel(level LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// GetLevel 获取日志级别
+func (l *StructuredLogger) GetLevel() LogLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// Debug 输出 DEBUG 级别日志
+func (l *StructuredLogger) Debug(format string, args ...interface{}) {
+	if l.GetLevel() >= LogDebug {
+		l.logger.Debugf(format, args...)
+	}
+}
+
+// Info 输出 INFO 级别日志
+func (l *StructuredLogger) Info(format string, args ...interface{}) {
+	if l.GetLevel() >= LogInfo {
+		l.logger.Infof(format, args...)
+	}
+}
+
+// Warn 输出 WARN 级别日志
+func (l *StructuredLogger) Warn(format string, args ...interface{}) {
+	if l.GetLevel() >= LogWarn {
+		l.logger.Warnf(format, args...)
+	}
+}
+
+// Error 输出 ERROR 级别日志
+func (l *StructuredLogger) Error(format string, args ...interface{}) {
+	if l.GetLevel() >= LogError {
+		l.logger.Errorf(format, args...)
+	}
+}
+
 // NoOpLogger 空日志实现（用于禁用日志）
 type NoOpLogger struct{}
 
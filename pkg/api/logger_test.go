@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -111,6 +112,18 @@ func TestDefaultLogger_NoArgs(t *testing.T) {
 	assert.Contains(t, output, "simple message")
 }
 
+func TestStructuredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStructuredLoggerWith(LogWarn, logging.NewWithSinks(logging.LevelDebug, logging.FormatJSON, logging.NewWriterSink(&buf)).Module("api"))
+
+	logger.Info("hidden")
+	logger.Warn("slow query: %dms", 1500)
+
+	output := buf.String()
+	assert.NotContains(t, output, "hidden")
+	assert.Contains(t, output, `"level":"WARN","module":"api","msg":"slow query: 1500ms"`)
+}
+
 func TestNoOpLogger(t *testing.T) {
 	logger := NewNoOpLogger()
 
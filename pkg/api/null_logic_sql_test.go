@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var nullLogicFixture = []string{
+	`CREATE TABLE l (id INT, v INT)`,
+	`INSERT INTO l (id, v) VALUES (1, 1), (2, 2), (3, NULL)`,
+}
+
+func TestNullLogic_WhereComparisons(t *testing.T) {
+	sess := newTestSession(t, nullLogicFixture...)
+
+	tests := []struct {
+		sql      string
+		expected []interface{}
+	}{
+		// 与 NULL 的比较结果为 UNKNOWN，不返回任何行
+		{"SELECT id FROM l WHERE v = NULL", []interface{}{}},
+		{"SELECT id FROM l WHERE v <> 1", []interface{}{int64(2)}},
+		{"SELECT id FROM l WHERE v > 0", []interface{}{int64(1), int64(2)}},
+		{"SELECT id FROM l WHERE v IS NULL", []interface{}{int64(3)}},
+		{"SELECT id FROM l WHERE v IS NOT NULL", []interface{}{int64(1), int64(2)}},
+		// NULL 安全等于
+		{"SELECT id FROM l WHERE v <=> NULL", []interface{}{int64(3)}},
+		{"SELECT id FROM l WHERE v <=> 2", []interface{}{int64(2)}},
+		// IN 列表中的 NULL
+		{"SELECT id FROM l WHERE v IN (1, NULL)", []interface{}{int64(1)}},
+		{"SELECT id FROM l WHERE v NOT IN (1, NULL)", []interface{}{}},
+		{"SELECT id FROM l WHERE v NOT IN (1)", []interface{}{int64(2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, rows := queryRows(t, sess, tt.sql)
+			assert.ElementsMatch(t, tt.expected, columnValues(rows, "id"))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOptimizeTable_SQL 测试 OPTIMIZE TABLE 在线整理普通表和分区表
+func TestOptimizeTable_SQL(t *testing.T) {
+	ds, session := newPartitionTestSession(t)
+
+	_, err := session.Execute(`CREATE TABLE items (id INT PRIMARY KEY, name VARCHAR(20))`)
+	require.NoError(t, err)
+	_, err = session.Execute(`CREATE TABLE parts (id INT PRIMARY KEY) PARTITION BY HASH (id) PARTITIONS 2`)
+	require.NoError(t, err)
+	for _, sql := range []string{
+		`INSERT INTO items VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd')`,
+		`INSERT INTO parts VALUES (1), (2), (3), (4), (5)`,
+		`DELETE FROM items WHERE id IN (2, 3)`,
+		`DELETE FROM parts WHERE id > 3`,
+	} {
+		_, err = session.Execute(sql)
+		require.NoError(t, err)
+	}
+
+	result, err := session.Execute(`OPTIMIZE TABLE items, parts`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.RowsAffected)
+
+	status, ok := ds.CompactionStatus("items")
+	require.True(t, ok)
+	assert.Equal(t, domain.CompactionStateCompleted, status.State)
+	assert.Equal(t, int64(2), status.RowsProcessed)
+
+	status, ok = ds.CompactionStatus("parts")
+	require.True(t, ok)
+	assert.Equal(t, int64(3), status.RowsProcessed)
+
+	assert.Equal(t, []int64{1, 4}, queryIDs(t, session, `SELECT id FROM items ORDER BY id`))
+	assert.Equal(t, []int64{1, 2, 3}, queryIDs(t, session, `SELECT id FROM parts ORDER BY id`))
+
+	// 通过 Query 执行时返回 MySQL 格式的结果集
+	rows, err := session.QueryAll(`OPTIMIZE TABLE items`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "items", rows[0]["Table"])
+	assert.Equal(t, "optimize", rows[0]["Op"])
+	assert.Equal(t, "status", rows[0]["Msg_type"])
+
+	_, err = session.Execute(`OPTIMIZE TABLE missing`)
+	assert.Error(t, err)
+}
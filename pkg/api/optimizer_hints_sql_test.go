@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOptimizerHintsSession 建表后直接通过数据源写入 rows 行，避免逐行执行 INSERT
+func newOptimizerHintsSession(t *testing.T, rows int) *Session {
+	sess := newTestSession(t, `CREATE TABLE h1 (id INT, v INT)`, `CREATE TABLE h2 (id INT, v INT)`)
+	ds := testDataSource(t, sess.db)
+
+	// 内存数据源的索引不会回填已有数据，因此先建索引再插入
+	require.NoError(t, ds.CreateIndex("h1", "v", "btree", false))
+
+	data := make([]domain.Row, 0, rows)
+	for i := 1; i <= rows; i++ {
+		data = append(data, domain.Row{"id": int64(i), "v": int64(i % 10)})
+	}
+	ctx := context.Background()
+	_, err := ds.Insert(ctx, "h1", data, nil)
+	require.NoError(t, err)
+	_, err = ds.Insert(ctx, "h2", data[:10], nil)
+	require.NoError(t, err)
+	return sess
+}
+
+func TestOptimizerHints_Parallel(t *testing.T) {
+	sess := newOptimizerHintsSession(t, 2500)
+
+	_, rows := queryRows(t, sess, "SELECT /*+ PARALLEL(3) */ id FROM h1")
+	ids := columnValues(rows, "id")
+	require.Len(t, ids, 2500)
+	// 并行分页读取后仍保持表的原始顺序
+	for i, id := range ids {
+		assert.Equal(t, int64(i+1), id)
+	}
+}
+
+func TestOptimizerHints_IndexAndJoinOrder(t *testing.T) {
+	sess := newOptimizerHintsSession(t, 100)
+
+	tests := []struct {
+		sql      string
+		expected int
+	}{
+		{"SELECT id FROM h1 FORCE INDEX (idx_h1_v) WHERE v = 3", 10},
+		{"SELECT /*+ INDEX(h1 v) */ id FROM h1 WHERE v = 3 AND id > 50", 5},
+		{"SELECT id FROM h1 IGNORE INDEX (v) WHERE v = 3", 10},
+		// 未知 hint 只产生警告，不影响查询
+		{"SELECT /*+ NOT_A_REAL_HINT(h1) */ id FROM h1 WHERE v = 3", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, rows := queryRows(t, sess, tt.sql)
+			assert.Len(t, rows, tt.expected)
+		})
+	}
+}
+
+func TestOptimizerHints_StraightJoin(t *testing.T) {
+	sess := newOptimizerHintsSession(t, 100)
+
+	// 固定连接顺序不改变结果
+	_, expected := queryRows(t, sess, "SELECT h2.id, h1.v FROM h2 JOIN h1 ON h1.id = h2.id")
+	for _, sql := range []string{
+		"SELECT STRAIGHT_JOIN h2.id, h1.v FROM h2 JOIN h1 ON h1.id = h2.id",
+		"SELECT h2.id, h1.v FROM h2 STRAIGHT_JOIN h1 ON h1.id = h2.id",
+		"SELECT /*+ JOIN_FIXED_ORDER MAX_EXECUTION_TIME(10000) */ h2.id, h1.v FROM h2 JOIN h1 ON h1.id = h2.id",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			_, rows := queryRows(t, sess, sql)
+			assert.ElementsMatch(t, expected, rows)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/dataexport"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// outfileBufferSize 写出导出文件时的缓冲区大小
+const outfileBufferSize = 256 * 1024
+
+// resolveOutfile 检查 INTO OUTFILE、BACKUP 等语句（statement 为语句名）的目标路径位于 OutfileDirs
+// 允许的目录中（类似 MySQL 的 secure_file_priv），返回解析符号链接后的绝对路径；未配置允许的目录时禁止导出到文件
+func (db *DB) resolveOutfile(statement, path string) (string, error) {
+	if len(db.config.OutfileDirs) == 0 {
+		return "", NewError(ErrCodeReadOnly,
+			"The server is running without outfile_dirs so it cannot execute "+statement, nil)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", WrapError(err, ErrCodeInvalidParam, fmt.Sprintf("invalid file path '%s'", path))
+	}
+	// 目录中的符号链接先解析，避免借助链接写到允许的目录之外
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return "", WrapError(err, ErrCodeInvalidParam, fmt.Sprintf("invalid file path '%s'", path))
+	}
+	target := filepath.Join(dir, filepath.Base(abs))
+	for _, allowed := range db.config.OutfileDirs {
+		root, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		rel, err := filepath.Rel(root, target)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return target, nil
+		}
+	}
+	return "", NewError(ErrCodeReadOnly,
+		fmt.Sprintf("The server is running with outfile_dirs so it cannot write to '%s'", path), nil)
+}
+
+// executeSelectInto 执行 SELECT ... INTO OUTFILE：查询结果逐行写入新文件，文件已存在时报错，
+// 写出失败时删除不完整的文件
+func (s *Session) executeSelectInto(ctx context.Context, stmt *parser.SelectIntoStatement) (*Result, error) {
+	opts, err := dataexport.ParseOptions(stmt.Format, stmt.Options)
+	if err != nil {
+		return nil, NewError(ErrCodeInvalidParam, "INTO OUTFILE: "+err.Error(), nil)
+	}
+	path, err := s.db.resolveOutfile("SELECT ... INTO OUTFILE", stmt.File)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.coreSession.ExecuteQuery(ctx, stmt.Query)
+	if err != nil {
+		if err.Error() == "query execution timed out" || err.Error() == "query was killed" {
+			return nil, WrapError(err, ErrCodeTimeout, "failed to execute query")
+		}
+		return nil, WrapError(err, ErrCodeSyntax, "failed to execute query")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf("File '%s' already exists", stmt.File), nil)
+		}
+		return nil, WrapError(err, ErrCodeInternal, "failed to create file")
+	}
+	buffered := bufio.NewWriterSize(file, outfileBufferSize)
+	n, err := writeOutfileRows(ctx, buffered, result, opts)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, WrapError(err, ErrCodeInternal, fmt.Sprintf("failed to write '%s'", stmt.File))
+	}
+
+	res := NewResult(n, 0, nil)
+	res.Warnings = result.Warnings
+	return res, nil
+}
+
+// writeOutfileRows 遍历结果集逐行写出，已写出的行立即释放，返回写出的行数
+func writeOutfileRows(ctx context.Context, w io.Writer, result *domain.QueryResult, opts dataexport.Options) (int64, error) {
+	writer, err := dataexport.NewWriter(w, result.Columns, opts)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for i, row := range result.Rows {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if err := writer.WriteRow(row); err != nil {
+			return n, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		result.Rows[i] = nil
+		n++
+	}
+	result.Rows = nil
+	return n, writer.Close()
+}
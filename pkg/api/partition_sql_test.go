@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPartitionTestSession(t *testing.T) (*memory.MVCCDataSource, *Session) {
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	t.Cleanup(func() { ds.Close(context.Background()) })
+
+	db, _ := NewDB(nil)
+	require.NoError(t, db.RegisterDataSource("test", ds))
+	require.NoError(t, db.SetDefaultDataSource("test"))
+
+	session := db.Session()
+	t.Cleanup(func() { session.Close() })
+	return ds, session
+}
+
+func queryIDs(t *testing.T, session *Session, sql string) []int64 {
+	query, err := session.Query(sql)
+	require.NoError(t, err)
+	defer query.Close()
+
+	var ids []int64
+	for query.Next() {
+		var id int64
+		require.NoError(t, query.Scan(&id))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// TestPartitionedTable_Range 测试 RANGE 分区表的建表、路由、裁剪和分区管理
+func TestPartitionedTable_Range(t *testing.T) {
+	ds, session := newPartitionTestSession(t)
+	ctx := context.Background()
+
+	_, err := session.Execute(`
+		CREATE TABLE orders (
+			id INT PRIMARY KEY,
+			amount INT
+		)
+		PARTITION BY RANGE (id) (
+			PARTITION p0 VALUES LESS THAN (10),
+			PARTITION p1 VALUES LESS THAN (20),
+			PARTITION p2 VALUES LESS THAN (30)
+		)`)
+	require.NoError(t, err)
+
+	info, err := ds.GetTableInfo(ctx, "orders")
+	require.NoError(t, err)
+	require.NotNil(t, info.Partition)
+	assert.Equal(t, "RANGE", info.Partition.Type)
+	assert.Equal(t, []string{"p0", "p1", "p2"}, info.Partition.PartitionNames())
+
+	// 分区子表对外不可见
+	tables, err := ds.GetTables(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders"}, tables)
+
+	_, err = session.Execute(`INSERT INTO orders (id, amount) VALUES (1, 100), (15, 150), (25, 250), (5, 50)`)
+	require.NoError(t, err)
+
+	// 超出所有分区的值被拒绝
+	_, err = session.Execute(`INSERT INTO orders (id, amount) VALUES (30, 300)`)
+	assert.Error(t, err)
+
+	assert.Equal(t, []int64{1, 5, 15, 25}, queryIDs(t, session, `SELECT id FROM orders ORDER BY id`))
+	assert.Equal(t, []int64{15}, queryIDs(t, session, `SELECT id FROM orders WHERE id = 15`))
+	assert.Equal(t, []int64{15, 25}, queryIDs(t, session, `SELECT id FROM orders WHERE id >= 12 ORDER BY id`))
+	assert.Equal(t, []int64{1, 5}, queryIDs(t, session, `SELECT id FROM orders WHERE id < 10 ORDER BY id`))
+	assert.Equal(t, []int64{5, 25}, queryIDs(t, session, `SELECT id FROM orders WHERE id IN (5, 25) ORDER BY id`))
+
+	// 更新分区键会把行移动到新分区
+	_, err = session.Execute(`UPDATE orders SET id = 22 WHERE id = 1`)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{15, 22, 25}, queryIDs(t, session, `SELECT id FROM orders WHERE id >= 10 ORDER BY id`))
+
+	_, err = session.Execute(`UPDATE orders SET amount = 0 WHERE id < 10`)
+	require.NoError(t, err)
+
+	_, err = session.Execute(`DELETE FROM orders WHERE id = 15`)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 22, 25}, queryIDs(t, session, `SELECT id FROM orders ORDER BY id`))
+
+	t.Run("add partition", func(t *testing.T) {
+		_, err := session.Execute(`ALTER TABLE orders ADD PARTITION (PARTITION p3 VALUES LESS THAN MAXVALUE)`)
+		require.NoError(t, err)
+
+		_, err = session.Execute(`INSERT INTO orders (id, amount) VALUES (100, 1000)`)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{100}, queryIDs(t, session, `SELECT id FROM orders WHERE id > 50`))
+
+		// MAXVALUE 之后不能再添加分区
+		_, err = session.Execute(`ALTER TABLE orders ADD PARTITION (PARTITION p4 VALUES LESS THAN (200))`)
+		assert.Error(t, err)
+	})
+
+	t.Run("drop partition", func(t *testing.T) {
+		_, err := session.Execute(`ALTER TABLE orders DROP PARTITION p2`)
+		require.NoError(t, err)
+
+		info, err := ds.GetTableInfo(ctx, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"p0", "p1", "p3"}, info.Partition.PartitionNames())
+
+		// p2 中的数据随分区一起删除，原范围由下一个分区接管
+		assert.Equal(t, []int64{5, 100}, queryIDs(t, session, `SELECT id FROM orders ORDER BY id`))
+		_, err = session.Execute(`INSERT INTO orders (id, amount) VALUES (25, 250)`)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{25, 100}, queryIDs(t, session, `SELECT id FROM orders WHERE id >= 20 ORDER BY id`))
+
+		_, err = session.Execute(`ALTER TABLE orders DROP PARTITION p9`)
+		assert.Error(t, err)
+	})
+
+	t.Run("drop table", func(t *testing.T) {
+		_, err := session.Execute(`DROP TABLE orders`)
+		require.NoError(t, err)
+
+		tables, err := ds.GetAllTables(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, tables)
+	})
+}
+
+// TestPartitionedTable_Hash 测试 HASH 分区表及 ADD PARTITION 后的数据重分布
+func TestPartitionedTable_Hash(t *testing.T) {
+	ds, session := newPartitionTestSession(t)
+	ctx := context.Background()
+
+	_, err := session.Execute(`
+		CREATE TABLE events (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			name VARCHAR(20)
+		)
+		PARTITION BY HASH (id) PARTITIONS 3`)
+	require.NoError(t, err)
+
+	info, err := ds.GetTableInfo(ctx, "events")
+	require.NoError(t, err)
+	assert.Equal(t, "HASH", info.Partition.Type)
+	assert.Equal(t, []string{"p0", "p1", "p2"}, info.Partition.PartitionNames())
+
+	for i := 0; i < 6; i++ {
+		_, err = session.Execute(`INSERT INTO events (name) VALUES ('e')`)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6}, queryIDs(t, session, `SELECT id FROM events ORDER BY id`))
+	assert.Equal(t, []int64{4}, queryIDs(t, session, `SELECT id FROM events WHERE id = 4`))
+
+	_, err = session.Execute(`ALTER TABLE events ADD PARTITION PARTITIONS 2`)
+	require.NoError(t, err)
+
+	info, err = ds.GetTableInfo(ctx, "events")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p0", "p1", "p2", "p3", "p4"}, info.Partition.PartitionNames())
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6}, queryIDs(t, session, `SELECT id FROM events ORDER BY id`))
+	assert.Equal(t, []int64{4}, queryIDs(t, session, `SELECT id FROM events WHERE id = 4`))
+
+	// HASH 分区不支持 DROP PARTITION
+	_, err = session.Execute(`ALTER TABLE events DROP PARTITION p0`)
+	assert.Error(t, err)
+}
+
+// TestPartitionedTable_Validation 测试非法的分区定义
+func TestPartitionedTable_Validation(t *testing.T) {
+	_, session := newPartitionTestSession(t)
+
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{"unknown column", `CREATE TABLE t1 (id INT) PARTITION BY HASH (x) PARTITIONS 2`},
+		{"non increasing bounds", `CREATE TABLE t2 (id INT) PARTITION BY RANGE (id) (
+			PARTITION p0 VALUES LESS THAN (10), PARTITION p1 VALUES LESS THAN (5))`},
+		{"primary key without partition column", `CREATE TABLE t3 (id INT PRIMARY KEY, k INT) PARTITION BY HASH (k) PARTITIONS 2`},
+		{"expression partitioning", `CREATE TABLE t4 (id INT) PARTITION BY HASH (id + 1) PARTITIONS 2`},
+		{"list partitioning", `CREATE TABLE t5 (id INT) PARTITION BY LIST (id) (PARTITION p0 VALUES IN (1, 2))`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := session.Execute(tt.sql)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := session.Execute(`ALTER TABLE missing ADD PARTITION (PARTITION p0 VALUES LESS THAN (10))`)
+	assert.Error(t, err)
+}
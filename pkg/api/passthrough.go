@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// SetPassthroughDataSources sets the data sources on which the session may run native
+// PASSTHROUGH('ds', 'query') queries; "*" allows every data source, nil denies all.
+// Passthrough queries bypass the SQL layer (ACL, write policies, column encryption),
+// so the permission is off by default.
+func (s *Session) SetPassthroughDataSources(names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passthrough = append([]string(nil), names...)
+}
+
+// canPassthrough 判断会话是否可以在数据源上执行原生查询
+func (s *Session) canPassthrough(dsName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, name := range s.passthrough {
+		if name == "*" || strings.EqualFold(name, dsName) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryPassthrough 执行 FROM 中含有 PASSTHROUGH('ds', 'native query') 的查询：原生查询原样发送给数据源，
+// 结果集按返回的列（或按值推断的类型）写入临时的内存数据源，再在其上执行外层查询。
+// 不含 PASSTHROUGH 的语句返回 false
+func (s *Session) queryPassthrough(boundSQL string) (*Query, bool, error) {
+	if s.coreSession == nil {
+		return nil, false, nil
+	}
+	pq, err := parser.ParsePassthrough(boundSQL)
+	if err != nil {
+		return nil, true, WrapError(err, ErrCodeSyntax, "failed to parse PASSTHROUGH")
+	}
+	if pq == nil {
+		return nil, false, nil
+	}
+	parseResult, err := s.coreSession.GetAdapter().Parse(pq.SQL)
+	if err != nil {
+		return nil, true, WrapError(err, ErrCodeSyntax, "failed to parse SQL")
+	}
+	if !parseResult.Success {
+		return nil, true, NewError(ErrCodeSyntax, "SQL parse error: "+parseResult.Error, nil)
+	}
+	if parseResult.Statement.Select == nil {
+		return nil, true, NewError(ErrCodeNotSupported, "PASSTHROUGH can only be used in the FROM clause of a SELECT", nil)
+	}
+	for _, table := range pq.Tables {
+		if !s.canPassthrough(table.DataSource) {
+			return nil, true, mysqlerrors.New(mysqlerrors.ErrSpecificAccessDenied,
+				"Access denied; you need the PASSTHROUGH privilege on data source '%s' for this operation", table.DataSource)
+		}
+	}
+
+	ctx := s.executionContext()
+	if timeout := s.coreSession.GetQueryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	results := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     parser.PassthroughTableAlias,
+		Writable: true,
+	})
+	if err := results.Connect(ctx); err != nil {
+		return nil, true, WrapError(err, ErrCodeInternal, "failed to prepare PASSTHROUGH result")
+	}
+	defer results.Close(ctx)
+
+	for _, table := range pq.Tables {
+		s.logger.Debug("Passthrough to %s: %s", table.DataSource, table.Query)
+		ds, err := s.db.GetDataSource(table.DataSource)
+		if err != nil {
+			return nil, true, err
+		}
+		native, err := ds.Execute(ctx, table.Query)
+		if err != nil {
+			return nil, true, WrapError(err, ErrCodeNotSupported, "PASSTHROUGH query on data source '"+table.DataSource+"' failed")
+		}
+		if native != nil {
+			domain.RecordRowsExamined(ctx, native.Rows)
+		}
+		if err := materializePassthrough(ctx, results, table.Table, native); err != nil {
+			return nil, true, WrapError(err, ErrCodeInternal, "failed to materialize PASSTHROUGH result")
+		}
+	}
+
+	result, err := optimizer.NewOptimizedExecutor(results, true).ExecuteSelect(ctx, parseResult.Statement.Select)
+	if err != nil {
+		return nil, true, WrapError(err, ErrCodeSyntax, "failed to execute query")
+	}
+	result, err = applyResultLimits(result, s.ResultLimits())
+	if err != nil {
+		return nil, true, err
+	}
+	return NewQuery(s, result, boundSQL, nil), true, nil
+}
+
+// materializePassthrough 把原生查询的结果集写入 ds 的 table 表，所有列可为空，
+// 数据源没有给出列类型时按值推断
+func materializePassthrough(ctx context.Context, ds domain.DataSource, table string, native *domain.QueryResult) error {
+	var rows []domain.Row
+	var columns []domain.ColumnInfo
+	if native != nil {
+		rows = native.Rows
+		for _, col := range native.Columns {
+			columns = append(columns, domain.ColumnInfo{Name: col.Name, Type: col.Type, Nullable: true})
+		}
+	}
+	if len(columns) == 0 {
+		// 数据源只返回了行：按列名排序，保证列的顺序确定
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			for name := range row {
+				if !seen[name] {
+					seen[name] = true
+					columns = append(columns, domain.ColumnInfo{Name: name, Nullable: true})
+				}
+			}
+		}
+		sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	}
+	for i := range columns {
+		if columns[i].Type == "" {
+			columns[i].Type = passthroughColumnType(rows, columns[i].Name)
+		}
+	}
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{Name: table, Columns: columns}); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := ds.Insert(ctx, table, rows, nil)
+	return err
+}
+
+// passthroughColumnType 按列中非 NULL 的值推断类型，类型不一致时为 TEXT
+func passthroughColumnType(rows []domain.Row, name string) string {
+	colType := ""
+	for _, row := range rows {
+		var t string
+		switch row[name].(type) {
+		case nil:
+			continue
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			t = "BIGINT"
+		case float32, float64:
+			t = "DOUBLE"
+		case bool:
+			t = "BOOLEAN"
+		case time.Time:
+			t = "DATETIME"
+		case []byte:
+			t = "BLOB"
+		case string:
+			t = "TEXT"
+		default:
+			t = "JSON"
+		}
+		switch {
+		case colType == "":
+			colType = t
+		case colType == "BIGINT" && t == "DOUBLE", colType == "DOUBLE" && t == "BIGINT":
+			colType = "DOUBLE"
+		case colType != t:
+			return "TEXT"
+		}
+	}
+	if colType == "" {
+		return "TEXT"
+	}
+	return colType
+}
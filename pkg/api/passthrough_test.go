@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nativeDataSource 把原生查询交给 execute 处理的测试数据源
+type nativeDataSource struct {
+	*memory.MVCCDataSource
+	queries []string
+	execute func(query string) *domain.QueryResult
+}
+
+func (ds *nativeDataSource) Execute(ctx context.Context, query string) (*domain.QueryResult, error) {
+	ds.queries = append(ds.queries, query)
+	return ds.execute(query), nil
+}
+
+func newPassthroughTestSession(t *testing.T) (*Session, *nativeDataSource) {
+	s := newTestSession(t, usersFixture...)
+	native := &nativeDataSource{
+		MVCCDataSource: memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "my_es"}),
+		execute: func(query string) *domain.QueryResult {
+			// 只返回行、不带列信息，列类型按值推断
+			return &domain.QueryResult{Rows: []domain.Row{
+				{"title": "go", "score": int64(3), "ratio": 0.5},
+				{"title": "sql", "score": int64(7), "ratio": int64(1), "tags": []interface{}{"db"}},
+			}}
+		},
+	}
+	require.NoError(t, native.Connect(context.Background()))
+	require.NoError(t, s.db.RegisterDataSource("my_es", native))
+	return s, native
+}
+
+// TestPassthrough_Query 测试原生查询原样发送给数据源，结果集可以在外层查询中过滤、排序和投影
+func TestPassthrough_Query(t *testing.T) {
+	s, native := newPassthroughTestSession(t)
+	s.SetPassthroughDataSources([]string{"my_es"})
+
+	rows, err := s.QueryAll(`SELECT title, score AS s FROM PASSTHROUGH('my_es', '{"query":{"match_all":{}}}') ` +
+		`WHERE score > 1 ORDER BY score DESC`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "sql", rows[0]["title"])
+	assert.EqualValues(t, 7, rows[0]["s"])
+	assert.Equal(t, []string{`{"query":{"match_all":{}}}`}, native.queries)
+
+	// 没有给出列信息时按列名排序，类型不一致的列按值推断
+	q, err := s.Query(`SELECT * FROM PASSTHROUGH('my_es', 'q') AS hits WHERE hits.title = 'go'`)
+	require.NoError(t, err)
+	defer q.Close()
+	columns := q.Columns()
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	assert.Equal(t, []string{"ratio", "score", "tags", "title"}, names)
+	assert.Equal(t, "DOUBLE", columns[0].Type)
+	assert.Equal(t, "BIGINT", columns[1].Type)
+}
+
+// TestPassthrough_Permission 测试原生查询需要按数据源授权
+func TestPassthrough_Permission(t *testing.T) {
+	s, native := newPassthroughTestSession(t)
+
+	_, err := s.QueryAll(`SELECT * FROM PASSTHROUGH('my_es', 'q')`)
+	require.Error(t, err)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrSpecificAccessDenied), "error: %v", err)
+	assert.Empty(t, native.queries)
+
+	s.SetPassthroughDataSources([]string{"other"})
+	_, err = s.QueryAll(`SELECT * FROM PASSTHROUGH('my_es', 'q')`)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrSpecificAccessDenied), "error: %v", err)
+
+	s.SetPassthroughDataSources([]string{"*"})
+	rows, err := s.QueryAll(`SELECT COUNT(*) AS n FROM PASSTHROUGH('my_es', 'q')`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, rows[0]["n"])
+
+	// 不支持原生查询的数据源返回错误
+	_, err = s.QueryAll(`SELECT * FROM PASSTHROUGH('default', 'q')`)
+	assert.Error(t, err)
+	_, err = s.QueryAll(`SELECT * FROM PASSTHROUGH('missing', 'q')`)
+	assert.Error(t, err)
+}
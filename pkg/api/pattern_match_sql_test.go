@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var patternMatchFixture = []string{
+	`CREATE TABLE p (id INT, name VARCHAR(50))`,
+	`INSERT INTO p (id, name) VALUES (1, 'apple'), (2, 'Apricot'), (3, '50% off'), (4, 'a_b'), (5, 'axb'), (6, NULL)`,
+}
+
+func TestPatternMatch_Where(t *testing.T) {
+	sess := newTestSession(t, patternMatchFixture...)
+
+	tests := []struct {
+		sql      string
+		expected []interface{}
+	}{
+		{"SELECT id FROM p WHERE name LIKE 'a%'", []interface{}{int64(1), int64(4), int64(5)}},
+		{"SELECT id FROM p WHERE name LIKE 'a_b'", []interface{}{int64(4), int64(5)}},
+		{"SELECT id FROM p WHERE name LIKE 'a\\_b'", []interface{}{int64(4)}},
+		{"SELECT id FROM p WHERE name LIKE 'a!_b' ESCAPE '!'", []interface{}{int64(4)}},
+		{"SELECT id FROM p WHERE name LIKE '%!%%' ESCAPE '!'", []interface{}{int64(3)}},
+		// NULL 不满足 NOT LIKE
+		{"SELECT id FROM p WHERE name NOT LIKE 'a%'", []interface{}{int64(2), int64(3)}},
+		{"SELECT id FROM p WHERE name COLLATE utf8mb4_general_ci LIKE 'ap%'", []interface{}{int64(1), int64(2)}},
+		{"SELECT id FROM p WHERE name ILIKE 'AP%'", []interface{}{int64(1), int64(2)}},
+		{"SELECT id FROM p WHERE name REGEXP '^a.b$'", []interface{}{int64(4), int64(5)}},
+		{"SELECT id FROM p WHERE name RLIKE '[0-9]+'", []interface{}{int64(3)}},
+		{"SELECT id FROM p WHERE name NOT REGEXP '^a'", []interface{}{int64(2), int64(3)}},
+		// 不能下推的条件与可下推的条件组合
+		{"SELECT id FROM p WHERE id > 1 AND name REGEXP '^a'", []interface{}{int64(4), int64(5)}},
+		{"SELECT id FROM p WHERE name LIKE 'apple' OR name LIKE 'axb'", []interface{}{int64(1), int64(5)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			_, rows := queryRows(t, sess, tt.sql)
+			assert.ElementsMatch(t, tt.expected, columnValues(rows, "id"))
+		})
+	}
+}
+
+func TestPatternMatch_InvalidRegexp(t *testing.T) {
+	sess := newTestSession(t, patternMatchFixture...)
+
+	_, err := sess.Query("SELECT id FROM p WHERE name REGEXP '('")
+	assert.Error(t, err)
+}
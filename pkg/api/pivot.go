@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+// evaluatePivot 计算 PIVOT / UNPIVOT 的结果集。源先执行并写入 results 中的临时表；
+// PIVOT 改写为按其余列分组的条件聚合 AGG(CASE WHEN column = value THEN expr END)，
+// UNPIVOT 把每行的各列展开为多行，跳过值为 NULL 的列
+func (s *Session) evaluatePivot(ctx context.Context, results domain.DataSource, tables map[string]domain.DataSource, pivot parser.Pivot) (*domain.QueryResult, error) {
+	sourceSQL := pivot.Source
+	if !pivot.Subquery {
+		sourceSQL = "SELECT * FROM " + pivot.Source
+	}
+	source, err := s.coreSession.ExecuteQuery(optimizer.WithTableOverrides(ctx, tables), sourceSQL)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeSyntax, "failed to execute pivot source")
+	}
+	if pivot.Unpivot {
+		return unpivot(source, pivot)
+	}
+
+	sourceTable := pivot.Table + "_source"
+	if err := materializePassthrough(ctx, results, sourceTable, source); err != nil {
+		return nil, WrapError(err, ErrCodeInternal, "failed to materialize pivot source")
+	}
+	tables[sourceTable] = results
+	sql, err := pivotSQL(source, sourceTable, pivot)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeInvalidParam, "failed to build pivot")
+	}
+	result, err := s.coreSession.ExecuteQuery(optimizer.WithTableOverrides(ctx, tables), sql)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeSyntax, "failed to execute pivot")
+	}
+	return result, nil
+}
+
+// pivotSQL 把 PIVOT 改写为条件聚合：源中未被 PIVOT 引用的列作为分组列
+func pivotSQL(source *domain.QueryResult, sourceTable string, pivot parser.Pivot) (string, error) {
+	used := map[string]bool{strings.ToLower(pivot.Column): true}
+	for _, agg := range pivot.Aggregates {
+		for _, col := range agg.Columns {
+			used[strings.ToLower(col)] = true
+		}
+	}
+	var groups, items []string
+	for _, col := range source.Columns {
+		if !used[strings.ToLower(col.Name)] {
+			groups = append(groups, security.QuoteIdentifier(col.Name))
+		}
+	}
+	items = append(items, groups...)
+	for v, value := range pivot.Values {
+		for a, agg := range pivot.Aggregates {
+			result := agg.Arg
+			if result == "*" {
+				result = "1"
+			}
+			literal, err := paramToSQLLiteral(value.Value)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, fmt.Sprintf("%s(CASE WHEN %s = %s THEN %s END) AS %s",
+				agg.Func, security.QuoteIdentifier(pivot.Column), literal, result, security.QuoteIdentifier(pivot.ColumnName(a, v))))
+		}
+	}
+	sql := "SELECT " + strings.Join(items, ", ") + " FROM " + security.QuoteIdentifier(sourceTable)
+	if len(groups) > 0 {
+		sql += " GROUP BY " + strings.Join(groups, ", ")
+	}
+	return sql, nil
+}
+
+// unpivot 把源的每行按 IN 列表展开为多行：其余列原样保留，Column 为标签，Value 为原列的值
+func unpivot(source *domain.QueryResult, pivot parser.Pivot) (*domain.QueryResult, error) {
+	unpivoted := make(map[string]bool, len(pivot.Values))
+	for _, value := range pivot.Values {
+		unpivoted[strings.ToLower(value.Column)] = true
+	}
+	result := &domain.QueryResult{}
+	var valueType string
+	for _, col := range source.Columns {
+		if !unpivoted[strings.ToLower(col.Name)] {
+			result.Columns = append(result.Columns, col)
+		} else if valueType == "" {
+			valueType = col.Type
+		}
+	}
+	for _, value := range pivot.Values {
+		if !hasColumn(source.Columns, value.Column) {
+			return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf("UNPIVOT: unknown column '%s'", value.Column), nil)
+		}
+	}
+	result.Columns = append(result.Columns,
+		domain.ColumnInfo{Name: pivot.Column, Type: "VARCHAR"},
+		domain.ColumnInfo{Name: pivot.Value, Type: valueType})
+
+	for _, row := range source.Rows {
+		for _, value := range pivot.Values {
+			v := rowValue(row, value.Column)
+			if v == nil {
+				continue
+			}
+			out := make(domain.Row, len(result.Columns))
+			for _, col := range result.Columns[:len(result.Columns)-2] {
+				out[col.Name] = row[col.Name]
+			}
+			out[pivot.Column] = value.Label
+			out[pivot.Value] = v
+			result.Rows = append(result.Rows, out)
+		}
+	}
+	result.Total = int64(len(result.Rows))
+	return result, nil
+}
+
+// hasColumn 判断结果集是否有名为 name 的列（不区分大小写）
+func hasColumn(columns []domain.ColumnInfo, name string) bool {
+	for _, col := range columns {
+		if strings.EqualFold(col.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// rowValue 按列名取值，列名不区分大小写
+func rowValue(row domain.Row, name string) interface{} {
+	if v, ok := row[name]; ok {
+		return v
+	}
+	for key, v := range row {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return nil
+}
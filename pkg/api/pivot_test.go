@@ -0,0 +1,87 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPivotTestSession 建立按地区、季度记录销售额的表 sales
+func newPivotTestSession(t *testing.T) *Session {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE sales (id INT PRIMARY KEY AUTO_INCREMENT, region VARCHAR(10), quarter VARCHAR(4), amount INT)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO sales (region, quarter, amount) VALUES ('east', 'Q1', 10), ('east', 'Q2', 20),
+		('west', 'Q1', 5), ('east', 'Q1', 1)`)
+	require.NoError(t, err)
+	return s
+}
+
+// TestPivot_ConditionalAggregates 测试 SUM/COUNT 的参数为 CASE WHEN 与 IF 表达式的条件聚合
+func TestPivot_ConditionalAggregates(t *testing.T) {
+	s := newPivotTestSession(t)
+
+	rows, err := s.QueryAll(`SELECT region, SUM(CASE WHEN quarter = 'Q1' THEN amount END) AS q1,
+		SUM(CASE quarter WHEN 'Q2' THEN amount ELSE 0 END) AS q2, COUNT(CASE WHEN quarter = 'Q1' THEN 1 END) AS n1,
+		SUM(IF(amount > 5, amount, 0)) AS big FROM sales GROUP BY region ORDER BY region`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 11, rows[0]["q1"])
+	assert.EqualValues(t, 20, rows[0]["q2"])
+	assert.EqualValues(t, 2, rows[0]["n1"])
+	assert.EqualValues(t, 30, rows[0]["big"])
+	assert.EqualValues(t, 5, rows[1]["q1"])
+	assert.EqualValues(t, 0, rows[1]["q2"])
+	assert.EqualValues(t, 1, rows[1]["n1"])
+	assert.EqualValues(t, 0, rows[1]["big"])
+}
+
+// TestPivot_Pivot 测试 PIVOT 把季度转为列
+func TestPivot_Pivot(t *testing.T) {
+	s := newPivotTestSession(t)
+
+	rows, err := s.QueryAll(`SELECT * FROM (SELECT region, quarter, amount FROM sales)
+		PIVOT (SUM(amount) FOR quarter IN ('Q1', 'Q2', 'Q3' AS third)) AS p ORDER BY region`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "east", rows[0]["region"])
+	assert.EqualValues(t, 11, rows[0]["Q1"])
+	assert.EqualValues(t, 20, rows[0]["Q2"])
+	assert.Nil(t, rows[0]["third"])
+	assert.EqualValues(t, 5, rows[1]["Q1"])
+	assert.Nil(t, rows[1]["Q2"])
+
+	// 多个聚合函数的列名为 取值_别名；没有其余列时只有一行
+	rows, err = s.QueryAll(`SELECT q1_total, q1_n, q2_total FROM (SELECT quarter, amount FROM sales)
+		PIVOT (SUM(amount) AS total, COUNT(*) AS n FOR quarter IN ('Q1' AS q1, 'Q2' AS q2))`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 16, rows[0]["q1_total"])
+	assert.EqualValues(t, 3, rows[0]["q1_n"])
+	assert.EqualValues(t, 20, rows[0]["q2_total"])
+}
+
+// TestPivot_Unpivot 测试 UNPIVOT 把宽表的列展开为行，跳过 NULL
+func TestPivot_Unpivot(t *testing.T) {
+	s := newPivotTestSession(t)
+	_, err := s.Execute(`CREATE TABLE wide (region VARCHAR(10), q1 INT, q2 INT)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO wide (region, q1, q2) VALUES ('east', 11, 20), ('west', 5, NULL)`)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`SELECT region, quarter, amount FROM wide
+		UNPIVOT (amount FOR quarter IN (q1 AS 'Q1', q2 AS 'Q2')) u ORDER BY region, quarter`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "east", rows[0]["region"])
+	assert.Equal(t, "Q1", rows[0]["quarter"])
+	assert.EqualValues(t, 11, rows[0]["amount"])
+	assert.Equal(t, "Q2", rows[1]["quarter"])
+	assert.EqualValues(t, 20, rows[1]["amount"])
+	assert.Equal(t, "west", rows[2]["region"])
+	assert.EqualValues(t, 5, rows[2]["amount"])
+
+	_, err = s.QueryAll(`SELECT * FROM wide UNPIVOT (amount FOR quarter IN (q1, q9))`)
+	assert.Error(t, err)
+}
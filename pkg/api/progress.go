@@ -0,0 +1,23 @@
+package api
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SetProgressHandler 设置长时间操作（建索引、OPTIMIZE TABLE 等）的进度回调，传 nil 取消。
+// 回调在执行语句的 goroutine 中同步调用，实现应尽快返回
+func (s *Session) SetProgressHandler(fn domain.ProgressFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = fn
+}
+
+// progressContext 为语句的执行上下文附加会话的进度回调
+func (s *Session) progressContext(ctx context.Context) context.Context {
+	s.mu.RLock()
+	fn := s.progress
+	s.mu.RUnlock()
+	return domain.WithProgress(ctx, fn)
+}
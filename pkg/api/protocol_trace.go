@@ -0,0 +1,22 @@
+package api
+
+import "strings"
+
+// varProtocolTrace 会话变量，SET [SESSION] protocol_trace = 1 开启连接的协议跟踪
+const varProtocolTrace = "protocol_trace"
+
+// ProtocolTrace 返回会话变量 protocol_trace 的值；set 为 false 表示未设置或值无效，
+// 此时由服务器配置决定是否跟踪
+func (s *Session) ProtocolTrace() (enabled, set bool) {
+	v, ok := s.coreSession.GetSessionVar(varProtocolTrace)
+	if !ok {
+		return false, false
+	}
+	switch strings.ToUpper(unquoteVar(v)) {
+	case "ON", "1", "TRUE":
+		return true, true
+	case "OFF", "0", "FALSE":
+		return false, true
+	}
+	return false, false
+}
@@ -18,6 +18,7 @@ type Query struct {
 	closed   bool
 	mu       sync.RWMutex
 	err      error
+	exec     *Result // 通过 Query 执行的 INSERT/UPDATE/DELETE 等语句的结果
 }
 
 // NewQuery 创建 Query
@@ -138,6 +139,31 @@ func (q *Query) Columns() []domain.ColumnInfo {
 	return cols
 }
 
+// Warnings 获取查询产生的警告（如结果集被截断），q 为 nil 时返回 nil
+func (q *Query) Warnings() []string {
+	if q == nil {
+		return nil
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.result == nil {
+		return nil
+	}
+	return append([]string(nil), q.result.Warnings...)
+}
+
+// ExecResult 返回通过 Query 执行的 INSERT/UPDATE/DELETE 等语句的影响行数、自增 ID 与附加信息，
+// 结果集查询返回 nil
+func (q *Query) ExecResult() *Result {
+	if q == nil {
+		return nil
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.exec
+}
+
 // Close 关闭查询
 func (q *Query) Close() error {
 	q.mu.Lock()
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
+)
+
+// varReportQueryID 会话变量，SET report_query_id = ON 时把语句的查询 ID 追加到 OK 包的附加信息中
+const varReportQueryID = "report_query_id"
+
+// LastQueryID returns the query ID of the statement the session is executing,
+// or of its previous statement. Query IDs come from the server-wide ID generator
+// shared with connection IDs and change events, so they increase over time and
+// match the QUERY_ID column of information_schema.PROCESSLIST.
+func (s *Session) LastQueryID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastQueryID
+}
+
+// beginQueryID 为语句分配查询 ID，执行期间 executionContext 把它传给 CoreSession，
+// 进程列表和 OK 包中报告的是同一个 ID
+func (s *Session) beginQueryID() string {
+	queryID := strconv.FormatInt(idgen.Next(), 10)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryID = queryID
+	s.lastQueryID = queryID
+	return queryID
+}
+
+// finishQueryID 语句执行结束，之后的执行不再使用它的查询 ID
+func (s *Session) finishQueryID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryID = ""
+}
+
+// appendQueryIDInfo 开启 report_query_id 时把查询 ID 追加到 DML/DDL 结果的附加信息
+func (s *Session) appendQueryIDInfo(result *Result, queryID string) {
+	if result == nil || !s.sessionSwitch(varReportQueryID) {
+		return
+	}
+	if result.Info != "" {
+		result.Info += "  "
+	}
+	result.Info += "Query ID: " + queryID
+}
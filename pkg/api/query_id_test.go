@@ -0,0 +1,48 @@
+package api
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryID_LastQueryID 测试每条语句分配递增的查询 ID
+func TestQueryID_LastQueryID(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	before := idgen.Next()
+	_, err := s.Execute(`INSERT INTO users (name, city) VALUES ('a', 'x')`)
+	require.NoError(t, err)
+	first, err := strconv.ParseInt(s.LastQueryID(), 10, 64)
+	require.NoError(t, err)
+	assert.Greater(t, first, before)
+
+	_, err = s.QueryAll(`SELECT name FROM users`)
+	require.NoError(t, err)
+	second, err := strconv.ParseInt(s.LastQueryID(), 10, 64)
+	require.NoError(t, err)
+	assert.Greater(t, second, first)
+}
+
+// TestQueryID_OKInfo 测试 report_query_id 开启后 DML 的附加信息带有查询 ID，位于统计之后
+func TestQueryID_OKInfo(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	res, err := s.Execute(`INSERT INTO users (name, city) VALUES ('a', 'x')`)
+	require.NoError(t, err)
+	assert.NotContains(t, res.Info, "Query ID")
+
+	_, err = s.Execute(`SET report_query_id = ON`)
+	require.NoError(t, err)
+	res, err = s.Execute(`UPDATE users SET city = 'y' WHERE name = 'a'`)
+	require.NoError(t, err)
+	assert.Equal(t, "Query ID: "+s.LastQueryID(), res.Info)
+
+	_, err = s.Execute(`SET report_query_stats = ON`)
+	require.NoError(t, err)
+	q, err := s.Query(`INSERT INTO users (name, city) VALUES ('b', 'x')`)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Regexp(t, `^Rows examined: 0 .*  Query ID: `+s.LastQueryID()+`$`, q.ExecResult().Info)
+}
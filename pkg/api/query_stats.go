@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/session"
+)
+
+// varReportQueryStats 会话变量，SET report_query_stats = ON 时把语句的资源使用统计追加到 OK 包的附加信息中
+const varReportQueryStats = "report_query_stats"
+
+// QueryStats is the resource usage of one statement, returned by LAST_QUERY_STATS()
+type QueryStats struct {
+	RowsExamined int64         `json:"rows_examined"` // rows read from data sources (rows matched for UPDATE/DELETE)
+	RowsSent     int64         `json:"rows_sent"`     // rows returned to the client
+	RowsAffected int64         `json:"rows_affected"` // rows inserted, updated or deleted
+	MemoryPeak   int64         `json:"memory_peak"`   // estimated peak bytes of rows materialized during execution
+	Duration     time.Duration `json:"-"`
+}
+
+// Info formats the statistics for the info string of an OK packet
+func (st QueryStats) Info() string {
+	return fmt.Sprintf("Rows examined: %d  Rows sent: %d  Memory peak: %d  Duration: %.6f",
+		st.RowsExamined, st.RowsSent, st.MemoryPeak, st.Duration.Seconds())
+}
+
+// LastQueryStats returns the resource usage of the session's previous statement.
+// Statements calling LAST_QUERY_STATS() do not replace it.
+func (s *Session) LastQueryStats() QueryStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastStats
+}
+
+// beginQueryStats 语句执行前开始统计资源使用，返回的统计在 executionContext 中传给执行器；
+// 读取 LAST_QUERY_STATS() 的语句不统计，返回 nil
+func (s *Session) beginQueryStats(sql string) *domain.QueryStats {
+	if s == nil || s.coreSession == nil {
+		return nil
+	}
+	var stats *domain.QueryStats
+	if _, found := parser.BindNiladicFunction(sql, parser.LastQueryStatsFunc, "NULL"); !found {
+		stats = &domain.QueryStats{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = stats
+	return stats
+}
+
+// finishQueryStats 语句执行结束后保存统计，返回的结果集计入发送的行数和物化内存
+func (s *Session) finishQueryStats(stats *domain.QueryStats, start time.Time, sent []domain.Row, affected int64) QueryStats {
+	stats.AddMemory(domain.EstimateRowsSize(sent))
+	last := QueryStats{
+		RowsExamined: stats.RowsExamined(),
+		RowsSent:     int64(len(sent)),
+		RowsAffected: affected,
+		MemoryPeak:   stats.MemoryPeak(),
+		Duration:     time.Since(start),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats = nil
+	s.lastStats = last
+	return last
+}
+
+// executionContext 语句的执行上下文：附加会话的进度回调、当前语句的查询 ID 与资源使用统计，
+// 会话绑定了分叉时分叉表改由副本提供
+func (s *Session) executionContext() context.Context {
+	s.mu.RLock()
+	stats := s.stats
+	queryID := s.queryID
+	fork := s.fork
+	s.mu.RUnlock()
+	ctx := domain.WithQueryStats(s.progressContext(session.WithQueryID(context.Background(), queryID)), stats)
+	if fork != nil {
+		ctx = optimizer.WithTableOverrides(ctx, fork.overrides)
+	}
+	return ctx
+}
+
+// bindLastQueryStats 把 LAST_QUERY_STATS() 替换为上一条语句统计的 JSON 字符串
+func (s *Session) bindLastQueryStats(sql string) string {
+	last := s.LastQueryStats()
+	doc, _ := json.Marshal(struct {
+		QueryStats
+		DurationUS int64 `json:"duration_us"`
+	}{last, last.Duration.Microseconds()})
+	bound, _ := parser.BindNiladicFunction(sql, parser.LastQueryStatsFunc, "'"+string(doc)+"'")
+	return bound
+}
+
+// sessionSwitch 开关型会话变量（如 report_query_stats）是否开启
+func (s *Session) sessionSwitch(name string) bool {
+	if s.coreSession == nil {
+		return false
+	}
+	v, ok := s.coreSession.GetSessionVar(name)
+	if !ok {
+		return false
+	}
+	switch strings.ToUpper(unquoteVar(v)) {
+	case "1", "ON", "TRUE":
+		return true
+	}
+	return false
+}
+
+// appendStatsInfo 开启 report_query_stats 时把统计追加到 DML/DDL 结果的附加信息
+func (s *Session) appendStatsInfo(result *Result, stats QueryStats) {
+	if result == nil || !s.sessionSwitch(varReportQueryStats) {
+		return
+	}
+	if result.Info != "" {
+		result.Info += "  "
+	}
+	result.Info += stats.Info()
+}
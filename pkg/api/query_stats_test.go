@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryStats_LastQueryStats 测试 LAST_QUERY_STATS() 返回上一条语句的统计，且读取它不会覆盖统计
+func TestQueryStats_LastQueryStats(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		_, err := s.Execute(`INSERT INTO users (name, city) VALUES (?, 'x')`, name)
+		require.NoError(t, err)
+	}
+
+	rows, err := s.QueryAll(`SELECT name FROM users LIMIT 2`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	last := s.LastQueryStats()
+	assert.EqualValues(t, 2, last.RowsSent)
+	assert.GreaterOrEqual(t, last.RowsExamined, int64(2))
+	assert.Positive(t, last.MemoryPeak)
+	assert.Positive(t, last.Duration)
+
+	for i := 0; i < 2; i++ {
+		rows, err = s.QueryAll(`SELECT LAST_QUERY_STATS()`)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(rows[0]["LAST_QUERY_STATS()"].(string)), &doc))
+		assert.EqualValues(t, 2, doc["rows_sent"])
+		assert.EqualValues(t, last.RowsExamined, doc["rows_examined"])
+		assert.Contains(t, doc, "memory_peak")
+		assert.Contains(t, doc, "duration_us")
+	}
+
+	res, err := s.Execute(`UPDATE users SET city = 'y' WHERE city = 'x'`)
+	require.NoError(t, err)
+	last = s.LastQueryStats()
+	assert.Equal(t, res.RowsAffected, last.RowsAffected)
+	assert.Equal(t, res.RowsAffected, last.RowsExamined)
+	assert.Zero(t, last.RowsSent)
+}
+
+// TestQueryStats_OKInfo 测试 report_query_stats 开启后 DML 的附加信息带有统计
+func TestQueryStats_OKInfo(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	res, err := s.Execute(`INSERT INTO users (name, city) VALUES ('a', 'x')`)
+	require.NoError(t, err)
+	assert.Empty(t, res.Info)
+
+	_, err = s.Execute(`SET report_query_stats = ON`)
+	require.NoError(t, err)
+	res, err = s.Execute(`DELETE FROM users WHERE name = 'a'`)
+	require.NoError(t, err)
+	assert.Contains(t, res.Info, "Rows examined: 1  Rows sent: 0  Memory peak: ")
+
+	// 通过 Query 执行的 DML（MySQL 协议的 COM_QUERY）同样带有统计
+	q, err := s.Query(`INSERT INTO users (name, city) VALUES ('b', 'x')`)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Contains(t, q.ExecResult().Info, "Rows examined: 0")
+}
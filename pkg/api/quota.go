@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/quota"
+)
+
+// SetQuotas 替换全部写入配额
+func (db *DB) SetQuotas(quotas []quota.Quota) error {
+	for _, q := range quotas {
+		if err := q.Validate(); err != nil {
+			return NewError(ErrCodeInvalidParam, err.Error(), nil)
+		}
+	}
+	db.quotas.Set(quotas)
+	return nil
+}
+
+// Quotas 返回配额管理器
+func (db *DB) Quotas() *quota.Manager {
+	return db.quotas
+}
+
+// quotaChecksEnabled 是否设置了配额
+func (s *Session) quotaChecksEnabled() bool {
+	return s.db != nil && s.db.quotas.Active()
+}
+
+// checkQuota 在分发前检查写入语句是否会使目标表或数据库超出当前用户的配额
+func (s *Session) checkQuota(stmt *parser.SQLStatement) error {
+	// 分叉表的写入只修改副本，不计入配额
+	if stmt == nil || !s.quotaChecksEnabled() || s.Fork() != nil {
+		return nil
+	}
+	database, table, rows, bytes, ok := quotaTarget(stmt)
+	if !ok {
+		return nil
+	}
+	if database == "" {
+		database = s.coreSession.GetCurrentDB()
+	}
+	catalog := quota.Catalog{DataSources: s.db.dsManager, Virtual: s.coreSession.GetVirtualDBRegistry()}
+	err := s.db.quotas.Check(context.Background(), catalog, s.GetUser(), database, table, rows, bytes)
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		return NewError(ErrCodeQuotaExceeded, exceeded.Error(), nil)
+	}
+	if err != nil {
+		return WrapError(err, ErrCodeInternal, "failed to check quota")
+	}
+	return nil
+}
+
+// quotaTarget 返回增加数据的语句的目标库、表以及写入的行数和数据量，其余语句 ok 为 false。
+// IMPORT DATA 的行数在读取文件前未知，返回 0
+func quotaTarget(stmt *parser.SQLStatement) (database, table string, rows, bytes int64, ok bool) {
+	switch stmt.Type {
+	case parser.SQLTypeInsert:
+		if stmt.Insert == nil {
+			return "", "", 0, 0, false
+		}
+		for _, values := range stmt.Insert.Values {
+			for _, v := range values {
+				bytes += quota.ValueSize(v)
+			}
+		}
+		return stmt.Insert.Database, stmt.Insert.Table, int64(len(stmt.Insert.Values)), bytes, true
+	case parser.SQLTypeGenerate:
+		if stmt.GenerateTable == nil {
+			return "", "", 0, 0, false
+		}
+		database, table = splitTableName(stmt.GenerateTable.Table)
+		return database, table, stmt.GenerateTable.Rows, 0, true
+	case parser.SQLTypeImportData:
+		if stmt.ImportData == nil {
+			return "", "", 0, 0, false
+		}
+		database, table = splitTableName(stmt.ImportData.Table)
+		return database, table, 0, 0, true
+	}
+	return "", "", 0, 0, false
+}
+
+// splitTableName 拆分 db.table 形式的表名
+func splitTableName(name string) (database, table string) {
+	if dot := strings.IndexByte(name, '.'); dot > 0 {
+		return name[:dot], name[dot+1:]
+	}
+	return "", name
+}
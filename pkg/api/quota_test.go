@@ -0,0 +1,99 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/quota"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertQuotaError(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeQuotaExceeded), "unexpected error: %v", err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrRecordFileFull, code)
+}
+
+// TestQuota_TableMaxRows 测试表的行数配额：写入后超出配额的 INSERT 被拒绝
+func TestQuota_TableMaxRows(t *testing.T) {
+	db := newXATestDB(t)
+	require.NoError(t, db.SetQuotas([]quota.Quota{{Database: "test", Table: "accounts", MaxRows: 3}}))
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`INSERT INTO accounts VALUES (3, 100), (4, 100)`)
+	assertQuotaError(t, err)
+	assert.Contains(t, err.Error(), "'test.accounts'")
+	assert.Contains(t, err.Error(), "max_rows 3")
+
+	_, err = s.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	require.NoError(t, err)
+	_, err = s.Query(`INSERT INTO accounts VALUES (4, 100)`)
+	assertQuotaError(t, err)
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`INSERT INTO accounts VALUES (4, 100)`)
+	assertQuotaError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	// 其他表和删除不受影响
+	_, err = s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1)`)
+	assert.NoError(t, err)
+	_, err = s.Execute(`DELETE FROM accounts WHERE id = 3`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO accounts VALUES (4, 100)`)
+	assert.NoError(t, err)
+}
+
+// TestQuota_DatabaseMaxBytes 测试数据库的数据量配额
+func TestQuota_DatabaseMaxBytes(t *testing.T) {
+	db := newXATestDB(t)
+	require.NoError(t, db.SetQuotas([]quota.Quota{{Database: "ledger", MaxBytes: 20}}))
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1000)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (2, 1234567890), (3, 1234567890)`)
+	assertQuotaError(t, err)
+	assert.Contains(t, err.Error(), "max_bytes 20")
+}
+
+// TestQuota_PerUser 测试用户自己的配额取代通用配额，不限制的配额取消通用配额
+func TestQuota_PerUser(t *testing.T) {
+	db := newXATestDB(t)
+	require.NoError(t, db.SetQuotas([]quota.Quota{
+		{Database: "test", MaxRows: 2},
+		{Database: "test", User: "tenant_a", MaxRows: 3},
+		{Database: "test", User: "admin"},
+	}))
+
+	s := db.Session()
+	defer s.Close()
+	_, err := s.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	assertQuotaError(t, err)
+
+	s.SetUser("tenant_a")
+	_, err = s.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO accounts VALUES (4, 100)`)
+	assertQuotaError(t, err)
+	assert.Contains(t, err.Error(), "for user 'tenant_a'")
+
+	s.SetUser("admin")
+	_, err = s.Execute(`INSERT INTO accounts VALUES (4, 100), (5, 100)`)
+	assert.NoError(t, err)
+}
+
+// TestQuota_Invalid 测试无效的配额被拒绝
+func TestQuota_Invalid(t *testing.T) {
+	db := newXATestDB(t)
+	err := db.SetQuotas([]quota.Quota{{Table: "accounts", MaxRows: 1}})
+	assert.True(t, IsErrorCode(err, ErrCodeInvalidParam))
+	err = db.SetQuotas([]quota.Quota{{Database: "test", MaxRows: -1}})
+	assert.True(t, IsErrorCode(err, ErrCodeInvalidParam))
+	assert.False(t, db.Quotas().Active())
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SetRecycleBinRetention sets how long dropped tables are kept in the recycle
+// bin of every datasource that supports it, so that UNDROP TABLE can restore
+// them. A retention of 0 drops tables for good and empties the recycle bins.
+func (db *DB) SetRecycleBinRetention(retention time.Duration) error {
+	if retention < 0 {
+		return NewError(ErrCodeInvalidParam, "recycle bin retention must not be negative", nil)
+	}
+	for _, ds := range db.dsManager.GetAllDataSources() {
+		if rb, ok := ds.(domain.RecycleBin); ok {
+			rb.SetRecycleBinRetention(retention)
+		}
+	}
+	return nil
+}
+
+// RecycledTables returns the tables in the recycle bin of a datasource. An
+// empty dsName selects the default datasource.
+func (db *DB) RecycledTables(dsName string) ([]domain.RecycledTable, error) {
+	var ds domain.DataSource
+	var err error
+	if dsName == "" {
+		ds, err = db.GetDefaultDataSource()
+	} else {
+		ds, err = db.GetDataSource(dsName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := ds.(domain.RecycleBin)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, "datasource does not support the recycle bin", nil)
+	}
+	return rb.RecycledTables(), nil
+}
+
+// SetChangeLogSize sets how many recent row changes every datasource that
+// supports FLASHBACK TABLE keeps. FLASHBACK TABLE can only reverse changes
+// still in the log; a size of 0 disables it.
+func (db *DB) SetChangeLogSize(size int) error {
+	if size < 0 {
+		return NewError(ErrCodeInvalidParam, "change log size must not be negative", nil)
+	}
+	for _, ds := range db.dsManager.GetAllDataSources() {
+		if fb, ok := ds.(domain.Flashbacker); ok {
+			fb.SetChangeLogSize(size)
+		}
+	}
+	return nil
+}
+
+// PurgeRecycleBin frees the dropped tables whose retention has expired in
+// every datasource and returns their number. The TTL purger calls it on every
+// pass.
+func (db *DB) PurgeRecycleBin(ctx context.Context) (int64, error) {
+	var total int64
+	var errs []error
+	for _, ds := range db.dsManager.GetAllDataSources() {
+		rb, ok := ds.(domain.RecycleBin)
+		if !ok {
+			continue
+		}
+		purged, err := rb.PurgeRecycleBin(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		total += purged
+	}
+	return total, errors.Join(errs...)
+}
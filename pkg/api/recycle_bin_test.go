@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecycleBin_Undrop 测试 DROP TABLE 把表移入回收站，UNDROP TABLE 恢复
+func TestRecycleBin_Undrop(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	require.NoError(t, db.SetRecycleBinRetention(time.Hour))
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`DROP TABLE accounts`)
+	require.NoError(t, err)
+	_, err = s.Query(`SELECT * FROM accounts`)
+	assert.Error(t, err)
+
+	recycled, err := db.RecycledTables("test")
+	require.NoError(t, err)
+	require.Len(t, recycled, 1)
+	assert.Equal(t, "accounts", recycled[0].Name)
+	assert.EqualValues(t, 2, recycled[0].Rows)
+
+	_, err = s.Execute(`UNDROP TABLE accounts`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+
+	_, err = s.Execute(`UNDROP TABLE accounts`)
+	assert.Error(t, err)
+
+	// 保留时间为 0 时直接删除
+	require.NoError(t, db.SetRecycleBinRetention(0))
+	_, err = s.Execute(`DROP TABLE accounts`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UNDROP TABLE accounts`)
+	assert.ErrorContains(t, err, "not in the recycle bin")
+
+	assert.True(t, IsErrorCode(db.SetRecycleBinRetention(-time.Hour), ErrCodeInvalidParam))
+	purged, err := db.PurgeRecycleBin(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+}
+
+// TestFlashbackTable 测试 FLASHBACK TABLE ... TO TIMESTAMP 撤销之后提交的 DML
+func TestFlashbackTable(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	require.NoError(t, db.SetChangeLogSize(1000))
+	s := db.Session()
+	defer s.Close()
+
+	time.Sleep(10 * time.Millisecond)
+	before := time.Now().Format(asOfLayout)
+	time.Sleep(10 * time.Millisecond)
+	_, err := s.Execute(`INSERT INTO accounts VALUES (3, 100)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = s.Execute(`DELETE FROM accounts WHERE id = 2`)
+	require.NoError(t, err)
+
+	result, err := s.Execute(`FLASHBACK TABLE accounts TO TIMESTAMP '` + before + `'`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.RowsAffected)
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts WHERE balance = 100`))
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+
+	// 变更日志不覆盖的时间报错
+	_, err = s.Execute(`FLASHBACK TABLE accounts TO TIMESTAMP '2000-01-01 00:00:00'`)
+	assert.ErrorContains(t, err, "only covers")
+	_, err = s.Execute(`FLASHBACK TABLE missing TO TIMESTAMP '` + before + `'`)
+	assert.Error(t, err)
+}
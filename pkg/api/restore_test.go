@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRestoreTestSession 创建只有空的 test 数据源的会话，用于恢复备份
+func newRestoreTestSession(t *testing.T, dir string) *Session {
+	s := newTestDB(t, &DBConfig{DefaultLogger: NewNoOpLogger(), OutfileDirs: []string{dir}}).Session()
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// TestRestoreDatabase_PointInTime 测试全量备份加增量备份恢复到最新状态和指定时间点
+func TestRestoreDatabase_PointInTime(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestSession(t, usersFixture...)
+	s.db.config.OutfileDirs = []string{dir}
+	require.NoError(t, s.db.SetChangeLogSize(100))
+	full := filepath.Join(dir, "full.tar")
+	inc1 := filepath.Join(dir, "inc1.tar")
+	inc2 := filepath.Join(dir, "inc2.tar")
+
+	_, err := s.Execute(fmt.Sprintf(`BACKUP DATABASE test TO '%s'`, full))
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO users (name, city) VALUES ('Dave', 'Rome')`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE users SET city = 'Lyon' WHERE name = 'Alice'`)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	until := time.Now()
+	time.Sleep(20 * time.Millisecond)
+	_, err = s.Execute(`DELETE FROM users WHERE name = 'bob'`)
+	require.NoError(t, err)
+
+	res, err := s.Execute(fmt.Sprintf(`BACKUP INCREMENTAL DATABASE test TO '%s' FROM '%s'`, inc1, full))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+	assert.Contains(t, res.Info, "at LSN test=3")
+
+	// 增量备份可以以上一个增量备份为基础
+	_, err = s.Execute(`INSERT INTO users (name, city) VALUES ('Eve', 'Oslo')`)
+	require.NoError(t, err)
+	res, err = s.Execute(fmt.Sprintf(`BACKUP INCREMENTAL DATABASE test TO '%s' FROM '%s'`, inc2, inc1))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.RowsAffected)
+
+	dst := newRestoreTestSession(t, dir)
+	res, err = dst.Execute(fmt.Sprintf(`RESTORE DATABASE * FROM '%s', '%s', '%s'`, full, inc1, inc2))
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, res.RowsAffected)
+	assert.Contains(t, res.Info, "to LSN test=4")
+	rows, err := dst.QueryAll(`SELECT id, name, city FROM users ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "Lyon", rows[0]["city"])
+	assert.Equal(t, "Carol", rows[1]["name"])
+	assert.Equal(t, "Eve", rows[3]["name"])
+
+	// 自增计数器越过重放的插入
+	_, err = dst.Execute(`INSERT INTO users (name, city) VALUES ('Frank', 'Kyiv')`)
+	require.NoError(t, err)
+	rows, err = dst.QueryAll(`SELECT id FROM users WHERE name = 'Frank'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 6, rows[0]["id"])
+
+	_, err = dst.Execute(fmt.Sprintf(`RESTORE DATABASE test FROM '%s'`, full))
+	assert.ErrorContains(t, err, "already exists")
+
+	// 恢复到删除 bob 之前
+	pit := newRestoreTestSession(t, dir)
+	result, err := pit.db.Restore(context.Background(), &RestoreOptions{Sources: []string{full, inc1, inc2}, Until: until})
+	require.NoError(t, err)
+	require.Len(t, result.Databases, 1)
+	assert.Equal(t, RestoredDatabase{Name: "test", Tables: 1, Rows: 3, Changes: 2, LSN: 2}, result.Databases[0])
+	rows, err = pit.QueryAll(`SELECT name FROM users ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "bob", rows[1]["name"])
+	assert.Equal(t, "Dave", rows[3]["name"])
+
+	// 增量备份必须接在已恢复的位置之后
+	_, err = newRestoreTestSession(t, dir).db.Restore(context.Background(), &RestoreOptions{Sources: []string{full, inc2}})
+	assert.ErrorContains(t, err, "does not continue from LSN 0")
+	_, err = newRestoreTestSession(t, dir).db.Restore(context.Background(), &RestoreOptions{Sources: []string{inc1}})
+	assert.ErrorContains(t, err, "expected full")
+	_, err = newRestoreTestSession(t, dir).db.Restore(context.Background(),
+		&RestoreOptions{Sources: []string{full}, Until: time.Now().Add(-time.Hour)})
+	assert.ErrorContains(t, err, "was taken at")
+}
+
+// TestBackupIncremental_ChangeArchive 测试变更日志已丢弃的变更从变更归档补齐，变更日志重置后需要新的全量备份
+func TestBackupIncremental_ChangeArchive(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestSession(t, usersFixture...)
+	s.db.config.OutfileDirs = []string{dir}
+	s.db.config.ChangeArchiveDir = filepath.Join(dir, "changes")
+	require.NoError(t, s.db.SetChangeLogSize(2))
+	full := filepath.Join(dir, "full.tar")
+	_, err := s.Execute(fmt.Sprintf(`BACKUP DATABASE test TO '%s'`, full))
+	require.NoError(t, err)
+
+	insert := func(name string) {
+		_, err := s.Execute(fmt.Sprintf(`INSERT INTO users (name, city) VALUES ('%s', 'Rome')`, name))
+		require.NoError(t, err)
+	}
+	ctx := context.Background()
+	insert("a1")
+	insert("a2")
+	archived, err := s.db.ArchiveChanges(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, archived)
+	insert("a3")
+	insert("a4")
+	archived, err = s.db.ArchiveChanges(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, archived)
+	insert("a5") // 只在变更日志中
+
+	inc := filepath.Join(dir, "inc.tar")
+	res, err := s.Execute(fmt.Sprintf(`BACKUP INCREMENTAL DATABASE test TO '%s' FROM '%s'`, inc, full))
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, res.RowsAffected)
+
+	dst := newRestoreTestSession(t, dir)
+	_, err = dst.Execute(fmt.Sprintf(`RESTORE DATABASE test FROM '%s', '%s'`, full, inc))
+	require.NoError(t, err)
+	rows, err := dst.QueryAll(`SELECT name FROM users`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 8)
+
+	// 没有归档时变更已丢失
+	s.db.config.ChangeArchiveDir = ""
+	_, err = s.Execute(fmt.Sprintf(`BACKUP INCREMENTAL DATABASE test TO '%s' FROM '%s'`, filepath.Join(dir, "lost.tar"), full))
+	assert.ErrorContains(t, err, "no longer in the change log")
+
+	require.NoError(t, s.db.SetChangeLogSize(0))
+	require.NoError(t, s.db.SetChangeLogSize(2))
+	_, err = s.Execute(fmt.Sprintf(`BACKUP INCREMENTAL DATABASE test TO '%s' FROM '%s'`, filepath.Join(dir, "reset.tar"), inc))
+	assert.ErrorContains(t, err, "take a new full backup")
+}
@@ -6,6 +6,8 @@ import "fmt"
 type Result struct {
 	RowsAffected int64
 	LastInsertID int64
+	Warnings     []string // 执行过程中产生的警告
+	Info         string   // 语句的附加信息，如 INSERT ... ON DUPLICATE KEY UPDATE 插入与更新的行数
 	err          error
 }
 
@@ -31,3 +33,11 @@ func (r *Result) Error() string {
 	}
 	return r.err.Error()
 }
+
+// warnings 返回警告，Result 为 nil 时返回 nil
+func (r *Result) warnings() []string {
+	if r == nil {
+		return nil
+	}
+	return r.Warnings
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ResultLimitAction 结果集超出上限时的处理方式
+type ResultLimitAction string
+
+const (
+	ResultLimitTruncate  ResultLimitAction = "truncate"   // 截断结果并附带警告
+	ResultLimitError     ResultLimitAction = "error"      // 返回错误
+	ResultLimitAutoLimit ResultLimitAction = "auto_limit" // 交互式客户端的 SELECT 自动加 LIMIT，其余情况截断
+)
+
+// 会话变量，SET [SESSION] 覆盖会话的结果集限制
+const (
+	varMaxResultRows     = "max_result_rows"
+	varMaxResultBytes    = "max_result_bytes"
+	varResultLimitAction = "result_limit_action"
+)
+
+// ParseResultLimitAction 解析处理方式（不区分大小写），空串为 truncate
+func ParseResultLimitAction(s string) (ResultLimitAction, error) {
+	switch a := ResultLimitAction(strings.ToLower(strings.TrimSpace(s))); a {
+	case "":
+		return ResultLimitTruncate, nil
+	case ResultLimitTruncate, ResultLimitError, ResultLimitAutoLimit:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid result limit action '%s' (expected truncate, error or auto_limit)", s)
+}
+
+// ResultLimits 会话的结果集大小上限
+type ResultLimits struct {
+	MaxRows  int64             // 最大行数，0 表示不限制
+	MaxBytes int64             // 最大字节数（按值的文本长度估算），0 表示不限制
+	Action   ResultLimitAction // 超出时的处理方式，默认 truncate
+}
+
+// enabled 是否设置了任一上限
+func (l ResultLimits) enabled() bool {
+	return l.MaxRows > 0 || l.MaxBytes > 0
+}
+
+// SetResultLimits 设置会话的结果集上限（通常按用户配置），会话变量可以覆盖
+func (s *Session) SetResultLimits(limits ResultLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resultLimits = limits
+}
+
+// SetInteractive 标记会话来自交互式客户端（CLIENT_INTERACTIVE），auto_limit 只对其注入 LIMIT
+func (s *Session) SetInteractive(interactive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interactive = interactive
+}
+
+// ResultLimits 返回会话生效的结果集上限：会话变量优先，其次是 SetResultLimits 的设置
+func (s *Session) ResultLimits() ResultLimits {
+	s.mu.RLock()
+	limits := s.resultLimits
+	s.mu.RUnlock()
+
+	if v, ok := s.coreSession.GetSessionVar(varMaxResultRows); ok {
+		if n, err := strconv.ParseInt(unquoteVar(v), 10, 64); err == nil && n >= 0 {
+			limits.MaxRows = n
+		}
+	}
+	if v, ok := s.coreSession.GetSessionVar(varMaxResultBytes); ok {
+		if n, err := strconv.ParseInt(unquoteVar(v), 10, 64); err == nil && n >= 0 {
+			limits.MaxBytes = n
+		}
+	}
+	if v, ok := s.coreSession.GetSessionVar(varResultLimitAction); ok {
+		if action, err := ParseResultLimitAction(unquoteVar(v)); err == nil {
+			limits.Action = action
+		}
+	}
+	if limits.Action == "" {
+		limits.Action = ResultLimitTruncate
+	}
+	return limits
+}
+
+// autoLimit 返回 auto_limit 策略下为交互式客户端注入的 LIMIT，不注入时返回 0
+func (s *Session) autoLimit(limits ResultLimits) int64 {
+	s.mu.RLock()
+	interactive := s.interactive
+	s.mu.RUnlock()
+	if limits.Action == ResultLimitAutoLimit && interactive {
+		return limits.MaxRows
+	}
+	return 0
+}
+
+// applyResultLimits 检查结果集是否超出上限：error 策略返回错误，其余截断并附带警告。
+// 不修改传入的结果（可能来自查询缓存）
+func applyResultLimits(result *domain.QueryResult, limits ResultLimits) (*domain.QueryResult, error) {
+	if result == nil || !limits.enabled() {
+		return result, nil
+	}
+
+	keep := len(result.Rows)
+	reason := ""
+	if limits.MaxRows > 0 && int64(keep) > limits.MaxRows {
+		keep = int(limits.MaxRows)
+		reason = fmt.Sprintf("max_result_rows (%d)", limits.MaxRows)
+	}
+	if limits.MaxBytes > 0 {
+		var size int64
+		for i, row := range result.Rows[:keep] {
+			size += rowSize(row)
+			if size > limits.MaxBytes {
+				keep = i
+				reason = fmt.Sprintf("max_result_bytes (%d)", limits.MaxBytes)
+				break
+			}
+		}
+	}
+	if reason == "" {
+		return result, nil
+	}
+
+	if limits.Action == ResultLimitError {
+		return nil, NewError(ErrCodeResultTooLarge,
+			fmt.Sprintf("Result set exceeds %s; add a LIMIT clause or raise the limit", reason), nil)
+	}
+	limited := *result
+	limited.Rows = result.Rows[:keep]
+	limited.Total = int64(keep)
+	limited.Warnings = append(append([]string(nil), result.Warnings...),
+		fmt.Sprintf("Result set truncated to %d rows by %s", keep, reason))
+	return &limited, nil
+}
+
+// rowSize 估算一行的大小（各列值的文本长度之和）
+func rowSize(row domain.Row) int64 {
+	var size int64
+	for _, v := range row {
+		switch val := v.(type) {
+		case nil:
+		case string:
+			size += int64(len(val))
+		case []byte:
+			size += int64(len(val))
+		default:
+			size += int64(len(fmt.Sprint(val)))
+		}
+	}
+	return size
+}
+
+// unquoteVar 去掉 SET 变量值两侧的引号
+func unquoteVar(v string) string {
+	return strings.Trim(strings.TrimSpace(v), `'"`)
+}
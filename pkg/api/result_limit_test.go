@@ -0,0 +1,113 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResultLimitTestSession(t *testing.T) *Session {
+	db := newXATestDB(t)
+	s := db.SessionWithOptions(&SessionOptions{DataSourceName: "ledger"})
+	t.Cleanup(func() { s.Close() })
+	_, err := s.Execute(`INSERT INTO entries (id, amount) VALUES (1, 10), (2, 20), (3, 30), (4, 40), (5, 50)`)
+	require.NoError(t, err)
+	return s
+}
+
+// TestResultLimits_Truncate 测试超出行数上限时截断结果并附带警告
+func TestResultLimits_Truncate(t *testing.T) {
+	s := newResultLimitTestSession(t)
+	s.SetResultLimits(ResultLimits{MaxRows: 3})
+
+	q, err := s.Query(`SELECT * FROM entries`)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, 3, q.RowsCount())
+	require.Len(t, q.Warnings(), 1)
+	assert.Contains(t, q.Warnings()[0], "max_result_rows")
+
+	// 未超出上限时不产生警告
+	q2, err := s.Query(`SELECT * FROM entries WHERE id <= 2`)
+	require.NoError(t, err)
+	defer q2.Close()
+	assert.Equal(t, 2, q2.RowsCount())
+	assert.Empty(t, q2.Warnings())
+}
+
+// TestResultLimits_Error 测试 error 策略返回 ER_TOO_BIG_SELECT
+func TestResultLimits_Error(t *testing.T) {
+	s := newResultLimitTestSession(t)
+	s.SetResultLimits(ResultLimits{MaxRows: 3, Action: ResultLimitError})
+
+	_, err := s.Query(`SELECT * FROM entries`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeResultTooLarge), "unexpected error: %v", err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrTooBigSelect, code)
+
+	assert.Equal(t, 3, countRows(t, s, `SELECT * FROM entries LIMIT 3`))
+}
+
+// TestResultLimits_MaxBytes 测试按字节数上限截断
+func TestResultLimits_MaxBytes(t *testing.T) {
+	s := newResultLimitTestSession(t)
+	// 每行 id + amount 共 3 个字符
+	s.SetResultLimits(ResultLimits{MaxBytes: 7})
+
+	q, err := s.Query(`SELECT * FROM entries`)
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, 2, q.RowsCount())
+	require.Len(t, q.Warnings(), 1)
+	assert.Contains(t, q.Warnings()[0], "max_result_bytes")
+}
+
+// TestResultLimits_AutoLimit 测试 auto_limit 只为交互式客户端注入 LIMIT，其余会话截断
+func TestResultLimits_AutoLimit(t *testing.T) {
+	s := newResultLimitTestSession(t)
+	s.SetResultLimits(ResultLimits{MaxRows: 2, Action: ResultLimitAutoLimit})
+
+	q, err := s.Query(`SELECT * FROM entries`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.RowsCount())
+	assert.Len(t, q.Warnings(), 1)
+	q.Close()
+
+	s.SetInteractive(true)
+	q, err = s.Query(`SELECT * FROM entries`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.RowsCount())
+	assert.Empty(t, q.Warnings())
+	q.Close()
+
+	// 显式 LIMIT 不被改写
+	q, err = s.Query(`SELECT * FROM entries LIMIT 1`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, q.RowsCount())
+	q.Close()
+}
+
+// TestResultLimits_SessionVariables 测试 SET 会话变量覆盖配置的上限
+func TestResultLimits_SessionVariables(t *testing.T) {
+	s := newResultLimitTestSession(t)
+	s.SetResultLimits(ResultLimits{MaxRows: 2})
+
+	_, err := s.Execute(`SET SESSION max_result_rows = 4`)
+	require.NoError(t, err)
+	_, err = s.Execute(`SET SESSION result_limit_action = 'error'`)
+	require.NoError(t, err)
+
+	limits := s.ResultLimits()
+	assert.Equal(t, int64(4), limits.MaxRows)
+	assert.Equal(t, ResultLimitError, limits.Action)
+
+	_, err = s.Query(`SELECT * FROM entries`)
+	assert.True(t, IsErrorCode(err, ErrCodeResultTooLarge), "unexpected error: %v", err)
+	assert.Equal(t, 4, countRows(t, s, `SELECT * FROM entries WHERE id <= 4`))
+
+	_, err = ParseResultLimitAction("drop")
+	assert.Error(t, err)
+}
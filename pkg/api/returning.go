@@ -0,0 +1,248 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+// queryReturning 执行带 RETURNING 子句的 INSERT/UPDATE/DELETE，以结果集返回受影响的行：
+//   - DELETE 在删除前用相同条件查询 RETURNING 表达式
+//   - INSERT 按主键（VALUES 中的值或分配的自增 ID）回查插入的行，因此包含默认值与生成列；
+//     没有单列主键的表由 VALUES 还原插入的行，只支持返回列名或 *
+//   - UPDATE 需要单列主键：先按条件查出主键，更新后回查新值（更新主键本身的行不会被返回）
+func (s *Session) queryReturning(boundSQL string, returning []string) (*Query, error) {
+	parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeSyntax, "failed to parse SQL")
+	}
+	if !parseResult.Success {
+		return nil, NewError(ErrCodeSyntax, "SQL parse error: "+parseResult.Error, nil)
+	}
+
+	stmt := parseResult.Statement
+	switch {
+	case stmt.Type == parser.SQLTypeDelete && stmt.Delete != nil:
+		selectSQL, ok := parser.DeleteToSelect(boundSQL, returning)
+		if !ok {
+			return nil, NewError(ErrCodeNotSupported, "unsupported DELETE ... RETURNING statement", nil)
+		}
+		deleted, err := s.queryBound(selectSQL)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.executeBound(boundSQL); err != nil {
+			return nil, err
+		}
+		return NewQuery(s, deleted.result, boundSQL, nil), nil
+
+	case stmt.Type == parser.SQLTypeUpdate && stmt.Update != nil:
+		info, err := s.TableInfo(stmt.Update.Table)
+		if err != nil {
+			return nil, err
+		}
+		pk := primaryKeyColumn(info)
+		if pk == "" {
+			return nil, NewError(ErrCodeNotSupported, "UPDATE ... RETURNING requires a single-column primary key", nil)
+		}
+		selectSQL, ok := parser.UpdateToSelect(boundSQL, []string{security.QuoteIdentifier(pk)})
+		if !ok {
+			return nil, NewError(ErrCodeNotSupported, "unsupported UPDATE ... RETURNING statement", nil)
+		}
+		matched, err := s.queryBound(selectSQL)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]interface{}, 0, len(matched.result.Rows))
+		for _, row := range matched.result.Rows {
+			keys = append(keys, row[pk])
+		}
+		if _, err := s.executeBound(boundSQL); err != nil {
+			return nil, err
+		}
+		return s.selectReturning(stmt.Update.Database, info.Name, pk, keys, returning)
+
+	case stmt.Type == parser.SQLTypeInsert && stmt.Insert != nil:
+		info, err := s.TableInfo(stmt.Insert.Table)
+		if err != nil {
+			return nil, err
+		}
+		pk := primaryKeyColumn(info)
+		var columns []returningColumn
+		if pk == "" {
+			// 执行前校验，避免插入成功却无法返回结果
+			if columns, err = returningColumns(info, returning); err != nil {
+				return nil, err
+			}
+		}
+		result, err := s.executeBound(boundSQL)
+		if err != nil {
+			return nil, err
+		}
+		rows := insertedRows(info, stmt.Insert, result.LastInsertID)
+		if pk != "" {
+			keys := make([]interface{}, 0, len(rows))
+			for _, row := range rows {
+				if row[pk] != nil {
+					keys = append(keys, row[pk])
+				}
+			}
+			q, err := s.selectReturning(stmt.Insert.Database, info.Name, pk, keys, returning)
+			if err == nil && q.result != nil {
+				q.result.Warnings = append(result.Warnings, q.result.Warnings...)
+			}
+			return q, err
+		}
+		return NewQuery(s, projectReturning(rows, columns, result.Warnings), boundSQL, nil), nil
+	}
+	return nil, NewError(ErrCodeNotSupported, "RETURNING is only supported for INSERT, UPDATE and DELETE statements", nil)
+}
+
+// selectReturning 按主键查询 RETURNING 表达式
+func (s *Session) selectReturning(database, table, pk string, keys []interface{}, returning []string) (*Query, error) {
+	where := "1 = 0"
+	if len(keys) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+		var err error
+		where, err = bindParams(security.QuoteIdentifier(pk)+" IN ("+placeholders+")", keys)
+		if err != nil {
+			return nil, WrapError(err, ErrCodeInvalidParam, "failed to bind primary keys")
+		}
+	}
+	return s.queryBound("SELECT " + strings.Join(returning, ", ") +
+		" FROM " + security.QuoteQualifiedIdentifier(database, table) + " WHERE " + where)
+}
+
+// primaryKeyColumn 返回表的单列主键，没有主键或为复合主键时返回空串
+func primaryKeyColumn(info *domain.TableInfo) string {
+	pk := ""
+	for _, col := range info.Columns {
+		if col.Primary {
+			if pk != "" {
+				return ""
+			}
+			pk = col.Name
+		}
+	}
+	return pk
+}
+
+// returningColumn INSERT ... RETURNING 的一个输出列
+type returningColumn struct {
+	domain.ColumnInfo
+	source string // 表中的列名
+}
+
+// returningColumns 解析 INSERT ... RETURNING 的列：* 或 列名 [[AS] 别名]
+func returningColumns(info *domain.TableInfo, returning []string) ([]returningColumn, error) {
+	var columns []returningColumn
+	for _, item := range returning {
+		if item == "*" {
+			for _, col := range info.Columns {
+				columns = append(columns, returningColumn{ColumnInfo: col, source: col.Name})
+			}
+			continue
+		}
+
+		fields := strings.Fields(item)
+		if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+			fields = []string{fields[0], fields[2]}
+		}
+		if len(fields) > 2 {
+			return nil, NewError(ErrCodeNotSupported, fmt.Sprintf("INSERT ... RETURNING on a table without primary key supports only column names, got '%s'", item), nil)
+		}
+		name := strings.Trim(fields[0], "`")
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = strings.Trim(name[dot+1:], "`")
+		}
+		found := false
+		for _, col := range info.Columns {
+			if strings.EqualFold(col.Name, name) {
+				out := col
+				if len(fields) == 2 {
+					out.Name = strings.Trim(fields[1], "`")
+				}
+				columns = append(columns, returningColumn{ColumnInfo: out, source: col.Name})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, NewError(ErrCodeNotSupported, fmt.Sprintf("INSERT ... RETURNING on a table without primary key supports only column names, got '%s'", item), nil)
+		}
+	}
+	return columns, nil
+}
+
+// projectReturning 按 RETURNING 列投影由 VALUES 还原的行
+func projectReturning(rows []domain.Row, columns []returningColumn, warnings []string) *domain.QueryResult {
+	projected := make([]domain.Row, len(rows))
+	for i, row := range rows {
+		projected[i] = make(domain.Row, len(columns))
+		for _, col := range columns {
+			projected[i][col.Name] = row[col.source]
+		}
+	}
+	resultColumns := make([]domain.ColumnInfo, len(columns))
+	for i, col := range columns {
+		resultColumns[i] = col.ColumnInfo
+	}
+	return &domain.QueryResult{
+		Columns:  resultColumns,
+		Rows:     projected,
+		Total:    int64(len(projected)),
+		Warnings: warnings,
+	}
+}
+
+// insertedRows 由 INSERT 的 VALUES 还原插入的行：未指定的自增列按 lastInsertID
+// （最后一行的 ID）倒推连续分配的值，其余未指定的列取默认值
+func insertedRows(info *domain.TableInfo, stmt *parser.InsertStatement, lastInsertID int64) []domain.Row {
+	names := stmt.Columns
+	if len(names) == 0 {
+		for _, col := range info.Columns {
+			names = append(names, col.Name)
+		}
+	}
+
+	rows := make([]domain.Row, len(stmt.Values))
+	var missingIDs []domain.Row
+	for i, values := range stmt.Values {
+		row := make(domain.Row, len(info.Columns))
+		for j, name := range names {
+			if j < len(values) {
+				row[name] = values[j]
+			}
+		}
+		for _, col := range info.Columns {
+			if row[col.Name] != nil {
+				continue
+			}
+			if col.AutoIncrement {
+				missingIDs = append(missingIDs, row)
+			} else if col.Default != "" {
+				row[col.Name] = col.Default
+			} else {
+				row[col.Name] = nil
+			}
+		}
+		rows[i] = row
+	}
+
+	if lastInsertID > 0 {
+		for _, col := range info.Columns {
+			if !col.AutoIncrement {
+				continue
+			}
+			first := lastInsertID - int64(len(missingIDs)) + 1
+			for i, row := range missingIDs {
+				row[col.Name] = first + int64(i)
+			}
+			break
+		}
+	}
+	return rows
+}
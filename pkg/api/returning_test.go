@@ -0,0 +1,80 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReturning_InsertGeneratedColumns 测试 INSERT ... RETURNING 返回自增 ID 与生成列
+func TestReturning_InsertGeneratedColumns(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE orders (
+		id INT PRIMARY KEY AUTO_INCREMENT,
+		qty INT,
+		price INT,
+		total INT GENERATED ALWAYS AS (qty * price) STORED
+	)`)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`INSERT INTO orders (qty, price) VALUES (2, 5), (3, 7) RETURNING *`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 1, rows[0]["id"])
+	assert.EqualValues(t, 10, rows[0]["total"])
+	assert.EqualValues(t, 2, rows[1]["id"])
+	assert.EqualValues(t, 21, rows[1]["total"])
+
+	rows, err = s.QueryAll(`INSERT INTO orders (id, qty, price) VALUES (10, 1, 1) RETURNING id, total`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 10, rows[0]["id"])
+	assert.EqualValues(t, 1, rows[0]["total"])
+}
+
+// TestReturning_UpdateAndDelete 测试 UPDATE/DELETE ... RETURNING
+func TestReturning_UpdateAndDelete(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	rows, err := s.QueryAll(`UPDATE users SET city = 'Lyon' WHERE city = ? RETURNING id, city`, "Paris")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 1, rows[0]["id"])
+	assert.Equal(t, "Lyon", rows[0]["city"])
+	assert.EqualValues(t, 3, rows[1]["id"])
+
+	// 没有匹配的行时返回空结果集
+	rows, err = s.QueryAll(`UPDATE users SET city = 'Nice' WHERE id = 99 RETURNING id`)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+
+	rows, err = s.QueryAll(`DELETE FROM users WHERE id = 2 RETURNING name, city`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "bob", rows[0]["name"])
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM users`))
+}
+
+// TestReturning_TableWithoutPrimaryKey 测试无主键的表由 VALUES 还原插入的行
+func TestReturning_TableWithoutPrimaryKey(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE events (kind VARCHAR(20), level INT DEFAULT 1)`)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`INSERT INTO events (kind) VALUES ('boot') RETURNING kind, level AS lvl`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "boot", rows[0]["kind"])
+	assert.Equal(t, "1", rows[0]["lvl"])
+
+	// 表达式在插入前被拒绝
+	_, err = s.Query(`INSERT INTO events (kind) VALUES ('x') RETURNING upper(kind)`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeNotSupported), "unexpected error: %v", err)
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM events`))
+
+	_, err = s.Query(`UPDATE events SET level = 2 RETURNING kind`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeNotSupported), "unexpected error: %v", err)
+}
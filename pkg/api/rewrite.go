@@ -0,0 +1,24 @@
+package api
+
+import "github.com/kasuganosora/sqlexec/pkg/parser"
+
+// RegisterRewriteHook adds a query rewrite hook to every session of the DB,
+// including sessions created before the call. Hooks run in registration
+// order: all PreParse functions rewrite the SQL text before it is parsed,
+// then all PostParse functions may modify the parsed statement. Each rewrite
+// that changed something is reported as a Note in the statement diagnostics
+// (SHOW WARNINGS). While any hook is registered the query cache is bypassed,
+// since a rewrite may depend on the session. The returned function
+// unregisters the hook.
+func (db *DB) RegisterRewriteHook(hook parser.RewriteHook) (func(), error) {
+	if err := db.rewriteHooks.Register(hook); err != nil {
+		return nil, NewError(ErrCodeInvalidParam, err.Error(), nil)
+	}
+	name := hook.Name
+	return func() { db.rewriteHooks.Unregister(name) }, nil
+}
+
+// RewriteHooks returns the names of the registered rewrite hooks in execution order
+func (db *DB) RewriteHooks() []string {
+	return db.rewriteHooks.Names()
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRewriteHooks_PreAndPostParse 测试 pre-parse 和 post-parse 钩子按顺序生效并写入诊断区
+func TestRewriteHooks_PreAndPostParse(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	unregisterRoute, err := s.db.RegisterRewriteHook(parser.RewriteHook{
+		Name: "route",
+		PreParse: func(rc parser.RewriteContext, sql string) (string, error) {
+			sql = strings.ReplaceAll(sql, "/*+ shard(1) */ ", "")
+			return strings.ReplaceAll(sql, "FROM people", "FROM users"), nil
+		},
+	})
+	require.NoError(t, err)
+
+	var seenDB string
+	unregisterTenant, err := s.db.RegisterRewriteHook(parser.RewriteHook{
+		Name: "tenant",
+		PostParse: func(rc parser.RewriteContext, stmt *parser.SQLStatement) (bool, error) {
+			if stmt.Select == nil || stmt.Select.From != "users" {
+				return false, nil
+			}
+			seenDB = rc.Database
+			tenant := &parser.Expression{
+				Type:     parser.ExprTypeOperator,
+				Operator: "eq",
+				Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: "city"},
+				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: "Paris"},
+			}
+			if stmt.Select.Where != nil {
+				tenant = &parser.Expression{Type: parser.ExprTypeOperator, Operator: "and", Left: stmt.Select.Where, Right: tenant}
+			}
+			stmt.Select.Where = tenant
+			return true, nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"route", "tenant"}, s.db.RewriteHooks())
+
+	_, err = s.db.RegisterRewriteHook(parser.RewriteHook{Name: "route", PreParse: func(_ parser.RewriteContext, sql string) (string, error) { return sql, nil }})
+	assert.Error(t, err)
+	_, err = s.db.RegisterRewriteHook(parser.RewriteHook{Name: "empty"})
+	assert.Error(t, err)
+
+	rows, err := s.QueryAll(`SELECT /*+ shard(1) */ name FROM people ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Alice", rows[0]["name"])
+	assert.Equal(t, "Carol", rows[1]["name"])
+	assert.Equal(t, "test", seenDB)
+
+	var notes []string
+	for _, d := range s.Diagnostics() {
+		if d.Level == session.DiagnosticNote {
+			notes = append(notes, d.Message)
+		}
+	}
+	require.Len(t, notes, 2)
+	assert.Contains(t, notes[0], "rewrite hook 'route'")
+	assert.Contains(t, notes[0], "FROM users")
+	assert.Contains(t, notes[1], "rewrite hook 'tenant'")
+
+	unregisterTenant()
+	unregisterRoute()
+	assert.Empty(t, s.db.RewriteHooks())
+	rows, err = s.QueryAll(`SELECT name FROM users ORDER BY id`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SchemaChange is a table or column change found by comparing two schema snapshots
+type SchemaChange = application.SchemaChange
+
+// schemaWatcher polls datasources that implement domain.SchemaChangeSource
+// and invalidates host caches for the tables they report as changed. It also
+// runs the periodic schema snapshots that detect drift in any datasource.
+type schemaWatcher struct {
+	mu           sync.Mutex
+	versions     map[string]int64
+	unsupported  map[string]bool
+	stop         chan struct{}
+	done         chan struct{}
+	snapshotStop chan struct{}
+	snapshotDone chan struct{}
+}
+
+func newSchemaWatcher() *schemaWatcher {
+	return &schemaWatcher{
+		versions:    make(map[string]int64),
+		unsupported: make(map[string]bool),
+	}
+}
+
+// InvalidateTable drops every host-side cache entry that depends on the
+// schema of tableName: query results and optimizer plans. Parsed statements
+// do not depend on the schema and stay cached.
+func (db *DB) InvalidateTable(tableName string) {
+	db.ClearTableCache(tableName)
+	optimizer.InvalidatePlanCaches()
+}
+
+// PollSchemaChanges asks every datasource that reports schema changes for
+// changes since the last poll and invalidates the affected tables.
+// Datasources that do not support change reporting are skipped from then on.
+func (db *DB) PollSchemaChanges(ctx context.Context) error {
+	w := db.schemaWatcher
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for name, ds := range db.dsManager.GetAllDataSources() {
+		source, ok := ds.(domain.SchemaChangeSource)
+		if !ok || w.unsupported[name] {
+			continue
+		}
+
+		since := w.versions[name]
+		changes, version, err := source.GetSchemaChanges(ctx, since)
+		if err != nil {
+			var unsupported *domain.ErrUnsupportedOperation
+			if errors.As(err, &unsupported) {
+				w.unsupported[name] = true
+				continue
+			}
+			errs = append(errs, err)
+			continue
+		}
+		w.versions[name] = version
+
+		for _, change := range changes {
+			db.logger.Info("Schema change reported by datasource '%s': table %s %s", name, change.Table, change.Type)
+			db.InvalidateTable(change.Table)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StartSchemaWatcher polls for schema changes every interval until
+// StopSchemaWatcher or Close is called. Calling it again restarts the poller.
+func (db *DB) StartSchemaWatcher(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	db.StopSchemaWatcher()
+
+	w := db.schemaWatcher
+	w.mu.Lock()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	w.stop, w.done = stop, done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := db.PollSchemaChanges(context.Background()); err != nil {
+					db.logger.Warn("Polling schema changes failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// StopSchemaWatcher stops the background schema change poller, if running
+func (db *DB) StopSchemaWatcher() {
+	w := db.schemaWatcher
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// SnapshotSchemas snapshots the table schemas of every datasource and returns
+// what changed since the previous snapshot: added and removed tables, and
+// added, removed and retyped columns. The first snapshot of a datasource is
+// only a baseline. Changed tables are invalidated and the changes are kept
+// for SchemaChanges and SHOW SCHEMA CHANGES.
+func (db *DB) SnapshotSchemas(ctx context.Context) ([]SchemaChange, error) {
+	changes, err := db.dsManager.SnapshotSchemas(ctx)
+	invalidated := make(map[string]bool)
+	for _, change := range changes {
+		db.logger.Info("Schema drift in datasource '%s': table %s %s %s", change.Database, change.Table, change.Column, change.Change)
+		if !invalidated[change.Table] {
+			invalidated[change.Table] = true
+			db.InvalidateTable(change.Table)
+		}
+	}
+	return changes, err
+}
+
+// SchemaChanges returns the schema changes found by previous snapshots, oldest
+// first. A non-empty database limits the result to that datasource.
+func (db *DB) SchemaChanges(database string) []SchemaChange {
+	return db.dsManager.SchemaChanges(database)
+}
+
+// StartSchemaSnapshots snapshots the datasource schemas every interval until
+// StopSchemaSnapshots or Close is called. A baseline snapshot is taken right
+// away. Calling it again restarts the snapshotter.
+func (db *DB) StartSchemaSnapshots(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	db.StopSchemaSnapshots()
+
+	w := db.schemaWatcher
+	w.mu.Lock()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	w.snapshotStop, w.snapshotDone = stop, done
+	w.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		snapshot := func() {
+			if _, err := db.SnapshotSchemas(context.Background()); err != nil {
+				db.logger.Warn("Snapshotting schemas failed: %v", err)
+			}
+		}
+		snapshot()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				snapshot()
+			}
+		}
+	}()
+}
+
+// StopSchemaSnapshots stops the background schema snapshotter, if running
+func (db *DB) StopSchemaSnapshots() {
+	w := db.schemaWatcher
+	w.mu.Lock()
+	stop, done := w.snapshotStop, w.snapshotDone
+	w.snapshotStop, w.snapshotDone = nil, nil
+	w.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changeReportingDataSource reports a fixed change log through GetSchemaChanges
+type changeReportingDataSource struct {
+	*mockDataSource
+	changes     []domain.SchemaChange
+	calls       []int64
+	unsupported bool
+}
+
+func (m *changeReportingDataSource) GetSchemaChanges(ctx context.Context, since int64) ([]domain.SchemaChange, int64, error) {
+	m.calls = append(m.calls, since)
+	if m.unsupported {
+		return nil, since, domain.NewErrUnsupportedOperation("mock", "get_changes")
+	}
+	return m.changes[since:], int64(len(m.changes)), nil
+}
+
+func TestDB_PollSchemaChanges(t *testing.T) {
+	db, err := NewDB(nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ds := &changeReportingDataSource{mockDataSource: newMockDataSource()}
+	require.NoError(t, db.RegisterDataSource("plugin", ds))
+
+	result := &domain.QueryResult{Rows: []domain.Row{{"id": 1}}}
+	db.cache.Set("SELECT * FROM users", nil, result)
+	db.cache.Set("SELECT * FROM orders", nil, result)
+
+	// No changes yet: nothing is invalidated
+	require.NoError(t, db.PollSchemaChanges(context.Background()))
+	_, found := db.cache.Get("SELECT * FROM users", nil)
+	assert.True(t, found)
+
+	ds.changes = append(ds.changes, domain.SchemaChange{Table: "users", Type: domain.SchemaChangeAltered})
+	require.NoError(t, db.PollSchemaChanges(context.Background()))
+	_, found = db.cache.Get("SELECT * FROM users", nil)
+	assert.False(t, found)
+	_, found = db.cache.Get("SELECT * FROM orders", nil)
+	assert.True(t, found)
+
+	// The next poll continues from the last reported version
+	require.NoError(t, db.PollSchemaChanges(context.Background()))
+	assert.Equal(t, []int64{0, 0, 1}, ds.calls)
+}
+
+func TestDB_PollSchemaChanges_Unsupported(t *testing.T) {
+	db, err := NewDB(nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ds := &changeReportingDataSource{mockDataSource: newMockDataSource(), unsupported: true}
+	require.NoError(t, db.RegisterDataSource("plugin", ds))
+
+	require.NoError(t, db.PollSchemaChanges(context.Background()))
+	require.NoError(t, db.PollSchemaChanges(context.Background()))
+	assert.Len(t, ds.calls, 1)
+}
+
+// TestDB_SnapshotSchemas 测试定期快照比较表结构：第一次快照只作为基准，之后报告新增、删除的表和列以及类型改变的列
+func TestDB_SnapshotSchemas(t *testing.T) {
+	db := newTestDB(t, nil, accountsFixture...)
+	s := db.Session()
+	defer s.Close()
+	_, err := s.Execute(`CREATE TABLE events (id INT PRIMARY KEY, payload VARCHAR(50), seen INT)`)
+	require.NoError(t, err)
+
+	changes, err := db.SnapshotSchemas(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// 上游重建了表
+	for _, sql := range []string{
+		`DROP TABLE events`,
+		`CREATE TABLE events (id INT PRIMARY KEY, payload TEXT, source VARCHAR(20))`,
+		`DROP TABLE accounts`,
+		`CREATE TABLE audit (id INT PRIMARY KEY)`,
+	} {
+		_, err := s.Execute(sql)
+		require.NoError(t, err, sql)
+	}
+	changes, err = db.SnapshotSchemas(context.Background())
+	require.NoError(t, err)
+	var summary []string
+	for _, c := range changes {
+		summary = append(summary, c.Table+" "+c.Column+" "+c.Change)
+	}
+	assert.Equal(t, []string{
+		"accounts  table_removed",
+		"audit  table_added",
+		"events payload column_retyped",
+		"events source column_added",
+		"events seen column_removed",
+	}, summary)
+	assert.Equal(t, "test", changes[2].Database)
+	assert.NotEqual(t, changes[2].OldType, changes[2].NewType)
+
+	// 没有变化时不再报告
+	changes, err = db.SnapshotSchemas(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// 记录的变化可以通过 SHOW SCHEMA CHANGES 查询
+	assert.Len(t, db.SchemaChanges("test"), 5)
+	assert.Empty(t, db.SchemaChanges("other"))
+	rows, err := s.QueryAll(`SHOW SCHEMA CHANGES FROM test`)
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	assert.Equal(t, "accounts", rows[0]["Table"])
+	assert.Nil(t, rows[0]["Column"])
+	assert.Equal(t, "column_retyped", rows[2]["Change"])
+	assert.NotNil(t, rows[2]["Old_type"])
+	assert.Nil(t, rows[3]["Old_type"])
+
+	_, err = s.QueryAll(`SHOW SCHEMA CHANGES FROM nope`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/dataexport"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSelectIntoOutfile 测试 SELECT ... INTO OUTFILE 以 CSV、JSON 和 Parquet 格式导出查询结果
+func TestSelectIntoOutfile(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	dir := t.TempDir()
+	s.db.config.OutfileDirs = []string{dir}
+
+	csvPath := filepath.Join(dir, "users.csv")
+	res, err := s.Execute(fmt.Sprintf(`SELECT id, name FROM users WHERE city = 'Paris' ORDER BY id INTO OUTFILE '%s' FIELDS TERMINATED BY ';'`, csvPath))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.RowsAffected)
+	data, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "id;name\n1;Alice\n3;Carol\n", string(data))
+
+	// 文件已存在时报错，不覆盖已有内容
+	_, err = s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s'`, csvPath))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	data, err = os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "id;name\n1;Alice\n3;Carol\n", string(data))
+
+	jsonPath := filepath.Join(dir, "users.jsonl")
+	res, err = s.Execute(fmt.Sprintf(`SELECT name, city FROM users ORDER BY id INTO OUTFILE '%s' FORMAT JSON`, jsonPath))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+	data, err = os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"Alice","city":"Paris"}`+"\n"+`{"name":"bob","city":"Berlin"}`+"\n"+`{"name":"Carol","city":"Paris"}`+"\n", string(data))
+
+	// 导出的 Parquet 文件可以再导入
+	parquetPath := filepath.Join(dir, "users.parquet")
+	q, err := s.Query(fmt.Sprintf(`SELECT id, name, city FROM users INTO OUTFILE '%s' FORMAT PARQUET WITH (compression = 'gzip')`, parquetPath))
+	require.NoError(t, err)
+	require.NotNil(t, q.ExecResult())
+	assert.EqualValues(t, 3, q.ExecResult().RowsAffected)
+	q.Close()
+	res, err = s.Execute(fmt.Sprintf(`IMPORT DATA INFILE '%s' FORMAT PARQUET INTO TABLE users_copy`, parquetPath))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+	rows, err := s.QueryAll(`SELECT name FROM users_copy WHERE city = 'Berlin'`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "bob", rows[0]["name"])
+
+	_, err = s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s' FORMAT XML`, filepath.Join(dir, "users.xml")))
+	assert.Error(t, err)
+	_, err = s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s' FORMAT JSON WITH (header = false)`, filepath.Join(dir, "x.jsonl")))
+	assert.Error(t, err)
+	_, err = s.Execute(fmt.Sprintf(`SELECT * FROM missing INTO OUTFILE '%s'`, filepath.Join(dir, "missing.csv")))
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "missing.csv"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestSelectIntoOutfileDirs 测试导出路径必须位于 outfile_dirs 允许的目录中
+func TestSelectIntoOutfileDirs(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	_, err := s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s'`, filepath.Join(dir, "a.csv")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "without outfile_dirs")
+	assert.Equal(t, ErrCodeReadOnly, err.(*Error).Code)
+
+	s.db.config.OutfileDirs = []string{dir}
+	for _, path := range []string{
+		filepath.Join(outside, "a.csv"),
+		filepath.Join(dir, "..", filepath.Base(outside), "a.csv"),
+		dir,
+	} {
+		_, err = s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s'`, path))
+		require.Error(t, err, path)
+		assert.Equal(t, ErrCodeReadOnly, err.(*Error).Code, path)
+	}
+
+	// 借助符号链接写到允许的目录之外
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(outside, link))
+	_, err = s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s'`, filepath.Join(link, "a.csv")))
+	require.Error(t, err)
+	_, err = os.Stat(filepath.Join(outside, "a.csv"))
+	assert.True(t, os.IsNotExist(err))
+
+	// 允许目录下的子目录
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	_, err = s.Execute(fmt.Sprintf(`SELECT * FROM users INTO OUTFILE '%s'`, filepath.Join(dir, "sub", "a.csv")))
+	require.NoError(t, err)
+}
+
+// TestWriteOutfileRows 测试导出时逐行写出并释放已写出的行，取消查询时停止写出
+func TestWriteOutfileRows(t *testing.T) {
+	opts, err := dataexport.ParseOptions("CSV", nil)
+	require.NoError(t, err)
+	result := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "id"}},
+		Rows:    []domain.Row{{"id": 1}, {"id": 2}},
+	}
+	rows := result.Rows
+	var sb strings.Builder
+	n, err := writeOutfileRows(context.Background(), &sb, result, opts)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, "id\n1\n2\n", sb.String())
+	assert.Nil(t, result.Rows)
+	assert.Equal(t, []domain.Row{nil, nil}, rows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result.Rows = []domain.Row{{"id": 1}}
+	n, err = writeOutfileRows(ctx, &strings.Builder{}, result, opts)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, n)
+}
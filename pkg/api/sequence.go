@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// bindSequenceFunctions 对语句中的 NEXTVAL、LASTVAL、SETVAL 等序列函数求值并替换为字面量
+func (s *Session) bindSequenceFunctions(sql string) (string, error) {
+	if s.coreSession == nil {
+		return sql, nil
+	}
+	ctx := context.Background()
+	bound, err := parser.BindSequenceFunctions(sql, func(call parser.SequenceCall) (string, error) {
+		switch call.Func {
+		case parser.SequenceNextVal:
+			value, err := s.coreSession.NextSequenceValue(ctx, call.Name)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(value, 10), nil
+		case parser.SequenceLastVal:
+			if value, ok := s.coreSession.LastSequenceValue(call.Name); ok {
+				return strconv.FormatInt(value, 10), nil
+			}
+		case parser.SequenceSetVal:
+			changed, err := s.coreSession.SetSequenceValue(ctx, call.Name, call.Value, call.Used)
+			if err != nil {
+				return "", err
+			}
+			if changed {
+				return strconv.FormatInt(call.Value, 10), nil
+			}
+		}
+		return "NULL", nil
+	})
+	if err != nil {
+		return "", WrapError(err, ErrCodeInvalidParam, "failed to evaluate sequence function")
+	}
+	return bound, nil
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSequence_NextValAndLastVal 测试 NEXTVAL / NEXT VALUE FOR 取值与会话级的 LASTVAL
+func TestSequence_NextValAndLastVal(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE SEQUENCE seq START WITH 10 INCREMENT BY 5`)
+	require.NoError(t, err)
+
+	// 还没有取过值时 LASTVAL 为 NULL
+	rows, err := s.QueryAll(`SELECT LASTVAL(seq)`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Nil(t, rows[0]["LASTVAL(seq)"])
+
+	rows, err = s.QueryAll(`SELECT NEXTVAL(seq), NEXT VALUE FOR seq`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 10, rows[0]["NEXTVAL(seq)"])
+	assert.EqualValues(t, 15, rows[0]["NEXT VALUE FOR seq"])
+
+	rows, err = s.QueryAll(`SELECT LASTVAL(seq) AS last, PREVIOUS VALUE FOR seq AS prev`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 15, rows[0]["last"])
+	assert.EqualValues(t, 15, rows[0]["prev"])
+
+	// LASTVAL 只属于本会话，序列的位置是共享的
+	other := s.db.Session()
+	defer other.Close()
+	rows, err = other.QueryAll(`SELECT LASTVAL(seq) AS last, NEXTVAL(seq) AS next`)
+	require.NoError(t, err)
+	assert.Nil(t, rows[0]["last"])
+	assert.EqualValues(t, 20, rows[0]["next"])
+}
+
+// TestSequence_ColumnDefault 测试 DEFAULT NEXTVAL(s) 列在 INSERT 没有给出值时取序列的下一个值
+func TestSequence_ColumnDefault(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE SEQUENCE order_seq START WITH 100`)
+	require.NoError(t, err)
+	_, err = s.Execute(`CREATE TABLE orders (id BIGINT DEFAULT NEXTVAL(order_seq), item VARCHAR(20))`)
+	require.NoError(t, err)
+
+	_, err = s.Execute(`INSERT INTO orders (item) VALUES ('a'), ('b')`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO orders (id, item) VALUES (7, 'c')`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO orders (id, item) VALUES (NEXT VALUE FOR order_seq, 'd')`)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`SELECT id, item FROM orders ORDER BY item`)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	for i, want := range []int64{100, 101, 7, 102} {
+		assert.EqualValues(t, want, rows[i]["id"], "row %d", i)
+	}
+}
+
+// TestSequence_AlterSetValAndCycle 测试 ALTER SEQUENCE、SETVAL 与 CYCLE
+func TestSequence_AlterSetValAndCycle(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE SEQUENCE s MINVALUE 1 MAXVALUE 3 CACHE 2`)
+	require.NoError(t, err)
+	next := func() interface{} {
+		rows, err := s.QueryAll(`SELECT NEXTVAL(s) AS v`)
+		require.NoError(t, err)
+		return rows[0]["v"]
+	}
+	assert.EqualValues(t, 1, next())
+	assert.EqualValues(t, 2, next())
+	assert.EqualValues(t, 3, next())
+
+	// 不循环的序列用完后报错
+	_, err = s.QueryAll(`SELECT NEXTVAL(s)`)
+	require.Error(t, err)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrSequenceRunOut), "error: %v", err)
+
+	_, err = s.Execute(`ALTER SEQUENCE s CYCLE`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, next())
+
+	_, err = s.Execute(`ALTER SEQUENCE s RESTART WITH 2`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, next())
+
+	// SETVAL 只能向前移动
+	rows, err := s.QueryAll(`SELECT SETVAL(s, 1) AS v`)
+	require.NoError(t, err)
+	assert.Nil(t, rows[0]["v"])
+	rows, err = s.QueryAll(`SELECT SETVAL(s, 2, FALSE) AS v`)
+	require.NoError(t, err)
+	assert.Nil(t, rows[0]["v"])
+	rows, err = s.QueryAll(`SELECT SETVAL(s, 3, FALSE) AS v`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, rows[0]["v"])
+	assert.EqualValues(t, 3, next())
+
+	// 序列保存为表，可以直接查看状态
+	rows, err = s.QueryAll(`SELECT next_not_cached_value, cycle_count FROM s`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 4, rows[0]["next_not_cached_value"])
+	assert.EqualValues(t, 1, rows[0]["cycle_count"])
+}
+
+// TestSequence_DDLErrors 测试序列 DDL 的 IF [NOT] EXISTS 与错误
+func TestSequence_DDLErrors(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE SEQUENCE s`)
+	require.NoError(t, err)
+	_, err = s.Execute(`CREATE SEQUENCE s`)
+	require.Error(t, err)
+	_, err = s.Execute(`CREATE SEQUENCE IF NOT EXISTS s`)
+	require.NoError(t, err)
+
+	_, err = s.Execute(`CREATE SEQUENCE bad START WITH 0`)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrSequenceInvalidData), "error: %v", err)
+
+	_, err = s.QueryAll(`SELECT NEXTVAL(users)`)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrNotSequence), "error: %v", err)
+	_, err = s.Execute(`DROP SEQUENCE users`)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrNotSequence), "error: %v", err)
+
+	_, err = s.Execute(`DROP SEQUENCE s`)
+	require.NoError(t, err)
+	_, err = s.Execute(`DROP SEQUENCE IF EXISTS s`)
+	require.NoError(t, err)
+	_, err = s.Execute(`DROP SEQUENCE s`)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrUnknownSequences), "error: %v", err)
+	_, err = s.Execute(`ALTER SEQUENCE IF EXISTS s INCREMENT BY 2`)
+	require.NoError(t, err)
+}
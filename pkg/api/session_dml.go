@@ -1,8 +1,8 @@
 package api
 
 import (
-	"context"
 	"fmt"
+	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
@@ -12,23 +12,80 @@ import (
 // Supports parameter binding with ? placeholders
 // For SELECT, SHOW, DESCRIBE, and EXPLAIN statements, use Query() or Explain() method instead
 func (s *Session) Execute(sql string, args ...interface{}) (*Result, error) {
+	start := time.Now()
+	diagnostics := s.beginDiagnostics(sql)
+	stats := s.beginQueryStats(sql)
+	queryID := s.beginQueryID()
+	result, err := s.execute(sql, args...)
+	s.finishQueryID()
+	recordExecute(s, sql, start, result, err)
+	if stats != nil {
+		var affected int64
+		if result != nil {
+			affected = result.RowsAffected
+		}
+		s.appendStatsInfo(result, s.finishQueryStats(stats, start, nil, affected))
+	}
+	s.appendQueryIDInfo(result, queryID)
+	if diagnostics {
+		s.recordDiagnostics(result.warnings(), err)
+	}
+	return result, err
+}
+
+// execute Execute 的实际实现
+func (s *Session) execute(sql string, args ...interface{}) (*Result, error) {
 	s.mu.RLock()
 	if s.err != nil {
 		s.mu.RUnlock()
 		return nil, s.err
 	}
 	s.mu.RUnlock()
+	if err := s.checkFork(); err != nil {
+		return nil, err
+	}
+
+	// Execute 不返回结果行，RETURNING 子句被忽略
+	translation, err := s.translateDialect(sql)
+	if err != nil {
+		return nil, err
+	}
+	sql = translation.SQL
 
 	// Bind parameters if provided
 	boundSQL := sql
 	if len(args) > 0 {
-		var err error
 		boundSQL, err = bindParams(sql, args)
 		if err != nil {
 			return nil, WrapError(err, ErrCodeInvalidParam, "failed to bind parameters")
 		}
 	}
+	boundSQL = s.bindUserVariables(boundSQL)
+	if boundSQL, err = s.bindSequenceFunctions(boundSQL); err != nil {
+		return nil, err
+	}
+	boundSQL = s.bindLastQueryStats(boundSQL)
+	release, err := s.admitWorkload(boundSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.executeBound(boundSQL)
+}
 
+// executeBound 执行已改写方言并绑定参数的语句，带幂等键的写入语句重复提交时返回第一次执行的结果
+func (s *Session) executeBound(boundSQL string) (*Result, error) {
+	if key, sql := s.idempotencyKey(boundSQL); key != "" {
+		return s.db.idempotency.execute(key, sql, func() (*Result, error) {
+			return s.executeStatement(boundSQL)
+		})
+	}
+	return s.executeStatement(boundSQL)
+}
+
+// executeStatement 执行已改写方言并绑定参数的语句
+func (s *Session) executeStatement(boundSQL string) (*Result, error) {
 	s.logger.Debug("Execute: %s", boundSQL)
 
 	// Parse SQL to determine statement type
@@ -47,9 +104,25 @@ func (s *Session) Execute(sql string, args ...interface{}) (*Result, error) {
 		return nil, NewError(ErrCodeInternal, "information_schema is read-only: DML operations are not supported", nil)
 	}
 
-	ctx := context.Background()
+	if err := s.checkWritePolicy(parseResult.Statement); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuota(parseResult.Statement); err != nil {
+		return nil, err
+	}
+
+	ctx := s.executionContext()
 	var result *domain.QueryResult
 
+	// 影子迁移中的表由当前的主数据源执行，成功后镜像到另一个数据源
+	baseCtx := ctx
+	shadows := s.shadowedTables(parseResult.Statement)
+	if len(shadows) > 0 {
+		if ctx, err = s.shadowContext(ctx, shadows, ShadowTable.Primary); err != nil {
+			return nil, err
+		}
+	}
+
 	switch parseResult.Statement.Type {
 	case parser.SQLTypeInsert:
 		result, err = s.coreSession.ExecuteInsert(ctx, boundSQL, nil)
@@ -76,12 +149,25 @@ func (s *Session) Execute(sql string, args ...interface{}) (*Result, error) {
 		result, err = s.coreSession.ExecuteDrop(ctx, boundSQL)
 	case parser.SQLTypeAlter:
 		result, err = s.coreSession.ExecuteAlter(ctx, boundSQL)
-	case parser.SQLTypeUse:
+	case parser.SQLTypeOptimize:
+		result, err = s.coreSession.ExecuteOptimize(ctx, boundSQL)
+	case parser.SQLTypeUse, parser.SQLTypeExport, parser.SQLTypeImport, parser.SQLTypeGenerate,
+		parser.SQLTypeImportData, parser.SQLTypeUndrop, parser.SQLTypeFlashback,
+		parser.SQLTypeCreateSeq, parser.SQLTypeAlterSeq, parser.SQLTypeDropSeq, parser.SQLTypeShadow:
 		result, err = s.coreSession.ExecuteQuery(ctx, boundSQL)
+	case parser.SQLTypeSelectInto:
+		return s.executeSelectInto(ctx, parseResult.Statement.SelectInto)
+	case parser.SQLTypeSetUserVar:
+		return s.executeSetUserVar(ctx, parseResult.Statement.UserVariables)
+	case parser.SQLTypeBackup:
+		return s.executeBackup(ctx, parseResult.Statement.Backup)
+	case parser.SQLTypeRestore:
+		return s.executeRestore(ctx, parseResult.Statement.Restore)
 	case parser.SQLTypeSet:
 		// SET NAMES, SET CHARACTER SET, SET @@variable, etc.
 		result, err = s.coreSession.ExecuteQuery(ctx, boundSQL)
-	case parser.SQLTypeSelect, parser.SQLTypeShow, parser.SQLTypeDescribe, parser.SQLTypeExplain:
+	case parser.SQLTypeSelect, parser.SQLTypeShow, parser.SQLTypeDescribe, parser.SQLTypeExplain,
+		parser.SQLTypeChecksum, parser.SQLTypeCheck, parser.SQLTypeDiff:
 		return nil, NewError(ErrCodeInvalidParam, fmt.Sprintf("use Query() method for %s statements (or Explain() for EXPLAIN)", parseResult.Statement.Type), nil)
 	default:
 		return nil, NewError(ErrCodeNotSupported, fmt.Sprintf("unsupported statement type: %v", parseResult.Statement.Type), nil)
@@ -94,6 +180,9 @@ func (s *Session) Execute(sql string, args ...interface{}) (*Result, error) {
 		}
 		return nil, WrapError(err, ErrCodeInternal, "failed to execute statement")
 	}
+	if len(shadows) > 0 {
+		s.mirrorWrite(baseCtx, parseResult.Statement.Type, boundSQL, shadows)
+	}
 
 	// Clear cache for affected table
 	if s.cacheEnabled {
@@ -121,6 +210,16 @@ func (s *Session) Execute(sql string, args ...interface{}) (*Result, error) {
 			if parseResult.Statement.Drop != nil {
 				tableName = parseResult.Statement.Drop.Name
 			}
+		case parser.SQLTypeUndrop:
+			tableName = parseResult.Statement.Undrop.Table
+		case parser.SQLTypeFlashback:
+			tableName = parseResult.Statement.Flashback.Table
+		case parser.SQLTypeShadow:
+			_, tableName = splitTableName(parseResult.Statement.Shadow.Table)
+		case parser.SQLTypeAlterSeq, parser.SQLTypeDropSeq:
+			for _, name := range parseResult.Statement.Sequence.Names {
+				s.db.cache.ClearTable(name)
+			}
 		}
 		if tableName != "" {
 			s.db.cache.ClearTable(tableName)
@@ -141,5 +240,10 @@ func (s *Session) Execute(sql string, args ...interface{}) (*Result, error) {
 		}
 	}
 
-	return NewResult(result.Total, lastInsertID, nil), nil
+	res := NewResult(result.Total, lastInsertID, nil)
+	res.Warnings = result.Warnings
+	if len(result.Rows) > 0 {
+		res.Info, _ = result.Rows[0]["info"].(string)
+	}
+	return res, nil
 }
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 
+	"github.com/kasuganosora/sqlexec/pkg/session"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
 )
 
@@ -27,6 +28,24 @@ func (s *Session) SetCurrentDB(dbName string) {
 	}
 }
 
+// UseDatabase switches the current database with USE semantics
+// Unqualified table names then resolve against the datasource registered under dbName
+func (s *Session) UseDatabase(dbName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.coreSession.UseDatabase(dbName); err != nil {
+		return WrapError(err, ErrCodeInternal, "failed to switch database")
+	}
+	if s.db != nil && s.db.cache != nil {
+		s.db.cache.SetCurrentDB(dbName)
+	}
+	return nil
+}
+
 // SetVirtualDBRegistry 设置虚拟数据库注册表
 func (s *Session) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
 	s.mu.Lock()
@@ -48,6 +67,42 @@ func (s *Session) GetCurrentDB() string {
 	return ""
 }
 
+// TakeSessionStateChanges returns the tracked session state changes (SET of system variables, USE)
+// since the last call, for reporting in OK packets to clients with CLIENT_SESSION_TRACK
+func (s *Session) TakeSessionStateChanges() session.SessionStateChanges {
+	if s.coreSession == nil {
+		return session.SessionStateChanges{}
+	}
+	return s.coreSession.TakeSessionStateChanges()
+}
+
+// Reset resets the session state without closing it
+// Rolls back any active transaction, drops temporary tables, clears session
+// variables and restores the default isolation level. The current database
+// and user are kept; used for COM_RESET_CONNECTION and COM_CHANGE_USER.
+func (s *Session) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.coreSession == nil {
+		return nil
+	}
+
+	if s.coreSession.InTx() {
+		s.logger.Debug("Rolling back uncommitted transaction on reset")
+	}
+	if err := s.coreSession.Reset(context.Background()); err != nil {
+		return WrapError(err, ErrCodeInternal, "failed to reset session")
+	}
+
+	if s.options != nil {
+		s.options.Isolation = IsolationRepeatableRead
+	}
+
+	s.logger.Debug("Session reset")
+	return nil
+}
+
 // Close closes the session and releases resources
 // Temporary tables created in this session are automatically dropped
 func (s *Session) Close() error {
@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/optimizer/plan"
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 )
@@ -16,52 +18,220 @@ type SelectStmt interface {
 
 // Query executes a SELECT, SHOW, or DESCRIBE query and returns a Query object for iterating through results
 // Supports parameter binding with ? placeholders
+// INSERT, UPDATE and DELETE are executed too and return a Query without columns; see Query.ExecResult
 // Example: session.Query("SELECT * FROM users WHERE id = ?", 1)
 func (s *Session) Query(sql string, args ...interface{}) (*Query, error) {
+	start := time.Now()
+	diagnostics := s.beginDiagnostics(sql)
+	stats := s.beginQueryStats(sql)
+	queryID := s.beginQueryID()
+	q, err := s.query(sql, args...)
+	s.finishQueryID()
+	recordQuery(s, sql, start, q, err)
+	if stats != nil {
+		var sent []domain.Row
+		var affected int64
+		if q != nil && q.exec != nil {
+			affected = q.exec.RowsAffected
+		} else if q != nil && q.result != nil {
+			sent = q.result.Rows
+		}
+		last := s.finishQueryStats(stats, start, sent, affected)
+		if q != nil {
+			s.appendStatsInfo(q.exec, last)
+		}
+	}
+	if q != nil {
+		s.appendQueryIDInfo(q.exec, queryID)
+	}
+	if diagnostics {
+		s.recordDiagnostics(q.Warnings(), err)
+	}
+	return q, err
+}
+
+// query Query 的实际实现
+func (s *Session) query(sql string, args ...interface{}) (*Query, error) {
 	s.mu.RLock()
 	if s.err != nil {
 		s.mu.RUnlock()
 		return nil, s.err
 	}
 	s.mu.RUnlock()
+	if err := s.checkFork(); err != nil {
+		return nil, err
+	}
+
+	translation, err := s.translateDialect(sql)
+	if err != nil {
+		return nil, err
+	}
+	sql = translation.SQL
 
 	// Bind parameters if provided
 	boundSQL := sql
 	if len(args) > 0 {
-		var err error
 		boundSQL, err = bindParams(sql, args)
 		if err != nil {
 			return nil, WrapError(err, ErrCodeInvalidParam, "failed to bind parameters")
 		}
 	}
+	boundSQL = s.bindUserVariables(boundSQL)
+	if boundSQL, err = s.bindSequenceFunctions(boundSQL); err != nil {
+		return nil, err
+	}
+	boundSQL = s.bindLastQueryStats(boundSQL)
+	series, err := parser.ParseTimeSeries(boundSQL)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeSyntax, "failed to parse time series query")
+	}
+	if series != nil {
+		boundSQL = series.SQL
+	}
+
+	release, err := s.admitWorkload(boundSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
+	if len(translation.Returning) > 0 {
+		return s.queryReturning(boundSQL, translation.Returning)
+	}
+	q, err := s.queryStatement(boundSQL)
+	if err == nil && series != nil {
+		if q.result, err = fillTimeSeries(q.result, series); err != nil {
+			return nil, err
+		}
+	}
+	return q, err
+}
+
+// queryStatement 按语句的形式选择执行方式
+func (s *Session) queryStatement(boundSQL string) (*Query, error) {
+	if q, ok, err := s.queryPassthrough(boundSQL); ok {
+		return q, err
+	}
+	if q, ok, err := s.queryTableFunctions(boundSQL); ok {
+		return q, err
+	}
+	if q, ok, err := s.queryExec(boundSQL); ok {
+		return q, err
+	}
+	return s.queryBound(boundSQL)
+}
+
+// queryExec 通过 Query 执行 INSERT/UPDATE/DELETE 等不返回结果集的语句（如 MySQL 协议的 COM_QUERY），
+// 返回没有列的 Query，执行结果由 ExecResult 获取；其他语句返回 false
+func (s *Session) queryExec(boundSQL string) (*Query, bool, error) {
+	if s.coreSession == nil {
+		return nil, false, nil
+	}
+	parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL)
+	if err != nil || !parseResult.Success {
+		return nil, false, nil
+	}
+	switch parseResult.Statement.Type {
+	case parser.SQLTypeInsert, parser.SQLTypeUpdate, parser.SQLTypeDelete, parser.SQLTypeTruncate, parser.SQLTypeAlter,
+		parser.SQLTypeSelectInto, parser.SQLTypeBackup, parser.SQLTypeRestore, parser.SQLTypeSetUserVar, parser.SQLTypeShadow:
+	default:
+		return nil, false, nil
+	}
+
+	result, err := s.executeBound(boundSQL)
+	if err != nil {
+		return nil, true, err
+	}
+	q := NewQuery(s, &domain.QueryResult{Total: result.RowsAffected, Warnings: result.Warnings}, boundSQL, nil)
+	q.exec = result
+	return q, true, nil
+}
+
+// queryBound 执行已改写方言并绑定参数的查询
+func (s *Session) queryBound(boundSQL string) (*Query, error) {
 	s.logger.Debug("Query: %s", boundSQL)
 
+	// 只读、写入策略或配额生效时，分发前检查 CREATE/DROP 等写入语句
+	if s.writeChecksEnabled() || s.quotaChecksEnabled() {
+		if parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL); err == nil && parseResult.Success {
+			if err := s.checkWritePolicy(parseResult.Statement); err != nil {
+				return nil, err
+			}
+			if err := s.checkQuota(parseResult.Statement); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 结果集上限；auto_limit 注入 LIMIT 的结果、查询改写钩子改写后的语句、按用户解密的加密列
+	// 和分叉会话读取的副本因会话而异，不走查询缓存
+	limits := s.ResultLimits()
+	autoLimit := s.autoLimit(limits)
+	useCache := s.cacheEnabled && autoLimit == 0 && s.db.rewriteHooks.Empty() && s.Fork() == nil &&
+		(s.db.config == nil || s.db.config.ColumnEncryption.decryptionPolicy() == nil)
+
+	// 影子迁移中的表由当前的主数据源读取，切换前后同一语句的结果来自不同的数据源，不走查询缓存
+	var shadows map[string]ShadowTable
+	if s.db.dsManager.HasShadows() {
+		if parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL); err == nil && parseResult.Success {
+			shadows = s.shadowedTables(parseResult.Statement)
+		}
+		useCache = useCache && len(shadows) == 0
+	}
+
 	// Check cache if enabled
-	if s.cacheEnabled {
+	if useCache {
 		if result, found := s.db.cache.Get(boundSQL, nil); found {
 			s.logger.Debug("Cache hit for query")
+			result, err := applyResultLimits(result, limits)
+			if err != nil {
+				return nil, err
+			}
 			return NewQuery(s, result, boundSQL, nil), nil
 		}
 	}
 
 	// Parse and execute query (使用 context.Background,超时由CoreSession内部处理)
-	ctx := context.Background()
+	ctx := s.executionContext()
+	if autoLimit > 0 {
+		ctx = optimizer.WithSelectLimit(ctx, autoLimit)
+	}
+	baseCtx := ctx
+	if len(shadows) > 0 {
+		var err error
+		if ctx, err = s.shadowContext(ctx, shadows, ShadowTable.Primary); err != nil {
+			return nil, err
+		}
+	}
 
 	result, err := s.coreSession.ExecuteQuery(ctx, boundSQL)
+	if domain.IsRetryable(err) {
+		// 后端连接中断：按数据库的故障切换策略重试 SELECT
+		result, err = s.retrySelect(ctx, boundSQL, err)
+	}
 	if err != nil {
 		// 检查错误类型并返回适当的错误码
 		if err.Error() == "query execution timed out" || err.Error() == "query was killed" {
 			return nil, WrapError(err, ErrCodeTimeout, "failed to execute query")
 		}
+		if domain.IsRetryable(err) {
+			return nil, WrapError(err, ErrCodeRetryable, "failed to execute query")
+		}
 		return nil, WrapError(err, ErrCodeSyntax, "failed to execute query")
 	}
+	if len(shadows) > 0 {
+		s.compareShadowRead(baseCtx, boundSQL, result, shadows)
+	}
 
 	// Cache result (with bound SQL, not original)
-	if s.cacheEnabled {
+	if useCache {
 		s.db.cache.Set(boundSQL, nil, result)
 	}
 
+	result, err = applyResultLimits(result, limits)
+	if err != nil {
+		return nil, err
+	}
 	return NewQuery(s, result, boundSQL, nil), nil
 }
 
@@ -150,43 +320,75 @@ func (s *Session) Explain(sql string, args ...interface{}) (string, error) {
 		}
 	}
 
+	physicalPlan, err := s.optimizeSelect(parseResult.Statement.Select)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate execution plan using ExplainPlan
+	output := "Query Execution Plan\n====================\n\n"
+	output += fmt.Sprintf("SQL: %s\n\n", boundSQL)
+	output += optimizer.ExplainPlanV2(physicalPlan)
+
+	// Cache explain result
+	if s.cacheEnabled {
+		s.db.cache.SetExplain(cacheKey, output)
+	}
+
+	return output, nil
+}
+
+// optimizeSelect 用优化器为 SELECT 生成物理计划
+func (s *Session) optimizeSelect(stmt *parser.SelectStatement) (*plan.Plan, error) {
 	// Get optimizer from executor
 	executor := s.coreSession.GetExecutor()
 	if executor == nil {
-		return "", NewError(ErrCodeInternal, "executor not available", nil)
+		return nil, NewError(ErrCodeInternal, "executor not available", nil)
 	}
 
 	enhancedOptimizer := executor.GetOptimizer()
 	if enhancedOptimizer == nil {
-		return "", NewError(ErrCodeInternal, "optimizer not available", nil)
+		return nil, NewError(ErrCodeInternal, "optimizer not available", nil)
 	}
 
 	// Build SQLStatement for optimizer
 	sqlStmt := &parser.SQLStatement{
 		Type:   parser.SQLTypeSelect,
-		Select: parseResult.Statement.Select,
+		Select: stmt,
 	}
 
 	// Optimize to get physical plan using EnhancedOptimizer
-	ctx := context.Background()
-	physicalPlan, err := enhancedOptimizer.Optimize(ctx, sqlStmt)
+	physicalPlan, err := enhancedOptimizer.Optimize(context.Background(), sqlStmt)
 	if err != nil {
-		return "", WrapError(err, ErrCodeInternal, "failed to generate execution plan")
+		return nil, WrapError(err, ErrCodeInternal, "failed to generate execution plan")
 	}
 
 	if physicalPlan == nil {
-		return "", NewError(ErrCodeInternal, "generated physical plan is nil", nil)
+		return nil, NewError(ErrCodeInternal, "generated physical plan is nil", nil)
 	}
+	return physicalPlan, nil
+}
 
-	// Generate execution plan using ExplainPlan
-	output := "Query Execution Plan\n====================\n\n"
-	output += fmt.Sprintf("SQL: %s\n\n", boundSQL)
-	output += optimizer.ExplainPlanV2(physicalPlan)
+// TableInfo returns the schema of a table in the current database
+// The current database names a registered datasource; the session's own datasource is used otherwise
+func (s *Session) TableInfo(tableName string) (*domain.TableInfo, error) {
+	s.mu.RLock()
+	if s.err != nil {
+		s.mu.RUnlock()
+		return nil, s.err
+	}
+	s.mu.RUnlock()
 
-	// Cache explain result
-	if s.cacheEnabled {
-		s.db.cache.SetExplain(cacheKey, output)
+	ds := s.coreSession.GetDataSource()
+	if dbName := s.coreSession.GetCurrentDB(); dbName != "" && s.db != nil {
+		if named, err := s.db.GetDataSource(dbName); err == nil {
+			ds = named
+		}
 	}
 
-	return output, nil
+	info, err := ds.GetTableInfo(context.Background(), tableName)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeTableNotFound, fmt.Sprintf("table '%s' doesn't exist", tableName))
+	}
+	return info, nil
 }
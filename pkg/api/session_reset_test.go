@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Reset(t *testing.T) {
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	defer ds.Close(context.Background())
+
+	db, _ := NewDB(nil)
+	_ = db.RegisterDataSource("test", ds)
+	_ = db.SetDefaultDataSource("test")
+
+	session := db.Session()
+	defer session.Close()
+
+	session.SetCurrentDB("test")
+	session.SetUser("alice")
+	session.SetIsolationLevel(IsolationReadCommitted)
+
+	_, err := session.Query("SET SESSION sql_mode = 'ANSI'")
+	require.NoError(t, err)
+
+	err = session.CreateTempTable("tmp_reset", &domain.TableInfo{
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "int"}},
+	})
+	require.NoError(t, err)
+
+	_, err = session.Begin()
+	require.NoError(t, err)
+	require.True(t, session.InTransaction())
+
+	require.NoError(t, session.Reset())
+
+	assert.False(t, session.InTransaction())
+	assert.Empty(t, session.coreSession.GetTempTables())
+	tables, err := ds.GetTables(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, tables, "tmp_reset")
+
+	_, ok := session.coreSession.GetSessionVar("sql_mode")
+	assert.False(t, ok)
+	assert.Equal(t, IsolationRepeatableRead, session.IsolationLevel())
+
+	// 当前数据库和用户保持不变
+	assert.Equal(t, "test", session.GetCurrentDB())
+	assert.Equal(t, "alice", session.GetUser())
+
+	// 重置后会话仍可继续使用
+	_, err = session.Begin()
+	assert.NoError(t, err)
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/session"
 )
 
 // Begin starts a new transaction
@@ -42,7 +44,11 @@ func (s *Session) InTransaction() bool {
 }
 
 // IsolationLevel returns current transaction isolation level
+// Reflects SET [SESSION] TRANSACTION ISOLATION LEVEL and SET transaction_isolation
 func (s *Session) IsolationLevel() IsolationLevel {
+	if s.coreSession != nil {
+		return parseIsolationLevel(s.coreSession.IsolationLevel())
+	}
 	if s.options == nil {
 		return IsolationRepeatableRead
 	}
@@ -58,6 +64,23 @@ func (s *Session) SetIsolationLevel(level IsolationLevel) {
 		s.options = &SessionOptions{}
 	}
 	s.options.Isolation = level
+	if s.coreSession != nil {
+		_ = s.coreSession.SetIsolationLevel(level.String())
+	}
 
 	s.logger.Debug("Isolation level set to: %s", level.String())
 }
+
+// parseIsolationLevel converts a transaction_isolation value (e.g. READ-COMMITTED)
+func parseIsolationLevel(level string) IsolationLevel {
+	switch level {
+	case session.IsolationReadUncommitted:
+		return IsolationReadUncommitted
+	case session.IsolationReadCommitted:
+		return IsolationReadCommitted
+	case session.IsolationSerializable:
+		return IsolationSerializable
+	default:
+		return IsolationRepeatableRead
+	}
+}
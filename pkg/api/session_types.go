@@ -4,6 +4,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/session"
 )
 
@@ -51,9 +53,21 @@ type Session struct {
 	cacheEnabled bool
 	logger       Logger
 	mu           sync.RWMutex
-	err          error         // Error state if session creation failed
-	queryTimeout time.Duration // 实际生效的超时时间
-	threadID     uint32        // 关联的线程ID (用于KILL)
+	err          error               // Error state if session creation failed
+	queryTimeout time.Duration       // 实际生效的超时时间
+	threadID     uint32              // 关联的线程ID (用于KILL)
+	super        bool                // 具有 SUPER 权限，不受 read_only 和写入策略限制
+	readOnly     bool                // 会话只读，拒绝所有写入语句（SUPER 权限也不例外）
+	resultLimits ResultLimits        // 结果集大小上限（会话变量可覆盖）
+	interactive  bool                // 交互式客户端，auto_limit 时为 SELECT 注入 LIMIT
+	progress     domain.ProgressFunc // 长时间操作的进度回调
+	dialect      parser.Dialect      // 输入 SQL 的方言（会话变量 sql_dialect 可覆盖）
+	passthrough  []string            // 允许执行 PASSTHROUGH 原生查询的数据源，"*" 表示全部
+	stats        *domain.QueryStats  // 正在执行的语句的资源使用统计
+	lastStats    QueryStats          // 上一条语句的资源使用统计（LAST_QUERY_STATS()）
+	queryID      string              // 正在执行的语句的查询 ID
+	lastQueryID  string              // 正在执行或上一条语句的查询 ID
+	fork         *Fork               // 绑定的分叉，分叉表的读写由副本提供
 }
 
 // SetThreadID 设置线程ID (用于KILL查询)
@@ -101,6 +115,44 @@ func (s *Session) SetUser(user string) {
 	}
 }
 
+// SetConnectionAttributes 设置客户端握手时发送的连接属性，显示在 information_schema.PROCESSLIST 中
+func (s *Session) SetConnectionAttributes(attrs map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.coreSession != nil {
+		s.coreSession.SetConnectionAttributes(attrs)
+	}
+}
+
+// ConnectionAttributes 获取客户端的连接属性
+func (s *Session) ConnectionAttributes() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.coreSession != nil {
+		return s.coreSession.ConnectionAttributes()
+	}
+	return nil
+}
+
+// SetQueryLabels 设置连接级查询标签（通常来自连接属性 query_labels），会话变量 query_labels 可以覆盖
+func (s *Session) SetQueryLabels(labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.coreSession != nil {
+		s.coreSession.SetQueryLabels(labels)
+	}
+}
+
+// QueryLabels 返回一条语句的查询标签：连接级标签与语句开头 /* key=value,... */ 注释中的标签合并
+func (s *Session) QueryLabels(sql string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.coreSession != nil {
+		return s.coreSession.QueryLabels(sql)
+	}
+	return session.ParseQueryLabels(sql)
+}
+
 // GetUser 获取当前用户名
 func (s *Session) GetUser() string {
 	s.mu.RLock()
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// ShadowTable is a table being migrated between datasources with SHADOW TABLE
+type ShadowTable = application.ShadowTable
+
+// ShadowTables returns the tables being migrated with SHADOW TABLE, with the
+// number of mirrored writes and compared reads of each
+func (db *DB) ShadowTables() []ShadowTable {
+	return db.dsManager.ShadowTables()
+}
+
+// ==================== 影子迁移的读写 ====================
+// SHADOW TABLE 只登记状态；会话执行引用这些表（未限定库名）的语句时，
+// 把表路由到当前的主数据源，写入成功后在镜像数据源上重放同一语句，
+// 开启比较的读取在镜像数据源上再执行一次并比较结果。镜像与比较都是尽力而为：
+// 失败和不一致只记录日志和计数（SHOW SHADOW TABLES），不影响返回给客户端的结果
+
+// shadowedTables 返回语句中未限定库名、在当前数据库处于影子迁移中的表（表名 -> 状态）
+func (s *Session) shadowedTables(stmt *parser.SQLStatement) map[string]ShadowTable {
+	// 分叉会话的写入不镜像，分叉表由副本提供
+	if s.db == nil || !s.db.dsManager.HasShadows() || s.Fork() != nil {
+		return nil
+	}
+	var names []string
+	switch stmt.Type {
+	case parser.SQLTypeSelect:
+		if stmt.Select == nil {
+			return nil
+		}
+		if stmt.Select.From != "" && !strings.Contains(stmt.Select.From, ".") {
+			names = append(names, stmt.Select.From)
+		}
+		for _, join := range stmt.Select.Joins {
+			if join.Database == "" {
+				names = append(names, join.Table)
+			}
+		}
+	case parser.SQLTypeInsert:
+		if stmt.Insert != nil && stmt.Insert.Database == "" {
+			names = append(names, stmt.Insert.Table)
+		}
+	case parser.SQLTypeUpdate:
+		if stmt.Update != nil && stmt.Update.Database == "" {
+			names = append(names, stmt.Update.Table)
+		}
+	case parser.SQLTypeDelete:
+		if stmt.Delete != nil && stmt.Delete.Database == "" {
+			names = append(names, stmt.Delete.Table)
+		}
+	}
+
+	database := s.GetCurrentDB()
+	if database == "" {
+		database = s.db.dsManager.GetDefaultName()
+	}
+	var tables map[string]ShadowTable
+	for _, name := range names {
+		if st, ok := s.db.dsManager.ShadowFor(database, name); ok {
+			if tables == nil {
+				tables = make(map[string]ShadowTable)
+			}
+			tables[name] = st
+		}
+	}
+	return tables
+}
+
+// checkShadowTransaction 事务直接读写数据源，不经过影子迁移的路由与镜像，
+// 因此拒绝在事务中访问影子迁移中的表
+func (s *Session) checkShadowTransaction(stmt *parser.SQLStatement) error {
+	for name, st := range s.shadowedTables(stmt) {
+		return NewError(ErrCodeNotSupported, fmt.Sprintf("table '%s' is shadowed to '%s': it cannot be used inside a transaction until UNSHADOW TABLE", name, st.Shadow), nil)
+	}
+	return nil
+}
+
+// shadowContext 把影子迁移中的表路由到 datasource 为其选择的数据源（主数据源或镜像数据源）
+func (s *Session) shadowContext(ctx context.Context, tables map[string]ShadowTable, datasource func(ShadowTable) string) (context.Context, error) {
+	overrides := make(map[string]domain.DataSource, len(tables))
+	for name, st := range tables {
+		database := datasource(st)
+		ds, err := s.db.dsManager.Get(database)
+		if err != nil {
+			return nil, NewError(ErrCodeDSNotFound, fmt.Sprintf("datasource '%s' of shadowed table '%s' not found", database, name), err)
+		}
+		overrides[name] = ds
+	}
+	return optimizer.WithTableOverrides(ctx, overrides), nil
+}
+
+// mirrorWrite 在镜像数据源上重放已在主数据源成功执行的写入语句
+func (s *Session) mirrorWrite(ctx context.Context, stmtType parser.SQLType, boundSQL string, tables map[string]ShadowTable) {
+	mirrorCtx, err := s.shadowContext(ctx, tables, ShadowTable.Mirror)
+	if err == nil {
+		switch stmtType {
+		case parser.SQLTypeInsert:
+			_, err = s.coreSession.ExecuteInsert(mirrorCtx, boundSQL, nil)
+		case parser.SQLTypeUpdate:
+			_, err = s.coreSession.ExecuteUpdate(mirrorCtx, boundSQL, nil, nil)
+		case parser.SQLTypeDelete:
+			_, err = s.coreSession.ExecuteDelete(mirrorCtx, boundSQL, nil)
+		}
+	}
+	for name, st := range tables {
+		if err != nil {
+			s.logger.Warn("Mirroring write on shadowed table %s.%s to %s failed: %v", st.Database, name, st.Mirror(), err)
+		}
+		s.db.dsManager.RecordShadowWrite(st.Database, name, err)
+	}
+}
+
+// compareShadowRead 在镜像数据源上再执行一次读取，与主数据源的结果比较；只比较开启了比较的表
+func (s *Session) compareShadowRead(ctx context.Context, boundSQL string, primary *domain.QueryResult, tables map[string]ShadowTable) {
+	compared := make(map[string]ShadowTable)
+	for name, st := range tables {
+		if st.Compare {
+			compared[name] = st
+		}
+	}
+	if len(compared) == 0 {
+		return
+	}
+
+	// 不比较的表在两次执行中都读取主数据源
+	var mismatch string
+	mirrorCtx, err := s.shadowContext(ctx, tables, func(st ShadowTable) string {
+		if st.Compare {
+			return st.Mirror()
+		}
+		return st.Primary()
+	})
+	var shadow *domain.QueryResult
+	if err == nil {
+		shadow, err = s.coreSession.ExecuteQuery(mirrorCtx, boundSQL)
+	}
+	if err != nil {
+		mismatch = fmt.Sprintf("shadow read failed: %v", err)
+	} else {
+		mismatch = compareShadowResults(primary, shadow)
+	}
+	for name, st := range compared {
+		if mismatch != "" {
+			s.logger.Warn("Shadow read of %s.%s differs between %s and %s: %s (%s)", st.Database, name, st.Primary(), st.Mirror(), mismatch, boundSQL)
+		}
+		s.db.dsManager.RecordShadowRead(st.Database, name, mismatch)
+	}
+}
+
+// compareShadowResults 不考虑行的顺序比较两个结果集，一致时返回空串
+func compareShadowResults(primary, shadow *domain.QueryResult) string {
+	if len(primary.Rows) != len(shadow.Rows) {
+		return fmt.Sprintf("%d rows on primary, %d rows on shadow", len(primary.Rows), len(shadow.Rows))
+	}
+	columns := make([]string, 0, len(primary.Columns))
+	for _, col := range primary.Columns {
+		columns = append(columns, col.Name)
+	}
+	if len(columns) == 0 && len(primary.Rows) > 0 {
+		for name := range primary.Rows[0] {
+			columns = append(columns, name)
+		}
+		sort.Strings(columns)
+	}
+
+	counts := make(map[string]int, len(primary.Rows))
+	for _, row := range primary.Rows {
+		counts[shadowRowText(row, columns)]++
+	}
+	differing := 0
+	for _, row := range shadow.Rows {
+		text := shadowRowText(row, columns)
+		if counts[text] == 0 {
+			differing++
+			continue
+		}
+		counts[text]--
+	}
+	if differing > 0 {
+		return fmt.Sprintf("%d of %d rows differ", differing, len(primary.Rows))
+	}
+	return ""
+}
+
+// shadowRowText 行的比较文本；不同数据源可能以浮点数返回整数，整数值的浮点数按整数比较
+func shadowRowText(row domain.Row, columns []string) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		v := row[col]
+		if v == nil {
+			parts[i] = "NULL"
+			continue
+		}
+		if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			v = int64(f)
+		}
+		parts[i] = fmt.Sprintf("%q", utils.ToString(v))
+	}
+	return strings.Join(parts, ",")
+}
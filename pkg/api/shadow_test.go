@@ -0,0 +1,113 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newShadowTestDB 在 ledger 数据库建立与默认数据库相同的 accounts，作为迁移的目标
+func newShadowTestDB(t *testing.T) (*DB, *Session) {
+	db := newXATestDB(t)
+	ledger := db.SessionWithOptions(&SessionOptions{DataSourceName: "ledger"})
+	t.Cleanup(func() { ledger.Close() })
+	_, err := ledger.Execute(`CREATE TABLE accounts (id INT PRIMARY KEY, balance INT)`)
+	require.NoError(t, err)
+	_, err = ledger.Execute(`INSERT INTO accounts VALUES (1, 100), (2, 100)`)
+	require.NoError(t, err)
+	return db, ledger
+}
+
+func balanceOf(t *testing.T, s *Session, sql string) interface{} {
+	row, err := s.QueryOne(sql)
+	require.NoError(t, err)
+	return row["balance"]
+}
+
+// TestShadowTable_MirrorCompareCutover 测试影子迁移镜像写入、比较读取、切换与结束
+func TestShadowTable_MirrorCompareCutover(t *testing.T) {
+	db, ledger := newShadowTestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`SHADOW TABLE accounts TO ledger WITH COMPARE`)
+	require.NoError(t, err)
+	_, err = s.Execute(`SHADOW TABLE accounts TO ledger`)
+	assert.Error(t, err, "already shadowed")
+
+	// 写入镜像到 ledger
+	_, err = s.Execute(`INSERT INTO accounts VALUES (3, 30)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE accounts SET balance = 150 WHERE id = 1`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 150, balanceOf(t, ledger, `SELECT balance FROM accounts WHERE id = 1`))
+	assert.Equal(t, 3, countRows(t, ledger, `SELECT * FROM accounts`))
+
+	// 一致的读取
+	assert.Equal(t, 3, countRows(t, s, `SELECT * FROM accounts`))
+	tables := db.ShadowTables()
+	require.Len(t, tables, 1)
+	assert.EqualValues(t, 2, tables[0].MirroredWrites)
+	assert.EqualValues(t, 1, tables[0].ComparedReads)
+	assert.Zero(t, tables[0].Mismatches)
+
+	// 直接修改 ledger 造成不一致：读取仍由主数据源提供，不一致被记录
+	_, err = ledger.Execute(`UPDATE accounts SET balance = 0 WHERE id = 2`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, balanceOf(t, s, `SELECT balance FROM accounts WHERE id = 2`))
+	tables = db.ShadowTables()
+	assert.EqualValues(t, 1, tables[0].Mismatches)
+	assert.Contains(t, tables[0].LastError, "1 of 1 rows differ")
+
+	// 事务不经过影子迁移的路由，拒绝访问
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`UPDATE accounts SET balance = 1 WHERE id = 1`)
+	assert.Error(t, err)
+	_, err = tx.Query(`SELECT * FROM accounts`)
+	assert.Error(t, err)
+	require.NoError(t, tx.Rollback())
+
+	// 切换后由 ledger 提供读取，写入镜像回原数据库
+	_, err = s.Execute(`CUTOVER TABLE accounts`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, balanceOf(t, s, `SELECT balance FROM accounts WHERE id = 2`))
+	_, err = s.Execute(`INSERT INTO accounts VALUES (4, 40)`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 40, balanceOf(t, ledger, `SELECT balance FROM accounts WHERE id = 4`))
+	assert.EqualValues(t, 40, balanceOf(t, s, `SELECT balance FROM test.accounts WHERE id = 4`))
+
+	q, err := s.Query(`SHOW SHADOW TABLES`)
+	require.NoError(t, err)
+	require.True(t, q.Next())
+	row := q.Row()
+	q.Close()
+	assert.Equal(t, "accounts", row["Table"])
+	assert.Equal(t, "ledger", row["Shadow"])
+	assert.Equal(t, "ledger", row["Primary"])
+	assert.Equal(t, "YES", row["Compare"])
+	assert.EqualValues(t, 3, row["Mirrored_writes"])
+
+	// 结束后表重新由当前数据库提供
+	_, err = s.Execute(`UNSHADOW TABLE accounts`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, balanceOf(t, s, `SELECT balance FROM accounts WHERE id = 2`))
+	assert.Empty(t, db.ShadowTables())
+	_, err = s.Execute(`CUTOVER TABLE accounts`)
+	assert.Error(t, err)
+}
+
+// TestShadowTable_Errors 测试影子迁移的目标必须存在同名的表
+func TestShadowTable_Errors(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	_, err := s.Execute(`SHADOW TABLE accounts TO ledger`)
+	assert.Error(t, err)
+	_, err = s.Execute(`SHADOW TABLE accounts TO nowhere`)
+	assert.Error(t, err)
+	_, err = s.Execute(`UNSHADOW TABLE accounts`)
+	assert.Error(t, err)
+}
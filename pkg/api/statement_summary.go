@@ -0,0 +1,98 @@
+package api
+
+import (
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// recordStatement 将一次语句执行计入全局语句摘要（information_schema.statements_summary），
+// 超过阈值时同时写入慢查询日志，并按采样比例捕获执行计划和执行统计。
+// sql 为绑定参数前的原始语句，sample 为实际执行的语句
+func recordStatement(s *Session, sql, sample string, start time.Time, rows int64, err error) {
+	latency := time.Since(start)
+	if sample == "" {
+		sample = sql
+	}
+
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	slowLog := monitor.GetSlowQueryLog()
+	summary := monitor.GetStatementSummary()
+	slow := slowLog.GetThreshold() > 0 && latency >= slowLog.GetThreshold()
+	if !slow && !summary.Enabled() {
+		return
+	}
+
+	digestText, digest := parser.NormalizeSQL(sql)
+	summary.Record(digest, digestText, sample, latency, rows, err)
+	if !slow {
+		return
+	}
+	id := slowLog.RecordStatement(sample, digest, s.GetUser(), latency, rows, errMsg)
+	if id == 0 {
+		return
+	}
+	if labels := s.QueryLabels(sql); len(labels) > 0 {
+		slowLog.SetLabels(id, labels)
+	}
+	if slowLog.ShouldSample() {
+		captureSample(s, id, digest, sample)
+	}
+}
+
+// captureSample 为慢查询日志中的一条记录捕获执行计划和执行统计，同时保存到语句摘要。
+// 只有 SELECT 有执行计划，其他语句只捕获统计
+func captureSample(s *Session, id int64, digest, boundSQL string) {
+	stats := &monitor.ExecutionStats{}
+	if summaryStats, ok := monitor.GetStatementSummary().Get(digest); ok {
+		stats.ExecCount = summaryStats.ExecCount
+		stats.AvgLatency = summaryStats.AvgLatency()
+		if summaryStats.ExecCount > 0 {
+			stats.AvgRows = summaryStats.SumRows / summaryStats.ExecCount
+		}
+	}
+
+	var explain string
+	if s != nil && s.coreSession != nil {
+		if parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL); err == nil && parseResult.Success &&
+			parseResult.Statement.Type == parser.SQLTypeSelect && parseResult.Statement.Select != nil {
+			if physicalPlan, err := s.optimizeSelect(parseResult.Statement.Select); err == nil {
+				stats.PlanDigest = optimizer.PlanDigest(physicalPlan)
+				stats.EstimatedCost = physicalPlan.Cost()
+				explain = optimizer.ExplainPlanV2(physicalPlan)
+				monitor.GetStatementSummary().RecordPlan(digest, stats.PlanDigest, explain)
+			} else {
+				s.logger.Debug("Failed to capture plan of slow statement: %v", err)
+			}
+		}
+	}
+	monitor.GetSlowQueryLog().SetSample(id, explain, stats)
+}
+
+// recordQuery 记录查询语句，行数为返回的行数
+func recordQuery(s *Session, sql string, start time.Time, q *Query, err error) {
+	var rows int64
+	var sample string
+	if q != nil {
+		sample = q.sql
+		if q.result != nil {
+			rows = int64(len(q.result.Rows))
+		}
+	}
+	recordStatement(s, sql, sample, start, rows, err)
+}
+
+// recordExecute 记录 DML/DDL 语句，行数为影响的行数
+func recordExecute(s *Session, sql string, start time.Time, result *Result, err error) {
+	var rows int64
+	if result != nil {
+		rows = result.RowsAffected
+	}
+	recordStatement(s, sql, "", start, rows, err)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_RecordsStatementSummary(t *testing.T) {
+	db, err := NewDB(nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "default",
+		Writable: true,
+	})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+	require.NoError(t, ds.CreateTable(context.Background(), &domain.TableInfo{
+		Name:    "ss_users",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}, {Name: "name", Type: "VARCHAR"}},
+	}))
+
+	sess := db.Session()
+	defer sess.Close()
+
+	summary := monitor.GetStatementSummary()
+	summary.Reset()
+
+	_, err = sess.Execute("INSERT INTO ss_users (id, name) VALUES (1, 'a')")
+	require.NoError(t, err)
+	_, err = sess.Execute("INSERT INTO ss_users (id, name) VALUES (2, 'b')")
+	require.NoError(t, err)
+
+	for _, id := range []int{1, 2} {
+		q, err := sess.Query("SELECT * FROM ss_users WHERE id = ?", id)
+		require.NoError(t, err)
+		q.Close()
+	}
+	_, err = sess.Query("SELECT * FROM ss_missing WHERE id = 1")
+	require.Error(t, err)
+
+	insert, ok := summary.Get(parser.SQLDigest("INSERT INTO ss_users (id, name) VALUES (1, 'a')"))
+	require.True(t, ok)
+	assert.Equal(t, int64(2), insert.ExecCount)
+	assert.Equal(t, int64(2), insert.SumRows)
+
+	sel, ok := summary.Get(parser.SQLDigest("SELECT * FROM ss_users WHERE id = 1"))
+	require.True(t, ok)
+	assert.Equal(t, int64(2), sel.ExecCount)
+	assert.Equal(t, int64(2), sel.SumRows)
+	assert.Equal(t, "SELECT * FROM ss_users WHERE id = 2", sel.SampleSQL)
+	assert.Equal(t, "select * from `ss_users` where `id` = ?", sel.DigestText)
+
+	missing, ok := summary.Get(parser.SQLDigest("SELECT * FROM ss_missing WHERE id = 1"))
+	require.True(t, ok)
+	assert.Equal(t, int64(1), missing.ErrorCount)
+	assert.NotEmpty(t, missing.LastError)
+
+	// 通过 information_schema 查询统计
+	q, err := sess.Query("SELECT EXEC_COUNT FROM information_schema.statements_summary WHERE DIGEST = '" + sel.Digest + "'")
+	require.NoError(t, err)
+	defer q.Close()
+	require.True(t, q.Next())
+	assert.EqualValues(t, 2, q.Row()["EXEC_COUNT"])
+}
+
+func TestSession_CapturesSlowStatementPlans(t *testing.T) {
+	db, err := NewDB(nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "default",
+		Writable: true,
+	})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+	require.NoError(t, ds.CreateTable(context.Background(), &domain.TableInfo{
+		Name:    "sp_orders",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}, {Name: "amount", Type: "INT"}},
+	}))
+
+	summary := monitor.GetStatementSummary()
+	summary.Reset()
+	slowLog := monitor.GetSlowQueryLog()
+	slowLog.Configure(time.Nanosecond, 10)
+	slowLog.SetExplainSampleRate(1)
+	slowLog.Clear()
+	defer func() {
+		slowLog.Configure(0, monitor.DefaultSlowQueryMaxEntries)
+		slowLog.SetExplainSampleRate(0)
+		slowLog.Clear()
+	}()
+
+	sess := db.Session()
+	defer sess.Close()
+	_, err = sess.Execute("INSERT INTO sp_orders (id, amount) VALUES (1, 10)")
+	require.NoError(t, err)
+	_, err = sess.QueryAll("SELECT * FROM sp_orders WHERE amount > 5")
+	require.NoError(t, err)
+
+	digest := parser.SQLDigest("SELECT * FROM sp_orders WHERE amount > 5")
+	entries := slowLog.GetSlowQueriesByDigest(digest)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].ExplainPlan, "TableScan")
+	require.NotNil(t, entries[0].Stats)
+	assert.Len(t, entries[0].Stats.PlanDigest, 64)
+	assert.Equal(t, int64(1), entries[0].Stats.ExecCount)
+
+	// 执行计划同时保存在语句摘要中
+	stats, ok := summary.Get(digest)
+	require.True(t, ok)
+	assert.Equal(t, entries[0].Stats.PlanDigest, stats.PlanDigest)
+	assert.Equal(t, entries[0].ExplainPlan, stats.Plan)
+
+	// 非 SELECT 语句只捕获统计
+	insert := slowLog.GetSlowQueriesByDigest(parser.SQLDigest("INSERT INTO sp_orders (id, amount) VALUES (1, 10)"))
+	require.Len(t, insert, 1)
+	assert.Empty(t, insert[0].ExplainPlan)
+	require.NotNil(t, insert[0].Stats)
+	assert.Empty(t, insert[0].Stats.PlanDigest)
+}
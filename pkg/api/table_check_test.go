@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChecksumTable_CompareDatabases 测试 CHECKSUM TABLE 比较两个库中内容相同但插入顺序不同的表
+func TestChecksumTable_CompareDatabases(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	replica := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "replica",
+		Writable: true,
+	})
+	require.NoError(t, replica.Connect(context.Background()))
+	require.NoError(t, s.db.RegisterDataSource("replica", replica))
+
+	ctx := context.Background()
+	require.NoError(t, replica.CreateTable(ctx, &domain.TableInfo{
+		Name: "users",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true, AutoIncrement: true},
+			{Name: "name", Type: "VARCHAR"},
+			{Name: "city", Type: "VARCHAR"},
+		},
+	}))
+	_, err := replica.Insert(ctx, "users", []domain.Row{
+		{"id": 3, "name": "Carol", "city": "Paris"},
+		{"id": 1, "name": "Alice", "city": "Paris"},
+		{"id": 2, "name": "bob", "city": "Berlin"},
+	}, nil)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`CHECKSUM TABLE users, replica.users, missing`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "users", rows[0]["Table"])
+	assert.Equal(t, "replica.users", rows[1]["Table"])
+	assert.NotNil(t, rows[0]["Checksum"])
+	assert.Equal(t, rows[0]["Checksum"], rows[1]["Checksum"])
+	assert.Nil(t, rows[2]["Checksum"])
+
+	_, err = s.Execute(`UPDATE replica.users SET city = 'Rome' WHERE id = 2`)
+	require.NoError(t, err)
+	rows, err = s.QueryAll(`CHECKSUM TABLE users, replica.users`)
+	require.NoError(t, err)
+	assert.NotEqual(t, rows[0]["Checksum"], rows[1]["Checksum"])
+}
+
+// TestCheckTable 测试 CHECK TABLE 对一致的表返回 OK，对不存在的表报告错误
+func TestCheckTable(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE INDEX idx_city ON users (city)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`UPDATE users SET city = 'Lyon' WHERE name = 'bob'`)
+	require.NoError(t, err)
+	_, err = s.Execute(`DELETE FROM users WHERE name = 'Alice'`)
+	require.NoError(t, err)
+
+	rows, err := s.QueryAll(`CHECK TABLE users EXTENDED`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "check", rows[0]["Op"])
+	assert.Equal(t, "status", rows[0]["Msg_type"])
+	assert.Equal(t, "OK", rows[0]["Msg_text"])
+
+	rows, err = s.QueryAll(`CHECK TABLE missing`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Error", rows[0]["Msg_type"])
+	assert.Equal(t, "Operation failed", rows[1]["Msg_text"])
+
+	_, err = s.Execute(`CHECK TABLE users`)
+	assert.Error(t, err)
+}
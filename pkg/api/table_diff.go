@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/tablediff"
+)
+
+// DiffTables compares two tables row by row, matching rows by key (the left table's
+// primary key when key is empty). Table names may be qualified as db.table, so the
+// tables can live in different datasources. Every difference is passed to emit as it
+// is found; the summary counts are returned when the comparison completes.
+// Equivalent to the DIFF TABLE left, right [KEY (...)] statement.
+func (s *Session) DiffTables(left, right string, key []string, emit func(tablediff.Difference) error) (*tablediff.Summary, error) {
+	s.mu.RLock()
+	if s.err != nil {
+		s.mu.RUnlock()
+		return nil, s.err
+	}
+	s.mu.RUnlock()
+
+	executor := s.coreSession.GetExecutor()
+	if executor == nil {
+		return nil, NewError(ErrCodeInternal, "executor not available", nil)
+	}
+	summary, err := executor.DiffTables(s.executionContext(), &parser.DiffStatement{Left: left, Right: right, Key: key}, emit)
+	if err != nil {
+		return nil, WrapError(err, ErrCodeInvalidParam, "failed to diff tables")
+	}
+	return summary, nil
+}
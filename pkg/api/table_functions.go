@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/builtin"
+	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// generateSeriesFunc GENERATE_SERIES(start, stop[, step])，用于时间序列补齐缺失的时间桶
+const generateSeriesFunc = "GENERATE_SERIES"
+
+// tableFunction 表函数的求值：返回作为表参与外层查询的结果集
+type tableFunction func(s *Session, ctx context.Context, call parser.TableFunction) (*domain.QueryResult, error)
+
+// tableFunctions FROM 子句中可用的表函数
+var tableFunctions = map[string]tableFunction{
+	graphReachableFunc:    (*Session).traverseGraph,
+	graphShortestPathFunc: (*Session).traverseGraph,
+	generateSeriesFunc:    (*Session).generateSeries,
+}
+
+// queryTableFunctions 执行 FROM 中含有表函数或 PIVOT / UNPIVOT 的查询：它们的结果写入临时的内存数据源，
+// 外层查询把它当作表执行，可以与普通表 JOIN。不含表函数与 PIVOT / UNPIVOT 的语句返回 false
+func (s *Session) queryTableFunctions(boundSQL string) (*Query, bool, error) {
+	if s.coreSession == nil {
+		return nil, false, nil
+	}
+	names := make([]string, 0, len(tableFunctions))
+	for name := range tableFunctions {
+		names = append(names, name)
+	}
+	calls, rewritten, err := parser.ParseTableFunctions(boundSQL, "__table_function_", names...)
+	if err != nil {
+		return nil, true, WrapError(err, ErrCodeSyntax, "failed to parse table function")
+	}
+	pivots, rewritten, err := parser.ParsePivots(rewritten, "__pivot_")
+	if err != nil {
+		return nil, true, WrapError(err, ErrCodeSyntax, "failed to parse PIVOT")
+	}
+	if calls == nil && pivots == nil {
+		return nil, false, nil
+	}
+
+	ctx := s.executionContext()
+	if timeout := s.coreSession.GetQueryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	results := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "table_functions",
+		Writable: true,
+	})
+	if err := results.Connect(ctx); err != nil {
+		return nil, true, WrapError(err, ErrCodeInternal, "failed to prepare table function result")
+	}
+	defer results.Close(ctx)
+
+	tables := make(map[string]domain.DataSource, len(calls))
+	for _, call := range calls {
+		result, err := tableFunctions[call.Name](s, ctx, call)
+		if err != nil {
+			return nil, true, err
+		}
+		if err := materializePassthrough(ctx, results, call.Table, result); err != nil {
+			return nil, true, WrapError(err, ErrCodeInternal, "failed to materialize "+call.Name+" result")
+		}
+		tables[call.Table] = results
+	}
+	for _, pivot := range pivots {
+		result, err := s.evaluatePivot(ctx, results, tables, pivot)
+		if err != nil {
+			return nil, true, err
+		}
+		if err := materializePassthrough(ctx, results, pivot.Table, result); err != nil {
+			return nil, true, WrapError(err, ErrCodeInternal, "failed to materialize PIVOT result")
+		}
+		tables[pivot.Table] = results
+	}
+
+	limits := s.ResultLimits()
+	ctx = optimizer.WithTableOverrides(ctx, tables)
+	if autoLimit := s.autoLimit(limits); autoLimit > 0 {
+		ctx = optimizer.WithSelectLimit(ctx, autoLimit)
+	}
+	result, err := s.coreSession.ExecuteQuery(ctx, rewritten)
+	if err != nil {
+		return nil, true, WrapError(err, ErrCodeSyntax, "failed to execute query")
+	}
+	result, err = applyResultLimits(result, limits)
+	if err != nil {
+		return nil, true, err
+	}
+	return NewQuery(s, result, boundSQL, nil), true, nil
+}
+
+// generateSeries GENERATE_SERIES(start, stop[, step])：整数序列，或按时间间隔（如 '5 minutes'）
+// 递增的时间序列，结果列为 value
+func (s *Session) generateSeries(ctx context.Context, call parser.TableFunction) (*domain.QueryResult, error) {
+	if len(call.Args) < 2 || len(call.Args) > 3 {
+		return nil, NewError(ErrCodeInvalidParam,
+			fmt.Sprintf("%s: wrong number of arguments, usage: GENERATE_SERIES(start, stop[, step])", call.Name), nil)
+	}
+	var step interface{}
+	if len(call.Args) == 3 {
+		step = call.Args[2]
+	}
+	values, err := builtin.GenerateSeries(call.Args[0], call.Args[1], step)
+	if err != nil {
+		return nil, NewError(ErrCodeInvalidParam, err.Error(), nil)
+	}
+	colType := "BIGINT"
+	if len(values) > 0 {
+		if _, ok := values[0].(time.Time); ok {
+			colType = "DATETIME"
+		}
+	}
+	result := &domain.QueryResult{Columns: []domain.ColumnInfo{{Name: "value", Type: colType}}}
+	for _, v := range values {
+		result.Rows = append(result.Rows, domain.Row{"value": v})
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShowTableMemory 测试 SHOW TABLE MEMORY 按列报告内存占用
+func TestShowTableMemory(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	rows, err := s.QueryAll(`SHOW TABLE MEMORY FROM users`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	byColumn := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		assert.Equal(t, "users", row["Table"])
+		byColumn[row["Column"].(string)] = row
+	}
+	city := byColumn["city"]
+	require.NotNil(t, city)
+	assert.EqualValues(t, 3, city["Values"])
+	assert.EqualValues(t, 2, city["Distinct_values"])
+
+	// 未指定表时报告当前数据库的所有表
+	rows, err = s.QueryAll(`show table memory`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	_, err = s.QueryAll(`SHOW TABLE MEMORY FROM missing`)
+	assert.Error(t, err)
+}
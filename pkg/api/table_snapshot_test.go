@@ -0,0 +1,44 @@
+package api
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableSnapshot_ExportImport 测试 EXPORT TABLE 导出快照后通过 IMPORT TABLE 恢复表
+func TestTableSnapshot_ExportImport(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	path := filepath.Join(t.TempDir(), "users.snap")
+
+	res, err := s.Execute(`EXPORT TABLE users TO '` + path + `'`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+
+	_, err = s.Execute(`DROP TABLE users`)
+	require.NoError(t, err)
+
+	res, err = s.Execute(`IMPORT TABLE FROM '` + path + `'`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+
+	rows, err := s.QueryAll(`SELECT name FROM users WHERE city = 'Paris' ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Alice", rows[0]["name"])
+	assert.Equal(t, "Carol", rows[1]["name"])
+
+	// 导入为新表，自增值从快照中的计数继续
+	_, err = s.Execute(`IMPORT TABLE users_copy FROM '` + path + `'`)
+	require.NoError(t, err)
+	res, err = s.Execute(`INSERT INTO users_copy (name, city) VALUES ('Dave', 'Rome')`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, res.LastInsertID)
+
+	_, err = s.Execute(`IMPORT TABLE users FROM '` + path + `'`)
+	assert.Error(t, err)
+	_, err = s.Execute(`EXPORT TABLE missing TO '` + path + `'`)
+	assert.Error(t, err)
+}
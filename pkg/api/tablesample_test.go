@@ -0,0 +1,45 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTableSample 测试 TABLESAMPLE 抽样读取 FROM 表
+func TestTableSample(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE events (id INT PRIMARY KEY, kind INT)`)
+	require.NoError(t, err)
+	values := make([]string, 1000)
+	for i := range values {
+		values[i] = fmt.Sprintf("(%d, %d)", i, i%2)
+	}
+	_, err = s.Execute("INSERT INTO events (id, kind) VALUES " + strings.Join(values, ", "))
+	require.NoError(t, err)
+
+	row, err := s.QueryOne(`SELECT COUNT(*) AS n FROM events TABLESAMPLE BERNOULLI(20)`)
+	require.NoError(t, err)
+	assert.InDelta(t, 200, row["n"], 80)
+
+	row, err = s.QueryOne(`SELECT COUNT(*) AS n FROM events TABLESAMPLE (25 ROWS)`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 25, row["n"])
+
+	// 相同的种子得到相同的样本；WHERE 与 LIMIT 作用于样本
+	first, err := s.QueryAll(`SELECT id, kind FROM events TABLESAMPLE SYSTEM(30%) REPEATABLE(9) WHERE kind = 1 LIMIT 5`)
+	require.NoError(t, err)
+	require.Len(t, first, 5)
+	for _, row := range first {
+		assert.EqualValues(t, 1, row["kind"])
+	}
+	second, err := s.QueryAll(`SELECT id, kind FROM events TABLESAMPLE SYSTEM(30%) REPEATABLE(9) WHERE kind = 1 LIMIT 5`)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	_, err = s.QueryAll(`SELECT * FROM events TABLESAMPLE BERNOULLI(200)`)
+	assert.Error(t, err)
+}
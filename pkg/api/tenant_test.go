@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTenancyTestDB 创建按租户隔离 orders 表的 DB，返回 root、alice（acme）和 bob（globex）的会话
+func newTenancyTestDB(t *testing.T) (db *DB, root, alice, bob *Session) {
+	t.Helper()
+	db, err := NewDB(&DBConfig{
+		DefaultLogger: NewDefaultLogger(LogError),
+		Tenancy: &tenant.Config{
+			Tables:        []string{"orders"},
+			Users:         map[string]string{"alice": "acme", "bob": "globex"},
+			UnscopedUsers: []string{"root"},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "default", Writable: true})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+
+	session := func(user string) *Session {
+		s := db.Session()
+		t.Cleanup(func() { s.Close() })
+		s.SetUser(user)
+		return s
+	}
+	root = session("root")
+	_, err = root.Execute(`CREATE TABLE orders (id INT PRIMARY KEY, amount INT, tenant_id VARCHAR(20))`)
+	require.NoError(t, err)
+	return db, root, session("alice"), session("bob")
+}
+
+// TestTenancy_ScopesStatements 测试多租户隔离对查询、写入和事务内语句的限定
+func TestTenancy_ScopesStatements(t *testing.T) {
+	db, root, alice, bob := newTenancyTestDB(t)
+	assert.Equal(t, []string{tenant.HookName}, db.RewriteHooks())
+
+	_, err := alice.Execute(`INSERT INTO orders (id, amount) VALUES (1, 10), (2, 20)`)
+	require.NoError(t, err)
+	_, err = bob.Execute(`INSERT INTO orders (id, amount) VALUES (3, 30)`)
+	require.NoError(t, err)
+
+	rows, err := alice.QueryAll(`SELECT id, tenant_id FROM orders ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "acme", rows[0]["tenant_id"])
+
+	// 其他租户的行不受影响
+	_, err = bob.Execute(`DELETE FROM orders WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = bob.Execute(`UPDATE orders SET amount = 0`)
+	require.NoError(t, err)
+	rows, err = root.QueryAll(`SELECT id, amount FROM orders ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.EqualValues(t, 10, rows[0]["amount"])
+	assert.EqualValues(t, 0, rows[2]["amount"])
+
+	// 事务内的语句同样被限定
+	tx, err := bob.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`DELETE FROM orders`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	rows, err = root.QueryAll(`SELECT id FROM orders`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	_, err = alice.QueryAll(`SELECT * FROM orders WHERE tenant_id = 'globex'`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrTableAccessDenied, code)
+
+	_, err = alice.Execute(`TRUNCATE TABLE orders`)
+	assert.Error(t, err)
+}
+
+// TestTenancy_OuterJoinKeepsUnmatchedRows 测试外连接对租户表的限定不会丢掉没有匹配的行
+func TestTenancy_OuterJoinKeepsUnmatchedRows(t *testing.T) {
+	_, root, alice, bob := newTenancyTestDB(t)
+	_, err := root.Execute(`CREATE TABLE products (id INT PRIMARY KEY, name VARCHAR(20))`)
+	require.NoError(t, err)
+	_, err = root.Execute(`INSERT INTO products (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')`)
+	require.NoError(t, err)
+	_, err = alice.Execute(`INSERT INTO orders (id, amount) VALUES (1, 10)`)
+	require.NoError(t, err)
+	_, err = bob.Execute(`INSERT INTO orders (id, amount) VALUES (2, 20)`)
+	require.NoError(t, err)
+
+	for _, sql := range []string{
+		`SELECT p.id AS pid, o.amount FROM products p LEFT JOIN orders o ON o.id = p.id ORDER BY p.id`,
+		`SELECT p.id AS pid, o.amount FROM orders o RIGHT JOIN products p ON o.id = p.id ORDER BY p.id`,
+	} {
+		rows, err := alice.QueryAll(sql)
+		require.NoError(t, err, sql)
+		require.Len(t, rows, 3, sql)
+		amounts := map[string]interface{}{}
+		for _, row := range rows {
+			amounts[fmt.Sprint(row["pid"])] = row["amount"]
+		}
+		assert.EqualValues(t, 10, amounts["1"], sql)
+		assert.Nil(t, amounts["2"], "the order of another tenant must not be joined: "+sql)
+		assert.Nil(t, amounts["3"], sql)
+	}
+}
+
+func TestTenancy_InvalidConfig(t *testing.T) {
+	_, err := NewDB(&DBConfig{Tenancy: &tenant.Config{}})
+	assert.Error(t, err)
+}
+
+// TestTenancy_SubqueriesAndUpserts 测试子查询、ON DUPLICATE KEY UPDATE 和扩展语句不会越过租户隔离
+func TestTenancy_SubqueriesAndUpserts(t *testing.T) {
+	db, root, alice, bob := newTenancyTestDB(t)
+	_, err := root.Execute(`CREATE TABLE products (id INT PRIMARY KEY, name VARCHAR(20))`)
+	require.NoError(t, err)
+	_, err = root.Execute(`INSERT INTO products (id, name) VALUES (1, 'a'), (2, 'b')`)
+	require.NoError(t, err)
+	_, err = alice.Execute(`INSERT INTO orders (id, amount) VALUES (1, 10)`)
+	require.NoError(t, err)
+	_, err = bob.Execute(`INSERT INTO orders (id, amount) VALUES (2, 20)`)
+	require.NoError(t, err)
+
+	// IN 子查询只看到本租户的行
+	rows, err := alice.QueryAll(`SELECT id FROM products WHERE id IN (SELECT id FROM orders)`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 1, rows[0]["id"])
+
+	_, err = alice.QueryAll(`SELECT * FROM (SELECT * FROM orders) o`)
+	assertTableAccessDenied(t, err)
+	_, err = alice.QueryAll(`CHECKSUM TABLE orders`)
+	assertTableAccessDenied(t, err)
+
+	// ON DUPLICATE KEY UPDATE 只更新本租户的行，与其他租户的行冲突时报主键冲突
+	_, err = alice.Execute(`INSERT INTO orders (id, amount) VALUES (1, 5) ON DUPLICATE KEY UPDATE amount = 11`)
+	require.NoError(t, err)
+	_, err = alice.Execute(`INSERT INTO orders (id, amount) VALUES (2, 5) ON DUPLICATE KEY UPDATE amount = 777`)
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrDupEntry, code)
+	rows, err = root.QueryAll(`SELECT id, amount FROM orders ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 11, rows[0]["amount"])
+	assert.EqualValues(t, 20, rows[1]["amount"])
+
+	// INTO OUTFILE 的查询在执行时同样被限定
+	dir := t.TempDir()
+	db.config.OutfileDirs = []string{dir}
+	path := filepath.Join(dir, "orders.csv")
+	_, err = alice.Execute(fmt.Sprintf(`SELECT id, tenant_id FROM orders INTO OUTFILE '%s'`, path))
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "id,tenant_id\n1,acme\n", string(data))
+}
+
+func assertTableAccessDenied(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrTableAccessDenied, code)
+}
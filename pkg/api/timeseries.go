@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/builtin"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// carryForward 按结果集的行序把 columns 列中的 NULL 替换为上一个非 NULL 值（LOCF）。
+// 结果集可能来自查询缓存，改写的行复制后再修改
+func carryForward(result *domain.QueryResult, columns []string) *domain.QueryResult {
+	if result == nil || len(result.Rows) == 0 {
+		return result
+	}
+	filled := *result
+	filled.Rows = make([]domain.Row, len(result.Rows))
+	last := make(map[string]interface{}, len(columns))
+	for i, row := range result.Rows {
+		filled.Rows[i] = row
+		copied := false
+		for _, col := range columns {
+			if v := row[col]; v != nil {
+				last[col] = v
+				continue
+			}
+			prev, seen := last[col]
+			if !seen {
+				continue
+			}
+			if !copied {
+				filled.Rows[i] = make(domain.Row, len(row))
+				for k, val := range row {
+					filled.Rows[i][k] = val
+				}
+				copied = true
+			}
+			filled.Rows[i][col] = prev
+		}
+	}
+	return &filled
+}
+
+// fillTimeSeries 对结果集依次补齐缺失的时间桶（TIME_BUCKET_GAPFILL）与向前填充（LOCF）
+func fillTimeSeries(result *domain.QueryResult, series *parser.TimeSeriesQuery) (*domain.QueryResult, error) {
+	if series.GapFill != nil {
+		filled, err := gapFill(result, series.GapFill)
+		if err != nil {
+			return nil, NewError(ErrCodeInvalidParam, err.Error(), nil)
+		}
+		result = filled
+	}
+	if len(series.LOCF) > 0 {
+		result = carryForward(result, series.LOCF)
+	}
+	return result, nil
+}
+
+// gapFill 把结果集按时间桶排序，并为 [start, finish) 内没有行的时间桶插入其他列为 NULL 的行；
+// 未给出 start/finish 时取结果中最早、最晚的时间桶。时间桶为 NULL 的行排在最后
+func gapFill(result *domain.QueryResult, gap *parser.GapFill) (*domain.QueryResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	width, err := builtin.ParseInterval(gap.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", parser.TimeBucketGapfillFunc, err)
+	}
+
+	type bucketRow struct {
+		at  time.Time
+		row domain.Row
+	}
+	rows := make([]bucketRow, 0, len(result.Rows))
+	var unbucketed []domain.Row
+	seen := make(map[int64]bool, len(result.Rows))
+	var earliest, latest time.Time
+	for _, row := range result.Rows {
+		v := row[gap.Column]
+		if v == nil {
+			unbucketed = append(unbucketed, row)
+			continue
+		}
+		at, err := builtin.ToTime(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", parser.TimeBucketGapfillFunc, err)
+		}
+		if len(rows) == 0 || at.Before(earliest) {
+			earliest = at
+		}
+		if len(rows) == 0 || at.After(latest) {
+			latest = at
+		}
+		rows = append(rows, bucketRow{at, row})
+		seen[at.UnixNano()] = true
+	}
+
+	start, finish := gap.Start, gap.Finish
+	if start == nil {
+		if len(rows) == 0 {
+			return result, nil
+		}
+		start = earliest
+	}
+	if finish == nil {
+		if len(rows) == 0 {
+			return result, nil
+		}
+		finish = latest.Add(width)
+	}
+	buckets, err := builtin.GapFillBuckets(gap.Interval, start, finish)
+	if err != nil {
+		return nil, err
+	}
+	for _, at := range buckets {
+		if seen[at.UnixNano()] {
+			continue
+		}
+		row := make(domain.Row, len(result.Columns))
+		for _, col := range result.Columns {
+			row[col.Name] = nil
+		}
+		row[gap.Column] = at
+		rows = append(rows, bucketRow{at, row})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].at.Before(rows[j].at) })
+
+	filled := *result
+	filled.Rows = make([]domain.Row, 0, len(rows)+len(unbucketed))
+	for _, r := range rows {
+		filled.Rows = append(filled.Rows, r.row)
+	}
+	filled.Rows = append(filled.Rows, unbucketed...)
+	filled.Total = int64(len(filled.Rows))
+	return &filled, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTimeSeriesTestSession 建立监控数据表 metrics：00:05 所在的 5 分钟时间桶没有数据
+func newTimeSeriesTestSession(t *testing.T) *Session {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE metrics (id INT PRIMARY KEY AUTO_INCREMENT, ts DATETIME, v DOUBLE)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO metrics (ts, v) VALUES ('2024-01-01 00:00:10', 1), ('2024-01-01 00:01:10', 2),
+		('2024-01-01 00:11:00', 5)`)
+	require.NoError(t, err)
+	return s
+}
+
+// TestTimeSeries_BucketAggregates 测试 TIME_BUCKET 分组与 FIRST/LAST 聚合
+func TestTimeSeries_BucketAggregates(t *testing.T) {
+	s := newTimeSeriesTestSession(t)
+
+	rows, err := s.QueryAll(`SELECT TIME_BUCKET('5 minutes', ts) AS bucket, FIRST(v, ts) AS first_v, LAST(v, ts) AS last_v
+		FROM metrics GROUP BY bucket ORDER BY bucket`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 1, rows[0]["first_v"])
+	assert.EqualValues(t, 2, rows[0]["last_v"])
+	assert.EqualValues(t, 5, rows[1]["last_v"])
+}
+
+// TestTimeSeries_GapFill 测试 TIME_BUCKET_GAPFILL 补齐缺失的时间桶以及 LOCF 向前填充
+func TestTimeSeries_GapFill(t *testing.T) {
+	s := newTimeSeriesTestSession(t)
+
+	rows, err := s.QueryAll(`SELECT TIME_BUCKET_GAPFILL('5 minutes', ts) AS bucket, AVG(v) AS avg_v FROM metrics GROUP BY bucket`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), rows[1]["bucket"])
+	assert.Nil(t, rows[1]["avg_v"])
+
+	rows, err = s.QueryAll(`SELECT TIME_BUCKET_GAPFILL('5 minutes', ts, '2023-12-31 23:55:00', '2024-01-01 00:20:00') AS bucket,
+		LOCF(AVG(v)) AS avg_v FROM metrics GROUP BY bucket`)
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	assert.Nil(t, rows[0]["avg_v"])
+	assert.EqualValues(t, 1.5, rows[1]["avg_v"])
+	assert.EqualValues(t, 1.5, rows[2]["avg_v"])
+	assert.EqualValues(t, 5, rows[4]["avg_v"])
+}
+
+// TestTimeSeries_GenerateSeries 测试 GENERATE_SERIES 表函数
+func TestTimeSeries_GenerateSeries(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	rows, err := s.QueryAll(`SELECT value FROM GENERATE_SERIES('2024-01-01 00:00:00', '2024-01-01 00:10:00', '5 minutes')`)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), rows[2]["value"])
+
+	rows, err = s.QueryAll(`SELECT value FROM GENERATE_SERIES(1, 5) WHERE value > 3`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	_, err = s.QueryAll(`SELECT value FROM GENERATE_SERIES(1)`)
+	assert.Error(t, err)
+}
@@ -3,10 +3,14 @@ package api
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 )
 
@@ -16,6 +20,11 @@ type Transaction struct {
 	tx      domain.Transaction
 	active  bool
 	mu      sync.Mutex
+
+	ddlTables []string // 事务内 DDL 涉及的表，提交后失效其缓存
+
+	// branches 跨数据源事务中其他数据源上的事务分支（库名 -> 分支），提交时两阶段提交
+	branches map[string]domain.Transaction
 }
 
 // NewTransaction 创建 Transaction
@@ -30,6 +39,18 @@ func NewTransaction(session *Session, tx domain.Transaction) *Transaction {
 // Query 事务内查询
 // Supports parameter binding with ? placeholders
 func (t *Transaction) Query(sql string, args ...interface{}) (*Query, error) {
+	start := time.Now()
+	diagnostics := t.session.beginDiagnostics(sql)
+	q, err := t.query(sql, args...)
+	recordQuery(t.session, sql, start, q, err)
+	if diagnostics {
+		t.session.recordDiagnostics(q.Warnings(), err)
+	}
+	return q, err
+}
+
+// query Query 的实际实现
+func (t *Transaction) query(sql string, args ...interface{}) (*Query, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -48,8 +69,7 @@ func (t *Transaction) Query(sql string, args ...interface{}) (*Query, error) {
 	}
 
 	// Parse SQL to extract table name and query options
-	adapter := parser.NewSQLAdapter()
-	parseResult, err := adapter.Parse(boundSQL)
+	parseResult, err := t.parse(boundSQL)
 	if err != nil {
 		return nil, WrapError(err, ErrCodeSyntax, "failed to parse SQL")
 	}
@@ -59,10 +79,17 @@ func (t *Transaction) Query(sql string, args ...interface{}) (*Query, error) {
 	if parseResult.Statement.Type != parser.SQLTypeSelect || parseResult.Statement.Select == nil {
 		return nil, NewError(ErrCodeInvalidParam, "transaction.Query only supports SELECT statements", nil)
 	}
+	if err := t.session.checkShadowTransaction(parseResult.Statement); err != nil {
+		return nil, err
+	}
 
 	selectStmt := parseResult.Statement.Select
-	tableName := selectStmt.From
-	options := &domain.QueryOptions{}
+	ctx := t.lockContext(selectStmt.LockNoWait)
+	tx, tableName, err := t.resolveTable(ctx, selectStmt.From)
+	if err != nil {
+		return nil, err
+	}
+	options := &domain.QueryOptions{Lock: domain.LockMode(selectStmt.Lock)}
 
 	// Extract WHERE filters
 	if selectStmt.Where != nil {
@@ -70,9 +97,12 @@ func (t *Transaction) Query(sql string, args ...interface{}) (*Query, error) {
 	}
 
 	// Extract ORDER BY
-	if len(selectStmt.OrderBy) > 0 {
-		options.OrderBy = selectStmt.OrderBy[0].Column
-		options.Order = selectStmt.OrderBy[0].Direction
+	for _, item := range selectStmt.OrderBy {
+		options.Sort = append(options.Sort, domain.OrderByItem{Column: item.Column, Direction: item.Direction, Collation: item.Collation})
+	}
+	if len(options.Sort) > 0 {
+		options.OrderBy = options.Sort[0].Column
+		options.Order = options.Sort[0].Direction
 	}
 
 	// Extract LIMIT and OFFSET
@@ -105,9 +135,9 @@ func (t *Transaction) Query(sql string, args ...interface{}) (*Query, error) {
 		options.SelectColumns = cols
 	}
 
-	result, err := t.tx.Query(context.Background(), tableName, options)
+	result, err := tx.Query(ctx, tableName, options)
 	if err != nil {
-		return nil, WrapError(err, ErrCodeTransaction, "transaction query failed")
+		return nil, t.wrapError(err, "transaction query failed")
 	}
 
 	return NewQuery(t.session, result, boundSQL, nil), nil
@@ -116,6 +146,18 @@ func (t *Transaction) Query(sql string, args ...interface{}) (*Query, error) {
 // Execute 事务内执行命令
 // Supports parameter binding with ? placeholders
 func (t *Transaction) Execute(sql string, args ...interface{}) (*Result, error) {
+	start := time.Now()
+	diagnostics := t.session.beginDiagnostics(sql)
+	result, err := t.execute(sql, args...)
+	recordExecute(t.session, sql, start, result, err)
+	if diagnostics {
+		t.session.recordDiagnostics(result.warnings(), err)
+	}
+	return result, err
+}
+
+// execute Execute 的实际实现
+func (t *Transaction) execute(sql string, args ...interface{}) (*Result, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -134,16 +176,24 @@ func (t *Transaction) Execute(sql string, args ...interface{}) (*Result, error)
 	}
 
 	// Parse SQL to determine statement type and extract parameters
-	adapter := parser.NewSQLAdapter()
-	parseResult, err := adapter.Parse(boundSQL)
+	parseResult, err := t.parse(boundSQL)
 	if err != nil {
 		return nil, WrapError(err, ErrCodeSyntax, "failed to parse SQL")
 	}
 	if !parseResult.Success {
 		return nil, NewError(ErrCodeSyntax, "SQL parse error: "+parseResult.Error, nil)
 	}
+	if err := t.session.checkWritePolicy(parseResult.Statement); err != nil {
+		return nil, err
+	}
+	if err := t.session.checkShadowTransaction(parseResult.Statement); err != nil {
+		return nil, err
+	}
+	if err := t.session.checkQuota(parseResult.Statement); err != nil {
+		return nil, err
+	}
 
-	ctx := context.Background()
+	ctx := t.lockContext(false)
 
 	switch parseResult.Statement.Type {
 	case parser.SQLTypeInsert:
@@ -162,9 +212,13 @@ func (t *Transaction) Execute(sql string, args ...interface{}) (*Result, error)
 			}
 			rows = append(rows, row)
 		}
-		affected, err := t.tx.Insert(ctx, insertStmt.Table, rows, nil)
+		tx, err := t.txFor(ctx, insertStmt.Database)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := tx.Insert(ctx, insertStmt.Table, rows, nil)
 		if err != nil {
-			return nil, WrapError(err, ErrCodeTransaction, "transaction insert failed")
+			return nil, t.wrapError(err, "transaction insert failed")
 		}
 		return NewResult(affected, 0, nil), nil
 
@@ -183,9 +237,23 @@ func (t *Transaction) Execute(sql string, args ...interface{}) (*Result, error)
 		if updateStmt.Where != nil {
 			filters = expressionToFilters(updateStmt.Where)
 		}
-		affected, err := t.tx.Update(ctx, updateStmt.Table, filters, updates, nil)
+		tx, err := t.txFor(ctx, updateStmt.Database)
+		if err != nil {
+			return nil, err
+		}
+		var affected int64
+		if parser.HasRowAssignments(updateStmt.Set) {
+			// SET 中引用列的表达式（balance = balance - 10）在事务内读出的每一行上求值
+			tableInfo, infoErr := t.tableInfo(ctx, updateStmt.Database, updateStmt.Table)
+			if infoErr != nil {
+				return nil, t.wrapError(infoErr, "transaction update failed")
+			}
+			affected, err = parser.UpdateEachRow(ctx, tx, updateStmt.Table, tableInfo, filters, updateStmt.Set, nil)
+		} else {
+			affected, err = tx.Update(ctx, updateStmt.Table, filters, updates, nil)
+		}
 		if err != nil {
-			return nil, WrapError(err, ErrCodeTransaction, "transaction update failed")
+			return nil, t.wrapError(err, "transaction update failed")
 		}
 		return NewResult(affected, 0, nil), nil
 
@@ -199,18 +267,254 @@ func (t *Transaction) Execute(sql string, args ...interface{}) (*Result, error)
 		if deleteStmt.Where != nil {
 			filters = expressionToFilters(deleteStmt.Where)
 		}
-		affected, err := t.tx.Delete(ctx, deleteStmt.Table, filters, nil)
+		tx, err := t.txFor(ctx, deleteStmt.Database)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := tx.Delete(ctx, deleteStmt.Table, filters, nil)
 		if err != nil {
-			return nil, WrapError(err, ErrCodeTransaction, "transaction delete failed")
+			return nil, t.wrapError(err, "transaction delete failed")
 		}
 		return NewResult(affected, 0, nil), nil
 
+	case parser.SQLTypeCreate, parser.SQLTypeDrop, parser.SQLTypeTruncate, parser.SQLTypeAlter:
+		return t.executeDDL(ctx, parseResult.Statement)
+
+	case parser.SQLTypeSavepoint:
+		if parseResult.Statement.Savepoint == nil {
+			return nil, NewError(ErrCodeSyntax, "invalid SAVEPOINT statement", nil)
+		}
+		return t.savepointOp(ctx, parseResult.Statement.Savepoint.Name, domain.SavepointTransaction.Savepoint)
+
+	case parser.SQLTypeRelease:
+		if parseResult.Statement.Savepoint == nil {
+			return nil, NewError(ErrCodeSyntax, "invalid RELEASE SAVEPOINT statement", nil)
+		}
+		return t.savepointOp(ctx, parseResult.Statement.Savepoint.Name, domain.SavepointTransaction.ReleaseSavepoint)
+
+	case parser.SQLTypeRollback:
+		// 仅支持 ROLLBACK TO SAVEPOINT，整体回滚请调用 Rollback()
+		if parseResult.Statement.Rollback == nil || parseResult.Statement.Rollback.Savepoint == "" {
+			return nil, NewError(ErrCodeNotSupported, "use Transaction.Rollback() to roll back the transaction", nil)
+		}
+		return t.savepointOp(ctx, parseResult.Statement.Rollback.Savepoint, domain.SavepointTransaction.RollbackToSavepoint)
+
 	default:
 		return nil, NewError(ErrCodeNotSupported,
 			fmt.Sprintf("transaction.Execute does not support %s statements", parseResult.Statement.Type), nil)
 	}
 }
 
+// executeDDL 在事务内执行 CREATE/DROP/TRUNCATE/ALTER TABLE
+// 需开启 DBConfig.TransactionalDDL 且数据源事务实现 domain.DDLTransaction，
+// 变更在提交时才对其他会话可见，回滚时撤销
+func (t *Transaction) executeDDL(ctx context.Context, stmt *parser.SQLStatement) (*Result, error) {
+	if stmt.CreateIndex != nil || stmt.DropIndex != nil {
+		return nil, NewError(ErrCodeNotSupported,
+			fmt.Sprintf("transaction.Execute does not support %s INDEX statements", stmt.Type), nil)
+	}
+	if t.session.db == nil || !t.session.db.config.TransactionalDDL {
+		return nil, NewError(ErrCodeNotSupported,
+			fmt.Sprintf("transaction.Execute does not support %s statements (transactional DDL is disabled)", stmt.Type), nil)
+	}
+	ddlTx, ok := t.tx.(domain.DDLTransaction)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, "data source does not support transactional DDL", nil)
+	}
+
+	ds := &txDDLDataSource{DataSource: t.session.coreSession.GetDataSource(), tx: ddlTx}
+	result, err := parser.NewQueryBuilder(ds).ExecuteStatement(ctx, stmt)
+	if err != nil {
+		return nil, t.wrapError(err, "transaction DDL failed")
+	}
+	t.ddlTables = append(t.ddlTables, ds.tables...)
+	return NewResult(result.Total, 0, nil), nil
+}
+
+// savepointOp 执行保存点操作
+func (t *Transaction) savepointOp(ctx context.Context, name string,
+	op func(domain.SavepointTransaction, context.Context, string) error) (*Result, error) {
+	sp, ok := t.tx.(domain.SavepointTransaction)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, "data source does not support savepoints", nil)
+	}
+	if err := op(sp, ctx, name); err != nil {
+		return nil, WrapError(err, ErrCodeTransaction, "savepoint operation failed")
+	}
+	return NewResult(0, 0, nil), nil
+}
+
+// Savepoint 创建保存点，同名保存点会被替换
+func (t *Transaction) Savepoint(name string) error {
+	_, err := t.Execute("SAVEPOINT " + name)
+	return err
+}
+
+// RollbackTo 回滚到保存点，保存点本身保留
+func (t *Transaction) RollbackTo(name string) error {
+	_, err := t.Execute("ROLLBACK TO SAVEPOINT " + name)
+	return err
+}
+
+// ReleaseSavepoint 释放保存点
+func (t *Transaction) ReleaseSavepoint(name string) error {
+	_, err := t.Execute("RELEASE SAVEPOINT " + name)
+	return err
+}
+
+// txDDLDataSource 将 DDL 转发到事务，其余操作使用会话的数据源
+type txDDLDataSource struct {
+	domain.DataSource
+	tx     domain.DDLTransaction
+	tables []string
+}
+
+func (d *txDDLDataSource) CreateTable(ctx context.Context, info *domain.TableInfo) error {
+	d.tables = append(d.tables, info.Name)
+	return d.tx.CreateTable(ctx, info)
+}
+
+func (d *txDDLDataSource) DropTable(ctx context.Context, tableName string) error {
+	d.tables = append(d.tables, tableName)
+	return d.tx.DropTable(ctx, tableName)
+}
+
+func (d *txDDLDataSource) TruncateTable(ctx context.Context, tableName string) error {
+	d.tables = append(d.tables, tableName)
+	return d.tx.TruncateTable(ctx, tableName)
+}
+
+func (d *txDDLDataSource) SetTableTTL(ctx context.Context, tableName string, ttl *domain.TTLInfo) error {
+	d.tables = append(d.tables, tableName)
+	return d.tx.SetTableTTL(ctx, tableName, ttl)
+}
+
+// parse 用会话的解析器解析语句，事务内的语句同样经过查询改写钩子
+func (t *Transaction) parse(sql string) (*parser.ParseResult, error) {
+	if t.session.coreSession == nil {
+		return parser.NewSQLAdapter().Parse(sql)
+	}
+	return t.session.coreSession.GetAdapter().Parse(sql)
+}
+
+// lockContext 返回携带锁等待超时和加密列解密权限的上下文
+// noWait 对应 NOWAIT；否则使用会话变量 innodb_lock_wait_timeout（秒），未设置时由数据源决定
+func (t *Transaction) lockContext(noWait bool) context.Context {
+	ctx := context.Background()
+	if t.session.coreSession != nil {
+		ctx = t.session.coreSession.ColumnDecryptionContext(ctx)
+	}
+	if noWait {
+		return domain.WithLockWaitTimeout(ctx, 0)
+	}
+	if t.session.coreSession == nil {
+		return ctx
+	}
+	if v, ok := t.session.coreSession.GetSessionVar("innodb_lock_wait_timeout"); ok {
+		if secs, err := strconv.ParseFloat(strings.Trim(v, "'\""), 64); err == nil && secs > 0 {
+			ctx = domain.WithLockWaitTimeout(ctx, time.Duration(secs*float64(time.Second)))
+		}
+	}
+	return ctx
+}
+
+// resolveTable 解析表名，返回表所在数据源的事务分支和表名
+// 未限定的表名属于当前数据库；db.table 的库名不是已注册的数据源时按原样使用完整表名
+func (t *Transaction) resolveTable(ctx context.Context, name string) (domain.Transaction, string, error) {
+	i := strings.Index(name, ".")
+	if i < 0 || t.session.db == nil {
+		tx, err := t.txFor(ctx, "")
+		return tx, name, err
+	}
+	if _, err := t.session.db.GetDataSource(name[:i]); err != nil {
+		return t.tx, name, nil
+	}
+	tx, err := t.txFor(ctx, name[:i])
+	return tx, name[i+1:], err
+}
+
+// txFor 返回语句所在数据源的事务，database 为空时使用当前数据库
+// database 为已注册的其他数据源时在其上开启事务分支，同一数据源的语句共用一个分支
+func (t *Transaction) txFor(ctx context.Context, database string) (domain.Transaction, error) {
+	if t.session.db == nil {
+		return t.tx, nil
+	}
+	if database == "" {
+		database = t.session.coreSession.GetCurrentDB()
+	}
+	ds, err := t.session.db.GetDataSource(database)
+	if err != nil || ds == t.session.coreSession.GetDataSource() {
+		return t.tx, nil
+	}
+	if tx, ok := t.branches[database]; ok {
+		return tx, nil
+	}
+
+	txDS, ok := ds.(domain.TransactionalDataSource)
+	if !ok {
+		return nil, NewError(ErrCodeNotSupported, "datasource '"+database+"' does not support transactions", nil)
+	}
+	tx, err := txDS.BeginTransaction(ctx, &domain.TransactionOptions{
+		IsolationLevel: t.session.coreSession.IsolationLevel(),
+	})
+	if err != nil {
+		return nil, WrapError(err, ErrCodeTransaction, "failed to begin transaction on datasource '"+database+"'")
+	}
+	if t.branches == nil {
+		t.branches = make(map[string]domain.Transaction)
+	}
+	t.branches[database] = tx
+	return tx, nil
+}
+
+// tableInfo 返回表结构，表所在的数据源与 txFor 的选择一致
+func (t *Transaction) tableInfo(ctx context.Context, database, table string) (*domain.TableInfo, error) {
+	ds := t.session.coreSession.GetDataSource()
+	if t.session.db != nil {
+		if database == "" {
+			database = t.session.coreSession.GetCurrentDB()
+		}
+		if named, err := t.session.db.GetDataSource(database); err == nil {
+			ds = named
+		}
+	}
+	return ds.GetTableInfo(ctx, table)
+}
+
+// participants 返回跨数据源事务的所有参与者，会话数据源在前
+func (t *Transaction) participants() []application.XAParticipant {
+	names := make([]string, 0, len(t.branches))
+	for name := range t.branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	participants := []application.XAParticipant{{
+		DataSource: t.session.db.dataSourceName(t.session.coreSession.GetDataSource()),
+		Tx:         t.tx,
+	}}
+	for _, name := range names {
+		participants = append(participants, application.XAParticipant{DataSource: name, Tx: t.branches[name]})
+	}
+	return participants
+}
+
+// wrapError 包装事务内操作的错误
+// 死锁时数据源已回滚被选为牺牲者的事务，事务对象随之失效，其他分支一并回滚
+func (t *Transaction) wrapError(err error, message string) error {
+	if domain.IsDeadlock(err) {
+		t.active = false
+		if len(t.branches) > 0 {
+			_ = t.session.db.xa.Rollback(context.Background(), t.participants())
+			t.branches = nil
+		}
+		if t.session.logger != nil {
+			t.session.logger.Warn("[TX] Transaction rolled back after deadlock")
+		}
+	}
+	return WrapError(err, ErrCodeTransaction, message)
+}
+
 // Commit 提交事务
 func (t *Transaction) Commit() error {
 	t.mu.Lock()
@@ -220,13 +524,27 @@ func (t *Transaction) Commit() error {
 		return NewError(ErrCodeTransaction, "transaction is not active", nil)
 	}
 
-	err := t.tx.Commit(context.Background())
-	if err != nil {
+	if len(t.branches) > 0 {
+		// 跨数据源：两阶段提交，失败时所有分支已回滚或等待恢复
+		err := t.session.db.xa.Commit(context.Background(), t.participants())
+		t.active = false
+		t.branches = nil
+		if err != nil {
+			return WrapError(err, ErrCodeTransaction, "commit failed")
+		}
+	} else if err := t.tx.Commit(context.Background()); err != nil {
 		return WrapError(err, ErrCodeTransaction, "commit failed")
 	}
 
 	t.active = false
 
+	if t.session.db != nil {
+		for _, table := range t.ddlTables {
+			t.session.db.InvalidateTable(table)
+		}
+	}
+	t.ddlTables = nil
+
 	if t.session.logger != nil {
 		t.session.logger.Debug("[TX] Transaction committed")
 	}
@@ -243,12 +561,19 @@ func (t *Transaction) Rollback() error {
 		return NewError(ErrCodeTransaction, "transaction is not active", nil)
 	}
 
-	err := t.tx.Rollback(context.Background())
+	var err error
+	if len(t.branches) > 0 {
+		err = t.session.db.xa.Rollback(context.Background(), t.participants())
+		t.branches = nil
+	} else {
+		err = t.tx.Rollback(context.Background())
+	}
 	if err != nil {
 		return WrapError(err, ErrCodeTransaction, "rollback failed")
 	}
 
 	t.active = false
+	t.ddlTables = nil
 
 	if t.session.logger != nil {
 		t.session.logger.Warn("[TX] Transaction rolled back")
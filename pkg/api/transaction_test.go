@@ -7,6 +7,7 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTransaction(t *testing.T) {
@@ -874,3 +875,25 @@ func TestTransaction_Execute_InsertWithColumns(t *testing.T) {
 	assert.Equal(t, "John", row["first_name"])
 	assert.Equal(t, "Doe", row["last_name"])
 }
+
+// TestTransaction_Execute_UpdateExpression 测试事务内 SET 引用列的赋值在每一行的当前值上求值
+func TestTransaction_Execute_UpdateExpression(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+	_, err := s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 10), (2, 20)`)
+	require.NoError(t, err)
+
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	result, err := tx.Execute(`UPDATE ledger.entries SET amount = amount + 5 WHERE id > 0`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, result.RowsAffected)
+	require.NoError(t, tx.Commit())
+
+	rows, err := s.QueryAll(`SELECT id, amount FROM ledger.entries ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 15, rows[0]["amount"])
+	assert.EqualValues(t, 25, rows[1]["amount"])
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTransactionalDDLSession(t *testing.T, enabled bool) (*memory.MVCCDataSource, *Session) {
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	t.Cleanup(func() { ds.Close(context.Background()) })
+
+	db, err := NewDB(&DBConfig{
+		DefaultLogger:    NewDefaultLogger(LogError),
+		TransactionalDDL: enabled,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.RegisterDataSource("test", ds))
+
+	session := db.Session()
+	t.Cleanup(func() {
+		session.Close()
+		db.Close()
+	})
+	return ds, session
+}
+
+// TestTransactionalDDL 测试事务内 DDL 提交后生效、回滚后撤销
+func TestTransactionalDDL(t *testing.T) {
+	ds, session := newTransactionalDDLSession(t, true)
+	ctx := context.Background()
+
+	_, err := session.Execute(`CREATE TABLE old (id INT PRIMARY KEY)`)
+	require.NoError(t, err)
+	_, err = session.Execute(`INSERT INTO old VALUES (1), (2)`)
+	require.NoError(t, err)
+
+	tx, err := session.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`CREATE TABLE fresh (id INT PRIMARY KEY, name VARCHAR(20), created_at DATETIME)`)
+	require.NoError(t, err)
+	_, err = tx.Execute(`INSERT INTO fresh (id, name) VALUES (1, 'a')`)
+	require.NoError(t, err)
+	_, err = tx.Execute(`DROP TABLE old`)
+	require.NoError(t, err)
+
+	// 提交前其他会话看不到变更
+	_, err = ds.GetTableInfo(ctx, "fresh")
+	assert.Error(t, err)
+	_, err = ds.GetTableInfo(ctx, "old")
+	assert.NoError(t, err)
+
+	require.NoError(t, tx.Commit())
+	_, err = ds.GetTableInfo(ctx, "old")
+	assert.Error(t, err)
+	rows, err := session.QueryAll(`SELECT name FROM fresh`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "a", rows[0]["name"])
+
+	// 回滚撤销 TRUNCATE 和 ALTER TTL
+	tx2, err := ds.BeginTransaction(ctx, nil)
+	require.NoError(t, err)
+	rollbackTx := NewTransaction(session, tx2)
+	_, err = rollbackTx.Execute(`TRUNCATE TABLE fresh`)
+	require.NoError(t, err)
+	_, err = rollbackTx.Execute(`ALTER TABLE fresh TTL = created_at + INTERVAL 1 DAY`)
+	require.NoError(t, err)
+	require.NoError(t, rollbackTx.Rollback())
+
+	info, err := ds.GetTableInfo(ctx, "fresh")
+	require.NoError(t, err)
+	assert.Nil(t, info.TTL)
+	rows, err = session.QueryAll(`SELECT id FROM fresh`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+}
+
+// TestTransactionalDDL_Disabled 测试未开启选项时事务内 DDL 被拒绝
+func TestTransactionalDDL_Disabled(t *testing.T) {
+	_, session := newTransactionalDDLSession(t, false)
+
+	tx, err := session.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = tx.Execute(`CREATE TABLE t1 (id INT)`)
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeNotSupported))
+}
+
+// TestTransaction_Savepoints 测试事务内保存点
+func TestTransaction_Savepoints(t *testing.T) {
+	_, session := newTransactionalDDLSession(t, true)
+
+	_, err := session.Execute(`CREATE TABLE sp (id INT PRIMARY KEY)`)
+	require.NoError(t, err)
+
+	tx, err := session.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`INSERT INTO sp (id) VALUES (1)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Savepoint("a"))
+	_, err = tx.Execute(`INSERT INTO sp (id) VALUES (2)`)
+	require.NoError(t, err)
+	_, err = tx.Execute(`CREATE TABLE sp_tmp (id INT)`)
+	require.NoError(t, err)
+
+	_, err = tx.Execute(`ROLLBACK TO SAVEPOINT a`)
+	require.NoError(t, err)
+	q, err := tx.Query(`SELECT * FROM sp`)
+	require.NoError(t, err)
+	assert.Len(t, q.result.Rows, 1)
+	q.Close()
+
+	require.NoError(t, tx.ReleaseSavepoint("a"))
+	assert.Error(t, tx.RollbackTo("a"))
+	require.NoError(t, tx.Commit())
+
+	rows, err := session.QueryAll(`SELECT id FROM sp`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+	_, err = session.Execute(`SELECT * FROM sp_tmp`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// defaultTTLPurgeBatchSize is the number of rows scanned per batch when
+// DBConfig.TTLPurgeBatchSize is not set
+const defaultTTLPurgeBatchSize = 1000
+
+// AuditLogger receives audit events produced by background DB tasks.
+// *security.AuditLogger implements it.
+type AuditLogger interface {
+	LogTTLPurge(database, table string, rowsDeleted int64, cutoff time.Time, duration int64, err error)
+}
+
+// TTLStats contains metrics of the TTL purger
+type TTLStats struct {
+	Runs         int64                    // completed purge passes
+	RowsPurged   int64                    // expired rows deleted in total
+	Errors       int64                    // tables whose purge failed
+	LastRun      time.Time                // start time of the last pass
+	LastDuration time.Duration            // duration of the last pass
+	Tables       map[string]TTLTableStats // keyed by "<datasource>.<table>"
+}
+
+// TTLTableStats contains TTL purge metrics of a single table
+type TTLTableStats struct {
+	DataSource string
+	Table      string
+	RowsPurged int64
+	LastRun    time.Time
+	LastCutoff time.Time
+	LastError  string
+}
+
+// ttlPurger deletes rows that outlived the TTL of their table
+type ttlPurger struct {
+	mu    sync.Mutex // serializes purge passes; guards audit, stop and done
+	audit AuditLogger
+
+	statsMu sync.RWMutex
+	stats   TTLStats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newTTLPurger() *ttlPurger {
+	return &ttlPurger{
+		stats: TTLStats{Tables: make(map[string]TTLTableStats)},
+	}
+}
+
+// SetAuditLogger sets the audit logger that records TTL purges
+func (db *DB) SetAuditLogger(al AuditLogger) {
+	p := db.ttlPurger
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.audit = al
+}
+
+// SetTableTTL sets the row expiration policy of a table: rows whose column
+// value is older than ttl are deleted by the TTL purger.
+// An empty dsName selects the default datasource.
+func (db *DB) SetTableTTL(ctx context.Context, dsName, tableName, column string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return NewError(ErrCodeInvalidParam, "TTL must be positive", nil)
+	}
+	return db.setTableTTL(ctx, dsName, tableName, &domain.TTLInfo{Column: column, Duration: ttl})
+}
+
+// RemoveTableTTL removes the row expiration policy of a table
+func (db *DB) RemoveTableTTL(ctx context.Context, dsName, tableName string) error {
+	return db.setTableTTL(ctx, dsName, tableName, nil)
+}
+
+func (db *DB) setTableTTL(ctx context.Context, dsName, tableName string, ttl *domain.TTLInfo) error {
+	var ds domain.DataSource
+	var err error
+	if dsName == "" {
+		ds, err = db.GetDefaultDataSource()
+	} else {
+		ds, err = db.GetDataSource(dsName)
+	}
+	if err != nil {
+		return err
+	}
+
+	tm, ok := ds.(domain.TTLManager)
+	if !ok {
+		return NewError(ErrCodeNotSupported, "datasource does not support TTL", nil)
+	}
+	if err := tm.SetTableTTL(ctx, tableName, ttl); err != nil {
+		return NewError(ErrCodeInternal, "failed to set TTL of table '"+tableName+"'", err)
+	}
+	db.InvalidateTable(tableName)
+	return nil
+}
+
+// TTLStats returns a snapshot of the TTL purger metrics
+func (db *DB) TTLStats() TTLStats {
+	p := db.ttlPurger
+	p.statsMu.RLock()
+	defer p.statsMu.RUnlock()
+
+	stats := p.stats
+	stats.Tables = make(map[string]TTLTableStats, len(p.stats.Tables))
+	for k, v := range p.stats.Tables {
+		stats.Tables[k] = v
+	}
+	return stats
+}
+
+// PurgeExpiredRows runs one TTL purge pass over every table with a TTL in
+// every writable datasource and returns the number of rows deleted.
+// Rows are scanned in batches of DBConfig.TTLPurgeBatchSize; each batch's
+// expired rows are deleted before the next batch is read.
+func (db *DB) PurgeExpiredRows(ctx context.Context) (int64, error) {
+	p := db.ttlPurger
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	start := time.Now()
+	var total int64
+	var errs []error
+	for dsName, ds := range db.dsManager.GetAllDataSources() {
+		if _, ok := ds.(domain.TTLManager); !ok || !ds.IsWritable() {
+			continue
+		}
+		tables, err := ds.GetTables(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, tableName := range tables {
+			info, err := ds.GetTableInfo(ctx, tableName)
+			if err != nil || info.TTL == nil {
+				continue
+			}
+
+			tableStart := time.Now()
+			cutoff := tableStart.Add(-info.TTL.Duration)
+			purged, err := db.purgeTable(ctx, ds, info, cutoff)
+			total += purged
+			p.recordTable(dsName, tableName, purged, tableStart, cutoff, err)
+
+			if purged > 0 {
+				db.InvalidateTable(tableName)
+				db.logger.Info("TTL purged %d expired rows from %s.%s", purged, dsName, tableName)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("purge %s.%s: %w", dsName, tableName, err))
+				db.logger.Warn("TTL purge of %s.%s failed: %v", dsName, tableName, err)
+			}
+			if p.audit != nil && (purged > 0 || err != nil) {
+				p.audit.LogTTLPurge(dsName, tableName, purged, cutoff, time.Since(tableStart).Milliseconds(), err)
+			}
+		}
+	}
+
+	p.statsMu.Lock()
+	p.stats.Runs++
+	p.stats.LastRun = start
+	p.stats.LastDuration = time.Since(start)
+	p.statsMu.Unlock()
+
+	return total, errors.Join(errs...)
+}
+
+// purgeTable deletes the rows of table whose TTL column is older than cutoff.
+// Expired rows are deleted by their TTL column values, so tables without a
+// primary key are supported as well.
+func (db *DB) purgeTable(ctx context.Context, ds domain.DataSource, table *domain.TableInfo, cutoff time.Time) (int64, error) {
+	column := table.TTL.Column
+	batchSize := db.config.TTLPurgeBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultTTLPurgeBatchSize
+	}
+
+	var purged int64
+	offset := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		result, err := ds.Query(ctx, table.Name, &domain.QueryOptions{
+			SelectColumns: []string{column},
+			Limit:         batchSize,
+			Offset:        offset,
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		seen := make(map[string]bool)
+		var expired []interface{}
+		kept := 0
+		for _, row := range result.Rows {
+			value := row[column]
+			if !ttlExpired(value, cutoff) {
+				kept++
+				continue
+			}
+			key := fmt.Sprintf("%T:%v", value, value)
+			if !seen[key] {
+				seen[key] = true
+				expired = append(expired, value)
+			}
+		}
+
+		if len(expired) > 0 {
+			n, err := ds.Delete(ctx, table.Name, []domain.Filter{
+				{Field: column, Operator: "IN", Value: expired},
+			}, nil)
+			purged += n
+			if err != nil {
+				return purged, err
+			}
+		}
+
+		if len(result.Rows) < batchSize {
+			return purged, nil
+		}
+		// Deleted rows are gone, so only the kept rows move the scan position
+		offset += kept
+	}
+}
+
+// ttlTimeLayouts are the layouts accepted for string TTL column values
+var ttlTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ttlExpired reports whether a TTL column value is older than cutoff.
+// Strings are parsed in the local time zone and integers are Unix seconds;
+// NULL and unparseable values never expire.
+func ttlExpired(value interface{}, cutoff time.Time) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case time.Time:
+		return v.Before(cutoff)
+	case string:
+		for _, layout := range ttlTimeLayouts {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t.Before(cutoff)
+			}
+		}
+		return false
+	}
+	if secs, err := utils.ToInt64(value); err == nil {
+		return time.Unix(secs, 0).Before(cutoff)
+	}
+	return false
+}
+
+func (p *ttlPurger) recordTable(dsName, tableName string, purged int64, start, cutoff time.Time, err error) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	key := dsName + "." + tableName
+	ts := p.stats.Tables[key]
+	ts.DataSource = dsName
+	ts.Table = tableName
+	ts.RowsPurged += purged
+	ts.LastRun = start
+	ts.LastCutoff = cutoff
+	ts.LastError = ""
+	if err != nil {
+		ts.LastError = err.Error()
+		p.stats.Errors++
+	}
+	p.stats.Tables[key] = ts
+	p.stats.RowsPurged += purged
+}
+
+// StartTTLPurger purges expired rows and table history older than its
+// retention every interval until StopTTLPurger or Close is called.
+// Calling it again restarts the purger.
+func (db *DB) StartTTLPurger(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	db.StopTTLPurger()
+
+	p := db.ttlPurger
+	p.mu.Lock()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	p.stop, p.done = stop, done
+	p.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := db.PurgeExpiredRows(context.Background()); err != nil {
+					db.logger.Warn("TTL purge failed: %v", err)
+				}
+				if _, err := db.PurgeHistory(context.Background()); err != nil {
+					db.logger.Warn("history purge failed: %v", err)
+				}
+				if _, err := db.PurgeRecycleBin(context.Background()); err != nil {
+					db.logger.Warn("recycle bin purge failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// StopTTLPurger stops the background TTL purger, if running
+func (db *DB) StopTTLPurger() {
+	p := db.ttlPurger
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAuditLogger struct {
+	tables []string
+	rows   []int64
+}
+
+func (l *recordingAuditLogger) LogTTLPurge(database, table string, rowsDeleted int64, cutoff time.Time, duration int64, err error) {
+	l.tables = append(l.tables, database+"."+table)
+	l.rows = append(l.rows, rowsDeleted)
+}
+
+func newTTLTestDB(t *testing.T, batchSize int) (*DB, *memory.MVCCDataSource, *Session) {
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+
+	db, err := NewDB(&DBConfig{
+		DefaultLogger:     NewDefaultLogger(LogError),
+		TTLPurgeBatchSize: batchSize,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.RegisterDataSource("test", ds))
+
+	session := db.Session()
+	t.Cleanup(func() {
+		session.Close()
+		db.Close()
+	})
+	return db, ds, session
+}
+
+func TestTableTTL_SQL(t *testing.T) {
+	_, ds, session := newTTLTestDB(t, 0)
+	ctx := context.Background()
+
+	_, err := session.Execute(`CREATE TABLE logs (id INT PRIMARY KEY, msg VARCHAR(50), created_at DATETIME) WITH TTL = '7d' ON COLUMN created_at`)
+	require.NoError(t, err)
+
+	info, err := ds.GetTableInfo(ctx, "logs")
+	require.NoError(t, err)
+	require.NotNil(t, info.TTL)
+	assert.Equal(t, "created_at", info.TTL.Column)
+	assert.Equal(t, 7*24*time.Hour, info.TTL.Duration)
+
+	_, err = session.Execute(`ALTER TABLE logs TTL = created_at + INTERVAL 12 HOUR`)
+	require.NoError(t, err)
+	info, err = ds.GetTableInfo(ctx, "logs")
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, info.TTL.Duration)
+
+	_, err = session.Execute(`ALTER TABLE logs REMOVE TTL`)
+	require.NoError(t, err)
+	info, err = ds.GetTableInfo(ctx, "logs")
+	require.NoError(t, err)
+	assert.Nil(t, info.TTL)
+
+	// TTL 列必须存在
+	_, err = session.Execute(`CREATE TABLE bad (id INT) WITH TTL = '1d' ON COLUMN missing`)
+	assert.Error(t, err)
+}
+
+func TestTableTTL_Purge(t *testing.T) {
+	db, ds, session := newTTLTestDB(t, 2)
+	ctx := context.Background()
+	audit := &recordingAuditLogger{}
+	db.SetAuditLogger(audit)
+
+	_, err := session.Execute(`CREATE TABLE events (id INT PRIMARY KEY, created_at DATETIME)`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour).Format("2006-01-02 15:04:05")
+	recent := now.Add(-time.Hour).Format("2006-01-02 15:04:05")
+	for i, ts := range []string{old, recent, old, old, recent, old, recent} {
+		_, err := session.Execute(fmt.Sprintf(`INSERT INTO events VALUES (%d, '%s')`, i+1, ts))
+		require.NoError(t, err)
+	}
+
+	// 未设置 TTL 时不清理
+	purged, err := db.PurgeExpiredRows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	require.NoError(t, db.SetTableTTL(ctx, "", "events", "CREATED_AT", 24*time.Hour))
+	info, err := ds.GetTableInfo(ctx, "events")
+	require.NoError(t, err)
+	assert.Equal(t, "created_at", info.TTL.Column)
+
+	purged, err = db.PurgeExpiredRows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+	assert.Equal(t, []int64{2, 5, 7}, queryIDs(t, session, `SELECT id FROM events ORDER BY id`))
+
+	stats := db.TTLStats()
+	assert.Equal(t, int64(2), stats.Runs)
+	assert.Equal(t, int64(4), stats.RowsPurged)
+	assert.Equal(t, int64(4), stats.Tables["test.events"].RowsPurged)
+	assert.Empty(t, stats.Tables["test.events"].LastError)
+
+	assert.Equal(t, []string{"test.events"}, audit.tables)
+	assert.Equal(t, []int64{4}, audit.rows)
+
+	// 再次清理没有过期行
+	purged, err = db.PurgeExpiredRows(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	assert.Len(t, audit.tables, 1)
+
+	require.NoError(t, db.RemoveTableTTL(ctx, "test", "events"))
+	assert.Error(t, db.SetTableTTL(ctx, "test", "events", "created_at", 0))
+	assert.Error(t, db.SetTableTTL(ctx, "test", "missing", "created_at", time.Hour))
+}
+
+func TestTableTTL_BackgroundPurger(t *testing.T) {
+	db, _, session := newTTLTestDB(t, 0)
+
+	_, err := session.Execute(`CREATE TABLE logs (id INT PRIMARY KEY, created_at DATETIME) WITH TTL = '1h' ON COLUMN created_at`)
+	require.NoError(t, err)
+	_, err = session.Execute(`INSERT INTO logs VALUES (1, '2000-01-01 00:00:00'), (2, '2999-01-01 00:00:00')`)
+	require.NoError(t, err)
+
+	db.StartTTLPurger(10 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return db.TTLStats().RowsPurged == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	db.StopTTLPurger()
+
+	assert.Equal(t, []int64{2}, queryIDs(t, session, `SELECT id FROM logs`))
+}
+
+func TestTTLExpired(t *testing.T) {
+	cutoff := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{nil, false},
+		{"2024-05-31 23:59:59", true},
+		{"2024-06-01 00:00:01", false},
+		{"2024-05-01", true},
+		{"not a time", false},
+		{cutoff.Add(-time.Second), true},
+		{cutoff.Unix() - 1, true},
+		{cutoff.Unix() + 1, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ttlExpired(tt.value, cutoff), "value %v", tt.value)
+	}
+}
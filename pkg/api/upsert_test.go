@@ -0,0 +1,95 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpsert_OnDuplicateKeyUpdate 测试 ON DUPLICATE KEY UPDATE 逐行插入或更新并报告计数
+func TestUpsert_OnDuplicateKeyUpdate(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	result, err := s.Execute(`INSERT INTO users (id, name, city) VALUES (1, 'Alice', 'Lyon'), (4, 'Dave', 'Rome'), (3, 'Carol', 'Paris')
+		ON DUPLICATE KEY UPDATE city = VALUES(city)`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.RowsAffected) // 插入 1 行计 1，更新 1 行计 2，值不变计 0
+	assert.EqualValues(t, 4, result.LastInsertID)
+	assert.Equal(t, "Records: 3  Duplicates: 2  Warnings: 0  Inserted: 1  Updated: 1", result.Info)
+
+	rows, err := s.QueryAll(`SELECT city, id FROM users WHERE id = 1`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Lyon", rows[0]["city"])
+	assert.Equal(t, 4, countRows(t, s, `SELECT * FROM users`))
+}
+
+// TestUpsert_InsertIgnoreViaQuery 测试通过 Query 执行 INSERT IGNORE（MySQL 协议的 COM_QUERY 路径）
+func TestUpsert_InsertIgnoreViaQuery(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	q, err := s.Query(`INSERT IGNORE INTO users (id, name) VALUES (2, 'Bob'), (5, 'Eve')`)
+	require.NoError(t, err)
+	assert.Empty(t, q.Columns())
+	result := q.ExecResult()
+	require.NotNil(t, result)
+	assert.EqualValues(t, 1, result.RowsAffected)
+	assert.Equal(t, "Records: 2  Duplicates: 1  Warnings: 1  Inserted: 1  Updated: 0", result.Info)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "Duplicate entry '2'")
+
+	rows, err := s.QueryAll(`SELECT name, id FROM users WHERE id = 2`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "bob", rows[0]["name"])
+
+	// 没有冲突处理的重复插入整体失败
+	_, err = s.Execute(`INSERT INTO users (id, name) VALUES (6, 'Frank'), (1, 'Alice')`)
+	require.Error(t, err)
+	assert.Equal(t, 4, countRows(t, s, `SELECT * FROM users`))
+}
+
+// TestUpsert_PostgresOnConflict 测试 Postgres 方言的 ON CONFLICT ... DO UPDATE
+func TestUpsert_PostgresOnConflict(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	s.SetDialect(parser.DialectPostgres)
+
+	result, err := s.Execute(`INSERT INTO users (id, name, city) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET city = EXCLUDED.city`, 2, "bob", "Oslo")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, result.RowsAffected)
+
+	rows, err := s.QueryAll(`SELECT city, id FROM users WHERE id = 2`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Oslo", rows[0]["city"])
+}
+
+// TestUpsert_ExpressionOnExistingRow 测试 ON DUPLICATE KEY UPDATE 的赋值在已有行上求值，VALUES(col) 取待插入的值
+func TestUpsert_ExpressionOnExistingRow(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`CREATE TABLE counters (id INT PRIMARY KEY, hits INT)`)
+	require.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO counters (id, hits) VALUES (1, 1)`)
+	require.NoError(t, err)
+
+	result, err := s.Execute(`INSERT INTO counters (id, hits) VALUES (1, 1) ON DUPLICATE KEY UPDATE hits = hits + 10`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, result.RowsAffected)
+
+	result, err = s.Execute(`INSERT INTO counters (id, hits) VALUES (1, 5), (2, 5) ON DUPLICATE KEY UPDATE hits = hits + VALUES(hits)`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.RowsAffected)
+
+	rows, err := s.QueryAll(`SELECT id, hits FROM counters ORDER BY id`)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.EqualValues(t, 16, rows[0]["hits"])
+	assert.EqualValues(t, 5, rows[1]["hits"])
+
+	// 不能在行上求值的赋值报错，不会被忽略
+	_, err = s.Execute(`INSERT INTO counters (id, hits) VALUES (1, 1) ON DUPLICATE KEY UPDATE hits = (SELECT 1)`)
+	require.Error(t, err)
+}
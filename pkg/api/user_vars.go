@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// traceIDUserVar 设置后作为会话追踪ID的用户变量（SET @trace_id = '...'）
+const traceIDUserVar = "trace_id"
+
+// bindUserVariables 把语句中引用的用户变量替换为当前值，未设置的变量为 NULL
+func (s *Session) bindUserVariables(sql string) string {
+	if s.coreSession == nil {
+		return sql
+	}
+	return parser.BindUserVariables(sql, func(name string) string {
+		value, _ := s.coreSession.UserVar(name)
+		literal, err := paramToSQLLiteral(value)
+		if err != nil {
+			return "NULL"
+		}
+		return string(literal)
+	})
+}
+
+// executeSetUserVar 执行 SELECT ... INTO @var、SET (@a, @b) := (SELECT ...) 和 SET @var = expr：
+// 查询最多返回一行，各列依次赋给变量。多于一行时报错且变量保持不变；
+// 没有结果时 SET 形式把变量置为 NULL，SELECT ... INTO 保持原值并返回警告
+func (s *Session) executeSetUserVar(ctx context.Context, stmt *parser.UserVariablesStatement) (*Result, error) {
+	result, err := s.coreSession.ExecuteQuery(ctx, stmt.Query)
+	if err != nil {
+		if err.Error() == "query execution timed out" || err.Error() == "query was killed" {
+			return nil, WrapError(err, ErrCodeTimeout, "failed to execute query")
+		}
+		return nil, WrapError(err, ErrCodeSyntax, "failed to execute query")
+	}
+	if len(result.Columns) != len(stmt.Variables) {
+		return nil, mysqlerrors.New(mysqlerrors.ErrWrongNumberOfColumns,
+			"The used SELECT statements have a different number of columns")
+	}
+	if len(result.Rows) > 1 {
+		return nil, mysqlerrors.New(mysqlerrors.ErrTooManyRows, "Result consisted of more than one row")
+	}
+
+	// SELECT ... INTO 报告读取的行数，SET 与 MySQL 一致不影响行
+	affected := int64(len(result.Rows))
+	if stmt.NullIfEmpty {
+		affected = 0
+	}
+	res := NewResult(affected, 0, nil)
+	res.Warnings = result.Warnings
+	values := make(map[string]interface{}, len(stmt.Variables))
+	switch {
+	case len(result.Rows) == 1:
+		for i, col := range result.Columns {
+			values[stmt.Variables[i]] = result.Rows[0][col.Name]
+		}
+	case stmt.NullIfEmpty:
+		for _, name := range stmt.Variables {
+			values[name] = nil
+		}
+	default:
+		res.Warnings = append(res.Warnings, "No data - zero rows fetched, selected, or processed")
+		return res, nil
+	}
+	s.coreSession.SetUserVars(values)
+
+	if value, ok := values[traceIDUserVar]; ok {
+		traceID := ""
+		if value != nil {
+			traceID = strings.TrimSpace(fmt.Sprint(value))
+		}
+		s.SetTraceID(traceID)
+	}
+	return res, nil
+}
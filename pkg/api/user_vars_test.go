@@ -0,0 +1,109 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserVariables_SelectInto 测试 SELECT ... INTO @var 把单行结果保存到会话变量
+func TestUserVariables_SelectInto(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	res, err := s.Execute(`SELECT name, city INTO @name, @city FROM users WHERE id = 1 LIMIT 1`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.RowsAffected)
+	rows, err := s.QueryAll(`SELECT @name, @city`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Alice", rows[0]["@name"])
+	assert.Equal(t, "Paris", rows[0]["@city"])
+
+	// 变量可以用在后续语句的任意位置
+	rows, err = s.QueryAll(`SELECT name FROM users WHERE city = @city AND name <> @name`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Carol", rows[0]["name"])
+
+	// 多于一行时报错，变量保持不变
+	_, err = s.Execute(`SELECT name INTO @name FROM users`)
+	require.Error(t, err)
+	var coder mysqlerrors.Coder
+	require.ErrorAs(t, err, &coder)
+	assert.Equal(t, mysqlerrors.ErrTooManyRows, coder.MySQLErrorCode())
+	_, err = s.Execute(`SELECT id, name INTO @name FROM users WHERE id = 1`)
+	require.ErrorAs(t, err, &coder)
+	assert.Equal(t, mysqlerrors.ErrWrongNumberOfColumns, coder.MySQLErrorCode())
+
+	// 没有结果时变量保持不变并产生警告
+	res, err = s.Execute(`SELECT name INTO @name FROM users WHERE id = 99`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, res.RowsAffected)
+	assert.Contains(t, res.Warnings, "No data - zero rows fetched, selected, or processed")
+	assert.Equal(t, "Alice", queryUserVar(t, s, "name"))
+
+	// 通过 Query 执行（MySQL 协议的 COM_QUERY）也返回执行结果
+	q, err := s.Query(`SELECT id INTO @id FROM users WHERE name = 'bob'`)
+	require.NoError(t, err)
+	require.NotNil(t, q.ExecResult())
+	q.Close()
+	assert.EqualValues(t, 2, queryUserVar(t, s, "id"))
+}
+
+// TestUserVariables_Set 测试 SET (@a, @b) := (SELECT ...) 与 SET @var = expr
+func TestUserVariables_Set(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+
+	res, err := s.Execute(`SET (@id, @name) := (SELECT id, name FROM users WHERE city = 'Berlin')`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, res.RowsAffected)
+	assert.EqualValues(t, 2, queryUserVar(t, s, "id"))
+	assert.Equal(t, "bob", queryUserVar(t, s, "name"))
+
+	_, err = s.Execute(`SET @id = @id + 1, @label := CONCAT(@name, '!')`)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, queryUserVar(t, s, "id"))
+	assert.Equal(t, "bob!", queryUserVar(t, s, "label"))
+
+	_, err = s.Execute(`INSERT INTO users (name, city) VALUES (@label, 'Rome')`)
+	require.NoError(t, err)
+	rows, err := s.QueryAll(`SELECT city FROM users WHERE name = @label`)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Rome", rows[0]["city"])
+
+	// SET 形式的查询没有结果时变量为 NULL
+	_, err = s.Execute(`SET (@id) := (SELECT id FROM users WHERE id = 99)`)
+	require.NoError(t, err)
+	assert.Nil(t, queryUserVar(t, s, "id"))
+
+	// SET @trace_id 设置会话的追踪ID
+	_, err = s.Execute(`SET @trace_id = 'req-1'`)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", s.GetTraceID())
+}
+
+// TestUserVariables_SessionScope 测试用户变量只在本会话可见，重置会话时清空
+func TestUserVariables_SessionScope(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	_, err := s.Execute(`SET @x = 42`)
+	require.NoError(t, err)
+
+	other := s.db.Session()
+	defer other.Close()
+	assert.Nil(t, queryUserVar(t, other, "x"))
+	assert.EqualValues(t, 42, queryUserVar(t, s, "x"))
+
+	require.NoError(t, s.Reset())
+	assert.Nil(t, queryUserVar(t, s, "x"))
+}
+
+func queryUserVar(t *testing.T, s *Session, name string) interface{} {
+	t.Helper()
+	rows, err := s.QueryAll(`SELECT @` + name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	return rows[0]["@"+name]
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type virtualItem struct {
+	ID    int64   `db:"id,pk"`
+	Name  string  `db:"name"`
+	Price float64 `json:"price"`
+}
+
+func TestVirtualStructTable_SQL(t *testing.T) {
+	db, err := NewDB(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "default",
+		Writable: true,
+	})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+
+	items := []virtualItem{{1, "apple", 1.5}, {2, "pear", 2.5}, {3, "plum", 3.5}}
+	table, err := virtual.NewStructTable("items", &items)
+	require.NoError(t, err)
+	registry := virtual.NewVirtualDatabaseRegistry()
+	require.NoError(t, registry.RegisterTable("app", table))
+
+	sess := db.Session()
+	t.Cleanup(func() { sess.Close() })
+	sess.SetVirtualDBRegistry(registry)
+
+	_, rows := queryRows(t, sess, "SELECT name FROM app.items WHERE price > 2 ORDER BY id DESC")
+	assert.Equal(t, []interface{}{"plum", "pear"}, columnValues(rows, "name"))
+
+	// 通过切片指针注册的表可以看到后续追加的数据
+	items = append(items, virtualItem{4, "fig", 4.5})
+	_, rows = queryRows(t, sess, "SELECT id FROM app.items WHERE name = 'fig'")
+	assert.Equal(t, []interface{}{int64(4)}, columnValues(rows, "id"))
+}
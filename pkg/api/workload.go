@@ -0,0 +1,22 @@
+package api
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/workload"
+)
+
+// admitWorkload 按用户、语句类型和估算成本将语句归入工作负载类别并占用一个并发名额，
+// 返回的函数在语句执行完后释放名额；未启用工作负载管理时直接放行
+func (s *Session) admitWorkload(boundSQL string) (func(), error) {
+	manager := workload.GetManager()
+	if s.coreSession == nil || !manager.Enabled() {
+		return func() {}, nil
+	}
+	req := workload.Request{User: s.GetUser()}
+	if parseResult, err := s.coreSession.GetAdapter().Parse(boundSQL); err == nil && parseResult.Success {
+		req.StatementType = string(parseResult.Statement.Type)
+		req.Cost = workload.EstimateCost(parseResult.Statement)
+	}
+	return manager.Admit(context.Background(), req)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/workload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWorkload_AdmissionByClass 测试语句按类别占用并发名额，名额已满时被拒绝且不影响其他类别
+func TestWorkload_AdmissionByClass(t *testing.T) {
+	s := newTestSession(t, usersFixture...)
+	manager := workload.GetManager()
+	manager.Configure(workload.Config{
+		Enabled: true,
+		Classes: []workload.Class{{Name: "olap", MaxConcurrency: 1}},
+		Rules:   []workload.Rule{{Class: "olap", StatementTypes: []string{"SELECT"}, MinCost: 100}},
+	})
+	t.Cleanup(func() { manager.Configure(workload.Config{}) })
+
+	// 占满 olap 类别的名额
+	release, err := manager.Admit(context.Background(), workload.Request{StatementType: "SELECT", Cost: 100})
+	require.NoError(t, err)
+
+	_, err = s.QueryAll(`SELECT * FROM users WHERE city LIKE 'P%'`)
+	var busy *workload.BusyError
+	require.True(t, errors.As(err, &busy), "unexpected error: %v", err)
+	assert.Equal(t, "olap", busy.Class)
+
+	// 点查和写入属于默认类别，不受影响
+	rows, err := s.QueryAll(`SELECT * FROM users WHERE id = 1`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+	_, err = s.Execute(`UPDATE users SET city = 'Lyon' WHERE id = 2`)
+	require.NoError(t, err)
+
+	release()
+	rows, err = s.QueryAll(`SELECT * FROM users WHERE city LIKE 'P%'`)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	status, err := s.QueryAll(`SHOW STATUS LIKE 'Workload_olap_%'`)
+	require.NoError(t, err)
+	values := make(map[string]interface{}, len(status))
+	for _, row := range status {
+		values[row["Variable_name"].(string)] = row["Value"]
+	}
+	assert.Equal(t, "1", values["Workload_olap_rejected"])
+	assert.Equal(t, "2", values["Workload_olap_admitted"])
+	assert.Equal(t, "0", values["Workload_olap_running"])
+}
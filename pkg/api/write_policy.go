@@ -0,0 +1,238 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// writeGuard 全局只读开关（read_only）和按数据库覆盖的写入策略
+type writeGuard struct {
+	mu       sync.RWMutex
+	readOnly bool
+	policies map[string]domain.WritePolicy // 小写库名 -> 策略
+}
+
+func newWriteGuard(readOnly bool, policies map[string]domain.WritePolicy) *writeGuard {
+	g := &writeGuard{readOnly: readOnly, policies: make(map[string]domain.WritePolicy)}
+	for name, policy := range policies {
+		if policy != domain.WritePolicyDefault {
+			g.policies[strings.ToLower(name)] = policy
+		}
+	}
+	return g
+}
+
+// active 是否设置了只读或任一写入策略
+func (g *writeGuard) active() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.readOnly || len(g.policies) > 0
+}
+
+// SetReadOnly 设置全局只读（SET GLOBAL read_only），具有 SUPER 权限的会话不受限制
+func (db *DB) SetReadOnly(readOnly bool) {
+	db.writeGuard.mu.Lock()
+	defer db.writeGuard.mu.Unlock()
+	db.writeGuard.readOnly = readOnly
+}
+
+// IsReadOnly 返回是否处于全局只读
+func (db *DB) IsReadOnly() bool {
+	db.writeGuard.mu.RLock()
+	defer db.writeGuard.mu.RUnlock()
+	return db.writeGuard.readOnly
+}
+
+// SetWritePolicy 设置数据库（数据源或虚拟数据库）的写入策略，覆盖数据源自身的 IsWritable
+// 和虚拟数据库注册时的策略；policy 为 domain.WritePolicyDefault 时取消覆盖
+func (db *DB) SetWritePolicy(database string, policy domain.WritePolicy) error {
+	if _, err := domain.ParseWritePolicy(string(policy)); err != nil {
+		return NewError(ErrCodeInvalidParam, err.Error(), nil)
+	}
+	db.writeGuard.mu.Lock()
+	defer db.writeGuard.mu.Unlock()
+	if policy == domain.WritePolicyDefault {
+		delete(db.writeGuard.policies, strings.ToLower(database))
+	} else {
+		db.writeGuard.policies[strings.ToLower(database)] = policy
+	}
+	return nil
+}
+
+// WritePolicy 返回为数据库设置的写入策略，未设置时返回 domain.WritePolicyDefault
+func (db *DB) WritePolicy(database string) domain.WritePolicy {
+	db.writeGuard.mu.RLock()
+	defer db.writeGuard.mu.RUnlock()
+	return db.writeGuard.policies[strings.ToLower(database)]
+}
+
+// SetSuperPrivilege 设置会话是否具有 SUPER 权限，具有时不受 read_only 和写入策略限制
+func (s *Session) SetSuperPrivilege(granted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.super = granted
+}
+
+// SetReadOnly 设置会话只读，只读会话拒绝所有 DML 和 DDL，SUPER 权限也不例外
+func (s *Session) SetReadOnly(readOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readOnly = readOnly
+}
+
+// IsReadOnly 返回会话是否只读
+func (s *Session) IsReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.readOnly
+}
+
+// writeChecksEnabled 是否需要检查写入语句：会话只读或绑定了分叉，或设置了只读、写入策略或虚拟数据库的写入策略
+func (s *Session) writeChecksEnabled() bool {
+	if s.IsReadOnly() || s.Fork() != nil {
+		return true
+	}
+	if s.db == nil || s.db.writeGuard == nil {
+		return false
+	}
+	if s.db.writeGuard.active() {
+		return true
+	}
+	if registry := s.coreSession.GetVirtualDBRegistry(); registry != nil {
+		for _, entry := range registry.List() {
+			if entry.WritePolicy != domain.WritePolicyDefault {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkWritePolicy 在分发前检查写入语句是否被全局只读或目标数据库的写入策略禁止
+func (s *Session) checkWritePolicy(stmt *parser.SQLStatement) error {
+	if stmt == nil || !s.writeChecksEnabled() {
+		return nil
+	}
+	database, ddl, ok := writeTarget(stmt)
+	if !ok {
+		return nil
+	}
+	s.mu.RLock()
+	super, readOnly, fork := s.super, s.readOnly, s.fork
+	s.mu.RUnlock()
+	if readOnly {
+		return NewError(ErrCodeReadOnly, "Cannot execute statement in a read-only session", nil)
+	}
+	if fork != nil {
+		// 分叉表的写入只修改副本，不受全局只读和写入策略限制，合并时再检查
+		return fork.checkWrite(stmt)
+	}
+	if super {
+		return nil
+	}
+
+	if s.db.IsReadOnly() {
+		return NewError(ErrCodeReadOnly,
+			"The MySQL server is running with the --read-only option so it cannot execute this statement", nil)
+	}
+	if database == "" {
+		database = s.coreSession.GetCurrentDB()
+	}
+	policy := s.effectiveWritePolicy(database)
+	if ddl && !policy.AllowsDDL() {
+		return NewError(ErrCodeReadOnly,
+			fmt.Sprintf("Database '%s' is read-only so it cannot execute this statement", database), nil)
+	}
+	if !ddl && !policy.AllowsDML() {
+		return NewError(ErrCodeReadOnly,
+			fmt.Sprintf("Database '%s' only accepts DDL (write policy %s) so it cannot execute this statement", database, policy), nil)
+	}
+	return nil
+}
+
+// effectiveWritePolicy 返回数据库生效的写入策略：DB 上的覆盖优先，其次是虚拟数据库注册时的策略
+func (s *Session) effectiveWritePolicy(database string) domain.WritePolicy {
+	if policy := s.db.WritePolicy(database); policy != domain.WritePolicyDefault {
+		return policy
+	}
+	if registry := s.coreSession.GetVirtualDBRegistry(); registry != nil {
+		if entry, ok := registry.Get(database); ok {
+			return entry.WritePolicy
+		}
+	}
+	return domain.WritePolicyDefault
+}
+
+// writeTarget 返回写入语句的目标数据库（空串表示当前数据库）以及是否为 DDL，非写入语句 ok 为 false。
+// 解析器只为 DML 和 CREATE TABLE 保留库名，其余 DDL 作用于当前数据库
+func writeTarget(stmt *parser.SQLStatement) (database string, ddl bool, ok bool) {
+	switch stmt.Type {
+	case parser.SQLTypeInsert:
+		if stmt.Insert != nil {
+			database = stmt.Insert.Database
+		}
+		return database, false, true
+	case parser.SQLTypeUpdate:
+		if stmt.Update != nil {
+			database = stmt.Update.Database
+		}
+		return database, false, true
+	case parser.SQLTypeDelete:
+		if stmt.Delete != nil {
+			database = stmt.Delete.Database
+		}
+		return database, false, true
+	case parser.SQLTypeCreate:
+		if stmt.Create != nil {
+			database = stmt.Create.Database
+		}
+		return database, true, true
+	case parser.SQLTypeGenerate, parser.SQLTypeImportData:
+		// 两者都可能创建表，按 DDL 处理
+		table := ""
+		if stmt.GenerateTable != nil {
+			table = stmt.GenerateTable.Table
+		} else if stmt.ImportData != nil {
+			table = stmt.ImportData.Table
+		}
+		if dot := strings.IndexByte(table, '.'); dot > 0 {
+			database = table[:dot]
+		}
+		return database, true, true
+	case parser.SQLTypeFlashback:
+		if stmt.Flashback != nil {
+			database, _ = splitTableName(stmt.Flashback.Table)
+		}
+		return database, false, true
+	case parser.SQLTypeUndrop:
+		if stmt.Undrop != nil {
+			database, _ = splitTableName(stmt.Undrop.Table)
+		}
+		return database, true, true
+	case parser.SQLTypeShadow:
+		// 影子迁移改变表的路由，按 DDL 处理
+		if stmt.Shadow != nil {
+			database, _ = splitTableName(stmt.Shadow.Table)
+		}
+		return database, true, true
+	case parser.SQLTypeCreateSeq, parser.SQLTypeAlterSeq, parser.SQLTypeDropSeq:
+		if stmt.Sequence != nil && len(stmt.Sequence.Names) > 0 {
+			database, _ = splitTableName(stmt.Sequence.Names[0])
+		}
+		return database, true, true
+	case parser.SQLTypeRestore:
+		// 恢复会创建表，多个数据库时按当前数据库检查，各数据源导入时再检查自身是否可写
+		if stmt.Restore != nil && len(stmt.Restore.Databases) == 1 {
+			database = stmt.Restore.Databases[0]
+		}
+		return database, true, true
+	case parser.SQLTypeCreateView, parser.SQLTypeDrop, parser.SQLTypeDropView,
+		parser.SQLTypeAlter, parser.SQLTypeTruncate, parser.SQLTypeOptimize, parser.SQLTypeImport:
+		return "", true, true
+	}
+	return "", false, false
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertReadOnlyError(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	assert.True(t, IsErrorCode(err, ErrCodeReadOnly), "unexpected error: %v", err)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrReadOnly, code)
+}
+
+// TestWritePolicy_GlobalReadOnly 测试全局只读禁止 DML 和 DDL，读取不受影响
+func TestWritePolicy_GlobalReadOnly(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	db.SetReadOnly(true)
+	assert.True(t, db.IsReadOnly())
+
+	_, err := s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	assertReadOnlyError(t, err)
+	_, err = s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1)`)
+	assertReadOnlyError(t, err)
+	_, err = s.Execute(`CREATE TABLE t2 (id INT PRIMARY KEY)`)
+	assertReadOnlyError(t, err)
+	_, err = s.Query(`DROP TABLE accounts`)
+	assertReadOnlyError(t, err)
+
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`DELETE FROM accounts WHERE id = 1`)
+	assertReadOnlyError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+
+	db.SetReadOnly(false)
+	_, err = s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	assert.NoError(t, err)
+}
+
+// TestWritePolicy_SuperBypass 测试具有 SUPER 权限的会话不受只读和写入策略限制
+func TestWritePolicy_SuperBypass(t *testing.T) {
+	db := newXATestDB(t)
+	db.SetReadOnly(true)
+	require.NoError(t, db.SetWritePolicy("ledger", domain.WritePolicyReadOnly))
+
+	s := db.Session()
+	defer s.Close()
+	s.SetSuperPrivilege(true)
+
+	_, err := s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	assert.NoError(t, err)
+	_, err = s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1)`)
+	assert.NoError(t, err)
+}
+
+// TestWritePolicy_SessionReadOnly 测试只读会话拒绝写入，SUPER 权限也不例外，其他会话不受影响
+func TestWritePolicy_SessionReadOnly(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+	s.SetReadOnly(true)
+	s.SetSuperPrivilege(true)
+	assert.True(t, s.IsReadOnly())
+
+	_, err := s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	assertReadOnlyError(t, err)
+	_, err = s.Query(`DROP TABLE accounts`)
+	assertReadOnlyError(t, err)
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+
+	other := db.Session()
+	defer other.Close()
+	_, err = other.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	assert.NoError(t, err)
+}
+
+// TestWritePolicy_PerDatabase 测试按数据库设置的 read_only 和 ddl_only 策略
+func TestWritePolicy_PerDatabase(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	require.NoError(t, db.SetWritePolicy("ledger", domain.WritePolicyDDLOnly))
+	assert.Equal(t, domain.WritePolicyDDLOnly, db.WritePolicy("LEDGER"))
+
+	// 其他数据库不受影响
+	_, err := s.Execute(`UPDATE accounts SET balance = 0 WHERE id = 1`)
+	assert.NoError(t, err)
+
+	// ddl_only：禁止 DML，允许 DDL
+	_, err = s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1)`)
+	assertReadOnlyError(t, err)
+	require.NoError(t, s.UseDatabase("ledger"))
+	_, err = s.Execute(`DELETE FROM entries`)
+	assertReadOnlyError(t, err)
+	_, err = s.Execute(`CREATE TABLE audit (id INT PRIMARY KEY)`)
+	assert.NoError(t, err)
+
+	// read_only：DDL 也被禁止
+	require.NoError(t, db.SetWritePolicy("ledger", domain.WritePolicyReadOnly))
+	_, err = s.Execute(`DROP TABLE audit`)
+	assertReadOnlyError(t, err)
+
+	// 取消覆盖后恢复可写
+	require.NoError(t, db.SetWritePolicy("ledger", domain.WritePolicyDefault))
+	_, err = s.Execute(`INSERT INTO entries (id, amount) VALUES (1, 1)`)
+	assert.NoError(t, err)
+
+	assert.Error(t, db.SetWritePolicy("ledger", "append_only"))
+}
+
+// TestWritePolicy_VirtualDatabase 测试虚拟数据库注册时的写入策略，DB 上的设置可以覆盖它
+func TestWritePolicy_VirtualDatabase(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	registry := virtual.NewVirtualDatabaseRegistry()
+	registry.Register(&virtual.VirtualDatabaseEntry{
+		Name:        "ledger",
+		Provider:    virtual.NewTableProvider(),
+		Writable:    true,
+		WritePolicy: domain.WritePolicyReadOnly,
+	})
+	s.SetVirtualDBRegistry(registry)
+
+	_, err := s.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1)`)
+	assertReadOnlyError(t, err)
+
+	parsed, err := s.coreSession.GetAdapter().Parse(`INSERT INTO ledger.entries (id, amount) VALUES (1, 1)`)
+	require.NoError(t, err)
+	require.NoError(t, db.SetWritePolicy("ledger", domain.WritePolicyFull))
+	assert.NoError(t, s.checkWritePolicy(parsed.Statement))
+}
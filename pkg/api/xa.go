@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// RecoverTransactions 完成跨数据源事务中处于不确定状态的分支
+// 恢复日志中已决定提交的分支被提交，其余已准备的分支被回滚；
+// 数据源注册时会自动恢复该数据源，提交失败（ErrXAInDoubt）后可手动调用
+func (db *DB) RecoverTransactions(ctx context.Context) (*application.XARecoveryResult, error) {
+	result, err := db.xa.Recover(ctx)
+	if err != nil {
+		return result, WrapError(err, ErrCodeTransaction, "transaction recovery failed")
+	}
+	return result, nil
+}
+
+// recoverDataSource 恢复新注册数据源上不确定的分支
+func (db *DB) recoverDataSource(name string, ds domain.DataSource) {
+	if _, ok := ds.(domain.XADataSource); !ok {
+		return
+	}
+	result, err := db.xa.RecoverDataSource(context.Background(), name)
+	if err != nil {
+		db.logger.Warn("XA recovery on datasource '%s' failed: %v", name, err)
+		return
+	}
+	if n := len(result.Committed) + len(result.RolledBack); n > 0 {
+		db.logger.Info("XA recovery on datasource '%s': %d committed, %d rolled back",
+			name, len(result.Committed), len(result.RolledBack))
+	}
+}
+
+// dataSourceName 返回数据源的注册名称
+func (db *DB) dataSourceName(ds domain.DataSource) string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for name, registered := range db.dataSources {
+		if registered == ds {
+			return name
+		}
+	}
+	return db.defaultDS
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newXATestDB 注册两个内存数据源：默认的 test（accounts 表）与 ledger（entries 表）
+func newXATestDB(t *testing.T) *DB {
+	db := newTestDB(t, nil, accountsFixture...)
+	ledger := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ledger.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("ledger", ledger))
+
+	s := db.SessionWithOptions(&SessionOptions{DataSourceName: "ledger"})
+	defer s.Close()
+	_, err := s.Execute(`CREATE TABLE entries (id INT PRIMARY KEY, amount INT)`)
+	require.NoError(t, err)
+	return db
+}
+
+func countRows(t *testing.T, s *Session, sql string) int {
+	q, err := s.Query(sql)
+	require.NoError(t, err)
+	defer q.Close()
+	n := 0
+	for q.Next() {
+		n++
+	}
+	return n
+}
+
+// TestXA_CrossDataSourceCommit 测试跨数据源事务通过两阶段提交同时生效
+func TestXA_CrossDataSourceCommit(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`UPDATE accounts SET balance = 50 WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = tx.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, -50)`)
+	require.NoError(t, err)
+
+	// 分支内读到自己的修改
+	q, err := tx.Query(`SELECT * FROM ledger.entries`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, q.RowsCount())
+	q.Close()
+
+	require.NoError(t, tx.Commit())
+
+	ls := db.SessionWithOptions(&SessionOptions{DataSourceName: "ledger"})
+	defer ls.Close()
+	assert.Equal(t, 1, countRows(t, ls, `SELECT * FROM entries`))
+	assert.Equal(t, 1, countRows(t, s, `SELECT * FROM accounts WHERE balance = 50`))
+
+	pending, err := db.xa.Log().Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// TestXA_CrossDataSourceRollback 测试回滚撤销所有数据源上的修改
+func TestXA_CrossDataSourceRollback(t *testing.T) {
+	db := newXATestDB(t)
+	s := db.Session()
+	defer s.Close()
+
+	tx, err := s.Begin()
+	require.NoError(t, err)
+	_, err = tx.Execute(`DELETE FROM accounts WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = tx.Execute(`INSERT INTO ledger.entries (id, amount) VALUES (1, -100)`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	ls := db.SessionWithOptions(&SessionOptions{DataSourceName: "ledger"})
+	defer ls.Close()
+	assert.Equal(t, 0, countRows(t, ls, `SELECT * FROM entries`))
+	assert.Equal(t, 2, countRows(t, s, `SELECT * FROM accounts`))
+}
+
+// TestXA_RecoverTransactions 测试恢复回滚没有提交决定的已准备分支
+func TestXA_RecoverTransactions(t *testing.T) {
+	db := newXATestDB(t)
+	ds, err := db.GetDataSource("ledger")
+	require.NoError(t, err)
+	ledger := ds.(*memory.MVCCDataSource)
+
+	ctx := context.Background()
+	tx, err := ledger.BeginTransaction(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.(*memory.MVCCTransaction).Prepare(ctx, "sqlexec-xa-lost.1"))
+
+	result, err := db.RecoverTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sqlexec-xa-lost.1"}, result.RolledBack)
+
+	xids, err := ledger.RecoverXA(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, xids)
+}
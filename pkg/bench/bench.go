@@ -0,0 +1,276 @@
+// Package bench 实现压测与负载生成：在服务器或进程内数据库上运行点查、范围扫描、
+// JOIN、插入与读写混合负载，统计吞吐量和延迟分位数，用于对比不同版本的性能
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/cli"
+)
+
+// Config 压测配置
+type Config struct {
+	Workloads   []string      // 依次运行的负载
+	Concurrency int           // 并发会话数
+	Duration    time.Duration // 每个负载的运行时长
+	Requests    int           // 每个负载的总请求数，大于 0 时忽略 Duration
+	Warmup      time.Duration // 计时前的预热时长
+	TableSize   int           // bench_items 初始行数
+	RangeSize   int           // 范围扫描的行数
+	Seed        int64         // 随机种子，相同种子生成相同的语句序列
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() Config {
+	return Config{
+		Workloads:   []string{"point-select"},
+		Concurrency: 4,
+		Duration:    10 * time.Second,
+		TableSize:   10000,
+		RangeSize:   100,
+		Seed:        1,
+	}
+}
+
+// Validate 检查配置
+func (c *Config) Validate() error {
+	if len(c.Workloads) == 0 {
+		return errors.New("no workload specified")
+	}
+	for _, name := range c.Workloads {
+		if _, ok := workloads[name]; !ok {
+			return fmt.Errorf("unknown workload %q (%s)", name, strings.Join(WorkloadNames(), ", "))
+		}
+	}
+	if c.Concurrency <= 0 {
+		return errors.New("concurrency must be positive")
+	}
+	if c.Requests <= 0 && c.Duration <= 0 {
+		return errors.New("either duration or requests must be positive")
+	}
+	if c.TableSize <= 0 {
+		return errors.New("table size must be positive")
+	}
+	if c.RangeSize <= 0 {
+		return errors.New("range size must be positive")
+	}
+	return nil
+}
+
+// Report 一个负载的压测结果
+type Report struct {
+	Workload    string        `json:"workload"`
+	Concurrency int           `json:"concurrency"`
+	Ops         int64         `json:"ops"`
+	Errors      int64         `json:"errors"`
+	Elapsed     time.Duration `json:"elapsed_ns"`
+	Throughput  float64       `json:"ops_per_sec"`
+	Min         time.Duration `json:"min_ns"`
+	Avg         time.Duration `json:"avg_ns"`
+	P50         time.Duration `json:"p50_ns"`
+	P90         time.Duration `json:"p90_ns"`
+	P95         time.Duration `json:"p95_ns"`
+	P99         time.Duration `json:"p99_ns"`
+	Max         time.Duration `json:"max_ns"`
+	FirstError  string        `json:"first_error,omitempty"`
+}
+
+// Prepare 重建压测表并写入 TableSize 行数据
+func Prepare(ctx context.Context, conn cli.Conn, cfg Config) error {
+	if err := Cleanup(ctx, conn); err != nil {
+		return err
+	}
+	ddl := []string{
+		fmt.Sprintf("CREATE TABLE %s (id INT PRIMARY KEY, name VARCHAR(32))", groupsTable),
+		fmt.Sprintf("CREATE TABLE %s (id INT PRIMARY KEY, k INT, c VARCHAR(64), pad VARCHAR(64))", itemsTable),
+	}
+	for _, sql := range ddl {
+		if _, err := conn.Exec(ctx, sql); err != nil {
+			return err
+		}
+	}
+
+	g := &generator{rnd: rand.New(rand.NewSource(cfg.Seed)), tableSize: cfg.TableSize}
+	if err := insertBatches(ctx, conn, groupsTable, "(id, name)", g.groupCount(), func(id int) string {
+		return fmt.Sprintf("(%d, 'group-%d')", id, id)
+	}); err != nil {
+		return err
+	}
+	return insertBatches(ctx, conn, itemsTable, "(id, k, c, pad)", cfg.TableSize, func(id int) string {
+		return fmt.Sprintf("(%d, %d, '%s', '%s')", id, g.rnd.Intn(g.groupCount())+1, randomText(g.rnd, 32), randomText(g.rnd, 16))
+	})
+}
+
+// insertBatches 以每条语句 500 行的批量 INSERT 写入 1..n 行
+func insertBatches(ctx context.Context, conn cli.Conn, table, columns string, n int, row func(id int) string) error {
+	const batchSize = 500
+	for start := 1; start <= n; start += batchSize {
+		var b strings.Builder
+		fmt.Fprintf(&b, "INSERT INTO %s %s VALUES ", table, columns)
+		for id := start; id < start+batchSize && id <= n; id++ {
+			if id > start {
+				b.WriteString(", ")
+			}
+			b.WriteString(row(id))
+		}
+		if _, err := conn.Exec(ctx, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup 删除压测表
+func Cleanup(ctx context.Context, conn cli.Conn) error {
+	for _, table := range []string{itemsTable, groupsTable} {
+		if _, err := conn.Exec(ctx, "DROP TABLE IF EXISTS "+table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run 依次运行配置中的负载；每个负载使用 Concurrency 个由 conn.Clone 打开的会话
+func Run(ctx context.Context, conn cli.Conn, cfg Config) ([]Report, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	sessions := make([]cli.Conn, 0, cfg.Concurrency)
+	defer func() {
+		for _, s := range sessions {
+			s.Close()
+		}
+	}()
+	for i := 0; i < cfg.Concurrency; i++ {
+		s, err := conn.Clone(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	// 插入从现有最大 id 之后开始，前一个负载插入的行不会冲突
+	nextID := int64(cfg.TableSize)
+	reports := make([]Report, 0, len(cfg.Workloads))
+	for i, name := range cfg.Workloads {
+		if cfg.Warmup > 0 {
+			warmup := cfg
+			warmup.Duration, warmup.Requests = cfg.Warmup, 0
+			runWorkload(ctx, sessions, name, warmup, int64(i)*1000+999, &nextID)
+		}
+		report := runWorkload(ctx, sessions, name, cfg, int64(i)*1000, &nextID)
+		reports = append(reports, report)
+		if ctx.Err() != nil {
+			return reports, ctx.Err()
+		}
+	}
+	return reports, nil
+}
+
+// workerResult 一个会话的统计
+type workerResult struct {
+	latencies []time.Duration
+	errors    int64
+	firstErr  error
+}
+
+func runWorkload(ctx context.Context, sessions []cli.Conn, name string, cfg Config, seedOffset int64, nextID *int64) Report {
+	ops := workloads[name]
+	runCtx := ctx
+	if cfg.Requests <= 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+	remaining := int64(cfg.Requests)
+
+	results := make([]workerResult, len(sessions))
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, s := range sessions {
+		wg.Add(1)
+		go func(i int, s cli.Conn) {
+			defer wg.Done()
+			g := &generator{
+				rnd:       rand.New(rand.NewSource(cfg.Seed + seedOffset + int64(i))),
+				tableSize: cfg.TableSize,
+				rangeSize: cfg.RangeSize,
+				nextID:    nextID,
+			}
+			res := &results[i]
+			for runCtx.Err() == nil {
+				if cfg.Requests > 0 && atomic.AddInt64(&remaining, -1) < 0 {
+					return
+				}
+				sql := pick(ops, g.rnd)(g)
+				begin := time.Now()
+				_, err := s.Exec(runCtx, sql)
+				elapsed := time.Since(begin)
+				if err != nil {
+					if runCtx.Err() != nil {
+						// 运行时长到期时被取消的语句不计入统计
+						return
+					}
+					res.errors++
+					if res.firstErr == nil {
+						res.firstErr = err
+					}
+					continue
+				}
+				res.latencies = append(res.latencies, elapsed)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	return summarize(name, len(sessions), time.Since(start), results)
+}
+
+// summarize 汇总各会话的延迟，分位数取最近秩
+func summarize(name string, concurrency int, elapsed time.Duration, results []workerResult) Report {
+	report := Report{Workload: name, Concurrency: concurrency, Elapsed: elapsed}
+	var all []time.Duration
+	for _, r := range results {
+		all = append(all, r.latencies...)
+		report.Errors += r.errors
+		if r.firstErr != nil && report.FirstError == "" {
+			report.FirstError = r.firstErr.Error()
+		}
+	}
+	report.Ops = int64(len(all))
+	if elapsed > 0 {
+		report.Throughput = float64(report.Ops) / elapsed.Seconds()
+	}
+	if len(all) == 0 {
+		return report
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	var total time.Duration
+	for _, d := range all {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		idx := int(math.Ceil(p*float64(len(all)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return all[idx]
+	}
+	report.Min = all[0]
+	report.Max = all[len(all)-1]
+	report.Avg = total / time.Duration(len(all))
+	report.P50 = percentile(0.50)
+	report.P90 = percentile(0.90)
+	report.P95 = percentile(0.95)
+	report.P99 = percentile(0.99)
+	return report
+}
@@ -0,0 +1,106 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.Workloads = []string{"point-select", "nope"}
+	assert.Error(t, cfg.Validate())
+
+	cfg = DefaultConfig()
+	cfg.Concurrency = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg = DefaultConfig()
+	cfg.Duration, cfg.Requests = 0, 0
+	assert.Error(t, cfg.Validate())
+}
+
+func TestSummarize(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	results := []workerResult{
+		{latencies: latencies[:50]},
+		{latencies: latencies[50:], errors: 2},
+	}
+
+	r := summarize("point-select", 2, 2*time.Second, results)
+	assert.Equal(t, int64(100), r.Ops)
+	assert.Equal(t, int64(2), r.Errors)
+	assert.Equal(t, 50.0, r.Throughput)
+	assert.Equal(t, time.Millisecond, r.Min)
+	assert.Equal(t, 100*time.Millisecond, r.Max)
+	assert.Equal(t, 50*time.Millisecond+500*time.Microsecond, r.Avg)
+	assert.Equal(t, 50*time.Millisecond, r.P50)
+	assert.Equal(t, 90*time.Millisecond, r.P90)
+	assert.Equal(t, 95*time.Millisecond, r.P95)
+	assert.Equal(t, 99*time.Millisecond, r.P99)
+
+	empty := summarize("insert", 1, time.Second, []workerResult{{}})
+	assert.Zero(t, empty.Ops)
+	assert.Zero(t, empty.P99)
+}
+
+func TestRun_Local(t *testing.T) {
+	conn, err := cli.OpenLocal("")
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx := context.Background()
+
+	cfg := DefaultConfig()
+	cfg.Workloads = WorkloadNames()
+	cfg.Concurrency = 2
+	cfg.Requests = 40
+	cfg.TableSize = 300
+	cfg.RangeSize = 10
+	require.NoError(t, Prepare(ctx, conn, cfg))
+
+	reports, err := Run(ctx, conn, cfg)
+	require.NoError(t, err)
+	require.Len(t, reports, len(cfg.Workloads))
+	for _, r := range reports {
+		assert.Equal(t, int64(40), r.Ops, r.Workload)
+		assert.Zero(t, r.Errors, "%s: %s", r.Workload, r.FirstError)
+		assert.Positive(t, r.P50, r.Workload)
+	}
+
+	// 插入负载写入的行在原有数据之后
+	result, err := conn.Exec(ctx, "SELECT COUNT(*) AS n FROM bench_items WHERE id > 300")
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.NotEqual(t, "0", fmt.Sprint(result.Rows[0][0]))
+
+	require.NoError(t, Cleanup(ctx, conn))
+	_, err = conn.Exec(ctx, "SELECT * FROM bench_items")
+	assert.Error(t, err)
+}
+
+func TestWriteReports(t *testing.T) {
+	reports := []Report{{Workload: "point-select", Concurrency: 4, Ops: 10, Throughput: 5, P99: 1500 * time.Microsecond}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReports(&buf, cli.FormatCSV, reports))
+	assert.Equal(t, "workload,threads,ops,errors,ops/s,min_ms,avg_ms,p50_ms,p90_ms,p95_ms,p99_ms,max_ms\n"+
+		"point-select,4,10,0,5.0,0.000,0.000,0.000,0.000,0.000,1.500,0.000\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteReports(&buf, cli.FormatJSON, reports))
+	var decoded []Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, reports, decoded)
+}
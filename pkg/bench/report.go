@@ -0,0 +1,53 @@
+package bench
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/cli"
+)
+
+// WriteReports 输出压测结果；json 格式输出完整的 Report 便于保存和比较，
+// 其他格式输出以毫秒为单位的汇总表
+func WriteReports(w io.Writer, format cli.Format, reports []Report) error {
+	if format == cli.FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	}
+
+	result := &cli.Result{
+		Columns: []string{"workload", "threads", "ops", "errors", "ops/s",
+			"min_ms", "avg_ms", "p50_ms", "p90_ms", "p95_ms", "p99_ms", "max_ms"},
+		HasRows: true,
+	}
+	for _, r := range reports {
+		result.Rows = append(result.Rows, []interface{}{
+			r.Workload, r.Concurrency, r.Ops, r.Errors, formatFloat(r.Throughput, 1),
+			millis(r.Min), millis(r.Avg), millis(r.P50), millis(r.P90), millis(r.P95), millis(r.P99), millis(r.Max),
+		})
+	}
+	if err := cli.WriteResult(w, format, result); err != nil {
+		return err
+	}
+	if format == cli.FormatTable {
+		for _, r := range reports {
+			if r.FirstError != "" {
+				if _, err := io.WriteString(w, r.Workload+": first error: "+r.FirstError+"\n"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func millis(d time.Duration) string {
+	return formatFloat(float64(d)/float64(time.Millisecond), 3)
+}
+
+func formatFloat(v float64, prec int) string {
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
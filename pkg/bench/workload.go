@@ -0,0 +1,125 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// 压测使用的表：bench_items 为主表，k 列引用 bench_groups.id，供 JOIN 负载使用
+const (
+	itemsTable  = "bench_items"
+	groupsTable = "bench_groups"
+)
+
+// rowsPerGroup 每个分组对应的 bench_items 行数
+const rowsPerGroup = 100
+
+// generator 一个 worker 的语句生成状态
+type generator struct {
+	rnd       *rand.Rand
+	tableSize int
+	rangeSize int
+	nextID    *int64 // 插入使用的下一个 id，所有 worker 共享
+}
+
+func (g *generator) randomID() int {
+	return g.rnd.Intn(g.tableSize) + 1
+}
+
+func (g *generator) randomRangeStart(size int) int {
+	if g.tableSize <= size {
+		return 1
+	}
+	return g.rnd.Intn(g.tableSize-size+1) + 1
+}
+
+func (g *generator) groupCount() int {
+	return g.tableSize/rowsPerGroup + 1
+}
+
+// op 生成一条语句
+type op func(g *generator) string
+
+func pointSelect(g *generator) string {
+	return fmt.Sprintf("SELECT c FROM %s WHERE id = %d", itemsTable, g.randomID())
+}
+
+func rangeScan(g *generator) string {
+	start := g.randomRangeStart(g.rangeSize)
+	return fmt.Sprintf("SELECT id, k, c FROM %s WHERE id BETWEEN %d AND %d", itemsTable, start, start+g.rangeSize-1)
+}
+
+func joinSelect(g *generator) string {
+	start := g.randomRangeStart(10)
+	return fmt.Sprintf("SELECT i.id, g.name FROM %s i JOIN %s g ON i.k = g.id WHERE i.id BETWEEN %d AND %d",
+		itemsTable, groupsTable, start, start+9)
+}
+
+func insertRow(g *generator) string {
+	id := atomic.AddInt64(g.nextID, 1)
+	return fmt.Sprintf("INSERT INTO %s (id, k, c, pad) VALUES (%d, %d, '%s', '%s')",
+		itemsTable, id, g.rnd.Intn(g.groupCount())+1, randomText(g.rnd, 32), randomText(g.rnd, 16))
+}
+
+func updateRow(g *generator) string {
+	return fmt.Sprintf("UPDATE %s SET k = %d WHERE id = %d", itemsTable, g.rnd.Intn(g.groupCount())+1, g.randomID())
+}
+
+// weightedOp 按权重选择的语句
+type weightedOp struct {
+	weight int
+	op     op
+}
+
+// workloads 内置负载
+var workloads = map[string][]weightedOp{
+	"point-select": {{1, pointSelect}},
+	"range-scan":   {{1, rangeScan}},
+	"join":         {{1, joinSelect}},
+	"insert":       {{1, insertRow}},
+	// mix 读写混合：60% 点查、20% 范围扫描、10% 插入、10% 更新
+	"mix": {{60, pointSelect}, {20, rangeScan}, {10, insertRow}, {10, updateRow}},
+}
+
+// WorkloadNames 返回内置负载名称
+func WorkloadNames() []string {
+	names := make([]string, 0, len(workloads))
+	for name := range workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pick 按权重选择一条语句
+func pick(ops []weightedOp, rnd *rand.Rand) op {
+	if len(ops) == 1 {
+		return ops[0].op
+	}
+	total := 0
+	for _, o := range ops {
+		total += o.weight
+	}
+	n := rnd.Intn(total)
+	for _, o := range ops {
+		if n < o.weight {
+			return o.op
+		}
+		n -= o.weight
+	}
+	return ops[len(ops)-1].op
+}
+
+const textChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+func randomText(rnd *rand.Rand, n int) string {
+	var b strings.Builder
+	b.Grow(n)
+	for i := 0; i < n; i++ {
+		b.WriteByte(textChars[rnd.Intn(len(textChars))])
+	}
+	return b.String()
+}
@@ -10,11 +10,10 @@ import (
 // advancedAggState holds additional state for advanced aggregate functions.
 // It is stored in the AggregateContext.AllValues field as the first element.
 type advancedAggState struct {
-	pairsX   []float64
-	pairsY   []float64
-	values   []float64
-	freqMap  map[string]int
-	distinct map[string]struct{}
+	pairsX  []float64
+	pairsY  []float64
+	values  []float64
+	freqMap map[string]int
 }
 
 // getAdvState retrieves or creates the advancedAggState from the context.
@@ -25,11 +24,10 @@ func getAdvState(ctx *AggregateContext) *advancedAggState {
 		}
 	}
 	st := &advancedAggState{
-		pairsX:   make([]float64, 0),
-		pairsY:   make([]float64, 0),
-		values:   make([]float64, 0),
-		freqMap:  make(map[string]int),
-		distinct: make(map[string]struct{}),
+		pairsX:  make([]float64, 0),
+		pairsY:  make([]float64, 0),
+		values:  make([]float64, 0),
+		freqMap: make(map[string]int),
 	}
 	ctx.AllValues = append([]interface{}{st}, ctx.AllValues...)
 	return st
@@ -93,14 +91,6 @@ func init() {
 			Example:     "ENTROPY(x) -> 1.585",
 			Category:    "aggregate",
 		},
-		{
-			Name:        "approx_count_distinct",
-			Handler:     aggApproxCountDistinct,
-			Result:      aggApproxCountDistinctResult,
-			Description: "Approximate count of distinct values",
-			Example:     "APPROX_COUNT_DISTINCT(x) -> 42",
-			Category:    "aggregate",
-		},
 	}
 
 	for _, fn := range advancedAggregates {
@@ -370,23 +360,3 @@ func aggEntropyResult(ctx *AggregateContext) (interface{}, error) {
 	}
 	return ent, nil
 }
-
-// --- approx_count_distinct ---
-
-func aggApproxCountDistinct(ctx *AggregateContext, args []interface{}) error {
-	if len(args) < 1 {
-		return fmt.Errorf("approx_count_distinct() requires 1 argument")
-	}
-	if args[0] == nil {
-		return nil
-	}
-	st := getAdvState(ctx)
-	key := toString(args[0])
-	st.distinct[key] = struct{}{}
-	return nil
-}
-
-func aggApproxCountDistinctResult(ctx *AggregateContext) (interface{}, error) {
-	st := getAdvState(ctx)
-	return int64(len(st.distinct)), nil
-}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/kasuganosora/sqlexec/pkg/sketch"
 	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
@@ -16,14 +17,21 @@ type AggregateContext struct {
 	Min         interface{}
 	Max         interface{}
 	AvgSum      float64
-	Values      []float64     // 用于标准差等
-	Strings     []string      // for GROUP_CONCAT
-	AllValues   []interface{} // for ARRAY_AGG, MEDIAN, MODE
-	BoolAnd     *bool         // for BOOL_AND
-	BoolOr      *bool         // for BOOL_OR
-	Separator   string        // for GROUP_CONCAT separator
-	ProductVal  float64       // for PRODUCT, init to 1.0
-	ProductInit bool          // for PRODUCT
+	Values      []float64           // 用于标准差等
+	Strings     []string            // for GROUP_CONCAT
+	AllValues   []interface{}       // for ARRAY_AGG, MEDIAN, MODE
+	BoolAnd     *bool               // for BOOL_AND
+	BoolOr      *bool               // for BOOL_OR
+	Separator   string              // for GROUP_CONCAT separator
+	ProductVal  float64             // for PRODUCT, init to 1.0
+	ProductInit bool                // for PRODUCT
+	Bits        uint64              // for BIT_AND / BIT_OR / BIT_XOR
+	BitsInit    bool                // for BIT_AND
+	Picked      interface{}         // for FIRST_BY / LAST_BY: 选中行的值
+	PickedKey   interface{}         // for FIRST_BY / LAST_BY: 选中行的时间
+	Distinct    *sketch.HyperLogLog // for APPROX_COUNT_DISTINCT
+	Digest      *sketch.TDigest     // for APPROX_PERCENTILE
+	Quantile    float64             // for APPROX_PERCENTILE: 0 到 1 的分位数
 }
 
 // NewAggregateContext 创建聚合上下文
@@ -43,18 +51,22 @@ type AggregateHandle func(ctx *AggregateContext, args []interface{}) error
 // AggregateResult 聚合结果函数
 type AggregateResult func(ctx *AggregateContext) (interface{}, error)
 
+// AggregateMerge 把部分聚合状态 src 合并到 dst，用于并行聚合
+type AggregateMerge func(dst, src *AggregateContext) error
+
 // AggregateFunctionInfo 聚合函数信息
 type AggregateFunctionInfo struct {
 	Name        string
 	Handler     AggregateHandle
 	Result      AggregateResult
+	Merge       AggregateMerge // 为 nil 时不能并行聚合
 	Description string
 	Example     string
 	Category    string
 }
 
 func init() {
-	// 聚合函数列表（将在initAggregateFunctions中注册）
+	InitAggregateFunctions()
 }
 
 // InitAggregateFunctions 初始化聚合函数
@@ -252,6 +264,30 @@ func InitAggregateFunctions() {
 			Example:     "PRODUCT(quantity) -> 120",
 			Category:    "aggregate",
 		},
+		{
+			Name:        "bit_and",
+			Handler:     aggBitAnd,
+			Result:      aggBitAndResult,
+			Description: "Bitwise AND of all values",
+			Example:     "BIT_AND(flags) -> 4",
+			Category:    "aggregate",
+		},
+		{
+			Name:        "bit_or",
+			Handler:     aggBitOr,
+			Result:      aggBitResult,
+			Description: "Bitwise OR of all values",
+			Example:     "BIT_OR(flags) -> 7",
+			Category:    "aggregate",
+		},
+		{
+			Name:        "bit_xor",
+			Handler:     aggBitXor,
+			Result:      aggBitResult,
+			Description: "Bitwise XOR of all values",
+			Example:     "BIT_XOR(flags) -> 3",
+			Category:    "aggregate",
+		},
 	}
 
 	for _, fn := range aggregateFunctions {
@@ -800,3 +836,74 @@ func aggProductResult(ctx *AggregateContext) (interface{}, error) {
 	}
 	return ctx.ProductVal, nil
 }
+
+// BIT_AND / BIT_OR / BIT_XOR
+// 与 MySQL 一致：结果为无符号 64 位整数，忽略 NULL；空集合时 BIT_AND 返回全 1，BIT_OR/BIT_XOR 返回 0
+func bitArg(arg interface{}) (uint64, error) {
+	if v, ok := arg.(uint64); ok {
+		return v, nil
+	}
+	v, err := utils.ToInt64(arg)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(v), nil
+}
+
+func aggBitAnd(ctx *AggregateContext, args []interface{}) error {
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		v, err := bitArg(arg)
+		if err != nil {
+			return err
+		}
+		if !ctx.BitsInit {
+			ctx.Bits = v
+			ctx.BitsInit = true
+		} else {
+			ctx.Bits &= v
+		}
+	}
+	return nil
+}
+
+func aggBitAndResult(ctx *AggregateContext) (interface{}, error) {
+	if !ctx.BitsInit {
+		return uint64(math.MaxUint64), nil
+	}
+	return ctx.Bits, nil
+}
+
+func aggBitOr(ctx *AggregateContext, args []interface{}) error {
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		v, err := bitArg(arg)
+		if err != nil {
+			return err
+		}
+		ctx.Bits |= v
+	}
+	return nil
+}
+
+func aggBitXor(ctx *AggregateContext, args []interface{}) error {
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		v, err := bitArg(arg)
+		if err != nil {
+			return err
+		}
+		ctx.Bits ^= v
+	}
+	return nil
+}
+
+func aggBitResult(ctx *AggregateContext) (interface{}, error) {
+	return ctx.Bits, nil
+}
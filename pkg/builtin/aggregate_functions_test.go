@@ -885,6 +885,40 @@ func TestAggNewContextDefaults(t *testing.T) {
 	}
 }
 
+// ---- BIT_AND / BIT_OR / BIT_XOR ----
+
+func TestAggBitFunctions(t *testing.T) {
+	values := []interface{}{int64(6), nil, int64(3), int(7)}
+
+	and := NewAggregateContext()
+	or := NewAggregateContext()
+	xor := NewAggregateContext()
+	for _, v := range values {
+		aggBitAnd(and, []interface{}{v})
+		aggBitOr(or, []interface{}{v})
+		aggBitXor(xor, []interface{}{v})
+	}
+
+	if r, _ := aggBitAndResult(and); r != uint64(2) {
+		t.Errorf("BIT_AND expected 2, got %v", r)
+	}
+	if r, _ := aggBitResult(or); r != uint64(7) {
+		t.Errorf("BIT_OR expected 7, got %v", r)
+	}
+	if r, _ := aggBitResult(xor); r != uint64(2) {
+		t.Errorf("BIT_XOR expected 2, got %v", r)
+	}
+}
+
+func TestAggBitFunctionsEmpty(t *testing.T) {
+	if r, _ := aggBitAndResult(NewAggregateContext()); r != uint64(math.MaxUint64) {
+		t.Errorf("BIT_AND of empty set expected MaxUint64, got %v", r)
+	}
+	if r, _ := aggBitResult(NewAggregateContext()); r != uint64(0) {
+		t.Errorf("BIT_OR of empty set expected 0, got %v", r)
+	}
+}
+
 // ---- Registration check for all new aggregate functions ----
 
 func TestAggAllNewFunctionsRegistered(t *testing.T) {
@@ -899,6 +933,7 @@ func TestAggAllNewFunctionsRegistered(t *testing.T) {
 		"percentile_cont", "percentile_disc",
 		"array_agg", "list",
 		"product",
+		"bit_and", "bit_or", "bit_xor",
 	}
 	for _, name := range names {
 		info, ok := GetAggregate(name)
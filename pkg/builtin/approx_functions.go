@@ -0,0 +1,135 @@
+package builtin
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/sketch"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+func init() {
+	for _, fn := range []*AggregateFunctionInfo{
+		{
+			Name:        "approx_count_distinct",
+			Handler:     aggApproxCountDistinct,
+			Result:      aggApproxCountDistinctResult,
+			Merge:       mergeApproxCountDistinct,
+			Description: "用 HyperLogLog 估计不同值的个数，标准误差约 0.81%",
+			Example:     "APPROX_COUNT_DISTINCT(user_id) -> 1048576",
+			Category:    "aggregate",
+		},
+		{
+			Name:        "approx_percentile",
+			Handler:     aggApproxPercentile,
+			Result:      aggApproxPercentileResult,
+			Merge:       mergeApproxPercentile,
+			Description: "用 t-digest 估计百分位数，百分比为 1 到 100",
+			Example:     "APPROX_PERCENTILE(latency, 99) -> 250.5",
+			Category:    "aggregate",
+		},
+	} {
+		RegisterAggregate(fn)
+	}
+}
+
+// aggApproxCountDistinct APPROX_COUNT_DISTINCT(expr)：NULL 被忽略
+func aggApproxCountDistinct(ctx *AggregateContext, args []interface{}) error {
+	if len(args) < 1 {
+		return fmt.Errorf("approx_count_distinct() requires 1 argument")
+	}
+	if args[0] == nil {
+		return nil
+	}
+	if ctx.Distinct == nil {
+		ctx.Distinct, _ = sketch.NewHyperLogLog(sketch.DefaultPrecision)
+	}
+	ctx.Distinct.Add(sketch.Hash(distinctKey(args[0])))
+	return nil
+}
+
+func aggApproxCountDistinctResult(ctx *AggregateContext) (interface{}, error) {
+	if ctx.Distinct == nil {
+		return int64(0), nil
+	}
+	return int64(ctx.Distinct.Estimate()), nil
+}
+
+func mergeApproxCountDistinct(dst, src *AggregateContext) error {
+	if src.Distinct == nil {
+		return nil
+	}
+	if dst.Distinct == nil {
+		dst.Distinct, _ = sketch.NewHyperLogLog(sketch.DefaultPrecision)
+	}
+	return dst.Distinct.Merge(src.Distinct)
+}
+
+// distinctKey 值的去重键：数值按数值比较，1 与 1.0 是同一个值
+func distinctKey(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return "s:" + val
+	case []byte:
+		return "s:" + string(val)
+	case time.Time:
+		return "t:" + strconv.FormatInt(val.UnixNano(), 10)
+	case bool:
+		if val {
+			return "n:1"
+		}
+		return "n:0"
+	}
+	if f, err := utils.ToFloat64(v); err == nil {
+		return "n:" + strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+// aggApproxPercentile APPROX_PERCENTILE(expr, percentage)：percentage 为 1 到 100 的常量，
+// 与 TiDB 相同；NULL 与非数值被忽略
+func aggApproxPercentile(ctx *AggregateContext, args []interface{}) error {
+	if len(args) != 2 {
+		return fmt.Errorf("approx_percentile() requires 2 arguments (expr, percentage)")
+	}
+	if ctx.Digest == nil {
+		percentage, err := utils.ToFloat64(args[1])
+		if err != nil || percentage <= 0 || percentage > 100 {
+			return fmt.Errorf("approx_percentile: percentage must be between 1 and 100, got %v", args[1])
+		}
+		ctx.Quantile = percentage / 100
+		ctx.Digest = sketch.NewTDigest(sketch.DefaultCompression)
+	}
+	if args[0] == nil {
+		return nil
+	}
+	if val, err := utils.ToFloat64(args[0]); err == nil {
+		ctx.Digest.Add(val)
+	}
+	return nil
+}
+
+func aggApproxPercentileResult(ctx *AggregateContext) (interface{}, error) {
+	if ctx.Digest == nil || ctx.Digest.Count() == 0 {
+		return nil, nil
+	}
+	v := ctx.Digest.Quantile(ctx.Quantile)
+	if math.IsNaN(v) {
+		return nil, nil
+	}
+	return v, nil
+}
+
+func mergeApproxPercentile(dst, src *AggregateContext) error {
+	if src.Digest == nil {
+		return nil
+	}
+	if dst.Digest == nil {
+		dst.Quantile = src.Quantile
+		dst.Digest = sketch.NewTDigest(sketch.DefaultCompression)
+	}
+	dst.Digest.Merge(src.Digest)
+	return nil
+}
@@ -0,0 +1,59 @@
+package builtin
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAggApproxCountDistinctMerge(t *testing.T) {
+	a, b := NewAggregateContext(), NewAggregateContext()
+	for i := 0; i < 3000; i++ {
+		if err := aggApproxCountDistinct(a, []interface{}{int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+		// 1500 与 1500.0 是同一个值
+		if err := aggApproxCountDistinct(b, []interface{}{float64(i + 1500)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mergeApproxCountDistinct(a, b); err != nil {
+		t.Fatal(err)
+	}
+	result, _ := aggApproxCountDistinctResult(a)
+	if n := result.(int64); math.Abs(float64(n)-4500) > 4500*0.03 {
+		t.Errorf("approx_count_distinct = %d, want about 4500", n)
+	}
+}
+
+func TestAggApproxPercentile(t *testing.T) {
+	a, b := NewAggregateContext(), NewAggregateContext()
+	for i := 1; i <= 1000; i++ {
+		ctx := a
+		if i%2 == 0 {
+			ctx = b
+		}
+		if err := aggApproxPercentile(ctx, []interface{}{int64(i), 90}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := aggApproxPercentile(a, []interface{}{nil, 90}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mergeApproxPercentile(a, b); err != nil {
+		t.Fatal(err)
+	}
+	result, _ := aggApproxPercentileResult(a)
+	if v := result.(float64); math.Abs(v-900) > 10 {
+		t.Errorf("approx_percentile(90) = %v, want about 900", v)
+	}
+
+	empty := NewAggregateContext()
+	if result, _ := aggApproxPercentileResult(empty); result != nil {
+		t.Errorf("empty group = %v, want NULL", result)
+	}
+	for _, bad := range []interface{}{0, 101, "x"} {
+		if err := aggApproxPercentile(NewAggregateContext(), []interface{}{1, bad}); err == nil {
+			t.Errorf("percentage %v: expected error", bad)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package builtin
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
@@ -63,6 +64,17 @@ func init() {
 			Example:     "IF(1 > 0, 'yes', 'no') -> 'yes'",
 			Category:    "control",
 		},
+		{
+			Name: "case",
+			Type: FunctionTypeScalar,
+			Signatures: []FunctionSignature{
+				{Name: "case", ReturnType: "any", ParamTypes: []string{"boolean", "any"}, Variadic: true},
+			},
+			Handler:     controlCase,
+			Description: "CASE WHEN 表达式：参数依次为条件与结果，参数个数为奇数时最后一个是 ELSE 的结果",
+			Example:     "CASE WHEN 1 > 2 THEN 'a' WHEN 2 > 1 THEN 'b' END -> 'b'",
+			Category:    "control",
+		},
 		{
 			Name: "iif",
 			Type: FunctionTypeScalar,
@@ -96,6 +108,28 @@ func init() {
 			Example:     "LEAST(1, 5, 3) -> 1",
 			Category:    "control",
 		},
+		{
+			Name: "cast",
+			Type: FunctionTypeScalar,
+			Signatures: []FunctionSignature{
+				{Name: "cast", ReturnType: "any", ParamTypes: []string{"any", "string"}, Variadic: false},
+			},
+			Handler:     controlCast,
+			Description: "按类型转换规则把值转换为 SIGNED、UNSIGNED、CHAR(n)、BINARY(n)、DATE、DATETIME、TIME、DECIMAL(p,s)、DOUBLE 或 FLOAT",
+			Example:     "CAST('3.14159' AS DECIMAL(10,2)) -> 3.14",
+			Category:    "control",
+		},
+		{
+			Name: "convert",
+			Type: FunctionTypeScalar,
+			Signatures: []FunctionSignature{
+				{Name: "convert", ReturnType: "any", ParamTypes: []string{"any", "string"}, Variadic: false},
+			},
+			Handler:     controlConvert,
+			Description: "CONVERT(expr, type) 同 CAST；CONVERT(expr USING charset) 把值转换为字符串",
+			Example:     "CONVERT('12', SIGNED) -> 12",
+			Category:    "control",
+		},
 	}
 
 	for _, fn := range controlFunctions {
@@ -157,6 +191,23 @@ func controlIf(args []interface{}) (interface{}, error) {
 	return args[2], nil
 }
 
+// controlCase returns the result of the first true condition, the ELSE result, or NULL.
+// CASE expressions are parsed into CASE(cond1, result1, ..., condN, resultN[, else]).
+func controlCase(args []interface{}) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("case requires at least one WHEN ... THEN branch")
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		if toBool(args[i]) {
+			return args[i+1], nil
+		}
+	}
+	if len(args)%2 == 1 {
+		return args[len(args)-1], nil
+	}
+	return nil, nil
+}
+
 // controlGreatest returns the largest value among its arguments.
 func controlGreatest(args []interface{}) (interface{}, error) {
 	if len(args) == 0 {
@@ -191,6 +242,47 @@ func controlLeast(args []interface{}) (interface{}, error) {
 	return best, nil
 }
 
+// controlCast converts args[0] to the type named by args[1], e.g. "DECIMAL(10,2)".
+// CAST(x AS type) and CONVERT(x, type) are both parsed into CAST(x, 'type').
+func controlCast(args []interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("cast() requires exactly 2 arguments")
+	}
+	typ, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("cast() target type must be a string, got %T", args[1])
+	}
+	ct, err := utils.ParseCastType(typ)
+	if err != nil {
+		return nil, err
+	}
+	return utils.CastValue(args[0], ct)
+}
+
+// controlConvert handles CONVERT(x, type) and CONVERT(x USING charset); the latter yields a string.
+func controlConvert(args []interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("convert() requires exactly 2 arguments")
+	}
+	typ, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("convert() target type must be a string, got %T", args[1])
+	}
+	if _, err := utils.ParseCastType(typ); err != nil {
+		if !convertCharsets[strings.ToLower(strings.TrimSpace(typ))] {
+			return nil, err
+		}
+		return utils.CastValue(args[0], utils.CastType{Name: "CHAR", Length: -1})
+	}
+	return controlCast(args)
+}
+
+// convertCharsets character sets accepted by CONVERT(x USING charset)
+var convertCharsets = map[string]bool{
+	"utf8": true, "utf8mb3": true, "utf8mb4": true, "latin1": true, "ascii": true,
+	"binary": true, "gbk": true, "gb18030": true, "ucs2": true, "utf16": true, "utf32": true,
+}
+
 // toBool converts a value to boolean for conditional evaluation.
 func toBool(v interface{}) bool {
 	if v == nil {
@@ -2,6 +2,7 @@ package builtin
 
 import (
 	"testing"
+	"time"
 )
 
 func TestCoalesce(t *testing.T) {
@@ -101,6 +102,29 @@ func TestControlIf(t *testing.T) {
 	}
 }
 
+func TestControlCase(t *testing.T) {
+	tests := []struct {
+		args []interface{}
+		want interface{}
+	}{
+		{[]interface{}{true, "a"}, "a"},
+		{[]interface{}{false, "a"}, nil},
+		{[]interface{}{false, "a", "else"}, "else"},
+		{[]interface{}{nil, "a", 1, "b", "else"}, "b"},
+		{[]interface{}{0, "a", false, "b"}, nil},
+	}
+	for _, tt := range tests {
+		result, err := controlCase(tt.args)
+		if err != nil {
+			t.Errorf("controlCase(%v) error = %v", tt.args, err)
+			continue
+		}
+		if result != tt.want {
+			t.Errorf("controlCase(%v) = %v, want %v", tt.args, result, tt.want)
+		}
+	}
+}
+
 func TestGreatest(t *testing.T) {
 	tests := []struct {
 		args []interface{}
@@ -149,6 +173,48 @@ func TestLeast(t *testing.T) {
 	}
 }
 
+func TestControlCast(t *testing.T) {
+	tests := []struct {
+		args []interface{}
+		want interface{}
+	}{
+		{[]interface{}{"12", "SIGNED"}, int64(12)},
+		{[]interface{}{"3.14159", "DECIMAL(10,2)"}, "3.14"},
+		{[]interface{}{1.005, "DECIMAL(10,2)"}, "1.01"},
+		{[]interface{}{42, "CHAR"}, "42"},
+		{[]interface{}{nil, "SIGNED"}, nil},
+		{[]interface{}{"2024-03-15 10:20:30", "DATE"}, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		result, err := controlCast(tt.args)
+		if err != nil {
+			t.Errorf("controlCast(%v) error = %v", tt.args, err)
+			continue
+		}
+		if result != tt.want {
+			t.Errorf("controlCast(%v) = %v, want %v", tt.args, result, tt.want)
+		}
+	}
+
+	if _, err := controlCast([]interface{}{1, "BLOB"}); err == nil {
+		t.Error("expected error for unsupported cast type")
+	}
+}
+
+func TestControlConvert(t *testing.T) {
+	result, err := controlConvert([]interface{}{"12", "UNSIGNED"})
+	if err != nil || result != uint64(12) {
+		t.Errorf("controlConvert(12, UNSIGNED) = %v, %v", result, err)
+	}
+	result, err = controlConvert([]interface{}{1.5, "utf8mb4"})
+	if err != nil || result != "1.5" {
+		t.Errorf("controlConvert(1.5 USING utf8mb4) = %v, %v", result, err)
+	}
+	if _, err := controlConvert([]interface{}{1, "klingon"}); err == nil {
+		t.Error("expected error for unknown convert target")
+	}
+}
+
 func TestToBool(t *testing.T) {
 	tests := []struct {
 		input interface{}
@@ -177,7 +243,7 @@ func TestToBool(t *testing.T) {
 }
 
 func TestControlFunctions_Registration(t *testing.T) {
-	funcs := []string{"coalesce", "nullif", "ifnull", "nvl", "if", "iif", "greatest", "least"}
+	funcs := []string{"coalesce", "nullif", "ifnull", "nvl", "case", "if", "iif", "greatest", "least", "cast", "convert"}
 	for _, name := range funcs {
 		fn, ok := GetGlobal(name)
 		if !ok {
@@ -228,7 +228,7 @@ func init() {
 		{Name: "make_timestamp", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "make_timestamp", ReturnType: "datetime", ParamTypes: []string{"integer", "integer", "integer", "integer", "integer", "integer"}, Variadic: false}}, Handler: dateMakeTimestamp, Description: "从年月日时分秒构造时间戳", Example: "MAKE_TIMESTAMP(2024, 3, 15, 12, 30, 45) -> '2024-03-15 12:30:45'", Category: "date"},
 		// === Batch 9: Advanced Date Functions ===
 		{Name: "age", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "age", ReturnType: "string", ParamTypes: []string{"datetime", "datetime"}, Variadic: false}}, Handler: dateAge, Description: "Difference between two dates as 'X years Y months Z days'", Example: "AGE('2026-03-15', '2024-01-01') -> '2 years 2 months 14 days'", Category: "date"},
-		{Name: "time_bucket", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "time_bucket", ReturnType: "datetime", ParamTypes: []string{"integer", "datetime"}, Variadic: false}}, Handler: dateTimeBucket, Description: "Truncate timestamp to interval boundary (seconds)", Example: "TIME_BUCKET(3600, '2024-01-01 12:34:56') -> '2024-01-01 12:00:00'", Category: "date"},
+		{Name: "time_bucket", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "time_bucket", ReturnType: "datetime", ParamTypes: []string{"string", "datetime", "datetime"}, Variadic: true}}, Handler: dateTimeBucket, Description: "Truncate timestamp to interval boundary (seconds or '5 minutes'), optionally aligned to an origin", Example: "TIME_BUCKET('15 minutes', '2024-01-01 12:34:56') -> '2024-01-01 12:30:00'", Category: "date"},
 		{Name: "century", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "century", ReturnType: "integer", ParamTypes: []string{"datetime"}, Variadic: false}}, Handler: dateCentury, Description: "Century of the date", Example: "CENTURY('2024-03-15') -> 21", Category: "date"},
 		{Name: "decade", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "decade", ReturnType: "integer", ParamTypes: []string{"datetime"}, Variadic: false}}, Handler: dateDecade, Description: "Decade of the date (year/10)", Example: "DECADE('2024-03-15') -> 202", Category: "date"},
 		{Name: "millennium", Type: FunctionTypeScalar, Signatures: []FunctionSignature{{Name: "millennium", ReturnType: "integer", ParamTypes: []string{"datetime"}, Variadic: false}}, Handler: dateMillennium, Description: "Millennium of the date", Example: "MILLENNIUM('2024-03-15') -> 3", Category: "date"},
@@ -795,28 +795,6 @@ func dateAge(args []interface{}) (interface{}, error) {
 	return fmt.Sprintf("%d years %d months %d days", years, months, days), nil
 }
 
-// dateTimeBucket truncates a timestamp to an interval boundary.
-// args[0] = interval in seconds, args[1] = datetime
-func dateTimeBucket(args []interface{}) (interface{}, error) {
-	if len(args) != 2 {
-		return nil, fmt.Errorf("time_bucket() requires exactly 2 arguments (interval_seconds, datetime)")
-	}
-	interval, err := utils.ToInt64(args[0])
-	if err != nil {
-		return nil, fmt.Errorf("time_bucket: interval must be integer: %w", err)
-	}
-	if interval <= 0 {
-		return nil, fmt.Errorf("time_bucket: interval must be positive")
-	}
-	t, err := toTime(args[1])
-	if err != nil {
-		return nil, err
-	}
-	unix := t.Unix()
-	truncated := unix - (unix % interval)
-	return time.Unix(truncated, 0).UTC(), nil
-}
-
 // dateCentury returns (year-1)/100 + 1.
 func dateCentury(args []interface{}) (interface{}, error) {
 	if len(args) != 1 {
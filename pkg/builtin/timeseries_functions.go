@@ -0,0 +1,255 @@
+package builtin
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// MaxSeriesLength GENERATE_SERIES 单次生成的最大行数，防止步长写错时生成海量的行
+const MaxSeriesLength = 1000000
+
+// intervalPattern '5 minutes'、'1 hour' 形式的时间间隔
+var intervalPattern = regexp.MustCompile(`(?i)^\s*(\d+(?:\.\d+)?)\s*([a-z]+)\s*$`)
+
+// intervalUnits 时间间隔的单位；月和年的长度不固定，不能作为时间桶宽度
+var intervalUnits = map[string]time.Duration{
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseInterval 解析时间间隔：整数秒数，或 '5 minutes'、'1 hour'、'15m'、'1h30m' 之类的字符串
+func ParseInterval(v interface{}) (time.Duration, error) {
+	var d time.Duration
+	switch val := v.(type) {
+	case nil:
+		return 0, fmt.Errorf("interval must not be NULL")
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err == nil {
+			d = time.Duration(n) * time.Second
+		} else if m := intervalPattern.FindStringSubmatch(val); m != nil {
+			unit, ok := intervalUnits[strings.ToLower(m[2])]
+			if !ok {
+				return 0, fmt.Errorf("unsupported interval unit '%s'", m[2])
+			}
+			n, _ := strconv.ParseFloat(m[1], 64)
+			d = time.Duration(n * float64(unit))
+		} else if parsed, err := time.ParseDuration(strings.TrimSpace(val)); err == nil {
+			d = parsed
+		} else {
+			return 0, fmt.Errorf("invalid interval '%s'", val)
+		}
+	default:
+		seconds, err := utils.ToFloat64(val)
+		if err != nil {
+			return 0, fmt.Errorf("interval must be a number of seconds or a string like '5 minutes'")
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("interval must be positive")
+	}
+	return d, nil
+}
+
+// bucketStart 返回 t 所在时间桶的起点，时间桶从 origin 开始按 width 对齐
+func bucketStart(t, origin time.Time, width time.Duration) time.Time {
+	offset := t.Sub(origin)
+	n := offset / width
+	if offset%width < 0 {
+		n-- // 早于 origin 的时间向下取整
+	}
+	return origin.Add(n * width)
+}
+
+// dateTimeBucket truncates a timestamp to an interval boundary.
+// args[0] = interval (seconds or a string like '5 minutes'), args[1] = datetime,
+// args[2] = optional origin the buckets are aligned to (default 1970-01-01 00:00:00 UTC)
+func dateTimeBucket(args []interface{}) (interface{}, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("time_bucket() requires 2 or 3 arguments (interval, datetime[, origin])")
+	}
+	if args[1] == nil {
+		return nil, nil
+	}
+	width, err := ParseInterval(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("time_bucket: %w", err)
+	}
+	t, err := toTime(args[1])
+	if err != nil {
+		return nil, err
+	}
+	origin := time.Unix(0, 0).UTC()
+	if len(args) == 3 && args[2] != nil {
+		if origin, err = toTime(args[2]); err != nil {
+			return nil, fmt.Errorf("time_bucket: %w", err)
+		}
+	}
+	return bucketStart(t.UTC(), origin.UTC(), width), nil
+}
+
+// GapFillBuckets 返回 [start, finish) 内按 interval 对齐的所有时间桶起点，对齐方式与 TIME_BUCKET 相同
+func GapFillBuckets(interval, start, finish interface{}) ([]time.Time, error) {
+	width, err := ParseInterval(interval)
+	if err != nil {
+		return nil, fmt.Errorf("time_bucket_gapfill: %w", err)
+	}
+	from, err := ToTime(start)
+	if err != nil {
+		return nil, fmt.Errorf("time_bucket_gapfill: %w", err)
+	}
+	to, err := ToTime(finish)
+	if err != nil {
+		return nil, fmt.Errorf("time_bucket_gapfill: %w", err)
+	}
+	first := bucketStart(from.UTC(), time.Unix(0, 0).UTC(), width)
+	if n := int64(to.Sub(first) / width); n > MaxSeriesLength {
+		return nil, fmt.Errorf("time_bucket_gapfill: more than %d buckets", MaxSeriesLength)
+	}
+	var out []time.Time
+	for t := first; t.Before(to); t = t.Add(width) {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// ToTime 把 DATETIME 值或日期时间字符串转换为 time.Time
+func ToTime(v interface{}) (time.Time, error) {
+	return toTime(v)
+}
+
+// GenerateSeries 生成从 start 到 stop（含）的序列：整数按 step（默认 1）递增，
+// start 为时间时按时间间隔 step（默认 1 秒）递增；step 为负时递减
+func GenerateSeries(start, stop, step interface{}) ([]interface{}, error) {
+	if start == nil || stop == nil {
+		return nil, nil
+	}
+	if from, err := utils.ToInt64(start); err == nil && !isTimeString(start) {
+		to, err := utils.ToInt64(stop)
+		if err != nil {
+			return nil, fmt.Errorf("generate_series: stop must be an integer")
+		}
+		inc := int64(1)
+		if step != nil {
+			if inc, err = utils.ToInt64(step); err != nil || inc == 0 {
+				return nil, fmt.Errorf("generate_series: step must be a non-zero integer")
+			}
+		}
+		if n := (to-from)/inc + 1; n > MaxSeriesLength {
+			return nil, fmt.Errorf("generate_series: more than %d values", MaxSeriesLength)
+		}
+		var out []interface{}
+		for v := from; (inc > 0 && v <= to) || (inc < 0 && v >= to); v += inc {
+			out = append(out, v)
+		}
+		return out, nil
+	}
+
+	from, err := toTime(start)
+	if err != nil {
+		return nil, fmt.Errorf("generate_series: start must be an integer or a datetime")
+	}
+	to, err := toTime(stop)
+	if err != nil {
+		return nil, fmt.Errorf("generate_series: %w", err)
+	}
+	inc := time.Second
+	negative := false
+	if step != nil {
+		if s, ok := step.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "-") {
+			negative, step = true, strings.TrimPrefix(strings.TrimSpace(s), "-")
+		}
+		if inc, err = ParseInterval(step); err != nil {
+			return nil, fmt.Errorf("generate_series: %w", err)
+		}
+		if negative {
+			inc = -inc
+		}
+	}
+	if n := int64(to.Sub(from)/inc) + 1; n > MaxSeriesLength {
+		return nil, fmt.Errorf("generate_series: more than %d values", MaxSeriesLength)
+	}
+	var out []interface{}
+	for t := from; (inc > 0 && !t.After(to)) || (inc < 0 && !t.Before(to)); t = t.Add(inc) {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// isTimeString 字符串形式的日期时间（而非数字字符串）
+func isTimeString(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && strings.ContainsAny(s, "-:")
+}
+
+func init() {
+	for _, fn := range []*AggregateFunctionInfo{
+		{
+			Name:        "first_by",
+			Handler:     aggFirstBy,
+			Result:      aggPickedResult,
+			Description: "返回时间最早的行的值",
+			Example:     "FIRST(value, ts) -> 1.5",
+			Category:    "aggregate",
+		},
+		{
+			Name:        "last_by",
+			Handler:     aggLastBy,
+			Result:      aggPickedResult,
+			Description: "返回时间最晚的行的值",
+			Example:     "LAST(value, ts) -> 2.5",
+			Category:    "aggregate",
+		},
+	} {
+		RegisterAggregate(fn)
+	}
+}
+
+// aggFirstBy FIRST(value, ts)：保留 ts 最小的行的 value，ts 为 NULL 的行被忽略；
+// 省略 ts 时保留第一行的值
+func aggFirstBy(ctx *AggregateContext, args []interface{}) error {
+	return aggPickBy(ctx, args, func(cmp int) bool { return cmp < 0 })
+}
+
+// aggLastBy LAST(value, ts)：保留 ts 最大的行的 value，ts 相同时取后出现的行；
+// 省略 ts 时保留最后一行的值
+func aggLastBy(ctx *AggregateContext, args []interface{}) error {
+	return aggPickBy(ctx, args, func(cmp int) bool { return cmp >= 0 })
+}
+
+// aggPickBy 按 ts 比较后决定是否用当前行替换已选中的值
+func aggPickBy(ctx *AggregateContext, args []interface{}, replace func(cmp int) bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("requires a value argument")
+	}
+	if len(args) == 1 {
+		// 没有时间列：按行的顺序
+		if ctx.Count == 0 || replace(0) {
+			ctx.Picked = args[0]
+		}
+		ctx.Count++
+		return nil
+	}
+	ts := args[1]
+	if ts == nil {
+		return nil
+	}
+	if ctx.Count == 0 || replace(compareValues(ts, ctx.PickedKey)) {
+		ctx.Picked, ctx.PickedKey = args[0], ts
+	}
+	ctx.Count++
+	return nil
+}
+
+func aggPickedResult(ctx *AggregateContext) (interface{}, error) {
+	return ctx.Picked, nil
+}
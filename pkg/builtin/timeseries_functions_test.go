@@ -0,0 +1,122 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want time.Duration
+	}{
+		{int64(300), 5 * time.Minute},
+		{"60", time.Minute},
+		{"5 minutes", 5 * time.Minute},
+		{"1 HOUR", time.Hour},
+		{"15m", 15 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"2 days", 48 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseInterval(tt.in)
+		if err != nil {
+			t.Fatalf("ParseInterval(%v) error = %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseInterval(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []interface{}{nil, "0", "-5 minutes", "1 month", "soon"} {
+		if _, err := ParseInterval(bad); err == nil {
+			t.Errorf("ParseInterval(%v) expected error", bad)
+		}
+	}
+}
+
+func TestDateTimeBucketInterval(t *testing.T) {
+	result, err := dateTimeBucket([]interface{}{"15 minutes", "2024-01-01 12:34:56"})
+	if err != nil {
+		t.Fatalf("dateTimeBucket() error = %v", err)
+	}
+	if got := result.(time.Time).Format("2006-01-02 15:04:05"); got != "2024-01-01 12:30:00" {
+		t.Errorf("got %v", got)
+	}
+
+	result, err = dateTimeBucket([]interface{}{"1 hour", "2024-01-01 12:34:56", "2024-01-01 00:30:00"})
+	if err != nil {
+		t.Fatalf("dateTimeBucket() error = %v", err)
+	}
+	if got := result.(time.Time).Format("2006-01-02 15:04:05"); got != "2024-01-01 12:30:00" {
+		t.Errorf("got %v with origin", got)
+	}
+
+	if result, err = dateTimeBucket([]interface{}{"1 hour", nil}); err != nil || result != nil {
+		t.Errorf("NULL timestamp: got %v, %v", result, err)
+	}
+}
+
+func TestGenerateSeries(t *testing.T) {
+	values, err := GenerateSeries(int64(1), int64(10), int64(3))
+	if err != nil {
+		t.Fatalf("GenerateSeries() error = %v", err)
+	}
+	if len(values) != 4 || values[3] != int64(10) {
+		t.Errorf("integer series = %v", values)
+	}
+
+	values, err = GenerateSeries("2024-01-01 00:00:00", "2024-01-01 01:00:00", "15 minutes")
+	if err != nil {
+		t.Fatalf("GenerateSeries() error = %v", err)
+	}
+	if len(values) != 5 {
+		t.Errorf("time series = %v", values)
+	}
+
+	values, err = GenerateSeries("2024-01-01 01:00:00", "2024-01-01 00:00:00", "-30 minutes")
+	if err != nil || len(values) != 3 {
+		t.Errorf("descending series = %v, %v", values, err)
+	}
+
+	if _, err = GenerateSeries(int64(1), int64(MaxSeriesLength+1), nil); err == nil {
+		t.Error("expected error for too many values")
+	}
+}
+
+func TestGapFillBuckets(t *testing.T) {
+	buckets, err := GapFillBuckets("5 minutes", "2024-01-01 00:02:00", "2024-01-01 00:15:00")
+	if err != nil {
+		t.Fatalf("GapFillBuckets() error = %v", err)
+	}
+	if len(buckets) != 3 || buckets[0].Format("15:04") != "00:00" || buckets[2].Format("15:04") != "00:10" {
+		t.Errorf("buckets = %v", buckets)
+	}
+}
+
+func TestAggFirstLastBy(t *testing.T) {
+	first, last := NewAggregateContext(), NewAggregateContext()
+	for _, row := range [][]interface{}{
+		{2.0, "2024-01-01 00:01:00"},
+		{1.0, "2024-01-01 00:00:00"},
+		{9.0, nil},
+		{3.0, "2024-01-01 00:02:00"},
+	} {
+		if err := aggFirstBy(first, row); err != nil {
+			t.Fatal(err)
+		}
+		if err := aggLastBy(last, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, _ := aggPickedResult(first); v != 1.0 {
+		t.Errorf("first = %v, want 1", v)
+	}
+	if v, _ := aggPickedResult(last); v != 3.0 {
+		t.Errorf("last = %v, want 3", v)
+	}
+
+	empty := NewAggregateContext()
+	if v, _ := aggPickedResult(empty); v != nil {
+		t.Errorf("empty group = %v, want NULL", v)
+	}
+}
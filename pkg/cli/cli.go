@@ -0,0 +1,269 @@
+// Package cli 实现 sqlexec-cli 命令行客户端：交互式 REPL 与脚本执行
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// Options 客户端选项
+type Options struct {
+	Format      Format // 输出格式，默认 table
+	Force       bool   // 脚本中语句出错后继续执行
+	Timing      bool   // 非交互模式下也输出语句耗时
+	HistoryFile string // 交互模式的历史文件，为空时不保存
+	Out         io.Writer
+	Err         io.Writer
+}
+
+// Client 命令行客户端
+type Client struct {
+	conn        Conn
+	out         io.Writer
+	errOut      io.Writer
+	format      Format
+	expanded    bool // \x 开启的纵向输出
+	timing      bool
+	force       bool
+	interactive bool
+	historyFile string
+	completer   *completer
+}
+
+// NewClient 创建客户端
+func NewClient(conn Conn, opts Options) *Client {
+	c := &Client{
+		conn:        conn,
+		out:         opts.Out,
+		errOut:      opts.Err,
+		format:      opts.Format,
+		timing:      opts.Timing,
+		force:       opts.Force,
+		historyFile: opts.HistoryFile,
+		completer:   newCompleter(conn),
+	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
+	if c.errOut == nil {
+		c.errOut = os.Stderr
+	}
+	if c.format == "" {
+		c.format = FormatTable
+	}
+	return c
+}
+
+// RunScript 执行 r 中的语句；出错时停止（Force 时继续），返回第一个错误
+func (c *Client) RunScript(ctx context.Context, r io.Reader) error {
+	var sp splitter
+	var firstErr error
+	run := func(stmts []statement) bool {
+		for _, st := range stmts {
+			err := c.execute(ctx, st)
+			if errors.Is(err, errQuit) {
+				return false
+			}
+			if err != nil {
+				c.printError(err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				if !c.force {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" && !run(sp.Feed(strings.TrimRight(line, "\r\n"))) {
+			return firstErr
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	run(sp.Flush())
+	return firstErr
+}
+
+// RunInteractive 在终端上运行 REPL，直到 \q 或 Ctrl-D
+func (c *Client) RunInteractive(ctx context.Context, in *os.File) error {
+	c.interactive = true
+	editor := newLineEditor(in, c.out, func(line string, pos int) ([]string, int) {
+		return c.completer.Complete(ctx, line, pos)
+	}, c.historyFile)
+	defer editor.SaveHistory()
+
+	// 执行语句时 Ctrl-C 取消当前语句而不是退出
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	fmt.Fprintln(c.out, "Type \\? for help, \\q to quit.")
+	var sp splitter
+	for {
+		prompt := c.prompt()
+		if sp.Pending() {
+			prompt = sp.Continuation()
+		}
+		line, err := editor.ReadLine(prompt)
+		if errors.Is(err, errInterrupt) {
+			sp.Reset()
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		editor.AddHistory(line)
+
+		for _, st := range sp.Feed(line) {
+			drainSignals(interrupts)
+			stmtCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				select {
+				case <-interrupts:
+					cancel()
+				case <-done:
+				}
+			}()
+			err := c.execute(stmtCtx, st)
+			close(done)
+			cancel()
+			if errors.Is(err, errQuit) {
+				return nil
+			}
+			if err != nil {
+				c.printError(err)
+			}
+		}
+	}
+}
+
+func drainSignals(ch chan os.Signal) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) prompt() string {
+	if db := c.conn.Database(); db != "" {
+		return fmt.Sprintf("sqlexec [%s]> ", db)
+	}
+	return "sqlexec> "
+}
+
+func (c *Client) execute(ctx context.Context, st statement) error {
+	if st.command {
+		return c.runCommand(ctx, st.text)
+	}
+	return c.runSQL(ctx, st)
+}
+
+// runSQL 执行一条 SQL 并输出结果
+func (c *Client) runSQL(ctx context.Context, st statement) error {
+	start := time.Now()
+	result, err := c.conn.Exec(ctx, st.text)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	if _, ok := parseUse(st.text); ok {
+		c.completer.invalidate()
+		c.notef("Database changed\n")
+		return nil
+	}
+	if changesSchema(st.text) {
+		c.completer.invalidate()
+	}
+
+	if !result.HasRows {
+		c.summaryf(elapsed, "Query OK, %d %s affected", result.RowsAffected, plural(result.RowsAffected, "row"))
+		return nil
+	}
+	format := c.format
+	if format == FormatTable && (st.vertical || c.expanded) {
+		format = FormatVertical
+	}
+	if err := WriteResult(c.out, format, result); err != nil {
+		return err
+	}
+	if len(result.Rows) == 0 {
+		c.summaryf(elapsed, "Empty set")
+	} else {
+		n := int64(len(result.Rows))
+		c.summaryf(elapsed, "%d %s in set", n, plural(n, "row"))
+	}
+	return nil
+}
+
+// changesSchema 判断语句是否可能改变表结构，需要刷新补全缓存
+func changesSchema(sql string) bool {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "CREATE", "DROP", "ALTER", "RENAME":
+		return true
+	}
+	return false
+}
+
+// notef 输出只在交互模式下显示的提示
+func (c *Client) notef(format string, args ...interface{}) {
+	if c.interactive {
+		fmt.Fprintf(c.out, format, args...)
+	}
+}
+
+// summaryf 输出语句摘要：交互模式下总是输出，脚本模式下仅在开启计时时输出
+func (c *Client) summaryf(elapsed time.Duration, format string, args ...interface{}) {
+	if !c.interactive && !c.timing {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if c.interactive && !c.timing {
+		fmt.Fprintf(c.out, "%s\n\n", msg)
+		return
+	}
+	fmt.Fprintf(c.out, "%s (%.3f sec)\n\n", msg, elapsed.Seconds())
+}
+
+func (c *Client) printError(err error) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(c.errOut, "ERROR: query aborted")
+		return
+	}
+	fmt.Fprintf(c.errOut, "ERROR: %v\n", err)
+}
+
+func plural(n int64, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
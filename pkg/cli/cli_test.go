@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLocalClient(t *testing.T, opts Options) (*Client, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	conn, err := OpenLocal("")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	var out, errOut bytes.Buffer
+	opts.Out, opts.Err = &out, &errOut
+	return NewClient(conn, opts), &out, &errOut
+}
+
+func TestRunScript(t *testing.T) {
+	client, out, errOut := newLocalClient(t, Options{Format: FormatCSV})
+	script := `
+CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(32));
+INSERT INTO users (id, name) VALUES (1, 'alice'), (2, NULL);
+-- comment only
+SELECT id, name FROM users ORDER BY id;
+SELECT COUNT(*) AS n FROM users`
+	require.NoError(t, client.RunScript(context.Background(), strings.NewReader(script)))
+	assert.Empty(t, errOut.String())
+	assert.Equal(t, "id,name\n1,alice\n2,\nn\n2\n", out.String())
+}
+
+func TestRunScript_Errors(t *testing.T) {
+	script := "SELECT * FROM missing;\nSELECT 1 AS one;\n"
+
+	client, out, errOut := newLocalClient(t, Options{Format: FormatCSV})
+	assert.Error(t, client.RunScript(context.Background(), strings.NewReader(script)))
+	assert.Contains(t, errOut.String(), "ERROR:")
+	assert.Empty(t, out.String())
+
+	// --force 继续执行后续语句，但仍返回错误
+	client, out, _ = newLocalClient(t, Options{Format: FormatCSV, Force: true})
+	assert.Error(t, client.RunScript(context.Background(), strings.NewReader(script)))
+	assert.Equal(t, "one\n1\n", out.String())
+}
+
+func TestRunScript_Commands(t *testing.T) {
+	client, out, errOut := newLocalClient(t, Options{Format: FormatCSV})
+	script := `CREATE TABLE items (id INT PRIMARY KEY, label VARCHAR(16));
+\d
+\di items
+\f json
+SELECT 1 AS one;
+\q
+SELECT 2 AS two;`
+	require.NoError(t, client.RunScript(context.Background(), strings.NewReader(script)))
+	assert.Empty(t, errOut.String())
+	assert.Contains(t, out.String(), "items")
+	assert.Contains(t, out.String(), "PRIMARY,id")
+	assert.Contains(t, out.String(), `{"one": 1}`)
+	assert.NotContains(t, out.String(), "two")
+}
+
+func TestLocalConn_Use(t *testing.T) {
+	conn, err := OpenLocal("")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	_, err = conn.Exec(ctx, "USE information_schema")
+	require.NoError(t, err)
+	assert.Equal(t, "information_schema", conn.Database())
+
+	assert.Error(t, conn.Use(ctx, "nope"))
+	assert.Equal(t, "information_schema", conn.Database())
+}
+
+func TestLocalConn_Clone(t *testing.T) {
+	conn, err := OpenLocal("")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	_, err = conn.Exec(ctx, "CREATE TABLE shared (id INT PRIMARY KEY)")
+	require.NoError(t, err)
+
+	clone, err := conn.Clone(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, conn.Database(), clone.Database())
+	_, err = clone.Exec(ctx, "INSERT INTO shared (id) VALUES (1)")
+	require.NoError(t, err)
+	require.NoError(t, clone.Close())
+
+	// 关闭克隆的会话不影响原连接
+	result, err := conn.Exec(ctx, "SELECT id FROM shared")
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+}
+
+func TestLocalConn_Lint(t *testing.T) {
+	conn, err := OpenLocal("")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	_, err = conn.Exec(ctx, "CREATE TABLE users (id INT PRIMARY KEY)")
+	require.NoError(t, err)
+
+	linter, ok := conn.(Linter)
+	require.True(t, ok)
+	reports, err := linter.Lint("SELECT name FROM users; DELETE FROM users")
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.True(t, reports[0].HasErrors())
+	assert.Len(t, reports[1].Issues, 1)
+
+	// 只检查，不执行
+	_, err = conn.Exec(ctx, "INSERT INTO users (id) VALUES (1)")
+	require.NoError(t, err)
+	_, err = linter.Lint("DELETE FROM users")
+	require.NoError(t, err)
+	result, err := conn.Exec(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+}
+
+func TestCompleter(t *testing.T) {
+	client, _, _ := newLocalClient(t, Options{})
+	ctx := context.Background()
+	_, err := client.conn.Exec(ctx, "CREATE TABLE orders (id INT PRIMARY KEY, customer VARCHAR(32))")
+	require.NoError(t, err)
+	c := client.completer
+
+	complete := func(line string) []string {
+		candidates, _ := c.Complete(ctx, line, len(line))
+		return candidates
+	}
+	assert.Equal(t, []string{"orders"}, complete("SELECT * FROM or"))
+	assert.Equal(t, []string{"select"}, complete("sel"))
+	assert.Contains(t, complete("SELECT cu"), "customer")
+	assert.Equal(t, []string{"orders.customer"}, complete("SELECT orders.c"))
+	assert.Contains(t, complete("USE inf"), "information_schema")
+	assert.Equal(t, []string{"\\di"}, complete("\\di"))
+	assert.Equal(t, []string{"json"}, complete("\\f j"))
+
+	candidates, start := c.Complete(ctx, "SELECT * FROM or WHERE", len("SELECT * FROM or"))
+	assert.Equal(t, []string{"orders"}, candidates)
+	assert.Equal(t, len("SELECT * FROM "), start)
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+// errQuit \q 命令结束会话
+var errQuit = errors.New("quit")
+
+// command 客户端反斜杠命令
+type command struct {
+	name string
+	args string
+	help string
+	run  func(c *Client, ctx context.Context, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"\\?", "", "show this help", (*Client).cmdHelp},
+		{"\\h", "", "show this help", (*Client).cmdHelp},
+		{"\\q", "", "quit", func(*Client, context.Context, []string) error { return errQuit }},
+		{"\\l", "", "list databases", func(c *Client, ctx context.Context, _ []string) error {
+			return c.runSQL(ctx, statement{text: "SHOW DATABASES"})
+		}},
+		{"\\d", "[TABLE]", "list tables, or describe TABLE", (*Client).cmdDescribe},
+		{"\\di", "TABLE", "list key columns (primary, unique, foreign) of TABLE", (*Client).cmdKeys},
+		{"\\c", "DATABASE", "switch database", (*Client).cmdUse},
+		{"\\u", "DATABASE", "switch database", (*Client).cmdUse},
+		{"\\f", "[FORMAT]", "show or set output format (table, csv, json, vertical)", (*Client).cmdFormat},
+		{"\\x", "", "toggle vertical output", func(c *Client, _ context.Context, _ []string) error {
+			c.expanded = !c.expanded
+			c.notef("Expanded display is %s.\n", onOff(c.expanded))
+			return nil
+		}},
+		{"\\timing", "", "toggle statement timing", func(c *Client, _ context.Context, _ []string) error {
+			c.timing = !c.timing
+			c.notef("Timing is %s.\n", onOff(c.timing))
+			return nil
+		}},
+	}
+}
+
+func commandNames() []string {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	return names
+}
+
+// runCommand 执行一条反斜杠命令
+func (c *Client) runCommand(ctx context.Context, text string) error {
+	fields := strings.Fields(text)
+	for _, cmd := range commands {
+		if cmd.name == fields[0] {
+			return cmd.run(c, ctx, fields[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %s, type \\? for help", fields[0])
+}
+
+func (c *Client) cmdHelp(context.Context, []string) error {
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-18s %s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.help)
+	}
+	b.WriteString("\nEnd statements with ; or \\G (vertical output).\n")
+	_, err := fmt.Fprint(c.out, b.String())
+	return err
+}
+
+func (c *Client) cmdDescribe(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return c.runSQL(ctx, statement{text: "SHOW TABLES"})
+	}
+	return c.runSQL(ctx, statement{text: "SHOW COLUMNS FROM " + quoteName(args[0])})
+}
+
+// cmdKeys 从 information_schema.KEY_COLUMN_USAGE 列出表的键列
+func (c *Client) cmdKeys(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: \\di TABLE")
+	}
+	schema, table := c.conn.Database(), strings.Trim(args[0], "`")
+	if dot := strings.IndexByte(table, '.'); dot >= 0 {
+		schema, table = strings.Trim(table[:dot], "`"), strings.Trim(table[dot+1:], "`")
+	}
+	return c.runSQL(ctx, statement{text: fmt.Sprintf(
+		"SELECT constraint_name, column_name, ordinal_position, referenced_table_name, referenced_column_name "+
+			"FROM information_schema.key_column_usage WHERE table_schema = %s AND table_name = %s",
+		sqlString(schema), sqlString(table))})
+}
+
+func (c *Client) cmdUse(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: \\c DATABASE")
+	}
+	if err := c.conn.Use(ctx, strings.Trim(args[0], "`")); err != nil {
+		return err
+	}
+	c.completer.invalidate()
+	c.notef("Database changed\n")
+	return nil
+}
+
+func (c *Client) cmdFormat(_ context.Context, args []string) error {
+	if len(args) == 0 {
+		c.notef("Output format is %s.\n", c.format)
+		return nil
+	}
+	format, err := ParseFormat(args[0])
+	if err != nil {
+		return err
+	}
+	c.format = format
+	return nil
+}
+
+// quoteName 引用 db.table 形式的表名
+func quoteName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(p, "`")
+	}
+	return security.QuoteQualifiedIdentifier(parts...)
+}
+
+// sqlString 把值写成 SQL 字符串字面量
+func sqlString(s string) string {
+	return "'" + strings.NewReplacer("\\", "\\\\", "'", "''").Replace(s) + "'"
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
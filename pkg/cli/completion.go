@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sqlKeywords Tab 补全使用的关键字
+var sqlKeywords = []string{
+	"ADD", "ALL", "ALTER", "ANALYZE", "AND", "AS", "ASC", "BEGIN", "BETWEEN", "BY", "CASE", "CHECK",
+	"CHECKSUM", "COLUMN", "COLUMNS", "COMMIT", "COUNT", "CREATE", "DATABASE", "DATABASES", "DEFAULT",
+	"DELETE", "DESC", "DESCRIBE", "DISTINCT", "DROP", "ELSE", "END", "EXISTS", "EXPLAIN", "FALSE",
+	"FROM", "FULL", "GRANT", "GROUP", "HAVING", "IN", "INDEX", "INNER", "INSERT", "INTO", "IS", "JOIN",
+	"KEY", "LEFT", "LIKE", "LIMIT", "NOT", "NULL", "OFFSET", "ON", "OR", "ORDER", "OUTER", "PRIMARY",
+	"PROCESSLIST", "REPLACE", "REVOKE", "RIGHT", "ROLLBACK", "SELECT", "SET", "SHOW", "STATUS",
+	"TABLE", "TABLES", "THEN", "TRUE", "TRUNCATE", "UNION", "UNIQUE", "UPDATE", "USE", "USING",
+	"VALUES", "VARIABLES", "VIEW", "WHEN", "WHERE", "WITH",
+}
+
+// tableKeywords 其后跟表名的关键字
+var tableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "TABLE": true,
+	"DESCRIBE": true, "DESC": true, "TRUNCATE": true,
+}
+
+// completer 根据 information_schema 补全表名、列名与数据库名
+type completer struct {
+	conn      Conn
+	databases []string
+	tables    []string
+	columns   map[string][]string // 表名 -> 列名
+	loaded    bool
+}
+
+func newCompleter(conn Conn) *completer {
+	return &completer{conn: conn}
+}
+
+// invalidate 当前数据库或表结构变化后重新加载
+func (c *completer) invalidate() {
+	c.loaded = false
+}
+
+// load 从 information_schema 读取当前数据库的表与列；失败时只补全关键字
+func (c *completer) load(ctx context.Context) {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.databases, c.tables, c.columns = nil, nil, map[string][]string{}
+
+	if r, err := c.conn.Exec(ctx, "SELECT schema_name FROM information_schema.schemata"); err == nil {
+		c.databases = firstColumn(r)
+	}
+	db := c.conn.Database()
+	if db == "" {
+		return
+	}
+	if r, err := c.conn.Exec(ctx, fmt.Sprintf(
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = %s", sqlString(db))); err == nil {
+		c.tables = firstColumn(r)
+	}
+	if r, err := c.conn.Exec(ctx, fmt.Sprintf(
+		"SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = %s", sqlString(db))); err == nil {
+		for _, row := range r.Rows {
+			if len(row) < 2 {
+				continue
+			}
+			table, _ := formatValue(row[0])
+			column, _ := formatValue(row[1])
+			c.columns[table] = append(c.columns[table], column)
+		}
+	}
+}
+
+func firstColumn(r *Result) []string {
+	values := make([]string, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		if len(row) > 0 {
+			if s, ok := formatValue(row[0]); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// Complete 返回光标前单词的候选补全，以及该单词在 line 中的起始位置
+func (c *completer) Complete(ctx context.Context, line string, pos int) ([]string, int) {
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " \t\n(,=") + 1
+	word := head[start:]
+	fields := strings.Fields(head[:start])
+
+	// 反斜杠命令
+	if len(fields) == 0 && strings.HasPrefix(word, "\\") {
+		return matchPrefix(commandNames(), word, false), start
+	}
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "\\") {
+		switch fields[0] {
+		case "\\f":
+			return matchPrefix([]string{string(FormatTable), string(FormatCSV), string(FormatJSON), string(FormatVertical)}, word, false), start
+		case "\\c", "\\u":
+			c.load(ctx)
+			return matchPrefix(c.databases, word, false), start
+		case "\\d", "\\di":
+			c.load(ctx)
+			return matchPrefix(c.tables, word, false), start
+		}
+		return nil, start
+	}
+
+	c.load(ctx)
+	prev := ""
+	if len(fields) > 0 {
+		prev = strings.ToUpper(strings.Trim(fields[len(fields)-1], "`"))
+	}
+	switch {
+	case prev == "USE":
+		return matchPrefix(c.databases, word, false), start
+	case tableKeywords[prev]:
+		return matchPrefix(c.tables, word, false), start
+	}
+
+	// 表名.列名
+	if dot := strings.LastIndexByte(word, '.'); dot > 0 {
+		table := strings.Trim(word[:dot], "`")
+		var candidates []string
+		for _, col := range c.columns[table] {
+			candidates = append(candidates, word[:dot+1]+col)
+		}
+		return matchPrefix(candidates, word, false), start
+	}
+
+	candidates := matchPrefix(sqlKeywords, word, true)
+	candidates = append(candidates, matchPrefix(c.tables, word, false)...)
+	var columns []string
+	for _, cols := range c.columns {
+		columns = append(columns, cols...)
+	}
+	candidates = append(candidates, matchPrefix(columns, word, false)...)
+	return dedupe(candidates), start
+}
+
+// matchPrefix 返回以 prefix 开头（不区分大小写）的候选；keyword 为 true 时按 prefix 的大小写输出
+func matchPrefix(candidates []string, prefix string, keyword bool) []string {
+	if prefix == "" && keyword {
+		return nil
+	}
+	lower := keyword && prefix != strings.ToUpper(prefix)
+	var matches []string
+	for _, cand := range candidates {
+		if len(cand) >= len(prefix) && strings.EqualFold(cand[:len(prefix)], prefix) {
+			if lower {
+				cand = strings.ToLower(cand)
+			}
+			matches = append(matches, cand)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+// Result 一条语句的执行结果
+type Result struct {
+	Columns      []string
+	Rows         [][]interface{}
+	HasRows      bool  // 是否返回结果集（SELECT、SHOW 等）
+	RowsAffected int64 // 不返回结果集的语句影响的行数
+}
+
+// Conn CLI 使用的连接：MySQL 协议连接或进程内数据库
+type Conn interface {
+	// Exec 执行一条语句
+	Exec(ctx context.Context, sql string) (*Result, error)
+	// Use 切换当前数据库
+	Use(ctx context.Context, database string) error
+	// Database 返回当前数据库
+	Database() string
+	// Clone 打开访问同一服务器或进程内数据库的新会话，当前数据库与原会话相同
+	Clone(ctx context.Context) (Conn, error)
+	Close() error
+}
+
+// Linter 可以只检查语句而不执行的连接，进程内数据库实现此接口
+type Linter interface {
+	Lint(sql string) ([]*api.LintReport, error)
+}
+
+// useRe 匹配 USE 语句，连接据此记录当前数据库
+var useRe = regexp.MustCompile("(?is)^\\s*USE\\s+`?([^`;\\s]+)`?\\s*;?\\s*$")
+
+// parseUse 返回 USE 语句切换到的数据库
+func parseUse(sql string) (string, bool) {
+	m := useRe.FindStringSubmatch(sql)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// rowKeywords 返回结果集的语句的首个关键字
+var rowKeywords = map[string]bool{
+	"SELECT": true, "SHOW": true, "DESC": true, "DESCRIBE": true, "EXPLAIN": true, "WITH": true,
+	"VALUES": true, "TABLE": true, "CHECK": true, "CHECKSUM": true, "ANALYZE": true, "HELP": true,
+}
+
+// returnsRows 判断语句是否返回结果集
+func returnsRows(sql string) bool {
+	s := strings.TrimLeft(sql, " \t\r\n(")
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end >= 0 {
+		s = s[:end]
+	}
+	return rowKeywords[strings.ToUpper(s)]
+}
+
+// MySQLOptions MySQL 协议连接参数
+type MySQLOptions struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	Timeout  time.Duration
+}
+
+// mysqlConn 通过 MySQL 协议连接 sqlexec 服务器
+type mysqlConn struct {
+	db       *sql.DB
+	conn     *sql.Conn // 固定一个连接，USE 与会话变量才能在语句间保持
+	database string
+	shared   bool // Clone 得到的连接不关闭共享的 db
+}
+
+// DialMySQL 通过 MySQL 协议连接服务器
+func DialMySQL(ctx context.Context, opts MySQLOptions) (Conn, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = opts.User
+	cfg.Passwd = opts.Password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s:%d", opts.Host, opts.Port)
+	cfg.DBName = opts.Database
+	cfg.AllowNativePasswords = true
+	cfg.Timeout = opts.Timeout
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := &mysqlConn{db: sql.OpenDB(connector), database: opts.Database}
+	if err := c.reconnect(ctx); err != nil {
+		c.db.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// reconnect 取一个新连接并恢复当前数据库，语句被取消后旧连接不可再用
+func (c *mysqlConn) reconnect(ctx context.Context) error {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	if c.database != "" {
+		if _, err := conn.ExecContext(ctx, "USE "+security.QuoteIdentifier(c.database)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *mysqlConn) Exec(ctx context.Context, query string) (*Result, error) {
+	if c.conn == nil {
+		if err := c.reconnect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	result, err := c.exec(ctx, query)
+	if err != nil && ctx.Err() != nil {
+		// 取消语句会关闭连接，下次执行时重新连接
+		c.conn.Close()
+		c.conn = nil
+		return nil, ctx.Err()
+	}
+	if err == nil {
+		if db, ok := parseUse(query); ok {
+			c.database = db
+		}
+	}
+	return result, err
+}
+
+func (c *mysqlConn) exec(ctx context.Context, query string) (*Result, error) {
+	if !returnsRows(query) {
+		res, err := c.conn.ExecContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		affected, _ := res.RowsAffected()
+		return &Result{RowsAffected: affected}, nil
+	}
+
+	rows, err := c.conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Columns: columns, HasRows: true}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, rows.Err()
+}
+
+func (c *mysqlConn) Use(ctx context.Context, database string) error {
+	_, err := c.Exec(ctx, "USE "+security.QuoteIdentifier(database))
+	return err
+}
+
+func (c *mysqlConn) Database() string {
+	return c.database
+}
+
+func (c *mysqlConn) Clone(ctx context.Context) (Conn, error) {
+	clone := &mysqlConn{db: c.db, database: c.database, shared: true}
+	if err := clone.reconnect(ctx); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+func (c *mysqlConn) Close() error {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	if c.shared {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// localConn 进程内数据库，只有一个可写的内存数据源 default
+type localConn struct {
+	db      *api.DB
+	session *api.Session
+	shared  bool // Clone 得到的会话不关闭共享的 db
+}
+
+// OpenLocal 创建进程内数据库，database 为空时使用 default
+func OpenLocal(database string) (Conn, error) {
+	db, err := api.NewDB(&api.DBConfig{
+		CacheEnabled:  false,
+		DefaultLogger: api.NewDefaultLogger(api.LogError),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "default",
+		Writable: true,
+	})
+	if err := ds.Connect(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := db.RegisterDataSource("default", ds); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	c := &localConn{db: db, session: db.Session()}
+	if database != "" {
+		c.session.SetCurrentDB(database)
+	}
+	return c, nil
+}
+
+func (c *localConn) Exec(ctx context.Context, query string) (*Result, error) {
+	if db, ok := parseUse(query); ok {
+		return &Result{}, c.Use(ctx, db)
+	}
+
+	q, err := c.session.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	if exec := q.ExecResult(); exec != nil {
+		return &Result{RowsAffected: exec.RowsAffected}, nil
+	}
+	columns := q.Columns()
+	if len(columns) == 0 && !returnsRows(query) {
+		return &Result{}, nil
+	}
+	result := &Result{Columns: make([]string, len(columns)), HasRows: true}
+	for i, col := range columns {
+		result.Columns[i] = col.Name
+	}
+	for q.Next() {
+		row := q.Row()
+		values := make([]interface{}, len(columns))
+		for i, col := range columns {
+			values[i] = row[col.Name]
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, q.Err()
+}
+
+func (c *localConn) Use(ctx context.Context, database string) error {
+	if _, err := c.db.GetDataSource(database); err != nil && !strings.EqualFold(database, "information_schema") {
+		return fmt.Errorf("unknown database '%s'", database)
+	}
+	c.session.SetCurrentDB(database)
+	return nil
+}
+
+func (c *localConn) Database() string {
+	return c.session.GetCurrentDB()
+}
+
+func (c *localConn) Lint(sql string) ([]*api.LintReport, error) {
+	return c.session.Lint(sql)
+}
+
+func (c *localConn) Clone(ctx context.Context) (Conn, error) {
+	clone := &localConn{db: c.db, session: c.db.Session(), shared: true}
+	clone.session.SetCurrentDB(c.session.GetCurrentDB())
+	return clone, nil
+}
+
+func (c *localConn) Close() error {
+	c.session.Close()
+	if c.shared {
+		return nil
+	}
+	return c.db.Close()
+}
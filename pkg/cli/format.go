@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format 结果输出格式
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatCSV      Format = "csv"
+	FormatJSON     Format = "json"
+	FormatVertical Format = "vertical"
+)
+
+// ParseFormat 解析输出格式名
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatTable, FormatCSV, FormatJSON, FormatVertical:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q (table, csv, json, vertical)", name)
+}
+
+// WriteResult 按格式输出结果集
+func WriteResult(w io.Writer, format Format, r *Result) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, r)
+	case FormatJSON:
+		return writeJSON(w, r)
+	case FormatVertical:
+		return writeVertical(w, r)
+	default:
+		return writeTable(w, r)
+	}
+}
+
+// formatValue 把值转成显示文本，NULL 返回 ok=false
+func formatValue(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return string(val), true
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format("2006-01-02"), true
+		}
+		return val.Format("2006-01-02 15:04:05"), true
+	default:
+		return fmt.Sprint(val), true
+	}
+}
+
+// displayValue 表格与纵向格式中的单元格文本
+func displayValue(v interface{}) string {
+	if s, ok := formatValue(v); ok {
+		return s
+	}
+	return "NULL"
+}
+
+func writeTable(w io.Writer, r *Result) error {
+	widths := make([]int, len(r.Columns))
+	for i, col := range r.Columns {
+		widths[i] = utf8.RuneCountInString(col)
+	}
+	cells := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		cells[i] = make([]string, len(r.Columns))
+		for j := range r.Columns {
+			var v interface{}
+			if j < len(row) {
+				v = row[j]
+			}
+			s := displayValue(v)
+			cells[i][j] = s
+			if n := utf8.RuneCountInString(s); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	var b strings.Builder
+	border := func() {
+		b.WriteByte('+')
+		for _, width := range widths {
+			b.WriteString(strings.Repeat("-", width+2))
+			b.WriteByte('+')
+		}
+		b.WriteByte('\n')
+	}
+	line := func(values []string) {
+		b.WriteByte('|')
+		for i, s := range values {
+			b.WriteByte(' ')
+			b.WriteString(s)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s)))
+			b.WriteString(" |")
+		}
+		b.WriteByte('\n')
+	}
+
+	border()
+	line(r.Columns)
+	border()
+	for _, row := range cells {
+		line(row)
+	}
+	if len(cells) > 0 {
+		border()
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCSV 首行为列名，NULL 输出为空字段
+func writeCSV(w io.Writer, r *Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(r.Columns))
+	for _, row := range r.Rows {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i], _ = formatValue(row[i])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON 输出对象数组，每行一个对象，键按列顺序排列
+func writeJSON(w io.Writer, r *Result) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range r.Rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j, col := range r.Columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			key, _ := json.Marshal(col)
+			b.Write(key)
+			b.WriteString(": ")
+
+			var v interface{}
+			if j < len(row) {
+				v = row[j]
+			}
+			switch val := v.(type) {
+			case []byte:
+				v = string(val)
+			case time.Time:
+				v, _ = formatValue(val)
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				data, _ = json.Marshal(fmt.Sprint(v))
+			}
+			b.Write(data)
+		}
+		b.WriteString("}")
+	}
+	if len(r.Rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeVertical 每行按 列名: 值 纵向输出，与 mysql 客户端的 \G 相同
+func writeVertical(w io.Writer, r *Result) error {
+	width := 0
+	for _, col := range r.Columns {
+		if n := utf8.RuneCountInString(col); n > width {
+			width = n
+		}
+	}
+	var b strings.Builder
+	for i, row := range r.Rows {
+		fmt.Fprintf(&b, "*************************** %d. row ***************************\n", i+1)
+		for j, col := range r.Columns {
+			var v interface{}
+			if j < len(row) {
+				v = row[j]
+			}
+			b.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(col)))
+			b.WriteString(col)
+			b.WriteString(": ")
+			b.WriteString(displayValue(v))
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResult() *Result {
+	return &Result{
+		Columns: []string{"id", "name"},
+		Rows:    [][]interface{}{{int64(1), "a,b"}, {int64(2), nil}},
+		HasRows: true,
+	}
+}
+
+func TestWriteResult_Table(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteResult(&buf, FormatTable, testResult()))
+	assert.Equal(t, ""+
+		"+----+------+\n"+
+		"| id | name |\n"+
+		"+----+------+\n"+
+		"| 1  | a,b  |\n"+
+		"| 2  | NULL |\n"+
+		"+----+------+\n", buf.String())
+}
+
+func TestWriteResult_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteResult(&buf, FormatCSV, testResult()))
+	assert.Equal(t, "id,name\n1,\"a,b\"\n2,\n", buf.String())
+}
+
+func TestWriteResult_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteResult(&buf, FormatJSON, testResult()))
+	assert.Equal(t, "[\n  {\"id\": 1, \"name\": \"a,b\"},\n  {\"id\": 2, \"name\": null}\n]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteResult(&buf, FormatJSON, &Result{Columns: []string{"id"}, HasRows: true}))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestWriteResult_Vertical(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteResult(&buf, FormatVertical, testResult()))
+	assert.Equal(t, ""+
+		"*************************** 1. row ***************************\n"+
+		"  id: 1\n"+
+		"name: a,b\n"+
+		"*************************** 2. row ***************************\n"+
+		"  id: 2\n"+
+		"name: NULL\n", buf.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package cli
+
+import "strings"
+
+// statement 一条待执行的输入
+type statement struct {
+	text     string
+	command  bool // 反斜杠命令（\d、\l 等），不发送给服务器
+	vertical bool // 以 \G 结尾，纵向输出
+}
+
+// splitter 把逐行输入切分成语句：语句以引号和注释之外的 ; 或 \G 结束，
+// 语句之间以反斜杠开头的部分是客户端命令，到 ; 或行尾结束
+type splitter struct {
+	buf strings.Builder
+}
+
+// Feed 追加一行输入，返回其中已完整的语句
+func (s *splitter) Feed(line string) []statement {
+	text := s.buf.String() + line + "\n"
+	s.buf.Reset()
+
+	var stmts []statement
+	for strings.TrimSpace(text) != "" {
+		if trimmed := strings.TrimLeft(text, " \t\r\n"); strings.HasPrefix(trimmed, "\\") && !isTerminator(trimmed) {
+			end := strings.IndexAny(trimmed, ";\n")
+			stmts = append(stmts, statement{text: strings.TrimSpace(trimmed[:end]), command: true})
+			text = trimmed[end+1:]
+			continue
+		}
+
+		end, vertical, _ := scanInput(text)
+		if end < 0 {
+			s.buf.WriteString(text)
+			break
+		}
+		if sql := strings.TrimSpace(text[:end]); sql != "" && !onlyComments(sql) {
+			stmts = append(stmts, statement{text: sql, vertical: vertical})
+		}
+		if text[end] == ';' {
+			text = text[end+1:]
+		} else {
+			text = text[end+2:]
+		}
+	}
+	return stmts
+}
+
+// Flush 返回缓冲区中剩余的未结束语句（脚本末尾允许省略分号）
+func (s *splitter) Flush() []statement {
+	sql := strings.TrimSpace(s.buf.String())
+	s.buf.Reset()
+	if sql == "" || onlyComments(sql) {
+		return nil
+	}
+	return []statement{{text: sql}}
+}
+
+// Pending 缓冲区中是否有未结束的语句
+func (s *splitter) Pending() bool {
+	return s.buf.Len() > 0
+}
+
+// Reset 丢弃未结束的语句
+func (s *splitter) Reset() {
+	s.buf.Reset()
+}
+
+// Continuation 返回续行提示符：在引号或块注释中时提示对应的结束符号
+func (s *splitter) Continuation() string {
+	switch _, _, open := scanInput(s.buf.String()); open {
+	case 0:
+		return "    -> "
+	case '*':
+		return "   /*> "
+	default:
+		return "    " + string(open) + "> "
+	}
+}
+
+// isTerminator 判断文本是否以 \G 或 \g 开头，它们结束语句而不是客户端命令
+func isTerminator(s string) bool {
+	return strings.HasPrefix(s, "\\G") || strings.HasPrefix(s, "\\g")
+}
+
+// scanInput 返回 text 中第一个引号与注释之外的 ; 或 \G（\g）的位置，没有时返回 -1；
+// open 为结尾处未闭合的引号字符，块注释未闭合时为 '*'
+func scanInput(text string) (end int, vertical bool, open byte) {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == ';':
+			return i, false, 0
+		case c == '\\' && i+1 < len(text) && (text[i+1] == 'G' || text[i+1] == 'g'):
+			return i, text[i+1] == 'G', 0
+		case c == '\'' || c == '"' || c == '`':
+			end := closeQuote(text, i)
+			if end >= len(text) {
+				return -1, false, c
+			}
+			i = end
+		case c == '#' || (c == '-' && i+1 < len(text) && text[i+1] == '-' &&
+			(i+2 >= len(text) || text[i+2] == ' ' || text[i+2] == '\t' || text[i+2] == '\n' || text[i+2] == '\r')):
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(text) && text[i+1] == '*':
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return -1, false, '*'
+			}
+			i += end + 3
+		}
+	}
+	return -1, false, 0
+}
+
+// closeQuote 返回 i 处引号的结束位置，未闭合时返回 len(text)
+func closeQuote(text string, i int) int {
+	quote := text[i]
+	for i++; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(text) && text[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(text)
+}
+
+// onlyComments 判断文本是否只有注释
+func onlyComments(sql string) bool {
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "--")):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*") && !strings.HasPrefix(sql[i:], "/*!"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return true
+			}
+			i += end + 3
+		default:
+			return false
+		}
+	}
+	return true
+}
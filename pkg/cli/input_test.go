@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitter_Feed(t *testing.T) {
+	var sp splitter
+
+	assert.Empty(t, sp.Feed("SELECT 1,"))
+	assert.True(t, sp.Pending())
+	assert.Equal(t, "    -> ", sp.Continuation())
+
+	stmts := sp.Feed("2; SELECT 3\\G")
+	require.Len(t, stmts, 2)
+	assert.Equal(t, "SELECT 1,\n2", stmts[0].text)
+	assert.False(t, stmts[0].vertical)
+	assert.Equal(t, "SELECT 3", stmts[1].text)
+	assert.True(t, stmts[1].vertical)
+	assert.False(t, sp.Pending())
+}
+
+func TestSplitter_QuotesAndComments(t *testing.T) {
+	var sp splitter
+
+	assert.Empty(t, sp.Feed("SELECT 'a;b"))
+	assert.Equal(t, "    '> ", sp.Continuation())
+	stmts := sp.Feed("c' -- trailing; comment")
+	assert.Empty(t, stmts)
+	stmts = sp.Feed("/* ; */ ;")
+	require.Len(t, stmts, 1)
+	assert.Equal(t, "SELECT 'a;b\nc' -- trailing; comment\n/* ; */", stmts[0].text)
+
+	assert.Empty(t, sp.Feed("/* open"))
+	assert.Equal(t, "   /*> ", sp.Continuation())
+	sp.Reset()
+
+	// 只有注释的片段不是语句
+	assert.Empty(t, sp.Feed("-- nothing here;"))
+	assert.Empty(t, sp.Feed("# nor here"))
+	assert.Empty(t, sp.Flush())
+}
+
+func TestSplitter_Commands(t *testing.T) {
+	var sp splitter
+
+	stmts := sp.Feed("  \\d users;")
+	require.Len(t, stmts, 1)
+	assert.True(t, stmts[0].command)
+	assert.Equal(t, "\\d users", stmts[0].text)
+
+	stmts = sp.Feed("SELECT 1; \\di users; SELECT 2;")
+	require.Len(t, stmts, 3)
+	assert.Equal(t, "SELECT 1", stmts[0].text)
+	assert.Equal(t, statement{text: "\\di users", command: true}, stmts[1])
+	assert.Equal(t, "SELECT 2", stmts[2].text)
+
+	// 语句中间的反斜杠不是命令
+	assert.Empty(t, sp.Feed("SELECT"))
+	stmts = sp.Feed("\\G")
+	require.Len(t, stmts, 1)
+	assert.False(t, stmts[0].command)
+	assert.True(t, stmts[0].vertical)
+}
+
+func TestSplitter_Flush(t *testing.T) {
+	var sp splitter
+	assert.Empty(t, sp.Feed("SELECT 1"))
+	stmts := sp.Flush()
+	require.Len(t, stmts, 1)
+	assert.Equal(t, "SELECT 1", stmts[0].text)
+	assert.False(t, sp.Pending())
+}
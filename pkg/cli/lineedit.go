@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxHistory 历史文件保留的最大行数
+const maxHistory = 1000
+
+// errInterrupt 输入时按下 Ctrl-C
+var errInterrupt = errors.New("interrupt")
+
+// completeFunc 返回 line 在 pos（字节位置）处的补全候选及被替换单词的起始位置
+type completeFunc func(line string, pos int) ([]string, int)
+
+// lineEditor 交互模式的行编辑器：支持历史、光标移动与 Tab 补全；
+// 终端不支持原始模式时退回按行读取
+type lineEditor struct {
+	in          *os.File
+	out         io.Writer
+	reader      *bufio.Reader
+	complete    completeFunc
+	history     []string
+	historyFile string
+}
+
+func newLineEditor(in *os.File, out io.Writer, complete completeFunc, historyFile string) *lineEditor {
+	e := &lineEditor{
+		in:          in,
+		out:         out,
+		reader:      bufio.NewReader(in),
+		complete:    complete,
+		historyFile: historyFile,
+	}
+	e.loadHistory()
+	return e
+}
+
+func (e *lineEditor) loadHistory() {
+	if e.historyFile == "" {
+		return
+	}
+	data, err := os.ReadFile(e.historyFile)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// AddHistory 记录一行输入，与上一条相同时忽略
+func (e *lineEditor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// SaveHistory 写回历史文件
+func (e *lineEditor) SaveHistory() error {
+	if e.historyFile == "" {
+		return nil
+	}
+	return os.WriteFile(e.historyFile, []byte(strings.Join(e.history, "\n")+"\n"), 0600)
+}
+
+// ReadLine 读取一行输入；Ctrl-C 返回 errInterrupt，空行上的 Ctrl-D 返回 io.EOF
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(int(e.in.Fd()))
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore()
+	return e.edit(prompt)
+}
+
+func (e *lineEditor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// lineState 正在编辑的一行
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func (e *lineEditor) refresh(s *lineState) {
+	var b strings.Builder
+	b.WriteString("\r")
+	b.WriteString(s.prompt)
+	b.WriteString(string(s.buf))
+	b.WriteString("\x1b[K")
+	if back := len(s.buf) - s.pos; back > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", back)
+	}
+	io.WriteString(e.out, b.String())
+}
+
+func (e *lineEditor) edit(prompt string) (string, error) {
+	s := &lineState{prompt: prompt}
+	histIdx := len(e.history)
+	var pending []rune // 浏览历史前正在编辑的内容
+	e.refresh(s)
+
+	setLine := func(line []rune) {
+		s.buf = append([]rune(nil), line...)
+		s.pos = len(s.buf)
+	}
+
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			io.WriteString(e.out, "\r\n")
+			return string(s.buf), nil
+		case 3: // Ctrl-C
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupt
+		case 4: // Ctrl-D
+			if len(s.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if s.pos < len(s.buf) {
+				s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+			}
+		case 127, 8: // Backspace
+			if s.pos > 0 {
+				s.buf = append(s.buf[:s.pos-1], s.buf[s.pos:]...)
+				s.pos--
+			}
+		case 1: // Ctrl-A
+			s.pos = 0
+		case 5: // Ctrl-E
+			s.pos = len(s.buf)
+		case 2: // Ctrl-B
+			if s.pos > 0 {
+				s.pos--
+			}
+		case 6: // Ctrl-F
+			if s.pos < len(s.buf) {
+				s.pos++
+			}
+		case 11: // Ctrl-K
+			s.buf = s.buf[:s.pos]
+		case 21: // Ctrl-U
+			s.buf = append([]rune(nil), s.buf[s.pos:]...)
+			s.pos = 0
+		case 23: // Ctrl-W
+			start := s.pos
+			for start > 0 && s.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && s.buf[start-1] != ' ' {
+				start--
+			}
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case 12: // Ctrl-L
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case 16, 14: // Ctrl-P, Ctrl-N
+			if r == 16 {
+				histIdx, pending = e.historyPrev(s, histIdx, pending, setLine)
+			} else {
+				histIdx = e.historyNext(histIdx, pending, setLine)
+			}
+		case '\t':
+			e.completeWord(s)
+		case 27: // 转义序列
+			switch e.readEscape() {
+			case "A":
+				histIdx, pending = e.historyPrev(s, histIdx, pending, setLine)
+			case "B":
+				histIdx = e.historyNext(histIdx, pending, setLine)
+			case "C":
+				if s.pos < len(s.buf) {
+					s.pos++
+				}
+			case "D":
+				if s.pos > 0 {
+					s.pos--
+				}
+			case "H", "1~", "7~":
+				s.pos = 0
+			case "F", "4~", "8~":
+				s.pos = len(s.buf)
+			case "3~":
+				if s.pos < len(s.buf) {
+					s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+				}
+			}
+		default:
+			if r < 32 {
+				continue
+			}
+			s.buf = append(s.buf[:s.pos], append([]rune{r}, s.buf[s.pos:]...)...)
+			s.pos++
+		}
+		e.refresh(s)
+	}
+}
+
+// readEscape 读取 ESC 之后的 CSI/SS3 序列，返回去掉前缀的部分（如 "A"、"3~"）
+func (e *lineEditor) readEscape() string {
+	b, err := e.reader.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return ""
+	}
+	var seq []byte
+	for {
+		c, err := e.reader.ReadByte()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, c)
+		if c >= 0x40 && c <= 0x7e {
+			return string(seq)
+		}
+	}
+}
+
+func (e *lineEditor) historyPrev(s *lineState, idx int, pending []rune, set func([]rune)) (int, []rune) {
+	if idx == 0 {
+		return idx, pending
+	}
+	if idx == len(e.history) {
+		pending = append([]rune(nil), s.buf...)
+	}
+	idx--
+	set([]rune(e.history[idx]))
+	return idx, pending
+}
+
+func (e *lineEditor) historyNext(idx int, pending []rune, set func([]rune)) int {
+	if idx >= len(e.history) {
+		return idx
+	}
+	idx++
+	if idx == len(e.history) {
+		set(pending)
+	} else {
+		set([]rune(e.history[idx]))
+	}
+	return idx
+}
+
+// completeWord 补全光标前的单词：唯一候选直接补全，多个候选时补全公共前缀，
+// 没有可补全的前缀则列出全部候选
+func (e *lineEditor) completeWord(s *lineState) {
+	if e.complete == nil {
+		return
+	}
+	line := string(s.buf)
+	bytePos := len(string(s.buf[:s.pos]))
+	candidates, start := e.complete(line, bytePos)
+	if len(candidates) == 0 {
+		return
+	}
+	word := line[start:bytePos]
+
+	replacement := candidates[0]
+	if len(candidates) == 1 {
+		replacement += " "
+	} else {
+		replacement = commonPrefix(candidates)
+		if utf8.RuneCountInString(replacement) <= utf8.RuneCountInString(word) {
+			io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+			return
+		}
+	}
+	head := []rune(line[:start] + replacement)
+	s.buf = append(head, s.buf[s.pos:]...)
+	s.pos = len(head)
+}
+
+// commonPrefix 返回候选的最长公共前缀（不区分大小写比较，保留第一个候选的写法）
+func commonPrefix(values []string) string {
+	prefix := []rune(values[0])
+	for _, v := range values[1:] {
+		r := []rune(v)
+		n := 0
+		for n < len(prefix) && n < len(r) && strings.EqualFold(string(prefix[n]), string(r[n])) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
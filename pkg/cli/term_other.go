@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package cli
+
+import "errors"
+
+// makeRaw 当前平台不支持原始模式，交互模式退回按行读取
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -3,9 +3,22 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
+	"github.com/kasuganosora/sqlexec/pkg/lookup"
+	"github.com/kasuganosora/sqlexec/pkg/objstore"
+	"github.com/kasuganosora/sqlexec/pkg/quota"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/tenant"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
 // Config 应用程序配置
@@ -22,13 +35,65 @@ type Config struct {
 	HTTPAPI    HTTPAPIConfig    `json:"http_api"`
 	MCP        MCPConfig        `json:"mcp"`
 	Paging     PagingConfig     `json:"paging"`
+	Workload   WorkloadConfig   `json:"workload"`
+	SystemDB   SystemDBConfig   `json:"system_db"`
+	Auth       AuthConfig       `json:"auth"`
 }
 
 // HTTPAPIConfig HTTP REST API 配置
 type HTTPAPIConfig struct {
-	Enabled bool   `json:"enabled"`
-	Host    string `json:"host"`
-	Port    int    `json:"port"`
+	Enabled     bool           `json:"enabled"`
+	Host        string         `json:"host"`
+	Port        int            `json:"port"`
+	Auth        HTTPAuthConfig `json:"auth"`
+	CORS        HTTPCORSConfig `json:"cors"`
+	TLS         HTTPTLSConfig  `json:"tls"`
+	MaxBodySize int64          `json:"max_body_size"` // 请求体大小上限（字节）
+	Gzip        bool           `json:"gzip"`          // 客户端支持时 gzip 压缩响应
+	WebUI       bool           `json:"web_ui"`        // 在 /ui/ 提供 Web 管理控制台
+}
+
+// HTTPCORSConfig 浏览器跨域访问策略
+type HTTPCORSConfig struct {
+	AllowedOrigins   []string `json:"allowed_origins"`   // 允许的来源，"*" 表示任意来源，为空时不允许跨域
+	AllowCredentials bool     `json:"allow_credentials"` // 允许携带 Cookie 等凭据
+	MaxAge           int      `json:"max_age"`           // 预检结果的缓存时间（秒）
+}
+
+// HTTPTLSConfig HTTPS 配置，证书和私钥文件都设置时启用，文件变化后自动重新加载
+type HTTPTLSConfig struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// Enabled 是否启用 HTTPS
+func (c HTTPTLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// HTTPAuthConfig HTTP API 认证配置
+type HTTPAuthConfig struct {
+	// AllowUnsignedKeys 允许只携带 API Key（X-API-Key 或 Authorization: Bearer）而不签名的请求，应配合 TLS 使用
+	AllowUnsignedKeys bool      `json:"allow_unsigned_keys"`
+	JWT               JWTConfig `json:"jwt"`
+	// ACLUsers API 客户端名到 ACL 用户的映射，客户端按映射到的用户获得 SUPER 等 ACL 权限；
+	// 未映射的客户端不具有任何 ACL 权限，不会因为与 ACL 用户同名而获得权限
+	ACLUsers map[string]string `json:"acl_users"`
+}
+
+// JWTConfig JWT Bearer 令牌校验配置
+type JWTConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Issuer   string `json:"issuer"`   // 要求的 iss，为空时不校验
+	Audience string `json:"audience"` // 要求 aud 包含的值，为空时不校验
+	JWKSURL  string `json:"jwks_url"` // RS256/ES256 等非对称签名的公钥集地址
+	Secret   string `json:"secret"`   // HS256 等对称签名的共享密钥
+
+	UserClaim  string `json:"user_claim"`  // 作为用户名的声明，默认 sub
+	ScopeClaim string `json:"scope_claim"` // 携带权限范围的声明，默认 scope
+	// ACLUserClaim 携带 ACL 用户名的声明，令牌按该用户获得 SUPER 等 ACL 权限；
+	// 为空时 JWT 用户不具有任何 ACL 权限（不按 user_claim 的值查找 ACL）
+	ACLUserClaim string `json:"acl_user_claim"`
 }
 
 // MCPConfig MCP 协议配置
@@ -45,6 +110,141 @@ type ServerConfig struct {
 	ServerVersion   string        `json:"server_version"`
 	KeepAlivePeriod time.Duration `json:"keep_alive_period"`
 	Debug           *bool         `json:"debug"` // Debug logging switch (default true, set false to disable)
+
+	// 连接准入控制
+	MaxConnections         int           `json:"max_connections"`          // 全局最大连接数，0 表示不限制
+	MaxUserConnections     int           `json:"max_user_connections"`     // 单用户最大连接数，0 表示不限制
+	ConnectionQueueSize    int           `json:"connection_queue_size"`    // 达到上限后的等待队列长度，0 表示直接拒绝
+	ConnectionQueueTimeout time.Duration `json:"connection_queue_timeout"` // 队列中等待空闲连接的超时时间
+
+	// 结果集写出流控
+	NetWriteTimeout   time.Duration `json:"net_write_timeout"`   // 客户端在该时间内未读取结果时断开连接，0 表示不限制
+	NetBufferLength   int           `json:"net_buffer_length"`   // 结果集写出的初始批量字节数
+	NetBufferMax      int           `json:"net_buffer_max"`      // 结果集输出缓冲上限，批量大小随客户端读取速度在两者之间调整
+	NetStallThreshold time.Duration `json:"net_stall_threshold"` // 单次写出阻塞超过该时间计为一次写阻塞
+
+	// ReadOnly 全局只读（read_only），只有具有 SUPER 权限的用户可以写入
+	ReadOnly bool `json:"read_only"`
+
+	// 协议跟踪：把连接收发的原始包记录到 protocol_trace_dir 下，每个连接一个文件
+	ProtocolTrace    bool   `json:"protocol_trace"`     // 所有连接从握手开始跟踪；关闭时可以用 SET SESSION protocol_trace = 1 单独开启
+	ProtocolTraceDir string `json:"protocol_trace_dir"` // 跟踪文件目录，为空时不能开启跟踪
+
+	// NodeID 全局 ID 生成器的节点号（0-63），连接 ID、查询 ID 和变更事件 ID 都带有节点号，
+	// 多个服务器实例的 ID 需要互不相同时为每个实例配置不同的值
+	NodeID int `json:"node_id"`
+
+	// Listeners MySQL 协议的监听地址列表，为空时只监听 host:port
+	Listeners []ListenerConfig `json:"listeners"`
+}
+
+// ListenerConfig MySQL 协议的一个监听地址，每个地址有各自的 TLS、只读和访问控制设置
+type ListenerConfig struct {
+	Network      string         `json:"network"`       // tcp（默认，同时接受 IPv4 和 IPv6）、tcp4、tcp6 或 unix
+	Address      string         `json:"address"`       // host:port，IPv6 地址写作 [::1]:3306；unix 为 socket 文件路径
+	TLS          HTTPTLSConfig  `json:"tls"`           // 证书和私钥，设置后客户端可以在握手时切换到 TLS（启动时加载）
+	RequireTLS   bool           `json:"require_tls"`   // 拒绝未使用 TLS 的客户端，需要配置 tls
+	ReadOnly     bool           `json:"read_only"`     // 该地址上的连接只读，SUPER 权限也不能写入
+	AllowedHosts []string       `json:"allowed_hosts"` // 允许连接的客户端 IP 或网段（CIDR），为空时不限制，对 unix socket 不生效
+	Protocol     ProtocolConfig `json:"protocol"`      // 握手时声明的服务器特征
+}
+
+// ProtocolConfig 握手时向客户端声明的服务器特征，用于兼容只认识特定服务器的旧客户端。
+// 先取 personality 预设，再用其余非空字段覆盖
+type ProtocolConfig struct {
+	Personality    string `json:"personality"`     // mysql-5.7、mysql-8.0 或 mariadb-10.6，为空时使用默认特征
+	ServerVersion  string `json:"server_version"`  // 握手包中的版本字符串
+	CapabilityMask uint32 `json:"capability_mask"` // 与能力标志按位与，去掉客户端不能处理的能力，0 表示不屏蔽
+	Charset        string `json:"charset"`         // 默认字符集排序规则，如 utf8mb4_general_ci
+	AuthPlugin     string `json:"auth_plugin"`     // mysql_native_password 或 caching_sha2_password
+}
+
+// validate 检查预设名称、排序规则和认证插件
+func (c ProtocolConfig) validate() error {
+	switch c.Personality {
+	case "", "mysql-5.7", "mysql-8.0", "mariadb-10.6":
+	default:
+		return fmt.Errorf("无效的协议预设: %s", c.Personality)
+	}
+	if c.Charset != "" && utils.GetCharsetID(c.Charset) == utils.CharsetUtf8mb40900AICi && c.Charset != "utf8mb4_0900_ai_ci" {
+		return fmt.Errorf("无效的字符集排序规则: %s", c.Charset)
+	}
+	switch c.AuthPlugin {
+	case "", "mysql_native_password", "caching_sha2_password":
+	default:
+		return fmt.Errorf("不支持的认证插件: %s", c.AuthPlugin)
+	}
+	return nil
+}
+
+// NetworkOrDefault 返回监听的网络类型，未设置时为 tcp
+func (c ListenerConfig) NetworkOrDefault() string {
+	if c.Network == "" {
+		return "tcp"
+	}
+	return c.Network
+}
+
+// validate 检查网络类型、地址、TLS 和允许的客户端地址
+func (c ListenerConfig) validate() error {
+	switch c.NetworkOrDefault() {
+	case "tcp", "tcp4", "tcp6":
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("无效的监听地址 %q: %w", c.Address, err)
+		}
+	case "unix":
+		if c.Address == "" {
+			return fmt.Errorf("unix socket 的监听地址不能为空")
+		}
+	default:
+		return fmt.Errorf("无效的监听网络类型: %s", c.Network)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("监听地址 %s 的 TLS 需要同时设置 cert_file 和 key_file", c.Address)
+	}
+	if c.RequireTLS && !c.TLS.Enabled() {
+		return fmt.Errorf("监听地址 %s 设置了 require_tls 但没有配置 TLS 证书", c.Address)
+	}
+	if _, err := ParseAllowedHosts(c.AllowedHosts); err != nil {
+		return fmt.Errorf("监听地址 %s 的 allowed_hosts 无效: %w", c.Address, err)
+	}
+	if err := c.Protocol.validate(); err != nil {
+		return fmt.Errorf("监听地址 %s 的 protocol 无效: %w", c.Address, err)
+	}
+	return nil
+}
+
+// ParseAllowedHosts 把 IP 或网段（CIDR）列表转换为网段，单个 IP 视为只包含该地址的网段
+func ParseAllowedHosts(hosts []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(hosts))
+	for _, host := range hosts {
+		if strings.Contains(host, "/") {
+			_, ipNet, err := net.ParseCIDR(host)
+			if err != nil {
+				return nil, err
+			}
+			nets = append(nets, ipNet)
+			continue
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return nil, fmt.Errorf("无效的 IP 地址: %s", host)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return nets, nil
+}
+
+// ListenerConfigs 返回所有监听地址，未配置 listeners 时为 host:port（tcp，同时接受 IPv4 和 IPv6）
+func (c *ServerConfig) ListenerConfigs() []ListenerConfig {
+	if len(c.Listeners) > 0 {
+		return c.Listeners
+	}
+	return []ListenerConfig{{Network: "tcp", Address: net.JoinHostPort(c.Host, strconv.Itoa(c.Port))}}
 }
 
 // IsDebugEnabled returns whether debug logging is enabled (default true)
@@ -55,20 +255,125 @@ func (c *ServerConfig) IsDebugEnabled() bool {
 	return *c.Debug
 }
 
+// AuthConfig MySQL 协议客户端认证配置
+type AuthConfig struct {
+	// VerifyPasswords 握手和 COM_CHANGE_USER 时按 ACL 中的用户校验 mysql_native_password 密码，未知用户和错误密码被拒绝
+	VerifyPasswords bool `json:"verify_passwords"`
+	// PasswordPolicy 创建用户和修改密码时检查的密码策略，以及密码有效期
+	PasswordPolicy security.PasswordPolicy `json:"password_policy"`
+	// Throttle 登录失败后的指数退避和临时锁定，按用户和客户端 IP 统计
+	Throttle security.LoginThrottleConfig `json:"throttle"`
+}
+
+// EncryptionConfig 列加密配置
+type EncryptionConfig struct {
+	// Keys 按密钥 ID 配置密钥来源（env 或 file），密钥为 64 个十六进制字符或 32 字节的 Base64
+	Keys security.KeyRing `json:"keys"`
+	// DecryptUsers 可以读取加密列明文的用户，为空表示所有用户
+	DecryptUsers []string `json:"decrypt_users"`
+}
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
 	MaxConnections int      `json:"max_connections"`
 	IdleTimeout    int      `json:"idle_timeout"`    // seconds
 	EnabledSources []string `json:"enabled_sources"` // 启用的数据源类型，核心版本可以只启用部分
 	DatabaseDir    string   `json:"database_dir"`    // 持久化存储根目录，默认 "./database"
+
+	// WritePolicies 按数据库（数据源或虚拟数据库名）设置写入策略：full、ddl_only 或 read_only
+	WritePolicies map[string]string `json:"write_policies"`
+
+	// OutfileDirs SELECT ... INTO OUTFILE 可以写入的目录，为空时禁止导出到文件
+	OutfileDirs []string `json:"outfile_dirs"`
+
+	// Quotas 按表和按数据库的写入配额（行数、数据量），可以按用户分别设置
+	Quotas []quota.Quota `json:"quotas"`
+
+	// Tenancy 多租户隔离：把用户映射到租户 ID，自动为按租户隔离的表上的语句加上租户条件
+	Tenancy *tenant.Config `json:"tenancy"`
+
+	// Encryption 加密列（ENCRYPTED）的密钥和可以读取明文的用户
+	Encryption *EncryptionConfig `json:"encryption"`
+
+	// BackupS3 BACKUP DATABASE ... TO 's3://bucket/key' 使用的 S3 连接配置，为空的字段从 AWS_* 环境变量读取
+	BackupS3 *objstore.S3Config `json:"backup_s3"`
+
+	// HistoryRetention 历史版本的保留时间（如 "24h"），用于 SELECT ... FOR SYSTEM_TIME AS OF 时间点查询。
+	// 键为数据源名（数据源内所有表）或 数据源名.表名
+	HistoryRetention map[string]string `json:"history_retention"`
+
+	// RecycleBinRetention 被删除的表在回收站中的保留时间（如 "24h"），保留期内可以用 UNDROP TABLE 恢复，为空时不保留
+	RecycleBinRetention string `json:"recycle_bin_retention"`
+
+	// ChangeLogSize 变更日志保留的最近行变更数，FLASHBACK TABLE 只能撤销仍在日志中的变更，0 时不保留
+	ChangeLogSize int `json:"change_log_size"`
+
+	// ChangeArchiveDir 定期归档变更日志的目录，增量备份需要的变更已不在变更日志中时从这里读取，为空时不归档
+	ChangeArchiveDir string `json:"change_archive_dir"`
+	// ChangeArchiveInterval 归档变更日志的间隔（如 "30s"），默认 "1m"，变更日志需要保留一个间隔内的全部变更
+	ChangeArchiveInterval string `json:"change_archive_interval"`
+
+	// IdempotencyTTL 写入语句幂等键（/* idempotency_key=... */）的保留时间（如 "10m"），
+	// 保留期内重复提交的语句返回第一次执行的结果，为空时不启用
+	IdempotencyTTL string `json:"idempotency_ttl"`
+
+	// Failover 按数据库（数据源名）设置后端连接中断时 SELECT 的重试与故障切换
+	Failover map[string]FailoverConfig `json:"failover"`
+
+	// HealthCheck 数据源存活探测与隔离
+	HealthCheck HealthCheckConfig `json:"health_check"`
+
+	// PluginWatchInterval 检查 datasource/ 插件目录变化的间隔（如 "5s"），新增的插件自动加载，删除的插件自动卸载；"0s" 表示只在启动时加载
+	PluginWatchInterval string `json:"plugin_watch_interval"`
+
+	// LookupTables 声明式查找表：内联或定义文件中的小型参考数据，启动时加载到内存数据库
+	LookupTables LookupTablesConfig `json:"lookup_tables"`
+
+	// Coercion 字符串与数值、日期混合比较以及 CAST 的转换规则：mysql（默认，取字符串开头的数字部分）
+	// 或 strict（只转换完整的数字字符串，否则报错）
+	Coercion string `json:"coercion"`
 }
 
-// LogConfig 日志配置
-type LogConfig struct {
-	Level  string `json:"level"`
-	Format string `json:"format"` // json or text
+// LookupTablesConfig 声明式查找表配置
+type LookupTablesConfig struct {
+	Database string         `json:"database"` // 查找表所在的数据库（内存数据源），默认 "lookup"
+	Tables   []lookup.Table `json:"tables"`   // 内联定义，数据文件的相对路径相对于当前目录
+	File     string         `json:"file"`     // JSON 或 YAML（.yaml、.yml）定义文件，数据文件的相对路径相对于定义文件所在的目录
+	// WatchInterval 检查定义文件和数据文件变化的间隔（如 "5s"），变化后重新加载；"0s" 表示只在启动时加载
+	WatchInterval string `json:"watch_interval"`
 }
 
+// Enabled 是否配置了查找表
+func (c LookupTablesConfig) Enabled() bool {
+	return len(c.Tables) > 0 || c.File != ""
+}
+
+// HealthCheckConfig 数据源存活探测配置
+type HealthCheckConfig struct {
+	Interval        string `json:"interval"`         // 探测间隔（如 "30s"），为空时不在后台探测
+	Timeout         string `json:"timeout"`          // 单个数据源探测的超时时间，默认 "5s"
+	QuarantineAfter int    `json:"quarantine_after"` // 连续探测失败多少次后隔离数据源，0 表示不隔离
+}
+
+// Durations 返回探测间隔和超时时间，无效或为空时为 0
+func (c HealthCheckConfig) Durations() (interval, timeout time.Duration) {
+	interval, _ = time.ParseDuration(c.Interval)
+	timeout, _ = time.ParseDuration(c.Timeout)
+	return interval, timeout
+}
+
+// FailoverConfig 数据库的语句级重试与故障切换配置
+type FailoverConfig struct {
+	Backup      string `json:"backup"`       // 备用（副本）数据源名，为空时在原数据源上重试
+	MaxRetries  int    `json:"max_retries"`  // 每条语句的最大重试次数，默认 2
+	BaseBackoff string `json:"base_backoff"` // 首次重试前的等待时间（如 "50ms"），之后每次翻倍并加随机抖动
+	MaxBackoff  string `json:"max_backoff"`  // 重试等待时间上限，默认 "2s"
+	RetryBudget int    `json:"retry_budget"` // 该数据库每分钟最多重试的次数，0 表示不限制
+}
+
+// LogConfig 日志配置：级别、格式（json 或 text）、按模块的级别、输出目标和重复日志的限流采样
+type LogConfig = logging.Config
+
 // PoolConfig 池配置
 type PoolConfig struct {
 	GoroutinePool GoroutinePoolConfig `json:"goroutine_pool"`
@@ -90,9 +395,17 @@ type ObjectPoolConfig struct {
 
 // CacheConfig 缓存配置
 type CacheConfig struct {
-	QueryCache  CachePoolConfig `json:"query_cache"`
-	ResultCache CachePoolConfig `json:"result_cache"`
-	SchemaCache CachePoolConfig `json:"schema_cache"`
+	QueryCache  CachePoolConfig  `json:"query_cache"`
+	ResultCache CachePoolConfig  `json:"result_cache"`
+	SchemaCache CachePoolConfig  `json:"schema_cache"`
+	ParseCache  ParseCacheConfig `json:"parse_cache"`
+}
+
+// ParseCacheConfig SQL 解析缓存配置
+type ParseCacheConfig struct {
+	Enabled      bool `json:"enabled"`
+	MaxEntries   int  `json:"max_entries"`    // 最大缓存条目数（LRU 淘汰）
+	MaxSQLLength int  `json:"max_sql_length"` // 超过该长度（字节）的 SQL 不缓存，0 表示不限制
 }
 
 // CachePoolConfig 缓存池配置
@@ -103,13 +416,22 @@ type CachePoolConfig struct {
 
 // MonitorConfig 监控配置
 type MonitorConfig struct {
-	SlowQuery SlowQueryConfig `json:"slow_query"`
+	SlowQuery        SlowQueryConfig        `json:"slow_query"`
+	StatementSummary StatementSummaryConfig `json:"statement_summary"`
+}
+
+// StatementSummaryConfig 语句摘要统计配置（information_schema.statements_summary）
+type StatementSummaryConfig struct {
+	Enabled    bool `json:"enabled"`
+	MaxEntries int  `json:"max_entries"` // 最多保留的摘要数，超出时淘汰最久未执行的摘要
 }
 
 // SlowQueryConfig 慢查询配置
 type SlowQueryConfig struct {
 	Threshold  time.Duration `json:"threshold"`
 	MaxEntries int           `json:"max_entries"`
+	// ExplainSampleRate 捕获执行计划和执行统计的慢语句比例（0-1），0 表示不捕获
+	ExplainSampleRate float64 `json:"explain_sample_rate"`
 }
 
 // ConnectionConfig 连接池配置
@@ -122,8 +444,121 @@ type ConnectionConfig struct {
 
 // SessionConfig 会话配置
 type SessionConfig struct {
-	MaxAge     time.Duration `json:"max_age"`
-	GCInterval time.Duration `json:"gc_interval"`
+	MaxAge       time.Duration     `json:"max_age"`
+	GCInterval   time.Duration     `json:"gc_interval"`
+	ResultLimits ResultLimitConfig `json:"result_limits"`
+	Passthrough  PassthroughConfig `json:"passthrough"`
+}
+
+// PassthroughConfig PASSTHROUGH 原生查询权限配置
+// 原生查询绕过 SQL 层的权限检查，默认所有用户都不能使用
+type PassthroughConfig struct {
+	// Users 用户可以执行原生查询的数据源，"*" 表示全部数据源
+	Users map[string][]string `json:"users,omitempty"`
+}
+
+// ForUser 返回用户可以执行原生查询的数据源
+func (c PassthroughConfig) ForUser(user string) []string {
+	return c.Users[user]
+}
+
+// ResultLimitConfig 结果集大小上限配置
+type ResultLimitConfig struct {
+	MaxRows  int64  `json:"max_rows"`  // 最大行数，0 表示不限制
+	MaxBytes int64  `json:"max_bytes"` // 最大字节数，0 表示不限制
+	Action   string `json:"action"`    // 超出时的处理方式：truncate（默认）、error、auto_limit
+	// Users 按用户覆盖，用户条目整体替换全局设置
+	Users map[string]ResultLimitConfig `json:"users,omitempty"`
+}
+
+// ForUser 返回用户生效的结果集上限
+func (c ResultLimitConfig) ForUser(user string) ResultLimitConfig {
+	if userConfig, ok := c.Users[user]; ok {
+		return userConfig
+	}
+	return ResultLimitConfig{MaxRows: c.MaxRows, MaxBytes: c.MaxBytes, Action: c.Action}
+}
+
+// validate 检查上限和处理方式
+func (c ResultLimitConfig) validate() error {
+	if c.MaxRows < 0 || c.MaxBytes < 0 {
+		return fmt.Errorf("结果集上限不能为负数")
+	}
+	switch strings.ToLower(c.Action) {
+	case "", "truncate", "error", "auto_limit":
+	default:
+		return fmt.Errorf("无效的结果集超限处理方式: %s", c.Action)
+	}
+	for user, userConfig := range c.Users {
+		if err := userConfig.validate(); err != nil {
+			return fmt.Errorf("用户 %s 的结果集上限无效: %w", user, err)
+		}
+	}
+	return nil
+}
+
+// WorkloadConfig 工作负载管理配置
+// 语句按规则归入资源类别，每个类别独立限制并发数和排队长度
+type WorkloadConfig struct {
+	Enabled      bool                  `json:"enabled"`
+	DefaultClass string                `json:"default_class"` // 未匹配规则的语句所属类别，为空时为 default（未配置时不限制并发）
+	Classes      []WorkloadClassConfig `json:"classes"`
+	Rules        []WorkloadRuleConfig  `json:"rules"` // 按顺序匹配，第一条匹配的规则生效
+}
+
+// WorkloadClassConfig 资源类别配置
+type WorkloadClassConfig struct {
+	Name           string        `json:"name"`
+	MaxConcurrency int           `json:"max_concurrency"` // 同时执行的语句数上限，0 表示不限制
+	MaxQueue       int           `json:"max_queue"`       // 名额用完后最多排队的语句数，0 表示直接拒绝
+	QueueTimeout   time.Duration `json:"queue_timeout"`   // 排队超时，0 表示一直等待
+}
+
+// WorkloadRuleConfig 分类规则，为空的条件匹配任意值
+type WorkloadRuleConfig struct {
+	Class          string   `json:"class"`
+	Users          []string `json:"users,omitempty"`
+	StatementTypes []string `json:"statement_types,omitempty"` // SELECT、INSERT、UPDATE 等
+	MinCost        float64  `json:"min_cost,omitempty"`        // 估算成本下限（含）
+	MaxCost        float64  `json:"max_cost,omitempty"`        // 估算成本上限（不含），0 表示不限制
+}
+
+// validate 检查类别定义和规则引用
+func (c WorkloadConfig) validate() error {
+	classes := make(map[string]bool, len(c.Classes))
+	for _, class := range c.Classes {
+		if class.Name == "" {
+			return fmt.Errorf("工作负载类别名不能为空")
+		}
+		if classes[class.Name] {
+			return fmt.Errorf("工作负载类别重复: %s", class.Name)
+		}
+		if class.MaxConcurrency < 0 || class.MaxQueue < 0 || class.QueueTimeout < 0 {
+			return fmt.Errorf("工作负载类别 %s 的并发数、队列长度和排队超时不能为负数", class.Name)
+		}
+		classes[class.Name] = true
+	}
+	defaultClass := c.DefaultClass
+	if defaultClass == "" {
+		defaultClass = "default"
+	}
+	for i, rule := range c.Rules {
+		if !classes[rule.Class] && rule.Class != defaultClass {
+			return fmt.Errorf("工作负载规则 %d 引用了未定义的类别: %s", i+1, rule.Class)
+		}
+		if rule.MinCost < 0 || rule.MaxCost < 0 || (rule.MaxCost > 0 && rule.MaxCost <= rule.MinCost) {
+			return fmt.Errorf("工作负载规则 %d 的成本范围无效", i+1)
+		}
+	}
+	return nil
+}
+
+// SystemDBConfig 系统数据库配置
+// 启用后用户、权限和数据源配置保存在系统数据库 sqlexec 中，而不是 users.json 等文件
+type SystemDBConfig struct {
+	Enabled bool   `json:"enabled"`
+	Storage string `json:"storage"`  // badger（默认，持久化到磁盘）或 memory
+	DataDir string `json:"data_dir"` // badger 数据目录，默认为 database_dir 下的 sqlexec 目录
 }
 
 // OptimizerConfig 优化器配置
@@ -148,6 +583,16 @@ func DefaultConfig() *Config {
 			Port:            3306,
 			ServerVersion:   "SqlExc",
 			KeepAlivePeriod: 30 * time.Second,
+
+			MaxConnections:         151,
+			MaxUserConnections:     0,
+			ConnectionQueueSize:    0,
+			ConnectionQueueTimeout: 10 * time.Second,
+
+			NetWriteTimeout:   60 * time.Second,
+			NetBufferLength:   16 * 1024,
+			NetBufferMax:      1024 * 1024,
+			NetStallThreshold: time.Second,
 		},
 		Database: DatabaseConfig{
 			MaxConnections: 100,
@@ -161,11 +606,14 @@ func DefaultConfig() *Config {
 				"sqlite",
 				"parquet",
 			},
-			DatabaseDir: "./database",
+			DatabaseDir:         "./database",
+			PluginWatchInterval: "5s",
+			LookupTables:        LookupTablesConfig{Database: "lookup", WatchInterval: "5s"},
 		},
 		Log: LogConfig{
-			Level:  "info",
-			Format: "text",
+			Level:    "info",
+			Format:   "text",
+			Sampling: logging.SamplingConfig{Interval: "1s", Initial: 10, Thereafter: 100},
 		},
 		Pool: PoolConfig{
 			GoroutinePool: GoroutinePoolConfig{
@@ -191,12 +639,21 @@ func DefaultConfig() *Config {
 				MaxSize: 100,
 				TTL:     1 * time.Hour,
 			},
+			ParseCache: ParseCacheConfig{
+				Enabled:      true,
+				MaxEntries:   1024,
+				MaxSQLLength: 16 * 1024,
+			},
 		},
 		Monitor: MonitorConfig{
 			SlowQuery: SlowQueryConfig{
 				Threshold:  1 * time.Second,
 				MaxEntries: 1000,
 			},
+			StatementSummary: StatementSummaryConfig{
+				Enabled:    true,
+				MaxEntries: 1000,
+			},
 		},
 		Connection: ConnectionConfig{
 			MaxOpen:     10,
@@ -215,6 +672,13 @@ func DefaultConfig() *Config {
 			Enabled: false,
 			Host:    "0.0.0.0",
 			Port:    8080,
+			CORS: HTTPCORSConfig{
+				AllowedOrigins: []string{"*"},
+				MaxAge:         86400,
+			},
+			MaxBodySize: 10 * 1024 * 1024,
+			Gzip:        true,
+			WebUI:       true,
 		},
 		MCP: MCPConfig{
 			Enabled: false,
@@ -228,6 +692,10 @@ func DefaultConfig() *Config {
 			SpillDir:      "",
 			EvictInterval: 5 * time.Second,
 		},
+		SystemDB: SystemDBConfig{
+			Enabled: false,
+			Storage: "badger",
+		},
 	}
 }
 
@@ -298,6 +766,163 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("无效的端口号: %d", config.Server.Port)
 	}
 
+	if config.Server.MaxConnections < 0 {
+		return fmt.Errorf("最大连接数不能为负数")
+	}
+
+	if config.Server.MaxUserConnections < 0 {
+		return fmt.Errorf("单用户最大连接数不能为负数")
+	}
+
+	if config.Server.ConnectionQueueSize < 0 {
+		return fmt.Errorf("连接等待队列长度不能为负数")
+	}
+
+	if config.Server.NetWriteTimeout < 0 || config.Server.NetBufferLength < 0 || config.Server.NetBufferMax < 0 || config.Server.NetStallThreshold < 0 {
+		return fmt.Errorf("结果集写出超时和缓冲大小不能为负数")
+	}
+
+	if config.Server.NetBufferMax > 0 && config.Server.NetBufferLength > config.Server.NetBufferMax {
+		return fmt.Errorf("net_buffer_length 不能大于 net_buffer_max")
+	}
+
+	if err := config.Log.Validate(); err != nil {
+		return fmt.Errorf("日志配置无效: %w", err)
+	}
+
+	if config.Server.ProtocolTrace && config.Server.ProtocolTraceDir == "" {
+		return fmt.Errorf("开启 protocol_trace 需要设置 protocol_trace_dir")
+	}
+
+	if config.Server.NodeID < 0 || config.Server.NodeID > idgen.MaxNode {
+		return fmt.Errorf("node_id 必须在 0 到 %d 之间", idgen.MaxNode)
+	}
+
+	for _, listener := range config.Server.Listeners {
+		if err := listener.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := config.Auth.PasswordPolicy.Validate(); err != nil {
+		return fmt.Errorf("密码策略无效: %w", err)
+	}
+	if err := config.Auth.Throttle.Validate(); err != nil {
+		return fmt.Errorf("登录限流配置无效: %w", err)
+	}
+
+	if err := config.Session.ResultLimits.validate(); err != nil {
+		return err
+	}
+
+	if config.HTTPAPI.MaxBodySize < 0 || config.HTTPAPI.CORS.MaxAge < 0 {
+		return fmt.Errorf("HTTP API 的请求体上限和 CORS max_age 不能为负数")
+	}
+	if (config.HTTPAPI.TLS.CertFile == "") != (config.HTTPAPI.TLS.KeyFile == "") {
+		return fmt.Errorf("HTTP API 的 TLS 需要同时设置 cert_file 和 key_file")
+	}
+
+	for name, policy := range config.Database.WritePolicies {
+		if _, err := domain.ParseWritePolicy(policy); err != nil {
+			return fmt.Errorf("数据库 %s 的写入策略无效: %w", name, err)
+		}
+	}
+
+	for _, q := range config.Database.Quotas {
+		if err := q.Validate(); err != nil {
+			return fmt.Errorf("配额无效: %w", err)
+		}
+	}
+
+	if config.Database.Tenancy != nil {
+		if err := config.Database.Tenancy.Validate(); err != nil {
+			return fmt.Errorf("多租户配置无效: %w", err)
+		}
+	}
+
+	if enc := config.Database.Encryption; enc != nil {
+		if len(enc.Keys) == 0 {
+			return fmt.Errorf("列加密配置无效: 至少需要一个密钥")
+		}
+		if err := enc.Keys.Validate(); err != nil {
+			return fmt.Errorf("列加密配置无效: %w", err)
+		}
+	}
+
+	for scope, value := range config.Database.HistoryRetention {
+		if d, err := time.ParseDuration(value); err != nil || d < 0 || scope == "" {
+			return fmt.Errorf("历史版本保留时间无效: %s = %q", scope, value)
+		}
+	}
+
+	if value := config.Database.RecycleBinRetention; value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("回收站保留时间无效: %q", value)
+		}
+	}
+
+	if config.Database.ChangeLogSize < 0 {
+		return fmt.Errorf("变更日志大小不能为负数")
+	}
+
+	if value := config.Database.ChangeArchiveInterval; value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("变更日志归档间隔无效: %q", value)
+		}
+	}
+
+	if value := config.Database.IdempotencyTTL; value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("幂等键保留时间无效: %q", value)
+		}
+	}
+
+	for name, fo := range config.Database.Failover {
+		if name == "" || fo.MaxRetries < 0 || fo.RetryBudget < 0 {
+			return fmt.Errorf("数据库 %s 的故障切换配置无效", name)
+		}
+		for _, value := range []string{fo.BaseBackoff, fo.MaxBackoff} {
+			if value == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(value); err != nil || d < 0 {
+				return fmt.Errorf("数据库 %s 的重试等待时间无效: %q", name, value)
+			}
+		}
+	}
+
+	for _, value := range []string{config.Database.HealthCheck.Interval, config.Database.HealthCheck.Timeout} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("健康检查时间无效: %q", value)
+		}
+	}
+
+	if config.Database.HealthCheck.QuarantineAfter < 0 {
+		return fmt.Errorf("隔离阈值不能为负数")
+	}
+
+	if value := config.Database.PluginWatchInterval; value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("插件目录检查间隔无效: %q", value)
+		}
+	}
+
+	if value := config.Database.LookupTables.WatchInterval; value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("查找表检查间隔无效: %q", value)
+		}
+	}
+	if err := lookup.Validate(config.Database.LookupTables.Tables); err != nil {
+		return fmt.Errorf("查找表配置无效: %w", err)
+	}
+
+	if _, err := utils.ParseCoercionMode(config.Database.Coercion); err != nil {
+		return fmt.Errorf("类型转换规则无效: %w", err)
+	}
+
 	if config.Database.MaxConnections < 1 {
 		return fmt.Errorf("最大连接数必须大于0")
 	}
@@ -330,10 +955,38 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("连接池最大空闲连接数必须大于0")
 	}
 
+	if config.Cache.ParseCache.MaxEntries < 0 || config.Cache.ParseCache.MaxSQLLength < 0 {
+		return fmt.Errorf("解析缓存大小不能为负数")
+	}
+
+	if rate := config.Monitor.SlowQuery.ExplainSampleRate; rate < 0 || rate > 1 {
+		return fmt.Errorf("慢查询执行计划采样比例必须在 0 到 1 之间: %v", rate)
+	}
+
+	if config.Monitor.StatementSummary.MaxEntries < 0 {
+		return fmt.Errorf("语句摘要最大条目数不能为负数")
+	}
+
+	if err := config.Workload.validate(); err != nil {
+		return err
+	}
+
+	if storage := strings.ToLower(config.SystemDB.Storage); storage != "" && storage != "badger" && storage != "memory" {
+		return fmt.Errorf("无效的系统数据库存储方式: %s", config.SystemDB.Storage)
+	}
+
 	return nil
 }
 
-// GetListenAddress 返回监听地址
+// SystemDBDataDir 返回系统数据库的数据目录，默认为 database_dir 下的 sqlexec 目录
+func (c *Config) SystemDBDataDir() string {
+	if c.SystemDB.DataDir != "" {
+		return c.SystemDB.DataDir
+	}
+	return filepath.Join(c.Database.DatabaseDir, "sqlexec")
+}
+
+// GetListenAddress 返回监听地址，IPv6 地址带方括号
 func (c *Config) GetListenAddress() string {
-	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
+	return net.JoinHostPort(c.Server.Host, strconv.Itoa(c.Server.Port))
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -19,6 +20,10 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 3306, config.Server.Port)
 	assert.Equal(t, "SqlExc", config.Server.ServerVersion)
 	assert.Equal(t, 30*time.Second, config.Server.KeepAlivePeriod)
+	assert.Equal(t, 151, config.Server.MaxConnections)
+	assert.Equal(t, 0, config.Server.MaxUserConnections)
+	assert.Equal(t, 0, config.Server.ConnectionQueueSize)
+	assert.Equal(t, 10*time.Second, config.Server.ConnectionQueueTimeout)
 
 	// 验证数据库配置
 	assert.Equal(t, 100, config.Database.MaxConnections)
@@ -28,6 +33,7 @@ func TestDefaultConfig(t *testing.T) {
 	// 验证日志配置
 	assert.Equal(t, "info", config.Log.Level)
 	assert.Equal(t, "text", config.Log.Format)
+	assert.Equal(t, 10, config.Log.Sampling.Initial)
 
 	// 验证池配置
 	assert.Equal(t, 10, config.Pool.GoroutinePool.MaxWorkers)
@@ -43,6 +49,11 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 10*time.Minute, config.Cache.ResultCache.TTL)
 	assert.Equal(t, 100, config.Cache.SchemaCache.MaxSize)
 	assert.Equal(t, 1*time.Hour, config.Cache.SchemaCache.TTL)
+	assert.True(t, config.Cache.ParseCache.Enabled)
+	assert.Equal(t, 1024, config.Cache.ParseCache.MaxEntries)
+	assert.Equal(t, 16*1024, config.Cache.ParseCache.MaxSQLLength)
+	assert.True(t, config.Monitor.StatementSummary.Enabled)
+	assert.Equal(t, 1000, config.Monitor.StatementSummary.MaxEntries)
 
 	// 验证监控配置
 	assert.Equal(t, 1*time.Second, config.Monitor.SlowQuery.Threshold)
@@ -136,6 +147,555 @@ func TestLoadConfig_InvalidMaxConnections(t *testing.T) {
 	assert.Contains(t, err.Error(), "最大连接数必须大于0")
 }
 
+func TestLoadConfig_InvalidServerConnectionLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		server map[string]interface{}
+		errMsg string
+	}{
+		{"negative max_connections", map[string]interface{}{"max_connections": -1}, "最大连接数不能为负数"},
+		{"negative max_user_connections", map[string]interface{}{"max_user_connections": -1}, "单用户最大连接数不能为负数"},
+		{"negative connection_queue_size", map[string]interface{}{"connection_queue_size": -1}, "连接等待队列长度不能为负数"},
+		{"protocol_trace without dir", map[string]interface{}{"protocol_trace": true}, "需要设置 protocol_trace_dir"},
+		{"node_id out of range", map[string]interface{}{"node_id": 64}, "node_id 必须在 0 到 63 之间"},
+		{"listener without port", listeners(map[string]interface{}{"address": "127.0.0.1"}), "无效的监听地址"},
+		{"listener bad network", listeners(map[string]interface{}{"network": "udp", "address": ":3306"}), "无效的监听网络类型"},
+		{"unix listener without path", listeners(map[string]interface{}{"network": "unix"}), "监听地址不能为空"},
+		{"require_tls without cert", listeners(map[string]interface{}{"address": ":3307", "require_tls": true}), "没有配置 TLS 证书"},
+		{"bad allowed_hosts", listeners(map[string]interface{}{"address": ":3307", "allowed_hosts": []string{"10.0.0.0/33"}}), "allowed_hosts 无效"},
+		{"bad personality", listeners(map[string]interface{}{"address": ":3307", "protocol": map[string]interface{}{"personality": "oracle"}}), "无效的协议预设"},
+		{"bad charset", listeners(map[string]interface{}{"address": ":3307", "protocol": map[string]interface{}{"charset": "klingon_ci"}}), "无效的字符集排序规则"},
+		{"bad auth plugin", listeners(map[string]interface{}{"address": ":3307", "protocol": map[string]interface{}{"auth_plugin": "sha256_password"}}), "不支持的认证插件"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			jsonData, _ := json.Marshal(map[string]interface{}{"server": tt.server})
+			require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+			config, err := LoadConfig(configPath)
+			assert.Error(t, err)
+			assert.Nil(t, config)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+// listeners 返回只包含一个监听地址的 server 配置
+func listeners(listener map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"listeners": []interface{}{listener}}
+}
+
+func TestLoadConfig_Listeners(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{"server": map[string]interface{}{
+		"listeners": []interface{}{
+			map[string]interface{}{"address": "[::]:3306"},
+			map[string]interface{}{"network": "unix", "address": "/tmp/sqlexec.sock"},
+			map[string]interface{}{"address": "0.0.0.0:3307", "read_only": true, "allowed_hosts": []string{"10.0.0.0/8", "192.168.1.5", "::1"}},
+		},
+	}})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	listeners := config.Server.ListenerConfigs()
+	require.Len(t, listeners, 3)
+	assert.Equal(t, "tcp", listeners[0].NetworkOrDefault())
+	assert.Equal(t, "unix", listeners[1].NetworkOrDefault())
+	assert.True(t, listeners[2].ReadOnly)
+
+	nets, err := ParseAllowedHosts(listeners[2].AllowedHosts)
+	require.NoError(t, err)
+	require.Len(t, nets, 3)
+	assert.True(t, nets[0].Contains(net.ParseIP("10.1.2.3")))
+	assert.True(t, nets[1].Contains(net.ParseIP("192.168.1.5")))
+	assert.False(t, nets[1].Contains(net.ParseIP("192.168.1.6")))
+	assert.True(t, nets[2].Contains(net.ParseIP("::1")))
+
+	// 未配置 listeners 时监听 host:port
+	assert.Equal(t, []ListenerConfig{{Network: "tcp", Address: "0.0.0.0:3306"}}, DefaultConfig().Server.ListenerConfigs())
+}
+
+func TestLoadConfig_WritePolicies(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"server":   map[string]interface{}{"read_only": true},
+		"database": map[string]interface{}{"write_policies": map[string]string{"reports": "read_only", "staging": "DDL_ONLY"}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, config.Server.ReadOnly)
+	assert.Equal(t, "read_only", config.Database.WritePolicies["reports"])
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"write_policies": map[string]string{"reports": "append_only"}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	config, err = LoadConfig(configPath)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "写入策略无效")
+}
+
+func TestLoadConfig_ResultLimits(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"session": map[string]interface{}{"result_limits": map[string]interface{}{
+			"max_rows": 1000,
+			"action":   "truncate",
+			"users": map[string]interface{}{
+				"analyst": map[string]interface{}{"max_rows": 100, "action": "auto_limit"},
+			},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	limits := config.Session.ResultLimits
+	assert.Equal(t, ResultLimitConfig{MaxRows: 1000, Action: "truncate"}, limits.ForUser("app"))
+	assert.Equal(t, ResultLimitConfig{MaxRows: 100, Action: "auto_limit"}, limits.ForUser("analyst"))
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"session": map[string]interface{}{"result_limits": map[string]interface{}{"max_rows": 10, "action": "drop"}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	config, err = LoadConfig(configPath)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "结果集超限处理方式")
+}
+
+func TestLoadConfig_Workload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"workload": map[string]interface{}{
+			"enabled": true,
+			"classes": []map[string]interface{}{
+				{"name": "oltp", "max_concurrency": 32},
+				{"name": "olap", "max_concurrency": 2, "max_queue": 10, "queue_timeout": int64(30 * time.Second)},
+			},
+			"rules": []map[string]interface{}{
+				{"class": "oltp", "max_cost": 10},
+				{"class": "olap", "users": []string{"analyst"}},
+			},
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, config.Workload.Enabled)
+	require.Len(t, config.Workload.Classes, 2)
+	assert.Equal(t, 30*time.Second, config.Workload.Classes[1].QueueTimeout)
+	assert.Equal(t, []string{"analyst"}, config.Workload.Rules[1].Users)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"workload": map[string]interface{}{
+			"rules": []map[string]interface{}{{"class": "missing"}},
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	config, err = LoadConfig(configPath)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "未定义的类别")
+}
+
+func TestLoadConfig_SystemDB(t *testing.T) {
+	assert.False(t, DefaultConfig().SystemDB.Enabled)
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"system_db": map[string]interface{}{"enabled": true, "data_dir": "/var/lib/sqlexec/system"},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, config.SystemDB.Enabled)
+	assert.Equal(t, "badger", config.SystemDB.Storage)
+	assert.Equal(t, "/var/lib/sqlexec/system", config.SystemDB.DataDir)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"system_db": map[string]interface{}{"enabled": true, "storage": "sqlite"},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Quotas(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"quotas": []map[string]interface{}{
+			{"database": "tenant_a", "max_bytes": 1 << 30},
+			{"database": "tenant_a", "table": "events", "user": "ingest", "max_rows": 1000000},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, config.Database.Quotas, 2)
+	assert.Equal(t, int64(1<<30), config.Database.Quotas[0].MaxBytes)
+	assert.Equal(t, "events", config.Database.Quotas[1].Table)
+	assert.Equal(t, "ingest", config.Database.Quotas[1].User)
+	assert.Equal(t, int64(1000000), config.Database.Quotas[1].MaxRows)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"quotas": []map[string]interface{}{{"table": "events", "max_rows": 10}}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Tenancy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"tenancy": map[string]interface{}{
+			"tables":         []string{"orders"},
+			"users":          map[string]string{"alice": "acme"},
+			"unscoped_users": []string{"root"},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.NotNil(t, config.Database.Tenancy)
+	assert.Equal(t, []string{"orders"}, config.Database.Tenancy.Tables)
+	assert.Equal(t, "acme", config.Database.Tenancy.Users["alice"])
+	assert.Equal(t, []string{"root"}, config.Database.Tenancy.UnscopedUsers)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"tenancy": map[string]interface{}{"users": map[string]string{"alice": "acme"}}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Encryption(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"encryption": map[string]interface{}{
+			"keys": map[string]interface{}{
+				"default": map[string]string{"env": "SQLEXEC_COLUMN_KEY"},
+				"pii":     map[string]string{"file": "/etc/sqlexec/pii.key"},
+			},
+			"decrypt_users": []string{"root"},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.NotNil(t, config.Database.Encryption)
+	assert.Equal(t, "SQLEXEC_COLUMN_KEY", config.Database.Encryption.Keys["default"].Env)
+	assert.Equal(t, "/etc/sqlexec/pii.key", config.Database.Encryption.Keys["pii"].File)
+	assert.Equal(t, []string{"root"}, config.Database.Encryption.DecryptUsers)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"encryption": map[string]interface{}{
+			"keys": map[string]interface{}{"default": map[string]string{}},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Auth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"auth": map[string]interface{}{
+			"verify_passwords": true,
+			"password_policy":  map[string]interface{}{"min_length": 12, "min_digits": 1, "expire_days": 90},
+			"throttle":         map[string]interface{}{"max_failures": 5, "base_delay": "100ms", "lockout_duration": "10m"},
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, config.Auth.VerifyPasswords)
+	assert.Equal(t, 12, config.Auth.PasswordPolicy.MinLength)
+	assert.Equal(t, 90, config.Auth.PasswordPolicy.ExpireDays)
+	assert.Equal(t, 5, config.Auth.Throttle.MaxFailures)
+	assert.Equal(t, "10m", config.Auth.Throttle.LockoutDuration)
+
+	for _, auth := range []map[string]interface{}{
+		{"password_policy": map[string]interface{}{"min_length": -1}},
+		{"throttle": map[string]interface{}{"max_failures": -1}},
+		{"throttle": map[string]interface{}{"base_delay": "soon"}},
+	} {
+		jsonData, _ = json.Marshal(map[string]interface{}{"auth": auth})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, "auth %v", auth)
+	}
+}
+
+func TestLoadConfig_HistoryRetention(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"history_retention": map[string]string{
+			"default":        "1h",
+			"default.orders": "168h",
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "168h", config.Database.HistoryRetention["default.orders"])
+
+	for _, value := range []string{"7d", "-1h"} {
+		jsonData, _ = json.Marshal(map[string]interface{}{
+			"database": map[string]interface{}{"history_retention": map[string]string{"default": value}},
+		})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLoadConfig_RecycleBin(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{
+			"recycle_bin_retention":   "24h",
+			"change_log_size":         10000,
+			"change_archive_dir":      "/var/lib/sqlexec/changes",
+			"change_archive_interval": "30s",
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "24h", config.Database.RecycleBinRetention)
+	assert.Equal(t, 10000, config.Database.ChangeLogSize)
+	assert.Equal(t, "/var/lib/sqlexec/changes", config.Database.ChangeArchiveDir)
+	assert.Equal(t, "30s", config.Database.ChangeArchiveInterval)
+
+	for _, database := range []map[string]interface{}{
+		{"recycle_bin_retention": "1d"},
+		{"recycle_bin_retention": "-1h"},
+		{"change_log_size": -1},
+		{"change_archive_interval": "0s"},
+	} {
+		jsonData, _ = json.Marshal(map[string]interface{}{"database": database})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, database)
+	}
+}
+
+func TestLoadConfig_IdempotencyTTL(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"idempotency_ttl": "10m"},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "10m", config.Database.IdempotencyTTL)
+
+	for _, value := range []string{"10", "0s", "-1m"} {
+		jsonData, _ = json.Marshal(map[string]interface{}{
+			"database": map[string]interface{}{"idempotency_ttl": value},
+		})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLoadConfig_Coercion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"coercion": "strict"},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "strict", config.Database.Coercion)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"coercion": "loose"},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Failover(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"failover": map[string]interface{}{
+			"shop": map[string]interface{}{"backup": "shop_replica", "max_retries": 3, "base_backoff": "20ms", "retry_budget": 100},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	fo := config.Database.Failover["shop"]
+	assert.Equal(t, "shop_replica", fo.Backup)
+	assert.Equal(t, 3, fo.MaxRetries)
+	assert.Equal(t, "20ms", fo.BaseBackoff)
+	assert.Equal(t, 100, fo.RetryBudget)
+
+	for _, fo := range []map[string]interface{}{
+		{"max_retries": -1},
+		{"retry_budget": -1},
+		{"base_backoff": "soon"},
+		{"max_backoff": "-1s"},
+	} {
+		jsonData, _ = json.Marshal(map[string]interface{}{"database": map[string]interface{}{"failover": map[string]interface{}{"shop": fo}}})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, fo)
+	}
+}
+
+func TestLoadConfig_HealthCheck(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"health_check": map[string]interface{}{
+			"interval": "30s", "timeout": "2s", "quarantine_after": 3,
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	interval, timeout := config.Database.HealthCheck.Durations()
+	assert.Equal(t, 30*time.Second, interval)
+	assert.Equal(t, 2*time.Second, timeout)
+	assert.Equal(t, 3, config.Database.HealthCheck.QuarantineAfter)
+
+	for _, hc := range []map[string]interface{}{
+		{"interval": "often"},
+		{"timeout": "-1s"},
+		{"quarantine_after": -1},
+	} {
+		jsonData, _ = json.Marshal(map[string]interface{}{"database": map[string]interface{}{"health_check": hc}})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, hc)
+	}
+}
+
+func TestLoadConfig_LookupTables(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"database": map[string]interface{}{"lookup_tables": map[string]interface{}{
+			"file": "lookup.yaml",
+			"tables": []interface{}{map[string]interface{}{
+				"name":    "countries",
+				"columns": []interface{}{map[string]interface{}{"name": "code", "primary": true}, map[string]interface{}{"name": "name"}},
+				"rows":    []interface{}{[]string{"US", "United States"}},
+			}},
+		}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	lt := config.Database.LookupTables
+	assert.True(t, lt.Enabled())
+	assert.Equal(t, "lookup", lt.Database)
+	assert.Equal(t, "5s", lt.WatchInterval)
+	assert.Equal(t, "lookup.yaml", lt.File)
+	require.Len(t, lt.Tables, 1)
+	assert.Equal(t, "countries", lt.Tables[0].Name)
+	assert.True(t, lt.Tables[0].Columns[0].Primary)
+	assert.False(t, DefaultConfig().Database.LookupTables.Enabled())
+
+	for _, lookupTables := range []map[string]interface{}{
+		{"watch_interval": "often"},
+		{"tables": []interface{}{map[string]interface{}{"name": "t", "rows": []interface{}{[]int{1}}}}},
+	} {
+		jsonData, _ = json.Marshal(map[string]interface{}{"database": map[string]interface{}{"lookup_tables": lookupTables}})
+		require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, lookupTables)
+	}
+}
+
+func TestLoadConfig_HTTPAPI(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"http_api": map[string]interface{}{
+			"enabled": true,
+			"cors":    map[string]interface{}{"allowed_origins": []string{"https://app.example.com"}, "allow_credentials": true},
+			"tls":     map[string]interface{}{"cert_file": "server.crt", "key_file": "server.key"},
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	api := config.HTTPAPI
+	assert.Equal(t, []string{"https://app.example.com"}, api.CORS.AllowedOrigins)
+	assert.True(t, api.CORS.AllowCredentials)
+	assert.Equal(t, 86400, api.CORS.MaxAge)
+	assert.True(t, api.TLS.Enabled())
+	assert.Equal(t, int64(10*1024*1024), api.MaxBodySize)
+	assert.True(t, api.Gzip)
+	assert.True(t, api.WebUI)
+
+	jsonData, _ = json.Marshal(map[string]interface{}{
+		"http_api": map[string]interface{}{"tls": map[string]interface{}{"cert_file": "server.crt"}},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+	config, err = LoadConfig(configPath)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "key_file")
+}
+
+func TestLoadConfig_InvalidParseCache(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"cache": map[string]interface{}{
+			"parse_cache": map[string]interface{}{"max_entries": -1},
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "解析缓存大小不能为负数")
+}
+
+func TestLoadConfig_InvalidLogConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"log": map[string]interface{}{
+			"level":   "info",
+			"modules": map[string]string{"parser": "verbose"},
+		},
+	})
+	require.NoError(t, os.WriteFile(configPath, jsonData, 0644))
+
+	config, err := LoadConfig(configPath)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "日志配置无效")
+}
+
 func TestLoadConfig_InvalidPoolConfig(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -386,6 +946,11 @@ func TestGetListenAddress(t *testing.T) {
 			port:     5432,
 			expected: "localhost:5432",
 		},
+		{
+			host:     "::",
+			port:     3306,
+			expected: "[::]:3306",
+		},
 	}
 
 	for _, tt := range tests {
@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/application"
@@ -17,14 +15,19 @@ import (
 // DatasourceTable is a writable virtual table for managing data source configurations
 type DatasourceTable struct {
 	dsManager *application.DataSourceManager
-	configDir string
+	store     DatasourceStore
 }
 
-// NewDatasourceTable creates a new DatasourceTable
+// NewDatasourceTable creates a new DatasourceTable that persists configurations in datasources.json
 func NewDatasourceTable(dsManager *application.DataSourceManager, configDir string) *DatasourceTable {
+	return NewDatasourceTableWithStore(dsManager, NewFileDatasourceStore(configDir))
+}
+
+// NewDatasourceTableWithStore creates a new DatasourceTable that persists configurations in store
+func NewDatasourceTableWithStore(dsManager *application.DataSourceManager, store DatasourceStore) *DatasourceTable {
 	return &DatasourceTable{
 		dsManager: dsManager,
-		configDir: configDir,
+		store:     store,
 	}
 }
 
@@ -90,7 +93,7 @@ func (t *DatasourceTable) Query(ctx context.Context, filters []domain.Filter, op
 // buildRows builds result rows from JSON file + runtime datasources
 func (t *DatasourceTable) buildRows() ([]domain.Row, error) {
 	// Load from JSON file
-	configs, err := loadDatasources(t.configDir)
+	configs, err := t.store.LoadDatasources()
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +172,7 @@ func (t *DatasourceTable) Insert(ctx context.Context, rows []domain.Row) (int64,
 	fileMu.Lock()
 	defer fileMu.Unlock()
 
-	configs, err := loadDatasources(t.configDir)
+	configs, err := t.store.LoadDatasources()
 	if err != nil {
 		return 0, err
 	}
@@ -209,8 +212,8 @@ func (t *DatasourceTable) Insert(ctx context.Context, rows []domain.Row) (int64,
 		inserted++
 	}
 
-	// Save to file (we already hold fileMu)
-	if err := saveDatasourcesUnlocked(t.configDir, configs); err != nil {
+	// Save to the store (we already hold fileMu)
+	if err := t.store.SaveDatasources(configs); err != nil {
 		return inserted, err
 	}
 
@@ -222,7 +225,7 @@ func (t *DatasourceTable) Update(ctx context.Context, filters []domain.Filter, u
 	fileMu.Lock()
 	defer fileMu.Unlock()
 
-	configs, err := loadDatasources(t.configDir)
+	configs, err := t.store.LoadDatasources()
 	if err != nil {
 		return 0, err
 	}
@@ -273,7 +276,7 @@ func (t *DatasourceTable) Update(ctx context.Context, filters []domain.Filter, u
 	}
 
 	if updated > 0 {
-		if err := saveDatasourcesUnlocked(t.configDir, configs); err != nil {
+		if err := t.store.SaveDatasources(configs); err != nil {
 			return updated, err
 		}
 	}
@@ -286,7 +289,7 @@ func (t *DatasourceTable) Delete(ctx context.Context, filters []domain.Filter) (
 	fileMu.Lock()
 	defer fileMu.Unlock()
 
-	configs, err := loadDatasources(t.configDir)
+	configs, err := t.store.LoadDatasources()
 	if err != nil {
 		return 0, err
 	}
@@ -306,7 +309,7 @@ func (t *DatasourceTable) Delete(ctx context.Context, filters []domain.Filter) (
 	}
 
 	if deleted > 0 {
-		if err := saveDatasourcesUnlocked(t.configDir, remaining); err != nil {
+		if err := t.store.SaveDatasources(remaining); err != nil {
 			return deleted, err
 		}
 	}
@@ -386,22 +389,6 @@ func (t *DatasourceTable) rowToConfig(row domain.Row) (domain.DataSourceConfig,
 	return cfg, nil
 }
 
-// saveDatasourcesUnlocked saves without acquiring the file lock (caller must hold it)
-func saveDatasourcesUnlocked(configDir string, configs []domain.DataSourceConfig) error {
-	filePath := filepath.Join(configDir, datasourcesFileName)
-
-	data, err := json.MarshalIndent(configs, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal datasource configs: %w", err)
-	}
-
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write %s: %w", filePath, err)
-	}
-
-	return nil
-}
-
 // applyFilters filters rows based on the given filters
 func applyFilters(rows []domain.Row, filters []domain.Filter) []domain.Row {
 	result := rows
@@ -45,3 +45,61 @@ func LoadDatasources(configDir string) ([]domain.DataSourceConfig, error) {
 	defer fileMu.Unlock()
 	return loadDatasources(configDir)
 }
+
+// DatasourceStore persists data source configurations. Callers serialize
+// load-modify-save sequences with fileMu.
+type DatasourceStore interface {
+	LoadDatasources() ([]domain.DataSourceConfig, error)
+	SaveDatasources(configs []domain.DataSourceConfig) error
+}
+
+// FileDatasourceStore keeps data source configurations in datasources.json
+type FileDatasourceStore struct {
+	configDir string
+}
+
+// NewFileDatasourceStore creates a store for datasources.json in configDir
+func NewFileDatasourceStore(configDir string) *FileDatasourceStore {
+	return &FileDatasourceStore{configDir: configDir}
+}
+
+// LoadDatasources reads datasources.json; a missing file means no configurations
+func (s *FileDatasourceStore) LoadDatasources() ([]domain.DataSourceConfig, error) {
+	return loadDatasources(s.configDir)
+}
+
+// SaveDatasources writes datasources.json
+func (s *FileDatasourceStore) SaveDatasources(configs []domain.DataSourceConfig) error {
+	filePath := filepath.Join(s.configDir, datasourcesFileName)
+
+	data, err := json.MarshalIndent(configs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal datasource configs: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// MigrateDatasources copies the configurations of from into to if to has
+// none yet. It reports whether any configuration was copied.
+func MigrateDatasources(from, to DatasourceStore) (bool, error) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	existing, err := to.LoadDatasources()
+	if err != nil || len(existing) > 0 {
+		return false, err
+	}
+	configs, err := from.LoadDatasources()
+	if err != nil || len(configs) == 0 {
+		return false, err
+	}
+	if err := to.SaveDatasources(configs); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -10,14 +10,22 @@ import (
 type Provider struct {
 	dsManager *application.DataSourceManager
 	configDir string
+	dsStore   DatasourceStore
 	tables    map[string]virtual.VirtualTable
 }
 
 // NewProvider creates a new config provider
 func NewProvider(dsManager *application.DataSourceManager, configDir string) *Provider {
+	return NewProviderWithDatasourceStore(dsManager, configDir, NewFileDatasourceStore(configDir))
+}
+
+// NewProviderWithDatasourceStore creates a new config provider whose datasource
+// table persists configurations in dsStore instead of datasources.json
+func NewProviderWithDatasourceStore(dsManager *application.DataSourceManager, configDir string, dsStore DatasourceStore) *Provider {
 	p := &Provider{
 		dsManager: dsManager,
 		configDir: configDir,
+		dsStore:   dsStore,
 		tables:    make(map[string]virtual.VirtualTable),
 	}
 	p.initializeTables()
@@ -26,7 +34,7 @@ func NewProvider(dsManager *application.DataSourceManager, configDir string) *Pr
 
 // initializeTables registers all config virtual tables
 func (p *Provider) initializeTables() {
-	p.tables["datasource"] = NewDatasourceTable(p.dsManager, p.configDir)
+	p.tables["datasource"] = NewDatasourceTableWithStore(p.dsManager, p.dsStore)
 	p.tables["api_client"] = NewAPIClientTable(p.configDir)
 }
 
@@ -0,0 +1,58 @@
+package config_schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/sysdb"
+)
+
+// SystemDBDatasourceStore keeps data source configurations in sqlexec.datasources.
+// Each row holds the full configuration as JSON next to its name, type and writable flag.
+type SystemDBDatasourceStore struct {
+	sys *sysdb.SystemDB
+}
+
+// NewSystemDBDatasourceStore creates a store backed by the system database
+func NewSystemDBDatasourceStore(sys *sysdb.SystemDB) *SystemDBDatasourceStore {
+	return &SystemDBDatasourceStore{sys: sys}
+}
+
+// LoadDatasources reads all configurations from sqlexec.datasources
+func (s *SystemDBDatasourceStore) LoadDatasources() ([]domain.DataSourceConfig, error) {
+	rows, err := s.sys.Rows(context.Background(), sysdb.TableDatasources)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]domain.DataSourceConfig, 0, len(rows))
+	for _, row := range rows {
+		var cfg domain.DataSourceConfig
+		data, _ := row["config"].(string)
+		if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse datasource config '%v': %w", row["name"], err)
+		}
+		configs = append(configs, cfg)
+	}
+	return configs, nil
+}
+
+// SaveDatasources replaces the rows of sqlexec.datasources
+func (s *SystemDBDatasourceStore) SaveDatasources(configs []domain.DataSourceConfig) error {
+	rows := make([]domain.Row, 0, len(configs))
+	for _, cfg := range configs {
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal datasource config '%s': %w", cfg.Name, err)
+		}
+		rows = append(rows, domain.Row{
+			"name":     cfg.Name,
+			"type":     string(cfg.Type),
+			"writable": cfg.Writable,
+			"config":   string(data),
+		})
+	}
+	return s.sys.ReplaceRows(context.Background(), sysdb.TableDatasources, rows)
+}
@@ -134,6 +134,8 @@ type QueryOptions struct {
 	Offset        int
 	Limit         int
 	OrderBy       []string
+	ForceIndex    string   // 优先使用的索引（FORCE_INDEX / USE_INDEX hint）
+	IgnoreIndexes []string // 不允许使用的索引（IGNORE_INDEX hint）
 }
 
 // DataService 数据访问服务实现
@@ -175,9 +177,11 @@ func (s *DataService) Query(ctx context.Context, tableName string, options *Quer
 
 	// 构建查询选项
 	queryOptions := &domain.QueryOptions{
-		Filters: options.Filters,
-		Offset:  options.Offset,
-		Limit:   options.Limit,
+		Filters:       options.Filters,
+		Offset:        options.Offset,
+		Limit:         options.Limit,
+		ForceIndex:    options.ForceIndex,
+		IgnoreIndexes: options.IgnoreIndexes,
 	}
 
 	// 查询数据
@@ -0,0 +1,282 @@
+// Package dataexport 把查询结果写成 CSV、JSON Lines 或 Parquet 文件：
+// 行逐条写入底层 io.Writer，CSV 和 JSON 不缓存结果，Parquet 按行组分批写出
+package dataexport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// Format 文件格式
+type Format string
+
+const (
+	FormatCSV     Format = "CSV"
+	FormatJSON    Format = "JSON"
+	FormatParquet Format = "PARQUET"
+)
+
+// Options 导出选项
+type Options struct {
+	Format      Format
+	Header      bool   // CSV 首行写出列名，默认 true
+	Delimiter   rune   // CSV 分隔符，默认 ','
+	Compression string // Parquet 压缩算法：snappy（默认）、gzip、zstd、lz4 或 none
+}
+
+// DefaultOptions 返回格式的默认选项
+func DefaultOptions(format Format) Options {
+	return Options{Format: format, Header: true, Delimiter: ',', Compression: "snappy"}
+}
+
+// ParseOptions 解析格式和 WITH 选项，选项名不区分大小写，格式为空时为 CSV
+func ParseOptions(format string, values map[string]string) (Options, error) {
+	if format == "" {
+		format = string(FormatCSV)
+	}
+	f := Format(strings.ToUpper(format))
+	switch f {
+	case FormatCSV, FormatJSON, FormatParquet:
+	default:
+		return Options{}, fmt.Errorf("unsupported format %s (CSV, JSON, PARQUET)", format)
+	}
+	opts := DefaultOptions(f)
+
+	for key, value := range values {
+		var err error
+		switch strings.ToLower(key) {
+		case "header":
+			if f != FormatCSV {
+				return Options{}, fmt.Errorf("option header only applies to CSV")
+			}
+			opts.Header, err = strconv.ParseBool(value)
+		case "delimiter":
+			if f != FormatCSV {
+				return Options{}, fmt.Errorf("option delimiter only applies to CSV")
+			}
+			opts.Delimiter, err = parseDelimiter(value)
+		case "compression":
+			if f != FormatParquet {
+				return Options{}, fmt.Errorf("option compression only applies to PARQUET")
+			}
+			opts.Compression = strings.ToLower(value)
+			if _, ok := compressionCodecs[opts.Compression]; !ok {
+				err = fmt.Errorf("must be snappy, gzip, zstd, lz4 or none")
+			}
+		default:
+			return Options{}, fmt.Errorf("unknown option %s (header, delimiter, compression)", key)
+		}
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid %s %q: %w", strings.ToLower(key), value, err)
+		}
+	}
+	return opts, nil
+}
+
+func parseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("must be a single character")
+	}
+	return r, nil
+}
+
+// ContentType 返回格式对应的 MIME 类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+// Extension 返回格式对应的文件扩展名
+func (f Format) Extension() string {
+	switch f {
+	case FormatJSON:
+		return ".jsonl"
+	case FormatParquet:
+		return ".parquet"
+	}
+	return ".csv"
+}
+
+// Writer 按列顺序写出结果行
+type Writer interface {
+	// WriteRow 写出一行，缺少的列写为 NULL
+	WriteRow(row domain.Row) error
+	// Close 写出缓存的数据，不关闭底层 io.Writer
+	Close() error
+}
+
+// NewWriter 创建写出 columns 各列的 Writer
+func NewWriter(w io.Writer, columns []domain.ColumnInfo, opts Options) (Writer, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("query returns no columns")
+	}
+	switch opts.Format {
+	case FormatCSV:
+		return newCSVWriter(w, columns, opts)
+	case FormatJSON:
+		return newJSONWriter(w, columns), nil
+	case FormatParquet:
+		return newParquetWriter(w, columns, opts), nil
+	}
+	return nil, fmt.Errorf("unsupported format %s", opts.Format)
+}
+
+// WriteAll 写出全部行并关闭 Writer，返回写出的行数
+func WriteAll(w io.Writer, columns []domain.ColumnInfo, rows []domain.Row, opts Options) (int64, error) {
+	writer, err := NewWriter(w, columns, opts)
+	if err != nil {
+		return 0, err
+	}
+	for i, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			return int64(i), fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return int64(len(rows)), err
+	}
+	return int64(len(rows)), nil
+}
+
+const datetimeLayout = "2006-01-02 15:04:05.999999"
+
+// csvWriter 写出 CSV，NULL 写为 \N，与 IMPORT DATA 和 MySQL LOAD DATA 一致
+type csvWriter struct {
+	w       *csv.Writer
+	columns []domain.ColumnInfo
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []domain.ColumnInfo, opts Options) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	if opts.Delimiter != 0 {
+		cw.w.Comma = opts.Delimiter
+	}
+	if opts.Header {
+		for i, col := range columns {
+			cw.record[i] = col.Name
+		}
+		if err := cw.w.Write(cw.record); err != nil {
+			return nil, err
+		}
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) WriteRow(row domain.Row) error {
+	for i, col := range c.columns {
+		c.record[i] = formatText(row[col.Name])
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatText 把值格式化为 CSV 字段
+func formatText(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return `\N`
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case bool:
+		if x {
+			return "1"
+		}
+		return "0"
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		return x.Format(datetimeLayout)
+	}
+	return fmt.Sprint(v)
+}
+
+// jsonWriter 每行写出一个 JSON 对象（JSON Lines），键的顺序与列顺序一致
+type jsonWriter struct {
+	w       *bufio.Writer
+	columns []domain.ColumnInfo
+	keys    [][]byte
+	buf     bytes.Buffer
+	enc     *json.Encoder
+}
+
+func newJSONWriter(w io.Writer, columns []domain.ColumnInfo) *jsonWriter {
+	jw := &jsonWriter{w: bufio.NewWriter(w), columns: columns}
+	jw.enc = json.NewEncoder(&jw.buf)
+	jw.enc.SetEscapeHTML(false)
+	for _, col := range columns {
+		key, _ := json.Marshal(col.Name)
+		jw.keys = append(jw.keys, key)
+	}
+	return jw
+}
+
+func (j *jsonWriter) WriteRow(row domain.Row) error {
+	j.buf.Reset()
+	j.buf.WriteByte('{')
+	for i, col := range j.columns {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		j.buf.Write(j.keys[i])
+		j.buf.WriteByte(':')
+		if err := j.enc.Encode(jsonValue(row[col.Name])); err != nil {
+			return fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		// Encode 在每个值后追加换行
+		j.buf.Truncate(j.buf.Len() - 1)
+	}
+	j.buf.WriteString("}\n")
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonWriter) Close() error { return j.w.Flush() }
+
+// jsonValue 把 JSON 无法表示的值转换为可编码的值
+func jsonValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(datetimeLayout)
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,136 @@
+package dataexport
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []domain.ColumnInfo{
+	{Name: "id", Type: "int"},
+	{Name: "name", Type: "varchar(20)"},
+	{Name: "score", Type: "double"},
+	{Name: "active", Type: "tinyint"},
+	{Name: "joined", Type: "date"},
+}
+
+var testRows = []domain.Row{
+	{"id": int64(1), "name": "alice", "score": 9.5, "active": true, "joined": "2024-01-02"},
+	{"id": int64(2), "name": "bob, \"jr\"", "score": nil, "active": false, "joined": nil},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteAll(&buf, testColumns, testRows, DefaultOptions(FormatCSV))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, "id,name,score,active,joined\n"+
+		"1,alice,9.5,1,2024-01-02\n"+
+		"2,\"bob, \"\"jr\"\"\",\\N,0,\\N\n", buf.String())
+
+	buf.Reset()
+	opts, err := ParseOptions("csv", map[string]string{"header": "false", "delimiter": `\t`})
+	require.NoError(t, err)
+	_, err = WriteAll(&buf, testColumns[:2], testRows[:1], opts)
+	require.NoError(t, err)
+	assert.Equal(t, "1\talice\n", buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	rows := append([]domain.Row{}, testRows...)
+	rows = append(rows, domain.Row{"id": int64(3), "name": "<c&d>", "joined": time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)})
+	_, err := WriteAll(&buf, testColumns, rows, DefaultOptions(FormatJSON))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":"alice","score":9.5,"active":true,"joined":"2024-01-02"}`+"\n"+
+		`{"id":2,"name":"bob, \"jr\"","score":null,"active":false,"joined":null}`+"\n"+
+		`{"id":3,"name":"<c&d>","score":null,"active":null,"joined":"2024-03-04 00:00:00"}`+"\n", buf.String())
+}
+
+func TestWriteParquet(t *testing.T) {
+	// 第一行的 score 为 NULL，类型由之后的行确定；count 声明为 TEXT 但值为整数
+	columns := append([]domain.ColumnInfo{}, testColumns...)
+	columns = append(columns, domain.ColumnInfo{Name: "count", Type: "TEXT"}, domain.ColumnInfo{Name: "created", Type: "datetime"})
+	rows := []domain.Row{
+		{"id": int64(1), "name": "alice", "active": true, "joined": "2024-01-02", "count": 3, "created": "2024-01-02 03:04:05"},
+		{"id": int64(2), "name": "bob", "score": 7.25, "active": false, "joined": "1969-12-31", "count": 4},
+	}
+	var buf bytes.Buffer
+	opts, err := ParseOptions("parquet", map[string]string{"compression": "zstd"})
+	require.NoError(t, err)
+	n, err := WriteAll(&buf, columns, rows, opts)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+
+	file, err := pq.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, file.NumRows())
+	types := map[string]string{}
+	for _, field := range file.Schema().Fields() {
+		assert.True(t, field.Optional(), field.Name())
+		types[field.Name()] = field.Type().String()
+	}
+	assert.Equal(t, map[string]string{
+		"id": "INT(64,true)", "name": "STRING", "score": "DOUBLE", "active": "BOOLEAN", "joined": "DATE",
+		"count": "INT(64,true)", "created": "TIMESTAMP(isAdjustedToUTC=true,unit=MICROS)",
+	}, types)
+
+	type record struct {
+		ID      *int64     `parquet:"id"`
+		Name    *string    `parquet:"name"`
+		Score   *float64   `parquet:"score"`
+		Active  *bool      `parquet:"active"`
+		Joined  *time.Time `parquet:"joined,date"`
+		Count   *int64     `parquet:"count"`
+		Created *time.Time `parquet:"created,timestamp(microsecond)"`
+	}
+	read, err := pq.Read[record](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	assert.Nil(t, read[0].Score)
+	assert.Equal(t, 7.25, *read[1].Score)
+	assert.Equal(t, "bob", *read[1].Name)
+	assert.True(t, *read[0].Active)
+	assert.Equal(t, "2024-01-02", read[0].Joined.UTC().Format("2006-01-02"))
+	assert.Equal(t, "1969-12-31", read[1].Joined.UTC().Format("2006-01-02"))
+	assert.EqualValues(t, 4, *read[1].Count)
+	assert.Equal(t, "2024-01-02 03:04:05", read[0].Created.UTC().Format("2006-01-02 15:04:05"))
+	assert.Nil(t, read[1].Created)
+
+	// 无法转换为列类型的值
+	buf.Reset()
+	_, err = WriteAll(&buf, columns[:1], []domain.Row{{"id": int64(1)}, {"id": "x"}}, DefaultOptions(FormatParquet))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "row 2")
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, opts.Format)
+	assert.True(t, opts.Header)
+	assert.Equal(t, ',', opts.Delimiter)
+
+	for _, tc := range []struct {
+		format string
+		values map[string]string
+	}{
+		{"xml", nil},
+		{"json", map[string]string{"header": "true"}},
+		{"csv", map[string]string{"compression": "gzip"}},
+		{"parquet", map[string]string{"compression": "brotli"}},
+		{"csv", map[string]string{"delimiter": ";;"}},
+		{"csv", map[string]string{"unknown": "1"}},
+	} {
+		_, err := ParseOptions(tc.format, tc.values)
+		assert.Error(t, err, "%v", tc)
+	}
+
+	_, err = NewWriter(&bytes.Buffer{}, nil, DefaultOptions(FormatCSV))
+	assert.Error(t, err)
+}
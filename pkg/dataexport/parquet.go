@@ -0,0 +1,406 @@
+package dataexport
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+)
+
+const (
+	// typeSampleRows 确定 Parquet 列类型时最多缓存的行数
+	typeSampleRows = 1000
+	// rowGroupRows 每个 Parquet 行组的行数，写满后行组写出到底层 io.Writer
+	rowGroupRows = 128 * 1024
+	// writeBatchRows 每次提交给 Parquet writer 的行数
+	writeBatchRows = 1024
+)
+
+// compressionCodecs Parquet 压缩算法，none 不压缩
+var compressionCodecs = map[string]compress.Codec{
+	"snappy": &pq.Snappy,
+	"gzip":   &pq.Gzip,
+	"zstd":   &pq.Zstd,
+	"lz4":    &pq.Lz4Raw,
+	"none":   nil,
+}
+
+// parquetKind Parquet 列的物理类型
+type parquetKind int
+
+const (
+	kindUnknown parquetKind = iota
+	kindString
+	kindBytes
+	kindInt
+	kindDouble
+	kindBool
+	kindDate
+	kindTimestamp
+)
+
+// declaredKind 根据列声明的类型确定 Parquet 类型，无法识别时返回 kindUnknown
+func declaredKind(colType string) parquetKind {
+	t := strings.ToLower(strings.TrimSpace(colType))
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.TrimSpace(strings.TrimSuffix(t, " unsigned"))
+	switch t {
+	case "int", "integer", "bigint", "smallint", "mediumint", "year", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64":
+		return kindInt
+	case "float", "double", "decimal", "numeric", "real", "float32", "float64":
+		return kindDouble
+	case "bool", "boolean":
+		return kindBool
+	case "date":
+		return kindDate
+	case "datetime", "timestamp":
+		return kindTimestamp
+	case "blob", "binary", "varbinary", "bytes":
+		return kindBytes
+	case "varchar", "char", "text", "string", "json":
+		return kindString
+	}
+	return kindUnknown
+}
+
+// valueKind 根据实际的值确定 Parquet 类型；字符串保存的日期和日期时间沿用声明的类型
+func valueKind(v interface{}, declared parquetKind) parquetKind {
+	switch v.(type) {
+	case bool:
+		return kindBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if declared == kindBool || declared == kindDouble {
+			return declared
+		}
+		return kindInt
+	case float32, float64:
+		return kindDouble
+	case time.Time:
+		if declared == kindDate {
+			return kindDate
+		}
+		return kindTimestamp
+	case []byte:
+		return kindBytes
+	case string:
+		if declared == kindDate || declared == kindTimestamp {
+			return declared
+		}
+	}
+	return kindString
+}
+
+func (k parquetKind) node() pq.Node {
+	switch k {
+	case kindBytes:
+		return pq.Leaf(pq.ByteArrayType)
+	case kindInt:
+		return pq.Leaf(pq.Int64Type)
+	case kindDouble:
+		return pq.Leaf(pq.DoubleType)
+	case kindBool:
+		return pq.Leaf(pq.BooleanType)
+	case kindDate:
+		return pq.Date()
+	case kindTimestamp:
+		return pq.Timestamp(pq.Microsecond)
+	}
+	return pq.String()
+}
+
+// parquetColumn 写出的列及其在 Parquet schema 中的序号
+type parquetColumn struct {
+	name  string
+	kind  parquetKind
+	index int
+}
+
+// parquetWriter 写出 Parquet 文件。列类型由声明的类型和前若干行的实际值共同确定：
+// 缓存行直到每列都出现非 NULL 值（最多 1000 行），之后才创建 schema 并写出缓存的行
+type parquetWriter struct {
+	out      io.Writer
+	opts     Options
+	columns  []domain.ColumnInfo
+	pending  []domain.Row
+	writer   *pq.Writer
+	targets  []parquetColumn
+	batch    []pq.Row
+	declared []parquetKind
+}
+
+func newParquetWriter(w io.Writer, columns []domain.ColumnInfo, opts Options) *parquetWriter {
+	// 同名的列只写出一次
+	seen := map[string]bool{}
+	var unique []domain.ColumnInfo
+	for _, col := range columns {
+		if !seen[col.Name] {
+			seen[col.Name] = true
+			unique = append(unique, col)
+		}
+	}
+	pw := &parquetWriter{out: w, opts: opts, columns: unique}
+	for _, col := range unique {
+		pw.declared = append(pw.declared, declaredKind(col.Type))
+	}
+	return pw
+}
+
+func (p *parquetWriter) WriteRow(row domain.Row) error {
+	if p.writer == nil {
+		p.pending = append(p.pending, row)
+		if len(p.pending) < typeSampleRows && !p.typesKnown() {
+			return nil
+		}
+		return p.start()
+	}
+	return p.write(row)
+}
+
+// typesKnown 缓存的行中每列都已出现非 NULL 值
+func (p *parquetWriter) typesKnown() bool {
+	for _, col := range p.columns {
+		found := false
+		for _, row := range p.pending {
+			if row[col.Name] != nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// start 根据缓存的行确定列类型，创建 writer 并写出缓存的行
+func (p *parquetWriter) start() error {
+	group := make(pq.Group, len(p.columns))
+	kinds := make(map[string]parquetKind, len(p.columns))
+	for i, col := range p.columns {
+		kind := p.declared[i]
+		for _, row := range p.pending {
+			if v := row[col.Name]; v != nil {
+				kind = valueKind(v, p.declared[i])
+				break
+			}
+		}
+		if kind == kindUnknown {
+			kind = kindString
+		}
+		kinds[col.Name] = kind
+		group[col.Name] = pq.Optional(kind.node())
+	}
+	schema := pq.NewSchema("export", group)
+	for i, field := range schema.Fields() {
+		p.targets = append(p.targets, parquetColumn{name: field.Name(), kind: kinds[field.Name()], index: i})
+	}
+
+	options := []pq.WriterOption{schema, pq.MaxRowsPerRowGroup(rowGroupRows)}
+	if codec := compressionCodecs[p.opts.Compression]; codec != nil {
+		options = append(options, pq.Compression(codec))
+	}
+	p.writer = pq.NewWriter(p.out, options...)
+
+	pending := p.pending
+	p.pending = nil
+	for _, row := range pending {
+		if err := p.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parquetWriter) write(row domain.Row) error {
+	values := make(pq.Row, len(p.targets))
+	for i, t := range p.targets {
+		v, err := parquetValue(row[t.name], t.kind)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", t.name, err)
+		}
+		if v.IsNull() {
+			values[i] = v.Level(0, 0, t.index)
+		} else {
+			values[i] = v.Level(0, 1, t.index)
+		}
+	}
+	p.batch = append(p.batch, values)
+	if len(p.batch) >= writeBatchRows {
+		return p.flush()
+	}
+	return nil
+}
+
+func (p *parquetWriter) flush() error {
+	if len(p.batch) == 0 {
+		return nil
+	}
+	_, err := p.writer.WriteRows(p.batch)
+	p.batch = p.batch[:0]
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	if p.writer == nil {
+		if err := p.start(); err != nil {
+			return err
+		}
+	}
+	if err := p.flush(); err != nil {
+		return err
+	}
+	return p.writer.Close()
+}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// parquetValue 把值转换为列类型的 Parquet 值
+func parquetValue(v interface{}, kind parquetKind) (pq.Value, error) {
+	if v == nil {
+		return pq.NullValue(), nil
+	}
+	switch kind {
+	case kindInt:
+		n, err := toInt64(v)
+		if err != nil {
+			return pq.Value{}, err
+		}
+		return pq.Int64Value(n), nil
+	case kindDouble:
+		f, err := toFloat64(v)
+		if err != nil {
+			return pq.Value{}, err
+		}
+		return pq.DoubleValue(f), nil
+	case kindBool:
+		switch x := v.(type) {
+		case bool:
+			return pq.BooleanValue(x), nil
+		case string:
+			b, err := strconv.ParseBool(x)
+			if err != nil {
+				return pq.Value{}, fmt.Errorf("invalid boolean %q", x)
+			}
+			return pq.BooleanValue(b), nil
+		}
+		n, err := toInt64(v)
+		if err != nil {
+			return pq.Value{}, err
+		}
+		return pq.BooleanValue(n != 0), nil
+	case kindDate, kindTimestamp:
+		t, ok := v.(time.Time)
+		if !ok {
+			s, isString := v.(string)
+			if !isString {
+				return pq.Value{}, fmt.Errorf("cannot convert %T to date", v)
+			}
+			var err error
+			if t, err = parseTime(s); err != nil {
+				return pq.Value{}, err
+			}
+		}
+		if kind == kindDate {
+			// DATE 为 1970-01-01 起的天数，之前的日期向下取整
+			secs := t.Unix()
+			days := secs / 86400
+			if secs%86400 < 0 {
+				days--
+			}
+			return pq.Int32Value(int32(days)), nil
+		}
+		return pq.Int64Value(t.UnixMicro()), nil
+	case kindBytes:
+		if b, ok := v.([]byte); ok {
+			return pq.ByteArrayValue(b), nil
+		}
+	}
+	return pq.ByteArrayValue([]byte(formatText(v))), nil
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), nil
+	case int8:
+		return int64(x), nil
+	case int16:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case uint:
+		return int64(x), nil
+	case uint8:
+		return int64(x), nil
+	case uint16:
+		return int64(x), nil
+	case uint32:
+		return int64(x), nil
+	case uint64:
+		return int64(x), nil
+	case float32:
+		if float32(int64(x)) == x {
+			return int64(x), nil
+		}
+	case float64:
+		if float64(int64(x)) == x {
+			return int64(x), nil
+		}
+	case bool:
+		if x {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
+			return n, nil
+		}
+		return 0, fmt.Errorf("invalid integer %q", x)
+	}
+	return 0, fmt.Errorf("cannot convert %v (%T) to integer", v, v)
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch x := v.(type) {
+	case float32:
+		return float64(x), nil
+	case float64:
+		return x, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", x)
+		}
+		return f, nil
+	}
+	n, err := toInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert %v (%T) to number", v, v)
+	}
+	return float64(n), nil
+}
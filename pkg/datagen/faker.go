@@ -0,0 +1,125 @@
+package datagen
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+var (
+	firstNames = []string{
+		"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth",
+		"David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+		"Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Emily", "Mark", "Emma", "Paul", "Olivia",
+	}
+	lastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee",
+		"Thompson", "White", "Harris", "Clark", "Lewis", "Walker", "Young", "Allen", "King", "Wright",
+	}
+	companyWords = []string{
+		"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli", "Vandelay", "Cyberdyne", "Soylent",
+		"Aperture", "Tyrell", "Wonka", "Gringotts", "Oscorp", "Massive", "Dynamic", "Pioneer", "Summit", "Vertex",
+	}
+	companySuffixes = []string{"Inc", "LLC", "Ltd", "Group", "Corp", "Systems", "Labs", "Holdings"}
+	cities          = []string{
+		"New York", "London", "Tokyo", "Paris", "Berlin", "Shanghai", "Sydney", "Toronto", "Madrid", "Rome",
+		"Seoul", "Singapore", "Amsterdam", "Chicago", "Beijing", "Mumbai", "Dubai", "Vienna", "Stockholm", "Osaka",
+	}
+	countries = []string{
+		"United States", "United Kingdom", "Japan", "France", "Germany", "China", "Australia", "Canada", "Spain",
+		"Italy", "South Korea", "Singapore", "Netherlands", "India", "Brazil", "Mexico", "Sweden", "Austria",
+	}
+	streetNames  = []string{"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Lake", "Hill", "Park", "River", "Sunset", "Washington"}
+	streetTypes  = []string{"St", "Ave", "Rd", "Blvd", "Ln", "Dr", "Way", "Ct"}
+	emailDomains = []string{"example.com", "example.org", "example.net", "mail.test", "corp.test"}
+	words        = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "commodo",
+	}
+)
+
+// fakers 仿真数据种类
+var fakers = map[string]func(rnd *rand.Rand) string{
+	"name":       func(rnd *rand.Rand) string { return pickOne(rnd, firstNames) + " " + pickOne(rnd, lastNames) },
+	"first_name": func(rnd *rand.Rand) string { return pickOne(rnd, firstNames) },
+	"last_name":  func(rnd *rand.Rand) string { return pickOne(rnd, lastNames) },
+	"username": func(rnd *rand.Rand) string {
+		return strings.ToLower(pickOne(rnd, firstNames)) + fmt.Sprintf("%d", rnd.Intn(1000))
+	},
+	"email": func(rnd *rand.Rand) string {
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(pickOne(rnd, firstNames)), strings.ToLower(pickOne(rnd, lastNames)),
+			rnd.Intn(100), pickOne(rnd, emailDomains))
+	},
+	"phone": func(rnd *rand.Rand) string {
+		return fmt.Sprintf("+1-%03d-%03d-%04d", rnd.Intn(800)+200, rnd.Intn(1000), rnd.Intn(10000))
+	},
+	"company": func(rnd *rand.Rand) string { return pickOne(rnd, companyWords) + " " + pickOne(rnd, companySuffixes) },
+	"city":    func(rnd *rand.Rand) string { return pickOne(rnd, cities) },
+	"country": func(rnd *rand.Rand) string { return pickOne(rnd, countries) },
+	"street": func(rnd *rand.Rand) string {
+		return fmt.Sprintf("%d %s %s", rnd.Intn(9999)+1, pickOne(rnd, streetNames), pickOne(rnd, streetTypes))
+	},
+	"word": func(rnd *rand.Rand) string { return pickOne(rnd, words) },
+	"sentence": func(rnd *rand.Rand) string {
+		n := rnd.Intn(8) + 4
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = pickOne(rnd, words)
+		}
+		s := strings.Join(parts, " ")
+		return strings.ToUpper(s[:1]) + s[1:] + "."
+	},
+	"uuid": func(rnd *rand.Rand) string {
+		var b [16]byte
+		rnd.Read(b[:])
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	},
+	"ipv4": func(rnd *rand.Rand) string {
+		return fmt.Sprintf("%d.%d.%d.%d", rnd.Intn(223)+1, rnd.Intn(256), rnd.Intn(256), rnd.Intn(254)+1)
+	},
+}
+
+// FakerKinds 返回 FAKER 支持的种类
+func FakerKinds() []string {
+	kinds := make([]string, 0, len(fakers))
+	for kind := range fakers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// faker 仿真数据生成器
+type faker struct {
+	gen func(rnd *rand.Rand) string
+}
+
+func newFaker(args []interface{}) (Generator, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FAKER takes 1 argument (kind)")
+	}
+	kind, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("FAKER kind must be a string")
+	}
+	gen, ok := fakers[strings.ToLower(kind)]
+	if !ok {
+		return nil, fmt.Errorf("unknown FAKER kind %q (%s)", kind, strings.Join(FakerKinds(), ", "))
+	}
+	return &faker{gen: gen}, nil
+}
+
+func (f *faker) Type() string { return "varchar" }
+
+func (f *faker) Next(rnd *rand.Rand, _ int64) interface{} {
+	return f.gen(rnd)
+}
+
+func pickOne(rnd *rand.Rand, values []string) string {
+	return values[rnd.Intn(len(values))]
+}
@@ -0,0 +1,167 @@
+// Package datagen 生成测试数据：序列、随机数、候选值和仿真数据（姓名、邮箱、城市等），
+// 供 CREATE TABLE ... AS GENERATE(...) 快速创建演示和压测用的数据集
+package datagen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Generator 一列数据的生成器
+type Generator interface {
+	// Type 列类型
+	Type() string
+	// Next 生成第 row 行（从 0 开始）的值
+	Next(rnd *rand.Rand, row int64) interface{}
+}
+
+// New 按名称创建生成器，名称不区分大小写：
+//   - SEQUENCE[(start[, step])]：start + row*step，默认从 1 开始、步长 1
+//   - RANDOM(min, max)：闭区间内的随机数，两个参数都是整数时生成整数，否则生成浮点数
+//   - FAKER('kind')：仿真数据，kind 见 FakerKinds
+//   - CHOICE(v1, v2, ...)：随机选择一个候选值
+func New(name string, args []interface{}) (Generator, error) {
+	switch strings.ToUpper(name) {
+	case "SEQUENCE":
+		return newSequence(args)
+	case "RANDOM":
+		return newRandom(args)
+	case "FAKER":
+		return newFaker(args)
+	case "CHOICE":
+		return newChoice(args)
+	}
+	return nil, fmt.Errorf("unknown generator %s (SEQUENCE, RANDOM, FAKER, CHOICE)", name)
+}
+
+// sequence 等差序列
+type sequence struct {
+	start, step int64
+}
+
+func newSequence(args []interface{}) (Generator, error) {
+	if len(args) > 2 {
+		return nil, fmt.Errorf("SEQUENCE takes at most 2 arguments")
+	}
+	seq := &sequence{start: 1, step: 1}
+	for i, arg := range args {
+		v, ok := arg.(int64)
+		if !ok {
+			return nil, fmt.Errorf("SEQUENCE arguments must be integers")
+		}
+		if i == 0 {
+			seq.start = v
+		} else {
+			seq.step = v
+		}
+	}
+	return seq, nil
+}
+
+func (s *sequence) Type() string { return "bigint" }
+
+func (s *sequence) Next(_ *rand.Rand, row int64) interface{} {
+	return s.start + row*s.step
+}
+
+// randomInt 整数闭区间上的均匀分布
+type randomInt struct {
+	min, max int64
+}
+
+// randomFloat 浮点区间上的均匀分布
+type randomFloat struct {
+	min, max float64
+}
+
+func newRandom(args []interface{}) (Generator, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("RANDOM takes 2 arguments (min, max)")
+	}
+	minInt, ok1 := args[0].(int64)
+	maxInt, ok2 := args[1].(int64)
+	if ok1 && ok2 {
+		if minInt > maxInt {
+			return nil, fmt.Errorf("RANDOM min %d is greater than max %d", minInt, maxInt)
+		}
+		return &randomInt{min: minInt, max: maxInt}, nil
+	}
+	minFloat, ok1 := toFloat(args[0])
+	maxFloat, ok2 := toFloat(args[1])
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("RANDOM arguments must be numbers")
+	}
+	if minFloat > maxFloat {
+		return nil, fmt.Errorf("RANDOM min %v is greater than max %v", minFloat, maxFloat)
+	}
+	return &randomFloat{min: minFloat, max: maxFloat}, nil
+}
+
+func (r *randomInt) Type() string { return "bigint" }
+
+func (r *randomInt) Next(rnd *rand.Rand, _ int64) interface{} {
+	span := uint64(r.max - r.min)
+	if span == math.MaxUint64 {
+		return int64(rnd.Uint64())
+	}
+	return r.min + int64(uint64(rnd.Int63())%(span+1))
+}
+
+func (r *randomFloat) Type() string { return "double" }
+
+func (r *randomFloat) Next(rnd *rand.Rand, _ int64) interface{} {
+	return r.min + rnd.Float64()*(r.max-r.min)
+}
+
+// choice 从候选值中随机选择
+type choice struct {
+	values []interface{}
+	typ    string
+}
+
+func newChoice(args []interface{}) (Generator, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("CHOICE needs at least 1 argument")
+	}
+	c := &choice{values: args, typ: "bigint"}
+	for _, arg := range args {
+		switch arg.(type) {
+		case string:
+			c.typ = "varchar"
+		case float64:
+			if c.typ != "varchar" {
+				c.typ = "double"
+			}
+		}
+	}
+	// 混合类型时统一转换，避免同一列出现不同类型的值
+	if c.typ != "bigint" {
+		c.values = make([]interface{}, len(args))
+		for i, arg := range args {
+			if c.typ == "varchar" {
+				c.values[i] = fmt.Sprint(arg)
+			} else {
+				c.values[i], _ = toFloat(arg)
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c *choice) Type() string { return c.typ }
+
+func (c *choice) Next(rnd *rand.Rand, _ int64) interface{} {
+	return c.values[rnd.Intn(len(c.values))]
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
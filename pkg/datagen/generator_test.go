@@ -0,0 +1,91 @@
+package datagen
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequence(t *testing.T) {
+	gen, err := New("sequence", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bigint", gen.Type())
+	assert.Equal(t, int64(1), gen.Next(nil, 0))
+	assert.Equal(t, int64(10), gen.Next(nil, 9))
+
+	gen, err = New("SEQUENCE", []interface{}{int64(100), int64(-5)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(90), gen.Next(nil, 2))
+
+	_, err = New("SEQUENCE", []interface{}{1.5})
+	assert.Error(t, err)
+}
+
+func TestRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	gen, err := New("RANDOM", []interface{}{int64(-3), int64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, "bigint", gen.Type())
+	seen := map[int64]bool{}
+	for i := 0; i < 1000; i++ {
+		v := gen.Next(rnd, int64(i)).(int64)
+		assert.True(t, v >= -3 && v <= 3, v)
+		seen[v] = true
+	}
+	assert.Len(t, seen, 7)
+
+	gen, err = New("RANDOM", []interface{}{int64(0), 1.5})
+	require.NoError(t, err)
+	assert.Equal(t, "double", gen.Type())
+	for i := 0; i < 100; i++ {
+		v := gen.Next(rnd, int64(i)).(float64)
+		assert.True(t, v >= 0 && v <= 1.5, v)
+	}
+
+	_, err = New("RANDOM", []interface{}{int64(5), int64(1)})
+	assert.Error(t, err)
+	_, err = New("RANDOM", []interface{}{"a", "b"})
+	assert.Error(t, err)
+}
+
+func TestChoice(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	gen, err := New("CHOICE", []interface{}{"a", int64(2)})
+	require.NoError(t, err)
+	assert.Equal(t, "varchar", gen.Type())
+	for i := 0; i < 20; i++ {
+		assert.Contains(t, []interface{}{"a", "2"}, gen.Next(rnd, 0))
+	}
+
+	_, err = New("CHOICE", nil)
+	assert.Error(t, err)
+}
+
+func TestFaker(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	patterns := map[string]*regexp.Regexp{
+		"email": regexp.MustCompile(`^[a-z]+\.[a-z]+\d+@[a-z.]+$`),
+		"uuid":  regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		"ipv4":  regexp.MustCompile(`^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`),
+		"name":  regexp.MustCompile(`^[A-Z][a-z]+ [A-Z][a-z]+$`),
+	}
+	for _, kind := range FakerKinds() {
+		gen, err := New("FAKER", []interface{}{kind})
+		require.NoError(t, err, kind)
+		assert.Equal(t, "varchar", gen.Type())
+		v, ok := gen.Next(rnd, 0).(string)
+		require.True(t, ok, kind)
+		assert.NotEmpty(t, v, kind)
+		if re, ok := patterns[kind]; ok {
+			assert.Regexp(t, re, v)
+		}
+	}
+
+	_, err := New("FAKER", []interface{}{"planet"})
+	assert.Error(t, err)
+	_, err = New("UNKNOWN", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,314 @@
+package dataimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnKind 列类型的大类，决定文件中的值如何转换
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindDate
+	kindDatetime
+)
+
+// kindOf 返回列类型对应的大类，未知类型按字符串处理
+func kindOf(colType string) columnKind {
+	t := strings.ToLower(strings.TrimSpace(colType))
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.TrimSpace(strings.TrimSuffix(t, " unsigned"))
+	switch t {
+	case "int", "integer", "bigint", "smallint", "mediumint", "tinyint", "year",
+		"int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return kindInt
+	case "float", "double", "decimal", "numeric", "real", "float32", "float64":
+		return kindFloat
+	case "bool", "boolean":
+		return kindBool
+	case "date":
+		return kindDate
+	case "datetime", "timestamp":
+		return kindDatetime
+	}
+	return kindString
+}
+
+// columnType 推断出的大类在新建表中使用的列类型
+func columnType(kind columnKind) string {
+	switch kind {
+	case kindInt:
+		return "bigint"
+	case kindFloat:
+		return "double"
+	case kindBool:
+		return "boolean"
+	case kindDate:
+		return "date"
+	case kindDatetime:
+		return "datetime"
+	}
+	return "varchar"
+}
+
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02 15:04:05"
+)
+
+// datetimeLayouts 可识别的日期时间格式
+var datetimeLayouts = []string{
+	datetimeLayout,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+func parseDatetime(s string) (time.Time, bool) {
+	for _, layout := range datetimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inferKind 根据样本值推断列的大类：全部为整数时为整数，全部为数字时为浮点数，
+// 依次再尝试布尔、日期和日期时间，都不满足时为字符串
+func inferKind(values []interface{}) columnKind {
+	isInt, isFloat, isBool, isDate, isDatetime := true, true, true, true, true
+	seen := false
+	for _, v := range values {
+		switch x := v.(type) {
+		case nil:
+			continue
+		case string:
+			s := strings.TrimSpace(x)
+			if s == "" {
+				continue
+			}
+			seen = true
+			_, errInt := strconv.ParseInt(s, 10, 64)
+			_, errFloat := strconv.ParseFloat(s, 64)
+			isInt = isInt && errInt == nil
+			isFloat = isFloat && errFloat == nil
+			isBool = isBool && (strings.EqualFold(s, "true") || strings.EqualFold(s, "false"))
+			_, errDate := time.Parse(dateLayout, s)
+			_, okDatetime := parseDatetime(s)
+			isDate = isDate && errDate == nil
+			isDatetime = isDatetime && (errDate == nil || okDatetime)
+		case json.Number:
+			seen = true
+			_, errInt := x.Int64()
+			isInt = isInt && errInt == nil
+			isBool, isDate, isDatetime = false, false, false
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			seen = true
+			isBool, isDate, isDatetime = false, false, false
+		case float32, float64:
+			seen = true
+			isInt, isBool, isDate, isDatetime = false, false, false, false
+		case bool:
+			seen = true
+			isInt, isFloat, isDate, isDatetime = false, false, false, false
+		case time.Time:
+			seen = true
+			isInt, isFloat, isBool, isDate = false, false, false, false
+		default:
+			return kindString
+		}
+	}
+	switch {
+	case !seen:
+		return kindString
+	case isInt:
+		return kindInt
+	case isFloat:
+		return kindFloat
+	case isBool:
+		return kindBool
+	case isDate:
+		return kindDate
+	case isDatetime:
+		return kindDatetime
+	}
+	return kindString
+}
+
+// convertValue 把文件中的值转换为列类型的值；空字符串在非字符串列中视为 NULL
+func convertValue(v interface{}, kind columnKind) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if s, ok := v.(string); ok && kind != kindString {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, nil
+		}
+		v = s
+	}
+	switch kind {
+	case kindInt:
+		return toInt(v)
+	case kindFloat:
+		return toFloat(v)
+	case kindBool:
+		return toBool(v)
+	case kindDate, kindDatetime:
+		return toTime(v, kind)
+	}
+	return toString(v)
+}
+
+func toInt(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		if n, err := strconv.ParseInt(x, 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(x, 64); err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			return int64(f), nil
+		}
+		if b, err := strconv.ParseBool(x); err == nil {
+			return boolToInt(b), nil
+		}
+		return nil, fmt.Errorf("invalid integer %q", x)
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return n, nil
+		}
+		return toInt(x.String())
+	case int:
+		return int64(x), nil
+	case int8:
+		return int64(x), nil
+	case int16:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case uint:
+		return int64(x), nil
+	case uint8:
+		return int64(x), nil
+	case uint16:
+		return int64(x), nil
+	case uint32:
+		return int64(x), nil
+	case uint64:
+		if x > math.MaxInt64 {
+			return nil, fmt.Errorf("integer %d out of range", x)
+		}
+		return int64(x), nil
+	case float32:
+		return toInt(strconv.FormatFloat(float64(x), 'f', -1, 32))
+	case float64:
+		return toInt(strconv.FormatFloat(x, 'f', -1, 64))
+	case bool:
+		return boolToInt(x), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to integer", v)
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", x)
+		}
+		return f, nil
+	case json.Number:
+		return toFloat(x.String())
+	case float32:
+		return float64(x), nil
+	case float64:
+		return x, nil
+	case bool:
+		return float64(boolToInt(x)), nil
+	}
+	n, err := toInt(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %T to number", v)
+	}
+	return float64(n.(int64)), nil
+}
+
+func toBool(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		b, err := strconv.ParseBool(x)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", x)
+		}
+		return b, nil
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %T to boolean", v)
+	}
+	return f.(float64) != 0, nil
+}
+
+// toTime 日期和日期时间以 MySQL 格式的字符串存储
+func toTime(v interface{}, kind columnKind) (interface{}, error) {
+	layout := datetimeLayout
+	if kind == kindDate {
+		layout = dateLayout
+	}
+	switch x := v.(type) {
+	case time.Time:
+		return x.Format(layout), nil
+	case string:
+		if t, err := time.Parse(dateLayout, x); err == nil {
+			return t.Format(layout), nil
+		}
+		if t, ok := parseDatetime(x); ok {
+			return t.Format(layout), nil
+		}
+		return nil, fmt.Errorf("invalid date %q", x)
+	}
+	return nil, fmt.Errorf("cannot convert %T to date", v)
+}
+
+func toString(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case []byte:
+		return string(x), nil
+	case json.Number:
+		return x.String(), nil
+	case time.Time:
+		return x.Format(datetimeLayout), nil
+	case map[string]interface{}, []interface{}:
+		// 嵌套的 JSON 对象和数组以 JSON 文本存储
+		b, err := json.Marshal(x)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	return fmt.Sprint(v), nil
+}
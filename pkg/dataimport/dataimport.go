@@ -0,0 +1,499 @@
+// Package dataimport 把 CSV、JSON 和 Parquet 文件导入表：表不存在时根据文件内容推断表结构并建表，
+// 表已存在时按字段名映射到列；记录的类型转换在多个 goroutine 中并行进行，
+// 错误行可以中止导入、跳过或收集，导入进度通过 domain.ReportProgress 报告
+package dataimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// Format 文件格式
+type Format string
+
+const (
+	FormatCSV     Format = "CSV"
+	FormatJSON    Format = "JSON"
+	FormatParquet Format = "PARQUET"
+)
+
+// ErrorMode 遇到错误行时的处理方式
+type ErrorMode string
+
+const (
+	OnErrorAbort   ErrorMode = "abort"   // 中止导入
+	OnErrorSkip    ErrorMode = "skip"    // 跳过错误行
+	OnErrorCollect ErrorMode = "collect" // 跳过错误行并在结果中返回
+)
+
+const (
+	// defaultBatchSize 默认每批转换和写入的行数
+	defaultBatchSize = 10000
+	// sampleSize 推断表结构使用的记录数
+	sampleSize = 1000
+	// maxCollectedRows collect 模式下最多返回的错误行数
+	maxCollectedRows = 1000
+)
+
+// Options 导入选项
+type Options struct {
+	Format    Format
+	Columns   []string  // 按顺序替换文件的字段名（JSON 不支持），为空时使用文件中的字段名
+	Header    bool      // CSV 首行是否为字段名，默认 true
+	Delimiter rune      // CSV 分隔符，默认 ','
+	ArrayRoot string    // JSON 数据数组所在的顶层字段，为空时文件本身是数组或 JSON Lines
+	OnError   ErrorMode // 默认 abort
+	MaxErrors int       // skip 和 collect 模式下允许的最多错误行数，0 表示不限制
+	Workers   int       // 并行转换记录的 goroutine 数，默认 GOMAXPROCS
+	BatchSize int       // 每批转换和写入的行数
+}
+
+// DefaultOptions 返回格式的默认选项
+func DefaultOptions(format Format) Options {
+	return Options{
+		Format:    format,
+		Header:    true,
+		Delimiter: ',',
+		OnError:   OnErrorAbort,
+		Workers:   runtime.GOMAXPROCS(0),
+		BatchSize: defaultBatchSize,
+	}
+}
+
+// ParseOptions 解析 IMPORT DATA 语句的格式、列清单和 WITH 选项，选项名不区分大小写
+func ParseOptions(format string, columns []string, values map[string]string) (Options, error) {
+	f := Format(strings.ToUpper(format))
+	switch f {
+	case FormatCSV, FormatJSON, FormatParquet:
+	default:
+		return Options{}, fmt.Errorf("unsupported format %s (CSV, JSON, PARQUET)", format)
+	}
+	opts := DefaultOptions(f)
+	if len(columns) > 0 && f == FormatJSON {
+		return Options{}, fmt.Errorf("column list is not supported for JSON, fields are matched by name")
+	}
+	opts.Columns = columns
+
+	for key, value := range values {
+		var err error
+		switch strings.ToLower(key) {
+		case "header":
+			if f != FormatCSV {
+				return Options{}, fmt.Errorf("option header only applies to CSV")
+			}
+			opts.Header, err = strconv.ParseBool(value)
+		case "delimiter":
+			if f != FormatCSV {
+				return Options{}, fmt.Errorf("option delimiter only applies to CSV")
+			}
+			opts.Delimiter, err = parseDelimiter(value)
+		case "array_root":
+			if f != FormatJSON {
+				return Options{}, fmt.Errorf("option array_root only applies to JSON")
+			}
+			opts.ArrayRoot = value
+		case "on_error":
+			switch mode := ErrorMode(strings.ToLower(value)); mode {
+			case OnErrorAbort, OnErrorSkip, OnErrorCollect:
+				opts.OnError = mode
+			default:
+				err = fmt.Errorf("must be abort, skip or collect")
+			}
+		case "max_errors":
+			opts.MaxErrors, err = parseCount(value, 0)
+		case "workers":
+			opts.Workers, err = parseCount(value, 1)
+		case "batch_size":
+			opts.BatchSize, err = parseCount(value, 1)
+		default:
+			return Options{}, fmt.Errorf("unknown option %s (header, delimiter, array_root, on_error, max_errors, workers, batch_size)", key)
+		}
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid %s %q: %w", strings.ToLower(key), value, err)
+		}
+	}
+	return opts, nil
+}
+
+func parseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("must be a single character")
+	}
+	return r, nil
+}
+
+func parseCount(value string, min int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("must be an integer")
+	}
+	if n < min {
+		return 0, fmt.Errorf("must be at least %d", min)
+	}
+	return n, nil
+}
+
+// BadRow 无法导入的行
+type BadRow struct {
+	Line  int64  `json:"line"` // CSV 为行号，JSON 为元素序号，Parquet 为行序号
+	Error string `json:"error"`
+}
+
+func (b BadRow) String() string {
+	return fmt.Sprintf("line %d: %s", b.Line, b.Error)
+}
+
+// Result 导入结果
+type Result struct {
+	Records int64    `json:"records"`            // 读取的记录数
+	Rows    int64    `json:"rows"`               // 写入的行数
+	Skipped int64    `json:"skipped"`            // 跳过的错误行数
+	BadRows []BadRow `json:"bad_rows,omitempty"` // collect 模式下收集的错误行，最多 1000 行
+	Created bool     `json:"created"`            // 表由本次导入创建
+}
+
+// Info 返回与 MySQL LOAD DATA 格式一致的导入信息
+func (r *Result) Info() string {
+	return fmt.Sprintf("Records: %d  Deleted: 0  Skipped: %d  Warnings: %d", r.Records, r.Skipped, len(r.BadRows))
+}
+
+// Import 把 path 指定的文件导入 ds 中的表 table。表不存在时根据前 1000 条记录推断列类型并建表，
+// 导入失败时删除新建的表；表已存在时文件字段按名称（不区分大小写）映射到列，
+// 没有对应列的字段被忽略，失败前已写入的批次会保留
+func Import(ctx context.Context, ds domain.DataSource, table, path string, opts Options) (*Result, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.OnError == "" {
+		opts.OnError = OnErrorAbort
+	}
+
+	src, err := openSource(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer src.close()
+
+	// 预读样本，用于推断表结构，之后与其余记录一起导入
+	var sample []record
+	for len(sample) < sampleSize {
+		rec, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sample = append(sample, rec)
+	}
+
+	info, err := ds.GetTableInfo(ctx, table)
+	created := false
+	if err != nil {
+		var notFound *domain.ErrTableNotFound
+		if !errors.As(err, &notFound) {
+			return nil, err
+		}
+		if info, err = inferTable(table, src.fields(), sample); err != nil {
+			return nil, err
+		}
+		if err := ds.CreateTable(ctx, info); err != nil {
+			return nil, fmt.Errorf("create table failed: %w", err)
+		}
+		created = true
+	}
+
+	imp := &importer{ds: ds, table: table, info: info, src: src, opts: opts, name: filepath.Base(path)}
+	if imp.targets, err = mapColumns(src.fields(), info); err == nil {
+		var res *Result
+		if res, err = imp.run(ctx, sample); err == nil {
+			res.Created = created
+			return res, nil
+		}
+	}
+	if created {
+		_ = ds.DropTable(context.Background(), table)
+	}
+	return nil, err
+}
+
+// inferTable 根据样本记录推断新表的结构，列顺序与文件字段一致
+func inferTable(table string, fields []string, sample []record) (*domain.TableInfo, error) {
+	info := &domain.TableInfo{Name: table}
+	seen := map[string]bool{}
+	for i, name := range fields {
+		if name == "" {
+			continue
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("duplicate field %s", name)
+		}
+		seen[strings.ToLower(name)] = true
+		values := make([]interface{}, 0, len(sample))
+		for _, rec := range sample {
+			if rec.err == nil && i < len(rec.values) {
+				values = append(values, rec.values[i])
+			}
+		}
+		info.Columns = append(info.Columns, domain.ColumnInfo{Name: name, Type: columnType(inferKind(values)), Nullable: true})
+	}
+	if len(info.Columns) == 0 {
+		return nil, fmt.Errorf("file has no fields")
+	}
+	return info, nil
+}
+
+// target 文件字段到表列的映射
+type target struct {
+	field  int
+	column string
+	kind   columnKind
+}
+
+// mapColumns 按名称（不区分大小写）把文件字段映射到表列
+func mapColumns(fields []string, info *domain.TableInfo) ([]target, error) {
+	columns := make(map[string]domain.ColumnInfo, len(info.Columns))
+	for _, col := range info.Columns {
+		columns[strings.ToLower(col.Name)] = col
+	}
+	var targets []target
+	for i, name := range fields {
+		col, ok := columns[strings.ToLower(name)]
+		if name == "" || !ok {
+			continue
+		}
+		targets = append(targets, target{field: i, column: col.Name, kind: kindOf(col.Type)})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no field in the file matches a column of table '%s'", info.Name)
+	}
+	return targets, nil
+}
+
+// chunk 一批记录，由读取 goroutine 按顺序产生、转换 goroutine 并行转换、写入方按顺序写入
+type chunk struct {
+	records []record
+	rows    []domain.Row
+	lines   []int64 // rows 对应的行号
+	bad     []BadRow
+	err     error // 读取文件失败，导入终止
+	done    chan struct{}
+}
+
+// importer 一次导入的状态
+type importer struct {
+	ds      domain.DataSource
+	table   string
+	info    *domain.TableInfo
+	src     source
+	opts    Options
+	name    string
+	targets []target
+}
+
+func (imp *importer) run(ctx context.Context, sample []record) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	work := make(chan *chunk, imp.opts.Workers)
+	ordered := make(chan *chunk, imp.opts.Workers*2)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		imp.read(ctx, sample, work, ordered)
+	}()
+	for i := 0; i < imp.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range work {
+				imp.convert(c)
+				close(c.done)
+			}
+		}()
+	}
+
+	res := &Result{}
+	for c := range ordered {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if c.err != nil {
+			return nil, c.err
+		}
+		res.Records += int64(len(c.records))
+		for _, bad := range c.bad {
+			if err := imp.reject(res, bad); err != nil {
+				return nil, err
+			}
+		}
+		if err := imp.insert(ctx, res, c); err != nil {
+			return nil, err
+		}
+		done, total := imp.src.progress()
+		domain.ReportProgress(ctx, domain.Progress{
+			Stage: 1, MaxStage: 1, Done: done, Total: total,
+			Info: fmt.Sprintf("importing %s into %s: %d rows", imp.name, imp.table, res.Rows),
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// read 按顺序读取记录并分批发送：每批同时进入 work（转换）和 ordered（按顺序写入）
+func (imp *importer) read(ctx context.Context, sample []record, work, ordered chan<- *chunk) {
+	defer close(work)
+	defer close(ordered)
+
+	send := func(c *chunk) bool {
+		if c.err != nil {
+			close(c.done)
+		}
+		select {
+		case ordered <- c:
+		case <-ctx.Done():
+			return false
+		}
+		if c.err != nil {
+			return false
+		}
+		select {
+		case work <- c:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	batch := make([]record, 0, imp.opts.BatchSize)
+	for {
+		var rec record
+		var err error
+		if len(sample) > 0 {
+			rec, sample = sample[0], sample[1:]
+		} else {
+			rec, err = imp.src.next()
+		}
+		if err != nil {
+			if len(batch) > 0 && !send(&chunk{records: batch, done: make(chan struct{})}) {
+				return
+			}
+			if err != io.EOF {
+				send(&chunk{err: fmt.Errorf("failed to read %s: %w", imp.name, err), done: make(chan struct{})})
+			}
+			return
+		}
+		batch = append(batch, rec)
+		if len(batch) == imp.opts.BatchSize {
+			if !send(&chunk{records: batch, done: make(chan struct{})}) {
+				return
+			}
+			batch = make([]record, 0, imp.opts.BatchSize)
+		}
+	}
+}
+
+// convert 把一批记录转换为表的行，无法转换的记录放入 bad
+func (imp *importer) convert(c *chunk) {
+	c.rows = make([]domain.Row, 0, len(c.records))
+	for _, rec := range c.records {
+		if rec.err != nil {
+			c.bad = append(c.bad, BadRow{Line: rec.line, Error: rec.err.Error()})
+			continue
+		}
+		row := make(domain.Row, len(imp.targets))
+		var rowErr error
+		for _, t := range imp.targets {
+			if t.field >= len(rec.values) {
+				continue
+			}
+			v, err := convertValue(rec.values[t.field], t.kind)
+			if err != nil {
+				rowErr = fmt.Errorf("column %s: %w", t.column, err)
+				break
+			}
+			if v != nil {
+				row[t.column] = v
+			}
+		}
+		if rowErr != nil {
+			c.bad = append(c.bad, BadRow{Line: rec.line, Error: rowErr.Error()})
+			continue
+		}
+		utils.ConvertBoolColumnsBasedOnSchema(row, imp.info)
+		c.rows = append(c.rows, row)
+		c.lines = append(c.lines, rec.line)
+	}
+}
+
+// reject 按错误处理方式记录一个错误行，需要中止导入时返回错误
+func (imp *importer) reject(res *Result, bad BadRow) error {
+	if imp.opts.OnError == OnErrorAbort {
+		return fmt.Errorf("%s: %s", imp.name, bad)
+	}
+	res.Skipped++
+	if imp.opts.MaxErrors > 0 && res.Skipped > int64(imp.opts.MaxErrors) {
+		return fmt.Errorf("%s: too many bad rows (more than %d), last %s", imp.name, imp.opts.MaxErrors, bad)
+	}
+	if imp.opts.OnError == OnErrorCollect && len(res.BadRows) < maxCollectedRows {
+		res.BadRows = append(res.BadRows, bad)
+	}
+	return nil
+}
+
+// insert 写入一批行；批量写入失败且允许跳过错误行时逐行重试，找出失败的行
+func (imp *importer) insert(ctx context.Context, res *Result, c *chunk) error {
+	if len(c.rows) == 0 {
+		return nil
+	}
+	n, err := imp.ds.Insert(ctx, imp.table, c.rows, &domain.InsertOptions{})
+	if err == nil {
+		res.Rows += n
+		return nil
+	}
+	if imp.opts.OnError == OnErrorAbort {
+		return fmt.Errorf("%s: lines %d-%d: insert failed: %w", imp.name, c.lines[0], c.lines[len(c.lines)-1], err)
+	}
+	for i, row := range c.rows {
+		n, err := imp.ds.Insert(ctx, imp.table, []domain.Row{row}, &domain.InsertOptions{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := imp.reject(res, BadRow{Line: c.lines[i], Error: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		res.Rows += n
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package dataimport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDataSource(t *testing.T) domain.DataSource {
+	t.Helper()
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	t.Cleanup(func() { ds.Close(context.Background()) })
+	return ds
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// queryRows 按 id 排序返回表中的所有行
+func queryRows(t *testing.T, ds domain.DataSource, table string) []domain.Row {
+	t.Helper()
+	result, err := ds.Query(context.Background(), table, &domain.QueryOptions{})
+	require.NoError(t, err)
+	rows := result.Rows
+	sort.Slice(rows, func(i, j int) bool { return rows[i]["id"].(int64) < rows[j]["id"].(int64) })
+	return rows
+}
+
+func columnTypes(t *testing.T, ds domain.DataSource, table string) map[string]string {
+	t.Helper()
+	info, err := ds.GetTableInfo(context.Background(), table)
+	require.NoError(t, err)
+	types := map[string]string{}
+	for _, col := range info.Columns {
+		types[col.Name] = col.Type
+	}
+	return types
+}
+
+func TestImportCSVCreatesTable(t *testing.T) {
+	ds := newTestDataSource(t)
+	path := writeTestFile(t, "users.csv", "\ufeffid,name,score,active,joined\n"+
+		"1,alice,9.5,true,2024-01-02\n"+
+		"2,\"bob, jr\",7,false,2024-02-03\n"+
+		"3,carol,,true,\\N\n")
+
+	res, err := Import(context.Background(), ds, "users", path, DefaultOptions(FormatCSV))
+	require.NoError(t, err)
+	assert.True(t, res.Created)
+	assert.EqualValues(t, 3, res.Records)
+	assert.EqualValues(t, 3, res.Rows)
+	assert.Equal(t, "Records: 3  Deleted: 0  Skipped: 0  Warnings: 0", res.Info())
+
+	assert.Equal(t, map[string]string{
+		"id": "bigint", "name": "varchar", "score": "double", "active": "boolean", "joined": "date",
+	}, columnTypes(t, ds, "users"))
+
+	rows := queryRows(t, ds, "users")
+	require.Len(t, rows, 3)
+	assert.Equal(t, "bob, jr", rows[1]["name"])
+	assert.Equal(t, 9.5, rows[0]["score"])
+	assert.Equal(t, "2024-01-02", rows[0]["joined"])
+	assert.Nil(t, rows[2]["score"])
+	assert.Nil(t, rows[2]["joined"])
+}
+
+func TestImportCSVIntoExistingTable(t *testing.T) {
+	ds := newTestDataSource(t)
+	require.NoError(t, ds.CreateTable(context.Background(), &domain.TableInfo{
+		Name: "items",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "int", Primary: true},
+			{Name: "Title", Type: "varchar(64)"},
+			{Name: "price", Type: "decimal(10,2)"},
+		},
+	}))
+	// 字段名不区分大小写，没有对应列的字段被忽略
+	path := writeTestFile(t, "items.csv", "ID,title,price,ignored\n1,pen,1.25,x\n2,book,12,y\n")
+
+	res, err := Import(context.Background(), ds, "items", path, DefaultOptions(FormatCSV))
+	require.NoError(t, err)
+	assert.False(t, res.Created)
+	assert.EqualValues(t, 2, res.Rows)
+
+	rows := queryRows(t, ds, "items")
+	require.Len(t, rows, 2)
+	assert.Equal(t, "book", rows[1]["Title"])
+	assert.Equal(t, 12.0, rows[1]["price"])
+	assert.NotContains(t, rows[0], "ignored")
+}
+
+func TestImportCSVWithoutHeader(t *testing.T) {
+	ds := newTestDataSource(t)
+	path := writeTestFile(t, "data.tsv", "1\tx\n2\ty\n")
+
+	opts, err := ParseOptions("csv", []string{"id", "code"}, map[string]string{"header": "false", "delimiter": `\t`})
+	require.NoError(t, err)
+	res, err := Import(context.Background(), ds, "codes", path, opts)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.Rows)
+
+	rows := queryRows(t, ds, "codes")
+	require.Len(t, rows, 2)
+	assert.Equal(t, "y", rows[1]["code"])
+}
+
+func TestImportErrorModes(t *testing.T) {
+	content := "id,amount\n1,10\n2,oops\n3,30\n4,40,extra\n5,50\n"
+	setup := func(t *testing.T) domain.DataSource {
+		ds := newTestDataSource(t)
+		require.NoError(t, ds.CreateTable(context.Background(), &domain.TableInfo{
+			Name: "orders",
+			Columns: []domain.ColumnInfo{
+				{Name: "id", Type: "bigint", Primary: true},
+				{Name: "amount", Type: "bigint"},
+			},
+		}))
+		return ds
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		ds := setup(t)
+		path := writeTestFile(t, "orders.csv", content)
+		_, err := Import(context.Background(), ds, "orders", path, DefaultOptions(FormatCSV))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 3")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		ds := setup(t)
+		path := writeTestFile(t, "orders.csv", content)
+		opts := DefaultOptions(FormatCSV)
+		opts.OnError = OnErrorSkip
+		res, err := Import(context.Background(), ds, "orders", path, opts)
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, res.Records)
+		assert.EqualValues(t, 3, res.Rows)
+		assert.EqualValues(t, 2, res.Skipped)
+		assert.Empty(t, res.BadRows)
+		assert.Len(t, queryRows(t, ds, "orders"), 3)
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		ds := setup(t)
+		path := writeTestFile(t, "orders.csv", content)
+		opts := DefaultOptions(FormatCSV)
+		opts.OnError = OnErrorCollect
+		res, err := Import(context.Background(), ds, "orders", path, opts)
+		require.NoError(t, err)
+		require.Len(t, res.BadRows, 2)
+		assert.EqualValues(t, 3, res.BadRows[0].Line)
+		assert.Contains(t, res.BadRows[0].Error, "amount")
+		assert.EqualValues(t, 5, res.BadRows[1].Line)
+		assert.Equal(t, "Records: 5  Deleted: 0  Skipped: 2  Warnings: 2", res.Info())
+	})
+
+	t.Run("max errors", func(t *testing.T) {
+		ds := setup(t)
+		path := writeTestFile(t, "orders.csv", content)
+		opts := DefaultOptions(FormatCSV)
+		opts.OnError = OnErrorSkip
+		opts.MaxErrors = 1
+		_, err := Import(context.Background(), ds, "orders", path, opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many bad rows")
+	})
+
+	t.Run("duplicate key retried row by row", func(t *testing.T) {
+		ds := setup(t)
+		path := writeTestFile(t, "orders.csv", "id,amount\n1,10\n1,11\n2,20\n")
+		opts := DefaultOptions(FormatCSV)
+		opts.OnError = OnErrorCollect
+		res, err := Import(context.Background(), ds, "orders", path, opts)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, res.Rows)
+		require.Len(t, res.BadRows, 1)
+		assert.EqualValues(t, 3, res.BadRows[0].Line)
+	})
+}
+
+func TestImportDropsCreatedTableOnFailure(t *testing.T) {
+	ds := newTestDataSource(t)
+	// 推断类型只使用前 1000 条记录，之后的 x 无法转换为整数
+	content := "id\n"
+	for i := 1; i <= sampleSize; i++ {
+		content += strconv.Itoa(i) + "\n"
+	}
+	path := writeTestFile(t, "ids.csv", content+"x\n")
+
+	_, err := Import(context.Background(), ds, "ids", path, DefaultOptions(FormatCSV))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1002")
+	_, err = ds.GetTableInfo(context.Background(), "ids")
+	assert.Error(t, err)
+}
+
+func TestImportJSON(t *testing.T) {
+	t.Run("array", func(t *testing.T) {
+		ds := newTestDataSource(t)
+		path := writeTestFile(t, "events.json", `[
+			{"id": 1, "kind": "click", "meta": {"x": 1}},
+			{"id": 2, "kind": "view", "ratio": 0.5},
+			42
+		]`)
+		opts := DefaultOptions(FormatJSON)
+		opts.OnError = OnErrorCollect
+		res, err := Import(context.Background(), ds, "events", path, opts)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, res.Rows)
+		require.Len(t, res.BadRows, 1)
+		assert.EqualValues(t, 3, res.BadRows[0].Line)
+
+		assert.Equal(t, map[string]string{"id": "bigint", "kind": "varchar", "meta": "varchar", "ratio": "double"},
+			columnTypes(t, ds, "events"))
+		rows := queryRows(t, ds, "events")
+		assert.Equal(t, `{"x":1}`, rows[0]["meta"])
+		assert.Equal(t, 0.5, rows[1]["ratio"])
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		ds := newTestDataSource(t)
+		path := writeTestFile(t, "events.ndjson", "{\"id\": 1, \"ok\": true}\n{\"id\": 2, \"ok\": false}\n")
+		res, err := Import(context.Background(), ds, "events", path, DefaultOptions(FormatJSON))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, res.Rows)
+		assert.Equal(t, "boolean", columnTypes(t, ds, "events")["ok"])
+	})
+
+	t.Run("array root", func(t *testing.T) {
+		ds := newTestDataSource(t)
+		path := writeTestFile(t, "export.json", `{"count": 2, "data": [{"id": 1}, {"id": 2}]}`)
+		opts, err := ParseOptions("JSON", nil, map[string]string{"array_root": "data"})
+		require.NoError(t, err)
+		res, err := Import(context.Background(), ds, "export", path, opts)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, res.Rows)
+	})
+}
+
+func TestImportParquet(t *testing.T) {
+	type item struct {
+		ID   int64   `parquet:"id"`
+		Name string  `parquet:"name"`
+		Cost float64 `parquet:"cost"`
+	}
+	path := filepath.Join(t.TempDir(), "items.parquet")
+	require.NoError(t, pq.WriteFile(path, []item{{1, "a", 1.5}, {2, "b", 2.5}, {3, "c", 3.5}}))
+
+	ds := newTestDataSource(t)
+	var last domain.Progress
+	ctx := domain.WithProgress(context.Background(), func(p domain.Progress) { last = p })
+	opts := DefaultOptions(FormatParquet)
+	opts.BatchSize = 2
+	res, err := Import(ctx, ds, "items", path, opts)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.Rows)
+	assert.EqualValues(t, 3, last.Done)
+	assert.EqualValues(t, 3, last.Total)
+
+	rows := queryRows(t, ds, "items")
+	require.Len(t, rows, 3)
+	assert.Equal(t, "c", rows[2]["name"])
+	assert.Equal(t, 3.5, rows[2]["cost"])
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions("csv", nil, map[string]string{"HEADER": "0", "delimiter": ";", "on_error": "SKIP", "workers": "2", "batch_size": "100"})
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, opts.Format)
+	assert.False(t, opts.Header)
+	assert.Equal(t, ';', opts.Delimiter)
+	assert.Equal(t, OnErrorSkip, opts.OnError)
+	assert.Equal(t, 2, opts.Workers)
+	assert.Equal(t, 100, opts.BatchSize)
+
+	for _, tc := range []struct {
+		format  string
+		columns []string
+		values  map[string]string
+	}{
+		{"xml", nil, nil},
+		{"json", []string{"a"}, nil},
+		{"json", nil, map[string]string{"header": "true"}},
+		{"csv", nil, map[string]string{"array_root": "data"}},
+		{"csv", nil, map[string]string{"delimiter": "ab"}},
+		{"csv", nil, map[string]string{"on_error": "ignore"}},
+		{"csv", nil, map[string]string{"workers": "0"}},
+		{"csv", nil, map[string]string{"unknown": "1"}},
+	} {
+		_, err := ParseOptions(tc.format, tc.columns, tc.values)
+		assert.Error(t, err, "%v", tc)
+	}
+}
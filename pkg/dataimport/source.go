@@ -0,0 +1,374 @@
+package dataimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/parquet"
+)
+
+// record 文件中的一条记录，values 与 source.fields() 一一对应
+type record struct {
+	line   int64 // CSV 为行号，JSON 为元素序号，Parquet 为行序号，均从 1 开始
+	values []interface{}
+	err    error // 记录本身无法解析（字段数不符、不是 JSON 对象等）
+}
+
+// source 按顺序读取文件中的记录
+type source interface {
+	// fields 文件的字段名，名称为空的字段不导入
+	fields() []string
+	// next 读取下一条记录，读完时返回 io.EOF；单条记录的错误放在 record.err 中
+	next() (record, error)
+	// progress 已读取的工作量和总工作量（CSV 和 JSON 为字节数，Parquet 为行数）
+	progress() (done, total int64)
+	close() error
+}
+
+func openSource(path string, opts Options) (source, error) {
+	switch opts.Format {
+	case FormatCSV:
+		return openCSV(path, opts)
+	case FormatJSON:
+		return openJSON(path, opts)
+	case FormatParquet:
+		return openParquet(path, opts)
+	}
+	return nil, fmt.Errorf("unsupported format %s", opts.Format)
+}
+
+// renameFields 用列清单替换前 len(columns) 个字段名，其余字段不导入
+func renameFields(names, columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return names, nil
+	}
+	if len(columns) > len(names) {
+		return nil, fmt.Errorf("column list has %d columns but the file has %d fields", len(columns), len(names))
+	}
+	renamed := make([]string, len(names))
+	copy(renamed, columns)
+	return renamed, nil
+}
+
+// countingReader 统计已读取的字节数，供进度报告使用
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// csvSource 读取 CSV 文件
+type csvSource struct {
+	file    *os.File
+	counter *countingReader
+	size    int64
+	reader  *csv.Reader
+	names   []string
+	width   int     // 每条记录应有的字段数
+	pending *record // 无表头时为确定字段数预读的第一条记录
+}
+
+func openCSV(path string, opts Options) (*csvSource, error) {
+	f, size, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &csvSource{file: f, size: size, counter: &countingReader{r: f}}
+	s.reader = csv.NewReader(s.counter)
+	s.reader.Comma = opts.Delimiter
+	s.reader.FieldsPerRecord = -1
+
+	var names []string
+	if opts.Header {
+		header, err := s.reader.Read()
+		if err != nil {
+			f.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("CSV file %q is empty", path)
+			}
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		for i, name := range header {
+			if i == 0 {
+				name = strings.TrimPrefix(name, "\ufeff")
+			}
+			names = append(names, strings.TrimSpace(name))
+		}
+	} else {
+		first, err := s.next()
+		if err != nil && err != io.EOF {
+			f.Close()
+			return nil, err
+		}
+		if err == nil {
+			s.pending = &first
+			for i := range first.values {
+				names = append(names, fmt.Sprintf("column_%d", i+1))
+			}
+		}
+	}
+	s.width = len(names)
+	if s.names, err = renameFields(names, opts.Columns); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *csvSource) fields() []string { return s.names }
+
+func (s *csvSource) next() (record, error) {
+	if s.pending != nil {
+		rec := *s.pending
+		s.pending = nil
+		return rec, nil
+	}
+	values, err := s.reader.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return record{line: int64(pe.StartLine), err: pe.Err}, nil
+		}
+		return record{}, err
+	}
+	line, _ := s.reader.FieldPos(0)
+	rec := record{line: int64(line)}
+	if s.width > 0 && len(values) != s.width {
+		rec.err = fmt.Errorf("expected %d fields, got %d", s.width, len(values))
+		return rec, nil
+	}
+	rec.values = make([]interface{}, len(values))
+	for i, v := range values {
+		// 与 MySQL LOAD DATA 一致，\N 表示 NULL
+		if v != `\N` {
+			rec.values[i] = v
+		}
+	}
+	return rec, nil
+}
+
+func (s *csvSource) progress() (int64, int64) { return s.counter.n.Load(), s.size }
+
+func (s *csvSource) close() error { return s.file.Close() }
+
+// jsonSampleSize 用于确定 JSON 字段的元素数，之后出现的新字段不导入
+const jsonSampleSize = 1000
+
+// jsonSource 读取 JSON 数组、array_root 指定的嵌套数组或每行一个对象的 JSON Lines 文件
+type jsonSource struct {
+	file    *os.File
+	counter *countingReader
+	size    int64
+	decoder *json.Decoder
+	array   bool          // 顶层是数组
+	items   []interface{} // array_root 指定的数组，整体读入
+	fromArr bool          // 从 items 读取
+	n       int64
+	sample  []record
+	names   []string
+	index   map[string]int
+}
+
+func openJSON(path string, opts Options) (*jsonSource, error) {
+	f, size, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &jsonSource{file: f, size: size, counter: &countingReader{r: f}}
+	buffered := bufio.NewReader(s.counter)
+	s.decoder = json.NewDecoder(buffered)
+	s.decoder.UseNumber()
+
+	if opts.ArrayRoot != "" {
+		var root map[string]interface{}
+		if err := s.decoder.Decode(&root); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		items, ok := root[opts.ArrayRoot].([]interface{})
+		if !ok {
+			f.Close()
+			return nil, fmt.Errorf("no JSON array found at %q", opts.ArrayRoot)
+		}
+		s.items, s.fromArr = items, true
+	} else if first, err := peekNonSpace(buffered); err == nil && first == '[' {
+		if _, err := s.decoder.Token(); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		s.array = true
+	}
+
+	// 读取样本确定字段，字段按名称排序
+	seen := map[string]bool{}
+	for len(s.sample) < jsonSampleSize {
+		rec, err := s.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if obj, ok := rec.values[0].(map[string]interface{}); ok {
+			for key := range obj {
+				if !seen[key] {
+					seen[key] = true
+					s.names = append(s.names, key)
+				}
+			}
+		}
+		s.sample = append(s.sample, rec)
+	}
+	sort.Strings(s.names)
+	s.index = make(map[string]int, len(s.names))
+	for i, name := range s.names {
+		s.index[name] = i
+	}
+	return s, nil
+}
+
+// peekNonSpace 返回第一个非空白字符，不消耗输入
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for n := 1; ; n++ {
+		b, err := r.Peek(n)
+		if len(b) < n {
+			return 0, err
+		}
+		switch c := b[n-1]; c {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return c, nil
+		}
+	}
+}
+
+// read 读取下一个 JSON 值，values[0] 为解码后的值
+func (s *jsonSource) read() (record, error) {
+	var v interface{}
+	switch {
+	case s.fromArr:
+		if len(s.items) == 0 {
+			return record{}, io.EOF
+		}
+		v, s.items = s.items[0], s.items[1:]
+	case s.array:
+		if !s.decoder.More() {
+			return record{}, io.EOF
+		}
+		if err := s.decoder.Decode(&v); err != nil {
+			return record{}, fmt.Errorf("failed to parse JSON element %d: %w", s.n+1, err)
+		}
+	default:
+		if err := s.decoder.Decode(&v); err != nil {
+			if err == io.EOF {
+				return record{}, io.EOF
+			}
+			return record{}, fmt.Errorf("failed to parse JSON value %d: %w", s.n+1, err)
+		}
+	}
+	s.n++
+	return record{line: s.n, values: []interface{}{v}}, nil
+}
+
+func (s *jsonSource) fields() []string { return s.names }
+
+func (s *jsonSource) next() (record, error) {
+	var rec record
+	if len(s.sample) > 0 {
+		rec, s.sample = s.sample[0], s.sample[1:]
+	} else {
+		var err error
+		if rec, err = s.read(); err != nil {
+			return record{}, err
+		}
+	}
+	obj, ok := rec.values[0].(map[string]interface{})
+	if !ok {
+		return record{line: rec.line, err: fmt.Errorf("expected a JSON object")}, nil
+	}
+	values := make([]interface{}, len(s.names))
+	for key, v := range obj {
+		if i, ok := s.index[key]; ok {
+			values[i] = v
+		}
+	}
+	rec.values = values
+	return rec, nil
+}
+
+func (s *jsonSource) progress() (int64, int64) { return s.counter.n.Load(), s.size }
+
+func (s *jsonSource) close() error { return s.file.Close() }
+
+// parquetSource 读取 Parquet 文件，文件整体读入内存
+type parquetSource struct {
+	names   []string
+	columns []string // 原始列名
+	data    []domain.Row
+	pos     int
+	done    atomic.Int64
+}
+
+func openParquet(path string, opts Options) (*parquetSource, error) {
+	info, rows, err := parquet.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &parquetSource{data: rows}
+	for _, col := range info.Columns {
+		s.columns = append(s.columns, col.Name)
+	}
+	if s.names, err = renameFields(s.columns, opts.Columns); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *parquetSource) fields() []string { return s.names }
+
+func (s *parquetSource) next() (record, error) {
+	if s.pos >= len(s.data) {
+		return record{}, io.EOF
+	}
+	row := s.data[s.pos]
+	s.data[s.pos] = nil
+	s.pos++
+	s.done.Store(int64(s.pos))
+	values := make([]interface{}, len(s.columns))
+	for i, name := range s.columns {
+		values[i] = row[name]
+	}
+	return record{line: int64(s.pos), values: values}, nil
+}
+
+func (s *parquetSource) progress() (int64, int64) { return s.done.Load(), int64(len(s.data)) }
+
+func (s *parquetSource) close() error { return nil }
+
+func openFile(path string) (*os.File, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("failed to stat %q: %w", path, err)
+	}
+	return f, stat.Size(), nil
+}
@@ -3,10 +3,15 @@ package operators
 import (
 	"context"
 	"fmt"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/kasuganosora/sqlexec/pkg/builtin"
 	"github.com/kasuganosora/sqlexec/pkg/dataaccess"
 	"github.com/kasuganosora/sqlexec/pkg/optimizer/plan"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/types"
 	"github.com/kasuganosora/sqlexec/pkg/utils"
@@ -57,6 +62,54 @@ func NewAggregateOperator(p *plan.Plan, das dataaccess.Service) (*AggregateOpera
 	}, nil
 }
 
+// aggState 单个分组内单个聚合函数的累积状态
+type aggState struct {
+	count    int
+	sum      float64
+	hasValue bool
+	value    interface{}               // MIN / MAX
+	seen     map[string]struct{}       // DISTINCT 去重
+	concat   []concatValue             // GROUP_CONCAT 收集的值（排序后再拼接）
+	builtin  *builtin.AggregateContext // STDDEV / VARIANCE / BIT_* 等复用内置聚合函数
+}
+
+// concatValue GROUP_CONCAT 收集的值及其所在的行，行用于 ORDER BY 排序
+type concatValue struct {
+	row domain.Row
+	val interface{}
+}
+
+// aggGroup 一个分组的分组列值和聚合状态
+type aggGroup struct {
+	row    domain.Row
+	states []*aggState
+}
+
+// builtinAggNames 复用 builtin 聚合函数实现的聚合类型
+var builtinAggNames = map[types.AggregationType]string{
+	types.StdDevPop:  "stddev_pop",
+	types.StdDevSamp: "stddev_samp",
+	types.VarPop:     "var_pop",
+	types.VarSamp:    "var_samp",
+	types.BitAnd:     "bit_and",
+	types.BitOr:      "bit_or",
+	types.BitXor:     "bit_xor",
+	types.First:      "first_by",
+	types.Last:       "last_by",
+
+	types.ApproxCountDistinct: "approx_count_distinct",
+	types.ApproxPercentile:    "approx_percentile",
+}
+
+// parallelAggThreshold 输入行数达到该值、且所有聚合函数的部分状态都可以合并时并行聚合
+const parallelAggThreshold = 50000
+
+// aggTable 分组聚合的中间结果
+type aggTable struct {
+	groups map[string]*aggGroup
+	order  []string // 分组首次出现的顺序
+}
+
 // Execute 执行聚合
 func (op *AggregateOperator) Execute(ctx context.Context) (*domain.QueryResult, error) {
 	// 执行子算子
@@ -69,124 +122,40 @@ func (op *AggregateOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		return nil, fmt.Errorf("execute child failed: %w", err)
 	}
 
-	// 分组聚合
-	groups := make(map[string]map[string]interface{})
-
-	var keyBuilder strings.Builder
-	for _, row := range childResult.Rows {
-		// 构建分组键
-		keyBuilder.Reset()
-		for _, col := range op.config.GroupByCols {
-			if val, ok := row[col]; ok {
-				fmt.Fprintf(&keyBuilder, "%v|", val)
-			}
-		}
-		groupKey := keyBuilder.String()
-
-		// 初始化分组
-		if _, exists := groups[groupKey]; !exists {
-			groups[groupKey] = make(map[string]interface{})
-			for _, col := range op.config.GroupByCols {
-				groups[groupKey][col] = row[col]
-			}
-		}
-
-		// 执行聚合函数
-		for aggIdx, agg := range op.config.AggFuncs {
-			alias := agg.Alias
-			if alias == "" && agg.Expr != nil {
-				alias = fmt.Sprintf("agg_%d", aggIdx)
-			}
+	// 分组聚合，按分组首次出现的顺序输出
+	var table *aggTable
+	if len(childResult.Rows) >= parallelAggThreshold && op.mergeable() {
+		table, err = op.aggregateParallel(childResult.Rows)
+	} else {
+		table, err = op.aggregate(childResult.Rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+	groups, groupOrder := table.groups, table.order
 
-			switch agg.Type {
-			case types.Count:
-				if _, ok := groups[groupKey][alias]; !ok {
-					groups[groupKey][alias] = 0
-				}
-				if count, ok := groups[groupKey][alias].(int); ok {
-					groups[groupKey][alias] = count + 1
-				}
-			case types.Sum:
-				if agg.Expr != nil && agg.Expr.Column != "" {
-					if val, ok := row[agg.Expr.Column]; ok {
-						if num, ok := toFloat64(val); ok {
-							if _, exists := groups[groupKey][alias]; !exists {
-								groups[groupKey][alias] = float64(0)
-							}
-							if sum, ok := toFloat64(groups[groupKey][alias]); ok {
-								groups[groupKey][alias] = sum + num
-							}
-						}
-					}
-				}
-			case types.Avg:
-				sumKey := alias + "_sum"
-				countKey := alias + "_count"
-				if _, ok := groups[groupKey][sumKey]; !ok {
-					groups[groupKey][sumKey] = float64(0)
-					groups[groupKey][countKey] = 0
-				}
-				if agg.Expr != nil && agg.Expr.Column != "" {
-					if val, ok := row[agg.Expr.Column]; ok {
-						if num, ok := toFloat64(val); ok {
-							if sum, ok := toFloat64(groups[groupKey][sumKey]); ok {
-								groups[groupKey][sumKey] = sum + num
-							}
-							if count, ok := groups[groupKey][countKey].(int); ok {
-								groups[groupKey][countKey] = count + 1
-							}
-						}
-					}
-				}
-			case types.Min:
-				if agg.Expr != nil && agg.Expr.Column != "" {
-					if val, ok := row[agg.Expr.Column]; ok && val != nil {
-						cur, exists := groups[groupKey][alias]
-						if !exists || cur == nil {
-							groups[groupKey][alias] = val
-						} else if utils.CompareValuesForSort(val, cur) < 0 {
-							groups[groupKey][alias] = val
-						}
-					}
-				}
-			case types.Max:
-				if agg.Expr != nil && agg.Expr.Column != "" {
-					if val, ok := row[agg.Expr.Column]; ok && val != nil {
-						cur, exists := groups[groupKey][alias]
-						if !exists || cur == nil {
-							groups[groupKey][alias] = val
-						} else if utils.CompareValuesForSort(val, cur) > 0 {
-							groups[groupKey][alias] = val
-						}
-					}
-				}
-			}
+	// 没有 GROUP BY 时即使没有输入行也输出一行（如 COUNT(*) = 0）
+	if len(op.config.GroupByCols) == 0 && len(groupOrder) == 0 && len(op.config.AggFuncs) > 0 {
+		group, err := op.newGroup(nil)
+		if err != nil {
+			return nil, err
 		}
+		groups[""] = group
+		groupOrder = append(groupOrder, "")
 	}
 
 	// 构建结果行
-	resultRows := make([]domain.Row, 0, len(groups))
-	for _, group := range groups {
-		// 计算AVG
+	resultRows := make([]domain.Row, 0, len(groupOrder))
+	for _, key := range groupOrder {
+		group := groups[key]
 		for aggIdx, agg := range op.config.AggFuncs {
-			if agg.Type == types.Avg {
-				alias := agg.Alias
-				if alias == "" {
-					alias = fmt.Sprintf("agg_%d", aggIdx)
-				}
-				sumKey := alias + "_sum"
-				countKey := alias + "_count"
-
-				if sum, ok := toFloat64(group[sumKey]); ok {
-					if count, ok := group[countKey].(int); ok && count > 0 {
-						group[alias] = sum / float64(count)
-					}
-				}
-				delete(group, sumKey)
-				delete(group, countKey)
+			val, err := op.finalize(group.states[aggIdx], agg)
+			if err != nil {
+				return nil, fmt.Errorf("aggregate %s failed: %w", op.aggAlias(aggIdx), err)
 			}
+			group.row[op.aggAlias(aggIdx)] = val
 		}
-		resultRows = append(resultRows, group)
+		resultRows = append(resultRows, group.row)
 	}
 
 	// 构建输出列
@@ -198,15 +167,17 @@ func (op *AggregateOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		})
 	}
 	for aggIdx, agg := range op.config.AggFuncs {
-		alias := agg.Alias
-		if alias == "" {
-			alias = fmt.Sprintf("agg_%d", aggIdx)
-		}
 		colType := "INTEGER"
 		switch agg.Type {
-		case types.Sum, types.Avg:
+		case types.Sum, types.Avg, types.StdDevPop, types.StdDevSamp, types.VarPop, types.VarSamp, types.ApproxPercentile:
 			colType = "DOUBLE"
-		case types.Min, types.Max:
+		case types.ApproxCountDistinct:
+			colType = "BIGINT"
+		case types.GroupConcat:
+			colType = "TEXT"
+		case types.BitAnd, types.BitOr, types.BitXor:
+			colType = "BIGINT UNSIGNED"
+		case types.Min, types.Max, types.First, types.Last:
 			// Preserve input column type if available
 			if agg.Expr != nil && agg.Expr.Column != "" {
 				colType = "TEXT" // default; overridden below if child provides type
@@ -221,7 +192,7 @@ func (op *AggregateOperator) Execute(ctx context.Context) (*domain.QueryResult,
 			}
 		}
 		outputColumns = append(outputColumns, domain.ColumnInfo{
-			Name: alias,
+			Name: op.aggAlias(aggIdx),
 			Type: colType,
 		})
 	}
@@ -231,3 +202,350 @@ func (op *AggregateOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		Rows:    resultRows,
 	}, nil
 }
+
+// aggregate 把输入行累积到各分组的聚合状态
+func (op *AggregateOperator) aggregate(rows []domain.Row) (*aggTable, error) {
+	table := &aggTable{groups: make(map[string]*aggGroup)}
+	var keyBuilder strings.Builder
+	groupVals := make([]interface{}, len(op.config.GroupByCols))
+	dispatches := op.caseDispatches()
+	done := make([]bool, len(op.config.AggFuncs))
+	for _, row := range rows {
+		// 构建分组键
+		keyBuilder.Reset()
+		for i, col := range op.config.GroupByCols {
+			val, err := op.groupValue(row, col)
+			if err != nil {
+				return nil, fmt.Errorf("evaluate group by %s failed: %w", col, err)
+			}
+			groupVals[i] = val
+			keyBuilder.WriteString(hashKey(val))
+			keyBuilder.WriteByte('|')
+		}
+		groupKey := keyBuilder.String()
+
+		// 初始化分组
+		group, exists := table.groups[groupKey]
+		if !exists {
+			var err error
+			group, err = op.newGroup(groupVals)
+			if err != nil {
+				return nil, err
+			}
+			table.groups[groupKey] = group
+			table.order = append(table.order, groupKey)
+		}
+
+		// 执行聚合函数：先按列值分派条件聚合，其余逐个求值
+		for i := range done {
+			done[i] = false
+		}
+		for _, d := range dispatches {
+			if err := op.dispatchCase(d, group, row, done); err != nil {
+				return nil, err
+			}
+		}
+		for aggIdx, agg := range op.config.AggFuncs {
+			if done[aggIdx] {
+				continue
+			}
+			if err := op.accumulate(group.states[aggIdx], agg, row); err != nil {
+				return nil, fmt.Errorf("aggregate %s failed: %w", op.aggAlias(aggIdx), err)
+			}
+		}
+	}
+	return table, nil
+}
+
+// aggregateParallel 把输入行切分为连续的分区并行聚合，再按分区顺序合并部分状态，
+// 因此分组的输出顺序与串行聚合相同
+func (op *AggregateOperator) aggregateParallel(rows []domain.Row) (*aggTable, error) {
+	workers := runtime.NumCPU()
+	if workers > 8 {
+		workers = 8
+	}
+	chunk := (len(rows) + workers - 1) / workers
+	partials := make([]*aggTable, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*chunk, (w+1)*chunk
+		if start >= len(rows) {
+			break
+		}
+		if end > len(rows) {
+			end = len(rows)
+		}
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			partials[w], errs[w] = op.aggregate(rows[start:end])
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	var table *aggTable
+	for w, partial := range partials {
+		if errs[w] != nil {
+			return nil, errs[w]
+		}
+		if partial == nil {
+			continue
+		}
+		if table == nil {
+			table = partial
+			continue
+		}
+		for _, key := range partial.order {
+			dst, exists := table.groups[key]
+			if !exists {
+				table.groups[key] = partial.groups[key]
+				table.order = append(table.order, key)
+				continue
+			}
+			for aggIdx, agg := range op.config.AggFuncs {
+				if err := mergeAggState(dst.states[aggIdx], partial.groups[key].states[aggIdx], agg); err != nil {
+					return nil, fmt.Errorf("aggregate %s failed: %w", op.aggAlias(aggIdx), err)
+				}
+			}
+		}
+	}
+	if table == nil {
+		table = &aggTable{groups: make(map[string]*aggGroup)}
+	}
+	return table, nil
+}
+
+// mergeable 所有聚合函数的部分状态都可以合并（DISTINCT、GROUP_CONCAT、FIRST/LAST 等不能）
+func (op *AggregateOperator) mergeable() bool {
+	for _, agg := range op.config.AggFuncs {
+		if agg.Distinct {
+			return false
+		}
+		switch agg.Type {
+		case types.Count, types.Sum, types.Avg, types.Min, types.Max:
+			continue
+		}
+		name, ok := builtinAggNames[agg.Type]
+		if !ok {
+			return false
+		}
+		if info, ok := builtin.GetAggregate(name); !ok || info.Merge == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeAggState 把部分聚合状态 src 合并到 dst
+func mergeAggState(dst, src *aggState, agg *types.AggregationItem) error {
+	switch agg.Type {
+	case types.Count, types.Sum, types.Avg:
+		dst.count += src.count
+		dst.sum += src.sum
+		dst.hasValue = dst.hasValue || src.hasValue
+	case types.Min, types.Max:
+		if !src.hasValue {
+			return nil
+		}
+		cmp := 0
+		if dst.hasValue {
+			cmp = utils.CompareValuesForSort(src.value, dst.value)
+		}
+		if !dst.hasValue || (agg.Type == types.Min && cmp < 0) || (agg.Type == types.Max && cmp > 0) {
+			dst.value, dst.hasValue = src.value, true
+		}
+	default:
+		info, _ := builtin.GetAggregate(builtinAggNames[agg.Type])
+		return info.Merge(dst.builtin, src.builtin)
+	}
+	return nil
+}
+
+// aggAlias 聚合结果列名，未指定别名时使用 agg_<序号>
+func (op *AggregateOperator) aggAlias(aggIdx int) string {
+	if alias := op.config.AggFuncs[aggIdx].Alias; alias != "" {
+		return alias
+	}
+	return fmt.Sprintf("agg_%d", aggIdx)
+}
+
+// groupValue 计算分组列的值，表达式分组（如 DATE(created_at)）在此求值
+func (op *AggregateOperator) groupValue(row domain.Row, col string) (interface{}, error) {
+	if expr, ok := op.config.GroupByExprs[col]; ok {
+		return evaluateExpression(row, expr)
+	}
+	return columnValue(row, col), nil
+}
+
+// newGroup 创建分组并初始化各聚合函数的状态
+func (op *AggregateOperator) newGroup(groupVals []interface{}) (*aggGroup, error) {
+	group := &aggGroup{
+		row:    make(domain.Row, len(op.config.GroupByCols)+len(op.config.AggFuncs)),
+		states: make([]*aggState, len(op.config.AggFuncs)),
+	}
+	for i, col := range op.config.GroupByCols {
+		group.row[col] = groupVals[i]
+	}
+	for i, agg := range op.config.AggFuncs {
+		state := &aggState{}
+		if agg.Distinct {
+			state.seen = make(map[string]struct{})
+		}
+		if name, ok := builtinAggNames[agg.Type]; ok {
+			if _, ok := builtin.GetAggregate(name); !ok {
+				return nil, fmt.Errorf("aggregate function %s is not registered", name)
+			}
+			state.builtin = builtin.NewAggregateContext()
+		}
+		group.states[i] = state
+	}
+	return group, nil
+}
+
+// aggArgValue 获取聚合函数参数在当前行的值
+// COUNT(*) / COUNT(1) 的参数为常量，返回 countAll = true
+func aggArgValue(row domain.Row, agg *types.AggregationItem) (val interface{}, countAll bool) {
+	if agg.Expr == nil {
+		return nil, true
+	}
+	if agg.Expr.Column != "" {
+		return columnValue(row, agg.Expr.Column), false
+	}
+	if strings.EqualFold(agg.Expr.Type, string(parser.ExprTypeValue)) {
+		return agg.Expr.Value, true
+	}
+	// 无法求值的参数（如函数调用本身）按 COUNT(*) 处理
+	return nil, true
+}
+
+// argValue 获取聚合函数参数在当前行的值，参数为表达式（如 CASE WHEN）时在行上求值
+func (op *AggregateOperator) argValue(row domain.Row, agg *types.AggregationItem) (val interface{}, countAll bool, err error) {
+	if expr, ok := op.config.AggArgExprs[agg.Alias]; ok {
+		val, err = evaluateExpression(row, expr)
+		return val, false, err
+	}
+	val, countAll = aggArgValue(row, agg)
+	return val, countAll, nil
+}
+
+// accumulate 将一行累积到聚合状态
+func (op *AggregateOperator) accumulate(state *aggState, agg *types.AggregationItem, row domain.Row) error {
+	val, countAll, err := op.argValue(row, agg)
+	if err != nil {
+		return err
+	}
+	return op.accumulateValue(state, agg, row, val, countAll)
+}
+
+// accumulateValue 将参数值累积到聚合状态
+func (op *AggregateOperator) accumulateValue(state *aggState, agg *types.AggregationItem, row domain.Row, val interface{}, countAll bool) error {
+	if agg.Type == types.Count && countAll && !agg.Distinct {
+		state.count++
+		return nil
+	}
+	if agg.Type == types.First || agg.Type == types.Last {
+		// 值为 NULL 的行同样可以被选中，由时间列决定取哪一行
+		args := []interface{}{val}
+		if len(agg.OrderBy) > 0 {
+			args = append(args, columnValue(row, agg.OrderBy[0].Column))
+		}
+		info, _ := builtin.GetAggregate(builtinAggNames[agg.Type])
+		return info.Handler(state.builtin, args)
+	}
+	// 除 COUNT(*) 外，聚合函数忽略 NULL
+	if val == nil {
+		return nil
+	}
+	if state.seen != nil {
+		key := hashKey(val)
+		if _, dup := state.seen[key]; dup {
+			return nil
+		}
+		state.seen[key] = struct{}{}
+	}
+
+	switch agg.Type {
+	case types.Count:
+		state.count++
+	case types.Sum, types.Avg:
+		if num, ok := toFloat64(val); ok {
+			state.sum += num
+			state.count++
+			state.hasValue = true
+		}
+	case types.Min:
+		if !state.hasValue || utils.CompareValuesForSort(val, state.value) < 0 {
+			state.value = val
+			state.hasValue = true
+		}
+	case types.Max:
+		if !state.hasValue || utils.CompareValuesForSort(val, state.value) > 0 {
+			state.value = val
+			state.hasValue = true
+		}
+	case types.GroupConcat:
+		state.concat = append(state.concat, concatValue{row: row, val: val})
+	default:
+		if state.builtin != nil {
+			info, _ := builtin.GetAggregate(builtinAggNames[agg.Type])
+			return info.Handler(state.builtin, append([]interface{}{val}, agg.Params...))
+		}
+	}
+	return nil
+}
+
+// finalize 计算聚合结果
+func (op *AggregateOperator) finalize(state *aggState, agg *types.AggregationItem) (interface{}, error) {
+	switch agg.Type {
+	case types.Count:
+		return state.count, nil
+	case types.Sum:
+		if !state.hasValue {
+			return nil, nil
+		}
+		return state.sum, nil
+	case types.Avg:
+		if !state.hasValue {
+			return nil, nil
+		}
+		return state.sum / float64(state.count), nil
+	case types.Min, types.Max:
+		return state.value, nil
+	case types.GroupConcat:
+		return groupConcat(state.concat, agg), nil
+	default:
+		if state.builtin == nil {
+			return nil, fmt.Errorf("unsupported aggregate type: %d", agg.Type)
+		}
+		info, _ := builtin.GetAggregate(builtinAggNames[agg.Type])
+		return info.Result(state.builtin)
+	}
+}
+
+// groupConcat 按 ORDER BY 排序后用分隔符拼接，没有非 NULL 值时返回 NULL
+func groupConcat(values []concatValue, agg *types.AggregationItem) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	if len(agg.OrderBy) > 0 {
+		sort.SliceStable(values, func(i, j int) bool {
+			for _, ob := range agg.OrderBy {
+				cmp := utils.CompareValuesForSort(columnValue(values[i].row, ob.Column), columnValue(values[j].row, ob.Column))
+				if cmp == 0 {
+					continue
+				}
+				if ob.Desc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = utils.ToString(v.val)
+	}
+	return strings.Join(parts, agg.Separator)
+}
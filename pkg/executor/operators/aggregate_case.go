@@ -0,0 +1,139 @@
+package operators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/types"
+)
+
+// caseDispatch 一组按同一列的取值选择分支的条件聚合 AGG(CASE WHEN col = 常量 THEN expr END)，
+// 即 PIVOT 展开后的形式。每行只读取一次 col，通过哈希表找到条件成立的聚合函数，
+// 其余聚合函数的参数为 NULL 而被忽略，不必对每个 CASE 逐一求值
+type caseDispatch struct {
+	column   string
+	numeric  bool                       // 常量都是数值，否则都是字符串
+	branches map[string][]int           // 常量 -> 条件成立的聚合函数下标
+	results  map[int]*parser.Expression // 聚合函数下标 -> THEN 表达式
+	aggs     []int                      // 组内所有聚合函数的下标
+}
+
+// caseDispatches 找出可以按列值分派的条件聚合，同一列上至少有两个时才分派
+func (op *AggregateOperator) caseDispatches() []*caseDispatch {
+	byColumn := make(map[string]*caseDispatch)
+	var order []string
+	for aggIdx, agg := range op.config.AggFuncs {
+		if agg.Type == types.First || agg.Type == types.Last {
+			continue // 这两个聚合函数不忽略 NULL 值
+		}
+		column, value, result, ok := caseBranch(op.config.AggArgExprs[agg.Alias])
+		if !ok {
+			continue
+		}
+		_, isString := value.(string)
+		d, exists := byColumn[column]
+		if !exists {
+			d = &caseDispatch{
+				column:   column,
+				numeric:  !isString,
+				branches: make(map[string][]int),
+				results:  make(map[int]*parser.Expression),
+			}
+			byColumn[column] = d
+			order = append(order, column)
+		}
+		if d.numeric == isString {
+			continue // 字符串与数值常量混用时逐个求值
+		}
+		key, _ := dispatchKey(value, d.numeric)
+		d.branches[key] = append(d.branches[key], aggIdx)
+		d.results[aggIdx] = result
+		d.aggs = append(d.aggs, aggIdx)
+	}
+
+	var dispatches []*caseDispatch
+	for _, column := range order {
+		if d := byColumn[column]; len(d.aggs) >= 2 {
+			dispatches = append(dispatches, d)
+		}
+	}
+	return dispatches
+}
+
+// caseBranch 识别 CASE WHEN col = 常量 THEN expr [ELSE NULL] END，常量为字符串或数值
+func caseBranch(expr *parser.Expression) (column string, value interface{}, result *parser.Expression, ok bool) {
+	if expr == nil || expr.Type != parser.ExprTypeFunction || !strings.EqualFold(expr.Function, parser.CaseFunc) {
+		return "", nil, nil, false
+	}
+	switch {
+	case len(expr.Args) == 2:
+	case len(expr.Args) == 3 && expr.Args[2].Type == parser.ExprTypeValue && expr.Args[2].Value == nil:
+	default:
+		return "", nil, nil, false
+	}
+	cond := expr.Args[0]
+	if cond.Type != parser.ExprTypeOperator || !(cond.Operator == "eq" || cond.Operator == "=") || cond.Left == nil || cond.Right == nil {
+		return "", nil, nil, false
+	}
+	col, val := cond.Left, cond.Right
+	if col.Type != parser.ExprTypeColumn {
+		col, val = val, col
+	}
+	if col.Type != parser.ExprTypeColumn || val.Type != parser.ExprTypeValue || val.Value == nil {
+		return "", nil, nil, false
+	}
+	if _, isString := val.Value.(string); !isString {
+		if _, isNumber := toFloat64(val.Value); !isNumber {
+			return "", nil, nil, false
+		}
+	}
+	return col.Column, val.Value, &expr.Args[1], true
+}
+
+// dispatchKey 分派用的键：与比较运算一致，数值按数值相等，字符串区分大小写；
+// 值的类型与常量不一致时返回 false，由调用方逐个求值
+func dispatchKey(v interface{}, numeric bool) (string, bool) {
+	if s, ok := v.(string); ok {
+		return s, !numeric
+	}
+	if !numeric {
+		return "", false
+	}
+	if i, ok := toInt64(v); ok {
+		return strconv.FormatInt(i, 10), true
+	}
+	if f, ok := toFloat64(v); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// dispatchCase 把一行分派给条件成立的聚合函数，组内的聚合函数在 done 中标记为已处理；
+// 列值的类型与常量不一致时不处理，由调用方逐个求值
+func (op *AggregateOperator) dispatchCase(d *caseDispatch, group *aggGroup, row domain.Row, done []bool) error {
+	v := columnValue(row, d.column)
+	var hits []int
+	if v != nil {
+		key, ok := dispatchKey(v, d.numeric)
+		if !ok {
+			return nil
+		}
+		hits = d.branches[key]
+	}
+	for _, aggIdx := range hits {
+		val, err := evaluateExpression(row, d.results[aggIdx])
+		if err == nil {
+			err = op.accumulateValue(group.states[aggIdx], op.config.AggFuncs[aggIdx], row, val, false)
+		}
+		if err != nil {
+			return fmt.Errorf("aggregate %s failed: %w", op.aggAlias(aggIdx), err)
+		}
+	}
+	for _, aggIdx := range d.aggs {
+		done[aggIdx] = true
+	}
+	return nil
+}
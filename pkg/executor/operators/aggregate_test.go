@@ -0,0 +1,294 @@
+package operators
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/optimizer/plan"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAggregate(rows []domain.Row, config *plan.AggregateConfig) *AggregateOperator {
+	return &AggregateOperator{
+		BaseOperator: &BaseOperator{
+			children: []Operator{&mockChildOperator{result: &domain.QueryResult{Rows: rows}}},
+		},
+		config: config,
+	}
+}
+
+func salesRows() []domain.Row {
+	return []domain.Row{
+		{"region": "east", "amount": int64(10), "flags": int64(6), "day": "2024-01-01 09:00:00"},
+		{"region": "east", "amount": int64(30), "flags": int64(3), "day": "2024-01-01 18:30:00"},
+		{"region": "east", "amount": int64(30), "flags": int64(7), "day": "2024-01-02 08:00:00"},
+		{"region": "west", "amount": int64(20), "flags": int64(1), "day": "2024-01-02 12:00:00"},
+		{"region": "west", "amount": nil, "flags": nil, "day": "2024-01-02 13:00:00"},
+	}
+}
+
+func column(name string) *types.Expression {
+	return &types.Expression{Type: "COLUMN", Column: name}
+}
+
+func resultByKey(t *testing.T, result *domain.QueryResult, key string) map[interface{}]domain.Row {
+	t.Helper()
+	rows := make(map[interface{}]domain.Row, len(result.Rows))
+	for _, row := range result.Rows {
+		rows[row[key]] = row
+	}
+	require.Len(t, rows, len(result.Rows), "group key %s should be unique", key)
+	return rows
+}
+
+func TestAggregateOperator_GroupByExpression(t *testing.T) {
+	op := newTestAggregate(salesRows(), &plan.AggregateConfig{
+		GroupByCols: []string{"d"},
+		GroupByExprs: map[string]*parser.Expression{
+			"d": {
+				Type:     parser.ExprTypeFunction,
+				Function: "DATE",
+				Args:     []parser.Expression{{Type: parser.ExprTypeColumn, Column: "day"}},
+			},
+		},
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.Count, Alias: "cnt", Expr: &types.Expression{Type: "VALUE", Value: int64(1)}},
+		},
+	})
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+	// 分组按首次出现的顺序输出
+	assert.Equal(t, 2, result.Rows[0]["cnt"])
+	assert.Equal(t, 3, result.Rows[1]["cnt"])
+	assert.Equal(t, "d", result.Columns[0].Name)
+}
+
+func TestAggregateOperator_GroupByArithmetic(t *testing.T) {
+	op := newTestAggregate(salesRows(), &plan.AggregateConfig{
+		GroupByCols: []string{"bucket"},
+		GroupByExprs: map[string]*parser.Expression{
+			"bucket": {
+				Type:     parser.ExprTypeOperator,
+				Operator: "mod",
+				Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: "amount"},
+				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: int64(20)},
+			},
+		},
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.Count, Alias: "cnt"},
+		},
+	})
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	rows := resultByKey(t, result, "bucket")
+	assert.Equal(t, 3, rows[int64(10)]["cnt"])
+	assert.Equal(t, 1, rows[int64(0)]["cnt"])
+	assert.Equal(t, 1, rows[nil]["cnt"], "NULL forms its own group")
+}
+
+func TestAggregateOperator_Distinct(t *testing.T) {
+	op := newTestAggregate(salesRows(), &plan.AggregateConfig{
+		GroupByCols: []string{"region"},
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.Count, Alias: "cnt", Expr: column("amount")},
+			{Type: types.Count, Alias: "distinct_cnt", Expr: column("amount"), Distinct: true},
+			{Type: types.Sum, Alias: "distinct_sum", Expr: column("amount"), Distinct: true},
+		},
+	})
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	rows := resultByKey(t, result, "region")
+
+	assert.Equal(t, 3, rows["east"]["cnt"])
+	assert.Equal(t, 2, rows["east"]["distinct_cnt"])
+	assert.Equal(t, float64(40), rows["east"]["distinct_sum"])
+	// COUNT(column) 忽略 NULL
+	assert.Equal(t, 1, rows["west"]["cnt"])
+}
+
+func TestAggregateOperator_GroupConcat(t *testing.T) {
+	op := newTestAggregate(salesRows(), &plan.AggregateConfig{
+		GroupByCols: []string{"region"},
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.GroupConcat, Alias: "plain", Expr: column("amount"), Separator: ","},
+			{
+				Type:      types.GroupConcat,
+				Alias:     "ordered",
+				Expr:      column("amount"),
+				Distinct:  true,
+				Separator: ";",
+				OrderBy:   []types.AggregationOrder{{Column: "amount", Desc: true}},
+			},
+		},
+	})
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	rows := resultByKey(t, result, "region")
+
+	assert.Equal(t, "10,30,30", rows["east"]["plain"])
+	assert.Equal(t, "30;10", rows["east"]["ordered"])
+	assert.Equal(t, "20", rows["west"]["plain"])
+	assert.Equal(t, "TEXT", result.Columns[1].Type)
+}
+
+func TestAggregateOperator_StatisticalAndBitAggregates(t *testing.T) {
+	op := newTestAggregate(salesRows(), &plan.AggregateConfig{
+		GroupByCols: []string{"region"},
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.StdDevPop, Alias: "std", Expr: column("amount")},
+			{Type: types.VarPop, Alias: "var", Expr: column("amount")},
+			{Type: types.VarSamp, Alias: "var_samp", Expr: column("amount")},
+			{Type: types.BitAnd, Alias: "bit_and", Expr: column("flags")},
+			{Type: types.BitOr, Alias: "bit_or", Expr: column("flags")},
+			{Type: types.BitXor, Alias: "bit_xor", Expr: column("flags")},
+		},
+	})
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	rows := resultByKey(t, result, "region")
+
+	east := rows["east"]
+	// amount = 10, 30, 30: mean 70/3
+	mean := 70.0 / 3
+	variance := ((10-mean)*(10-mean) + 2*(30-mean)*(30-mean)) / 3
+	assert.InDelta(t, variance, east["var"], 1e-9)
+	assert.InDelta(t, math.Sqrt(variance), east["std"], 1e-9)
+	assert.InDelta(t, variance*3/2, east["var_samp"], 1e-9)
+	assert.Equal(t, uint64(2), east["bit_and"])
+	assert.Equal(t, uint64(7), east["bit_or"])
+	assert.Equal(t, uint64(2), east["bit_xor"])
+
+	west := rows["west"]
+	assert.Nil(t, west["var_samp"], "sample variance of a single value is NULL")
+	assert.Equal(t, uint64(1), west["bit_and"])
+}
+
+func TestAggregateOperator_EmptyInputWithoutGroupBy(t *testing.T) {
+	op := newTestAggregate(nil, &plan.AggregateConfig{
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.Count, Alias: "cnt"},
+			{Type: types.Sum, Alias: "total", Expr: column("amount")},
+			{Type: types.GroupConcat, Alias: "names", Expr: column("name"), Separator: ","},
+		},
+	})
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, 0, result.Rows[0]["cnt"])
+	assert.Nil(t, result.Rows[0]["total"])
+	assert.Nil(t, result.Rows[0]["names"])
+
+	// 有 GROUP BY 时空输入不产生分组
+	op = newTestAggregate(nil, &plan.AggregateConfig{
+		GroupByCols: []string{"region"},
+		AggFuncs:    []*types.AggregationItem{{Type: types.Count, Alias: "cnt"}},
+	})
+	result, err = op.Execute(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+}
+
+func TestAggregateOperator_ApproxAggregatesParallel(t *testing.T) {
+	// 行数超过并行阈值，分组交替出现
+	rows := make([]domain.Row, parallelAggThreshold*2)
+	for i := range rows {
+		region := "east"
+		if i%2 == 1 {
+			region = "west"
+		}
+		rows[i] = domain.Row{"region": region, "user": int64(i % 1000), "latency": float64(i % 100)}
+	}
+	config := &plan.AggregateConfig{
+		GroupByCols: []string{"region"},
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.Count, Alias: "cnt", Expr: &types.Expression{Type: "VALUE", Value: int64(1)}},
+			{Type: types.ApproxCountDistinct, Alias: "users", Expr: column("user")},
+			{Type: types.ApproxPercentile, Alias: "p50", Expr: column("latency"), Params: []interface{}{int64(50)}},
+			{Type: types.Max, Alias: "max_latency", Expr: column("latency")},
+		},
+	}
+	op := newTestAggregate(rows, config)
+	require.True(t, op.mergeable())
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+	assert.Equal(t, "east", result.Rows[0]["region"])
+	assert.Equal(t, parallelAggThreshold, result.Rows[0]["cnt"])
+	assert.EqualValues(t, 500, result.Rows[0]["users"])
+	assert.InDelta(t, 49, result.Rows[0]["p50"], 1.5)
+	assert.Equal(t, float64(99), result.Rows[1]["max_latency"])
+
+	// DISTINCT 不能合并部分状态，回退到串行聚合
+	config.AggFuncs[0].Distinct = true
+	assert.False(t, op.mergeable())
+}
+
+// caseWhen CASE WHEN col = value THEN result END
+func caseWhen(col string, value interface{}, result parser.Expression) *parser.Expression {
+	return &parser.Expression{
+		Type:     parser.ExprTypeFunction,
+		Function: parser.CaseFunc,
+		Args: []parser.Expression{
+			{
+				Type:     parser.ExprTypeOperator,
+				Operator: "eq",
+				Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: col},
+				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: value},
+			},
+			result,
+		},
+	}
+}
+
+func TestAggregateOperator_ConditionalAggregates(t *testing.T) {
+	amount := parser.Expression{Type: parser.ExprTypeColumn, Column: "amount"}
+	one := parser.Expression{Type: parser.ExprTypeValue, Value: int64(1)}
+	rows := append(salesRows(), domain.Row{"region": int64(7), "amount": int64(1), "day": "2024-01-03 00:00:00"})
+	config := &plan.AggregateConfig{
+		AggFuncs: []*types.AggregationItem{
+			{Type: types.Sum, Alias: "east"},
+			{Type: types.Sum, Alias: "west"},
+			{Type: types.Count, Alias: "east_n"},
+			{Type: types.Count, Alias: "seven"},
+			{Type: types.Max, Alias: "big"},
+		},
+		AggArgExprs: map[string]*parser.Expression{
+			"east":   caseWhen("region", "east", amount),
+			"west":   caseWhen("region", "west", amount),
+			"east_n": caseWhen("region", "east", one),
+			"seven":  caseWhen("region", int64(7), one),
+			"big": {
+				Type:     parser.ExprTypeOperator,
+				Operator: "gt",
+				Left:     &amount,
+				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: int64(25)},
+			},
+		},
+	}
+	op := newTestAggregate(rows, config)
+	dispatches := op.caseDispatches()
+	require.Len(t, dispatches, 1, "CASE branches on region are dispatched together")
+	assert.Equal(t, []int{0, 1, 2}, dispatches[0].aggs, "numeric constants are evaluated one by one")
+
+	result, err := op.Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.EqualValues(t, 70, result.Rows[0]["east"])
+	assert.EqualValues(t, 20, result.Rows[0]["west"])
+	assert.EqualValues(t, 3, result.Rows[0]["east_n"])
+	assert.EqualValues(t, 1, result.Rows[0]["seven"], "a region value of another type falls back to evaluating CASE")
+	assert.EqualValues(t, 1, result.Rows[0]["big"])
+}
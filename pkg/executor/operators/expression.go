@@ -0,0 +1,75 @@
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/builtin"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// columnValue 获取行中的列值，找不到时尝试去掉表名前缀（如 "t.id" -> "id"）
+func columnValue(row domain.Row, col string) interface{} {
+	if val, ok := row[col]; ok {
+		return val
+	}
+	if idx := strings.LastIndex(col, "."); idx >= 0 {
+		return row[col[idx+1:]]
+	}
+	return nil
+}
+
+// evaluateExpression 在单行上计算表达式
+// 支持列引用、常量、算术运算和内置标量函数（如 DATE(created_at)、amount * 2）
+func evaluateExpression(row domain.Row, expr *parser.Expression) (interface{}, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	switch expr.Type {
+	case parser.ExprTypeColumn:
+		return columnValue(row, expr.Column), nil
+	case parser.ExprTypeValue:
+		return expr.Value, nil
+	case parser.ExprTypeFunction:
+		fn, ok := builtin.GetGlobal(strings.ToLower(expr.Function))
+		if !ok {
+			return nil, fmt.Errorf("function not found: %s", expr.Function)
+		}
+		args := make([]interface{}, len(expr.Args))
+		for i := range expr.Args {
+			val, err := evaluateExpression(row, &expr.Args[i])
+			if err != nil {
+				return nil, err
+			}
+			args[i] = val
+		}
+		return fn.Handler(args)
+	case parser.ExprTypeOperator:
+		if utils.IsArithmeticOperator(expr.Operator) {
+			return evaluateArithmetic(row, expr)
+		}
+		// 比较与逻辑运算按三值逻辑求值：TRUE → 1，FALSE → 0，UNKNOWN → NULL
+		return predicates.getExpressionValue(row, expr)
+	default:
+		return nil, fmt.Errorf("unsupported expression type: %s", expr.Type)
+	}
+}
+
+// predicates 比较与逻辑运算的求值器，不依赖算子的状态
+var predicates SelectionOperator
+
+// evaluateArithmetic 计算算术运算的两个操作数，再交给 utils.Arithmetic 求值
+func evaluateArithmetic(row domain.Row, expr *parser.Expression) (interface{}, error) {
+	left, err := evaluateExpression(row, expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := evaluateExpression(row, expr.Right)
+	if err != nil {
+		return nil, err
+	}
+	return utils.Arithmetic(expr.Operator, left, right)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/kasuganosora/sqlexec/pkg/dataaccess"
 	"github.com/kasuganosora/sqlexec/pkg/optimizer/plan"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/types"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
 // HashJoinOperator Hash Join算子
@@ -80,6 +82,69 @@ func multiHashKey(row domain.Row, cols []string) string {
 	return key
 }
 
+// numericJoinCols reports for each join column pair whether either side holds numbers.
+// Such columns are keyed numerically, so "1" = 1 matches as in MySQL.
+func numericJoinCols(leftRows []domain.Row, leftCols []string, rightRows []domain.Row, rightCols []string) []bool {
+	numeric := make([]bool, max(len(leftCols), len(rightCols)))
+	scan := func(rows []domain.Row, cols []string) {
+		for i, col := range cols {
+			for _, row := range rows {
+				if v := row[col]; v != nil {
+					if utils.IsNumeric(v) {
+						numeric[i] = true
+					}
+					break
+				}
+			}
+		}
+	}
+	scan(leftRows, leftCols)
+	scan(rightRows, rightCols)
+	return numeric
+}
+
+// joinHashKey builds the hash key of the join columns of a row. Numbers are normalized
+// (1, 1.0 and, in numeric columns, "1" share a key) per the value coercion rules.
+// Returns false when any join column is NULL: NULL = x is UNKNOWN, so such a row never matches.
+func joinHashKey(row domain.Row, cols []string, numeric []bool) (string, bool) {
+	for _, col := range cols {
+		if row[col] == nil {
+			return "", false
+		}
+	}
+	if len(cols) == 1 {
+		return hashKey(utils.JoinKey(row[cols[0]], numeric[0])), true
+	}
+	var sb strings.Builder
+	for i, col := range cols {
+		if i > 0 {
+			sb.WriteByte('|')
+		}
+		sb.WriteString(hashKey(utils.JoinKey(row[col], numeric[i])))
+	}
+	return sb.String(), true
+}
+
+// buildHashTable builds a hash table over rows keyed by the join columns, skipping NULL keys.
+func buildHashTable(rows []domain.Row, cols []string, numeric []bool) map[string][]domain.Row {
+	hashTable := make(map[string][]domain.Row)
+	for _, row := range rows {
+		if key, ok := joinHashKey(row, cols, numeric); ok {
+			hashTable[key] = append(hashTable[key], row)
+		}
+	}
+	return hashTable
+}
+
+// probeHashTable returns the rows matching the join columns of row; a NULL key matches nothing.
+func probeHashTable(hashTable map[string][]domain.Row, row domain.Row, cols []string, numeric []bool) []domain.Row {
+	key, ok := joinHashKey(row, cols, numeric)
+	if !ok {
+		return nil
+	}
+	return hashTable[key]
+}
+
 // mergeRowPair merges a left row and a right row, prefixing conflicting column
 // names from the right side with "right_".
 func mergeRowPair(left, right domain.Row, totalCols int) domain.Row {
@@ -157,40 +222,41 @@ func (op *HashJoinOperator) Execute(ctx context.Context) (*domain.QueryResult, e
 	joinType := op.config.JoinType
 
 	// Build hash table from right side
-	hashTable := make(map[string][]domain.Row)
+	var (
+		hashTable map[string][]domain.Row
+		numeric   []bool
+	)
 	if hasCondition {
-		for _, rightRow := range rightResult.Rows {
-			key := multiHashKey(rightRow, rightJoinCols)
-			hashTable[key] = append(hashTable[key], rightRow)
-		}
+		numeric = numericJoinCols(leftResult.Rows, leftJoinCols, rightResult.Rows, rightJoinCols)
+		hashTable = buildHashTable(rightResult.Rows, rightJoinCols, numeric)
 	}
 
 	var joinedRows []domain.Row
 
 	switch joinType {
 	case types.InnerJoin, types.HashJoin:
-		joinedRows = op.executeInnerJoin(leftResult, hashTable, leftJoinCols, hasCondition, rightResult, totalCols)
+		joinedRows = op.executeInnerJoin(leftResult, hashTable, leftJoinCols, numeric, hasCondition, rightResult, totalCols)
 
 	case types.LeftOuterJoin:
-		joinedRows = op.executeLeftJoin(leftResult, rightResult, hashTable, leftJoinCols, hasCondition, totalCols)
+		joinedRows = op.executeLeftJoin(leftResult, rightResult, hashTable, leftJoinCols, numeric, hasCondition, totalCols)
 
 	case types.RightOuterJoin:
-		joinedRows = op.executeRightJoin(leftResult, rightResult, hashTable, rightJoinCols, leftJoinCols, hasCondition, totalCols)
+		joinedRows = op.executeRightJoin(leftResult, rightResult, hashTable, rightJoinCols, leftJoinCols, numeric, hasCondition, totalCols)
 
 	case types.FullOuterJoin:
-		joinedRows = op.executeFullOuterJoin(leftResult, rightResult, hashTable, leftJoinCols, rightJoinCols, hasCondition, totalCols)
+		joinedRows = op.executeFullOuterJoin(leftResult, rightResult, hashTable, leftJoinCols, rightJoinCols, numeric, hasCondition, totalCols)
 
 	case types.CrossJoin:
 		joinedRows = op.executeCrossJoin(leftResult, rightResult, totalCols)
 
 	case types.SemiJoin:
-		joinedRows = op.executeSemiJoin(leftResult, hashTable, leftJoinCols, hasCondition, rightResult)
+		joinedRows = op.executeSemiJoin(leftResult, hashTable, leftJoinCols, numeric, hasCondition, rightResult)
 
 	case types.AntiSemiJoin:
-		joinedRows = op.executeAntiSemiJoin(leftResult, hashTable, leftJoinCols, hasCondition, rightResult)
+		joinedRows = op.executeAntiSemiJoin(leftResult, hashTable, leftJoinCols, numeric, hasCondition, rightResult)
 
 	default:
-		joinedRows = op.executeInnerJoin(leftResult, hashTable, leftJoinCols, hasCondition, rightResult, totalCols)
+		joinedRows = op.executeInnerJoin(leftResult, hashTable, leftJoinCols, numeric, hasCondition, rightResult, totalCols)
 	}
 
 	mergedColumns := mergeColumnInfos(leftResult.Columns, rightResult.Columns, joinType)
@@ -235,15 +301,12 @@ func (op *HashJoinOperator) getRightJoinCols() []string {
 	return nil
 }
 
-func (op *HashJoinOperator) executeInnerJoin(leftResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, hasCondition bool, rightResult *domain.QueryResult, totalCols int) []domain.Row {
+func (op *HashJoinOperator) executeInnerJoin(leftResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, numeric []bool, hasCondition bool, rightResult *domain.QueryResult, totalCols int) []domain.Row {
 	rows := make([]domain.Row, 0, len(leftResult.Rows))
 	if hasCondition {
 		for _, leftRow := range leftResult.Rows {
-			key := multiHashKey(leftRow, leftJoinCols)
-			if matchedRows, ok := hashTable[key]; ok {
-				for _, rightRow := range matchedRows {
-					rows = append(rows, mergeRowPair(leftRow, rightRow, totalCols))
-				}
+			for _, rightRow := range probeHashTable(hashTable, leftRow, leftJoinCols, numeric) {
+				rows = append(rows, mergeRowPair(leftRow, rightRow, totalCols))
 			}
 		}
 	} else {
@@ -252,12 +315,11 @@ func (op *HashJoinOperator) executeInnerJoin(leftResult *domain.QueryResult, has
 	return rows
 }
 
-func (op *HashJoinOperator) executeLeftJoin(leftResult, rightResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, hasCondition bool, totalCols int) []domain.Row {
+func (op *HashJoinOperator) executeLeftJoin(leftResult, rightResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, numeric []bool, hasCondition bool, totalCols int) []domain.Row {
 	rows := make([]domain.Row, 0, len(leftResult.Rows))
 	if hasCondition {
 		for _, leftRow := range leftResult.Rows {
-			key := multiHashKey(leftRow, leftJoinCols)
-			if matchedRows, ok := hashTable[key]; ok {
+			if matchedRows := probeHashTable(hashTable, leftRow, leftJoinCols, numeric); len(matchedRows) > 0 {
 				for _, rightRow := range matchedRows {
 					rows = append(rows, mergeRowPair(leftRow, rightRow, totalCols))
 				}
@@ -275,17 +337,12 @@ func (op *HashJoinOperator) executeLeftJoin(leftResult, rightResult *domain.Quer
 	return rows
 }
 
-func (op *HashJoinOperator) executeRightJoin(leftResult, rightResult *domain.QueryResult, hashTable map[string][]domain.Row, rightJoinCols, leftJoinCols []string, hasCondition bool, totalCols int) []domain.Row {
+func (op *HashJoinOperator) executeRightJoin(leftResult, rightResult *domain.QueryResult, hashTable map[string][]domain.Row, rightJoinCols, leftJoinCols []string, numeric []bool, hasCondition bool, totalCols int) []domain.Row {
 	rows := make([]domain.Row, 0, len(rightResult.Rows))
 	if hasCondition {
-		leftHashTable := make(map[string][]domain.Row)
-		for _, leftRow := range leftResult.Rows {
-			key := multiHashKey(leftRow, leftJoinCols)
-			leftHashTable[key] = append(leftHashTable[key], leftRow)
-		}
+		leftHashTable := buildHashTable(leftResult.Rows, leftJoinCols, numeric)
 		for _, rightRow := range rightResult.Rows {
-			key := multiHashKey(rightRow, rightJoinCols)
-			if matchedRows, ok := leftHashTable[key]; ok {
+			if matchedRows := probeHashTable(leftHashTable, rightRow, rightJoinCols, numeric); len(matchedRows) > 0 {
 				for _, leftRow := range matchedRows {
 					rows = append(rows, mergeRowPair(leftRow, rightRow, totalCols))
 				}
@@ -303,7 +360,7 @@ func (op *HashJoinOperator) executeRightJoin(leftResult, rightResult *domain.Que
 	return rows
 }
 
-func (op *HashJoinOperator) executeFullOuterJoin(leftResult, rightResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols, rightJoinCols []string, hasCondition bool, totalCols int) []domain.Row {
+func (op *HashJoinOperator) executeFullOuterJoin(leftResult, rightResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols, rightJoinCols []string, numeric []bool, hasCondition bool, totalCols int) []domain.Row {
 	rows := make([]domain.Row, 0, len(leftResult.Rows)+len(rightResult.Rows))
 	if !hasCondition {
 		return op.executeCrossJoin(leftResult, rightResult, totalCols)
@@ -312,14 +369,13 @@ func (op *HashJoinOperator) executeFullOuterJoin(leftResult, rightResult *domain
 	rightMatched := make(map[int]bool)
 
 	for _, leftRow := range leftResult.Rows {
-		key := multiHashKey(leftRow, leftJoinCols)
-		if matchedRows, ok := hashTable[key]; ok {
+		key, _ := joinHashKey(leftRow, leftJoinCols, numeric)
+		if matchedRows := probeHashTable(hashTable, leftRow, leftJoinCols, numeric); len(matchedRows) > 0 {
 			for _, rightRow := range matchedRows {
 				rows = append(rows, mergeRowPair(leftRow, rightRow, totalCols))
 			}
 			for ri, rightRow := range rightResult.Rows {
-				rkey := multiHashKey(rightRow, rightJoinCols)
-				if rkey == key {
+				if rkey, ok := joinHashKey(rightRow, rightJoinCols, numeric); ok && rkey == key {
 					rightMatched[ri] = true
 				}
 			}
@@ -347,12 +403,11 @@ func (op *HashJoinOperator) executeCrossJoin(leftResult, rightResult *domain.Que
 	return rows
 }
 
-func (op *HashJoinOperator) executeSemiJoin(leftResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, hasCondition bool, rightResult *domain.QueryResult) []domain.Row {
+func (op *HashJoinOperator) executeSemiJoin(leftResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, numeric []bool, hasCondition bool, rightResult *domain.QueryResult) []domain.Row {
 	rows := make([]domain.Row, 0)
 	if hasCondition {
 		for _, leftRow := range leftResult.Rows {
-			key := multiHashKey(leftRow, leftJoinCols)
-			if _, ok := hashTable[key]; ok {
+			if len(probeHashTable(hashTable, leftRow, leftJoinCols, numeric)) > 0 {
 				rows = append(rows, leftRow)
 			}
 		}
@@ -364,12 +419,11 @@ func (op *HashJoinOperator) executeSemiJoin(leftResult *domain.QueryResult, hash
 	return rows
 }
 
-func (op *HashJoinOperator) executeAntiSemiJoin(leftResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, hasCondition bool, rightResult *domain.QueryResult) []domain.Row {
+func (op *HashJoinOperator) executeAntiSemiJoin(leftResult *domain.QueryResult, hashTable map[string][]domain.Row, leftJoinCols []string, numeric []bool, hasCondition bool, rightResult *domain.QueryResult) []domain.Row {
 	rows := make([]domain.Row, 0)
 	if hasCondition {
 		for _, leftRow := range leftResult.Rows {
-			key := multiHashKey(leftRow, leftJoinCols)
-			if _, ok := hashTable[key]; !ok {
+			if len(probeHashTable(hashTable, leftRow, leftJoinCols, numeric)) == 0 {
 				rows = append(rows, leftRow)
 			}
 		}
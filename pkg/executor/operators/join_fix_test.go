@@ -274,6 +274,54 @@ func TestHashKey_NilValue(t *testing.T) {
 	assert.Equal(t, keyNil, hashKey(nil))
 }
 
+// NULL join keys never match: NULL = NULL is UNKNOWN, not TRUE.
+func TestHashJoin_NullKeysNeverMatch(t *testing.T) {
+	left := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}, {Name: "name", Type: "TEXT"}},
+		Rows: []domain.Row{
+			{"id": int64(1), "name": "Alice"},
+			{"id": nil, "name": "Ghost"},
+		},
+	}
+	right := func() *domain.QueryResult {
+		return &domain.QueryResult{
+			Columns: []domain.ColumnInfo{{Name: "user_id", Type: "INT"}, {Name: "amount", Type: "FLOAT"}},
+			Rows: []domain.Row{
+				{"user_id": int64(1), "amount": float64(100)},
+				{"user_id": nil, "amount": float64(200)},
+			},
+		}
+	}
+
+	result, err := makeTestJoinOp(types.InnerJoin, left, right(), "id", "user_id").Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Alice", result.Rows[0]["name"])
+
+	result, err = makeTestJoinOp(types.LeftOuterJoin, left, right(), "id", "user_id").Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+	for _, row := range result.Rows {
+		if row["name"] == "Ghost" {
+			assert.Nil(t, row["amount"], "NULL key should be NULL-extended, not matched")
+		}
+	}
+
+	result, err = makeTestJoinOp(types.FullOuterJoin, left, right(), "id", "user_id").Execute(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 3, "matched pair plus both unmatched NULL-key rows")
+
+	result, err = makeTestJoinOp(types.SemiJoin, left, right(), "id", "user_id").Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Alice", result.Rows[0]["name"])
+
+	result, err = makeTestJoinOp(types.AntiSemiJoin, left, right(), "id", "user_id").Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Ghost", result.Rows[0]["name"])
+}
+
 // ---------------------------------------------------------------------------
 // Fix #21 -- Multi-condition JOIN
 // ---------------------------------------------------------------------------
@@ -622,3 +670,45 @@ func TestHashJoin_HashJoinType_TreatedAsInner(t *testing.T) {
 	// Same result as INNER JOIN: 3 matched rows.
 	require.Len(t, result.Rows, 3)
 }
+
+// Join keys follow the value coercion rules: 1 = '1' = 1.0 as in MySQL,
+// while two string columns still compare as strings.
+func TestHashJoin_CoercesMixedKeyTypes(t *testing.T) {
+	left := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}, {Name: "name", Type: "TEXT"}},
+		Rows: []domain.Row{
+			{"id": int64(1), "name": "Alice"},
+			{"id": int64(2), "name": "Bob"},
+			{"id": int64(3), "name": "Charlie"},
+		},
+	}
+	right := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "user_id", Type: "TEXT"}, {Name: "amount", Type: "FLOAT"}},
+		Rows: []domain.Row{
+			{"user_id": "1", "amount": float64(100)},
+			{"user_id": " 2", "amount": float64(200)},
+			{"user_id": float64(3), "amount": float64(300)},
+			{"user_id": "x", "amount": float64(400)},
+		},
+	}
+
+	result, err := makeTestJoinOp(types.InnerJoin, left, right, "id", "user_id").Execute(context.Background())
+	require.NoError(t, err)
+	names := make([]interface{}, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		names = append(names, row["name"])
+	}
+	assert.ElementsMatch(t, []interface{}{"Alice", "Bob", "Charlie"}, names)
+
+	strLeft := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "code", Type: "TEXT"}},
+		Rows:    []domain.Row{{"code": "01"}},
+	}
+	strRight := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "ref", Type: "TEXT"}},
+		Rows:    []domain.Row{{"ref": "1"}, {"ref": "01"}},
+	}
+	result, err = makeTestJoinOp(types.InnerJoin, strLeft, strRight, "code", "ref").Execute(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1, "string keys are not coerced to numbers")
+}
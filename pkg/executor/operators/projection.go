@@ -68,7 +68,11 @@ func (op *ProjectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		}
 	}
 
-	// 计算输出列
+	// 计算输出列，列引用沿用子算子的列类型
+	childTypes := make(map[string]string, len(childResult.Columns))
+	for _, col := range childResult.Columns {
+		childTypes[col.Name] = col.Type
+	}
 	outputColumns := make([]domain.ColumnInfo, 0, numExprs)
 	for i, expr := range op.config.Expressions {
 		colName := ""
@@ -79,9 +83,13 @@ func (op *ProjectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		} else {
 			colName = fmt.Sprintf("col_%d", i)
 		}
+		colType := "TEXT"
+		if expr.Type == parser.ExprTypeColumn && childTypes[expr.Column] != "" {
+			colType = childTypes[expr.Column]
+		}
 		outputColumns = append(outputColumns, domain.ColumnInfo{
 			Name: colName,
-			Type: "TEXT",
+			Type: colType,
 		})
 	}
 
@@ -107,13 +115,20 @@ func (op *ProjectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 				colName = fmt.Sprintf("col_%d", j)
 			}
 
-			// 简化处理：只处理列引用
-			if expr.Type == parser.ExprTypeColumn {
+			switch {
+			case expr.Type == parser.ExprTypeColumn:
 				if val, ok := row[expr.Column]; ok {
 					newRow[colName] = val
 				}
-			} else {
-				newRow[colName] = nil
+			case hasColumn(row, colName):
+				// 子算子已经计算好的列（如窗口函数、向量距离）
+				newRow[colName] = row[colName]
+			default:
+				val, err := evaluateExpression(row, expr)
+				if err != nil {
+					return nil, fmt.Errorf("evaluate column %s: %w", colName, err)
+				}
+				newRow[colName] = val
 			}
 		}
 		resultRows[i] = newRow
@@ -124,3 +139,9 @@ func (op *ProjectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		Rows:    resultRows,
 	}, nil
 }
+
+// hasColumn 判断行中是否存在列
+func hasColumn(row domain.Row, col string) bool {
+	_, ok := row[col]
+	return ok
+}
@@ -50,6 +50,11 @@ func (op *SelectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 		return nil, fmt.Errorf("SelectionOperator requires at least 1 child")
 	}
 
+	// 常量 REGEXP 模式在执行前校验，避免非法模式被静默当作不匹配
+	if err := validateRegexpPatterns(op.config.Condition); err != nil {
+		return nil, err
+	}
+
 	childResult, err := op.children[0].Execute(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("execute child failed: %w", err)
@@ -58,7 +63,11 @@ func (op *SelectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 	// 应用过滤条件（预分配容量，减少 append 扩容）
 	filteredRows := make([]domain.Row, 0, len(childResult.Rows)/2+1)
 	for _, row := range childResult.Rows {
-		if op.evaluateCondition(row, op.config.Condition) {
+		matched, err := op.evaluateCondition(row, op.config.Condition)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
 			filteredRows = append(filteredRows, row)
 		}
 	}
@@ -79,106 +88,232 @@ func (op *SelectionOperator) Execute(ctx context.Context) (*domain.QueryResult,
 	}, nil
 }
 
-// evaluateCondition 评估条件
-func (op *SelectionOperator) evaluateCondition(row domain.Row, cond *parser.Expression) bool {
+// evaluateCondition 评估条件，只有结果为 TRUE 的行通过（FALSE 和 UNKNOWN 都被过滤）
+func (op *SelectionOperator) evaluateCondition(row domain.Row, cond *parser.Expression) (bool, error) {
+	result, err := op.evaluatePredicate(row, cond)
+	return result.IsTrue(), err
+}
+
+// evaluatePredicate 按 SQL 三值逻辑评估条件，函数求值失败时返回错误
+func (op *SelectionOperator) evaluatePredicate(row domain.Row, cond *parser.Expression) (utils.TriBool, error) {
 	if cond == nil {
-		return true
+		return utils.TriTrue, nil
 	}
 
 	switch cond.Type {
 	case parser.ExprTypeOperator:
 		return op.evaluateOperator(row, cond)
 	case parser.ExprTypeColumn:
-		col := cond.Column
-		val, ok := row[col]
-		if !ok {
-			// Try stripping table qualifier
-			if idx := strings.LastIndex(col, "."); idx >= 0 {
-				val, ok = row[col[idx+1:]]
-			}
+		val, err := op.getExpressionValue(row, cond)
+		if err != nil || val == nil {
+			return utils.TriUnknown, err
 		}
-		if ok && val != nil {
-			switch v := val.(type) {
-			case bool:
-				return v
-			case int:
-				return v != 0
-			case string:
-				return v != ""
-			}
+		switch v := val.(type) {
+		case bool:
+			return utils.TriBoolOf(v), nil
+		case string:
+			return utils.TriBoolOf(v != ""), nil
 		}
-		return false
+		if f, ok := toFloat64(val); ok {
+			return utils.TriBoolOf(f != 0), nil
+		}
+		return utils.TriFalse, nil
 	default:
-		return true
+		return utils.TriTrue, nil
 	}
 }
 
 // evaluateOperator 评估操作符表达式
-func (op *SelectionOperator) evaluateOperator(row domain.Row, expr *parser.Expression) bool {
+// 与 NULL 的比较结果为 UNKNOWN；IS [NOT] NULL 和 <=> 总是返回 TRUE 或 FALSE
+func (op *SelectionOperator) evaluateOperator(row domain.Row, expr *parser.Expression) (utils.TriBool, error) {
 	// Handle unary and logical operators first (no need for both left/right values)
 	switch expr.Operator {
 	case "IS NULL", "is null":
-		leftVal := op.getExpressionValue(row, expr.Left)
-		return leftVal == nil
+		leftVal, err := op.getExpressionValue(row, expr.Left)
+		return utils.TriBoolOf(leftVal == nil), err
 	case "IS NOT NULL", "is not null":
-		leftVal := op.getExpressionValue(row, expr.Left)
-		return leftVal != nil
+		leftVal, err := op.getExpressionValue(row, expr.Left)
+		return utils.TriBoolOf(leftVal != nil), err
 	case "AND", "and":
-		return op.evaluateCondition(row, expr.Left) && op.evaluateCondition(row, expr.Right)
+		left, err := op.evaluatePredicate(row, expr.Left)
+		if err != nil || left == utils.TriFalse {
+			return utils.TriFalse, err
+		}
+		right, err := op.evaluatePredicate(row, expr.Right)
+		return left.And(right), err
 	case "OR", "or":
-		return op.evaluateCondition(row, expr.Left) || op.evaluateCondition(row, expr.Right)
+		left, err := op.evaluatePredicate(row, expr.Left)
+		if err != nil || left == utils.TriTrue {
+			return left, err
+		}
+		right, err := op.evaluatePredicate(row, expr.Right)
+		return left.Or(right), err
+	case "NOT", "not":
+		result, err := op.evaluatePredicate(row, expr.Left)
+		return result.Not(), err
+	}
+
+	leftVal, err := op.getExpressionValue(row, expr.Left)
+	if err != nil {
+		return utils.TriUnknown, err
+	}
+	rightVal, err := op.getExpressionValue(row, expr.Right)
+	if err != nil {
+		return utils.TriUnknown, err
+	}
+
+	switch expr.Operator {
+	case "nulleq", "<=>":
+		if leftVal == nil || rightVal == nil {
+			return utils.TriBoolOf(leftVal == nil && rightVal == nil), nil
+		}
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) == 0), nil
+	case "IN", "in":
+		return op.inValues(row, leftVal, rightVal)
+	case "NOT IN", "not in":
+		result, err := op.inValues(row, leftVal, rightVal)
+		return result.Not(), err
+	case "BETWEEN", "between":
+		return op.betweenValues(row, leftVal, rightVal)
+	case "NOT BETWEEN", "not between":
+		result, err := op.betweenValues(row, leftVal, rightVal)
+		return result.Not(), err
 	}
 
-	leftVal := op.getExpressionValue(row, expr.Left)
-	rightVal := op.getExpressionValue(row, expr.Right)
+	// 其余比较运算中任一操作数为 NULL 时结果为 UNKNOWN
+	if leftVal == nil || rightVal == nil {
+		return utils.TriUnknown, nil
+	}
 
 	switch expr.Operator {
 	case "eq", "===", "=":
-		return op.compareValues(leftVal, rightVal) == 0
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) == 0), nil
 	case "ne", "!=", "<>":
-		return op.compareValues(leftVal, rightVal) != 0
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) != 0), nil
 	case "gt", ">":
-		return op.compareValues(leftVal, rightVal) > 0
-	case "gte", ">=":
-		return op.compareValues(leftVal, rightVal) >= 0
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) > 0), nil
+	case "ge", "gte", ">=":
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) >= 0), nil
 	case "lt", "<":
-		return op.compareValues(leftVal, rightVal) < 0
-	case "lte", "<=":
-		return op.compareValues(leftVal, rightVal) <= 0
-	case "like":
-		return op.likeValues(leftVal, rightVal)
-	case "not like":
-		return !op.likeValues(leftVal, rightVal)
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) < 0), nil
+	case "le", "lte", "<=":
+		return utils.TriBoolOf(op.compareValues(leftVal, rightVal) <= 0), nil
+	case "like", "LIKE", "ilike", "ILIKE":
+		return utils.TriBoolOf(op.matchLike(expr, leftVal, rightVal)), nil
+	case "not like", "NOT LIKE", "not ilike", "NOT ILIKE":
+		return utils.TriBoolOf(!op.matchLike(expr, leftVal, rightVal)), nil
+	case "regexp", "REGEXP", "rlike", "RLIKE":
+		return op.matchRegexp(expr, leftVal, rightVal), nil
+	case "not regexp", "NOT REGEXP", "not rlike", "NOT RLIKE":
+		return op.matchRegexp(expr, leftVal, rightVal).Not(), nil
 	default:
-		return false
+		return utils.TriFalse, nil
+	}
+}
+
+// inValues 评估 value IN (list)
+// value 为 NULL，或没有匹配项且列表中含 NULL 时结果为 UNKNOWN
+func (op *SelectionOperator) inValues(row domain.Row, value, list interface{}) (utils.TriBool, error) {
+	items, ok := list.([]interface{})
+	if !ok || len(items) == 0 {
+		return utils.TriFalse, nil
+	}
+	if value == nil {
+		return utils.TriUnknown, nil
+	}
+
+	result := utils.TriFalse
+	for _, item := range items {
+		itemVal, err := op.listItemValue(row, item)
+		if err != nil {
+			return utils.TriUnknown, err
+		}
+		if itemVal == nil {
+			result = utils.TriUnknown
+			continue
+		}
+		if op.compareValues(value, itemVal) == 0 {
+			return utils.TriTrue, nil
+		}
 	}
+	return result, nil
 }
 
-// getExpressionValue 获取表达式值
-func (op *SelectionOperator) getExpressionValue(row domain.Row, expr *parser.Expression) interface{} {
+// betweenValues 评估 value BETWEEN min AND max，等价于 value >= min AND value <= max
+func (op *SelectionOperator) betweenValues(row domain.Row, value, bounds interface{}) (utils.TriBool, error) {
+	items, ok := bounds.([]interface{})
+	if !ok || len(items) < 2 {
+		return utils.TriFalse, nil
+	}
+
+	compareBound := func(bound interface{}, satisfied func(int) bool) (utils.TriBool, error) {
+		boundVal, err := op.listItemValue(row, bound)
+		if err != nil || value == nil || boundVal == nil {
+			return utils.TriUnknown, err
+		}
+		return utils.TriBoolOf(satisfied(op.compareValues(value, boundVal))), nil
+	}
+
+	lower, err := compareBound(items[0], func(c int) bool { return c >= 0 })
+	if err != nil {
+		return utils.TriUnknown, err
+	}
+	upper, err := compareBound(items[1], func(c int) bool { return c <= 0 })
+	return lower.And(upper), err
+}
+
+// listItemValue 获取 IN 列表或 BETWEEN 边界中的值（BETWEEN 边界以表达式形式存储）
+func (op *SelectionOperator) listItemValue(row domain.Row, item interface{}) (interface{}, error) {
+	if expr, ok := item.(*parser.Expression); ok {
+		return op.getExpressionValue(row, expr)
+	}
+	return item, nil
+}
+
+// getExpressionValue 获取表达式值，函数求值失败时返回错误
+func (op *SelectionOperator) getExpressionValue(row domain.Row, expr *parser.Expression) (interface{}, error) {
 	if expr == nil {
-		return nil
+		return nil, nil
 	}
 
 	switch expr.Type {
 	case parser.ExprTypeColumn:
 		if val, ok := row[expr.Column]; ok {
-			return val
+			return val, nil
 		}
 		// Try stripping table qualifier (e.g., "accounts.deleted_at" → "deleted_at")
 		if idx := strings.LastIndex(expr.Column, "."); idx >= 0 {
-			return row[expr.Column[idx+1:]]
+			return row[expr.Column[idx+1:]], nil
 		}
-		return nil
+		return nil, nil
 	case parser.ExprTypeValue:
-		return expr.Value
+		return expr.Value, nil
+	case parser.ExprTypeFunction:
+		// 函数（如 CAST(s AS SIGNED)）
+		return evaluateExpression(row, expr)
 	case parser.ExprTypeOperator:
-		if op.evaluateOperator(row, expr) {
-			return 1
+		if utils.IsArithmeticOperator(expr.Operator) {
+			val, err := evaluateArithmetic(row, expr)
+			if err != nil {
+				return nil, nil
+			}
+			return val, nil
+		}
+		// 谓词结果：TRUE → 1，FALSE → 0，UNKNOWN → NULL
+		result, err := op.evaluateOperator(row, expr)
+		if err != nil {
+			return nil, err
+		}
+		switch result {
+		case utils.TriTrue:
+			return 1, nil
+		case utils.TriFalse:
+			return 0, nil
+		default:
+			return nil, nil
 		}
-		return 0
 	default:
-		return nil
+		return nil, nil
 	}
 }
 
@@ -200,9 +335,9 @@ func (op *SelectionOperator) compareValues(a, b interface{}) int {
 		return 0
 	}
 
-	// 尝试转换为float64比较
-	aFloat, aOk := a.(float64)
-	bFloat, bOk := b.(float64)
+	// 尝试转换为float64比较（包括 SUM/AVG 结果与整数常量的混合比较）
+	aFloat, aOk := toFloat64(a)
+	bFloat, bOk := toFloat64(b)
 	if aOk && bOk {
 		if aFloat < bFloat {
 			return -1
@@ -224,9 +359,26 @@ func (op *SelectionOperator) compareValues(a, b interface{}) int {
 		return 0
 	}
 
+	// 字符串与数值比较：与 MySQL 一致，字符串按数值比较（不是数值的字符串为 0）
+	if _, isNum := toFloat64(b); aOk && isNum {
+		return op.compareValues(stringNumber(aStr), bFloat)
+	}
+	if _, isNum := toFloat64(a); bOk && isNum {
+		return op.compareValues(aFloat, stringNumber(bStr))
+	}
+
 	return 0
 }
 
+// stringNumber 字符串对应的数值，不是数值时为 0
+func stringNumber(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
 // toInt64 尝试转换为int64（仅限整数类型，不转换float64以避免精度丢失）
 func toInt64(v interface{}) (int64, bool) {
 	switch val := v.(type) {
@@ -250,15 +402,66 @@ func toInt64(v interface{}) (int64, bool) {
 	return 0, false
 }
 
-// likeValues implements SQL LIKE pattern matching
+// likeValues implements SQL LIKE pattern matching with the default escape character
 func (op *SelectionOperator) likeValues(value, pattern interface{}) bool {
-	valStr, ok := value.(string)
-	if !ok {
-		valStr = utils.ToString(value)
+	return utils.MatchesLikeEscape(utils.ToString(value), utils.ToString(pattern), utils.DefaultLikeEscape)
+}
+
+// matchLike 按表达式的 ESCAPE 字符和排序规则执行 LIKE / ILIKE 匹配
+// ILIKE 总是忽略大小写；LIKE 仅在显式 COLLATE 为 _ci 排序规则时忽略大小写
+func (op *SelectionOperator) matchLike(expr *parser.Expression, value, pattern interface{}) bool {
+	escape := rune(utils.DefaultLikeEscape)
+	if expr.Escape != "" {
+		escape = []rune(expr.Escape)[0]
 	}
-	patStr, ok := pattern.(string)
-	if !ok {
-		patStr = utils.ToString(pattern)
+	collation := expressionCollation(expr)
+	if collation == "" && strings.Contains(strings.ToUpper(expr.Operator), "ILIKE") {
+		collation = "utf8mb4_general_ci"
+	}
+	return utils.MatchesLikeWith(utils.ToString(value), utils.ToString(pattern), escape, collation)
+}
+
+// matchRegexp 执行 REGEXP / RLIKE 匹配，非法的模式结果为 UNKNOWN
+func (op *SelectionOperator) matchRegexp(expr *parser.Expression, value, pattern interface{}) utils.TriBool {
+	matched, err := utils.MatchesRegexp(utils.ToString(value), utils.ToString(pattern), expressionCollation(expr))
+	if err != nil {
+		return utils.TriUnknown
+	}
+	return utils.TriBoolOf(matched)
+}
+
+// expressionCollation 返回比较操作数上通过 COLLATE 指定的排序规则
+func expressionCollation(expr *parser.Expression) string {
+	if expr.Right != nil && expr.Right.Collation != "" {
+		return expr.Right.Collation
+	}
+	if expr.Left != nil {
+		return expr.Left.Collation
+	}
+	return ""
+}
+
+// isRegexpOperator 判断是否为 REGEXP / RLIKE 类操作符
+func isRegexpOperator(operator string) bool {
+	switch strings.ToUpper(operator) {
+	case "REGEXP", "RLIKE", "NOT REGEXP", "NOT RLIKE":
+		return true
+	}
+	return false
+}
+
+// validateRegexpPatterns 校验条件中所有常量 REGEXP 模式
+func validateRegexpPatterns(expr *parser.Expression) error {
+	if expr == nil || expr.Type != parser.ExprTypeOperator {
+		return nil
+	}
+	if isRegexpOperator(expr.Operator) && expr.Right != nil && expr.Right.Type == parser.ExprTypeValue && expr.Right.Value != nil {
+		if _, err := utils.MatchesRegexp("", utils.ToString(expr.Right.Value), expressionCollation(expr)); err != nil {
+			return err
+		}
+	}
+	if err := validateRegexpPatterns(expr.Left); err != nil {
+		return err
 	}
-	return utils.MatchesLike(valStr, patStr)
+	return validateRegexpPatterns(expr.Right)
 }
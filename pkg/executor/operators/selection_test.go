@@ -1,10 +1,14 @@
 package operators
 
 import (
+	"context"
+	"strings"
 	"testing"
 
+	"github.com/kasuganosora/sqlexec/pkg/optimizer/plan"
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
 // TestLikeValues tests the LIKE operator implementation
@@ -213,8 +217,7 @@ func TestEvaluateOperator(t *testing.T) {
 			expected: true,
 		},
 
-		// NULL handling - compareValues treats NULL as equal to everything (returns 0)
-		// This is a design choice of the current implementation
+		// NULL handling - comparisons with NULL are UNKNOWN and never match
 		{
 			name: "eq with NULL value",
 			row:  domain.Row{"id": nil},
@@ -224,7 +227,7 @@ func TestEvaluateOperator(t *testing.T) {
 				Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: "id"},
 				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: int64(123)},
 			},
-			expected: true, // compareValues(nil, 123) returns 0 (equal)
+			expected: false, // NULL = 123 is UNKNOWN
 		},
 		{
 			name: "LIKE with NULL value",
@@ -235,7 +238,7 @@ func TestEvaluateOperator(t *testing.T) {
 				Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: "name"},
 				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: "%"},
 			},
-			expected: true, // nil is converted to "" by utils.ToString and "" matches "%"
+			expected: false, // NULL LIKE '%' is UNKNOWN
 		},
 
 		// Different data types
@@ -278,8 +281,11 @@ func TestEvaluateOperator(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := op.evaluateOperator(tt.row, tt.expr)
-			if result != tt.expected {
+			tri, err := op.evaluateOperator(tt.row, tt.expr)
+			if err != nil {
+				t.Fatalf("evaluateOperator() error: %v", err)
+			}
+			if result := tri.IsTrue(); result != tt.expected {
 				t.Errorf("evaluateOperator() = %v, expected %v", result, tt.expected)
 			}
 		})
@@ -469,7 +475,10 @@ func TestEvaluateCondition(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := op.evaluateCondition(tt.row, tt.cond)
+			result, err := op.evaluateCondition(tt.row, tt.cond)
+			if err != nil {
+				t.Fatalf("evaluateCondition() error: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("evaluateCondition() = %v, expected %v", result, tt.expected)
 			}
@@ -537,7 +546,10 @@ func TestGetExpressionValue(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := op.getExpressionValue(tt.row, tt.expr)
+			result, err := op.getExpressionValue(tt.row, tt.expr)
+			if err != nil {
+				t.Fatalf("getExpressionValue() error: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("getExpressionValue() = %v (%T), expected %v (%T)",
 					result, result, tt.expected, tt.expected)
@@ -553,6 +565,44 @@ func TestSelectionOperatorIntegration(t *testing.T) {
 	t.Log("Integration tests require dataaccess.Service mock - skipping for unit test coverage")
 }
 
+// TestSelectionOperator_FunctionConditions tests functions in the condition (e.g. CAST(code AS SIGNED) = 5):
+// their values are compared, and an evaluation error fails the query instead of filtering the row out
+func TestSelectionOperator_FunctionConditions(t *testing.T) {
+	child := &mockChildOperator{result: &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "code", Type: "VARCHAR"}},
+		Rows:    []domain.Row{{"code": "5"}, {"code": " 5"}, {"code": "6"}},
+	}}
+	selection := func(fn string, args ...parser.Expression) *SelectionOperator {
+		return &SelectionOperator{
+			BaseOperator: &BaseOperator{children: []Operator{child}},
+			config: &plan.SelectionConfig{Condition: &parser.Expression{
+				Type:     parser.ExprTypeOperator,
+				Operator: "eq",
+				Left:     &parser.Expression{Type: parser.ExprTypeFunction, Function: fn, Args: args},
+				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: int64(5)},
+			}},
+		}
+	}
+	code := parser.Expression{Type: parser.ExprTypeColumn, Column: "code"}
+
+	result, err := selection("CAST", code, parser.Expression{Type: parser.ExprTypeValue, Value: "SIGNED"}).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("expected 2 rows with CAST(code AS SIGNED) = 5, got %v", result.Rows)
+	}
+
+	_, err = selection("CAST", code, parser.Expression{Type: parser.ExprTypeValue, Value: "NO_SUCH_TYPE"}).Execute(context.Background())
+	if err == nil {
+		t.Fatal("expected the CAST error to be returned")
+	}
+	_, err = selection("no_such_function", code).Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "function not found") {
+		t.Fatalf("expected a function not found error, got %v", err)
+	}
+}
+
 // BenchmarkLikeValues benchmarks the LIKE pattern matching
 func BenchmarkLikeValues(b *testing.B) {
 	op := &SelectionOperator{}
@@ -577,3 +627,149 @@ func BenchmarkLikeValues(b *testing.B) {
 		})
 	}
 }
+
+// TestEvaluateOperator_ParserComparisonOps covers the operator names produced by the
+// parser (ge/le) and mixed numeric comparisons such as SUM results against integer literals.
+func TestEvaluateOperator_ParserComparisonOps(t *testing.T) {
+	op := &SelectionOperator{}
+	row := domain.Row{"total": float64(900), "cnt": int64(2)}
+
+	tests := []struct {
+		name     string
+		column   string
+		operator string
+		value    interface{}
+		expected bool
+	}{
+		{"ge equal", "cnt", "ge", int64(2), true},
+		{"ge less", "cnt", "ge", int64(3), false},
+		{"le equal", "cnt", "le", int64(2), true},
+		{"float gt int", "total", "gt", int64(500), true},
+		{"float lt int", "total", "lt", int64(500), false},
+		{"float eq int", "total", "eq", int64(900), true},
+		{"float ge int", "total", "ge", int64(900), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := &parser.Expression{
+				Type:     parser.ExprTypeOperator,
+				Operator: tt.operator,
+				Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: tt.column},
+				Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: tt.value},
+			}
+			if got, err := op.evaluateOperator(row, expr); err != nil || got.IsTrue() != tt.expected {
+				t.Errorf("%s %s %v = %v, want %v", tt.column, tt.operator, tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEvaluatePredicate_ThreeValuedLogic covers SQL three-valued logic with NULL operands.
+func TestEvaluatePredicate_ThreeValuedLogic(t *testing.T) {
+	op := &SelectionOperator{}
+	row := domain.Row{"a": nil, "b": int64(1)}
+
+	col := func(name string) *parser.Expression {
+		return &parser.Expression{Type: parser.ExprTypeColumn, Column: name}
+	}
+	val := func(v interface{}) *parser.Expression {
+		return &parser.Expression{Type: parser.ExprTypeValue, Value: v}
+	}
+	binary := func(operator string, left, right *parser.Expression) *parser.Expression {
+		return &parser.Expression{Type: parser.ExprTypeOperator, Operator: operator, Left: left, Right: right}
+	}
+
+	tests := []struct {
+		name     string
+		expr     *parser.Expression
+		expected utils.TriBool
+	}{
+		{"NULL = 1", binary("eq", col("a"), val(int64(1))), utils.TriUnknown},
+		{"NULL <> 1", binary("ne", col("a"), val(int64(1))), utils.TriUnknown},
+		{"NULL = NULL", binary("eq", col("a"), val(nil)), utils.TriUnknown},
+		{"NULL <=> NULL", binary("nulleq", col("a"), val(nil)), utils.TriTrue},
+		{"1 <=> NULL", binary("nulleq", col("b"), col("a")), utils.TriFalse},
+		{"1 <=> 1", binary("nulleq", col("b"), val(int64(1))), utils.TriTrue},
+		{"NOT (NULL = 1)", &parser.Expression{Type: parser.ExprTypeOperator, Operator: "not", Left: binary("eq", col("a"), val(int64(1)))}, utils.TriUnknown},
+		{"UNKNOWN AND FALSE", binary("and", binary("eq", col("a"), val(int64(1))), binary("eq", col("b"), val(int64(2)))), utils.TriFalse},
+		{"UNKNOWN AND TRUE", binary("and", binary("eq", col("a"), val(int64(1))), binary("eq", col("b"), val(int64(1)))), utils.TriUnknown},
+		{"UNKNOWN OR TRUE", binary("or", binary("eq", col("a"), val(int64(1))), binary("eq", col("b"), val(int64(1)))), utils.TriTrue},
+		{"UNKNOWN OR FALSE", binary("or", binary("eq", col("a"), val(int64(1))), binary("eq", col("b"), val(int64(2)))), utils.TriUnknown},
+		{"NULL IN (1, 2)", binary("IN", col("a"), val([]interface{}{int64(1), int64(2)})), utils.TriUnknown},
+		{"1 IN (NULL, 1)", binary("IN", col("b"), val([]interface{}{nil, int64(1)})), utils.TriTrue},
+		{"1 NOT IN (2, NULL)", binary("NOT IN", col("b"), val([]interface{}{int64(2), nil})), utils.TriUnknown},
+		{"1 NOT IN (2, 3)", binary("NOT IN", col("b"), val([]interface{}{int64(2), int64(3)})), utils.TriTrue},
+		{"1 BETWEEN NULL AND 0", binary("BETWEEN", col("b"), val([]interface{}{val(nil), val(int64(0))})), utils.TriFalse},
+		{"1 BETWEEN 0 AND NULL", binary("BETWEEN", col("b"), val([]interface{}{val(int64(0)), val(nil)})), utils.TriUnknown},
+		{"NULL LIKE '%'", binary("LIKE", col("a"), val("%")), utils.TriUnknown},
+		{"NULL IS NULL", &parser.Expression{Type: parser.ExprTypeOperator, Operator: "IS NULL", Left: col("a")}, utils.TriTrue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := op.evaluatePredicate(row, tt.expr); err != nil || got != tt.expected {
+				t.Errorf("evaluatePredicate(%s) = %v (%v), want %v", tt.name, got, err, tt.expected)
+			}
+			// 只有 TRUE 的行通过过滤
+			if got, _ := op.evaluateCondition(row, tt.expr); got != (tt.expected == utils.TriTrue) {
+				t.Errorf("evaluateCondition(%s) = %v", tt.name, got)
+			}
+		})
+	}
+}
+
+// TestEvaluatePredicate_PatternMatching covers LIKE with ESCAPE and COLLATE, ILIKE and REGEXP.
+func TestEvaluatePredicate_PatternMatching(t *testing.T) {
+	op := &SelectionOperator{}
+	row := domain.Row{"name": "Hello_World", "price": "50%", "n": nil}
+
+	col := func(name string) *parser.Expression {
+		return &parser.Expression{Type: parser.ExprTypeColumn, Column: name}
+	}
+	val := func(v interface{}) *parser.Expression {
+		return &parser.Expression{Type: parser.ExprTypeValue, Value: v}
+	}
+	binary := func(operator string, left, right *parser.Expression) *parser.Expression {
+		return &parser.Expression{Type: parser.ExprTypeOperator, Operator: operator, Left: left, Right: right}
+	}
+	escaped := func(operator, pattern, escape string) *parser.Expression {
+		expr := binary(operator, col("name"), val(pattern))
+		expr.Escape = escape
+		return expr
+	}
+
+	tests := []struct {
+		name     string
+		expr     *parser.Expression
+		expected utils.TriBool
+	}{
+		{"LIKE default escape", binary("LIKE", col("price"), val(`50\%`)), utils.TriTrue},
+		{"LIKE escaped underscore", binary("LIKE", col("name"), val(`Hello\_%`)), utils.TriTrue},
+		{"LIKE custom escape", escaped("LIKE", "Hello!_W%", "!"), utils.TriTrue},
+		{"LIKE custom escape literal", escaped("LIKE", "Hello!%", "!"), utils.TriFalse},
+		{"NOT LIKE", binary("NOT LIKE", col("name"), val("World%")), utils.TriTrue},
+		{"LIKE is case sensitive", binary("LIKE", col("name"), val("hello%")), utils.TriFalse},
+		{"LIKE with _ci collation", binary("LIKE", col("name"), &parser.Expression{Type: parser.ExprTypeValue, Value: "hello%", Collation: "utf8mb4_general_ci"}), utils.TriTrue},
+		{"ILIKE", binary("ILIKE", col("name"), val("hello%")), utils.TriTrue},
+		{"NOT ILIKE", binary("NOT ILIKE", col("name"), val("HELLO%")), utils.TriFalse},
+		{"REGEXP", binary("REGEXP", col("name"), val("^H.*d$")), utils.TriTrue},
+		{"NOT REGEXP", binary("NOT REGEXP", col("name"), val("[0-9]")), utils.TriTrue},
+		{"REGEXP case sensitive", binary("REGEXP", col("name"), val("^h")), utils.TriFalse},
+		{"NULL REGEXP", binary("REGEXP", col("n"), val(".")), utils.TriUnknown},
+		{"NULL NOT ILIKE", binary("NOT ILIKE", col("n"), val("%")), utils.TriUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := op.evaluatePredicate(row, tt.expr); err != nil || got != tt.expected {
+				t.Errorf("evaluatePredicate(%s) = %v (%v), want %v", tt.name, got, err, tt.expected)
+			}
+		})
+	}
+
+	err := validateRegexpPatterns(binary("and", binary("eq", col("n"), val(1)), binary("REGEXP", col("name"), val("("))))
+	if err == nil {
+		t.Error("expected invalid REGEXP pattern to be rejected")
+	}
+}
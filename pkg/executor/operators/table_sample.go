@@ -0,0 +1,71 @@
+package operators
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// sampleBlockSize SYSTEM 抽样的数据块行数：块内的行同时保留或丢弃
+const sampleBlockSize = 64
+
+// sampleRows 按 TABLESAMPLE 从扫描结果中抽取行，保持原有的行序。
+// n ROWS 使用蓄水池抽样；BERNOULLI 逐行、SYSTEM 逐块以 Percent% 的概率保留
+func sampleRows(rows []domain.Row, sample *parser.TableSample) []domain.Row {
+	seed := time.Now().UnixNano()
+	if sample.Seed != nil {
+		seed = *sample.Seed
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	if sample.Rows > 0 {
+		if int64(len(rows)) <= sample.Rows {
+			return rows
+		}
+		picked := make([]int, sample.Rows)
+		for i := range picked {
+			picked[i] = i
+		}
+		for i := len(picked); i < len(rows); i++ {
+			if j := rng.Int63n(int64(i + 1)); j < sample.Rows {
+				picked[j] = i
+			}
+		}
+		sort.Ints(picked)
+		sampled := make([]domain.Row, len(picked))
+		for i, pos := range picked {
+			sampled[i] = rows[pos]
+		}
+		return sampled
+	}
+
+	var sampled []domain.Row
+	keep := false
+	for i, row := range rows {
+		switch {
+		case sample.Method == parser.SampleBernoulli:
+			keep = rng.Float64()*100 < sample.Percent
+		case i%sampleBlockSize == 0:
+			keep = rng.Float64()*100 < sample.Percent
+		}
+		if keep {
+			sampled = append(sampled, row)
+		}
+	}
+	return sampled
+}
+
+// pageRows 取 offset 之后的至多 limit 行，limit 为 0 表示不限制（与下推到扫描的 LIMIT 一致）
+func pageRows(rows []domain.Row, offset, limit int64) []domain.Row {
+	if offset >= int64(len(rows)) {
+		return nil
+	}
+	rows = rows[offset:]
+	if limit > 0 && limit < int64(len(rows)) {
+		rows = rows[:limit]
+	}
+	return rows
+}
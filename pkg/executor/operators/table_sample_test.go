@@ -0,0 +1,57 @@
+package operators
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func numberedRows(n int) []domain.Row {
+	rows := make([]domain.Row, n)
+	for i := range rows {
+		rows[i] = domain.Row{"id": int64(i)}
+	}
+	return rows
+}
+
+func TestSampleRows(t *testing.T) {
+	rows := numberedRows(10000)
+	seed := int64(7)
+
+	bernoulli := sampleRows(rows, &parser.TableSample{Method: parser.SampleBernoulli, Percent: 10, Seed: &seed})
+	assert.InDelta(t, 1000, len(bernoulli), 150)
+	assert.Equal(t, bernoulli, sampleRows(rows, &parser.TableSample{Method: parser.SampleBernoulli, Percent: 10, Seed: &seed}),
+		"the same seed yields the same sample")
+
+	// SYSTEM 按块保留：样本由完整的连续块组成
+	system := sampleRows(rows, &parser.TableSample{Method: parser.SampleSystem, Percent: 20, Seed: &seed})
+	require.NotEmpty(t, system)
+	assert.Zero(t, len(system)%sampleBlockSize)
+	for i := 0; i < len(system); i += sampleBlockSize {
+		start := system[i]["id"].(int64)
+		assert.Zero(t, start%sampleBlockSize)
+		assert.Equal(t, start+sampleBlockSize-1, system[i+sampleBlockSize-1]["id"])
+	}
+
+	// n ROWS：恰好 n 行且保持原有的行序
+	fixed := sampleRows(rows, &parser.TableSample{Rows: 50, Seed: &seed})
+	require.Len(t, fixed, 50)
+	for i := 1; i < len(fixed); i++ {
+		assert.Less(t, fixed[i-1]["id"].(int64), fixed[i]["id"].(int64))
+	}
+	assert.Len(t, sampleRows(rows[:10], &parser.TableSample{Rows: 50}), 10)
+
+	assert.Empty(t, sampleRows(rows, &parser.TableSample{Method: parser.SampleBernoulli, Percent: 0}))
+	assert.Len(t, sampleRows(rows, &parser.TableSample{Method: parser.SampleSystem, Percent: 100}), len(rows))
+}
+
+func TestPageRows(t *testing.T) {
+	rows := numberedRows(5)
+	assert.Len(t, pageRows(rows, 0, 0), 5)
+	assert.Equal(t, rows[1:3], pageRows(rows, 1, 2))
+	assert.Equal(t, rows[3:], pageRows(rows, 3, 10))
+	assert.Empty(t, pageRows(rows, 5, 1))
+}
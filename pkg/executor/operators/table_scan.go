@@ -3,6 +3,7 @@ package operators
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/kasuganosora/sqlexec/pkg/dataaccess"
 	"github.com/kasuganosora/sqlexec/pkg/optimizer/feedback"
@@ -36,24 +37,90 @@ func (op *TableScanOperator) Execute(ctx context.Context) (*domain.QueryResult,
 	options := &dataaccess.QueryOptions{
 		SelectColumns: make([]string, 0),
 		Filters:       op.config.Filters,
+		ForceIndex:    op.config.ForceIndex,
+		IgnoreIndexes: op.config.IgnoreIndexes,
 	}
 
 	for _, col := range op.config.Columns {
 		options.SelectColumns = append(options.SelectColumns, col.Name)
 	}
 
-	if op.config.LimitInfo != nil {
+	// 抽样在读取之后进行，下推的 LIMIT 作用于样本
+	if op.config.LimitInfo != nil && op.config.Sample == nil {
 		options.Limit = int(op.config.LimitInfo.Limit)
 		options.Offset = int(op.config.LimitInfo.Offset)
 	}
 
-	result, err := op.dataAccessService.Query(ctx, op.config.TableName, options)
+	var result *domain.QueryResult
+	var err error
+	if op.config.Parallelism > 1 && len(options.Filters) == 0 && options.Limit == 0 && options.Offset == 0 {
+		result, err = op.parallelScan(ctx, options, op.config.Parallelism)
+	} else {
+		result, err = op.dataAccessService.Query(ctx, op.config.TableName, options)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("query table failed: %w", err)
 	}
 
+	domain.RecordRowsExamined(ctx, result.Rows)
+
 	// DQ feedback: record actual table size for cost model calibration
 	feedback.GetGlobalFeedback().RecordTableSize(op.config.TableName, int64(len(result.Rows)))
 
+	if op.config.Sample != nil {
+		sampled := *result
+		sampled.Rows = sampleRows(result.Rows, op.config.Sample)
+		if limit := op.config.LimitInfo; limit != nil && (limit.Limit > 0 || limit.Offset > 0) {
+			sampled.Rows = pageRows(sampled.Rows, limit.Offset, limit.Limit)
+		}
+		sampled.Total = int64(len(sampled.Rows))
+		return &sampled, nil
+	}
 	return result, nil
 }
+
+// parallelScanPageSize 并行扫描时每个分页的行数
+const parallelScanPageSize = 1024
+
+// parallelScan 按 PARALLEL(n) hint 以 n 个并发分页读取整表，结果保持分页顺序
+// 每一轮并发读取 n 个连续分页，直到某个分页不满为止
+func (op *TableScanOperator) parallelScan(ctx context.Context, options *dataaccess.QueryOptions, workers int) (*domain.QueryResult, error) {
+	merged := &domain.QueryResult{}
+	pages := make([]*domain.QueryResult, workers)
+	errs := make([]error, workers)
+
+	for round := 0; ; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			pageOptions := *options
+			pageOptions.Offset = (round*workers + i) * parallelScanPageSize
+			pageOptions.Limit = parallelScanPageSize
+
+			wg.Add(1)
+			go func(i int, pageOptions dataaccess.QueryOptions) {
+				defer wg.Done()
+				pages[i], errs[i] = op.dataAccessService.Query(ctx, op.config.TableName, &pageOptions)
+			}(i, pageOptions)
+		}
+		wg.Wait()
+
+		for i := 0; i < workers; i++ {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			page := pages[i]
+			if merged.Columns == nil {
+				merged.Columns = page.Columns
+			}
+			merged.Rows = append(merged.Rows, page.Rows...)
+			if len(page.Rows) < parallelScanPageSize {
+				merged.Total = int64(len(merged.Rows))
+				return merged, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Package graph 实现保存为边表的图上的遍历，供 GRAPH_REACHABLE、GRAPH_SHORTEST_PATH 等表函数使用
+//
+// 边表的每一行是一条有向边（起点、终点和可选的权重），遍历前一次读入并建立邻接表，
+// 之后的广度优先搜索与 Dijkstra 最短路径都在内存中完成，不产生递归 CTE 那样逐层膨胀的中间结果。
+// 节点值统一为 int64（各种整数与整数值的浮点数）或 string（字符串与 []byte），
+// 因此边表中的 INT 与 BIGINT 节点、查询中的 1 与 '1' 可以互相匹配。
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// Direction 遍历方向
+type Direction int
+
+const (
+	Outgoing Direction = iota // 沿边的方向
+	Incoming                  // 逆着边的方向
+	Both                      // 把边当作无向边
+)
+
+// ParseDirection 解析遍历方向 'out'、'in' 或 'both'，空串为 'out'
+func ParseDirection(s string) (Direction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "out", "outgoing":
+		return Outgoing, nil
+	case "in", "incoming":
+		return Incoming, nil
+	case "both", "any":
+		return Both, nil
+	}
+	return Outgoing, fmt.Errorf("unknown traversal direction '%s', expected 'out', 'in' or 'both'", s)
+}
+
+// Edge 一条有向边
+type Edge struct {
+	From   interface{}
+	To     interface{}
+	Weight float64
+}
+
+// arc 邻接表中的一条出边
+type arc struct {
+	to     interface{}
+	weight float64
+}
+
+// Graph 按遍历方向建立的邻接表
+type Graph struct {
+	adj      map[interface{}][]arc
+	nodes    map[interface{}]bool
+	weighted bool // 存在权重不为 1 的边
+}
+
+// New 按遍历方向建立图，起点或终点为 NULL 的边被忽略，权重不能为负
+func New(edges []Edge, dir Direction) (*Graph, error) {
+	g := &Graph{adj: make(map[interface{}][]arc), nodes: make(map[interface{}]bool)}
+	for _, e := range edges {
+		from, to := NodeKey(e.From), NodeKey(e.To)
+		if from == nil || to == nil {
+			continue
+		}
+		if e.Weight < 0 || math.IsNaN(e.Weight) {
+			return nil, fmt.Errorf("edge %v -> %v has a negative weight", from, to)
+		}
+		if e.Weight != 1 {
+			g.weighted = true
+		}
+		g.nodes[from], g.nodes[to] = true, true
+		if dir != Incoming {
+			g.adj[from] = append(g.adj[from], arc{to: to, weight: e.Weight})
+		}
+		if dir != Outgoing {
+			g.adj[to] = append(g.adj[to], arc{to: from, weight: e.Weight})
+		}
+	}
+	return g, nil
+}
+
+// NodeKey 把节点值统一为可比较的 int64 或 string，NULL 返回 nil
+func NodeKey(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case float32, float64:
+		f, _ := utils.ToFloat64(val)
+		if f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			return int64(f)
+		}
+		return utils.ToString(val)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		n, _ := utils.ToInt64(val)
+		return n
+	}
+	return utils.ToString(v)
+}
+
+// resolve 返回图中与 v 对应的节点：整数与数字字符串可以互相匹配，图中没有时返回统一后的 v
+func (g *Graph) resolve(v interface{}) interface{} {
+	key := NodeKey(v)
+	if g.nodes[key] {
+		return key
+	}
+	switch k := key.(type) {
+	case int64:
+		if s := strconv.FormatInt(k, 10); g.nodes[s] {
+			return s
+		}
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(k), 10, 64); err == nil && g.nodes[n] {
+			return n
+		}
+	}
+	return key
+}
+
+// Visit 从起点可达的一个节点
+type Visit struct {
+	Node   interface{}
+	Depth  int         // 到起点的最少跳数
+	Parent interface{} // 最短路径上的前一个节点，起点为 nil
+}
+
+// Reachable 广度优先返回从 start 出发 maxDepth 跳（小于 0 时不限）以内可达的节点，
+// 按深度和发现顺序排列，第一个是深度为 0 的起点
+func (g *Graph) Reachable(start interface{}, maxDepth int) []Visit {
+	start = g.resolve(start)
+	if start == nil {
+		return nil
+	}
+	visits := []Visit{{Node: start}}
+	seen := map[interface{}]bool{start: true}
+	for head := 0; head < len(visits); head++ {
+		cur := visits[head]
+		if maxDepth >= 0 && cur.Depth >= maxDepth {
+			continue
+		}
+		for _, a := range g.adj[cur.Node] {
+			if seen[a.to] {
+				continue
+			}
+			seen[a.to] = true
+			visits = append(visits, Visit{Node: a.to, Depth: cur.Depth + 1, Parent: cur.Node})
+		}
+	}
+	return visits
+}
+
+// Step 最短路径上的一个节点
+type Step struct {
+	Node interface{}
+	Cost float64 // 从起点到该节点的累计权重
+}
+
+// ShortestPath 返回从 from 到 to、不超过 maxDepth 跳（小于 0 时不限）的累计权重最小的路径，
+// 包含两个端点；不可达时返回 nil。所有边权重为 1 时按广度优先搜索，否则使用 Dijkstra 算法
+func (g *Graph) ShortestPath(from, to interface{}, maxDepth int) []Step {
+	from, to = g.resolve(from), g.resolve(to)
+	if from == nil || to == nil {
+		return nil
+	}
+	if from == to {
+		return []Step{{Node: from}}
+	}
+	if !g.weighted {
+		return g.hopPath(from, to, maxDepth)
+	}
+	return g.weightedPath(from, to, maxDepth)
+}
+
+// hopPath 无权图上的最短路径：沿广度优先搜索树的前驱从 to 回溯到 from
+func (g *Graph) hopPath(from, to interface{}, maxDepth int) []Step {
+	parents := make(map[interface{}]interface{})
+	for _, v := range g.Reachable(from, maxDepth) {
+		parents[v.Node] = v.Parent
+		if v.Node != to {
+			continue
+		}
+		path := make([]Step, v.Depth+1)
+		node := to
+		for i := v.Depth; i >= 0; i-- {
+			path[i] = Step{Node: node, Cost: float64(i)}
+			node = parents[node]
+		}
+		return path
+	}
+	return nil
+}
+
+// pathState Dijkstra 的搜索状态；限制跳数时同一节点按已走的跳数区分
+type pathState struct {
+	node interface{}
+	hops int
+}
+
+// pathItem 优先队列中的一个状态
+type pathItem struct {
+	state pathState
+	cost  float64
+}
+
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// weightedPath 带权图上的最短路径（Dijkstra）
+func (g *Graph) weightedPath(from, to interface{}, maxDepth int) []Step {
+	limited := maxDepth >= 0
+	start := pathState{node: from}
+	dist := map[pathState]float64{start: 0}
+	prev := make(map[pathState]pathState)
+	done := make(map[pathState]bool)
+	queue := &pathQueue{{state: start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		cur := item.state
+		if done[cur] {
+			continue
+		}
+		done[cur] = true
+		if cur.node == to {
+			var path []Step
+			for s := cur; ; s = prev[s] {
+				path = append(path, Step{Node: s.node, Cost: dist[s]})
+				if s == start {
+					break
+				}
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		if limited && cur.hops >= maxDepth {
+			continue
+		}
+		for _, a := range g.adj[cur.node] {
+			next := pathState{node: a.to}
+			if limited {
+				next.hops = cur.hops + 1
+			}
+			cost := item.cost + a.weight
+			if d, ok := dist[next]; ok && d <= cost {
+				continue
+			}
+			dist[next] = cost
+			prev[next] = cur
+			heap.Push(queue, pathItem{state: next, cost: cost})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chain 1 -> 2 -> 3 -> 4，外加 1 -> 5 与环 4 -> 1
+func chain(t *testing.T, dir Direction) *Graph {
+	g, err := New([]Edge{
+		{From: 1, To: 2, Weight: 1},
+		{From: int64(2), To: int32(3), Weight: 1},
+		{From: 3, To: 4, Weight: 1},
+		{From: 1, To: 5, Weight: 1},
+		{From: 4, To: 1, Weight: 1},
+		{From: nil, To: 9, Weight: 1},
+	}, dir)
+	require.NoError(t, err)
+	return g
+}
+
+func nodes(visits []Visit) []interface{} {
+	var out []interface{}
+	for _, v := range visits {
+		out = append(out, v.Node)
+	}
+	return out
+}
+
+func TestReachable(t *testing.T) {
+	g := chain(t, Outgoing)
+	visits := g.Reachable(1, 2)
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(5), int64(3)}, nodes(visits))
+	assert.Equal(t, Visit{Node: int64(3), Depth: 2, Parent: int64(2)}, visits[3])
+	assert.Nil(t, visits[0].Parent)
+
+	// 不限深度时环不会导致重复访问
+	assert.Len(t, g.Reachable(int64(1), -1), 5)
+	// 数字字符串匹配整数节点；不在图中的起点只返回自身
+	assert.Len(t, g.Reachable("3", 1), 2)
+	assert.Equal(t, []interface{}{int64(42)}, nodes(g.Reachable(42, 3)))
+
+	assert.Equal(t, []interface{}{int64(5), int64(1), int64(4)}, nodes(chain(t, Incoming).Reachable(5, 2)))
+	assert.Len(t, chain(t, Both).Reachable(5, 1), 2)
+}
+
+func TestShortestPath(t *testing.T) {
+	g := chain(t, Outgoing)
+	path := g.ShortestPath(2, 5, -1)
+	assert.Equal(t, []Step{{int64(2), 0}, {int64(3), 1}, {int64(4), 2}, {int64(1), 3}, {int64(5), 4}}, path)
+	assert.Nil(t, g.ShortestPath(2, 5, 3))
+	assert.Nil(t, g.ShortestPath(5, 1, -1))
+	assert.Equal(t, []Step{{Node: int64(5)}}, g.ShortestPath(5, 5, 0))
+
+	// 带权：直达 a -> c 较贵，经 b 更便宜；限制为 1 跳时只能直达
+	g, err := New([]Edge{
+		{From: "a", To: "c", Weight: 10},
+		{From: "a", To: "b", Weight: 2},
+		{From: []byte("b"), To: "c", Weight: 3},
+	}, Outgoing)
+	require.NoError(t, err)
+	assert.Equal(t, []Step{{"a", 0}, {"b", 2}, {"c", 5}}, g.ShortestPath("a", "c", -1))
+	assert.Equal(t, []Step{{"a", 0}, {"c", 10}}, g.ShortestPath("a", "c", 1))
+
+	_, err = New([]Edge{{From: 1, To: 2, Weight: -1}}, Outgoing)
+	assert.Error(t, err)
+}
+
+func TestParseDirection(t *testing.T) {
+	for in, want := range map[string]Direction{"": Outgoing, "OUT": Outgoing, "in": Incoming, "both": Both} {
+		dir, err := ParseDirection(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, dir)
+	}
+	_, err := ParseDirection("up")
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+// Package idgen 生成服务器范围内单调递增的 64 位 ID（类似 snowflake），
+// 用于连接 ID、查询 ID 和变更事件，使日志、进程列表、协议跟踪和变更数据捕获中的事件可以互相关联。
+//
+// ID 的布局（从高位到低位）：1 位符号（始终为 0）、51 位微秒时间戳（自 2025-01-01 UTC 起，约 71 年）、
+// 6 位节点号、6 位序号。时间戳由启动时的墙上时间加上单调时钟的流逝时间得到，
+// 系统时间被调整（NTP 跳变、手动修改）时不会回退或跳跃；同一微秒内的序号用尽时借用下一微秒，
+// 因此同一生成器的 ID 严格递增，ID 中的时间戳也不会回退
+package idgen
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	nodeBits     = 6
+	sequenceBits = 6
+
+	// MaxNode 节点号的最大值
+	MaxNode     = 1<<nodeBits - 1
+	maxSequence = 1<<sequenceBits - 1
+	timeShift   = nodeBits + sequenceBits
+)
+
+// epoch 时间戳的起点
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator ID 生成器，可以并发使用
+type Generator struct {
+	node  int64
+	start time.Time // 带单调时钟读数，time.Since(start) 不受系统时间调整影响
+	base  int64     // start 对应的微秒时间戳
+
+	mu       sync.Mutex
+	last     int64 // 上一个 ID 的微秒时间戳
+	sequence int64
+}
+
+// New 创建节点号为 node 的生成器，多个服务器实例的 ID 需要互不相同时为每个实例配置不同的节点号
+func New(node int) (*Generator, error) {
+	if node < 0 || node > MaxNode {
+		return nil, fmt.Errorf("node id %d out of range [0, %d]", node, MaxNode)
+	}
+	start := time.Now()
+	return &Generator{node: int64(node), start: start, base: start.Sub(epoch).Microseconds()}, nil
+}
+
+// Next 返回下一个 ID
+func (g *Generator) Next() int64 {
+	now := g.base + time.Since(g.start).Microseconds()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now > g.last {
+		g.last = now
+		g.sequence = 0
+	} else if g.sequence < maxSequence {
+		g.sequence++
+	} else {
+		// 同一微秒内序号用尽，借用下一微秒
+		g.last++
+		g.sequence = 0
+	}
+	return g.last<<timeShift | g.node<<sequenceBits | g.sequence
+}
+
+// Node 生成器的节点号
+func (g *Generator) Node() int {
+	return int(g.node)
+}
+
+// Time 返回 ID 中的时间戳（微秒精度）
+func Time(id int64) time.Time {
+	return epoch.Add(time.Duration(id>>timeShift) * time.Microsecond)
+}
+
+// Node 返回 ID 中的节点号
+func Node(id int64) int {
+	return int(id >> sequenceBits & MaxNode)
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultGen, _ = New(0)
+)
+
+// SetNode 用节点号 node 重建全局生成器，服务器启动时按配置调用一次
+func SetNode(node int) error {
+	gen, err := New(node)
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	// 新生成器的 ID 不能小于旧生成器已经发出的 ID
+	defaultGen.mu.Lock()
+	gen.last = defaultGen.last
+	gen.sequence = maxSequence
+	defaultGen.mu.Unlock()
+	defaultGen = gen
+	return nil
+}
+
+// Next 从全局生成器取下一个 ID
+func Next() int64 {
+	defaultMu.RLock()
+	gen := defaultGen
+	defaultMu.RUnlock()
+	return gen.Next()
+}
@@ -0,0 +1,70 @@
+package idgen
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Monotonic(t *testing.T) {
+	gen, err := New(5)
+	require.NoError(t, err)
+
+	// 远超每微秒的序号数，借用后续微秒时仍然严格递增
+	last := gen.Next()
+	for i := 0; i < 10000; i++ {
+		id := gen.Next()
+		require.Greater(t, id, last)
+		assert.Equal(t, 5, Node(id))
+		last = id
+	}
+	assert.WithinDuration(t, time.Now(), Time(last), time.Second)
+}
+
+func TestGenerator_Concurrent(t *testing.T) {
+	gen, err := New(1)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]int64, 1000)
+			for i := range ids {
+				ids[i] = gen.Next()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				assert.False(t, seen[id], "duplicate id %d", id)
+				seen[id] = true
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 8000)
+}
+
+func TestNew_InvalidNode(t *testing.T) {
+	_, err := New(-1)
+	assert.Error(t, err)
+	_, err = New(MaxNode + 1)
+	assert.Error(t, err)
+}
+
+func TestSetNode(t *testing.T) {
+	before := Next()
+	require.NoError(t, SetNode(3))
+	defer SetNode(0)
+
+	id := Next()
+	assert.Greater(t, id, before)
+	assert.Equal(t, 3, Node(id))
+	assert.Error(t, SetNode(MaxNode+1))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/application"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
 )
 
@@ -173,71 +174,8 @@ func (t *KeyColumnUsageTable) Query(ctx context.Context, filters []domain.Filter
 	}, nil
 }
 
-// applyFilters applies filters to result rows
+// applyFilters applies filters to result rows (using utils package, which
+// also handles AND/OR filter trees)
 func (t *KeyColumnUsageTable) applyFilters(rows []domain.Row, filters []domain.Filter) ([]domain.Row, error) {
-	for _, filter := range filters {
-		var filteredRows []domain.Row
-
-		for _, row := range rows {
-			matches, err := t.matchesFilter(row, filter)
-			if err != nil {
-				return nil, err
-			}
-			if matches {
-				filteredRows = append(filteredRows, row)
-			}
-		}
-
-		rows = filteredRows
-	}
-
-	return rows, nil
-}
-
-// matchesFilter checks if a row matches a filter
-func (t *KeyColumnUsageTable) matchesFilter(row domain.Row, filter domain.Filter) (bool, error) {
-	value, exists := row[filter.Field]
-	if !exists {
-		return false, nil
-	}
-
-	// Convert value to string for comparison
-	var strValue string
-	if value == nil {
-		strValue = ""
-	} else {
-		strValue = fmt.Sprintf("%v", value)
-	}
-
-	// Apply operator
-	switch filter.Operator {
-	case "=":
-		return strValue == fmt.Sprintf("%v", filter.Value), nil
-	case "!=":
-		return strValue != fmt.Sprintf("%v", filter.Value), nil
-	case "like":
-		return t.matchesLike(strValue, fmt.Sprintf("%v", filter.Value)), nil
-	default:
-		return false, fmt.Errorf("unsupported filter operator: %s", filter.Operator)
-	}
-}
-
-// matchesLike implements simple LIKE pattern matching
-func (t *KeyColumnUsageTable) matchesLike(value, pattern string) bool {
-	// Simple implementation - can be enhanced for full LIKE support
-	if pattern == "%" {
-		return true
-	}
-	if pattern == value {
-		return true
-	}
-	if len(pattern) > 0 && pattern[0] == '%' && len(pattern) > 1 {
-		suffix := pattern[1:]
-		return len(value) >= len(suffix) && value[len(value)-len(suffix):] == suffix
-	}
-	if len(pattern) > 0 && pattern[len(pattern)-1] == '%' && len(pattern) > 1 {
-		prefix := pattern[:len(pattern)-1]
-		return len(value) >= len(prefix) && value[:len(prefix)] == prefix
-	}
-	return false
+	return utils.ApplyFilters(rows, filters)
 }
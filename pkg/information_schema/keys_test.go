@@ -3,6 +3,7 @@ package information_schema
 import (
 	"testing"
 
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,3 +31,22 @@ func TestKeyColumnUsageTableGetSchema(t *testing.T) {
 	assert.Contains(t, columnNames, "table_name")
 	assert.Contains(t, columnNames, "column_name")
 }
+
+func TestKeyColumnUsageTableApplyFilters_And(t *testing.T) {
+	table := &KeyColumnUsageTable{}
+	rows := []domain.Row{
+		{"table_schema": "default", "table_name": "t", "column_name": "id"},
+		{"table_schema": "default", "table_name": "u", "column_name": "id"},
+		{"table_schema": "other", "table_name": "t", "column_name": "id"},
+	}
+
+	filtered, err := table.applyFilters(rows, []domain.Filter{{
+		LogicOp: "AND",
+		SubFilters: []domain.Filter{
+			{Field: "table_schema", Operator: "=", Value: "default"},
+			{Field: "table_name", Operator: "=", Value: "t"},
+		},
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+}
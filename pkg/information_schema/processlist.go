@@ -0,0 +1,181 @@
+package information_schema
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// Connection attributes that MySQL clients send in the handshake and that
+// PROCESSLIST shows in their own columns
+const (
+	connAttrProgramName   = "program_name"
+	connAttrClientVersion = "_client_version"
+	connAttrOSUser        = "_os_user"
+)
+
+// ProcessListTable represents information_schema.PROCESSLIST
+// It lists the running statements like SHOW PROCESSLIST, plus the connection
+// attributes sent by the client. PROGRAM_NAME, CLIENT_VERSION and OS_USER are
+// NULL when the client did not send them; CONNECTION_ATTRS holds all
+// attributes as a JSON object. QUERY_ID is the ID of the running statement
+// and TRACE_ID its trace ID, which defaults to the connection ID; both come
+// from the server-wide ID generator and appear in logs and OK packets too.
+// LABELS holds the query labels of the statement (the query_labels session
+// variable merged with the leading /* key=value */ comment) as a JSON object.
+type ProcessListTable struct{}
+
+// NewProcessListTable creates a new ProcessListTable backed by the registered process list provider
+func NewProcessListTable() virtual.VirtualTable {
+	return &ProcessListTable{}
+}
+
+// GetName returns table name
+func (t *ProcessListTable) GetName() string {
+	return "PROCESSLIST"
+}
+
+// GetSchema returns table schema
+func (t *ProcessListTable) GetSchema() []domain.ColumnInfo {
+	return []domain.ColumnInfo{
+		{Name: "ID", Type: "bigint unsigned", Nullable: false},
+		{Name: "USER", Type: "varchar(32)", Nullable: false},
+		{Name: "HOST", Type: "varchar(261)", Nullable: false},
+		{Name: "DB", Type: "varchar(64)", Nullable: true},
+		{Name: "COMMAND", Type: "varchar(16)", Nullable: false},
+		{Name: "TIME", Type: "int", Nullable: false},
+		{Name: "STATE", Type: "varchar(64)", Nullable: true},
+		{Name: "INFO", Type: "text", Nullable: true},
+		{Name: "PROGRAM_NAME", Type: "varchar(1024)", Nullable: true},
+		{Name: "CLIENT_VERSION", Type: "varchar(1024)", Nullable: true},
+		{Name: "OS_USER", Type: "varchar(1024)", Nullable: true},
+		{Name: "CONNECTION_ATTRS", Type: "text", Nullable: true},
+		{Name: "QUERY_ID", Type: "bigint", Nullable: true},
+		{Name: "TRACE_ID", Type: "varchar(255)", Nullable: true},
+		{Name: "LABELS", Type: "text", Nullable: true},
+	}
+}
+
+// Query executes a query against PROCESSLIST table
+func (t *ProcessListTable) Query(ctx context.Context, filters []domain.Filter, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	rows := t.getRows()
+
+	var err error
+	if len(filters) > 0 {
+		rows, err = utils.ApplyFilters(rows, filters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options != nil && options.Limit > 0 {
+		start := options.Offset
+		if start < 0 {
+			start = 0
+		}
+		end := start + int(options.Limit)
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if start >= len(rows) {
+			rows = []domain.Row{}
+		} else {
+			rows = rows[start:end]
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: t.GetSchema(),
+		Rows:    rows,
+		Total:   int64(len(rows)),
+	}, nil
+}
+
+// getRows converts the process list snapshot to rows
+func (t *ProcessListTable) getRows() []domain.Row {
+	provider := GetProcessListProvider()
+	if provider == nil {
+		return []domain.Row{}
+	}
+	processList := provider()
+	rows := make([]domain.Row, 0, len(processList))
+	for _, item := range processList {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		threadID, _ := itemMap["ThreadID"].(uint32)
+		sql, _ := itemMap["SQL"].(string)
+		duration, _ := itemMap["Duration"].(time.Duration)
+		status, _ := itemMap["Status"].(string)
+		user, _ := itemMap["User"].(string)
+		host, _ := itemMap["Host"].(string)
+		db, _ := itemMap["DB"].(string)
+		attrs, _ := itemMap["ConnAttrs"].(map[string]string)
+		labels, _ := itemMap["Labels"].(map[string]string)
+		queryID, _ := itemMap["QueryID"].(string)
+		traceID, _ := itemMap["TraceID"].(string)
+
+		state := "executing"
+		if status == "canceled" {
+			state = "killed"
+		} else if status == "timeout" {
+			state = "timeout"
+		}
+
+		var dbValue, queryIDValue, traceIDValue interface{}
+		if db != "" {
+			dbValue = db
+		}
+		if id, err := strconv.ParseInt(queryID, 10, 64); err == nil {
+			queryIDValue = id
+		}
+		if traceID != "" {
+			traceIDValue = traceID
+		}
+
+		rows = append(rows, domain.Row{
+			"ID":               uint64(threadID),
+			"USER":             user,
+			"HOST":             host,
+			"DB":               dbValue,
+			"COMMAND":          "Query",
+			"TIME":             int64(duration.Seconds()),
+			"STATE":            state,
+			"INFO":             sql,
+			"PROGRAM_NAME":     connAttr(attrs, connAttrProgramName),
+			"CLIENT_VERSION":   connAttr(attrs, connAttrClientVersion),
+			"OS_USER":          connAttr(attrs, connAttrOSUser),
+			"CONNECTION_ATTRS": jsonObject(attrs),
+			"QUERY_ID":         queryIDValue,
+			"TRACE_ID":         traceIDValue,
+			"LABELS":           jsonObject(labels),
+		})
+	}
+	return rows
+}
+
+// jsonObject encodes a string map as a JSON object, or returns nil for an empty map
+func jsonObject(m map[string]string) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+// connAttr returns a connection attribute, or nil when the client did not send it
+func connAttr(attrs map[string]string, name string) interface{} {
+	if value, ok := attrs[name]; ok {
+		return value
+	}
+	return nil
+}
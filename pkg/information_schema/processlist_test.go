@@ -0,0 +1,82 @@
+package information_schema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessListTable(t *testing.T) {
+	table := NewProcessListTable()
+	assert.Equal(t, "PROCESSLIST", table.GetName())
+
+	RegisterProcessListProvider(nil)
+	result, err := table.Query(context.Background(), nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+
+	RegisterProcessListProvider(func() []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"ThreadID": uint32(7),
+				"QueryID":  "1234567890123",
+				"TraceID":  "req-42",
+				"SQL":      "SELECT SLEEP(10)",
+				"Duration": 3 * time.Second,
+				"Status":   "running",
+				"User":     "app",
+				"Host":     "10.0.0.5:51234",
+				"DB":       "shop",
+				"ConnAttrs": map[string]string{
+					"program_name":    "billing-worker",
+					"_client_version": "8.0.33",
+					"_os_user":        "deploy",
+					"_pid":            "4242",
+				},
+				"Labels": map[string]string{"app": "checkout"},
+			},
+			map[string]interface{}{
+				"ThreadID": uint32(8),
+				"SQL":      "SELECT 1",
+				"Status":   "canceled",
+			},
+		}
+	})
+	t.Cleanup(func() { RegisterProcessListProvider(nil) })
+
+	result, err = table.Query(context.Background(), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+
+	row := result.Rows[0]
+	assert.Equal(t, uint64(7), row["ID"])
+	assert.Equal(t, int64(3), row["TIME"])
+	assert.Equal(t, "shop", row["DB"])
+	assert.Equal(t, "billing-worker", row["PROGRAM_NAME"])
+	assert.Equal(t, "8.0.33", row["CLIENT_VERSION"])
+	assert.Equal(t, "deploy", row["OS_USER"])
+	var attrs map[string]string
+	require.NoError(t, json.Unmarshal([]byte(row["CONNECTION_ATTRS"].(string)), &attrs))
+	assert.Equal(t, "4242", attrs["_pid"])
+	assert.Equal(t, int64(1234567890123), row["QUERY_ID"])
+	assert.Equal(t, "req-42", row["TRACE_ID"])
+	assert.Equal(t, `{"app":"checkout"}`, row["LABELS"])
+
+	// 客户端未发送连接属性时为 NULL
+	row = result.Rows[1]
+	assert.Equal(t, "killed", row["STATE"])
+	assert.Nil(t, row["DB"])
+	assert.Nil(t, row["PROGRAM_NAME"])
+	assert.Nil(t, row["CONNECTION_ATTRS"])
+	assert.Nil(t, row["QUERY_ID"])
+	assert.Nil(t, row["LABELS"])
+
+	result, err = table.Query(context.Background(), []domain.Filter{{Field: "PROGRAM_NAME", Operator: "=", Value: "billing-worker"}}, nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+}
@@ -72,6 +72,9 @@ func (p *Provider) initializeTables() {
 	p.tables["system_variables"] = NewSystemVariablesTable()
 	p.tables["plugins"] = NewPluginsTable()
 	p.tables["engines"] = NewEnginesTable(p.dsManager)
+	p.tables["statements_summary"] = NewStatementsSummaryTable()
+	p.tables["quota_usage"] = NewQuotaUsageTable(p.dsManager, p.vdbRegistry)
+	p.tables["processlist"] = NewProcessListTable()
 
 	// Register MySQL privilege tables (if ACL manager is available)
 	if p.aclManager != nil {
@@ -44,7 +44,7 @@ func TestListVirtualTables(t *testing.T) {
 
 	tables := provider.ListVirtualTables()
 
-	assert.Len(t, tables, 10) // Should have 10 tables
+	assert.Len(t, tables, 13) // Should have 13 tables
 	assert.Contains(t, tables, "schemata")
 	assert.Contains(t, tables, "tables")
 	assert.Contains(t, tables, "columns")
@@ -55,6 +55,9 @@ func TestListVirtualTables(t *testing.T) {
 	assert.Contains(t, tables, "system_variables")
 	assert.Contains(t, tables, "plugins")
 	assert.Contains(t, tables, "engines")
+	assert.Contains(t, tables, "statements_summary")
+	assert.Contains(t, tables, "quota_usage")
+	assert.Contains(t, tables, "processlist")
 }
 
 func TestHasTable(t *testing.T) {
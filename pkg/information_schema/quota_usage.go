@@ -0,0 +1,117 @@
+package information_schema
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/quota"
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// QuotaUsageTable represents information_schema.QUOTA_USAGE
+// It lists every configured quota with the current usage of its table or database.
+// USER is empty for quotas that apply to all users; TABLE_NAME is NULL for database quotas;
+// a MAX_ROWS or MAX_BYTES of 0 means unlimited.
+type QuotaUsageTable struct {
+	dsManager   *application.DataSourceManager
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+}
+
+// NewQuotaUsageTable creates a new QuotaUsageTable backed by the registered quota manager
+func NewQuotaUsageTable(dsManager *application.DataSourceManager, registry *virtual.VirtualDatabaseRegistry) virtual.VirtualTable {
+	return &QuotaUsageTable{dsManager: dsManager, vdbRegistry: registry}
+}
+
+// GetName returns table name
+func (t *QuotaUsageTable) GetName() string {
+	return "QUOTA_USAGE"
+}
+
+// GetSchema returns table schema
+func (t *QuotaUsageTable) GetSchema() []domain.ColumnInfo {
+	return []domain.ColumnInfo{
+		{Name: "USER", Type: "varchar(32)", Nullable: false},
+		{Name: "TABLE_SCHEMA", Type: "varchar(64)", Nullable: false},
+		{Name: "TABLE_NAME", Type: "varchar(64)", Nullable: true},
+		{Name: "MAX_ROWS", Type: "bigint", Nullable: false},
+		{Name: "USED_ROWS", Type: "bigint", Nullable: false},
+		{Name: "MAX_BYTES", Type: "bigint", Nullable: false},
+		{Name: "USED_BYTES", Type: "bigint", Nullable: false},
+	}
+}
+
+// Query executes a query against QUOTA_USAGE table
+func (t *QuotaUsageTable) Query(ctx context.Context, filters []domain.Filter, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	rows, err := t.getRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filters) > 0 {
+		rows, err = utils.ApplyFilters(rows, filters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options != nil && options.Limit > 0 {
+		start := options.Offset
+		if start < 0 {
+			start = 0
+		}
+		end := start + int(options.Limit)
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if start >= len(rows) {
+			rows = []domain.Row{}
+		} else {
+			rows = rows[start:end]
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: t.GetSchema(),
+		Rows:    rows,
+		Total:   int64(len(rows)),
+	}, nil
+}
+
+// getRows measures the usage of every quota scope once
+func (t *QuotaUsageTable) getRows(ctx context.Context) ([]domain.Row, error) {
+	manager := GetQuotaManager()
+	if manager == nil {
+		return []domain.Row{}, nil
+	}
+	catalog := quota.Catalog{DataSources: t.dsManager, Virtual: t.vdbRegistry}
+	usages := make(map[string]quota.Usage)
+
+	quotas := manager.List()
+	rows := make([]domain.Row, 0, len(quotas))
+	for _, q := range quotas {
+		usage, ok := usages[q.Scope()]
+		if !ok {
+			var err error
+			if usage, err = catalog.Measure(ctx, q.Database, q.Table); err != nil {
+				return nil, err
+			}
+			usages[q.Scope()] = usage
+		}
+		var table interface{}
+		if q.Table != "" {
+			table = q.Table
+		}
+		rows = append(rows, domain.Row{
+			"USER":         q.User,
+			"TABLE_SCHEMA": q.Database,
+			"TABLE_NAME":   table,
+			"MAX_ROWS":     q.MaxRows,
+			"USED_ROWS":    usage.Rows,
+			"MAX_BYTES":    q.MaxBytes,
+			"USED_BYTES":   usage.Bytes,
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,56 @@
+package information_schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/quota"
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaUsageTable(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "app", Writable: true})
+	require.NoError(t, ds.Connect(ctx))
+	require.NoError(t, ds.CreateTable(ctx, &domain.TableInfo{Name: "orders", Columns: []domain.ColumnInfo{
+		{Name: "id", Type: "INT", Primary: true},
+	}}))
+	_, err := ds.Insert(ctx, "orders", []domain.Row{{"id": 1}, {"id": 22}}, &domain.InsertOptions{})
+	require.NoError(t, err)
+	manager := application.NewDataSourceManager()
+	require.NoError(t, manager.Register("app", ds))
+
+	table := NewQuotaUsageTable(manager, nil)
+	assert.Equal(t, "QUOTA_USAGE", table.GetName())
+
+	RegisterQuotaManager(nil)
+	result, err := table.Query(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+
+	RegisterQuotaManager(quota.NewManager([]quota.Quota{
+		{Database: "app", Table: "orders", MaxRows: 10},
+		{Database: "app", User: "tenant_a", MaxBytes: 100},
+	}))
+	defer RegisterQuotaManager(nil)
+
+	result, err = table.Query(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+	assert.Equal(t, "tenant_a", result.Rows[0]["USER"])
+	assert.Nil(t, result.Rows[0]["TABLE_NAME"])
+	assert.Equal(t, int64(100), result.Rows[0]["MAX_BYTES"])
+	assert.Equal(t, int64(3), result.Rows[0]["USED_BYTES"])
+	assert.Equal(t, "", result.Rows[1]["USER"])
+	assert.Equal(t, "orders", result.Rows[1]["TABLE_NAME"])
+	assert.Equal(t, int64(10), result.Rows[1]["MAX_ROWS"])
+	assert.Equal(t, int64(2), result.Rows[1]["USED_ROWS"])
+
+	result, err = table.Query(ctx, []domain.Filter{{Field: "TABLE_NAME", Operator: "=", Value: "orders"}}, nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+}
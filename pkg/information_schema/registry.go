@@ -3,6 +3,7 @@ package information_schema
 import (
 	"sync"
 
+	"github.com/kasuganosora/sqlexec/pkg/quota"
 	"github.com/kasuganosora/sqlexec/server/acl"
 )
 
@@ -26,6 +27,50 @@ func GetACLManager() *acl.ACLManager {
 	return globalACLManager
 }
 
+// 全局配额管理器（用于 QUOTA_USAGE）
+var (
+	globalQuotaManager *quota.Manager
+	quotaManagerMutex  sync.RWMutex
+)
+
+// RegisterQuotaManager 注册全局配额管理器
+func RegisterQuotaManager(m *quota.Manager) {
+	quotaManagerMutex.Lock()
+	defer quotaManagerMutex.Unlock()
+	globalQuotaManager = m
+}
+
+// GetQuotaManager 获取全局配额管理器
+func GetQuotaManager() *quota.Manager {
+	quotaManagerMutex.RLock()
+	defer quotaManagerMutex.RUnlock()
+	return globalQuotaManager
+}
+
+// ProcessListProvider 进程列表提供者函数类型（用于 PROCESSLIST，避免依赖 session 包）
+// 每一项为 map[string]interface{}，字段与 session.GetProcessListForOptimizer 返回的一致
+type ProcessListProvider func() []interface{}
+
+// 全局进程列表提供者（用于 PROCESSLIST）
+var (
+	globalProcessListProvider ProcessListProvider
+	processListProviderMutex  sync.RWMutex
+)
+
+// RegisterProcessListProvider 注册全局进程列表提供者
+func RegisterProcessListProvider(provider ProcessListProvider) {
+	processListProviderMutex.Lock()
+	defer processListProviderMutex.Unlock()
+	globalProcessListProvider = provider
+}
+
+// GetProcessListProvider 获取全局进程列表提供者
+func GetProcessListProvider() ProcessListProvider {
+	processListProviderMutex.RLock()
+	defer processListProviderMutex.RUnlock()
+	return globalProcessListProvider
+}
+
 // GetACLManagerAdapter 获取适配后的ACL Manager（实现ACLManager接口）
 func GetACLManagerAdapter() ACLManager {
 	aclManagerMutex.RLock()
@@ -0,0 +1,131 @@
+package information_schema
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// StatementsSummaryTable represents information_schema.STATEMENTS_SUMMARY
+// It aggregates executions per statement digest (literals replaced by ?).
+// Latency columns are in nanoseconds, rows are ordered by SUM_LATENCY descending.
+// The PLAN columns hold the last plan captured by slow log sampling.
+type StatementsSummaryTable struct {
+	summary *monitor.StatementSummary
+}
+
+// NewStatementsSummaryTable creates a new StatementsSummaryTable backed by the global statement summary
+func NewStatementsSummaryTable() virtual.VirtualTable {
+	return &StatementsSummaryTable{summary: monitor.GetStatementSummary()}
+}
+
+// GetName returns table name
+func (t *StatementsSummaryTable) GetName() string {
+	return "STATEMENTS_SUMMARY"
+}
+
+// GetSchema returns table schema
+func (t *StatementsSummaryTable) GetSchema() []domain.ColumnInfo {
+	return []domain.ColumnInfo{
+		{Name: "DIGEST", Type: "varchar(64)", Nullable: false},
+		{Name: "DIGEST_TEXT", Type: "text", Nullable: false},
+		{Name: "QUERY_SAMPLE_TEXT", Type: "text", Nullable: true},
+		{Name: "EXEC_COUNT", Type: "bigint", Nullable: false},
+		{Name: "SUM_ERRORS", Type: "bigint", Nullable: false},
+		{Name: "SUM_LATENCY", Type: "bigint", Nullable: false},
+		{Name: "MIN_LATENCY", Type: "bigint", Nullable: false},
+		{Name: "MAX_LATENCY", Type: "bigint", Nullable: false},
+		{Name: "AVG_LATENCY", Type: "bigint", Nullable: false},
+		{Name: "SUM_ROWS", Type: "bigint", Nullable: false},
+		{Name: "MAX_ROWS", Type: "bigint", Nullable: false},
+		{Name: "AVG_ROWS", Type: "bigint", Nullable: false},
+		{Name: "LAST_ERROR", Type: "text", Nullable: true},
+		{Name: "FIRST_SEEN", Type: "datetime", Nullable: false},
+		{Name: "LAST_SEEN", Type: "datetime", Nullable: false},
+		{Name: "PLAN_DIGEST", Type: "varchar(64)", Nullable: true},
+		{Name: "PLAN", Type: "text", Nullable: true},
+		{Name: "PLAN_CHANGES", Type: "bigint", Nullable: false},
+		{Name: "PLAN_CAPTURED_AT", Type: "datetime", Nullable: true},
+	}
+}
+
+// Query executes a query against STATEMENTS_SUMMARY table
+func (t *StatementsSummaryTable) Query(ctx context.Context, filters []domain.Filter, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	rows := t.getRows()
+
+	var err error
+	if len(filters) > 0 {
+		rows, err = utils.ApplyFilters(rows, filters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options != nil && options.Limit > 0 {
+		start := options.Offset
+		if start < 0 {
+			start = 0
+		}
+		end := start + int(options.Limit)
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if start >= len(rows) {
+			rows = []domain.Row{}
+		} else {
+			rows = rows[start:end]
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: t.GetSchema(),
+		Rows:    rows,
+		Total:   int64(len(rows)),
+	}, nil
+}
+
+// getRows converts statement summary snapshot to rows
+func (t *StatementsSummaryTable) getRows() []domain.Row {
+	snapshot := t.summary.Snapshot()
+	rows := make([]domain.Row, 0, len(snapshot))
+	for i := range snapshot {
+		s := &snapshot[i]
+		var avgRows int64
+		if s.ExecCount > 0 {
+			avgRows = s.SumRows / s.ExecCount
+		}
+		var lastError interface{}
+		if s.LastError != "" {
+			lastError = s.LastError
+		}
+		var planDigest, plan, planCapturedAt interface{}
+		if s.PlanDigest != "" {
+			planDigest, plan, planCapturedAt = s.PlanDigest, s.Plan, s.PlanCapturedAt
+		}
+		rows = append(rows, domain.Row{
+			"DIGEST":            s.Digest,
+			"DIGEST_TEXT":       s.DigestText,
+			"QUERY_SAMPLE_TEXT": s.SampleSQL,
+			"EXEC_COUNT":        s.ExecCount,
+			"SUM_ERRORS":        s.ErrorCount,
+			"SUM_LATENCY":       s.SumLatency.Nanoseconds(),
+			"MIN_LATENCY":       s.MinLatency.Nanoseconds(),
+			"MAX_LATENCY":       s.MaxLatency.Nanoseconds(),
+			"AVG_LATENCY":       s.AvgLatency().Nanoseconds(),
+			"SUM_ROWS":          s.SumRows,
+			"MAX_ROWS":          s.MaxRows,
+			"AVG_ROWS":          avgRows,
+			"LAST_ERROR":        lastError,
+			"FIRST_SEEN":        s.FirstSeen,
+			"LAST_SEEN":         s.LastSeen,
+			"PLAN_DIGEST":       planDigest,
+			"PLAN":              plan,
+			"PLAN_CHANGES":      s.PlanChanges,
+			"PLAN_CAPTURED_AT":  planCapturedAt,
+		})
+	}
+	return rows
+}
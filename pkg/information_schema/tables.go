@@ -148,11 +148,15 @@ func (t *TablesTable) Query(ctx context.Context, filters []domain.Filter, option
 				continue
 			}
 
+			tableType := "BASE TABLE"
+			if _, ok := tableInfo.Atts[domain.SequenceMetaKey]; ok {
+				tableType = "SEQUENCE"
+			}
 			row := domain.Row{
 				"table_catalog":    "def",
 				"table_schema":     dsName,
 				"table_name":       tableName,
-				"table_type":       "BASE TABLE",
+				"table_type":       tableType,
 				"engine":           "MEMORY",
 				"version":          int64(10),
 				"row_format":       "Fixed",
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config 日志配置
+type Config struct {
+	// Level 默认级别：debug、info、warn、error，默认 info
+	Level string `json:"level"`
+	// Format 输出格式：text（key=value）或 json，默认 text
+	Format string `json:"format"`
+	// Modules 按模块覆盖级别，如 {"parser": "debug", "resource": "warn"}，模块名按点分层级匹配
+	Modules map[string]string `json:"modules,omitempty"`
+	// Sinks 输出目标：stdout、stderr、file:<path>、syslog[:<tag>]，为空时输出到 stdout
+	Sinks []string `json:"sinks,omitempty"`
+	// Sampling 重复警告和错误的限流采样，Initial 为 0 时不采样
+	Sampling SamplingConfig `json:"sampling"`
+}
+
+// SamplingConfig 限流采样配置：每个 Interval 内同一条日志先输出 Initial 条，之后每 Thereafter 条输出一条
+type SamplingConfig struct {
+	Interval   string `json:"interval,omitempty"` // 窗口长度（如 "1s"），默认 1s
+	Initial    int    `json:"initial,omitempty"`
+	Thereafter int    `json:"thereafter,omitempty"`
+}
+
+// Validate 检查配置，不打开任何 sink
+func (c Config) Validate() error {
+	if _, err := ParseLevel(c.Level); err != nil {
+		return err
+	}
+	if _, err := ParseFormat(c.Format); err != nil {
+		return err
+	}
+	for module, level := range c.Modules {
+		if strings.TrimSpace(module) == "" {
+			return fmt.Errorf("logging: empty module name")
+		}
+		if _, err := ParseLevel(level); err != nil {
+			return fmt.Errorf("logging: module %s: %w", module, err)
+		}
+	}
+	for _, spec := range c.Sinks {
+		if err := validateSink(spec); err != nil {
+			return err
+		}
+	}
+	if c.Sampling.Initial < 0 || c.Sampling.Thereafter < 0 {
+		return fmt.Errorf("logging: sampling counts must not be negative")
+	}
+	_, err := c.Sampling.interval()
+	return err
+}
+
+func (c SamplingConfig) interval() (time.Duration, error) {
+	if c.Interval == "" {
+		return time.Second, nil
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("logging: invalid sampling interval %q", c.Interval)
+	}
+	return d, nil
+}
+
+// build 按配置创建 core，打开 sink 失败时关闭已经打开的 sink
+func (c Config) build() (*core, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	level, _ := ParseLevel(c.Level)
+	format, _ := ParseFormat(c.Format)
+	lc := &core{level: level, format: format, now: time.Now}
+
+	if len(c.Modules) > 0 {
+		lc.modules = make(map[string]Level, len(c.Modules))
+		for module, name := range c.Modules {
+			lc.modules[strings.ToLower(strings.TrimSpace(module))], _ = ParseLevel(name)
+		}
+	}
+	if c.Sampling.Initial > 0 {
+		interval, _ := c.Sampling.interval()
+		lc.sampler = newSampler(interval, c.Sampling.Initial, c.Sampling.Thereafter)
+	}
+
+	specs := c.Sinks
+	if len(specs) == 0 {
+		specs = []string{"stdout"}
+	}
+	for _, spec := range specs {
+		sink, err := ParseSink(spec)
+		if err != nil {
+			_ = lc.close()
+			return nil, err
+		}
+		lc.sinks = append(lc.sinks, sink)
+	}
+	return lc, nil
+}
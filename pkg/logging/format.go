@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format 日志行的编码格式
+type Format int
+
+const (
+	// FormatText logfmt 风格的 key=value 文本
+	FormatText Format = iota
+	// FormatJSON 每行一个 JSON 对象
+	FormatJSON
+)
+
+// ParseFormat 解析格式名，空字符串为 text
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text", "logfmt":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("logging: unknown format %q", s)
+	}
+}
+
+// timeLayout 微秒精度的 RFC 3339 时间
+const timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// badKey 键值对个数为奇数或键不是字符串时使用的键名
+const badKey = "!BADKEY"
+
+func (f Format) encode(e *Entry) []byte {
+	if f == FormatJSON {
+		return encodeJSON(e)
+	}
+	return encodeText(e)
+}
+
+func encodeText(e *Entry) []byte {
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(e.Time.Format(timeLayout))
+	b.WriteString(" level=")
+	b.WriteString(e.Level.String())
+	if e.Module != "" {
+		b.WriteString(" module=")
+		writeTextValue(&b, e.Module)
+	}
+	b.WriteString(" msg=")
+	writeTextValue(&b, e.Message)
+	forEachField(e.Fields, func(key string, value any) {
+		b.WriteByte(' ')
+		writeTextValue(&b, key)
+		b.WriteByte('=')
+		writeTextValue(&b, textValue(value))
+	})
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// writeTextValue 写出 logfmt 值，含空白、引号、等号或不可打印字符时加引号
+func writeTextValue(b *strings.Builder, s string) {
+	if needsQuote(s) {
+		b.WriteString(strconv.Quote(s))
+		return
+	}
+	b.WriteString(s)
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || r <= ' ' || r == '=' || r == '"' || r == '\\' || !strconv.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func textValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(timeLayout)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func encodeJSON(e *Entry) []byte {
+	var b strings.Builder
+	b.WriteString(`{"time":`)
+	writeJSONString(&b, e.Time.Format(timeLayout))
+	b.WriteString(`,"level":`)
+	writeJSONString(&b, e.Level.String())
+	if e.Module != "" {
+		b.WriteString(`,"module":`)
+		writeJSONString(&b, e.Module)
+	}
+	b.WriteString(`,"msg":`)
+	writeJSONString(&b, e.Message)
+	forEachField(e.Fields, func(key string, value any) {
+		b.WriteByte(',')
+		writeJSONString(&b, key)
+		b.WriteByte(':')
+		writeJSONValue(&b, value)
+	})
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func writeJSONString(b *strings.Builder, s string) {
+	data, _ := json.Marshal(s)
+	b.Write(data)
+}
+
+func writeJSONValue(b *strings.Builder, value any) {
+	switch v := value.(type) {
+	case error:
+		writeJSONString(b, v.Error())
+		return
+	case time.Duration:
+		writeJSONString(b, v.String())
+		return
+	case time.Time:
+		writeJSONString(b, v.Format(timeLayout))
+		return
+	case json.Marshaler:
+	case fmt.Stringer:
+		writeJSONString(b, v.String())
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		writeJSONString(b, fmt.Sprint(value))
+		return
+	}
+	b.Write(data)
+}
+
+// forEachField 按键值对遍历字段，缺少值或键不是字符串时用 badKey 记录该项
+func forEachField(fields []any, fn func(key string, value any)) {
+	for i := 0; i < len(fields); {
+		key, ok := fields[i].(string)
+		if !ok || i+1 >= len(fields) {
+			fn(badKey, fields[i])
+			i++
+			continue
+		}
+		fn(key, fields[i+1])
+		i += 2
+	}
+}
@@ -0,0 +1,272 @@
+// Package logging 结构化日志：每条日志由消息和键值对组成，按文本（logfmt）或 JSON 输出到一个或多个 sink，
+// 支持按模块设置级别，并对短时间内重复出现的警告和错误做限流采样，使生产环境的日志可以被机器解析。
+//
+// 各层通过 Module 取得带模块名的 Logger；未显式创建的 Logger 写入全局配置（Configure / SetDefault），
+// 因此包级变量形式的 Logger 在服务器启动时重新配置后也会生效。
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level 日志级别
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String 返回日志级别字符串
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel 解析日志级别名（不区分大小写），空字符串为 info
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("logging: unknown level %q", s)
+	}
+}
+
+// core 一组日志配置（级别、格式、sink、采样），由同一配置创建的 Logger 共享
+type core struct {
+	level   Level
+	modules map[string]Level // 小写模块名到级别
+	format  Format
+	sampler *sampler // nil 表示不采样
+	now     func() time.Time
+
+	mu    sync.Mutex // 保证多个 sink 中的日志行顺序一致
+	sinks []Sink
+}
+
+// levelFor 返回模块的生效级别，模块名按点分层级匹配（resource.parquet 未配置时使用 resource 的级别）
+func (c *core) levelFor(module string) Level {
+	if len(c.modules) == 0 || module == "" {
+		return c.level
+	}
+	name := strings.ToLower(module)
+	for {
+		if level, ok := c.modules[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return c.level
+		}
+		name = name[:i]
+	}
+}
+
+func (c *core) write(e *Entry) {
+	line := c.format.encode(e)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, sink := range c.sinks {
+		// 写日志失败时没有更合适的地方报告，忽略
+		_ = sink.Write(e.Level, line)
+	}
+}
+
+func (c *core) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for _, sink := range c.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Entry 一条日志
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Module  string
+	Message string
+	Fields  []any // 交替的键和值
+}
+
+// Logger 结构化日志记录器，可以并发使用
+type Logger struct {
+	core   *core // nil 表示使用全局配置
+	module string
+	fields []any
+}
+
+// New 按配置创建 Logger，不再使用时调用 Close 关闭文件和 syslog sink
+func New(cfg Config) (*Logger, error) {
+	c, err := cfg.build()
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{core: c}, nil
+}
+
+// NewWithSinks 创建输出到给定 sink 的 Logger，主要用于测试和嵌入式使用
+func NewWithSinks(level Level, format Format, sinks ...Sink) *Logger {
+	return &Logger{core: &core{level: level, format: format, now: time.Now, sinks: sinks}}
+}
+
+// Close 关闭 Logger 的 sink；全局 Logger 的 sink 由 Configure 管理，调用无效果
+func (l *Logger) Close() error {
+	if l.core == nil {
+		return nil
+	}
+	return l.core.close()
+}
+
+// Module 返回输出模块名为 name 的 Logger，继承已绑定的字段
+func (l *Logger) Module(name string) *Logger {
+	return &Logger{core: l.core, module: name, fields: l.fields}
+}
+
+// With 返回每条日志都附带给定键值对的 Logger
+func (l *Logger) With(kv ...any) *Logger {
+	fields := make([]any, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &Logger{core: l.core, module: l.module, fields: fields}
+}
+
+// Enabled 返回该级别的日志是否会输出，用于跳过代价较高的字段计算
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.current().levelFor(l.module)
+}
+
+// Debug 输出 DEBUG 级别日志，kv 为交替的键和值
+func (l *Logger) Debug(msg string, kv ...any) { l.log(LevelDebug, msg, msg, kv) }
+
+// Info 输出 INFO 级别日志
+func (l *Logger) Info(msg string, kv ...any) { l.log(LevelInfo, msg, msg, kv) }
+
+// Warn 输出 WARN 级别日志
+func (l *Logger) Warn(msg string, kv ...any) { l.log(LevelWarn, msg, msg, kv) }
+
+// Error 输出 ERROR 级别日志
+func (l *Logger) Error(msg string, kv ...any) { l.log(LevelError, msg, msg, kv) }
+
+// Debugf 以格式化消息输出 DEBUG 级别日志，供仍使用 printf 风格的调用方逐步迁移
+func (l *Logger) Debugf(format string, args ...any) { l.logf(LevelDebug, format, args) }
+
+// Infof 以格式化消息输出 INFO 级别日志
+func (l *Logger) Infof(format string, args ...any) { l.logf(LevelInfo, format, args) }
+
+// Warnf 以格式化消息输出 WARN 级别日志
+func (l *Logger) Warnf(format string, args ...any) { l.logf(LevelWarn, format, args) }
+
+// Errorf 以格式化消息输出 ERROR 级别日志
+func (l *Logger) Errorf(format string, args ...any) { l.logf(LevelError, format, args) }
+
+// Printf 与 Infof 相同，使 Logger 满足只需要 Printf 的日志接口
+func (l *Logger) Printf(format string, args ...any) { l.logf(LevelInfo, format, args) }
+
+func (l *Logger) logf(level Level, format string, args []any) {
+	c := l.current()
+	if level < c.levelFor(l.module) {
+		return
+	}
+	// 采样按格式串归并，参数不同的同一条消息视为重复
+	l.emit(c, level, format, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) log(level Level, key, msg string, kv []any) {
+	c := l.current()
+	if level < c.levelFor(l.module) {
+		return
+	}
+	l.emit(c, level, key, msg, kv)
+}
+
+func (l *Logger) emit(c *core, level Level, key, msg string, kv []any) {
+	now := c.now()
+	fields := l.fields
+	if c.sampler != nil && level >= LevelWarn {
+		ok, dropped := c.sampler.allow(l.module+"\x00"+key, now)
+		if !ok {
+			return
+		}
+		if dropped > 0 {
+			fields = append(fields[:len(fields):len(fields)], "sampled_dropped", dropped)
+		}
+	}
+	if len(kv) > 0 {
+		fields = append(fields[:len(fields):len(fields)], kv...)
+	}
+	c.write(&Entry{Time: now, Level: level, Module: l.module, Message: msg, Fields: fields})
+}
+
+func (l *Logger) current() *core {
+	if l.core != nil {
+		return l.core
+	}
+	return global.Load()
+}
+
+var global atomic.Pointer[core]
+
+func init() {
+	global.Store(&core{level: LevelInfo, format: FormatText, now: time.Now, sinks: []Sink{Stdout()}})
+}
+
+var std = &Logger{}
+
+// Default 返回使用全局配置的 Logger
+func Default() *Logger {
+	return std
+}
+
+// Module 返回使用全局配置、模块名为 name 的 Logger
+func Module(name string) *Logger {
+	return &Logger{module: name}
+}
+
+// SetDefault 让全局配置使用 l 的级别、格式和 sink，之前的全局 sink 会被关闭；
+// l 必须由 New 或 NewWithSinks 创建
+func SetDefault(l *Logger) {
+	if l == nil || l.core == nil {
+		return
+	}
+	if old := global.Swap(l.core); old != nil && old != l.core {
+		_ = old.close()
+	}
+}
+
+// Configure 按配置重建全局日志，配置无效时保留原配置并返回错误
+func Configure(cfg Config) error {
+	l, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	SetDefault(l)
+	return nil
+}
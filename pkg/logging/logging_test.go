@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedLogger(t *testing.T, cfg Config) (*Logger, *bytes.Buffer) {
+	t.Helper()
+	cfg.Sinks = []string{"stdout"}
+	l, err := New(cfg)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	l.core.sinks = []Sink{NewWriterSink(&buf)}
+	l.core.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC) }
+	return l, &buf
+}
+
+func TestLogger_TextFormat(t *testing.T) {
+	l, buf := fixedLogger(t, Config{})
+
+	l.Module("server").With("conn", 7).Warn("accept failed", "err", errors.New("broken pipe"), "addr", "")
+	assert.Equal(t, `time=2026-01-02T03:04:05.000006Z level=WARN module=server msg="accept failed" conn=7 err="broken pipe" addr=""`+"\n", buf.String())
+
+	buf.Reset()
+	l.Info("odd", "key")
+	assert.Equal(t, `time=2026-01-02T03:04:05.000006Z level=INFO msg=odd !BADKEY=key`+"\n", buf.String())
+}
+
+func TestLogger_JSONFormat(t *testing.T) {
+	l, buf := fixedLogger(t, Config{Format: "json"})
+
+	l.Module("resource.parquet").Error("flush failed", "table", "t1", "rows", 3, "took", 2*time.Millisecond, "err", errors.New("disk full"))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, map[string]any{
+		"time":   "2026-01-02T03:04:05.000006Z",
+		"level":  "ERROR",
+		"module": "resource.parquet",
+		"msg":    "flush failed",
+		"table":  "t1",
+		"rows":   float64(3),
+		"took":   "2ms",
+		"err":    "disk full",
+	}, got)
+}
+
+func TestLogger_ModuleLevels(t *testing.T) {
+	l, buf := fixedLogger(t, Config{Level: "warn", Modules: map[string]string{"parser": "debug", "Resource": "error"}})
+
+	l.Module("server").Info("hidden")
+	l.Module("parser").Debug("shown")
+	l.Module("resource.csv").Warn("hidden")
+	l.Module("resource.csv").Error("shown")
+	assert.Equal(t, 2, strings.Count(buf.String(), "msg=shown"))
+	assert.NotContains(t, buf.String(), "hidden")
+
+	assert.True(t, l.Module("parser.sub").Enabled(LevelDebug))
+	assert.False(t, l.Module("server").Enabled(LevelInfo))
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	l, buf := fixedLogger(t, Config{Sampling: SamplingConfig{Interval: "1s", Initial: 2, Thereafter: 3}})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	l.core.now = func() time.Time { return now }
+
+	log := l.Module("server")
+	for i := 0; i < 8; i++ {
+		log.Warnf("read packet from %d failed", i)
+		log.Info("not sampled")
+	}
+	// 前 2 条，之后第 5、8 条
+	out := buf.String()
+	assert.Equal(t, 4, strings.Count(out, "failed"))
+	assert.Equal(t, 8, strings.Count(out, "not sampled"))
+	assert.Contains(t, out, `msg="read packet from 4 failed" sampled_dropped=2`)
+
+	// 新窗口先报告上一窗口丢弃的条数
+	buf.Reset()
+	log.Warnf("read packet from %d failed", 8)
+	log.Warnf("read packet from %d failed", 9)
+	now = now.Add(time.Second)
+	log.Warnf("read packet from %d failed", 10)
+	assert.Equal(t, 1, strings.Count(buf.String(), "failed"))
+	assert.Contains(t, buf.String(), `msg="read packet from 10 failed" sampled_dropped=2`)
+}
+
+func TestLogger_GlobalModule(t *testing.T) {
+	var buf bytes.Buffer
+	log := Module("lookup") // 在重新配置前取得，之后的配置仍然生效
+
+	SetDefault(NewWithSinks(LevelDebug, FormatJSON, NewWriterSink(&buf)))
+	defer SetDefault(NewWithSinks(LevelInfo, FormatText, Stdout()))
+
+	log.Debugf("reloaded %d tables", 2)
+	assert.Contains(t, buf.String(), `"module":"lookup","msg":"reloaded 2 tables"`)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{Level: "WARNING", Format: "json", Sinks: []string{"stderr", "file:/tmp/x.log", "syslog:sqlexec"}}.Validate())
+	assert.Error(t, Config{Level: "verbose"}.Validate())
+	assert.Error(t, Config{Format: "xml"}.Validate())
+	assert.Error(t, Config{Modules: map[string]string{"parser": "loud"}}.Validate())
+	assert.Error(t, Config{Sinks: []string{"file:"}}.Validate())
+	assert.Error(t, Config{Sinks: []string{"kafka"}}.Validate())
+	assert.Error(t, Config{Sampling: SamplingConfig{Interval: "0s", Initial: 1}}.Validate())
+	assert.Error(t, Config{Sampling: SamplingConfig{Initial: -1}}.Validate())
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "server.log")
+	l, err := New(Config{Sinks: []string{"file:" + path}})
+	require.NoError(t, err)
+
+	l.Module("server").Info("started", "port", 3306)
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "module=server msg=started port=3306\n")
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"sync"
+	"time"
+)
+
+// maxSampleKeys 采样计数表的上限，超过时清理已过期的窗口
+const maxSampleKeys = 4096
+
+// sampler 对重复日志限流：每个窗口内同一条日志先输出 initial 条，之后每 thereafter 条输出一条
+// （thereafter 为 0 时丢弃其余）。被丢弃的条数记在该条日志下一次输出时的 sampled_dropped 字段中
+type sampler struct {
+	interval   time.Duration
+	initial    int
+	thereafter int
+
+	mu     sync.Mutex
+	counts map[string]*sampleCount
+}
+
+type sampleCount struct {
+	start   time.Time
+	n       int
+	dropped int
+}
+
+func newSampler(interval time.Duration, initial, thereafter int) *sampler {
+	return &sampler{
+		interval:   interval,
+		initial:    initial,
+		thereafter: thereafter,
+		counts:     make(map[string]*sampleCount),
+	}
+}
+
+// allow 返回 key 对应的日志是否输出，以及输出时此前被丢弃的条数
+func (s *sampler) allow(key string, now time.Time) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counts[key]
+	if !ok {
+		if len(s.counts) >= maxSampleKeys {
+			s.purge(now)
+		}
+		c = &sampleCount{start: now}
+		s.counts[key] = c
+	} else if now.Sub(c.start) >= s.interval {
+		// 新窗口，上一窗口丢弃的条数仍要报告
+		c.start = now
+		c.n = 0
+	}
+	c.n++
+
+	if c.n > s.initial && (s.thereafter <= 0 || (c.n-s.initial)%s.thereafter != 0) {
+		c.dropped++
+		return false, 0
+	}
+	dropped := c.dropped
+	c.dropped = 0
+	return true, dropped
+}
+
+// purge 删除窗口已过期且没有未报告丢弃数的计数
+func (s *sampler) purge(now time.Time) {
+	for key, c := range s.counts {
+		if now.Sub(c.start) >= s.interval && c.dropped == 0 {
+			delete(s.counts, key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sink 日志输出目标，Write 收到的是编码好的一整行（以换行结尾）
+type Sink interface {
+	Write(level Level, line []byte) error
+	Close() error
+}
+
+// writerSink 输出到 io.Writer，不负责关闭
+type writerSink struct {
+	w io.Writer
+}
+
+// NewWriterSink 创建输出到 w 的 sink，Close 不会关闭 w
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+// Stdout 输出到标准输出的 sink
+func Stdout() Sink {
+	return NewWriterSink(os.Stdout)
+}
+
+// Stderr 输出到标准错误的 sink
+func Stderr() Sink {
+	return NewWriterSink(os.Stderr)
+}
+
+func (s *writerSink) Write(_ Level, line []byte) error {
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	return nil
+}
+
+// fileSink 以追加方式写入的日志文件
+type fileSink struct {
+	f *os.File
+}
+
+// NewFileSink 打开（不存在时创建）日志文件 path，目录不存在时一并创建
+func NewFileSink(path string) (Sink, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("logging: create log directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("logging: open log file: %w", err)
+	}
+	return &fileSink{f: f}, nil
+}
+
+func (s *fileSink) Write(_ Level, line []byte) error {
+	_, err := s.f.Write(line)
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+// ParseSink 按描述创建 sink：stdout、stderr、file:<path> 或 syslog[:<tag>]
+func ParseSink(spec string) (Sink, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch strings.ToLower(kind) {
+	case "stdout":
+		return Stdout(), nil
+	case "stderr":
+		return Stderr(), nil
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("logging: file sink requires a path (file:<path>)")
+		}
+		return NewFileSink(arg)
+	case "syslog":
+		if arg == "" {
+			arg = "sqlexec"
+		}
+		return NewSyslogSink(arg)
+	default:
+		return nil, fmt.Errorf("logging: unknown sink %q", spec)
+	}
+}
+
+// validateSink 检查 sink 描述的语法，不打开文件或连接 syslog
+func validateSink(spec string) error {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch strings.ToLower(kind) {
+	case "stdout", "stderr", "syslog":
+		return nil
+	case "file":
+		if arg == "" {
+			return fmt.Errorf("logging: file sink requires a path (file:<path>)")
+		}
+		return nil
+	default:
+		return fmt.Errorf("logging: unknown sink %q", spec)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package logging
+
+import "fmt"
+
+// NewSyslogSink 当前平台不支持 syslog
+func NewSyslogSink(tag string) (Sink, error) {
+	return nil, fmt.Errorf("logging: syslog is not supported on this platform")
+}
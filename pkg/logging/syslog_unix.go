@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd
+
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// syslogSink 写入本机 syslog，日志级别映射到 syslog 优先级
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink 连接本机 syslog，tag 为日志中的程序名
+func NewSyslogSink(tag string) (Sink, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("logging: connect syslog: %w", err)
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(level Level, line []byte) error {
+	msg := strings.TrimSuffix(string(line), "\n")
+	switch level {
+	case LevelDebug:
+		return s.w.Debug(msg)
+	case LevelWarn:
+		return s.w.Warning(msg)
+	case LevelError:
+		return s.w.Err(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,179 @@
+package lookup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/logging"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// fileStamp 文件的一个版本
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// lookupLog 查找表模块的结构化日志
+var lookupLog = logging.Module("lookup")
+
+// Loader 把内联定义和定义文件中的查找表加载到内存数据源 ds。
+// 重新加载时先在临时数据源中建好所有表，全部成功后才逐表替换 ds 中的数据，
+// 加载失败时 ds 保留上一次成功加载的数据
+type Loader struct {
+	ds       *memory.MVCCDataSource
+	inline   []Table
+	file     string
+	onReload func(tables []string)
+
+	mu     sync.Mutex
+	loaded map[string]bool      // ds 中由定义加载的表
+	stamps map[string]fileStamp // 上一次加载时定义文件和数据文件的版本
+}
+
+// NewLoader 创建加载器；inline 中数据文件的相对路径相对于当前目录，
+// 定义文件 file 中的相对于定义文件所在的目录，file 为空时只加载内联定义
+func NewLoader(ds *memory.MVCCDataSource, inline []Table, file string) *Loader {
+	return &Loader{ds: ds, inline: inline, file: file, loaded: make(map[string]bool)}
+}
+
+// OnReload 设置每次加载成功后的回调，参数为替换或删除的表，用于使依赖这些表的缓存失效
+func (l *Loader) OnReload(fn func(tables []string)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onReload = fn
+}
+
+// Load 读取定义并加载所有表，定义中不再有的表从 ds 删除
+func (l *Loader) Load(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tables, err := l.definitions()
+	// 失败时同样记录文件的版本，文件再次变化后才重试，避免反复加载写了一半的文件
+	l.stamps = l.statFiles(tables)
+	if err != nil {
+		return err
+	}
+	scratch, err := build(ctx, tables)
+	if err != nil {
+		return err
+	}
+	defer scratch.Close(context.Background())
+
+	current := make(map[string]bool, len(tables))
+	for _, name := range tableNames(tables) {
+		info, rows, err := scratch.GetLatestTableData(name)
+		if err != nil {
+			return fmt.Errorf("lookup table %s: %w", name, err)
+		}
+		if err := l.ds.LoadTable(name, info, rows); err != nil {
+			return fmt.Errorf("lookup table %s: %w", name, err)
+		}
+		current[name] = true
+	}
+	changed := tableNames(tables)
+	for name := range l.loaded {
+		if current[name] {
+			continue
+		}
+		if err := l.ds.DropTable(ctx, name); err != nil {
+			return fmt.Errorf("drop lookup table %s: %w", name, err)
+		}
+		changed = append(changed, name)
+	}
+	l.loaded = current
+	if l.onReload != nil {
+		l.onReload(changed)
+	}
+	return nil
+}
+
+// Changed 报告定义文件或其引用的数据文件自上一次加载以来是否变化
+func (l *Loader) Changed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tables, _ := l.definitions()
+	current := l.statFiles(tables)
+	if len(current) != len(l.stamps) {
+		return true
+	}
+	for path, stamp := range current {
+		if previous, ok := l.stamps[path]; !ok || previous != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch 每隔 interval 检查文件是否变化并重新加载，直到 ctx 取消
+func (l *Loader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || l.file == "" && len(l.inline) == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !l.Changed() {
+					continue
+				}
+				if err := l.Load(ctx); err != nil {
+					lookupLog.Error("failed to reload lookup tables", "err", err)
+				} else {
+					lookupLog.Info("reloaded lookup tables")
+				}
+			}
+		}
+	}()
+}
+
+// definitions 合并内联定义和定义文件，解析数据文件的路径
+func (l *Loader) definitions() ([]Table, error) {
+	tables := append([]Table(nil), l.inline...)
+	if l.file != "" {
+		defs, err := ParseFile(l.file)
+		if err != nil {
+			return nil, err
+		}
+		dir := filepath.Dir(l.file)
+		for _, t := range defs.Tables {
+			if t.File != "" {
+				t.File = dataFile(t.File, dir)
+			}
+			tables = append(tables, t)
+		}
+	}
+	if err := Validate(tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// statFiles 定义文件和数据文件的当前版本，不存在的文件不记录
+func (l *Loader) statFiles(tables []Table) map[string]fileStamp {
+	paths := make([]string, 0, len(tables)+1)
+	if l.file != "" {
+		paths = append(paths, l.file)
+	}
+	for _, t := range tables {
+		if t.File != "" {
+			paths = append(paths, t.File)
+		}
+	}
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{size: fi.Size(), modTime: fi.ModTime()}
+		}
+	}
+	return stamps
+}
@@ -0,0 +1,239 @@
+// Package lookup 把声明式定义的查找表加载到内存数据源：表的数据可以直接写在定义中（行），
+// 也可以引用 CSV、JSON 或 Parquet 文件。定义来自配置文件中的内联定义和 JSON / YAML 定义文件，
+// 定义文件或其引用的数据文件变化时重新加载，参考数据无需另建数据源
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/dataimport"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"gopkg.in/yaml.v3"
+)
+
+// Definitions 定义文件的内容
+type Definitions struct {
+	Tables []Table `json:"tables" yaml:"tables"`
+}
+
+// Table 一个查找表：Rows 与 File 二选一。Rows 的每一行是按列顺序的数组或按列名的对象，
+// 此时必须声明 Columns；File 的表结构在未声明 Columns 时根据文件内容推断
+type Table struct {
+	Name    string        `json:"name" yaml:"name"`
+	Columns []Column      `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Rows    []interface{} `json:"rows,omitempty" yaml:"rows,omitempty"`
+	File    string        `json:"file,omitempty" yaml:"file,omitempty"` // 相对路径相对于定义所在的目录
+}
+
+// Column 查找表的列，Type 为空时为 VARCHAR
+type Column struct {
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type,omitempty" yaml:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty" yaml:"primary,omitempty"`
+}
+
+// ParseFile 读取定义文件，按扩展名解析为 YAML（.yaml、.yml）或 JSON
+func ParseFile(path string) (*Definitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defs := &Definitions{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, defs)
+	default:
+		err = json.Unmarshal(data, defs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse lookup table definitions %s: %w", path, err)
+	}
+	return defs, nil
+}
+
+// Validate 检查表名不重复、每个表有且只有一种数据来源
+func Validate(tables []Table) error {
+	seen := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		if t.Name == "" {
+			return fmt.Errorf("lookup table name is required")
+		}
+		if seen[strings.ToLower(t.Name)] {
+			return fmt.Errorf("lookup table %s is defined more than once", t.Name)
+		}
+		seen[strings.ToLower(t.Name)] = true
+		if t.File != "" && len(t.Rows) > 0 {
+			return fmt.Errorf("lookup table %s: rows and file are mutually exclusive", t.Name)
+		}
+		if t.File == "" && len(t.Columns) == 0 {
+			return fmt.Errorf("lookup table %s: columns are required for inline rows", t.Name)
+		}
+		for _, col := range t.Columns {
+			if col.Name == "" {
+				return fmt.Errorf("lookup table %s: column name is required", t.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// build 在临时的内存数据源中建立表并写入数据，由数据源完成类型与主键的检查
+func build(ctx context.Context, tables []Table) (*memory.MVCCDataSource, error) {
+	scratch := memory.NewMVCCDataSource(nil)
+	if err := scratch.Connect(ctx); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if err := buildTable(ctx, scratch, t); err != nil {
+			scratch.Close(context.Background())
+			return nil, fmt.Errorf("lookup table %s: %w", t.Name, err)
+		}
+	}
+	return scratch, nil
+}
+
+func buildTable(ctx context.Context, ds *memory.MVCCDataSource, t Table) error {
+	if len(t.Columns) > 0 {
+		info := &domain.TableInfo{Name: t.Name}
+		for _, col := range t.Columns {
+			colType := col.Type
+			if colType == "" {
+				colType = "VARCHAR"
+			}
+			info.Columns = append(info.Columns, domain.ColumnInfo{Name: col.Name, Type: strings.ToUpper(colType),
+				Primary: col.Primary, Nullable: !col.Primary})
+		}
+		if err := ds.CreateTable(ctx, info); err != nil {
+			return err
+		}
+	}
+	if t.File != "" {
+		format, err := fileFormat(t.File)
+		if err != nil {
+			return err
+		}
+		_, err = dataimport.Import(ctx, ds, t.Name, t.File, dataimport.DefaultOptions(format))
+		return err
+	}
+
+	rows := make([]domain.Row, 0, len(t.Rows))
+	for i, value := range t.Rows {
+		row, err := tableRow(t.Columns, value)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := ds.Insert(ctx, t.Name, rows, nil)
+	return err
+}
+
+// tableRow 把内联的一行（数组或对象）转换为按列名的行
+func tableRow(columns []Column, value interface{}) (domain.Row, error) {
+	row := make(domain.Row, len(columns))
+	switch v := value.(type) {
+	case []interface{}:
+		if len(v) != len(columns) {
+			return nil, fmt.Errorf("has %d values, table has %d columns", len(v), len(columns))
+		}
+		for i, col := range columns {
+			row[col.Name] = normalize(v[i], col.Type)
+		}
+	case map[string]interface{}:
+		for name := range v {
+			if columnIndex(columns, name) < 0 {
+				return nil, fmt.Errorf("unknown column %s", name)
+			}
+		}
+		for _, col := range columns {
+			row[col.Name] = normalize(lookupField(v, col.Name), col.Type)
+		}
+	default:
+		return nil, fmt.Errorf("must be an array or an object")
+	}
+	return row, nil
+}
+
+// normalize JSON 的数值都是 float64、YAML 的整数是 int，按列类型统一为 int64 或 float64
+func normalize(v interface{}, colType string) interface{} {
+	upper := strings.ToUpper(colType)
+	integer := strings.Contains(upper, "INT")
+	floating := strings.Contains(upper, "FLOAT") || strings.Contains(upper, "DOUBLE") ||
+		strings.Contains(upper, "DECIMAL") || strings.Contains(upper, "NUMERIC") || upper == "REAL"
+	switch n := v.(type) {
+	case int:
+		if floating {
+			return float64(n)
+		}
+		return int64(n)
+	case float64:
+		if integer && n == math.Trunc(n) {
+			return int64(n)
+		}
+	}
+	return v
+}
+
+func columnIndex(columns []Column, name string) int {
+	for i, col := range columns {
+		if strings.EqualFold(col.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookupField 按列名（不区分大小写）取对象中的值
+func lookupField(fields map[string]interface{}, name string) interface{} {
+	if v, ok := fields[name]; ok {
+		return v
+	}
+	for key, v := range fields {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// dataFile 数据文件的路径，相对路径相对于 dir
+func dataFile(file, dir string) string {
+	if filepath.IsAbs(file) || dir == "" {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+// fileFormat 按扩展名确定数据文件的格式
+func fileFormat(path string) (dataimport.Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return dataimport.FormatCSV, nil
+	case ".json", ".jsonl", ".ndjson":
+		return dataimport.FormatJSON, nil
+	case ".parquet":
+		return dataimport.FormatParquet, nil
+	}
+	return "", fmt.Errorf("unsupported data file %s (.csv, .json, .jsonl, .parquet)", path)
+}
+
+// tableNames 表名，按名称排序
+func tableNames(tables []Table) []string {
+	names := make([]string, 0, len(tables))
+	for _, t := range tables {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,128 @@
+package lookup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDefinitions = `
+tables:
+  - name: countries
+    columns:
+      - {name: code, primary: true}
+      - {name: name}
+      - {name: population, type: BIGINT}
+    rows:
+      - [US, United States, 331]
+      - {code: FR, name: France, population: 68}
+  - name: currencies
+    file: currencies.csv
+`
+
+func newTestLoader(t *testing.T, inline []Table) (*Loader, *memory.MVCCDataSource, string) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lookup.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testDefinitions), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "currencies.csv"), []byte("code,rate\nUSD,1\nEUR,1.08\n"), 0644))
+
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	t.Cleanup(func() { ds.Close(context.Background()) })
+	return NewLoader(ds, inline, file), ds, dir
+}
+
+func queryAll(t *testing.T, ds domain.DataSource, table string) []domain.Row {
+	result, err := ds.Query(context.Background(), table, &domain.QueryOptions{SelectAll: true})
+	require.NoError(t, err)
+	return result.Rows
+}
+
+// rewrite 写入文件并推后修改时间，确保文件版本变化
+func rewrite(t *testing.T, path, content string, age int) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	at := time.Now().Add(time.Duration(age) * time.Second)
+	require.NoError(t, os.Chtimes(path, at, at))
+}
+
+// TestLoader_Load 测试从 YAML 定义加载内联行、数据文件和内联定义的表
+func TestLoader_Load(t *testing.T) {
+	inline := []Table{{Name: "flags", Columns: []Column{{Name: "id", Type: "INT", Primary: true}, {Name: "on", Type: "BOOL"}},
+		Rows: []interface{}{[]interface{}{float64(1), true}}}}
+	loader, ds, _ := newTestLoader(t, inline)
+	var reloaded []string
+	loader.OnReload(func(tables []string) { reloaded = tables })
+	require.NoError(t, loader.Load(context.Background()))
+	assert.Equal(t, []string{"countries", "currencies", "flags"}, reloaded)
+
+	countries := queryAll(t, ds, "countries")
+	require.Len(t, countries, 2)
+	byCode := map[interface{}]domain.Row{}
+	for _, row := range countries {
+		byCode[row["code"]] = row
+	}
+	assert.Equal(t, "United States", byCode["US"]["name"])
+	assert.EqualValues(t, 68, byCode["FR"]["population"])
+
+	info, err := ds.GetTableInfo(context.Background(), "countries")
+	require.NoError(t, err)
+	assert.True(t, info.Columns[0].Primary)
+
+	assert.Len(t, queryAll(t, ds, "currencies"), 2)
+	flags := queryAll(t, ds, "flags")
+	require.Len(t, flags, 1)
+	assert.EqualValues(t, 1, flags[0]["id"])
+	assert.False(t, loader.Changed())
+}
+
+// TestLoader_Reload 测试文件变化后重新加载、删除不再定义的表，失败时保留上一次的数据
+func TestLoader_Reload(t *testing.T) {
+	loader, ds, dir := newTestLoader(t, nil)
+	require.NoError(t, loader.Load(context.Background()))
+
+	rewrite(t, filepath.Join(dir, "currencies.csv"), "code,rate\nUSD,1\nEUR,1.08\nJPY,0.0067\n", 10)
+	assert.True(t, loader.Changed())
+	require.NoError(t, loader.Load(context.Background()))
+	assert.Len(t, queryAll(t, ds, "currencies"), 3)
+	assert.False(t, loader.Changed())
+
+	// 无效的定义不影响已加载的数据，文件再次变化前不重试
+	rewrite(t, filepath.Join(dir, "lookup.yaml"), "tables:\n  - name: countries\n    rows: [[US]]\n", 20)
+	assert.Error(t, loader.Load(context.Background()))
+	assert.False(t, loader.Changed())
+	assert.Len(t, queryAll(t, ds, "countries"), 2)
+
+	// 表从定义中移除后被删除
+	rewrite(t, filepath.Join(dir, "lookup.yaml"), "tables:\n  - name: currencies\n    file: currencies.csv\n", 30)
+	assert.True(t, loader.Changed())
+	require.NoError(t, loader.Load(context.Background()))
+	tables, err := ds.GetTables(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"currencies"}, tables)
+}
+
+// TestParseFile_JSON 测试 JSON 定义文件与定义的校验
+func TestParseFile_JSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lookup.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"tables":[{"name":"t","columns":[{"name":"id","type":"INT"}],"rows":[{"id":1}]}]}`), 0644))
+	defs, err := ParseFile(file)
+	require.NoError(t, err)
+	require.Len(t, defs.Tables, 1)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1)}}, defs.Tables[0].Rows)
+
+	assert.Error(t, Validate([]Table{{Name: "t", File: "a.csv"}, {Name: "T", File: "b.csv"}}))
+	assert.Error(t, Validate([]Table{{Name: "t", File: "a.csv", Columns: []Column{{Name: "id"}}, Rows: []interface{}{[]interface{}{1}}}}))
+	assert.Error(t, Validate([]Table{{Name: "t", Rows: []interface{}{[]interface{}{1}}}}))
+
+	_, err = tableRow([]Column{{Name: "id"}}, map[string]interface{}{"nope": 1})
+	assert.Error(t, err)
+	_, err = tableRow([]Column{{Name: "id"}}, []interface{}{1, 2})
+	assert.Error(t, err)
+}
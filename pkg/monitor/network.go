@@ -0,0 +1,61 @@
+package monitor
+
+import "sync/atomic"
+
+// NetworkStats 结果集写出的流控统计（并发安全）
+type NetworkStats struct {
+	bytesSent    atomic.Int64
+	flushes      atomic.Int64
+	stalls       atomic.Int64
+	stalledConns atomic.Int64
+	timeouts     atomic.Int64
+}
+
+// NetworkSnapshot 流控统计快照
+type NetworkSnapshot struct {
+	BytesSent          int64 // 累计写出的结果集字节数
+	Flushes            int64 // 累计批量写出次数
+	Stalls             int64 // 累计写阻塞超过阈值的次数
+	StalledConnections int64 // 当前因客户端读取缓慢而写阻塞的连接数
+	Timeouts           int64 // 累计因写超时断开的连接数
+}
+
+var globalNetworkStats = &NetworkStats{}
+
+// GetNetworkStats 获取全局流控统计
+func GetNetworkStats() *NetworkStats {
+	return globalNetworkStats
+}
+
+// RecordFlush 记录一次批量写出
+func (s *NetworkStats) RecordFlush(bytes int) {
+	s.flushes.Add(1)
+	s.bytesSent.Add(int64(bytes))
+}
+
+// StallBegin 连接写阻塞超过阈值
+func (s *NetworkStats) StallBegin() {
+	s.stalls.Add(1)
+	s.stalledConns.Add(1)
+}
+
+// StallEnd 阻塞的写入已完成或失败
+func (s *NetworkStats) StallEnd() {
+	s.stalledConns.Add(-1)
+}
+
+// RecordTimeout 记录一次写超时
+func (s *NetworkStats) RecordTimeout() {
+	s.timeouts.Add(1)
+}
+
+// Snapshot 返回统计快照
+func (s *NetworkStats) Snapshot() NetworkSnapshot {
+	return NetworkSnapshot{
+		BytesSent:          s.bytesSent.Load(),
+		Flushes:            s.flushes.Load(),
+		Stalls:             s.stalls.Load(),
+		StalledConnections: s.stalledConns.Load(),
+		Timeouts:           s.timeouts.Load(),
+	}
+}
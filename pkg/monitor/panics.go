@@ -0,0 +1,115 @@
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
+)
+
+// panic 被恢复的位置
+const (
+	PanicScopeConnection = "connection" // 连接处理（握手、读包、会话管理）
+	PanicScopeStatement  = "statement"  // 单条命令或语句的执行
+	PanicScopeHTTP       = "http"       // HTTP API 请求
+)
+
+// maxPanicReports 保留的最近 panic 报告数
+const maxPanicReports = 32
+
+// PanicReport 一次被恢复的 panic 的诊断信息
+type PanicReport struct {
+	ID           int64     `json:"id"` // 与错误消息和日志中的 panic_id 对应
+	Time         time.Time `json:"time"`
+	Scope        string    `json:"scope"`
+	ConnectionID int64     `json:"connection_id,omitempty"`
+	User         string    `json:"user,omitempty"`
+	Command      string    `json:"command,omitempty"`
+	Query        string    `json:"query,omitempty"`
+	Value        string    `json:"value"` // panic 的值
+	Stack        string    `json:"stack"`
+}
+
+// PanicError panic 转换成的错误，返回给客户端时不包含堆栈
+type PanicError struct {
+	Report *PanicReport
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("internal error: %s aborted by a server panic (panic id %d)", e.Report.Scope, e.Report.ID)
+}
+
+// PanicStats 被恢复的 panic 的计数和最近的报告（并发安全）
+type PanicStats struct {
+	connection atomic.Int64
+	statement  atomic.Int64
+	http       atomic.Int64
+
+	mu     sync.Mutex
+	recent []PanicReport // 按时间先后，最多 maxPanicReports 条
+}
+
+// PanicSnapshot panic 统计快照
+type PanicSnapshot struct {
+	Connection int64         // 累计在连接处理中恢复的 panic 数
+	Statement  int64         // 累计在命令执行中恢复的 panic 数
+	HTTP       int64         // 累计在 HTTP API 请求中恢复的 panic 数
+	Recent     []PanicReport // 最近的报告，最新的在前
+}
+
+var globalPanicStats = &PanicStats{}
+
+// GetPanicStats 获取全局 panic 统计
+func GetPanicStats() *PanicStats {
+	return globalPanicStats
+}
+
+// Record 记录一次被恢复的 panic，填写报告的 ID 和时间并返回对应的错误
+func (s *PanicStats) Record(report PanicReport) *PanicError {
+	report.ID = idgen.Next()
+	report.Time = idgen.Time(report.ID)
+	switch report.Scope {
+	case PanicScopeConnection:
+		s.connection.Add(1)
+	case PanicScopeHTTP:
+		s.http.Add(1)
+	default:
+		s.statement.Add(1)
+	}
+
+	s.mu.Lock()
+	if len(s.recent) >= maxPanicReports {
+		s.recent = append(s.recent[:0], s.recent[1:]...)
+	}
+	s.recent = append(s.recent, report)
+	s.mu.Unlock()
+	return &PanicError{Report: &report}
+}
+
+// Snapshot 返回统计快照
+func (s *PanicStats) Snapshot() PanicSnapshot {
+	s.mu.Lock()
+	recent := make([]PanicReport, len(s.recent))
+	for i, r := range s.recent {
+		recent[len(recent)-1-i] = r
+	}
+	s.mu.Unlock()
+	return PanicSnapshot{
+		Connection: s.connection.Load(),
+		Statement:  s.statement.Load(),
+		HTTP:       s.http.Load(),
+		Recent:     recent,
+	}
+}
+
+// Reset 清空计数和报告
+func (s *PanicStats) Reset() {
+	s.connection.Store(0)
+	s.statement.Store(0)
+	s.http.Store(0)
+	s.mu.Lock()
+	s.recent = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicStats_Record(t *testing.T) {
+	stats := &PanicStats{}
+
+	perr := stats.Record(PanicReport{Scope: PanicScopeStatement, ConnectionID: 7, Query: "SELECT 1", Value: "boom"})
+	require.NotNil(t, perr.Report)
+	assert.NotZero(t, perr.Report.ID)
+	assert.False(t, perr.Report.Time.IsZero())
+	assert.Contains(t, perr.Error(), fmt.Sprintf("panic id %d", perr.Report.ID))
+	assert.NotContains(t, perr.Error(), "boom")
+
+	stats.Record(PanicReport{Scope: PanicScopeConnection})
+	stats.Record(PanicReport{Scope: PanicScopeHTTP})
+
+	snap := stats.Snapshot()
+	assert.Equal(t, int64(1), snap.Statement)
+	assert.Equal(t, int64(1), snap.Connection)
+	assert.Equal(t, int64(1), snap.HTTP)
+	require.Len(t, snap.Recent, 3)
+	assert.Equal(t, PanicScopeHTTP, snap.Recent[0].Scope)
+	assert.Equal(t, "SELECT 1", snap.Recent[2].Query)
+
+	stats.Reset()
+	assert.Equal(t, PanicSnapshot{Recent: []PanicReport{}}, stats.Snapshot())
+}
+
+func TestPanicStats_KeepsRecentReports(t *testing.T) {
+	stats := &PanicStats{}
+	for i := 0; i < maxPanicReports+5; i++ {
+		stats.Record(PanicReport{Scope: PanicScopeStatement, Value: fmt.Sprint(i)})
+	}
+
+	snap := stats.Snapshot()
+	assert.Equal(t, int64(maxPanicReports+5), snap.Statement)
+	require.Len(t, snap.Recent, maxPanicReports)
+	assert.Equal(t, fmt.Sprint(maxPanicReports+4), snap.Recent[0].Value)
+	assert.Equal(t, "5", snap.Recent[maxPanicReports-1].Value)
+}
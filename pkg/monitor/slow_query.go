@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -17,7 +18,19 @@ type SlowQueryLog struct {
 	RowCount    int64
 	ExecutedBy  string
 	Error       string
-	ExplainPlan string
+	Digest      string            // 语句摘要，与 information_schema.statements_summary 的 DIGEST 对应
+	ExplainPlan string            // 采样捕获的执行计划
+	Stats       *ExecutionStats   // 采样捕获的执行统计，未被采样时为 nil
+	Labels      map[string]string // 语句的查询标签（如 app、request_id）
+}
+
+// ExecutionStats 采样慢语句时捕获的执行统计
+type ExecutionStats struct {
+	PlanDigest    string        // 执行计划摘要，同一语句摘要下发生变化说明执行计划变了
+	EstimatedCost float64       // 优化器估算的计划成本
+	ExecCount     int64         // 捕获时该语句摘要的累计执行次数
+	AvgLatency    time.Duration // 捕获时该语句摘要的平均延迟
+	AvgRows       int64         // 捕获时该语句摘要的平均行数
 }
 
 // SlowQueryAnalyzer 慢查询分析器
@@ -28,6 +41,8 @@ type SlowQueryAnalyzer struct {
 	threshold    time.Duration
 	maxEntries   int
 	nextID       int64
+	sampleRate   float64 // 捕获执行计划的慢语句比例
+	random       func() float64
 }
 
 // NewSlowQueryAnalyzer 创建慢查询分析器
@@ -38,6 +53,34 @@ func NewSlowQueryAnalyzer(threshold time.Duration, maxEntries int) *SlowQueryAna
 		threshold:    threshold,
 		maxEntries:   maxEntries,
 		nextID:       1,
+		random:       rand.Float64,
+	}
+}
+
+// DefaultSlowQueryMaxEntries 慢查询日志默认最多保留的条目数
+const DefaultSlowQueryMaxEntries = 1000
+
+// 全局慢查询日志，阈值为 0（默认）时不记录
+var globalSlowQueryLog = NewSlowQueryAnalyzer(0, DefaultSlowQueryMaxEntries)
+
+// GetSlowQueryLog 获取全局慢查询日志
+func GetSlowQueryLog() *SlowQueryAnalyzer {
+	return globalSlowQueryLog
+}
+
+// Configure 设置慢查询阈值和最多保留的条目数，threshold <= 0 表示不记录
+func (s *SlowQueryAnalyzer) Configure(threshold time.Duration, maxEntries int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if maxEntries <= 0 {
+		maxEntries = DefaultSlowQueryMaxEntries
+	}
+	s.threshold = threshold
+	s.maxEntries = maxEntries
+	for len(s.slowQueries) > s.maxEntries {
+		delete(s.slowQueryMap, s.slowQueries[0].ID)
+		s.slowQueries = s.slowQueries[1:]
 	}
 }
 
@@ -57,28 +100,14 @@ func (s *SlowQueryAnalyzer) RecordSlowQuery(sql string, duration time.Duration,
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	log := &SlowQueryLog{
-		ID:         s.nextID,
+	return s.appendLocked(&SlowQueryLog{
 		SQL:        sql,
 		Duration:   duration,
 		Timestamp:  time.Now(),
 		TableName:  tableName,
 		RowCount:   rowCount,
 		ExecutedBy: "system",
-	}
-
-	s.slowQueryMap[log.ID] = log
-	s.slowQueries = append(s.slowQueries, log)
-	s.nextID++
-
-	// 如果超出最大条目数，移除最旧的记录
-	if len(s.slowQueries) > s.maxEntries {
-		oldest := s.slowQueries[0]
-		delete(s.slowQueryMap, oldest.ID)
-		s.slowQueries = s.slowQueries[1:]
-	}
-
-	return log.ID
+	})
 }
 
 // RecordSlowQueryWithError 记录带错误的慢查询
@@ -90,8 +119,7 @@ func (s *SlowQueryAnalyzer) RecordSlowQueryWithError(sql string, duration time.D
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	log := &SlowQueryLog{
-		ID:         s.nextID,
+	return s.appendLocked(&SlowQueryLog{
 		SQL:        sql,
 		Duration:   duration,
 		Timestamp:  time.Now(),
@@ -99,13 +127,98 @@ func (s *SlowQueryAnalyzer) RecordSlowQueryWithError(sql string, duration time.D
 		RowCount:   rowCount,
 		ExecutedBy: "system",
 		Error:      errMsg,
+	})
+}
+
+// RecordStatement 记录一条执行超过阈值的语句、语句摘要及执行用户；阈值为 0 时不记录
+func (s *SlowQueryAnalyzer) RecordStatement(sql, digest, user string, duration time.Duration, rowCount int64, errMsg string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.threshold <= 0 || duration < s.threshold {
+		return 0
 	}
+	return s.appendLocked(&SlowQueryLog{
+		SQL:        sql,
+		Duration:   duration,
+		Timestamp:  time.Now(),
+		RowCount:   rowCount,
+		ExecutedBy: user,
+		Error:      errMsg,
+		Digest:     digest,
+	})
+}
+
+// SetExplainSampleRate 设置捕获执行计划和执行统计的慢语句比例（0-1），0 表示不捕获
+func (s *SlowQueryAnalyzer) SetExplainSampleRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sampleRate = rate
+}
+
+// ExplainSampleRate 获取捕获执行计划的慢语句比例
+func (s *SlowQueryAnalyzer) ExplainSampleRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sampleRate
+}
+
+// ShouldSample 按采样比例决定是否为刚记录的慢语句捕获执行计划
+func (s *SlowQueryAnalyzer) ShouldSample() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	switch {
+	case s.sampleRate <= 0:
+		return false
+	case s.sampleRate >= 1:
+		return true
+	}
+	return s.random() < s.sampleRate
+}
+
+// SetSample 为慢查询记录保存采样捕获的执行计划和执行统计
+func (s *SlowQueryAnalyzer) SetSample(id int64, explainPlan string, stats *ExecutionStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if log, ok := s.slowQueryMap[id]; ok {
+		log.ExplainPlan = explainPlan
+		log.Stats = stats
+	}
+}
+
+// SetLabels 为慢查询记录保存语句的查询标签
+func (s *SlowQueryAnalyzer) SetLabels(id int64, labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if log, ok := s.slowQueryMap[id]; ok {
+		log.Labels = labels
+	}
+}
+
+// GetSlowQueriesByDigest 获取指定语句摘要的慢查询
+func (s *SlowQueryAnalyzer) GetSlowQueriesByDigest(digest string) []*SlowQueryLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []*SlowQueryLog{}
+	for _, log := range s.slowQueries {
+		if log.Digest == digest {
+			result = append(result, log)
+		}
+	}
+	return result
+}
 
+// appendLocked 分配 ID 并追加记录，超出最大条目数时移除最旧的记录
+func (s *SlowQueryAnalyzer) appendLocked(log *SlowQueryLog) int64 {
+	log.ID = s.nextID
 	s.slowQueryMap[log.ID] = log
 	s.slowQueries = append(s.slowQueries, log)
 	s.nextID++
 
-	if len(s.slowQueries) > s.maxEntries {
+	for len(s.slowQueries) > s.maxEntries {
 		oldest := s.slowQueries[0]
 		delete(s.slowQueryMap, oldest.ID)
 		s.slowQueries = s.slowQueries[1:]
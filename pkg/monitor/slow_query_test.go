@@ -610,3 +610,70 @@ func TestConcurrentSlowQueries(t *testing.T) {
 		t.Errorf("Expected 1000 slow queries, got %d", count)
 	}
 }
+
+func TestRecordStatement(t *testing.T) {
+	analyzer := NewSlowQueryAnalyzer(0, 10)
+
+	// 阈值为 0 时不记录
+	if id := analyzer.RecordStatement("SELECT 1", "", "app", time.Hour, 1, ""); id != 0 {
+		t.Errorf("RecordStatement with zero threshold = %d, want 0", id)
+	}
+
+	analyzer.Configure(100*time.Millisecond, 2)
+	if id := analyzer.RecordStatement("SELECT 1", "", "app", 50*time.Millisecond, 1, ""); id != 0 {
+		t.Errorf("RecordStatement below threshold = %d, want 0", id)
+	}
+	for i := 0; i < 3; i++ {
+		analyzer.RecordStatement(fmt.Sprintf("SELECT %d", i), "", "app", time.Second, 1, "")
+	}
+	queries := analyzer.GetAllSlowQueries()
+	if len(queries) != 2 {
+		t.Fatalf("Expected 2 slow queries, got %d", len(queries))
+	}
+	if queries[0].SQL != "SELECT 1" || queries[0].ExecutedBy != "app" {
+		t.Errorf("oldest entry = %q by %q, want SELECT 1 by app", queries[0].SQL, queries[0].ExecutedBy)
+	}
+
+	// 缩小上限时淘汰最旧的记录
+	analyzer.Configure(time.Second, 1)
+	if queries := analyzer.GetAllSlowQueries(); len(queries) != 1 || queries[0].SQL != "SELECT 2" {
+		t.Errorf("Configure did not evict the oldest entries: %v", queries)
+	}
+}
+
+func TestExplainSampling(t *testing.T) {
+	analyzer := NewSlowQueryAnalyzer(time.Millisecond, 10)
+
+	// 默认不采样
+	if analyzer.ShouldSample() {
+		t.Error("ShouldSample() should be false without a sample rate")
+	}
+	analyzer.SetExplainSampleRate(1)
+	if !analyzer.ShouldSample() {
+		t.Error("ShouldSample() should always be true at rate 1")
+	}
+	analyzer.SetExplainSampleRate(0.25)
+	analyzer.random = func() float64 { return 0.2 }
+	if !analyzer.ShouldSample() {
+		t.Error("ShouldSample() should be true below the sample rate")
+	}
+	analyzer.random = func() float64 { return 0.3 }
+	if analyzer.ShouldSample() {
+		t.Error("ShouldSample() should be false above the sample rate")
+	}
+
+	id := analyzer.RecordStatement("SELECT * FROM t WHERE id = 1", "d1", "app", time.Second, 1, "")
+	analyzer.RecordStatement("SELECT * FROM u", "d2", "app", time.Second, 5, "")
+	analyzer.SetSample(id, "scan_t [TableScan]\n", &ExecutionStats{PlanDigest: "p1", ExecCount: 3})
+
+	queries := analyzer.GetSlowQueriesByDigest("d1")
+	if len(queries) != 1 {
+		t.Fatalf("GetSlowQueriesByDigest() returned %d entries, want 1", len(queries))
+	}
+	if queries[0].ExplainPlan != "scan_t [TableScan]\n" || queries[0].Stats == nil || queries[0].Stats.PlanDigest != "p1" {
+		t.Errorf("sample not stored: plan=%q stats=%+v", queries[0].ExplainPlan, queries[0].Stats)
+	}
+	if other := analyzer.GetSlowQueriesByDigest("d2"); len(other) != 1 || other[0].Stats != nil {
+		t.Errorf("unsampled entry should have no stats: %+v", other)
+	}
+}
@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultStatementSummaryMaxEntries 语句摘要默认最多保留的摘要数
+const DefaultStatementSummaryMaxEntries = 1000
+
+// StatementStats 按语句摘要聚合的执行统计
+type StatementStats struct {
+	Digest     string
+	DigestText string // 规范化后的 SQL（字面量替换为 ?）
+	SampleSQL  string // 最近一次执行的原始 SQL
+	ExecCount  int64
+	ErrorCount int64
+	SumLatency time.Duration
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	SumRows    int64
+	MaxRows    int64
+	LastError  string
+	FirstSeen  time.Time
+	LastSeen   time.Time
+
+	// 慢语句采样捕获的最近一次执行计划
+	Plan           string
+	PlanDigest     string
+	PlanCapturedAt time.Time
+	PlanChanges    int64 // 捕获到的执行计划与上一次不同的次数
+}
+
+// AvgLatency 平均延迟
+func (s *StatementStats) AvgLatency() time.Duration {
+	if s.ExecCount == 0 {
+		return 0
+	}
+	return s.SumLatency / time.Duration(s.ExecCount)
+}
+
+// StatementSummary 语句摘要统计（并发安全）
+// 按摘要聚合执行次数、延迟、返回行数和错误数，用于定位热点语句和慢语句。
+// 摘要数超过上限时淘汰最久未执行的摘要
+type StatementSummary struct {
+	mu         sync.Mutex
+	enabled    bool
+	maxEntries int
+	ll         *list.List // 按最近执行时间排序，队首最新
+	items      map[string]*list.Element
+}
+
+// NewStatementSummary 创建语句摘要统计，maxEntries <= 0 表示不限制摘要数
+func NewStatementSummary(maxEntries int) *StatementSummary {
+	return &StatementSummary{
+		enabled:    true,
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+var globalStatementSummary = NewStatementSummary(DefaultStatementSummaryMaxEntries)
+
+// GetStatementSummary 获取全局语句摘要统计
+func GetStatementSummary() *StatementSummary {
+	return globalStatementSummary
+}
+
+// Configure 设置是否启用以及最大摘要数
+func (s *StatementSummary) Configure(enabled bool, maxEntries int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enabled = enabled
+	s.maxEntries = maxEntries
+	s.evictLocked()
+}
+
+// Enabled 是否启用统计
+func (s *StatementSummary) Enabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enabled
+}
+
+// Record 记录一次语句执行
+func (s *StatementSummary) Record(digest, digestText, sampleSQL string, latency time.Duration, rows int64, err error) {
+	if digest == "" {
+		return
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled {
+		return
+	}
+
+	var stats *StatementStats
+	if elem, ok := s.items[digest]; ok {
+		stats = elem.Value.(*StatementStats)
+		s.ll.MoveToFront(elem)
+	} else {
+		stats = &StatementStats{
+			Digest:     digest,
+			DigestText: digestText,
+			MinLatency: latency,
+			FirstSeen:  now,
+		}
+		s.items[digest] = s.ll.PushFront(stats)
+		s.evictLocked()
+	}
+
+	stats.SampleSQL = sampleSQL
+	stats.ExecCount++
+	stats.SumLatency += latency
+	if latency < stats.MinLatency {
+		stats.MinLatency = latency
+	}
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+	stats.SumRows += rows
+	if rows > stats.MaxRows {
+		stats.MaxRows = rows
+	}
+	if err != nil {
+		stats.ErrorCount++
+		stats.LastError = err.Error()
+	}
+	stats.LastSeen = now
+}
+
+// RecordPlan 保存为该摘要采样捕获的执行计划，计划摘要与上一次不同时计入 PlanChanges；
+// 摘要不存在（未启用或已淘汰）时不做任何事
+func (s *StatementSummary) RecordPlan(digest, planDigest, plan string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[digest]
+	if !ok {
+		return
+	}
+	stats := elem.Value.(*StatementStats)
+	if stats.PlanDigest != "" && stats.PlanDigest != planDigest {
+		stats.PlanChanges++
+	}
+	stats.Plan = plan
+	stats.PlanDigest = planDigest
+	stats.PlanCapturedAt = time.Now()
+}
+
+// Get 获取指定摘要的统计快照
+func (s *StatementSummary) Get(digest string) (StatementStats, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.items[digest]
+	if !ok {
+		return StatementStats{}, false
+	}
+	return *elem.Value.(*StatementStats), true
+}
+
+// Snapshot 返回所有摘要的统计快照，按总延迟降序排列
+func (s *StatementSummary) Snapshot() []StatementStats {
+	s.mu.Lock()
+	result := make([]StatementStats, 0, s.ll.Len())
+	for elem := s.ll.Front(); elem != nil; elem = elem.Next() {
+		result = append(result, *elem.Value.(*StatementStats))
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].SumLatency > result[j].SumLatency
+	})
+	return result
+}
+
+// Len 返回当前摘要数
+func (s *StatementSummary) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+// Reset 清空统计
+func (s *StatementSummary) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ll.Init()
+	s.items = make(map[string]*list.Element)
+}
+
+// evictLocked 淘汰超出上限的最久未执行摘要（调用方需持有锁）
+func (s *StatementSummary) evictLocked() {
+	if s.maxEntries <= 0 {
+		return
+	}
+	for s.ll.Len() > s.maxEntries {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*StatementStats).Digest)
+	}
+}
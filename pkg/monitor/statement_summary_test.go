@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatementSummary_Record(t *testing.T) {
+	s := NewStatementSummary(10)
+	s.Record("d1", "select ?", "SELECT 1", 10*time.Millisecond, 1, nil)
+	s.Record("d1", "select ?", "SELECT 2", 30*time.Millisecond, 3, nil)
+	s.Record("d1", "select ?", "SELECT 3", 20*time.Millisecond, 0, errors.New("boom"))
+
+	stats, ok := s.Get("d1")
+	if !ok {
+		t.Fatal("digest d1 should be recorded")
+	}
+	if stats.ExecCount != 3 {
+		t.Errorf("ExecCount = %d, want 3", stats.ExecCount)
+	}
+	if stats.ErrorCount != 1 || stats.LastError != "boom" {
+		t.Errorf("ErrorCount = %d, LastError = %q, want 1, boom", stats.ErrorCount, stats.LastError)
+	}
+	if stats.MinLatency != 10*time.Millisecond || stats.MaxLatency != 30*time.Millisecond {
+		t.Errorf("Min/MaxLatency = %v/%v, want 10ms/30ms", stats.MinLatency, stats.MaxLatency)
+	}
+	if stats.AvgLatency() != 20*time.Millisecond {
+		t.Errorf("AvgLatency = %v, want 20ms", stats.AvgLatency())
+	}
+	if stats.SumRows != 4 || stats.MaxRows != 3 {
+		t.Errorf("SumRows/MaxRows = %d/%d, want 4/3", stats.SumRows, stats.MaxRows)
+	}
+	if stats.SampleSQL != "SELECT 3" {
+		t.Errorf("SampleSQL = %q, want latest statement", stats.SampleSQL)
+	}
+}
+
+func TestStatementSummary_EvictsLeastRecent(t *testing.T) {
+	s := NewStatementSummary(2)
+	s.Record("d1", "a", "a", time.Millisecond, 0, nil)
+	s.Record("d2", "b", "b", time.Millisecond, 0, nil)
+	s.Record("d1", "a", "a", time.Millisecond, 0, nil)
+	s.Record("d3", "c", "c", time.Millisecond, 0, nil)
+
+	if _, ok := s.Get("d2"); ok {
+		t.Error("d2 should have been evicted")
+	}
+	if _, ok := s.Get("d1"); !ok {
+		t.Error("d1 should still be present")
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len = %d, want 2", s.Len())
+	}
+}
+
+func TestStatementSummary_SnapshotOrderAndReset(t *testing.T) {
+	s := NewStatementSummary(0)
+	s.Record("fast", "fast", "fast", time.Millisecond, 0, nil)
+	s.Record("slow", "slow", "slow", time.Second, 0, nil)
+
+	snapshot := s.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Digest != "slow" {
+		t.Fatalf("snapshot should be ordered by SumLatency desc, got %+v", snapshot)
+	}
+
+	s.Reset()
+	if s.Len() != 0 {
+		t.Errorf("Len after Reset = %d, want 0", s.Len())
+	}
+}
+
+func TestStatementSummary_Disabled(t *testing.T) {
+	s := NewStatementSummary(10)
+	s.Configure(false, 10)
+	s.Record("d1", "a", "a", time.Millisecond, 0, nil)
+	if s.Len() != 0 {
+		t.Error("disabled summary should not record")
+	}
+}
+
+func TestStatementSummary_RecordPlan(t *testing.T) {
+	s := NewStatementSummary(10)
+
+	// 摘要不存在时忽略
+	s.RecordPlan("missing", "p1", "plan")
+	if _, ok := s.Get("missing"); ok {
+		t.Fatal("RecordPlan should not create a digest")
+	}
+
+	s.Record("d1", "select * from t", "SELECT * FROM t", time.Second, 1, nil)
+	s.RecordPlan("d1", "p1", "scan_t [TableScan]\n")
+	s.RecordPlan("d1", "p1", "scan_t [TableScan]\n")
+	stats, _ := s.Get("d1")
+	if stats.PlanDigest != "p1" || stats.Plan != "scan_t [TableScan]\n" || stats.PlanCapturedAt.IsZero() {
+		t.Errorf("plan not stored: %+v", stats)
+	}
+	if stats.PlanChanges != 0 {
+		t.Errorf("PlanChanges = %d, want 0 for the same plan", stats.PlanChanges)
+	}
+
+	s.RecordPlan("d1", "p2", "other plan")
+	stats, _ = s.Get("d1")
+	if stats.PlanChanges != 1 || stats.PlanDigest != "p2" {
+		t.Errorf("PlanChanges/PlanDigest = %d/%s, want 1/p2", stats.PlanChanges, stats.PlanDigest)
+	}
+}
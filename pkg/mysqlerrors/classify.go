@@ -0,0 +1,152 @@
+package mysqlerrors
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Classify 将错误映射为 MySQL 错误码和 SQLSTATE
+// 优先使用错误链中实现了 Coder 的错误给出的错误码，其次按错误文本由内向外归类，
+// 无法识别的错误返回 ER_UNKNOWN_ERROR (1105, HY000)
+func Classify(err error) (uint16, string) {
+	if err == nil {
+		return 0, StateSuccess
+	}
+
+	var chain []error
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		chain = append(chain, e)
+		if myErr, ok := e.(*Error); ok && myErr.Code != 0 {
+			if myErr.State != "" {
+				return myErr.Code, myErr.State
+			}
+			return myErr.Code, SQLState(myErr.Code)
+		}
+		if c, ok := e.(Coder); ok {
+			if code := c.MySQLErrorCode(); code != 0 {
+				return code, SQLState(code)
+			}
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return ErrQueryInterrupted, StateGeneral
+	}
+
+	// 从最内层的原始错误开始归类，外层包装信息（如 "[SYNTAX_ERROR] failed to execute query"）
+	// 往往比较笼统，只在内层无法识别时才使用
+	for i := len(chain) - 1; i >= 0; i-- {
+		if code := classifyMessage(strings.ToLower(chain[i].Error())); code != ErrUnknown {
+			return code, SQLState(code)
+		}
+	}
+	return ErrUnknown, StateGeneral
+}
+
+// classifyMessage 按错误文本归类（msg 已转为小写）
+// 规则按从具体到宽泛的顺序排列，先匹配者优先
+func classifyMessage(msg string) uint16 {
+	has := func(subs ...string) bool {
+		for _, s := range subs {
+			if !strings.Contains(msg, s) {
+				return false
+			}
+		}
+		return true
+	}
+	notExist := has("not found") || has("does not exist") || has("doesn't exist")
+
+	switch {
+	// 连接准入
+	case has("too many connections"):
+		return ErrConCount
+	case has("max_user_connections"):
+		return ErrTooManyUserConnections
+
+	// 后端数据源连接中断（可重试）
+	case has("lost connection to"), has("is quarantined"):
+		return ErrNetReadError
+
+	// 权限
+	case has("access denied for user") && has("to database"):
+		return ErrDBAccessDenied
+	case has("command denied"):
+		return ErrTableAccessDenied
+	case has("access denied; you need"):
+		return ErrSpecificAccessDenied
+	case has("access denied"):
+		return ErrAccessDenied
+
+	// 事务与并发
+	case has("transaction characteristics can't be changed"):
+		return ErrCantChangeTx
+	case has("xaer_nota"):
+		return ErrXANotA
+	case has("xaer_rmfail"):
+		return ErrXARMFail
+	case has("deadlock"):
+		return ErrLockDeadlock
+	case has("lock wait timeout"), has("transaction conflict"), has("write conflict"):
+		return ErrLockWaitTimeout
+	case has("read-only transaction"), has("read only transaction"):
+		return ErrReadOnlyTxn
+	case has("read-only"), has("read only"):
+		return ErrReadOnly
+
+	// 约束
+	case has("duplicate key name"), has("index already exists"):
+		return ErrDupKeyName
+	case has("duplicate entry"), has("duplicate key violation"), has("unique constraint"):
+		return ErrDupEntry
+	case has("duplicate column"):
+		return ErrDupFieldName
+	case has("cannot be null"), has("not null constraint"):
+		return ErrBadNull
+	case has("data too long"):
+		return ErrDataTooLong
+	case has("out of range value"):
+		return ErrDataOutOfRange
+
+	// 对象不存在 / 已存在
+	case has("column") && notExist, has("unknown column"):
+		return ErrBadFieldError
+	case has("table") && notExist:
+		return ErrNoSuchTable
+	case has("unknown table"):
+		return ErrBadTable
+	case has("no database selected"):
+		return ErrNoDB
+	case has("unknown database"), has("database") && notExist:
+		return ErrBadDB
+	case has("table") && has("already exists"):
+		return ErrTableExists
+	case has("database") && has("already exists"), has("database exists"):
+		return ErrDBCreateExists
+	case has("unknown system variable"):
+		return ErrUnknownSystemVariable
+	case has("can't be set to the value of"):
+		return ErrWrongValueForVar
+	case has("unknown prepared statement"):
+		return ErrUnknownStmtHandler
+	case has("needs to be re-prepared"):
+		return ErrNeedReprepare
+
+	// 语法
+	case has("no statements found"), has("empty query"):
+		return ErrEmptyQuery
+	case has("syntax"), has("parse"):
+		return ErrParseError
+	case has("not supported"), has("unsupported"):
+		return ErrNotSupportedYet
+
+	// 超时
+	case has("maximum statement execution time exceeded"):
+		return ErrQueryTimeout
+	case has("query execution timed out"):
+		return ErrQueryTimeout
+	case has("query execution was interrupted"), has("query was killed"):
+		return ErrQueryInterrupted
+	}
+	return ErrUnknown
+}
@@ -0,0 +1,132 @@
+package mysqlerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type coderErr struct{ code uint16 }
+
+func (e coderErr) Error() string          { return "coder error" }
+func (e coderErr) MySQLErrorCode() uint16 { return e.code }
+
+func TestClassify_Messages(t *testing.T) {
+	tests := []struct {
+		msg   string
+		code  uint16
+		state string
+	}{
+		{"table 'users' not found", ErrNoSuchTable, StateNoSuchTable},
+		{"table 'orders' does not exist: open failed", ErrNoSuchTable, StateNoSuchTable},
+		{"column 'age' not found in table users", ErrBadFieldError, StateBadField},
+		{"Duplicate entry '1' for key 'PRIMARY'", ErrDupEntry, StateIntegrity},
+		{"duplicate key violation for unique index", ErrDupEntry, StateIntegrity},
+		{"duplicate column name: id", ErrDupFieldName, StateDupField},
+		{"index already exists: idx_name", ErrDupKeyName, StateSyntaxOrAccess},
+		{"syntax error at line 1 near 'FORM'", ErrParseError, StateSyntaxOrAccess},
+		{"no statements found", ErrEmptyQuery, StateSyntaxOrAccess},
+		{"Lock wait timeout exceeded; try restarting transaction", ErrLockWaitTimeout, StateGeneral},
+		{"Transaction Conflict. Please retry", ErrLockWaitTimeout, StateGeneral},
+		{"Deadlock found when trying to get lock", ErrLockDeadlock, StateSerialization},
+		{"Transaction characteristics can't be changed while a transaction is in progress", ErrCantChangeTx, StateInvalidTxState},
+		{"XAER_NOTA: Unknown XID", ErrXANotA, StateXANotA},
+		{"XAER_RMFAIL: The command cannot be executed when global transaction is in the PREPARED state", ErrXARMFail, StateXARMFail},
+		{"Variable 'transaction_isolation' can't be set to the value of 'DIRTY'", ErrWrongValueForVar, StateSyntaxOrAccess},
+		{"data source is read-only, INSERT operation not allowed", ErrReadOnly, StateGeneral},
+		{"Cannot execute statement in a READ ONLY transaction", ErrReadOnlyTxn, StateReadOnlyTxn},
+		{"access denied for user 'bob'", ErrAccessDenied, StateAccessDenied},
+		{"SELECT command denied to user 'bob'@'%' for table 't'", ErrTableAccessDenied, StateSyntaxOrAccess},
+		{"database 'shop' not found: no such datasource", ErrBadDB, StateSyntaxOrAccess},
+		{"table t1 already exists", ErrTableExists, StateTableExists},
+		{"Column 'name' cannot be null", ErrBadNull, StateIntegrity},
+		{"query execution timed out", ErrQueryTimeout, StateGeneral},
+		{"query was killed", ErrQueryInterrupted, StateGeneral},
+		{"Too many connections", ErrConCount, StateConnRejected},
+		{"lost connection to mysql data source: query: driver: bad connection", ErrNetReadError, StateCommLink},
+		{"data source shop is quarantined: 3 consecutive failed health checks", ErrNetReadError, StateCommLink},
+		{"Prepared statement needs to be re-prepared: table orders was altered or dropped", ErrNeedReprepare, StateGeneral},
+		{"something unexpected happened", ErrUnknown, StateGeneral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			code, state := Classify(errors.New(tt.msg))
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.state, state)
+		})
+	}
+}
+
+func TestClassify_Nil(t *testing.T) {
+	code, state := Classify(nil)
+	assert.Equal(t, uint16(0), code)
+	assert.Equal(t, StateSuccess, state)
+	assert.Nil(t, FromError(nil))
+}
+
+func TestClassify_ContextErrors(t *testing.T) {
+	code, state := Classify(fmt.Errorf("execute: %w", context.DeadlineExceeded))
+	assert.Equal(t, ErrQueryInterrupted, code)
+	assert.Equal(t, StateGeneral, state)
+}
+
+func TestClassify_TypedErrorWins(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", New(ErrLockWaitTimeout, "table %s is locked", "t1"))
+	code, state := Classify(err)
+	assert.Equal(t, ErrLockWaitTimeout, code)
+	assert.Equal(t, StateGeneral, state)
+	assert.True(t, Is(err, ErrLockWaitTimeout))
+
+	code, _ = Classify(fmt.Errorf("outer: %w", coderErr{code: ErrDupEntry}))
+	assert.Equal(t, ErrDupEntry, code)
+}
+
+func TestClassify_ZeroCoderFallsThrough(t *testing.T) {
+	inner := errors.New("table 'x' not found")
+	err := &wrapCoder{cause: inner}
+	code, _ := Classify(err)
+	assert.Equal(t, ErrNoSuchTable, code)
+}
+
+func TestClassify_InnermostMessageFirst(t *testing.T) {
+	// 外层的笼统描述含有 "syntax"，但原始错误是唯一键冲突
+	err := fmt.Errorf("[SYNTAX_ERROR] failed to execute query: %w", errors.New("Duplicate entry '1' for key 'PRIMARY'"))
+	code, state := Classify(err)
+	assert.Equal(t, ErrDupEntry, code)
+	assert.Equal(t, StateIntegrity, state)
+}
+
+func TestFromError(t *testing.T) {
+	orig := New(ErrBadDB, "Unknown database '%s'", "shop")
+	assert.Same(t, orig, FromError(orig))
+	assert.Equal(t, "Unknown database 'shop'", orig.Error())
+
+	plain := errors.New("table 'users' not found")
+	myErr := FromError(plain)
+	assert.Equal(t, ErrNoSuchTable, myErr.Code)
+	assert.Equal(t, StateNoSuchTable, myErr.State)
+	assert.Equal(t, plain.Error(), myErr.Message)
+	assert.ErrorIs(t, myErr, plain)
+
+	wrapped := Wrap(plain, ErrLockWaitTimeout)
+	assert.Equal(t, ErrLockWaitTimeout, wrapped.Code)
+	assert.ErrorIs(t, wrapped, plain)
+	assert.Nil(t, Wrap(nil, ErrUnknown))
+}
+
+func TestSQLState(t *testing.T) {
+	assert.Equal(t, StateIntegrity, SQLState(ErrDupEntry))
+	assert.Equal(t, StateNoSuchTable, SQLState(ErrNoSuchTable))
+	assert.Equal(t, StateGeneral, SQLState(ErrLockWaitTimeout))
+	assert.Equal(t, StateSuccess, SQLState(0))
+}
+
+type wrapCoder struct{ cause error }
+
+func (e *wrapCoder) Error() string          { return "failed: " + e.cause.Error() }
+func (e *wrapCoder) Unwrap() error          { return e.cause }
+func (e *wrapCoder) MySQLErrorCode() uint16 { return 0 }
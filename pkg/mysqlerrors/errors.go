@@ -0,0 +1,220 @@
+// Package mysqlerrors 将引擎内部错误映射为 MySQL 错误码与 SQLSTATE
+//
+// 客户端驱动和 ORM 通常依据错误码（而非错误文本）决定是否重试、
+// 是否提示唯一键冲突等，因此所有 ERR 包都应通过本包生成错误码。
+package mysqlerrors
+
+import "fmt"
+
+// MySQL 错误码
+const (
+	// 连接与权限
+	ErrConCount               uint16 = 1040 // ER_CON_COUNT_ERROR
+	ErrDBAccessDenied         uint16 = 1044 // ER_DBACCESS_DENIED_ERROR
+	ErrAccessDenied           uint16 = 1045 // ER_ACCESS_DENIED_ERROR
+	ErrTableAccessDenied      uint16 = 1142 // ER_TABLEACCESS_DENIED_ERROR
+	ErrTooManyUserConnections uint16 = 1203 // ER_TOO_MANY_USER_CONNECTIONS
+	ErrSpecificAccessDenied   uint16 = 1227 // ER_SPECIFIC_ACCESS_DENIED_ERROR
+	ErrNetReadError           uint16 = 1158 // ER_NET_READ_ERROR
+	ErrNotValidPassword       uint16 = 1819 // ER_NOT_VALID_PASSWORD
+	ErrPasswordExpired        uint16 = 1862 // ER_MUST_CHANGE_PASSWORD_LOGIN
+	ErrAccountLocked          uint16 = 3955 // ER_USER_ACCESS_DENIED_FOR_USER_ACCOUNT_BLOCKED_BY_PASSWORD_LOCK
+	ErrHostNotPrivileged      uint16 = 1130 // ER_HOST_NOT_PRIVILEGED
+	ErrSecureTransport        uint16 = 3159 // ER_SECURE_TRANSPORT_REQUIRED
+
+	// 对象不存在 / 已存在
+	ErrNoDB           uint16 = 1046 // ER_NO_DB_ERROR
+	ErrBadDB          uint16 = 1049 // ER_BAD_DB_ERROR
+	ErrTableExists    uint16 = 1050 // ER_TABLE_EXISTS_ERROR
+	ErrBadTable       uint16 = 1051 // ER_BAD_TABLE_ERROR
+	ErrBadFieldError  uint16 = 1054 // ER_BAD_FIELD_ERROR
+	ErrNonUniq        uint16 = 1052 // ER_NON_UNIQ_ERROR
+	ErrNonUniqTable   uint16 = 1066 // ER_NONUNIQ_TABLE
+	ErrDupFieldName   uint16 = 1060 // ER_DUP_FIELDNAME
+	ErrDupKeyName     uint16 = 1061 // ER_DUP_KEYNAME
+	ErrNoSuchTable    uint16 = 1146 // ER_NO_SUCH_TABLE
+	ErrDBCreateExists uint16 = 1007 // ER_DB_CREATE_EXISTS
+
+	// 数据约束
+	ErrBadNull        uint16 = 1048 // ER_BAD_NULL_ERROR
+	ErrDupEntry       uint16 = 1062 // ER_DUP_ENTRY
+	ErrDataTooLong    uint16 = 1406 // ER_DATA_TOO_LONG
+	ErrDataOutOfRange uint16 = 1690 // ER_DATA_OUT_OF_RANGE
+	ErrRecordFileFull uint16 = 1114 // ER_RECORD_FILE_FULL
+
+	// 语法与语句
+	ErrParseError            uint16 = 1064 // ER_PARSE_ERROR
+	ErrEmptyQuery            uint16 = 1065 // ER_EMPTY_QUERY
+	ErrNotSupportedYet       uint16 = 1235 // ER_NOT_SUPPORTED_YET
+	ErrUnknownSystemVariable uint16 = 1193 // ER_UNKNOWN_SYSTEM_VARIABLE
+	ErrWrongValueForVar      uint16 = 1231 // ER_WRONG_VALUE_FOR_VAR
+	ErrUnknownStmtHandler    uint16 = 1243 // ER_UNKNOWN_STMT_HANDLER
+	ErrTooBigSelect          uint16 = 1104 // ER_TOO_BIG_SELECT
+	ErrWarnDeprecatedSyntax  uint16 = 1287 // ER_WARN_DEPRECATED_SYNTAX
+	ErrTooManyRows           uint16 = 1172 // ER_TOO_MANY_ROWS
+	ErrWrongNumberOfColumns  uint16 = 1222 // ER_WRONG_NUMBER_OF_COLUMNS_IN_SELECT
+	ErrNeedReprepare         uint16 = 1615 // ER_NEED_REPREPARE
+
+	// 事务与并发
+	ErrLockWaitTimeout       uint16 = 1205 // ER_LOCK_WAIT_TIMEOUT
+	ErrLockDeadlock          uint16 = 1213 // ER_LOCK_DEADLOCK
+	ErrReadOnly              uint16 = 1290 // ER_OPTION_PREVENTS_STATEMENT
+	ErrCantChangeTx          uint16 = 1568 // ER_CANT_CHANGE_TX_CHARACTERISTICS
+	ErrXANotA                uint16 = 1397 // ER_XAER_NOTA
+	ErrXARMFail              uint16 = 1399 // ER_XAER_RMFAIL
+	ErrReadOnlyTxn           uint16 = 1792 // ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION
+	ErrTooManyConcurrentTrxs uint16 = 1637 // ER_TOO_MANY_CONCURRENT_TRXS
+
+	// 执行中断
+	ErrQueryInterrupted uint16 = 1317 // ER_QUERY_INTERRUPTED
+	ErrQueryTimeout     uint16 = 3024 // ER_QUERY_TIMEOUT
+
+	// 序列（MariaDB 错误码）
+	ErrSequenceRunOut      uint16 = 4084 // ER_SEQUENCE_RUN_OUT
+	ErrSequenceInvalidData uint16 = 4085 // ER_SEQUENCE_INVALID_DATA
+	ErrNotSequence         uint16 = 4089 // ER_NOT_SEQUENCE
+	ErrUnknownSequences    uint16 = 4091 // ER_UNKNOWN_SEQUENCES
+
+	// 兜底
+	ErrUnknown uint16 = 1105 // ER_UNKNOWN_ERROR
+)
+
+// SQLSTATE 状态码
+const (
+	StateSuccess        = "00000"
+	StateGeneral        = "HY000" // 通用错误
+	StateSyntaxOrAccess = "42000" // 语法错误或访问违规
+	StateNoSuchTable    = "42S02" // 表不存在
+	StateTableExists    = "42S01" // 表已存在
+	StateBadField       = "42S22" // 列不存在
+	StateDupField       = "42S21" // 列已存在
+	StateIntegrity      = "23000" // 完整性约束违反
+	StateDataException  = "22001" // 字符串数据右截断
+	StateOutOfRange     = "22003" // 数值越界
+	StateAccessDenied   = "28000" // 认证失败
+	StateConnRejected   = "08004" // 服务器拒绝连接
+	StateCommLink       = "08S01" // 通信链路故障
+	StateNoDB           = "3D000" // 未选择数据库
+	StateCardinality    = "21000" // 列数不匹配
+	StateSerialization  = "40001" // 序列化失败（死锁）
+	StateReadOnlyTxn    = "25006" // 只读事务
+	StateInvalidTxState = "25001" // 事务进行中，不允许的操作
+	StateXANotA         = "XAE04" // 未知的 XA 事务
+	StateXARMFail       = "XAE07" // XA 事务状态不允许该操作
+)
+
+// sqlStates 错误码到 SQLSTATE 的映射，未列出的错误码使用 HY000
+var sqlStates = map[uint16]string{
+	ErrConCount:               StateConnRejected,
+	ErrDBAccessDenied:         StateSyntaxOrAccess,
+	ErrAccessDenied:           StateAccessDenied,
+	ErrTableAccessDenied:      StateSyntaxOrAccess,
+	ErrTooManyUserConnections: StateSyntaxOrAccess,
+	ErrSpecificAccessDenied:   StateSyntaxOrAccess,
+	ErrNetReadError:           StateCommLink,
+	ErrNoDB:                   StateNoDB,
+	ErrBadDB:                  StateSyntaxOrAccess,
+	ErrTableExists:            StateTableExists,
+	ErrBadTable:               StateNoSuchTable,
+	ErrBadFieldError:          StateBadField,
+	ErrNonUniq:                StateIntegrity,
+	ErrNonUniqTable:           StateSyntaxOrAccess,
+	ErrDupFieldName:           StateDupField,
+	ErrDupKeyName:             StateSyntaxOrAccess,
+	ErrNoSuchTable:            StateNoSuchTable,
+	ErrBadNull:                StateIntegrity,
+	ErrDupEntry:               StateIntegrity,
+	ErrDataTooLong:            StateDataException,
+	ErrDataOutOfRange:         StateOutOfRange,
+	ErrParseError:             StateSyntaxOrAccess,
+	ErrEmptyQuery:             StateSyntaxOrAccess,
+	ErrNotSupportedYet:        StateSyntaxOrAccess,
+	ErrUnknownSystemVariable:  StateGeneral,
+	ErrWrongValueForVar:       StateSyntaxOrAccess,
+	ErrUnknownStmtHandler:     StateGeneral,
+	ErrTooBigSelect:           StateSyntaxOrAccess,
+	ErrTooManyRows:            StateSyntaxOrAccess,
+	ErrWrongNumberOfColumns:   StateCardinality,
+	ErrLockDeadlock:           StateSerialization,
+	ErrCantChangeTx:           StateInvalidTxState,
+	ErrXANotA:                 StateXANotA,
+	ErrXARMFail:               StateXARMFail,
+	ErrReadOnlyTxn:            StateReadOnlyTxn,
+	ErrNotSequence:            StateNoSuchTable,
+	ErrUnknownSequences:       StateNoSuchTable,
+}
+
+// SQLState 返回错误码对应的 SQLSTATE
+func SQLState(code uint16) string {
+	if code == 0 {
+		return StateSuccess
+	}
+	if state, ok := sqlStates[code]; ok {
+		return state
+	}
+	return StateGeneral
+}
+
+// Error 带 MySQL 错误码的错误
+type Error struct {
+	Code    uint16
+	State   string
+	Message string
+	Cause   error
+}
+
+// New 创建带错误码的错误，SQLSTATE 由错误码推导
+func New(code uint16, format string, args ...interface{}) *Error {
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	return &Error{Code: code, State: SQLState(code), Message: msg}
+}
+
+// Wrap 为已有错误附加 MySQL 错误码，保留原始错误链
+func Wrap(err error, code uint16) *Error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, State: SQLState(code), Message: err.Error(), Cause: err}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// MySQLErrorCode 实现 Coder 接口
+func (e *Error) MySQLErrorCode() uint16 {
+	return e.Code
+}
+
+// Coder 由能够直接给出 MySQL 错误码的错误类型实现
+// 返回 0 表示无法确定，分类时继续检查被包装的错误
+type Coder interface {
+	MySQLErrorCode() uint16
+}
+
+// Is 判断错误链中是否存在指定 MySQL 错误码
+func Is(err error, code uint16) bool {
+	c, _ := Classify(err)
+	return c == code
+}
+
+// FromError 将任意错误转换为 *Error（nil 返回 nil）
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if myErr, ok := err.(*Error); ok && myErr.Code != 0 {
+		return myErr
+	}
+	code, state := Classify(err)
+	return &Error{Code: code, State: state, Message: err.Error(), Cause: err}
+}
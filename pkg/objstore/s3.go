@@ -0,0 +1,210 @@
+// Package objstore 把文件上传到对象存储，目前支持 S3 及兼容 S3 API 的服务（MinIO、Ceph RGW 等）
+package objstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config S3 连接配置，为空的字段从标准的 AWS_* 环境变量读取
+type S3Config struct {
+	Endpoint        string `json:"endpoint"`          // 服务地址，如 "https://s3.us-east-1.amazonaws.com" 或 "http://minio:9000"，默认按区域生成 AWS 地址
+	Region          string `json:"region"`            // 区域，默认 AWS_REGION，都为空时为 "us-east-1"
+	AccessKeyID     string `json:"access_key_id"`     // 默认 AWS_ACCESS_KEY_ID
+	SecretAccessKey string `json:"secret_access_key"` // 默认 AWS_SECRET_ACCESS_KEY
+	SessionToken    string `json:"session_token"`     // 临时凭证的会话令牌，默认 AWS_SESSION_TOKEN
+	PathStyle       bool   `json:"path_style"`        // 使用 endpoint/bucket/key 形式的地址（MinIO 等需要），默认使用虚拟主机形式 bucket.endpoint/key
+}
+
+// MaxPutSize 单次 PUT 上传的对象大小上限（S3 的限制）
+const MaxPutSize = 5 << 30
+
+// S3Client 使用 AWS Signature Version 4 签名请求的最小 S3 客户端
+type S3Client struct {
+	endpoint  *url.URL
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	pathStyle bool
+	http      *http.Client
+	now       func() time.Time
+}
+
+// NewS3Client 创建 S3 客户端，cfg 为 nil 时只使用环境变量
+func NewS3Client(cfg *S3Config) (*S3Client, error) {
+	if cfg == nil {
+		cfg = &S3Config{}
+	}
+	pick := func(value, env string) string {
+		if value != "" {
+			return value
+		}
+		return os.Getenv(env)
+	}
+	c := &S3Client{
+		region:    pick(cfg.Region, "AWS_REGION"),
+		accessKey: pick(cfg.AccessKeyID, "AWS_ACCESS_KEY_ID"),
+		secretKey: pick(cfg.SecretAccessKey, "AWS_SECRET_ACCESS_KEY"),
+		token:     pick(cfg.SessionToken, "AWS_SESSION_TOKEN"),
+		pathStyle: cfg.PathStyle,
+		http:      http.DefaultClient,
+		now:       time.Now,
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("s3: no credentials: set access_key_id and secret_access_key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	endpoint := pick(cfg.Endpoint, "AWS_ENDPOINT_URL")
+	if endpoint == "" {
+		endpoint = "https://s3." + c.region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("s3: invalid endpoint %q", endpoint)
+	}
+	c.endpoint = u
+	return c, nil
+}
+
+// ParseURL 解析 s3://bucket/key 形式的地址
+func ParseURL(target string) (bucket, key string, ok bool) {
+	rest, found := strings.CutPrefix(target, "s3://")
+	if !found {
+		return "", "", false
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", false
+	}
+	return bucket, key, true
+}
+
+// IsURL 判断目标是否为 s3:// 地址
+func IsURL(target string) bool {
+	return strings.HasPrefix(target, "s3://")
+}
+
+// PutObject 上传对象，body 的内容先计算 SHA-256 用于签名，再从头读取上传，size 为内容长度
+func (c *S3Client) PutObject(ctx context.Context, bucket, key string, body io.ReadSeeker, size int64) error {
+	if size > MaxPutSize {
+		return fmt.Errorf("s3: object of %d bytes exceeds the single upload limit of %d bytes", size, int64(MaxPutSize))
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	u := *c.endpoint
+	escapedKey := escapePath(key)
+	if c.pathStyle {
+		prefix := strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/"
+		u.Path = prefix + key
+		u.RawPath = prefix + escapedKey
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + key
+		u.RawPath = "/" + escapedKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	c.sign(req, hex.EncodeToString(hash.Sum(nil)))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3: put s3://%s/%s: %w", bucket, key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("s3: put s3://%s/%s: %s: %s", bucket, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign 按 AWS Signature Version 4 为请求添加 Authorization 等头
+func (c *S3Client) sign(req *http.Request, payloadHash string) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.token != "" {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Del("Host")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath 按 SigV4 的要求编码对象键：除未保留字符和 '/' 外都做百分号编码
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		ch := key[i]
+		if ('A' <= ch && ch <= 'Z') || ('a' <= ch && ch <= 'z') || ('0' <= ch && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || ch == '/' {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
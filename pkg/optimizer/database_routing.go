@@ -0,0 +1,239 @@
+package optimizer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/dataaccess"
+	"github.com/kasuganosora/sqlexec/pkg/executor"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== 按数据库路由数据源 ====================
+// 每个已注册的数据源是一个数据库：未限定的表名解析到当前数据库（USE 切换），
+// db.table 解析到对应数据库的数据源；JOIN 的各表分别解析，可以跨数据源
+
+// queryEngine 绑定到某个数据源的优化器和计划执行器
+type queryEngine struct {
+	optimizer    *EnhancedOptimizer
+	planExecutor executor.Executor
+}
+
+// errUnknownDatabase 未知数据库（MySQL 错误码 1049）
+func errUnknownDatabase(name string) error {
+	return mysqlerrors.New(mysqlerrors.ErrBadDB, "Unknown database '%s'", name)
+}
+
+// currentDataSource 返回当前数据库的数据源，当前数据库未注册时使用会话的数据源
+func (e *OptimizedExecutor) currentDataSource() domain.DataSource {
+	if e.dsManager != nil && e.currentDB != "" {
+		if ds, err := e.dsManager.Get(e.currentDB); err == nil {
+			return ds
+		}
+	}
+	return e.dataSource
+}
+
+// failoverRoute 故障切换路由：对数据库 primary 的表访问改由 backup 数据源提供
+type failoverRoute struct {
+	primary string
+	backup  string
+}
+
+// WithDataSourceFailover 将本次执行中对数据库 primary 的表访问路由到备用数据源 backup，
+// 用于主数据源连接中断后在副本上重试 SELECT
+func WithDataSourceFailover(ctx context.Context, primary, backup string) context.Context {
+	return context.WithValue(ctx, failoverKey, failoverRoute{primary: primary, backup: backup})
+}
+
+// failoverDataSource 返回数据库 database 在故障切换后使用的备用数据源，未切换时返回 nil
+func (e *OptimizedExecutor) failoverDataSource(ctx context.Context, database string) domain.DataSource {
+	route, ok := ctx.Value(failoverKey).(failoverRoute)
+	if !ok || e.dsManager == nil {
+		return nil
+	}
+	if database == "" {
+		database = e.currentDB
+	}
+	if database != route.primary {
+		return nil
+	}
+	ds, err := e.dsManager.Get(route.backup)
+	if err != nil {
+		return nil
+	}
+	return ds
+}
+
+// WithTableOverrides 本次执行中未限定库名的表 tables 的键改由对应的数据源提供，
+// 用于把表函数等语句内生成的结果集作为表与普通表一起查询。ctx 中已有的覆盖保留，同名的表以 tables 为准
+func WithTableOverrides(ctx context.Context, tables map[string]domain.DataSource) context.Context {
+	if outer, _ := ctx.Value(tableOverrideKey).(map[string]domain.DataSource); len(outer) > 0 {
+		merged := make(map[string]domain.DataSource, len(outer)+len(tables))
+		for name, ds := range outer {
+			merged[name] = ds
+		}
+		for name, ds := range tables {
+			merged[name] = ds
+		}
+		tables = merged
+	}
+	return context.WithValue(ctx, tableOverrideKey, tables)
+}
+
+// overrideDataSource 返回本次执行中提供表 table 的数据源，没有覆盖时返回 nil
+func overrideDataSource(ctx context.Context, table string) domain.DataSource {
+	tables, _ := ctx.Value(tableOverrideKey).(map[string]domain.DataSource)
+	return tables[table]
+}
+
+// resolveTable 返回表所在的数据源和去掉库名后的表名
+// database 为空时使用当前数据库；库名未注册时，若当前数据源中存在同名（含点号）的表则按原样使用
+func (e *OptimizedExecutor) resolveTable(ctx context.Context, database, table string) (domain.DataSource, string, error) {
+	if database == "" {
+		if ds := overrideDataSource(ctx, table); ds != nil {
+			return ds, table, nil
+		}
+	}
+	if ds := e.failoverDataSource(ctx, database); ds != nil {
+		return ds, table, nil
+	}
+	current := e.currentDataSource()
+	if database == "" || e.dsManager == nil {
+		if e.dsManager != nil {
+			// 被健康检查隔离的数据源立即失败，不等待后端
+			if err := e.dsManager.CheckQuarantine(e.currentDB); err != nil {
+				return nil, "", err
+			}
+		}
+		return current, table, nil
+	}
+	if ds, err := e.dsManager.Get(database); err == nil {
+		if err := e.dsManager.CheckQuarantine(database); err != nil {
+			return nil, "", err
+		}
+		return ds, table, nil
+	}
+	if _, err := current.GetTableInfo(ctx, database+"."+table); err == nil {
+		return current, database + "." + table, nil
+	}
+	return nil, "", errUnknownDatabase(database)
+}
+
+// resolveQualifiedTable 同 resolveTable，表名可以是 db.table 形式
+func (e *OptimizedExecutor) resolveQualifiedTable(ctx context.Context, name string) (domain.DataSource, string, error) {
+	if i := strings.Index(name, "."); i > 0 {
+		return e.resolveTable(ctx, name[:i], name[i+1:])
+	}
+	return e.resolveTable(ctx, "", name)
+}
+
+// engineFor 返回数据源对应的优化器和执行器，会话数据源之外的按需创建并缓存；
+// 本次执行中覆盖表的数据源只在语句内使用，不缓存
+func (e *OptimizedExecutor) engineFor(ctx context.Context, table string, ds domain.DataSource) *queryEngine {
+	if ds == e.dataSource {
+		return &queryEngine{optimizer: e.optimizer, planExecutor: e.planExecutor}
+	}
+	if overrideDataSource(ctx, table) == ds {
+		return newQueryEngine(ds)
+	}
+	e.enginesMu.Lock()
+	defer e.enginesMu.Unlock()
+	if engine, ok := e.engines[ds]; ok {
+		return engine
+	}
+	engine := newQueryEngine(ds)
+	if e.engines == nil {
+		e.engines = make(map[domain.DataSource]*queryEngine)
+	}
+	e.engines[ds] = engine
+	return engine
+}
+
+// newQueryEngine 创建绑定到数据源的优化器和执行器
+func newQueryEngine(ds domain.DataSource) *queryEngine {
+	return &queryEngine{
+		optimizer:    NewEnhancedOptimizer(ds, 0),
+		planExecutor: executor.NewExecutor(dataaccess.NewDataService(ds)),
+	}
+}
+
+// qualifiedName 拼接库名和表名
+func qualifiedName(database, table string) string {
+	if database == "" {
+		return table
+	}
+	return database + "." + table
+}
+
+// routedTable 跨数据库 JOIN 中的一张表：所在的数据源和去掉库名后的表名
+type routedTable struct {
+	ds    domain.DataSource
+	table string
+}
+
+// crossDatabaseDataSource 跨数据库 JOIN 的读取视图，按 db.table 将读取转发到表所在的数据源，
+// 其余操作使用 FROM 表的数据源
+type crossDatabaseDataSource struct {
+	domain.DataSource
+	tables map[string]routedTable
+}
+
+func (d *crossDatabaseDataSource) GetTableInfo(ctx context.Context, tableName string) (*domain.TableInfo, error) {
+	if t, ok := d.tables[tableName]; ok {
+		return t.ds.GetTableInfo(ctx, t.table)
+	}
+	return d.DataSource.GetTableInfo(ctx, tableName)
+}
+
+func (d *crossDatabaseDataSource) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	if t, ok := d.tables[tableName]; ok {
+		return t.ds.Query(ctx, t.table, options)
+	}
+	return d.DataSource.Query(ctx, tableName, options)
+}
+
+// routeCrossDatabaseJoin 解析 FROM 和各 JOIN 表所在的数据源，表分布在多个数据源时
+// 返回去掉库名的语句和按表名转发读取的视图；不同库的同名表以 db.table 区分。
+// 都在同一数据源时返回 nil
+func (e *OptimizedExecutor) routeCrossDatabaseJoin(ctx context.Context, stmt *parser.SelectStatement) (*parser.SelectStatement, domain.DataSource, error) {
+	if len(stmt.Joins) == 0 || (e.dsManager == nil && ctx.Value(tableOverrideKey) == nil) {
+		return nil, nil, nil
+	}
+	fromDS, fromTable, err := e.resolveQualifiedTable(ctx, stmt.From)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	routed := *stmt
+	routed.From = fromTable
+	routed.Joins = make([]parser.JoinInfo, len(stmt.Joins))
+	tables := map[string]routedTable{fromTable: {ds: fromDS, table: fromTable}}
+	cross := false
+	for i, join := range stmt.Joins {
+		ds, table, err := e.resolveTable(ctx, join.Database, join.Table)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ds != fromDS {
+			cross = true
+		}
+		name := table
+		if t, ok := tables[name]; ok && (t.ds != ds || t.table != table) {
+			database := join.Database
+			if database == "" {
+				database = e.currentDB
+			}
+			name = qualifiedName(database, table)
+		}
+		routed.Joins[i] = join
+		routed.Joins[i].Table = name
+		tables[name] = routedTable{ds: ds, table: table}
+	}
+	if !cross {
+		return nil, nil, nil
+	}
+	return &routed, &crossDatabaseDataSource{DataSource: fromDS, tables: tables}, nil
+}
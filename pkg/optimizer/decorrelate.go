@@ -287,6 +287,7 @@ func (r *DecorrelateRule) aggregationSubquery(apply *LogicalApply, agg *LogicalA
 
 	// Add Aggregate on top (note: NewLogicalAggregate expects (aggFuncs, groupByCols, child))
 	newAgg := NewLogicalAggregate(agg.GetAggFuncs(), groupByCols, join)
+	newAgg.SetGroupByExprs(agg.GetGroupByExprs())
 	return newAgg, nil
 }
 
@@ -213,6 +213,7 @@ func (r *EnhancedColumnPruningRule) pruneDataSource(dataSource *LogicalDataSourc
 	newDataSource := NewLogicalDataSource(dataSource.TableName, dataSource.TableInfo)
 	newDataSource.Columns = newColumns
 	newDataSource.Statistics = dataSource.Statistics
+	newDataSource.Sample = dataSource.Sample
 	newDataSource.PushDownPredicates(dataSource.GetPushedDownPredicates())
 
 	if limitInfo := dataSource.GetPushedDownLimit(); limitInfo != nil {
@@ -16,7 +16,6 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/types"
-	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
 // EnhancedOptimizer 增强的优化器
@@ -277,26 +276,7 @@ func (eo *EnhancedOptimizer) Optimize(ctx context.Context, stmt *parser.SQLState
 	debugln("=== Enhanced Optimizer Started ===")
 
 	// 1. 解析 Hints（如果 SQL 中有）
-	var hints *OptimizerHints
-	if stmt != nil && stmt.RawSQL != "" {
-		parsedHints, cleanSQLStr, err := eo.hintsParser.ExtractHintsFromSQL(stmt.RawSQL)
-		if err != nil {
-			debugf("  [HINTS] Warning: Failed to parse hints: %v\n", err)
-			hints = &OptimizerHints{} // 使用空 hints 继续
-		} else {
-			// 转换 ParsedHints 为 OptimizerHints
-			hints = convertParsedHints(parsedHints)
-			if hints != nil {
-				debugf("  [HINTS] Parsed hints from SQL\n")
-			}
-			// 更新 SQL（去除 hints）
-			if cleanSQLStr != "" {
-				stmt.RawSQL = cleanSQLStr
-			}
-		}
-	} else {
-		hints = &OptimizerHints{} // 使用空 hints
-	}
+	hints := eo.parseStatementHints(stmt)
 
 	// 2. 转换为逻辑计划
 	logicalPlan, err := eo.baseOptimizer.convertToLogicalPlan(stmt)
@@ -374,7 +354,7 @@ func (eo *EnhancedOptimizer) applyEnhancedRules(ctx context.Context, plan Logica
 func (eo *EnhancedOptimizer) convertToPlanEnhanced(ctx context.Context, logicalPlan LogicalPlan, optCtx *OptimizationContext) (*plan.Plan, error) {
 	switch p := logicalPlan.(type) {
 	case *LogicalDataSource:
-		return eo.convertDataSourceEnhanced(ctx, p)
+		return eo.convertDataSourceEnhanced(ctx, p, optCtx)
 	case *LogicalSelection:
 		return eo.convertSelectionEnhanced(ctx, p, optCtx)
 	case *LogicalProjection:
@@ -401,7 +381,7 @@ func (eo *EnhancedOptimizer) convertToPlanEnhanced(ctx context.Context, logicalP
 }
 
 // convertDataSourceEnhanced 转换数据源（增强版）
-func (eo *EnhancedOptimizer) convertDataSourceEnhanced(ctx context.Context, p *LogicalDataSource) (*plan.Plan, error) {
+func (eo *EnhancedOptimizer) convertDataSourceEnhanced(ctx context.Context, p *LogicalDataSource, optCtx *OptimizationContext) (*plan.Plan, error) {
 	tableName := p.TableName
 
 	// 应用索引选择
@@ -446,6 +426,16 @@ func (eo *EnhancedOptimizer) convertDataSourceEnhanced(ctx context.Context, p *L
 	// 更新成本
 	scanCost := eo.costModel.ScanCost(tableName, 10000, useIndex) // 使用默认估算
 
+	// 索引 hint：USE_INDEX 在数据源中与 FORCE_INDEX 同样作为首选索引传递
+	forceIndex := p.ForcedIndex()
+	if forceIndex == "" {
+		forceIndex = p.PreferredIndex()
+	}
+	parallelism := 0
+	if optCtx != nil && optCtx.Hints != nil {
+		parallelism = optCtx.Hints.Parallel
+	}
+
 	return &plan.Plan{
 		ID:           fmt.Sprintf("scan_%s", tableName),
 		Type:         plan.TypeTableScan,
@@ -455,9 +445,13 @@ func (eo *EnhancedOptimizer) convertDataSourceEnhanced(ctx context.Context, p *L
 			TableName:       tableName,
 			Columns:         columns,
 			Filters:         filters,
-			LimitInfo:       &types.LimitInfo{Limit: 0, Offset: 0},
+			LimitInfo:       convertToTypesLimitInfo(p.GetPushedDownLimit()),
 			EnableParallel:  true,
 			MinParallelRows: 100,
+			ForceIndex:      forceIndex,
+			IgnoreIndexes:   p.IgnoredIndexes(),
+			Parallelism:     parallelism,
+			Sample:          p.Sample,
 		},
 		EstimatedCost: scanCost,
 	}, nil
@@ -484,7 +478,7 @@ func (eo *EnhancedOptimizer) convertSelectionEnhanced(ctx context.Context, p *Lo
 		OutputSchema: child.OutputSchema,
 		Children:     []*plan.Plan{child},
 		Config: &plan.SelectionConfig{
-			Condition: p.GetConditions()[0],
+			Condition: combineConditions(p.GetConditions()),
 		},
 	}, nil
 }
@@ -717,6 +711,45 @@ func convertToTypesExprRecursive(expr *parser.Expression) *types.Expression {
 	}
 }
 
+// convertToTypesAggFuncs 将聚合项转换为执行器使用的 types.AggregationItem
+func convertToTypesAggFuncs(aggFuncs []*AggregationItem) []*types.AggregationItem {
+	converted := make([]*types.AggregationItem, len(aggFuncs))
+	for i, agg := range aggFuncs {
+		item := &types.AggregationItem{
+			Type:      types.AggregationType(agg.Type),
+			Expr:      convertToTypesExpr(agg.Expr),
+			Alias:     agg.Alias,
+			Distinct:  agg.Distinct,
+			Separator: agg.Separator,
+			Params:    agg.Params,
+		}
+		for _, ob := range agg.OrderBy {
+			item.OrderBy = append(item.OrderBy, types.AggregationOrder{
+				Column: ob.Column,
+				Desc:   strings.EqualFold(ob.Direction, "DESC"),
+			})
+		}
+		converted[i] = item
+	}
+	return converted
+}
+
+// aggArgExprs 收集参数为函数或运算表达式的聚合函数（如 SUM(CASE WHEN ... END)），
+// types.Expression 不能表示这类参数，由执行器按 parser.Expression 求值
+func aggArgExprs(aggFuncs []*AggregationItem) map[string]*parser.Expression {
+	var exprs map[string]*parser.Expression
+	for _, agg := range aggFuncs {
+		if agg.Expr == nil || (agg.Expr.Type != parser.ExprTypeFunction && agg.Expr.Type != parser.ExprTypeOperator) {
+			continue
+		}
+		if exprs == nil {
+			exprs = make(map[string]*parser.Expression)
+		}
+		exprs[agg.Alias] = agg.Expr
+	}
+	return exprs
+}
+
 // convertAggregateEnhanced 转换聚合（增强版）
 func (eo *EnhancedOptimizer) convertAggregateEnhanced(ctx context.Context, p *LogicalAggregate, optCtx *OptimizationContext) (*plan.Plan, error) {
 	if len(p.Children()) == 0 {
@@ -735,25 +768,16 @@ func (eo *EnhancedOptimizer) convertAggregateEnhanced(ctx context.Context, p *Lo
 	// 计算成本
 	_ = eo.costModel.AggregateCost(int64(10000), len(groupByCols), len(aggFuncs))
 
-	// 转换aggFuncs到types.AggregationItem
-	convertedAggFuncs := make([]*types.AggregationItem, len(aggFuncs))
-	for i, agg := range aggFuncs {
-		convertedAggFuncs[i] = &types.AggregationItem{
-			Type:     types.AggregationType(agg.Type),
-			Expr:     convertToTypesExpr(agg.Expr),
-			Alias:    agg.Alias,
-			Distinct: agg.Distinct,
-		}
-	}
-
 	return &plan.Plan{
 		ID:           fmt.Sprintf("agg_%d_%d", len(groupByCols), len(aggFuncs)),
 		Type:         plan.TypeAggregate,
 		OutputSchema: child.OutputSchema,
 		Children:     []*plan.Plan{child},
 		Config: &plan.AggregateConfig{
-			GroupByCols: groupByCols,
-			AggFuncs:    convertedAggFuncs,
+			GroupByCols:  groupByCols,
+			GroupByExprs: p.GetGroupByExprs(),
+			AggFuncs:     convertToTypesAggFuncs(aggFuncs),
+			AggArgExprs:  aggArgExprs(aggFuncs),
 		},
 	}, nil
 }
@@ -902,6 +926,10 @@ func (a *DPJoinReorderAdapter) Apply(ctx context.Context, plan LogicalPlan, optC
 	if !ok {
 		return plan, nil
 	}
+	if optCtx != nil && optCtx.Hints.JoinOrderFixed() {
+		debugln("  [DP REORDER] STRAIGHT_JOIN hint present, keeping written JOIN order")
+		return plan, nil
+	}
 
 	debugln("  [DP REORDER] Starting DP JOIN reorder optimization")
 
@@ -1139,43 +1167,31 @@ func convertPredicatesToFilters(conditions []*parser.Expression) []domain.Filter
 
 // expressionToFilter converts a parser expression to a domain filter
 func expressionToFilter(expr *parser.Expression) *domain.Filter {
-	if expr == nil || expr.Type != parser.ExprTypeOperator {
+	if expr == nil || requiresInEngineEvaluation(expr) {
 		return nil
 	}
-
-	// Handle unary operators (IS NULL / IS NOT NULL)
-	if expr.Left != nil && expr.Right == nil {
-		op := strings.ToLower(expr.Operator)
-		if op == "is null" || op == "isnull" || op == "is not null" || op == "isnotnull" {
-			// Left side must be column name
-			if expr.Left.Type == parser.ExprTypeColumn && expr.Left.Column != "" {
-				return &domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: expr.Operator,
-					Value:    nil,
-				}
-			}
-		}
+	filter, ok := parser.ToFilter(expr)
+	if !ok {
+		return nil
 	}
+	return &filter
+}
 
-	// Handle binary comparison expressions
-	if expr.Left != nil && expr.Right != nil && expr.Operator != "" {
-		// Left side is column name
-		if expr.Left.Type == parser.ExprTypeColumn && expr.Left.Column != "" {
-			// Right side is constant value
-			if expr.Right.Type == parser.ExprTypeValue {
-				// Use MapOperator to normalize operator (handles like, not like, etc.)
-				operator := utils.MapOperator(expr.Operator)
-				return &domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: operator,
-					Value:    expr.Right.Value,
-				}
-			}
-		}
+// requiresInEngineEvaluation reports whether a predicate depends on options that
+// datasource filters cannot carry (ESCAPE, COLLATE, ILIKE, REGEXP). Such
+// predicates stay in the Selection operator instead of being pushed down.
+func requiresInEngineEvaluation(expr *parser.Expression) bool {
+	if expr.Escape != "" {
+		return true
 	}
-
-	return nil
+	if (expr.Left != nil && expr.Left.Collation != "") || (expr.Right != nil && expr.Right.Collation != "") {
+		return true
+	}
+	switch strings.ToUpper(expr.Operator) {
+	case "ILIKE", "NOT ILIKE", "REGEXP", "NOT REGEXP", "RLIKE", "NOT RLIKE":
+		return true
+	}
+	return false
 }
 
 func convertAggFuncs(aggItems []*AggregationItem) []*AggregationItem {
@@ -1258,6 +1274,57 @@ func expressionToString(expr *parser.Expression) string {
 }
 
 // convertParsedHints 将 parser.ParsedHints 转换为 optimizer.OptimizerHints
+// ParseStatementHints 解析语句中的优化器 hints
+// SELECT 语句优先使用解析器提取的 hints（包括 STRAIGHT_JOIN 与 USE/FORCE/IGNORE INDEX 子句），
+// 否则从 RawSQL 中的 /*+ ... */ 注释提取。没有 hints 时返回空的 OptimizerHints
+func ParseStatementHints(stmt *parser.SQLStatement) *OptimizerHints {
+	return parseStatementHints(defaultHintsParser, stmt)
+}
+
+var defaultHintsParser = parser.NewHintsParser()
+
+// parseStatementHints 使用优化器自身的 hints 解析器解析语句 hints，并去除 RawSQL 中的 hint 注释
+func (eo *EnhancedOptimizer) parseStatementHints(stmt *parser.SQLStatement) *OptimizerHints {
+	hp := eo.hintsParser
+	if hp == nil {
+		hp = defaultHintsParser
+	}
+	hints := parseStatementHints(hp, stmt)
+	if stmt != nil && stmt.RawSQL != "" {
+		if _, cleanSQLStr, err := hp.ExtractHintsFromSQL(stmt.RawSQL); err == nil && cleanSQLStr != "" {
+			stmt.RawSQL = cleanSQLStr
+		}
+	}
+	return hints
+}
+
+func parseStatementHints(hp *parser.HintsParser, stmt *parser.SQLStatement) *OptimizerHints {
+	if stmt == nil {
+		return &OptimizerHints{}
+	}
+
+	var parsedHints *parser.ParsedHints
+	var err error
+	if stmt.Select != nil && stmt.Select.Hints != "" {
+		parsedHints, err = hp.ParseFromComment(stmt.Select.Hints)
+	} else if stmt.RawSQL != "" {
+		parsedHints, _, err = hp.ExtractHintsFromSQL(stmt.RawSQL)
+	}
+	if err != nil {
+		debugf("  [HINTS] Warning: Failed to parse hints: %v\n", err)
+		return &OptimizerHints{}
+	}
+
+	hints := convertParsedHints(parsedHints)
+	if stmt.Select != nil && stmt.Select.StraightJoin {
+		hints.StraightJoin = true
+	}
+	if parsedHints != nil {
+		debugf("  [HINTS] Parsed hints: %s\n", parsedHints.String())
+	}
+	return hints
+}
+
 func convertParsedHints(ph *parser.ParsedHints) *OptimizerHints {
 	if ph == nil {
 		return &OptimizerHints{}
@@ -1300,6 +1367,8 @@ func convertParsedHints(ph *parser.ParsedHints) *OptimizerHints {
 		MemoryQuota:           ph.MemoryQuota,
 		ReadConsistentReplica: ph.ReadConsistentReplica,
 		ResourceGroup:         ph.ResourceGroup,
+		Parallel:              ph.Parallel,
+		Warnings:              ph.Warnings,
 	}
 }
 
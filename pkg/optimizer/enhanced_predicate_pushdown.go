@@ -65,13 +65,8 @@ func (r *EnhancedPredicatePushdownRule) Apply(ctx context.Context, plan LogicalP
 		}
 	}
 
-	// 3. 处理OR条件，转换为UNION
-	if orPlan := r.tryConvertORToUnion(selection); orPlan != nil {
-		debugln("  [PUSHDOWN] Converted OR to UNION")
-		return orPlan, nil
-	}
-
-	// 4. 合并相邻Selection
+	// 3. 合并相邻Selection
+	// OR 条件不转换为 UNION（见 EnhancedRuleSet），由 Selection 算子求值
 	merged := r.mergeAdjacentSelections(selection)
 	if merged != selection {
 		return merged, nil
@@ -123,12 +118,17 @@ func (r *EnhancedPredicatePushdownRule) tryPushDownToDataSource(selection *Logic
 }
 
 // canPushDownToDataSource 检查条件是否可以下推到DataSource
-// ALL referenced columns must be present in the DataSource schema
+// ALL referenced columns must be present in the DataSource schema, and the
+// condition must be expressible as a datasource filter; otherwise it would be
+// silently dropped (e.g. OR, NOT, REGEXP, arithmetic on columns)
 func (r *EnhancedPredicatePushdownRule) canPushDownToDataSource(cond *parser.Expression, dataSource *LogicalDataSource) bool {
 	schema := dataSource.Schema()
 	if len(schema) == 0 {
 		return false
 	}
+	if expressionToFilter(cond) == nil {
+		return false
+	}
 
 	// 检查所有引用的列是否都在DataSource中
 	cols := r.extractColumnsFromExpression(cond)
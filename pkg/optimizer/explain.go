@@ -1,6 +1,9 @@
 package optimizer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"strings"
 
 	"github.com/kasuganosora/sqlexec/pkg/optimizer/plan"
@@ -45,3 +48,31 @@ func explainPlanV2(p *plan.Plan, depth int) string {
 
 	return builder.String()
 }
+
+// PlanDigest 返回执行计划结构的摘要（64 位十六进制字符串）
+// 只取算子类型、连接顺序和扫描的表及指定索引，LIMIT 值等字面量不影响摘要，
+// 同一语句的计划摘要发生变化说明执行计划变了
+func PlanDigest(p *plan.Plan) string {
+	h := sha256.New()
+	writePlanShape(h, p, 0)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writePlanShape 按先序写出计划树的结构
+func writePlanShape(w io.Writer, p *plan.Plan, depth int) {
+	if p == nil {
+		return
+	}
+	io.WriteString(w, strings.Repeat(" ", depth))
+	io.WriteString(w, string(p.Type))
+	if scan, ok := p.Config.(*plan.TableScanConfig); ok {
+		io.WriteString(w, " "+scan.TableName)
+		if scan.ForceIndex != "" {
+			io.WriteString(w, " index="+scan.ForceIndex)
+		}
+	}
+	io.WriteString(w, "\n")
+	for _, child := range p.Children {
+		writePlanShape(w, child, depth+1)
+	}
+}
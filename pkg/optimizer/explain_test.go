@@ -91,3 +91,31 @@ func TestExplainPlanV2(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanDigest(t *testing.T) {
+	build := func(limit, first, second string) *plan.Plan {
+		return &plan.Plan{ID: limit, Type: plan.TypeLimit, Children: []*plan.Plan{{
+			ID: "join_1", Type: plan.TypeHashJoin, Children: []*plan.Plan{
+				{ID: "scan_" + first, Type: plan.TypeTableScan, Config: &plan.TableScanConfig{TableName: first}},
+				{ID: "scan_" + second, Type: plan.TypeTableScan, Config: &plan.TableScanConfig{TableName: second}},
+			},
+		}}}
+	}
+
+	digest := PlanDigest(build("limit_10_0", "orders", "users"))
+	if len(digest) != 64 {
+		t.Fatalf("expected a 64 character digest, got %q", digest)
+	}
+	if got := PlanDigest(build("limit_20_0", "orders", "users")); got != digest {
+		t.Error("LIMIT values should not change the plan digest")
+	}
+	if got := PlanDigest(build("limit_10_0", "users", "orders")); got == digest {
+		t.Error("a different join order should change the plan digest")
+	}
+
+	indexed := build("limit_10_0", "orders", "users")
+	indexed.Children[0].Children[0].Config.(*plan.TableScanConfig).ForceIndex = "idx_user"
+	if got := PlanDigest(indexed); got == digest {
+		t.Error("a forced index should change the plan digest")
+	}
+}
@@ -2,6 +2,7 @@ package optimizer
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
 
@@ -191,19 +192,37 @@ func (e *ExpressionEvaluator) evaluateOperator(expr *parser.Expression, row pars
 		return nil, fmt.Errorf("right operand evaluation failed: %w", err)
 	}
 
+	op := strings.ToLower(expr.Operator)
+
+	// 比较运算中任一操作数为 NULL 时结果为 NULL（UNKNOWN），<=> 除外
+	switch op {
+	case "<=>", "nulleq":
+		if left == nil || right == nil {
+			return left == nil && right == nil, nil
+		}
+		return utils.CompareValuesForSort(left, right) == 0, nil
+	case "=", "eq", "!=", "<>", "neq", "ne", ">", "gt", ">=", "gte", "ge", "<", "lt", "<=", "lte", "le",
+		"like", "not like", "ilike", "not ilike", "regexp", "not regexp", "rlike", "not rlike",
+		"+", "plus", "-", "minus", "*", "mul", "/", "div", "%", "mod":
+		// 算术运算同样传播 NULL
+		if left == nil || right == nil {
+			return nil, nil
+		}
+	}
+
 	// 根据运算符类型计算
-	switch strings.ToLower(expr.Operator) {
+	switch op {
 	case "=", "eq":
 		return utils.CompareValuesForSort(left, right) == 0, nil
-	case "!=", "<>", "neq":
+	case "!=", "<>", "neq", "ne":
 		return utils.CompareValuesForSort(left, right) != 0, nil
 	case ">", "gt":
 		return utils.CompareValuesForSort(left, right) > 0, nil
-	case ">=", "gte":
+	case ">=", "gte", "ge":
 		return utils.CompareValuesForSort(left, right) >= 0, nil
 	case "<", "lt":
 		return utils.CompareValuesForSort(left, right) < 0, nil
-	case "<=", "lte":
+	case "<=", "lte", "le":
 		return utils.CompareValuesForSort(left, right) <= 0, nil
 	case "+", "plus":
 		return e.addValues(left, right)
@@ -213,56 +232,71 @@ func (e *ExpressionEvaluator) evaluateOperator(expr *parser.Expression, row pars
 		return e.mulValues(left, right)
 	case "/", "div":
 		return e.divValues(left, right)
-	case "like":
-		return e.likeValues(left, right), nil
-	case "not like":
-		return !e.likeValues(left, right), nil
+	case "%", "mod":
+		return e.modValues(left, right)
+	case "like", "ilike":
+		return e.likeValues(expr, left, right), nil
+	case "not like", "not ilike":
+		return !e.likeValues(expr, left, right), nil
+	case "regexp", "rlike", "not regexp", "not rlike":
+		matched, err := utils.MatchesRegexp(utils.ToString(left), utils.ToString(right), operandCollation(expr))
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(op, "not ") {
+			return !matched, nil
+		}
+		return matched, nil
 	case "in":
-		return e.inValues(left, right), nil
+		return triValue(e.inValues(left, right)), nil
 	case "not in":
-		return !e.inValues(left, right), nil
-	case "between":
-		if vals, ok := right.([]interface{}); ok && len(vals) == 2 {
-			return e.betweenValues(left, vals[0], vals[1]), nil
+		return triValue(e.inValues(left, right).Not()), nil
+	case "between", "not between":
+		vals, ok := right.([]interface{})
+		if !ok || len(vals) != 2 {
+			return false, nil
 		}
-		return false, nil
+		result := e.betweenValues(left, vals[0], vals[1])
+		if op == "not between" {
+			result = result.Not()
+		}
+		return triValue(result), nil
 	default:
 		return nil, fmt.Errorf("unsupported operator: %s", expr.Operator)
 	}
 }
 
-// evaluateLogicalOp 计算逻辑运算符
+// evaluateLogicalOp 计算逻辑运算符（三值逻辑：FALSE AND NULL = FALSE，TRUE OR NULL = TRUE，其余含 NULL 时为 NULL）
 func (e *ExpressionEvaluator) evaluateLogicalOp(expr *parser.Expression, row parser.Row) (any, error) {
 	if expr.Left == nil || expr.Right == nil {
 		return nil, fmt.Errorf("invalid logical operator expression")
 	}
 
-	left, err := e.evaluateInternal(expr.Left, row)
+	leftVal, err := e.evaluateInternal(expr.Left, row)
 	if err != nil {
 		return nil, err
 	}
+	left := e.toTriBool(leftVal)
 
 	// 短路求值
-	if strings.EqualFold(expr.Operator, "and") {
-		if !e.isTrue(left) {
-			return false, nil
-		}
-		right, err := e.evaluateInternal(expr.Right, row)
-		if err != nil {
-			return nil, err
-		}
-		return e.isTrue(right), nil
+	isAnd := strings.EqualFold(expr.Operator, "and")
+	if isAnd && left == utils.TriFalse {
+		return false, nil
 	}
-
-	// OR
-	if e.isTrue(left) {
+	if !isAnd && left == utils.TriTrue {
 		return true, nil
 	}
-	right, err := e.evaluateInternal(expr.Right, row)
+
+	rightVal, err := e.evaluateInternal(expr.Right, row)
 	if err != nil {
 		return nil, err
 	}
-	return e.isTrue(right), nil
+	right := e.toTriBool(rightVal)
+
+	if isAnd {
+		return triValue(left.And(right)), nil
+	}
+	return triValue(left.Or(right)), nil
 }
 
 // evaluateUnaryOp 计算一元运算符
@@ -287,8 +321,8 @@ func (e *ExpressionEvaluator) evaluateUnaryOp(expr *parser.Expression, row parse
 		// 正号
 		return operand, nil
 	case "not":
-		// 逻辑非
-		return !e.isTrue(operand), nil
+		// 逻辑非，NOT NULL 仍为 NULL
+		return triValue(e.toTriBool(operand).Not()), nil
 	case "is null", "isnull":
 		// IS NULL 检查
 		return operand == nil, nil
@@ -437,37 +471,98 @@ func (e *ExpressionEvaluator) divValues(a, b any) (any, error) {
 	return aNum / bNum, nil
 }
 
-// likeValues LIKE 模式匹配
-func (e *ExpressionEvaluator) likeValues(value, pattern interface{}) bool {
-	valStr, ok := value.(string)
-	if !ok {
-		valStr = utils.ToString(value)
+// modValues 取模运算，除数为 0 时结果为 NULL
+func (e *ExpressionEvaluator) modValues(a, b any) (any, error) {
+	aNum, aErr := utils.ToFloat64(a)
+	bNum, bErr := utils.ToFloat64(b)
+	if aErr != nil || bErr != nil {
+		return nil, fmt.Errorf("cannot compute modulo of non-numeric values")
 	}
-	patStr, ok := pattern.(string)
-	if !ok {
-		patStr = utils.ToString(pattern)
+	if bNum == 0 {
+		return nil, nil
+	}
+	return math.Mod(aNum, bNum), nil
+}
+
+// likeValues LIKE / ILIKE 模式匹配，支持 ESCAPE 字符和 COLLATE 指定的排序规则
+func (e *ExpressionEvaluator) likeValues(expr *parser.Expression, value, pattern interface{}) bool {
+	escape := rune(utils.DefaultLikeEscape)
+	if expr.Escape != "" {
+		escape = []rune(expr.Escape)[0]
 	}
-	return utils.MatchesLike(valStr, patStr)
+	collation := operandCollation(expr)
+	if collation == "" && strings.Contains(strings.ToLower(expr.Operator), "ilike") {
+		collation = "utf8mb4_general_ci"
+	}
+	return utils.MatchesLikeWith(utils.ToString(value), utils.ToString(pattern), escape, collation)
+}
+
+// operandCollation 返回比较操作数上通过 COLLATE 指定的排序规则
+func operandCollation(expr *parser.Expression) string {
+	if expr.Right != nil && expr.Right.Collation != "" {
+		return expr.Right.Collation
+	}
+	if expr.Left != nil {
+		return expr.Left.Collation
+	}
+	return ""
 }
 
 // inValues IN 操作
-func (e *ExpressionEvaluator) inValues(value, values any) bool {
+// value 为 NULL，或没有匹配项且列表中含 NULL 时结果为 UNKNOWN
+func (e *ExpressionEvaluator) inValues(value, values any) utils.TriBool {
 	valList, ok := values.([]any)
-	if !ok {
-		return false
+	if !ok || len(valList) == 0 {
+		return utils.TriFalse
+	}
+	if value == nil {
+		return utils.TriUnknown
 	}
 
+	result := utils.TriFalse
 	for _, v := range valList {
+		if v == nil {
+			result = utils.TriUnknown
+			continue
+		}
 		if utils.CompareValuesForSort(value, v) == 0 {
-			return true
+			return utils.TriTrue
 		}
 	}
-	return false
+	return result
+}
+
+// betweenValues BETWEEN 操作，等价于 value >= min AND value <= max
+func (e *ExpressionEvaluator) betweenValues(value, min, max any) utils.TriBool {
+	bound := func(b any, satisfied func(int) bool) utils.TriBool {
+		if value == nil || b == nil {
+			return utils.TriUnknown
+		}
+		return utils.TriBoolOf(satisfied(utils.CompareValuesForSort(value, b)))
+	}
+	lower := bound(min, func(c int) bool { return c >= 0 })
+	upper := bound(max, func(c int) bool { return c <= 0 })
+	return lower.And(upper)
+}
+
+// toTriBool 将表达式结果转换为三值逻辑，NULL 为 UNKNOWN
+func (e *ExpressionEvaluator) toTriBool(value any) utils.TriBool {
+	if value == nil {
+		return utils.TriUnknown
+	}
+	return utils.TriBoolOf(e.isTrue(value))
 }
 
-// betweenValues BETWEEN 操作
-func (e *ExpressionEvaluator) betweenValues(value, min, max any) bool {
-	return utils.CompareValuesForSort(value, min) >= 0 && utils.CompareValuesForSort(value, max) <= 0
+// triValue 将三值逻辑结果转换为表达式值，UNKNOWN 为 NULL
+func triValue(t utils.TriBool) any {
+	switch t {
+	case utils.TriTrue:
+		return true
+	case utils.TriFalse:
+		return false
+	default:
+		return nil
+	}
 }
 
 // isTrue 判断值是否为真
@@ -28,6 +28,28 @@ func (o *Optimizer) extractConditions(expr *parser.Expression) []*parser.Express
 	return []*parser.Expression{expr}
 }
 
+// combineConditions 将条件列表合并为左深 AND 表达式，是 extractConditions 的逆操作
+// Selection 算子只接收单个条件，多个条件必须合并后才能全部生效
+func combineConditions(conditions []*parser.Expression) *parser.Expression {
+	var combined *parser.Expression
+	for _, cond := range conditions {
+		if cond == nil {
+			continue
+		}
+		if combined == nil {
+			combined = cond
+			continue
+		}
+		combined = &parser.Expression{
+			Type:     parser.ExprTypeOperator,
+			Operator: "and",
+			Left:     combined,
+			Right:    cond,
+		}
+	}
+	return combined
+}
+
 // extractAggFuncs 提取聚合函数
 // 从 SELECT 列中识别并提取聚合函数（如 COUNT, SUM, AVG, MAX, MIN）
 func (o *Optimizer) extractAggFuncs(cols []parser.SelectColumn) []*AggregationItem {
@@ -50,9 +72,12 @@ func (o *Optimizer) extractAggFuncs(cols []parser.SelectColumn) []*AggregationIt
 			if col.Alias != "" {
 				aggItem.Alias = col.Alias
 			} else {
-				// 生成默认别名（如 "COUNT_id", "SUM_amount"）
-				aggItem.Alias = fmt.Sprintf("%s_%s", aggItem.Type.String(),
-					o.expressionToString(aggItem.Expr))
+				// 生成默认别名（如 "COUNT_id", "SUM_amount", "COUNT_*"）
+				argStr := o.expressionToString(aggItem.Expr)
+				if aggItem.Type == Count && aggItem.Expr != nil && aggItem.Expr.Type == parser.ExprTypeValue {
+					argStr = "*"
+				}
+				aggItem.Alias = fmt.Sprintf("%s_%s", aggItem.Type.String(), argStr)
 			}
 			aggFuncs = append(aggFuncs, aggItem)
 		}
@@ -76,6 +101,8 @@ func (o *Optimizer) parseAggregationFunction(expr *parser.Expression) *Aggregati
 		// 假设表达式中有 FunctionName 和 Args 字段
 		if name, ok := expr.Value.(string); ok {
 			funcName = name
+		} else if parser.IsTimeSeriesAggregate(expr.Function) {
+			funcName = expr.Function
 		}
 		funcExpr = expr
 	} else if expr.Type == parser.ExprTypeColumn {
@@ -107,18 +134,75 @@ func (o *Optimizer) parseAggregationFunction(expr *parser.Expression) *Aggregati
 		aggType = Max
 	case "MIN":
 		aggType = Min
+	case "GROUP_CONCAT":
+		aggType = GroupConcat
+	case "STD", "STDDEV", "STDDEV_POP":
+		aggType = StdDevPop
+	case "STDDEV_SAMP":
+		aggType = StdDevSamp
+	case "VARIANCE", "VAR_POP":
+		aggType = VarPop
+	case "VAR_SAMP":
+		aggType = VarSamp
+	case "BIT_AND":
+		aggType = BitAnd
+	case "BIT_OR":
+		aggType = BitOr
+	case "BIT_XOR":
+		aggType = BitXor
+	case parser.FirstByFunc:
+		aggType = First
+	case parser.LastByFunc:
+		aggType = Last
+	case "APPROX_COUNT_DISTINCT":
+		aggType = ApproxCountDistinct
+	case "APPROX_PERCENTILE":
+		aggType = ApproxPercentile
 	default:
 		// 不是聚合函数
 		return nil
 	}
 
 	// 构建聚合项
-	return &AggregationItem{
+	item := &AggregationItem{
 		Type:     aggType,
 		Expr:     funcExpr,
 		Alias:    "",
 		Distinct: isDistinct,
 	}
+	if funcExpr == nil {
+		return item
+	}
+
+	item.Distinct = item.Distinct || funcExpr.Distinct
+	args := funcExpr.Args
+	if aggType == GroupConcat {
+		// 最后一个参数是分隔符
+		item.Separator = ","
+		if n := len(args); n >= 2 && args[n-1].Type == parser.ExprTypeValue {
+			item.Separator = utils.ToString(args[n-1].Value)
+			args = args[:n-1]
+		}
+		item.OrderBy = funcExpr.OrderBy
+	}
+	if (aggType == First || aggType == Last) && len(args) == 2 && args[1].Type == parser.ExprTypeColumn {
+		// 第二个参数是决定取哪一行的时间列
+		item.OrderBy = []parser.OrderByItem{{Column: args[1].Column, Direction: "ASC"}}
+	}
+	if aggType == ApproxPercentile {
+		// 百分比必须是常量，缺少时由聚合函数报告参数错误
+		for _, arg := range args[1:] {
+			if arg.Type == parser.ExprTypeValue {
+				item.Params = append(item.Params, arg.Value)
+			}
+		}
+	}
+	// 聚合项的 Expr 为函数参数
+	if len(args) > 0 {
+		arg := args[0]
+		item.Expr = &arg
+	}
+	return item
 }
 
 // expressionToString 将表达式转换为字符串（Optimizer 方法版本）
@@ -167,6 +251,8 @@ func (o *Optimizer) valueToExpression(val interface{}) parser.Expression {
 	}
 
 	switch v := val.(type) {
+	case *parser.Expression:
+		return *v
 	case int, int32, int64:
 		return parser.Expression{
 			Type:  parser.ExprTypeValue,
@@ -1,11 +1,8 @@
 package optimizer
 
 import (
-	"strings"
-
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
-	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
 // convertConditionsToFilters 将条件表达式转换为过滤器
@@ -54,55 +51,7 @@ func (o *Optimizer) extractFiltersFromCondition(expr *parser.Expression) []domai
 	return filters
 }
 
-// convertExpressionToFilter 将表达式转换为过滤器
+// convertExpressionToFilter 将表达式转换为过滤器，OR / NOT 组成的谓词树转换为嵌套过滤器
 func (o *Optimizer) convertExpressionToFilter(expr *parser.Expression) *domain.Filter {
-	if expr == nil || expr.Type != parser.ExprTypeOperator {
-		return nil
-	}
-
-	// 处理一元操作符 (IS NULL / IS NOT NULL)
-	if expr.Left != nil && expr.Right == nil {
-		op := strings.ToLower(expr.Operator)
-		if op == "is null" || op == "isnull" || op == "is not null" || op == "isnotnull" {
-			// 左边必须是列名
-			if expr.Left.Type == parser.ExprTypeColumn && expr.Left.Column != "" {
-				return &domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: utils.MapOperator(expr.Operator),
-					Value:    nil,
-				}
-			}
-		}
-	}
-
-	// 处理二元比较表达式 (e.g., age > 30, name = 'Alice')
-	if expr.Left != nil && expr.Right != nil && expr.Operator != "" {
-		// 左边是列名
-		if expr.Left.Type == parser.ExprTypeColumn && expr.Left.Column != "" {
-			// 右边是常量值
-			if expr.Right.Type == parser.ExprTypeValue {
-				// 映射操作符
-				operator := utils.MapOperator(expr.Operator)
-				return &domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: operator,
-					Value:    expr.Right.Value,
-				}
-			}
-		}
-	}
-
-	// 处理 AND 逻辑表达式
-	if expr.Operator == "and" && expr.Left != nil && expr.Right != nil {
-		leftFilter := o.convertExpressionToFilter(expr.Left)
-		rightFilter := o.convertExpressionToFilter(expr.Right)
-		if leftFilter != nil {
-			return leftFilter
-		}
-		if rightFilter != nil {
-			return rightFilter
-		}
-	}
-
-	return nil
+	return expressionToFilter(expr)
 }
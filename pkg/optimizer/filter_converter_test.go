@@ -107,7 +107,7 @@ func TestExtractFiltersFromCondition(t *testing.T) {
 			expected: 2,
 		},
 		{
-			name: "OR condition - single filter (OR cannot be converted to simple filter)",
+			name: "OR condition - single nested filter",
 			expr: &parser.Expression{
 				Type:     parser.ExprTypeOperator,
 				Operator: "or",
@@ -124,7 +124,7 @@ func TestExtractFiltersFromCondition(t *testing.T) {
 					Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: 2},
 				},
 			},
-			expected: 0,
+			expected: 1,
 		},
 	}
 
@@ -0,0 +1,93 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// useFunctionalIndexes 将“函数索引表达式 比较 常量”形式的条件改写为索引列上的条件
+//
+// 函数索引的键保存在数据源的隐藏列中，改写后的条件直接下推到数据源，由数据源通过索引定位行；
+// 返回下推的条件和其余仍需在 Selection 中求值的条件
+func (o *Optimizer) useFunctionalIndexes(tableName string, conditions []*parser.Expression) (pushed, remaining []*parser.Expression) {
+	fim, ok := o.dataSource.(domain.FunctionalIndexManager)
+	if !ok {
+		return nil, conditions
+	}
+	indexes := fim.FunctionalIndexes(context.Background(), tableName)
+	if len(indexes) == 0 {
+		return nil, conditions
+	}
+
+	remaining = make([]*parser.Expression, 0, len(conditions))
+	for _, cond := range conditions {
+		if pred := functionalIndexPredicate(cond, indexes); pred != nil {
+			pushed = append(pushed, pred)
+		} else {
+			remaining = append(remaining, cond)
+		}
+	}
+	return pushed, remaining
+}
+
+// functionalIndexPredicate 条件的左侧与某个函数索引的表达式相同时，返回索引列上的等价条件
+func functionalIndexPredicate(cond *parser.Expression, indexes []domain.FunctionalIndex) *parser.Expression {
+	if cond == nil || cond.Type != parser.ExprTypeOperator || cond.Left == nil ||
+		cond.Right == nil || cond.Right.Type != parser.ExprTypeValue {
+		return nil
+	}
+	for _, idx := range indexes {
+		indexExpr, err := parseGeneratedExpr(idx.Expression)
+		if err != nil || !sameExpression(cond.Left, indexExpr) {
+			continue
+		}
+		pred := &parser.Expression{
+			Type:     parser.ExprTypeOperator,
+			Operator: cond.Operator,
+			Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: idx.Column},
+			Right:    cond.Right,
+		}
+		if expressionToFilter(pred) != nil {
+			return pred
+		}
+	}
+	return nil
+}
+
+// sameExpression 判断两个表达式在结构上是否相同（函数名、运算符和列名不区分大小写，列名忽略表名限定）
+func sameExpression(a, b *parser.Expression) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type || a.Distinct != b.Distinct || a.Collation != b.Collation ||
+		!strings.EqualFold(a.Function, b.Function) || !strings.EqualFold(a.Operator, b.Operator) {
+		return false
+	}
+	switch a.Type {
+	case parser.ExprTypeColumn:
+		return strings.EqualFold(unqualifiedColumn(a.Column), unqualifiedColumn(b.Column))
+	case parser.ExprTypeValue:
+		return fmt.Sprintf("%T:%v", a.Value, a.Value) == fmt.Sprintf("%T:%v", b.Value, b.Value)
+	}
+	if len(a.Args) != len(b.Args) {
+		return false
+	}
+	for i := range a.Args {
+		if !sameExpression(&a.Args[i], &b.Args[i]) {
+			return false
+		}
+	}
+	return sameExpression(a.Left, b.Left) && sameExpression(a.Right, b.Right)
+}
+
+// unqualifiedColumn 去掉列名的表名限定，如 users.email -> email
+func unqualifiedColumn(name string) string {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name[idx+1:]
+	}
+	return name
+}
@@ -0,0 +1,65 @@
+package optimizer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/generated"
+)
+
+// generatedExprCache 生成列表达式解析缓存（表达式文本 -> *parser.Expression）
+var generatedExprCache sync.Map
+
+var (
+	generatedExprAdapter = parser.NewSQLAdapter()
+
+	generatedExprEvaluatorOnce sync.Once
+	generatedExprEvaluator     *ExpressionEvaluator
+)
+
+func init() {
+	// generated 包不能依赖 parser/optimizer，通过注册回调使生成列
+	// 与查询使用同一套表达式语义
+	generated.RegisterExpressionEvaluator(evaluateGeneratedExpr)
+}
+
+// evaluateGeneratedExpr 使用 SQL 表达式求值器计算生成列表达式
+func evaluateGeneratedExpr(exprText string, row domain.Row) (interface{}, error) {
+	expr, err := parseGeneratedExpr(exprText)
+	if err != nil {
+		return nil, err
+	}
+	// 延迟创建，确保所有内置函数都已注册
+	generatedExprEvaluatorOnce.Do(func() {
+		generatedExprEvaluator = NewExpressionEvaluator(newGlobalFunctionAPI())
+	})
+	return generatedExprEvaluator.evaluateInternal(expr, parser.Row(row))
+}
+
+// parseGeneratedExpr 将生成列表达式文本解析为表达式树
+func parseGeneratedExpr(exprText string) (*parser.Expression, error) {
+	if cached, ok := generatedExprCache.Load(exprText); ok {
+		return cached.(*parser.Expression), nil
+	}
+
+	result, err := generatedExprAdapter.Parse("SELECT " + exprText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated column expression %q: %w", exprText, err)
+	}
+	stmt := result.Statement.Select
+	if stmt == nil || len(stmt.Columns) != 1 {
+		return nil, fmt.Errorf("invalid generated column expression %q", exprText)
+	}
+
+	col := stmt.Columns[0]
+	expr := col.Expr
+	if expr == nil {
+		// 单独的列引用不会生成 Expr
+		expr = &parser.Expression{Type: parser.ExprTypeColumn, Column: col.Name}
+	}
+
+	generatedExprCache.Store(exprText, expr)
+	return expr, nil
+}
@@ -2,7 +2,6 @@ package optimizer
 
 import (
 	"context"
-	"fmt"
 )
 
 // HintAwareIndexRule 支持 hints 的索引使用规则
@@ -70,7 +69,7 @@ func (r *HintAwareIndexRule) applyIndexHintsToDataSource(dataSource *LogicalData
 
 	// Priority 1: FORCE_INDEX - 强制使用指定索引
 	if indexList, ok := hints.ForceIndex[tableName]; ok && len(indexList) > 0 {
-		fmt.Printf("  [HINT INDEX] FORCE_INDEX for table %s: %v\n", tableName, indexList)
+		debugf("  [HINT INDEX] FORCE_INDEX for table %s: %v\n", tableName, indexList)
 		dataSource.ForceUseIndex(indexList[0]) // 强制使用第一个索引
 		dataSource.SetHintApplied("FORCE_INDEX")
 		return dataSource, nil
@@ -78,7 +77,7 @@ func (r *HintAwareIndexRule) applyIndexHintsToDataSource(dataSource *LogicalData
 
 	// Priority 2: USE_INDEX - 优先使用指定索引
 	if indexList, ok := hints.UseIndex[tableName]; ok && len(indexList) > 0 {
-		fmt.Printf("  [HINT INDEX] USE_INDEX for table %s: %v\n", tableName, indexList)
+		debugf("  [HINT INDEX] USE_INDEX for table %s: %v\n", tableName, indexList)
 		dataSource.PreferIndex(indexList[0]) // 优先使用第一个索引
 		dataSource.SetHintApplied("USE_INDEX")
 		return dataSource, nil
@@ -86,7 +85,7 @@ func (r *HintAwareIndexRule) applyIndexHintsToDataSource(dataSource *LogicalData
 
 	// Priority 3: IGNORE_INDEX - 忽略指定索引
 	if indexList, ok := hints.IgnoreIndex[tableName]; ok && len(indexList) > 0 {
-		fmt.Printf("  [HINT INDEX] IGNORE_INDEX for table %s: %v\n", tableName, indexList)
+		debugf("  [HINT INDEX] IGNORE_INDEX for table %s: %v\n", tableName, indexList)
 		for _, idx := range indexList {
 			dataSource.IgnoreIndex(idx)
 		}
@@ -96,7 +95,7 @@ func (r *HintAwareIndexRule) applyIndexHintsToDataSource(dataSource *LogicalData
 
 	// Priority 4: ORDER_INDEX - 强制排序索引
 	if orderIndex, ok := hints.OrderIndex[tableName]; ok && orderIndex != "" {
-		fmt.Printf("  [HINT INDEX] ORDER_INDEX for table %s: %s\n", tableName, orderIndex)
+		debugf("  [HINT INDEX] ORDER_INDEX for table %s: %s\n", tableName, orderIndex)
 		dataSource.SetOrderIndex(orderIndex)
 		dataSource.SetHintApplied("ORDER_INDEX")
 		return dataSource, nil
@@ -104,7 +103,7 @@ func (r *HintAwareIndexRule) applyIndexHintsToDataSource(dataSource *LogicalData
 
 	// Priority 5: NO_ORDER_INDEX - 忽略排序索引
 	if noOrderIndex, ok := hints.NoOrderIndex[tableName]; ok && noOrderIndex != "" {
-		fmt.Printf("  [HINT INDEX] NO_ORDER_INDEX for table %s: %s\n", tableName, noOrderIndex)
+		debugf("  [HINT INDEX] NO_ORDER_INDEX for table %s: %s\n", tableName, noOrderIndex)
 		dataSource.IgnoreOrderIndex(noOrderIndex)
 		dataSource.SetHintApplied("NO_ORDER_INDEX")
 		return dataSource, nil
@@ -419,3 +419,66 @@ func TestHintAwareJoinReorderRule_ApplyNegativeHints(t *testing.T) {
 		})
 	}
 }
+
+func TestJoinReorderRule_StraightJoinKeepsOrder(t *testing.T) {
+	t1 := NewLogicalDataSource("t1", nil)
+	t2 := NewLogicalDataSource("t2", nil)
+	t3 := NewLogicalDataSource("t3", nil)
+	inner := NewLogicalJoin(InnerJoin, t1, t2, []*JoinCondition{})
+	join := NewLogicalJoin(InnerJoin, inner, t3, []*JoinCondition{})
+
+	optCtx := &OptimizationContext{Hints: &OptimizerHints{StraightJoin: true}}
+
+	result, err := (&JoinReorderRule{}).Apply(context.Background(), join, optCtx)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result != join {
+		t.Error("JoinReorderRule should keep the written order under STRAIGHT_JOIN")
+	}
+
+	result, err = (&DPJoinReorderAdapter{}).Apply(context.Background(), join, optCtx)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result != join {
+		t.Error("DPJoinReorderAdapter should keep the written order under STRAIGHT_JOIN")
+	}
+}
+
+func TestParseStatementHints(t *testing.T) {
+	stmt := &parser.SQLStatement{
+		Type: parser.SQLTypeSelect,
+		Select: &parser.SelectStatement{
+			From:         "t1",
+			Hints:        "MAX_EXECUTION_TIME(250) PARALLEL(4) FORCE_INDEX(t1 idx_a) UNKNOWN_HINT",
+			StraightJoin: true,
+		},
+	}
+
+	hints := ParseStatementHints(stmt)
+	if !hints.JoinOrderFixed() {
+		t.Error("Expected join order to be fixed")
+	}
+	if hints.MaxExecutionTime.Milliseconds() != 250 {
+		t.Errorf("Expected MaxExecutionTime 250ms, got %v", hints.MaxExecutionTime)
+	}
+	if hints.Parallel != 4 {
+		t.Errorf("Expected Parallel 4, got %d", hints.Parallel)
+	}
+	if idx := hints.ForceIndex["t1"]; len(idx) != 1 || idx[0] != "idx_a" {
+		t.Errorf("Expected FORCE_INDEX for t1, got %v", hints.ForceIndex)
+	}
+	if len(hints.Warnings) != 1 {
+		t.Errorf("Expected 1 warning for UNKNOWN_HINT, got %v", hints.Warnings)
+	}
+
+	// 没有 SELECT hints 时从 RawSQL 中提取
+	hints = ParseStatementHints(&parser.SQLStatement{RawSQL: "SELECT /*+ PARALLEL(2) */ * FROM t"})
+	if hints.Parallel != 2 {
+		t.Errorf("Expected Parallel 2 from RawSQL, got %d", hints.Parallel)
+	}
+	if ParseStatementHints(nil).JoinOrderFixed() {
+		t.Error("nil statement should produce empty hints")
+	}
+}
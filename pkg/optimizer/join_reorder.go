@@ -52,6 +52,10 @@ func containsJoin(plan LogicalPlan) bool {
 // Apply 应用规则：重排序JOIN顺序
 func (r *JoinReorderRule) Apply(ctx context.Context, plan LogicalPlan, optCtx *OptimizationContext) (LogicalPlan, error) {
 	debugln("  [DEBUG] JoinReorderRule.Apply: 开始")
+	if optCtx != nil && optCtx.Hints.JoinOrderFixed() {
+		debugln("  [DEBUG] JoinReorderRule.Apply: STRAIGHT_JOIN，保持书写顺序")
+		return plan, nil
+	}
 	// 收集所有JOIN节点
 	joinNodes := collectJoins(plan)
 	debugln("  [DEBUG] JoinReorderRule.Apply: 收集到JOIN节点数:", len(joinNodes))
@@ -2,12 +2,15 @@ package optimizer
 
 import (
 	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
 )
 
 // LogicalAggregate 逻辑聚合
 type LogicalAggregate struct {
 	aggFuncs      []*AggregationItem
 	groupByFields []string
+	groupByExprs  map[string]*parser.Expression // 表达式分组：分组名 -> 表达式
 	children      []LogicalPlan
 	algorithm     AggregationAlgorithm // 聚合算法
 	appliedHints  []string             // 已应用的 hints
@@ -71,6 +74,16 @@ func (p *LogicalAggregate) GetGroupByCols() []string {
 	return p.groupByFields
 }
 
+// GetGroupByExprs 返回表达式分组（分组名 -> 表达式）
+func (p *LogicalAggregate) GetGroupByExprs() map[string]*parser.Expression {
+	return p.groupByExprs
+}
+
+// SetGroupByExprs 设置表达式分组
+func (p *LogicalAggregate) SetGroupByExprs(exprs map[string]*parser.Expression) {
+	p.groupByExprs = exprs
+}
+
 // GetGroupBy 返回分组列列表 (别名)
 func (p *LogicalAggregate) GetGroupBy() []string {
 	return p.groupByFields
@@ -15,6 +15,7 @@ type LogicalDataSource struct {
 	pushedDownPredicates []*parser.Expression // 下推的谓词条件
 	pushedDownLimit      *LimitInfo           // 下推的Limit信息
 	pushedDownTopN       *TopNInfo            // 下推的TopN信息
+	Sample               *parser.TableSample  // TABLESAMPLE 抽样，nil 表示读取全部行
 
 	// Hints 相关字段
 	forceUseIndex    string   // 强制使用的索引（FORCE_INDEX）
@@ -23,6 +23,21 @@ func (p *LogicalDataSource) IgnoreIndex(indexName string) {
 	p.addAppliedHint("IGNORE_INDEX")
 }
 
+// ForcedIndex 返回 FORCE_INDEX 指定的索引，未指定时返回空字符串
+func (p *LogicalDataSource) ForcedIndex() string {
+	return p.forceUseIndex
+}
+
+// PreferredIndex 返回 USE_INDEX 指定的索引，未指定时返回空字符串
+func (p *LogicalDataSource) PreferredIndex() string {
+	return p.preferIndex
+}
+
+// IgnoredIndexes 返回 IGNORE_INDEX 指定的索引列表
+func (p *LogicalDataSource) IgnoredIndexes() []string {
+	return p.ignoreIndexes
+}
+
 // SetOrderIndex 设置排序索引（ORDER_INDEX）
 func (p *LogicalDataSource) SetOrderIndex(indexName string) {
 	p.orderIndex = indexName
@@ -2,8 +2,10 @@ package optimizer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/kasuganosora/sqlexec/pkg/builtin"
 	"github.com/kasuganosora/sqlexec/pkg/dataaccess"
@@ -13,6 +15,7 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/application"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/sequence"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
 )
 
@@ -40,6 +43,9 @@ type OptimizedExecutor struct {
 	functionAPI   *builtin.FunctionAPI             // 函数API
 	exprEvaluator *ExpressionEvaluator             // 表达式求值器
 	sessionVars   map[string]string                // 会话级系统变量覆盖
+
+	enginesMu sync.Mutex
+	engines   map[domain.DataSource]*queryEngine // 其他数据库的优化器和执行器
 }
 
 // contextKey 是context中的key类型
@@ -47,8 +53,16 @@ type contextKey int
 
 const (
 	aclManagerKey contextKey = iota
+	selectLimitKey
+	failoverKey
+	tableOverrideKey
 )
 
+// WithSelectLimit 为没有 LIMIT 的顶层 SELECT 注入 LIMIT n（结果集上限的 auto_limit 策略）
+func WithSelectLimit(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, selectLimitKey, n)
+}
+
 // NewOptimizedExecutor 创建优化的执行器
 func NewOptimizedExecutor(dataSource domain.DataSource, useOptimizer bool) *OptimizedExecutor {
 	return newOptimizedExecutor(dataSource, nil, useOptimizer, "")
@@ -61,18 +75,7 @@ func NewOptimizedExecutorWithDSManager(dataSource domain.DataSource, dsManager *
 
 // newOptimizedExecutor 内部构造函数，合并公共逻辑
 func newOptimizedExecutor(dataSource domain.DataSource, dsManager *application.DataSourceManager, useOptimizer bool, defaultDB string) *OptimizedExecutor {
-	functionAPI := builtin.NewFunctionAPI()
-	// 使用包装器将旧的 FunctionRegistry 适配到新的 FunctionAPI
-	registry := builtin.GetGlobalRegistry()
-	// 注册所有旧的全局函数到新的API
-	for _, info := range registry.List() {
-		functionAPI.RegisterScalarFunction(
-			info.Name,
-			info.Name,
-			info.Description,
-			info.Handler,
-		)
-	}
+	functionAPI := newGlobalFunctionAPI()
 
 	// 统一使用增强优化器
 	opt := NewEnhancedOptimizer(dataSource, 0) // parallelism=0 表示自动选择最优并行度
@@ -94,6 +97,23 @@ func newOptimizedExecutor(dataSource domain.DataSource, dsManager *application.D
 	}
 }
 
+// newGlobalFunctionAPI 创建包含所有全局内置函数的 FunctionAPI
+func newGlobalFunctionAPI() *builtin.FunctionAPI {
+	functionAPI := builtin.NewFunctionAPI()
+	// 使用包装器将旧的 FunctionRegistry 适配到新的 FunctionAPI
+	registry := builtin.GetGlobalRegistry()
+	// 注册所有旧的全局函数到新的API
+	for _, info := range registry.List() {
+		functionAPI.RegisterScalarFunction(
+			info.Name,
+			info.Name,
+			info.Description,
+			info.Handler,
+		)
+	}
+	return functionAPI
+}
+
 // SetUseOptimizer 设置是否使用优化器
 func (e *OptimizedExecutor) SetUseOptimizer(use bool) {
 	e.useOptimizer = use
@@ -239,6 +259,27 @@ func (e *OptimizedExecutor) ExecuteSelect(ctx context.Context, stmt *parser.Sele
 		ctx = context.WithValue(ctx, "user", e.currentUser)
 	}
 
+	// auto_limit：只作用于本次顶层 SELECT，子查询不受影响
+	if n, ok := ctx.Value(selectLimitKey).(int64); ok && n > 0 {
+		ctx = context.WithValue(ctx, selectLimitKey, int64(0))
+		if stmt.Limit == nil {
+			stmt.Limit = &n
+		}
+	}
+
+	// WHERE 中的 IN (SELECT ...) 先执行并物化为值列表，之后可以像普通 IN 列表一样下推；
+	// DATABASE() 依赖会话状态，替换为当前数据库名
+	where, err := parser.MaterializeSubqueries(ctx, stmt.Where, e.ExecuteSelect)
+	if err != nil {
+		return nil, err
+	}
+	where = bindCurrentDatabase(where, e.currentDB)
+	if where != stmt.Where {
+		materialized := *stmt
+		materialized.Where = where
+		stmt = &materialized
+	}
+
 	// Check if this is an information_schema query
 	// information_schema queries should use QueryBuilder path to access virtual tables
 	if isInformationSchemaQuery(stmt.From, e.currentDB, e.dsManager) {
@@ -252,8 +293,22 @@ func (e *OptimizedExecutor) ExecuteSelect(ctx context.Context, stmt *parser.Sele
 		return e.executeVirtualDBSelect(ctx, stmt, vdbName)
 	}
 
-	// 如果启用了优化器，使用优化路径
-	if e.useOptimizer {
+	// JOIN 的表分布在多个数据源时，从各自的数据源读取后由 QueryBuilder 连接
+	routed, crossDS, err := e.routeCrossDatabaseJoin(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	if routed != nil {
+		builder := parser.NewQueryBuilder(crossDS)
+		return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+			Type:   parser.SQLTypeSelect,
+			Select: routed,
+		})
+	}
+
+	// 逻辑计划尚不包含 JOIN，连接查询由 QueryBuilder 执行；
+	// 数据源能执行整条语句时也交给 QueryBuilder 下推
+	if e.useOptimizer && len(stmt.Joins) == 0 && !e.canPushDownSelect(ctx, stmt) {
 		return e.executeWithOptimizer(ctx, stmt)
 	}
 
@@ -261,6 +316,38 @@ func (e *OptimizedExecutor) ExecuteSelect(ctx context.Context, stmt *parser.Sele
 	return e.executeWithBuilder(ctx, stmt)
 }
 
+// canPushDownSelect 判断 FROM 表所在的数据源能否执行整条语句
+func (e *OptimizedExecutor) canPushDownSelect(ctx context.Context, stmt *parser.SelectStatement) bool {
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.From)
+	if err != nil {
+		return false
+	}
+	routed := *stmt
+	routed.From = table
+	return parser.CanPushDownSelect(ds, &routed)
+}
+
+// bindCurrentDatabase 把条件中的 DATABASE() / SCHEMA() 替换为当前数据库名，只复制被改写的路径
+func bindCurrentDatabase(expr *parser.Expression, db string) *parser.Expression {
+	if expr == nil {
+		return nil
+	}
+	if expr.Type == parser.ExprTypeFunction && len(expr.Args) == 0 {
+		switch strings.ToUpper(expr.Function) {
+		case "DATABASE", "SCHEMA":
+			return &parser.Expression{Type: parser.ExprTypeValue, Value: db}
+		}
+	}
+	left := bindCurrentDatabase(expr.Left, db)
+	right := bindCurrentDatabase(expr.Right, db)
+	if left == expr.Left && right == expr.Right {
+		return expr
+	}
+	bound := *expr
+	bound.Left, bound.Right = left, right
+	return &bound
+}
+
 // ExecuteShow 执行 SHOW 语句 - 转换为 information_schema 查询
 func (e *OptimizedExecutor) ExecuteShow(ctx context.Context, showStmt *parser.ShowStatement) (*domain.QueryResult, error) {
 	// 将用户信息传递到 context（用于权限检查）
@@ -268,10 +355,29 @@ func (e *OptimizedExecutor) ExecuteShow(ctx context.Context, showStmt *parser.Sh
 		ctx = context.WithValue(ctx, "user", e.currentUser)
 	}
 
+	if showStmt.Type == "TABLE_MEMORY" {
+		return e.executeShowTableMemory(ctx, showStmt)
+	}
+	if showStmt.Type == "DATASOURCES" {
+		return e.executeShowDatasources()
+	}
+	if showStmt.Type == "PLUGINS" {
+		return e.executeShowPlugins()
+	}
+	if showStmt.Type == "SCHEMA_CHANGES" {
+		return e.executeShowSchemaChanges(showStmt.Database)
+	}
+	if showStmt.Type == "SHADOW_TABLES" {
+		return e.executeShowShadowTables()
+	}
+
 	showExecutor := NewShowExecutor(e.currentDB, e.dsManager, e.executeWithBuilder)
 	return showExecutor.ExecuteShow(ctx, showStmt)
 }
 
+// errMaxExecutionTimeExceeded MAX_EXECUTION_TIME hint 超时（MySQL 错误 3024）
+var errMaxExecutionTimeExceeded = errors.New("Query execution was interrupted, maximum statement execution time exceeded")
+
 // executeWithOptimizer 使用优化器执行查询
 func (e *OptimizedExecutor) executeWithOptimizer(ctx context.Context, stmt *parser.SelectStatement) (*domain.QueryResult, error) {
 	debugln("  [DEBUG] 开始优化查询...")
@@ -299,6 +405,23 @@ func (e *OptimizedExecutor) executeWithOptimizer(ctx context.Context, stmt *pars
 		return e.executeWithBuilder(ctx, stmt)
 	}
 
+	// 按 FROM 表所在的数据库选择数据源
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.From)
+	if err != nil {
+		return nil, err
+	}
+	if table != stmt.From {
+		routed := *stmt
+		routed.From = table
+		stmt = &routed
+	}
+
+	// 只统计行数的查询由数据源直接计数
+	if result, ok := e.executeRowCount(ctx, ds, stmt); ok {
+		return result, nil
+	}
+	engine := e.engineFor(ctx, table, ds)
+
 	// 1. 构建 SQLStatement
 	sqlStmt := &parser.SQLStatement{
 		Type:   parser.SQLTypeSelect,
@@ -306,9 +429,21 @@ func (e *OptimizedExecutor) executeWithOptimizer(ctx context.Context, stmt *pars
 	}
 	debugln("  [DEBUG] SQLStatement构建完成")
 
+	// MAX_EXECUTION_TIME hint 限制本条语句的执行时间
+	hints := ParseStatementHints(sqlStmt)
+	execCtx := ctx
+	if hints.MaxExecutionTime > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, hints.MaxExecutionTime)
+		defer cancel()
+	}
+	for _, warning := range hints.Warnings {
+		debugf("  [HINTS] %s\n", warning)
+	}
+
 	// 2. 优化查询计划
 	debugln("  [DEBUG] 调用 Optimize...")
-	executionPlan, err := e.optimizer.Optimize(ctx, sqlStmt)
+	executionPlan, err := engine.optimizer.Optimize(execCtx, sqlStmt)
 
 	if err != nil {
 		return nil, fmt.Errorf("optimizer failed: %w", err)
@@ -317,11 +452,17 @@ func (e *OptimizedExecutor) executeWithOptimizer(ctx context.Context, stmt *pars
 
 	// 3. 执行计划（使用新的 executor）
 	debugln("  [DEBUG] 开始执行计划...")
-	result, err := e.executePlan(ctx, executionPlan)
+	result, err := engine.planExecutor.Execute(execCtx, executionPlan)
+	if execCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, errMaxExecutionTimeExceeded
+	}
 	if err != nil {
 		return nil, fmt.Errorf("execute plan failed: %w", err)
 	}
 	debugln("  [DEBUG] 计划执行完成")
+	if len(hints.Warnings) > 0 {
+		result.Warnings = append(result.Warnings, hints.Warnings...)
+	}
 
 	// 4. 设置列信息
 	// When the plan executor has already populated result.Columns (e.g. from an
@@ -330,7 +471,7 @@ func (e *OptimizedExecutor) executeWithOptimizer(ctx context.Context, stmt *pars
 	// not match any column in the underlying table, so filterColumns would return
 	// an empty list and erase the correct aggregate column information.
 	if len(result.Columns) == 0 {
-		tableInfo, err := e.dataSource.GetTableInfo(ctx, stmt.From)
+		tableInfo, err := ds.GetTableInfo(ctx, stmt.From)
 		if err == nil {
 			// 根据选择的列过滤
 			if !isWildcard(stmt.Columns) {
@@ -399,7 +540,16 @@ func (e *OptimizedExecutor) executeWithBuilder(ctx context.Context, stmt *parser
 		}
 	}
 
-	builder := parser.NewQueryBuilder(e.dataSource)
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.From)
+	if err != nil {
+		return nil, err
+	}
+	if table != stmt.From {
+		routed := *stmt
+		routed.From = table
+		stmt = &routed
+	}
+	builder := parser.NewQueryBuilder(ds)
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:   parser.SQLTypeSelect,
 		Select: stmt,
@@ -409,57 +559,75 @@ func (e *OptimizedExecutor) executeWithBuilder(ctx context.Context, stmt *parser
 // ExecuteInsert 执行 INSERT
 func (e *OptimizedExecutor) ExecuteInsert(ctx context.Context, stmt *parser.InsertStatement) (*domain.QueryResult, error) {
 	// Check if trying to INSERT into information_schema
-	if isInformationSchemaTable(stmt.Table) {
+	if isInformationSchemaTable(qualifiedName(stmt.Database, stmt.Table)) {
 		return nil, fmt.Errorf("information_schema is read-only: INSERT operation not supported")
 	}
 
 	// Check if this is a virtual database INSERT
-	if vdbName := isVirtualDBQuery(stmt.Table, e.currentDB, e.vdbRegistry); vdbName != "" {
+	if vdbName := isVirtualDBQuery(qualifiedName(stmt.Database, stmt.Table), e.currentDB, e.vdbRegistry); vdbName != "" {
 		return e.executeVirtualDBInsert(ctx, stmt, vdbName)
 	}
 
-	builder := parser.NewQueryBuilder(e.dataSource)
+	ds, table, err := e.resolveTable(ctx, stmt.Database, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	routed := *stmt
+	routed.Database, routed.Table = "", table
+	builder := parser.NewQueryBuilder(ds)
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:   parser.SQLTypeInsert,
-		Insert: stmt,
+		Insert: &routed,
 	})
 }
 
 // ExecuteUpdate 执行 UPDATE
 func (e *OptimizedExecutor) ExecuteUpdate(ctx context.Context, stmt *parser.UpdateStatement) (*domain.QueryResult, error) {
 	// Check if trying to UPDATE information_schema
-	if isInformationSchemaTable(stmt.Table) {
+	if isInformationSchemaTable(qualifiedName(stmt.Database, stmt.Table)) {
 		return nil, fmt.Errorf("information_schema is read-only: UPDATE operation not supported")
 	}
 
 	// Check if this is a virtual database UPDATE
-	if vdbName := isVirtualDBQuery(stmt.Table, e.currentDB, e.vdbRegistry); vdbName != "" {
+	if vdbName := isVirtualDBQuery(qualifiedName(stmt.Database, stmt.Table), e.currentDB, e.vdbRegistry); vdbName != "" {
 		return e.executeVirtualDBUpdate(ctx, stmt, vdbName)
 	}
 
-	builder := parser.NewQueryBuilder(e.dataSource)
+	ds, table, err := e.resolveTable(ctx, stmt.Database, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	routed := *stmt
+	routed.Database, routed.Table = "", table
+	builder := parser.NewQueryBuilder(ds)
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:   parser.SQLTypeUpdate,
-		Update: stmt,
+		Update: &routed,
 	})
 }
 
 // ExecuteDelete 执行 DELETE
 func (e *OptimizedExecutor) ExecuteDelete(ctx context.Context, stmt *parser.DeleteStatement) (*domain.QueryResult, error) {
 	// Check if trying to DELETE from information_schema
-	if isInformationSchemaTable(stmt.Table) {
+	if isInformationSchemaTable(qualifiedName(stmt.Database, stmt.Table)) {
 		return nil, fmt.Errorf("information_schema is read-only: DELETE operation not supported")
 	}
 
 	// Check if this is a virtual database DELETE
-	if vdbName := isVirtualDBQuery(stmt.Table, e.currentDB, e.vdbRegistry); vdbName != "" {
+	if vdbName := isVirtualDBQuery(qualifiedName(stmt.Database, stmt.Table), e.currentDB, e.vdbRegistry); vdbName != "" {
 		return e.executeVirtualDBDelete(ctx, stmt, vdbName)
 	}
 
-	builder := parser.NewQueryBuilder(e.dataSource)
+	ds, table, err := e.resolveTable(ctx, stmt.Database, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	routed := *stmt
+	routed.Database, routed.Table = "", table
+	builder := parser.NewQueryBuilder(ds)
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:   parser.SQLTypeDelete,
-		Delete: stmt,
+		Delete: &routed,
 	})
 }
 
@@ -475,13 +643,7 @@ func (e *OptimizedExecutor) ExecuteCreate(ctx context.Context, stmt *parser.Crea
 // ExecuteDrop 执行 DROP
 func (e *OptimizedExecutor) ExecuteDrop(ctx context.Context, stmt *parser.DropStatement) (*domain.QueryResult, error) {
 	// 使用当前数据库的数据源（如果设置了）
-	ds := e.dataSource
-	if e.dsManager != nil && e.currentDB != "" {
-		if currentDS, err := e.dsManager.Get(e.currentDB); err == nil {
-			ds = currentDS
-		}
-	}
-	builder := parser.NewQueryBuilder(ds)
+	builder := parser.NewQueryBuilder(e.currentDataSource())
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type: parser.SQLTypeDrop,
 		Drop: stmt,
@@ -491,29 +653,177 @@ func (e *OptimizedExecutor) ExecuteDrop(ctx context.Context, stmt *parser.DropSt
 // ExecuteAlter 执行 ALTER
 func (e *OptimizedExecutor) ExecuteAlter(ctx context.Context, stmt *parser.AlterStatement) (*domain.QueryResult, error) {
 	// 使用当前数据库的数据源
-	ds := e.dataSource
-	if e.dsManager != nil && e.currentDB != "" {
-		if currentDS, err := e.dsManager.Get(e.currentDB); err == nil {
-			ds = currentDS
-		}
-	}
-	builder := parser.NewQueryBuilder(ds)
+	builder := parser.NewQueryBuilder(e.currentDataSource())
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:  parser.SQLTypeAlter,
 		Alter: stmt,
 	})
 }
 
-// ExecuteCreateIndex 执行 CREATE INDEX
-func (e *OptimizedExecutor) ExecuteCreateIndex(ctx context.Context, stmt *parser.CreateIndexStatement) (*domain.QueryResult, error) {
+// ExecuteOptimize 执行 OPTIMIZE TABLE
+func (e *OptimizedExecutor) ExecuteOptimize(ctx context.Context, stmt *parser.OptimizeStatement) (*domain.QueryResult, error) {
 	// 使用当前数据库的数据源
-	ds := e.dataSource
-	if e.dsManager != nil && e.currentDB != "" {
-		if currentDS, err := e.dsManager.Get(e.currentDB); err == nil {
-			ds = currentDS
-		}
+	builder := parser.NewQueryBuilder(e.currentDataSource())
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:     parser.SQLTypeOptimize,
+		Optimize: stmt,
+	})
+}
+
+// ExecuteExportTable 执行 EXPORT TABLE
+func (e *OptimizedExecutor) ExecuteExportTable(ctx context.Context, stmt *parser.ExportTableStatement) (*domain.QueryResult, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	builder := parser.NewQueryBuilder(ds)
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:        parser.SQLTypeExport,
+		ExportTable: &parser.ExportTableStatement{Table: table, File: stmt.File},
+	})
+}
+
+// ExecuteImportTable 执行 IMPORT TABLE
+func (e *OptimizedExecutor) ExecuteImportTable(ctx context.Context, stmt *parser.ImportTableStatement) (*domain.QueryResult, error) {
+	// 使用当前数据库的数据源
+	builder := parser.NewQueryBuilder(e.currentDataSource())
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:        parser.SQLTypeImport,
+		ImportTable: stmt,
+	})
+}
+
+// ExecuteGenerateTable 执行 CREATE TABLE ... AS GENERATE，表名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteGenerateTable(ctx context.Context, stmt *parser.GenerateTableStatement) (*domain.QueryResult, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	generate := *stmt
+	generate.Table = table
+	builder := parser.NewQueryBuilder(ds)
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:          parser.SQLTypeGenerate,
+		GenerateTable: &generate,
+	})
+}
+
+// ExecuteImportData 执行 IMPORT DATA INFILE，表名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteImportData(ctx context.Context, stmt *parser.ImportDataStatement) (*domain.QueryResult, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	importData := *stmt
+	importData.Table = table
+	builder := parser.NewQueryBuilder(ds)
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:       parser.SQLTypeImportData,
+		ImportData: &importData,
+	})
+}
+
+// ExecuteUndropTable 执行 UNDROP TABLE，表名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteUndropTable(ctx context.Context, stmt *parser.UndropTableStatement) (*domain.QueryResult, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.Table)
+	if err != nil {
+		return nil, err
 	}
 	builder := parser.NewQueryBuilder(ds)
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:   parser.SQLTypeUndrop,
+		Undrop: &parser.UndropTableStatement{Table: table},
+	})
+}
+
+// ExecuteFlashbackTable 执行 FLASHBACK TABLE ... TO TIMESTAMP，表名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteFlashbackTable(ctx context.Context, stmt *parser.FlashbackTableStatement) (*domain.QueryResult, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	builder := parser.NewQueryBuilder(ds)
+	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
+		Type:      parser.SQLTypeFlashback,
+		Flashback: &parser.FlashbackTableStatement{Table: table, Timestamp: stmt.Timestamp},
+	})
+}
+
+// ExecuteSequence 执行 CREATE/ALTER/DROP SEQUENCE，序列名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteSequence(ctx context.Context, stmtType parser.SQLType, stmt *parser.SequenceStatement) (*domain.QueryResult, error) {
+	for _, name := range stmt.Names {
+		ds, table, err := e.resolveQualifiedTable(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		seq := *stmt
+		seq.Names = []string{table}
+		builder := parser.NewQueryBuilder(ds)
+		if _, err := builder.ExecuteStatement(ctx, &parser.SQLStatement{Type: stmtType, Sequence: &seq}); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.QueryResult{Total: 0}, nil
+}
+
+// NextSequenceValue 返回序列的下一个值，序列名可以带库名前缀
+func (e *OptimizedExecutor) NextSequenceValue(ctx context.Context, name string) (int64, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return sequence.Next(ctx, ds, table)
+}
+
+// SetSequenceValue 设置序列的下一个值（SETVAL），序列没有改变时返回 false
+func (e *OptimizedExecutor) SetSequenceValue(ctx context.Context, name string, value int64, used bool) (bool, error) {
+	ds, table, err := e.resolveQualifiedTable(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return sequence.SetValue(ctx, ds, table, value, used)
+}
+
+// ExecuteChecksum 执行 CHECKSUM TABLE，表名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteChecksum(ctx context.Context, stmt *parser.ChecksumStatement) (*domain.QueryResult, error) {
+	return e.executeTableMaintenance(ctx, stmt.Tables, func(table string) *parser.SQLStatement {
+		return &parser.SQLStatement{Type: parser.SQLTypeChecksum, Checksum: &parser.ChecksumStatement{Tables: []string{table}}}
+	})
+}
+
+// ExecuteCheck 执行 CHECK TABLE，表名可以带库名前缀
+func (e *OptimizedExecutor) ExecuteCheck(ctx context.Context, stmt *parser.CheckStatement) (*domain.QueryResult, error) {
+	return e.executeTableMaintenance(ctx, stmt.Tables, func(table string) *parser.SQLStatement {
+		return &parser.SQLStatement{Type: parser.SQLTypeCheck, Check: &parser.CheckStatement{Tables: []string{table}}}
+	})
+}
+
+// executeTableMaintenance 在每个表所在的数据源上执行语句并合并结果，结果中的表名保持语句中的写法
+func (e *OptimizedExecutor) executeTableMaintenance(ctx context.Context, tables []string, stmtFor func(table string) *parser.SQLStatement) (*domain.QueryResult, error) {
+	merged := &domain.QueryResult{}
+	for _, name := range tables {
+		ds, table, err := e.resolveQualifiedTable(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		result, err := parser.NewQueryBuilder(ds).ExecuteStatement(ctx, stmtFor(table))
+		if err != nil {
+			return nil, err
+		}
+		merged.Columns = result.Columns
+		for _, row := range result.Rows {
+			row["Table"] = name
+			merged.Rows = append(merged.Rows, row)
+		}
+	}
+	merged.Total = int64(len(merged.Rows))
+	return merged, nil
+}
+
+// ExecuteCreateIndex 执行 CREATE INDEX
+func (e *OptimizedExecutor) ExecuteCreateIndex(ctx context.Context, stmt *parser.CreateIndexStatement) (*domain.QueryResult, error) {
+	// 使用当前数据库的数据源
+	builder := parser.NewQueryBuilder(e.currentDataSource())
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:        parser.SQLTypeCreate,
 		CreateIndex: stmt,
@@ -523,13 +833,7 @@ func (e *OptimizedExecutor) ExecuteCreateIndex(ctx context.Context, stmt *parser
 // ExecuteDropIndex 执行 DROP INDEX
 func (e *OptimizedExecutor) ExecuteDropIndex(ctx context.Context, stmt *parser.DropIndexStatement) (*domain.QueryResult, error) {
 	// 使用当前数据库的数据源
-	ds := e.dataSource
-	if e.dsManager != nil && e.currentDB != "" {
-		if currentDS, err := e.dsManager.Get(e.currentDB); err == nil {
-			ds = currentDS
-		}
-	}
-	builder := parser.NewQueryBuilder(ds)
+	builder := parser.NewQueryBuilder(e.currentDataSource())
 	return builder.ExecuteStatement(ctx, &parser.SQLStatement{
 		Type:      parser.SQLTypeDrop,
 		DropIndex: stmt,
@@ -108,29 +108,21 @@ func (o *Optimizer) convertSelect(stmt *parser.SelectStatement) (LogicalPlan, er
 		}
 
 		// 应用 GROUP BY（Aggregate）
+		var resolver *aggregateRefResolver
 		if len(stmt.GroupBy) > 0 {
 			aggFuncs := o.extractAggFuncs(stmt.Columns)
-			logicalPlan = NewLogicalAggregate(aggFuncs, stmt.GroupBy, logicalPlan)
+			agg := NewLogicalAggregate(aggFuncs, stmt.GroupBy, logicalPlan)
+			agg.SetGroupByExprs(stmt.GroupByExprs)
+			logicalPlan = agg
+			resolver = newAggregateRefResolver(o, agg)
 		}
 
+		// 应用 HAVING（聚合之后的 Selection）
+		logicalPlan = o.applyHaving(stmt.Having, resolver, logicalPlan)
+
 		// Apply ORDER BY (Sort)
 		if len(stmt.OrderBy) > 0 {
-			sortItems := make([]parser.OrderItem, len(stmt.OrderBy))
-			for i, item := range stmt.OrderBy {
-				sortItems[i] = parser.OrderItem{
-					Expr: parser.Expression{
-						Type:   parser.ExprTypeColumn,
-						Column: item.Column,
-					},
-					Direction: item.Direction,
-				}
-			}
-			// Create pointer slice for NewLogicalSort
-			sortItemsPtr := make([]*parser.OrderItem, len(sortItems))
-			for i := range sortItems {
-				sortItemsPtr[i] = &sortItems[i]
-			}
-			logicalPlan = NewLogicalSort(sortItemsPtr, logicalPlan)
+			logicalPlan = NewLogicalSort(buildSortItems(stmt.OrderBy, resolver), logicalPlan)
 		}
 
 		// 应用 LIMIT（Limit）
@@ -143,6 +135,9 @@ func (o *Optimizer) convertSelect(stmt *parser.SelectStatement) (LogicalPlan, er
 			logicalPlan = NewLogicalLimit(limit, offset, logicalPlan)
 		}
 
+		if resolver != nil {
+			logicalPlan = resolver.project(logicalPlan)
+		}
 		return logicalPlan, nil
 	}
 
@@ -154,40 +149,44 @@ func (o *Optimizer) convertSelect(stmt *parser.SelectStatement) (LogicalPlan, er
 	}
 	debugln("  [DEBUG] convertSelect: GetTableInfo 成功, 列数:", len(tableInfo.Columns))
 
-	var logicalPlan LogicalPlan = NewLogicalDataSource(stmt.From, tableInfo)
+	dataSource := NewLogicalDataSource(stmt.From, tableInfo)
+	dataSource.Sample = stmt.Sample
+	var logicalPlan LogicalPlan = dataSource
 	debugln("  [DEBUG] convertSelect: LogicalDataSource 创建完成")
 
 	// 2. 应用 WHERE 条件（Selection）
 	if stmt.Where != nil {
 		conditions := o.extractConditions(stmt.Where)
-		logicalPlan = NewLogicalSelection(conditions, logicalPlan)
+		if len(stmt.Joins) == 0 {
+			var pushed []*parser.Expression
+			pushed, conditions = o.useFunctionalIndexes(stmt.From, conditions)
+			dataSource.PushDownPredicates(pushed)
+		}
+		if len(conditions) > 0 {
+			logicalPlan = NewLogicalSelection(conditions, logicalPlan)
+		}
 	}
 
 	// 3. 应用 GROUP BY 或独立聚合函数（Aggregate）
 	// 没有 GROUP BY 但存在聚合函数（如 SELECT count(*) FROM t）时也需要 AggregateOperator
 	aggFuncs := o.extractAggFuncs(stmt.Columns)
+	var resolver *aggregateRefResolver
 	if len(stmt.GroupBy) > 0 || len(aggFuncs) > 0 {
-		logicalPlan = NewLogicalAggregate(aggFuncs, stmt.GroupBy, logicalPlan)
+		agg := NewLogicalAggregate(aggFuncs, stmt.GroupBy, logicalPlan)
+		agg.SetGroupByExprs(stmt.GroupByExprs)
+		logicalPlan = agg
+		resolver = newAggregateRefResolver(o, agg)
+	} else if cols, ok := distinctColumns(stmt); ok {
+		// SELECT DISTINCT a, b 等价于按 a, b 分组
+		logicalPlan = NewLogicalAggregate(nil, cols, logicalPlan)
 	}
 
-	// 4. 应用 ORDER BY（Sort）
+	// 3.1 应用 HAVING（聚合之后的 Selection）
+	logicalPlan = o.applyHaving(stmt.Having, resolver, logicalPlan)
+
+	// 4. 应用 ORDER BY（Sort），聚合函数排序项解析为聚合输出列
 	if len(stmt.OrderBy) > 0 {
-		sortItems := make([]parser.OrderItem, len(stmt.OrderBy))
-		for i, item := range stmt.OrderBy {
-			sortItems[i] = parser.OrderItem{
-				Expr: parser.Expression{
-					Type:   parser.ExprTypeColumn,
-					Column: item.Column,
-				},
-				Direction: item.Direction,
-			}
-		}
-		// Create pointer slice for NewLogicalSort
-		sortItemsPtr := make([]*parser.OrderItem, len(sortItems))
-		for i := range sortItems {
-			sortItemsPtr[i] = &sortItems[i]
-		}
-		logicalPlan = NewLogicalSort(sortItemsPtr, logicalPlan)
+		logicalPlan = NewLogicalSort(buildSortItems(stmt.OrderBy, resolver), logicalPlan)
 	}
 
 	// 5. 应用 LIMIT（Limit）
@@ -200,6 +199,11 @@ func (o *Optimizer) convertSelect(stmt *parser.SelectStatement) (LogicalPlan, er
 		logicalPlan = NewLogicalLimit(limit, offset, logicalPlan)
 	}
 
+	// 5.1 去除 HAVING / ORDER BY 引入的隐藏聚合列
+	if resolver != nil {
+		logicalPlan = resolver.project(logicalPlan)
+	}
+
 	// 6. 应用 SELECT 列（Projection）
 	// 当存在聚合函数时跳过 Projection：AggregateOperator 已经产生了正确的输出列。
 	// 额外的 Projection 会尝试投影不存在的列名（如 "COUNT_*"），导致空结果。
@@ -217,6 +221,10 @@ func (o *Optimizer) convertSelect(stmt *parser.SelectStatement) (LogicalPlan, er
 				Type:   parser.ExprTypeColumn,
 				Column: col.Name,
 			}
+			// 标量表达式（qty * price、UPPER(name)、CAST(...)）由 ProjectionOperator 逐行计算
+			if isScalarProjection(col.Expr) {
+				exprs[i] = col.Expr
+			}
 			if col.Alias != "" {
 				aliases[i] = col.Alias
 			} else {
@@ -229,6 +237,34 @@ func (o *Optimizer) convertSelect(stmt *parser.SelectStatement) (LogicalPlan, er
 	return logicalPlan, nil
 }
 
+// distinctColumns 返回 SELECT DISTINCT 的去重列；只有全部选择列都是列引用时才按分组去重
+func distinctColumns(stmt *parser.SelectStatement) ([]string, bool) {
+	if !stmt.Distinct || isWildcard(stmt.Columns) {
+		return nil, false
+	}
+	cols := make([]string, 0, len(stmt.Columns))
+	for _, col := range stmt.Columns {
+		if col.Name == "" || isScalarProjection(col.Expr) {
+			return nil, false
+		}
+		cols = append(cols, col.Name)
+	}
+	return cols, len(cols) > 0
+}
+
+// isScalarProjection 判断 SELECT 列是否为需要逐行计算的标量表达式：
+// 常量、运算符和函数调用；列引用直接投影
+func isScalarProjection(expr *parser.Expression) bool {
+	if expr == nil {
+		return false
+	}
+	switch expr.Type {
+	case parser.ExprTypeValue, parser.ExprTypeOperator, parser.ExprTypeFunction:
+		return true
+	}
+	return false
+}
+
 // convertInsert 转换 INSERT 语句
 func (o *Optimizer) convertInsert(stmt *parser.InsertStatement) (LogicalPlan, error) {
 	// 验证表存在
@@ -375,6 +411,7 @@ func (o *Optimizer) convertToPlan(ctx context.Context, logicalPlan LogicalPlan,
 				LimitInfo:       convertToTypesLimitInfo(limitInfo),
 				EnableParallel:  true,
 				MinParallelRows: 100,
+				Sample:          p.Sample,
 			},
 		}, nil
 	case *LogicalSelection:
@@ -393,7 +430,7 @@ func (o *Optimizer) convertToPlan(ctx context.Context, logicalPlan LogicalPlan,
 			OutputSchema: child.OutputSchema,
 			Children:     []*plan.Plan{child},
 			Config: &plan.SelectionConfig{
-				Condition: conditions[0],
+				Condition: combineConditions(conditions),
 			},
 		}, nil
 	case *LogicalProjection:
@@ -491,26 +528,16 @@ func (o *Optimizer) convertToPlan(ctx context.Context, logicalPlan LogicalPlan,
 		if err != nil {
 			return nil, err
 		}
-		// 转换aggFuncs到types.AggregationItem
-		aggFuncs := p.GetAggFuncs()
-		convertedAggFuncs := make([]*types.AggregationItem, len(aggFuncs))
-		for i, agg := range aggFuncs {
-			convertedAggFuncs[i] = &types.AggregationItem{
-				Type:     types.AggregationType(agg.Type),
-				Expr:     convertToTypesExpr(agg.Expr),
-				Alias:    agg.Alias,
-				Distinct: agg.Distinct,
-			}
-		}
-
 		return &plan.Plan{
 			ID:           fmt.Sprintf("agg_%d", len(p.GetGroupByCols())),
 			Type:         plan.TypeAggregate,
 			OutputSchema: child.OutputSchema,
 			Children:     []*plan.Plan{child},
 			Config: &plan.AggregateConfig{
-				AggFuncs:    convertedAggFuncs,
-				GroupByCols: p.GetGroupByCols(),
+				AggFuncs:     convertToTypesAggFuncs(p.GetAggFuncs()),
+				GroupByCols:  p.GetGroupByCols(),
+				GroupByExprs: p.GetGroupByExprs(),
+				AggArgExprs:  aggArgExprs(p.GetAggFuncs()),
 			},
 		}, nil
 	default:
@@ -485,3 +485,19 @@ func toFloat(v interface{}) float64 {
 		return 0
 	}
 }
+
+func TestPlanCache_InvalidatePlanCaches(t *testing.T) {
+	cache := NewPlanCache(100)
+	cache.Put(42, nil)
+	if _, ok := cache.Get(42); !ok {
+		t.Fatal("expected cached plan before invalidation")
+	}
+
+	InvalidatePlanCaches()
+	if _, ok := cache.Get(42); ok {
+		t.Error("expected plan cached before InvalidatePlanCaches to miss")
+	}
+	if cache.Size() != 0 {
+		t.Errorf("expected stale plan to be evicted, size = %d", cache.Size())
+	}
+}
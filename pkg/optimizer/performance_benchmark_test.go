@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -1084,8 +1085,8 @@ BenchmarkJoin2Table_Inner-8     500      2000000 ns/op   3456 B/op    34 allocs/
 		t.Fatal("no benchmark results parsed")
 	}
 
-	// 保存结果
-	baselinePath := "benchmark/baseline.json"
+	// 保存结果到临时目录，不覆盖已提交的 benchmark/baseline.json
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
 	if err := saveBenchmarkResults(results, baselinePath); err != nil {
 		t.Fatalf("failed to save benchmark results: %v", err)
 	}
@@ -237,8 +237,8 @@ func (p *PhysicalTableScan) executeSerialScan(ctx context.Context) (*domain.Quer
 			}
 		}
 
-		// 调用 Query 方法
-		result, err = p.dataSource.Query(ctx, p.TableName, options)
+		// 调用 Query 方法，支持流式查询的数据源逐页读取
+		result, err = domain.QueryRows(ctx, p.dataSource, p.TableName, options)
 		if err != nil {
 			return nil, err
 		}
@@ -1,9 +1,18 @@
 package plan
 
-import "github.com/kasuganosora/sqlexec/pkg/types"
+import (
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/types"
+)
 
 // AggregateConfig 聚合配置
 type AggregateConfig struct {
 	AggFuncs    []*types.AggregationItem
 	GroupByCols []string
+	// GroupByExprs 表达式分组（如 GROUP BY DATE(created_at)）：分组列名 -> 表达式
+	// 不在其中的分组列直接按同名列取值
+	GroupByExprs map[string]*parser.Expression
+	// AggArgExprs 参数为表达式的聚合函数（如 SUM(CASE WHEN ... END)）：聚合结果列名 -> 参数表达式
+	// 不在其中的聚合函数按 AggregationItem.Expr 取值
+	AggArgExprs map[string]*parser.Expression
 }
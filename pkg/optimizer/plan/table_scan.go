@@ -1,7 +1,10 @@
 package plan
 
-import "github.com/kasuganosora/sqlexec/pkg/resource/domain"
-import "github.com/kasuganosora/sqlexec/pkg/types"
+import (
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/types"
+)
 
 // TableScanConfig 表扫描配置
 type TableScanConfig struct {
@@ -11,4 +14,9 @@ type TableScanConfig struct {
 	LimitInfo       *types.LimitInfo
 	EnableParallel  bool
 	MinParallelRows int64
+	ForceIndex      string   // FORCE_INDEX / USE_INDEX hint 指定的索引
+	IgnoreIndexes   []string // IGNORE_INDEX hint 指定的索引
+	Parallelism     int      // PARALLEL(n) hint 指定的扫描并行度，0 表示默认
+	// Sample TABLESAMPLE 抽样，nil 表示读取全部行；LimitInfo 作用于抽样结果
+	Sample *parser.TableSample
 }
@@ -23,9 +23,20 @@ type PlanCache struct {
 	misses  int64
 }
 
+// planCacheEpoch is bumped by InvalidatePlanCaches; plans cached under an
+// older epoch are treated as misses.
+var planCacheEpoch int64
+
+// InvalidatePlanCaches invalidates the plans of every PlanCache, e.g. after a
+// datasource reports a schema change that the owning optimizers cannot observe.
+func InvalidatePlanCaches() {
+	atomic.AddInt64(&planCacheEpoch, 1)
+}
+
 // CachedPlan stores a cached execution plan with metadata.
 type CachedPlan struct {
 	Plan       *plan.Plan
+	Epoch      int64
 	CreatedAt  time.Time
 	HitCount   int64
 	LastHit    time.Time
@@ -55,8 +66,19 @@ func (pc *PlanCache) Get(fingerprint uint64) (*plan.Plan, bool) {
 	// Read fields under RLock to avoid data race
 	cachedPlan := entry.Plan
 	lastHit := entry.LastHit
+	epoch := entry.Epoch
 	pc.mu.RUnlock()
 
+	if epoch != atomic.LoadInt64(&planCacheEpoch) {
+		pc.mu.Lock()
+		if pc.cache[fingerprint] == entry {
+			delete(pc.cache, fingerprint)
+		}
+		pc.mu.Unlock()
+		atomic.AddInt64(&pc.misses, 1)
+		return nil, false
+	}
+
 	atomic.AddInt64(&pc.hits, 1)
 	atomic.AddInt64(&entry.HitCount, 1)
 
@@ -85,6 +107,7 @@ func (pc *PlanCache) Put(fingerprint uint64, p *plan.Plan) {
 	now := time.Now()
 	pc.cache[fingerprint] = &CachedPlan{
 		Plan:      p,
+		Epoch:     atomic.LoadInt64(&planCacheEpoch),
 		CreatedAt: now,
 		LastHit:   now,
 	}
@@ -181,7 +204,10 @@ func fingerprintSelect(h hash.Hash64, sel *parser.SelectStatement) {
 
 	// Columns
 	for _, col := range sel.Columns {
-		fmt.Fprintf(h, "col:%s.%s|", col.Table, col.Name)
+		fmt.Fprintf(h, "col:%s.%s:%s|", col.Table, col.Name, col.Alias)
+		if col.Expr != nil {
+			fingerprintExpr(h, col.Expr)
+		}
 	}
 
 	// WHERE clause structure
@@ -191,7 +217,7 @@ func fingerprintSelect(h hash.Hash64, sel *parser.SelectStatement) {
 
 	// JOINs
 	for _, j := range sel.Joins {
-		fmt.Fprintf(h, "join:%s.%s|", j.Type, j.Table)
+		fmt.Fprintf(h, "join:%s.%s.%s|", j.Type, j.Database, j.Table)
 		if j.Condition != nil {
 			fingerprintExpr(h, j.Condition)
 		}
@@ -200,11 +226,31 @@ func fingerprintSelect(h hash.Hash64, sel *parser.SelectStatement) {
 	// ORDER BY
 	for _, ob := range sel.OrderBy {
 		fmt.Fprintf(h, "order:%s.%s|", ob.Column, ob.Direction)
+		if ob.Expr != nil {
+			fingerprintExpr(h, ob.Expr)
+		}
 	}
 
 	// GROUP BY
 	for _, gb := range sel.GroupBy {
 		fmt.Fprintf(h, "group:%s|", gb)
+		if expr, ok := sel.GroupByExprs[gb]; ok {
+			fingerprintExpr(h, expr)
+		}
+	}
+
+	// HAVING
+	if sel.Having != nil {
+		fmt.Fprint(h, "having:")
+		fingerprintExpr(h, sel.Having)
+	}
+
+	// TABLESAMPLE
+	if sample := sel.Sample; sample != nil {
+		fmt.Fprintf(h, "sample:%s:%g:%d|", sample.Method, sample.Percent, sample.Rows)
+		if sample.Seed != nil {
+			fmt.Fprintf(h, "seed:%d|", *sample.Seed)
+		}
 	}
 
 	// LIMIT & OFFSET
@@ -223,6 +269,18 @@ func fingerprintExpr(h hash.Hash64, expr *parser.Expression) {
 	}
 
 	fmt.Fprintf(h, "(%v:%s:%s:%v|", expr.Type, expr.Column, expr.Operator, expr.Value)
+	if expr.Function != "" {
+		fmt.Fprintf(h, "fn:%s:%v|", expr.Function, expr.Distinct)
+	}
+	for i := range expr.Args {
+		fingerprintExpr(h, &expr.Args[i])
+	}
+	for _, ob := range expr.OrderBy {
+		fmt.Fprintf(h, "order:%s.%s|", ob.Column, ob.Direction)
+	}
+	if expr.Escape != "" || expr.Collation != "" {
+		fmt.Fprintf(h, "esc:%s:coll:%s|", expr.Escape, expr.Collation)
+	}
 
 	if expr.Left != nil {
 		fingerprintExpr(h, expr.Left)
@@ -122,6 +122,7 @@ func (pc *PlanConverter) convertDataSource(ctx context.Context, p *LogicalDataSo
 			LimitInfo:       &types.LimitInfo{Limit: 0, Offset: 0},
 			EnableParallel:  true,
 			MinParallelRows: 100,
+			Sample:          p.Sample,
 		},
 		EstimatedCost: scanCost,
 	}, nil
@@ -312,25 +313,16 @@ func (pc *PlanConverter) convertAggregate(ctx context.Context, p *LogicalAggrega
 	// Calculate cost
 	_ = pc.costModel.AggregateCost(int64(10000), len(groupByCols), len(aggFuncs))
 
-	// Convert aggFuncs to types.AggregationItem
-	convertedAggFuncs := make([]*types.AggregationItem, len(aggFuncs))
-	for i, agg := range aggFuncs {
-		convertedAggFuncs[i] = &types.AggregationItem{
-			Type:     types.AggregationType(agg.Type),
-			Expr:     convertToTypesExpr(agg.Expr),
-			Alias:    agg.Alias,
-			Distinct: agg.Distinct,
-		}
-	}
-
 	return &plan.Plan{
 		ID:           fmt.Sprintf("agg_%d_%d", len(groupByCols), len(aggFuncs)),
 		Type:         plan.TypeAggregate,
 		OutputSchema: child.OutputSchema,
 		Children:     []*plan.Plan{child},
 		Config: &plan.AggregateConfig{
-			GroupByCols: groupByCols,
-			AggFuncs:    convertedAggFuncs,
+			GroupByCols:  groupByCols,
+			GroupByExprs: p.GetGroupByExprs(),
+			AggFuncs:     convertToTypesAggFuncs(aggFuncs),
+			AggArgExprs:  aggArgExprs(aggFuncs),
 		},
 	}, nil
 }
@@ -0,0 +1,128 @@
+package optimizer
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// aggregateRefResolver 将 HAVING / ORDER BY 中的聚合函数解析为聚合节点的输出列
+// 与 SELECT 列表中相同的聚合函数复用其输出列（HAVING COUNT(*) > 5 引用 COUNT(*) AS c）；
+// 没有出现在 SELECT 列表中的聚合函数作为隐藏列追加到聚合节点，最终由 Projection 去除
+type aggregateRefResolver struct {
+	o       *Optimizer
+	agg     *LogicalAggregate
+	visible []string // 聚合节点原有的输出列（分组列 + SELECT 中的聚合函数）
+	hidden  int      // 追加的隐藏聚合列数
+}
+
+// newAggregateRefResolver 创建聚合引用解析器
+func newAggregateRefResolver(o *Optimizer, agg *LogicalAggregate) *aggregateRefResolver {
+	visible := make([]string, 0, len(agg.groupByFields)+len(agg.aggFuncs))
+	visible = append(visible, agg.groupByFields...)
+	for _, item := range agg.aggFuncs {
+		visible = append(visible, item.Alias)
+	}
+	return &aggregateRefResolver{o: o, agg: agg, visible: visible}
+}
+
+// resolve 返回聚合函数表达式对应的输出列名，expr 不是聚合函数时返回 false
+func (r *aggregateRefResolver) resolve(expr *parser.Expression) (string, bool) {
+	if expr == nil || expr.Type != parser.ExprTypeFunction {
+		return "", false
+	}
+	item := r.o.parseAggregationFunction(expr)
+	if item == nil {
+		return "", false
+	}
+
+	key := aggregationKey(item)
+	for _, existing := range r.agg.aggFuncs {
+		if aggregationKey(existing) == key {
+			return existing.Alias, true
+		}
+	}
+
+	r.hidden++
+	item.Alias = fmt.Sprintf("__agg_%d", r.hidden)
+	r.agg.aggFuncs = append(r.agg.aggFuncs, item)
+	return item.Alias, true
+}
+
+// rewrite 复制表达式，并将其中的聚合函数替换为对输出列的引用
+func (r *aggregateRefResolver) rewrite(expr *parser.Expression) *parser.Expression {
+	if expr == nil {
+		return nil
+	}
+	if alias, ok := r.resolve(expr); ok {
+		return &parser.Expression{Type: parser.ExprTypeColumn, Column: alias}
+	}
+
+	rewritten := *expr
+	rewritten.Left = r.rewrite(expr.Left)
+	rewritten.Right = r.rewrite(expr.Right)
+	if len(expr.Args) > 0 {
+		rewritten.Args = make([]parser.Expression, len(expr.Args))
+		for i := range expr.Args {
+			rewritten.Args[i] = *r.rewrite(&expr.Args[i])
+		}
+	}
+	return &rewritten
+}
+
+// project 存在隐藏聚合列时，在 child 之上添加只保留原有输出列的 Projection
+func (r *aggregateRefResolver) project(child LogicalPlan) LogicalPlan {
+	if r.hidden == 0 {
+		return child
+	}
+	exprs := make([]*parser.Expression, len(r.visible))
+	for i, name := range r.visible {
+		exprs[i] = &parser.Expression{Type: parser.ExprTypeColumn, Column: name}
+	}
+	return NewLogicalProjection(exprs, r.visible, child)
+}
+
+// aggregationKey 生成聚合项的比较键，函数类型、DISTINCT、参数和 GROUP_CONCAT 选项都相同时视为同一聚合
+func aggregationKey(item *AggregationItem) string {
+	h := fnv.New64a()
+	fingerprintExpr(h, item.Expr)
+	for _, ob := range item.OrderBy {
+		fmt.Fprintf(h, "order:%s.%s|", ob.Column, ob.Direction)
+	}
+	return fmt.Sprintf("%s|%v|%s|%x", item.Type, item.Distinct, item.Separator, h.Sum64())
+}
+
+// buildSortItems 转换 ORDER BY 项；resolver 不为空时聚合函数排序项解析为聚合输出列
+func buildSortItems(orderBy []parser.OrderByItem, resolver *aggregateRefResolver) []*parser.OrderItem {
+	sortItems := make([]*parser.OrderItem, len(orderBy))
+	for i, item := range orderBy {
+		column := item.Column
+		if resolver != nil {
+			if alias, ok := resolver.resolve(item.Expr); ok {
+				column = alias
+			}
+		}
+		sortItems[i] = &parser.OrderItem{
+			Expr: parser.Expression{
+				Type:   parser.ExprTypeColumn,
+				Column: column,
+			},
+			Direction: item.Direction,
+		}
+	}
+	return sortItems
+}
+
+// applyHaving 在聚合节点之上应用 HAVING 条件
+// HAVING 中可以引用分组列、SELECT 列别名（HAVING c > 5）以及聚合函数（HAVING SUM(x) > 10）
+func (o *Optimizer) applyHaving(having *parser.Expression, resolver *aggregateRefResolver, child LogicalPlan) LogicalPlan {
+	if having == nil {
+		return child
+	}
+	if resolver != nil {
+		having = resolver.rewrite(having)
+	}
+	// 整个 HAVING 作为单个条件，AND/OR 由 Selection 算子递归求值
+	return NewLogicalSelection([]*parser.Expression{having}, child)
+}
@@ -0,0 +1,78 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func aggCall(name string, column string) *parser.Expression {
+	return &parser.Expression{
+		Type:     parser.ExprTypeFunction,
+		Function: name,
+		Value:    name,
+		Args:     []parser.Expression{{Type: parser.ExprTypeColumn, Column: column}},
+	}
+}
+
+func TestAggregateRefResolver_ReusesSelectAggregates(t *testing.T) {
+	o := &Optimizer{}
+	agg := NewLogicalAggregate(o.extractAggFuncs([]parser.SelectColumn{
+		{Name: "dept", Expr: &parser.Expression{Type: parser.ExprTypeColumn, Column: "dept"}},
+		{Expr: aggCall("SUM", "salary"), Alias: "total"},
+	}), []string{"dept"}, &MockLogicalPlan{})
+	resolver := newAggregateRefResolver(o, agg)
+
+	// HAVING SUM(salary) > 100 AND total < 1000
+	having := resolver.rewrite(&parser.Expression{
+		Type:     parser.ExprTypeOperator,
+		Operator: "and",
+		Left: &parser.Expression{
+			Type:     parser.ExprTypeOperator,
+			Operator: "gt",
+			Left:     aggCall("SUM", "salary"),
+			Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: int64(100)},
+		},
+		Right: &parser.Expression{
+			Type:     parser.ExprTypeOperator,
+			Operator: "lt",
+			Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: "total"},
+			Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: int64(1000)},
+		},
+	})
+
+	assert.Equal(t, parser.ExprTypeColumn, having.Left.Left.Type)
+	assert.Equal(t, "total", having.Left.Left.Column)
+	assert.Equal(t, "total", having.Right.Left.Column)
+	assert.Len(t, agg.GetAggFuncs(), 1)
+
+	var child LogicalPlan = agg
+	assert.Same(t, child, resolver.project(child), "no projection without hidden aggregates")
+}
+
+func TestAggregateRefResolver_AddsHiddenAggregates(t *testing.T) {
+	o := &Optimizer{}
+	agg := NewLogicalAggregate(o.extractAggFuncs([]parser.SelectColumn{
+		{Expr: aggCall("COUNT", "id"), Alias: "c"},
+	}), []string{"dept"}, &MockLogicalPlan{})
+	resolver := newAggregateRefResolver(o, agg)
+
+	sortItems := buildSortItems([]parser.OrderByItem{
+		{Column: "MAX(`salary`)", Direction: "DESC", Expr: aggCall("MAX", "salary")},
+		{Column: "c", Direction: "ASC"},
+	}, resolver)
+	having := resolver.rewrite(aggCall("MAX", "salary"))
+
+	require.Len(t, agg.GetAggFuncs(), 2)
+	hidden := agg.GetAggFuncs()[1]
+	assert.Equal(t, Max, hidden.Type)
+	assert.Equal(t, hidden.Alias, sortItems[0].Expr.Column)
+	assert.Equal(t, hidden.Alias, having.Column, "same aggregate resolves to the same hidden column")
+	assert.Equal(t, "c", sortItems[1].Expr.Column)
+
+	proj, ok := resolver.project(agg).(*LogicalProjection)
+	require.True(t, ok)
+	assert.Equal(t, []string{"dept", "c"}, proj.GetAliases())
+}
@@ -0,0 +1,59 @@
+package optimizer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// countAllAlias 语句只统计 FROM 表的行数（SELECT COUNT(*) FROM t [WHERE ...]）时返回结果列名，
+// 与聚合算子的命名一致：有别名用别名，否则为 COUNT_*
+func countAllAlias(stmt *parser.SelectStatement) (string, bool) {
+	if len(stmt.Columns) != 1 || stmt.Distinct || len(stmt.Joins) > 0 || len(stmt.GroupBy) > 0 ||
+		stmt.Having != nil || stmt.Sample != nil || stmt.Lock != "" {
+		return "", false
+	}
+	// LIMIT 0 或 OFFSET 跳过了唯一的结果行，交给常规路径
+	if (stmt.Limit != nil && *stmt.Limit <= 0) || (stmt.Offset != nil && *stmt.Offset != 0) {
+		return "", false
+	}
+	col := stmt.Columns[0]
+	expr := col.Expr
+	if col.IsWildcard || expr == nil || expr.Type != parser.ExprTypeFunction ||
+		!strings.EqualFold(expr.Function, "COUNT") || expr.Distinct {
+		return "", false
+	}
+	// COUNT(*) 被解析为 COUNT(1)；COUNT(NULL) 结果为 0，不是统计行数
+	if len(expr.Args) != 1 || expr.Args[0].Type != parser.ExprTypeValue || expr.Args[0].Value == nil {
+		return "", false
+	}
+	if col.Alias != "" {
+		return col.Alias, true
+	}
+	return "COUNT_*", true
+}
+
+// executeRowCount 只统计行数的查询由数据源计数，不读取行。
+// WHERE 不能全部转换为数据源过滤器、数据源不支持计数或只能给出估计值时 ok 为 false，由调用方执行常规计划
+func (e *OptimizedExecutor) executeRowCount(ctx context.Context, ds domain.DataSource, stmt *parser.SelectStatement) (*domain.QueryResult, bool) {
+	alias, ok := countAllAlias(stmt)
+	if !ok {
+		return nil, false
+	}
+	filters, residual := parser.SplitFilters(stmt.Where)
+	if residual != nil {
+		return nil, false
+	}
+	count, ok := domain.ExactRowCount(ctx, ds, stmt.From, filters)
+	if !ok {
+		return nil, false
+	}
+	debugf("  [DEBUG] COUNT(*) answered by data source row count: %d\n", count)
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: alias, Type: "INTEGER"}},
+		Rows:    []domain.Row{{alias: count}},
+		Total:   1,
+	}, true
+}
@@ -0,0 +1,109 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rowCountDataSource 记录 Query 和 RowCount 的调用次数，estimate 为 true 时只给出估计值
+type rowCountDataSource struct {
+	*memory.MVCCDataSource
+	estimate bool
+	queries  int
+	counts   int
+}
+
+func (d *rowCountDataSource) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	d.queries++
+	return d.MVCCDataSource.Query(ctx, tableName, options)
+}
+
+func (d *rowCountDataSource) RowCount(ctx context.Context, tableName string, filters []domain.Filter) (int64, bool, error) {
+	d.counts++
+	count, exact, err := d.MVCCDataSource.RowCount(ctx, tableName, filters)
+	return count, exact && !d.estimate, err
+}
+
+func newRowCountDataSource(t *testing.T) *rowCountDataSource {
+	t.Helper()
+	ctx := context.Background()
+	mem := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Writable: true})
+	require.NoError(t, mem.Connect(ctx))
+	require.NoError(t, mem.CreateTable(ctx, &domain.TableInfo{
+		Name: "items",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INTEGER"},
+			{Name: "status", Type: "VARCHAR"},
+		},
+	}))
+	_, err := mem.Insert(ctx, "items", []domain.Row{
+		{"id": int64(1), "status": "active"},
+		{"id": int64(2), "status": "closed"},
+		{"id": int64(3), "status": "active"},
+	}, nil)
+	require.NoError(t, err)
+	return &rowCountDataSource{MVCCDataSource: mem}
+}
+
+func parseSelectSQL(t *testing.T, sql string) *parser.SelectStatement {
+	t.Helper()
+	result, err := parser.NewSQLAdapter().Parse(sql)
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+	return result.Statement.Select
+}
+
+func TestExecuteSelect_RowCount(t *testing.T) {
+	tests := []struct {
+		sql      string
+		column   string
+		want     interface{}
+		counted  bool
+		estimate bool
+	}{
+		{sql: "SELECT COUNT(*) FROM items", column: "COUNT_*", want: int64(3), counted: true},
+		{sql: "SELECT count(1) AS n FROM items WHERE status = 'active'", column: "n", want: int64(2), counted: true},
+		{sql: "SELECT COUNT(*) FROM items LIMIT 10", column: "COUNT_*", want: int64(3), counted: true},
+		// 数据源只能给出估计值、WHERE 不能全部下推或不是单纯统计行数时按行统计
+		{sql: "SELECT COUNT(*) FROM items", column: "COUNT_*", want: 3, estimate: true},
+		{sql: "SELECT COUNT(*) AS n FROM items WHERE UPPER(status) = 'ACTIVE'", column: "n", want: 2},
+		{sql: "SELECT COUNT(status) AS n FROM items", column: "n", want: 3},
+		{sql: "SELECT COUNT(DISTINCT status) AS n FROM items", column: "n", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			ds := newRowCountDataSource(t)
+			ds.estimate = tt.estimate
+			e := NewOptimizedExecutor(ds, true)
+
+			result, err := e.ExecuteSelect(context.Background(), parseSelectSQL(t, tt.sql))
+			require.NoError(t, err)
+			require.Len(t, result.Rows, 1)
+			assert.Equal(t, tt.column, result.Columns[0].Name)
+			assert.EqualValues(t, tt.want, result.Rows[0][tt.column])
+			if tt.counted {
+				assert.Equal(t, 1, ds.counts)
+				assert.Zero(t, ds.queries, "rows should not be read")
+			} else {
+				assert.NotZero(t, ds.queries)
+			}
+		})
+	}
+}
+
+func TestExecuteSelect_RowCountLimitZero(t *testing.T) {
+	ds := newRowCountDataSource(t)
+	e := NewOptimizedExecutor(ds, true)
+
+	result, err := e.ExecuteSelect(context.Background(), parseSelectSQL(t, "SELECT COUNT(*) FROM items LIMIT 0"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+	assert.Zero(t, ds.counts)
+}
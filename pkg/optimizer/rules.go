@@ -43,10 +43,27 @@ func (r *PredicatePushDownRule) Apply(ctx context.Context, plan LogicalPlan, opt
 
 	// If child node is DataSource, mark predicates to DataSource (pushdown success)
 	if dataSource, ok := child.(*LogicalDataSource); ok {
-		// Mark Selection conditions to DataSource for filtering during scan
-		dataSource.PushDownPredicates(selection.Conditions())
-		// Return child, eliminate Selection node (conditions pushed down to DataSource)
-		return child, nil
+		// Only conditions expressible as datasource filters can be pushed;
+		// the rest stay in the Selection and are evaluated in-engine
+		pushable := make([]*parser.Expression, 0, len(selection.Conditions()))
+		remaining := make([]*parser.Expression, 0)
+		for _, cond := range selection.Conditions() {
+			if expressionToFilter(cond) != nil {
+				pushable = append(pushable, cond)
+			} else {
+				remaining = append(remaining, cond)
+			}
+		}
+		if len(pushable) > 0 {
+			// Mark Selection conditions to DataSource for filtering during scan
+			dataSource.PushDownPredicates(pushable)
+		}
+		if len(remaining) == 0 {
+			// Return child, eliminate Selection node (conditions pushed down to DataSource)
+			return child, nil
+		}
+		selection.filterConditions = remaining
+		return plan, nil
 	}
 
 	// If child node is Selection, merge conditions
@@ -124,6 +141,7 @@ func (r *ColumnPruningRule) Apply(ctx context.Context, plan LogicalPlan, optCtx
 
 			newDataSource := NewLogicalDataSource(dataSource.TableName, dataSource.TableInfo)
 			newDataSource.Columns = newColumns
+			newDataSource.Sample = dataSource.Sample
 			newDataSource.PushDownPredicates(predicates)
 			if limitInfo != nil {
 				newDataSource.PushDownLimit(limitInfo.Limit, limitInfo.Offset)
@@ -149,6 +167,9 @@ func collectRequiredColumns(expr *parser.Expression, cols map[string]bool) {
 	// 递归处理子表达式
 	collectRequiredColumns(expr.Left, cols)
 	collectRequiredColumns(expr.Right, cols)
+	for i := range expr.Args {
+		collectRequiredColumns(&expr.Args[i], cols)
+	}
 }
 
 // ProjectionEliminationRule 投影消除规则
@@ -497,7 +518,8 @@ func EnhancedRuleSet(estimator CardinalityEstimator) RuleSet {
 		NewDecorrelateRule(estimator),
 		NewSubqueryMaterializationRule(),
 		NewSubqueryFlatteningRule(),
-		NewORToUnionRule(),
+		// 不包含 ORToUnionRule：UNION ALL 会重复返回同时满足多个分支的行，
+		// OR 条件保留在 Selection 中求值
 		NewMaxMinEliminationRule(estimator),
 	}
 	debugln("  [DEBUG] EnhancedRuleSet: 创建增强规则集, 数量:", len(rules))
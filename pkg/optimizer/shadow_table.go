@@ -0,0 +1,123 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ExecuteShadow 执行 SHADOW TABLE t TO db [WITH COMPARE]、CUTOVER TABLE t、UNSHADOW TABLE t。
+// 语句只登记影子迁移的状态，读写的路由与镜像由执行语句的会话按状态完成
+func (e *OptimizedExecutor) ExecuteShadow(ctx context.Context, stmt *parser.ShadowStatement) (*domain.QueryResult, error) {
+	if e.dsManager == nil {
+		return nil, fmt.Errorf("%s TABLE requires a data source manager", shadowVerb(stmt.Action))
+	}
+	database, table := e.shadowTableName(stmt.Table)
+	if database == "" {
+		return nil, fmt.Errorf("%s TABLE: no database selected", shadowVerb(stmt.Action))
+	}
+
+	var info string
+	switch stmt.Action {
+	case parser.ShadowStart:
+		if err := e.dsManager.StartShadow(ctx, database, table, stmt.Target, stmt.Compare); err != nil {
+			return nil, fmt.Errorf("SHADOW TABLE: %w", err)
+		}
+		info = fmt.Sprintf("Writes to %s.%s are mirrored to %s", database, table, stmt.Target)
+	case parser.ShadowCutover:
+		st, err := e.dsManager.CutoverShadow(database, table)
+		if err != nil {
+			return nil, fmt.Errorf("CUTOVER TABLE: %w", err)
+		}
+		info = fmt.Sprintf("%s.%s is served by %s, writes are mirrored to %s", database, table, st.Primary(), st.Mirror())
+	case parser.ShadowStop:
+		if _, err := e.dsManager.StopShadow(database, table); err != nil {
+			return nil, fmt.Errorf("UNSHADOW TABLE: %w", err)
+		}
+		info = fmt.Sprintf("%s.%s is no longer shadowed", database, table)
+	default:
+		return nil, fmt.Errorf("unsupported shadow action: %s", stmt.Action)
+	}
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "info", Type: "VARCHAR"}},
+		Rows:    []domain.Row{{"info": info}},
+		Total:   0,
+	}, nil
+}
+
+// shadowTableName 拆分影子迁移语句的表名，未限定库名时使用当前数据库，没有当前数据库时使用默认数据源
+func (e *OptimizedExecutor) shadowTableName(name string) (database, table string) {
+	if i := strings.Index(name, "."); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	database = e.currentDB
+	if database == "" {
+		database = e.dsManager.GetDefaultName()
+	}
+	return database, name
+}
+
+// shadowVerb 影子迁移操作对应的语句关键字
+func shadowVerb(action string) string {
+	switch action {
+	case parser.ShadowCutover:
+		return "CUTOVER"
+	case parser.ShadowStop:
+		return "UNSHADOW"
+	}
+	return "SHADOW"
+}
+
+// executeShowShadowTables 执行 SHOW SHADOW TABLES：列出影子迁移中的表、当前的主数据源和镜像、比较的计数
+func (e *OptimizedExecutor) executeShowShadowTables() (*domain.QueryResult, error) {
+	if e.dsManager == nil {
+		return nil, fmt.Errorf("SHOW SHADOW TABLES requires a data source manager")
+	}
+
+	tables := e.dsManager.ShadowTables()
+	rows := make([]domain.Row, 0, len(tables))
+	for _, st := range tables {
+		var lastError interface{}
+		if st.LastError != "" {
+			lastError = st.LastError
+		}
+		compare := "NO"
+		if st.Compare {
+			compare = "YES"
+		}
+		rows = append(rows, domain.Row{
+			"Database":        st.Database,
+			"Table":           st.Table,
+			"Shadow":          st.Shadow,
+			"Primary":         st.Primary(),
+			"Compare":         compare,
+			"Mirrored_writes": st.MirroredWrites,
+			"Mirror_errors":   st.MirrorErrors,
+			"Compared_reads":  st.ComparedReads,
+			"Mismatches":      st.Mismatches,
+			"Last_error":      lastError,
+			"Since":           st.Since.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Database", Type: "VARCHAR"},
+			{Name: "Table", Type: "VARCHAR"},
+			{Name: "Shadow", Type: "VARCHAR"},
+			{Name: "Primary", Type: "VARCHAR"},
+			{Name: "Compare", Type: "VARCHAR"},
+			{Name: "Mirrored_writes", Type: "BIGINT"},
+			{Name: "Mirror_errors", Type: "BIGINT"},
+			{Name: "Compared_reads", Type: "BIGINT"},
+			{Name: "Mismatches", Type: "BIGINT"},
+			{Name: "Last_error", Type: "VARCHAR", Nullable: true},
+			{Name: "Since", Type: "DATETIME"},
+		},
+		Rows:  rows,
+		Total: int64(len(rows)),
+	}, nil
+}
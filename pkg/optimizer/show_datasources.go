@@ -0,0 +1,48 @@
+package optimizer
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// executeShowDatasources 执行 SHOW DATASOURCES，列出各数据源最近一次健康检查的结果
+func (e *OptimizedExecutor) executeShowDatasources() (*domain.QueryResult, error) {
+	if e.dsManager == nil {
+		return nil, fmt.Errorf("SHOW DATASOURCES requires a data source manager")
+	}
+
+	rows := make([]domain.Row, 0)
+	for _, h := range e.dsManager.Health() {
+		var lastCheck, lastError interface{}
+		if !h.LastCheck.IsZero() {
+			lastCheck = h.LastCheck.Format("2006-01-02 15:04:05")
+		}
+		if h.LastError != "" {
+			lastError = h.LastError
+		}
+		rows = append(rows, domain.Row{
+			"Name":                 h.Name,
+			"Type":                 string(h.Type),
+			"Status":               h.Status,
+			"Latency_ms":           float64(h.Latency.Microseconds()) / 1000,
+			"Last_check":           lastCheck,
+			"Consecutive_failures": int64(h.ConsecutiveFailures),
+			"Last_error":           lastError,
+		})
+	}
+
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Name", Type: "VARCHAR"},
+			{Name: "Type", Type: "VARCHAR"},
+			{Name: "Status", Type: "VARCHAR"},
+			{Name: "Latency_ms", Type: "DOUBLE"},
+			{Name: "Last_check", Type: "DATETIME", Nullable: true},
+			{Name: "Consecutive_failures", Type: "BIGINT"},
+			{Name: "Last_error", Type: "VARCHAR", Nullable: true},
+		},
+		Rows:  rows,
+		Total: int64(len(rows)),
+	}, nil
+}
@@ -3,12 +3,16 @@ package optimizer
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/information_schema"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/workload"
 )
 
 // ShowExecutor SHOW 语句执行器
@@ -60,7 +64,7 @@ func (e *ShowExecutor) ExecuteShow(ctx context.Context, showStmt *parser.ShowSta
 func (e *ShowExecutor) executeShowTables(ctx context.Context, showStmt *parser.ShowStatement) (*domain.QueryResult, error) {
 	var whereClause string
 	if showStmt.Like != "" {
-		whereClause = fmt.Sprintf(" AND table_name LIKE '%s'", showStmt.Like)
+		whereClause = " AND table_name LIKE " + security.QuoteString(showStmt.Like)
 	}
 	if showStmt.Where != "" {
 		whereClause = fmt.Sprintf(" AND (%s)", showStmt.Where)
@@ -74,8 +78,8 @@ func (e *ShowExecutor) executeShowTables(ctx context.Context, showStmt *parser.S
 	}
 
 	// 构建 SQL 语句
-	sql := fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = '%s'%s",
-		currentDB, whereClause)
+	sql := fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = %s%s",
+		security.QuoteString(currentDB), whereClause)
 	debugf("  [DEBUG] SHOW TABLES converted to: %s, currentDB=%s\n", sql, currentDB)
 
 	// 解析 SQL
@@ -96,7 +100,7 @@ func (e *ShowExecutor) executeShowTables(ctx context.Context, showStmt *parser.S
 func (e *ShowExecutor) executeShowDatabases(ctx context.Context, showStmt *parser.ShowStatement) (*domain.QueryResult, error) {
 	var whereClause string
 	if showStmt.Like != "" {
-		whereClause = fmt.Sprintf(" WHERE schema_name LIKE '%s'", showStmt.Like)
+		whereClause = " WHERE schema_name LIKE " + security.QuoteString(showStmt.Like)
 	}
 	if showStmt.Where != "" {
 		if whereClause == "" {
@@ -131,7 +135,7 @@ func (e *ShowExecutor) executeShowColumns(ctx context.Context, showStmt *parser.
 
 	var whereClause string
 	if showStmt.Like != "" {
-		whereClause = fmt.Sprintf(" AND column_name LIKE '%s'", showStmt.Like)
+		whereClause = " AND column_name LIKE " + security.QuoteString(showStmt.Like)
 	}
 	if showStmt.Where != "" {
 		whereClause = fmt.Sprintf(" AND (%s)", showStmt.Where)
@@ -140,11 +144,11 @@ func (e *ShowExecutor) executeShowColumns(ctx context.Context, showStmt *parser.
 	// 使用当前数据库作为 table_schema 过滤条件，避免跨库列混淆
 	schemaFilter := ""
 	if e.currentDB != "" {
-		schemaFilter = fmt.Sprintf(" AND table_schema = '%s'", e.currentDB)
+		schemaFilter = " AND table_schema = " + security.QuoteString(e.currentDB)
 	}
 
-	sql := fmt.Sprintf("SELECT * FROM information_schema.columns WHERE table_name = '%s'%s%s",
-		showStmt.Table, schemaFilter, whereClause)
+	sql := fmt.Sprintf("SELECT * FROM information_schema.columns WHERE table_name = %s%s%s",
+		security.QuoteString(showStmt.Table), schemaFilter, whereClause)
 	debugf("  [DEBUG] SHOW COLUMNS converted to: %s\n", sql)
 
 	// 解析 SQL
@@ -297,6 +301,10 @@ func (e *ShowExecutor) executeShowStatus(ctx context.Context, showStmt *parser.S
 		{"Variable_name": "Bytes_received", "Value": "0"},
 		{"Variable_name": "Bytes_sent", "Value": "0"},
 	}
+	status = append(status, parseCacheStatus()...)
+	status = append(status, networkStatus()...)
+	status = append(status, panicStatus()...)
+	status = append(status, workloadStatus()...)
 
 	// Apply LIKE filter if provided
 	if showStmt.Like != "" {
@@ -326,6 +334,64 @@ func (e *ShowExecutor) executeShowStatus(ctx context.Context, showStmt *parser.S
 	}, nil
 }
 
+// parseCacheStatus 返回解析缓存的状态变量
+func parseCacheStatus() []domain.Row {
+	stats := parser.GetParseCache().Stats()
+	return []domain.Row{
+		{"Variable_name": "Parse_cache_entries", "Value": strconv.Itoa(stats.Entries)},
+		{"Variable_name": "Parse_cache_evictions", "Value": strconv.FormatUint(stats.Evictions, 10)},
+		{"Variable_name": "Parse_cache_hits", "Value": strconv.FormatUint(stats.Hits, 10)},
+		{"Variable_name": "Parse_cache_max_entries", "Value": strconv.Itoa(stats.MaxEntries)},
+		{"Variable_name": "Parse_cache_misses", "Value": strconv.FormatUint(stats.Misses, 10)},
+	}
+}
+
+// networkStatus 返回结果集写出的流控状态变量
+func networkStatus() []domain.Row {
+	stats := monitor.GetNetworkStats().Snapshot()
+	return []domain.Row{
+		{"Variable_name": "Net_result_bytes_sent", "Value": strconv.FormatInt(stats.BytesSent, 10)},
+		{"Variable_name": "Net_result_flushes", "Value": strconv.FormatInt(stats.Flushes, 10)},
+		{"Variable_name": "Net_stalled_connections", "Value": strconv.FormatInt(stats.StalledConnections, 10)},
+		{"Variable_name": "Net_write_stalls", "Value": strconv.FormatInt(stats.Stalls, 10)},
+		{"Variable_name": "Net_write_timeouts", "Value": strconv.FormatInt(stats.Timeouts, 10)},
+	}
+}
+
+// panicStatus 返回被恢复的 panic 计数
+func panicStatus() []domain.Row {
+	stats := monitor.GetPanicStats().Snapshot()
+	return []domain.Row{
+		{"Variable_name": "Panics_connection", "Value": strconv.FormatInt(stats.Connection, 10)},
+		{"Variable_name": "Panics_http", "Value": strconv.FormatInt(stats.HTTP, 10)},
+		{"Variable_name": "Panics_statement", "Value": strconv.FormatInt(stats.Statement, 10)},
+	}
+}
+
+// workloadStatus 返回各工作负载类别的状态变量（Workload_<类别>_<指标>），未启用时为空
+func workloadStatus() []domain.Row {
+	manager := workload.GetManager()
+	if !manager.Enabled() {
+		return nil
+	}
+	var rows []domain.Row
+	for _, stats := range manager.Stats() {
+		prefix := "Workload_" + stats.Name + "_"
+		rows = append(rows,
+			domain.Row{"Variable_name": prefix + "admitted", "Value": strconv.FormatInt(stats.Admitted, 10)},
+			domain.Row{"Variable_name": prefix + "max_concurrency", "Value": strconv.Itoa(stats.MaxConcurrency)},
+			domain.Row{"Variable_name": prefix + "max_queue", "Value": strconv.Itoa(stats.MaxQueue)},
+			domain.Row{"Variable_name": prefix + "queued", "Value": strconv.Itoa(stats.Queued)},
+			domain.Row{"Variable_name": prefix + "rejected", "Value": strconv.FormatInt(stats.Rejected, 10)},
+			domain.Row{"Variable_name": prefix + "running", "Value": strconv.Itoa(stats.Running)},
+			domain.Row{"Variable_name": prefix + "timed_out", "Value": strconv.FormatInt(stats.TimedOut, 10)},
+			domain.Row{"Variable_name": prefix + "total_wait_ms", "Value": strconv.FormatInt(stats.TotalWaitMs, 10)},
+			domain.Row{"Variable_name": prefix + "waited", "Value": strconv.FormatInt(stats.Waited, 10)},
+		)
+	}
+	return rows
+}
+
 // matchLike performs simple SQL LIKE pattern matching (case-insensitive)
 func matchLike(s, pattern string) bool {
 	// Convert both strings to lowercase for case-insensitive matching
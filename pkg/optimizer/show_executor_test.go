@@ -366,6 +366,15 @@ func TestExecuteShowStatus(t *testing.T) {
 			expectedVars:  []string{"Threads_connected", "Threads_running"},
 			expectMinRows: 2,
 		},
+		{
+			name: "SHOW STATUS LIKE 'Parse_cache%'",
+			showStmt: &parser.ShowStatement{
+				Type: "STATUS",
+				Like: "'Parse_cache%'",
+			},
+			expectedVars:  []string{"Parse_cache_hits", "Parse_cache_misses", "Parse_cache_entries"},
+			expectMinRows: 3,
+		},
 	}
 
 	for _, tt := range tests {
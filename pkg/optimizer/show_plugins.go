@@ -0,0 +1,66 @@
+package optimizer
+
+import (
+	"path/filepath"
+
+	"github.com/kasuganosora/sqlexec/pkg/plugin"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+var pluginManager *plugin.PluginManager
+
+// RegisterPluginManager 注册插件管理器（用于 SHOW PLUGINS）
+func RegisterPluginManager(pm *plugin.PluginManager) {
+	pluginManager = pm
+}
+
+// executeShowPlugins 执行 SHOW PLUGINS，列出已加载的数据源插件和加载失败的插件文件
+// 前五列与 MySQL 相同，未注册插件管理器时（嵌入式使用）结果为空
+func (e *OptimizedExecutor) executeShowPlugins() (*domain.QueryResult, error) {
+	rows := make([]domain.Row, 0)
+	if pluginManager != nil {
+		for _, p := range pluginManager.Plugins() {
+			name := string(p.Type)
+			if name == "" {
+				name = filepath.Base(p.FilePath)
+			}
+			var version, description, lastError interface{}
+			if p.Version != "" {
+				version = p.Version
+			}
+			if p.Description != "" {
+				description = p.Description
+			}
+			if p.Error != "" {
+				lastError = p.Error
+			}
+			rows = append(rows, domain.Row{
+				"Name":        name,
+				"Status":      p.Status,
+				"Type":        "DATASOURCE",
+				"Library":     filepath.Base(p.FilePath),
+				"License":     nil,
+				"Version":     version,
+				"Datasources": int64(p.Datasources),
+				"Description": description,
+				"Error":       lastError,
+			})
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Name", Type: "VARCHAR"},
+			{Name: "Status", Type: "VARCHAR"},
+			{Name: "Type", Type: "VARCHAR"},
+			{Name: "Library", Type: "VARCHAR", Nullable: true},
+			{Name: "License", Type: "VARCHAR", Nullable: true},
+			{Name: "Version", Type: "VARCHAR", Nullable: true},
+			{Name: "Datasources", Type: "BIGINT"},
+			{Name: "Description", Type: "VARCHAR", Nullable: true},
+			{Name: "Error", Type: "VARCHAR", Nullable: true},
+		},
+		Rows:  rows,
+		Total: int64(len(rows)),
+	}, nil
+}
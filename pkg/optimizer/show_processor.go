@@ -10,6 +10,7 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/optimizer/executor"
 	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 )
 
 // DefaultShowProcessor 是 ShowProcessor 接口的默认实现
@@ -52,7 +53,7 @@ func (sp *DefaultShowProcessor) SetProcessListProvider(provider ProcessListProvi
 // ProcessShowTables 处理 SHOW TABLES 语句
 func (sp *DefaultShowProcessor) ProcessShowTables(ctx context.Context) (executor.ResultSet, error) {
 	// SHOW TABLES -> SELECT table_name FROM information_schema.tables WHERE table_schema = ?
-	sql := fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = '%s'", sp.currentDB)
+	sql := "SELECT table_name FROM information_schema.tables WHERE table_schema = " + security.QuoteString(sp.currentDB)
 
 	adapter := parser.NewSQLAdapter()
 	parseResult, err := adapter.Parse(sql)
@@ -107,9 +108,9 @@ func (sp *DefaultShowProcessor) ProcessShowColumns(ctx context.Context, tableNam
 	// SHOW COLUMNS FROM table -> SELECT * FROM information_schema.columns WHERE table_name = ? AND table_schema = ?
 	schemaFilter := ""
 	if sp.currentDB != "" {
-		schemaFilter = fmt.Sprintf(" AND table_schema = '%s'", sp.currentDB)
+		schemaFilter = " AND table_schema = " + security.QuoteString(sp.currentDB)
 	}
-	sql := fmt.Sprintf("SELECT * FROM information_schema.columns WHERE table_name = '%s'%s", tableName, schemaFilter)
+	sql := fmt.Sprintf("SELECT * FROM information_schema.columns WHERE table_name = %s%s", security.QuoteString(tableName), schemaFilter)
 
 	adapter := parser.NewSQLAdapter()
 	parseResult, err := adapter.Parse(sql)
@@ -250,14 +251,14 @@ func (sp *DefaultShowProcessor) ProcessShowCreateTable(ctx context.Context, tabl
 	// 构建 CREATE TABLE 语句
 	var columns []string
 	for _, col := range tableInfo.Columns {
-		colDef := fmt.Sprintf("`%s` %s", col.Name, col.Type)
+		colDef := security.QuoteIdentifier(col.Name) + " " + col.Type
 		if !col.Nullable {
 			colDef += " NOT NULL"
 		}
 		columns = append(columns, colDef)
 	}
 
-	createStmt := fmt.Sprintf("CREATE TABLE `%s` (\n  %s\n)", tableName, strings.Join(columns, ",\n  "))
+	createStmt := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", security.QuoteIdentifier(tableName), strings.Join(columns, ",\n  "))
 
 	rows := []map[string]interface{}{
 		{"Table": tableName, "Create Table": createStmt},
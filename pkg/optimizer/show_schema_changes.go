@@ -0,0 +1,58 @@
+package optimizer
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// executeShowSchemaChanges 执行 SHOW SCHEMA CHANGES [FROM db]：列出定期快照检测到的表结构变化，
+// 按检测时间排序；指定数据库时只列出该数据源的变化
+func (e *OptimizedExecutor) executeShowSchemaChanges(database string) (*domain.QueryResult, error) {
+	if e.dsManager == nil {
+		return nil, fmt.Errorf("SHOW SCHEMA CHANGES requires a data source manager")
+	}
+	if database != "" {
+		if _, err := e.dsManager.Get(database); err != nil {
+			return nil, errUnknownDatabase(database)
+		}
+	}
+
+	changes := e.dsManager.SchemaChanges(database)
+	rows := make([]domain.Row, 0, len(changes))
+	for _, c := range changes {
+		var column, oldType, newType interface{}
+		if c.Column != "" {
+			column = c.Column
+		}
+		if c.OldType != "" {
+			oldType = c.OldType
+		}
+		if c.NewType != "" {
+			newType = c.NewType
+		}
+		rows = append(rows, domain.Row{
+			"Database":    c.Database,
+			"Table":       c.Table,
+			"Column":      column,
+			"Change":      c.Change,
+			"Old_type":    oldType,
+			"New_type":    newType,
+			"Detected_at": c.DetectedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Database", Type: "VARCHAR"},
+			{Name: "Table", Type: "VARCHAR"},
+			{Name: "Column", Type: "VARCHAR", Nullable: true},
+			{Name: "Change", Type: "VARCHAR"},
+			{Name: "Old_type", Type: "VARCHAR", Nullable: true},
+			{Name: "New_type", Type: "VARCHAR", Nullable: true},
+			{Name: "Detected_at", Type: "DATETIME"},
+		},
+		Rows:  rows,
+		Total: int64(len(rows)),
+	}, nil
+}
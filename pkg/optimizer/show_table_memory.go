@@ -0,0 +1,68 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// executeShowTableMemory 执行 SHOW TABLE MEMORY [FROM table]，按列报告表的内存占用
+// 未指定表时报告当前数据库的所有表
+func (e *OptimizedExecutor) executeShowTableMemory(ctx context.Context, showStmt *parser.ShowStatement) (*domain.QueryResult, error) {
+	ds := e.currentDataSource()
+	tables := []string{showStmt.Table}
+	if showStmt.Table != "" {
+		var err error
+		ds, tables[0], err = e.resolveQualifiedTable(ctx, showStmt.Table)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if tables, err = ds.GetTables(ctx); err != nil {
+			return nil, err
+		}
+		sort.Strings(tables)
+	}
+
+	reporter, ok := ds.(domain.TableMemoryReporter)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support SHOW TABLE MEMORY")
+	}
+
+	rows := make([]domain.Row, 0)
+	for _, table := range tables {
+		usages, err := reporter.TableMemoryUsage(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range usages {
+			rows = append(rows, domain.Row{
+				"Table":           table,
+				"Column":          u.Column,
+				"Type":            u.Type,
+				"Values":          u.Values,
+				"Distinct_values": u.DistinctValues,
+				"Logical_bytes":   u.LogicalBytes,
+				"Memory_bytes":    u.MemoryBytes,
+			})
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Table", Type: "VARCHAR"},
+			{Name: "Column", Type: "VARCHAR"},
+			{Name: "Type", Type: "VARCHAR"},
+			{Name: "Values", Type: "BIGINT"},
+			{Name: "Distinct_values", Type: "BIGINT"},
+			{Name: "Logical_bytes", Type: "BIGINT"},
+			{Name: "Memory_bytes", Type: "BIGINT"},
+		},
+		Rows:  rows,
+		Total: int64(len(rows)),
+	}, nil
+}
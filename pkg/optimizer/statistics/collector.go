@@ -43,8 +43,13 @@ func (sc *SamplingCollector) CollectStatistics(ctx context.Context, tableName st
 	}
 
 	// 第二步：计算采样参数
-	// TableInfo 没有 RowCount 字段，使用默认值
+	// 数据源能统计行数时使用它给出的行数（精确值或估计值），否则使用默认值
 	totalRows := int64(100000) // 默认假设10万行
+	if counter, ok := sc.dataSource.(domain.RowCountDataSource); ok {
+		if count, _, err := counter.RowCount(ctx, tableName, nil); err == nil {
+			totalRows = count
+		}
+	}
 	sampleSize := sc.calculateSampleSize(totalRows)
 	if sampleSize <= 0 {
 		sampleSize = 100 // 最小采样100行
@@ -254,7 +254,9 @@ func TestSamplingCollector_CollectStatistics(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, stats)
 	assert.Equal(t, "test_table", stats.Name)
-	assert.Greater(t, stats.RowCount, int64(0))
+	// 内存数据源统计出实际行数，不再使用默认的 10 万行
+	assert.Equal(t, int64(100), stats.RowCount)
+	assert.Equal(t, int64(100), stats.EstimatedRowCount)
 	assert.Greater(t, stats.SampleCount, int64(0))
 	assert.Greater(t, stats.SampleRatio, 0.0)
 	assert.Greater(t, stats.SampleRatio, 0.0)
@@ -97,6 +97,18 @@ const (
 	Avg
 	Max
 	Min
+	GroupConcat
+	StdDevPop
+	StdDevSamp
+	VarPop
+	VarSamp
+	BitAnd
+	BitOr
+	BitXor
+	First               // FIRST(value, ts)
+	Last                // LAST(value, ts)
+	ApproxCountDistinct // APPROX_COUNT_DISTINCT(expr)，HyperLogLog
+	ApproxPercentile    // APPROX_PERCENTILE(expr, percentage)，t-digest
 )
 
 // String 返回 AggregationType 的字符串表示
@@ -112,17 +124,45 @@ func (at AggregationType) String() string {
 		return "MAX"
 	case Min:
 		return "MIN"
+	case GroupConcat:
+		return "GROUP_CONCAT"
+	case StdDevPop:
+		return "STDDEV_POP"
+	case StdDevSamp:
+		return "STDDEV_SAMP"
+	case VarPop:
+		return "VAR_POP"
+	case VarSamp:
+		return "VAR_SAMP"
+	case BitAnd:
+		return "BIT_AND"
+	case BitOr:
+		return "BIT_OR"
+	case BitXor:
+		return "BIT_XOR"
+	case First:
+		return "FIRST"
+	case Last:
+		return "LAST"
+	case ApproxCountDistinct:
+		return "APPROX_COUNT_DISTINCT"
+	case ApproxPercentile:
+		return "APPROX_PERCENTILE"
 	default:
 		return "UNKNOWN"
 	}
 }
 
 // AggregationItem 聚合项
+// Expr 为聚合函数的参数（COUNT(*) 为常量）
 type AggregationItem struct {
-	Type     AggregationType
-	Expr     *parser.Expression
-	Alias    string
-	Distinct bool
+	Type      AggregationType
+	Expr      *parser.Expression
+	Alias     string
+	Distinct  bool
+	Separator string               // GROUP_CONCAT 分隔符
+	OrderBy   []parser.OrderByItem // GROUP_CONCAT 内的 ORDER BY
+	Params    []interface{}        // 表达式之后的常量参数（如 APPROX_PERCENTILE 的百分比）
 }
 
 // JoinCondition 连接条件
@@ -281,6 +321,15 @@ type OptimizerHints struct {
 	MemoryQuota           int64
 	ReadConsistentReplica bool
 	ResourceGroup         string
+	Parallel              int // PARALLEL(n)：表扫描并行度
+
+	// Warnings 被忽略的 hint（未知 hint 或参数无效），随查询结果返回给客户端
+	Warnings []string
+}
+
+// JoinOrderFixed 是否要求按书写顺序连接（STRAIGHT_JOIN），此时 JOIN 重排规则不生效
+func (h *OptimizerHints) JoinOrderFixed() bool {
+	return h != nil && h.StraightJoin
 }
 
 // HintAwareRule 支持 hints 的优化规则接口
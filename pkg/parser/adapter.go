@@ -3,13 +3,19 @@ package parser
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/security"
 	"github.com/pingcap/tidb/pkg/parser"
 	"github.com/pingcap/tidb/pkg/parser/ast"
-	_ "github.com/pingcap/tidb/pkg/parser/test_driver"
+	"github.com/pingcap/tidb/pkg/parser/format"
+	"github.com/pingcap/tidb/pkg/parser/opcode"
+	"github.com/pingcap/tidb/pkg/parser/test_driver"
 )
 
 // SQLAdapter SQL 解析适配器
@@ -21,6 +27,10 @@ import (
 type SQLAdapter struct {
 	mu     sync.Mutex
 	parser *parser.Parser
+
+	// 查询改写钩子，在解析锁之外执行，钩子内部可以再调用 Parse
+	rewriteHooks   *RewriteHooks
+	rewriteContext func() RewriteContext
 }
 
 // simplifyTypeName 简化类型名，移除长度和精度说明
@@ -40,32 +50,103 @@ func NewSQLAdapter() *SQLAdapter {
 	}
 }
 
-// Parse 解析 SQL 语句
-func (a *SQLAdapter) Parse(sql string) (*ParseResult, error) {
+// SetRewriteHooks 设置查询改写钩子，rc 在每次解析时提供当前会话信息（可为 nil）
+func (a *SQLAdapter) SetRewriteHooks(hooks *RewriteHooks, rc func() RewriteContext) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	a.rewriteHooks = hooks
+	a.rewriteContext = rc
+}
+
+// Parse 解析 SQL 语句，设置了改写钩子时先执行 pre-parse 钩子，解析成功后再执行 post-parse 钩子
+func (a *SQLAdapter) Parse(sql string) (*ParseResult, error) {
+	a.mu.Lock()
+	hooks, rcFn := a.rewriteHooks, a.rewriteContext
+	a.mu.Unlock()
+	if hooks.Empty() {
+		return a.parse(sql)
+	}
+
+	var rc RewriteContext
+	if rcFn != nil {
+		rc = rcFn()
+	}
+	rewritten, traces, err := hooks.RewriteSQL(rc, sql)
+	if err != nil {
+		return &ParseResult{Success: false, Error: err.Error()}, err
+	}
+	result, err := a.parse(rewritten)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	postTraces, err := hooks.TransformStatement(rc, result.Statement)
+	if err != nil {
+		return &ParseResult{Success: false, Error: err.Error()}, err
+	}
+	result.Rewrites = append(traces, postTraces...)
+	return result, nil
+}
 
-	// 预处理 SQL：将 WITH 子句转换为 COMMENT 子句
-	preprocessedSQL := preprocessWithClause(sql)
+// parse 解析 SQL 语句（不执行改写钩子）
+func (a *SQLAdapter) parse(sql string) (*ParseResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	stmtNodes, _, err := a.parser.Parse(preprocessedSQL, "", "")
+	ext, err := parseExtensionStatement(sql)
 	if err != nil {
 		return &ParseResult{
 			Success: false,
 			Error:   err.Error(),
 		}, fmt.Errorf("parse SQL failed: %w", err)
 	}
+	if ext != nil {
+		ext.TableRefs = extensionTableRefs(ext)
+		return &ParseResult{Statement: ext, Success: true}, nil
+	}
 
-	if len(stmtNodes) == 0 {
+	// ENCRYPTED 列属性不属于 TiDB 语法，解析前去除（命中缓存时也需要提取）
+	tidbSQL, encrypted, err := extractEncryptedColumns(sql)
+	if err != nil {
 		return &ParseResult{
 			Success: false,
-			Error:   "no statements found",
-		}, fmt.Errorf("no statements found")
+			Error:   err.Error(),
+		}, fmt.Errorf("parse SQL failed: %w", err)
+	}
+
+	// 命中解析缓存时跳过 TiDB 解析，仅重新转换 AST
+	stmt, cached := globalParseCache.Get(sql)
+	if !cached {
+		// 预处理 SQL：将 WITH 子句转换为 COMMENT 子句，FIRST/LAST 聚合改写为 FIRST_BY/LAST_BY
+		preprocessedSQL := preprocessWithClause(preprocessTTLClause(preprocessAsOfClause(preprocessTableSample(preprocessTimeSeriesAggregates(tidbSQL)))))
+
+		stmtNodes, _, err := a.parser.Parse(preprocessedSQL, "", "")
+		if err != nil {
+			return &ParseResult{
+				Success: false,
+				Error:   err.Error(),
+			}, fmt.Errorf("parse SQL failed: %w", err)
+		}
+
+		if len(stmtNodes) == 0 {
+			return &ParseResult{
+				Success: false,
+				Error:   "no statements found",
+			}, fmt.Errorf("no statements found")
+		}
+
+		// 只处理第一条语句
+		stmt = stmtNodes[0]
+		globalParseCache.Put(sql, stmt)
 	}
 
-	// 只处理第一条语句
-	stmt := stmtNodes[0]
 	statement, err := a.convertToStatement(stmt)
+	if err == nil {
+		statement.TableRefs = astTableRefs(stmt)
+		err = a.applyAsOf(stmt, statement)
+	}
+	if err == nil {
+		err = applyEncryptedColumns(statement, encrypted)
+	}
 	if err != nil {
 		return &ParseResult{
 			Success: false,
@@ -92,6 +173,10 @@ func (a *SQLAdapter) ParseMulti(sql string) ([]*ParseResult, error) {
 	results := make([]*ParseResult, 0, len(stmtNodes))
 	for _, stmt := range stmtNodes {
 		statement, err := a.convertToStatement(stmt)
+		if err == nil {
+			statement.TableRefs = astTableRefs(stmt)
+			err = a.applyAsOf(stmt, statement)
+		}
 		if err != nil {
 			results = append(results, &ParseResult{
 				Success: false,
@@ -190,6 +275,26 @@ func (a *SQLAdapter) convertToStatement(node ast.StmtNode) (*SQLStatement, error
 		}
 		stmt.Alter = alterStmt
 
+	case *ast.RollbackStmt:
+		stmt.Type = SQLTypeRollback
+		stmt.Rollback = &TransactionStatement{Savepoint: stmtNode.SavepointName}
+
+	case *ast.SavepointStmt:
+		stmt.Type = SQLTypeSavepoint
+		stmt.Savepoint = &SavepointStatement{Name: stmtNode.Name}
+
+	case *ast.ReleaseSavepointStmt:
+		stmt.Type = SQLTypeRelease
+		stmt.Savepoint = &SavepointStatement{Name: stmtNode.Name}
+
+	case *ast.OptimizeTableStmt:
+		stmt.Type = SQLTypeOptimize
+		optimizeStmt := &OptimizeStatement{}
+		for _, table := range stmtNode.Tables {
+			optimizeStmt.Tables = append(optimizeStmt.Tables, table.Name.String())
+		}
+		stmt.Optimize = optimizeStmt
+
 	case *ast.CreateIndexStmt:
 		stmt.Type = SQLTypeCreate
 		createIndexStmt, err := a.convertCreateIndexStmt(stmtNode)
@@ -264,6 +369,29 @@ func (a *SQLAdapter) convertToStatement(node ast.StmtNode) (*SQLStatement, error
 		setStmt := a.convertSetStmt(stmtNode)
 		stmt.Set = setStmt
 
+	case *ast.CreateSequenceStmt:
+		stmt.Type = SQLTypeCreateSeq
+		stmt.Sequence = &SequenceStatement{
+			Names:       []string{qualifiedTableName(stmtNode.Name)},
+			IfNotExists: stmtNode.IfNotExists,
+			Options:     a.convertSequenceOptions(stmtNode.SeqOptions),
+		}
+
+	case *ast.AlterSequenceStmt:
+		stmt.Type = SQLTypeAlterSeq
+		stmt.Sequence = &SequenceStatement{
+			Names:    []string{qualifiedTableName(stmtNode.Name)},
+			IfExists: stmtNode.IfExists,
+			Options:  a.convertSequenceOptions(stmtNode.SeqOptions),
+		}
+
+	case *ast.DropSequenceStmt:
+		stmt.Type = SQLTypeDropSeq
+		stmt.Sequence = &SequenceStatement{IfExists: stmtNode.IfExists}
+		for _, name := range stmtNode.Sequences {
+			stmt.Sequence.Names = append(stmt.Sequence.Names, qualifiedTableName(name))
+		}
+
 	default:
 		stmt.Type = SQLTypeUnknown
 	}
@@ -275,6 +403,23 @@ func (a *SQLAdapter) convertToStatement(node ast.StmtNode) (*SQLStatement, error
 func (a *SQLAdapter) convertSelectStmt(stmt *ast.SelectStmt) (*SelectStatement, error) {
 	selectStmt := &SelectStatement{
 		Distinct: stmt.Distinct,
+		Hints:    extractSelectHints(stmt.Text()),
+	}
+	if stmt.SelectStmtOpts != nil && stmt.SelectStmtOpts.StraightJoin {
+		selectStmt.StraightJoin = true
+	}
+	if stmt.LockInfo != nil {
+		switch stmt.LockInfo.LockType {
+		case ast.SelectLockForUpdate, ast.SelectLockForUpdateNoWait:
+			selectStmt.Lock = "UPDATE"
+		case ast.SelectLockForShare, ast.SelectLockForShareNoWait:
+			selectStmt.Lock = "SHARE"
+		case ast.SelectLockNone:
+		default:
+			return nil, fmt.Errorf("unsupported locking read: %s", stmt.LockInfo.LockType)
+		}
+		selectStmt.LockNoWait = stmt.LockInfo.LockType == ast.SelectLockForUpdateNoWait ||
+			stmt.LockInfo.LockType == ast.SelectLockForShareNoWait
 	}
 
 	// 解析 SELECT 列
@@ -291,8 +436,9 @@ func (a *SQLAdapter) convertSelectStmt(stmt *ast.SelectStmt) (*SelectStatement,
 
 	// 解析 FROM
 	if stmt.From != nil && stmt.From.TableRefs != nil {
-		// 从 Join 的左表获取主表名
-		if tableSource, ok := stmt.From.TableRefs.Left.(*ast.TableSource); ok {
+		// JOIN 树最左侧的表是主表
+		var main *ast.TableName
+		if tableSource := leftmostTableSource(stmt.From.TableRefs); tableSource != nil {
 			if tableName, ok := tableSource.Source.(*ast.TableName); ok {
 				// Preserve full qualified table name (schema.table)
 				fullName := tableName.Name.String()
@@ -300,14 +446,34 @@ func (a *SQLAdapter) convertSelectStmt(stmt *ast.SelectStmt) (*SelectStatement,
 					fullName = tableName.Schema.String() + "." + fullName
 				}
 				selectStmt.From = fullName
+				selectStmt.Database = tableName.Schema.String()
+				selectStmt.FromAlias = tableSource.AsName.String()
+				main = tableName
 			}
 		}
+		sample, err := fromTableSample(stmt.From.TableRefs, main)
+		if err != nil {
+			return nil, err
+		}
+		selectStmt.Sample = sample
 
-		// 解析 JOIN（从右表）
+		// 解析 JOIN：a JOIN b JOIN c 为 Join{Join{a, b}, c}，每个有右表的 Join 节点是一个 JOIN
 		if stmt.From.TableRefs.Right != nil {
-			selectStmt.Joins = a.convertJoinTree(stmt.From.TableRefs.Right)
+			selectStmt.Joins = a.convertJoinTree(stmt.From.TableRefs)
+		}
+
+		// t1 STRAIGHT_JOIN t2 以及 USE/FORCE/IGNORE INDEX 子句转换为等价的 hint
+		var tableHints []string
+		if collectTableRefHints(stmt.From.TableRefs, &tableHints) {
+			selectStmt.StraightJoin = true
+		}
+		if len(tableHints) > 0 {
+			selectStmt.Hints = strings.TrimSpace(selectStmt.Hints + " " + strings.Join(tableHints, " "))
 		}
 	}
+	if selectStmt.StraightJoin && !strings.Contains(strings.ToUpper(selectStmt.Hints), "STRAIGHT_JOIN") {
+		selectStmt.Hints = strings.TrimSpace(selectStmt.Hints + " STRAIGHT_JOIN")
+	}
 
 	// 解析 WHERE
 	if stmt.Where != nil {
@@ -322,8 +488,16 @@ func (a *SQLAdapter) convertSelectStmt(stmt *ast.SelectStmt) (*SelectStatement,
 	if stmt.GroupBy != nil {
 		selectStmt.GroupBy = make([]string, 0, len(stmt.GroupBy.Items))
 		for _, item := range stmt.GroupBy.Items {
-			if col, ok := item.Expr.(*ast.ColumnNameExpr); ok {
-				selectStmt.GroupBy = append(selectStmt.GroupBy, col.Name.Name.String())
+			name, expr := a.convertGroupByItem(item.Expr, stmt.Fields)
+			if name == "" {
+				continue
+			}
+			selectStmt.GroupBy = append(selectStmt.GroupBy, name)
+			if expr != nil {
+				if selectStmt.GroupByExprs == nil {
+					selectStmt.GroupByExprs = make(map[string]*Expression)
+				}
+				selectStmt.GroupByExprs[name] = expr
 			}
 		}
 	}
@@ -341,22 +515,8 @@ func (a *SQLAdapter) convertSelectStmt(stmt *ast.SelectStmt) (*SelectStatement,
 	if stmt.OrderBy != nil {
 		selectStmt.OrderBy = make([]OrderByItem, 0, len(stmt.OrderBy.Items))
 		for _, item := range stmt.OrderBy.Items {
-			direction := "ASC"
-			if item.Desc {
-				direction = "DESC"
-			}
-			// Handle column name expressions
-			if col, ok := item.Expr.(*ast.ColumnNameExpr); ok {
-				selectStmt.OrderBy = append(selectStmt.OrderBy, OrderByItem{
-					Column:    col.Name.Name.String(),
-					Direction: direction,
-				})
-			} else if funcCall, ok := item.Expr.(*ast.FuncCallExpr); ok {
-				// Handle function expressions like vec_cosine_distance(...)
-				selectStmt.OrderBy = append(selectStmt.OrderBy, OrderByItem{
-					Column:    extractFuncCallString(funcCall),
-					Direction: direction,
-				})
+			if orderItem, ok := a.convertOrderByItem(item, stmt.Fields); ok {
+				selectStmt.OrderBy = append(selectStmt.OrderBy, orderItem)
 			}
 		}
 	}
@@ -431,6 +591,69 @@ func (a *SQLAdapter) convertSelectStmt(stmt *ast.SelectStmt) (*SelectStatement,
 	return selectStmt, nil
 }
 
+// selectHintPattern 匹配紧跟在 SELECT 关键字后的 /*+ ... */ 优化器 hint 注释
+var selectHintPattern = regexp.MustCompile(`(?is)^\s*(?:\(\s*)*SELECT\s*/\*\+(.*?)\*/`)
+
+// extractSelectHints 返回 SELECT 语句 hint 注释中的内容，没有 hint 时返回空字符串
+func extractSelectHints(text string) string {
+	match := selectHintPattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
+
+// collectTableRefHints 将 FROM 子句中的索引提示（USE/FORCE/IGNORE INDEX）转换为 hint 文本，
+// 返回 JOIN 树中是否出现了 STRAIGHT_JOIN 连接
+func collectTableRefHints(node ast.ResultSetNode, hints *[]string) bool {
+	switch n := node.(type) {
+	case *ast.Join:
+		straight := n.StraightJoin
+		if collectTableRefHints(n.Left, hints) {
+			straight = true
+		}
+		if n.Right != nil && collectTableRefHints(n.Right, hints) {
+			straight = true
+		}
+		return straight
+	case *ast.TableSource:
+		tableName, ok := n.Source.(*ast.TableName)
+		if !ok {
+			return false
+		}
+		for _, indexHint := range tableName.IndexHints {
+			var name string
+			switch indexHint.HintType {
+			case ast.HintUse:
+				name = "USE_INDEX"
+			case ast.HintForce:
+				name = "FORCE_INDEX"
+			case ast.HintIgnore:
+				name = "IGNORE_INDEX"
+			default:
+				continue
+			}
+			args := []string{tableName.Name.O}
+			for _, idx := range indexHint.IndexNames {
+				args = append(args, idx.O)
+			}
+			*hints = append(*hints, name+"("+strings.Join(args, " ")+")")
+		}
+	}
+	return false
+}
+
+// leftmostTableSource 返回 JOIN 树最左侧的表
+func leftmostTableSource(node ast.ResultSetNode) *ast.TableSource {
+	switch n := node.(type) {
+	case *ast.Join:
+		return leftmostTableSource(n.Left)
+	case *ast.TableSource:
+		return n
+	}
+	return nil
+}
+
 // convertJoinTree 递归转换 JOIN 树
 func (a *SQLAdapter) convertJoinTree(node ast.ResultSetNode) []JoinInfo {
 	result := make([]JoinInfo, 0)
@@ -446,6 +669,11 @@ func (a *SQLAdapter) convertJoinTree(node ast.ResultSetNode) []JoinInfo {
 			result = append(result, leftJoins...)
 		}
 
+		// 只有左表的 Join 节点（FROM t）不是 JOIN
+		if n.Right == nil {
+			return result
+		}
+
 		// 处理当前 JOIN
 		joinType := JoinTypeInner
 		switch n.Tp {
@@ -454,7 +682,10 @@ func (a *SQLAdapter) convertJoinTree(node ast.ResultSetNode) []JoinInfo {
 		case ast.RightJoin:
 			joinType = JoinTypeRight
 		case ast.CrossJoin:
-			joinType = JoinTypeCross
+			// 解析器将 a JOIN b ON ... 也记为 CrossJoin，带 ON 条件时是内连接
+			if n.On == nil {
+				joinType = JoinTypeCross
+			}
 		}
 
 		joinInfo := JoinInfo{
@@ -465,6 +696,7 @@ func (a *SQLAdapter) convertJoinTree(node ast.ResultSetNode) []JoinInfo {
 		if tableSource, ok := n.Right.(*ast.TableSource); ok {
 			if tableName, ok := tableSource.Source.(*ast.TableName); ok {
 				joinInfo.Table = tableName.Name.String()
+				joinInfo.Database = tableName.Schema.String()
 				if tableSource.AsName.L != "" {
 					joinInfo.Alias = tableSource.AsName.String()
 				}
@@ -491,17 +723,20 @@ func (a *SQLAdapter) convertJoinTree(node ast.ResultSetNode) []JoinInfo {
 // convertInsertStmt 转换 INSERT 语句
 func (a *SQLAdapter) convertInsertStmt(stmt *ast.InsertStmt) (*InsertStatement, error) {
 	// 从 TableRefsClause 获取表名
-	var tableName string
+	var tableName, database string
 	if stmt.Table != nil && stmt.Table.TableRefs != nil {
 		if tableSource, ok := stmt.Table.TableRefs.Left.(*ast.TableSource); ok {
 			if tableNameNode, ok := tableSource.Source.(*ast.TableName); ok {
 				tableName = tableNameNode.Name.String()
+				database = tableNameNode.Schema.String()
 			}
 		}
 	}
 
 	insertStmt := &InsertStatement{
-		Table: tableName,
+		Table:    tableName,
+		Database: database,
+		Ignore:   stmt.IgnoreErr,
 	}
 
 	// 解析列名
@@ -535,18 +770,11 @@ func (a *SQLAdapter) convertInsertStmt(stmt *ast.InsertStmt) (*InsertStatement,
 			Set:   make(map[string]interface{}),
 		}
 		for _, assign := range stmt.OnDuplicate {
-			col := assign.Column.Name.String()
-			// Check for VALUES(column) reference (MySQL-specific)
-			if valuesExpr, ok := assign.Expr.(*ast.ValuesExpr); ok {
-				refCol := valuesExpr.Column.Name.Name.String()
-				onDup.Set[col] = ValuesRef{Column: refCol}
-				continue
-			}
-			val, err := a.extractValue(assign.Expr)
+			val, err := a.convertAssignment(assign.Expr)
 			if err != nil {
-				continue
+				return nil, fmt.Errorf("ON DUPLICATE KEY UPDATE %s: %w", assign.Column.Name.String(), err)
 			}
-			onDup.Set[col] = val
+			onDup.Set[assign.Column.Name.String()] = val
 		}
 		insertStmt.OnDuplicate = onDup
 	}
@@ -557,26 +785,28 @@ func (a *SQLAdapter) convertInsertStmt(stmt *ast.InsertStmt) (*InsertStatement,
 // convertUpdateStmt 转换 UPDATE 语句
 func (a *SQLAdapter) convertUpdateStmt(stmt *ast.UpdateStmt) (*UpdateStatement, error) {
 	// 从 TableRefsClause 获取表名
-	var tableName string
+	var tableName, database string
 	if stmt.TableRefs != nil && stmt.TableRefs.TableRefs != nil {
 		if tableSource, ok := stmt.TableRefs.TableRefs.Left.(*ast.TableSource); ok {
 			if tableNameNode, ok := tableSource.Source.(*ast.TableName); ok {
 				tableName = tableNameNode.Name.String()
+				database = tableNameNode.Schema.String()
 			}
 		}
 	}
 
 	updateStmt := &UpdateStatement{
-		Table: tableName,
-		Set:   make(map[string]interface{}),
+		Table:    tableName,
+		Database: database,
+		Set:      make(map[string]interface{}),
 	}
 
 	// 解析 SET 子句 (List 是 []*Assignment)
 	for _, assign := range stmt.List {
 		col := assign.Column.Name.String()
-		val, err := a.extractValue(assign.Expr)
+		val, err := a.convertAssignment(assign.Expr)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("SET %s: %w", col, err)
 		}
 		updateStmt.Set[col] = val
 	}
@@ -625,17 +855,19 @@ func (a *SQLAdapter) convertUpdateStmt(stmt *ast.UpdateStmt) (*UpdateStatement,
 // convertDeleteStmt 转换 DELETE 语句
 func (a *SQLAdapter) convertDeleteStmt(stmt *ast.DeleteStmt) (*DeleteStatement, error) {
 	// 从 TableRefsClause 获取表名
-	var tableName string
+	var tableName, database string
 	if stmt.TableRefs != nil && stmt.TableRefs.TableRefs != nil {
 		if tableSource, ok := stmt.TableRefs.TableRefs.Left.(*ast.TableSource); ok {
 			if tableNameNode, ok := tableSource.Source.(*ast.TableName); ok {
 				tableName = tableNameNode.Name.String()
+				database = tableNameNode.Schema.String()
 			}
 		}
 	}
 
 	deleteStmt := &DeleteStatement{
-		Table: tableName,
+		Table:    tableName,
+		Database: database,
 	}
 
 	// 解析 WHERE
@@ -710,6 +942,11 @@ func (a *SQLAdapter) convertCreateTableStmt(stmt *ast.CreateTableStmt) (*CreateS
 			colInfo.VectorType = "float32"
 		}
 
+		// UNSIGNED / ZEROFILL 修饰符会被 simplifyTypeName 截掉，单独记录
+		lowerType := strings.ToLower(colTypeStr)
+		colInfo.Zerofill = strings.Contains(lowerType, "zerofill")
+		colInfo.Unsigned = colInfo.Zerofill || strings.Contains(lowerType, "unsigned")
+
 		// 从 Options 解析列属性
 		for _, opt := range col.Options {
 			switch opt.Tp {
@@ -718,6 +955,14 @@ func (a *SQLAdapter) convertCreateTableStmt(stmt *ast.CreateTableStmt) (*CreateS
 				colInfo.Primary = opt.Tp == ast.ColumnOptionPrimaryKey
 			case ast.ColumnOptionDefaultValue:
 				if opt.Expr != nil {
+					seqDefault, ok, err := a.sequenceDefault(opt.Expr, stmt.Table.Schema.O)
+					if err != nil {
+						return nil, err
+					}
+					if ok {
+						colInfo.Default = seqDefault
+						break
+					}
 					val, _ := a.extractValue(opt.Expr)
 					colInfo.Default = val
 				}
@@ -762,11 +1007,143 @@ func (a *SQLAdapter) convertCreateTableStmt(stmt *ast.CreateTableStmt) (*CreateS
 		if opt.Tp == ast.TableOptionComment {
 			createStmt.Options["comment"] = opt.StrValue
 		}
+		if opt.Tp == ast.TableOptionTTL {
+			ttl, err := convertTTLOption(opt)
+			if err != nil {
+				return nil, err
+			}
+			createStmt.TTL = ttl
+		}
+	}
+
+	if stmt.Partition != nil {
+		partition, err := a.convertPartitionOptions(stmt.Partition)
+		if err != nil {
+			return nil, err
+		}
+		createStmt.Partition = partition
 	}
 
 	return createStmt, nil
 }
 
+// convertPartitionOptions 转换 PARTITION BY 子句
+// 支持 RANGE(col)、RANGE COLUMNS(col)、HASH(col) 和 KEY(col)（按 HASH 处理），分区键必须是单个列
+func (a *SQLAdapter) convertPartitionOptions(opts *ast.PartitionOptions) (*PartitionInfo, error) {
+	if opts.Sub != nil {
+		return nil, fmt.Errorf("subpartitioning is not supported")
+	}
+
+	partition := &PartitionInfo{}
+	switch opts.Tp {
+	case ast.PartitionTypeRange:
+		partition.Type = "RANGE"
+	case ast.PartitionTypeHash, ast.PartitionTypeKey:
+		partition.Type = "HASH"
+	default:
+		return nil, fmt.Errorf("unsupported partition type: %s", opts.Tp.String())
+	}
+
+	switch {
+	case opts.Expr != nil:
+		col, ok := opts.Expr.(*ast.ColumnNameExpr)
+		if !ok {
+			return nil, fmt.Errorf("partition expression must be a column: %s", restoreExprText(opts.Expr))
+		}
+		partition.Column = col.Name.Name.String()
+	case len(opts.ColumnNames) == 1:
+		partition.Column = opts.ColumnNames[0].Name.String()
+	default:
+		return nil, fmt.Errorf("partitioning by exactly one column is supported")
+	}
+
+	defs, err := a.convertPartitionDefinitions(opts.Definitions, partition.Type)
+	if err != nil {
+		return nil, err
+	}
+	partition.Partitions = defs
+
+	// HASH 分区可以只指定 PARTITIONS n，分区名为 p0..pn-1
+	if len(partition.Partitions) == 0 {
+		if partition.Type == "RANGE" {
+			return nil, fmt.Errorf("RANGE partitioning requires partition definitions")
+		}
+		num := int(opts.Num)
+		if num == 0 {
+			num = 1
+		}
+		partition.Partitions = hashPartitionDefs(0, num)
+	}
+
+	return partition, nil
+}
+
+// convertPartitionDefinitions 转换分区定义列表，partitionType 为空时（ALTER TABLE ... ADD PARTITION）不校验分区类型
+func (a *SQLAdapter) convertPartitionDefinitions(defs []*ast.PartitionDefinition, partitionType string) ([]PartitionDef, error) {
+	result := make([]PartitionDef, 0, len(defs))
+	for _, def := range defs {
+		pd := PartitionDef{Name: def.Name.String()}
+		if clause, ok := def.Clause.(*ast.PartitionDefinitionClauseLessThan); ok {
+			if partitionType == "HASH" || len(clause.Exprs) != 1 {
+				return nil, fmt.Errorf("invalid VALUES LESS THAN clause for partition %s", pd.Name)
+			}
+			if _, isMax := clause.Exprs[0].(*ast.MaxValueExpr); isMax {
+				pd.MaxValue = true
+			} else {
+				val, err := a.extractValue(clause.Exprs[0])
+				if err != nil {
+					return nil, fmt.Errorf("invalid VALUES LESS THAN value for partition %s: %w", pd.Name, err)
+				}
+				pd.LessThan = val
+			}
+		} else if partitionType == "RANGE" {
+			return nil, fmt.Errorf("RANGE partition %s requires VALUES LESS THAN", pd.Name)
+		}
+		result = append(result, pd)
+	}
+	return result, nil
+}
+
+// ttlUnitDurations INTERVAL 单位对应的时长，MONTH、QUARTER、YEAR 按 30、90、365 天计算
+var ttlUnitDurations = map[ast.TimeUnitType]time.Duration{
+	ast.TimeUnitSecond:  time.Second,
+	ast.TimeUnitMinute:  time.Minute,
+	ast.TimeUnitHour:    time.Hour,
+	ast.TimeUnitDay:     24 * time.Hour,
+	ast.TimeUnitWeek:    7 * 24 * time.Hour,
+	ast.TimeUnitMonth:   30 * 24 * time.Hour,
+	ast.TimeUnitQuarter: 90 * 24 * time.Hour,
+	ast.TimeUnitYear:    365 * 24 * time.Hour,
+}
+
+// convertTTLOption 转换 TTL = col + INTERVAL n UNIT 表选项
+func convertTTLOption(opt *ast.TableOption) (*TTLInfo, error) {
+	if opt.ColumnName == nil || opt.Value == nil || opt.TimeUnitValue == nil {
+		return nil, fmt.Errorf("invalid TTL option")
+	}
+	unit, ok := ttlUnitDurations[opt.TimeUnitValue.Unit]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TTL interval unit: %s", opt.TimeUnitValue.Unit.String())
+	}
+	n, err := strconv.ParseInt(fmt.Sprintf("%v", opt.Value.GetValue()), 10, 64)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("TTL interval must be a positive integer: %v", opt.Value.GetValue())
+	}
+	return &TTLInfo{
+		Column:   opt.ColumnName.Name.String(),
+		Duration: time.Duration(n) * unit,
+	}, nil
+}
+
+// hashPartitionDefs 生成 HASH 分区定义 p{start}..p{start+count-1}
+func hashPartitionDefs(start, count int) []PartitionDef {
+	defs := make([]PartitionDef, count)
+	for i := range defs {
+		defs[i] = PartitionDef{Name: fmt.Sprintf("p%d", start+i)}
+	}
+	return defs
+}
+
 // isVectorType 检查是否为 VECTOR 类型
 func isVectorType(typeStr string) bool {
 	upperType := strings.ToUpper(typeStr)
@@ -832,6 +1209,35 @@ func (a *SQLAdapter) convertAlterTableStmt(stmt *ast.AlterTableStmt) (*AlterStat
 			Type: fmt.Sprintf("%d", int(spec.Tp)),
 		}
 
+		switch spec.Tp {
+		case ast.AlterTableAddPartitions:
+			action.Type = AlterActionAddPartition
+			action.PartitionNum = int(spec.Num)
+			defs, err := a.convertPartitionDefinitions(spec.PartDefinitions, "")
+			if err != nil {
+				return nil, err
+			}
+			action.Partitions = defs
+		case ast.AlterTableDropPartition:
+			action.Type = AlterActionDropPartition
+			for _, name := range spec.PartitionNames {
+				action.PartitionNames = append(action.PartitionNames, name.String())
+			}
+		case ast.AlterTableRemoveTTL:
+			action.Type = AlterActionRemoveTTL
+		case ast.AlterTableOption:
+			for _, opt := range spec.Options {
+				if opt.Tp == ast.TableOptionTTL {
+					ttl, err := convertTTLOption(opt)
+					if err != nil {
+						return nil, err
+					}
+					action.Type = AlterActionSetTTL
+					action.TTL = ttl
+				}
+			}
+		}
+
 		if spec.NewColumnName != nil {
 			action.OldName = spec.OldColumnName.Name.String()
 			action.NewName = spec.NewColumnName.Name.String()
@@ -843,6 +1249,120 @@ func (a *SQLAdapter) convertAlterTableStmt(stmt *ast.AlterTableStmt) (*AlterStat
 	return alterStmt, nil
 }
 
+// convertGroupByItem 转换 GROUP BY 项，返回分组名和分组表达式（普通列返回 nil 表达式）
+// 支持列名、SELECT 列别名（GROUP BY day）、位置（GROUP BY 1）和任意表达式（GROUP BY DATE(created_at)）。
+// 表达式与某个 SELECT 列相同时，分组名取该列的输出名，使聚合结果中的列名与 SELECT 列一致
+func (a *SQLAdapter) convertGroupByItem(node ast.ExprNode, fields *ast.FieldList) (string, *Expression) {
+	var field *ast.SelectField
+	switch n := node.(type) {
+	case *ast.ColumnNameExpr:
+		name := n.Name.Name.String()
+		if n.Name.Table.L == "" && fields != nil {
+			// 别名引用（GROUP BY day）按别名对应的 SELECT 表达式分组
+			for _, f := range fields.Fields {
+				if f.AsName.L == n.Name.Name.L {
+					field = f
+					break
+				}
+			}
+		}
+		if field == nil {
+			return name, nil
+		}
+	case *ast.PositionExpr:
+		if fields == nil || n.N < 1 || n.N > len(fields.Fields) {
+			return "", nil
+		}
+		field = fields.Fields[n.N-1]
+		if col, ok := field.Expr.(*ast.ColumnNameExpr); ok && field.AsName.L == "" {
+			return col.Name.Name.String(), nil
+		}
+	default:
+		if fields != nil {
+			text := restoreExprText(node)
+			for _, f := range fields.Fields {
+				if f.Expr != nil && restoreExprText(f.Expr) == text {
+					field = f
+					break
+				}
+			}
+		}
+		if field == nil {
+			expr, err := a.convertExpression(node)
+			if err != nil {
+				return "", nil
+			}
+			return restoreExprText(node), expr
+		}
+	}
+
+	expr, err := a.convertExpression(field.Expr)
+	if err != nil {
+		return "", nil
+	}
+	name := field.AsName.String()
+	if name == "" {
+		name = strings.TrimSpace(field.Text())
+	}
+	if name == "" {
+		name = restoreExprText(field.Expr)
+	}
+	return name, expr
+}
+
+// convertOrderByItem 转换 SELECT 的 ORDER BY 项
+// 列名和 SELECT 列别名按列名排序；位置（ORDER BY 2）解析为对应的 SELECT 列；
+// 聚合函数（ORDER BY COUNT(*)）保留表达式，由优化器解析为聚合输出列
+func (a *SQLAdapter) convertOrderByItem(item *ast.ByItem, fields *ast.FieldList) (OrderByItem, bool) {
+	direction := "ASC"
+	if item.Desc {
+		direction = "DESC"
+	}
+
+	node := item.Expr
+	if pos, ok := node.(*ast.PositionExpr); ok {
+		if fields == nil || pos.N < 1 || pos.N > len(fields.Fields) {
+			return OrderByItem{}, false
+		}
+		field := fields.Fields[pos.N-1]
+		if field.AsName.L != "" {
+			return OrderByItem{Column: field.AsName.String(), Direction: direction}, true
+		}
+		node = field.Expr
+	}
+
+	// ORDER BY name COLLATE utf8mb4_general_ci：记录排序规则，按内部表达式排序
+	collation := ""
+	if c, ok := node.(*ast.SetCollationExpr); ok {
+		collation = strings.ToLower(c.Collate)
+		node = c.Expr
+	}
+
+	switch n := node.(type) {
+	case *ast.ColumnNameExpr:
+		return OrderByItem{Column: n.Name.Name.String(), Direction: direction, Collation: collation}, true
+	case *ast.FuncCallExpr:
+		// Handle function expressions like vec_cosine_distance(...)
+		return OrderByItem{Column: extractFuncCallString(n), Direction: direction, Collation: collation}, true
+	case *ast.AggregateFuncExpr:
+		expr, err := a.convertExpression(n)
+		if err != nil {
+			return OrderByItem{}, false
+		}
+		return OrderByItem{Column: restoreExprText(n), Direction: direction, Collation: collation, Expr: expr}, true
+	}
+	return OrderByItem{}, false
+}
+
+// restoreExprText 将表达式还原为规范化的 SQL 文本，用于比较两个表达式是否相同
+func restoreExprText(node ast.ExprNode) string {
+	var sb strings.Builder
+	if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return ""
+	}
+	return sb.String()
+}
+
 // convertSelectField 转换 SELECT 字段
 func (a *SQLAdapter) convertSelectField(field *ast.SelectField) (*SelectColumn, error) {
 	col := &SelectColumn{}
@@ -914,6 +1434,9 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 		// ValueExpr 是接口类型
 		expr.Type = ExprTypeValue
 		expr.Value = n.GetValue()
+		if dec, ok := expr.Value.(*test_driver.MyDecimal); ok {
+			expr.Value = decimalLiteralValue(dec)
+		}
 
 	case *ast.FuncCallExpr:
 		expr.Type = ExprTypeFunction
@@ -927,10 +1450,12 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 
 	case *ast.AggregateFuncExpr:
 		// Aggregate functions such as COUNT(*), SUM(col), AVG(col), etc.
+		// GROUP_CONCAT 的最后一个参数是分隔符（未指定时 TiDB 填充 ","）
 		expr.Type = ExprTypeFunction
 		expr.Function = strings.ToUpper(n.F)
 		// Store the function name in Value so that parseAggregationFunction can read it.
 		expr.Value = n.F
+		expr.Distinct = n.Distinct
 		args := make([]Expression, 0, len(n.Args))
 		for _, arg := range n.Args {
 			converted, _ := a.convertExpression(arg)
@@ -939,20 +1464,64 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 			}
 		}
 		expr.Args = args
+		if n.Order != nil {
+			for _, item := range n.Order.Items {
+				direction := "ASC"
+				if item.Desc {
+					direction = "DESC"
+				}
+				if col, ok := item.Expr.(*ast.ColumnNameExpr); ok {
+					expr.OrderBy = append(expr.OrderBy, OrderByItem{
+						Column:    col.Name.Name.String(),
+						Direction: direction,
+					})
+				}
+			}
+		}
 
 	case *ast.PatternLikeOrIlikeExpr:
-		// 处理 LIKE 表达式
+		// 处理 LIKE / ILIKE 表达式
+		expr.Type = ExprTypeOperator
+		expr.Operator = "LIKE"
+		if !n.IsLike {
+			expr.Operator = "ILIKE"
+		}
+		if n.Not {
+			expr.Operator = "NOT " + expr.Operator
+		}
+		// 只记录非默认的转义字符，默认为反斜杠
+		if n.Escape != 0 && n.Escape != '\\' {
+			expr.Escape = string(rune(n.Escape))
+		}
+		left, _ := a.convertExpression(n.Expr)
+		right, _ := a.convertExpression(n.Pattern)
+		expr.Left = left
+		expr.Right = right
+
+	case *ast.PatternRegexpExpr:
+		// 处理 REGEXP / RLIKE 表达式
 		expr.Type = ExprTypeOperator
 		if n.Not {
-			expr.Operator = "NOT LIKE"
+			expr.Operator = "NOT REGEXP"
 		} else {
-			expr.Operator = "LIKE"
+			expr.Operator = "REGEXP"
 		}
 		left, _ := a.convertExpression(n.Expr)
 		right, _ := a.convertExpression(n.Pattern)
 		expr.Left = left
 		expr.Right = right
 
+	case *ast.SetCollationExpr:
+		// COLLATE 子句：转换内部表达式并记录排序规则
+		innerExpr, err := a.convertExpression(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if innerExpr != nil {
+			innerExpr.Collation = strings.ToLower(n.Collate)
+		}
+		return innerExpr, nil
+
 	case *ast.BetweenExpr:
 		// 处理 BETWEEN 表达式
 		expr.Type = ExprTypeOperator
@@ -967,10 +1536,10 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 		leftBound, _ := a.convertExpression(n.Left)
 		rightBound, _ := a.convertExpression(n.Right)
 		// 将 BETWEEN 转换为一个包含两个值的列表
-		// expr.Left: 列, expr.Right: [min, max]
+		// expr.Left: 列, expr.Right: [min, max]；常量边界直接存值，便于下推给数据源
 		expr.Right = &Expression{
 			Type:  ExprTypeValue,
-			Value: []interface{}{leftBound, rightBound},
+			Value: []interface{}{betweenBound(leftBound), betweenBound(rightBound)},
 		}
 
 	case *ast.PatternInExpr:
@@ -983,17 +1552,30 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 		}
 		left, _ := a.convertExpression(n.Expr)
 		expr.Left = left
-		// 提取 IN 列表中的所有值
+		// IN (SELECT ...)：子查询在执行前物化为值列表
+		if sub, ok := n.Sel.(*ast.SubqueryExpr); ok {
+			sel, ok := sub.Query.(*ast.SelectStmt)
+			if !ok {
+				return nil, fmt.Errorf("unsupported IN subquery: %T", sub.Query)
+			}
+			subStmt, err := a.convertSelectStmt(sel)
+			if err != nil {
+				return nil, err
+			}
+			expr.Right = &Expression{Type: ExprTypeSubquery, Subquery: subStmt}
+			break
+		}
+		// 提取 IN 列表中的所有值，非常量项（如 IN (a, b + 1)）保留为表达式
 		values := make([]interface{}, 0, len(n.List))
 		for _, item := range n.List {
-			if valExpr, ok := item.(ast.ValueExpr); ok {
-				values = append(values, valExpr.GetValue())
+			converted, err := a.convertExpression(item)
+			if err != nil {
+				return nil, err
+			}
+			if converted.Type == ExprTypeValue {
+				values = append(values, converted.Value)
 			} else {
-				// 复杂表达式，尝试转换
-				converted, _ := a.convertExpression(item)
-				if converted != nil && converted.Type == ExprTypeValue {
-					values = append(values, converted.Value)
-				}
+				values = append(values, converted)
 			}
 		}
 		expr.Right = &Expression{
@@ -1020,6 +1602,74 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 		}
 		return innerExpr, nil
 
+	case *ast.UnaryOperationExpr:
+		// NOT x 转换为 not 运算符（操作数在 Left），负数常量直接取负，-x 转换为 0 - x
+		operand, err := a.convertExpression(n.V)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case opcode.Not, opcode.Not2:
+			expr.Type = ExprTypeOperator
+			expr.Operator = "not"
+			expr.Left = operand
+		case opcode.Plus:
+			return operand, nil
+		case opcode.Minus:
+			if operand.Type == ExprTypeValue {
+				if val, err := a.extractValue(n); err == nil {
+					expr.Type = ExprTypeValue
+					expr.Value = val
+					break
+				}
+			}
+			expr.Type = ExprTypeOperator
+			expr.Operator = "minus"
+			expr.Left = &Expression{Type: ExprTypeValue, Value: int64(0)}
+			expr.Right = operand
+		default:
+			expr.Type = ExprTypeValue
+		}
+
+	case *ast.CaseExpr:
+		// CASE 表达式转换为函数 CASE(cond1, result1, ..., condN, resultN[, else])；
+		// CASE x WHEN v THEN ... 的条件为 x = v
+		expr.Type = ExprTypeFunction
+		expr.Function = CaseFunc
+		var operand *Expression
+		if n.Value != nil {
+			operand, _ = a.convertExpression(n.Value)
+		}
+		for _, when := range n.WhenClauses {
+			cond, _ := a.convertExpression(when.Expr)
+			if operand != nil {
+				cond = &Expression{Type: ExprTypeOperator, Operator: "eq", Left: operand, Right: cond}
+			}
+			result, _ := a.convertExpression(when.Result)
+			expr.Args = append(expr.Args, *cond, *result)
+		}
+		if n.ElseClause != nil {
+			elseResult, _ := a.convertExpression(n.ElseClause)
+			expr.Args = append(expr.Args, *elseResult)
+		}
+
+	case *ast.FuncCastExpr:
+		// CAST(x AS type)、CONVERT(x, type) 和 BINARY x 转换为函数 CAST(x, 'type')
+		var sb strings.Builder
+		n.Tp.RestoreAsCastType(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb), false)
+		arg, err := a.convertExpression(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		expr.Type = ExprTypeFunction
+		expr.Function = CastFunc
+		expr.Args = []Expression{*arg, {Type: ExprTypeValue, Value: sb.String()}}
+
+	case *ast.ValuesExpr:
+		// ON DUPLICATE KEY UPDATE 中的 VALUES(col)，求值前替换为待插入行的值
+		expr.Type = ExprTypeValue
+		expr.Value = ValuesRef{Column: n.Column.Name.Name.String()}
+
 	case *ast.VariableExpr:
 		// 系统变量或会话变量：@@var_name 或 @var_name
 		expr.Type = ExprTypeColumn
@@ -1036,12 +1686,37 @@ func (a *SQLAdapter) convertExpression(node ast.ExprNode) (*Expression, error) {
 	return expr, nil
 }
 
+// betweenBound 返回 BETWEEN 边界：常量为其值，其它表达式原样保留
+func betweenBound(bound *Expression) interface{} {
+	if bound != nil && bound.Type == ExprTypeValue {
+		return bound.Value
+	}
+	return bound
+}
+
 // extractValue 提取值
 func (a *SQLAdapter) extractValue(node ast.ExprNode) (interface{}, error) {
 	if node == nil {
 		return nil, fmt.Errorf("node is nil")
 	}
 
+	// 负数常量：-4、-2.5
+	if unary, ok := node.(*ast.UnaryOperationExpr); ok && unary.Op == opcode.Minus {
+		val, err := a.extractValue(unary.V)
+		if err != nil {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case int64:
+			return -v, nil
+		case uint64:
+			return -int64(v), nil
+		case float64:
+			return -v, nil
+		}
+		return nil, fmt.Errorf("not a numeric value: %v", val)
+	}
+
 	// 尝试转换为ValueExpr
 	if valExpr, ok := node.(ast.ValueExpr); ok {
 		val := valExpr.GetValue()
@@ -1129,6 +1804,29 @@ func convertTiDBValue(val interface{}) (interface{}, error) {
 	}
 }
 
+// decimalLiteralValue 转换 DECIMAL 常量（如 19.99）
+// float64 的最短表示还原为同一个十进制数时转换为 float64（与 DECIMAL 列中保存的值一致），
+// 否则保留十进制文本，不静默丢失精度（与数值比较时按数值规则转换）
+func decimalLiteralValue(dec *test_driver.MyDecimal) interface{} {
+	text := dec.String()
+	f, err := parseDecimalString(text)
+	if err != nil || normalizeDecimalText(strconv.FormatFloat(f, 'f', -1, 64)) != normalizeDecimalText(text) {
+		return text
+	}
+	return f
+}
+
+// normalizeDecimalText 去掉十进制文本小数部分末尾的 0，"-0" 统一为 "0"
+func normalizeDecimalText(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
 // parseDecimalString 尝试解析DECIMAL字符串为float64
 func parseDecimalString(s string) (float64, error) {
 	// 使用Go的strconv解析
@@ -1179,11 +1877,24 @@ func (a *SQLAdapter) collectColumnNames(expr ast.ExprNode, names map[string]bool
 		// 括号表达式，递归处理内部表达式
 		a.collectColumnNames(n.Expr, names)
 
+	case *ast.FuncCastExpr:
+		// CAST / CONVERT 表达式，递归处理被转换的值
+		a.collectColumnNames(n.Expr, names)
+
 	case *ast.PatternLikeOrIlikeExpr:
 		// LIKE 表达式，递归处理
 		a.collectColumnNames(n.Expr, names)
 		a.collectColumnNames(n.Pattern, names)
 
+	case *ast.PatternRegexpExpr:
+		// REGEXP 表达式，递归处理
+		a.collectColumnNames(n.Expr, names)
+		a.collectColumnNames(n.Pattern, names)
+
+	case *ast.SetCollationExpr:
+		// COLLATE 表达式，递归处理内部表达式
+		a.collectColumnNames(n.Expr, names)
+
 	case *ast.BetweenExpr:
 		// BETWEEN 表达式，递归处理
 		a.collectColumnNames(n.Expr, names)
@@ -1227,6 +1938,14 @@ func (a *SQLAdapter) convertShowStmt(stmt *ast.ShowStmt) (*ShowStatement, error)
 		showStmt.Type = "VARIABLES"
 	case ast.ShowStatus:
 		showStmt.Type = "STATUS"
+	case ast.ShowPlugins:
+		showStmt.Type = "PLUGINS"
+	case ast.ShowWarnings, ast.ShowErrors:
+		showStmt.Type = "WARNINGS"
+		if stmt.Tp == ast.ShowErrors {
+			showStmt.Type = "ERRORS"
+		}
+		showStmt.Count = stmt.CountWarningsOrErrors
 	default:
 		showStmt.Type = "UNKNOWN"
 	}
@@ -1302,10 +2021,13 @@ func (a *SQLAdapter) convertCreateIndexStmt(stmt *ast.CreateIndexStmt) (*CreateI
 	// 检查是否为向量索引（通过 KeyType 或 USING 子句）
 	indexType := "btree"
 	isVectorIndex := false
+	// 通过 KeyType 或 USING 子句明确声明的向量索引（索引名推断的除外）
+	vectorDeclared := false
 
 	// 优先检查 TiDB 的 KeyType
 	if stmt.KeyType == ast.IndexKeyTypeVector {
 		isVectorIndex = true
+		vectorDeclared = true
 		createIndexStmt.IsVectorIndex = true
 		createIndexStmt.IndexType = "VECTOR"
 		// 从 IndexOption.Tp 提取向量索引类型
@@ -1351,6 +2073,7 @@ func (a *SQLAdapter) convertCreateIndexStmt(stmt *ast.CreateIndexStmt) (*CreateI
 				createIndexStmt.IsVectorIndex = true
 				createIndexStmt.VectorIndexType = usingType
 				createIndexStmt.IndexType = "VECTOR"
+				vectorDeclared = true
 			}
 		}
 
@@ -1402,6 +2125,19 @@ func (a *SQLAdapter) convertCreateIndexStmt(stmt *ast.CreateIndexStmt) (*CreateI
 				// TiDB 向量索引语法：CREATE VECTOR INDEX idx ((VEC_COSINE_DISTANCE(embedding)))
 				// 解析表达式提取列名和度量类型
 				columnName, metric, err := extractVectorDistanceFunc(spec.Expr)
+				if err != nil && !vectorDeclared {
+					// 不是向量距离函数：函数索引，如 CREATE INDEX idx ON t ((LOWER(email)))
+					if len(stmt.IndexPartSpecifications) != 1 {
+						return nil, fmt.Errorf("functional index must consist of a single expression")
+					}
+					createIndexStmt.Expression = restoreExprText(spec.Expr)
+					createIndexStmt.ExpressionColumns = a.extractColumnNames(spec.Expr)
+					sort.Strings(createIndexStmt.ExpressionColumns)
+					createIndexStmt.IsVectorIndex = false
+					createIndexStmt.VectorIndexType = ""
+					createIndexStmt.IndexType = strings.ToUpper(indexType)
+					return createIndexStmt, nil
+				}
 				if err != nil {
 					return nil, fmt.Errorf("invalid vector index expression: %w", err)
 				}
@@ -1567,9 +2303,9 @@ func (a *SQLAdapter) convertCreateViewStmt(stmt *ast.CreateViewStmt) (*CreateVie
 			username := stmt.Definer.Username
 			hostname := stmt.Definer.Hostname
 			if hostname != "" {
-				createViewStmt.Definer = fmt.Sprintf("'%s'@'%s'", username, hostname)
+				createViewStmt.Definer = security.QuoteString(username) + "@" + security.QuoteString(hostname)
 			} else {
-				createViewStmt.Definer = fmt.Sprintf("'%s'", username)
+				createViewStmt.Definer = security.QuoteString(username)
 			}
 		}
 	}
@@ -1642,7 +2378,7 @@ func (a *SQLAdapter) convertSetStmt(stmt *ast.SetStmt) *SetStatement {
 
 	for _, varAssign := range stmt.Variables {
 		// Check for SET NAMES charset
-		if strings.ToUpper(varAssign.Name) == "NAMES" {
+		if varAssign.Name == ast.SetNames || strings.ToUpper(varAssign.Name) == "NAMES" {
 			setStmt.Type = "NAMES"
 			if varAssign.Value != nil {
 				if val, err := a.extractValue(varAssign.Value); err == nil {
@@ -1655,7 +2391,7 @@ func (a *SQLAdapter) convertSetStmt(stmt *ast.SetStmt) *SetStatement {
 		}
 
 		// Check for SET CHARACTER SET charset
-		if strings.ToUpper(varAssign.Name) == "CHARACTER SET" || strings.ToUpper(varAssign.Name) == "CHARSET" {
+		if varAssign.Name == ast.SetCharset || strings.ToUpper(varAssign.Name) == "CHARACTER SET" || strings.ToUpper(varAssign.Name) == "CHARSET" {
 			setStmt.Type = "CHARACTER SET"
 			if varAssign.Value != nil {
 				if val, err := a.extractValue(varAssign.Value); err == nil {
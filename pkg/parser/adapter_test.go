@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"sync"
 	"testing"
 
@@ -159,6 +160,32 @@ func TestParseDecimalString(t *testing.T) {
 	}
 }
 
+// TestConvertDecimalLiteral 测试 DECIMAL 常量的转换：float64 能精确还原的转换为 float64，
+// 否则保留十进制文本；其他字面量（如十六进制、位值）不按 DECIMAL 处理
+func TestConvertDecimalLiteral(t *testing.T) {
+	adapter := NewSQLAdapter()
+	whereValue := func(sql string) interface{} {
+		t.Helper()
+		result, err := adapter.Parse(sql)
+		require.NoError(t, err)
+		require.NotNil(t, result.Statement.Select)
+		require.NotNil(t, result.Statement.Select.Where)
+		require.NotNil(t, result.Statement.Select.Where.Right)
+		return result.Statement.Select.Where.Right.Value
+	}
+
+	assert.Equal(t, 19.99, whereValue("SELECT * FROM t WHERE price = 19.99"))
+	assert.Equal(t, float64(100), whereValue("SELECT * FROM t WHERE price = 100.00"))
+	assert.Equal(t, -0.5, whereValue("SELECT * FROM t WHERE price = -0.50"))
+	// float64 无法精确表示的 DECIMAL 保留十进制文本
+	assert.Equal(t, "12345678901234567890.123456789", whereValue("SELECT * FROM t WHERE price = 12345678901234567890.123456789"))
+	assert.Equal(t, "0.10000000000000000001", whereValue("SELECT * FROM t WHERE price = 0.10000000000000000001"))
+	// 整数和 DOUBLE 字面量不受影响，十六进制字面量不会被当作 DECIMAL 解析
+	assert.Equal(t, int64(7), whereValue("SELECT * FROM t WHERE price = 7"))
+	assert.Equal(t, 1.5e3, whereValue("SELECT * FROM t WHERE price = 1.5e3"))
+	assert.NotEqual(t, float64(65), whereValue("SELECT * FROM t WHERE code = 0x41"))
+}
+
 // TestParseInsertOnDuplicateKeyUpdate tests that ON DUPLICATE KEY UPDATE
 // is properly extracted from the TiDB AST into InsertStatement.OnDuplicate.
 func TestParseInsertOnDuplicateKeyUpdate(t *testing.T) {
@@ -190,6 +217,162 @@ func TestParseInsertWithoutOnDuplicate(t *testing.T) {
 	assert.Nil(t, result.Statement.Insert.OnDuplicate, "OnDuplicate should be nil for plain INSERT")
 }
 
+// TestParseUpdateAssignments 测试 SET 中的常量直接取值，引用列的表达式保留为 *Expression 在行上求值，
+// 不能按行求值的表达式报错而不是被忽略
+func TestParseUpdateAssignments(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("UPDATE items SET name = 'x', qty = qty + 1 WHERE id = 2")
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	set := result.Statement.Update.Set
+	assert.Equal(t, "x", set["name"])
+	require.IsType(t, &Expression{}, set["qty"])
+	assert.True(t, HasRowAssignments(set))
+
+	updates, err := EvaluateAssignments(set, map[string]interface{}{"id": int64(2), "qty": int64(7)}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "x", updates["name"])
+	assert.EqualValues(t, 8, updates["qty"])
+
+	result, err = adapter.Parse("UPDATE items SET qty = (SELECT MAX(qty) FROM items) WHERE id = 2")
+	assert.True(t, err != nil || !result.Success, "subquery assignment must be rejected")
+}
+
+// TestParseGroupByExpressions covers GROUP BY on expressions, aliases and positions.
+func TestParseGroupByExpressions(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT DATE(created_at) AS day, status, amount % 10, COUNT(*) FROM orders GROUP BY day, 2, amount % 10")
+	require.NoError(t, err)
+	sel := result.Statement.Select
+	require.NotNil(t, sel)
+
+	assert.Equal(t, []string{"day", "status", "amount % 10"}, sel.GroupBy)
+	require.Contains(t, sel.GroupByExprs, "day")
+	assert.Equal(t, ExprTypeFunction, sel.GroupByExprs["day"].Type)
+	assert.Equal(t, "DATE", sel.GroupByExprs["day"].Function)
+	assert.NotContains(t, sel.GroupByExprs, "status", "plain column grouping needs no expression")
+	require.Contains(t, sel.GroupByExprs, "amount % 10")
+	assert.Equal(t, ExprTypeOperator, sel.GroupByExprs["amount % 10"].Type)
+
+	// 未出现在 SELECT 列中的表达式使用规范化文本作为分组名
+	result, err = adapter.Parse("SELECT COUNT(*) FROM orders GROUP BY YEAR(created_at)")
+	require.NoError(t, err)
+	sel = result.Statement.Select
+	require.Len(t, sel.GroupBy, 1)
+	assert.Equal(t, "YEAR(`created_at`)", sel.GroupBy[0])
+	assert.Contains(t, sel.GroupByExprs, sel.GroupBy[0])
+}
+
+// TestParseAggregateModifiers covers DISTINCT and ORDER BY / SEPARATOR inside aggregates.
+func TestParseAggregateModifiers(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT COUNT(DISTINCT user_id), GROUP_CONCAT(name ORDER BY name DESC SEPARATOR ';') FROM orders")
+	require.NoError(t, err)
+	cols := result.Statement.Select.Columns
+	require.Len(t, cols, 2)
+
+	count := cols[0].Expr
+	require.NotNil(t, count)
+	assert.True(t, count.Distinct)
+
+	concat := cols[1].Expr
+	require.NotNil(t, concat)
+	assert.Equal(t, "GROUP_CONCAT", concat.Function)
+	assert.False(t, concat.Distinct)
+	require.Len(t, concat.Args, 2, "separator is passed as the last argument")
+	assert.Equal(t, ";", concat.Args[1].Value)
+	assert.Equal(t, []OrderByItem{{Column: "name", Direction: "DESC"}}, concat.OrderBy)
+}
+
+func TestParseCaseExpression(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT SUM(CASE WHEN quarter = 'Q1' THEN amount END), CASE quarter WHEN 'Q2' THEN 1 ELSE 0 END FROM sales")
+	require.NoError(t, err)
+	cols := result.Statement.Select.Columns
+	require.Len(t, cols, 2)
+
+	searched := cols[0].Expr.Args[0]
+	assert.Equal(t, CaseFunc, searched.Function)
+	require.Len(t, searched.Args, 2, "without ELSE only the WHEN/THEN pair is passed")
+	assert.Equal(t, "eq", searched.Args[0].Operator)
+	assert.Equal(t, "amount", searched.Args[1].Column)
+
+	simple := cols[1].Expr
+	assert.Equal(t, CaseFunc, simple.Function)
+	require.Len(t, simple.Args, 3)
+	assert.Equal(t, "eq", simple.Args[0].Operator, "simple CASE compares the operand with each WHEN value")
+	assert.Equal(t, "quarter", simple.Args[0].Left.Column)
+	assert.EqualValues(t, 0, simple.Args[2].Value)
+}
+
+func TestParseCastExpression(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT CAST(price AS DECIMAL(10,2)), CONVERT(id, SIGNED), CAST(name AS CHAR(5)), CONVERT(name USING utf8mb4) FROM t WHERE CAST(created AS DATE) = '2024-03-15'")
+	require.NoError(t, err)
+	stmt := result.Statement.Select
+	require.Len(t, stmt.Columns, 4)
+
+	price := stmt.Columns[0].Expr
+	assert.Equal(t, CastFunc, price.Function)
+	require.Len(t, price.Args, 2)
+	assert.Equal(t, "price", price.Args[0].Column)
+	assert.Equal(t, "DECIMAL(10, 2)", price.Args[1].Value)
+
+	assert.Equal(t, CastFunc, stmt.Columns[1].Expr.Function, "CONVERT(x, type) is the same as CAST")
+	assert.Equal(t, "SIGNED", stmt.Columns[1].Expr.Args[1].Value)
+	assert.Equal(t, "CHAR(5)", stmt.Columns[2].Expr.Args[1].Value)
+
+	using := stmt.Columns[3].Expr
+	assert.True(t, strings.EqualFold(using.Function, "convert"))
+	require.Len(t, using.Args, 2)
+	assert.Equal(t, "utf8mb4", using.Args[1].Value)
+
+	require.NotNil(t, stmt.Where)
+	require.NotNil(t, stmt.Where.Left)
+	assert.Equal(t, CastFunc, stmt.Where.Left.Function)
+	assert.Equal(t, "created", stmt.Where.Left.Args[0].Column)
+	assert.Equal(t, "DATE", stmt.Where.Left.Args[1].Value)
+}
+
+func TestParseOrderByAfterAggregation(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT dept, COUNT(*) c, SUM(salary) FROM emp GROUP BY dept HAVING c > 5 ORDER BY c DESC, COUNT(*), 3, 1 DESC")
+	require.NoError(t, err)
+	stmt := result.Statement.Select
+
+	require.NotNil(t, stmt.Having)
+	assert.Equal(t, "c", stmt.Having.Left.Column)
+
+	require.Len(t, stmt.OrderBy, 4)
+	assert.Equal(t, OrderByItem{Column: "c", Direction: "DESC"}, stmt.OrderBy[0])
+
+	// 聚合函数排序项保留表达式
+	require.NotNil(t, stmt.OrderBy[1].Expr)
+	assert.Equal(t, "ASC", stmt.OrderBy[1].Direction)
+	assert.Equal(t, ExprTypeFunction, stmt.OrderBy[1].Expr.Type)
+
+	// 位置引用解析为对应的 SELECT 列
+	require.NotNil(t, stmt.OrderBy[2].Expr)
+	assert.Equal(t, ExprTypeFunction, stmt.OrderBy[2].Expr.Type)
+	assert.Equal(t, OrderByItem{Column: "dept", Direction: "DESC"}, stmt.OrderBy[3])
+}
+
+func TestParseOrderByCollation(t *testing.T) {
+	result, err := NewSQLAdapter().Parse("SELECT name FROM users ORDER BY name COLLATE utf8mb4_General_CI DESC, id")
+	require.NoError(t, err)
+	stmt := result.Statement.Select
+
+	require.Len(t, stmt.OrderBy, 2)
+	assert.Equal(t, OrderByItem{Column: "name", Direction: "DESC", Collation: "utf8mb4_general_ci"}, stmt.OrderBy[0])
+	assert.Equal(t, OrderByItem{Column: "id", Direction: "ASC"}, stmt.OrderBy[1])
+}
+
 // TestConcurrentParsing ensures the parser mutex prevents data races.
 // Before the fix, concurrent Parse calls would panic with index-out-of-range
 // or type assertion failures in yyParse.
@@ -227,3 +410,65 @@ func TestConcurrentParsing(t *testing.T) {
 		t.Errorf("concurrent parse failed: %v", err)
 	}
 }
+
+// TestParsePatternOperators covers LIKE ... ESCAPE, ILIKE, REGEXP / RLIKE and COLLATE.
+func TestParsePatternOperators(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	tests := []struct {
+		sql      string
+		operator string
+		escape   string
+	}{
+		{"SELECT * FROM t WHERE a LIKE 'x!_%' ESCAPE '!'", "LIKE", "!"},
+		{"SELECT * FROM t WHERE a LIKE 'x\\_%'", "LIKE", ""},
+		{"SELECT * FROM t WHERE a NOT LIKE 'x%'", "NOT LIKE", ""},
+		{"SELECT * FROM t WHERE a ILIKE 'x%'", "ILIKE", ""},
+		{"SELECT * FROM t WHERE a REGEXP '^x'", "REGEXP", ""},
+		{"SELECT * FROM t WHERE a RLIKE '^x'", "REGEXP", ""},
+		{"SELECT * FROM t WHERE a NOT REGEXP '^x'", "NOT REGEXP", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := adapter.Parse(tt.sql)
+			require.NoError(t, err)
+			where := result.Statement.Select.Where
+			require.NotNil(t, where)
+			assert.Equal(t, tt.operator, where.Operator)
+			assert.Equal(t, tt.escape, where.Escape)
+			assert.Equal(t, "a", where.Left.Column)
+		})
+	}
+
+	result, err := adapter.Parse("SELECT * FROM t WHERE a COLLATE utf8mb4_general_ci LIKE 'x%'")
+	require.NoError(t, err)
+	where := result.Statement.Select.Where
+	assert.Equal(t, "a", where.Left.Column)
+	assert.Equal(t, "utf8mb4_general_ci", where.Left.Collation)
+}
+
+// TestSQLAdapter_SelectHints 测试 SELECT 中的优化器 hint 与索引提示
+func TestSQLAdapter_SelectHints(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT /*+ MAX_EXECUTION_TIME(100) PARALLEL(2) */ * FROM t1 FORCE INDEX (idx_a) JOIN t2 ON t1.id = t2.id")
+	require.NoError(t, err)
+	sel := result.Statement.Select
+	require.NotNil(t, sel)
+	assert.Equal(t, "MAX_EXECUTION_TIME(100) PARALLEL(2) FORCE_INDEX(t1 idx_a)", sel.Hints)
+	assert.False(t, sel.StraightJoin)
+
+	result, err = adapter.Parse("SELECT STRAIGHT_JOIN * FROM t1, t2")
+	require.NoError(t, err)
+	assert.True(t, result.Statement.Select.StraightJoin)
+	assert.Equal(t, "STRAIGHT_JOIN", result.Statement.Select.Hints)
+
+	result, err = adapter.Parse("SELECT * FROM t1 STRAIGHT_JOIN t2 ON t1.id = t2.id")
+	require.NoError(t, err)
+	assert.True(t, result.Statement.Select.StraightJoin)
+
+	result, err = adapter.Parse("SELECT * FROM t1 IGNORE INDEX (idx_a, idx_b)")
+	require.NoError(t, err)
+	assert.Equal(t, "IGNORE_INDEX(t1 idx_a idx_b)", result.Statement.Select.Hints)
+}
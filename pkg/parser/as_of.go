@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb/pkg/parser/ast"
+)
+
+// timestampLayouts AS OF 和 FLASHBACK TABLE ... TO TIMESTAMP 接受的时间格式，按本地时区解析
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseTimestamp 解析 AS OF 和 FLASHBACK TABLE ... TO TIMESTAMP 的时间
+func ParseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp '%s'", value)
+}
+
+// preprocessAsOfClause 将 FOR SYSTEM_TIME AS OF 转换为 TiDB 的 AS OF TIMESTAMP。
+// TiDB 要求表别名写在 AS OF 之前，写在时间之后的 AS 别名会被移到前面
+// 例如：SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01 00:00:00' AS x
+// 转换为：SELECT * FROM t AS x AS OF TIMESTAMP '2024-01-01 00:00:00'
+// 按词法单元匹配，字符串和标识符中的同名文本保持不变
+func preprocessAsOfClause(sql string) string {
+	if !strings.Contains(strings.ToUpper(sql), "SYSTEM_TIME") {
+		return sql
+	}
+	toks := tokenizeDialect(sql, false)
+	changed := false
+	for i := 0; i < len(toks); i++ {
+		end, ok := systemTimeAsOfAt(toks, i)
+		if !ok {
+			continue
+		}
+		clause := "AS OF TIMESTAMP "
+		if end < len(toks) && toks[end].kind == tokSpace {
+			end++
+		}
+		if j := nextSignificant(toks, end); j >= 0 && toks[j].kind == tokString {
+			clause += toks[j].text
+			end = j + 1
+			if a := nextSignificant(toks, end); a >= 0 && isWord(toks[a], "AS") {
+				if n := nextSignificant(toks, a+1); n >= 0 && (toks[n].kind == tokWord || toks[n].kind == tokBacktick) {
+					clause = "AS " + toks[n].text + " " + clause
+					end = n + 1
+				}
+			}
+		}
+		toks = spliceTokens(toks, i, end, []dialectToken{{kind: tokWord, text: clause}})
+		changed = true
+	}
+	if !changed {
+		return sql
+	}
+	return renderTokens(toks)
+}
+
+// systemTimeAsOfAt 判断 toks[i] 是否开始 FOR SYSTEM_TIME AS OF [TIMESTAMP]，返回子句之后的位置
+func systemTimeAsOfAt(toks []dialectToken, i int) (int, bool) {
+	if !isWord(toks[i], "FOR") {
+		return 0, false
+	}
+	for _, word := range []string{"SYSTEM_TIME", "AS", "OF"} {
+		if i = nextSignificant(toks, i+1); i < 0 || !isWord(toks[i], word) {
+			return 0, false
+		}
+	}
+	if j := nextSignificant(toks, i+1); j >= 0 && isWord(toks[j], "TIMESTAMP") {
+		i = j
+	}
+	return i + 1, true
+}
+
+// asOfCollector 收集语句中所有表的 AS OF 子句
+type asOfCollector struct {
+	clauses []*ast.AsOfClause
+}
+
+func (c *asOfCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if tn, ok := n.(*ast.TableName); ok && tn.AsOf != nil {
+		c.clauses = append(c.clauses, tn.AsOf)
+	}
+	return n, false
+}
+
+func (c *asOfCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// applyAsOf 将语句中的 AS OF 时间设置到 SELECT 语句上。
+// 时间点作用于整条语句读取的所有表，因此各表的 AS OF 必须相同
+func (a *SQLAdapter) applyAsOf(node ast.StmtNode, stmt *SQLStatement) error {
+	collector := &asOfCollector{}
+	node.Accept(collector)
+	if len(collector.clauses) == 0 {
+		return nil
+	}
+	if stmt.Select == nil {
+		return fmt.Errorf("AS OF is only supported in SELECT statements")
+	}
+
+	var asOf string
+	for i, clause := range collector.clauses {
+		value, err := a.extractValue(clause.TsExpr)
+		if err != nil {
+			return fmt.Errorf("AS OF requires a timestamp literal: %w", err)
+		}
+		ts, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("AS OF requires a timestamp literal, got %v", value)
+		}
+		if i > 0 && ts != asOf {
+			return fmt.Errorf("all tables in a statement must use the same AS OF timestamp")
+		}
+		asOf = ts
+	}
+	stmt.Select.AsOf = asOf
+	return nil
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreprocessAsOfClause(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM t AS OF TIMESTAMP '2024-01-01 00:00:00'",
+		preprocessAsOfClause("SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01 00:00:00'"))
+	assert.Equal(t, "SELECT * FROM t AS x AS OF TIMESTAMP '2024-01-01' WHERE x.a = 1",
+		preprocessAsOfClause("SELECT * FROM t for system_time as of timestamp '2024-01-01' AS x WHERE x.a = 1"))
+	assert.Equal(t, "SELECT * FROM t x AS OF TIMESTAMP NOW()",
+		preprocessAsOfClause("SELECT * FROM t x FOR SYSTEM_TIME AS OF NOW()"))
+
+	// 字符串和标识符中的 FOR SYSTEM_TIME AS OF 保持不变
+	sql := "SELECT 'for system_time as of ''2024-01-01'' AS x' AS `for system_time as of` FROM t"
+	assert.Equal(t, sql, preprocessAsOfClause(sql))
+	assert.Equal(t, "SELECT * FROM t AS `x y` AS OF TIMESTAMP '2024-01-01' WHERE note = 'FOR SYSTEM_TIME AS OF now'",
+		preprocessAsOfClause("SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01' AS `x y` WHERE note = 'FOR SYSTEM_TIME AS OF now'"))
+}
+
+func TestParseAsOf(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01 00:00:00' WHERE id > 1")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Select)
+	assert.Equal(t, "2024-01-01 00:00:00", result.Statement.Select.AsOf)
+	assert.Equal(t, "t", result.Statement.Select.From)
+
+	result, err = adapter.Parse("SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01' AS a " +
+		"JOIN u FOR SYSTEM_TIME AS OF '2024-01-01' AS b ON a.id = b.id")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01", result.Statement.Select.AsOf)
+
+	result, err = adapter.Parse("SELECT * FROM t")
+	require.NoError(t, err)
+	assert.Empty(t, result.Statement.Select.AsOf)
+
+	_, err = adapter.Parse("SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01' AS a " +
+		"JOIN u FOR SYSTEM_TIME AS OF '2024-02-01' AS b ON a.id = b.id")
+	assert.ErrorContains(t, err, "same AS OF timestamp")
+
+	_, err = adapter.Parse("SELECT * FROM t FOR SYSTEM_TIME AS OF NOW()")
+	assert.ErrorContains(t, err, "timestamp literal")
+
+	_, err = adapter.Parse("INSERT INTO u SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-01-01'")
+	assert.ErrorContains(t, err, "only supported in SELECT")
+}
+
+func TestParseUndropAndFlashback(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("UNDROP TABLE `db1`.orders;")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeUndrop, result.Statement.Type)
+	assert.Equal(t, "db1.orders", result.Statement.Undrop.Table)
+
+	result, err = adapter.Parse("flashback table orders to timestamp '2024-01-01 10:00:00'")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeFlashback, result.Statement.Type)
+	assert.Equal(t, &FlashbackTableStatement{Table: "orders", Timestamp: "2024-01-01 10:00:00"}, result.Statement.Flashback)
+
+	_, err = adapter.Parse("FLASHBACK TABLE orders TO TIMESTAMP 'yesterday'")
+	assert.ErrorContains(t, err, "invalid timestamp")
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+)
+
+// assignmentChecker 找出 SET 赋值中不能按行求值的表达式（子查询、聚合函数、窗口函数等）
+type assignmentChecker struct {
+	unsupported ast.ExprNode
+}
+
+func (c *assignmentChecker) Enter(n ast.Node) (ast.Node, bool) {
+	expr, ok := n.(ast.ExprNode)
+	if !ok || c.unsupported != nil {
+		return n, c.unsupported != nil
+	}
+	switch expr.(type) {
+	case ast.ValueExpr, *ast.ColumnNameExpr, *ast.BinaryOperationExpr, *ast.UnaryOperationExpr,
+		*ast.ParenthesesExpr, *ast.FuncCallExpr, *ast.FuncCastExpr, *ast.CaseExpr, *ast.IsNullExpr,
+		*ast.BetweenExpr, *ast.PatternInExpr, *ast.PatternLikeOrIlikeExpr, *ast.PatternRegexpExpr,
+		*ast.SetCollationExpr, *ast.VariableExpr, *ast.ValuesExpr:
+		return n, false
+	}
+	c.unsupported = expr
+	return n, true
+}
+
+func (c *assignmentChecker) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// convertAssignment 转换 SET 中的赋值：常量直接取值，VALUES(col) 为 ValuesRef，
+// 引用列的表达式（qty = qty + 1）转换为 *Expression，执行时在要修改的行上求值
+func (a *SQLAdapter) convertAssignment(node ast.ExprNode) (interface{}, error) {
+	if values, ok := node.(*ast.ValuesExpr); ok {
+		return ValuesRef{Column: values.Column.Name.Name.String()}, nil
+	}
+	if val, err := a.extractValue(node); err == nil {
+		return val, nil
+	}
+	checker := &assignmentChecker{}
+	node.Accept(checker)
+	if checker.unsupported != nil {
+		return nil, fmt.Errorf("unsupported expression in assignment: %T", checker.unsupported)
+	}
+	return a.convertExpression(node)
+}
+
+// HasRowAssignments 判断 SET 中是否有需要在每一行上求值的表达式
+func HasRowAssignments(set map[string]interface{}) bool {
+	for _, val := range set {
+		if _, ok := val.(*Expression); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluateAssignments 在 row 上计算 SET 的值：表达式中的列取 row 的值，
+// VALUES(col) 取 inserted 的值（ON DUPLICATE KEY UPDATE 中待插入的行，UPDATE 时为 nil）
+func EvaluateAssignments(set map[string]interface{}, row, inserted domain.Row) (domain.Row, error) {
+	updates := make(domain.Row, len(set))
+	for col, val := range set {
+		switch v := val.(type) {
+		case ValuesRef:
+			updates[col] = inserted[v.Column]
+		case *Expression:
+			result, err := evaluateValue(row, bindValuesRefs(v, inserted))
+			if err != nil {
+				return nil, fmt.Errorf("cannot evaluate assignment to %s: %w", col, err)
+			}
+			updates[col] = result
+		default:
+			updates[col] = val
+		}
+	}
+	return updates, nil
+}
+
+// bindValuesRefs 把表达式中的 VALUES(col) 替换为待插入行的值，只复制被替换的路径
+func bindValuesRefs(expr *Expression, inserted domain.Row) *Expression {
+	if expr == nil {
+		return nil
+	}
+	if ref, ok := expr.Value.(ValuesRef); ok && expr.Type == ExprTypeValue {
+		return &Expression{Type: ExprTypeValue, Value: inserted[ref.Column]}
+	}
+	left, right := bindValuesRefs(expr.Left, inserted), bindValuesRefs(expr.Right, inserted)
+	var args []Expression
+	for i := range expr.Args {
+		if arg := bindValuesRefs(&expr.Args[i], inserted); arg != &expr.Args[i] {
+			if args == nil {
+				args = append([]Expression(nil), expr.Args...)
+			}
+			args[i] = *arg
+		}
+	}
+	if left == expr.Left && right == expr.Right && args == nil {
+		return expr
+	}
+	bound := *expr
+	bound.Left, bound.Right = left, right
+	if args != nil {
+		bound.Args = args
+	}
+	return &bound
+}
+
+// RowStore 逐行更新需要的读写操作，domain.DataSource 与 domain.Transaction 都满足
+type RowStore interface {
+	Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error)
+	Update(ctx context.Context, tableName string, filters []domain.Filter, updates domain.Row, options *domain.UpdateOptions) (int64, error)
+}
+
+// UpdateEachRow 执行含列引用表达式的 UPDATE：读出 filters 匹配的行，在每一行上计算 SET 的值，
+// 再按主键更新该行。check 不为 nil 时在写入前检查每一行的新值（视图的 CHECK OPTION）
+func UpdateEachRow(ctx context.Context, store RowStore, tableName string, tableInfo *domain.TableInfo, filters []domain.Filter,
+	set map[string]interface{}, check func(row, updates domain.Row) error) (int64, error) {
+	var keys []string
+	for _, col := range tableInfo.Columns {
+		if col.Primary {
+			keys = append(keys, col.Name)
+		}
+	}
+	if len(keys) == 0 {
+		return 0, fmt.Errorf("UPDATE with expressions on table %s requires a primary key", tableName)
+	}
+
+	result, err := store.Query(ctx, tableName, &domain.QueryOptions{Filters: filters})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query rows to update: %w", err)
+	}
+	var affected int64
+	for _, row := range result.Rows {
+		updates, err := EvaluateAssignments(set, row, nil)
+		if err != nil {
+			return affected, err
+		}
+		if check != nil {
+			if err := check(row, updates); err != nil {
+				return affected, err
+			}
+		}
+		n, err := store.Update(ctx, tableName, []domain.Filter{primaryKeyFilter(keys, []domain.Row{row})}, updates, nil)
+		if err != nil {
+			return affected, err
+		}
+		affected += n
+	}
+	return affected, nil
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackupDatabase(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("BACKUP DATABASE * TO 's3://backups/nightly.tar'")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeBackup, result.Statement.Type)
+	assert.Equal(t, &BackupStatement{Target: "s3://backups/nightly.tar"}, result.Statement.Backup)
+
+	result, err = adapter.Parse("backup database shop, `audit log` to '/backups/shop.tar';")
+	require.NoError(t, err)
+	assert.Equal(t, &BackupStatement{Databases: []string{"shop", "audit log"}, Target: "/backups/shop.tar"}, result.Statement.Backup)
+}
+
+func TestParseBackupIncremental(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("BACKUP INCREMENTAL DATABASE shop TO '/backups/inc1.tar' FROM '/backups/full.tar'")
+	require.NoError(t, err)
+	assert.Equal(t, &BackupStatement{Databases: []string{"shop"}, Target: "/backups/inc1.tar", Incremental: true, Base: "/backups/full.tar"}, result.Statement.Backup)
+
+	_, err = adapter.Parse("BACKUP INCREMENTAL DATABASE shop TO '/backups/inc1.tar'")
+	assert.Error(t, err)
+	_, err = adapter.Parse("BACKUP DATABASE shop TO '/backups/inc1.tar' FROM '/backups/full.tar'")
+	assert.Error(t, err)
+}
+
+func TestParseRestoreDatabase(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("RESTORE DATABASE * FROM '/backups/full.tar'")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeRestore, result.Statement.Type)
+	assert.Equal(t, &RestoreStatement{Sources: []string{"/backups/full.tar"}}, result.Statement.Restore)
+
+	result, err = adapter.Parse("restore database shop from '/backups/full.tar', '/backups/inc1.tar' until timestamp '2026-10-16 12:00:00';")
+	require.NoError(t, err)
+	assert.Equal(t, &RestoreStatement{
+		Databases: []string{"shop"},
+		Sources:   []string{"/backups/full.tar", "/backups/inc1.tar"},
+		Until:     "2026-10-16 12:00:00",
+	}, result.Statement.Restore)
+
+	_, err = adapter.Parse("RESTORE DATABASE shop FROM '/backups/full.tar' UNTIL TIMESTAMP 'yesterday'")
+	assert.Error(t, err)
+}
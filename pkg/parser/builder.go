@@ -2,14 +2,22 @@ package parser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/builtin"
+	"github.com/kasuganosora/sqlexec/pkg/datagen"
+	"github.com/kasuganosora/sqlexec/pkg/dataimport"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 	domain "github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/generated"
 	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
@@ -63,12 +71,39 @@ func (b *QueryBuilder) ExecuteStatement(ctx context.Context, stmt *SQLStatement)
 			return b.executeDropIndex(ctx, stmt.DropIndex)
 		}
 		return b.executeDrop(ctx, stmt.Drop)
+	case SQLTypeTruncate:
+		// TRUNCATE 复用 DROP 的执行路径（解析器为 TRUNCATE 填充 stmt.Drop）
+		return b.executeDrop(ctx, stmt.Drop)
 	case SQLTypeAlter:
 		return b.executeAlter(ctx, stmt.Alter)
 	case SQLTypeCreateView:
 		return b.executeCreateView(ctx, stmt.CreateView)
 	case SQLTypeDropView:
 		return b.executeDropView(ctx, stmt.DropView)
+	case SQLTypeOptimize:
+		return b.executeOptimize(ctx, stmt.Optimize)
+	case SQLTypeExport:
+		return b.executeExportTable(ctx, stmt.ExportTable)
+	case SQLTypeImport:
+		return b.executeImportTable(ctx, stmt.ImportTable)
+	case SQLTypeChecksum:
+		return b.executeChecksum(ctx, stmt.Checksum)
+	case SQLTypeCheck:
+		return b.executeCheck(ctx, stmt.Check)
+	case SQLTypeGenerate:
+		return b.executeGenerateTable(ctx, stmt.GenerateTable)
+	case SQLTypeImportData:
+		return b.executeImportData(ctx, stmt.ImportData)
+	case SQLTypeUndrop:
+		return b.executeUndropTable(ctx, stmt.Undrop)
+	case SQLTypeFlashback:
+		return b.executeFlashbackTable(ctx, stmt.Flashback)
+	case SQLTypeCreateSeq:
+		return b.executeCreateSequence(ctx, stmt.Sequence)
+	case SQLTypeAlterSeq:
+		return b.executeAlterSequence(ctx, stmt.Sequence)
+	case SQLTypeDropSeq:
+		return b.executeDropSequence(ctx, stmt.Sequence)
 	// Note: SQLTypeAlterView is not supported by TiDB
 	default:
 		return nil, fmt.Errorf("unsupported SQL type: %s", stmt.Type)
@@ -95,27 +130,59 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 	}
 	options.SelectAll = isSelectAll
 
-	// 处理 WHERE 条件
-	if stmt.Where != nil {
-		options.Filters = b.convertExpressionToFilters(stmt.Where)
-	}
-
 	// Detect if post-processing is needed
 	hasAggregates := b.hasAggregateFunctions(stmt.Columns)
 	hasGroupBy := len(stmt.GroupBy) > 0
 	hasJoins := len(stmt.Joins) > 0
 
-	// Only apply ORDER BY/LIMIT/OFFSET at dataSource level if no post-processing needed
-	if !hasAggregates && !hasGroupBy && !hasJoins {
-		if len(stmt.OrderBy) > 0 {
-			options.OrderBy = stmt.OrderBy[0].Column
-			options.Order = stmt.OrderBy[0].Direction
+	// 处理 WHERE 条件：IN (SELECT ...) 先物化为值列表；能转换的合取项下推给数据源，
+	// 其余的作为剩余条件在本地求值。有 JOIN 时 WHERE 可能引用任意一张表，在连接之后整体求值
+	where, err := MaterializeSubqueries(ctx, stmt.Where, b.executeSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	// 数据源能执行整条语句时直接下推，不必把表读回本地
+	pushStmt := stmt
+	if where != stmt.Where {
+		materialized := *stmt
+		materialized.Where = where
+		pushStmt = &materialized
+	}
+	if CanPushDownSelect(b.dataSource, pushStmt) {
+		result, err := b.dataSource.(SelectPushdownDataSource).PushDownSelect(ctx, pushStmt)
+		if err != nil {
+			return nil, err
 		}
-		if stmt.Limit != nil {
-			options.Limit = int(*stmt.Limit)
+		domain.RecordRowsExamined(ctx, result.Rows)
+		return result, nil
+	}
+
+	residual := where
+	if !hasJoins {
+		options.Filters, residual = SplitFilters(where)
+		options.Filters = b.convertFilterValues(options.Filters)
+	}
+
+	// Only apply ORDER BY/LIMIT/OFFSET at dataSource level if no post-processing needed.
+	// 数据源没有声明已排序（QueryResult.Ordered）时在本地排序；只有数据源能按全部排序键排序时
+	// LIMIT/OFFSET 才随排序下推，否则在本地排序后分页
+	pushDown := !hasAggregates && !hasGroupBy && !hasJoins
+	pagedByDataSource := false
+	if pushDown {
+		options.Sort = toDomainSortKeys(stmt.OrderBy)
+		if len(options.Sort) > 0 {
+			options.OrderBy = options.Sort[0].Column
+			options.Order = options.Sort[0].Direction
 		}
-		if stmt.Offset != nil {
-			options.Offset = int(*stmt.Offset)
+		if residual == nil && domain.CanPushDownPaging(b.dataSource, stmt.From, options.Sort) {
+			pagedByDataSource = true
+			if stmt.Limit != nil {
+				options.Limit = int(*stmt.Limit)
+			}
+			if stmt.Offset != nil {
+				options.Offset = int(*stmt.Offset)
+			}
 		}
 	}
 
@@ -124,12 +191,32 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
+	domain.RecordRowsExamined(ctx, result.Rows)
+
+	if residual != nil && !hasJoins {
+		if result.Rows, err = filterRows(result.Rows, residual); err != nil {
+			return nil, err
+		}
+		result.Total = int64(len(result.Rows))
+	}
+
+	if pushDown {
+		if len(options.Sort) > 0 && !result.Ordered {
+			result.Rows = b.sortRows(result.Rows, stmt.OrderBy)
+		}
+		if !pagedByDataSource && (stmt.Limit != nil || stmt.Offset != nil) {
+			result.Rows = applyLimitOffset(result.Rows, stmt.Limit, stmt.Offset)
+			result.Total = int64(len(result.Rows))
+		}
+	}
 
 	// =========================================================================
 	// 处理 JOIN
 	// =========================================================================
+	var joined []joinedTable
 	if hasJoins {
 		mainTableName := stmt.From
+		joined = append(joined, newJoinedTable(mainTableName, stmt.From, stmt.FromAlias, result.Columns))
 		// Prefix main table rows with table name to avoid column name conflicts
 		prefixedRows := make([]domain.Row, 0, len(result.Rows))
 		for _, row := range result.Rows {
@@ -137,6 +224,9 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 			for k, v := range row {
 				newRow[k] = v
 				newRow[mainTableName+"."+k] = v
+				if stmt.FromAlias != "" {
+					newRow[stmt.FromAlias+"."+k] = v
+				}
 			}
 			prefixedRows = append(prefixedRows, newRow)
 		}
@@ -157,23 +247,35 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 			if err != nil {
 				return nil, fmt.Errorf("join query on table '%s' failed: %w", joinTableName, err)
 			}
+			domain.RecordRowsExamined(ctx, joinResult.Rows)
 			joinResultCache[joinTableName] = joinResult
 
-			// Prefix join table rows with table name and alias
+			// Prefix join table rows with table name and alias.
+			// 自连接时表名前缀已属于 FROM 表，只用别名区分
+			prefixTable := joinTableName
+			if joinTableName == mainTableName && joinAlias != joinTableName {
+				prefixTable = joinAlias
+			}
 			joinRows := make([]domain.Row, 0, len(joinResult.Rows))
 			for _, row := range joinResult.Rows {
 				newRow := make(domain.Row)
 				for k, v := range row {
-					newRow[joinTableName+"."+k] = v
-					if joinAlias != joinTableName {
+					newRow[prefixTable+"."+k] = v
+					if joinAlias != prefixTable {
 						newRow[joinAlias+"."+k] = v
 					}
 				}
 				joinRows = append(joinRows, newRow)
 			}
 
+			joined = append(joined, newJoinedTable(prefixTable, joinTableName, join.Alias, joinResult.Columns))
+
 			// Merge rows based on join type
-			currentRows = b.performJoin(currentRows, joinRows, join, joinTableName, joinAlias, joinResult.Columns)
+			currentRows = b.performJoin(currentRows, joinRows, join, prefixTable, joinAlias, joinResult.Columns)
+		}
+
+		if currentRows, err = filterRows(currentRows, residual); err != nil {
+			return nil, err
 		}
 
 		result.Rows = currentRows
@@ -234,21 +336,24 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 		}
 
 		// 处理 HAVING - filter groups after aggregation
+		// HAVING 可以引用 SELECT 列别名（HAVING c > 5），别名从聚合结果行中解析
 		if stmt.Having != nil {
 			filteredGroups := make([]domain.Row, 0)
-			i := 0
-			for _, groupRows := range groups {
-				if i >= len(groupedRows) {
-					break
-				}
-				if b.evaluateHavingExpression(stmt.Having, groupRows) {
+			filteredRows := make([][]domain.Row, 0)
+			for i, groupRows := range groups {
+				if b.evaluateHavingExpression(stmt.Having, groupRows, groupedRows[i]) {
 					filteredGroups = append(filteredGroups, groupedRows[i])
+					filteredRows = append(filteredRows, groupRows)
 				}
-				i++
 			}
 			groupedRows = filteredGroups
+			groups = filteredRows
 		}
 
+		// 处理 ORDER BY / LIMIT - 在分组之后对聚合结果排序和截取
+		groupedRows = b.sortGroupedRows(groupedRows, groups, stmt.OrderBy)
+		groupedRows = applyLimitOffset(groupedRows, stmt.Limit, stmt.Offset)
+
 		result.Rows = groupedRows
 		result.Total = int64(len(groupedRows))
 
@@ -329,45 +434,44 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 		return result, nil
 	}
 
-	// 如果不是 select *，则需要根据 SELECT 的列来过滤结果
+	// 如果不是 select *，则需要根据 SELECT 的列来过滤结果。
+	// 结果列名取列别名；有 JOIN 时没有别名的限定列（a.name、b.name）与其他选择列同名的，
+	// 改用限定名，避免不同表的同名列在结果行中相互覆盖
 	if len(stmt.Columns) > 0 {
-		selectedColumns := make([]string, 0, len(stmt.Columns))
+		selected := make([]SelectColumn, 0, len(stmt.Columns))
 		for _, col := range stmt.Columns {
 			if len(col.Name) > 0 {
-				selectedColumns = append(selectedColumns, col.Name)
+				selected = append(selected, col)
 			}
 		}
 
-		if len(selectedColumns) == 0 {
+		if len(selected) == 0 {
 			return result, nil
 		}
 
-		newColumns := make([]domain.ColumnInfo, 0, len(selectedColumns))
-		for _, colName := range selectedColumns {
-			found := false
-			for _, col := range result.Columns {
-				if col.Name == colName {
-					newColumns = append(newColumns, col)
-					found = true
+		newColumns := make([]domain.ColumnInfo, 0, len(selected))
+		for _, col := range selected {
+			info := domain.ColumnInfo{Name: col.Name, Type: "int64", Nullable: true}
+			for _, c := range result.Columns {
+				if c.Name == col.Name || (col.Table != "" && c.Name == col.Table+"."+col.Name) {
+					info = c
 					break
 				}
 			}
-			if !found {
-				newColumns = append(newColumns, domain.ColumnInfo{
-					Name:     colName,
-					Type:     "int64",
-					Nullable: true,
-					Primary:  false,
-				})
-			}
+			info.Name = projectedColumnName(col, selected, joined)
+			newColumns = append(newColumns, info)
 		}
 
 		filteredRows := make([]domain.Row, 0, len(result.Rows))
 		for _, row := range result.Rows {
 			filteredRow := make(domain.Row)
-			for _, colName := range selectedColumns {
-				if val, exists := row[colName]; exists {
-					filteredRow[colName] = val
+			for i, col := range selected {
+				val, exists, err := joinedColumnValue(row, col, joined)
+				if err != nil {
+					return nil, err
+				}
+				if exists {
+					filteredRow[newColumns[i].Name] = val
 				}
 			}
 			filteredRows = append(filteredRows, filteredRow)
@@ -380,10 +484,106 @@ func (b *QueryBuilder) executeSelect(ctx context.Context, stmt *SelectStatement)
 	return result, nil
 }
 
+// projectedColumnName 返回选择列在结果行中的列名：有别名时用别名；有 JOIN 时
+// 与其他没有别名的选择列同名的限定列用 SQL 中写出的限定名（e.name），否则用列名
+func projectedColumnName(col SelectColumn, selected []SelectColumn, tables []joinedTable) string {
+	if col.Alias != "" {
+		return col.Alias
+	}
+	if len(tables) == 0 || col.Table == "" {
+		return col.Name
+	}
+	for _, other := range selected {
+		if other.Alias == "" && strings.EqualFold(other.Name, col.Name) &&
+			!strings.EqualFold(other.Table, col.Table) {
+			return col.Table + "." + col.Name
+		}
+	}
+	return col.Name
+}
+
 // =============================================================================
 // JOIN helper methods
 // =============================================================================
 
+// joinedTable JOIN 中的一张表：prefix 为行中该表列名的前缀，refs 为 SQL 中可以引用该表的名称
+type joinedTable struct {
+	prefix  string
+	refs    []string
+	columns map[string]bool
+}
+
+// newJoinedTable 记录 JOIN 中的表：有别名时用别名引用，否则用表名引用，
+// 带库名前缀的表（db.t）也可以只用表名引用
+func newJoinedTable(prefix, table, alias string, columns []domain.ColumnInfo) joinedTable {
+	t := joinedTable{prefix: prefix, columns: make(map[string]bool, len(columns))}
+	if alias != "" {
+		t.refs = []string{alias}
+	} else {
+		t.refs = []string{table}
+		if i := strings.LastIndexByte(table, '.'); i >= 0 {
+			t.refs = append(t.refs, table[i+1:])
+		}
+	}
+	for _, col := range columns {
+		t.columns[col.Name] = true
+	}
+	return t
+}
+
+func (t joinedTable) referencedBy(name string) bool {
+	for _, ref := range t.refs {
+		if strings.EqualFold(ref, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinedColumnValue 返回选择列在行中的值。没有 JOIN 时按列名查找；
+// 有 JOIN 时限定列（e.amount）按表名或别名找到对应的表，未限定的列在包含该列的表中查找，
+// 多张表都有该列时与 MySQL 一样报告列名有歧义
+func joinedColumnValue(row domain.Row, col SelectColumn, tables []joinedTable) (interface{}, bool, error) {
+	if len(tables) == 0 {
+		val, exists := row[col.Name]
+		return val, exists, nil
+	}
+
+	var owner *joinedTable
+	if col.Table != "" {
+		for i := range tables {
+			if !tables[i].referencedBy(col.Table) {
+				continue
+			}
+			if owner != nil {
+				return nil, false, mysqlerrors.New(mysqlerrors.ErrNonUniqTable, "Not unique table/alias: '%s'", col.Table)
+			}
+			owner = &tables[i]
+		}
+		if owner == nil {
+			val, exists := row[col.Table+"."+col.Name]
+			return val, exists, nil
+		}
+	} else {
+		for i := range tables {
+			if !tables[i].columns[col.Name] {
+				continue
+			}
+			if owner != nil {
+				return nil, false, mysqlerrors.New(mysqlerrors.ErrNonUniq, "Column '%s' in field list is ambiguous", col.Name)
+			}
+			owner = &tables[i]
+		}
+		if owner == nil {
+			// 不属于任何表的列（如计算列）按列名查找
+			val, exists := row[col.Name]
+			return val, exists, nil
+		}
+	}
+	val, exists := row[owner.prefix+"."+col.Name]
+	return val, exists, nil
+}
+
 // performJoin merges left and right row sets based on join type and condition
 func (b *QueryBuilder) performJoin(leftRows []domain.Row, rightRows []domain.Row, join JoinInfo, joinTableName, joinAlias string, rightColumns []domain.ColumnInfo) []domain.Row {
 	switch join.Type {
@@ -437,14 +637,21 @@ func (b *QueryBuilder) performInnerJoin(leftRows, rightRows []domain.Row, condit
 // hashInnerJoin performs an inner join using a hash map on the right side
 func (b *QueryBuilder) hashInnerJoin(leftRows, rightRows []domain.Row, leftCol, rightCol string) []domain.Row {
 	// Build hash table on right rows (typically smaller or equal)
+	// NULL = NULL is UNKNOWN, so rows with a NULL join key never match
 	hashTable := make(map[string][]domain.Row)
 	for _, right := range rightRows {
+		if right[rightCol] == nil {
+			continue
+		}
 		key := fmt.Sprintf("%v", right[rightCol])
 		hashTable[key] = append(hashTable[key], right)
 	}
 
 	result := make([]domain.Row, 0, len(leftRows))
 	for _, left := range leftRows {
+		if left[leftCol] == nil {
+			continue
+		}
 		key := fmt.Sprintf("%v", left[leftCol])
 		if matches, ok := hashTable[key]; ok {
 			for _, right := range matches {
@@ -608,6 +815,13 @@ func (b *QueryBuilder) evaluateJoinCondition(row domain.Row, condition *Expressi
 			return b.evaluateJoinCondition(row, condition.Left) || b.evaluateJoinCondition(row, condition.Right)
 		}
 
+		if op == "is null" {
+			return b.resolveExprValue(row, condition.Left) == nil
+		}
+		if op == "is not null" {
+			return b.resolveExprValue(row, condition.Left) != nil
+		}
+
 		if condition.Left == nil || condition.Right == nil {
 			return false
 		}
@@ -676,7 +890,10 @@ func (b *QueryBuilder) hasAggregateFunctions(columns []SelectColumn) bool {
 // isAggregateFunction checks if a function name is an aggregate function
 func (b *QueryBuilder) isAggregateFunction(funcName string) bool {
 	switch strings.ToUpper(funcName) {
-	case "COUNT", "SUM", "AVG", "MIN", "MAX":
+	case "COUNT", "SUM", "AVG", "MIN", "MAX",
+		"GROUP_CONCAT", "STDDEV_POP", "STDDEV_SAMP", "VAR_POP", "VAR_SAMP",
+		"BIT_AND", "BIT_OR", "BIT_XOR", FirstByFunc, LastByFunc,
+		"APPROX_COUNT_DISTINCT", "APPROX_PERCENTILE":
 		return true
 	default:
 		return false
@@ -697,8 +914,38 @@ func (b *QueryBuilder) computeAggregate(funcName string, args []Expression, rows
 	case "MAX":
 		return b.computeMax(args, rows)
 	default:
+		return b.computeBuiltinAggregate(funcName, args, rows)
+	}
+}
+
+// computeBuiltinAggregate computes aggregates implemented in the builtin package
+// (GROUP_CONCAT, STDDEV_POP, VAR_POP, BIT_AND, ...). Column arguments are read
+// from each row, constant arguments (e.g. the GROUP_CONCAT separator) are passed as-is.
+func (b *QueryBuilder) computeBuiltinAggregate(funcName string, args []Expression, rows []domain.Row) interface{} {
+	info, ok := builtin.GetAggregate(strings.ToLower(funcName))
+	if !ok {
+		return nil
+	}
+	ctx := builtin.NewAggregateContext()
+	for _, row := range rows {
+		vals := make([]interface{}, len(args))
+		for i, arg := range args {
+			switch arg.Type {
+			case ExprTypeColumn:
+				vals[i] = b.getColumnValue(row, arg.Column)
+			case ExprTypeValue:
+				vals[i] = arg.Value
+			}
+		}
+		if err := info.Handler(ctx, vals); err != nil {
+			return nil
+		}
+	}
+	result, err := info.Result(ctx)
+	if err != nil {
 		return nil
 	}
+	return result
 }
 
 // computeCount computes COUNT(*) or COUNT(column)
@@ -855,8 +1102,84 @@ func (b *QueryBuilder) buildGroupKey(row domain.Row, groupByCols []string) strin
 // HAVING helper methods
 // =============================================================================
 
+// toDomainSortKeys 把 ORDER BY 转换为下推给数据源的排序键
+func toDomainSortKeys(orderBy []OrderByItem) []domain.OrderByItem {
+	if len(orderBy) == 0 {
+		return nil
+	}
+	keys := make([]domain.OrderByItem, len(orderBy))
+	for i, item := range orderBy {
+		keys[i] = domain.OrderByItem{Column: item.Column, Direction: item.Direction, Collation: item.Collation}
+	}
+	return keys
+}
+
+// sortRows 按 ORDER BY 对数据源返回的行排序（稳定排序），字符串按排序项的排序规则比较
+func (b *QueryBuilder) sortRows(rows []domain.Row, orderBy []OrderByItem) []domain.Row {
+	return b.sortGroupedRows(rows, nil, orderBy)
+}
+
+// sortGroupedRows 按 ORDER BY 对聚合结果排序
+// 排序项可以是分组列、SELECT 列别名或聚合函数（ORDER BY COUNT(*)）；groups 为 nil 时只按列排序
+func (b *QueryBuilder) sortGroupedRows(rows []domain.Row, groups [][]domain.Row, orderBy []OrderByItem) []domain.Row {
+	if len(orderBy) == 0 || len(rows) < 2 {
+		return rows
+	}
+
+	// 预先计算每个分组的排序键
+	keys := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		keys[i] = make([]interface{}, len(orderBy))
+		for j, item := range orderBy {
+			if groups != nil && item.Expr != nil && item.Expr.Type == ExprTypeFunction && b.isAggregateFunction(item.Expr.Function) {
+				keys[i][j] = b.computeAggregate(item.Expr.Function, item.Expr.Args, groups[i])
+			} else {
+				keys[i][j] = b.getColumnValue(row, item.Column)
+			}
+		}
+	}
+
+	indexes := make([]int, len(rows))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(x, y int) bool {
+		for j, item := range orderBy {
+			cmp := utils.CompareValuesForSortWithCollation(keys[indexes[x]][j], keys[indexes[y]][j], item.Collation)
+			if cmp == 0 {
+				continue
+			}
+			if strings.EqualFold(item.Direction, "DESC") {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	sorted := make([]domain.Row, len(rows))
+	for i, idx := range indexes {
+		sorted[i] = rows[idx]
+	}
+	return sorted
+}
+
+// applyLimitOffset 对结果行应用 LIMIT / OFFSET
+func applyLimitOffset(rows []domain.Row, limit, offset *int64) []domain.Row {
+	if offset != nil && *offset > 0 {
+		if *offset >= int64(len(rows)) {
+			return []domain.Row{}
+		}
+		rows = rows[*offset:]
+	}
+	if limit != nil && *limit >= 0 && *limit < int64(len(rows)) {
+		rows = rows[:*limit]
+	}
+	return rows
+}
+
 // evaluateHavingExpression evaluates a HAVING expression against a group of rows
-func (b *QueryBuilder) evaluateHavingExpression(expr *Expression, groupRows []domain.Row) bool {
+func (b *QueryBuilder) evaluateHavingExpression(expr *Expression, groupRows []domain.Row, aggRow domain.Row) bool {
 	if expr == nil {
 		return true
 	}
@@ -866,14 +1189,14 @@ func (b *QueryBuilder) evaluateHavingExpression(expr *Expression, groupRows []do
 		op := strings.ToLower(expr.Operator)
 
 		if op == "and" {
-			return b.evaluateHavingExpression(expr.Left, groupRows) && b.evaluateHavingExpression(expr.Right, groupRows)
+			return b.evaluateHavingExpression(expr.Left, groupRows, aggRow) && b.evaluateHavingExpression(expr.Right, groupRows, aggRow)
 		}
 		if op == "or" {
-			return b.evaluateHavingExpression(expr.Left, groupRows) || b.evaluateHavingExpression(expr.Right, groupRows)
+			return b.evaluateHavingExpression(expr.Left, groupRows, aggRow) || b.evaluateHavingExpression(expr.Right, groupRows, aggRow)
 		}
 
-		leftVal := b.resolveHavingExprValue(expr.Left, groupRows)
-		rightVal := b.resolveHavingExprValue(expr.Right, groupRows)
+		leftVal := b.resolveHavingExprValue(expr.Left, groupRows, aggRow)
+		rightVal := b.resolveHavingExprValue(expr.Right, groupRows, aggRow)
 
 		sqlOp := b.convertOperator(op)
 		result, err := utils.CompareValues(leftVal, rightVal, sqlOp)
@@ -895,7 +1218,7 @@ func (b *QueryBuilder) evaluateHavingExpression(expr *Expression, groupRows []do
 }
 
 // resolveHavingExprValue resolves a HAVING expression to a value, computing aggregates as needed
-func (b *QueryBuilder) resolveHavingExprValue(expr *Expression, groupRows []domain.Row) interface{} {
+func (b *QueryBuilder) resolveHavingExprValue(expr *Expression, groupRows []domain.Row, aggRow domain.Row) interface{} {
 	if expr == nil {
 		return nil
 	}
@@ -906,6 +1229,10 @@ func (b *QueryBuilder) resolveHavingExprValue(expr *Expression, groupRows []doma
 		}
 		return nil
 	case ExprTypeColumn:
+		// 优先解析聚合结果中的列（SELECT 列别名）
+		if val, ok := aggRow[expr.Column]; ok {
+			return val
+		}
 		if len(groupRows) > 0 {
 			return b.getColumnValue(groupRows[0], expr.Column)
 		}
@@ -970,6 +1297,10 @@ func (b *QueryBuilder) executeInsert(ctx context.Context, stmt *InsertStatement)
 				row[col] = values[i]
 			}
 		}
+		// 没有给出值的 DEFAULT NEXTVAL(s) 列
+		if err := b.fillSequenceDefaults(ctx, tableInfo, row); err != nil {
+			return nil, err
+		}
 		// 过滤生成列（不允许显式插入）
 		filteredRow := generated.FilterGeneratedColumns(row, tableInfo)
 
@@ -984,108 +1315,167 @@ func (b *QueryBuilder) executeInsert(ctx context.Context, stmt *InsertStatement)
 		rows = append(rows, filteredRow)
 	}
 
-	options := &domain.InsertOptions{
-		Replace: false,
+	if stmt.OnDuplicate != nil || stmt.Ignore {
+		upserted, err := b.executeUpsert(ctx, stmt, tableInfo, rows)
+		if err != nil {
+			return nil, err
+		}
+		// 与 MySQL 的 INSERT 信息格式一致，附加逐行的插入与更新计数
+		info := fmt.Sprintf("Records: %d  Duplicates: %d  Warnings: %d  Inserted: %d  Updated: %d",
+			len(rows), upserted.Duplicates(), len(upserted.Warnings), upserted.Inserted, upserted.Updated)
+		return &domain.QueryResult{
+			Total: upserted.AffectedRows(),
+			Rows: []domain.Row{
+				{"rows_affected": upserted.AffectedRows(), "last_insert_id": upserted.LastInsertID, "info": info},
+			},
+			Warnings: upserted.Warnings,
+		}, nil
 	}
 
-	affected, err := b.dataSource.Insert(ctx, stmt.Table, rows, options)
-	if err != nil && stmt.OnDuplicate != nil {
-		// ON DUPLICATE KEY UPDATE: insert failed (likely duplicate key),
-		// fall back to updating existing rows using the OnDuplicate SET values.
+	affected, err := b.dataSource.Insert(ctx, stmt.Table, rows, &domain.InsertOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("insert failed: %w", err)
+	}
 
-		// Collect the set of columns being updated so we can exclude them from filters.
-		updatingCols := make(map[string]bool, len(stmt.OnDuplicate.Set))
-		for col := range stmt.OnDuplicate.Set {
-			updatingCols[col] = true
+	// Get last insert ID from the last inserted row's auto-increment column
+	var lastInsertID int64
+	if len(rows) > 0 {
+		lastInsertID = autoIncrementValue(tableInfo, rows[len(rows)-1])
+	}
+
+	return &domain.QueryResult{
+		Total: affected,
+		Rows: []domain.Row{
+			{"rows_affected": affected, "last_insert_id": lastInsertID},
+		},
+	}, nil
+}
+
+// executeUpsert 执行 INSERT IGNORE 与 INSERT ... ON DUPLICATE KEY UPDATE。
+// 数据源实现 domain.Upserter 时整条语句原子执行；否则逐行插入，主键或唯一键冲突时跳过或更新已有行
+func (b *QueryBuilder) executeUpsert(ctx context.Context, stmt *InsertStatement, tableInfo *domain.TableInfo, rows []domain.Row) (*domain.UpsertResult, error) {
+	// 同时指定时 ON DUPLICATE KEY UPDATE 优先，IGNORE 不再跳过冲突的行
+	options := &domain.UpsertOptions{Ignore: stmt.OnDuplicate == nil}
+	var guard []domain.Filter
+	if stmt.OnDuplicate != nil {
+		filters, residual := SplitFilters(stmt.OnDuplicate.Where)
+		if residual != nil {
+			return nil, fmt.Errorf("ON DUPLICATE KEY UPDATE: unsupported condition on the existing row")
+		}
+		guard = b.convertFilterValues(filters)
+		options.Update = func(existing, inserted domain.Row) (domain.Row, error) {
+			if err := checkUpsertGuard(existing, guard); err != nil {
+				return nil, err
+			}
+			return EvaluateAssignments(stmt.OnDuplicate.Set, existing, inserted)
 		}
+	}
 
-		totalAffected := int64(0)
-		for _, row := range rows {
-			// Resolve ValuesRef: replace with actual values from the INSERT row
-			updateData := make(domain.Row)
-			for col, val := range stmt.OnDuplicate.Set {
-				if ref, ok := val.(ValuesRef); ok {
-					// VALUES(column) references the value from INSERT VALUES
-					updateData[col] = row[ref.Column]
-				} else {
-					updateData[col] = val
-				}
+	if upserter, ok := b.dataSource.(domain.Upserter); ok {
+		result, err := upserter.Upsert(ctx, stmt.Table, rows, options)
+		var unsupported *domain.ErrUnsupportedOperation
+		if !errors.As(err, &unsupported) {
+			if err != nil {
+				return nil, fmt.Errorf("insert failed: %w", err)
 			}
+			return result, nil
+		}
+	}
 
-			// Build filters to identify which existing row to update.
-			// Priority: primary key > unique columns > non-updated inserted columns.
-			filters := make([]domain.Filter, 0)
-			for _, col := range tableInfo.Columns {
-				if col.Primary || col.Unique {
-					if val, ok := row[col.Name]; ok {
-						filters = append(filters, domain.Filter{
-							Field:    col.Name,
-							Operator: "=",
-							Value:    val,
-						})
-					}
-				}
+	result := &domain.UpsertResult{}
+	for _, row := range rows {
+		n, err := b.dataSource.Insert(ctx, stmt.Table, []domain.Row{row}, &domain.InsertOptions{})
+		if err == nil {
+			result.Inserted += n
+			if id := autoIncrementValue(tableInfo, row); id != 0 {
+				result.LastInsertID = id
 			}
-			if len(filters) == 0 {
-				// No primary/unique key found via column flags. Use inserted
-				// columns that are NOT being updated as the match key.
-				for _, colName := range stmt.Columns {
-					if updatingCols[colName] {
-						continue // skip columns being SET — they don't identify the row
-					}
-					if val, ok := row[colName]; ok {
-						filters = append(filters, domain.Filter{
-							Field:    colName,
-							Operator: "=",
-							Value:    val,
-						})
-					}
+			continue
+		}
+		if !strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, fmt.Errorf("insert failed: %w", err)
+		}
+		if options.Ignore {
+			result.Skipped++
+			result.Warnings = append(result.Warnings, err.Error())
+			continue
+		}
+
+		// 按主键或唯一列定位冲突的行
+		filters := make([]domain.Filter, 0)
+		for _, col := range tableInfo.Columns {
+			if col.Primary || col.Unique {
+				if val, ok := row[col.Name]; ok && val != nil {
+					filters = append(filters, domain.Filter{Field: col.Name, Operator: "=", Value: val})
 				}
 			}
-			if len(filters) > 0 {
-				n, updateErr := b.dataSource.Update(ctx, stmt.Table, filters, updateData, nil)
-				if updateErr != nil {
-					return nil, fmt.Errorf("upsert update failed: %w", updateErr)
-				}
-				totalAffected += n
+		}
+		if len(filters) == 0 {
+			return nil, fmt.Errorf("insert failed: %w", err)
+		}
+		existing, queryErr := b.dataSource.Query(ctx, stmt.Table, &domain.QueryOptions{Filters: filters})
+		if queryErr != nil {
+			return nil, fmt.Errorf("insert failed: %w", queryErr)
+		}
+		if len(existing.Rows) == 0 {
+			return nil, fmt.Errorf("insert failed: %w", err)
+		}
+		for _, existingRow := range existing.Rows {
+			if guardErr := checkUpsertGuard(existingRow, guard); guardErr != nil {
+				return nil, fmt.Errorf("insert failed: %w", err)
 			}
 		}
-		affected = totalAffected
-		err = nil
-	} else if err != nil {
-		return nil, fmt.Errorf("insert failed: %w", err)
+		// 赋值在冲突的已有行上求值（qty = qty + 10），VALUES(col) 取待插入行的值
+		updates, err := EvaluateAssignments(stmt.OnDuplicate.Set, existing.Rows[0], row)
+		if err != nil {
+			return nil, fmt.Errorf("upsert update failed: %w", err)
+		}
+		n, err = b.dataSource.Update(ctx, stmt.Table, filters, updates, nil)
+		if err != nil {
+			return nil, fmt.Errorf("upsert update failed: %w", err)
+		}
+		if n > 0 {
+			result.Updated++
+		} else {
+			result.Unchanged++
+		}
 	}
+	return result, nil
+}
 
-	// Get last insert ID from auto-increment column
-	var lastInsertID int64
-	tableInfo2, _ := b.dataSource.GetTableInfo(ctx, stmt.Table)
-	if tableInfo2 != nil {
-		for _, col := range tableInfo2.Columns {
-			if col.AutoIncrement {
-				// Find the last inserted row's ID
-				if len(rows) > 0 {
-					if val, ok := rows[len(rows)-1][col.Name]; ok {
-						switch v := val.(type) {
-						case int64:
-							lastInsertID = v
-						case int:
-							lastInsertID = int64(v)
-						case float64:
-							lastInsertID = int64(v)
-						}
-					}
-				}
-				break
-			}
-		}
+// checkUpsertGuard 检查冲突的已有行满足 ON DUPLICATE KEY UPDATE 的条件，不满足时按主键冲突报错；
+// existing 为 nil 时由调用方自行检查
+func checkUpsertGuard(existing domain.Row, guard []domain.Filter) error {
+	if existing == nil || len(guard) == 0 {
+		return nil
+	}
+	ok, err := utils.MatchesAllSubFilters(existing, guard)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("Duplicate entry: the conflicting row cannot be updated by this statement")
 	}
+	return nil
+}
 
-	return &domain.QueryResult{
-		Total: affected,
-		Rows: []domain.Row{
-			{"rows_affected": affected, "last_insert_id": lastInsertID},
-		},
-	}, nil
+// autoIncrementValue 返回行中自增列的值，没有自增列或值不是整数时返回 0
+func autoIncrementValue(tableInfo *domain.TableInfo, row domain.Row) int64 {
+	for _, col := range tableInfo.Columns {
+		if !col.AutoIncrement {
+			continue
+		}
+		switch v := row[col.Name].(type) {
+		case int64:
+			return v
+		case int:
+			return int64(v)
+		case float64:
+			return int64(v)
+		}
+		return 0
+	}
+	return 0
 }
 
 // executeUpdate 执行 UPDATE
@@ -1102,9 +1492,9 @@ func (b *QueryBuilder) executeUpdate(ctx context.Context, stmt *UpdateStatement)
 	}
 
 	// 转换 WHERE 条件
-	var filters []domain.Filter
-	if stmt.Where != nil {
-		filters = b.convertExpressionToFilters(stmt.Where)
+	filters, err := b.writeFilters(ctx, stmt.Table, stmt.Where)
+	if err != nil {
+		return nil, err
 	}
 
 	// 转换更新数据，并过滤生成列
@@ -1116,12 +1506,12 @@ func (b *QueryBuilder) executeUpdate(ctx context.Context, stmt *UpdateStatement)
 	filteredUpdates := generated.FilterGeneratedColumns(updates, tableInfo)
 
 	// Check if table is a view and validate with CHECK OPTION
-	if viewInfo, isView := b.getViewInfo(tableInfo); isView {
+	if viewInfo, isView := b.getViewInfo(tableInfo); isView && !HasRowAssignments(filteredUpdates) {
 		validator := NewCheckOptionValidator(viewInfo)
 
 		// Get rows that would be updated to validate them
 		queryResult, err := b.dataSource.Query(ctx, stmt.Table, &domain.QueryOptions{
-			Filters: b.convertExpressionToFilters(stmt.Where),
+			Filters: filters,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to query rows for check option validation: %w", err)
@@ -1135,14 +1525,27 @@ func (b *QueryBuilder) executeUpdate(ctx context.Context, stmt *UpdateStatement)
 		}
 	}
 
-	options := &domain.UpdateOptions{
-		Upsert: false,
+	// SET 中引用列的表达式（qty = qty + 1）在每一行上求值，逐行按主键更新
+	var affected int64
+	if HasRowAssignments(filteredUpdates) {
+		var check func(row, updates domain.Row) error
+		if viewInfo, isView := b.getViewInfo(tableInfo); isView {
+			validator := NewCheckOptionValidator(viewInfo)
+			check = func(row, updates domain.Row) error {
+				if err := validator.ValidateUpdate(row, updates); err != nil {
+					return fmt.Errorf("view check option failed: %w", err)
+				}
+				return nil
+			}
+		}
+		affected, err = UpdateEachRow(ctx, b.dataSource, stmt.Table, tableInfo, filters, filteredUpdates, check)
+	} else {
+		affected, err = b.dataSource.Update(ctx, stmt.Table, filters, filteredUpdates, &domain.UpdateOptions{})
 	}
-
-	affected, err := b.dataSource.Update(ctx, stmt.Table, filters, filteredUpdates, options)
 	if err != nil {
 		return nil, fmt.Errorf("update failed: %w", err)
 	}
+	domain.RecordRowsMatched(ctx, affected)
 
 	return &domain.QueryResult{
 		Total: affected,
@@ -1157,9 +1560,9 @@ func (b *QueryBuilder) executeDelete(ctx context.Context, stmt *DeleteStatement)
 	}
 
 	// 转换 WHERE 条件
-	var filters []domain.Filter
-	if stmt.Where != nil {
-		filters = b.convertExpressionToFilters(stmt.Where)
+	filters, err := b.writeFilters(ctx, stmt.Table, stmt.Where)
+	if err != nil {
+		return nil, err
 	}
 
 	options := &domain.DeleteOptions{
@@ -1170,12 +1573,103 @@ func (b *QueryBuilder) executeDelete(ctx context.Context, stmt *DeleteStatement)
 	if err != nil {
 		return nil, fmt.Errorf("delete failed: %w", err)
 	}
+	domain.RecordRowsMatched(ctx, affected)
 
 	return &domain.QueryResult{
 		Total: affected,
 	}, nil
 }
 
+// writeFilters 把 UPDATE/DELETE 的 WHERE 转换为数据源过滤器。
+// 无法下推的剩余条件在本地对候选行求值，再以命中行的主键作为过滤器，保证不会放宽匹配范围
+func (b *QueryBuilder) writeFilters(ctx context.Context, tableName string, where *Expression) ([]domain.Filter, error) {
+	where, err := MaterializeSubqueries(ctx, where, b.executeSelect)
+	if err != nil {
+		return nil, err
+	}
+	filters, residual := SplitFilters(where)
+	filters = b.convertFilterValues(filters)
+	if residual == nil {
+		return filters, nil
+	}
+
+	tableInfo, err := b.dataSource.GetTableInfo(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table info: %w", err)
+	}
+	var keys []string
+	for _, col := range tableInfo.Columns {
+		if col.Primary {
+			keys = append(keys, col.Name)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("WHERE condition on table %s cannot be pushed down and the table has no primary key", tableName)
+	}
+
+	result, err := b.dataSource.Query(ctx, tableName, &domain.QueryOptions{Filters: filters})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rows for WHERE evaluation: %w", err)
+	}
+	rows, err := filterRows(result.Rows, residual)
+	if err != nil {
+		return nil, err
+	}
+	return []domain.Filter{primaryKeyFilter(keys, rows)}, nil
+}
+
+// primaryKeyFilter 构造只匹配 rows 中主键的过滤器：单列主键使用 IN，复合主键使用 OR 连接的 AND；
+// 没有命中行时返回空 IN 列表，不匹配任何行
+func primaryKeyFilter(keys []string, rows []domain.Row) domain.Filter {
+	if len(keys) == 1 || len(rows) == 0 {
+		values := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, row[keys[0]])
+		}
+		return domain.Filter{Field: keys[0], Operator: "IN", Value: values}
+	}
+
+	anyOf := make([]domain.Filter, 0, len(rows))
+	for _, row := range rows {
+		allOf := make([]domain.Filter, 0, len(keys))
+		for _, key := range keys {
+			allOf = append(allOf, domain.Filter{Field: key, Operator: "=", Value: row[key]})
+		}
+		anyOf = append(anyOf, domain.Filter{LogicOp: "AND", SubFilters: allOf})
+	}
+	return domain.Filter{LogicOp: "OR", SubFilters: anyOf}
+}
+
+// filterRows 保留 cond 求值为 TRUE 的行，cond 为 nil 时原样返回
+func filterRows(rows []domain.Row, cond *Expression) ([]domain.Row, error) {
+	if cond == nil {
+		return rows, nil
+	}
+	filtered := make([]domain.Row, 0, len(rows))
+	for _, row := range rows {
+		match, err := evaluateCondition(row, cond)
+		if err != nil {
+			return nil, err
+		}
+		if match.IsTrue() {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered, nil
+}
+
+// convertFilterValues 规范化下推过滤器中的常量
+func (b *QueryBuilder) convertFilterValues(filters []domain.Filter) []domain.Filter {
+	for i := range filters {
+		if len(filters[i].SubFilters) > 0 {
+			b.convertFilterValues(filters[i].SubFilters)
+			continue
+		}
+		filters[i].Value = b.convertValue(filters[i].Value)
+	}
+	return filters
+}
+
 // executeCreate 执行 CREATE
 func (b *QueryBuilder) executeCreate(ctx context.Context, stmt *CreateStatement) (*domain.QueryResult, error) {
 	// 检查数据源是否可写
@@ -1198,14 +1692,27 @@ func (b *QueryBuilder) executeCreate(ctx context.Context, stmt *CreateStatement)
 				Default:       fmt.Sprintf("%v", col.Default),
 				Unique:        col.Unique,
 				AutoIncrement: col.AutoInc,
+				Unsigned:      col.Unsigned,
+				Zerofill:      col.Zerofill,
 				// Generated column support
 				IsGenerated:      col.IsGenerated,
 				GeneratedType:    col.GeneratedType,
 				GeneratedExpr:    col.GeneratedExpr,
 				GeneratedDepends: col.GeneratedDepends,
+				Encryption:       col.Encryption.ToDomain(),
 			})
 		}
 
+		if stmt.Partition != nil {
+			tableInfo.Partition = &domain.PartitionInfo{
+				Type:       stmt.Partition.Type,
+				Column:     stmt.Partition.Column,
+				Partitions: toDomainPartitionDefs(stmt.Partition.Partitions),
+			}
+		}
+
+		tableInfo.TTL = toDomainTTL(stmt.TTL)
+
 		// Handle PERSISTENT option for hybrid data source
 		if stmt.Persistent {
 			// Check if data source supports EnablePersistence (HybridDataSource)
@@ -1280,7 +1787,338 @@ func (b *QueryBuilder) executeDrop(ctx context.Context, stmt *DropStatement) (*d
 
 // executeAlter 执行 ALTER
 func (b *QueryBuilder) executeAlter(ctx context.Context, stmt *AlterStatement) (*domain.QueryResult, error) {
-	return nil, fmt.Errorf("ALTER TABLE is not currently supported")
+	// 目前仅支持分区管理和 TTL
+	if len(stmt.Actions) == 0 {
+		return nil, fmt.Errorf("ALTER TABLE is not currently supported")
+	}
+	for _, action := range stmt.Actions {
+		switch action.Type {
+		case AlterActionAddPartition, AlterActionDropPartition, AlterActionSetTTL, AlterActionRemoveTTL:
+		default:
+			return nil, fmt.Errorf("ALTER TABLE is not currently supported")
+		}
+	}
+
+	if !b.dataSource.IsWritable() {
+		return nil, fmt.Errorf("data source is read-only, ALTER operation not allowed")
+	}
+
+	for _, action := range stmt.Actions {
+		var err error
+		switch action.Type {
+		case AlterActionAddPartition, AlterActionDropPartition:
+			err = b.alterPartitions(ctx, stmt.Name, action)
+		case AlterActionSetTTL, AlterActionRemoveTTL:
+			tm, ok := b.dataSource.(domain.TTLManager)
+			if !ok {
+				return nil, fmt.Errorf("data source does not support TTL")
+			}
+			err = tm.SetTableTTL(ctx, stmt.Name, toDomainTTL(action.TTL))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("alter table '%s' failed: %w", stmt.Name, err)
+		}
+	}
+
+	return &domain.QueryResult{
+		Total: 0,
+	}, nil
+}
+
+// executeOptimize 执行 OPTIMIZE TABLE：在线整理表的行存储和索引
+// 每个表返回一行 MySQL 格式的结果（Table, Op, Msg_type, Msg_text）
+func (b *QueryBuilder) executeOptimize(ctx context.Context, stmt *OptimizeStatement) (*domain.QueryResult, error) {
+	tc, ok := b.dataSource.(domain.TableCompactor)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support OPTIMIZE TABLE")
+	}
+
+	result := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Table", Type: "VARCHAR"},
+			{Name: "Op", Type: "VARCHAR"},
+			{Name: "Msg_type", Type: "VARCHAR"},
+			{Name: "Msg_text", Type: "VARCHAR"},
+		},
+	}
+	for _, table := range stmt.Tables {
+		progress, err := tc.CompactTable(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("optimize table '%s' failed: %w", table, err)
+		}
+		result.Rows = append(result.Rows, domain.Row{
+			"Table":    table,
+			"Op":       "optimize",
+			"Msg_type": "status",
+			"Msg_text": fmt.Sprintf("OK: %d rows compacted, %d pages -> %d pages", progress.RowsProcessed, progress.PagesBefore, progress.PagesAfter),
+		})
+	}
+	result.Total = int64(len(result.Rows))
+	return result, nil
+}
+
+// executeExportTable 执行 EXPORT TABLE：将表导出为二进制快照文件，Total 为导出的行数
+func (b *QueryBuilder) executeExportTable(ctx context.Context, stmt *ExportTableStatement) (*domain.QueryResult, error) {
+	ts, ok := b.dataSource.(domain.TableSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support EXPORT TABLE")
+	}
+	info, err := ts.ExportTable(ctx, stmt.Table, stmt.File)
+	if err != nil {
+		return nil, fmt.Errorf("export table '%s' failed: %w", stmt.Table, err)
+	}
+	return &domain.QueryResult{Total: info.Rows}, nil
+}
+
+// executeImportTable 执行 IMPORT TABLE：从快照文件创建表，Total 为导入的行数
+func (b *QueryBuilder) executeImportTable(ctx context.Context, stmt *ImportTableStatement) (*domain.QueryResult, error) {
+	ts, ok := b.dataSource.(domain.TableSnapshotter)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support IMPORT TABLE")
+	}
+	info, err := ts.ImportTable(ctx, stmt.Table, stmt.File)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.QueryResult{Total: info.Rows}, nil
+}
+
+// executeUndropTable 执行 UNDROP TABLE：从回收站恢复最近删除的同名表
+func (b *QueryBuilder) executeUndropTable(ctx context.Context, stmt *UndropTableStatement) (*domain.QueryResult, error) {
+	rb, ok := b.dataSource.(domain.RecycleBin)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support UNDROP TABLE")
+	}
+	if err := rb.UndropTable(ctx, stmt.Table); err != nil {
+		return nil, fmt.Errorf("undrop table '%s' failed: %w", stmt.Table, err)
+	}
+	return &domain.QueryResult{Total: 0}, nil
+}
+
+// executeFlashbackTable 执行 FLASHBACK TABLE ... TO TIMESTAMP：撤销表在该时间之后提交的 DML，
+// Total 为撤销的变更数
+func (b *QueryBuilder) executeFlashbackTable(ctx context.Context, stmt *FlashbackTableStatement) (*domain.QueryResult, error) {
+	fb, ok := b.dataSource.(domain.Flashbacker)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support FLASHBACK TABLE")
+	}
+	to, err := ParseTimestamp(stmt.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	reversed, err := fb.FlashbackTable(ctx, stmt.Table, to)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.QueryResult{Total: reversed}, nil
+}
+
+// generateBatchSize CREATE TABLE ... AS GENERATE 每批写入的行数
+const generateBatchSize = 10000
+
+// executeGenerateTable 执行 CREATE TABLE ... AS GENERATE：创建表并分批写入生成的数据，
+// 写入失败时删除已创建的表，Total 为写入的行数
+func (b *QueryBuilder) executeGenerateTable(ctx context.Context, stmt *GenerateTableStatement) (*domain.QueryResult, error) {
+	if !b.dataSource.IsWritable() {
+		return nil, fmt.Errorf("data source is read-only, CREATE operation not allowed")
+	}
+
+	gens := make([]datagen.Generator, len(stmt.Columns))
+	tableInfo := &domain.TableInfo{Name: stmt.Table, Columns: make([]domain.ColumnInfo, len(stmt.Columns))}
+	for i, col := range stmt.Columns {
+		gen, err := datagen.New(col.Generator, col.Args)
+		if err != nil {
+			return nil, fmt.Errorf("column '%s': %w", col.Name, err)
+		}
+		gens[i] = gen
+		tableInfo.Columns[i] = domain.ColumnInfo{
+			Name:     col.Name,
+			Type:     gen.Type(),
+			Nullable: !col.Primary,
+			Primary:  col.Primary,
+		}
+	}
+
+	if err := b.dataSource.CreateTable(ctx, tableInfo); err != nil {
+		return nil, fmt.Errorf("create table failed: %w", err)
+	}
+
+	rnd := rand.New(rand.NewSource(stmt.Seed))
+	for start := int64(0); start < stmt.Rows; start += generateBatchSize {
+		if err := ctx.Err(); err != nil {
+			_ = b.dataSource.DropTable(context.Background(), stmt.Table)
+			return nil, err
+		}
+		end := start + generateBatchSize
+		if end > stmt.Rows {
+			end = stmt.Rows
+		}
+		rows := make([]domain.Row, 0, end-start)
+		for row := start; row < end; row++ {
+			r := make(domain.Row, len(gens))
+			for i, gen := range gens {
+				r[stmt.Columns[i].Name] = gen.Next(rnd, row)
+			}
+			rows = append(rows, r)
+		}
+		if _, err := b.dataSource.Insert(ctx, stmt.Table, rows, &domain.InsertOptions{}); err != nil {
+			_ = b.dataSource.DropTable(context.Background(), stmt.Table)
+			return nil, fmt.Errorf("generate table '%s' failed: %w", stmt.Table, err)
+		}
+	}
+	return &domain.QueryResult{Total: stmt.Rows}, nil
+}
+
+// executeImportData 执行 IMPORT DATA INFILE：把文件导入表，Total 为写入的行数，
+// 结果行的 info 与 MySQL LOAD DATA 的信息格式一致，collect 模式收集的错误行作为警告返回
+func (b *QueryBuilder) executeImportData(ctx context.Context, stmt *ImportDataStatement) (*domain.QueryResult, error) {
+	if !b.dataSource.IsWritable() {
+		return nil, fmt.Errorf("data source is read-only, IMPORT DATA not allowed")
+	}
+	opts, err := dataimport.ParseOptions(stmt.Format, stmt.Columns, stmt.Options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := dataimport.Import(ctx, b.dataSource, stmt.Table, stmt.File, opts)
+	if err != nil {
+		return nil, fmt.Errorf("import data into '%s' failed: %w", stmt.Table, err)
+	}
+	result := &domain.QueryResult{
+		Total: res.Rows,
+		Rows:  []domain.Row{{"rows_affected": res.Rows, "info": res.Info()}},
+	}
+	for _, bad := range res.BadRows {
+		result.Warnings = append(result.Warnings, bad.String())
+	}
+	return result, nil
+}
+
+// executeChecksum 执行 CHECKSUM TABLE：每个表返回一行（Table, Checksum），表不存在时校验和为 NULL
+func (b *QueryBuilder) executeChecksum(ctx context.Context, stmt *ChecksumStatement) (*domain.QueryResult, error) {
+	tc, ok := b.dataSource.(domain.TableChecker)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support CHECKSUM TABLE")
+	}
+
+	result := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Table", Type: "VARCHAR"},
+			{Name: "Checksum", Type: "BIGINT UNSIGNED", Nullable: true},
+		},
+	}
+	for _, table := range stmt.Tables {
+		row := domain.Row{"Table": table, "Checksum": nil}
+		checksum, err := tc.ChecksumTable(ctx, table)
+		var notFound *domain.ErrTableNotFound
+		switch {
+		case err == nil:
+			row["Checksum"] = checksum.Checksum
+		case !errors.As(err, &notFound):
+			return nil, fmt.Errorf("checksum table '%s' failed: %w", table, err)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	result.Total = int64(len(result.Rows))
+	return result, nil
+}
+
+// executeCheck 执行 CHECK TABLE：每个发现的问题返回一行 error，
+// 每个表最后返回一行 MySQL 格式的结论（Table, Op, Msg_type, Msg_text）
+func (b *QueryBuilder) executeCheck(ctx context.Context, stmt *CheckStatement) (*domain.QueryResult, error) {
+	tc, ok := b.dataSource.(domain.TableChecker)
+	if !ok {
+		return nil, fmt.Errorf("data source does not support CHECK TABLE")
+	}
+
+	result := &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Table", Type: "VARCHAR"},
+			{Name: "Op", Type: "VARCHAR"},
+			{Name: "Msg_type", Type: "VARCHAR"},
+			{Name: "Msg_text", Type: "VARCHAR"},
+		},
+	}
+	addRow := func(table, msgType, msgText string) {
+		result.Rows = append(result.Rows, domain.Row{"Table": table, "Op": "check", "Msg_type": msgType, "Msg_text": msgText})
+	}
+	for _, table := range stmt.Tables {
+		check, err := tc.CheckTable(ctx, table)
+		if err != nil {
+			var notFound *domain.ErrTableNotFound
+			if !errors.As(err, &notFound) {
+				return nil, fmt.Errorf("check table '%s' failed: %w", table, err)
+			}
+			addRow(table, "Error", fmt.Sprintf("Table '%s' doesn't exist", table))
+			addRow(table, "status", "Operation failed")
+			continue
+		}
+		for _, problem := range check.Problems {
+			addRow(table, "Error", problem)
+		}
+		if check.OK() {
+			addRow(table, "status", "OK")
+		} else {
+			addRow(table, "error", "Corrupt")
+		}
+	}
+	result.Total = int64(len(result.Rows))
+	return result, nil
+}
+
+// alterPartitions 执行 ADD PARTITION / DROP PARTITION
+func (b *QueryBuilder) alterPartitions(ctx context.Context, tableName string, action AlterAction) error {
+	pm, ok := b.dataSource.(domain.PartitionManager)
+	if !ok {
+		return fmt.Errorf("data source does not support partition management")
+	}
+
+	if action.Type == AlterActionDropPartition {
+		return pm.DropPartitions(ctx, tableName, action.PartitionNames)
+	}
+
+	defs := toDomainPartitionDefs(action.Partitions)
+	if len(defs) == 0 && action.PartitionNum > 0 {
+		// ADD PARTITION PARTITIONS n：HASH 分区按序号继续命名
+		var err error
+		defs, err = b.nextHashPartitionDefs(ctx, tableName, action.PartitionNum)
+		if err != nil {
+			return err
+		}
+	}
+	return pm.AddPartitions(ctx, tableName, defs)
+}
+
+// toDomainTTL 将解析得到的 TTL 转换为 domain 过期策略（nil 表示移除）
+func toDomainTTL(ttl *TTLInfo) *domain.TTLInfo {
+	if ttl == nil {
+		return nil
+	}
+	return &domain.TTLInfo{Column: ttl.Column, Duration: ttl.Duration}
+}
+
+// nextHashPartitionDefs 为 HASH 分区表生成 count 个新分区定义
+func (b *QueryBuilder) nextHashPartitionDefs(ctx context.Context, tableName string, count int) ([]domain.PartitionDef, error) {
+	tableInfo, err := b.dataSource.GetTableInfo(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if !tableInfo.IsPartitioned() {
+		return nil, fmt.Errorf("table %s is not partitioned", tableName)
+	}
+	return toDomainPartitionDefs(hashPartitionDefs(len(tableInfo.Partition.Partitions), count)), nil
+}
+
+// toDomainPartitionDefs 将解析得到的分区定义转换为 domain 分区定义
+func toDomainPartitionDefs(defs []PartitionDef) []domain.PartitionDef {
+	result := make([]domain.PartitionDef, 0, len(defs))
+	for _, def := range defs {
+		pd := domain.PartitionDef{Name: def.Name}
+		if !def.MaxValue {
+			pd.LessThan = def.LessThan
+		}
+		result = append(result, pd)
+	}
+	return result
 }
 
 // executeCreateIndex 执行 CREATE INDEX
@@ -1308,6 +2146,34 @@ func (b *QueryBuilder) executeCreateIndex(ctx context.Context, stmt *CreateIndex
 		idxType = "btree" // 默认使用 btree
 	}
 
+	// 函数索引：索引键由表达式计算
+	if stmt.Expression != "" {
+		fim, ok := b.dataSource.(domain.FunctionalIndexManager)
+		if !ok {
+			return nil, fmt.Errorf("data source does not support functional indexes")
+		}
+		err := fim.CreateFunctionalIndex(ctx, stmt.TableName, domain.FunctionalIndex{
+			Name:       stmt.IndexName,
+			Expression: stmt.Expression,
+			Depends:    stmt.ExpressionColumns,
+			Unique:     stmt.Unique,
+		}, idxType)
+		if err != nil {
+			return nil, fmt.Errorf("create index failed: %w", err)
+		}
+		return &domain.QueryResult{Total: 0}, nil
+	}
+
+	// 优先使用可报告回填进度的接口
+	if progressIndexManager, ok := b.dataSource.(interface {
+		CreateIndexWithColumnsContext(ctx context.Context, tableName string, columnNames []string, indexType string, unique bool) error
+	}); ok {
+		if err := progressIndexManager.CreateIndexWithColumnsContext(ctx, stmt.TableName, stmt.Columns, idxType, stmt.Unique); err != nil {
+			return nil, fmt.Errorf("create index failed: %w", err)
+		}
+		return &domain.QueryResult{Total: 0}, nil
+	}
+
 	// 使用支持多列索引的接口
 	indexManager, ok := b.dataSource.(interface {
 		CreateIndexWithColumns(tableName string, columnNames []string, indexType string, unique bool) error
@@ -1452,124 +2318,6 @@ func (b *QueryBuilder) executeDropIndex(ctx context.Context, stmt *DropIndexStat
 	}, nil
 }
 
-// convertExpressionToFilters 将表达式转换为过滤器列表
-func (b *QueryBuilder) convertExpressionToFilters(expr *Expression) []domain.Filter {
-	return b.convertExpressionToFiltersInternal(expr, false)
-}
-
-// convertExpressionToFiltersInternal 内部递归函数
-func (b *QueryBuilder) convertExpressionToFiltersInternal(expr *Expression, isInOr bool) []domain.Filter {
-	filters := make([]domain.Filter, 0)
-
-	if expr == nil {
-		return filters
-	}
-
-	switch expr.Type {
-	case ExprTypeOperator:
-		// 处理 IS NULL / IS NOT NULL（一元运算符，只有 Left，没有 Right）
-		if expr.Left != nil && expr.Right == nil {
-			op := strings.ToUpper(expr.Operator)
-			if (op == "IS NULL" || op == "ISNULL") && expr.Left.Type == ExprTypeColumn {
-				filters = append(filters, domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: "IS NULL",
-					Value:    nil,
-				})
-				return filters
-			}
-			if (op == "IS NOT NULL" || op == "ISNOTNULL") && expr.Left.Type == ExprTypeColumn {
-				filters = append(filters, domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: "IS NOT NULL",
-					Value:    nil,
-				})
-				return filters
-			}
-		}
-
-		if expr.Left != nil && expr.Right != nil {
-			if expr.Operator == "and" || expr.Operator == "or" {
-				leftFilters := b.convertExpressionToFiltersInternal(expr.Left, expr.Operator == "or")
-				rightFilters := b.convertExpressionToFiltersInternal(expr.Right, expr.Operator == "or")
-
-				if len(leftFilters) > 0 || len(rightFilters) > 0 {
-					logicOp := strings.ToUpper(expr.Operator)
-					filters = append(filters, domain.Filter{
-						LogicOp:    logicOp,
-						SubFilters: append(leftFilters, rightFilters...),
-					})
-				}
-				return filters
-			}
-
-			if expr.Left.Type == ExprTypeColumn && expr.Right.Type == ExprTypeValue {
-				operator := b.convertOperator(expr.Operator)
-				value := b.convertValue(expr.Right.Value)
-				filters = append(filters, domain.Filter{
-					Field:    expr.Left.Column,
-					Operator: operator,
-					Value:    value,
-				})
-				return filters
-			}
-
-			// 处理 BETWEEN 操作符
-			if expr.Left.Type == ExprTypeColumn && expr.Right.Type == ExprTypeValue {
-				if expr.Operator == "BETWEEN" || expr.Operator == "NOT BETWEEN" {
-					// expr.Right.Value 应该是 [min, max] 的数组
-					if valueSlice, ok := expr.Right.Value.([]interface{}); ok && len(valueSlice) >= 2 {
-						// 获取左边界和右边界的值
-						minValue := b.extractExpressionValue(valueSlice[0])
-						maxValue := b.extractExpressionValue(valueSlice[1])
-						if minValue != nil && maxValue != nil {
-							operator := expr.Operator
-							filters = append(filters, domain.Filter{
-								Field:    expr.Left.Column,
-								Operator: operator,
-								Value:    []interface{}{minValue, maxValue},
-							})
-							return filters
-						}
-					}
-				}
-			}
-		}
-
-	case ExprTypeFunction:
-		if expr.Function == "in" {
-			if len(expr.Args) > 1 {
-				if expr.Args[0].Type == ExprTypeColumn {
-					values := make([]interface{}, 0)
-					for i := 1; i < len(expr.Args); i++ {
-						if expr.Args[i].Type == ExprTypeValue {
-							values = append(values, b.convertValue(expr.Args[i].Value))
-						}
-					}
-					filters = append(filters, domain.Filter{
-						Field:    expr.Args[0].Column,
-						Operator: "IN",
-						Value:    values,
-					})
-					return filters
-				}
-			}
-		}
-
-	case ExprTypeColumn:
-		if expr.Type == ExprTypeColumn {
-			filters = append(filters, domain.Filter{
-				Field:    expr.Column,
-				Operator: "=",
-				Value:    true,
-			})
-			return filters
-		}
-	}
-
-	return filters
-}
-
 // convertOperator 转换操作符
 func (b *QueryBuilder) convertOperator(op string) string {
 	switch op {
@@ -1581,6 +2329,8 @@ func (b *QueryBuilder) convertOperator(op string) string {
 		return "="
 	case "ne", "NE": // TiDB Parser使用小写"ne"
 		return "!="
+	case "nulleq", "NULLEQ": // NULL 安全等于 <=>
+		return "<=>"
 	case ">", "gt", "GT":
 		return ">"
 	case "<", "lt", "LT":
@@ -1653,26 +2403,6 @@ func (b *QueryBuilder) convertValue(val interface{}) interface{} {
 	}
 }
 
-// extractExpressionValue 从表达式或值中提取实际值
-func (b *QueryBuilder) extractExpressionValue(val interface{}) interface{} {
-	if val == nil {
-		return nil
-	}
-
-	// 如果是 Expression 类型，提取其值
-	if expr, ok := val.(*Expression); ok && expr != nil {
-		if expr.Type == ExprTypeValue {
-			return expr.Value
-		}
-		// 递归处理嵌套表达式
-		if expr.Left != nil {
-			return b.extractExpressionValue(expr.Left)
-		}
-	}
-
-	return b.convertValue(val)
-}
-
 // getViewInfo checks if a table is a view and returns its metadata
 func (b *QueryBuilder) getViewInfo(tableInfo *domain.TableInfo) (*domain.ViewInfo, bool) {
 	if tableInfo.Atts == nil {
@@ -1848,7 +2578,9 @@ func (b *QueryBuilder) executeAlterView(ctx context.Context, stmt *AlterViewStat
 }
 */
 
-// buildSelectSQL builds a SELECT SQL string from a SelectStatement
+// buildSelectSQL builds a SELECT SQL string from a SelectStatement.
+// Identifiers are backtick-quoted and literals escaped so the stored view
+// definition re-parses to the same statement.
 func (b *QueryBuilder) buildSelectSQL(stmt *SelectStatement) string {
 	sql := "SELECT"
 
@@ -1861,12 +2593,9 @@ func (b *QueryBuilder) buildSelectSQL(stmt *SelectStatement) string {
 	if len(stmt.Columns) > 0 {
 		colNames := make([]string, 0, len(stmt.Columns))
 		for _, col := range stmt.Columns {
-			name := col.Name
-			if name == "" {
-				name = "*"
-			}
+			name := b.buildSelectColumnSQL(col)
 			if col.Alias != "" {
-				name += " AS " + col.Alias
+				name += " AS " + security.QuoteIdentifier(col.Alias)
 			}
 			colNames = append(colNames, name)
 		}
@@ -1875,7 +2604,7 @@ func (b *QueryBuilder) buildSelectSQL(stmt *SelectStatement) string {
 
 	// FROM
 	if stmt.From != "" {
-		sql += " FROM " + stmt.From
+		sql += " FROM " + quoteColumnRef(stmt.From)
 	}
 
 	// WHERE
@@ -1885,7 +2614,15 @@ func (b *QueryBuilder) buildSelectSQL(stmt *SelectStatement) string {
 
 	// GROUP BY
 	if len(stmt.GroupBy) > 0 {
-		sql += " GROUP BY " + strings.Join(stmt.GroupBy, ", ")
+		groupItems := make([]string, 0, len(stmt.GroupBy))
+		for _, item := range stmt.GroupBy {
+			if expr, ok := stmt.GroupByExprs[item]; ok && expr != nil {
+				groupItems = append(groupItems, b.buildExpressionSQL(expr))
+			} else {
+				groupItems = append(groupItems, quoteColumnRef(item))
+			}
+		}
+		sql += " GROUP BY " + strings.Join(groupItems, ", ")
 	}
 
 	// HAVING
@@ -1897,7 +2634,7 @@ func (b *QueryBuilder) buildSelectSQL(stmt *SelectStatement) string {
 	if len(stmt.OrderBy) > 0 {
 		orderItems := make([]string, 0, len(stmt.OrderBy))
 		for _, item := range stmt.OrderBy {
-			orderItems = append(orderItems, item.Column+" "+item.Direction)
+			orderItems = append(orderItems, quoteColumnRef(item.Column)+" "+item.Direction)
 		}
 		sql += " ORDER BY " + strings.Join(orderItems, ", ")
 	}
@@ -1915,6 +2652,24 @@ func (b *QueryBuilder) buildSelectSQL(stmt *SelectStatement) string {
 	return sql
 }
 
+// buildSelectColumnSQL builds the SQL for a single select list item (without alias)
+func (b *QueryBuilder) buildSelectColumnSQL(col SelectColumn) string {
+	switch {
+	case col.IsWildcard || (col.Name == "" && col.Expr == nil):
+		if col.Table != "" {
+			return security.QuoteIdentifier(col.Table) + ".*"
+		}
+		return "*"
+	case strings.HasPrefix(col.Name, "@"):
+		// 系统/用户变量保持原样
+		return col.Name
+	case col.Expr != nil && col.Expr.Type != ExprTypeColumn:
+		return b.buildExpressionSQL(col.Expr)
+	default:
+		return security.QuoteQualifiedIdentifier(col.Table, col.Name)
+	}
+}
+
 // buildExpressionSQL builds an expression SQL string from an Expression
 func (b *QueryBuilder) buildExpressionSQL(expr *Expression) string {
 	if expr == nil {
@@ -1923,10 +2678,10 @@ func (b *QueryBuilder) buildExpressionSQL(expr *Expression) string {
 
 	switch expr.Type {
 	case ExprTypeColumn:
-		return expr.Column
+		return quoteColumnRef(expr.Column)
 
 	case ExprTypeValue:
-		return fmt.Sprintf("%v", expr.Value)
+		return FormatSQLLiteral(expr.Value)
 
 	case ExprTypeOperator:
 		left := b.buildExpressionSQL(expr.Left)
@@ -1934,7 +2689,7 @@ func (b *QueryBuilder) buildExpressionSQL(expr *Expression) string {
 		if expr.Operator == "and" || expr.Operator == "or" {
 			return fmt.Sprintf("(%s) %s (%s)", left, strings.ToUpper(expr.Operator), right)
 		}
-		return fmt.Sprintf("%s %s %s", left, strings.ToUpper(expr.Operator), right)
+		return fmt.Sprintf("%s %s %s", left, sqlOperatorSymbol(expr.Operator), right)
 
 	case ExprTypeFunction:
 		args := make([]string, 0, len(expr.Args))
@@ -1948,6 +2703,48 @@ func (b *QueryBuilder) buildExpressionSQL(expr *Expression) string {
 	}
 }
 
+// sqlOperatorSymbol converts a parser operator name (e.g. "ge") to its SQL symbol
+func sqlOperatorSymbol(op string) string {
+	switch strings.ToLower(op) {
+	case "eq":
+		return "="
+	case "ne", "neq":
+		return "!="
+	case "gt":
+		return ">"
+	case "ge", "gte":
+		return ">="
+	case "lt":
+		return "<"
+	case "le", "lte":
+		return "<="
+	case "nulleq":
+		return "<=>"
+	case "plus":
+		return "+"
+	case "minus":
+		return "-"
+	case "mul":
+		return "*"
+	case "div":
+		return "/"
+	case "mod":
+		return "%"
+	case "intdiv":
+		return "DIV"
+	default:
+		return strings.ToUpper(op)
+	}
+}
+
+// quoteColumnRef quotes a possibly qualified name such as "db.t.col"
+func quoteColumnRef(name string) string {
+	if name == "*" {
+		return name
+	}
+	return security.QuoteQualifiedIdentifier(strings.Split(name, ".")...)
+}
+
 // parseViewAlgorithm 解析视图算法字符串为 ViewAlgorithm 类型
 func parseViewAlgorithm(algorithm string) domain.ViewAlgorithm {
 	switch strings.ToUpper(algorithm) {
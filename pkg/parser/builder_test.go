@@ -3,6 +3,7 @@ package parser
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
@@ -808,6 +809,149 @@ func TestExecuteSelect_HavingWithSum(t *testing.T) {
 	}
 }
 
+func TestExecuteSelect_HavingAliasWithOrderByAndLimit(t *testing.T) {
+	ds := setupUsersAndOrders()
+	builder := NewQueryBuilder(ds)
+
+	sumAmount := Expression{
+		Type:     ExprTypeFunction,
+		Function: "sum",
+		Args:     []Expression{{Type: ExprTypeColumn, Column: "amount"}},
+	}
+	limit := int64(1)
+
+	// SELECT product, SUM(amount) AS total FROM orders GROUP BY product
+	// HAVING total >= 200 ORDER BY SUM(amount) DESC, product DESC LIMIT 1
+	stmt := &SelectStatement{
+		Columns: []SelectColumn{
+			{Name: "product"},
+			{Expr: &sumAmount, Alias: "total", Name: "SUM(amount)"},
+		},
+		From:    "orders",
+		GroupBy: []string{"product"},
+		Having: &Expression{
+			Type:     ExprTypeOperator,
+			Operator: "ge",
+			Left:     &Expression{Type: ExprTypeColumn, Column: "total"},
+			Right:    &Expression{Type: ExprTypeValue, Value: int64(200)},
+		},
+		OrderBy: []OrderByItem{
+			{Column: "SUM(`amount`)", Direction: "DESC", Expr: &sumAmount},
+			{Column: "product", Direction: "DESC"},
+		},
+		Limit: &limit,
+	}
+
+	result, err := builder.executeSelect(context.Background(), stmt)
+	if err != nil {
+		t.Fatalf("executeSelect failed: %v", err)
+	}
+
+	// Widget=300, Gizmo=300, Gadget=200 -> sorted by total DESC then product DESC: Widget, Gizmo, Gadget
+	if len(result.Rows) != 1 {
+		t.Fatalf("expected 1 row after LIMIT, got %d: %v", len(result.Rows), result.Rows)
+	}
+	if prod := fmt.Sprintf("%v", result.Rows[0]["product"]); prod != "Widget" {
+		t.Errorf("expected Widget first, got %s", prod)
+	}
+
+	// 别名排序 + OFFSET
+	offset := int64(2)
+	stmt.OrderBy = []OrderByItem{{Column: "total", Direction: "ASC"}, {Column: "product", Direction: "ASC"}}
+	stmt.Limit = nil
+	stmt.Offset = &offset
+	result, err = builder.executeSelect(context.Background(), stmt)
+	if err != nil {
+		t.Fatalf("executeSelect failed: %v", err)
+	}
+	if len(result.Rows) != 1 || fmt.Sprintf("%v", result.Rows[0]["product"]) != "Widget" {
+		t.Errorf("expected [Widget] after OFFSET 2, got %v", result.Rows)
+	}
+}
+
+// sortableMockDataSource 声明支持多列排序的数据源，记录收到的查询选项，
+// 按原样返回行并声明已排序，用于验证查询层信任数据源的排序
+type sortableMockDataSource struct {
+	*mockDataSource
+	lastOptions *domain.QueryOptions
+}
+
+func (m *sortableMockDataSource) SupportsSort(tableName string, keys []domain.OrderByItem) bool {
+	return true
+}
+
+func (m *sortableMockDataSource) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	m.lastOptions = options
+	result, err := m.mockDataSource.Query(ctx, tableName, options)
+	if err != nil {
+		return nil, err
+	}
+	result.Ordered = true
+	return result, nil
+}
+
+func TestExecuteSelect_OrderByNegotiation(t *testing.T) {
+	limit, offset := int64(2), int64(1)
+	stmt := &SelectStatement{
+		Columns: []SelectColumn{{Name: "name"}},
+		From:    "users",
+		OrderBy: []OrderByItem{
+			{Column: "department", Direction: "ASC"},
+			{Column: "name", Direction: "DESC"},
+		},
+		Limit:  &limit,
+		Offset: &offset,
+	}
+	names := func(result *domain.QueryResult) []string {
+		out := make([]string, len(result.Rows))
+		for i, row := range result.Rows {
+			out[i] = fmt.Sprintf("%v", row["name"])
+		}
+		return out
+	}
+
+	// 数据源不支持多列排序：不下推分页，本地排序后再分页
+	// Engineering: Bob, Alice; HR: Eve; Sales: Diana, Charlie
+	result, err := NewQueryBuilder(setupUsersAndOrders()).executeSelect(context.Background(), stmt)
+	if err != nil {
+		t.Fatalf("executeSelect failed: %v", err)
+	}
+	if got := fmt.Sprint(names(result)); got != "[Alice Eve]" {
+		t.Errorf("expected [Alice Eve], got %s", got)
+	}
+
+	// 数据源声明支持并已排序：排序和分页一起下推，不再本地排序
+	ds := &sortableMockDataSource{mockDataSource: setupUsersAndOrders()}
+	if _, err := NewQueryBuilder(ds).executeSelect(context.Background(), stmt); err != nil {
+		t.Fatalf("executeSelect failed: %v", err)
+	}
+	opts := ds.lastOptions
+	if len(opts.Sort) != 2 || opts.Sort[1].Column != "name" || opts.Sort[1].Direction != "DESC" {
+		t.Errorf("expected both sort keys pushed down, got %v", opts.Sort)
+	}
+	if opts.OrderBy != "department" || opts.Limit != 2 || opts.Offset != 1 {
+		t.Errorf("expected legacy order and paging pushed down, got %+v", opts)
+	}
+
+	// 排序规则：大小写不敏感时 alice 与 Alice 相等，保持数据源返回的顺序
+	ci := newMockDataSource()
+	ci.addTable("t", []domain.ColumnInfo{{Name: "name", Type: "text"}}, []domain.Row{
+		{"name": "bob"}, {"name": "alice"}, {"name": "Alice"}, {"name": "Bob"},
+	})
+	stmt = &SelectStatement{
+		Columns: []SelectColumn{{Name: "name"}},
+		From:    "t",
+		OrderBy: []OrderByItem{{Column: "name", Direction: "ASC", Collation: "utf8mb4_general_ci"}},
+	}
+	result, err = NewQueryBuilder(ci).executeSelect(context.Background(), stmt)
+	if err != nil {
+		t.Fatalf("executeSelect failed: %v", err)
+	}
+	if got := fmt.Sprint(names(result)); got != "[alice Alice bob Bob]" {
+		t.Errorf("expected [alice Alice bob Bob], got %s", got)
+	}
+}
+
 // =============================================================================
 // Tests for combined features
 // =============================================================================
@@ -1130,6 +1274,59 @@ func TestExecuteSelect_InnerJoinNoMatch(t *testing.T) {
 	}
 }
 
+func TestExecuteSelect_JoinNullKeysNeverMatch(t *testing.T) {
+	ds := newMockDataSource()
+
+	ds.addTable("t1", []domain.ColumnInfo{
+		{Name: "id", Type: "int64", Primary: true},
+		{Name: "k", Type: "int64", Nullable: true},
+	}, []domain.Row{
+		{"id": int64(1), "k": int64(1)},
+		{"id": int64(2), "k": nil},
+	})
+
+	ds.addTable("t2", []domain.ColumnInfo{
+		{Name: "id", Type: "int64", Primary: true},
+		{Name: "k", Type: "int64", Nullable: true},
+	}, []domain.Row{
+		{"id": int64(10), "k": int64(1)},
+		{"id": int64(20), "k": nil},
+	})
+
+	builder := NewQueryBuilder(ds)
+
+	// "=" 走哈希连接，"eq" 走逐行条件求值，两者都不应匹配 NULL 键
+	for _, operator := range []string{"=", "eq"} {
+		stmt := &SelectStatement{
+			Columns: []SelectColumn{{IsWildcard: true}},
+			From:    "t1",
+			Joins: []JoinInfo{
+				{
+					Type:  JoinTypeInner,
+					Table: "t2",
+					Condition: &Expression{
+						Type:     ExprTypeOperator,
+						Operator: operator,
+						Left:     &Expression{Type: ExprTypeColumn, Column: "t1.k"},
+						Right:    &Expression{Type: ExprTypeColumn, Column: "t2.k"},
+					},
+				},
+			},
+		}
+
+		result, err := builder.executeSelect(context.Background(), stmt)
+		if err != nil {
+			t.Fatalf("executeSelect failed: %v", err)
+		}
+		if len(result.Rows) != 1 {
+			t.Errorf("INNER JOIN with %q on NULL keys: expected 1 row, got %d", operator, len(result.Rows))
+			for i, row := range result.Rows {
+				t.Logf("  row[%d]: %v", i, row)
+			}
+		}
+	}
+}
+
 func TestExecuteSelect_AggregateWithNulls(t *testing.T) {
 	ds := newMockDataSource()
 	ds.addTable("scores", []domain.ColumnInfo{
@@ -1747,3 +1944,144 @@ func TestGetColumnValue_PrefixResolution(t *testing.T) {
 		t.Errorf("getColumnValue missing key: expected nil, got %v", val)
 	}
 }
+
+func TestJoinedColumnValue(t *testing.T) {
+	cols := func(names ...string) []domain.ColumnInfo {
+		out := make([]domain.ColumnInfo, len(names))
+		for i, n := range names {
+			out[i] = domain.ColumnInfo{Name: n}
+		}
+		return out
+	}
+	tables := []joinedTable{
+		newJoinedTable("db1.users", "db1.users", "", cols("id", "name")),
+		newJoinedTable("orders", "orders", "o", cols("id", "total")),
+	}
+	row := domain.Row{
+		"id": int64(1), "name": "alice",
+		"db1.users.id": int64(1), "db1.users.name": "alice",
+		"orders.id": int64(7), "o.id": int64(7), "orders.total": 9.5, "o.total": 9.5,
+	}
+
+	val, ok, err := joinedColumnValue(row, SelectColumn{Table: "o", Name: "id"}, tables)
+	if err != nil || !ok || val != int64(7) {
+		t.Errorf("o.id: got %v, %v, %v", val, ok, err)
+	}
+	// 带库名的表可以只用表名引用
+	val, ok, err = joinedColumnValue(row, SelectColumn{Table: "users", Name: "id"}, tables)
+	if err != nil || !ok || val != int64(1) {
+		t.Errorf("users.id: got %v, %v, %v", val, ok, err)
+	}
+	val, ok, err = joinedColumnValue(row, SelectColumn{Name: "total"}, tables)
+	if err != nil || !ok || val != 9.5 {
+		t.Errorf("total: got %v, %v, %v", val, ok, err)
+	}
+
+	// 两张表都有 id 列
+	_, _, err = joinedColumnValue(row, SelectColumn{Name: "id"}, tables)
+	if err == nil || !strings.Contains(err.Error(), "Column 'id' in field list is ambiguous") {
+		t.Errorf("unqualified id: expected ambiguity error, got %v", err)
+	}
+
+	// 没有 JOIN 时直接按列名查找
+	val, ok, err = joinedColumnValue(domain.Row{"id": int64(3)}, SelectColumn{Name: "id"}, nil)
+	if err != nil || !ok || val != int64(3) {
+		t.Errorf("no joins: got %v, %v, %v", val, ok, err)
+	}
+}
+
+// upsertMockDataSource 主键冲突时插入报错的数据源，记录 ON DUPLICATE KEY UPDATE 的更新
+type upsertMockDataSource struct {
+	*mockDataSource
+	updates []domain.Row
+}
+
+func (m *upsertMockDataSource) Insert(ctx context.Context, tableName string, rows []domain.Row, options *domain.InsertOptions) (int64, error) {
+	for _, row := range rows {
+		for _, existing := range m.data[tableName] {
+			if fmt.Sprint(existing["id"]) == fmt.Sprint(row["id"]) {
+				return 0, fmt.Errorf("Duplicate entry '%v' for key 'PRIMARY'", row["id"])
+			}
+		}
+		m.data[tableName] = append(m.data[tableName], row)
+	}
+	return int64(len(rows)), nil
+}
+
+func (m *upsertMockDataSource) Update(ctx context.Context, tableName string, filters []domain.Filter, updates domain.Row, options *domain.UpdateOptions) (int64, error) {
+	m.updates = append(m.updates, updates)
+	return 1, nil
+}
+
+// TestExecuteUpsert_Guard 测试 ON DUPLICATE KEY UPDATE 的 Where 限定可以更新的已有行，
+// 不满足时按主键冲突报错（数据源不支持 Upsert 时逐行处理的路径）
+func TestExecuteUpsert_Guard(t *testing.T) {
+	ds := &upsertMockDataSource{mockDataSource: newMockDataSource()}
+	ds.addTable("orders", []domain.ColumnInfo{
+		{Name: "id", Type: "int64", Primary: true},
+		{Name: "amount", Type: "int64"},
+		{Name: "tenant_id", Type: "text"},
+	}, []domain.Row{
+		{"id": int64(1), "amount": int64(10), "tenant_id": "acme"},
+		{"id": int64(2), "amount": int64(20), "tenant_id": "globex"},
+	})
+	builder := NewQueryBuilder(ds)
+	upsert := func(id int64) error {
+		_, err := builder.ExecuteStatement(context.Background(), &SQLStatement{
+			Type: SQLTypeInsert,
+			Insert: &InsertStatement{
+				Table:   "orders",
+				Columns: []string{"id", "amount", "tenant_id"},
+				Values:  [][]interface{}{{id, int64(5), "acme"}},
+				OnDuplicate: &UpdateStatement{
+					Table: "orders",
+					Set:   map[string]interface{}{"amount": int64(99)},
+					Where: &Expression{
+						Type:     ExprTypeOperator,
+						Operator: "eq",
+						Left:     &Expression{Type: ExprTypeColumn, Column: "tenant_id"},
+						Right:    &Expression{Type: ExprTypeValue, Value: "acme"},
+					},
+				},
+			},
+		})
+		return err
+	}
+
+	if err := upsert(1); err != nil {
+		t.Fatalf("upsert of a matching row failed: %v", err)
+	}
+	if len(ds.updates) != 1 || ds.updates[0]["amount"] != int64(99) {
+		t.Fatalf("expected the matching row to be updated, got %v", ds.updates)
+	}
+
+	err := upsert(2)
+	if err == nil || !strings.Contains(err.Error(), "Duplicate entry '2'") {
+		t.Fatalf("expected a duplicate entry error, got %v", err)
+	}
+	if len(ds.updates) != 1 {
+		t.Fatalf("the row of another tenant must not be updated, got %v", ds.updates)
+	}
+}
+
+// TestExecuteUpsert_ExistingRow 测试逐行处理时 ON DUPLICATE KEY UPDATE 的赋值在冲突的已有行上求值，
+// VALUES(col) 取待插入行的值
+func TestExecuteUpsert_ExistingRow(t *testing.T) {
+	ds := &upsertMockDataSource{mockDataSource: newMockDataSource()}
+	ds.addTable("counters", []domain.ColumnInfo{
+		{Name: "id", Type: "int64", Primary: true},
+		{Name: "hits", Type: "int64"},
+	}, []domain.Row{{"id": int64(1), "hits": int64(10)}})
+
+	adapter := NewSQLAdapter()
+	result, err := adapter.Parse("INSERT INTO counters (id, hits) VALUES (1, 5) ON DUPLICATE KEY UPDATE hits = hits + VALUES(hits)")
+	if err != nil || !result.Success {
+		t.Fatalf("parse failed: %v %s", err, result.Error)
+	}
+	if _, err := NewQueryBuilder(ds).ExecuteStatement(context.Background(), result.Statement); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if len(ds.updates) != 1 || ds.updates[0]["hits"] != int64(15) {
+		t.Fatalf("expected hits = 10 + 5, got %v", ds.updates)
+	}
+}
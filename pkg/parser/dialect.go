@@ -0,0 +1,752 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dialect 输入 SQL 的方言，非 MySQL 方言在解析前被改写为 MySQL 兼容的 SQL
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// Translation 方言改写的结果
+type Translation struct {
+	SQL string // MySQL 兼容的 SQL
+	// Returning 被剥离的 RETURNING 子句的各个表达式（已改写），没有 RETURNING 时为空；
+	// 由调用方在执行 INSERT/DELETE 后自行生成结果行
+	Returning []string
+}
+
+// DialectTranslator 把某种方言的 SQL 改写为 MySQL 兼容的 SQL
+type DialectTranslator interface {
+	Translate(sql string) (*Translation, error)
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[Dialect]DialectTranslator{
+		DialectPostgres: &tokenTranslator{
+			quotedIdents:    true,
+			positional:      true,
+			casts:           true,
+			onConflict:      true,
+			returning:       true,
+			limitNormalizer: true,
+		},
+		DialectSQLite: &tokenTranslator{
+			quotedIdents:    true,
+			bracketIdents:   true,
+			insertOr:        true,
+			autoincrement:   true,
+			onConflict:      true,
+			returning:       true,
+			limitNormalizer: true,
+		},
+	}
+	dialectAliases = map[string]Dialect{
+		"":           DialectMySQL,
+		"mysql":      DialectMySQL,
+		"mariadb":    DialectMySQL,
+		"tidb":       DialectMySQL,
+		"postgres":   DialectPostgres,
+		"postgresql": DialectPostgres,
+		"pg":         DialectPostgres,
+		"sqlite":     DialectSQLite,
+		"sqlite3":    DialectSQLite,
+	}
+)
+
+// RegisterDialect 注册（或替换）方言的改写器，名称不区分大小写；MySQL 方言不可替换
+func RegisterDialect(name Dialect, translator DialectTranslator) error {
+	name = Dialect(strings.ToLower(strings.TrimSpace(string(name))))
+	if name == "" || name == DialectMySQL || translator == nil {
+		return fmt.Errorf("invalid dialect registration '%s'", name)
+	}
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[name] = translator
+	return nil
+}
+
+// ParseDialect 解析方言名称（不区分大小写），空串为 mysql；支持 postgresql/pg、sqlite3 等别名
+func ParseDialect(s string) (Dialect, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if d, ok := dialectAliases[name]; ok {
+		return d, nil
+	}
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	if _, ok := dialects[Dialect(name)]; ok {
+		return Dialect(name), nil
+	}
+	return "", fmt.Errorf("unknown sql dialect '%s'", s)
+}
+
+// TranslateDialect 把 dialect 方言的 SQL 改写为 MySQL 兼容的 SQL；
+// mysql 方言只剥离解析器不支持的 RETURNING 子句
+func TranslateDialect(dialect Dialect, sql string) (*Translation, error) {
+	if dialect == "" || dialect == DialectMySQL {
+		stripped, returning := SplitReturning(sql)
+		return &Translation{SQL: stripped, Returning: returning}, nil
+	}
+	dialectsMu.RLock()
+	translator, ok := dialects[dialect]
+	dialectsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sql dialect '%s'", dialect)
+	}
+	return translator.Translate(sql)
+}
+
+// tokenTranslator 基于词法单元的改写器，按开关组合出 Postgres/SQLite 的改写规则：
+//   - "ident"（以及 SQLite 的 [ident]）改为 `ident`
+//   - $1, $2 ... 位置参数改为 ?（必须按顺序出现且不重复）
+//   - expr::type 改为 CAST(expr AS type)，类型名映射为 MySQL 的 CAST 类型
+//   - a || b 改为 CONCAT(a, b)
+//   - OFFSET y LIMIT x、单独的 OFFSET、LIMIT ALL、FETCH FIRST n ROWS ONLY 规整为 LIMIT x OFFSET y
+//   - INSERT OR REPLACE/IGNORE 改为 REPLACE / INSERT IGNORE，AUTOINCREMENT 改为 AUTO_INCREMENT
+//   - INSERT ... ON CONFLICT [...] DO NOTHING 改为 INSERT IGNORE，DO UPDATE SET 改为
+//     ON DUPLICATE KEY UPDATE（EXCLUDED.col 改为 VALUES(col)）
+//   - 剥离 INSERT/UPDATE/DELETE 末尾的 RETURNING 子句，放入 Translation.Returning
+//
+// ILIKE 与 LIMIT x OFFSET y 解析器原生支持，无需改写。
+// :: 与 || 的操作数只取相邻的基本表达式（标识符、字面量、函数调用或括号表达式），
+// 更复杂的操作数需要加括号
+type tokenTranslator struct {
+	quotedIdents    bool
+	bracketIdents   bool
+	positional      bool
+	casts           bool
+	insertOr        bool
+	autoincrement   bool
+	onConflict      bool
+	returning       bool
+	limitNormalizer bool
+}
+
+// Translate 实现 DialectTranslator
+func (t *tokenTranslator) Translate(sql string) (*Translation, error) {
+	toks := tokenizeDialect(sql, t.bracketIdents)
+
+	nextParam := 1
+	for i, tok := range toks {
+		switch {
+		case tok.kind == tokQuotedIdent && (t.quotedIdents || tok.text[0] == '['):
+			toks[i] = dialectToken{kind: tokBacktick, text: backtickQuote(unquoteIdent(tok.text))}
+		case tok.kind == tokParam && t.positional:
+			if tok.text != fmt.Sprintf("$%d", nextParam) {
+				return nil, fmt.Errorf("positional parameter %s out of order: parameters must appear as $1, $2, ... exactly once", tok.text)
+			}
+			nextParam++
+			toks[i] = dialectToken{kind: tokPunct, text: "?"}
+		case tok.kind == tokWord && t.autoincrement && strings.EqualFold(tok.text, "AUTOINCREMENT"):
+			toks[i] = dialectToken{kind: tokWord, text: "AUTO_INCREMENT"}
+		}
+	}
+
+	var err error
+	if t.casts {
+		if toks, err = rewriteCasts(toks); err != nil {
+			return nil, err
+		}
+	}
+	toks = rewriteConcat(toks)
+	if t.limitNormalizer {
+		toks = rewriteLimitClauses(toks)
+	}
+	if t.insertOr {
+		toks = rewriteInsertOr(toks)
+	}
+	if t.onConflict {
+		if toks, err = rewriteOnConflict(toks); err != nil {
+			return nil, err
+		}
+	}
+
+	translation := &Translation{}
+	if t.returning {
+		toks, translation.Returning = stripReturning(toks)
+	}
+	translation.SQL = renderTokens(toks)
+	return translation, nil
+}
+
+// castTypes Postgres/SQLite 类型名到 MySQL CAST 目标类型的映射
+var castTypes = map[string]string{
+	"text":              "CHAR",
+	"varchar":           "CHAR",
+	"char":              "CHAR",
+	"character":         "CHAR",
+	"character varying": "CHAR",
+	"bpchar":            "CHAR",
+	"name":              "CHAR",
+	"uuid":              "CHAR",
+	"int":               "SIGNED",
+	"integer":           "SIGNED",
+	"int2":              "SIGNED",
+	"int4":              "SIGNED",
+	"int8":              "SIGNED",
+	"smallint":          "SIGNED",
+	"bigint":            "SIGNED",
+	"bool":              "SIGNED",
+	"boolean":           "SIGNED",
+	"numeric":           "DECIMAL",
+	"decimal":           "DECIMAL",
+	"real":              "DOUBLE",
+	"float":             "DOUBLE",
+	"float4":            "DOUBLE",
+	"float8":            "DOUBLE",
+	"double precision":  "DOUBLE",
+	"date":              "DATE",
+	"time":              "TIME",
+	"timestamp":         "DATETIME",
+	"timestamptz":       "DATETIME",
+	"json":              "JSON",
+	"jsonb":             "JSON",
+	"bytea":             "BINARY",
+	"blob":              "BINARY",
+}
+
+// rewriteCasts 把 expr::type 改写为 CAST(expr AS type)
+func rewriteCasts(toks []dialectToken) ([]dialectToken, error) {
+	for i := 0; i < len(toks); i++ {
+		if toks[i].kind != tokPunct || toks[i].text != "::" {
+			continue
+		}
+		start := operandStart(toks, i)
+		if start < 0 {
+			return nil, fmt.Errorf("cannot determine the operand of '::' cast")
+		}
+
+		// 类型名：一个或两个单词（double precision、character varying），可带 (n[, m])
+		j := nextSignificant(toks, i+1)
+		if j < 0 || toks[j].kind != tokWord {
+			return nil, fmt.Errorf("expected a type name after '::'")
+		}
+		typeName := strings.ToLower(toks[j].text)
+		end := j
+		if k := nextSignificant(toks, j+1); k >= 0 && toks[k].kind == tokWord {
+			if _, ok := castTypes[typeName+" "+strings.ToLower(toks[k].text)]; ok {
+				typeName += " " + strings.ToLower(toks[k].text)
+				end = k
+			}
+		}
+		mapped, ok := castTypes[typeName]
+		if !ok {
+			return nil, fmt.Errorf("unsupported cast type '%s'", typeName)
+		}
+		typeToks := []dialectToken{{kind: tokWord, text: mapped}}
+		if k := nextSignificant(toks, end+1); k >= 0 && toks[k].text == "(" {
+			closing := matchingParen(toks, k)
+			if closing < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in cast type")
+			}
+			// 只有 CHAR 与 DECIMAL 接受长度/精度参数
+			if mapped == "CHAR" || mapped == "DECIMAL" {
+				typeToks = append(typeToks, toks[k:closing+1]...)
+			}
+			end = closing
+		}
+
+		replacement := []dialectToken{{kind: tokWord, text: "CAST"}, {kind: tokPunct, text: "("}}
+		replacement = append(replacement, toks[start:i]...)
+		replacement = append(replacement, dialectToken{kind: tokSpace, text: " "}, dialectToken{kind: tokWord, text: "AS"}, dialectToken{kind: tokSpace, text: " "})
+		replacement = append(replacement, typeToks...)
+		replacement = append(replacement, dialectToken{kind: tokPunct, text: ")"})
+		toks = spliceTokens(toks, start, end+1, replacement)
+		i = start + len(replacement) - 1
+	}
+	return toks, nil
+}
+
+// rewriteConcat 把 a || b 改写为 CONCAT(a, b)；连续的 || 逐个嵌套
+func rewriteConcat(toks []dialectToken) []dialectToken {
+	for i := 0; i < len(toks); i++ {
+		if toks[i].kind != tokPunct || toks[i].text != "||" {
+			continue
+		}
+		start := operandStart(toks, i)
+		end := operandEnd(toks, i+1)
+		if start < 0 || end < 0 {
+			continue
+		}
+		left := trimSpaceTokens(toks[start:i])
+		right := trimSpaceTokens(toks[i+1 : end+1])
+		replacement := []dialectToken{{kind: tokWord, text: "CONCAT"}, {kind: tokPunct, text: "("}}
+		replacement = append(replacement, left...)
+		replacement = append(replacement, dialectToken{kind: tokPunct, text: ","}, dialectToken{kind: tokSpace, text: " "})
+		replacement = append(replacement, right...)
+		replacement = append(replacement, dialectToken{kind: tokPunct, text: ")"})
+		toks = spliceTokens(toks, start, end+1, replacement)
+		i = start
+	}
+	return toks
+}
+
+// maxLimit 只有 OFFSET 时补上的 LIMIT（MySQL 要求 OFFSET 前必须有 LIMIT）
+const maxLimit = "18446744073709551615"
+
+// rewriteLimitClauses 把同一层级连续出现的 LIMIT/OFFSET/FETCH 子句规整为 LIMIT x [OFFSET y]。
+// 无法识别的形式（如 LIMIT a, b）保持原样，交给解析器处理
+func rewriteLimitClauses(toks []dialectToken) []dialectToken {
+	for i := 0; i < len(toks); i++ {
+		if toks[i].kind != tokWord {
+			continue
+		}
+		switch strings.ToUpper(toks[i].text) {
+		case "LIMIT", "OFFSET", "FETCH":
+		default:
+			continue
+		}
+
+		limit, offset, end, ok := parseLimitRun(toks, i)
+		if !ok {
+			continue
+		}
+		var replacement []dialectToken
+		if limit != "" || offset != "" {
+			if limit == "" {
+				limit = maxLimit
+			}
+			replacement = append(replacement, dialectToken{kind: tokWord, text: "LIMIT"},
+				dialectToken{kind: tokSpace, text: " "}, dialectToken{kind: tokWord, text: limit})
+			if offset != "" {
+				replacement = append(replacement, dialectToken{kind: tokSpace, text: " "}, dialectToken{kind: tokWord, text: "OFFSET"},
+					dialectToken{kind: tokSpace, text: " "}, dialectToken{kind: tokWord, text: offset})
+			}
+		}
+		toks = spliceTokens(toks, i, end, replacement)
+		i += len(replacement)
+	}
+	return toks
+}
+
+// parseLimitRun 解析从 i 开始的 LIMIT/OFFSET/FETCH 子句序列，返回限制值、偏移值与序列之后的位置
+func parseLimitRun(toks []dialectToken, i int) (limit, offset string, end int, ok bool) {
+	end = i
+	for {
+		j := nextSignificant(toks, end)
+		if j < 0 || toks[j].kind != tokWord {
+			return limit, offset, end, end > i
+		}
+		switch strings.ToUpper(toks[j].text) {
+		case "LIMIT":
+			k := nextSignificant(toks, j+1)
+			if k < 0 {
+				return "", "", 0, false
+			}
+			if toks[k].kind == tokWord && strings.EqualFold(toks[k].text, "ALL") {
+				limit = ""
+			} else if isLimitValue(toks[k]) {
+				limit = toks[k].text
+			} else {
+				return "", "", 0, false
+			}
+			// LIMIT a, b 是 MySQL 原生写法
+			if n := nextSignificant(toks, k+1); n >= 0 && toks[n].text == "," {
+				return "", "", 0, false
+			}
+			end = k + 1
+		case "OFFSET":
+			k := nextSignificant(toks, j+1)
+			if k < 0 || !isLimitValue(toks[k]) {
+				return "", "", 0, false
+			}
+			offset = toks[k].text
+			end = k + 1
+			if n := nextSignificant(toks, end); n >= 0 && isWord(toks[n], "ROW", "ROWS") {
+				end = n + 1
+			}
+		case "FETCH":
+			k := nextSignificant(toks, j+1)
+			if k < 0 || !isWord(toks[k], "FIRST", "NEXT") {
+				return "", "", 0, false
+			}
+			count := "1"
+			k = nextSignificant(toks, k+1)
+			if k >= 0 && isLimitValue(toks[k]) {
+				count = toks[k].text
+				k = nextSignificant(toks, k+1)
+			}
+			if k < 0 || !isWord(toks[k], "ROW", "ROWS") {
+				return "", "", 0, false
+			}
+			k = nextSignificant(toks, k+1)
+			if k < 0 || !isWord(toks[k], "ONLY") {
+				return "", "", 0, false
+			}
+			limit = count
+			end = k + 1
+		default:
+			return limit, offset, end, end > i
+		}
+	}
+}
+
+// isLimitValue LIMIT/OFFSET 的值只能是整数或占位符
+func isLimitValue(tok dialectToken) bool {
+	return tok.kind == tokNumber || (tok.kind == tokPunct && tok.text == "?")
+}
+
+// rewriteInsertOr 把 SQLite 的 INSERT OR <冲突处理> 改写为 MySQL 写法
+func rewriteInsertOr(toks []dialectToken) []dialectToken {
+	i := nextSignificant(toks, 0)
+	if i < 0 || !isWord(toks[i], "INSERT") {
+		return toks
+	}
+	j := nextSignificant(toks, i+1)
+	if j < 0 || !isWord(toks[j], "OR") {
+		return toks
+	}
+	k := nextSignificant(toks, j+1)
+	if k < 0 || toks[k].kind != tokWord {
+		return toks
+	}
+	var replacement []dialectToken
+	switch strings.ToUpper(toks[k].text) {
+	case "REPLACE":
+		replacement = []dialectToken{{kind: tokWord, text: "REPLACE"}}
+	case "IGNORE":
+		replacement = []dialectToken{{kind: tokWord, text: "INSERT"}, {kind: tokSpace, text: " "}, {kind: tokWord, text: "IGNORE"}}
+	case "ABORT", "FAIL", "ROLLBACK":
+		replacement = []dialectToken{{kind: tokWord, text: "INSERT"}}
+	default:
+		return toks
+	}
+	return spliceTokens(toks, i, k+1, replacement)
+}
+
+// rewriteOnConflict 把 INSERT ... ON CONFLICT [(cols)] DO NOTHING 改写为 INSERT IGNORE，
+// DO UPDATE SET 改写为 ON DUPLICATE KEY UPDATE。冲突目标列被忽略：与任一主键或唯一键冲突都会触发
+func rewriteOnConflict(toks []dialectToken) ([]dialectToken, error) {
+	first := nextSignificant(toks, 0)
+	if first < 0 || !isWord(toks[first], "INSERT") {
+		return toks, nil
+	}
+	depth := 0
+	for i := first; i < len(toks); i++ {
+		switch {
+		case toks[i].text == "(":
+			depth++
+		case toks[i].text == ")":
+			depth--
+		case depth == 0 && isWord(toks[i], "ON"):
+			j := nextSignificant(toks, i+1)
+			if j < 0 || !isWord(toks[j], "CONFLICT") {
+				continue
+			}
+			k := nextSignificant(toks, j+1)
+			if k >= 0 && toks[k].text == "(" {
+				if k = matchingParen(toks, k); k < 0 {
+					return nil, fmt.Errorf("unbalanced parentheses in ON CONFLICT")
+				}
+				k = nextSignificant(toks, k+1)
+			}
+			if k < 0 || !isWord(toks[k], "DO") {
+				return nil, fmt.Errorf("unsupported ON CONFLICT clause")
+			}
+			action := nextSignificant(toks, k+1)
+			if action >= 0 && isWord(toks[action], "UPDATE") {
+				return rewriteConflictUpdate(toks, i, action)
+			}
+			if action < 0 || !isWord(toks[action], "NOTHING") {
+				return nil, fmt.Errorf("unsupported ON CONFLICT clause")
+			}
+			if i > 0 && toks[i-1].kind == tokSpace {
+				i--
+			}
+			toks = spliceTokens(toks, i, action+1, nil)
+			ignore := []dialectToken{{kind: tokSpace, text: " "}, {kind: tokWord, text: "IGNORE"}}
+			return spliceTokens(toks, first+1, first+1, ignore), nil
+		}
+	}
+	return toks, nil
+}
+
+// rewriteConflictUpdate 把 ON CONFLICT [...] DO UPDATE SET a = EXCLUDED.a 改写为
+// ON DUPLICATE KEY UPDATE a = VALUES(a)；带 WHERE 条件的 DO UPDATE 无法等价改写，返回错误
+func rewriteConflictUpdate(toks []dialectToken, on, update int) ([]dialectToken, error) {
+	set := nextSignificant(toks, update+1)
+	if set < 0 || !isWord(toks[set], "SET") {
+		return nil, fmt.Errorf("unsupported ON CONFLICT clause")
+	}
+	depth := 0
+	for i := set + 1; i < len(toks); i++ {
+		switch {
+		case toks[i].text == "(":
+			depth++
+		case toks[i].text == ")":
+			depth--
+		case depth == 0 && isWord(toks[i], "WHERE"):
+			return nil, fmt.Errorf("ON CONFLICT ... DO UPDATE ... WHERE is not supported")
+		case depth == 0 && isWord(toks[i], "RETURNING"):
+			i = len(toks)
+		case isWord(toks[i], "EXCLUDED"):
+			dot := nextSignificant(toks, i+1)
+			if dot < 0 || toks[dot].text != "." {
+				continue
+			}
+			col := nextSignificant(toks, dot+1)
+			if col < 0 || (toks[col].kind != tokWord && toks[col].kind != tokBacktick && toks[col].kind != tokQuotedIdent) {
+				return nil, fmt.Errorf("invalid EXCLUDED column reference")
+			}
+			values := []dialectToken{{kind: tokWord, text: "VALUES"}, {kind: tokPunct, text: "("}, toks[col], {kind: tokPunct, text: ")"}}
+			toks = spliceTokens(toks, i, col+1, values)
+			i += len(values) - 1
+		}
+	}
+	clause := []dialectToken{
+		{kind: tokWord, text: "ON"}, {kind: tokSpace, text: " "},
+		{kind: tokWord, text: "DUPLICATE"}, {kind: tokSpace, text: " "},
+		{kind: tokWord, text: "KEY"}, {kind: tokSpace, text: " "},
+		{kind: tokWord, text: "UPDATE"},
+	}
+	return spliceTokens(toks, on, set+1, clause), nil
+}
+
+// stripReturning 剥离 INSERT/UPDATE/DELETE 最外层的 RETURNING 子句，返回其中的各个表达式
+func stripReturning(toks []dialectToken) ([]dialectToken, []string) {
+	first := nextSignificant(toks, 0)
+	if first < 0 || !isWord(toks[first], "INSERT", "UPDATE", "DELETE", "REPLACE") {
+		return toks, nil
+	}
+	depth := 0
+	for i := first; i < len(toks); i++ {
+		switch {
+		case toks[i].text == "(":
+			depth++
+		case toks[i].text == ")":
+			depth--
+		case depth == 0 && isWord(toks[i], "RETURNING"):
+			var items []string
+			var current []dialectToken
+			itemDepth := 0
+			flush := func() {
+				if item := strings.TrimSpace(renderTokens(current)); item != "" {
+					items = append(items, item)
+				}
+				current = nil
+			}
+			for _, tok := range toks[i+1:] {
+				switch {
+				case tok.text == "(":
+					itemDepth++
+				case tok.text == ")":
+					itemDepth--
+				case itemDepth == 0 && tok.text == ",":
+					flush()
+					continue
+				case itemDepth == 0 && tok.text == ";":
+					continue
+				}
+				current = append(current, tok)
+			}
+			flush()
+			return trimSpaceTokens(toks[:i]), items
+		}
+	}
+	return toks, nil
+}
+
+// operandStart 返回运算符 op（位于 i）左侧基本表达式的起始位置，无法确定时返回 -1
+func operandStart(toks []dialectToken, i int) int {
+	j := prevSignificant(toks, i-1)
+	if j < 0 {
+		return -1
+	}
+	switch tok := toks[j]; {
+	case tok.text == ")":
+		open := matchingOpenParen(toks, j)
+		if open < 0 {
+			return -1
+		}
+		j = open
+		// 函数调用：括号前紧挨着函数名
+		if k := prevSignificant(toks, open-1); k >= 0 && k == open-1 && toks[k].kind == tokWord && !isWord(toks[k], operandKeywords...) {
+			j = k
+		} else {
+			return j
+		}
+	case isOperandToken(tok):
+	default:
+		return -1
+	}
+	// 限定名 a.b.c
+	for {
+		dot := prevSignificant(toks, j-1)
+		if dot < 0 || toks[dot].text != "." {
+			return j
+		}
+		k := prevSignificant(toks, dot-1)
+		if k < 0 || (toks[k].kind != tokWord && toks[k].kind != tokBacktick) {
+			return j
+		}
+		j = k
+	}
+}
+
+// operandEnd 返回从 i 开始的基本表达式的结束位置（含），无法确定时返回 -1
+func operandEnd(toks []dialectToken, i int) int {
+	j := nextSignificant(toks, i)
+	if j < 0 {
+		return -1
+	}
+	if toks[j].text == "-" || toks[j].text == "+" {
+		if k := nextSignificant(toks, j+1); k >= 0 && toks[k].kind == tokNumber {
+			return k
+		}
+		return -1
+	}
+	if toks[j].text == "(" {
+		return matchingParen(toks, j)
+	}
+	if !isOperandToken(toks[j]) {
+		return -1
+	}
+	for {
+		next := nextSignificant(toks, j+1)
+		switch {
+		case next >= 0 && next == j+1 && toks[next].text == "(" && toks[j].kind == tokWord:
+			return matchingParen(toks, next)
+		case next >= 0 && toks[next].text == ".":
+			k := nextSignificant(toks, next+1)
+			if k < 0 || (toks[k].kind != tokWord && toks[k].kind != tokBacktick && toks[k].text != "*") {
+				return j
+			}
+			j = k
+		default:
+			return j
+		}
+	}
+}
+
+// operandKeywords 可以出现在左括号之前但不是函数名的关键字
+var operandKeywords = []string{
+	"SELECT", "WHERE", "AND", "OR", "NOT", "IN", "ON", "AS", "WHEN", "THEN", "ELSE",
+	"BY", "VALUES", "FROM", "SET", "HAVING", "LIKE", "ILIKE", "IS", "EXISTS", "RETURNING",
+}
+
+func isOperandToken(tok dialectToken) bool {
+	switch tok.kind {
+	case tokWord, tokBacktick, tokString, tokNumber:
+		return true
+	}
+	return tok.kind == tokPunct && tok.text == "?"
+}
+
+// isWord 判断是否为给定关键字之一（不区分大小写）
+func isWord(tok dialectToken, words ...string) bool {
+	if tok.kind != tokWord {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(tok.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func nextSignificant(toks []dialectToken, i int) int {
+	for ; i < len(toks); i++ {
+		if toks[i].kind != tokSpace && toks[i].kind != tokComment {
+			return i
+		}
+	}
+	return -1
+}
+
+func prevSignificant(toks []dialectToken, i int) int {
+	for ; i >= 0; i-- {
+		if toks[i].kind != tokSpace && toks[i].kind != tokComment {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchingParen 返回与 i 处左括号匹配的右括号位置
+func matchingParen(toks []dialectToken, i int) int {
+	depth := 0
+	for ; i < len(toks); i++ {
+		if toks[i].kind != tokPunct {
+			continue
+		}
+		switch toks[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// matchingOpenParen 返回与 i 处右括号匹配的左括号位置
+func matchingOpenParen(toks []dialectToken, i int) int {
+	depth := 0
+	for ; i >= 0; i-- {
+		if toks[i].kind != tokPunct {
+			continue
+		}
+		switch toks[i].text {
+		case ")":
+			depth++
+		case "(":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// spliceTokens 用 replacement 替换 toks[start:end]
+func spliceTokens(toks []dialectToken, start, end int, replacement []dialectToken) []dialectToken {
+	out := make([]dialectToken, 0, len(toks)-(end-start)+len(replacement))
+	out = append(out, toks[:start]...)
+	out = append(out, replacement...)
+	return append(out, toks[end:]...)
+}
+
+func trimSpaceTokens(toks []dialectToken) []dialectToken {
+	for len(toks) > 0 && toks[0].kind == tokSpace {
+		toks = toks[1:]
+	}
+	for len(toks) > 0 && toks[len(toks)-1].kind == tokSpace {
+		toks = toks[:len(toks)-1]
+	}
+	return toks
+}
+
+func renderTokens(toks []dialectToken) string {
+	var sb strings.Builder
+	for _, tok := range toks {
+		sb.WriteString(tok.text)
+	}
+	return sb.String()
+}
+
+// unquoteIdent 去掉 "ident" 或 [ident] 的引号，"" 还原为 "
+func unquoteIdent(text string) string {
+	if text[0] == '[' {
+		return strings.TrimSuffix(text[1:], "]")
+	}
+	inner := strings.TrimSuffix(text[1:], `"`)
+	return strings.ReplaceAll(inner, `""`, `"`)
+}
+
+// backtickQuote 用反引号引用标识符
+func backtickQuote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateDialect_Postgres(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"quoted identifiers", `SELECT "Name", "a""b" FROM "users" u WHERE u."id" = 1`, "SELECT `Name`, `a\"b` FROM `users` u WHERE u.`id` = 1"},
+		{"strings untouched", `SELECT 'say "hi"', "x" FROM t`, "SELECT 'say \"hi\"', `x` FROM t"},
+		{"positional params", "SELECT * FROM t WHERE a = $1 AND b = $2", "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{"cast", "SELECT id::text, price::numeric(10,2), f(x)::int, t.n::double precision FROM t", "SELECT CAST(id AS CHAR), CAST(price AS DECIMAL(10,2)), CAST(f(x) AS SIGNED), CAST(t.n AS DOUBLE) FROM t"},
+		{"chained cast", "SELECT '1'::text::int", "SELECT CAST(CAST('1' AS CHAR) AS SIGNED)"},
+		{"concat", "SELECT first || ' ' || last FROM t", "SELECT CONCAT(CONCAT(first, ' '), last) FROM t"},
+		{"concat with calls", "SELECT upper(a) || (b) FROM t", "SELECT CONCAT(upper(a), (b)) FROM t"},
+		{"ilike is native", "SELECT * FROM t WHERE name ILIKE 'a%'", "SELECT * FROM t WHERE name ILIKE 'a%'"},
+		{"limit offset is native", "SELECT * FROM t LIMIT 10 OFFSET 5", "SELECT * FROM t LIMIT 10 OFFSET 5"},
+		{"offset before limit", "SELECT * FROM t OFFSET 5 LIMIT 10", "SELECT * FROM t LIMIT 10 OFFSET 5"},
+		{"offset only", "SELECT * FROM t OFFSET 5 ROWS", "SELECT * FROM t LIMIT " + maxLimit + " OFFSET 5"},
+		{"limit all", "SELECT * FROM t LIMIT ALL", "SELECT * FROM t "},
+		{"fetch first", "SELECT * FROM t ORDER BY id OFFSET 2 ROWS FETCH FIRST 3 ROWS ONLY", "SELECT * FROM t ORDER BY id LIMIT 3 OFFSET 2"},
+		{"fetch in subquery", "SELECT * FROM (SELECT * FROM t FETCH NEXT ROW ONLY) s", "SELECT * FROM (SELECT * FROM t LIMIT 1) s"},
+		{"mysql limit untouched", "SELECT * FROM t LIMIT 5, 10", "SELECT * FROM t LIMIT 5, 10"},
+		{"offset column untouched", "SELECT offset FROM t", "SELECT offset FROM t"},
+		{"on conflict do nothing", "INSERT INTO t (id) VALUES (1) ON CONFLICT (id) DO NOTHING", "INSERT IGNORE INTO t (id) VALUES (1)"},
+		{"on conflict do update", `INSERT INTO t (id, v) VALUES (1, 2) ON CONFLICT (id) DO UPDATE SET v = excluded."v", w = 3`, "INSERT INTO t (id, v) VALUES (1, 2) ON DUPLICATE KEY UPDATE v = VALUES(`v`), w = 3"},
+		{"comments kept", "SELECT \"a\" -- \"comment\"\nFROM t", "SELECT `a` -- \"comment\"\nFROM t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := TranslateDialect(DialectPostgres, tt.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tr.SQL)
+			assert.Empty(t, tr.Returning)
+		})
+	}
+}
+
+func TestTranslateDialect_SQLite(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"bracket identifiers", "SELECT [first name] FROM [t]", "SELECT `first name` FROM `t`"},
+		{"insert or replace", "INSERT OR REPLACE INTO t VALUES (1)", "REPLACE INTO t VALUES (1)"},
+		{"insert or ignore", "insert or ignore into t values (1)", "INSERT IGNORE into t values (1)"},
+		{"autoincrement", "CREATE TABLE t (id INTEGER PRIMARY KEY AUTOINCREMENT)", "CREATE TABLE t (id INTEGER PRIMARY KEY AUTO_INCREMENT)"},
+		{"concat", "SELECT a || b FROM t", "SELECT CONCAT(a, b) FROM t"},
+		{"dollar is not positional", "SELECT $1", "SELECT $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := TranslateDialect(DialectSQLite, tt.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tr.SQL)
+		})
+	}
+}
+
+func TestTranslateDialect_Returning(t *testing.T) {
+	tr, err := TranslateDialect(DialectPostgres, `INSERT INTO t ("id", name) VALUES (1, 'a') RETURNING "id", upper(name) AS n;`)
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t (`id`, name) VALUES (1, 'a')", tr.SQL)
+	assert.Equal(t, []string{"`id`", "upper(name) AS n"}, tr.Returning)
+
+	// 子查询中的 RETURNING 字样与 SELECT 语句不处理
+	tr, err = TranslateDialect(DialectPostgres, "SELECT returning FROM t")
+	require.NoError(t, err)
+	assert.Empty(t, tr.Returning)
+}
+
+func TestTranslateDialect_Errors(t *testing.T) {
+	_, err := TranslateDialect(DialectPostgres, "SELECT $2, $1")
+	assert.Error(t, err)
+
+	_, err = TranslateDialect(DialectPostgres, "SELECT x::interval FROM t")
+	assert.Error(t, err)
+
+	_, err = TranslateDialect(DialectPostgres, "INSERT INTO t VALUES (1) ON CONFLICT (id) DO UPDATE SET v = 2 WHERE t.v < 2")
+	assert.Error(t, err)
+
+	_, err = TranslateDialect(Dialect("oracle"), "SELECT 1")
+	assert.Error(t, err)
+}
+
+func TestParseDialect(t *testing.T) {
+	for input, want := range map[string]Dialect{
+		"":           DialectMySQL,
+		"MySQL":      DialectMySQL,
+		"postgresql": DialectPostgres,
+		" PG ":       DialectPostgres,
+		"sqlite3":    DialectSQLite,
+	} {
+		got, err := ParseDialect(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	_, err := ParseDialect("oracle")
+	assert.Error(t, err)
+}
+
+type upperTranslator struct{}
+
+func (upperTranslator) Translate(sql string) (*Translation, error) {
+	return &Translation{SQL: strings.ToUpper(sql)}, nil
+}
+
+func TestRegisterDialect(t *testing.T) {
+	require.NoError(t, RegisterDialect("Shouty", upperTranslator{}))
+	d, err := ParseDialect("shouty")
+	require.NoError(t, err)
+	tr, err := TranslateDialect(d, "select 1")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1", tr.SQL)
+
+	assert.Error(t, RegisterDialect(DialectMySQL, upperTranslator{}))
+	assert.Error(t, RegisterDialect("x", nil))
+
+	// 改写后的 SQL 可被解析器接受
+	tr, err = TranslateDialect(DialectPostgres, `SELECT "id"::text || 'x' FROM "t" OFFSET 1 LIMIT 2`)
+	require.NoError(t, err)
+	result, err := NewSQLAdapter().Parse(tr.SQL)
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Error)
+}
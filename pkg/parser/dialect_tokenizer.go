@@ -0,0 +1,125 @@
+package parser
+
+import "strings"
+
+// dialectTokenKind 方言改写使用的词法单元类型
+type dialectTokenKind int
+
+const (
+	tokSpace       dialectTokenKind = iota // 空白
+	tokComment                             // -- 或 /* */ 注释
+	tokWord                                // 关键字或未加引号的标识符
+	tokNumber                              // 数字字面量
+	tokString                              // '...' 字符串字面量
+	tokQuotedIdent                         // "ident" 或 [ident]
+	tokBacktick                            // `ident`
+	tokParam                               // $1 位置参数
+	tokPunct                               // 运算符与标点
+)
+
+// dialectToken 词法单元，text 保留原始文本，拼接所有 text 即还原原 SQL
+type dialectToken struct {
+	kind dialectTokenKind
+	text string
+}
+
+// twoCharOperators 需要作为整体识别的双字符运算符
+var twoCharOperators = []string{"::", "||", "<=", ">=", "<>", "!=", ":="}
+
+// tokenizeDialect 把 SQL 切分为词法单元；bracketIdents 为 true 时 [ident] 作为带引号的标识符（SQLite）
+func tokenizeDialect(sql string, bracketIdents bool) []dialectToken {
+	var toks []dialectToken
+	emit := func(kind dialectTokenKind, start, end int) {
+		toks = append(toks, dialectToken{kind: kind, text: sql[start:end]})
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for i < len(sql) && (sql[i] == ' ' || sql[i] == '\t' || sql[i] == '\n' || sql[i] == '\r') {
+				i++
+			}
+			emit(tokSpace, start, i)
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			emit(tokComment, start, i)
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			if end := strings.Index(sql[i+2:], "*/"); end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+			emit(tokComment, start, i)
+		case c == '\'':
+			i = skipQuoted(sql, i) + 1
+			emit(tokString, start, min(i, len(sql)))
+		case c == '"':
+			i = skipDoubledQuote(sql, i, '"') + 1
+			emit(tokQuotedIdent, start, min(i, len(sql)))
+		case c == '`':
+			i = skipDoubledQuote(sql, i, '`') + 1
+			emit(tokBacktick, start, min(i, len(sql)))
+		case c == '[' && bracketIdents:
+			if end := strings.IndexByte(sql[i:], ']'); end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 1
+			}
+			emit(tokQuotedIdent, start, i)
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			for i++; i < len(sql) && isDigit(sql[i]); i++ {
+			}
+			emit(tokParam, start, i)
+		case isDigit(c) || (c == '.' && i+1 < len(sql) && isDigit(sql[i+1])):
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.' || isIdentByte(sql[i])) {
+				// 指数部分的符号：1e-5
+				if (sql[i] == 'e' || sql[i] == 'E') && i+1 < len(sql) && (sql[i+1] == '-' || sql[i+1] == '+') {
+					i++
+				}
+				i++
+			}
+			emit(tokNumber, start, i)
+		case isIdentByte(c) || c >= 0x80:
+			for i < len(sql) && (isIdentByte(sql[i]) || isDigit(sql[i]) || sql[i] == '$' || sql[i] >= 0x80) {
+				i++
+			}
+			emit(tokWord, start, i)
+		default:
+			i++
+			for _, op := range twoCharOperators {
+				if strings.HasPrefix(sql[start:], op) {
+					i = start + len(op)
+					break
+				}
+			}
+			emit(tokPunct, start, i)
+		}
+	}
+	return toks
+}
+
+// skipDoubledQuote 返回从 i 开始、以 quote 引用的标识符的结束引号位置，连续两个引号表示转义
+func skipDoubledQuote(sql string, i int, quote byte) int {
+	for i++; i < len(sql); i++ {
+		if sql[i] == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"github.com/pingcap/tidb/pkg/parser"
+)
+
+// NormalizeSQL 规范化 SQL 并计算语句摘要
+// 字面量替换为 ?，IN 列表和多行 VALUES 折叠为 ( ... )，关键字转为小写，标识符加反引号。
+// 参数值不同但结构相同的语句得到相同的摘要，例如：
+//
+//	SELECT * FROM users WHERE id = 42  -> select * from `users` where `id` = ?
+//
+// 只做词法处理，不要求 SQL 语法正确；可并发调用
+func NormalizeSQL(sql string) (normalized string, digest string) {
+	normalized, d := parser.NormalizeDigest(sql)
+	return normalized, d.String()
+}
+
+// SQLDigest 返回 SQL 的语句摘要（64 位十六进制字符串）
+func SQLDigest(sql string) string {
+	_, digest := NormalizeSQL(sql)
+	return digest
+}
@@ -0,0 +1,24 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	n1, d1 := NormalizeSQL("SELECT * FROM users WHERE id = 42 AND name = 'bob'")
+	n2, d2 := NormalizeSQL("select *  from users where id=7 and name='alice'")
+	assert.Equal(t, "select * from `users` where `id` = ? and `name` = ?", n1)
+	assert.Equal(t, n1, n2)
+	assert.Equal(t, d1, d2)
+	assert.Len(t, d1, 64)
+
+	// 参数占位符与字面量得到相同摘要
+	assert.Equal(t, d1, SQLDigest("SELECT * FROM users WHERE id = ? AND name = ?"))
+
+	// IN 列表长度不影响摘要
+	assert.Equal(t, SQLDigest("SELECT * FROM t WHERE id IN (1, 2)"), SQLDigest("SELECT * FROM t WHERE id IN (1, 2, 3, 4)"))
+
+	assert.NotEqual(t, d1, SQLDigest("SELECT * FROM orders WHERE id = 1"))
+}
@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// EncryptionInfo 列的加密设置（ENCRYPTED 列属性）
+type EncryptionInfo struct {
+	Mode  string `json:"mode"`             // randomized、deterministic 或 blind_index
+	KeyID string `json:"key_id,omitempty"` // KEY 'id'，为空时使用 default
+}
+
+// columnConstraintWords 表定义中以这些关键字开头的项不是列定义
+var columnConstraintWords = map[string]bool{
+	"PRIMARY": true, "KEY": true, "INDEX": true, "UNIQUE": true, "CONSTRAINT": true,
+	"FOREIGN": true, "CHECK": true, "FULLTEXT": true, "SPATIAL": true, "VECTOR": true,
+}
+
+// extractEncryptedColumns 从 CREATE TABLE 中去除 TiDB 不支持的 ENCRYPTED 列属性并返回各列的加密设置：
+//
+//	col type ENCRYPTED [RANDOMIZED | DETERMINISTIC | WITH BLIND INDEX] [KEY 'id']
+//
+// 例如：CREATE TABLE t (email VARCHAR(100) ENCRYPTED WITH BLIND INDEX KEY 'pii')
+func extractEncryptedColumns(sql string) (string, map[string]*EncryptionInfo, error) {
+	if !strings.Contains(strings.ToUpper(sql), "ENCRYPTED") {
+		return sql, nil, nil
+	}
+	toks := tokenizeDialect(sql, false)
+
+	// 只处理 CREATE [TEMPORARY] TABLE
+	words := 0
+	isCreateTable := false
+	for _, t := range toks {
+		if t.kind != tokWord {
+			if t.kind == tokPunct {
+				break
+			}
+			continue
+		}
+		w := strings.ToUpper(t.text)
+		if words == 0 && w != "CREATE" {
+			return sql, nil, nil
+		}
+		words++
+		if w == "TABLE" {
+			isCreateTable = true
+			break
+		}
+	}
+	if !isCreateTable {
+		return sql, nil, nil
+	}
+
+	// next 返回 i 之后第一个非空白、非注释的词法单元位置
+	next := func(i int) int {
+		for i++; i < len(toks); i++ {
+			if toks[i].kind != tokSpace && toks[i].kind != tokComment {
+				return i
+			}
+		}
+		return len(toks)
+	}
+	isWord := func(i int, word string) bool {
+		return i < len(toks) && toks[i].kind == tokWord && strings.EqualFold(toks[i].text, word)
+	}
+
+	columns := make(map[string]*EncryptionInfo)
+	depth := 0
+	column := ""      // 当前列定义的列名
+	startDef := false // 下一个词法单元是否为新定义的开头
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.kind == tokSpace || t.kind == tokComment {
+			continue
+		}
+		if t.kind == tokPunct {
+			switch t.text {
+			case "(":
+				depth++
+				if depth == 1 {
+					startDef = true
+				}
+			case ")":
+				depth--
+			case ",":
+				if depth == 1 {
+					startDef = true
+				}
+			}
+			continue
+		}
+		if depth != 1 {
+			continue
+		}
+		if startDef {
+			startDef = false
+			column = ""
+			switch t.kind {
+			case tokBacktick:
+				column = strings.ReplaceAll(t.text[1:len(t.text)-1], "``", "`")
+			case tokWord:
+				if !columnConstraintWords[strings.ToUpper(t.text)] {
+					column = t.text
+				}
+			}
+			continue
+		}
+		if column == "" || !isWord(i, "ENCRYPTED") {
+			continue
+		}
+
+		info := &EncryptionInfo{Mode: domain.EncryptionRandomized}
+		end := i
+		j := next(i)
+		switch {
+		case isWord(j, "RANDOMIZED"):
+			end, j = j, next(j)
+		case isWord(j, "DETERMINISTIC"):
+			info.Mode = domain.EncryptionDeterministic
+			end, j = j, next(j)
+		case isWord(j, "WITH"):
+			k := next(j)
+			l := next(k)
+			if !isWord(k, "BLIND") || !isWord(l, "INDEX") {
+				return "", nil, fmt.Errorf("expected WITH BLIND INDEX after ENCRYPTED on column '%s'", column)
+			}
+			info.Mode = domain.EncryptionBlindIndex
+			end, j = l, next(l)
+		}
+		if isWord(j, "KEY") {
+			if k := next(j); k < len(toks) && toks[k].kind == tokString {
+				info.KeyID = strings.ReplaceAll(toks[k].text[1:len(toks[k].text)-1], "''", "'")
+				end = k
+			}
+		}
+		if _, dup := columns[strings.ToLower(column)]; dup {
+			return "", nil, fmt.Errorf("duplicate ENCRYPTED attribute on column '%s'", column)
+		}
+		columns[strings.ToLower(column)] = info
+		for k := i; k <= end; k++ {
+			toks[k].text = ""
+		}
+		i = end
+	}
+	if len(columns) == 0 {
+		return sql, nil, nil
+	}
+
+	var sb strings.Builder
+	for _, t := range toks {
+		sb.WriteString(t.text)
+	}
+	return sb.String(), columns, nil
+}
+
+// applyEncryptedColumns 把 extractEncryptedColumns 得到的加密设置写入 CREATE TABLE 的列
+func applyEncryptedColumns(stmt *SQLStatement, columns map[string]*EncryptionInfo) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	if stmt.Create == nil {
+		return fmt.Errorf("ENCRYPTED is only supported in CREATE TABLE")
+	}
+	found := 0
+	for i := range stmt.Create.Columns {
+		col := &stmt.Create.Columns[i]
+		if info, ok := columns[strings.ToLower(col.Name)]; ok {
+			encryption := *info
+			col.Encryption = &encryption
+			found++
+		}
+	}
+	if found != len(columns) {
+		return fmt.Errorf("ENCRYPTED refers to an unknown column")
+	}
+	return nil
+}
+
+// ToDomain 转换为存储层的列加密设置
+func (e *EncryptionInfo) ToDomain() *domain.ColumnEncryption {
+	if e == nil {
+		return nil
+	}
+	return &domain.ColumnEncryption{Mode: e.Mode, KeyID: e.KeyID}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractEncryptedColumns(t *testing.T) {
+	sql, columns, err := extractEncryptedColumns("CREATE TABLE t (id INT PRIMARY KEY, " +
+		"`ssn` VARCHAR(11) ENCRYPTED DETERMINISTIC, email VARCHAR(100) NOT NULL ENCRYPTED WITH BLIND INDEX KEY 'pii', " +
+		"note TEXT ENCRYPTED, label VARCHAR(20) DEFAULT 'ENCRYPTED')")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE t (id INT PRIMARY KEY, `ssn` VARCHAR(11) , email VARCHAR(100) NOT NULL , "+
+		"note TEXT , label VARCHAR(20) DEFAULT 'ENCRYPTED')", sql)
+	assert.Equal(t, map[string]*EncryptionInfo{
+		"ssn":   {Mode: domain.EncryptionDeterministic},
+		"email": {Mode: domain.EncryptionBlindIndex, KeyID: "pii"},
+		"note":  {Mode: domain.EncryptionRandomized},
+	}, columns)
+
+	// 其他语句和不含 ENCRYPTED 属性的建表语句保持不变
+	for _, s := range []string{
+		"SELECT 'ENCRYPTED' FROM t",
+		"CREATE TABLE t (encrypted INT)",
+		"INSERT INTO t VALUES ('x ENCRYPTED')",
+	} {
+		out, columns, err := extractEncryptedColumns(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, out)
+		assert.Nil(t, columns)
+	}
+
+	_, _, err = extractEncryptedColumns("CREATE TABLE t (a TEXT ENCRYPTED WITH INDEX)")
+	assert.Error(t, err)
+}
+
+func TestParseEncryptedColumns(t *testing.T) {
+	adapter := NewSQLAdapter()
+	sql := "CREATE TABLE secrets (id INT PRIMARY KEY, ssn VARCHAR(11) ENCRYPTED DETERMINISTIC KEY 'k1', note TEXT)"
+
+	// 第二次解析命中解析缓存，加密设置同样生效
+	for i := 0; i < 2; i++ {
+		result, err := adapter.Parse(sql)
+		require.NoError(t, err)
+		require.NotNil(t, result.Statement.Create)
+		cols := result.Statement.Create.Columns
+		require.Len(t, cols, 3)
+		assert.Equal(t, &EncryptionInfo{Mode: domain.EncryptionDeterministic, KeyID: "k1"}, cols[1].Encryption)
+		assert.Nil(t, cols[2].Encryption)
+	}
+}
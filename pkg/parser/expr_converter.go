@@ -113,6 +113,9 @@ func (c *ExprConverter) ExtractDependencies(expr ast.ExprNode) []string {
 		case *ast.PatternLikeOrIlikeExpr:
 			traverse(n.Expr)
 			traverse(n.Pattern)
+		case *ast.PatternRegexpExpr:
+			traverse(n.Expr)
+			traverse(n.Pattern)
 		case *ast.BetweenExpr:
 			traverse(n.Expr)
 			traverse(n.Left)
@@ -0,0 +1,400 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/builtin"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// filterOperators 可以下推的比较运算符，键为解析器产生的运算符（小写）
+var filterOperators = map[string]string{
+	"=": "=", "eq": "=",
+	"!=": "!=", "<>": "!=", "ne": "!=",
+	">": ">", "gt": ">",
+	">=": ">=", "ge": ">=", "gte": ">=",
+	"<": "<", "lt": "<",
+	"<=": "<=", "le": "<=", "lte": "<=",
+	"<=>": "<=>", "nulleq": "<=>",
+	"like": "LIKE", "not like": "NOT LIKE",
+	"in": "IN", "not in": "NOT IN",
+	"between": "BETWEEN", "not between": "NOT BETWEEN",
+}
+
+// negatedOperators NOT 下推到叶子时比较运算符的取反；<=> 的否定对 NULL 为真，无法用过滤器表达
+var negatedOperators = map[string]string{
+	"=": "!=", "!=": "=",
+	">": "<=", "<=": ">",
+	"<": ">=", ">=": "<",
+	"LIKE": "NOT LIKE", "NOT LIKE": "LIKE",
+	"IN": "NOT IN", "NOT IN": "IN",
+	"BETWEEN": "NOT BETWEEN", "NOT BETWEEN": "BETWEEN",
+}
+
+// mirroredOperators 常量在左边时交换操作数后的运算符：5 < v 等价于 v > 5
+var mirroredOperators = map[string]string{
+	"=": "=", "!=": "!=", "<=>": "<=>",
+	">": "<", ">=": "<=",
+	"<": ">", "<=": ">=",
+}
+
+// ToFilter 把谓词转换为等价的数据源过滤器，无法完整表达时返回 false。
+//
+// 支持比较、LIKE、IN、BETWEEN、IS [NOT] NULL 以及由它们组成的 AND / OR / NOT 树。
+// 数据源按二值逻辑匹配过滤器，因此 NOT 不作为过滤器下推，而是按德摩根律推到叶子并取反运算符：
+// NOT (v = 1) 转换为 v != 1，v 为 NULL 的行两者都不匹配，与 SQL 三值逻辑一致。
+// 函数、运算表达式、ESCAPE 和 COLLATE 等无法表达的谓词返回 false
+func ToFilter(expr *Expression) (domain.Filter, bool) {
+	return toFilter(expr, false)
+}
+
+// toFilter 转换谓词，negated 表示谓词处于奇数层 NOT 之下
+func toFilter(expr *Expression, negated bool) (domain.Filter, bool) {
+	if expr == nil || expr.Type != ExprTypeOperator || expr.Escape != "" || hasCollation(expr) {
+		return domain.Filter{}, false
+	}
+
+	op := strings.ToLower(expr.Operator)
+	switch op {
+	case "not", "!":
+		return toFilter(expr.Left, !negated)
+	case "and", "or", "&&", "||":
+		logic := "AND"
+		if op == "or" || op == "||" {
+			logic = "OR"
+		}
+		if negated {
+			logic = map[string]string{"AND": "OR", "OR": "AND"}[logic]
+		}
+		left, ok := toFilter(expr.Left, negated)
+		if !ok {
+			return domain.Filter{}, false
+		}
+		right, ok := toFilter(expr.Right, negated)
+		if !ok {
+			return domain.Filter{}, false
+		}
+		return domain.Filter{
+			LogicOp:    logic,
+			SubFilters: append(flattenFilter(left, logic), flattenFilter(right, logic)...),
+		}, true
+	case "is null", "isnull", "is not null", "isnotnull":
+		if expr.Left == nil || expr.Left.Type != ExprTypeColumn || expr.Left.Column == "" {
+			return domain.Filter{}, false
+		}
+		isNull := (op == "is null" || op == "isnull") != negated
+		operator := "IS NOT NULL"
+		if isNull {
+			operator = "IS NULL"
+		}
+		return domain.Filter{Field: expr.Left.Column, Operator: operator}, true
+	}
+
+	operator, ok := filterOperators[op]
+	if !ok || expr.Left == nil || expr.Right == nil {
+		return domain.Filter{}, false
+	}
+	field, value := expr.Left, expr.Right
+	if field.Type != ExprTypeColumn {
+		mirrored, ok := mirroredOperators[operator]
+		if !ok {
+			return domain.Filter{}, false
+		}
+		field, value, operator = expr.Right, expr.Left, mirrored
+	}
+	if field.Type != ExprTypeColumn || field.Column == "" || value.Type != ExprTypeValue ||
+		!isConstantOperand(operator, value.Value) {
+		return domain.Filter{}, false
+	}
+	if negated {
+		if operator, ok = negatedOperators[operator]; !ok {
+			return domain.Filter{}, false
+		}
+	}
+	return domain.Filter{Field: field.Column, Operator: operator, Value: value.Value}, true
+}
+
+// hasCollation 判断比较是否指定了排序规则
+func hasCollation(expr *Expression) bool {
+	return expr.Collation != "" ||
+		(expr.Left != nil && expr.Left.Collation != "") ||
+		(expr.Right != nil && expr.Right.Collation != "")
+}
+
+// isConstantOperand 判断运算符的右操作数是否为过滤器能携带的常量：
+// IN 为常量列表，BETWEEN 为两个常量边界，其余为单个常量
+func isConstantOperand(operator string, value interface{}) bool {
+	items, isList := value.([]interface{})
+	switch operator {
+	case "IN", "NOT IN", "BETWEEN", "NOT BETWEEN":
+		if !isList || (strings.HasSuffix(operator, "BETWEEN") && len(items) != 2) {
+			return false
+		}
+		for _, item := range items {
+			if _, ok := item.(*Expression); ok {
+				return false
+			}
+		}
+		return true
+	}
+	return !isList
+}
+
+// flattenFilter 展开与外层逻辑相同的子树：a OR (b OR c) 转换为 OR(a, b, c)
+func flattenFilter(f domain.Filter, logic string) []domain.Filter {
+	if f.LogicOp == logic {
+		return f.SubFilters
+	}
+	return []domain.Filter{f}
+}
+
+// SplitFilters 把 WHERE 条件按顶层 AND 拆开：能转换的合取项转换为数据源过滤器，
+// 其余合取项组成剩余条件，由调用方在本地求值，保证不会丢失任何条件
+func SplitFilters(expr *Expression) ([]domain.Filter, *Expression) {
+	if expr == nil {
+		return nil, nil
+	}
+	if expr.Type == ExprTypeOperator && strings.EqualFold(expr.Operator, "and") {
+		leftFilters, leftResidual := SplitFilters(expr.Left)
+		rightFilters, rightResidual := SplitFilters(expr.Right)
+		return append(leftFilters, rightFilters...), andExpression(leftResidual, rightResidual)
+	}
+	if f, ok := ToFilter(expr); ok {
+		return []domain.Filter{f}, nil
+	}
+	return nil, expr
+}
+
+// andExpression 用 AND 连接两个条件，忽略 nil
+func andExpression(left, right *Expression) *Expression {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return &Expression{Type: ExprTypeOperator, Operator: "and", Left: left, Right: right}
+}
+
+// MaterializeSubqueries 用 run 执行条件中 IN (SELECT ...) 的子查询，把结果物化为值列表，
+// 之后的 IN 条件与普通 IN 列表一样可以下推给数据源。
+// 返回的表达式只复制了被改写的路径，不含子查询时返回原表达式
+func MaterializeSubqueries(ctx context.Context, expr *Expression, run func(context.Context, *SelectStatement) (*domain.QueryResult, error)) (*Expression, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	if expr.Type == ExprTypeSubquery {
+		result, err := run(ctx, expr.Subquery)
+		if err != nil {
+			return nil, fmt.Errorf("subquery failed: %w", err)
+		}
+		if len(result.Columns) > 1 {
+			return nil, fmt.Errorf("operand should contain 1 column(s), subquery returns %d", len(result.Columns))
+		}
+		values := make([]interface{}, 0, len(result.Rows))
+		if len(result.Columns) == 1 {
+			col := result.Columns[0].Name
+			for _, row := range result.Rows {
+				values = append(values, row[col])
+			}
+		}
+		return &Expression{Type: ExprTypeValue, Value: values}, nil
+	}
+
+	left, err := MaterializeSubqueries(ctx, expr.Left, run)
+	if err != nil {
+		return nil, err
+	}
+	right, err := MaterializeSubqueries(ctx, expr.Right, run)
+	if err != nil {
+		return nil, err
+	}
+	if left == expr.Left && right == expr.Right {
+		return expr, nil
+	}
+	materialized := *expr
+	materialized.Left, materialized.Right = left, right
+	return &materialized, nil
+}
+
+// evaluateCondition 按 SQL 三值逻辑在行上求值条件，用于无法下推给数据源的剩余条件
+func evaluateCondition(row domain.Row, expr *Expression) (utils.TriBool, error) {
+	if expr == nil {
+		return utils.TriTrue, nil
+	}
+	if expr.Type != ExprTypeOperator || utils.IsArithmeticOperator(expr.Operator) {
+		val, err := evaluateValue(row, expr)
+		if err != nil {
+			return utils.TriUnknown, err
+		}
+		return truthValue(val), nil
+	}
+
+	op := strings.ToLower(expr.Operator)
+	switch op {
+	case "not", "!":
+		result, err := evaluateCondition(row, expr.Left)
+		return result.Not(), err
+	case "and", "&&":
+		left, err := evaluateCondition(row, expr.Left)
+		if err != nil || left == utils.TriFalse {
+			return left, err
+		}
+		right, err := evaluateCondition(row, expr.Right)
+		return left.And(right), err
+	case "or", "||":
+		left, err := evaluateCondition(row, expr.Left)
+		if err != nil || left == utils.TriTrue {
+			return left, err
+		}
+		right, err := evaluateCondition(row, expr.Right)
+		return left.Or(right), err
+	}
+
+	left, err := evaluateValue(row, expr.Left)
+	if err != nil {
+		return utils.TriUnknown, err
+	}
+	right, err := evaluateValue(row, expr.Right)
+	if err != nil {
+		return utils.TriUnknown, err
+	}
+
+	operator, ok := filterOperators[op]
+	if !ok {
+		operator = strings.ToUpper(op)
+	}
+	if collation := conditionCollation(expr); collation != "" && left != nil && right != nil {
+		matched, err := utils.CompareValuesWithCollation(left, right, operator, collation)
+		return utils.TriBoolOf(matched), err
+	}
+	if expr.Escape != "" && strings.HasSuffix(operator, "LIKE") && left != nil && right != nil {
+		escape := []rune(expr.Escape)[0]
+		matched := utils.MatchesLikeEscape(utils.ToString(left), utils.ToString(right), escape)
+		return utils.TriBoolOf(matched != strings.HasPrefix(operator, "NOT ")), nil
+	}
+	return utils.CompareValuesTri(left, right, operator)
+}
+
+// conditionCollation 返回比较使用的排序规则
+func conditionCollation(expr *Expression) string {
+	for _, e := range []*Expression{expr, expr.Left, expr.Right} {
+		if e != nil && e.Collation != "" {
+			return e.Collation
+		}
+	}
+	return ""
+}
+
+// evaluateValue 在行上求值标量表达式：列、常量、内置函数、算术运算和谓词（TRUE → 1，FALSE → 0，UNKNOWN → NULL）
+func evaluateValue(row domain.Row, expr *Expression) (interface{}, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	switch expr.Type {
+	case ExprTypeColumn:
+		return lookupColumn(row, expr.Column), nil
+	case ExprTypeValue:
+		items, ok := expr.Value.([]interface{})
+		if !ok {
+			return expr.Value, nil
+		}
+		// IN 列表和 BETWEEN 边界中的非常量项
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			if itemExpr, ok := item.(*Expression); ok {
+				val, err := evaluateValue(row, itemExpr)
+				if err != nil {
+					return nil, err
+				}
+				item = val
+			}
+			values[i] = item
+		}
+		return values, nil
+	case ExprTypeFunction:
+		fn, ok := builtin.GetGlobal(strings.ToLower(expr.Function))
+		if !ok {
+			return nil, fmt.Errorf("function not found: %s", expr.Function)
+		}
+		args := make([]interface{}, len(expr.Args))
+		for i := range expr.Args {
+			val, err := evaluateValue(row, &expr.Args[i])
+			if err != nil {
+				return nil, err
+			}
+			args[i] = val
+		}
+		return fn.Handler(args)
+	case ExprTypeOperator:
+		if utils.IsArithmeticOperator(expr.Operator) {
+			return evaluateArithmetic(row, expr)
+		}
+		result, err := evaluateCondition(row, expr)
+		if err != nil {
+			return nil, err
+		}
+		switch result {
+		case utils.TriTrue:
+			return int64(1), nil
+		case utils.TriFalse:
+			return int64(0), nil
+		}
+		return nil, nil
+	case ExprTypeSubquery:
+		return nil, fmt.Errorf("subquery was not materialized before evaluation")
+	}
+	return nil, fmt.Errorf("unsupported expression type: %s", expr.Type)
+}
+
+// lookupColumn 读取列值：先按原名查找，再去掉表限定符，最后按 JOIN 结果中的 table.column 后缀查找
+func lookupColumn(row domain.Row, column string) interface{} {
+	if val, ok := row[column]; ok {
+		return val
+	}
+	if idx := strings.LastIndex(column, "."); idx >= 0 {
+		if val, ok := row[column[idx+1:]]; ok {
+			return val
+		}
+		return nil
+	}
+	suffix := "." + column
+	for k, v := range row {
+		if strings.HasSuffix(k, suffix) {
+			return v
+		}
+	}
+	return nil
+}
+
+// truthValue 把标量值解释为条件：NULL 为 UNKNOWN，数字非 0 为真，字符串非空为真
+func truthValue(val interface{}) utils.TriBool {
+	switch v := val.(type) {
+	case nil:
+		return utils.TriUnknown
+	case bool:
+		return utils.TriBoolOf(v)
+	case string:
+		return utils.TriBoolOf(v != "")
+	}
+	if f, err := utils.ToFloat64(val); err == nil {
+		return utils.TriBoolOf(f != 0)
+	}
+	return utils.TriFalse
+}
+
+// evaluateArithmetic 计算算术运算的两个操作数，再交给 utils.Arithmetic 求值
+func evaluateArithmetic(row domain.Row, expr *Expression) (interface{}, error) {
+	left, err := evaluateValue(row, expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := evaluateValue(row, expr.Right)
+	if err != nil {
+		return nil, err
+	}
+	return utils.Arithmetic(expr.Operator, left, right)
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseWhere(t *testing.T, where string) *Expression {
+	t.Helper()
+	result, err := NewSQLAdapter().Parse("SELECT * FROM t WHERE " + where)
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+	return result.Statement.Select.Where
+}
+
+func TestToFilter(t *testing.T) {
+	tests := []struct {
+		where    string
+		expected domain.Filter
+	}{
+		{"a = 1", domain.Filter{Field: "a", Operator: "=", Value: int64(1)}},
+		{"1 < a", domain.Filter{Field: "a", Operator: ">", Value: int64(1)}},
+		{"NOT a > 1", domain.Filter{Field: "a", Operator: "<=", Value: int64(1)}},
+		{"NOT a IN (1, 2)", domain.Filter{Field: "a", Operator: "NOT IN", Value: []interface{}{int64(1), int64(2)}}},
+		{"NOT a IS NULL", domain.Filter{Field: "a", Operator: "IS NOT NULL"}},
+		{"a NOT BETWEEN 1 AND 5", domain.Filter{Field: "a", Operator: "NOT BETWEEN", Value: []interface{}{int64(1), int64(5)}}},
+		{"a = 1 OR (b = 2 OR c = 3)", domain.Filter{LogicOp: "OR", SubFilters: []domain.Filter{
+			{Field: "a", Operator: "=", Value: int64(1)},
+			{Field: "b", Operator: "=", Value: int64(2)},
+			{Field: "c", Operator: "=", Value: int64(3)},
+		}}},
+		{"NOT (a = 1 OR b LIKE 'x%')", domain.Filter{LogicOp: "AND", SubFilters: []domain.Filter{
+			{Field: "a", Operator: "!=", Value: int64(1)},
+			{Field: "b", Operator: "NOT LIKE", Value: "x%"},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.where, func(t *testing.T) {
+			filter, ok := ToFilter(parseWhere(t, tt.where))
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+func TestToFilter_Untranslatable(t *testing.T) {
+	for _, where := range []string{
+		"UPPER(a) = 'X'",
+		"a + 1 = 2",
+		"a = b",
+		"a = 1 OR UPPER(b) = 'X'",
+		"NOT a <=> 1",
+		"a LIKE 'x!%' ESCAPE '!'",
+		"a REGEXP 'x'",
+	} {
+		t.Run(where, func(t *testing.T) {
+			_, ok := ToFilter(parseWhere(t, where))
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestSplitFilters(t *testing.T) {
+	filters, residual := SplitFilters(parseWhere(t, "a = 1 AND UPPER(b) = 'X' AND (c = 2 OR d = 3)"))
+	require.Len(t, filters, 2)
+	assert.Equal(t, "a", filters[0].Field)
+	assert.Equal(t, "OR", filters[1].LogicOp)
+	require.NotNil(t, residual)
+	assert.Equal(t, ExprTypeFunction, residual.Left.Type)
+
+	filters, residual = SplitFilters(parseWhere(t, "a = 1 AND b = 2"))
+	assert.Len(t, filters, 2)
+	assert.Nil(t, residual)
+}
+
+func TestMaterializeSubqueries(t *testing.T) {
+	where := parseWhere(t, "a = 1 AND b IN (SELECT id FROM u)")
+	require.Equal(t, ExprTypeSubquery, where.Right.Right.Type)
+
+	run := func(ctx context.Context, stmt *SelectStatement) (*domain.QueryResult, error) {
+		assert.Equal(t, "u", stmt.From)
+		return &domain.QueryResult{
+			Columns: []domain.ColumnInfo{{Name: "id"}},
+			Rows:    []domain.Row{{"id": int64(7)}, {"id": int64(8)}},
+		}, nil
+	}
+	materialized, err := MaterializeSubqueries(context.Background(), where, run)
+	require.NoError(t, err)
+	assert.Equal(t, ExprTypeSubquery, where.Right.Right.Type, "original expression must not be modified")
+
+	filters, residual := SplitFilters(materialized)
+	assert.Nil(t, residual)
+	require.Len(t, filters, 2)
+	assert.Equal(t, domain.Filter{Field: "b", Operator: "IN", Value: []interface{}{int64(7), int64(8)}}, filters[1])
+}
+
+func TestMaterializeSubqueries_TooManyColumns(t *testing.T) {
+	run := func(ctx context.Context, stmt *SelectStatement) (*domain.QueryResult, error) {
+		return &domain.QueryResult{Columns: []domain.ColumnInfo{{Name: "id"}, {Name: "name"}}}, nil
+	}
+	_, err := MaterializeSubqueries(context.Background(), parseWhere(t, "b IN (SELECT id, name FROM u)"), run)
+	assert.Error(t, err)
+}
+
+func TestExecuteSelect_ResidualWhere(t *testing.T) {
+	builder := NewQueryBuilder(setupUsersAndOrders())
+
+	// 函数条件无法下推，在本地求值
+	result, err := builder.executeSelect(context.Background(), &SelectStatement{
+		Columns: []SelectColumn{{IsWildcard: true}},
+		From:    "users",
+		Where:   parseWhere(t, "department = 'Sales' AND NOT (UPPER(name) = 'DIANA' OR id > 4)"),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Charlie", result.Rows[0]["name"])
+	assert.Equal(t, int64(1), result.Total)
+}
@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+// 模糊测试 SQL 前端（适配器 + QueryBuilder），要求任意输入都不 panic、在期限内结束，
+// 并且同一输入重复解析、执行得到相同的错误。使用 Go 原生模糊测试，OSS-Fuzz 可直接构建：
+//
+//	go test ./pkg/parser -run '^$' -fuzz FuzzParse -fuzztime 60s
+//	go test ./pkg/parser -run '^$' -fuzz FuzzBuildAndExecute -fuzztime 60s
+//
+// 发现的输入保存在 testdata/fuzz/<FuzzXxx>/ 下，之后作为普通测试用例运行
+
+const (
+	// fuzzDeadline 单个输入解析或执行的最长时间，超过视为死循环
+	fuzzDeadline = 5 * time.Second
+
+	// fuzzMaxSQLLen 更长的输入直接跳过，避免把时间花在巨大的输入上
+	fuzzMaxSQLLen = 4096
+)
+
+// fuzzSeeds 初始语料：常见语句、各种语法扩展和客户端生成的奇怪 SQL
+var fuzzSeeds = []string{
+	"SELECT 1",
+	"SELECT * FROM users",
+	"SELECT id, name FROM users WHERE id = 1 AND name LIKE 'A%' ORDER BY name DESC LIMIT 10 OFFSET 2",
+	"SELECT u.name, SUM(o.amount) AS total FROM users u LEFT JOIN orders o ON u.id = o.user_id GROUP BY u.name HAVING total > 100",
+	"SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE amount > 100)",
+	"SELECT department, COUNT(*) OVER (PARTITION BY department ORDER BY id) FROM users",
+	"WITH t AS (SELECT id FROM users) SELECT * FROM t",
+	"SELECT CASE WHEN id > 2 THEN 'a' ELSE NULL END, COALESCE(name, ''), CAST(id AS CHAR) FROM users",
+	"SELECT * FROM users u JOIN orders o USING (id) NATURAL JOIN users",
+	"INSERT INTO users (id, name) VALUES (6, 'Frank'), (7, NULL)",
+	"INSERT INTO orders SELECT * FROM orders WHERE id < 0",
+	"UPDATE users SET name = CONCAT(name, '!') WHERE department = 'HR'",
+	"DELETE FROM orders WHERE amount BETWEEN 10 AND 20 OR product IS NULL",
+	"CREATE TABLE t (id INT PRIMARY KEY AUTO_INCREMENT, v VARCHAR(10) DEFAULT 'x', KEY idx_v (v))",
+	"DROP TABLE IF EXISTS t",
+	"SHOW TABLES",
+	"EXPLAIN SELECT * FROM users",
+	"SELECT id::text FROM users",
+	"SELECT /*+ MAX_EXECUTION_TIME(10) */ * FROM users",
+	"SELECT @@version, @x := 1, ?",
+	"select`id`from`users`where`id`=1",
+	"SELECT 'it''s', \"dq\", 0x1F, b'101', 1e308, -0.0, N'x', _utf8mb4'y' COLLATE utf8mb4_bin",
+	"SELECT * FROM users /* unterminated",
+	"SELECT ((((((((((1))))))))))",
+	"SELECT * FROM users WHERE name = '\\'; DROP TABLE users; --'",
+	"SELECT 1;;SELECT 2;",
+	"SET NAMES utf8mb4; SET autocommit=0",
+	"/* mysql-connector-java */SELECT  @@session.auto_increment_increment AS auto_increment_increment, @@character_set_client AS character_set_client",
+	"SELECT * FROM `users` WHERE `id` IN ()",
+	"",
+	"\x00",
+	"SELECT '\xff\xfe'",
+}
+
+// runWithDeadline 在期限内执行 fn，fn 中的 panic 连同堆栈报告为测试失败
+func runWithDeadline(t *testing.T, sql string, fn func()) {
+	t.Helper()
+	done := make(chan string, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Sprintf("panic: %v\n%s", r, debug.Stack())
+				return
+			}
+			done <- ""
+		}()
+		fn()
+	}()
+
+	select {
+	case msg := <-done:
+		if msg != "" {
+			t.Fatalf("%q: %s", sql, msg)
+		}
+	case <-time.After(fuzzDeadline):
+		t.Fatalf("%q: did not finish within %s", sql, fuzzDeadline)
+	}
+}
+
+// parseOutcome 解析结果的可比较摘要
+func parseOutcome(sql string) string {
+	result, err := NewSQLAdapter().Parse(sql)
+	switch {
+	case err != nil:
+		return "error: " + err.Error()
+	case result == nil:
+		return "nil result"
+	case !result.Success:
+		return "failed: " + result.Error
+	case result.Statement == nil:
+		return "nil statement"
+	}
+	return "ok: " + string(result.Statement.Type)
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, sql string) {
+		if len(sql) > fuzzMaxSQLLen {
+			t.Skip()
+		}
+		runWithDeadline(t, sql, func() {
+			first := parseOutcome(sql)
+			if second := parseOutcome(sql); first != second {
+				t.Errorf("%q: unstable parse result: %s / %s", sql, first, second)
+			}
+			_, _ = NewSQLAdapter().ParseMulti(sql)
+			_ = SplitStatements(sql)
+			_, _ = NormalizeSQL(sql)
+		})
+	})
+}
+
+// executeOutcome 在固定的 users / orders 表上执行语句，返回错误的摘要
+func executeOutcome(stmt *SQLStatement) string {
+	ctx, cancel := context.WithTimeout(context.Background(), fuzzDeadline)
+	defer cancel()
+	_, err := NewQueryBuilder(setupUsersAndOrders()).ExecuteStatement(ctx, stmt)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return "ok"
+}
+
+func FuzzBuildAndExecute(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, sql string) {
+		if len(sql) > fuzzMaxSQLLen {
+			t.Skip()
+		}
+		result, err := NewSQLAdapter().Parse(sql)
+		if err != nil || result == nil || !result.Success || result.Statement == nil {
+			t.Skip()
+		}
+		runWithDeadline(t, sql, func() {
+			first := executeOutcome(result.Statement)
+			again, err := NewSQLAdapter().Parse(sql)
+			if err != nil || !again.Success {
+				t.Errorf("%q: second parse failed: %v", sql, err)
+				return
+			}
+			if second := executeOutcome(again.Statement); first != second {
+				t.Errorf("%q: unstable execution result: %s / %s", sql, first, second)
+			}
+		})
+	})
+}
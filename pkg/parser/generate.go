@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// generateTablePattern 匹配 CREATE TABLE t AS GENERATE(...)，括号内的参数由 optionParser 解析
+var generateTablePattern = regexp.MustCompile("(?is)^\\s*CREATE\\s+TABLE\\s+((?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?)\\s+AS\\s+GENERATE\\s*\\((.*)\\)\\s*;?\\s*$")
+
+// maxGenerateRows 单条语句最多生成的行数
+const maxGenerateRows = 100_000_000
+
+// parseGenerateTable 解析 CREATE TABLE t AS GENERATE(rows=N, [seed=N,] columns=(...))
+func parseGenerateTable(sql string, m []string) (*SQLStatement, error) {
+	stmt := &GenerateTableStatement{Table: strings.ReplaceAll(m[1], "`", "")}
+	p := &optionParser{tokens: tokenizeOptions(m[2])}
+	if err := p.parseOptions(stmt); err != nil {
+		return nil, fmt.Errorf("GENERATE: %w", err)
+	}
+	if stmt.Rows <= 0 {
+		return nil, fmt.Errorf("GENERATE: rows must be positive")
+	}
+	if stmt.Rows > maxGenerateRows {
+		return nil, fmt.Errorf("GENERATE: rows must not exceed %d", maxGenerateRows)
+	}
+	if len(stmt.Columns) == 0 {
+		return nil, fmt.Errorf("GENERATE: columns is required")
+	}
+	return &SQLStatement{Type: SQLTypeGenerate, RawSQL: sql, GenerateTable: stmt}, nil
+}
+
+// optionToken 扩展语句选项列表（GENERATE 参数、IMPORT DATA 的 WITH 选项）的词法单元
+type optionToken struct {
+	kind  byte // 'i' 标识符，'n' 数字，'s' 字符串，其余为标点本身
+	text  string
+	value interface{}
+}
+
+// tokenizeOptions 把选项列表切分成词法单元；无法识别的字符作为单字符标点，由解析时报错
+func tokenizeOptions(s string) []optionToken {
+	var tokens []optionToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s); j++ {
+				if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						b.WriteByte(c)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				tokens = append(tokens, optionToken{kind: '?', text: s[i:]})
+				return tokens
+			}
+			tokens = append(tokens, optionToken{kind: 's', text: s[i : j+1], value: b.String()})
+			i = j + 1
+		case c == '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end < 0 {
+				tokens = append(tokens, optionToken{kind: '?', text: s[i:]})
+				return tokens
+			}
+			tokens = append(tokens, optionToken{kind: 'i', text: s[i+1 : i+1+end]})
+			i += end + 2
+		case isOptionDigit(c) || ((c == '-' || c == '.') && i+1 < len(s) && (isOptionDigit(s[i+1]) || s[i+1] == '.')):
+			j := i + 1
+			for j < len(s) && (isOptionDigit(s[j]) || s[j] == '.' || s[j] == '_' || s[j] == 'e' || s[j] == 'E' ||
+				((s[j] == '+' || s[j] == '-') && (s[j-1] == 'e' || s[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, optionToken{kind: 'n', text: s[i:j]})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || isOptionDigit(s[j]) || s[j] >= 'a' && s[j] <= 'z' || s[j] >= 'A' && s[j] <= 'Z') {
+				j++
+			}
+			tokens = append(tokens, optionToken{kind: 'i', text: s[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, optionToken{kind: c, text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isOptionDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// optionParser 选项列表的递归下降解析器
+type optionParser struct {
+	tokens []optionToken
+	pos    int
+}
+
+func (p *optionParser) peek() optionToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return optionToken{}
+}
+
+func (p *optionParser) next() optionToken {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+func (p *optionParser) expect(kind byte) error {
+	if t := p.next(); t.kind != kind {
+		return fmt.Errorf("expected %q, got %s", kind, describeToken(t))
+	}
+	return nil
+}
+
+func describeToken(t optionToken) string {
+	if t.kind == 0 {
+		return "end of input"
+	}
+	return strconv.Quote(t.text)
+}
+
+// parseOptions 解析 name = value 列表
+func (p *optionParser) parseOptions(stmt *GenerateTableStatement) error {
+	seen := map[string]bool{}
+	for {
+		name := p.next()
+		if name.kind != 'i' {
+			return fmt.Errorf("expected option name, got %s", describeToken(name))
+		}
+		key := strings.ToLower(name.text)
+		if seen[key] {
+			return fmt.Errorf("duplicate option %s", key)
+		}
+		seen[key] = true
+		if err := p.expect('='); err != nil {
+			return err
+		}
+		switch key {
+		case "rows":
+			n, err := p.parseInteger()
+			if err != nil {
+				return fmt.Errorf("rows: %w", err)
+			}
+			stmt.Rows = n
+		case "seed":
+			n, err := p.parseInteger()
+			if err != nil {
+				return fmt.Errorf("seed: %w", err)
+			}
+			stmt.Seed = n
+		case "columns":
+			cols, err := p.parseColumns()
+			if err != nil {
+				return err
+			}
+			stmt.Columns = cols
+		default:
+			return fmt.Errorf("unknown option %s (rows, seed, columns)", name.text)
+		}
+		if p.peek().kind == 0 {
+			return nil
+		}
+		if err := p.expect(','); err != nil {
+			return err
+		}
+	}
+}
+
+// parseInteger 解析整数，允许 1e6 这样没有小数部分的科学计数法
+func (p *optionParser) parseInteger() (int64, error) {
+	t := p.next()
+	if t.kind != 'n' {
+		return 0, fmt.Errorf("expected number, got %s", describeToken(t))
+	}
+	v, err := parseOptionNumber(t.text)
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) <= math.MaxInt64/2 {
+			return int64(n), nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not an integer", t.text)
+}
+
+// parseOptionNumber 把数字文本转换为 int64 或 float64
+func parseOptionNumber(text string) (interface{}, error) {
+	clean := strings.ReplaceAll(text, "_", "")
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %s", text)
+	}
+	return f, nil
+}
+
+// parseColumns 解析 (name GENERATOR[(args)] [PRIMARY KEY], ...)
+func (p *optionParser) parseColumns() ([]GenerateColumn, error) {
+	if err := p.expect('('); err != nil {
+		return nil, fmt.Errorf("columns: %w", err)
+	}
+	var cols []GenerateColumn
+	seen := map[string]bool{}
+	for {
+		name := p.next()
+		if name.kind != 'i' {
+			return nil, fmt.Errorf("columns: expected column name, got %s", describeToken(name))
+		}
+		if seen[strings.ToLower(name.text)] {
+			return nil, fmt.Errorf("columns: duplicate column %s", name.text)
+		}
+		seen[strings.ToLower(name.text)] = true
+		gen := p.next()
+		if gen.kind != 'i' {
+			return nil, fmt.Errorf("column %s: expected generator, got %s", name.text, describeToken(gen))
+		}
+		col := GenerateColumn{Name: name.text, Generator: strings.ToUpper(gen.text)}
+		if p.peek().kind == '(' {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", name.text, err)
+			}
+			col.Args = args
+		}
+		if t := p.peek(); t.kind == 'i' && strings.EqualFold(t.text, "PRIMARY") {
+			p.next()
+			if t := p.next(); t.kind != 'i' || !strings.EqualFold(t.text, "KEY") {
+				return nil, fmt.Errorf("column %s: expected KEY after PRIMARY", name.text)
+			}
+			col.Primary = true
+		}
+		cols = append(cols, col)
+
+		switch t := p.next(); t.kind {
+		case ',':
+		case ')':
+			return cols, nil
+		default:
+			return nil, fmt.Errorf("columns: expected \",\" or \")\", got %s", describeToken(t))
+		}
+	}
+}
+
+// parseArgs 解析生成器参数 (arg, ...)，参数为数字或字符串
+func (p *optionParser) parseArgs() ([]interface{}, error) {
+	p.next()
+	var args []interface{}
+	if p.peek().kind == ')' {
+		p.next()
+		return args, nil
+	}
+	for {
+		t := p.next()
+		switch t.kind {
+		case 'n':
+			v, err := parseOptionNumber(t.text)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, v)
+		case 's':
+			args = append(args, t.value)
+		default:
+			return nil, fmt.Errorf("expected number or string argument, got %s", describeToken(t))
+		}
+		switch t := p.next(); t.kind {
+		case ',':
+		case ')':
+			return args, nil
+		default:
+			return nil, fmt.Errorf("expected \",\" or \")\", got %s", describeToken(t))
+		}
+	}
+}
@@ -2,12 +2,15 @@ package parser
 
 import (
 	"fmt"
-	"log"
 	"sync"
 
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/pingcap/tidb/pkg/parser/ast"
 )
 
+// parserLog 解析器模块的结构化日志
+var parserLog = logging.Module("parser")
+
 // StmtHandler SQL 语句处理器接口
 type StmtHandler interface {
 	Handle(stmt ast.StmtNode) (result interface{}, err error)
@@ -48,7 +51,7 @@ func (c *HandlerChain) Handle(stmt ast.StmtNode) (interface{}, error) {
 	}
 
 	stmtType := GetStmtType(stmt)
-	log.Printf("SQL 语句类型: %s", stmtType)
+	parserLog.Debug("SQL 语句类型", "type", stmtType)
 
 	// 查找对应的处理器
 	c.mu.RLock()
@@ -82,12 +85,9 @@ func (h *QueryHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 		return nil, fmt.Errorf("不是 SELECT 语句")
 	}
 
-	log.Printf("处理 SELECT 语句")
-
 	// 提取查询信息
 	info := ExtractSQLInfo(stmt)
-	log.Printf("涉及表: %v", info.Tables)
-	log.Printf("涉及列: %v", info.Columns)
+	parserLog.Debug("处理 SELECT 语句", "tables", info.Tables, "columns", info.Columns)
 
 	// 返回查询结果
 	return &QueryResult{
@@ -122,9 +122,7 @@ func (h *DMLHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 		stmtType = "UNKNOWN"
 	}
 
-	log.Printf("处理 %s 语句", stmtType)
-	log.Printf("涉及表: %v", info.Tables)
-	log.Printf("涉及列: %v", info.Columns)
+	parserLog.Debug("处理 DML 语句", "type", stmtType, "tables", info.Tables, "columns", info.Columns)
 
 	return &DMLResult{
 		Type:     stmtType,
@@ -164,9 +162,7 @@ func (h *DDLHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 		stmtType = "UNKNOWN"
 	}
 
-	log.Printf("处理 %s 语句", stmtType)
-	log.Printf("涉及表: %v", info.Tables)
-	log.Printf("涉及数据库: %v", info.Databases)
+	parserLog.Debug("处理 DDL 语句", "type", stmtType, "tables", info.Tables, "databases", info.Databases)
 
 	return &DDLResult{
 		Type:      stmtType,
@@ -190,7 +186,7 @@ func (h *SetHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 		return nil, fmt.Errorf("不是 SET 语句")
 	}
 
-	log.Printf("处理 SET 语句，变量数量: %d", len(setStmt.Variables))
+	parserLog.Debug("处理 SET 语句", "count", len(setStmt.Variables))
 
 	vars := make(map[string]interface{})
 	for _, variable := range setStmt.Variables {
@@ -205,7 +201,7 @@ func (h *SetHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 		}
 
 		vars[varName] = varValue
-		log.Printf("设置变量: %s = %s", varName, varValue)
+		parserLog.Debug("设置变量", "name", varName, "value", varValue)
 	}
 
 	return &SetResult{
@@ -230,7 +226,7 @@ func (h *ShowHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 		return nil, fmt.Errorf("不是 SHOW 语句")
 	}
 
-	log.Printf("处理 SHOW 语句")
+	parserLog.Debug("处理 SHOW 语句")
 
 	info := ExtractSQLInfo(stmt)
 
@@ -258,7 +254,7 @@ func (h *UseHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 
 	dbName := useStmt.DBName
 
-	log.Printf("处理 USE 语句，切换到数据库: %s", dbName)
+	parserLog.Debug("处理 USE 语句", "database", dbName)
 
 	return &UseResult{
 		Type:     "USE",
@@ -277,7 +273,7 @@ func NewDefaultHandler() *DefaultHandler {
 // Handle 处理未知类型语句
 func (h *DefaultHandler) Handle(stmt ast.StmtNode) (interface{}, error) {
 	stmtType := GetStmtType(stmt)
-	log.Printf("使用默认处理器处理语句: %s", stmtType)
+	parserLog.Debug("使用默认处理器处理语句", "type", stmtType)
 
 	return &DefaultResult{
 		Type: stmtType,
@@ -24,8 +24,8 @@ type HintsParser struct {
 // NewHintsParser 创建 hints 解析器
 func NewHintsParser() *HintsParser {
 	return &HintsParser{
-		hintCommentPattern: regexp.MustCompile(`/\*+(.*?)\*/`),
-		hintPattern:        regexp.MustCompile(`([A-Z_0-9]+)\s*\(\s*(.*?)\s*\)`),
+		hintCommentPattern: regexp.MustCompile(`(?s)/\*\+(.*?)\*/`),
+		hintPattern:        regexp.MustCompile(`(?i)([A-Z_][A-Z_0-9]*)\s*(?:\(\s*(.*?)\s*\))?`),
 		leadingPattern:     regexp.MustCompile(`LEADING\s*\((.*?)\)`),
 		tableListPattern:   regexp.MustCompile(`([^,]+)(?:,|$)`),
 		indexListPattern:   regexp.MustCompile(`([^@]+)(?:@([^,]+))?(?:,|$)`),
@@ -71,7 +71,7 @@ func (hp *HintsParser) ParseFromComment(comment string) (*ParsedHints, error) {
 				hints.MPP1PhaseAgg = true
 			case "MPP_2PHASE_AGG", "MPP2PHASEAGG":
 				hints.MPP2PhaseAgg = true
-			case "STRAIGHT_JOIN":
+			case "STRAIGHT_JOIN", "JOIN_FIXED_ORDER":
 				hints.StraightJoin = true
 			case "SEMI_JOIN_REWRITE":
 				hints.SemiJoinRewrite = true
@@ -81,6 +81,8 @@ func (hp *HintsParser) ParseFromComment(comment string) (*ParsedHints, error) {
 				hints.UseTOJA = true
 			case "READ_CONSISTENT_REPLICA":
 				hints.ReadConsistentReplica = true
+			default:
+				hints.addWarning("Optimizer hint %s is not supported or requires arguments, ignored", hintName)
 			}
 			continue
 		}
@@ -103,13 +105,13 @@ func (hp *HintsParser) ParseFromComment(comment string) (*ParsedHints, error) {
 			hints.NoMergeJoinTables = hp.parseTableList(hintArgs)
 		case "NO_INDEX_JOIN":
 			hints.NoIndexJoinTables = hp.parseTableList(hintArgs)
-		case "LEADING":
+		case "LEADING", "JOIN_ORDER":
 			hints.LeadingOrder = hp.parseTableList(hintArgs)
 		case "USE_INDEX":
 			hp.parseIndexHint(hintArgs, hints.UseIndex)
-		case "FORCE_INDEX":
+		case "FORCE_INDEX", "INDEX":
 			hp.parseIndexHint(hintArgs, hints.ForceIndex)
-		case "IGNORE_INDEX":
+		case "IGNORE_INDEX", "NO_INDEX":
 			hp.parseIndexHint(hintArgs, hints.IgnoreIndex)
 		case "ORDER_INDEX":
 			table, index := hp.parseTableIndexPair(hintArgs)
@@ -125,6 +127,15 @@ func (hp *HintsParser) ParseFromComment(comment string) (*ParsedHints, error) {
 			duration, err := hp.parseDuration(hintArgs)
 			if err == nil {
 				hints.MaxExecutionTime = duration
+			} else {
+				hints.addWarning("Invalid MAX_EXECUTION_TIME hint argument %q, ignored", hintArgs)
+			}
+		case "PARALLEL":
+			n, err := strconv.Atoi(hintArgs)
+			if err == nil && n > 0 {
+				hints.Parallel = n
+			} else {
+				hints.addWarning("Invalid PARALLEL hint argument %q, ignored", hintArgs)
 			}
 		case "MEMORY_QUOTA":
 			quota, err := strconv.ParseInt(hintArgs, 10, 64)
@@ -136,8 +147,8 @@ func (hp *HintsParser) ParseFromComment(comment string) (*ParsedHints, error) {
 		case "QB_NAME":
 			hints.QBName = hintArgs
 		default:
-			// Unknown hint - log warning but don't fail
-			fmt.Printf("[WARN] Unknown hint: %s\n", hintName)
+			// Unknown hint - record a warning but don't fail
+			hints.addWarning("Optimizer hint %s is not supported, ignored", hintName)
 		}
 	}
 
@@ -176,7 +187,7 @@ func (hp *HintsParser) ExtractHintsFromSQL(sql string) (*ParsedHints, string, er
 	}
 
 	// Check if hints are empty (no actual hints found)
-	if hints.String() == "" {
+	if hints.String() == "" && len(hints.Warnings) == 0 {
 		return hp.NewParsedHints(), sql, nil
 	}
 
@@ -227,6 +238,9 @@ func (hp *HintsParser) parseIndexHint(args string, target map[string][]string) {
 					if tableName != "" && indexName != "" {
 						target[tableName] = []string{indexName}
 					}
+				} else if fields := strings.Fields(item); len(fields) > 1 {
+					// MySQL style: table followed by index names, e.g. INDEX(t idx1 idx2)
+					target[fields[0]] = fields[1:]
 				} else {
 					// Table without specific index
 					target[item] = []string{}
@@ -322,6 +336,15 @@ type ParsedHints struct {
 	MemoryQuota           int64
 	ReadConsistentReplica bool
 	ResourceGroup         string
+	Parallel              int // PARALLEL(n) 扫描并行度
+
+	// Warnings 未识别或参数无效而被忽略的 hint
+	Warnings []string
+}
+
+// addWarning 记录被忽略的 hint
+func (h *ParsedHints) addWarning(format string, args ...interface{}) {
+	h.Warnings = append(h.Warnings, fmt.Sprintf(format, args...))
 }
 
 // NewParsedHints 创建空的 ParsedHints
@@ -396,6 +419,9 @@ func (h *ParsedHints) String() string {
 	if h.MemoryQuota > 0 {
 		parts = append(parts, fmt.Sprintf("MEMORY_QUOTA(%d)", h.MemoryQuota))
 	}
+	if h.Parallel > 0 {
+		parts = append(parts, fmt.Sprintf("PARALLEL(%d)", h.Parallel))
+	}
 	if h.ReadConsistentReplica {
 		parts = append(parts, "READ_CONSISTENT_REPLICA")
 	}
@@ -607,6 +607,74 @@ func TestHintsParser_NewParsedHints(t *testing.T) {
 	}
 }
 
+func TestHintsParser_MySQLStyleHints(t *testing.T) {
+	parser := NewHintsParser()
+
+	hints, err := parser.ParseFromComment("straight_join max_execution_time(500) PARALLEL(4) INDEX(t idx_a) NO_INDEX(u idx_b)")
+	if err != nil {
+		t.Fatalf("ParseFromComment failed: %v", err)
+	}
+
+	if !hints.StraightJoin {
+		t.Error("lowercase bare STRAIGHT_JOIN should be recognized")
+	}
+	if hints.MaxExecutionTime != 500*time.Millisecond {
+		t.Errorf("Expected MaxExecutionTime 500ms, got %v", hints.MaxExecutionTime)
+	}
+	if hints.Parallel != 4 {
+		t.Errorf("Expected Parallel 4, got %d", hints.Parallel)
+	}
+	if idx := hints.ForceIndex["t"]; len(idx) != 1 || idx[0] != "idx_a" {
+		t.Errorf("Expected INDEX(t idx_a) as force index, got %v", hints.ForceIndex)
+	}
+	if idx := hints.IgnoreIndex["u"]; len(idx) != 1 || idx[0] != "idx_b" {
+		t.Errorf("Expected NO_INDEX(u idx_b) as ignore index, got %v", hints.IgnoreIndex)
+	}
+	if len(hints.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", hints.Warnings)
+	}
+	if !contains(hints.String(), "PARALLEL(4)") {
+		t.Errorf("String() should contain PARALLEL(4), got %s", hints.String())
+	}
+}
+
+func TestHintsParser_UnknownHintWarnings(t *testing.T) {
+	parser := NewHintsParser()
+
+	hints, err := parser.ParseFromComment("HASH_AGG() BOGUS_HINT(t) PARALLEL(zero)")
+	if err != nil {
+		t.Fatalf("ParseFromComment failed: %v", err)
+	}
+
+	if !hints.HashAgg {
+		t.Error("HashAgg should still be applied")
+	}
+	if hints.Parallel != 0 {
+		t.Errorf("Invalid PARALLEL should be ignored, got %d", hints.Parallel)
+	}
+	if len(hints.Warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", hints.Warnings)
+	}
+	if !contains(hints.Warnings[0], "BOGUS_HINT") {
+		t.Errorf("Expected warning for BOGUS_HINT, got %s", hints.Warnings[0])
+	}
+}
+
+func TestHintsParser_ExtractHintsFromSQL_IgnoresPlainComments(t *testing.T) {
+	parser := NewHintsParser()
+
+	hints, cleanSQL, err := parser.ExtractHintsFromSQL("SELECT /* just a comment */ * FROM t")
+	if err != nil {
+		t.Fatalf("ExtractHintsFromSQL failed: %v", err)
+	}
+	if len(hints.Warnings) != 0 {
+		t.Errorf("Plain comments should not produce hint warnings, got %v", hints.Warnings)
+	}
+	if cleanSQL != "SELECT /* just a comment */ * FROM t" {
+		t.Errorf("Plain comments should be kept, got %s", cleanSQL)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && indexOf(s, substr) >= 0)
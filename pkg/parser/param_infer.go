@@ -0,0 +1,345 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/pingcap/tidb/pkg/parser/test_driver"
+)
+
+// ParamKind 占位符类型的推断来源
+type ParamKind string
+
+const (
+	ParamKindUnknown ParamKind = ""        // 无法推断，按字符串处理
+	ParamKindColumn  ParamKind = "column"  // 与列比较或赋值，类型取自表结构
+	ParamKindInteger ParamKind = "integer" // LIMIT / OFFSET
+	ParamKindString  ParamKind = "string"  // LIKE / REGEXP 模式
+)
+
+// ParamInfo 预处理语句中 ? 占位符的推断信息
+type ParamInfo struct {
+	Offset int       // 占位符在 SQL 文本中的字节偏移
+	Kind   ParamKind // 推断来源
+	Table  string    // Kind 为 column 时关联列所在的表（已解析别名）
+	Column string    // Kind 为 column 时关联的列
+}
+
+// InferParams 按出现顺序返回语句中所有 ? 占位符的推断信息
+// 占位符与列比较（a = ?、a IN (?, ?)、a BETWEEN ? AND ?）、为列赋值（UPDATE SET、INSERT VALUES）时
+// 关联到该列；tableColumns 用于解析没有列清单的 INSERT，可以为 nil
+func InferParams(stmt ast.StmtNode, tableColumns func(table string) []string) []ParamInfo {
+	if stmt == nil {
+		return nil
+	}
+
+	c := &paramCollector{
+		aliases:  make(map[string]string),
+		bindings: make(map[*test_driver.ParamMarkerExpr]ParamInfo),
+	}
+	// 先收集表和别名，再遍历表达式，保证 WHERE 中的限定列能解析到表
+	stmt.Accept(&tableCollector{c: c})
+
+	if insert, ok := stmt.(*ast.InsertStmt); ok {
+		c.bindInsertValues(insert, tableColumns)
+	}
+	stmt.Accept(c)
+
+	sort.Slice(c.markers, func(i, j int) bool { return c.markers[i].Offset < c.markers[j].Offset })
+	params := make([]ParamInfo, len(c.markers))
+	for i, marker := range c.markers {
+		info, ok := c.bindings[marker]
+		if !ok {
+			info = ParamInfo{Kind: ParamKindUnknown}
+		}
+		info.Offset = marker.Offset
+		params[i] = info
+	}
+	return params
+}
+
+// ResultColumn SELECT 语句结果列的来源信息
+type ResultColumn struct {
+	Name     string // 结果列名（别名优先）
+	Table    string // 来源表（已解析别名），表达式列为空
+	Column   string // 来源列，表达式列为空
+	Wildcard bool   // 是否为 * 或 t.*，此时 Table 为空表示语句的默认表
+	Count    bool   // 是否为 COUNT 聚合，结果类型固定为整数
+}
+
+// InferResultColumns 按 SELECT 字段顺序返回结果列的来源，非 SELECT 语句返回 nil
+func InferResultColumns(stmt ast.StmtNode) []ResultColumn {
+	sel, ok := stmt.(*ast.SelectStmt)
+	if !ok || sel.Fields == nil {
+		return nil
+	}
+
+	c := &paramCollector{aliases: make(map[string]string)}
+	if sel.From != nil {
+		sel.From.Accept(&tableCollector{c: c})
+	}
+
+	columns := make([]ResultColumn, 0, len(sel.Fields.Fields))
+	for _, field := range sel.Fields.Fields {
+		if field.WildCard != nil {
+			rc := ResultColumn{Wildcard: true}
+			if field.WildCard.Table.L != "" {
+				rc.Table = c.resolveTable(field.WildCard.Table)
+			}
+			columns = append(columns, rc)
+			continue
+		}
+
+		rc := ResultColumn{Name: field.AsName.O}
+		switch expr := unwrapParentheses(field.Expr).(type) {
+		case *ast.ColumnNameExpr:
+			rc.Table = c.defaultTable
+			if expr.Name.Table.L != "" {
+				rc.Table = c.resolveTable(expr.Name.Table)
+			}
+			rc.Column = expr.Name.Name.O
+			if rc.Name == "" {
+				rc.Name = rc.Column
+			}
+		case *ast.AggregateFuncExpr:
+			rc.Count = strings.EqualFold(expr.F, ast.AggFuncCount)
+		}
+		if rc.Name == "" {
+			rc.Name = field.Text()
+		}
+		columns = append(columns, rc)
+	}
+	return columns
+}
+
+// tableCollector 收集语句引用的表及其别名
+type tableCollector struct {
+	c *paramCollector
+}
+
+func (t *tableCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if ts, ok := n.(*ast.TableSource); ok {
+		if name, ok := ts.Source.(*ast.TableName); ok {
+			table := name.Name.O
+			if t.c.defaultTable == "" {
+				t.c.defaultTable = table
+			}
+			if ts.AsName.L != "" {
+				t.c.aliases[ts.AsName.L] = table
+			}
+		}
+	}
+	return n, false
+}
+
+func (t *tableCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// paramCollector 遍历 AST，记录占位符及其关联的列
+type paramCollector struct {
+	defaultTable string
+	aliases      map[string]string
+	bindings     map[*test_driver.ParamMarkerExpr]ParamInfo
+	markers      []*test_driver.ParamMarkerExpr
+}
+
+func (c *paramCollector) Enter(n ast.Node) (ast.Node, bool) {
+	switch x := n.(type) {
+	case *test_driver.ParamMarkerExpr:
+		c.markers = append(c.markers, x)
+	case *ast.BinaryOperationExpr:
+		c.bindToColumn(x.R, x.L)
+		c.bindToColumn(x.L, x.R)
+	case *ast.PatternInExpr:
+		for _, item := range x.List {
+			c.bindToColumn(item, x.Expr)
+		}
+	case *ast.BetweenExpr:
+		c.bindToColumn(x.Left, x.Expr)
+		c.bindToColumn(x.Right, x.Expr)
+	case *ast.PatternLikeOrIlikeExpr:
+		c.bindKind(x.Pattern, ParamKindString)
+	case *ast.PatternRegexpExpr:
+		c.bindKind(x.Pattern, ParamKindString)
+	case *ast.Assignment:
+		if x.Column != nil {
+			c.bindColumnName(x.Expr, x.Column)
+		}
+	case *ast.Limit:
+		c.bindKind(x.Count, ParamKindInteger)
+		c.bindKind(x.Offset, ParamKindInteger)
+	}
+	return n, false
+}
+
+func (c *paramCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// bindInsertValues 将 INSERT VALUES 中的占位符关联到对应位置的列
+func (c *paramCollector) bindInsertValues(insert *ast.InsertStmt, tableColumns func(table string) []string) {
+	columns := make([]string, len(insert.Columns))
+	for i, col := range insert.Columns {
+		columns[i] = col.Name.O
+	}
+	if len(columns) == 0 && tableColumns != nil && c.defaultTable != "" {
+		columns = tableColumns(c.defaultTable)
+	}
+
+	for _, row := range insert.Lists {
+		for i, value := range row {
+			if i >= len(columns) {
+				break
+			}
+			if marker := paramMarker(value); marker != nil {
+				c.bind(marker, ParamInfo{Kind: ParamKindColumn, Table: c.defaultTable, Column: columns[i]})
+			}
+		}
+	}
+}
+
+// bindToColumn 当 other 为列引用时，将 expr 中的占位符关联到该列
+func (c *paramCollector) bindToColumn(expr, other ast.ExprNode) {
+	if col, ok := unwrapParentheses(other).(*ast.ColumnNameExpr); ok && col.Name != nil {
+		c.bindColumnName(expr, col.Name)
+	}
+}
+
+// bindColumnName 将 expr 中的占位符关联到列 name
+func (c *paramCollector) bindColumnName(expr ast.ExprNode, name *ast.ColumnName) {
+	marker := paramMarker(expr)
+	if marker == nil {
+		return
+	}
+	table := c.defaultTable
+	if name.Table.L != "" {
+		table = c.resolveTable(name.Table)
+	}
+	c.bind(marker, ParamInfo{Kind: ParamKindColumn, Table: table, Column: name.Name.O})
+}
+
+// resolveTable 将表限定名（可能是别名）解析为表名
+func (c *paramCollector) resolveTable(name ast.CIStr) string {
+	if resolved, ok := c.aliases[name.L]; ok {
+		return resolved
+	}
+	return name.O
+}
+
+// bindKind 为 expr 中的占位符记录不依赖列的类型
+func (c *paramCollector) bindKind(expr ast.ExprNode, kind ParamKind) {
+	if marker := paramMarker(expr); marker != nil {
+		c.bind(marker, ParamInfo{Kind: kind})
+	}
+}
+
+// bind 记录占位符信息，先推断出的结果优先
+func (c *paramCollector) bind(marker *test_driver.ParamMarkerExpr, info ParamInfo) {
+	if _, exists := c.bindings[marker]; !exists {
+		c.bindings[marker] = info
+	}
+}
+
+// paramMarker 返回 expr（去除括号后）对应的占位符，不是占位符时返回 nil
+func paramMarker(expr ast.ExprNode) *test_driver.ParamMarkerExpr {
+	if expr == nil {
+		return nil
+	}
+	marker, _ := unwrapParentheses(expr).(*test_driver.ParamMarkerExpr)
+	return marker
+}
+
+// unwrapParentheses 去除表达式外层的括号
+func unwrapParentheses(expr ast.ExprNode) ast.ExprNode {
+	for {
+		p, ok := expr.(*ast.ParenthesesExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
+
+// BindParams 将参数值按占位符偏移替换为 SQL 字面量
+// params 为 InferParams 的结果；偏移与 SQL 文本不一致时按顺序替换引号之外的 ?
+func BindParams(sql string, params []ParamInfo, values []interface{}) string {
+	offsets := make([]int, 0, len(params))
+	for _, p := range params {
+		if p.Offset < 0 || p.Offset >= len(sql) || sql[p.Offset] != '?' {
+			offsets = placeholderOffsets(sql)
+			break
+		}
+		offsets = append(offsets, p.Offset)
+	}
+
+	var b strings.Builder
+	b.Grow(len(sql) + len(values)*8)
+	last := 0
+	for i, offset := range offsets {
+		if i >= len(values) {
+			break
+		}
+		b.WriteString(sql[last:offset])
+		b.WriteString(FormatSQLLiteral(values[i]))
+		last = offset + 1
+	}
+	b.WriteString(sql[last:])
+	return b.String()
+}
+
+// placeholderOffsets 返回引号和反引号之外所有 ? 的偏移
+func placeholderOffsets(sql string) []int {
+	var offsets []int
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' && quote != '`' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '?':
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// FormatSQLLiteral 将参数值格式化为 SQL 字面量，字符串中的引号和反斜杠会被转义
+func FormatSQLLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return security.QuoteString(v)
+	case []byte:
+		return security.QuoteString(string(v))
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int:
+		return strconv.Itoa(v)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return security.QuoteString(v.Format("2006-01-02 15:04:05.999999"))
+	default:
+		return security.QuoteString(fmt.Sprintf("%v", v))
+	}
+}
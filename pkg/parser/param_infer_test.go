@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferParams(t *testing.T) {
+	p := NewParser()
+
+	stmt, err := p.ParseOneStmtText("SELECT * FROM users u WHERE u.age > ? AND name LIKE ? AND id IN (?, ?) LIMIT ?")
+	require.NoError(t, err)
+	params := InferParams(stmt, nil)
+	require.Len(t, params, 5)
+	assert.Equal(t, ParamInfo{Offset: 36, Kind: ParamKindColumn, Table: "users", Column: "age"}, params[0])
+	assert.Equal(t, ParamKindString, params[1].Kind)
+	assert.Equal(t, "id", params[2].Column)
+	assert.Equal(t, "id", params[3].Column)
+	assert.Equal(t, ParamKindInteger, params[4].Kind)
+
+	stmt, err = p.ParseOneStmtText("INSERT INTO users VALUES (?, ?)")
+	require.NoError(t, err)
+	params = InferParams(stmt, func(table string) []string { return []string{"id", "name"} })
+	require.Len(t, params, 2)
+	assert.Equal(t, "name", params[1].Column)
+	assert.Equal(t, "users", params[1].Table)
+
+	stmt, err = p.ParseOneStmtText("UPDATE users SET name = ? WHERE ? = 1")
+	require.NoError(t, err)
+	params = InferParams(stmt, nil)
+	require.Len(t, params, 2)
+	assert.Equal(t, "name", params[0].Column)
+	assert.Equal(t, ParamKindUnknown, params[1].Kind)
+}
+
+func TestInferResultColumns(t *testing.T) {
+	p := NewParser()
+
+	stmt, err := p.ParseOneStmtText("SELECT o.*, u.name AS user_name, COUNT(*), age + 1 FROM users u JOIN orders o ON u.id = o.uid")
+	require.NoError(t, err)
+	columns := InferResultColumns(stmt)
+	require.Len(t, columns, 4)
+	assert.Equal(t, ResultColumn{Table: "orders", Wildcard: true}, columns[0])
+	assert.Equal(t, ResultColumn{Name: "user_name", Table: "users", Column: "name"}, columns[1])
+	assert.True(t, columns[2].Count)
+	assert.Empty(t, columns[3].Column)
+	assert.NotEmpty(t, columns[3].Name)
+
+	stmt, err = p.ParseOneStmtText("DELETE FROM users WHERE id = ?")
+	require.NoError(t, err)
+	assert.Nil(t, InferResultColumns(stmt))
+}
+
+func TestBindParams(t *testing.T) {
+	sql := "SELECT * FROM t WHERE a = ? AND b = '?' AND c = ?"
+	p := NewParser()
+	stmt, err := p.ParseOneStmtText(sql)
+	require.NoError(t, err)
+
+	bound := BindParams(sql, InferParams(stmt, nil), []interface{}{1, "it's"})
+	assert.Equal(t, "SELECT * FROM t WHERE a = 1 AND b = '?' AND c = 'it\\'s'", bound)
+
+	// 没有推断信息时按引号之外的 ? 顺序替换
+	bound = BindParams(sql, []ParamInfo{{Offset: -1}, {Offset: -1}}, []interface{}{nil, true})
+	assert.Equal(t, "SELECT * FROM t WHERE a = NULL AND b = '?' AND c = TRUE", bound)
+}
@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/tidb/pkg/parser/ast"
+)
+
+const (
+	// DefaultParseCacheMaxEntries 解析缓存默认最大条目数
+	DefaultParseCacheMaxEntries = 1024
+	// DefaultParseCacheMaxSQLLength 默认可缓存的 SQL 最大长度（字节）
+	DefaultParseCacheMaxSQLLength = 16 * 1024
+)
+
+// ParseCacheStats 解析缓存统计
+type ParseCacheStats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64
+	Entries    int
+	MaxEntries int
+}
+
+// parseCacheEntry 缓存条目
+// 只缓存 TiDB 解析得到的 AST，每次命中都会重新转换为 SQLStatement，
+// 因此调用方拿到的 SQLStatement 不会在会话之间共享，可以放心修改
+type parseCacheEntry struct {
+	key  string
+	stmt ast.StmtNode
+}
+
+// ParseCache SQL 解析缓存（LRU，并发安全）
+// 以 SQL 原文（只去掉首尾空白和末尾分号）为键缓存 TiDB AST，避免高 QPS 场景下重复解析逐字相同的 SQL，
+// 如 ORM 和预处理语句反复发送的固定文本。字面量、大小写或空白不同的语句各占一个条目。
+// AST 不依赖表结构，DDL 之后仍然有效，不需要失效
+type ParseCache struct {
+	mu           sync.Mutex
+	maxEntries   int // 0 表示禁用缓存
+	maxSQLLength int // 超过该长度的 SQL 不缓存，0 表示不限制
+	ll           *list.List
+	items        map[string]*list.Element
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// NewParseCache 创建解析缓存，maxEntries <= 0 表示禁用
+func NewParseCache(maxEntries, maxSQLLength int) *ParseCache {
+	return &ParseCache{
+		maxEntries:   maxEntries,
+		maxSQLLength: maxSQLLength,
+		ll:           list.New(),
+		items:        make(map[string]*list.Element),
+	}
+}
+
+var globalParseCache = NewParseCache(DefaultParseCacheMaxEntries, DefaultParseCacheMaxSQLLength)
+
+// GetParseCache 获取全局解析缓存
+func GetParseCache() *ParseCache {
+	return globalParseCache
+}
+
+// cacheKey 返回 SQL 的缓存键：去掉首尾空白和末尾分号，不做其他规范化
+func cacheKey(sql string) string {
+	return strings.TrimRight(strings.TrimSpace(sql), "; \t\r\n")
+}
+
+// Configure 调整缓存容量，缩容时按 LRU 顺序淘汰多余条目
+func (c *ParseCache) Configure(maxEntries, maxSQLLength int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntries = maxEntries
+	c.maxSQLLength = maxSQLLength
+	c.evictLocked()
+}
+
+// Get 查找缓存的 AST
+func (c *ParseCache) Get(sql string) (ast.StmtNode, bool) {
+	c.mu.Lock()
+	if c.maxEntries <= 0 {
+		c.mu.Unlock()
+		return nil, false
+	}
+	elem, ok := c.items[cacheKey(sql)]
+	if ok {
+		c.ll.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return elem.Value.(*parseCacheEntry).stmt, true
+}
+
+// Put 缓存 AST，DDL 语句和超长 SQL 不缓存
+func (c *ParseCache) Put(sql string, stmt ast.StmtNode) {
+	if stmt == nil {
+		return
+	}
+	if _, isDDL := stmt.(ast.DDLNode); isDDL {
+		return
+	}
+	key := cacheKey(sql)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxEntries <= 0 || (c.maxSQLLength > 0 && len(key) > c.maxSQLLength) {
+		return
+	}
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*parseCacheEntry).stmt = stmt
+		c.ll.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&parseCacheEntry{key: key, stmt: stmt})
+	c.evictLocked()
+}
+
+// Invalidate 清空缓存
+func (c *ParseCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Len 返回当前缓存条目数
+func (c *ParseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Stats 返回缓存统计
+func (c *ParseCache) Stats() ParseCacheStats {
+	c.mu.Lock()
+	entries, maxEntries := c.ll.Len(), c.maxEntries
+	c.mu.Unlock()
+	return ParseCacheStats{
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Evictions:  c.evictions.Load(),
+		Entries:    entries,
+		MaxEntries: maxEntries,
+	}
+}
+
+// evictLocked 淘汰超出容量的最久未使用条目（调用方需持有锁）
+func (c *ParseCache) evictLocked() {
+	limit := c.maxEntries
+	if limit < 0 {
+		limit = 0
+	}
+	for c.ll.Len() > limit {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*parseCacheEntry).key)
+		c.evictions.Add(1)
+	}
+}
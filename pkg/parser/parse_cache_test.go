@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/pkg/parser"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseNode(t *testing.T, sql string) ast.StmtNode {
+	t.Helper()
+	stmt, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err)
+	return stmt
+}
+
+func TestParseCache_HitMiss(t *testing.T) {
+	c := NewParseCache(4, 0)
+	stmt := mustParseNode(t, "SELECT 1")
+
+	_, ok := c.Get("SELECT 1")
+	assert.False(t, ok)
+
+	c.Put("SELECT 1", stmt)
+	got, ok := c.Get("  SELECT 1; ")
+	require.True(t, ok)
+	assert.Same(t, stmt, got)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestParseCache_LRUEviction(t *testing.T) {
+	c := NewParseCache(2, 0)
+	c.Put("SELECT 1", mustParseNode(t, "SELECT 1"))
+	c.Put("SELECT 2", mustParseNode(t, "SELECT 2"))
+
+	// 访问 SELECT 1 使其成为最近使用
+	_, ok := c.Get("SELECT 1")
+	require.True(t, ok)
+
+	c.Put("SELECT 3", mustParseNode(t, "SELECT 3"))
+	_, ok = c.Get("SELECT 2")
+	assert.False(t, ok)
+	_, ok = c.Get("SELECT 1")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+
+	c.Configure(1, 0)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestParseCache_Limits(t *testing.T) {
+	c := NewParseCache(4, 10)
+	c.Put("SELECT 1 FROM dual", mustParseNode(t, "SELECT 1 FROM dual"))
+	assert.Equal(t, 0, c.Len(), "SQL longer than maxSQLLength should not be cached")
+
+	c.Put("CREATE TABLE t (a INT)", mustParseNode(t, "CREATE TABLE t (a INT)"))
+	assert.Equal(t, 0, c.Len(), "DDL should not be cached")
+
+	disabled := NewParseCache(0, 0)
+	disabled.Put("SELECT 1", mustParseNode(t, "SELECT 1"))
+	_, ok := disabled.Get("SELECT 1")
+	assert.False(t, ok)
+}
+
+func TestParseCache_Invalidate(t *testing.T) {
+	c := NewParseCache(4, 0)
+	c.Put("SELECT 1", mustParseNode(t, "SELECT 1"))
+	c.Invalidate()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestSQLAdapter_ParseUsesCache(t *testing.T) {
+	before := GetParseCache().Stats()
+
+	adapter := NewSQLAdapter()
+	sql := "SELECT id, name FROM parse_cache_users WHERE id = 42"
+	first, err := adapter.Parse(sql)
+	require.NoError(t, err)
+	second, err := NewSQLAdapter().Parse(sql)
+	require.NoError(t, err)
+
+	after := GetParseCache().Stats()
+	assert.Equal(t, before.Hits+1, after.Hits)
+
+	// 命中缓存时返回的是新转换的语句，不与首次解析结果共享
+	require.NotNil(t, first.Statement.Select)
+	require.NotNil(t, second.Statement.Select)
+	assert.NotSame(t, first.Statement.Select, second.Statement.Select)
+	assert.Equal(t, first.Statement.Select.From, second.Statement.Select.From)
+}
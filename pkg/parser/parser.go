@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -29,8 +30,13 @@ func (p *Parser) ParseSQL(sql string) ([]ast.StmtNode, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	tidbSQL, _, err := extractEncryptedColumns(sql)
+	if err != nil {
+		return nil, fmt.Errorf("解析 SQL 失败: %w", err)
+	}
+
 	// 预处理 SQL：将 WITH 子句转换为 COMMENT 子句
-	preprocessedSQL := preprocessWithClause(sql)
+	preprocessedSQL := preprocessWithClause(preprocessTTLClause(preprocessAsOfClause(preprocessTableSample(tidbSQL))))
 
 	stmtNodes, warnings, err := p.parser.ParseSQL(preprocessedSQL)
 	if err != nil {
@@ -128,6 +134,271 @@ func preprocessWithClause(sql string) string {
 	return newSQL
 }
 
+// ttlValuePattern 匹配 WITH TTL 的时长字面量，如 '7d'
+var ttlValuePattern = regexp.MustCompile(`(?i)^'\s*(\d+)\s*([a-z]+)\s*'$`)
+
+// tableNameList 匹配逗号分隔的表名列表，表名可以带库名前缀和反引号
+const tableNameList = "(?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?(?:\\s*,\\s*(?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?)*"
+
+// tableName 匹配表名 t 或 db.t，名称可以用反引号括起
+const tableName = "(?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?"
+
+// databaseNameList 匹配逗号分隔的库名列表，库名可以带反引号
+const databaseNameList = "(?:`[^`]+`|\\w+)(?:\\s*,\\s*(?:`[^`]+`|\\w+))*"
+
+// 以下语句 TiDB 解析器不支持，在解析前直接识别
+var (
+	// showTableMemoryPattern 匹配 SHOW TABLE MEMORY [FROM table]
+	showTableMemoryPattern = regexp.MustCompile("(?i)^\\s*SHOW\\s+TABLE\\s+MEMORY(?:\\s+(?:FROM|IN)\\s+(`[^`]+`|[\\w.]+))?\\s*;?\\s*$")
+	// showDatasourcesPattern 匹配 SHOW DATASOURCES
+	showDatasourcesPattern = regexp.MustCompile("(?i)^\\s*SHOW\\s+DATASOURCES\\s*;?\\s*$")
+	// showSchemaChangesPattern 匹配 SHOW SCHEMA CHANGES [FROM|IN db]
+	showSchemaChangesPattern = regexp.MustCompile("(?i)^\\s*SHOW\\s+SCHEMA\\s+CHANGES(?:\\s+(?:FROM|IN)\\s+(`[^`]+`|\\w+))?\\s*;?\\s*$")
+	// exportTablePattern 匹配 EXPORT TABLE t TO 'file'
+	exportTablePattern = regexp.MustCompile("(?i)^\\s*EXPORT\\s+TABLE\\s+(`[^`]+`|[\\w.]+)\\s+TO\\s+'([^']*)'\\s*;?\\s*$")
+	// importTablePattern 匹配 IMPORT TABLE [t] FROM 'file'
+	importTablePattern = regexp.MustCompile("(?i)^\\s*IMPORT\\s+TABLE(?:\\s+(`[^`]+`|[\\w.]+))?\\s+FROM\\s+'([^']*)'\\s*;?\\s*$")
+	// undropTablePattern 匹配 UNDROP TABLE t
+	undropTablePattern = regexp.MustCompile("(?i)^\\s*UNDROP\\s+TABLE\\s+((?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?)\\s*;?\\s*$")
+	// flashbackTablePattern 匹配 FLASHBACK TABLE t TO TIMESTAMP 'time'
+	flashbackTablePattern = regexp.MustCompile("(?i)^\\s*FLASHBACK\\s+TABLE\\s+((?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?)\\s+TO\\s+TIMESTAMP\\s+'([^']*)'\\s*;?\\s*$")
+	// backupDatabasePattern 匹配 BACKUP [INCREMENTAL] {DATABASE|SCHEMA} {* | db[, db]} TO 'target' [FROM 'base']（与 TiDB 的 BR 语法一致）
+	backupDatabasePattern = regexp.MustCompile("(?i)^\\s*BACKUP\\s+(INCREMENTAL\\s+)?(?:DATABASES?|SCHEMAS?)\\s+(\\*|" + databaseNameList + ")\\s+TO\\s+'([^']*)'(?:\\s+FROM\\s+'([^']*)')?\\s*;?\\s*$")
+	// restoreDatabasePattern 匹配 RESTORE {DATABASE|SCHEMA} {* | db[, db]} FROM 'full'[, 'incremental' ...] [UNTIL TIMESTAMP 'time']
+	restoreDatabasePattern = regexp.MustCompile("(?i)^\\s*RESTORE\\s+(?:DATABASES?|SCHEMAS?)\\s+(\\*|" + databaseNameList + ")\\s+FROM\\s+('[^']*'(?:\\s*,\\s*'[^']*')*)(?:\\s+UNTIL\\s+TIMESTAMP\\s+'([^']*)')?\\s*;?\\s*$")
+	// checkTablePattern 匹配 CHECKSUM TABLE t1[, t2] [QUICK|EXTENDED] 和 CHECK TABLE t1[, t2] [选项]
+	// MySQL 的选项只影响检查方式，这里总是执行完整检查
+	checkTablePattern = regexp.MustCompile("(?i)^\\s*(CHECKSUM|CHECK)\\s+TABLE\\s+(" + tableNameList + ")(?:\\s+(?:QUICK|EXTENDED|FAST|MEDIUM|CHANGED|FOR\\s+UPGRADE))*\\s*;?\\s*$")
+	// diffTablePattern 匹配 DIFF TABLE a, b [KEY (col[, col])]
+	diffTablePattern = regexp.MustCompile("(?i)^\\s*DIFF\\s+TABLES?\\s+(" + tableName + ")\\s*,\\s*(" + tableName + ")(?:\\s+KEY\\s*\\(\\s*(" + databaseNameList + ")\\s*\\))?\\s*;?\\s*$")
+	// shadowTablePattern 匹配 SHADOW TABLE t TO db [WITH COMPARE]
+	shadowTablePattern = regexp.MustCompile("(?i)^\\s*SHADOW\\s+TABLE\\s+(" + tableName + ")\\s+TO\\s+(`[^`]+`|\\w+)(\\s+WITH\\s+COMPARE)?\\s*;?\\s*$")
+	// cutoverTablePattern 匹配 CUTOVER TABLE t 和 UNSHADOW TABLE t
+	cutoverTablePattern = regexp.MustCompile("(?i)^\\s*(CUTOVER|UNSHADOW)\\s+TABLE\\s+(" + tableName + ")\\s*;?\\s*$")
+	// showShadowTablesPattern 匹配 SHOW SHADOW TABLES
+	showShadowTablesPattern = regexp.MustCompile("(?i)^\\s*SHOW\\s+SHADOW\\s+TABLES\\s*;?\\s*$")
+)
+
+// parseExtensionStatement 识别 TiDB 解析器不支持的扩展语句，不是扩展语句时返回 nil
+func parseExtensionStatement(sql string) (*SQLStatement, error) {
+	if m := showTableMemoryPattern.FindStringSubmatch(sql); m != nil {
+		return &SQLStatement{
+			Type:   SQLTypeShow,
+			RawSQL: sql,
+			Show:   &ShowStatement{Type: "TABLE_MEMORY", Table: strings.Trim(m[1], "`")},
+		}, nil
+	}
+	if showDatasourcesPattern.MatchString(sql) {
+		return &SQLStatement{
+			Type:   SQLTypeShow,
+			RawSQL: sql,
+			Show:   &ShowStatement{Type: "DATASOURCES"},
+		}, nil
+	}
+	if showShadowTablesPattern.MatchString(sql) {
+		return &SQLStatement{
+			Type:   SQLTypeShow,
+			RawSQL: sql,
+			Show:   &ShowStatement{Type: "SHADOW_TABLES"},
+		}, nil
+	}
+	if m := showSchemaChangesPattern.FindStringSubmatch(sql); m != nil {
+		return &SQLStatement{
+			Type:   SQLTypeShow,
+			RawSQL: sql,
+			Show:   &ShowStatement{Type: "SCHEMA_CHANGES", Database: strings.Trim(m[1], "`")},
+		}, nil
+	}
+	if m := exportTablePattern.FindStringSubmatch(sql); m != nil {
+		return &SQLStatement{
+			Type:        SQLTypeExport,
+			RawSQL:      sql,
+			ExportTable: &ExportTableStatement{Table: strings.Trim(m[1], "`"), File: m[2]},
+		}, nil
+	}
+	if m := importTablePattern.FindStringSubmatch(sql); m != nil {
+		return &SQLStatement{
+			Type:        SQLTypeImport,
+			RawSQL:      sql,
+			ImportTable: &ImportTableStatement{Table: strings.Trim(m[1], "`"), File: m[2]},
+		}, nil
+	}
+	if m := undropTablePattern.FindStringSubmatch(sql); m != nil {
+		return &SQLStatement{
+			Type:   SQLTypeUndrop,
+			RawSQL: sql,
+			Undrop: &UndropTableStatement{Table: strings.ReplaceAll(m[1], "`", "")},
+		}, nil
+	}
+	if m := flashbackTablePattern.FindStringSubmatch(sql); m != nil {
+		if _, err := ParseTimestamp(m[2]); err != nil {
+			return nil, err
+		}
+		return &SQLStatement{
+			Type:      SQLTypeFlashback,
+			RawSQL:    sql,
+			Flashback: &FlashbackTableStatement{Table: strings.ReplaceAll(m[1], "`", ""), Timestamp: m[2]},
+		}, nil
+	}
+	if m := backupDatabasePattern.FindStringSubmatch(sql); m != nil {
+		incremental := m[1] != ""
+		if incremental != (m[4] != "") {
+			return nil, fmt.Errorf("BACKUP INCREMENTAL requires FROM 'base' and FROM requires INCREMENTAL")
+		}
+		return &SQLStatement{
+			Type:   SQLTypeBackup,
+			RawSQL: sql,
+			Backup: &BackupStatement{Databases: splitDatabaseNames(m[2]), Target: m[3], Incremental: incremental, Base: m[4]},
+		}, nil
+	}
+	if m := restoreDatabasePattern.FindStringSubmatch(sql); m != nil {
+		if m[3] != "" {
+			if _, err := ParseTimestamp(m[3]); err != nil {
+				return nil, err
+			}
+		}
+		var sources []string
+		for _, source := range strings.Split(m[2], ",") {
+			sources = append(sources, strings.Trim(strings.TrimSpace(source), "'"))
+		}
+		return &SQLStatement{
+			Type:    SQLTypeRestore,
+			RawSQL:  sql,
+			Restore: &RestoreStatement{Databases: splitDatabaseNames(m[1]), Sources: sources, Until: m[3]},
+		}, nil
+	}
+	if m := checkTablePattern.FindStringSubmatch(sql); m != nil {
+		var tables []string
+		for _, name := range strings.Split(m[2], ",") {
+			tables = append(tables, strings.ReplaceAll(strings.TrimSpace(name), "`", ""))
+		}
+		if strings.EqualFold(m[1], "CHECKSUM") {
+			return &SQLStatement{Type: SQLTypeChecksum, RawSQL: sql, Checksum: &ChecksumStatement{Tables: tables}}, nil
+		}
+		return &SQLStatement{Type: SQLTypeCheck, RawSQL: sql, Check: &CheckStatement{Tables: tables}}, nil
+	}
+	if m := diffTablePattern.FindStringSubmatch(sql); m != nil {
+		var key []string
+		if m[3] != "" {
+			key = splitDatabaseNames(m[3])
+		}
+		return &SQLStatement{
+			Type:   SQLTypeDiff,
+			RawSQL: sql,
+			Diff:   &DiffStatement{Left: strings.ReplaceAll(m[1], "`", ""), Right: strings.ReplaceAll(m[2], "`", ""), Key: key},
+		}, nil
+	}
+	if m := shadowTablePattern.FindStringSubmatch(sql); m != nil {
+		return &SQLStatement{
+			Type:   SQLTypeShadow,
+			RawSQL: sql,
+			Shadow: &ShadowStatement{Action: ShadowStart, Table: strings.ReplaceAll(m[1], "`", ""),
+				Target: strings.Trim(m[2], "`"), Compare: m[3] != ""},
+		}, nil
+	}
+	if m := cutoverTablePattern.FindStringSubmatch(sql); m != nil {
+		action := ShadowCutover
+		if strings.EqualFold(m[1], "UNSHADOW") {
+			action = ShadowStop
+		}
+		return &SQLStatement{
+			Type:   SQLTypeShadow,
+			RawSQL: sql,
+			Shadow: &ShadowStatement{Action: action, Table: strings.ReplaceAll(m[2], "`", "")},
+		}, nil
+	}
+	if m := generateTablePattern.FindStringSubmatch(sql); m != nil {
+		return parseGenerateTable(sql, m)
+	}
+	if m := importDataPattern.FindStringSubmatch(sql); m != nil {
+		return parseImportData(sql, m)
+	}
+	if stmt, err := parseUserVariables(sql); stmt != nil || err != nil {
+		return stmt, err
+	}
+	return parseSelectInto(sql)
+}
+
+// splitDatabaseNames 拆分逗号分隔的库名列表，"*" 表示所有数据库，返回 nil
+func splitDatabaseNames(list string) []string {
+	if list == "*" {
+		return nil
+	}
+	var databases []string
+	for _, name := range strings.Split(list, ",") {
+		databases = append(databases, strings.Trim(strings.TrimSpace(name), "`"))
+	}
+	return databases
+}
+
+// ttlUnits TTL 简写单位到 INTERVAL 单位的映射
+var ttlUnits = map[string]string{
+	"s": "SECOND", "sec": "SECOND", "second": "SECOND", "seconds": "SECOND",
+	"m": "MINUTE", "min": "MINUTE", "minute": "MINUTE", "minutes": "MINUTE",
+	"h": "HOUR", "hour": "HOUR", "hours": "HOUR",
+	"d": "DAY", "day": "DAY", "days": "DAY",
+	"w": "WEEK", "week": "WEEK", "weeks": "WEEK",
+}
+
+// preprocessTTLClause 将 WITH TTL 子句转换为 TiDB 的 TTL 表选项
+// 例如：CREATE TABLE logs (...) WITH TTL = '7d' ON COLUMN created_at
+// 转换为：CREATE TABLE logs (...) TTL = created_at + INTERVAL 7 DAY
+func preprocessTTLClause(sql string) string {
+	if !strings.Contains(strings.ToUpper(sql), "TTL") {
+		return sql
+	}
+	// 按词法单元匹配，字符串和引号中的文本不会被当作子句
+	toks := tokenizeDialect(sql, false)
+	changed := false
+	for i := range toks {
+		if !isWord(toks[i], "WITH") {
+			continue
+		}
+		ttl := nextSignificant(toks, i+1)
+		if ttl < 0 || !isWord(toks[ttl], "TTL") {
+			continue
+		}
+		eq := nextSignificant(toks, ttl+1)
+		if eq < 0 || toks[eq].kind != tokPunct || toks[eq].text != "=" {
+			continue
+		}
+		value := nextSignificant(toks, eq+1)
+		if value < 0 || toks[value].kind != tokString {
+			continue
+		}
+		on := nextSignificant(toks, value+1)
+		if on < 0 || !isWord(toks[on], "ON") {
+			continue
+		}
+		col := nextSignificant(toks, on+1)
+		if col < 0 || !isWord(toks[col], "COLUMN") {
+			continue
+		}
+		name := nextSignificant(toks, col+1)
+		if name < 0 || (toks[name].kind != tokWord && toks[name].kind != tokBacktick) {
+			continue
+		}
+		m := ttlValuePattern.FindStringSubmatch(toks[value].text)
+		if m == nil {
+			continue
+		}
+		unit, ok := ttlUnits[strings.ToLower(m[2])]
+		if !ok {
+			// 无法识别的单位保持原样，交由解析器报错
+			continue
+		}
+		toks[i].text = "TTL = " + toks[name].text + " + INTERVAL " + m[1] + " " + unit
+		for k := i + 1; k <= name; k++ {
+			toks[k].text = ""
+		}
+		changed = true
+	}
+	if !changed {
+		return sql
+	}
+	return renderTokens(toks)
+}
+
 // ParseOneStmtText 解析 SQL 文本（去除注释和空白）
 func (p *Parser) ParseOneStmtText(sql string) (ast.StmtNode, error) {
 	// 去除首尾空白
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -274,6 +276,505 @@ func TestParseShowStmt(t *testing.T) {
 	}
 }
 
+func TestParseShowTableMemory(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SHOW TABLE MEMORY FROM `orders`;")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "TABLE_MEMORY", result.Statement.Show.Type)
+	assert.Equal(t, "orders", result.Statement.Show.Table)
+
+	result, err = adapter.Parse("show table memory")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "TABLE_MEMORY", result.Statement.Show.Type)
+	assert.Empty(t, result.Statement.Show.Table)
+}
+
+func TestParseShowDatasources(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("show datasources;")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeShow, result.Statement.Type)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "DATASOURCES", result.Statement.Show.Type)
+}
+
+func TestParseShowSchemaChanges(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SHOW SCHEMA CHANGES;")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "SCHEMA_CHANGES", result.Statement.Show.Type)
+	assert.Empty(t, result.Statement.Show.Database)
+
+	result, err = adapter.Parse("show schema changes from `plugin_db`")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "plugin_db", result.Statement.Show.Database)
+}
+
+func TestParseQualifiedJoinTables(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT u.id FROM db1.users u JOIN db2.orders o ON u.id = o.user_id LEFT JOIN items ON o.id = items.order_id")
+	require.NoError(t, err)
+	sel := result.Statement.Select
+	require.NotNil(t, sel)
+	assert.Equal(t, "db1.users", sel.From)
+	assert.Equal(t, "db1", sel.Database)
+	assert.Equal(t, "u", sel.FromAlias)
+	require.Len(t, sel.Joins, 2)
+	assert.Equal(t, JoinTypeInner, sel.Joins[0].Type)
+	assert.Equal(t, "orders", sel.Joins[0].Table)
+	assert.Equal(t, "db2", sel.Joins[0].Database)
+	assert.Equal(t, "o", sel.Joins[0].Alias)
+	assert.Equal(t, JoinTypeLeft, sel.Joins[1].Type)
+	assert.Equal(t, "items", sel.Joins[1].Table)
+	assert.Empty(t, sel.Joins[1].Database)
+}
+
+func TestParseShowPlugins(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SHOW PLUGINS")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeShow, result.Statement.Type)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "PLUGINS", result.Statement.Show.Type)
+}
+
+func TestParseTableSnapshot(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("EXPORT TABLE `orders` TO '/tmp/orders.snap';")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeExport, result.Statement.Type)
+	require.NotNil(t, result.Statement.ExportTable)
+	assert.Equal(t, "orders", result.Statement.ExportTable.Table)
+	assert.Equal(t, "/tmp/orders.snap", result.Statement.ExportTable.File)
+
+	result, err = adapter.Parse("import table from '/tmp/orders.snap'")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeImport, result.Statement.Type)
+	require.NotNil(t, result.Statement.ImportTable)
+	assert.Empty(t, result.Statement.ImportTable.Table)
+	assert.Equal(t, "/tmp/orders.snap", result.Statement.ImportTable.File)
+
+	result, err = adapter.Parse("IMPORT TABLE orders_copy FROM '/tmp/orders.snap'")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.ImportTable)
+	assert.Equal(t, "orders_copy", result.Statement.ImportTable.Table)
+}
+
+func TestParseChecksumAndCheckTable(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("CHECKSUM TABLE orders, `sales`.`items` EXTENDED;")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeChecksum, result.Statement.Type)
+	require.NotNil(t, result.Statement.Checksum)
+	assert.Equal(t, []string{"orders", "sales.items"}, result.Statement.Checksum.Tables)
+
+	result, err = adapter.Parse("check table orders quick")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeCheck, result.Statement.Type)
+	require.NotNil(t, result.Statement.Check)
+	assert.Equal(t, []string{"orders"}, result.Statement.Check.Tables)
+}
+
+func TestParseDiffTable(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("DIFF TABLE orders, `archive`.`orders`;")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeDiff, result.Statement.Type)
+	require.NotNil(t, result.Statement.Diff)
+	assert.Equal(t, "orders", result.Statement.Diff.Left)
+	assert.Equal(t, "archive.orders", result.Statement.Diff.Right)
+	assert.Empty(t, result.Statement.Diff.Key)
+
+	result, err = adapter.Parse("diff table a, b key (region, `id`)")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Diff)
+	assert.Equal(t, []string{"region", "id"}, result.Statement.Diff.Key)
+}
+
+func TestParseShadowTable(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SHADOW TABLE `shop`.orders TO `internal` WITH COMPARE;")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeShadow, result.Statement.Type)
+	assert.Equal(t, &ShadowStatement{Action: ShadowStart, Table: "shop.orders", Target: "internal", Compare: true}, result.Statement.Shadow)
+
+	result, err = adapter.Parse("shadow table orders to internal")
+	require.NoError(t, err)
+	assert.Equal(t, &ShadowStatement{Action: ShadowStart, Table: "orders", Target: "internal"}, result.Statement.Shadow)
+
+	result, err = adapter.Parse("CUTOVER TABLE orders")
+	require.NoError(t, err)
+	assert.Equal(t, &ShadowStatement{Action: ShadowCutover, Table: "orders"}, result.Statement.Shadow)
+
+	result, err = adapter.Parse("unshadow table orders;")
+	require.NoError(t, err)
+	assert.Equal(t, &ShadowStatement{Action: ShadowStop, Table: "orders"}, result.Statement.Shadow)
+
+	result, err = adapter.Parse("SHOW SHADOW TABLES")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Show)
+	assert.Equal(t, "SHADOW_TABLES", result.Statement.Show.Type)
+}
+
+func TestParseGenerateTable(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("CREATE TABLE t AS GENERATE(rows=1e6, columns=(id SEQUENCE PRIMARY KEY, name FAKER('name'), amount RANDOM(0,1000)));")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeGenerate, result.Statement.Type)
+	gen := result.Statement.GenerateTable
+	require.NotNil(t, gen)
+	assert.Equal(t, "t", gen.Table)
+	assert.EqualValues(t, 1000000, gen.Rows)
+	assert.Equal(t, []GenerateColumn{
+		{Name: "id", Generator: "SEQUENCE", Primary: true},
+		{Name: "name", Generator: "FAKER", Args: []interface{}{"name"}},
+		{Name: "amount", Generator: "RANDOM", Args: []interface{}{int64(0), int64(1000)}},
+	}, gen.Columns)
+
+	result, err = adapter.Parse("create table `demo`.`items` as generate(seed = 7, columns = (`k` sequence(100, -2), price random(0.5, 9.99), tag choice('a', 'it''s')), rows = 10)")
+	require.NoError(t, err)
+	gen = result.Statement.GenerateTable
+	require.NotNil(t, gen)
+	assert.Equal(t, "demo.items", gen.Table)
+	assert.EqualValues(t, 10, gen.Rows)
+	assert.EqualValues(t, 7, gen.Seed)
+	assert.Equal(t, []interface{}{int64(100), int64(-2)}, gen.Columns[0].Args)
+	assert.Equal(t, []interface{}{0.5, 9.99}, gen.Columns[1].Args)
+	assert.Equal(t, []interface{}{"a", "it's"}, gen.Columns[2].Args)
+
+	for _, sql := range []string{
+		"CREATE TABLE t AS GENERATE(columns=(id SEQUENCE))",
+		"CREATE TABLE t AS GENERATE(rows=0, columns=(id SEQUENCE))",
+		"CREATE TABLE t AS GENERATE(rows=1.5, columns=(id SEQUENCE))",
+		"CREATE TABLE t AS GENERATE(rows=10)",
+		"CREATE TABLE t AS GENERATE(rows=10, columns=(id SEQUENCE, id SEQUENCE))",
+		"CREATE TABLE t AS GENERATE(rows=10, size=3, columns=(id SEQUENCE))",
+		"CREATE TABLE t AS GENERATE(rows=10, columns=(id SEQUENCE(1,))",
+	} {
+		result, err := adapter.Parse(sql)
+		assert.Error(t, err, sql)
+		assert.False(t, result.Success, sql)
+	}
+}
+
+func TestParseImportData(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("IMPORT DATA INFILE '/tmp/it''s.csv' FORMAT csv INTO TABLE `demo`.`users` (id, `name`) WITH (header = false, delimiter = ';', ON_ERROR = collect, max_errors = 10);")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeImportData, result.Statement.Type)
+	imp := result.Statement.ImportData
+	require.NotNil(t, imp)
+	assert.Equal(t, "/tmp/it's.csv", imp.File)
+	assert.Equal(t, "CSV", imp.Format)
+	assert.Equal(t, "demo.users", imp.Table)
+	assert.Equal(t, []string{"id", "name"}, imp.Columns)
+	assert.Equal(t, map[string]string{"header": "false", "delimiter": ";", "on_error": "collect", "max_errors": "10"}, imp.Options)
+
+	result, err = adapter.Parse("import data infile 'rows.json' format JSON into table events")
+	require.NoError(t, err)
+	imp = result.Statement.ImportData
+	require.NotNil(t, imp)
+	assert.Equal(t, "JSON", imp.Format)
+	assert.Empty(t, imp.Columns)
+	assert.Empty(t, imp.Options)
+
+	for _, sql := range []string{
+		"IMPORT DATA INFILE 'a.xml' FORMAT XML INTO TABLE t",
+		"IMPORT DATA INFILE 'a.csv' FORMAT CSV INTO TABLE t (id, )",
+		"IMPORT DATA INFILE 'a.csv' FORMAT CSV INTO TABLE t WITH (header)",
+		"IMPORT DATA INFILE 'a.csv' FORMAT CSV INTO TABLE t WITH (header = true, header = false)",
+	} {
+		result, err := adapter.Parse(sql)
+		assert.Error(t, err, sql)
+		assert.False(t, result.Success, sql)
+	}
+}
+
+func TestParseSelectInto(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT id, name FROM users WHERE age > 18 ORDER BY id INTO OUTFILE '/tmp/it''s.csv';")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeSelectInto, result.Statement.Type)
+	into := result.Statement.SelectInto
+	require.NotNil(t, into)
+	assert.Equal(t, "SELECT id, name FROM users WHERE age > 18 ORDER BY id", into.Query)
+	assert.Equal(t, "/tmp/it's.csv", into.File)
+	assert.Equal(t, "CSV", into.Format)
+	assert.Empty(t, into.Options)
+
+	result, err = adapter.Parse("select * from t into outfile 'out.parquet' format parquet with (compression = zstd)")
+	require.NoError(t, err)
+	into = result.Statement.SelectInto
+	require.NotNil(t, into)
+	assert.Equal(t, "PARQUET", into.Format)
+	assert.Equal(t, map[string]string{"compression": "zstd"}, into.Options)
+
+	// MySQL FIELDS TERMINATED BY is the delimiter option
+	result, err = adapter.Parse("SELECT * FROM t INTO OUTFILE 'out.csv' FIELDS TERMINATED BY ';' WITH (header = false)")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"delimiter": ";", "header": "false"}, result.Statement.SelectInto.Options)
+
+	// 字符串和标识符中的 INTO OUTFILE 不是导出子句
+	result, err = adapter.Parse("SELECT 'x INTO OUTFILE ''y''' AS note, `into outfile` FROM t WHERE s = 'INTO DUMPFILE z'")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeSelect, result.Statement.Type)
+	result, err = adapter.Parse("SELECT 'a INTO OUTFILE b' FROM t INTO OUTFILE 'out.json' FORMAT JSON")
+	require.NoError(t, err)
+	into = result.Statement.SelectInto
+	require.NotNil(t, into)
+	assert.Equal(t, "SELECT 'a INTO OUTFILE b' FROM t", into.Query)
+	assert.Equal(t, "out.json", into.File)
+	assert.Equal(t, "JSON", into.Format)
+
+	for _, sql := range []string{
+		"SELECT * FROM t INTO OUTFILE 'out.xml' FORMAT XML",
+		"SELECT * FROM (SELECT * FROM t INTO OUTFILE 'a.csv') x",
+		"SELECT * FROM t INTO OUTFILE 'out.csv' LIMIT 1",
+		"SELECT * INTO OUTFILE 'out.csv' FROM t",
+		"SELECT * FROM t INTO DUMPFILE 'out.bin'",
+		"SELECT * FROM t INTO OUTFILE 'out.csv' FIELDS TERMINATED BY ';' WITH (delimiter = ',')",
+	} {
+		result, err := adapter.Parse(sql)
+		assert.Error(t, err, sql)
+		assert.False(t, result.Success, sql)
+	}
+}
+
+func TestParseUserVariables(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT name, age INTO @Name, @`user age` FROM users WHERE id = 1 LIMIT 1;")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeSetUserVar, result.Statement.Type)
+	vars := result.Statement.UserVariables
+	require.NotNil(t, vars)
+	assert.Equal(t, []string{"name", "user age"}, vars.Variables)
+	assert.Equal(t, "SELECT name, age FROM users WHERE id = 1 LIMIT 1", vars.Query)
+	assert.False(t, vars.NullIfEmpty)
+
+	result, err = adapter.Parse("SET (@a, @b) := (SELECT x, y FROM t WHERE id IN (1, 2))")
+	require.NoError(t, err)
+	vars = result.Statement.UserVariables
+	require.NotNil(t, vars)
+	assert.Equal(t, []string{"a", "b"}, vars.Variables)
+	assert.Equal(t, "SELECT x, y FROM t WHERE id IN (1, 2)", vars.Query)
+	assert.True(t, vars.NullIfEmpty)
+
+	result, err = adapter.Parse("SET @a = 1, @b := CONCAT('x', 'y')")
+	require.NoError(t, err)
+	vars = result.Statement.UserVariables
+	require.NotNil(t, vars)
+	assert.Equal(t, []string{"a", "b"}, vars.Variables)
+	assert.Equal(t, "SELECT 1, CONCAT('x', 'y')", vars.Query)
+
+	// 系统变量和 INTO OUTFILE 仍按原语句解析
+	result, err = adapter.Parse("SET NAMES utf8mb4, @@session.sql_mode = ''")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeSet, result.Statement.Type)
+	result, err = adapter.Parse("SELECT * FROM t INTO OUTFILE 'out.csv'")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeSelectInto, result.Statement.Type)
+
+	for _, sql := range []string{
+		"SET (@a, @b) := SELECT x, y FROM t",
+		"SET (@a) := (1)",
+		"SELECT x INTO @a, b FROM t",
+		"SET @a = 1, @@session.sql_mode = ''",
+	} {
+		result, err := adapter.Parse(sql)
+		assert.Error(t, err, sql)
+		assert.False(t, result.Success, sql)
+	}
+}
+
+func TestBindUserVariables(t *testing.T) {
+	values := map[string]string{"a": "1", "b": "'x'"}
+	value := func(name string) string {
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return "NULL"
+	}
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT @a, @B + 1 AS c, @missing", "SELECT 1 AS `@a`, 'x' + 1 AS c, NULL AS `@missing`"},
+		{"SELECT * FROM t WHERE id = @a AND name IN (@b, 'y')", "SELECT * FROM t WHERE id = 1 AND name IN ('x', 'y')"},
+		{"SET @a = @a + 1, @b := @a", "SET @a = 1 + 1, @b := 1"},
+		{"SET (@a, @b) := (SELECT @a, x FROM t)", "SET (@a, @b) := (SELECT 1 AS `@a`, x FROM t)"},
+		{"SELECT x INTO @a, @b FROM t WHERE y = @b", "SELECT x INTO @a, @b FROM t WHERE y = 'x'"},
+		{"SELECT @@version, '@a', `@a`", "SELECT @@version, '@a', `@a`"},
+		{"GRANT SELECT ON *.* TO 'u'@'%', u2@localhost", "GRANT SELECT ON *.* TO 'u'@'%', u2@localhost"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, BindUserVariables(tt.sql, value), tt.sql)
+	}
+}
+
+func TestParseSequenceStatements(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("CREATE SEQUENCE IF NOT EXISTS db.s START WITH 10 INCREMENT BY -2 MINVALUE 0 NOCACHE CYCLE")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeCreateSeq, result.Statement.Type)
+	seq := result.Statement.Sequence
+	require.NotNil(t, seq)
+	assert.Equal(t, []string{"db.s"}, seq.Names)
+	assert.True(t, seq.IfNotExists)
+	require.NotNil(t, seq.Options.Start)
+	assert.EqualValues(t, 10, *seq.Options.Start)
+	assert.EqualValues(t, -2, *seq.Options.Increment)
+	assert.EqualValues(t, 0, *seq.Options.MinValue)
+	assert.EqualValues(t, 0, *seq.Options.Cache)
+	assert.True(t, *seq.Options.Cycle)
+	assert.Nil(t, seq.Options.MaxValue)
+
+	result, err = adapter.Parse("ALTER SEQUENCE s NO MAXVALUE RESTART WITH 5")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeAlterSeq, result.Statement.Type)
+	seq = result.Statement.Sequence
+	assert.True(t, seq.Options.NoMaxValue)
+	assert.True(t, seq.Options.Restart)
+	assert.EqualValues(t, 5, *seq.Options.RestartWith)
+
+	result, err = adapter.Parse("DROP SEQUENCE IF EXISTS s1, db.s2")
+	require.NoError(t, err)
+	assert.Equal(t, SQLTypeDropSeq, result.Statement.Type)
+	assert.Equal(t, []string{"s1", "db.s2"}, result.Statement.Sequence.Names)
+	assert.True(t, result.Statement.Sequence.IfExists)
+
+	result, err = adapter.Parse("CREATE TABLE t (id BIGINT DEFAULT NEXT VALUE FOR s, b INT DEFAULT 1)")
+	require.NoError(t, err)
+	assert.Equal(t, "nextval(`s`)", result.Statement.Create.Columns[0].Default)
+
+	_, err = adapter.Parse("CREATE TABLE db1.t (id BIGINT DEFAULT NEXTVAL(db2.s))")
+	assert.Error(t, err)
+}
+
+func TestBindSequenceFunctions(t *testing.T) {
+	var calls []SequenceCall
+	value := func(call SequenceCall) (string, error) {
+		calls = append(calls, call)
+		return strconv.Itoa(len(calls)), nil
+	}
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT NEXTVAL(s), nextval(`db`.`s`) + 1 AS x", "SELECT 1 AS `NEXTVAL(s)`, 2 + 1 AS x"},
+		{"INSERT INTO t VALUES (NEXT VALUE FOR db.s, PREVIOUS VALUE FOR s)", "INSERT INTO t VALUES (1, 2)"},
+		{"SELECT LASTVAL(s) FROM t WHERE t.nextval(s) = 'NEXTVAL(s)'", "SELECT 1 AS `LASTVAL(s)` FROM t WHERE t.nextval(s) = 'NEXTVAL(s)'"},
+		{"CREATE TABLE t (id INT DEFAULT NEXTVAL(s))", "CREATE TABLE t (id INT DEFAULT NEXTVAL(s))"},
+		{"SELECT SETVAL(s, -5, 0, 1)", "SELECT 1 AS `SETVAL(s, -5, 0, 1)`"},
+	}
+	for _, tt := range tests {
+		calls = nil
+		got, err := BindSequenceFunctions(tt.sql, value)
+		require.NoError(t, err, tt.sql)
+		assert.Equal(t, tt.want, got, tt.sql)
+	}
+
+	calls = nil
+	_, err := BindSequenceFunctions("SELECT NEXTVAL(`db`.`s`), NEXT VALUE FOR s, SETVAL(s, 7, FALSE)", value)
+	require.NoError(t, err)
+	assert.Equal(t, []SequenceCall{
+		{Func: SequenceNextVal, Name: "db.s"},
+		{Func: SequenceNextVal, Name: "s"},
+		{Func: SequenceSetVal, Name: "s", Value: 7, Used: false},
+	}, calls)
+
+	for _, sql := range []string{"SELECT NEXTVAL(1)", "SELECT NEXTVAL(s, 1)", "SELECT SETVAL(s, x)", "SELECT SETVAL(s)"} {
+		_, err := BindSequenceFunctions(sql, value)
+		assert.Error(t, err, sql)
+	}
+}
+
+func TestBindNiladicFunction(t *testing.T) {
+	got, found := BindNiladicFunction("SELECT last_query_stats(), JSON_EXTRACT(LAST_QUERY_STATS ( ), '$.a') FROM t", LastQueryStatsFunc, "'{}'")
+	assert.True(t, found)
+	assert.Equal(t, "SELECT '{}' AS `last_query_stats()`, JSON_EXTRACT('{}', '$.a') FROM t", got)
+
+	for _, sql := range []string{
+		"SELECT 'LAST_QUERY_STATS()' FROM t",
+		"SELECT t.last_query_stats() FROM t",
+		"SELECT LAST_QUERY_STATS(1)",
+	} {
+		got, found := BindNiladicFunction(sql, LastQueryStatsFunc, "'{}'")
+		assert.False(t, found, sql)
+		assert.Equal(t, sql, got)
+	}
+}
+
+func TestParsePassthrough(t *testing.T) {
+	q, err := ParsePassthrough(`SELECT hits FROM PASSTHROUGH('my_es', '{"query":{"match":{"title":"it''s"}}}') WHERE hits > 1`)
+	require.NoError(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, []PassthroughTable{
+		{DataSource: "my_es", Query: `{"query":{"match":{"title":"it's"}}}`, Table: "__passthrough_1"},
+	}, q.Tables)
+	assert.Equal(t, "SELECT hits FROM `__passthrough_1` AS `passthrough` WHERE hits > 1", q.SQL)
+
+	q, err = ParsePassthrough(`SELECT a.id FROM PASSTHROUGH('pg', 'SELECT 1 AS id') AS a JOIN passthrough('pg', 'SELECT 2') b ON a.id = b.id`)
+	require.NoError(t, err)
+	require.Len(t, q.Tables, 2)
+	assert.Equal(t, "SELECT a.id FROM `__passthrough_1` AS a JOIN `__passthrough_2` b ON a.id = b.id", q.SQL)
+
+	// 不在 FROM 中的同名函数或字符串不是 PASSTHROUGH 表
+	q, err = ParsePassthrough(`SELECT passthrough('a', 'b'), 'FROM PASSTHROUGH(x)' FROM t`)
+	require.NoError(t, err)
+	assert.Nil(t, q)
+
+	for _, sql := range []string{
+		`SELECT * FROM PASSTHROUGH('ds')`,
+		`SELECT * FROM PASSTHROUGH(ds, 'q')`,
+		`SELECT * FROM PASSTHROUGH('ds', '')`,
+		`SELECT * FROM PASSTHROUGH('ds', 'q'`,
+	} {
+		_, err := ParsePassthrough(sql)
+		assert.Error(t, err, sql)
+	}
+}
+
+func TestParseTableFunctions(t *testing.T) {
+	calls, sql, err := ParseTableFunctions(`SELECT * FROM graph_reachable('e(a, b)', -1, NULL, 'in') r JOIN users u ON u.id = r.node`,
+		"__graph_", "GRAPH_REACHABLE")
+	require.NoError(t, err)
+	assert.Equal(t, []TableFunction{
+		{Name: "GRAPH_REACHABLE", Args: []interface{}{"e(a, b)", int64(-1), nil, "in"}, Table: "__graph_1"},
+	}, calls)
+	assert.Equal(t, "SELECT * FROM `__graph_1` r JOIN users u ON u.id = r.node", sql)
+
+	calls, sql, err = ParseTableFunctions(`SELECT * FROM t, F(1.5) WHERE x = 1`, "__f_", "F")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1.5}, calls[0].Args)
+	assert.Equal(t, "SELECT * FROM t, `__f_1` AS `f` WHERE x = 1", sql)
+
+	calls, sql, err = ParseTableFunctions(`SELECT F(1) FROM t`, "__f_", "F")
+	require.NoError(t, err)
+	assert.Nil(t, calls)
+	assert.Equal(t, `SELECT F(1) FROM t`, sql)
+
+	_, _, err = ParseTableFunctions(`SELECT * FROM F(a + 1)`, "__f_", "F")
+	assert.Error(t, err)
+}
+
 func TestParseUseStmt(t *testing.T) {
 	p := NewParser()
 
@@ -328,6 +829,23 @@ func TestParseCreateTableStmt(t *testing.T) {
 	}
 }
 
+func TestParseCreateTableUnsignedColumns(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("CREATE TABLE t (a INT(10) UNSIGNED, b TINYINT ZEROFILL, c BIGINT)")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Create)
+	cols := result.Statement.Create.Columns
+	require.Len(t, cols, 3)
+
+	assert.True(t, strings.EqualFold("int", cols[0].Type))
+	assert.True(t, cols[0].Unsigned)
+	assert.False(t, cols[0].Zerofill)
+	assert.True(t, cols[1].Unsigned)
+	assert.True(t, cols[1].Zerofill)
+	assert.False(t, cols[2].Unsigned)
+}
+
 func TestParseCreateTableStmtPersistent(t *testing.T) {
 	p := NewParser()
 	adapter := NewSQLAdapter()
@@ -382,6 +900,24 @@ func TestParseCompositeIndex(t *testing.T) {
 	assert.Equal(t, []string{"category", "brand", "price"}, result4.Statement.CreateIndex.Columns)
 }
 
+func TestParseFunctionalIndex(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("CREATE UNIQUE INDEX idx_email_lower ON users ((LOWER(email)))")
+	require.NoError(t, err)
+	stmt := result.Statement.CreateIndex
+	require.NotNil(t, stmt)
+	assert.Equal(t, "LOWER(`email`)", stmt.Expression)
+	assert.Equal(t, []string{"email"}, stmt.ExpressionColumns)
+	assert.Empty(t, stmt.Columns)
+	assert.False(t, stmt.IsVectorIndex)
+	assert.True(t, stmt.Unique)
+
+	// 多个表达式键暂不支持
+	_, err = adapter.Parse("CREATE INDEX idx_multi ON users ((LOWER(email)), (UPPER(name)))")
+	assert.Error(t, err)
+}
+
 func TestParseDropTableStmt(t *testing.T) {
 	p := NewParser()
 
@@ -639,3 +1175,19 @@ func TestSQLWithComments(t *testing.T) {
 		t.Error("Should parse SQL with comments successfully")
 	}
 }
+
+func TestPreprocessTTLClause(t *testing.T) {
+	assert.Equal(t, "CREATE TABLE logs (id INT, created_at DATETIME) TTL = created_at + INTERVAL 7 DAY",
+		preprocessTTLClause("CREATE TABLE logs (id INT, created_at DATETIME) WITH TTL = '7d' ON COLUMN created_at"))
+	assert.Equal(t, "CREATE TABLE logs (ts DATETIME) TTL = `ts` + INTERVAL 12 HOUR",
+		preprocessTTLClause("CREATE TABLE logs (ts DATETIME) with ttl = ' 12 hours ' on column `ts`"))
+
+	// 字符串中的文本保持原样
+	for _, sql := range []string{
+		`INSERT INTO notes VALUES ("WITH TTL = '7d' ON COLUMN created_at")`,
+		`INSERT INTO notes VALUES ('WITH TTL = ''7d'' ON COLUMN created_at')`,
+		"CREATE TABLE logs (ts DATETIME) WITH TTL = '7x' ON COLUMN ts",
+	} {
+		assert.Equal(t, sql, preprocessTTLClause(sql))
+	}
+}
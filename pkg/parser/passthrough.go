@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PassthroughTableAlias 未指定别名时 PASSTHROUGH 结果集的表名
+const PassthroughTableAlias = "passthrough"
+
+// PassthroughTable FROM 子句中的一个 PASSTHROUGH('数据源', '原生查询') 表
+type PassthroughTable struct {
+	DataSource string // 数据源名
+	Query      string // 原样发送给数据源的原生查询
+	Table      string // 改写后的语句中结果集的表名
+}
+
+// PassthroughQuery 含有 PASSTHROUGH 表的查询
+type PassthroughQuery struct {
+	Tables []PassthroughTable
+	SQL    string // PASSTHROUGH(...) 替换为 Tables[i].Table 后的语句
+}
+
+// ParsePassthrough 识别 FROM/JOIN 中的 PASSTHROUGH('ds', 'native query') [[AS] alias]，
+// 不含 PASSTHROUGH 表时返回 nil。每个调用替换为名为 __passthrough_N 的表并保留别名
+// （未指定时为 passthrough），原生查询的结果集写入该表后再执行改写后的语句
+func ParsePassthrough(sql string) (*PassthroughQuery, error) {
+	calls, rewritten, err := ParseTableFunctions(sql, "__passthrough_", "PASSTHROUGH")
+	if err != nil || calls == nil {
+		return nil, err
+	}
+	query := PassthroughQuery{SQL: rewritten}
+	for _, call := range calls {
+		if len(call.Args) != 2 {
+			return nil, fmt.Errorf("PASSTHROUGH: expected 2 arguments (data source, native query), got %d", len(call.Args))
+		}
+		dsName, ok := call.Args[0].(string)
+		if !ok || dsName == "" {
+			return nil, fmt.Errorf("PASSTHROUGH: the data source name must be a string literal")
+		}
+		native, ok := call.Args[1].(string)
+		if !ok || strings.TrimSpace(native) == "" {
+			return nil, fmt.Errorf("PASSTHROUGH: the native query must be a non-empty string literal")
+		}
+		query.Tables = append(query.Tables, PassthroughTable{DataSource: dsName, Query: native, Table: call.Table})
+	}
+	return &query, nil
+}
@@ -0,0 +1,268 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pivot FROM 子句中的 PIVOT / UNPIVOT：
+//
+//	source PIVOT (AGG(expr) [AS alias], ... FOR column IN (value [AS label], ...)) [[AS] alias]
+//	source UNPIVOT (value_column FOR name_column IN (column [AS label], ...)) [[AS] alias]
+//
+// source 为表名或括号内的子查询
+type Pivot struct {
+	Unpivot    bool
+	Source     string           // 源表名或子查询
+	Subquery   bool             // Source 是否为子查询
+	Aggregates []PivotAggregate // PIVOT 的聚合函数
+	Column     string           // PIVOT 按其取值转为列的列；UNPIVOT 中保存原列名（标签）的列
+	Value      string           // UNPIVOT 中保存原列值的列
+	Values     []PivotValue     // IN 列表
+	Table      string           // 改写后的语句中结果集的表名
+}
+
+// PivotAggregate PIVOT 中的一个聚合函数
+type PivotAggregate struct {
+	Func    string   // 大写的函数名
+	Arg     string   // 参数文本，COUNT(*) 为 *
+	Columns []string // 参数中引用的列名
+	Alias   string
+}
+
+// PivotValue IN 列表中的一项：PIVOT 为常量，UNPIVOT 为列名
+type PivotValue struct {
+	Value  interface{} // PIVOT 的常量：string、int64、float64
+	Column string      // UNPIVOT 的列名
+	Label  string      // 结果中的列名（PIVOT）或标签值（UNPIVOT）
+}
+
+// ColumnName PIVOT 结果中第 value 个取值、第 agg 个聚合函数对应的列名：
+// 只有一个且未指定别名的聚合函数时为取值的标签，否则为 标签_别名（别名默认为小写的函数名）
+func (p *Pivot) ColumnName(agg, value int) string {
+	a := p.Aggregates[agg]
+	if len(p.Aggregates) == 1 && a.Alias == "" {
+		return p.Values[value].Label
+	}
+	alias := a.Alias
+	if alias == "" {
+		alias = strings.ToLower(a.Func)
+	}
+	return p.Values[value].Label + "_" + alias
+}
+
+// ParsePivots 识别 FROM/JOIN 中的 PIVOT / UNPIVOT，把源与 PIVOT 子句一起替换为名为 tablePrefix + 序号 的表
+// 并保留别名（未指定时为小写的 pivot / unpivot）。不含 PIVOT / UNPIVOT 时返回 nil 与原语句
+func ParsePivots(sql, tablePrefix string) ([]Pivot, string, error) {
+	if !strings.Contains(strings.ToUpper(sql), "PIVOT") {
+		return nil, sql, nil
+	}
+	toks := tokenizeDialect(sql, false)
+	var pivots []Pivot
+	for i := 0; i < len(toks); i++ {
+		if !isWord(toks[i], "PIVOT", "UNPIVOT") {
+			continue
+		}
+		open := nextSignificant(toks, i+1)
+		if open < 0 || toks[open].text != "(" {
+			continue
+		}
+		start, source, subquery, ok := pivotSource(toks, i)
+		if !ok {
+			continue
+		}
+		keyword := strings.ToUpper(toks[i].text)
+		closing := matchingParen(toks, open)
+		if closing < 0 {
+			return nil, "", fmt.Errorf("%s: missing ')'", keyword)
+		}
+		pivot := Pivot{
+			Unpivot:  keyword == "UNPIVOT",
+			Source:   source,
+			Subquery: subquery,
+			Table:    tablePrefix + strconv.Itoa(len(pivots)+1),
+		}
+		if err := parsePivotBody(&pivot, toks[open+1:closing]); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", keyword, err)
+		}
+		pivots = append(pivots, pivot)
+
+		replacement := backtickQuote(pivot.Table)
+		if !tableFunctionHasAlias(toks, closing+1) {
+			replacement += " AS " + backtickQuote(strings.ToLower(keyword))
+		}
+		toks = spliceTokens(toks, start, closing+1, []dialectToken{{kind: tokBacktick, text: replacement}})
+		i = start
+	}
+	if len(pivots) == 0 {
+		return nil, sql, nil
+	}
+	return pivots, renderTokens(toks), nil
+}
+
+// pivotSource 找出 PIVOT 关键字（位于 i）之前的源：[db.]table 或 (subquery)，可带被忽略的别名；
+// 源必须紧跟在 FROM、JOIN 或逗号之后。返回源的起始位置与文本
+func pivotSource(toks []dialectToken, i int) (int, string, bool, bool) {
+	end := prevSignificant(toks, i-1)
+	if end < 0 {
+		return 0, "", false, false
+	}
+	// 跳过源的别名
+	if isIdentToken(toks[end]) && !isWord(toks[end], "FROM", "JOIN") {
+		if p := prevSignificant(toks, end-1); p >= 0 {
+			switch {
+			case isWord(toks[p], "AS"):
+				end = prevSignificant(toks, p-1)
+			case toks[p].text == ")" || isIdentToken(toks[p]) && !isWord(toks[p], "FROM", "JOIN"):
+				end = p
+			}
+		}
+	}
+	if end < 0 {
+		return 0, "", false, false
+	}
+
+	start, subquery := end, false
+	switch {
+	case toks[end].text == ")":
+		start, subquery = matchingOpenParen(toks, end), true
+		if start < 0 {
+			return 0, "", false, false
+		}
+	case isIdentToken(toks[end]):
+		if p := prevSignificant(toks, end-1); p >= 0 && toks[p].text == "." {
+			if q := prevSignificant(toks, p-1); q >= 0 && isIdentToken(toks[q]) {
+				start = q
+			}
+		}
+	default:
+		return 0, "", false, false
+	}
+	if p := prevSignificant(toks, start-1); p < 0 || !(isWord(toks[p], "FROM", "JOIN") || toks[p].text == ",") {
+		return 0, "", false, false
+	}
+	if subquery {
+		return start, strings.TrimSpace(renderTokens(toks[start+1 : end])), true, true
+	}
+	return start, renderTokens(toks[start : end+1]), false, true
+}
+
+// isIdentToken 判断词法单元是否可以作为标识符
+func isIdentToken(tok dialectToken) bool {
+	return tok.kind == tokWord || tok.kind == tokBacktick || tok.kind == tokQuotedIdent
+}
+
+// parsePivotBody 解析 PIVOT / UNPIVOT 括号内的 ... FOR column IN (...)
+func parsePivotBody(pivot *Pivot, body []dialectToken) error {
+	forAt, depth := -1, 0
+	for i, tok := range body {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case depth == 0 && isWord(tok, "FOR"):
+			forAt = i
+		}
+		if forAt >= 0 {
+			break
+		}
+	}
+	if forAt < 0 {
+		return fmt.Errorf("expected FOR column IN (...)")
+	}
+	rest := body[forAt+1:]
+	column := nextSignificant(rest, 0)
+	in := nextSignificant(rest, column+1)
+	if column < 0 || !isIdentToken(rest[column]) || in < 0 || !isWord(rest[in], "IN") {
+		return fmt.Errorf("expected FOR column IN (...)")
+	}
+	open := nextSignificant(rest, in+1)
+	if open < 0 || rest[open].text != "(" {
+		return fmt.Errorf("expected FOR column IN (...)")
+	}
+	closing := matchingParen(rest, open)
+	if closing < 0 || nextSignificant(rest, closing+1) >= 0 {
+		return fmt.Errorf("unexpected text after the IN list")
+	}
+	pivot.Column = aliasText(rest[column])
+
+	if pivot.Unpivot {
+		value := trimSpaceTokens(body[:forAt])
+		if len(value) != 1 || !isIdentToken(value[0]) {
+			return fmt.Errorf("expected value_column FOR name_column IN (column, ...)")
+		}
+		pivot.Value = aliasText(value[0])
+	} else {
+		for _, item := range splitTopLevel(body[:forAt], ",") {
+			agg, err := parsePivotAggregate(trimSpaceTokens(item))
+			if err != nil {
+				return err
+			}
+			pivot.Aggregates = append(pivot.Aggregates, agg)
+		}
+	}
+
+	for _, item := range splitTopLevel(rest[open+1:closing], ",") {
+		expr, label, aliased := splitSelectAlias(trimSpaceTokens(item))
+		if len(expr) == 0 {
+			return fmt.Errorf("empty item in the IN list")
+		}
+		var value PivotValue
+		if pivot.Unpivot {
+			if len(expr) != 1 || !isIdentToken(expr[0]) {
+				return fmt.Errorf("IN list item %q is not a column", renderTokens(expr))
+			}
+			value.Column = aliasText(expr[0])
+			value.Label = value.Column
+		} else {
+			v, ok := tableFunctionArg(expr)
+			if !ok || v == nil {
+				return fmt.Errorf("IN list item %q must be a constant", renderTokens(expr))
+			}
+			value.Value = v
+			value.Label = fmt.Sprint(v)
+		}
+		if aliased {
+			value.Label = label
+		}
+		pivot.Values = append(pivot.Values, value)
+	}
+	return nil
+}
+
+// parsePivotAggregate 解析 AGG(expr) [AS alias]
+func parsePivotAggregate(item []dialectToken) (PivotAggregate, error) {
+	expr, alias, _ := splitSelectAlias(item)
+	if len(expr) == 0 || expr[0].kind != tokWord {
+		return PivotAggregate{}, fmt.Errorf("expected an aggregate function, got %q", renderTokens(item))
+	}
+	args := callArgs(expr, expr[0].text)
+	if args == nil || nextSignificant(args, 0) < 0 {
+		return PivotAggregate{}, fmt.Errorf("expected an aggregate function, got %q", renderTokens(item))
+	}
+	agg := PivotAggregate{
+		Func:  strings.ToUpper(expr[0].text),
+		Arg:   strings.TrimSpace(renderTokens(args)),
+		Alias: alias,
+	}
+	for i, tok := range args {
+		if !isIdentToken(tok) || tok.kind == tokWord && pivotKeywords[strings.ToUpper(tok.text)] {
+			continue
+		}
+		// 函数名与限定名中的表名不是列
+		if next := nextSignificant(args, i+1); next >= 0 && (args[next].text == "(" || args[next].text == ".") {
+			continue
+		}
+		agg.Columns = append(agg.Columns, aliasText(tok))
+	}
+	return agg, nil
+}
+
+// pivotKeywords 聚合函数参数中不是列名的常见关键字
+var pivotKeywords = map[string]bool{
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "AND": true, "OR": true, "NOT": true,
+	"NULL": true, "IS": true, "IN": true, "LIKE": true, "BETWEEN": true, "DISTINCT": true, "TRUE": true,
+	"FALSE": true, "AS": true, "INTERVAL": true, "DIV": true, "MOD": true, "XOR": true,
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePivots(t *testing.T) {
+	pivots, sql, err := ParsePivots("SELECT * FROM sales", "__p_")
+	require.NoError(t, err)
+	assert.Nil(t, pivots)
+	assert.Equal(t, "SELECT * FROM sales", sql)
+
+	pivots, sql, err = ParsePivots("SELECT * FROM (SELECT region, quarter, amount FROM sales) s "+
+		"PIVOT (SUM(amount), COUNT(*) AS n FOR quarter IN ('Q1', 2 AS two)) AS p WHERE region = 'east'", "__p_")
+	require.NoError(t, err)
+	require.Len(t, pivots, 1)
+	assert.Equal(t, "SELECT * FROM `__p_1` AS p WHERE region = 'east'", sql)
+	pivot := pivots[0]
+	assert.False(t, pivot.Unpivot)
+	assert.True(t, pivot.Subquery)
+	assert.Equal(t, "SELECT region, quarter, amount FROM sales", pivot.Source)
+	assert.Equal(t, "quarter", pivot.Column)
+	require.Len(t, pivot.Aggregates, 2)
+	assert.Equal(t, PivotAggregate{Func: "SUM", Arg: "amount", Columns: []string{"amount"}}, pivot.Aggregates[0])
+	assert.Equal(t, PivotAggregate{Func: "COUNT", Arg: "*", Alias: "n"}, pivot.Aggregates[1])
+	assert.Equal(t, []PivotValue{{Value: "Q1", Label: "Q1"}, {Value: int64(2), Label: "two"}}, pivot.Values)
+	assert.Equal(t, "Q1_sum", pivot.ColumnName(0, 0))
+	assert.Equal(t, "two_n", pivot.ColumnName(1, 1))
+
+	pivots, sql, err = ParsePivots("SELECT * FROM db.wide UNPIVOT (amount FOR quarter IN (q1 AS 'Q1', `q2`))", "__p_")
+	require.NoError(t, err)
+	require.Len(t, pivots, 1)
+	assert.Equal(t, "SELECT * FROM `__p_1` AS `unpivot`", sql)
+	pivot = pivots[0]
+	assert.True(t, pivot.Unpivot)
+	assert.Equal(t, "db.wide", pivot.Source)
+	assert.Equal(t, "amount", pivot.Value)
+	assert.Equal(t, "quarter", pivot.Column)
+	assert.Equal(t, []PivotValue{{Column: "q1", Label: "Q1"}, {Column: "q2", Label: "q2"}}, pivot.Values)
+}
+
+func TestParsePivots_Errors(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT * FROM t PIVOT (SUM(v) quarter IN ('Q1'))",
+		"SELECT * FROM t PIVOT (SUM(v) FOR quarter IN (other_col))",
+		"SELECT * FROM t PIVOT (v FOR quarter IN ('Q1'))",
+		"SELECT * FROM t UNPIVOT (v FOR k IN ('a'))",
+		"SELECT * FROM t PIVOT (SUM(v) FOR k IN ('a')",
+	} {
+		_, _, err := ParsePivots(sql, "__p_")
+		assert.Error(t, err, sql)
+	}
+}
@@ -0,0 +1,76 @@
+package parser
+
+import "strings"
+
+// SplitReturning 剥离 INSERT/REPLACE/UPDATE/DELETE 最外层的 RETURNING 子句（MariaDB 语法），
+// 返回剩余的 SQL 与 RETURNING 的各个表达式；没有 RETURNING 时原样返回
+func SplitReturning(sql string) (string, []string) {
+	if !containsFold(sql, "RETURNING") {
+		return sql, nil
+	}
+	toks, returning := stripReturning(tokenizeDialect(sql, false))
+	if len(returning) == 0 {
+		return sql, nil
+	}
+	return renderTokens(toks), returning
+}
+
+// DeleteToSelect 把 DELETE FROM t [WHERE ...] [ORDER BY ...] [LIMIT ...] 改写为
+// 选出同一批行的 SELECT <selectList> FROM ...；多表 DELETE 等无法改写的形式返回 false
+func DeleteToSelect(sql string, selectList []string) (string, bool) {
+	toks := tokenizeDialect(sql, false)
+	first := nextSignificant(toks, 0)
+	if first < 0 || !isWord(toks[first], "DELETE") {
+		return "", false
+	}
+	from := nextSignificant(toks, first+1)
+	if from < 0 || !isWord(toks[from], "FROM") {
+		return "", false
+	}
+	return "SELECT " + strings.Join(selectList, ", ") + " " + strings.TrimSpace(renderTokens(toks[from:])), true
+}
+
+// UpdateToSelect 把 UPDATE t SET ... [WHERE ...] [ORDER BY ...] [LIMIT ...] 改写为
+// 选出同一批行的 SELECT <selectList> FROM t ...；无法识别的形式返回 false
+func UpdateToSelect(sql string, selectList []string) (string, bool) {
+	toks := tokenizeDialect(sql, false)
+	first := nextSignificant(toks, 0)
+	if first < 0 || !isWord(toks[first], "UPDATE") {
+		return "", false
+	}
+
+	set, tail := -1, len(toks)
+	depth := 0
+	for i := first + 1; i < len(toks); i++ {
+		switch {
+		case toks[i].text == "(":
+			depth++
+		case toks[i].text == ")":
+			depth--
+		case depth == 0 && set < 0 && isWord(toks[i], "SET"):
+			set = i
+		case depth == 0 && set >= 0 && isWord(toks[i], "WHERE", "ORDER", "LIMIT"):
+			tail = i
+		}
+		if tail < len(toks) {
+			break
+		}
+	}
+	if set < 0 {
+		return "", false
+	}
+	table := strings.TrimSpace(renderTokens(toks[first+1 : set]))
+	if table == "" || strings.Contains(table, ",") || containsFold(table, "JOIN") {
+		return "", false
+	}
+	selectSQL := "SELECT " + strings.Join(selectList, ", ") + " FROM " + table
+	if tail < len(toks) {
+		selectSQL += " " + strings.TrimSpace(renderTokens(toks[tail:]))
+	}
+	return selectSQL, true
+}
+
+// containsFold 不区分大小写的子串判断
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToUpper(s), substr)
+}
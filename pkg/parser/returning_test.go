@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitReturning(t *testing.T) {
+	sql, returning := SplitReturning("INSERT INTO t (a) VALUES ('returning') RETURNING id, (a + 1) AS b")
+	assert.Equal(t, "INSERT INTO t (a) VALUES ('returning')", sql)
+	assert.Equal(t, []string{"id", "(a + 1) AS b"}, returning)
+
+	sql, returning = SplitReturning("SELECT 'RETURNING x' FROM t")
+	assert.Equal(t, "SELECT 'RETURNING x' FROM t", sql)
+	assert.Nil(t, returning)
+
+	// MySQL 方言同样剥离
+	tr, err := TranslateDialect(DialectMySQL, "DELETE FROM t WHERE id = 1 RETURNING *")
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE id = 1", tr.SQL)
+	assert.Equal(t, []string{"*"}, tr.Returning)
+}
+
+func TestDeleteAndUpdateToSelect(t *testing.T) {
+	sql, ok := DeleteToSelect("DELETE FROM t WHERE a = 'x' ORDER BY id LIMIT 2", []string{"id", "a"})
+	assert.True(t, ok)
+	assert.Equal(t, "SELECT id, a FROM t WHERE a = 'x' ORDER BY id LIMIT 2", sql)
+
+	_, ok = DeleteToSelect("DELETE t1 FROM t1 JOIN t2 ON t1.id = t2.id", []string{"*"})
+	assert.False(t, ok)
+
+	sql, ok = UpdateToSelect("UPDATE db.t SET a = (SELECT 1 WHERE 1), b = 'where' WHERE id > 3 LIMIT 1", []string{"`id`"})
+	assert.True(t, ok)
+	assert.Equal(t, "SELECT `id` FROM db.t WHERE id > 3 LIMIT 1", sql)
+
+	sql, ok = UpdateToSelect("UPDATE t SET a = 1", []string{"id"})
+	assert.True(t, ok)
+	assert.Equal(t, "SELECT id FROM t", sql)
+
+	_, ok = UpdateToSelect("UPDATE t1 JOIN t2 ON t1.id = t2.id SET t1.a = 1", []string{"id"})
+	assert.False(t, ok)
+}
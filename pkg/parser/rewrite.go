@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RewriteContext 改写钩子可见的会话信息
+type RewriteContext struct {
+	User     string // 当前用户
+	Database string // 当前数据库
+}
+
+// SQLRewriter 解析前的 SQL 文本改写（如去掉厂商注释、按分片 hint 改写表名），返回改写后的 SQL
+type SQLRewriter func(rc RewriteContext, sql string) (string, error)
+
+// StatementTransformer 解析后的语句改写（如自动追加 tenant_id 条件），直接修改 stmt，返回是否做了改动
+type StatementTransformer func(rc RewriteContext, stmt *SQLStatement) (bool, error)
+
+// RewriteHook 一个查询改写钩子，PreParse 和 PostParse 至少设置一个
+type RewriteHook struct {
+	Name      string
+	PreParse  SQLRewriter
+	PostParse StatementTransformer
+}
+
+// 改写阶段
+const (
+	RewritePhasePreParse  = "pre-parse"
+	RewritePhasePostParse = "post-parse"
+)
+
+// RewriteTrace 一次生效的改写（SQL 文本或语句实际发生了变化）
+type RewriteTrace struct {
+	Hook  string `json:"hook"`
+	Phase string `json:"phase"`
+	// SQL 改写后的 SQL，仅 pre-parse 阶段记录
+	SQL string `json:"sql,omitempty"`
+}
+
+// RewriteHooks 按注册顺序执行的改写钩子集合，可在多个会话间共享
+type RewriteHooks struct {
+	mu    sync.RWMutex
+	hooks []RewriteHook
+}
+
+// NewRewriteHooks 创建空的改写钩子集合
+func NewRewriteHooks() *RewriteHooks {
+	return &RewriteHooks{}
+}
+
+// Register 在末尾追加一个钩子，名称不能为空也不能重复
+func (h *RewriteHooks) Register(hook RewriteHook) error {
+	if strings.TrimSpace(hook.Name) == "" {
+		return fmt.Errorf("rewrite hook name is empty")
+	}
+	if hook.PreParse == nil && hook.PostParse == nil {
+		return fmt.Errorf("rewrite hook %q has neither a pre-parse nor a post-parse function", hook.Name)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, existing := range h.hooks {
+		if existing.Name == hook.Name {
+			return fmt.Errorf("rewrite hook %q already registered", hook.Name)
+		}
+	}
+	h.hooks = append(h.hooks, hook)
+	return nil
+}
+
+// Unregister 移除指定名称的钩子，返回是否存在
+func (h *RewriteHooks) Unregister(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, hook := range h.hooks {
+		if hook.Name == name {
+			h.hooks = append(h.hooks[:i:i], h.hooks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Names 按执行顺序返回已注册钩子的名称
+func (h *RewriteHooks) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, len(h.hooks))
+	for i, hook := range h.hooks {
+		names[i] = hook.Name
+	}
+	return names
+}
+
+// Empty 是否没有注册任何钩子
+func (h *RewriteHooks) Empty() bool {
+	if h == nil {
+		return true
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.hooks) == 0
+}
+
+// snapshot 返回当前钩子列表的副本，执行钩子时不持有锁
+func (h *RewriteHooks) snapshot() []RewriteHook {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.hooks) == 0 {
+		return nil
+	}
+	return append([]RewriteHook(nil), h.hooks...)
+}
+
+// RewriteSQL 依次执行 pre-parse 钩子
+func (h *RewriteHooks) RewriteSQL(rc RewriteContext, sql string) (string, []RewriteTrace, error) {
+	var traces []RewriteTrace
+	for _, hook := range h.snapshot() {
+		if hook.PreParse == nil {
+			continue
+		}
+		rewritten, err := hook.PreParse(rc, sql)
+		if err != nil {
+			return "", traces, fmt.Errorf("rewrite hook %q: %w", hook.Name, err)
+		}
+		if rewritten != sql {
+			traces = append(traces, RewriteTrace{Hook: hook.Name, Phase: RewritePhasePreParse, SQL: rewritten})
+			sql = rewritten
+		}
+	}
+	return sql, traces, nil
+}
+
+// TransformStatement 依次执行 post-parse 钩子
+func (h *RewriteHooks) TransformStatement(rc RewriteContext, stmt *SQLStatement) ([]RewriteTrace, error) {
+	var traces []RewriteTrace
+	for _, hook := range h.snapshot() {
+		if hook.PostParse == nil {
+			continue
+		}
+		changed, err := hook.PostParse(rc, stmt)
+		if err != nil {
+			return traces, fmt.Errorf("rewrite hook %q: %w", hook.Name, err)
+		}
+		if changed {
+			traces = append(traces, RewriteTrace{Hook: hook.Name, Phase: RewritePhasePostParse})
+		}
+	}
+	return traces, nil
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteHooks_Order(t *testing.T) {
+	hooks := NewRewriteHooks()
+	require.NoError(t, hooks.Register(RewriteHook{
+		Name: "filter",
+		PreParse: func(_ RewriteContext, sql string) (string, error) {
+			return sql + " WHERE id = 1", nil
+		},
+	}))
+	require.NoError(t, hooks.Register(RewriteHook{
+		Name: "noop",
+		PreParse: func(_ RewriteContext, sql string) (string, error) {
+			return sql, nil
+		},
+	}))
+	require.NoError(t, hooks.Register(RewriteHook{
+		Name: "limit",
+		PostParse: func(rc RewriteContext, stmt *SQLStatement) (bool, error) {
+			if stmt.Select == nil || rc.User != "alice" {
+				return false, nil
+			}
+			limit := int64(10)
+			stmt.Select.Limit = &limit
+			return true, nil
+		},
+	}))
+	assert.Error(t, hooks.Register(RewriteHook{Name: "noop", PreParse: func(_ RewriteContext, sql string) (string, error) { return sql, nil }}))
+
+	adapter := NewSQLAdapter()
+	adapter.SetRewriteHooks(hooks, func() RewriteContext { return RewriteContext{User: "alice"} })
+
+	result, err := adapter.Parse("SELECT * FROM users")
+	require.NoError(t, err)
+	require.NotNil(t, result.Statement.Select)
+	require.NotNil(t, result.Statement.Select.Where)
+	require.NotNil(t, result.Statement.Select.Limit)
+	assert.Equal(t, int64(10), *result.Statement.Select.Limit)
+
+	// 只记录实际生效的改写
+	require.Len(t, result.Rewrites, 2)
+	assert.Equal(t, RewriteTrace{Hook: "filter", Phase: RewritePhasePreParse, SQL: "SELECT * FROM users WHERE id = 1"}, result.Rewrites[0])
+	assert.Equal(t, RewriteTrace{Hook: "limit", Phase: RewritePhasePostParse}, result.Rewrites[1])
+
+	assert.True(t, hooks.Unregister("filter"))
+	assert.False(t, hooks.Unregister("filter"))
+	assert.Equal(t, []string{"noop", "limit"}, hooks.Names())
+}
+
+func TestRewriteHooks_Error(t *testing.T) {
+	hooks := NewRewriteHooks()
+	require.NoError(t, hooks.Register(RewriteHook{
+		Name: "reject",
+		PreParse: func(_ RewriteContext, sql string) (string, error) {
+			return "", errors.New("denied")
+		},
+	}))
+
+	adapter := NewSQLAdapter()
+	adapter.SetRewriteHooks(hooks, nil)
+	result, err := adapter.Parse("SELECT 1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `rewrite hook "reject": denied`)
+	assert.False(t, result.Success)
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errIntoFileSyntax INTO OUTFILE 不在语句末尾或形式不支持时返回的错误
+var errIntoFileSyntax = fmt.Errorf("unsupported INTO OUTFILE syntax: use query INTO OUTFILE 'file' [FORMAT CSV|JSON|PARQUET] [WITH (...)] at the end of the statement")
+
+// exportFormats SELECT ... INTO OUTFILE 支持的文件格式
+var exportFormats = map[string]bool{"CSV": true, "JSON": true, "PARQUET": true}
+
+// parseSelectInto 识别以 INTO OUTFILE 子句结尾的查询，不是时返回 nil：
+//
+//	query INTO OUTFILE 'file' [FORMAT fmt] [FIELDS TERMINATED BY 'c'] [WITH (...)]
+//
+// 按词法单元匹配，字符串和标识符中的 INTO OUTFILE 不受影响；
+// 其他位置或形式的 INTO OUTFILE / INTO DUMPFILE 报错，避免查询被当作普通 SELECT 执行。
+// FIELDS TERMINATED BY 等同于 delimiter 选项
+func parseSelectInto(sql string) (*SQLStatement, error) {
+	toks := trimStatementEnd(tokenizeDialect(sql, false))
+	first := nextSignificant(toks, 0)
+	if first < 0 || !(isWord(toks[first], "SELECT", "WITH") || toks[first].text == "(") {
+		return nil, nil
+	}
+	into, depth := -1, 0
+	for i, tok := range toks {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case isWord(tok, "INTO"):
+			j := nextSignificant(toks, i+1)
+			if j < 0 || !isWord(toks[j], "OUTFILE", "DUMPFILE") {
+				continue
+			}
+			if depth != 0 || !isWord(toks[j], "OUTFILE") || into >= 0 {
+				return nil, errIntoFileSyntax
+			}
+			into = i
+		}
+	}
+	if into < 0 {
+		return nil, nil
+	}
+
+	stmt := &SelectIntoStatement{Query: strings.TrimSpace(renderTokens(toks[:into])), Format: "CSV"}
+	i := nextSignificant(toks, nextSignificant(toks, into+1)+1)
+	file, ok := intoFileString(toks, i)
+	if !ok {
+		return nil, errIntoFileSyntax
+	}
+	stmt.File = file
+	i = nextSignificant(toks, i+1)
+
+	if i >= 0 && isWord(toks[i], "FORMAT") {
+		i = nextSignificant(toks, i+1)
+		if i < 0 || toks[i].kind != tokWord {
+			return nil, errIntoFileSyntax
+		}
+		stmt.Format = strings.ToUpper(toks[i].text)
+		if !exportFormats[stmt.Format] {
+			return nil, fmt.Errorf("INTO OUTFILE: unsupported format %s (CSV, JSON, PARQUET)", toks[i].text)
+		}
+		i = nextSignificant(toks, i+1)
+	}
+
+	var delimiter string
+	hasDelimiter := false
+	if i >= 0 && isWord(toks[i], "FIELDS") {
+		for _, word := range []string{"TERMINATED", "BY"} {
+			if i = nextSignificant(toks, i+1); i < 0 || !isWord(toks[i], word) {
+				return nil, errIntoFileSyntax
+			}
+		}
+		i = nextSignificant(toks, i+1)
+		if delimiter, ok = intoFileString(toks, i); !ok {
+			return nil, errIntoFileSyntax
+		}
+		hasDelimiter = true
+		i = nextSignificant(toks, i+1)
+	}
+
+	if i >= 0 && isWord(toks[i], "WITH") {
+		open := nextSignificant(toks, i+1)
+		if open < 0 || toks[open].text != "(" {
+			return nil, errIntoFileSyntax
+		}
+		end := matchingParen(toks, open)
+		if end < 0 {
+			return nil, errIntoFileSyntax
+		}
+		p := &optionParser{tokens: tokenizeOptions(renderTokens(toks[open+1 : end]))}
+		options, err := p.parseKeyValues()
+		if err != nil {
+			return nil, fmt.Errorf("INTO OUTFILE: %w", err)
+		}
+		stmt.Options = options
+		i = nextSignificant(toks, end+1)
+	}
+	if i >= 0 {
+		return nil, errIntoFileSyntax
+	}
+
+	if hasDelimiter {
+		if _, ok := stmt.Options["delimiter"]; ok {
+			return nil, fmt.Errorf("INTO OUTFILE: both FIELDS TERMINATED BY and delimiter are given")
+		}
+		if stmt.Options == nil {
+			stmt.Options = map[string]string{}
+		}
+		stmt.Options["delimiter"] = delimiter
+	}
+	return &SQLStatement{Type: SQLTypeSelectInto, RawSQL: sql, SelectInto: stmt}, nil
+}
+
+// intoFileString 返回 toks[i] 处单引号字符串的内容，连续两个单引号还原为一个
+func intoFileString(toks []dialectToken, i int) (string, bool) {
+	if i < 0 || toks[i].kind != tokString {
+		return "", false
+	}
+	text := toks[i].text
+	if len(text) < 2 || !strings.HasSuffix(text, "'") {
+		return "", false
+	}
+	return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), true
+}
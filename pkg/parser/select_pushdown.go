@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SelectPushdownDataSource 能把整条 SELECT 翻译为自身查询语言执行的数据源（如远端 SQL 数据库）。
+//
+// 查询层在 IN (SELECT ...) 物化之后询问 CanPushDownSelect：返回 true 时由数据源执行整条语句，
+// 结果即为最终结果（投影、连接、分组、排序和分页都已完成）；否则按表读取数据，
+// 过滤条件通过 QueryOptions 下推，其余部分由查询层在本地完成
+type SelectPushdownDataSource interface {
+	// CanPushDownSelect 数据源能否完整翻译 stmt
+	CanPushDownSelect(stmt *SelectStatement) bool
+	// PushDownSelect 在数据源上执行 stmt
+	PushDownSelect(ctx context.Context, stmt *SelectStatement) (*domain.QueryResult, error)
+}
+
+// CanPushDownSelect 判断 stmt 能否整条交给 ds 执行
+func CanPushDownSelect(ds domain.DataSource, stmt *SelectStatement) bool {
+	pushdown, ok := ds.(SelectPushdownDataSource)
+	return ok && pushdown.CanPushDownSelect(stmt)
+}
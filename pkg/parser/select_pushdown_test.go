@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushdownDataSource 只接受不含 JOIN 的语句，记录收到的语句
+type pushdownDataSource struct {
+	*mockDataSource
+	pushed []*SelectStatement
+}
+
+func (d *pushdownDataSource) CanPushDownSelect(stmt *SelectStatement) bool {
+	return len(stmt.Joins) == 0
+}
+
+func (d *pushdownDataSource) PushDownSelect(ctx context.Context, stmt *SelectStatement) (*domain.QueryResult, error) {
+	d.pushed = append(d.pushed, stmt)
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{{Name: "remote"}},
+		Rows:    []domain.Row{{"remote": int64(1)}},
+		Total:   1,
+	}, nil
+}
+
+func TestExecuteSelect_PushDownSelect(t *testing.T) {
+	ds := &pushdownDataSource{mockDataSource: setupUsersAndOrders()}
+	builder := NewQueryBuilder(ds)
+
+	result, err := builder.executeSelect(context.Background(), &SelectStatement{
+		Columns: []SelectColumn{{IsWildcard: true}},
+		From:    "users",
+		Where:   parseWhere(t, "id IN (SELECT user_id FROM orders)"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Row{{"remote": int64(1)}}, result.Rows)
+
+	// 子查询先下推执行，外层语句收到物化后的 IN 列表
+	require.Len(t, ds.pushed, 2)
+	assert.Equal(t, "orders", ds.pushed[0].From)
+	assert.Equal(t, []interface{}{int64(1)}, ds.pushed[1].Where.Right.Value)
+}
+
+func TestExecuteSelect_PushDownSelectFallback(t *testing.T) {
+	ds := &pushdownDataSource{mockDataSource: setupUsersAndOrders()}
+	builder := NewQueryBuilder(ds)
+
+	result, err := builder.executeSelect(context.Background(), &SelectStatement{
+		Columns: []SelectColumn{{IsWildcard: true}},
+		From:    "users",
+		Joins: []JoinInfo{{
+			Type:      JoinTypeInner,
+			Table:     "orders",
+			Condition: parseWhere(t, "users.id = orders.user_id"),
+		}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, ds.pushed)
+	assert.Len(t, result.Rows, 5)
+}
@@ -0,0 +1,317 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	domain "github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/sequence"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+)
+
+// 序列函数名
+const (
+	SequenceNextVal = "NEXTVAL"
+	SequenceLastVal = "LASTVAL"
+	SequenceSetVal  = "SETVAL"
+)
+
+// convertSequenceOptions 转换 CREATE/ALTER SEQUENCE 的选项
+func (a *SQLAdapter) convertSequenceOptions(opts []*ast.SequenceOption) sequence.Options {
+	var out sequence.Options
+	for _, opt := range opts {
+		value := opt.IntValue
+		switch opt.Tp {
+		case ast.SequenceOptionIncrementBy:
+			out.Increment = &value
+		case ast.SequenceStartWith:
+			out.Start = &value
+		case ast.SequenceMinValue:
+			out.MinValue, out.NoMinValue = &value, false
+		case ast.SequenceNoMinValue:
+			out.MinValue, out.NoMinValue = nil, true
+		case ast.SequenceMaxValue:
+			out.MaxValue, out.NoMaxValue = &value, false
+		case ast.SequenceNoMaxValue:
+			out.MaxValue, out.NoMaxValue = nil, true
+		case ast.SequenceCache:
+			out.Cache = &value
+		case ast.SequenceNoCache:
+			zero := int64(0)
+			out.Cache = &zero
+		case ast.SequenceCycle, ast.SequenceNoCycle:
+			cycle := opt.Tp == ast.SequenceCycle
+			out.Cycle = &cycle
+		case ast.SequenceRestart:
+			out.Restart, out.RestartWith = true, nil
+		case ast.SequenceRestartWith:
+			out.Restart, out.RestartWith = true, &value
+		}
+	}
+	return out
+}
+
+// qualifiedTableName 返回 db.name 形式的表名，未指定库名时只有表名
+func qualifiedTableName(name *ast.TableName) string {
+	if name.Schema.O != "" {
+		return name.Schema.O + "." + name.Name.O
+	}
+	return name.Name.O
+}
+
+// sequenceDefault 识别列默认值 DEFAULT NEXTVAL(s) / DEFAULT NEXT VALUE FOR s，返回保存的默认值表达式。
+// 插入时在表所在的数据源中查找序列，因此序列必须与表在同一个库中
+func (a *SQLAdapter) sequenceDefault(expr ast.ExprNode, tableSchema string) (string, bool, error) {
+	fn, ok := expr.(*ast.FuncCallExpr)
+	if !ok || fn.FnName.L != "nextval" || len(fn.Args) != 1 {
+		return "", false, nil
+	}
+	seq, ok := fn.Args[0].(*ast.TableNameExpr)
+	if !ok {
+		return "", false, nil
+	}
+	if seq.Name.Schema.L != "" && seq.Name.Schema.L != strings.ToLower(tableSchema) {
+		return "", false, fmt.Errorf("DEFAULT NEXTVAL(%s): the sequence must be in the same database as the table",
+			qualifiedTableName(seq.Name))
+	}
+	return sequence.DefaultExpr(seq.Name.Name.O), true, nil
+}
+
+// SequenceCall 语句中的一次序列函数调用
+type SequenceCall struct {
+	Func  string // SequenceNextVal、SequenceLastVal 或 SequenceSetVal
+	Name  string // 序列名，可以带库名前缀，不含引号
+	Value int64  // SETVAL 设置的值
+	Used  bool   // SETVAL 的 is_used 参数，默认为 true
+}
+
+// BindSequenceFunctions 把语句中的 NEXTVAL(s)、NEXT VALUE FOR s、LASTVAL(s)、PREVIOUS VALUE FOR s
+// 和 SETVAL(s, n[, is_used[, round]]) 替换为 value 返回的字面量。每处调用在执行前求值一次，
+// 因此一条语句处理多行时同一处 NEXTVAL 对所有行取相同的值。
+// CREATE/ALTER 语句不改写，其中的 DEFAULT NEXTVAL(s) 作为列默认值保存
+func BindSequenceFunctions(sql string, value func(call SequenceCall) (string, error)) (string, error) {
+	if !strings.Contains(strings.ToUpper(sql), "VAL") {
+		return sql, nil
+	}
+	toks := tokenizeDialect(sql, false)
+	if first := nextSignificant(toks, 0); first < 0 || isWord(toks[first], "CREATE", "ALTER") {
+		return sql, nil
+	}
+
+	var sb strings.Builder
+	var list selectListTracker
+	prev := -1 // 上一个有效词法单元
+	for i := 0; i < len(toks); i++ {
+		call, end, err := sequenceCallAt(toks, i)
+		if err != nil {
+			return "", err
+		}
+		if end == 0 {
+			list.observe(toks[i])
+			if toks[i].kind != tokSpace && toks[i].kind != tokComment {
+				prev = i
+			}
+			sb.WriteString(toks[i].text)
+			continue
+		}
+		literal, err := value(call)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(literal)
+		if list.isColumn(toks, prev, nextSignificant(toks, end)) {
+			sb.WriteString(" AS " + backtickQuote(renderTokens(toks[i:end])))
+		}
+		prev = end - 1
+		i = end - 1
+	}
+	return sb.String(), nil
+}
+
+// sequenceCallAt 判断 toks[i] 是否为序列函数调用的开始，返回调用与其后的位置；不是时 end 为 0
+func sequenceCallAt(toks []dialectToken, i int) (call SequenceCall, end int, err error) {
+	tok := toks[i]
+	if tok.kind != tokWord {
+		return call, 0, nil
+	}
+	// t.nextval(...) 之类的限定名不是序列函数
+	if p := prevSignificant(toks, i-1); p >= 0 && toks[p].text == "." {
+		return call, 0, nil
+	}
+	next := nextSignificant(toks, i+1)
+	if next < 0 {
+		return call, 0, nil
+	}
+
+	// NEXT VALUE FOR s / PREVIOUS VALUE FOR s
+	if isWord(tok, "NEXT", "PREVIOUS") && isWord(toks[next], "VALUE") {
+		j := nextSignificant(toks, next+1)
+		if j < 0 || !isWord(toks[j], "FOR") {
+			return call, 0, nil
+		}
+		call.Func = SequenceNextVal
+		if isWord(tok, "PREVIOUS") {
+			call.Func = SequenceLastVal
+		}
+		name, nameEnd, ok := sequenceNameAt(toks, nextSignificant(toks, j+1))
+		if !ok {
+			return call, 0, fmt.Errorf("%s VALUE FOR: expected a sequence name", strings.ToUpper(tok.text))
+		}
+		call.Name = name
+		return call, nameEnd, nil
+	}
+
+	if !isWord(tok, SequenceNextVal, SequenceLastVal, SequenceSetVal) || toks[next].text != "(" {
+		return call, 0, nil
+	}
+	call.Func = strings.ToUpper(tok.text)
+	closing := matchingParen(toks, next)
+	if closing < 0 {
+		return call, 0, nil
+	}
+	args := splitTopLevel(toks[next+1:closing], ",")
+	name, nameEnd, ok := sequenceNameAt(args[0], nextSignificant(args[0], 0))
+	if !ok || nextSignificant(args[0], nameEnd) >= 0 {
+		return call, 0, fmt.Errorf("%s: the first argument must be a sequence name", call.Func)
+	}
+	call.Name = name
+
+	if call.Func != SequenceSetVal {
+		if len(args) != 1 {
+			return call, 0, fmt.Errorf("%s: expected 1 argument, got %d", call.Func, len(args))
+		}
+		return call, closing + 1, nil
+	}
+	if len(args) < 2 || len(args) > 4 {
+		return call, 0, fmt.Errorf("SETVAL: expected 2 to 4 arguments, got %d", len(args))
+	}
+	if call.Value, err = sequenceIntArg(args[1]); err != nil {
+		return call, 0, err
+	}
+	call.Used = true
+	if len(args) > 2 {
+		used := strings.TrimSpace(renderTokens(args[2]))
+		switch {
+		case strings.EqualFold(used, "TRUE"):
+		case strings.EqualFold(used, "FALSE"):
+			call.Used = false
+		default:
+			n, err := sequenceIntArg(args[2])
+			if err != nil {
+				return call, 0, err
+			}
+			call.Used = n != 0
+		}
+	}
+	// 第四个参数 round 对应 cycle_count，这里只检查它是整数
+	if len(args) > 3 {
+		if _, err := sequenceIntArg(args[3]); err != nil {
+			return call, 0, err
+		}
+	}
+	return call, closing + 1, nil
+}
+
+// sequenceNameAt 读取 i 处的序列名 name 或 db.name，返回不含引号的名称与其后的位置
+func sequenceNameAt(toks []dialectToken, i int) (string, int, bool) {
+	ident := func(i int) (string, bool) {
+		if i < 0 || i >= len(toks) {
+			return "", false
+		}
+		switch toks[i].kind {
+		case tokWord:
+			return toks[i].text, true
+		case tokBacktick:
+			inner := strings.TrimSuffix(toks[i].text[1:], "`")
+			return strings.ReplaceAll(inner, "``", "`"), true
+		}
+		return "", false
+	}
+	name, ok := ident(i)
+	if !ok {
+		return "", 0, false
+	}
+	if i+2 < len(toks) && toks[i+1].text == "." {
+		if table, ok := ident(i + 2); ok {
+			return name + "." + table, i + 3, true
+		}
+	}
+	return name, i + 1, true
+}
+
+// sequenceIntArg 解析 SETVAL 的整数常量参数
+func sequenceIntArg(toks []dialectToken) (int64, error) {
+	text := strings.Join(strings.Fields(renderTokens(toks)), "")
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("SETVAL: argument %q must be an integer constant", strings.TrimSpace(renderTokens(toks)))
+	}
+	return n, nil
+}
+
+// executeCreateSequence 执行 CREATE SEQUENCE
+func (b *QueryBuilder) executeCreateSequence(ctx context.Context, stmt *SequenceStatement) (*domain.QueryResult, error) {
+	if !b.dataSource.IsWritable() {
+		return nil, fmt.Errorf("data source is read-only, CREATE SEQUENCE operation not allowed")
+	}
+	name := stmt.Names[0]
+	if _, err := b.dataSource.GetTableInfo(ctx, name); err == nil && stmt.IfNotExists {
+		return &domain.QueryResult{Total: 0}, nil
+	}
+	if err := sequence.Create(ctx, b.dataSource, name, stmt.Options); err != nil {
+		return nil, err
+	}
+	return &domain.QueryResult{Total: 0}, nil
+}
+
+// executeAlterSequence 执行 ALTER SEQUENCE
+func (b *QueryBuilder) executeAlterSequence(ctx context.Context, stmt *SequenceStatement) (*domain.QueryResult, error) {
+	if !b.dataSource.IsWritable() {
+		return nil, fmt.Errorf("data source is read-only, ALTER SEQUENCE operation not allowed")
+	}
+	name := stmt.Names[0]
+	if _, err := b.dataSource.GetTableInfo(ctx, name); err != nil && stmt.IfExists {
+		return &domain.QueryResult{Total: 0}, nil
+	}
+	if err := sequence.Alter(ctx, b.dataSource, name, stmt.Options); err != nil {
+		return nil, err
+	}
+	return &domain.QueryResult{Total: 0}, nil
+}
+
+// executeDropSequence 执行 DROP SEQUENCE
+func (b *QueryBuilder) executeDropSequence(ctx context.Context, stmt *SequenceStatement) (*domain.QueryResult, error) {
+	if !b.dataSource.IsWritable() {
+		return nil, fmt.Errorf("data source is read-only, DROP SEQUENCE operation not allowed")
+	}
+	for _, name := range stmt.Names {
+		if _, err := b.dataSource.GetTableInfo(ctx, name); err != nil && stmt.IfExists {
+			continue
+		}
+		if err := sequence.Drop(ctx, b.dataSource, name); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.QueryResult{Total: 0}, nil
+}
+
+// fillSequenceDefaults 为 INSERT 没有给出的 DEFAULT NEXTVAL(s) 列取序列的下一个值
+func (b *QueryBuilder) fillSequenceDefaults(ctx context.Context, tableInfo *domain.TableInfo, row domain.Row) error {
+	for _, col := range tableInfo.Columns {
+		name, ok := sequence.DefaultName(col.Default)
+		if !ok {
+			continue
+		}
+		if _, given := row[col.Name]; given {
+			continue
+		}
+		value, err := sequence.Next(ctx, b.dataSource, name)
+		if err != nil {
+			return err
+		}
+		row[col.Name] = value
+	}
+	return nil
+}
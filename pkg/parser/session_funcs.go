@@ -0,0 +1,59 @@
+package parser
+
+import "strings"
+
+// LastQueryStatsFunc 返回本会话上一条语句资源使用统计的函数
+const LastQueryStatsFunc = "LAST_QUERY_STATS"
+
+// BindNiladicFunction 把语句中无参数的函数调用 name() 替换为字面量 literal，
+// 直接作为查询列出现的调用加上别名以保留原来的列名。返回改写后的语句与是否出现了该调用；
+// 字符串、注释和 t.name() 之类的限定名不受影响
+func BindNiladicFunction(sql, name, literal string) (string, bool) {
+	if !strings.Contains(strings.ToUpper(sql), name) {
+		return sql, false
+	}
+	toks := tokenizeDialect(sql, false)
+
+	var sb strings.Builder
+	var list selectListTracker
+	found := false
+	prev := -1 // 上一个有效词法单元
+	for i := 0; i < len(toks); i++ {
+		end := niladicCallAt(toks, i, name)
+		if end == 0 {
+			list.observe(toks[i])
+			if toks[i].kind != tokSpace && toks[i].kind != tokComment {
+				prev = i
+			}
+			sb.WriteString(toks[i].text)
+			continue
+		}
+		found = true
+		sb.WriteString(literal)
+		if list.isColumn(toks, prev, nextSignificant(toks, end)) {
+			sb.WriteString(" AS " + backtickQuote(renderTokens(toks[i:end])))
+		}
+		prev = end - 1
+		i = end - 1
+	}
+	return sb.String(), found
+}
+
+// niladicCallAt 判断 toks[i] 是否为调用 name()，返回调用之后的位置；不是时返回 0
+func niladicCallAt(toks []dialectToken, i int, name string) int {
+	if !isWord(toks[i], name) {
+		return 0
+	}
+	if p := prevSignificant(toks, i-1); p >= 0 && toks[p].text == "." {
+		return 0
+	}
+	open := nextSignificant(toks, i+1)
+	if open < 0 || toks[open].text != "(" {
+		return 0
+	}
+	closing := nextSignificant(toks, open+1)
+	if closing < 0 || toks[closing].text != ")" {
+		return 0
+	}
+	return closing + 1
+}
@@ -0,0 +1,80 @@
+package parser
+
+import "strings"
+
+// SplitStatements 按分号拆分多语句 SQL（COM_QUERY 多语句批处理）
+// 字符串、带引号的标识符和注释中的分号不作为分隔符；只有空白或注释的片段被丢弃。
+// 只做词法处理，不要求 SQL 语法正确
+func SplitStatements(sql string) []string {
+	var statements []string
+	start := 0
+	hasContent := false // 当前片段是否有注释以外的内容
+
+	flush := func(end int) {
+		if hasContent {
+			statements = append(statements, strings.TrimSpace(sql[start:end]))
+		}
+		start = end + 1
+		hasContent = false
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == ';':
+			flush(i)
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i)
+			hasContent = true
+		case c == '#' || (c == '-' && isLineCommentStart(sql, i)):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			// /*! ... */ 可执行注释按语句内容处理
+			if i+2 < len(sql) && sql[i+2] == '!' {
+				hasContent = true
+			}
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			hasContent = true
+		}
+	}
+	flush(len(sql))
+	return statements
+}
+
+// skipQuoted 返回从 i 开始的引号内容的结束引号位置；
+// 连续两个相同引号表示转义，字符串中的反斜杠转义下一个字符
+func skipQuoted(sql string, i int) int {
+	quote := sql[i]
+	for i++; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
+
+// isLineCommentStart 判断 i 处是否为 "-- " 注释（两个减号后须为空白或结尾）
+func isLineCommentStart(sql string, i int) bool {
+	if i+1 >= len(sql) || sql[i+1] != '-' {
+		return false
+	}
+	return i+2 >= len(sql) || sql[i+2] == ' ' || sql[i+2] == '\t' || sql[i+2] == '\n' || sql[i+2] == '\r'
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"single", "SELECT 1", []string{"SELECT 1"}},
+		{"trailing semicolon", "SELECT 1;", []string{"SELECT 1"}},
+		{"multiple", "SELECT 1; SELECT 2 ;\nINSERT INTO t VALUES (1)", []string{"SELECT 1", "SELECT 2", "INSERT INTO t VALUES (1)"}},
+		{"empty statements", ";; SELECT 1;;", []string{"SELECT 1"}},
+		{"semicolon in string", "SELECT 'a;b'; SELECT \"c;d\"", []string{"SELECT 'a;b'", "SELECT \"c;d\""}},
+		{"escaped quotes", `SELECT 'it''s;', 'x\';y'; SELECT 2`, []string{`SELECT 'it''s;', 'x\';y'`, "SELECT 2"}},
+		{"semicolon in identifier", "SELECT `a;b` FROM t; SELECT 2", []string{"SELECT `a;b` FROM t", "SELECT 2"}},
+		{"comments", "SELECT 1 -- one;\n; /* two; */ SELECT 2 # three;", []string{"SELECT 1 -- one;", "/* two; */ SELECT 2 # three;"}},
+		{"comment only", "SELECT 1; -- done", []string{"SELECT 1"}},
+		{"minus is not a comment", "SELECT 2--1; SELECT 3", []string{"SELECT 2--1", "SELECT 3"}},
+		{"executable comment", "/*!40101 SET NAMES utf8 */; SELECT 1", []string{"/*!40101 SET NAMES utf8 */", "SELECT 1"}},
+		{"blank", "  \n ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SplitStatements(tt.sql))
+		})
+	}
+}
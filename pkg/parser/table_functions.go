@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TableFunction FROM 子句中的一个表函数调用，如 GRAPH_REACHABLE('edges', 1, 3)
+type TableFunction struct {
+	Name  string        // 大写的函数名
+	Args  []interface{} // 常量参数：string、int64、float64 或 nil（NULL）
+	Table string        // 改写后的语句中结果集的表名
+}
+
+// ParseTableFunctions 识别 FROM/JOIN 中对 names 的调用 NAME(常量, ...) [[AS] alias]，
+// 把每个调用替换为名为 tablePrefix + 序号 的表并保留别名（未指定时为小写的函数名）。
+// 不含这些调用时返回 nil 与原语句
+func ParseTableFunctions(sql, tablePrefix string, names ...string) ([]TableFunction, string, error) {
+	upper := strings.ToUpper(sql)
+	present := false
+	for _, name := range names {
+		present = present || strings.Contains(upper, name)
+	}
+	if !present {
+		return nil, sql, nil
+	}
+
+	toks := tokenizeDialect(sql, false)
+	var calls []TableFunction
+	for i := 0; i < len(toks); i++ {
+		if !isWord(toks[i], names...) {
+			continue
+		}
+		open := nextSignificant(toks, i+1)
+		if open < 0 || toks[open].text != "(" {
+			continue
+		}
+		if p := prevSignificant(toks, i-1); p < 0 || !(isWord(toks[p], "FROM", "JOIN") || toks[p].text == ",") {
+			continue
+		}
+		name := strings.ToUpper(toks[i].text)
+		closing := matchingParen(toks, open)
+		if closing < 0 {
+			return nil, "", fmt.Errorf("%s: missing ')'", name)
+		}
+		call := TableFunction{Name: name, Table: tablePrefix + strconv.Itoa(len(calls)+1)}
+		if nextSignificant(toks[open+1:closing], 0) >= 0 {
+			for n, arg := range splitTopLevel(toks[open+1:closing], ",") {
+				value, ok := tableFunctionArg(arg)
+				if !ok {
+					return nil, "", fmt.Errorf("%s: argument %d must be a constant", name, n+1)
+				}
+				call.Args = append(call.Args, value)
+			}
+		}
+		calls = append(calls, call)
+
+		// 已有别名时只替换调用本身，否则补上默认别名
+		replacement := backtickQuote(call.Table)
+		if !tableFunctionHasAlias(toks, closing+1) {
+			replacement += " AS " + backtickQuote(strings.ToLower(name))
+		}
+		toks = spliceTokens(toks, i, closing+1, []dialectToken{{kind: tokBacktick, text: replacement}})
+	}
+	if len(calls) == 0 {
+		return nil, sql, nil
+	}
+	return calls, renderTokens(toks), nil
+}
+
+// tableFunctionHasAlias 判断表函数调用之后（从 i 开始）是否有表别名
+func tableFunctionHasAlias(toks []dialectToken, i int) bool {
+	j := nextSignificant(toks, i)
+	if j < 0 {
+		return false
+	}
+	switch toks[j].kind {
+	case tokBacktick, tokQuotedIdent:
+		return true
+	case tokWord:
+		return isWord(toks[j], "AS") || !isWord(toks[j], "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "UNION",
+			"JOIN", "INNER", "LEFT", "RIGHT", "CROSS", "NATURAL", "STRAIGHT_JOIN", "ON", "USING", "WINDOW", "FOR", "LOCK", "INTO")
+	}
+	return false
+}
+
+// tableFunctionArg 解析只含一个常量的参数：字符串、可带负号的数字或 NULL
+func tableFunctionArg(toks []dialectToken) (interface{}, bool) {
+	toks = trimSpaceTokens(toks)
+	negative := false
+	if len(toks) == 2 && toks[0].text == "-" && toks[1].kind == tokNumber {
+		negative, toks = true, toks[1:]
+	}
+	if len(toks) != 1 {
+		return nil, false
+	}
+	switch tok := toks[0]; {
+	case tok.kind == tokString:
+		return unquoteString(tok.text), true
+	case isWord(tok, "NULL"):
+		return nil, true
+	case tok.kind == tokNumber:
+		text := tok.text
+		if negative {
+			text = "-" + text
+		}
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, true
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// unquoteString 去掉字符串字面量的引号，还原连续两个单引号与反斜杠转义
+func unquoteString(text string) string {
+	inner := strings.TrimSuffix(text[1:], "'")
+	var sb strings.Builder
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case c == '\'' && i+1 < len(inner) && inner[i+1] == '\'':
+			i++
+		case c == '\\' && i+1 < len(inner):
+			i++
+			c = inner[i]
+			switch c {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'r':
+				c = '\r'
+			case '0':
+				c = 0
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package parser
+
+import "github.com/pingcap/tidb/pkg/parser/ast"
+
+// tableRefCollector 收集 AST 中所有的表名节点：FROM、JOIN、子查询、派生表、CTE、UNION 各分支以及写入的目标表
+type tableRefCollector struct {
+	tables []string
+}
+
+func (c *tableRefCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if tn, ok := n.(*ast.TableName); ok && tn.Name.O != "" {
+		name := tn.Name.O
+		if tn.Schema.O != "" {
+			name = tn.Schema.O + "." + name
+		}
+		c.tables = append(c.tables, name)
+	}
+	return n, false
+}
+
+func (c *tableRefCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// astTableRefs 返回语句引用的所有表，同一个表每出现一次记录一次
+func astTableRefs(node ast.StmtNode) []string {
+	collector := &tableRefCollector{}
+	node.Accept(collector)
+	return collector.tables
+}
+
+// extensionTableRefs 返回扩展语句（TiDB 语法之外）直接读写的表。
+// SELECT ... INTO OUTFILE 与用户变量赋值的查询在执行时重新解析，不在此列出
+func extensionTableRefs(stmt *SQLStatement) []string {
+	var tables []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if name != "" {
+				tables = append(tables, name)
+			}
+		}
+	}
+	switch {
+	case stmt.Show != nil:
+		add(stmt.Show.Table)
+	case stmt.ExportTable != nil:
+		add(stmt.ExportTable.Table)
+	case stmt.ImportTable != nil:
+		add(stmt.ImportTable.Table)
+	case stmt.Checksum != nil:
+		add(stmt.Checksum.Tables...)
+	case stmt.Check != nil:
+		add(stmt.Check.Tables...)
+	case stmt.Diff != nil:
+		add(stmt.Diff.Left, stmt.Diff.Right)
+	case stmt.Shadow != nil:
+		add(stmt.Shadow.Table)
+	case stmt.GenerateTable != nil:
+		add(stmt.GenerateTable.Table)
+	case stmt.ImportData != nil:
+		add(stmt.ImportData.Table)
+	case stmt.Undrop != nil:
+		add(stmt.Undrop.Table)
+	case stmt.Flashback != nil:
+		add(stmt.Flashback.Table)
+	}
+	return tables
+}
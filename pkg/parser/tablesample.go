@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/pingcap/tidb/pkg/parser/ast"
+)
+
+// 抽样方式
+const (
+	SampleBernoulli = "BERNOULLI" // 逐行按比例抽样
+	SampleSystem    = "SYSTEM"    // 按数据块抽样：块内的行同时保留或丢弃
+)
+
+// preprocessTableSample 将 TABLESAMPLE SYSTEM(0.1%) 中的百分号改写为 TiDB 支持的 PERCENT
+func preprocessTableSample(sql string) string {
+	if !strings.Contains(strings.ToUpper(sql), "TABLESAMPLE") {
+		return sql
+	}
+	toks := tokenizeDialect(sql, false)
+	changed := false
+	for i := range toks {
+		if !isWord(toks[i], "TABLESAMPLE") {
+			continue
+		}
+		open := nextSignificant(toks, i+1)
+		if open >= 0 && toks[open].kind == tokWord {
+			open = nextSignificant(toks, open+1) // 抽样方式
+		}
+		if open < 0 || toks[open].text != "(" {
+			continue
+		}
+		closing := matchingParen(toks, open)
+		if closing < 0 {
+			continue
+		}
+		if p := prevSignificant(toks, closing-1); p > open && toks[p].text == "%" {
+			toks[p].text = " PERCENT"
+			changed = true
+		}
+	}
+	if !changed {
+		return sql
+	}
+	return renderTokens(toks)
+}
+
+// convertTableSample 转换 TABLESAMPLE [BERNOULLI | SYSTEM] (n [PERCENT | ROWS]) [REPEATABLE(seed)]：
+// 未指定单位时为百分比，未指定抽样方式时为 SYSTEM
+func convertTableSample(sample *ast.TableSample) (*TableSample, error) {
+	result := &TableSample{Method: SampleSystem}
+	switch sample.SampleMethod {
+	case ast.SampleMethodTypeBernoulli:
+		result.Method = SampleBernoulli
+	case ast.SampleMethodTypeTiDBRegion:
+		return nil, fmt.Errorf("TABLESAMPLE REGIONS is not supported, use BERNOULLI or SYSTEM")
+	}
+
+	amount, err := sampleConstant(sample.Expr)
+	if err != nil {
+		return nil, err
+	}
+	if sample.SampleClauseUnit == ast.SampleClauseUnitTypeRow {
+		if amount < 0 || amount != float64(int64(amount)) {
+			return nil, fmt.Errorf("TABLESAMPLE: the number of rows must be a non-negative integer")
+		}
+		result.Rows = int64(amount)
+	} else {
+		if amount < 0 || amount > 100 {
+			return nil, fmt.Errorf("TABLESAMPLE: the percentage must be between 0 and 100")
+		}
+		result.Percent = amount
+	}
+
+	if sample.RepeatableSeed != nil {
+		seed, err := sampleConstant(sample.RepeatableSeed)
+		if err != nil {
+			return nil, err
+		}
+		n := int64(seed)
+		result.Seed = &n
+	}
+	return result, nil
+}
+
+// sampleConstant TABLESAMPLE 中的数值常量
+func sampleConstant(expr ast.ExprNode) (float64, error) {
+	value, ok := expr.(ast.ValueExpr)
+	if !ok {
+		return 0, fmt.Errorf("TABLESAMPLE: expected a numeric constant")
+	}
+	v, err := convertTiDBValue(value.GetValue())
+	if err != nil {
+		return 0, fmt.Errorf("TABLESAMPLE: %w", err)
+	}
+	if _, isString := v.(string); isString || v == nil {
+		return 0, fmt.Errorf("TABLESAMPLE: expected a numeric constant, got %v", v)
+	}
+	f, err := utils.ToFloat64(v)
+	if err != nil {
+		return 0, fmt.Errorf("TABLESAMPLE: %w", err)
+	}
+	return f, nil
+}
+
+// sampledTables 收集 FROM 中带 TABLESAMPLE 的表
+type sampledTables struct {
+	tables []*ast.TableName
+}
+
+func (c *sampledTables) Enter(n ast.Node) (ast.Node, bool) {
+	if tn, ok := n.(*ast.TableName); ok && tn.TableSample != nil {
+		c.tables = append(c.tables, tn)
+	}
+	return n, false
+}
+
+func (c *sampledTables) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// fromTableSample 转换 FROM 主表的 TABLESAMPLE；JOIN 的其他表不支持抽样
+func fromTableSample(from *ast.Join, main *ast.TableName) (*TableSample, error) {
+	collector := &sampledTables{}
+	from.Accept(collector)
+	for _, tn := range collector.tables {
+		if tn != main {
+			return nil, fmt.Errorf("TABLESAMPLE is only supported on the first table in FROM")
+		}
+	}
+	if main == nil || main.TableSample == nil {
+		return nil, nil
+	}
+	return convertTableSample(main.TableSample)
+}
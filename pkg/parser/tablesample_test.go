@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreprocessTableSample(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM t TABLESAMPLE SYSTEM(0.1 PERCENT)", preprocessTableSample("SELECT * FROM t TABLESAMPLE SYSTEM(0.1%)"))
+	assert.Equal(t, "SELECT * FROM t TABLESAMPLE (5 PERCENT) WHERE a % 2 = 0",
+		preprocessTableSample("SELECT * FROM t TABLESAMPLE (5%) WHERE a % 2 = 0"))
+	assert.Equal(t, "SELECT a % 2 FROM t", preprocessTableSample("SELECT a % 2 FROM t"))
+}
+
+func TestParseTableSample(t *testing.T) {
+	adapter := NewSQLAdapter()
+
+	result, err := adapter.Parse("SELECT * FROM t AS x TABLESAMPLE BERNOULLI(1.5) REPEATABLE(42) WHERE a = 1")
+	require.NoError(t, err)
+	sel := result.Statement.Select
+	assert.Equal(t, "x", sel.FromAlias)
+	require.NotNil(t, sel.Sample)
+	assert.Equal(t, SampleBernoulli, sel.Sample.Method)
+	assert.Equal(t, 1.5, sel.Sample.Percent)
+	require.NotNil(t, sel.Sample.Seed)
+	assert.EqualValues(t, 42, *sel.Sample.Seed)
+
+	result, err = adapter.Parse("SELECT * FROM t TABLESAMPLE SYSTEM(0.1%)")
+	require.NoError(t, err)
+	assert.Equal(t, &TableSample{Method: SampleSystem, Percent: 0.1}, result.Statement.Select.Sample)
+
+	result, err = adapter.Parse("SELECT * FROM t TABLESAMPLE (100 ROWS)")
+	require.NoError(t, err)
+	assert.Equal(t, &TableSample{Method: SampleSystem, Rows: 100}, result.Statement.Select.Sample)
+
+	result, err = adapter.Parse("SELECT * FROM t")
+	require.NoError(t, err)
+	assert.Nil(t, result.Statement.Select.Sample)
+
+	for _, sql := range []string{
+		"SELECT * FROM t TABLESAMPLE BERNOULLI(101)",
+		"SELECT * FROM t TABLESAMPLE (1.5 ROWS)",
+		"SELECT * FROM t TABLESAMPLE REGIONS()",
+		"SELECT * FROM t JOIN u TABLESAMPLE BERNOULLI(1) ON t.id = u.id",
+	} {
+		_, err := adapter.Parse(sql)
+		assert.Error(t, err, sql)
+	}
+}
@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 时间序列聚合函数 FIRST(value, ts) / LAST(value, ts) 的内部函数名：
+// FIRST、LAST 在 TiDB 语法中是关键字，不能直接作为函数名解析，解析前改写为这两个名称
+const (
+	FirstByFunc = "FIRST_BY"
+	LastByFunc  = "LAST_BY"
+)
+
+// 在结果集上完成的时间序列列函数
+const (
+	LOCFFunc              = "LOCF"                // 把 NULL 替换为上一行的值（last observation carried forward）
+	TimeBucketGapfillFunc = "TIME_BUCKET_GAPFILL" // 按时间桶分组并补齐缺失的时间桶
+)
+
+// IsTimeSeriesAggregate 判断函数名是否为 FIRST_BY / LAST_BY
+func IsTimeSeriesAggregate(name string) bool {
+	return strings.EqualFold(name, FirstByFunc) || strings.EqualFold(name, LastByFunc)
+}
+
+// preprocessTimeSeriesAggregates 将函数调用 FIRST(...) / LAST(...) 改写为 FIRST_BY(...) / LAST_BY(...)；
+// 字符串、注释、t.first(...) 之类的限定名以及 FETCH FIRST 不受影响
+func preprocessTimeSeriesAggregates(sql string) string {
+	upper := strings.ToUpper(sql)
+	if !strings.Contains(upper, "FIRST") && !strings.Contains(upper, "LAST") {
+		return sql
+	}
+	toks := tokenizeDialect(sql, false)
+	changed := false
+	for i := range toks {
+		if !isWord(toks[i], "FIRST", "LAST") {
+			continue
+		}
+		if open := nextSignificant(toks, i+1); open < 0 || toks[open].text != "(" {
+			continue
+		}
+		if p := prevSignificant(toks, i-1); p >= 0 && (toks[p].text == "." || isWord(toks[p], "FETCH")) {
+			continue
+		}
+		toks[i].text = strings.ToUpper(toks[i].text) + "_BY"
+		changed = true
+	}
+	if !changed {
+		return sql
+	}
+	return renderTokens(toks)
+}
+
+// TimeSeriesQuery 需要在结果集上补齐缺失的时间桶或向前填充 NULL 的查询
+type TimeSeriesQuery struct {
+	GapFill *GapFill // 补齐时间桶的列，nil 表示不补齐
+	LOCF    []string // 按行序向前填充 NULL 的结果列
+	SQL     string   // 改写后的语句
+}
+
+// GapFill TIME_BUCKET_GAPFILL(interval, ts[, start, finish]) 列：结果集按时间桶排序，
+// [start, finish) 内缺失的时间桶补为其他列为 NULL 的行；省略 start/finish 时取结果中的最小、最大时间桶
+type GapFill struct {
+	Column   string      // 结果列名
+	Interval interface{} // 时间桶宽度
+	Start    interface{}
+	Finish   interface{}
+}
+
+// ParseTimeSeries 识别最外层 SELECT 列表中的 LOCF(expr) 与 TIME_BUCKET_GAPFILL(...) 列：
+// LOCF(expr) [[AS] alias] 改写为 expr AS alias（未指定别名时为原来的列文本），
+// TIME_BUCKET_GAPFILL(interval, ts, ...) 在整条语句中改写为 TIME_BUCKET(interval, ts)。
+// 不含这些列时返回 nil
+func ParseTimeSeries(sql string) (*TimeSeriesQuery, error) {
+	upper := strings.ToUpper(sql)
+	if !strings.Contains(upper, LOCFFunc) && !strings.Contains(upper, TimeBucketGapfillFunc) {
+		return nil, nil
+	}
+	toks := tokenizeDialect(sql, false)
+	sel := nextSignificant(toks, 0)
+	if sel < 0 || !isWord(toks[sel], "SELECT") {
+		return nil, nil
+	}
+	// 列列表到顶层的 FROM 为止
+	end, depth := len(toks), 0
+	for i := sel + 1; i < len(toks) && end == len(toks); i++ {
+		switch {
+		case toks[i].text == "(":
+			depth++
+		case toks[i].text == ")":
+			depth--
+		case depth == 0 && isWord(toks[i], "FROM"):
+			end = i
+		}
+	}
+
+	var query TimeSeriesQuery
+	items := splitTopLevel(toks[sel+1:end], ",")
+	rendered := make([]string, len(items))
+	for n, item := range items {
+		rendered[n] = renderTokens(item)
+		expr, alias, aliased := splitSelectAlias(trimSpaceTokens(item))
+		if expr == nil {
+			continue
+		}
+		name := alias
+		if !aliased {
+			name = renderTokens(expr)
+		}
+		locf := false
+		if inner := callArgs(expr, LOCFFunc); inner != nil {
+			locf, expr = true, trimSpaceTokens(inner)
+			query.LOCF = append(query.LOCF, name)
+		}
+		if args := callArgs(expr, TimeBucketGapfillFunc); args != nil && query.GapFill == nil {
+			gap, err := parseGapFill(args)
+			if err != nil {
+				return nil, err
+			}
+			gap.Column = name
+			query.GapFill = gap
+		} else if !locf {
+			continue
+		}
+		rendered[n] = " " + strings.TrimSpace(renderTokens(expr)) + " AS " + backtickQuote(name) + " "
+	}
+	if query.GapFill == nil && query.LOCF == nil {
+		return nil, nil
+	}
+	query.SQL = rewriteGapFillCalls(renderTokens(toks[:sel+1]) + strings.Join(rendered, ",") + renderTokens(toks[end:]))
+	return &query, nil
+}
+
+// splitSelectAlias 把 SELECT 列拆分为表达式与别名 [AS] alias
+func splitSelectAlias(item []dialectToken) ([]dialectToken, string, bool) {
+	if len(item) == 0 {
+		return nil, "", false
+	}
+	last := len(item) - 1
+	if item[last].kind == tokPunct {
+		return item, "", false
+	}
+	p := prevSignificant(item, last-1)
+	if p < 0 || item[p].text != ")" && !isWord(item[p], "AS") {
+		return item, "", false
+	}
+	expr := item[:last]
+	if isWord(item[p], "AS") {
+		expr = item[:p]
+	}
+	return trimSpaceTokens(expr), aliasText(item[last]), true
+}
+
+// callArgs 表达式恰好是调用 name(...) 时返回括号内的词法单元，否则返回 nil
+func callArgs(expr []dialectToken, name string) []dialectToken {
+	if len(expr) == 0 || !isWord(expr[0], name) {
+		return nil
+	}
+	open := nextSignificant(expr, 1)
+	if open < 0 || expr[open].text != "(" || matchingParen(expr, open) != len(expr)-1 {
+		return nil
+	}
+	return expr[open+1 : len(expr)-1]
+}
+
+// parseGapFill 解析 TIME_BUCKET_GAPFILL 的参数，宽度与起止时间必须是常量
+func parseGapFill(args []dialectToken) (*GapFill, error) {
+	parts := splitTopLevel(args, ",")
+	if len(parts) != 2 && len(parts) != 4 {
+		return nil, fmt.Errorf("%s: expected (interval, ts) or (interval, ts, start, finish)", TimeBucketGapfillFunc)
+	}
+	var gap GapFill
+	var ok bool
+	if gap.Interval, ok = tableFunctionArg(parts[0]); !ok {
+		return nil, fmt.Errorf("%s: the interval must be a constant", TimeBucketGapfillFunc)
+	}
+	if len(parts) == 4 {
+		if gap.Start, ok = tableFunctionArg(parts[2]); !ok {
+			return nil, fmt.Errorf("%s: start must be a constant", TimeBucketGapfillFunc)
+		}
+		if gap.Finish, ok = tableFunctionArg(parts[3]); !ok {
+			return nil, fmt.Errorf("%s: finish must be a constant", TimeBucketGapfillFunc)
+		}
+	}
+	return &gap, nil
+}
+
+// rewriteGapFillCalls 把语句中所有 TIME_BUCKET_GAPFILL(interval, ts, ...) 改写为 TIME_BUCKET(interval, ts)
+func rewriteGapFillCalls(sql string) string {
+	toks := tokenizeDialect(sql, false)
+	for i := 0; i < len(toks); i++ {
+		if !isWord(toks[i], TimeBucketGapfillFunc) {
+			continue
+		}
+		open := nextSignificant(toks, i+1)
+		if open < 0 || toks[open].text != "(" {
+			continue
+		}
+		closing := matchingParen(toks, open)
+		if closing < 0 {
+			break
+		}
+		parts := splitTopLevel(toks[open+1:closing], ",")
+		if len(parts) < 2 {
+			continue
+		}
+		call := "TIME_BUCKET(" + strings.TrimSpace(renderTokens(parts[0])) + ", " + strings.TrimSpace(renderTokens(parts[1])) + ")"
+		toks = spliceTokens(toks, i, closing+1, []dialectToken{{kind: tokWord, text: call}})
+	}
+	return renderTokens(toks)
+}
+
+// aliasText 别名词法单元对应的名称
+func aliasText(tok dialectToken) string {
+	switch tok.kind {
+	case tokBacktick:
+		return strings.ReplaceAll(strings.TrimSuffix(tok.text[1:], "`"), "``", "`")
+	case tokQuotedIdent:
+		return unquoteIdent(tok.text)
+	case tokString:
+		return unquoteString(tok.text)
+	}
+	return tok.text
+}
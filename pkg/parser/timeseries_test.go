@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreprocessTimeSeriesAggregates(t *testing.T) {
+	assert.Equal(t, "SELECT FIRST_BY(v, ts), LAST_BY(v, ts) FROM m",
+		preprocessTimeSeriesAggregates("SELECT FIRST(v, ts), LAST(v, ts) FROM m"))
+	assert.Equal(t, "SELECT t.first(v), 'first(x)' FROM t FETCH FIRST (1) ROWS ONLY",
+		preprocessTimeSeriesAggregates("SELECT t.first(v), 'first(x)' FROM t FETCH FIRST (1) ROWS ONLY"))
+}
+
+func TestParseTimeSeries(t *testing.T) {
+	query, err := ParseTimeSeries("SELECT * FROM m")
+	require.NoError(t, err)
+	assert.Nil(t, query)
+
+	query, err = ParseTimeSeries("SELECT TIME_BUCKET_GAPFILL('5 minutes', ts) AS bucket, LOCF(AVG(v)) AS v, LOCF(MAX(v)) " +
+		"FROM m GROUP BY TIME_BUCKET_GAPFILL('5 minutes', ts)")
+	require.NoError(t, err)
+	require.NotNil(t, query)
+	assert.Equal(t, []string{"v", "LOCF(MAX(v))"}, query.LOCF)
+	require.NotNil(t, query.GapFill)
+	assert.Equal(t, "bucket", query.GapFill.Column)
+	assert.Equal(t, "5 minutes", query.GapFill.Interval)
+	assert.Nil(t, query.GapFill.Start)
+	assert.Equal(t, "SELECT TIME_BUCKET('5 minutes', ts) AS `bucket` , AVG(v) AS `v` , MAX(v) AS `LOCF(MAX(v))` "+
+		"FROM m GROUP BY TIME_BUCKET('5 minutes', ts)", query.SQL)
+
+	query, err = ParseTimeSeries("SELECT TIME_BUCKET_GAPFILL(60, ts, '2024-01-01', '2024-01-02') b FROM m GROUP BY b")
+	require.NoError(t, err)
+	require.NotNil(t, query.GapFill)
+	assert.Equal(t, "b", query.GapFill.Column)
+	assert.Equal(t, "2024-01-01", query.GapFill.Start)
+	assert.Equal(t, "2024-01-02", query.GapFill.Finish)
+
+	_, err = ParseTimeSeries("SELECT TIME_BUCKET_GAPFILL(60, ts, NOW(), '2024-01-02') FROM m")
+	assert.ErrorContains(t, err, "start must be a constant")
+}
@@ -2,6 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/sequence"
 )
 
 // SQLType SQL 语句类型
@@ -26,6 +29,8 @@ const (
 	SQLTypeBegin      SQLType = "BEGIN"
 	SQLTypeCommit     SQLType = "COMMIT"
 	SQLTypeRollback   SQLType = "ROLLBACK"
+	SQLTypeSavepoint  SQLType = "SAVEPOINT"
+	SQLTypeRelease    SQLType = "RELEASE SAVEPOINT"
 	SQLTypeUse        SQLType = "USE"
 	SQLTypeCreateUser SQLType = "CREATE USER"
 	SQLTypeDropUser   SQLType = "DROP USER"
@@ -33,6 +38,24 @@ const (
 	SQLTypeRevoke     SQLType = "REVOKE"
 	SQLTypeSetPasswd  SQLType = "SET PASSWORD"
 	SQLTypeSet        SQLType = "SET"
+	SQLTypeOptimize   SQLType = "OPTIMIZE"
+	SQLTypeExport     SQLType = "EXPORT TABLE"
+	SQLTypeImport     SQLType = "IMPORT TABLE"
+	SQLTypeChecksum   SQLType = "CHECKSUM TABLE"
+	SQLTypeCheck      SQLType = "CHECK TABLE"
+	SQLTypeDiff       SQLType = "DIFF TABLE"
+	SQLTypeShadow     SQLType = "SHADOW TABLE"
+	SQLTypeGenerate   SQLType = "CREATE TABLE AS GENERATE"
+	SQLTypeImportData SQLType = "IMPORT DATA"
+	SQLTypeSelectInto SQLType = "SELECT INTO OUTFILE"
+	SQLTypeUndrop     SQLType = "UNDROP TABLE"
+	SQLTypeFlashback  SQLType = "FLASHBACK TABLE"
+	SQLTypeBackup     SQLType = "BACKUP DATABASE"
+	SQLTypeRestore    SQLType = "RESTORE DATABASE"
+	SQLTypeSetUserVar SQLType = "SET USER VARIABLE"
+	SQLTypeCreateSeq  SQLType = "CREATE SEQUENCE"
+	SQLTypeAlterSeq   SQLType = "ALTER SEQUENCE"
+	SQLTypeDropSeq    SQLType = "DROP SEQUENCE"
 	SQLTypeUnknown    SQLType = "UNKNOWN"
 )
 
@@ -65,6 +88,7 @@ type SQLStatement struct {
 	Begin       *TransactionStatement `json:"begin,omitempty"`
 	Commit      *TransactionStatement `json:"commit,omitempty"`
 	Rollback    *TransactionStatement `json:"rollback,omitempty"`
+	Savepoint   *SavepointStatement   `json:"savepoint,omitempty"` // SAVEPOINT / RELEASE SAVEPOINT
 	Use         *UseStatement         `json:"use,omitempty"`
 	CreateUser  *CreateUserStatement  `json:"create_user,omitempty"`
 	DropUser    *DropUserStatement    `json:"drop_user,omitempty"`
@@ -72,21 +96,75 @@ type SQLStatement struct {
 	Revoke      *RevokeStatement      `json:"revoke,omitempty"`
 	SetPassword *SetPasswordStatement `json:"set_password,omitempty"`
 	Set         *SetStatement         `json:"set,omitempty"`
+	Optimize    *OptimizeStatement    `json:"optimize,omitempty"`
+	ExportTable *ExportTableStatement `json:"export_table,omitempty"`
+	ImportTable *ImportTableStatement `json:"import_table,omitempty"`
+	Checksum    *ChecksumStatement    `json:"checksum,omitempty"`
+	Check       *CheckStatement       `json:"check,omitempty"`
+	// Diff DIFF TABLE a, b [KEY (col[, col])]
+	Diff *DiffStatement `json:"diff,omitempty"`
+	// Shadow SHADOW TABLE t TO db [WITH COMPARE] / CUTOVER TABLE t / UNSHADOW TABLE t
+	Shadow *ShadowStatement `json:"shadow,omitempty"`
+	// GenerateTable CREATE TABLE t AS GENERATE(...)
+	GenerateTable *GenerateTableStatement `json:"generate_table,omitempty"`
+	// ImportData IMPORT DATA INFILE 'file' FORMAT CSV INTO TABLE t
+	ImportData *ImportDataStatement `json:"import_data,omitempty"`
+	// SelectInto SELECT ... INTO OUTFILE 'file' [FORMAT CSV]
+	SelectInto *SelectIntoStatement `json:"select_into,omitempty"`
+	// Undrop UNDROP TABLE t
+	Undrop *UndropTableStatement `json:"undrop,omitempty"`
+	// Flashback FLASHBACK TABLE t TO TIMESTAMP 'time'
+	Flashback *FlashbackTableStatement `json:"flashback,omitempty"`
+	// Backup BACKUP [INCREMENTAL] DATABASE {* | db[, db]} TO 'target' [FROM 'base']
+	Backup *BackupStatement `json:"backup,omitempty"`
+	// Restore RESTORE DATABASE {* | db[, db]} FROM 'full'[, 'incremental' ...] [UNTIL TIMESTAMP 'time']
+	Restore *RestoreStatement `json:"restore,omitempty"`
+	// UserVariables SELECT ... INTO @a[, @b] / SET (@a[, @b]) := (SELECT ...) / SET @a = expr
+	UserVariables *UserVariablesStatement `json:"user_variables,omitempty"`
+	// Sequence CREATE/ALTER/DROP SEQUENCE
+	Sequence *SequenceStatement `json:"sequence,omitempty"`
+	// TableRefs 语句引用的所有表（带库名时为 db.table），包括子查询、派生表、CTE 和 UNION 各分支中的表，
+	// 同一个表每出现一次记录一次，供按表做访问控制的改写钩子使用
+	TableRefs []string `json:"-"`
 }
 
 // SelectStatement SELECT 语句
 type SelectStatement struct {
 	Distinct bool           `json:"distinct"`
 	Columns  []SelectColumn `json:"columns"`
-	From     string         `json:"from"`
-	Joins    []JoinInfo     `json:"joins,omitempty"`
-	Where    *Expression    `json:"where,omitempty"`
-	GroupBy  []string       `json:"group_by,omitempty"`
-	Having   *Expression    `json:"having,omitempty"`
-	OrderBy  []OrderByItem  `json:"order_by,omitempty"`
-	Limit    *int64         `json:"limit,omitempty"`
-	Offset   *int64         `json:"offset,omitempty"`
-	Hints    string         `json:"hints,omitempty"` // Raw hints string from SQL comment
+	From     string         `json:"from"`               // 限定表名保留 db.table 形式
+	Database string         `json:"database,omitempty"` // FROM 表限定的库名（db.table）
+	// FromAlias FROM 表的别名（FROM users u）
+	FromAlias string      `json:"from_alias,omitempty"`
+	Joins     []JoinInfo  `json:"joins,omitempty"`
+	Where     *Expression `json:"where,omitempty"`
+	GroupBy   []string    `json:"group_by,omitempty"`
+	// GroupByExprs 表达式分组：GroupBy 中的分组名 -> 表达式（如 GROUP BY DATE(created_at)）
+	// 普通列分组不在其中
+	GroupByExprs map[string]*Expression `json:"group_by_exprs,omitempty"`
+	Having       *Expression            `json:"having,omitempty"`
+	OrderBy      []OrderByItem          `json:"order_by,omitempty"`
+	Limit        *int64                 `json:"limit,omitempty"`
+	Offset       *int64                 `json:"offset,omitempty"`
+	Hints        string                 `json:"hints,omitempty"` // Raw hints string from SQL comment
+	// StraightJoin SELECT STRAIGHT_JOIN 或 t1 STRAIGHT_JOIN t2：按书写顺序连接，不做 JOIN 重排
+	StraightJoin bool `json:"straight_join,omitempty"`
+	// Lock 锁定读：UPDATE（FOR UPDATE）或 SHARE（FOR SHARE / LOCK IN SHARE MODE），仅在事务内生效
+	Lock string `json:"lock,omitempty"`
+	// LockNoWait NOWAIT：锁冲突时立即返回错误而不等待
+	LockNoWait bool `json:"lock_no_wait,omitempty"`
+	// AsOf FOR SYSTEM_TIME AS OF '时间'：读取各表在该时间点的历史版本
+	AsOf string `json:"as_of,omitempty"`
+	// Sample FROM 表的 TABLESAMPLE：只读取表中的一部分行
+	Sample *TableSample `json:"sample,omitempty"`
+}
+
+// TableSample TABLESAMPLE 子句：按比例或行数抽样读取表
+type TableSample struct {
+	Method  string  `json:"method"`            // BERNOULLI 或 SYSTEM
+	Percent float64 `json:"percent,omitempty"` // 抽样比例（0-100）
+	Rows    int64   `json:"rows,omitempty"`    // n ROWS：抽样的行数，非 0 时忽略 Percent
+	Seed    *int64  `json:"seed,omitempty"`    // REPEATABLE(seed)：相同的种子得到相同的样本
 }
 
 // ValuesRef is a sentinel value used in ON DUPLICATE KEY UPDATE to reference
@@ -95,33 +173,40 @@ type SelectStatement struct {
 //	INSERT INTO t (a, b) VALUES (1, 2) ON DUPLICATE KEY UPDATE b = VALUES(b)
 //
 // The SET map for ON DUPLICATE KEY UPDATE will contain {"b": ValuesRef{Column: "b"}}.
+// Inside expressions (b = b + VALUES(b)) it is the value of an ExprTypeValue node.
 type ValuesRef struct {
 	Column string `json:"column"`
 }
 
 // InsertStatement INSERT 语句
 type InsertStatement struct {
-	Table       string           `json:"table"`
-	Columns     []string         `json:"columns,omitempty"`
-	Values      [][]interface{}  `json:"values"`
+	Table    string          `json:"table"`
+	Database string          `json:"database,omitempty"` // 限定表名中的库名（db.table）
+	Columns  []string        `json:"columns,omitempty"`
+	Values   [][]interface{} `json:"values"`
+	// OnDuplicate ON DUPLICATE KEY UPDATE 的赋值；Where 为冲突的已有行必须满足的条件，
+	// 不满足时不更新该行，语句按主键冲突报错（如多租户隔离限定只能更新本租户的行）
 	OnDuplicate *UpdateStatement `json:"on_duplicate,omitempty"`
+	Ignore      bool             `json:"ignore,omitempty"` // INSERT IGNORE：跳过与已有行冲突的行
 }
 
 // UpdateStatement UPDATE 语句
 type UpdateStatement struct {
-	Table   string                 `json:"table"`
-	Set     map[string]interface{} `json:"set"`
-	Where   *Expression            `json:"where,omitempty"`
-	OrderBy []OrderByItem          `json:"order_by,omitempty"`
-	Limit   *int64                 `json:"limit,omitempty"`
+	Table    string                 `json:"table"`
+	Database string                 `json:"database,omitempty"` // 限定表名中的库名（db.table）
+	Set      map[string]interface{} `json:"set"`
+	Where    *Expression            `json:"where,omitempty"`
+	OrderBy  []OrderByItem          `json:"order_by,omitempty"`
+	Limit    *int64                 `json:"limit,omitempty"`
 }
 
 // DeleteStatement DELETE 语句
 type DeleteStatement struct {
-	Table   string        `json:"table"`
-	Where   *Expression   `json:"where,omitempty"`
-	OrderBy []OrderByItem `json:"order_by,omitempty"`
-	Limit   *int64        `json:"limit,omitempty"`
+	Table    string        `json:"table"`
+	Database string        `json:"database,omitempty"` // 限定表名中的库名（db.table）
+	Where    *Expression   `json:"where,omitempty"`
+	OrderBy  []OrderByItem `json:"order_by,omitempty"`
+	Limit    *int64        `json:"limit,omitempty"`
 }
 
 // CreateStatement CREATE 语句
@@ -132,6 +217,28 @@ type CreateStatement struct {
 	Columns    []ColumnInfo           `json:"columns,omitempty"`
 	Options    map[string]interface{} `json:"options,omitempty"`
 	Persistent bool                   `json:"persistent,omitempty"` // PERSISTENT=1 for hybrid storage
+	Partition  *PartitionInfo         `json:"partition,omitempty"`  // PARTITION BY 子句
+	TTL        *TTLInfo               `json:"ttl,omitempty"`        // TTL = col + INTERVAL n UNIT
+}
+
+// TTLInfo 表过期策略（TTL 表选项）
+type TTLInfo struct {
+	Column   string        `json:"column"`
+	Duration time.Duration `json:"duration"`
+}
+
+// PartitionInfo 分区定义（PARTITION BY RANGE/HASH）
+type PartitionInfo struct {
+	Type       string         `json:"type"`   // RANGE, HASH
+	Column     string         `json:"column"` // 分区键
+	Partitions []PartitionDef `json:"partitions,omitempty"`
+}
+
+// PartitionDef 单个分区定义
+type PartitionDef struct {
+	Name     string      `json:"name"`
+	LessThan interface{} `json:"less_than,omitempty"` // VALUES LESS THAN 的上界
+	MaxValue bool        `json:"max_value,omitempty"` // VALUES LESS THAN MAXVALUE
 }
 
 // DropStatement DROP 语句
@@ -142,6 +249,145 @@ type DropStatement struct {
 	IfExists bool     `json:"if_exists"`
 }
 
+// OptimizeStatement OPTIMIZE TABLE 语句
+type OptimizeStatement struct {
+	Tables []string `json:"tables"`
+}
+
+// ExportTableStatement EXPORT TABLE t TO 'file' 语句：将表导出为二进制快照文件
+type ExportTableStatement struct {
+	Table string `json:"table"`
+	File  string `json:"file"`
+}
+
+// ImportTableStatement IMPORT TABLE [t] FROM 'file' 语句：从快照文件创建表
+// Table 为空时使用快照中的表名
+type ImportTableStatement struct {
+	Table string `json:"table,omitempty"`
+	File  string `json:"file"`
+}
+
+// ChecksumStatement CHECKSUM TABLE 语句：计算表数据的校验和
+type ChecksumStatement struct {
+	Tables []string `json:"tables"`
+}
+
+// CheckStatement CHECK TABLE 语句：检查表的内部一致性
+type CheckStatement struct {
+	Tables []string `json:"tables"`
+}
+
+// DiffStatement DIFF TABLE a, b [KEY (col[, col])] 语句：按主键比较两个表的数据，
+// 表名可以带库名前缀以比较不同数据源中的表；Key 为空时使用左表的主键
+type DiffStatement struct {
+	Left  string   `json:"left"`
+	Right string   `json:"right"`
+	Key   []string `json:"key,omitempty"`
+}
+
+// 影子迁移操作
+const (
+	ShadowStart   = "START"
+	ShadowCutover = "CUTOVER"
+	ShadowStop    = "STOP"
+)
+
+// ShadowStatement SHADOW TABLE t TO db [WITH COMPARE]、CUTOVER TABLE t、UNSHADOW TABLE t 语句：
+// 开始、切换、结束表到数据源 Target 的影子迁移；Table 可以带库名前缀指定表原来所在的数据库
+type ShadowStatement struct {
+	Action  string `json:"action"`
+	Table   string `json:"table"`
+	Target  string `json:"target,omitempty"`
+	Compare bool   `json:"compare,omitempty"`
+}
+
+// GenerateTableStatement CREATE TABLE t AS GENERATE(rows=N, columns=(...)) 语句：
+// 创建表并写入 Rows 行生成的数据，相同 Seed 生成相同的数据
+type GenerateTableStatement struct {
+	Table   string           `json:"table"`
+	Rows    int64            `json:"rows"`
+	Seed    int64            `json:"seed"`
+	Columns []GenerateColumn `json:"columns"`
+}
+
+// GenerateColumn 生成列：Generator 为大写的生成器名称（SEQUENCE、RANDOM、FAKER、CHOICE），
+// Args 为 int64、float64 或 string 类型的参数
+type GenerateColumn struct {
+	Name      string        `json:"name"`
+	Generator string        `json:"generator"`
+	Args      []interface{} `json:"args,omitempty"`
+	Primary   bool          `json:"primary,omitempty"`
+}
+
+// ImportDataStatement IMPORT DATA INFILE 'file' FORMAT CSV|JSON|PARQUET INTO TABLE t [(c1, ...)] [WITH (...)] 语句：
+// 把文件导入表，表不存在时推断表结构并建表；Columns 按顺序替换文件的字段名，
+// Options 为 WITH 中的选项（名称为小写），由 dataimport.ParseOptions 校验
+type ImportDataStatement struct {
+	File    string            `json:"file"`
+	Format  string            `json:"format"`
+	Table   string            `json:"table"`
+	Columns []string          `json:"columns,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// SelectIntoStatement SELECT ... INTO OUTFILE 'file' [FORMAT CSV|JSON|PARQUET] [WITH (...)] 语句：
+// 把查询结果写入服务器上的文件；Query 为去掉 INTO OUTFILE 子句的查询，
+// Options 为 WITH 中的选项（名称为小写），由 dataexport.ParseOptions 校验
+type SelectIntoStatement struct {
+	Query   string            `json:"query"`
+	File    string            `json:"file"`
+	Format  string            `json:"format"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// UserVariablesStatement 为会话的用户变量赋值的语句：Query 必须最多返回一行，
+// 各列依次赋给 Variables（小写、不含 @）。NullIfEmpty 为 true（SET 形式）时查询没有结果则变量置为 NULL，
+// 否则（SELECT ... INTO）变量保持原值
+type UserVariablesStatement struct {
+	Variables   []string `json:"variables"`
+	Query       string   `json:"query"`
+	NullIfEmpty bool     `json:"null_if_empty,omitempty"`
+}
+
+// SequenceStatement CREATE/ALTER/DROP SEQUENCE 语句，序列名可以带库名前缀；
+// 只有 DROP SEQUENCE 可以同时指定多个序列
+type SequenceStatement struct {
+	Names       []string         `json:"names"`
+	IfNotExists bool             `json:"if_not_exists,omitempty"`
+	IfExists    bool             `json:"if_exists,omitempty"`
+	Options     sequence.Options `json:"options"`
+}
+
+// UndropTableStatement UNDROP TABLE t 语句：从回收站恢复最近删除的同名表
+type UndropTableStatement struct {
+	Table string `json:"table"`
+}
+
+// FlashbackTableStatement FLASHBACK TABLE t TO TIMESTAMP 'time' 语句：
+// 按变更日志撤销表在 Timestamp 之后提交的 DML，Timestamp 由 ParseTimestamp 解析
+type FlashbackTableStatement struct {
+	Table     string `json:"table"`
+	Timestamp string `json:"timestamp"`
+}
+
+// BackupStatement BACKUP [INCREMENTAL] DATABASE {* | db[, db]} TO 'target' [FROM 'base'] 语句：
+// 在写入继续的同时对数据库做一致性在线备份，Databases 为 nil 表示所有数据库，Target 为文件路径或 s3://bucket/key。
+// 增量备份只包含备份文件 Base 之后提交的变更
+type BackupStatement struct {
+	Databases   []string `json:"databases,omitempty"`
+	Target      string   `json:"target"`
+	Incremental bool     `json:"incremental,omitempty"`
+	Base        string   `json:"base,omitempty"`
+}
+
+// RestoreStatement RESTORE DATABASE {* | db[, db]} FROM 'full'[, 'incremental' ...] [UNTIL TIMESTAMP 'time'] 语句：
+// 从全量备份恢复表并依次重放增量备份中的变更，Until 不为空时只重放该时间之前提交的变更，由 ParseTimestamp 解析
+type RestoreStatement struct {
+	Databases []string `json:"databases,omitempty"`
+	Sources   []string `json:"sources"`
+	Until     string   `json:"until,omitempty"`
+}
+
 // AlterStatement ALTER 语句
 type AlterStatement struct {
 	Type    string        `json:"type"` // TABLE, etc.
@@ -151,12 +397,27 @@ type AlterStatement struct {
 
 // AlterAction ALTER 操作
 type AlterAction struct {
-	Type    string      `json:"type"` // ADD, DROP, MODIFY, CHANGE, etc.
+	Type    string      `json:"type"` // ADD, DROP, MODIFY, CHANGE, ADD PARTITION, DROP PARTITION, etc.
 	Column  *ColumnInfo `json:"column,omitempty"`
 	OldName string      `json:"old_name,omitempty"`
 	NewName string      `json:"new_name,omitempty"`
+
+	// 分区管理（ADD PARTITION / DROP PARTITION）
+	Partitions     []PartitionDef `json:"partitions,omitempty"`      // ADD PARTITION (PARTITION p VALUES LESS THAN ...)
+	PartitionNum   int            `json:"partition_num,omitempty"`   // ADD PARTITION PARTITIONS n（HASH）
+	PartitionNames []string       `json:"partition_names,omitempty"` // DROP PARTITION p1, p2
+
+	TTL *TTLInfo `json:"ttl,omitempty"` // SET TTL
 }
 
+// 分区管理的 ALTER 操作类型
+const (
+	AlterActionAddPartition  = "ADD PARTITION"
+	AlterActionDropPartition = "DROP PARTITION"
+	AlterActionSetTTL        = "SET TTL"
+	AlterActionRemoveTTL     = "REMOVE TTL"
+)
+
 // CreateIndexStatement CREATE INDEX 语句
 type CreateIndexStatement struct {
 	IndexName string   `json:"index_name"`
@@ -166,6 +427,10 @@ type CreateIndexStatement struct {
 	Unique    bool     `json:"unique"`
 	IfExists  bool     `json:"if_exists"`
 
+	// 函数索引：索引键由表达式计算，如 CREATE INDEX idx ON t ((LOWER(email)))
+	Expression        string   `json:"expression,omitempty"`         // 索引表达式（SQL 文本），此时 Columns 为空
+	ExpressionColumns []string `json:"expression_columns,omitempty"` // 表达式引用的列
+
 	// Vector Index 配置
 	IsVectorIndex   bool                   `json:"is_vector_index,omitempty"`
 	VectorIndexType string                 `json:"vector_index_type,omitempty"` // hnsw, ivf_flat, flat
@@ -187,7 +452,10 @@ type ShowStatement struct {
 	Table string `json:"table,omitempty"`
 	Where string `json:"where,omitempty"`
 	Like  string `json:"like,omitempty"`
-	Full  bool   `json:"full,omitempty"` // SHOW FULL PROCESSLIST
+	Full  bool   `json:"full,omitempty"`  // SHOW FULL PROCESSLIST
+	Count bool   `json:"count,omitempty"` // SHOW COUNT(*) WARNINGS / SHOW COUNT(*) ERRORS
+	// Database SHOW SCHEMA CHANGES FROM db 限定的数据库
+	Database string `json:"database,omitempty"`
 }
 
 // DescribeStatement DESCRIBE 语句
@@ -222,6 +490,7 @@ type SelectColumn struct {
 type JoinInfo struct {
 	Type      JoinType    `json:"type"`
 	Table     string      `json:"table"`
+	Database  string      `json:"database,omitempty"` // 限定表名中的库名（db.table），为空时使用当前数据库
 	Alias     string      `json:"alias,omitempty"`
 	Condition *Expression `json:"condition,omitempty"`
 }
@@ -239,20 +508,40 @@ const (
 
 // TransactionStatement 事务语句
 type TransactionStatement struct {
-	Level string `json:"level,omitempty"` // 隔离级别：READ UNCOMMITTED, READ COMMITTED, REPEATABLE READ, SERIALIZABLE
+	Level     string `json:"level,omitempty"`     // 隔离级别：READ UNCOMMITTED, READ COMMITTED, REPEATABLE READ, SERIALIZABLE
+	Savepoint string `json:"savepoint,omitempty"` // ROLLBACK TO SAVEPOINT 的保存点名称
+}
+
+// SavepointStatement 保存点语句
+type SavepointStatement struct {
+	Name string `json:"name"`
 }
 
 // Expression 表达式
 type Expression struct {
-	Type     ExprType     `json:"type"`
-	Column   string       `json:"column,omitempty"`
-	Value    interface{}  `json:"value,omitempty"`
-	Operator string       `json:"operator,omitempty"`
-	Left     *Expression  `json:"left,omitempty"`
-	Right    *Expression  `json:"right,omitempty"`
-	Args     []Expression `json:"args,omitempty"`
-	Function string       `json:"function,omitempty"`
-}
+	Type     ExprType      `json:"type"`
+	Column   string        `json:"column,omitempty"`
+	Value    interface{}   `json:"value,omitempty"`
+	Operator string        `json:"operator,omitempty"`
+	Left     *Expression   `json:"left,omitempty"`
+	Right    *Expression   `json:"right,omitempty"`
+	Args     []Expression  `json:"args,omitempty"`
+	Function string        `json:"function,omitempty"`
+	Distinct bool          `json:"distinct,omitempty"` // 聚合函数的 DISTINCT，如 COUNT(DISTINCT a)
+	OrderBy  []OrderByItem `json:"order_by,omitempty"` // 聚合函数内的 ORDER BY，如 GROUP_CONCAT(a ORDER BY a)
+	// LIKE ... ESCAPE 指定的转义字符，为空时使用默认的反斜杠
+	Escape string `json:"escape,omitempty"`
+	// COLLATE 子句指定的排序规则，如 name LIKE 'a%' COLLATE utf8mb4_general_ci
+	Collation string `json:"collation,omitempty"`
+	// IN (SELECT ...) 的子查询，执行前由 MaterializeSubqueries 物化为值列表
+	Subquery *SelectStatement `json:"subquery,omitempty"`
+}
+
+// CaseFunc CASE 表达式转换成的函数名，参数为 cond1, result1, ..., condN, resultN[, else]
+const CaseFunc = "CASE"
+
+// CastFunc CAST / CONVERT(x, type) / BINARY x 转换成的函数名，参数为被转换的值和目标类型文本，如 'DECIMAL(10,2)'
+const CastFunc = "CAST"
 
 // ExprType 表达式类型
 type ExprType string
@@ -263,13 +552,15 @@ const (
 	ExprTypeOperator ExprType = "OPERATOR"
 	ExprTypeFunction ExprType = "FUNCTION"
 	ExprTypeList     ExprType = "LIST"
+	ExprTypeSubquery ExprType = "SUBQUERY"
 )
 
 // OrderByItem 排序项
 type OrderByItem struct {
-	Column    string `json:"column"`
-	Direction string `json:"direction"`           // ASC, DESC
-	Collation string `json:"collation,omitempty"` // COLLATE clause (optional)
+	Column    string      `json:"column"`
+	Direction string      `json:"direction"`           // ASC, DESC
+	Collation string      `json:"collation,omitempty"` // COLLATE clause (optional)
+	Expr      *Expression `json:"expr,omitempty"`      // 聚合函数排序项（如 ORDER BY COUNT(*)），由优化器解析为聚合输出列
 }
 
 // ForeignKeyInfo 外键信息
@@ -289,6 +580,8 @@ type ColumnInfo struct {
 	Default    interface{}     `json:"default,omitempty"`
 	Unique     bool            `json:"unique,omitempty"`
 	AutoInc    bool            `json:"auto_increment,omitempty"`
+	Unsigned   bool            `json:"unsigned,omitempty"`
+	Zerofill   bool            `json:"zerofill,omitempty"`
 	ForeignKey *ForeignKeyInfo `json:"foreign_key,omitempty"`
 	Comment    string          `json:"comment,omitempty"`
 
@@ -301,6 +594,9 @@ type ColumnInfo struct {
 	// Vector Columns 支持
 	VectorDim  int    `json:"vector_dim,omitempty"`  // 向量维度
 	VectorType string `json:"vector_type,omitempty"` // 向量类型（如 "float32"）
+
+	// ENCRYPTED 列属性
+	Encryption *EncryptionInfo `json:"encryption,omitempty"`
 }
 
 // IsVectorType 检查是否为向量类型
@@ -354,9 +650,10 @@ type SetStatement struct {
 
 // ParseResult 解析结果
 type ParseResult struct {
-	Statement *SQLStatement `json:"statement"`
-	Success   bool          `json:"success"`
-	Error     string        `json:"error,omitempty"`
+	Statement *SQLStatement  `json:"statement"`
+	Success   bool           `json:"success"`
+	Error     string         `json:"error,omitempty"`
+	Rewrites  []RewriteTrace `json:"rewrites,omitempty"` // 生效的查询改写钩子，按执行顺序
 }
 
 // ParserError 解析错误
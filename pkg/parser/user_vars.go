@@ -0,0 +1,309 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseUserVariables 识别为用户变量赋值的语句，不是时返回 nil：
+//
+//	SELECT expr[, ...] INTO @a[, @b] [FROM ...]
+//	SET (@a[, @b]) {:= | =} (SELECT ...)
+//	SET @a {:= | =} expr[, @b {:= | =} expr]
+//
+// 同时设置系统变量的 SET 语句（如 SET @a = 1, NAMES utf8mb4）仍交给 TiDB 解析器
+func parseUserVariables(sql string) (*SQLStatement, error) {
+	if !strings.Contains(sql, "@") {
+		return nil, nil
+	}
+	toks := trimStatementEnd(tokenizeDialect(sql, false))
+	first := nextSignificant(toks, 0)
+	if first < 0 {
+		return nil, nil
+	}
+	var stmt *UserVariablesStatement
+	var err error
+	switch {
+	case isWord(toks[first], "SET"):
+		stmt, err = parseSetUserVariables(toks, first+1)
+	case isWord(toks[first], "SELECT", "WITH"):
+		stmt, err = parseSelectIntoVariables(toks)
+	}
+	if stmt == nil || err != nil {
+		return nil, err
+	}
+	return &SQLStatement{Type: SQLTypeSetUserVar, RawSQL: sql, UserVariables: stmt}, nil
+}
+
+// parseSetUserVariables 解析 SET 之后（从 i 开始）的用户变量赋值
+func parseSetUserVariables(toks []dialectToken, i int) (*UserVariablesStatement, error) {
+	i = nextSignificant(toks, i)
+	if i < 0 {
+		return nil, nil
+	}
+	if toks[i].text == "(" {
+		return parseSetUserVariableTuple(toks, i)
+	}
+
+	stmt := &UserVariablesStatement{NullIfEmpty: true}
+	var exprs []string
+	items := splitTopLevel(toks[i:], ",")
+	for _, item := range items {
+		j := nextSignificant(item, 0)
+		name, end, ok := "", 0, j >= 0
+		if ok {
+			name, end, ok = userVariableAt(item, j)
+		}
+		if ok {
+			j = nextSignificant(item, end)
+			ok = j >= 0 && (item[j].text == "=" || item[j].text == ":=")
+		}
+		if !ok {
+			// 系统变量、SET NAMES 等交给 TiDB 解析器；用户变量的值是表达式，不能与它们写在同一条语句中
+			if hasUserVariableTarget(items) {
+				return nil, fmt.Errorf("SET: user variables cannot be assigned together with system variables")
+			}
+			return nil, nil
+		}
+		expr := strings.TrimSpace(renderTokens(item[j+1:]))
+		if expr == "" {
+			return nil, fmt.Errorf("SET @%s: missing value", name)
+		}
+		stmt.Variables = append(stmt.Variables, name)
+		exprs = append(exprs, expr)
+	}
+	stmt.Query = "SELECT " + strings.Join(exprs, ", ")
+	return stmt, nil
+}
+
+// hasUserVariableTarget 判断 SET 的赋值项中是否有用户变量
+func hasUserVariableTarget(items [][]dialectToken) bool {
+	for _, item := range items {
+		if j := nextSignificant(item, 0); j >= 0 {
+			if _, _, ok := userVariableAt(item, j); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseSetUserVariableTuple 解析 SET (@a, @b) := (query)，open 为变量列表的左括号
+func parseSetUserVariableTuple(toks []dialectToken, open int) (*UserVariablesStatement, error) {
+	stmt := &UserVariablesStatement{NullIfEmpty: true}
+	i := nextSignificant(toks, open+1)
+	for i >= 0 {
+		name, end, ok := userVariableAt(toks, i)
+		if !ok {
+			return nil, nil
+		}
+		stmt.Variables = append(stmt.Variables, name)
+		i = nextSignificant(toks, end)
+		if i < 0 || toks[i].text != "," {
+			break
+		}
+		i = nextSignificant(toks, i+1)
+	}
+	if i < 0 || toks[i].text != ")" {
+		return nil, nil
+	}
+	i = nextSignificant(toks, i+1)
+	if i < 0 || (toks[i].text != "=" && toks[i].text != ":=") {
+		return nil, fmt.Errorf("SET (@var, ...): expected := (query)")
+	}
+	i = nextSignificant(toks, i+1)
+	if i < 0 || toks[i].text != "(" || matchingParen(toks, i) != len(toks)-1 {
+		return nil, fmt.Errorf("SET (@var, ...) := (query): the query must be enclosed in parentheses")
+	}
+	stmt.Query = strings.TrimSpace(renderTokens(toks[i+1 : len(toks)-1]))
+	if q := nextSignificant(toks, i+1); q < 0 || !isWord(toks[q], "SELECT", "WITH") {
+		return nil, fmt.Errorf("SET (@var, ...) := (query): the query must be a SELECT statement")
+	}
+	return stmt, nil
+}
+
+// parseSelectIntoVariables 解析最外层带 INTO @var 列表的查询，Query 为去掉 INTO 子句的查询
+func parseSelectIntoVariables(toks []dialectToken) (*UserVariablesStatement, error) {
+	depth := 0
+	for i, tok := range toks {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case depth == 0 && isWord(tok, "INTO"):
+			j := nextSignificant(toks, i+1)
+			if j < 0 {
+				return nil, nil
+			}
+			if _, _, ok := userVariableAt(toks, j); !ok {
+				// INTO OUTFILE 等
+				return nil, nil
+			}
+			stmt := &UserVariablesStatement{}
+			end := j
+			for j >= 0 {
+				name, next, ok := userVariableAt(toks, j)
+				if !ok {
+					return nil, fmt.Errorf("INTO: expected a user variable after ','")
+				}
+				stmt.Variables = append(stmt.Variables, name)
+				end = next
+				j = nextSignificant(toks, next)
+				if j < 0 || toks[j].text != "," {
+					break
+				}
+				j = nextSignificant(toks, j+1)
+			}
+			start := i
+			if start > 0 && toks[start-1].kind == tokSpace {
+				start--
+			}
+			rest := append(append([]dialectToken(nil), toks[:start]...), toks[end:]...)
+			stmt.Query = strings.TrimSpace(renderTokens(rest))
+			return stmt, nil
+		}
+	}
+	return nil, nil
+}
+
+// BindUserVariables 把语句中引用的用户变量 @name 替换为 value 返回的字面量，
+// 赋值目标（SET @a = ...、SET (@a, @b) := ...、INTO @a）保持不变。
+// 直接作为查询列出现的变量加上别名，结果列名仍为 @name
+func BindUserVariables(sql string, value func(name string) string) string {
+	if !strings.Contains(sql, "@") {
+		return sql
+	}
+	toks := tokenizeDialect(sql, false)
+	first := nextSignificant(toks, 0)
+	setStmt := first >= 0 && isWord(toks[first], "SET")
+	// SET (@a, @b) := ... 的变量列表
+	tupleEnd := -1
+	if setStmt {
+		if i := nextSignificant(toks, first+1); i >= 0 && toks[i].text == "(" {
+			tupleEnd = matchingParen(toks, i)
+		}
+	}
+
+	var sb strings.Builder
+	var list selectListTracker
+	into := false
+	prev := -1 // 上一个有效词法单元
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		name, end, ok := userVariableAt(toks, i)
+		if !ok {
+			list.observe(tok)
+			if tok.kind != tokSpace && tok.kind != tokComment {
+				into = isWord(tok, "INTO") || (into && tok.text == ",")
+				prev = i
+			}
+			sb.WriteString(tok.text)
+			continue
+		}
+
+		next := nextSignificant(toks, end)
+		target := into ||
+			(setStmt && i < tupleEnd) ||
+			(setStmt && list.depth == 0 && next >= 0 && (toks[next].text == "=" || toks[next].text == ":="))
+		if target {
+			sb.WriteString(renderTokens(toks[i:end]))
+		} else {
+			sb.WriteString(value(name))
+			if list.isColumn(toks, prev, next) {
+				sb.WriteString(" AS " + backtickQuote(renderTokens(toks[i:end])))
+			}
+		}
+		into = into && target
+		prev = end - 1
+		i = end - 1
+	}
+	return sb.String()
+}
+
+// selectListTracker 逐个观察词法单元，跟踪当前位置是否位于 SELECT 的列列表中
+type selectListTracker struct {
+	depth      int
+	selectList []bool // 每层括号是否位于 SELECT 的列列表中
+}
+
+func (t *selectListTracker) observe(tok dialectToken) {
+	if t.selectList == nil {
+		t.selectList = []bool{false}
+	}
+	switch {
+	case tok.text == "(":
+		t.depth++
+		t.selectList = append(t.selectList, false)
+	case tok.text == ")" && t.depth > 0:
+		t.depth--
+		t.selectList = t.selectList[:len(t.selectList)-1]
+	case isWord(tok, "SELECT"):
+		t.selectList[t.depth] = true
+	case isWord(tok, "FROM", "INTO", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "UNION", "WINDOW"):
+		t.selectList[t.depth] = false
+	}
+}
+
+// isColumn 判断位于有效词法单元 prev 与 next 之间的表达式是否单独构成一个查询列，
+// 这样的表达式被替换为字面量后需要加上别名以保留原来的列名
+func (t *selectListTracker) isColumn(toks []dialectToken, prev, next int) bool {
+	if t.selectList == nil || !t.selectList[t.depth] {
+		return false
+	}
+	return prev >= 0 && (toks[prev].text == "," || isWord(toks[prev], "SELECT", "DISTINCT")) &&
+		(next < 0 || toks[next].text == "," || toks[next].text == ";" || toks[next].text == ")" ||
+			isWord(toks[next], "FROM", "INTO", "UNION"))
+}
+
+// userVariableAt 判断 toks[i] 是否为用户变量引用 @name 或 @`name`，返回小写的变量名与变量之后的位置。
+// @@name 是系统变量；紧跟在字符串或标识符之后的 @ 属于账户名（'user'@'host'）
+func userVariableAt(toks []dialectToken, i int) (name string, end int, ok bool) {
+	if toks[i].text != "@" || i+1 >= len(toks) {
+		return "", 0, false
+	}
+	if i > 0 {
+		switch prev := toks[i-1]; {
+		case prev.text == "@", prev.kind == tokWord, prev.kind == tokString, prev.kind == tokBacktick, prev.kind == tokQuotedIdent:
+			return "", 0, false
+		}
+	}
+	switch next := toks[i+1]; next.kind {
+	case tokWord:
+		return strings.ToLower(next.text), i + 2, true
+	case tokBacktick:
+		inner := strings.TrimSuffix(next.text[1:], "`")
+		return strings.ToLower(strings.ReplaceAll(inner, "``", "`")), i + 2, true
+	}
+	return "", 0, false
+}
+
+// splitTopLevel 按括号外的分隔符 sep 切分词法单元
+func splitTopLevel(toks []dialectToken, sep string) [][]dialectToken {
+	var parts [][]dialectToken
+	depth, start := 0, 0
+	for i, tok := range toks {
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			depth--
+		case depth == 0 && tok.text == sep:
+			parts = append(parts, toks[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, toks[start:])
+}
+
+// trimStatementEnd 去掉语句末尾的空白、注释和分号
+func trimStatementEnd(toks []dialectToken) []dialectToken {
+	for len(toks) > 0 {
+		last := toks[len(toks)-1]
+		if last.kind != tokSpace && last.kind != tokComment && last.text != ";" {
+			break
+		}
+		toks = toks[:len(toks)-1]
+	}
+	return toks
+}
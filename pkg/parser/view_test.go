@@ -100,7 +100,7 @@ func TestBuildSelectSQL(t *testing.T) {
 	}
 
 	sql := builder.buildSelectSQL(stmt)
-	expected := "SELECT id, name FROM users"
+	expected := "SELECT `id`, `name` FROM `users`"
 	if sql != expected {
 		t.Errorf("buildSelectSQL = %q, want %q", sql, expected)
 	}
@@ -114,7 +114,7 @@ func TestBuildSelectSQL(t *testing.T) {
 	}
 
 	sql = builder.buildSelectSQL(stmt)
-	expected = "SELECT id, name FROM users WHERE active"
+	expected = "SELECT `id`, `name` FROM `users` WHERE `active`"
 	if sql != expected {
 		t.Errorf("buildSelectSQL with WHERE = %q, want %q", sql, expected)
 	}
@@ -126,7 +126,7 @@ func TestBuildSelectSQL(t *testing.T) {
 	}
 
 	sql = builder.buildSelectSQL(stmt)
-	expected = "SELECT id, name FROM users ORDER BY id DESC"
+	expected = "SELECT `id`, `name` FROM `users` ORDER BY `id` DESC"
 	if sql != expected {
 		t.Errorf("buildSelectSQL with ORDER BY = %q, want %q", sql, expected)
 	}
@@ -136,7 +136,7 @@ func TestBuildSelectSQL(t *testing.T) {
 	stmt.Limit = &limit
 
 	sql = builder.buildSelectSQL(stmt)
-	expected = "SELECT id, name FROM users ORDER BY id DESC LIMIT 10"
+	expected = "SELECT `id`, `name` FROM `users` ORDER BY `id` DESC LIMIT 10"
 	if sql != expected {
 		t.Errorf("buildSelectSQL with LIMIT = %q, want %q", sql, expected)
 	}
@@ -147,7 +147,7 @@ func TestBuildSelectSQL(t *testing.T) {
 	stmt.Distinct = true
 
 	sql = builder.buildSelectSQL(stmt)
-	expected = "SELECT DISTINCT id, name FROM users"
+	expected = "SELECT DISTINCT `id`, `name` FROM `users`"
 	if sql != expected {
 		t.Errorf("buildSelectSQL with DISTINCT = %q, want %q", sql, expected)
 	}
@@ -163,7 +163,7 @@ func TestBuildExpressionSQL(t *testing.T) {
 	}
 
 	sql := builder.buildExpressionSQL(expr)
-	expected := "id"
+	expected := "`id`"
 	if sql != expected {
 		t.Errorf("buildExpressionSQL(column) = %q, want %q", sql, expected)
 	}
@@ -195,12 +195,39 @@ func TestBuildExpressionSQL(t *testing.T) {
 	}
 
 	sql = builder.buildExpressionSQL(expr)
-	expected = "(active) AND (1)"
+	expected = "(`active`) AND (1)"
 	if sql != expected {
 		t.Errorf("buildExpressionSQL(operator) = %q, want %q", sql, expected)
 	}
 }
 
+func TestBuildSelectSQL_QuotesIdentifiersAndLiterals(t *testing.T) {
+	adapter := NewSQLAdapter()
+	result, err := adapter.Parse("CREATE VIEW v AS SELECT `a``b`, name AS `select` FROM `my table` WHERE name = 'x'' OR ''1''=''1' AND age >= 18")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	builder := &QueryBuilder{dataSource: nil}
+	sql := builder.buildSelectSQL(result.Statement.CreateView.Select)
+	expected := "SELECT `a``b`, `name` AS `select` FROM `my table` WHERE (`name` = 'x\\' OR \\'1\\'=\\'1') AND (`age` >= 18)"
+	if sql != expected {
+		t.Fatalf("buildSelectSQL = %q, want %q", sql, expected)
+	}
+
+	// The stored definition must re-parse to the same statement
+	reparsed, err := adapter.Parse(sql)
+	if err != nil || !reparsed.Success {
+		t.Fatalf("reparse of %q failed: %v", sql, err)
+	}
+	if reparsed.Statement.Select.From != "my table" {
+		t.Errorf("reparsed FROM = %q, want %q", reparsed.Statement.Select.From, "my table")
+	}
+	if got := reparsed.Statement.Select.Where.Left.Right.Value; got != "x' OR '1'='1" {
+		t.Errorf("reparsed literal = %v, want %q", got, "x' OR '1'='1")
+	}
+}
+
 func TestGetViewInfo(t *testing.T) {
 	builder := &QueryBuilder{dataSource: nil}
 
@@ -53,6 +53,15 @@ func (v *SQLVisitor) Enter(n ast.Node) (ast.Node, bool) {
 	case *ast.TableName:
 		// 提取表名
 		v.info.Tables = append(v.info.Tables, node.Name.String())
+		if node.Schema.String() != "" {
+			v.info.Databases = append(v.info.Databases, node.Schema.String())
+		}
+	case *ast.UseStmt:
+		v.info.Databases = append(v.info.Databases, node.DBName)
+	case *ast.ShowStmt:
+		if node.DBName != "" {
+			v.info.Databases = append(v.info.Databases, node.DBName)
+		}
 	case *ast.ColumnName:
 		// 提取列名
 		if node.Name.String() != "" {
@@ -66,8 +75,14 @@ func (v *SQLVisitor) Enter(n ast.Node) (ast.Node, bool) {
 		v.info.IsUpdate = true
 	case *ast.DeleteStmt:
 		v.info.IsDelete = true
-	case *ast.CreateTableStmt, *ast.DropTableStmt, *ast.CreateDatabaseStmt, *ast.DropDatabaseStmt:
+	case *ast.CreateTableStmt, *ast.DropTableStmt:
+		v.info.IsDDL = true
+	case *ast.CreateDatabaseStmt:
+		v.info.IsDDL = true
+		v.info.Databases = append(v.info.Databases, node.Name.String())
+	case *ast.DropDatabaseStmt:
 		v.info.IsDDL = true
+		v.info.Databases = append(v.info.Databases, node.Name.String())
 	}
 	return n, false
 }
@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// CapabilitySchemaChanges is the capability a plugin declares in PluginGetInfo
+// when it implements the "get_changes" method
+const CapabilitySchemaChanges = "get_changes"
+
+// SchemaChangesResult is the result payload of a "get_changes" request
+type SchemaChangesResult struct {
+	Changes []domain.SchemaChange `json:"changes"`
+	Version int64                 `json:"version"`
+}
+
+// requestSchemaChanges asks the plugin for the schema changes after version since
+func requestSchemaChanges(call requestFunc, since int64) ([]domain.SchemaChange, int64, error) {
+	resp, err := call("get_changes", map[string]interface{}{
+		"since": since,
+	})
+	if err != nil {
+		return nil, since, err
+	}
+
+	var result SchemaChangesResult
+	if err := decodeResult(resp, &result); err != nil {
+		return nil, since, err
+	}
+	return result.Changes, result.Version, nil
+}
@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// DefaultCursorPageSize is the number of rows requested per page when streaming query results
+const DefaultCursorPageSize = 1000
+
+// CursorPage is the result payload of "query" (with page_size) and "fetch" requests.
+// Plugins that do not support cursors return a plain QueryResult without cursor_id,
+// which the host treats as a single, final page.
+// SchemaVersion is an optional opaque version of the table schema; when it changes
+// between pages the table was altered or dropped and the cursor fails with ErrNeedReprepare.
+type CursorPage struct {
+	CursorID      string              `json:"cursor_id,omitempty"`
+	Columns       []domain.ColumnInfo `json:"columns,omitempty"`
+	Rows          []domain.Row        `json:"rows"`
+	Done          bool                `json:"done"`
+	SchemaVersion uint64              `json:"schema_version,omitempty"`
+}
+
+// requestFunc sends a JSON-RPC request to a plugin
+type requestFunc func(method string, params map[string]interface{}) (*PluginResponse, error)
+
+// decodeResult decodes a plugin response result into v
+func decodeResult(resp *PluginResponse, v interface{}) error {
+	if resp.Error != "" {
+		if resp.Retryable {
+			return domain.NewErrRetryable("plugin", errors.New(resp.Error))
+		}
+		return fmt.Errorf("%s", resp.Error)
+	}
+	data, err := json.Marshal(resp.Result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// pluginCursor exposes a plugin-side cursor as a domain.RowIterator.
+// Pages are fetched lazily; Close releases the cursor on the plugin side.
+type pluginCursor struct {
+	call          requestFunc
+	pageSize      int
+	table         string
+	cursorID      string
+	schemaVersion uint64
+	columns       []domain.ColumnInfo
+	rows          []domain.Row
+	pos           int
+	done          bool
+	closed        bool
+}
+
+// openPluginCursor sends a paged "query" request and returns an iterator over the results
+func openPluginCursor(call requestFunc, tableName string, options *domain.QueryOptions, pageSize int) (*pluginCursor, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultCursorPageSize
+	}
+	resp, err := call("query", map[string]interface{}{
+		"table":     tableName,
+		"options":   options,
+		"page_size": pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var page CursorPage
+	if err := decodeResult(resp, &page); err != nil {
+		return nil, err
+	}
+
+	c := &pluginCursor{
+		call:          call,
+		pageSize:      pageSize,
+		table:         tableName,
+		cursorID:      page.CursorID,
+		schemaVersion: page.SchemaVersion,
+		columns:       page.Columns,
+		rows:          page.Rows,
+		// Legacy plugins return every row at once without a cursor
+		done: page.Done || page.CursorID == "",
+	}
+	return c, nil
+}
+
+// Columns returns the result columns reported with the first page
+func (c *pluginCursor) Columns() []domain.ColumnInfo {
+	return c.columns
+}
+
+// Next returns the next row, fetching a new page from the plugin when needed
+func (c *pluginCursor) Next(ctx context.Context) (domain.Row, error) {
+	for c.pos >= len(c.rows) {
+		if c.done || c.closed {
+			return nil, io.EOF
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := c.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	row := c.rows[c.pos]
+	c.pos++
+	return row, nil
+}
+
+// fetch requests the next page of the cursor
+func (c *pluginCursor) fetch() error {
+	resp, err := c.call("fetch", map[string]interface{}{
+		"cursor_id": c.cursorID,
+		"page_size": c.pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	var page CursorPage
+	if err := decodeResult(resp, &page); err != nil {
+		return fmt.Errorf("fetch cursor '%s': %w", c.cursorID, err)
+	}
+	// The columns reported with the first page no longer describe the rows;
+	// the cursor stays open so Close still releases it on the plugin side
+	if c.schemaVersion != 0 && page.SchemaVersion != 0 && page.SchemaVersion != c.schemaVersion {
+		return domain.NewErrNeedReprepare(c.table)
+	}
+	c.rows = page.Rows
+	c.pos = 0
+	c.done = page.Done || len(page.Rows) == 0
+	return nil
+}
+
+// Close releases the cursor. Cursors that were fully consumed are released
+// by the plugin automatically, so close_cursor is only sent for open cursors.
+func (c *pluginCursor) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.rows = nil
+	if c.done || c.cursorID == "" {
+		return nil
+	}
+
+	resp, err := c.call("close_cursor", map[string]interface{}{
+		"cursor_id": c.cursorID,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("close cursor '%s': %s", c.cursorID, resp.Error)
+	}
+	return nil
+}
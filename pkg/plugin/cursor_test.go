@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCursorPlugin serves a paged result set the way a cursor-aware plugin would
+type fakeCursorPlugin struct {
+	rows    []domain.Row
+	methods []string
+	offset  int
+}
+
+func (f *fakeCursorPlugin) call(method string, params map[string]interface{}) (*PluginResponse, error) {
+	f.methods = append(f.methods, method)
+	switch method {
+	case "query", "fetch":
+		size := params["page_size"].(int)
+		end := f.offset + size
+		if end > len(f.rows) {
+			end = len(f.rows)
+		}
+		page := map[string]interface{}{
+			"cursor_id": "c1",
+			"rows":      f.rows[f.offset:end],
+			"done":      end == len(f.rows),
+		}
+		if method == "query" {
+			page["columns"] = []domain.ColumnInfo{{Name: "id", Type: "int64"}}
+		}
+		f.offset = end
+		return &PluginResponse{Result: page}, nil
+	case "close_cursor":
+		return &PluginResponse{Result: map[string]interface{}{"success": true}}, nil
+	}
+	return &PluginResponse{Error: "unknown method: " + method}, nil
+}
+
+func newFakeCursorPlugin(n int) *fakeCursorPlugin {
+	f := &fakeCursorPlugin{}
+	for i := 0; i < n; i++ {
+		f.rows = append(f.rows, domain.Row{"id": i})
+	}
+	return f
+}
+
+func TestPluginCursor_StreamsPages(t *testing.T) {
+	f := newFakeCursorPlugin(5)
+	it, err := openPluginCursor(f.call, "t", nil, 2)
+	require.NoError(t, err)
+
+	result, err := domain.CollectRows(context.Background(), it)
+	require.NoError(t, err)
+	require.NoError(t, it.Close())
+
+	assert.Len(t, result.Rows, 5)
+	assert.Equal(t, float64(4), result.Rows[4]["id"])
+	assert.Equal(t, "id", result.Columns[0].Name)
+	// 1 query + 2 fetches; exhausted cursors need no close_cursor
+	assert.Equal(t, []string{"query", "fetch", "fetch"}, f.methods)
+}
+
+func TestPluginCursor_CloseEarly(t *testing.T) {
+	f := newFakeCursorPlugin(5)
+	it, err := openPluginCursor(f.call, "t", nil, 2)
+	require.NoError(t, err)
+
+	_, err = it.Next(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, it.Close())
+	require.NoError(t, it.Close())
+	assert.Equal(t, []string{"query", "close_cursor"}, f.methods)
+}
+
+func TestPluginCursor_LegacyPlugin(t *testing.T) {
+	call := func(method string, params map[string]interface{}) (*PluginResponse, error) {
+		return &PluginResponse{Result: map[string]interface{}{
+			"rows":  []domain.Row{{"id": 1}, {"id": 2}},
+			"total": 2,
+		}}, nil
+	}
+	it, err := openPluginCursor(call, "t", nil, 0)
+	require.NoError(t, err)
+
+	result, err := domain.CollectRows(context.Background(), it)
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 2)
+}
+
+func TestPluginCursor_FetchError(t *testing.T) {
+	f := newFakeCursorPlugin(5)
+	call := func(method string, params map[string]interface{}) (*PluginResponse, error) {
+		if method == "fetch" {
+			return nil, fmt.Errorf("plugin crashed")
+		}
+		return f.call(method, params)
+	}
+	it, err := openPluginCursor(call, "t", nil, 2)
+	require.NoError(t, err)
+
+	_, err = domain.CollectRows(context.Background(), it)
+	assert.ErrorContains(t, err, "plugin crashed")
+}
+
+func TestPluginCursor_RetryableError(t *testing.T) {
+	f := newFakeCursorPlugin(5)
+	call := func(method string, params map[string]interface{}) (*PluginResponse, error) {
+		if method == "fetch" {
+			return &PluginResponse{Error: "connection reset by peer", Retryable: true}, nil
+		}
+		return f.call(method, params)
+	}
+	it, err := openPluginCursor(call, "t", nil, 2)
+	require.NoError(t, err)
+
+	_, err = domain.CollectRows(context.Background(), it)
+	assert.True(t, domain.IsRetryable(err))
+	assert.ErrorContains(t, err, "connection reset by peer")
+
+	_, err = openPluginCursor(func(string, map[string]interface{}) (*PluginResponse, error) {
+		return &PluginResponse{Error: "table not found"}, nil
+	}, "t", nil, 2)
+	assert.False(t, domain.IsRetryable(err))
+}
+
+func TestPluginCursor_SchemaChangedBetweenPages(t *testing.T) {
+	var methods []string
+	version := uint64(1)
+	call := func(method string, params map[string]interface{}) (*PluginResponse, error) {
+		methods = append(methods, method)
+		if method == "close_cursor" {
+			return &PluginResponse{Result: map[string]interface{}{"success": true}}, nil
+		}
+		page := map[string]interface{}{
+			"cursor_id":      "c1",
+			"rows":           []domain.Row{{"id": 1}},
+			"schema_version": version,
+		}
+		version++
+		return &PluginResponse{Result: page}, nil
+	}
+
+	it, err := openPluginCursor(call, "orders", nil, 1)
+	require.NoError(t, err)
+	_, err = it.Next(context.Background())
+	require.NoError(t, err)
+
+	_, err = it.Next(context.Background())
+	var reprepare *domain.ErrNeedReprepare
+	require.ErrorAs(t, err, &reprepare)
+	assert.Equal(t, "orders", reprepare.Table)
+
+	require.NoError(t, it.Close())
+	assert.Equal(t, []string{"query", "fetch", "close_cursor"}, methods)
+}
@@ -73,6 +73,7 @@ func (l *DLLPluginLoader) Load(path string) (domain.DataSourceFactory, PluginInf
 		handleRequestProc: handleRequestProc,
 		freeStringProc:    freeStringProc,
 		pluginType:        info.Type,
+		info:              info,
 	}
 
 	return factory, info, nil
@@ -108,6 +109,7 @@ type DLLDataSourceFactory struct {
 	handleRequestProc *syscall.Proc
 	freeStringProc    *syscall.Proc
 	pluginType        domain.DataSourceType
+	info              PluginInfo
 }
 
 // GetType returns the datasource type handled by this plugin
@@ -132,6 +134,7 @@ func (f *DLLDataSourceFactory) Create(config *domain.DataSourceConfig) (domain.D
 		freeStringProc:    f.freeStringProc,
 		config:            config,
 		instanceID:        config.Name,
+		info:              f.info,
 	}
 
 	// Send create request to the DLL
@@ -155,14 +158,29 @@ type DLLDataSource struct {
 	config            *domain.DataSourceConfig
 	instanceID        string
 	connected         bool
+	info              PluginInfo
 }
 
 // callDLL sends a JSON-RPC request to the DLL and returns the response
 func (ds *DLLDataSource) callDLL(method string, params map[string]interface{}) (*PluginResponse, error) {
+	return ds.callDLLWithLabels(method, nil, params)
+}
+
+// labeledCall returns a requestFunc that forwards the query labels of ctx with every request
+func (ds *DLLDataSource) labeledCall(ctx context.Context) requestFunc {
+	labels := domain.QueryLabelsFromContext(ctx)
+	return func(method string, params map[string]interface{}) (*PluginResponse, error) {
+		return ds.callDLLWithLabels(method, labels, params)
+	}
+}
+
+// callDLLWithLabels sends a JSON-RPC request carrying query labels to the DLL and returns the response
+func (ds *DLLDataSource) callDLLWithLabels(method string, labels map[string]string, params map[string]interface{}) (*PluginResponse, error) {
 	req := PluginRequest{
 		Method: method,
 		ID:     ds.instanceID,
 		Params: params,
+		Labels: labels,
 	}
 
 	reqJSON, err := json.Marshal(req)
@@ -300,34 +318,41 @@ func (ds *DLLDataSource) GetTableInfo(ctx context.Context, tableName string) (*d
 	return &info, nil
 }
 
-// Query executes a query
+// Query executes a query. Results are streamed page by page through a plugin
+// cursor so that large tables never have to fit in a single JSON response.
 func (ds *DLLDataSource) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
-	resp, err := ds.callDLL("query", map[string]interface{}{
-		"table":   tableName,
-		"options": options,
-	})
+	it, err := ds.QueryStream(ctx, tableName, options)
 	if err != nil {
 		return nil, err
 	}
-	if resp.Error != "" {
-		return nil, fmt.Errorf("%s", resp.Error)
-	}
+	defer it.Close()
+	return domain.CollectRows(ctx, it)
+}
 
-	data, err := json.Marshal(resp.Result)
-	if err != nil {
-		return nil, err
+// QueryStream executes a query and returns a cursor-backed row iterator
+func (ds *DLLDataSource) QueryStream(ctx context.Context, tableName string, options *domain.QueryOptions) (domain.RowIterator, error) {
+	return openPluginCursor(ds.labeledCall(ctx), tableName, options, DefaultCursorPageSize)
+}
+
+// GetSchemaChanges returns the schema changes the plugin reports after version since
+func (ds *DLLDataSource) GetSchemaChanges(ctx context.Context, since int64) ([]domain.SchemaChange, int64, error) {
+	if !ds.info.HasCapability(CapabilitySchemaChanges) {
+		return nil, since, domain.NewErrUnsupportedOperation(string(ds.info.Type), "get_changes")
 	}
+	return requestSchemaChanges(ds.callDLL, since)
+}
 
-	var result domain.QueryResult
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, err
+// RowCount returns the number of rows of tableName matching filters as counted by the plugin
+func (ds *DLLDataSource) RowCount(ctx context.Context, tableName string, filters []domain.Filter) (int64, bool, error) {
+	if !ds.info.HasCapability(CapabilityRowCount) {
+		return 0, false, domain.NewErrUnsupportedOperation(string(ds.info.Type), "row_count")
 	}
-	return &result, nil
+	return requestRowCount(ds.labeledCall(ctx), tableName, filters)
 }
 
 // Insert inserts rows
 func (ds *DLLDataSource) Insert(ctx context.Context, tableName string, rows []domain.Row, options *domain.InsertOptions) (int64, error) {
-	resp, err := ds.callDLL("insert", map[string]interface{}{
+	resp, err := ds.labeledCall(ctx)("insert", map[string]interface{}{
 		"table":   tableName,
 		"rows":    rows,
 		"options": options,
@@ -349,7 +374,7 @@ func (ds *DLLDataSource) Insert(ctx context.Context, tableName string, rows []do
 
 // Update updates rows
 func (ds *DLLDataSource) Update(ctx context.Context, tableName string, filters []domain.Filter, updates domain.Row, options *domain.UpdateOptions) (int64, error) {
-	resp, err := ds.callDLL("update", map[string]interface{}{
+	resp, err := ds.labeledCall(ctx)("update", map[string]interface{}{
 		"table":   tableName,
 		"filters": filters,
 		"updates": updates,
@@ -372,7 +397,7 @@ func (ds *DLLDataSource) Update(ctx context.Context, tableName string, filters [
 
 // Delete deletes rows
 func (ds *DLLDataSource) Delete(ctx context.Context, tableName string, filters []domain.Filter, options *domain.DeleteOptions) (int64, error) {
-	resp, err := ds.callDLL("delete", map[string]interface{}{
+	resp, err := ds.labeledCall(ctx)("delete", map[string]interface{}{
 		"table":   tableName,
 		"filters": filters,
 		"options": options,
@@ -436,7 +461,7 @@ func (ds *DLLDataSource) TruncateTable(ctx context.Context, tableName string) er
 
 // Execute executes raw SQL
 func (ds *DLLDataSource) Execute(ctx context.Context, sql string) (*domain.QueryResult, error) {
-	resp, err := ds.callDLL("execute", map[string]interface{}{
+	resp, err := ds.labeledCall(ctx)("execute", map[string]interface{}{
 		"sql": sql,
 	})
 	if err != nil {
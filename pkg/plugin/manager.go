@@ -3,17 +3,20 @@ package plugin
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/resource/application"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 )
 
+// pluginLog is the structured logger of the plugin manager
+var pluginLog = logging.Module("plugin")
+
 // PluginManager manages the lifecycle of datasource plugins
 type PluginManager struct {
 	registry  *application.Registry
@@ -21,7 +24,12 @@ type PluginManager struct {
 	configDir string
 	loader    PluginLoader
 	mu        sync.Mutex
+	scanMu    sync.Mutex // serializes Rescan
 	plugins   []PluginInfo
+	// files are the plugin files handled so far (loaded, skipped or failed), by path
+	files map[string]*pluginFile
+	// pending are new files seen by the last rescan, loaded once they stop changing
+	pending map[string]fileStamp
 }
 
 // NewPluginManager creates a new plugin manager
@@ -32,13 +40,15 @@ func NewPluginManager(registry *application.Registry, dsManager *application.Dat
 		configDir: configDir,
 		loader:    newPlatformLoader(),
 		plugins:   make([]PluginInfo, 0),
+		files:     make(map[string]*pluginFile),
+		pending:   make(map[string]fileStamp),
 	}
 }
 
 // ScanAndLoad scans the plugin directory and loads all compatible plugins
 func (pm *PluginManager) ScanAndLoad(pluginDir string) error {
 	if pm.loader == nil {
-		log.Printf("[PLUGIN] Plugin loading not supported on this platform")
+		pluginLog.Info("plugin loading not supported on this platform")
 		return nil
 	}
 
@@ -46,7 +56,7 @@ func (pm *PluginManager) ScanAndLoad(pluginDir string) error {
 	info, err := os.Stat(pluginDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			log.Printf("[PLUGIN] Plugin directory '%s' does not exist, skipping", pluginDir)
+			pluginLog.Info("plugin directory does not exist, skipping", "dir", pluginDir)
 			return nil
 		}
 		return fmt.Errorf("failed to stat plugin directory: %w", err)
@@ -55,35 +65,21 @@ func (pm *PluginManager) ScanAndLoad(pluginDir string) error {
 		return fmt.Errorf("plugin path '%s' is not a directory", pluginDir)
 	}
 
-	ext := pm.loader.SupportedExtension()
-	log.Printf("[PLUGIN] Scanning '%s' for %s files...", pluginDir, ext)
+	pluginLog.Info("scanning plugin directory", "dir", pluginDir, "ext", pm.loader.SupportedExtension())
 
-	entries, err := os.ReadDir(pluginDir)
+	files, err := pm.listPluginFiles(pluginDir)
 	if err != nil {
-		return fmt.Errorf("failed to read plugin directory: %w", err)
+		return err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		if !strings.HasSuffix(strings.ToLower(name), ext) {
-			continue
-		}
-
-		pluginPath := filepath.Join(pluginDir, name)
-		if err := pm.LoadPlugin(pluginPath); err != nil {
-			log.Printf("[PLUGIN] Failed to load plugin '%s': %v", name, err)
-			continue
-		}
+	for _, path := range sortedPaths(files) {
+		pm.loadFile(path, files[path])
 	}
 
 	pm.mu.Lock()
 	count := len(pm.plugins)
 	pm.mu.Unlock()
-	log.Printf("[PLUGIN] Loaded %d plugin(s)", count)
+	pluginLog.Info("plugins loaded", "count", count)
 
 	// After loading all plugins, create datasource instances from config
 	pm.createDatasourcesFromConfig()
@@ -93,28 +89,34 @@ func (pm *PluginManager) ScanAndLoad(pluginDir string) error {
 
 // LoadPlugin loads a single plugin file
 func (pm *PluginManager) LoadPlugin(path string) error {
+	_, err := pm.loadPlugin(path)
+	return err
+}
+
+// loadPlugin loads a single plugin file and returns its metadata; a plugin
+// whose type is already registered is skipped with an empty Type
+func (pm *PluginManager) loadPlugin(path string) (PluginInfo, error) {
 	factory, info, err := pm.loader.Load(path)
 	if err != nil {
-		return err
+		return PluginInfo{}, err
 	}
 
 	// Register the factory in the registry
 	if err := pm.registry.Register(factory); err != nil {
 		// If already registered, skip
 		if strings.Contains(err.Error(), "already registered") {
-			log.Printf("[PLUGIN] Factory type '%s' already registered, skipping", info.Type)
-			return nil
+			pluginLog.Warn("factory type already registered, skipping", "type", info.Type)
+			return PluginInfo{}, nil
 		}
-		return fmt.Errorf("failed to register factory: %w", err)
+		return PluginInfo{}, fmt.Errorf("failed to register factory: %w", err)
 	}
 
 	pm.mu.Lock()
 	pm.plugins = append(pm.plugins, info)
 	pm.mu.Unlock()
-	log.Printf("[PLUGIN] Loaded plugin: type=%s, version=%s, file=%s",
-		info.Type, info.Version, filepath.Base(path))
+	pluginLog.Info("loaded plugin", "type", info.Type, "version", info.Version, "file", filepath.Base(path))
 
-	return nil
+	return info, nil
 }
 
 // createDatasourcesFromConfig reads config.datasource and creates instances for plugin types
@@ -125,7 +127,7 @@ func (pm *PluginManager) createDatasourcesFromConfig() {
 
 	configs, err := config_schema.LoadDatasources(pm.configDir)
 	if err != nil {
-		log.Printf("[PLUGIN] Failed to load datasource configs: %v", err)
+		pluginLog.Error("failed to load datasource configs", "err", err)
 		return
 	}
 
@@ -145,31 +147,30 @@ func (pm *PluginManager) createDatasourcesFromConfig() {
 
 		// Check if already registered
 		if _, err := pm.dsManager.Get(cfg.Name); err == nil {
-			log.Printf("[PLUGIN] Datasource '%s' already registered, skipping", cfg.Name)
+			pluginLog.Warn("datasource already registered, skipping", "datasource", cfg.Name)
 			continue
 		}
 
 		cfgCopy := cfg
 		ds, err := pm.dsManager.CreateFromConfig(&cfgCopy)
 		if err != nil {
-			log.Printf("[PLUGIN] Failed to create datasource '%s' (type=%s): %v",
-				cfg.Name, cfg.Type, err)
+			pluginLog.Error("failed to create datasource", "datasource", cfg.Name, "type", cfg.Type, "err", err)
 			continue
 		}
 
 		if err := ds.Connect(context.Background()); err != nil {
-			log.Printf("[PLUGIN] Failed to connect datasource '%s': %v", cfg.Name, err)
+			pluginLog.Error("failed to connect datasource", "datasource", cfg.Name, "err", err)
 			continue
 		}
 
 		if err := pm.dsManager.Register(cfg.Name, ds); err != nil {
 			// Close the connection to avoid resource leak
 			ds.Close(context.Background())
-			log.Printf("[PLUGIN] Failed to register datasource '%s': %v", cfg.Name, err)
+			pluginLog.Error("failed to register datasource", "datasource", cfg.Name, "err", err)
 			continue
 		}
 
-		log.Printf("[PLUGIN] Created datasource '%s' from config (type=%s)", cfg.Name, cfg.Type)
+		pluginLog.Info("created datasource from config", "datasource", cfg.Name, "type", cfg.Type)
 	}
 }
 
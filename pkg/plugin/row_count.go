@@ -0,0 +1,33 @@
+package plugin
+
+import (
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// CapabilityRowCount is the capability a plugin declares in PluginGetInfo
+// when it implements the "row_count" method
+const CapabilityRowCount = "row_count"
+
+// RowCountResult is the result payload of a "row_count" request.
+// Exact is false when Count is only an estimate (e.g. from table metadata)
+type RowCountResult struct {
+	Count int64 `json:"count"`
+	Exact bool  `json:"exact"`
+}
+
+// requestRowCount asks the plugin how many rows of tableName match filters
+func requestRowCount(call requestFunc, tableName string, filters []domain.Filter) (int64, bool, error) {
+	resp, err := call("row_count", map[string]interface{}{
+		"table":   tableName,
+		"filters": filters,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	var result RowCountResult
+	if err := decodeResult(resp, &result); err != nil {
+		return 0, false, err
+	}
+	return result.Count, result.Exact, nil
+}
@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestRowCount(t *testing.T) {
+	var gotMethod string
+	var gotParams map[string]interface{}
+	call := func(method string, params map[string]interface{}) (*PluginResponse, error) {
+		gotMethod, gotParams = method, params
+		return &PluginResponse{Result: map[string]interface{}{"count": 42, "exact": true}}, nil
+	}
+
+	filters := []domain.Filter{{Field: "status", Operator: "=", Value: "active"}}
+	count, exact, err := requestRowCount(call, "orders", filters)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+	assert.True(t, exact)
+	assert.Equal(t, "row_count", gotMethod)
+	assert.Equal(t, "orders", gotParams["table"])
+	assert.Equal(t, filters, gotParams["filters"])
+}
+
+func TestRequestRowCount_Estimate(t *testing.T) {
+	count, exact, err := requestRowCount(func(string, map[string]interface{}) (*PluginResponse, error) {
+		return &PluginResponse{Result: map[string]interface{}{"count": 1000}}, nil
+	}, "orders", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), count)
+	assert.False(t, exact)
+
+	_, _, err = requestRowCount(func(string, map[string]interface{}) (*PluginResponse, error) {
+		return &PluginResponse{Error: "table not found"}, nil
+	}, "missing", nil)
+	assert.ErrorContains(t, err, "table not found")
+}
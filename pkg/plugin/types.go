@@ -10,6 +10,18 @@ type PluginInfo struct {
 	Version     string                `json:"version"`
 	Description string                `json:"description"`
 	FilePath    string                `json:"file_path"`
+	// Capabilities lists optional protocol methods the plugin implements (e.g. "get_changes")
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// HasCapability reports whether the plugin declared the given optional method
+func (i PluginInfo) HasCapability(name string) bool {
+	for _, c := range i.Capabilities {
+		if c == name {
+			return true
+		}
+	}
+	return false
 }
 
 // PluginLoader is the interface for loading plugins from shared library files
@@ -28,10 +40,16 @@ type PluginRequest struct {
 	Method string                 `json:"method"`
 	ID     string                 `json:"id,omitempty"`
 	Params map[string]interface{} `json:"params,omitempty"`
+	// Labels carries the query labels (e.g. app, request_id) of the statement
+	// that caused the request, so the plugin can correlate it downstream
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // PluginResponse is the JSON-RPC response format from DLL plugins
 type PluginResponse struct {
 	Result interface{} `json:"result,omitempty"`
 	Error  string      `json:"error,omitempty"`
+	// Retryable marks Error as a lost backend connection: the read did not complete
+	// and can be retried (reported to the host as domain.ErrRetryable)
+	Retryable bool `json:"retryable,omitempty"`
 }
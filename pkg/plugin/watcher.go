@@ -0,0 +1,257 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// Plugin states reported by Plugins
+const (
+	PluginActive = "ACTIVE" // loaded, its factory is registered
+	PluginFailed = "FAILED" // the file could not be loaded; retried after it changes
+)
+
+// PluginStatus is a plugin file and its load state, as listed by SHOW PLUGINS
+type PluginStatus struct {
+	PluginInfo
+	Status string `json:"status"`
+	// Datasources is the number of registered datasources of the plugin type
+	Datasources int    `json:"datasources"`
+	Error       string `json:"error,omitempty"`
+}
+
+// fileStamp identifies a version of a plugin file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// pluginFile is a plugin file handled by ScanAndLoad or Rescan
+type pluginFile struct {
+	stamp      fileStamp
+	pluginType domain.DataSourceType // empty when the file failed or its type was already registered
+	err        error
+}
+
+// Watch rescans pluginDir every interval until ctx is cancelled, so that
+// plugins can be added and removed without restarting the server
+func (pm *PluginManager) Watch(ctx context.Context, pluginDir string, interval time.Duration) {
+	if pm.loader == nil || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := pm.Rescan(pluginDir); err != nil {
+					pluginLog.Error("failed to rescan plugin directory", "dir", pluginDir, "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// Rescan compares pluginDir with the plugins loaded from it. New files are
+// loaded once they are unchanged since the previous rescan, so a file still
+// being copied is not opened half-written; files that failed to load are
+// retried the same way after they change. Plugins whose file was removed are
+// detached. Datasources in config.datasource of newly loaded plugin types are
+// created.
+func (pm *PluginManager) Rescan(pluginDir string) error {
+	if pm.loader == nil {
+		return nil
+	}
+	pm.scanMu.Lock()
+	defer pm.scanMu.Unlock()
+
+	files, err := pm.listPluginFiles(pluginDir)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Clean(pluginDir)
+
+	pm.mu.Lock()
+	var removed []string
+	for path := range pm.files {
+		if _, ok := files[path]; !ok && filepath.Dir(path) == dir {
+			removed = append(removed, path)
+		}
+	}
+	for path := range pm.pending {
+		if _, ok := files[path]; !ok {
+			delete(pm.pending, path)
+		}
+	}
+	pm.mu.Unlock()
+	sort.Strings(removed)
+	for _, path := range removed {
+		pm.removeFile(path)
+	}
+
+	loaded := false
+	for _, path := range sortedPaths(files) {
+		stamp := files[path]
+		pm.mu.Lock()
+		if f := pm.files[path]; f != nil {
+			if f.err == nil || f.stamp == stamp {
+				pm.mu.Unlock()
+				continue
+			}
+			delete(pm.files, path)
+		}
+		if pending, ok := pm.pending[path]; !ok || pending != stamp {
+			pm.pending[path] = stamp
+			pm.mu.Unlock()
+			continue
+		}
+		delete(pm.pending, path)
+		pm.mu.Unlock()
+
+		if pm.loadFile(path, stamp) {
+			loaded = true
+		}
+	}
+
+	if loaded {
+		pm.createDatasourcesFromConfig()
+	}
+	return nil
+}
+
+// Detach unloads the plugin of the given type: its datasources are
+// unregistered, so new statements no longer reach them, and closed; then its
+// factory is removed so that no datasource of the type can be created. Go
+// plugins stay mapped in the process, as the runtime cannot unload them.
+func (pm *PluginManager) Detach(pluginType domain.DataSourceType) error {
+	pm.mu.Lock()
+	index := -1
+	for i, p := range pm.plugins {
+		if p.Type == pluginType {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		pm.mu.Unlock()
+		return fmt.Errorf("plugin type '%s' is not loaded", pluginType)
+	}
+	pm.plugins = append(pm.plugins[:index], pm.plugins[index+1:]...)
+	pm.mu.Unlock()
+
+	for name, ds := range pm.dsManager.GetAllDataSources() {
+		if cfg := ds.GetConfig(); cfg == nil || cfg.Type != pluginType {
+			continue
+		}
+		if err := pm.dsManager.Unregister(name); err != nil {
+			pluginLog.Error("failed to detach datasource", "datasource", name, "err", err)
+			continue
+		}
+		pluginLog.Info("detached datasource", "datasource", name, "type", pluginType)
+	}
+
+	if err := pm.registry.Unregister(pluginType); err != nil {
+		return fmt.Errorf("failed to unregister factory: %w", err)
+	}
+	pluginLog.Info("detached plugin", "type", pluginType)
+	return nil
+}
+
+// Plugins returns the loaded plugins followed by the plugin files that failed to load
+func (pm *PluginManager) Plugins() []PluginStatus {
+	counts := make(map[domain.DataSourceType]int)
+	for _, ds := range pm.dsManager.GetAllDataSources() {
+		if cfg := ds.GetConfig(); cfg != nil {
+			counts[cfg.Type]++
+		}
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	result := make([]PluginStatus, 0, len(pm.plugins))
+	for _, p := range pm.plugins {
+		result = append(result, PluginStatus{PluginInfo: p, Status: PluginActive, Datasources: counts[p.Type]})
+	}
+	var failed []PluginStatus
+	for path, f := range pm.files {
+		if f.err != nil {
+			failed = append(failed, PluginStatus{PluginInfo: PluginInfo{FilePath: path}, Status: PluginFailed, Error: f.err.Error()})
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].FilePath < failed[j].FilePath })
+	return append(result, failed...)
+}
+
+// loadFile loads a plugin file found in the plugin directory and records
+// the outcome; it reports whether a new plugin type was registered
+func (pm *PluginManager) loadFile(path string, stamp fileStamp) bool {
+	info, err := pm.loadPlugin(path)
+	if err != nil {
+		pluginLog.Error("failed to load plugin", "file", filepath.Base(path), "err", err)
+	}
+	pm.mu.Lock()
+	pm.files[path] = &pluginFile{stamp: stamp, pluginType: info.Type, err: err}
+	pm.mu.Unlock()
+	return err == nil && info.Type != ""
+}
+
+// removeFile forgets a plugin file that was removed and detaches its plugin
+func (pm *PluginManager) removeFile(path string) {
+	pm.mu.Lock()
+	f := pm.files[path]
+	delete(pm.files, path)
+	pm.mu.Unlock()
+
+	pluginLog.Info("plugin file removed", "file", filepath.Base(path))
+	if f != nil && f.pluginType != "" {
+		if err := pm.Detach(f.pluginType); err != nil {
+			pluginLog.Error("failed to detach plugin", "type", f.pluginType, "err", err)
+		}
+	}
+}
+
+// listPluginFiles returns the plugin files in pluginDir; a missing directory has none
+func (pm *PluginManager) listPluginFiles(pluginDir string) (map[string]fileStamp, error) {
+	files := make(map[string]fileStamp)
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	ext := strings.ToLower(pm.loader.SupportedExtension())
+	for _, entry := range entries {
+		if entry.IsDir() || ext == "" || !strings.HasSuffix(strings.ToLower(entry.Name()), ext) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed while scanning
+			continue
+		}
+		files[filepath.Join(pluginDir, entry.Name())] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return files, nil
+}
+
+// sortedPaths returns the paths of files in load order
+func sortedPaths(files map[string]fileStamp) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
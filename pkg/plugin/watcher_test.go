@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFactory creates memory datasources under the plugin's type
+type fakeFactory struct {
+	pluginType domain.DataSourceType
+}
+
+func (f *fakeFactory) Create(config *domain.DataSourceConfig) (domain.DataSource, error) {
+	return memory.NewMVCCDataSource(config), nil
+}
+
+func (f *fakeFactory) GetType() domain.DataSourceType { return f.pluginType }
+
+func (f *fakeFactory) GetMetadata() domain.DriverMetadata { return domain.DriverMetadata{} }
+
+// fakeLoader loads ".fake" files whose content is the plugin type; "broken" fails to load
+type fakeLoader struct{}
+
+func (fakeLoader) Load(path string) (domain.DataSourceFactory, PluginInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, PluginInfo{}, err
+	}
+	pluginType := domain.DataSourceType(strings.TrimSpace(string(data)))
+	if pluginType == "broken" {
+		return nil, PluginInfo{}, fmt.Errorf("bad plugin")
+	}
+	return &fakeFactory{pluginType: pluginType}, PluginInfo{Type: pluginType, Version: "1.0", FilePath: path}, nil
+}
+
+func (fakeLoader) SupportedExtension() string { return ".fake" }
+
+func newWatchTestManager(t *testing.T) (*PluginManager, *application.Registry, *application.DataSourceManager) {
+	registry := application.NewRegistry()
+	dsManager := application.NewDataSourceManagerWithRegistry(registry)
+	pm := NewPluginManager(registry, dsManager, "")
+	pm.loader = fakeLoader{}
+	return pm, registry, dsManager
+}
+
+func TestRescan_LoadsNewPluginOnceStable(t *testing.T) {
+	pm, registry, _ := newWatchTestManager(t)
+	dir := t.TempDir()
+	require.NoError(t, pm.ScanAndLoad(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kv.fake"), []byte("kv"), 0644))
+
+	// 第一次检查只记录文件，避免加载仍在复制中的文件
+	require.NoError(t, pm.Rescan(dir))
+	assert.Empty(t, pm.GetLoadedPlugins())
+
+	require.NoError(t, pm.Rescan(dir))
+	plugins := pm.Plugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, domain.DataSourceType("kv"), plugins[0].Type)
+	assert.Equal(t, PluginActive, plugins[0].Status)
+	_, err := registry.Create(&domain.DataSourceConfig{Type: "kv", Name: "kv1"})
+	assert.NoError(t, err)
+}
+
+func TestRescan_DetachesRemovedPlugin(t *testing.T) {
+	pm, registry, dsManager := newWatchTestManager(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kv.fake")
+	require.NoError(t, os.WriteFile(path, []byte("kv"), 0644))
+	require.NoError(t, pm.ScanAndLoad(dir))
+
+	ds, err := dsManager.CreateFromConfig(&domain.DataSourceConfig{Type: "kv", Name: "kv1"})
+	require.NoError(t, err)
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, dsManager.Register("kv1", ds))
+	assert.Equal(t, 1, pm.Plugins()[0].Datasources)
+
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, pm.Rescan(dir))
+
+	assert.Empty(t, pm.Plugins())
+	_, err = dsManager.Get("kv1")
+	assert.Error(t, err)
+	assert.False(t, ds.IsConnected())
+	_, err = registry.Create(&domain.DataSourceConfig{Type: "kv", Name: "kv2"})
+	assert.Error(t, err)
+}
+
+func TestRescan_ReportsAndRetriesFailedPlugin(t *testing.T) {
+	pm, _, _ := newWatchTestManager(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kv.fake")
+	require.NoError(t, os.WriteFile(path, []byte("broken"), 0644))
+	require.NoError(t, pm.ScanAndLoad(dir))
+
+	plugins := pm.Plugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, PluginFailed, plugins[0].Status)
+	assert.Contains(t, plugins[0].Error, "bad plugin")
+
+	// 文件更新后重新加载
+	require.NoError(t, os.WriteFile(path, []byte("kv2"), 0644))
+	require.NoError(t, pm.Rescan(dir))
+	require.NoError(t, pm.Rescan(dir))
+	plugins = pm.Plugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, PluginActive, plugins[0].Status)
+	assert.Equal(t, domain.DataSourceType("kv2"), plugins[0].Type)
+}
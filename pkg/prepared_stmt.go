@@ -0,0 +1,216 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// preparedStatement 会话中保存的预处理语句及其元数据
+type preparedStatement struct {
+	Query        string
+	Params       []parser.ParamInfo
+	ParamMeta    []protocol.FieldMeta
+	Columns      []protocol.FieldMeta
+	TableSchemas []tableSchemaVersion // prepare 时引用表的结构版本，执行时用于判断表结构是否变化
+}
+
+// tableSchemaVersion 预处理语句引用的表及其结构版本，表不存在时版本为 0
+type tableSchemaVersion struct {
+	Table   string
+	Version uint64
+}
+
+// preparedStmtKey 返回预处理语句在会话中的存储键
+func preparedStmtKey(stmtID uint32) string {
+	return fmt.Sprintf("stmt_%d", stmtID)
+}
+
+// prepareStatement 解析语句并根据表结构推断参数和结果列的元数据
+func (s *Server) prepareStatement(ctx context.Context, query string) (*preparedStatement, error) {
+	stmtNode, err := s.parser.ParseOneStmtText(query)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := newSchemaLookup(ctx, s.GetDataSource())
+	params := parser.InferParams(stmtNode, schema.columnNames)
+
+	prepared := &preparedStatement{
+		Query:     query,
+		Params:    params,
+		ParamMeta: make([]protocol.FieldMeta, len(params)),
+	}
+	for i, param := range params {
+		prepared.ParamMeta[i] = schema.paramFieldMeta(param)
+	}
+	for _, rc := range parser.InferResultColumns(stmtNode) {
+		prepared.Columns = append(prepared.Columns, schema.resultFieldMetas(rc)...)
+	}
+	prepared.TableSchemas = schema.versions()
+	return prepared, nil
+}
+
+// checkTableSchemas 检查预处理语句引用的表结构是否与 prepare 时相同。
+// 表被修改、删除或在 prepare 之后才创建时返回 ER_NEED_REPREPARE，客户端重新 prepare 后得到新的元数据
+func (s *Server) checkTableSchemas(ctx context.Context, prepared *preparedStatement) error {
+	schema := newSchemaLookup(ctx, s.GetDataSource())
+	for _, ts := range prepared.TableSchemas {
+		if domain.SchemaVersion(schema.table(ts.Table)) != ts.Version {
+			return domain.NewErrNeedReprepare(ts.Table)
+		}
+	}
+	return nil
+}
+
+// schemaLookup 在一次 prepare 中缓存表结构
+type schemaLookup struct {
+	ctx    context.Context
+	ds     domain.DataSource
+	tables map[string]*domain.TableInfo
+	order  []string
+}
+
+func newSchemaLookup(ctx context.Context, ds domain.DataSource) *schemaLookup {
+	return &schemaLookup{ctx: ctx, ds: ds, tables: make(map[string]*domain.TableInfo)}
+}
+
+// table 返回表结构，表不存在或未设置数据源时返回 nil
+func (l *schemaLookup) table(name string) *domain.TableInfo {
+	if name == "" || l.ds == nil {
+		return nil
+	}
+	if info, ok := l.tables[name]; ok {
+		return info
+	}
+	info, err := l.ds.GetTableInfo(l.ctx, name)
+	if err != nil {
+		info = nil
+	}
+	l.tables[name] = info
+	l.order = append(l.order, name)
+	return info
+}
+
+// column 返回表中的列定义
+func (l *schemaLookup) column(table, column string) (*domain.TableInfo, *domain.ColumnInfo) {
+	info := l.table(table)
+	if info == nil {
+		return nil, nil
+	}
+	for i := range info.Columns {
+		if strings.EqualFold(info.Columns[i].Name, column) {
+			return info, &info.Columns[i]
+		}
+	}
+	return info, nil
+}
+
+// columnNames 返回表的列名列表，供 INSERT 无列清单时推断参数
+func (l *schemaLookup) columnNames(table string) []string {
+	info := l.table(table)
+	if info == nil {
+		return nil
+	}
+	names := make([]string, len(info.Columns))
+	for i, col := range info.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// versions 按引用顺序返回所有引用表的结构版本，表不存在时版本为 0
+func (l *schemaLookup) versions() []tableSchemaVersion {
+	versions := make([]tableSchemaVersion, len(l.order))
+	for i, name := range l.order {
+		versions[i] = tableSchemaVersion{Table: name, Version: domain.SchemaVersion(l.tables[name])}
+	}
+	return versions
+}
+
+// paramFieldMeta 返回占位符的参数元数据
+func (l *schemaLookup) paramFieldMeta(param parser.ParamInfo) protocol.FieldMeta {
+	switch param.Kind {
+	case parser.ParamKindColumn:
+		if _, col := l.column(param.Table, param.Column); col != nil {
+			meta := columnFieldMeta("", *col)
+			// 参数不携带来源信息，只保留类型相关的属性
+			meta.Name = "?"
+			meta.OrgName = ""
+			meta.Flags &= protocol.UNSIGNED_FLAG | protocol.BINARY_COLLATION_FLAG | protocol.NUM_FLAG
+			return meta
+		}
+	case parser.ParamKindInteger:
+		meta := genericFieldMeta("?")
+		meta.Type = protocol.MYSQL_TYPE_LONGLONG
+		meta.CharacterSet = 63
+		meta.ColumnLength = 21
+		meta.Flags = protocol.BINARY_COLLATION_FLAG | protocol.NUM_FLAG
+		return meta
+	}
+	return genericFieldMeta("?")
+}
+
+// resultFieldMetas 返回结果列的元数据，* 展开为表的全部列
+func (l *schemaLookup) resultFieldMetas(rc parser.ResultColumn) []protocol.FieldMeta {
+	if rc.Wildcard {
+		table := rc.Table
+		if table == "" && len(l.order) == 0 {
+			return nil
+		}
+		if table == "" {
+			table = l.order[0]
+		}
+		info := l.table(table)
+		if info == nil {
+			return nil
+		}
+		metas := make([]protocol.FieldMeta, len(info.Columns))
+		for i, col := range info.Columns {
+			metas[i] = columnFieldMeta(info.Name, col)
+		}
+		return metas
+	}
+
+	if rc.Column != "" {
+		if info, col := l.column(rc.Table, rc.Column); col != nil {
+			meta := columnFieldMeta(info.Name, *col)
+			meta.Name = rc.Name
+			return []protocol.FieldMeta{meta}
+		}
+	}
+
+	meta := genericFieldMeta(rc.Name)
+	if rc.Count {
+		meta.Type = protocol.MYSQL_TYPE_LONGLONG
+		meta.CharacterSet = 63
+		meta.ColumnLength = 21
+		meta.Flags = protocol.NOT_NULL_FLAG | protocol.BINARY_COLLATION_FLAG | protocol.NUM_FLAG
+	}
+	return []protocol.FieldMeta{meta}
+}
+
+// genericFieldMeta 返回无法推断类型时使用的字符串元数据
+func genericFieldMeta(name string) protocol.FieldMeta {
+	return protocol.FieldMeta{
+		Catalog:                   "def",
+		Name:                      name,
+		LengthOfFixedLengthFields: 12,
+		CharacterSet:              33,
+		ColumnLength:              255,
+		Type:                      protocol.MYSQL_TYPE_VAR_STRING,
+		Reserved:                  "\x00\x00",
+	}
+}
+
+// columnFieldMeta 根据列定义生成列元数据，类型映射见 protocol.ColumnFieldMeta
+func columnFieldMeta(table string, col domain.ColumnInfo) protocol.FieldMeta {
+	meta := protocol.ColumnFieldMeta(col)
+	meta.Table = table
+	meta.OrgTable = table
+	return meta
+}
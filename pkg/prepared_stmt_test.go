@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnFieldMeta(t *testing.T) {
+	meta := columnFieldMeta("orders", domain.ColumnInfo{Name: "amount", Type: "DECIMAL(10,2)"})
+	assert.Equal(t, uint8(protocol.MYSQL_TYPE_NEWDECIMAL), meta.Type)
+	assert.Equal(t, uint32(12), meta.ColumnLength)
+	assert.Equal(t, uint8(2), meta.Decimals)
+	assert.Equal(t, "orders", meta.OrgTable)
+
+	meta = columnFieldMeta("", domain.ColumnInfo{Name: "id", Type: "bigint unsigned", Primary: true, AutoIncrement: true})
+	assert.Equal(t, uint8(protocol.MYSQL_TYPE_LONGLONG), meta.Type)
+	assert.Equal(t, uint16(63), meta.CharacterSet)
+	assert.NotZero(t, meta.Flags&protocol.UNSIGNED_FLAG)
+	assert.NotZero(t, meta.Flags&protocol.PRI_KEY_FLAG)
+	assert.NotZero(t, meta.Flags&protocol.NOT_NULL_FLAG)
+	assert.NotZero(t, meta.Flags&protocol.AUTO_INCREMENT_FLAG)
+
+	meta = columnFieldMeta("", domain.ColumnInfo{Name: "name", Type: "varchar(64)", Nullable: true})
+	assert.Equal(t, uint8(protocol.MYSQL_TYPE_VAR_STRING), meta.Type)
+	assert.Equal(t, uint32(64), meta.ColumnLength)
+	assert.Zero(t, meta.Flags&protocol.NOT_NULL_FLAG)
+
+	meta = columnFieldMeta("", domain.ColumnInfo{Name: "created", Type: "datetime(3)", Nullable: true})
+	assert.Equal(t, uint8(protocol.MYSQL_TYPE_DATETIME), meta.Type)
+	assert.Equal(t, uint8(3), meta.Decimals)
+}
+
+func TestCheckTableSchemas(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Writable: true})
+	server := NewServer(nil)
+	require.NoError(t, server.SetDataSource(ds))
+	orders := &domain.TableInfo{
+		Name:    "orders",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}, {Name: "amount", Type: "DECIMAL(10,2)"}},
+	}
+	require.NoError(t, ds.CreateTable(ctx, orders))
+
+	prepared, err := server.prepareStatement(ctx, "SELECT id, amount FROM orders WHERE id = ?")
+	require.NoError(t, err)
+	require.NoError(t, server.checkTableSchemas(ctx, prepared))
+
+	// 删除后以不同的列重新创建
+	require.NoError(t, ds.DropTable(ctx, "orders"))
+	orders.Columns = append(orders.Columns, domain.ColumnInfo{Name: "note", Type: "VARCHAR(64)", Nullable: true})
+	require.NoError(t, ds.CreateTable(ctx, orders))
+
+	err = server.checkTableSchemas(ctx, prepared)
+	var reprepare *domain.ErrNeedReprepare
+	require.ErrorAs(t, err, &reprepare)
+	assert.Equal(t, "orders", reprepare.Table)
+	code, _ := mysqlerrors.Classify(err)
+	assert.Equal(t, mysqlerrors.ErrNeedReprepare, code)
+
+	// 重新 prepare 后按新的表结构执行
+	prepared, err = server.prepareStatement(ctx, "SELECT id, amount FROM orders WHERE id = ?")
+	require.NoError(t, err)
+	assert.NoError(t, server.checkTableSchemas(ctx, prepared))
+
+	require.NoError(t, ds.DropTable(ctx, "orders"))
+	assert.Error(t, server.checkTableSchemas(ctx, prepared))
+}
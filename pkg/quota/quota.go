@@ -0,0 +1,290 @@
+// Package quota 实现按表和按数据库的写入配额：限制行数和数据量（字节），
+// 可以按用户分别设置，写入会超出配额时拒绝
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// Quota 一条配额。Table 为空时限制整个数据库（数据源或虚拟数据库）；
+// User 为空时对所有用户生效，设置时只对该用户的写入生效，并取代同一范围内的通用配额
+type Quota struct {
+	Database string `json:"database"`
+	Table    string `json:"table,omitempty"`
+	User     string `json:"user,omitempty"`
+	MaxRows  int64  `json:"max_rows,omitempty"`  // 最大行数，0 表示不限制
+	MaxBytes int64  `json:"max_bytes,omitempty"` // 最大数据量（按值的文本长度估算），0 表示不限制
+}
+
+// Validate 检查配额的设置
+func (q Quota) Validate() error {
+	if q.Database == "" {
+		return fmt.Errorf("quota: database is required")
+	}
+	if q.MaxRows < 0 || q.MaxBytes < 0 {
+		return fmt.Errorf("quota on %s: max_rows and max_bytes must not be negative", q.Scope())
+	}
+	return nil
+}
+
+// Scope 返回配额的范围：db 或 db.table
+func (q Quota) Scope() string {
+	if q.Table == "" {
+		return q.Database
+	}
+	return q.Database + "." + q.Table
+}
+
+// Unlimited 行数和数据量都不限制，用于为个别用户取消通用配额
+func (q Quota) Unlimited() bool {
+	return q.MaxRows == 0 && q.MaxBytes == 0
+}
+
+// Usage 表或数据库的用量
+type Usage struct {
+	Rows  int64
+	Bytes int64
+}
+
+// ExceededError 写入会超出配额
+type ExceededError struct {
+	Quota Quota
+	Usage Usage // 写入前的用量
+	Rows  int64 // 写入的行数
+	Bytes int64 // 写入的数据量
+}
+
+func (e *ExceededError) Error() string {
+	who := ""
+	if e.Quota.User != "" {
+		who = fmt.Sprintf(" for user '%s'", e.Quota.User)
+	}
+	if e.Quota.MaxRows > 0 && e.Usage.Rows+e.Rows > e.Quota.MaxRows {
+		return fmt.Sprintf("Quota exceeded on '%s'%s: %d rows used, writing %d more would exceed max_rows %d",
+			e.Quota.Scope(), who, e.Usage.Rows, e.Rows, e.Quota.MaxRows)
+	}
+	return fmt.Sprintf("Quota exceeded on '%s'%s: %d bytes used, writing %d more would exceed max_bytes %d",
+		e.Quota.Scope(), who, e.Usage.Bytes, e.Bytes, e.Quota.MaxBytes)
+}
+
+// Manager 保存配额，并发安全
+type Manager struct {
+	mu     sync.RWMutex
+	quotas []Quota
+}
+
+// NewManager 创建配额管理器
+func NewManager(quotas []Quota) *Manager {
+	m := &Manager{}
+	m.Set(quotas)
+	return m
+}
+
+// Set 替换全部配额
+func (m *Manager) Set(quotas []Quota) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quotas = append([]Quota(nil), quotas...)
+}
+
+// List 返回全部配额，按库名、表名、用户排序
+func (m *Manager) List() []Quota {
+	m.mu.RLock()
+	quotas := append([]Quota(nil), m.quotas...)
+	m.mu.RUnlock()
+	sort.SliceStable(quotas, func(i, j int) bool {
+		a, b := quotas[i], quotas[j]
+		if !strings.EqualFold(a.Database, b.Database) {
+			return strings.ToLower(a.Database) < strings.ToLower(b.Database)
+		}
+		if !strings.EqualFold(a.Table, b.Table) {
+			return strings.ToLower(a.Table) < strings.ToLower(b.Table)
+		}
+		return a.User < b.User
+	})
+	return quotas
+}
+
+// Active 是否设置了任一配额
+func (m *Manager) Active() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.quotas) > 0
+}
+
+// Applicable 返回写入 database.table 时 user 受到的配额：表级和库级各最多一条，
+// 用户自己的配额优先于通用配额，不限制的配额不返回
+func (m *Manager) Applicable(user, database, table string) []Quota {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	scopes := []string{""}
+	if table != "" {
+		scopes = []string{table, ""}
+	}
+	var result []Quota
+	for _, scope := range scopes {
+		var general, own *Quota
+		for i := range m.quotas {
+			q := &m.quotas[i]
+			if !strings.EqualFold(q.Database, database) || !strings.EqualFold(q.Table, scope) {
+				continue
+			}
+			switch q.User {
+			case "":
+				general = q
+			case user:
+				own = q
+			}
+		}
+		if own != nil {
+			general = own
+		}
+		if general != nil && !general.Unlimited() {
+			result = append(result, *general)
+		}
+	}
+	return result
+}
+
+// Check 检查 user 向 database.table 写入 rows 行、bytes 字节后是否超出配额。
+// 写入量未知（rows 和 bytes 都为 0）时，配额已经用满即视为超出
+func (m *Manager) Check(ctx context.Context, catalog Catalog, user, database, table string, rows, bytes int64) error {
+	for _, q := range m.Applicable(user, database, table) {
+		usage, err := catalog.Measure(ctx, q.Database, q.Table)
+		if err != nil {
+			return err
+		}
+		if exceeds(q, usage, rows, bytes) {
+			return &ExceededError{Quota: q, Usage: usage, Rows: rows, Bytes: bytes}
+		}
+	}
+	return nil
+}
+
+func exceeds(q Quota, usage Usage, rows, bytes int64) bool {
+	unknown := rows == 0 && bytes == 0
+	if q.MaxRows > 0 && (usage.Rows+rows > q.MaxRows || unknown && usage.Rows >= q.MaxRows) {
+		return true
+	}
+	return q.MaxBytes > 0 && (usage.Bytes+bytes > q.MaxBytes || unknown && usage.Bytes >= q.MaxBytes)
+}
+
+// Catalog 按库名查找数据源或虚拟数据库，用于计算用量
+type Catalog struct {
+	DataSources *application.DataSourceManager
+	Virtual     *virtual.VirtualDatabaseRegistry
+}
+
+// Measure 计算表（table 为空时为整个数据库）的用量。
+// 用量通过读取全部行得到，只在设置了配额的范围内计算
+func (c Catalog) Measure(ctx context.Context, database, table string) (Usage, error) {
+	var usage Usage
+	tables, read, err := c.resolve(ctx, database)
+	if err != nil {
+		return usage, err
+	}
+	if table != "" {
+		t, ok := lookupTable(tables, table)
+		if !ok {
+			return usage, nil
+		}
+		tables = []string{t}
+	}
+	for _, t := range tables {
+		rows, err := read(ctx, t)
+		if err != nil {
+			return usage, fmt.Errorf("quota: read %s.%s: %w", database, t, err)
+		}
+		usage.Rows += int64(len(rows))
+		for _, row := range rows {
+			usage.Bytes += RowSize(row)
+		}
+	}
+	return usage, nil
+}
+
+type readFunc func(ctx context.Context, table string) ([]domain.Row, error)
+
+// resolve 返回数据库中的表和读取表的方法，数据源优先于同名的虚拟数据库
+func (c Catalog) resolve(ctx context.Context, database string) ([]string, readFunc, error) {
+	if c.DataSources != nil {
+		if ds, err := c.DataSources.Get(database); err == nil {
+			tables, err := ds.GetTables(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+			return tables, func(ctx context.Context, table string) ([]domain.Row, error) {
+				result, err := ds.Query(ctx, table, &domain.QueryOptions{})
+				if err != nil {
+					return nil, err
+				}
+				return result.Rows, nil
+			}, nil
+		}
+	}
+	if c.Virtual != nil {
+		if entry, ok := c.Virtual.Get(database); ok {
+			return entry.Provider.ListVirtualTables(), func(ctx context.Context, table string) ([]domain.Row, error) {
+				vt, err := entry.Provider.GetVirtualTable(table)
+				if err != nil {
+					return nil, err
+				}
+				result, err := vt.Query(ctx, nil, &domain.QueryOptions{})
+				if err != nil {
+					return nil, err
+				}
+				return result.Rows, nil
+			}, nil
+		}
+	}
+	// 库不存在时没有用量
+	return nil, func(context.Context, string) ([]domain.Row, error) { return nil, nil }, nil
+}
+
+// lookupTable 不区分大小写查找表名，表不存在时 ok 为 false（尚未创建的表没有用量）
+func lookupTable(tables []string, name string) (string, bool) {
+	for _, t := range tables {
+		if strings.EqualFold(t, name) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// RowSize 估算一行的数据量（各列值的文本长度之和）
+func RowSize(row domain.Row) int64 {
+	var size int64
+	for _, v := range row {
+		size += ValueSize(v)
+	}
+	return size
+}
+
+// ValueSize 估算一个值的数据量（文本长度）
+func ValueSize(v interface{}) int64 {
+	switch val := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(val))
+	case []byte:
+		return int64(len(val))
+	default:
+		return int64(len(fmt.Sprint(val)))
+	}
+}
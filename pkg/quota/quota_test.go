@@ -0,0 +1,130 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCatalog(t *testing.T) Catalog {
+	t.Helper()
+	ctx := context.Background()
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "app", Writable: true})
+	require.NoError(t, ds.Connect(ctx))
+	for _, name := range []string{"orders", "users"} {
+		require.NoError(t, ds.CreateTable(ctx, &domain.TableInfo{Name: name, Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+			{Name: "name", Type: "VARCHAR(32)"},
+		}}))
+	}
+	_, err := ds.Insert(ctx, "orders", []domain.Row{{"id": 1, "name": "abcd"}, {"id": 2, "name": "ef"}}, &domain.InsertOptions{})
+	require.NoError(t, err)
+	_, err = ds.Insert(ctx, "users", []domain.Row{{"id": 1, "name": "root"}}, &domain.InsertOptions{})
+	require.NoError(t, err)
+
+	manager := application.NewDataSourceManager()
+	require.NoError(t, manager.Register("app", ds))
+	return Catalog{DataSources: manager}
+}
+
+func TestCatalog_Measure(t *testing.T) {
+	ctx := context.Background()
+	catalog := newCatalog(t)
+
+	usage, err := catalog.Measure(ctx, "app", "ORDERS")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Rows: 2, Bytes: 8}, usage)
+
+	usage, err = catalog.Measure(ctx, "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Rows: 3, Bytes: 13}, usage)
+
+	usage, err = catalog.Measure(ctx, "app", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{}, usage)
+	usage, err = catalog.Measure(ctx, "nosuchdb", "")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{}, usage)
+}
+
+func TestCatalog_MeasureVirtualDatabase(t *testing.T) {
+	type item struct {
+		V string `db:"v"`
+	}
+	table, err := virtual.NewStructTable("items", []item{{V: "abc"}, {V: "de"}})
+	require.NoError(t, err)
+	registry := virtual.NewVirtualDatabaseRegistry()
+	require.NoError(t, registry.RegisterTable("vdb", table))
+
+	usage, err := Catalog{Virtual: registry}.Measure(context.Background(), "vdb", "")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Rows: 2, Bytes: 5}, usage)
+}
+
+func TestManager_Applicable(t *testing.T) {
+	m := NewManager([]Quota{
+		{Database: "app", MaxRows: 100},
+		{Database: "app", Table: "orders", MaxRows: 10},
+		{Database: "app", Table: "orders", User: "alice", MaxRows: 20},
+		{Database: "app", User: "admin"},
+	})
+
+	assert.Equal(t, []Quota{
+		{Database: "app", Table: "orders", MaxRows: 10},
+		{Database: "app", MaxRows: 100},
+	}, m.Applicable("bob", "APP", "Orders"))
+	assert.Equal(t, []Quota{
+		{Database: "app", Table: "orders", User: "alice", MaxRows: 20},
+		{Database: "app", MaxRows: 100},
+	}, m.Applicable("alice", "app", "orders"))
+	// 不限制的用户配额取消库级通用配额
+	assert.Equal(t, []Quota{{Database: "app", Table: "orders", MaxRows: 10}}, m.Applicable("admin", "app", "orders"))
+	assert.Equal(t, []Quota{{Database: "app", MaxRows: 100}}, m.Applicable("bob", "app", "users"))
+	assert.Empty(t, m.Applicable("bob", "other", "orders"))
+
+	assert.True(t, m.Active())
+	m.Set(nil)
+	assert.False(t, m.Active())
+	var nilManager *Manager
+	assert.False(t, nilManager.Active())
+}
+
+func TestManager_Check(t *testing.T) {
+	ctx := context.Background()
+	catalog := newCatalog(t)
+	m := NewManager([]Quota{
+		{Database: "app", Table: "orders", MaxRows: 3},
+		{Database: "app", MaxBytes: 20},
+	})
+
+	assert.NoError(t, m.Check(ctx, catalog, "bob", "app", "orders", 1, 2))
+	err := m.Check(ctx, catalog, "bob", "app", "orders", 2, 2)
+	var exceeded *ExceededError
+	require.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, "orders", exceeded.Quota.Table)
+	assert.Equal(t, Usage{Rows: 2, Bytes: 8}, exceeded.Usage)
+	assert.Contains(t, err.Error(), "max_rows 3")
+
+	err = m.Check(ctx, catalog, "bob", "app", "users", 1, 10)
+	require.True(t, errors.As(err, &exceeded))
+	assert.Contains(t, err.Error(), "'app': 13 bytes used, writing 10 more would exceed max_bytes 20")
+
+	// 写入量未知时，只有配额用满才拒绝
+	assert.NoError(t, m.Check(ctx, catalog, "bob", "app", "orders", 0, 0))
+	m.Set([]Quota{{Database: "app", Table: "orders", MaxRows: 2}})
+	assert.Error(t, m.Check(ctx, catalog, "bob", "app", "orders", 0, 0))
+}
+
+func TestQuota_Validate(t *testing.T) {
+	assert.NoError(t, Quota{Database: "app", MaxRows: 1}.Validate())
+	assert.Error(t, Quota{Table: "orders"}.Validate())
+	assert.Error(t, Quota{Database: "app", MaxBytes: -1}.Validate())
+	assert.Equal(t, "app.orders", Quota{Database: "app", Table: "orders"}.Scope())
+}
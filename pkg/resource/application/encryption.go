@@ -0,0 +1,16 @@
+package application
+
+import "github.com/kasuganosora/sqlexec/pkg/resource/domain"
+
+// SetKeyProvider 设置加密列的密钥来源，应用于已注册和之后注册的支持加密列的数据源
+func (m *DataSourceManager) SetKeyProvider(keys domain.KeyProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys = keys
+	for _, ds := range m.sources {
+		if mgr, ok := ds.(domain.ColumnEncryptionManager); ok {
+			mgr.SetKeyProvider(keys)
+		}
+	}
+}
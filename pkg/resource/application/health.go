@@ -0,0 +1,168 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== 数据源健康检查 ====================
+
+// 数据源健康状态
+const (
+	HealthUnknown     = "unknown"     // 尚未探测
+	HealthUp          = "up"          // 最近一次探测成功
+	HealthDown        = "down"        // 最近一次探测失败
+	HealthQuarantined = "quarantined" // 连续探测失败次数达到阈值，已隔离
+)
+
+// DefaultHealthCheckTimeout 单个数据源探测的默认超时时间
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// DataSourceHealth 数据源的健康状态（SHOW DATASOURCES、/readyz）
+type DataSourceHealth struct {
+	Name                string                `json:"name"`
+	Type                domain.DataSourceType `json:"type"`
+	Status              string                `json:"status"`
+	Latency             time.Duration         `json:"latency"`              // 最近一次探测耗时
+	LastCheck           time.Time             `json:"last_check"`           // 最近一次探测时间
+	LastError           string                `json:"last_error,omitempty"` // 最近一次探测失败的原因
+	ConsecutiveFailures int                   `json:"consecutive_failures"`
+}
+
+// healthState 健康检查状态，由 DataSourceManager 持有
+type healthState struct {
+	mu              sync.RWMutex
+	checks          map[string]*DataSourceHealth
+	quarantineAfter int // 连续失败多少次后隔离，0 表示不隔离
+}
+
+// SetQuarantineThreshold 设置连续探测失败多少次后隔离数据源，0 表示从不隔离
+// 被隔离的数据源上的语句立即失败，直到探测再次成功
+func (m *DataSourceManager) SetQuarantineThreshold(failures int) {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	m.health.quarantineAfter = failures
+	for _, h := range m.health.checks {
+		h.Status = healthStatus(h.ConsecutiveFailures, h.LastCheck, failures)
+	}
+}
+
+// CheckHealth 探测所有数据源并返回探测后的健康状态
+// 探测先检查 IsConnected，再执行 Ping（实现了 domain.HealthChecker 时）或 GetTables
+func (m *DataSourceManager) CheckHealth(ctx context.Context, timeout time.Duration) []DataSourceHealth {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	sources := m.GetAllDataSources()
+
+	var wg sync.WaitGroup
+	for name, ds := range sources {
+		wg.Add(1)
+		go func(name string, ds domain.DataSource) {
+			defer wg.Done()
+			start := time.Now()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := probeDataSource(probeCtx, ds)
+			cancel()
+			m.recordHealth(name, ds, start, time.Since(start), err)
+		}(name, ds)
+	}
+	wg.Wait()
+	return m.Health()
+}
+
+// probeDataSource 对数据源执行一次存活探测
+func probeDataSource(ctx context.Context, ds domain.DataSource) error {
+	if !ds.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+	if checker, ok := ds.(domain.HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	_, err := ds.GetTables(ctx)
+	return err
+}
+
+// recordHealth 记录一次探测结果
+func (m *DataSourceManager) recordHealth(name string, ds domain.DataSource, checkedAt time.Time, latency time.Duration, err error) {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+
+	h := m.health.checks[name]
+	if h == nil {
+		h = &DataSourceHealth{Name: name}
+		m.health.checks[name] = h
+	}
+	if cfg := ds.GetConfig(); cfg != nil {
+		h.Type = cfg.Type
+	}
+	h.LastCheck = checkedAt
+	h.Latency = latency
+	if err != nil {
+		h.LastError = err.Error()
+		h.ConsecutiveFailures++
+	} else {
+		h.LastError = ""
+		h.ConsecutiveFailures = 0
+	}
+	h.Status = healthStatus(h.ConsecutiveFailures, h.LastCheck, m.health.quarantineAfter)
+}
+
+// healthStatus 根据连续失败次数计算状态
+func healthStatus(failures int, lastCheck time.Time, quarantineAfter int) string {
+	switch {
+	case lastCheck.IsZero():
+		return HealthUnknown
+	case failures == 0:
+		return HealthUp
+	case quarantineAfter > 0 && failures >= quarantineAfter:
+		return HealthQuarantined
+	default:
+		return HealthDown
+	}
+}
+
+// Health 返回所有已注册数据源最近一次探测的健康状态，按名称排序
+func (m *DataSourceManager) Health() []DataSourceHealth {
+	sources := m.GetAllDataSources()
+
+	m.health.mu.RLock()
+	defer m.health.mu.RUnlock()
+	result := make([]DataSourceHealth, 0, len(sources))
+	for name, ds := range sources {
+		if h := m.health.checks[name]; h != nil {
+			result = append(result, *h)
+			continue
+		}
+		h := DataSourceHealth{Name: name, Status: HealthUnknown}
+		if cfg := ds.GetConfig(); cfg != nil {
+			h.Type = cfg.Type
+		}
+		result = append(result, h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// CheckQuarantine 数据源被隔离时返回 domain.ErrQuarantined
+func (m *DataSourceManager) CheckQuarantine(name string) error {
+	m.health.mu.RLock()
+	defer m.health.mu.RUnlock()
+	h := m.health.checks[name]
+	if h == nil || h.Status != HealthQuarantined {
+		return nil
+	}
+	return domain.NewErrQuarantined(name, fmt.Sprintf("%d consecutive failed health checks, last error: %s", h.ConsecutiveFailures, h.LastError))
+}
+
+// forgetHealth 注销数据源时删除其健康状态
+func (m *DataSourceManager) forgetHealth(name string) {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	delete(m.health.checks, name)
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// TestDataSourceManager_CheckHealth 测试数据源存活探测与隔离
+func TestDataSourceManager_CheckHealth(t *testing.T) {
+	manager := NewDataSourceManager()
+	manager.SetQuarantineThreshold(2)
+
+	up := &MockDataSource{connected: true}
+	down := &MockDataSource{connected: false}
+	if err := manager.Register("a-up", up); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := manager.Register("b-down", down); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	health := manager.Health()
+	if len(health) != 2 || health[0].Status != HealthUnknown || health[1].Status != HealthUnknown {
+		t.Fatalf("Expected two unknown data sources before the first probe, got %+v", health)
+	}
+
+	health = manager.CheckHealth(context.Background(), 0)
+	if health[0].Name != "a-up" || health[0].Status != HealthUp {
+		t.Errorf("Expected a-up to be up, got %+v", health[0])
+	}
+	if health[1].Status != HealthDown || health[1].LastError == "" || health[1].Type != "mock" {
+		t.Errorf("Expected b-down to be down with an error, got %+v", health[1])
+	}
+	if err := manager.CheckQuarantine("b-down"); err != nil {
+		t.Errorf("Expected b-down not to be quarantined after one failure, got %v", err)
+	}
+
+	manager.CheckHealth(context.Background(), 0)
+	err := manager.CheckQuarantine("b-down")
+	if err == nil || !domain.IsRetryable(err) {
+		t.Errorf("Expected b-down to be quarantined, got %v", err)
+	}
+	if err := manager.CheckQuarantine("a-up"); err != nil {
+		t.Errorf("Expected a-up not to be quarantined, got %v", err)
+	}
+
+	// 关闭隔离后立即解除
+	manager.SetQuarantineThreshold(0)
+	if err := manager.CheckQuarantine("b-down"); err != nil {
+		t.Errorf("Expected quarantine to be lifted, got %v", err)
+	}
+
+	// 探测成功后恢复
+	manager.SetQuarantineThreshold(1)
+	down.connected = true
+	health = manager.CheckHealth(context.Background(), 0)
+	if health[1].Status != HealthUp || health[1].ConsecutiveFailures != 0 {
+		t.Errorf("Expected b-down to recover, got %+v", health[1])
+	}
+
+	// 注销后不再保留健康状态
+	if err := manager.Unregister("b-down"); err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if len(manager.Health()) != 1 {
+		t.Errorf("Expected health of unregistered data source to be dropped")
+	}
+	if _, ok := manager.health.checks["b-down"]; ok {
+		t.Errorf("Expected health state of b-down to be removed")
+	}
+}
@@ -16,6 +16,10 @@ type DataSourceManager struct {
 	registry     *Registry
 	defaultDS    string
 	enabledTypes map[domain.DataSourceType]bool
+	health       *healthState
+	schema       *schemaState
+	shadow       *shadowState
+	keys         domain.KeyProvider // 加密列的密钥来源
 	mu           sync.RWMutex
 }
 
@@ -25,6 +29,9 @@ func NewDataSourceManager() *DataSourceManager {
 		sources:      make(map[string]domain.DataSource),
 		registry:     NewRegistry(),
 		enabledTypes: make(map[domain.DataSourceType]bool),
+		health:       &healthState{checks: make(map[string]*DataSourceHealth)},
+		schema:       &schemaState{snapshots: make(map[string]map[string]*domain.TableInfo)},
+		shadow:       &shadowState{tables: make(map[string]*ShadowTable)},
 	}
 }
 
@@ -34,6 +41,9 @@ func NewDataSourceManagerWithRegistry(registry *Registry) *DataSourceManager {
 		sources:      make(map[string]domain.DataSource),
 		registry:     registry,
 		enabledTypes: make(map[domain.DataSourceType]bool),
+		health:       &healthState{checks: make(map[string]*DataSourceHealth)},
+		schema:       &schemaState{snapshots: make(map[string]map[string]*domain.TableInfo)},
+		shadow:       &shadowState{tables: make(map[string]*ShadowTable)},
 	}
 }
 
@@ -61,6 +71,9 @@ func (m *DataSourceManager) Register(name string, ds domain.DataSource) error {
 		m.defaultDS = name
 	}
 
+	if mgr, ok := ds.(domain.ColumnEncryptionManager); ok && m.keys != nil {
+		mgr.SetKeyProvider(m.keys)
+	}
 	m.sources[name] = ds
 	return nil
 }
@@ -81,6 +94,9 @@ func (m *DataSourceManager) Unregister(name string) error {
 	}
 
 	delete(m.sources, name)
+	m.forgetHealth(name)
+	m.forgetSchema(name)
+	m.forgetShadows(name)
 
 	// 如果删除的是默认数据源，重新设置默认值
 	if m.defaultDS == name {
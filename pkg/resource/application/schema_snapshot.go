@@ -0,0 +1,184 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== 表结构快照与漂移检测 ====================
+// 定期为每个数据源的表结构拍摄快照，与上一次快照比较得到新增、删除的表和列以及类型改变的列。
+// 插件数据源的表结构由上游决定，依赖虚拟表结构的使用方据此发现上游的变化
+
+// 表结构变化类型
+const (
+	SchemaTableAdded    = "table_added"
+	SchemaTableRemoved  = "table_removed"
+	SchemaColumnAdded   = "column_added"
+	SchemaColumnRemoved = "column_removed"
+	SchemaColumnRetyped = "column_retyped"
+)
+
+// maxSchemaChanges 保留的表结构变化记录数，超出时丢弃最早的记录
+const maxSchemaChanges = 1000
+
+// SchemaChange 两次快照之间的一处表结构变化（SHOW SCHEMA CHANGES）
+type SchemaChange struct {
+	Database   string    `json:"database"`
+	Table      string    `json:"table"`
+	Column     string    `json:"column,omitempty"` // 表的增删为空
+	Change     string    `json:"change"`
+	OldType    string    `json:"old_type,omitempty"` // 删除或改变类型的列原来的类型
+	NewType    string    `json:"new_type,omitempty"` // 新增或改变类型的列现在的类型
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// schemaState 表结构快照状态，由 DataSourceManager 持有
+type schemaState struct {
+	mu        sync.Mutex
+	snapshots map[string]map[string]*domain.TableInfo // 数据源 -> 表名 -> 表结构
+	changes   []SchemaChange
+}
+
+// SnapshotSchemas 为每个数据源的表结构拍摄快照，返回与上一次快照相比的变化并计入变化记录。
+// 数据源的第一次快照只作为比较的基准；读取失败的数据源或表保留上一次的快照
+func (m *DataSourceManager) SnapshotSchemas(ctx context.Context) ([]SchemaChange, error) {
+	sources := m.GetAllDataSources()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m.schema.mu.Lock()
+	defer m.schema.mu.Unlock()
+
+	now := time.Now()
+	var changes []SchemaChange
+	var errs []error
+	for _, name := range names {
+		previous, seen := m.schema.snapshots[name]
+		current, err := snapshotDataSource(ctx, sources[name], previous)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("snapshot schema of data source %s: %w", name, err))
+			continue
+		}
+		m.schema.snapshots[name] = current
+		if seen {
+			changes = append(changes, diffSchemas(name, previous, current, now)...)
+		}
+	}
+	for name := range m.schema.snapshots {
+		if _, ok := sources[name]; !ok {
+			delete(m.schema.snapshots, name)
+		}
+	}
+
+	m.schema.changes = append(m.schema.changes, changes...)
+	if over := len(m.schema.changes) - maxSchemaChanges; over > 0 {
+		m.schema.changes = append([]SchemaChange(nil), m.schema.changes[over:]...)
+	}
+	return changes, errors.Join(errs...)
+}
+
+// SchemaChanges 返回记录的表结构变化，按检测时间排序；database 不为空时只返回该数据源的变化
+func (m *DataSourceManager) SchemaChanges(database string) []SchemaChange {
+	m.schema.mu.Lock()
+	defer m.schema.mu.Unlock()
+	result := make([]SchemaChange, 0, len(m.schema.changes))
+	for _, change := range m.schema.changes {
+		if database == "" || change.Database == database {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// snapshotDataSource 读取数据源所有表的结构，读取失败的表沿用 previous 中的结构
+func snapshotDataSource(ctx context.Context, ds domain.DataSource, previous map[string]*domain.TableInfo) (map[string]*domain.TableInfo, error) {
+	tables, err := ds.GetTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]*domain.TableInfo, len(tables))
+	for _, table := range tables {
+		info, err := ds.GetTableInfo(ctx, table)
+		if err != nil {
+			if old, ok := previous[table]; ok {
+				snapshot[table] = old
+			}
+			continue
+		}
+		// 数据源可能返回内部的表结构，复制列以免随之改变
+		copied := *info
+		copied.Columns = append([]domain.ColumnInfo(nil), info.Columns...)
+		snapshot[table] = &copied
+	}
+	return snapshot, nil
+}
+
+// diffSchemas 比较数据源的两次快照，结果按表名、列的顺序排列
+func diffSchemas(database string, previous, current map[string]*domain.TableInfo, at time.Time) []SchemaChange {
+	tables := make([]string, 0, len(previous)+len(current))
+	for table := range previous {
+		tables = append(tables, table)
+	}
+	for table := range current {
+		if _, ok := previous[table]; !ok {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+
+	var changes []SchemaChange
+	add := func(table, column, change, oldType, newType string) {
+		changes = append(changes, SchemaChange{Database: database, Table: table, Column: column, Change: change,
+			OldType: oldType, NewType: newType, DetectedAt: at})
+	}
+	for _, table := range tables {
+		before, after := previous[table], current[table]
+		switch {
+		case before == nil:
+			add(table, "", SchemaTableAdded, "", "")
+			continue
+		case after == nil:
+			add(table, "", SchemaTableRemoved, "", "")
+			continue
+		}
+		oldColumns := make(map[string]domain.ColumnInfo, len(before.Columns))
+		for _, col := range before.Columns {
+			oldColumns[strings.ToLower(col.Name)] = col
+		}
+		newColumns := make(map[string]bool, len(after.Columns))
+		for _, col := range after.Columns {
+			key := strings.ToLower(col.Name)
+			newColumns[key] = true
+			old, ok := oldColumns[key]
+			switch {
+			case !ok:
+				add(table, col.Name, SchemaColumnAdded, "", col.Type)
+			case !strings.EqualFold(old.Type, col.Type):
+				add(table, col.Name, SchemaColumnRetyped, old.Type, col.Type)
+			}
+		}
+		for _, col := range before.Columns {
+			if !newColumns[strings.ToLower(col.Name)] {
+				add(table, col.Name, SchemaColumnRemoved, col.Type, "")
+			}
+		}
+	}
+	return changes
+}
+
+// forgetSchema 注销数据源时删除其快照，已记录的变化保留
+func (m *DataSourceManager) forgetSchema(name string) {
+	m.schema.mu.Lock()
+	defer m.schema.mu.Unlock()
+	delete(m.schema.snapshots, name)
+}
@@ -0,0 +1,183 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==================== 表影子迁移 ====================
+// 把表从一个数据源迁移到另一个数据源时，先让新数据源中的同名表作为影子：
+// 读取由主表提供（可选地与影子比较并记录不一致），写入同时镜像到影子；
+// 切换（cutover）后两者角色互换，旧表继续接收镜像写入以便回退
+
+// ShadowTable 一个处于影子迁移中的表（SHOW SHADOW TABLES）
+type ShadowTable struct {
+	Database       string    `json:"database"` // 表原来所在的数据源
+	Table          string    `json:"table"`
+	Shadow         string    `json:"shadow"`          // 迁移目标数据源，其中有同名的表
+	Compare        bool      `json:"compare"`         // 读取时是否与影子比较
+	CutOver        bool      `json:"cut_over"`        // 已切换：读写以迁移目标为主
+	Since          time.Time `json:"since"`           // 开始影子迁移的时间
+	MirroredWrites int64     `json:"mirrored_writes"` // 成功镜像的写入语句数
+	MirrorErrors   int64     `json:"mirror_errors"`   // 镜像失败的写入语句数
+	ComparedReads  int64     `json:"compared_reads"`  // 与影子比较过的读取语句数
+	Mismatches     int64     `json:"mismatches"`      // 结果不一致的读取语句数
+	LastError      string    `json:"last_error,omitempty"`
+}
+
+// Primary 当前提供读取、先执行写入的数据源
+func (t ShadowTable) Primary() string {
+	if t.CutOver {
+		return t.Shadow
+	}
+	return t.Database
+}
+
+// Mirror 当前接收镜像写入、参与读取比较的数据源
+func (t ShadowTable) Mirror() string {
+	if t.CutOver {
+		return t.Database
+	}
+	return t.Shadow
+}
+
+// shadowState 影子迁移中的表，由 DataSourceManager 持有
+type shadowState struct {
+	mu     sync.Mutex
+	tables map[string]*ShadowTable // 小写的 数据源.表名 -> 状态
+}
+
+func shadowKey(database, table string) string {
+	return strings.ToLower(database) + "." + strings.ToLower(table)
+}
+
+// StartShadow 开始影子迁移：数据源 database 中的表 table 继续作为主表，
+// 写入镜像到数据源 shadow 中的同名表，compare 为 true 时读取与影子比较
+func (m *DataSourceManager) StartShadow(ctx context.Context, database, table, shadow string, compare bool) error {
+	if strings.EqualFold(database, shadow) {
+		return fmt.Errorf("table %s.%s cannot shadow itself", database, table)
+	}
+	for _, name := range []string{database, shadow} {
+		ds, err := m.Get(name)
+		if err != nil {
+			return err
+		}
+		if _, err := ds.GetTableInfo(ctx, table); err != nil {
+			return fmt.Errorf("table %s.%s: %w", name, table, err)
+		}
+	}
+
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	key := shadowKey(database, table)
+	if existing, ok := m.shadow.tables[key]; ok {
+		return fmt.Errorf("table %s.%s is already shadowed by %s", database, table, existing.Shadow)
+	}
+	m.shadow.tables[key] = &ShadowTable{Database: database, Table: table, Shadow: shadow, Compare: compare, Since: time.Now()}
+	return nil
+}
+
+// CutoverShadow 切换影子迁移中的表的主从角色，再次切换即回退
+func (m *DataSourceManager) CutoverShadow(database, table string) (ShadowTable, error) {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	st, ok := m.shadow.tables[shadowKey(database, table)]
+	if !ok {
+		return ShadowTable{}, fmt.Errorf("table %s.%s is not shadowed", database, table)
+	}
+	st.CutOver = !st.CutOver
+	return *st, nil
+}
+
+// StopShadow 结束影子迁移，不再镜像写入；表重新由访问它的数据库提供
+func (m *DataSourceManager) StopShadow(database, table string) (ShadowTable, error) {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	key := shadowKey(database, table)
+	st, ok := m.shadow.tables[key]
+	if !ok {
+		return ShadowTable{}, fmt.Errorf("table %s.%s is not shadowed", database, table)
+	}
+	delete(m.shadow.tables, key)
+	return *st, nil
+}
+
+// ShadowFor 返回数据源 database 中的表 table 的影子迁移状态
+func (m *DataSourceManager) ShadowFor(database, table string) (ShadowTable, bool) {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	if st, ok := m.shadow.tables[shadowKey(database, table)]; ok {
+		return *st, true
+	}
+	return ShadowTable{}, false
+}
+
+// HasShadows 是否有表处于影子迁移中，没有时语句无需检查所访问的表
+func (m *DataSourceManager) HasShadows() bool {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	return len(m.shadow.tables) > 0
+}
+
+// ShadowTables 返回所有影子迁移中的表，按数据源、表名排序
+func (m *DataSourceManager) ShadowTables() []ShadowTable {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	result := make([]ShadowTable, 0, len(m.shadow.tables))
+	for _, st := range m.shadow.tables {
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Database != result[j].Database {
+			return result[i].Database < result[j].Database
+		}
+		return result[i].Table < result[j].Table
+	})
+	return result
+}
+
+// RecordShadowWrite 记录一次镜像写入的结果
+func (m *DataSourceManager) RecordShadowWrite(database, table string, err error) {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	st, ok := m.shadow.tables[shadowKey(database, table)]
+	if !ok {
+		return
+	}
+	if err != nil {
+		st.MirrorErrors++
+		st.LastError = err.Error()
+		return
+	}
+	st.MirroredWrites++
+}
+
+// RecordShadowRead 记录一次读取比较的结果，mismatch 描述不一致之处，为空表示一致
+func (m *DataSourceManager) RecordShadowRead(database, table, mismatch string) {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	st, ok := m.shadow.tables[shadowKey(database, table)]
+	if !ok {
+		return
+	}
+	st.ComparedReads++
+	if mismatch != "" {
+		st.Mismatches++
+		st.LastError = mismatch
+	}
+}
+
+// forgetShadows 注销数据源时结束以它为主表或影子的影子迁移
+func (m *DataSourceManager) forgetShadows(name string) {
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+	for key, st := range m.shadow.tables {
+		if st.Database == name || st.Shadow == name {
+			delete(m.shadow.tables, key)
+		}
+	}
+}
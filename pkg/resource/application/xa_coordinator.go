@@ -0,0 +1,274 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== 两阶段提交协调者 ====================
+
+// xidPrefix 协调者生成的 xid 前缀，恢复时只处理带此前缀的分支
+const xidPrefix = "sqlexec-xa-"
+
+// XAParticipant 跨数据源事务的参与者
+type XAParticipant struct {
+	DataSource string             // 数据源名称
+	Tx         domain.Transaction // 该数据源上的事务分支
+}
+
+// ErrXAInDoubt 已决定提交但部分分支提交失败，由 Recover 继续完成
+type ErrXAInDoubt struct {
+	XID         string
+	DataSources []string
+	Err         error
+}
+
+func (e *ErrXAInDoubt) Error() string {
+	return fmt.Sprintf("transaction %s committed but data sources %s are pending recovery: %v",
+		e.XID, strings.Join(e.DataSources, ", "), e.Err)
+}
+
+func (e *ErrXAInDoubt) Unwrap() error {
+	return e.Err
+}
+
+// XARecoveryResult 一次恢复的结果
+type XARecoveryResult struct {
+	Committed  []string // 按日志中的提交决定提交的分支
+	RolledBack []string // 没有提交决定而回滚的分支
+	Pending    []string // 仍未完成的事务（参与者不可用或提交失败）
+}
+
+// XACoordinator 跨数据源事务的两阶段提交协调者
+// 第一阶段准备所有分支，全部成功后将提交决定写入恢复日志，第二阶段逐个提交；
+// 任一分支准备失败则全部回滚。协调者崩溃或提交失败后由 Recover 完成不确定的分支
+type XACoordinator struct {
+	manager  *DataSourceManager
+	log      XALog
+	mu       sync.Mutex
+	seq      uint64
+	inflight map[string]bool // 正在提交的事务，恢复时跳过
+}
+
+// NewXACoordinator 创建协调者，log 为 nil 时使用内存恢复日志
+func NewXACoordinator(manager *DataSourceManager, log XALog) *XACoordinator {
+	if log == nil {
+		log = NewMemoryXALog()
+	}
+	return &XACoordinator{
+		manager:  manager,
+		log:      log,
+		inflight: make(map[string]bool),
+	}
+}
+
+// Log 返回恢复日志
+func (c *XACoordinator) Log() XALog {
+	return c.log
+}
+
+// Commit 提交跨数据源事务
+// 只有一个参与者时直接提交；多个参与者时要求每个分支都实现 domain.XATransaction
+func (c *XACoordinator) Commit(ctx context.Context, participants []XAParticipant) error {
+	switch len(participants) {
+	case 0:
+		return nil
+	case 1:
+		return participants[0].Tx.Commit(ctx)
+	}
+
+	for _, p := range participants {
+		if _, ok := p.Tx.(domain.XATransaction); !ok {
+			c.rollbackAll(ctx, participants)
+			return fmt.Errorf("data source %s does not support two-phase commit", p.DataSource)
+		}
+	}
+
+	xid := c.begin()
+	defer c.end(xid)
+
+	// 第一阶段：准备
+	for i, p := range participants {
+		if err := p.Tx.(domain.XATransaction).Prepare(ctx, branchXID(xid, i)); err != nil {
+			c.rollbackAll(ctx, participants)
+			return fmt.Errorf("prepare failed on data source %s: %w", p.DataSource, err)
+		}
+	}
+
+	// 提交点：提交决定持久化后事务即视为已提交
+	rec := XALogRecord{XID: xid, Time: time.Now()}
+	for _, p := range participants {
+		rec.DataSources = append(rec.DataSources, p.DataSource)
+	}
+	if err := c.log.LogCommit(rec); err != nil {
+		c.rollbackAll(ctx, participants)
+		return fmt.Errorf("failed to log commit decision: %w", err)
+	}
+
+	// 第二阶段：提交
+	var failed []string
+	var firstErr error
+	for _, p := range participants {
+		if err := p.Tx.Commit(ctx); err != nil {
+			failed = append(failed, p.DataSource)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return &ErrXAInDoubt{XID: xid, DataSources: failed, Err: firstErr}
+	}
+	return c.log.LogEnd(xid)
+}
+
+// Rollback 回滚所有参与者，返回第一个错误
+func (c *XACoordinator) Rollback(ctx context.Context, participants []XAParticipant) error {
+	return c.rollbackAll(ctx, participants)
+}
+
+func (c *XACoordinator) rollbackAll(ctx context.Context, participants []XAParticipant) error {
+	var firstErr error
+	for _, p := range participants {
+		if err := p.Tx.Rollback(ctx); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("rollback failed on data source %s: %w", p.DataSource, err)
+		}
+	}
+	return firstErr
+}
+
+// Recover 完成所有已注册数据源上不确定的分支：
+// 日志中有提交决定的分支提交，其余本协调者生成的已准备分支回滚
+func (c *XACoordinator) Recover(ctx context.Context) (*XARecoveryResult, error) {
+	return c.recover(ctx, c.manager.List())
+}
+
+// RecoverDataSource 只完成指定数据源上不确定的分支，用于数据源注册后
+func (c *XACoordinator) RecoverDataSource(ctx context.Context, name string) (*XARecoveryResult, error) {
+	return c.recover(ctx, []string{name})
+}
+
+func (c *XACoordinator) recover(ctx context.Context, names []string) (*XARecoveryResult, error) {
+	records, err := c.log.Pending()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xa log: %w", err)
+	}
+	decided := make(map[string]XALogRecord, len(records))
+	for _, rec := range records {
+		decided[rec.XID] = rec
+	}
+
+	result := &XARecoveryResult{}
+	unresolved := make(map[string]bool) // 仍有分支未完成的事务
+	checked := make(map[string]bool)    // 已成功检查的数据源
+	var firstErr error
+	for _, name := range names {
+		ds, err := c.manager.Get(name)
+		if err != nil {
+			continue
+		}
+		xads, ok := ds.(domain.XADataSource)
+		if !ok {
+			continue
+		}
+		branches, err := xads.RecoverXA(ctx)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("recover on data source %s failed: %w", name, err)
+			}
+			continue
+		}
+		checked[name] = true
+
+		for _, branch := range branches {
+			xid, ok := globalXID(branch)
+			if !ok || c.isInflight(xid) {
+				continue
+			}
+			if _, committed := decided[xid]; committed {
+				if err := xads.CommitXA(ctx, branch); err != nil {
+					unresolved[xid] = true
+					if firstErr == nil {
+						firstErr = fmt.Errorf("commit of %s on data source %s failed: %w", branch, name, err)
+					}
+					continue
+				}
+				result.Committed = append(result.Committed, branch)
+			} else {
+				if err := xads.RollbackXA(ctx, branch); err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("rollback of %s on data source %s failed: %w", branch, name, err)
+					}
+					continue
+				}
+				result.RolledBack = append(result.RolledBack, branch)
+			}
+		}
+	}
+
+	// 所有参与者都已检查且没有剩余分支的事务结束；参与者不可用的继续保留
+	for _, rec := range records {
+		if c.isInflight(rec.XID) {
+			continue
+		}
+		done := !unresolved[rec.XID]
+		for _, name := range rec.DataSources {
+			if !checked[name] {
+				done = false
+			}
+		}
+		if !done {
+			result.Pending = append(result.Pending, rec.XID)
+			continue
+		}
+		if err := c.log.LogEnd(rec.XID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return result, firstErr
+}
+
+// begin 生成新的 xid 并标记为正在提交
+func (c *XACoordinator) begin() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	xid := xidPrefix + strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(c.seq, 10)
+	c.inflight[xid] = true
+	return xid
+}
+
+func (c *XACoordinator) end(xid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.inflight, xid)
+}
+
+func (c *XACoordinator) isInflight(xid string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inflight[xid]
+}
+
+// branchXID 分支 xid：全局 xid 加分支序号
+func branchXID(xid string, i int) string {
+	return xid + "." + strconv.Itoa(i)
+}
+
+// globalXID 从分支 xid 取出全局 xid，非本协调者生成的返回 false
+func globalXID(branch string) (string, bool) {
+	if !strings.HasPrefix(branch, xidPrefix) {
+		return "", false
+	}
+	i := strings.LastIndex(branch, ".")
+	if i < 0 {
+		return "", false
+	}
+	return branch[:i], true
+}
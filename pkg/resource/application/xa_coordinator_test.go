@@ -0,0 +1,184 @@
+package application
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// newXATestManager 注册两个各有一张 items 表的内存数据源 a、b
+func newXATestManager(t *testing.T) *DataSourceManager {
+	t.Helper()
+	ctx := context.Background()
+	manager := NewDataSourceManager()
+	for _, name := range []string{"a", "b"} {
+		ds := memory.NewMVCCDataSource(nil)
+		if err := ds.Connect(ctx); err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+		t.Cleanup(func() { ds.Close(ctx) })
+		if err := ds.CreateTable(ctx, &domain.TableInfo{
+			Name:    "items",
+			Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}},
+		}); err != nil {
+			t.Fatalf("CreateTable() error = %v", err)
+		}
+		if err := manager.Register(name, ds); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	return manager
+}
+
+// beginXABranches 在 a、b 上各开启一个插入了一行的事务
+func beginXABranches(t *testing.T, manager *DataSourceManager) []XAParticipant {
+	t.Helper()
+	ctx := context.Background()
+	var participants []XAParticipant
+	for _, name := range []string{"a", "b"} {
+		ds, _ := manager.Get(name)
+		tx, err := ds.(domain.TransactionalDataSource).BeginTransaction(ctx, nil)
+		if err != nil {
+			t.Fatalf("BeginTransaction() error = %v", err)
+		}
+		if _, err := tx.Insert(ctx, "items", []domain.Row{{"id": int64(1)}}, nil); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+		participants = append(participants, XAParticipant{DataSource: name, Tx: tx})
+	}
+	return participants
+}
+
+func countItems(t *testing.T, manager *DataSourceManager, name string) int {
+	t.Helper()
+	result, err := manager.Query(context.Background(), name, "items", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	return len(result.Rows)
+}
+
+// TestXACoordinator_Commit 测试两阶段提交在所有数据源上生效
+func TestXACoordinator_Commit(t *testing.T) {
+	manager := newXATestManager(t)
+	c := NewXACoordinator(manager, nil)
+
+	if err := c.Commit(context.Background(), beginXABranches(t, manager)); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if n := countItems(t, manager, name); n != 1 {
+			t.Errorf("rows in %s = %d, want 1", name, n)
+		}
+	}
+	if pending, _ := c.Log().Pending(); len(pending) != 0 {
+		t.Errorf("pending log records after commit = %v", pending)
+	}
+}
+
+// TestXACoordinator_PrepareFailure 测试任一分支准备失败时所有分支回滚
+func TestXACoordinator_PrepareFailure(t *testing.T) {
+	manager := newXATestManager(t)
+	c := NewXACoordinator(manager, nil)
+	ctx := context.Background()
+
+	participants := beginXABranches(t, manager)
+	// 已准备的事务不能再次准备，使 b 的准备失败
+	if err := participants[1].Tx.(domain.XATransaction).Prepare(ctx, "other"); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	if err := c.Commit(ctx, participants); err == nil {
+		t.Fatal("expected Commit() to fail")
+	}
+	for _, name := range []string{"a", "b"} {
+		if n := countItems(t, manager, name); n != 0 {
+			t.Errorf("rows in %s = %d, want 0", name, n)
+		}
+	}
+}
+
+// TestXACoordinator_Recover 测试协调者崩溃后按恢复日志完成不确定的分支
+func TestXACoordinator_Recover(t *testing.T) {
+	manager := newXATestManager(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "xa.log")
+
+	// 模拟崩溃：两个分支都已准备、提交决定已写入日志，但尚未提交
+	log, err := OpenFileXALog(path)
+	if err != nil {
+		t.Fatalf("OpenFileXALog() error = %v", err)
+	}
+	decided := xidPrefix + "decided"
+	for i, p := range beginXABranches(t, manager) {
+		if err := p.Tx.(domain.XATransaction).Prepare(ctx, branchXID(decided, i)); err != nil {
+			t.Fatalf("Prepare() error = %v", err)
+		}
+	}
+	if err := log.LogCommit(XALogRecord{XID: decided, DataSources: []string{"a", "b"}}); err != nil {
+		t.Fatalf("LogCommit() error = %v", err)
+	}
+	// 没有提交决定的已准备分支
+	ds, _ := manager.Get("a")
+	orphan, _ := ds.(domain.TransactionalDataSource).BeginTransaction(ctx, nil)
+	if _, err := orphan.Insert(ctx, "items", []domain.Row{{"id": int64(2)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := orphan.(domain.XATransaction).Prepare(ctx, branchXID(xidPrefix+"undecided", 0)); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	log.Close()
+
+	log, err = OpenFileXALog(path)
+	if err != nil {
+		t.Fatalf("reopen OpenFileXALog() error = %v", err)
+	}
+	defer log.Close()
+	c := NewXACoordinator(manager, log)
+	result, err := c.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if len(result.Committed) != 2 || len(result.RolledBack) != 1 || len(result.Pending) != 0 {
+		t.Errorf("Recover() = %+v, want 2 committed and 1 rolled back", result)
+	}
+	for _, name := range []string{"a", "b"} {
+		if n := countItems(t, manager, name); n != 1 {
+			t.Errorf("rows in %s = %d, want 1", name, n)
+		}
+	}
+	if pending, _ := log.Pending(); len(pending) != 0 {
+		t.Errorf("pending log records after recovery = %v", pending)
+	}
+}
+
+// TestFileXALog_Replay 测试文件日志重放与压缩
+func TestFileXALog_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xa", "xa.log")
+	log, err := OpenFileXALog(path)
+	if err != nil {
+		t.Fatalf("OpenFileXALog() error = %v", err)
+	}
+	for _, xid := range []string{"x1", "x2"} {
+		if err := log.LogCommit(XALogRecord{XID: xid, DataSources: []string{"a"}}); err != nil {
+			t.Fatalf("LogCommit() error = %v", err)
+		}
+	}
+	if err := log.LogEnd("x1"); err != nil {
+		t.Fatalf("LogEnd() error = %v", err)
+	}
+	log.Close()
+
+	log, err = OpenFileXALog(path)
+	if err != nil {
+		t.Fatalf("reopen OpenFileXALog() error = %v", err)
+	}
+	defer log.Close()
+	pending, _ := log.Pending()
+	if len(pending) != 1 || pending[0].XID != "x2" || pending[0].DataSources[0] != "a" {
+		t.Errorf("Pending() = %+v, want only x2", pending)
+	}
+}
@@ -0,0 +1,243 @@
+package application
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ==================== 两阶段提交恢复日志 ====================
+
+// XALogRecord 提交决定记录：所有参与者准备成功后写入，全部提交后结束
+type XALogRecord struct {
+	XID         string    `json:"xid"`
+	DataSources []string  `json:"data_sources"` // 参与者数据源，下标即分支序号
+	Time        time.Time `json:"time"`
+}
+
+// XALog 协调者的恢复日志
+// 只记录提交决定（推定回滚）：恢复时没有记录的已准备分支一律回滚
+type XALog interface {
+	// LogCommit 持久化提交决定，返回后事务视为已提交
+	LogCommit(rec XALogRecord) error
+
+	// LogEnd 记录事务的所有分支均已完成
+	LogEnd(xid string) error
+
+	// Pending 返回已决定提交但尚未完成的事务
+	Pending() ([]XALogRecord, error)
+
+	// Close 关闭日志
+	Close() error
+}
+
+// MemoryXALog 内存恢复日志，进程内有效
+type MemoryXALog struct {
+	mu      sync.Mutex
+	pending map[string]XALogRecord
+}
+
+// NewMemoryXALog 创建内存恢复日志
+func NewMemoryXALog() *MemoryXALog {
+	return &MemoryXALog{pending: make(map[string]XALogRecord)}
+}
+
+// LogCommit 记录提交决定
+func (l *MemoryXALog) LogCommit(rec XALogRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending[rec.XID] = rec
+	return nil
+}
+
+// LogEnd 删除已完成的事务
+func (l *MemoryXALog) LogEnd(xid string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pending, xid)
+	return nil
+}
+
+// Pending 返回未完成的事务（按 xid 排序）
+func (l *MemoryXALog) Pending() ([]XALogRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return sortedRecords(l.pending), nil
+}
+
+// Close 关闭日志
+func (l *MemoryXALog) Close() error {
+	return nil
+}
+
+// xaLogEntry 文件日志的一行
+type xaLogEntry struct {
+	Op string `json:"op"` // commit 或 end
+	XALogRecord
+}
+
+// FileXALog 文件恢复日志：每条记录一行 JSON，写入后立即 fsync
+// 打开时重放日志，并压缩为仅包含未完成事务的新文件
+type FileXALog struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	pending map[string]XALogRecord
+}
+
+// OpenFileXALog 打开（不存在时创建）文件恢复日志
+func OpenFileXALog(path string) (*FileXALog, error) {
+	l := &FileXALog{path: path, pending: make(map[string]XALogRecord)}
+	if err := l.replay(); err != nil {
+		return nil, err
+	}
+	if err := l.rewrite(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// replay 读取已有日志，末尾不完整的行（写入时崩溃）被忽略
+func (l *FileXALog) replay() error {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open xa log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry xaLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		switch entry.Op {
+		case "commit":
+			l.pending[entry.XID] = entry.XALogRecord
+		case "end":
+			delete(l.pending, entry.XID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read xa log: %w", err)
+	}
+	return nil
+}
+
+// rewrite 将未完成事务写入临时文件后替换原日志
+func (l *FileXALog) rewrite() error {
+	if dir := filepath.Dir(l.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create xa log directory: %w", err)
+		}
+	}
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("create xa log: %w", err)
+	}
+	for _, rec := range sortedRecords(l.pending) {
+		if err := writeXALogEntry(f, xaLogEntry{Op: "commit", XALogRecord: rec}); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync xa log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write xa log: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("replace xa log: %w", err)
+	}
+
+	l.file, err = os.OpenFile(l.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open xa log: %w", err)
+	}
+	return nil
+}
+
+// LogCommit 追加提交决定
+func (l *FileXALog) LogCommit(rec XALogRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.append(xaLogEntry{Op: "commit", XALogRecord: rec}); err != nil {
+		return err
+	}
+	l.pending[rec.XID] = rec
+	return nil
+}
+
+// LogEnd 追加完成记录
+func (l *FileXALog) LogEnd(xid string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.append(xaLogEntry{Op: "end", XALogRecord: XALogRecord{XID: xid, Time: time.Now()}}); err != nil {
+		return err
+	}
+	delete(l.pending, xid)
+	return nil
+}
+
+// Pending 返回未完成的事务（按 xid 排序）
+func (l *FileXALog) Pending() ([]XALogRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return sortedRecords(l.pending), nil
+}
+
+// Close 关闭日志文件
+func (l *FileXALog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *FileXALog) append(entry xaLogEntry) error {
+	if l.file == nil {
+		return fmt.Errorf("xa log is closed")
+	}
+	if err := writeXALogEntry(l.file, entry); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("sync xa log: %w", err)
+	}
+	return nil
+}
+
+func writeXALogEntry(f *os.File, entry xaLogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode xa log entry: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write xa log: %w", err)
+	}
+	return nil
+}
+
+func sortedRecords(m map[string]XALogRecord) []XALogRecord {
+	records := make([]XALogRecord, 0, len(m))
+	for _, rec := range m {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].XID < records[j].XID })
+	return records
+}
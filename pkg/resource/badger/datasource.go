@@ -3,6 +3,7 @@ package badger
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/dgraph-io/badger/v4"
@@ -562,8 +563,12 @@ func (ds *BadgerDataSource) matchesFilters(row domain.Row, filters []domain.Filt
 
 // matchesFilter checks a single filter
 func (ds *BadgerDataSource) matchesFilter(row domain.Row, filter domain.Filter) bool {
-	if filter.Logic != "" && len(filter.SubFilters) > 0 {
-		switch filter.Logic {
+	logic := filter.Logic
+	if logic == "" {
+		logic = filter.LogicOp
+	}
+	if logic != "" && len(filter.SubFilters) > 0 {
+		switch strings.ToUpper(logic) {
 		case "AND":
 			for _, sf := range filter.SubFilters {
 				if !ds.matchesFilter(row, sf) {
@@ -581,22 +586,26 @@ func (ds *BadgerDataSource) matchesFilter(row domain.Row, filter domain.Filter)
 		}
 	}
 
-	// Simple filter
-	val, exists := row[filter.Field]
-	if !exists {
-		return false
-	}
-
-	return ds.matchesOperator(val, filter.Operator, filter.Value)
+	// Simple filter (a missing field is treated as NULL)
+	return ds.matchesOperator(row[filter.Field], filter.Operator, filter.Value)
 }
 
 // matchesOperator checks if value matches operator
+// Comparisons with NULL are UNKNOWN and never match; only IS [NOT] NULL and <=> test for NULL.
 func (ds *BadgerDataSource) matchesOperator(val interface{}, op string, target interface{}) bool {
-	if val == nil && target == nil {
-		return op == "=" || op == "==" || op == "IS"
+	switch strings.ToUpper(op) {
+	case "IS NULL", "ISNULL", "IS":
+		return val == nil
+	case "IS NOT NULL", "ISNOTNULL", "IS NOT":
+		return val != nil
+	case "<=>":
+		if val == nil || target == nil {
+			return val == nil && target == nil
+		}
+		op = "="
 	}
-	if val == nil {
-		return op == "!=" || op == "<>" || op == "IS NOT"
+	if val == nil || target == nil {
+		return false
 	}
 
 	switch op {
@@ -614,8 +623,29 @@ func (ds *BadgerDataSource) matchesOperator(val interface{}, op string, target i
 		return ds.compareValues(val, target) <= 0
 	case "LIKE", "like":
 		return ds.matchLike(fmt.Sprintf("%v", val), fmt.Sprintf("%v", target))
+	case "NOT LIKE", "not like":
+		return !ds.matchLike(fmt.Sprintf("%v", val), fmt.Sprintf("%v", target))
 	case "IN", "in":
 		return ds.matchIn(val, target)
+	case "NOT IN", "not in":
+		// x NOT IN (..., NULL) is UNKNOWN when nothing matches
+		list, ok := target.([]interface{})
+		if !ok || ds.matchIn(val, target) {
+			return false
+		}
+		for _, item := range list {
+			if item == nil {
+				return false
+			}
+		}
+		return true
+	case "BETWEEN", "between", "NOT BETWEEN", "not between":
+		bounds, ok := target.([]interface{})
+		if !ok || len(bounds) != 2 || bounds[0] == nil || bounds[1] == nil {
+			return false
+		}
+		within := ds.compareValues(val, bounds[0]) >= 0 && ds.compareValues(val, bounds[1]) <= 0
+		return within != strings.HasPrefix(strings.ToUpper(op), "NOT ")
 	default:
 		return false
 	}
@@ -676,7 +706,7 @@ func (ds *BadgerDataSource) matchIn(val interface{}, list interface{}) bool {
 	switch v := list.(type) {
 	case []interface{}:
 		for _, item := range v {
-			if fmt.Sprintf("%v", val) == fmt.Sprintf("%v", item) {
+			if item != nil && fmt.Sprintf("%v", val) == fmt.Sprintf("%v", item) {
 				return true
 			}
 		}
@@ -415,3 +415,53 @@ func TestBadgerDataSource_LikePattern(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, result.Rows, 4) // all single character IDs
 }
+
+func TestBadgerDataSource_NestedAndNegatedFilters(t *testing.T) {
+	ds := NewBadgerDataSource(&domain.DataSourceConfig{
+		Name:    "test",
+		Type:    domain.DataSourceTypeMemory,
+		Options: map[string]interface{}{"in_memory": true},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, ds.Connect(ctx))
+	defer ds.Close(ctx)
+
+	require.NoError(t, ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "users",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "VARCHAR(64)", Primary: true},
+			{Name: "email", Type: "VARCHAR(255)"},
+		},
+	}))
+	_, err := ds.Insert(ctx, "users", []domain.Row{
+		{"id": "1", "email": "alice@example.com"},
+		{"id": "2", "email": "bob@test.org"},
+		{"id": "3", "email": nil},
+		{"id": "4", "email": "david@example.com"},
+	}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		filter   domain.Filter
+		expected int
+	}{
+		{"LogicOp OR", domain.Filter{LogicOp: "OR", SubFilters: []domain.Filter{
+			{Field: "id", Operator: "=", Value: "1"},
+			{Field: "email", Operator: "IS NULL"},
+		}}, 2},
+		{"NOT LIKE skips NULL", domain.Filter{Field: "email", Operator: "NOT LIKE", Value: "%.com"}, 1},
+		{"NOT IN", domain.Filter{Field: "id", Operator: "NOT IN", Value: []interface{}{"1", "2"}}, 2},
+		{"NOT IN with NULL", domain.Filter{Field: "id", Operator: "NOT IN", Value: []interface{}{"1", nil}}, 0},
+		{"BETWEEN", domain.Filter{Field: "id", Operator: "BETWEEN", Value: []interface{}{"2", "3"}}, 2},
+		{"NOT BETWEEN", domain.Filter{Field: "id", Operator: "NOT BETWEEN", Value: []interface{}{"2", "3"}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ds.Query(ctx, "users", &domain.QueryOptions{Filters: []domain.Filter{tt.filter}})
+			require.NoError(t, err)
+			assert.Len(t, result.Rows, tt.expected)
+		})
+	}
+}
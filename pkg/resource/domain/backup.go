@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+// OnlineBackupSource 支持在线备份的数据源接口：在写入继续进行的同时获取所有表在同一时刻的一致视图
+type OnlineBackupSource interface {
+	// BackupSnapshot 固定表的当前版本并记录此刻的变更日志位置，tables 为空时备份所有非临时表
+	// 快照关闭前固定的版本不会被回收，写入不受影响
+	BackupSnapshot(ctx context.Context, tables []string) (BackupSnapshot, error)
+}
+
+// BackupSnapshot 在线备份固定的一致视图，使用完毕后必须调用 Close
+type BackupSnapshot interface {
+	// LSN 返回快照对应的变更日志位置：LSN 不大于它的变更都已包含在快照中，
+	// 之后的变更可以通过 ChangeLogSource.ChangesSince(ctx, LSN) 增量获取
+	LSN() int64
+
+	// ChangeLog 返回快照时变更日志的标识（见 ChangeLogSource.ChangeLogID），变更日志未启用时为空，
+	// 此时 LSN 不能用于增量备份
+	ChangeLog() string
+
+	// Tables 返回快照包含的表名（已排序）
+	Tables() []string
+
+	// Skipped 返回未备份的表，例如分区表
+	Skipped() []string
+
+	// WriteTable 把表在快照中的版本按 TableSnapshotter 的快照格式写入 w
+	WriteTable(ctx context.Context, table string, w io.Writer) (*TableSnapshotInfo, error)
+
+	// Close 释放固定的版本
+	Close()
+}
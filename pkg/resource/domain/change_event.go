@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// 行变更类型
+const (
+	ChangeInsert = "INSERT"
+	ChangeUpdate = "UPDATE"
+	ChangeDelete = "DELETE"
+)
+
+// ChangeEvent 变更日志中的一条已提交的行变更
+type ChangeEvent struct {
+	LSN    int64     `json:"lsn"`          // 变更日志中的位置，按提交顺序递增
+	ID     int64     `json:"id,omitempty"` // 全局 ID 生成器分配的事件 ID，跨数据源按提交顺序递增，可与查询 ID、连接 ID 比较先后
+	Time   time.Time `json:"time"`         // 提交时间
+	Table  string    `json:"table"`
+	Type   string    `json:"type"`             // INSERT, UPDATE, DELETE
+	TxnID  int64     `json:"txn_id,omitempty"` // 所属事务，自动提交的语句为 0
+	Before Row       `json:"before,omitempty"` // 变更前的行，INSERT 时为 nil
+	After  Row       `json:"after,omitempty"`  // 变更后的行，DELETE 时为 nil
+}
+
+// ChangeLogSource 记录已提交行变更的数据源接口
+// 变更数据捕获、嵌入方的变更通知等功能都基于同一份变更日志
+type ChangeLogSource interface {
+	// SubscribeChanges 订阅表的行变更，table 为空时订阅所有表，返回取消订阅的函数
+	// fn 在语句或事务提交后、写入调用返回前按 LSN 顺序同步调用，调用时不持有数据源的锁，
+	// 因此可以在 fn 中查询数据源。事件中的行与存储共享，fn 不能修改
+	SubscribeChanges(table string, fn func(ChangeEvent)) (unsubscribe func())
+
+	// ChangesSince 返回 LSN 大于 since 的变更以及当前最新的 LSN
+	// 变更日志只保留最近的记录，since 之后的记录已被丢弃时返回错误
+	ChangesSince(ctx context.Context, since int64) ([]ChangeEvent, int64, error)
+
+	// ChangeLogID 返回变更日志的标识，变更日志未启用（没有订阅者且不保留变更）时为空。
+	// 变更日志停用期间的写入不分配 LSN，每次重新启用或数据源重建后标识都会改变，
+	// 只有标识相同的 LSN 之间才是连续的
+	ChangeLogID() string
+}
+
+// ChangeApplier 支持重放已提交行变更的数据源接口，用于从增量备份恢复
+type ChangeApplier interface {
+	// ApplyChanges 按顺序把变更应用到表上，在一个新版本中生效：INSERT 插入 After，
+	// UPDATE、DELETE 按 Before 找到完全相同的行；找不到时不做任何修改并返回错误
+	ApplyChanges(ctx context.Context, tableName string, changes []ChangeEvent) (int64, error)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// 表整理状态
+const (
+	CompactionStateRunning   = "RUNNING"
+	CompactionStateCompleted = "COMPLETED"
+	CompactionStateFailed    = "FAILED"
+)
+
+// CompactionProgress 表整理（OPTIMIZE TABLE）的进度
+type CompactionProgress struct {
+	Table         string    `json:"table"`
+	State         string    `json:"state"`
+	RowsTotal     int64     `json:"rows_total"`
+	RowsProcessed int64     `json:"rows_processed"`
+	PagesBefore   int       `json:"pages_before"`
+	PagesAfter    int       `json:"pages_after"`
+	Attempts      int       `json:"attempts"` // 因并发写入而重试的次数（含首次）
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Percent 返回已处理行数的百分比
+func (p *CompactionProgress) Percent() float64 {
+	if p == nil || p.RowsTotal == 0 {
+		if p != nil && p.State == CompactionStateCompleted {
+			return 100
+		}
+		return 0
+	}
+	return float64(p.RowsProcessed) * 100 / float64(p.RowsTotal)
+}
+
+// TableCompactor 支持在线整理表存储的数据源接口
+type TableCompactor interface {
+	// CompactTable 重建表的行存储和索引，整理期间不阻塞读取
+	CompactTable(ctx context.Context, tableName string) (*CompactionProgress, error)
+	// CompactionStatus 返回表最近一次整理的进度
+	CompactionStatus(tableName string) (*CompactionProgress, bool)
+}
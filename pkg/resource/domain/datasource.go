@@ -81,3 +81,36 @@ type Transaction interface {
 	// Delete 删除数据
 	Delete(ctx context.Context, tableName string, filters []Filter, options *DeleteOptions) (int64, error)
 }
+
+// DDLTransaction 支持事务性 DDL 的事务接口
+// 事务内执行的 DDL 仅在提交后对其他会话可见，回滚时撤销
+type DDLTransaction interface {
+	Transaction
+
+	// CreateTable 在事务内创建表
+	CreateTable(ctx context.Context, tableInfo *TableInfo) error
+
+	// DropTable 在事务内删除表
+	DropTable(ctx context.Context, tableName string) error
+
+	// TruncateTable 在事务内清空表
+	TruncateTable(ctx context.Context, tableName string) error
+
+	// SetTableTTL 在事务内设置表的过期策略，ttl 为 nil 时移除
+	SetTableTTL(ctx context.Context, tableName string, ttl *TTLInfo) error
+}
+
+// SavepointTransaction 支持保存点的事务接口
+// 回滚到保存点时同时撤销保存点之后的数据修改和 DDL
+type SavepointTransaction interface {
+	Transaction
+
+	// Savepoint 创建保存点，同名保存点会被覆盖
+	Savepoint(ctx context.Context, name string) error
+
+	// RollbackToSavepoint 回滚到保存点，保存点之后创建的保存点被删除
+	RollbackToSavepoint(ctx context.Context, name string) error
+
+	// ReleaseSavepoint 删除保存点及其之后创建的保存点
+	ReleaseSavepoint(ctx context.Context, name string) error
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 列加密模式
+const (
+	// EncryptionRandomized 随机加密：相同明文每次得到不同密文，不能按值查找
+	EncryptionRandomized = "randomized"
+	// EncryptionDeterministic 确定性加密：相同明文得到相同密文，支持等值查找，但会暴露值是否相等
+	EncryptionDeterministic = "deterministic"
+	// EncryptionBlindIndex 随机加密并在隐藏列中保存明文的 HMAC（盲索引），等值查找使用盲索引
+	EncryptionBlindIndex = "blind_index"
+)
+
+// DefaultEncryptionKey 未指定 KEY 时使用的密钥 ID
+const DefaultEncryptionKey = "default"
+
+// ColumnEncryption 列的加密设置（CREATE TABLE 中的 ENCRYPTED 列属性）
+type ColumnEncryption struct {
+	Mode  string `json:"mode"`             // randomized、deterministic 或 blind_index
+	KeyID string `json:"key_id,omitempty"` // 密钥 ID，为空时使用 default
+	// Context 建表时确定的"表名.列名"，作为 AES-GCM 的附加认证数据（AAD），
+	// 密文复制到其他表或列后无法解密；重命名表或列不改变它
+	Context string `json:"context,omitempty"`
+}
+
+// Key 返回列使用的密钥 ID
+func (e *ColumnEncryption) Key() string {
+	if e.KeyID == "" {
+		return DefaultEncryptionKey
+	}
+	return e.KeyID
+}
+
+// Validate 检查加密模式
+func (e *ColumnEncryption) Validate() error {
+	switch e.Mode {
+	case EncryptionRandomized, EncryptionDeterministic, EncryptionBlindIndex:
+		return nil
+	}
+	return fmt.Errorf("unknown column encryption mode '%s'", e.Mode)
+}
+
+// SupportsEquality 是否支持等值查找（=、!=、IN）
+func (e *ColumnEncryption) SupportsEquality() bool {
+	return e.Mode == EncryptionDeterministic || e.Mode == EncryptionBlindIndex
+}
+
+// HasEncryptedColumns 表中是否有加密列
+func (t *TableInfo) HasEncryptedColumns() bool {
+	for _, col := range t.Columns {
+		if col.Encryption != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyProvider 列加密的密钥来源（KMS），可以是环境变量、文件或外部密钥管理服务
+type KeyProvider interface {
+	// DataKey 返回密钥 ID 对应的 32 字节 AES-256 密钥
+	DataKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// ColumnEncryptionManager 支持加密列的数据源接口
+type ColumnEncryptionManager interface {
+	// SetKeyProvider 设置加密列使用的密钥来源
+	SetKeyProvider(keys KeyProvider)
+}
+
+// ErrEncryptedColumnOperator 加密列上不支持的查询条件
+type ErrEncryptedColumnOperator struct {
+	Column   string
+	Operator string
+}
+
+func (e *ErrEncryptedColumnOperator) Error() string {
+	return fmt.Sprintf("operator %s is not supported on encrypted column '%s'", strings.ToUpper(e.Operator), e.Column)
+}
+
+type columnDecryptionKey struct{}
+
+// WithColumnDecryption 允许本次操作读取加密列的明文，未设置时查询结果中的加密列保持密文
+func WithColumnDecryption(ctx context.Context) context.Context {
+	return context.WithValue(ctx, columnDecryptionKey{}, true)
+}
+
+// ColumnDecryptionAllowed 本次操作是否可以读取加密列的明文
+func ColumnDecryptionAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(columnDecryptionKey{}).(bool)
+	return allowed
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // 数据源领域错误
 
@@ -229,3 +232,64 @@ func (e *ErrGeneratedColumnValidation) Error() string {
 func NewErrGeneratedColumnValidation(message string) *ErrGeneratedColumnValidation {
 	return &ErrGeneratedColumnValidation{Message: message}
 }
+
+// ErrRetryable connection lost while reading from a backing datasource (dropped connection,
+// reset by peer, bad pooled connection). The statement did not complete, so idempotent
+// reads can be retried on a new connection or a replica.
+type ErrRetryable struct {
+	DataSourceType string
+	Err            error
+}
+
+func (e *ErrRetryable) Error() string {
+	return fmt.Sprintf("lost connection to %s data source: %v", e.DataSourceType, e.Err)
+}
+
+func (e *ErrRetryable) Unwrap() error {
+	return e.Err
+}
+
+// NewErrRetryable creates retryable connection error
+func NewErrRetryable(dataSourceType string, err error) *ErrRetryable {
+	return &ErrRetryable{DataSourceType: dataSourceType, Err: err}
+}
+
+// ErrQuarantined datasource taken out of service after failing consecutive health checks.
+// Statements fail fast instead of waiting on the backend until a health check succeeds again.
+type ErrQuarantined struct {
+	DataSource string
+	Reason     string
+}
+
+func (e *ErrQuarantined) Error() string {
+	return fmt.Sprintf("data source %s is quarantined: %s", e.DataSource, e.Reason)
+}
+
+// NewErrQuarantined creates quarantined datasource error
+func NewErrQuarantined(dataSource, reason string) *ErrQuarantined {
+	return &ErrQuarantined{DataSource: dataSource, Reason: reason}
+}
+
+// ErrNeedReprepare table referenced by a prepared statement or an open cursor was altered or
+// dropped after the statement was prepared or the cursor opened. Rows can no longer be read with
+// the metadata the client holds, so the client has to prepare the statement again.
+type ErrNeedReprepare struct {
+	Table string
+}
+
+func (e *ErrNeedReprepare) Error() string {
+	return fmt.Sprintf("Prepared statement needs to be re-prepared: table %s was altered or dropped", e.Table)
+}
+
+// NewErrNeedReprepare creates need reprepare error
+func NewErrNeedReprepare(tableName string) *ErrNeedReprepare {
+	return &ErrNeedReprepare{Table: tableName}
+}
+
+// IsRetryable reports whether err (or any error it wraps) is an ErrRetryable or
+// an ErrQuarantined, i.e. a read that may succeed on a replica or later on
+func IsRetryable(err error) bool {
+	var retryable *ErrRetryable
+	var quarantined *ErrQuarantined
+	return errors.As(err, &retryable) || errors.As(err, &quarantined)
+}
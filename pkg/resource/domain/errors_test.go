@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -373,3 +375,22 @@ func TestErrorMessageFormats(t *testing.T) {
 		})
 	}
 }
+
+// TestErrRetryable 测试可重试错误的识别
+func TestErrRetryable(t *testing.T) {
+	cause := errors.New("connection reset by peer")
+	err := fmt.Errorf("query: %w", NewErrRetryable("mysql", cause))
+
+	if !IsRetryable(err) {
+		t.Errorf("wrapped ErrRetryable should be retryable")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("ErrRetryable should unwrap to its cause")
+	}
+	if !strings.Contains(err.Error(), "lost connection to mysql data source") {
+		t.Errorf("unexpected message '%s'", err.Error())
+	}
+	if IsRetryable(cause) || IsRetryable(nil) {
+		t.Errorf("plain errors should not be retryable")
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RecycledTable 回收站中被删除的表
+type RecycledTable struct {
+	Name      string    `json:"name"`
+	DroppedAt time.Time `json:"dropped_at"`
+	ExpiresAt time.Time `json:"expires_at"` // 超过该时间后被清理，不能再恢复
+	Rows      int64     `json:"rows"`
+}
+
+// RecycleBin 支持回收站的数据源接口：DROP TABLE 把表移入回收站，保留期内可以用 UNDROP TABLE 恢复
+type RecycleBin interface {
+	// SetRecycleBinRetention 设置被删除的表在回收站中的保留时间，0 时 DROP TABLE 直接删除表并清空回收站
+	SetRecycleBinRetention(retention time.Duration)
+	// RecycledTables 返回回收站中的表，按删除时间排列
+	RecycledTables() []RecycledTable
+	// UndropTable 恢复回收站中最近删除的同名表
+	UndropTable(ctx context.Context, tableName string) error
+	// PurgeRecycleBin 清理超过保留时间的表，返回清理的表数
+	PurgeRecycleBin(ctx context.Context) (int64, error)
+}
+
+// Flashbacker 支持 FLASHBACK TABLE 的数据源接口：按变更日志撤销表最近的 DML
+type Flashbacker interface {
+	// SetChangeLogSize 设置变更日志保留的最近变更数，0 时不保留，FLASHBACK TABLE 不可用
+	SetChangeLogSize(size int)
+	// FlashbackTable 撤销表在 to 之后提交的行变更，返回撤销的变更数
+	FlashbackTable(ctx context.Context, tableName string, to time.Time) (int64, error)
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "context"
+
+// FunctionalIndex 函数索引（表达式索引），如 CREATE INDEX idx ON t ((LOWER(email)))
+// 索引键由表达式计算，保存在隐藏的 STORED 生成列中，随写入自动维护
+type FunctionalIndex struct {
+	Name       string   `json:"name"`       // 索引名
+	Expression string   `json:"expression"` // 索引表达式（SQL 文本）
+	Column     string   `json:"column"`     // 保存索引键的隐藏列
+	Depends    []string `json:"depends"`    // 表达式引用的列
+	Unique     bool     `json:"unique"`
+}
+
+// FunctionalIndexManager 支持函数索引的数据源接口
+type FunctionalIndexManager interface {
+	// CreateFunctionalIndex 创建函数索引：添加隐藏列、回填现有行并建立索引
+	// 只需设置 idx 的 Name、Expression、Depends 和 Unique，Column 由数据源分配
+	CreateFunctionalIndex(ctx context.Context, tableName string, idx FunctionalIndex, indexType string) error
+
+	// FunctionalIndexes 返回表上的函数索引
+	FunctionalIndexes(ctx context.Context, tableName string) []FunctionalIndex
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// HealthChecker 支持轻量级存活探测的数据源接口
+// 未实现时，健康检查以 GetTables 作为探测查询
+type HealthChecker interface {
+	// Ping 执行一次轻量查询（如 SELECT 1），确认后端服务可用
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// HistoryManager 保留表的历史版本、支持时间点查询（SELECT ... FOR SYSTEM_TIME AS OF）的数据源接口
+type HistoryManager interface {
+	// SetHistoryRetention 设置历史版本的保留时间，tableName 为空时设置数据源内所有表的默认值，
+	// retention 为 0 时不保留历史版本
+	SetHistoryRetention(ctx context.Context, tableName string, retention time.Duration) error
+	// HistoryRetention 返回表的历史版本保留时间
+	HistoryRetention(tableName string) time.Duration
+	// PurgeHistory 清理超出保留时间的历史版本，返回清理的版本数
+	PurgeHistory(ctx context.Context) (int64, error)
+}
+
+// ErrHistoryUnavailable 请求的时间点没有可用的历史版本
+type ErrHistoryUnavailable struct {
+	Table  string
+	AsOf   time.Time
+	Reason string
+}
+
+func (e *ErrHistoryUnavailable) Error() string {
+	return fmt.Sprintf("no history of table '%s' as of '%s': %s",
+		e.Table, e.AsOf.Format("2006-01-02 15:04:05"), e.Reason)
+}
+
+// NewErrHistoryUnavailable 创建历史版本不可用错误
+func NewErrHistoryUnavailable(table string, asOf time.Time, reason string) *ErrHistoryUnavailable {
+	return &ErrHistoryUnavailable{Table: table, AsOf: asOf, Reason: reason}
+}
+
+type asOfKey struct{}
+
+// asOf 时间点查询的时间，served 记录是否有数据源按该时间读取了历史版本
+type asOf struct {
+	at     time.Time
+	served atomic.Bool
+}
+
+// WithAsOf 设置本次查询读取的时间点
+func WithAsOf(ctx context.Context, at time.Time) context.Context {
+	return context.WithValue(ctx, asOfKey{}, &asOf{at: at})
+}
+
+// AsOfFromContext 读取上下文中的查询时间点
+func AsOfFromContext(ctx context.Context) (time.Time, bool) {
+	a, ok := ctx.Value(asOfKey{}).(*asOf)
+	if !ok {
+		return time.Time{}, false
+	}
+	return a.at, true
+}
+
+// MarkAsOfServed 数据源按上下文中的时间点读取了历史版本
+func MarkAsOfServed(ctx context.Context) {
+	if a, ok := ctx.Value(asOfKey{}).(*asOf); ok {
+		a.served.Store(true)
+	}
+}
+
+// AsOfServed 是否有数据源按上下文中的时间点读取了历史版本。
+// 设置了时间点但没有数据源处理时，查询读到的是最新数据，调用方应当报错
+func AsOfServed(ctx context.Context) bool {
+	a, ok := ctx.Value(asOfKey{}).(*asOf)
+	return ok && a.served.Load()
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LockMode 锁定读的锁模式
+type LockMode string
+
+const (
+	LockModeNone   LockMode = ""
+	LockModeShared LockMode = "SHARE"  // SELECT ... LOCK IN SHARE MODE / FOR SHARE
+	LockModeUpdate LockMode = "UPDATE" // SELECT ... FOR UPDATE
+)
+
+// DefaultLockWaitTimeout 默认的锁等待超时（与 innodb_lock_wait_timeout 默认值一致）
+const DefaultLockWaitTimeout = 50 * time.Second
+
+// ErrLockWaitTimeout 锁等待超时错误（MySQL 错误码 1205）
+type ErrLockWaitTimeout struct {
+	Resource string
+}
+
+func (e *ErrLockWaitTimeout) Error() string {
+	return fmt.Sprintf("Lock wait timeout exceeded; try restarting transaction (waiting for %s)", e.Resource)
+}
+
+// NewErrLockWaitTimeout 创建锁等待超时错误
+func NewErrLockWaitTimeout(resource string) *ErrLockWaitTimeout {
+	return &ErrLockWaitTimeout{Resource: resource}
+}
+
+// ErrDeadlock 死锁错误（MySQL 错误码 1213），被选为牺牲者的事务已回滚
+type ErrDeadlock struct {
+	TxnID int64
+}
+
+func (e *ErrDeadlock) Error() string {
+	return "Deadlock found when trying to get lock; try restarting transaction"
+}
+
+// NewErrDeadlock 创建死锁错误
+func NewErrDeadlock(txnID int64) *ErrDeadlock {
+	return &ErrDeadlock{TxnID: txnID}
+}
+
+// IsDeadlock 判断错误是否为死锁
+func IsDeadlock(err error) bool {
+	var e *ErrDeadlock
+	return errors.As(err, &e)
+}
+
+type lockWaitTimeoutKey struct{}
+
+// WithLockWaitTimeout 设置本次操作的锁等待超时，0 表示不等待（NOWAIT）
+func WithLockWaitTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, lockWaitTimeoutKey{}, timeout)
+}
+
+// LockWaitTimeoutFromContext 读取上下文中的锁等待超时
+func LockWaitTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(lockWaitTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
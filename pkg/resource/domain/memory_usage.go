@@ -0,0 +1,19 @@
+package domain
+
+import "context"
+
+// ColumnMemoryUsage 列的内存占用（SHOW TABLE MEMORY）
+type ColumnMemoryUsage struct {
+	Column         string `json:"column"`
+	Type           string `json:"type"`
+	Values         int64  `json:"values"`          // 非 NULL 值的数量
+	DistinctValues int64  `json:"distinct_values"` // 不同值的数量（仅字符串列统计）
+	LogicalBytes   int64  `json:"logical_bytes"`   // 每个值单独存储时的大小
+	MemoryBytes    int64  `json:"memory_bytes"`    // 实际占用，共享的字符串数据只计一次
+}
+
+// TableMemoryReporter 支持按列报告表内存占用的数据源接口
+type TableMemoryReporter interface {
+	// TableMemoryUsage 返回表最新版本中各列的内存占用
+	TableMemoryUsage(ctx context.Context, tableName string) ([]ColumnMemoryUsage, error)
+}
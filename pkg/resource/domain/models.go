@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 // DataSourceType 数据源类型
 type DataSourceType string
 
@@ -55,6 +57,8 @@ type TableInfo struct {
 	Atts      map[string]interface{} `json:"atts,omitempty"`      // 表属性
 	Charset   string                 `json:"charset,omitempty"`   // 表字符集
 	Collation string                 `json:"collation,omitempty"` // 表排序规则
+	Partition *PartitionInfo         `json:"partition,omitempty"` // 分区定义（非分区表为 nil）
+	TTL       *TTLInfo               `json:"ttl,omitempty"`       // 过期策略（未设置为 nil）
 }
 
 // ColumnInfo 列信息
@@ -66,6 +70,8 @@ type ColumnInfo struct {
 	Default       string          `json:"default,omitempty"`
 	Unique        bool            `json:"unique,omitempty"`         // 唯一约束
 	AutoIncrement bool            `json:"auto_increment,omitempty"` // 自动递增
+	Unsigned      bool            `json:"unsigned,omitempty"`       // 无符号整数
+	Zerofill      bool            `json:"zerofill,omitempty"`       // 零填充显示（隐含 UNSIGNED）
 	ForeignKey    *ForeignKeyInfo `json:"foreign_key,omitempty"`    // 外键约束
 
 	// Generated Columns 支持
@@ -74,6 +80,12 @@ type ColumnInfo struct {
 	GeneratedExpr    string   `json:"generated_expr,omitempty"`    // 表达式字符串
 	GeneratedDepends []string `json:"generated_depends,omitempty"` // 依赖的列名
 
+	// Hidden 隐藏列（如函数索引的索引键），只在数据源内部存储，不出现在表结构和查询结果中
+	Hidden bool `json:"hidden,omitempty"`
+
+	// Encryption 加密列（ENCRYPTED），值以密文存储，nil 表示不加密
+	Encryption *ColumnEncryption `json:"encryption,omitempty"`
+
 	// Vector Columns 支持
 	VectorDim  int    `json:"vector_dim,omitempty"`  // 向量维度
 	VectorType string `json:"vector_type,omitempty"` // 向量类型（如 "float32"）
@@ -83,6 +95,15 @@ type ColumnInfo struct {
 	Collation string `json:"collation,omitempty"`
 }
 
+// IsUnsigned 检查是否为无符号列（显式标记或类型名中带 UNSIGNED/ZEROFILL）
+func (c ColumnInfo) IsUnsigned() bool {
+	if c.Unsigned || c.Zerofill {
+		return true
+	}
+	t := strings.ToLower(c.Type)
+	return strings.Contains(t, "unsigned") || strings.Contains(t, "zerofill")
+}
+
 // IsVectorType 检查是否为向量类型
 func (c ColumnInfo) IsVectorType() bool {
 	return c.VectorDim > 0
@@ -117,9 +138,11 @@ func GetRowAttributes(row Row) (map[string]any, bool) {
 
 // QueryResult 查询结果
 type QueryResult struct {
-	Columns []ColumnInfo `json:"columns"`
-	Rows    []Row        `json:"rows"`
-	Total   int64        `json:"total"`
+	Columns  []ColumnInfo `json:"columns"`
+	Rows     []Row        `json:"rows"`
+	Total    int64        `json:"total"`
+	Warnings []string     `json:"warnings,omitempty"` // 执行过程中产生的警告（如被忽略的优化器 hint）
+	Ordered  bool         `json:"ordered,omitempty"`  // 数据源已按 QueryOptions.SortKeys() 完整排序（含排序规则），上层不再排序
 }
 
 // Filter 查询过滤器（支持嵌套逻辑）
@@ -132,16 +155,41 @@ type Filter struct {
 	SubFilters []Filter    `json:"sub_filters,omitempty"` // 子过滤器（保留向后兼容）
 }
 
+// OrderByItem 排序键
+type OrderByItem struct {
+	Column    string `json:"column"`
+	Direction string `json:"direction,omitempty"` // ASC（默认）, DESC
+	Collation string `json:"collation,omitempty"` // 字符串的排序规则，为空时按二进制比较
+}
+
 // QueryOptions 查询选项
 type QueryOptions struct {
-	Filters       []Filter `json:"filters,omitempty"`
-	OrderBy       string   `json:"order_by,omitempty"`
-	Order         string   `json:"order,omitempty"` // ASC, DESC
-	Limit         int      `json:"limit,omitempty"`
-	Offset        int      `json:"offset,omitempty"`
-	SelectAll     bool     `json:"select_all,omitempty"`     // 是否是 select *
-	SelectColumns []string `json:"select_columns,omitempty"` // 指定要查询的列（列裁剪）
-	User          string   `json:"user,omitempty"`           // 当前用户名（用于权限检查）
+	Filters       []Filter      `json:"filters,omitempty"`
+	Sort          []OrderByItem `json:"sort,omitempty"` // 多列排序，设置时优先于 OrderBy/Order
+	OrderBy       string        `json:"order_by,omitempty"`
+	Order         string        `json:"order,omitempty"` // ASC, DESC
+	Limit         int           `json:"limit,omitempty"`
+	Offset        int           `json:"offset,omitempty"`
+	SelectAll     bool          `json:"select_all,omitempty"`     // 是否是 select *
+	SelectColumns []string      `json:"select_columns,omitempty"` // 指定要查询的列（列裁剪）
+	User          string        `json:"user,omitempty"`           // 当前用户名（用于权限检查）
+	ForceIndex    string        `json:"force_index,omitempty"`    // 优先使用的索引（FORCE_INDEX / USE_INDEX hint），数据源可忽略
+	IgnoreIndexes []string      `json:"ignore_indexes,omitempty"` // 不允许使用的索引（IGNORE_INDEX hint）
+	Lock          LockMode      `json:"lock,omitempty"`           // 锁定读（FOR UPDATE / FOR SHARE），仅在事务内生效
+}
+
+// SortKeys 返回排序键：优先使用 Sort，否则由单列的 OrderBy/Order 构成，没有排序时返回 nil
+func (o *QueryOptions) SortKeys() []OrderByItem {
+	if o == nil {
+		return nil
+	}
+	if len(o.Sort) > 0 {
+		return o.Sort
+	}
+	if o.OrderBy == "" {
+		return nil
+	}
+	return []OrderByItem{{Column: o.OrderBy, Direction: o.Order}}
 }
 
 // InsertOptions 插入选项
@@ -287,3 +335,6 @@ const (
 	ViewMetaKey  = "__view__" // 视图元数据在 TableInfo.Atts 中的键名
 	MaxViewDepth = 10         // 视图嵌套最大深度
 )
+
+// SequenceMetaKey 序列表在 TableInfo.Atts 中的标记键名，序列保存为只有一行状态的表
+const SequenceMetaKey = "__sequence__"
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 分区类型
+const (
+	PartitionTypeRange = "RANGE"
+	PartitionTypeHash  = "HASH"
+)
+
+// PartitionInfo 表分区定义
+type PartitionInfo struct {
+	Type       string         `json:"type"`   // RANGE, HASH
+	Column     string         `json:"column"` // 分区键
+	Partitions []PartitionDef `json:"partitions"`
+}
+
+// PartitionDef 单个分区定义
+type PartitionDef struct {
+	Name string `json:"name"`
+	// LessThan RANGE 分区的上界（不包含），nil 表示 MAXVALUE；HASH 分区忽略
+	LessThan interface{} `json:"less_than,omitempty"`
+}
+
+// PartitionNames 返回所有分区名
+func (p *PartitionInfo) PartitionNames() []string {
+	names := make([]string, len(p.Partitions))
+	for i, def := range p.Partitions {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// PartitionIndex 按名称查找分区（不区分大小写），不存在时返回 -1
+func (p *PartitionInfo) PartitionIndex(name string) int {
+	for i, def := range p.Partitions {
+		if strings.EqualFold(def.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Clone 深拷贝分区定义
+func (p *PartitionInfo) Clone() *PartitionInfo {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.Partitions = make([]PartitionDef, len(p.Partitions))
+	copy(clone.Partitions, p.Partitions)
+	return &clone
+}
+
+// IsPartitioned 表是否为分区表
+func (t *TableInfo) IsPartitioned() bool {
+	return t.Partition != nil && len(t.Partition.Partitions) > 0
+}
+
+// PartitionManager 支持 ALTER TABLE ... ADD/DROP PARTITION 的数据源接口
+type PartitionManager interface {
+	// AddPartitions 为分区表添加分区
+	// RANGE 分区的上界必须大于现有分区；HASH 分区会按新的分区数重新分布数据
+	AddPartitions(ctx context.Context, tableName string, partitions []PartitionDef) error
+
+	// DropPartitions 删除 RANGE 分区及其中的数据
+	DropPartitions(ctx context.Context, tableName string, names []string) error
+}
+
+// ErrNoPartitionForValue 行的分区键不落在任何分区内
+type ErrNoPartitionForValue struct {
+	TableName string
+	Value     interface{}
+}
+
+func (e *ErrNoPartitionForValue) Error() string {
+	return fmt.Sprintf("table %s has no partition for value %v", e.TableName, e.Value)
+}
+
+// NewErrNoPartitionForValue 创建分区不存在错误
+func NewErrNoPartitionForValue(tableName string, value interface{}) *ErrNoPartitionForValue {
+	return &ErrNoPartitionForValue{TableName: tableName, Value: value}
+}
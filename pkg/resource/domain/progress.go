@@ -0,0 +1,46 @@
+package domain
+
+import "context"
+
+// Progress 长时间操作（建索引、表整理等）的执行进度
+type Progress struct {
+	Stage    int    // 当前阶段，从 1 开始
+	MaxStage int    // 总阶段数
+	Done     int64  // 当前阶段已处理的工作量（通常为行数）
+	Total    int64  // 当前阶段的总工作量，未知时为 0
+	Info     string // 当前阶段的说明
+}
+
+// Percent 当前阶段的完成百分比（0-100），总量未知时返回 0
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	if p.Done >= p.Total {
+		return 100
+	}
+	return float64(p.Done) * 100 / float64(p.Total)
+}
+
+// ProgressFunc 进度回调，在执行操作的 goroutine 中同步调用，实现应尽快返回
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress 为本次操作设置进度回调
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress 向上下文中的进度回调报告进度，未设置回调时不做任何事
+func ReportProgress(ctx context.Context, p Progress) {
+	if ctx == nil {
+		return
+	}
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(p)
+	}
+}
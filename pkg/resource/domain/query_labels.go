@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+type queryLabelsKey struct{}
+
+// WithQueryLabels 设置本次执行的查询标签（如 app、request_id），数据源可以转发给下游系统用于关联
+func WithQueryLabels(ctx context.Context, labels map[string]string) context.Context {
+	if len(labels) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, queryLabelsKey{}, labels)
+}
+
+// QueryLabelsFromContext 读取上下文中的查询标签，未设置时返回 nil。返回的 map 只读
+func QueryLabelsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	labels, _ := ctx.Value(queryLabelsKey{}).(map[string]string)
+	return labels
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// QueryStats 一条语句执行期间的资源使用统计，由执行器在读取数据时累加，可并发更新
+type QueryStats struct {
+	rowsExamined atomic.Int64
+	memory       atomic.Int64
+	memoryPeak   atomic.Int64
+}
+
+// RowsExamined 从数据源读取的行数
+func (s *QueryStats) RowsExamined() int64 {
+	return s.rowsExamined.Load()
+}
+
+// MemoryPeak 执行期间物化的行占用内存的峰值估算（字节）
+func (s *QueryStats) MemoryPeak() int64 {
+	return s.memoryPeak.Load()
+}
+
+// AddRows 记录从数据源读取的一批行，并把它们计入物化内存
+func (s *QueryStats) AddRows(rows []Row) {
+	s.rowsExamined.Add(int64(len(rows)))
+	s.AddMemory(EstimateRowsSize(rows))
+}
+
+// AddMemory 记录新物化的数据大小并更新峰值；执行器不释放中间结果，因此只增不减
+func (s *QueryStats) AddMemory(bytes int64) {
+	current := s.memory.Add(bytes)
+	for {
+		peak := s.memoryPeak.Load()
+		if current <= peak || s.memoryPeak.CompareAndSwap(peak, current) {
+			return
+		}
+	}
+}
+
+type queryStatsKey struct{}
+
+// WithQueryStats 为本次执行设置资源使用统计
+func WithQueryStats(ctx context.Context, stats *QueryStats) context.Context {
+	if stats == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStatsKey{}, stats)
+}
+
+// RecordRowsExamined 把从数据源读取的行计入上下文中的统计，未设置统计时不做任何事
+func RecordRowsExamined(ctx context.Context, rows []Row) {
+	if ctx == nil {
+		return
+	}
+	if stats, ok := ctx.Value(queryStatsKey{}).(*QueryStats); ok {
+		stats.AddRows(rows)
+	}
+}
+
+// RecordRowsMatched 把 UPDATE/DELETE 匹配的行数计入上下文中的统计。
+// 数据源在内部按 WHERE 过滤，执行器只能得到匹配的行数
+func RecordRowsMatched(ctx context.Context, n int64) {
+	if ctx == nil {
+		return
+	}
+	if stats, ok := ctx.Value(queryStatsKey{}).(*QueryStats); ok {
+		stats.rowsExamined.Add(n)
+	}
+}
+
+// EstimateRowsSize 估算行的内存大小：每个值按其文本长度计，另加每列 16 字节的键与接口开销
+func EstimateRowsSize(rows []Row) int64 {
+	var size int64
+	for _, row := range rows {
+		for key, v := range row {
+			size += int64(len(key)) + 16
+			switch val := v.(type) {
+			case nil:
+			case string:
+				size += int64(len(val))
+			case []byte:
+				size += int64(len(val))
+			case int64, float64, int, bool:
+				size += 8
+			default:
+				size += int64(len(fmt.Sprint(val)))
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "context"
+
+// RowCountDataSource 能够不返回行就统计表行数的数据源接口
+//
+// 查询层用它执行只统计行数的查询（SELECT COUNT(*) FROM t WHERE ...），以及在没有统计信息时为优化器提供表的行数：
+//   - filters 与 QueryOptions.Filters 的语义相同，为空时统计全表
+//   - exact 为 true 时 count 是精确值，查询层直接作为 COUNT(*) 的结果返回；
+//     为 false 时 count 只是估计值（如来自元数据或采样），只用于代价估算，COUNT(*) 仍按行统计
+//   - 数据源不能统计某张表或某组过滤条件时返回错误（如 ErrUnsupportedOperation），查询层回退到读取行
+type RowCountDataSource interface {
+	// RowCount 返回表中满足 filters 的行数，exact 表示结果是否精确
+	RowCount(ctx context.Context, tableName string, filters []Filter) (count int64, exact bool, err error)
+}
+
+// ExactRowCount 返回数据源统计的精确行数；数据源不支持、统计失败或只能给出估计值时 ok 为 false
+func ExactRowCount(ctx context.Context, ds DataSource, tableName string, filters []Filter) (count int64, ok bool) {
+	counter, isCounter := ds.(RowCountDataSource)
+	if !isCounter {
+		return 0, false
+	}
+	count, exact, err := counter.RowCount(ctx, tableName, filters)
+	if err != nil || !exact {
+		return 0, false
+	}
+	return count, true
+}
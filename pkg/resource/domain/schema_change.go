@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// 表结构变化类型
+const (
+	SchemaChangeCreated = "created"
+	SchemaChangeAltered = "altered"
+	SchemaChangeDropped = "dropped"
+)
+
+// SchemaChange 数据源报告的一次表结构变化
+type SchemaChange struct {
+	Table string `json:"table"`
+	Type  string `json:"type"` // created, altered, dropped
+}
+
+// SchemaChangeSource 能够报告表结构变化的数据源接口
+// 宿主定期轮询，根据返回的变化清理表信息、执行计划和查询结果缓存
+type SchemaChangeSource interface {
+	DataSource
+
+	// GetSchemaChanges 返回版本 since 之后发生的表结构变化以及当前版本
+	// 首次调用时 since 为 0；不支持时返回 ErrUnsupportedOperation，宿主将不再轮询
+	GetSchemaChanges(ctx context.Context, since int64) ([]SchemaChange, int64, error)
+}
+
+// SchemaVersion 返回表结构的版本：由列的名称、顺序、类型、可空和无符号属性计算，表不存在（info 为 nil）时为 0。
+// 预处理语句和游标记录所引用表的版本，执行或读取下一页时版本不同说明按原有元数据解释的行已不可靠。
+// 结构相同的表版本相同（包括删除后按相同结构重建），此时原有元数据仍然适用
+func SchemaVersion(info *TableInfo) uint64 {
+	if info == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	for _, col := range info.Columns {
+		fmt.Fprintf(h, "%s\x00%s\x00%t\x00%t;", col.Name, col.Type, col.Nullable, col.Unsigned)
+	}
+	return h.Sum64()
+}
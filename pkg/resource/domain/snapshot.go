@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+// TableSnapshotInfo 表快照导出/导入的结果
+type TableSnapshotInfo struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes"` // 快照文件大小
+}
+
+// TableSnapshotter 支持将表导出为二进制快照文件并从快照导入的数据源接口
+// 快照包含表结构、按列存储的数据和索引定义，用于快速冷启动和在环境之间传输数据集
+type TableSnapshotter interface {
+	// ExportTable 将表的最新版本写入快照文件
+	ExportTable(ctx context.Context, tableName, path string) (*TableSnapshotInfo, error)
+
+	// ImportTable 从快照文件创建表并载入数据和索引，tableName 为空时使用快照中的表名
+	// 表已存在或快照校验失败时返回错误
+	ImportTable(ctx context.Context, tableName, path string) (*TableSnapshotInfo, error)
+}
@@ -0,0 +1,27 @@
+package domain
+
+// SortableDataSource 能够按多列排序键（含方向和排序规则）排序的数据源接口
+//
+// 查询层与数据源按以下规则协商 ORDER BY 下推：
+//   - QueryOptions.Sort 总是携带完整的排序键，OrderBy/Order 携带第一个排序键，供只支持单列排序的数据源使用
+//   - 数据源按排序键完整排序后设置 QueryResult.Ordered，查询层不再排序；否则查询层自行排序
+//   - 只有排序结果可信时 LIMIT/OFFSET 才随排序一起下推：数据源实现本接口且 SupportsSort 返回 true，
+//     或者只有一个不带排序规则的排序键（与只认识 OrderBy/Order 的数据源保持兼容）；
+//     其它情况下数据源返回全部匹配的行，由查询层排序后再分页
+//
+// 排序语义与 utils.CompareValuesForSortWithCollation 一致：NULL 最小，数字与数字字符串按数值比较
+type SortableDataSource interface {
+	// SupportsSort 数据源能否按 keys 对表排序并在排序后分页
+	SupportsSort(tableName string, keys []OrderByItem) bool
+}
+
+// CanPushDownPaging 判断带排序的查询能否把 LIMIT/OFFSET 下推给数据源
+func CanPushDownPaging(ds DataSource, tableName string, keys []OrderByItem) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	if sortable, ok := ds.(SortableDataSource); ok {
+		return sortable.SupportsSort(tableName, keys)
+	}
+	return len(keys) == 1 && keys[0].Collation == ""
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"io"
+)
+
+// RowIterator 逐行读取查询结果的迭代器
+// 数据源可以按页从底层拉取数据，避免一次性加载整个结果集
+type RowIterator interface {
+	// Columns 返回结果列信息
+	Columns() []ColumnInfo
+
+	// Next 返回下一行，没有更多数据时返回 io.EOF
+	Next(ctx context.Context) (Row, error)
+
+	// Close 释放迭代器持有的资源，可重复调用
+	Close() error
+}
+
+// StreamingDataSource 支持流式查询的数据源接口
+type StreamingDataSource interface {
+	DataSource
+
+	// QueryStream 以迭代器形式返回查询结果，调用方负责 Close
+	QueryStream(ctx context.Context, tableName string, options *QueryOptions) (RowIterator, error)
+}
+
+// QueryRows 查询数据，数据源支持流式查询时逐页读取结果
+func QueryRows(ctx context.Context, ds DataSource, tableName string, options *QueryOptions) (*QueryResult, error) {
+	streaming, ok := ds.(StreamingDataSource)
+	if !ok {
+		return ds.Query(ctx, tableName, options)
+	}
+
+	it, err := streaming.QueryStream(ctx, tableName, options)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	return CollectRows(ctx, it)
+}
+
+// CollectRows 读取迭代器中的全部行
+func CollectRows(ctx context.Context, it RowIterator) (*QueryResult, error) {
+	result := &QueryResult{Columns: it.Columns()}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		row, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	result.Total = int64(len(result.Rows))
+	return result, nil
+}
+
+// sliceRowIterator 基于内存结果的迭代器
+type sliceRowIterator struct {
+	columns []ColumnInfo
+	rows    []Row
+	pos     int
+}
+
+// NewSliceRowIterator 将已加载的查询结果包装为迭代器
+func NewSliceRowIterator(result *QueryResult) RowIterator {
+	if result == nil {
+		return &sliceRowIterator{}
+	}
+	return &sliceRowIterator{columns: result.Columns, rows: result.Rows}
+}
+
+func (it *sliceRowIterator) Columns() []ColumnInfo {
+	return it.columns
+}
+
+func (it *sliceRowIterator) Next(ctx context.Context) (Row, error) {
+	if it.pos >= len(it.rows) {
+		return nil, io.EOF
+	}
+	row := it.rows[it.pos]
+	it.pos++
+	return row, nil
+}
+
+func (it *sliceRowIterator) Close() error {
+	it.rows = nil
+	return nil
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "context"
+
+// TableChecksum CHECKSUM TABLE 的结果
+type TableChecksum struct {
+	Table    string `json:"table"`
+	Rows     int64  `json:"rows"`
+	Checksum uint64 `json:"checksum"`
+}
+
+// TableCheckResult CHECK TABLE 的结果
+type TableCheckResult struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+	// Problems 发现的损坏或约束冲突，为空表示表完好
+	Problems []string `json:"problems,omitempty"`
+}
+
+// OK 表是否通过检查
+func (r *TableCheckResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// TableChecker 支持校验和与一致性检查的数据源接口
+type TableChecker interface {
+	// ChecksumTable 计算表数据的校验和
+	// 校验和与行的顺序和物理存储无关，内容相同的表在不同节点上得到相同结果，可用于比对副本
+	ChecksumTable(ctx context.Context, tableName string) (*TableChecksum, error)
+
+	// CheckTable 检查表的内部一致性（索引与行存储是否一致、约束是否成立），返回发现的问题
+	CheckTable(ctx context.Context, tableName string) (*TableCheckResult, error)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TTLInfo 表的过期（保留）策略
+// Column 列的值早于当前时间减去 Duration 的行视为过期，由后台清理任务删除
+type TTLInfo struct {
+	Column   string        `json:"column"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Clone 拷贝过期策略
+func (t *TTLInfo) Clone() *TTLInfo {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
+
+// TTLManager 支持设置表过期策略的数据源接口
+type TTLManager interface {
+	// SetTableTTL 设置表的过期策略，ttl 为 nil 时移除
+	SetTableTTL(ctx context.Context, tableName string, ttl *TTLInfo) error
+}
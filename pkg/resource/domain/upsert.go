@@ -0,0 +1,37 @@
+package domain
+
+import "context"
+
+// UpsertOptions 插入时与已有行冲突（主键或唯一键重复）的处理方式
+type UpsertOptions struct {
+	// Ignore 跳过冲突的行并产生警告（INSERT IGNORE）
+	Ignore bool
+	// Update 计算冲突行的新值（ON DUPLICATE KEY UPDATE），existing 为表中已有的行，
+	// inserted 为待插入的行；返回的行只需包含要修改的列
+	Update func(existing, inserted Row) (Row, error)
+}
+
+// UpsertResult 逐行冲突处理的统计
+type UpsertResult struct {
+	Inserted     int64    `json:"inserted"`
+	Updated      int64    `json:"updated"`
+	Unchanged    int64    `json:"unchanged"` // 冲突但更新后值不变的行
+	Skipped      int64    `json:"skipped"`   // 因 Ignore 被跳过的行
+	LastInsertID int64    `json:"last_insert_id"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// AffectedRows 按 MySQL 的约定返回影响行数：插入计 1，更新计 2，值不变与跳过计 0
+func (r *UpsertResult) AffectedRows() int64 {
+	return r.Inserted + 2*r.Updated
+}
+
+// Duplicates 返回与已有行冲突的行数
+func (r *UpsertResult) Duplicates() int64 {
+	return r.Updated + r.Unchanged + r.Skipped
+}
+
+// Upserter 支持原子地插入或更新冲突行的数据源接口：整条语句要么全部生效，要么不生效
+type Upserter interface {
+	Upsert(ctx context.Context, tableName string, rows []Row, options *UpsertOptions) (*UpsertResult, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WritePolicy 数据库的写入策略，在语句分发前统一检查
+type WritePolicy string
+
+const (
+	WritePolicyDefault  WritePolicy = ""          // 未设置：由数据源自身的 IsWritable 决定
+	WritePolicyFull     WritePolicy = "full"      // 允许 DML 和 DDL
+	WritePolicyDDLOnly  WritePolicy = "ddl_only"  // 只允许 DDL，禁止修改数据
+	WritePolicyReadOnly WritePolicy = "read_only" // 禁止 DML 和 DDL
+)
+
+// ParseWritePolicy 解析写入策略（不区分大小写，空串表示未设置）
+func ParseWritePolicy(s string) (WritePolicy, error) {
+	switch p := WritePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case WritePolicyDefault, WritePolicyFull, WritePolicyDDLOnly, WritePolicyReadOnly:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid write policy '%s' (expected full, ddl_only or read_only)", s)
+}
+
+// AllowsDML 是否允许 INSERT/UPDATE/DELETE
+func (p WritePolicy) AllowsDML() bool {
+	return p != WritePolicyDDLOnly && p != WritePolicyReadOnly
+}
+
+// AllowsDDL 是否允许 CREATE/DROP/ALTER 等结构变更
+func (p WritePolicy) AllowsDDL() bool {
+	return p != WritePolicyReadOnly
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// XATransaction 支持两阶段提交的事务接口（可选）
+// Prepare 成功后事务不再接受语句，之后只能 Commit 或 Rollback
+type XATransaction interface {
+	Transaction
+
+	// Prepare 第一阶段：校验事务能够提交并以 xid 标记为已准备
+	Prepare(ctx context.Context, xid string) error
+}
+
+// XADataSource 支持恢复已准备事务的数据源接口（可选）
+// 协调者在故障恢复时通过 xid 完成处于不确定状态的事务
+type XADataSource interface {
+	TransactionalDataSource
+
+	// RecoverXA 返回已准备但尚未提交或回滚的事务 xid
+	RecoverXA(ctx context.Context) ([]string, error)
+
+	// CommitXA 提交已准备的事务
+	CommitXA(ctx context.Context, xid string) error
+
+	// RollbackXA 回滚已准备的事务
+	RollbackXA(ctx context.Context, xid string) error
+}
+
+// ErrXAUnknown 未知的 XA 事务（MySQL 错误码 1397）
+type ErrXAUnknown struct {
+	XID string
+}
+
+func (e *ErrXAUnknown) Error() string {
+	return fmt.Sprintf("XAER_NOTA: Unknown XID '%s'", e.XID)
+}
+
+// NewErrXAUnknown 创建未知 XA 事务错误
+func NewErrXAUnknown(xid string) *ErrXAUnknown {
+	return &ErrXAUnknown{XID: xid}
+}
+
+// IsXAUnknown 判断错误是否为未知 XA 事务
+func IsXAUnknown(err error) bool {
+	var e *ErrXAUnknown
+	return errors.As(err, &e)
+}
+
+// ErrXAPrepared 事务已准备，不能再执行语句（MySQL 错误码 1399）
+type ErrXAPrepared struct {
+	XID string
+}
+
+func (e *ErrXAPrepared) Error() string {
+	return fmt.Sprintf("XAER_RMFAIL: The command cannot be executed when global transaction is in the PREPARED state (xid '%s')", e.XID)
+}
+
+// NewErrXAPrepared 创建事务已准备错误
+func NewErrXAPrepared(xid string) *ErrXAPrepared {
+	return &ErrXAPrepared{XID: xid}
+}
@@ -9,6 +9,19 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 )
 
+// ExpressionEvaluatorFunc SQL 表达式求值函数（用于避免循环依赖）
+// expr 为生成列定义中的表达式文本，row 包含表中所有列（未赋值的列为 nil）
+type ExpressionEvaluatorFunc func(expr string, row domain.Row) (interface{}, error)
+
+var sqlExpressionEvaluator ExpressionEvaluatorFunc
+
+// RegisterExpressionEvaluator 注册完整的 SQL 表达式求值器
+// 注册后生成列使用与查询相同的表达式语义（字符串字面量、函数、CASE 等）；
+// 未注册时使用内置的简单算术求值器
+func RegisterExpressionEvaluator(fn ExpressionEvaluatorFunc) {
+	sqlExpressionEvaluator = fn
+}
+
 // GeneratedColumnEvaluator 生成列求值器
 type GeneratedColumnEvaluator struct {
 	functionAPI *builtin.FunctionAPI
@@ -37,6 +50,10 @@ func (e *GeneratedColumnEvaluator) Evaluate(
 	row domain.Row,
 	schema *domain.TableInfo,
 ) (interface{}, error) {
+	if sqlExpressionEvaluator != nil {
+		return sqlExpressionEvaluator(expr, completeRow(row, schema))
+	}
+
 	result, err := e.evaluateExpression(expr, row)
 	if err != nil {
 		return nil, err
@@ -45,6 +62,45 @@ func (e *GeneratedColumnEvaluator) Evaluate(
 	return result, nil
 }
 
+// EvaluateColumn 计算单个生成列的值并转换为列类型
+func (e *GeneratedColumnEvaluator) EvaluateColumn(
+	col *domain.ColumnInfo,
+	row domain.Row,
+	schema *domain.TableInfo,
+) (interface{}, error) {
+	val, err := e.Evaluate(col.GeneratedExpr, row, schema)
+	if err != nil {
+		return nil, err
+	}
+	return CastToType(val, col.Type)
+}
+
+// completeRow 补齐 schema 中缺失的列（视为 NULL），避免表达式因列不存在而报错
+func completeRow(row domain.Row, schema *domain.TableInfo) domain.Row {
+	if schema == nil {
+		return row
+	}
+	missing := false
+	for _, col := range schema.Columns {
+		if _, ok := row[col.Name]; !ok {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return row
+	}
+
+	result := make(domain.Row, len(schema.Columns))
+	for _, col := range schema.Columns {
+		result[col.Name] = nil
+	}
+	for k, v := range row {
+		result[k] = v
+	}
+	return result
+}
+
 // EvaluateAll 递归计算所有生成列
 func (e *GeneratedColumnEvaluator) EvaluateAll(
 	row domain.Row,
@@ -70,19 +126,12 @@ func (e *GeneratedColumnEvaluator) EvaluateAll(
 			continue
 		}
 
-		val, evalErr := e.Evaluate(colInfo.GeneratedExpr, result, schema)
+		val, evalErr := e.EvaluateColumn(colInfo, result, schema)
 		if evalErr != nil {
 			result[colName] = nil
 			continue
 		}
-
-		castedVal, castErr := CastToType(val, colInfo.Type)
-		if castErr != nil {
-			result[colName] = nil
-			continue
-		} else {
-			result[colName] = castedVal
-		}
+		result[colName] = val
 	}
 
 	return result, nil
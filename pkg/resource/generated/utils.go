@@ -64,8 +64,16 @@ func GetAffectedGeneratedColumns(
 		}
 	}
 
-	// 转换为切片并按计算顺序排序
+	// 转换为切片并按计算顺序排序（被依赖的生成列先计算）
 	result := make([]string, 0, len(affected))
+	if order, err := NewGeneratedColumnEvaluator().GetEvaluationOrder(schema); err == nil {
+		for _, colName := range order {
+			if affected[colName] {
+				result = append(result, colName)
+			}
+		}
+		return result
+	}
 	for colName := range affected {
 		result = append(result, colName)
 	}
@@ -412,3 +412,18 @@ func TestCastToFloat_MoreIntTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAffectedGeneratedColumns_EvaluationOrder(t *testing.T) {
+	schema := &domain.TableInfo{
+		Name: "test_table",
+		Columns: []domain.ColumnInfo{
+			{Name: "col1", Type: "INT", Nullable: true},
+			{Name: "col4", Type: "INT", Nullable: true, IsGenerated: true, GeneratedExpr: "col3+1", GeneratedDepends: []string{"col3"}},
+			{Name: "col3", Type: "INT", Nullable: true, IsGenerated: true, GeneratedExpr: "col2+1", GeneratedDepends: []string{"col2"}},
+			{Name: "col2", Type: "INT", Nullable: true, IsGenerated: true, GeneratedExpr: "col1*2", GeneratedDepends: []string{"col1"}},
+		},
+	}
+
+	affected := GetAffectedGeneratedColumns([]string{"col1"}, schema)
+	assert.Equal(t, []string{"col2", "col3", "col4"}, affected)
+}
@@ -50,22 +50,10 @@ func (v *VirtualCalculator) CalculateColumn(
 		return nil, fmt.Errorf("generated expression is empty")
 	}
 
-	// 使用现有的求值器计算表达式
+	// 使用现有的求值器计算表达式并转换到列的目标类型
+	// 计算或转换失败时返回 NULL 和错误
 	evaluator := NewGeneratedColumnEvaluator()
-	result, err := evaluator.Evaluate(col.GeneratedExpr, row, schema)
-	if err != nil {
-		// VIRTUAL 列计算失败时返回 NULL 和错误
-		return nil, err
-	}
-
-	// 类型转换到列的目标类型
-	castedResult, castErr := CastToType(result, col.Type)
-	if castErr != nil {
-		// 类型转换失败时返回 NULL 和错误
-		return nil, castErr
-	}
-
-	return castedResult, nil
+	return evaluator.EvaluateColumn(col, row, schema)
 }
 
 // CalculateRowVirtuals 计算行中所有 VIRTUAL 列的值
@@ -282,6 +282,147 @@ func (ds *HybridDataSource) TruncateTable(ctx context.Context, tableName string)
 	return source.TruncateTable(ctx, tableName)
 }
 
+// AddPartitions adds partitions to a partitioned table
+func (ds *HybridDataSource) AddPartitions(ctx context.Context, tableName string, partitions []domain.PartitionDef) error {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return err
+	}
+	pm, ok := source.(domain.PartitionManager)
+	if !ok {
+		return fmt.Errorf("data source for table %s does not support partitioning", tableName)
+	}
+	return pm.AddPartitions(ctx, tableName, partitions)
+}
+
+// DropPartitions drops partitions from a partitioned table
+func (ds *HybridDataSource) DropPartitions(ctx context.Context, tableName string, names []string) error {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return err
+	}
+	pm, ok := source.(domain.PartitionManager)
+	if !ok {
+		return fmt.Errorf("data source for table %s does not support partitioning", tableName)
+	}
+	return pm.DropPartitions(ctx, tableName, names)
+}
+
+// SetTableTTL sets or removes the row expiration policy of a table
+func (ds *HybridDataSource) SetTableTTL(ctx context.Context, tableName string, ttl *domain.TTLInfo) error {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return err
+	}
+	tm, ok := source.(domain.TTLManager)
+	if !ok {
+		return fmt.Errorf("data source for table %s does not support TTL", tableName)
+	}
+	return tm.SetTableTTL(ctx, tableName, ttl)
+}
+
+// CompactTable rebuilds the row storage and indexes of a table online
+func (ds *HybridDataSource) CompactTable(ctx context.Context, tableName string) (*domain.CompactionProgress, error) {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tc, ok := source.(domain.TableCompactor)
+	if !ok {
+		return nil, fmt.Errorf("data source for table %s does not support compaction", tableName)
+	}
+	return tc.CompactTable(ctx, tableName)
+}
+
+// CompactionStatus returns the progress of the last compaction of a table
+func (ds *HybridDataSource) CompactionStatus(tableName string) (*domain.CompactionProgress, bool) {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return nil, false
+	}
+	tc, ok := source.(domain.TableCompactor)
+	if !ok {
+		return nil, false
+	}
+	return tc.CompactionStatus(tableName)
+}
+
+// ChecksumTable computes an order-independent checksum of a table
+func (ds *HybridDataSource) ChecksumTable(ctx context.Context, tableName string) (*domain.TableChecksum, error) {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tc, ok := source.(domain.TableChecker)
+	if !ok {
+		return nil, fmt.Errorf("data source for table %s does not support CHECKSUM TABLE", tableName)
+	}
+	return tc.ChecksumTable(ctx, tableName)
+}
+
+// CheckTable verifies the internal consistency of a table
+func (ds *HybridDataSource) CheckTable(ctx context.Context, tableName string) (*domain.TableCheckResult, error) {
+	source, err := ds.ddlSource(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tc, ok := source.(domain.TableChecker)
+	if !ok {
+		return nil, fmt.Errorf("data source for table %s does not support CHECK TABLE", tableName)
+	}
+	return tc.CheckTable(ctx, tableName)
+}
+
+// SubscribeChanges subscribes to the committed row changes of the memory data source.
+// Subscribing before Connect has no effect.
+func (ds *HybridDataSource) SubscribeChanges(table string, fn func(domain.ChangeEvent)) func() {
+	ds.mu.RLock()
+	mem := ds.memory
+	ds.mu.RUnlock()
+	if mem == nil {
+		return func() {}
+	}
+	return mem.SubscribeChanges(table, fn)
+}
+
+// ChangesSince returns the retained row changes of the memory data source after LSN since
+func (ds *HybridDataSource) ChangesSince(ctx context.Context, since int64) ([]domain.ChangeEvent, int64, error) {
+	ds.mu.RLock()
+	mem := ds.memory
+	ds.mu.RUnlock()
+	if mem == nil {
+		return nil, 0, fmt.Errorf("data source not connected")
+	}
+	return mem.ChangesSince(ctx, since)
+}
+
+// ChangeLogID returns the identity of the change log of the memory data source
+func (ds *HybridDataSource) ChangeLogID() string {
+	ds.mu.RLock()
+	mem := ds.memory
+	ds.mu.RUnlock()
+	if mem == nil {
+		return ""
+	}
+	return mem.ChangeLogID()
+}
+
+// ddlSource returns the data source that owns the schema of the table
+func (ds *HybridDataSource) ddlSource(tableName string) (domain.DataSource, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	if !ds.connected {
+		return nil, fmt.Errorf("data source not connected")
+	}
+
+	source := ds.router.GetDDLSource(tableName)
+	if source == nil {
+		return nil, fmt.Errorf("no data source available for table %s", tableName)
+	}
+	return source, nil
+}
+
 // ==================== CRUD Operations ====================
 
 // Query queries rows from a table
@@ -512,6 +653,132 @@ func (t *HybridTransaction) Delete(ctx context.Context, tableName string, filter
 	return t.memTxn.Delete(ctx, tableName, filters, options)
 }
 
+// CreateTable delegates transactional CREATE TABLE to the memory transaction.
+// Like DML in a hybrid transaction, it only affects the memory side.
+func (t *HybridTransaction) CreateTable(ctx context.Context, tableInfo *domain.TableInfo) error {
+	ddl, err := t.ddlTxn()
+	if err != nil {
+		return err
+	}
+	return ddl.CreateTable(ctx, tableInfo)
+}
+
+// DropTable delegates transactional DROP TABLE to the memory transaction.
+func (t *HybridTransaction) DropTable(ctx context.Context, tableName string) error {
+	ddl, err := t.ddlTxn()
+	if err != nil {
+		return err
+	}
+	return ddl.DropTable(ctx, tableName)
+}
+
+// TruncateTable delegates transactional TRUNCATE TABLE to the memory transaction.
+func (t *HybridTransaction) TruncateTable(ctx context.Context, tableName string) error {
+	ddl, err := t.ddlTxn()
+	if err != nil {
+		return err
+	}
+	return ddl.TruncateTable(ctx, tableName)
+}
+
+// SetTableTTL delegates transactional TTL changes to the memory transaction.
+func (t *HybridTransaction) SetTableTTL(ctx context.Context, tableName string, ttl *domain.TTLInfo) error {
+	ddl, err := t.ddlTxn()
+	if err != nil {
+		return err
+	}
+	return ddl.SetTableTTL(ctx, tableName, ttl)
+}
+
+// Savepoint delegates to the memory transaction.
+func (t *HybridTransaction) Savepoint(ctx context.Context, name string) error {
+	sp, err := t.savepointTxn()
+	if err != nil {
+		return err
+	}
+	return sp.Savepoint(ctx, name)
+}
+
+// RollbackToSavepoint delegates to the memory transaction.
+func (t *HybridTransaction) RollbackToSavepoint(ctx context.Context, name string) error {
+	sp, err := t.savepointTxn()
+	if err != nil {
+		return err
+	}
+	return sp.RollbackToSavepoint(ctx, name)
+}
+
+// ReleaseSavepoint delegates to the memory transaction.
+func (t *HybridTransaction) ReleaseSavepoint(ctx context.Context, name string) error {
+	sp, err := t.savepointTxn()
+	if err != nil {
+		return err
+	}
+	return sp.ReleaseSavepoint(ctx, name)
+}
+
+// Prepare delegates the first phase of a two-phase commit to the memory
+// transaction.
+func (t *HybridTransaction) Prepare(ctx context.Context, xid string) error {
+	xa, ok := t.memTxn.(domain.XATransaction)
+	if !ok {
+		return fmt.Errorf("memory transaction does not support two-phase commit")
+	}
+	return xa.Prepare(ctx, xid)
+}
+
+// RecoverXA returns the prepared transactions of the memory data source.
+func (ds *HybridDataSource) RecoverXA(ctx context.Context) ([]string, error) {
+	mem, err := ds.xaSource()
+	if err != nil {
+		return nil, err
+	}
+	return mem.RecoverXA(ctx)
+}
+
+// CommitXA commits a prepared memory transaction.
+func (ds *HybridDataSource) CommitXA(ctx context.Context, xid string) error {
+	mem, err := ds.xaSource()
+	if err != nil {
+		return err
+	}
+	return mem.CommitXA(ctx, xid)
+}
+
+// RollbackXA rolls back a prepared memory transaction.
+func (ds *HybridDataSource) RollbackXA(ctx context.Context, xid string) error {
+	mem, err := ds.xaSource()
+	if err != nil {
+		return err
+	}
+	return mem.RollbackXA(ctx, xid)
+}
+
+func (ds *HybridDataSource) xaSource() (*memory.MVCCDataSource, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if !ds.connected {
+		return nil, fmt.Errorf("data source not connected")
+	}
+	return ds.memory, nil
+}
+
+func (t *HybridTransaction) ddlTxn() (domain.DDLTransaction, error) {
+	ddl, ok := t.memTxn.(domain.DDLTransaction)
+	if !ok {
+		return nil, fmt.Errorf("memory transaction does not support transactional DDL")
+	}
+	return ddl, nil
+}
+
+func (t *HybridTransaction) savepointTxn() (domain.SavepointTransaction, error) {
+	sp, ok := t.memTxn.(domain.SavepointTransaction)
+	if !ok {
+		return nil, fmt.Errorf("memory transaction does not support savepoints")
+	}
+	return sp, nil
+}
+
 // ==================== Dual-Write Operations ====================
 
 // dualWrite writes to both data sources
@@ -4,17 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"os"
 	"path/filepath"
 	"sort"
 
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/filemeta"
 	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
 )
 
+// dsLog JSON 数据源的结构化日志
+var dsLog = logging.Module("resource.json")
+
 // JSONAdapter JSON文件数据源适配器
 // 继承 MVCCDataSource，只负责JSON格式的加载和写回
 type JSONAdapter struct {
@@ -137,7 +140,7 @@ func (a *JSONAdapter) Connect(ctx context.Context) error {
 	if meta != nil {
 		for _, idx := range meta.Indexes {
 			if err := a.MVCCDataSource.CreateIndexWithColumns(idx.Table, idx.Columns, idx.Type, idx.Unique); err != nil {
-				log.Printf("warning: failed to rebuild index %s on %s: %v", idx.Name, idx.Table, err)
+				dsLog.Warn("failed to rebuild index", "index", idx.Name, "table", idx.Table, "err", err)
 			}
 		}
 	}
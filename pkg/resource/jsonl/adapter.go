@@ -5,18 +5,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/filemeta"
 	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
 )
 
+// dsLog JSONL 数据源的结构化日志
+var dsLog = logging.Module("resource.jsonl")
+
 // JSONLAdapter JSONL文件数据源适配器
 // 继承 MVCCDataSource，只负责JSONL格式的加载和写回
 type JSONLAdapter struct {
@@ -132,7 +135,7 @@ func (a *JSONLAdapter) Connect(ctx context.Context) error {
 	if meta != nil {
 		for _, idx := range meta.Indexes {
 			if err := a.MVCCDataSource.CreateIndexWithColumns(idx.Table, idx.Columns, idx.Type, idx.Unique); err != nil {
-				log.Printf("warning: failed to rebuild index %s on %s: %v", idx.Name, idx.Table, err)
+				dsLog.Warn("failed to rebuild index", "index", idx.Name, "table", idx.Table, "err", err)
 			}
 		}
 	}
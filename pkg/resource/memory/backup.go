@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// BackupSnapshot pins the latest version of the selected tables (all
+// non-temporary tables when tables is empty) and the change log position at
+// one instant. Writes continue while the snapshot is open; the pinned versions
+// are kept by garbage collection until Close.
+//
+// Non-transactional writes bump the version under m.mu and install it and
+// record its changes under the table lock, and commits hold m.mu throughout,
+// so each pinned version contains exactly the changes up to the recorded LSN.
+func (m *MVCCDataSource) BackupSnapshot(ctx context.Context, tables []string) (domain.BackupSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.connected {
+		return nil, domain.NewErrNotConnected("memory")
+	}
+
+	explicit := len(tables) > 0
+	if !explicit {
+		for name := range m.tables {
+			if !m.tempTables[name] && !isPartitionTable(name) {
+				tables = append(tables, name)
+			}
+		}
+	}
+
+	// Every write has released m.mu before it installs its version and
+	// records its changes, so all table locks are taken to wait for writes
+	// still in flight before the versions and the LSN are read.
+	for _, tableVer := range m.tables {
+		tableVer.mu.RLock()
+	}
+	defer func() {
+		for _, tableVer := range m.tables {
+			tableVer.mu.RUnlock()
+		}
+	}()
+
+	snap := &backupSnapshot{
+		ds:     m,
+		tables: make(map[string]*backupTable, len(tables)),
+	}
+	minVer := m.currentVer
+	for _, name := range tables {
+		tableVer, ok := m.tables[name]
+		if !ok || isPartitionTable(name) || m.tempTables[name] {
+			return nil, domain.NewErrTableNotFound(name)
+		}
+		data := tableVer.versions[tableVer.latest]
+		if data == nil {
+			return nil, domain.NewErrTableNotFound(name)
+		}
+		if data.schema.IsPartitioned() {
+			if explicit {
+				return nil, fmt.Errorf("cannot back up partitioned table '%s'", name)
+			}
+			snap.skipped = append(snap.skipped, name)
+			continue
+		}
+
+		autoInc := make(map[string]int64)
+		prefix := name + "."
+		for key, v := range m.autoIncCounters {
+			if strings.HasPrefix(key, prefix) {
+				autoInc[key[len(prefix):]] = v
+			}
+		}
+		indexes, _ := m.indexManager.GetTableIndexes(name)
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+
+		snap.tables[name] = &backupTable{tableVer: tableVer, data: data, autoInc: autoInc, indexes: indexes}
+		snap.names = append(snap.names, name)
+		minVer = min(minVer, data.version)
+	}
+	sort.Strings(snap.names)
+	sort.Strings(snap.skipped)
+
+	m.changes.mu.Lock()
+	snap.lsn = m.changes.lastLSN
+	snap.changeLog = m.changes.idLocked()
+	m.changes.mu.Unlock()
+
+	// Register a snapshot without tables so garbage collection keeps every
+	// version from the oldest pinned one on
+	snap.txnID = m.nextTxID
+	m.nextTxID++
+	m.snapshots[snap.txnID] = &Snapshot{
+		txnID:          snap.txnID,
+		startVer:       minVer,
+		createdAt:      time.Now(),
+		tableSnapshots: make(map[string]*COWTableSnapshot),
+		createdTables:  make(map[string]*TableVersions),
+		droppedTables:  make(map[string]bool),
+	}
+	return snap, nil
+}
+
+// backupTable is a table pinned by a backup snapshot
+type backupTable struct {
+	tableVer *TableVersions
+	data     *TableData
+	autoInc  map[string]int64
+	indexes  []*IndexInfo
+}
+
+// backupSnapshot implements domain.BackupSnapshot
+type backupSnapshot struct {
+	ds        *MVCCDataSource
+	txnID     int64
+	lsn       int64
+	changeLog string
+	names     []string
+	skipped   []string
+	tables    map[string]*backupTable
+
+	closeOnce sync.Once
+}
+
+func (s *backupSnapshot) LSN() int64 {
+	return s.lsn
+}
+
+func (s *backupSnapshot) ChangeLog() string {
+	return s.changeLog
+}
+
+func (s *backupSnapshot) Tables() []string {
+	return s.names
+}
+
+func (s *backupSnapshot) Skipped() []string {
+	return s.skipped
+}
+
+// WriteTable writes the pinned version of a table in the table snapshot
+// format, so it can be restored with ImportTable.
+func (s *backupSnapshot) WriteTable(ctx context.Context, table string, w io.Writer) (*domain.TableSnapshotInfo, error) {
+	bt, ok := s.tables[table]
+	if !ok {
+		return nil, domain.NewErrTableNotFound(table)
+	}
+
+	// Dropping a table releases the rows of all its versions, so check that
+	// the table still exists and hold its lock while copying the rows out.
+	m := s.ds
+	m.mu.RLock()
+	current, ok := m.tables[table]
+	if !ok || current != bt.tableVer {
+		m.mu.RUnlock()
+		return nil, fmt.Errorf("table '%s' was dropped during the backup", table)
+	}
+	bt.tableVer.mu.RLock()
+	m.mu.RUnlock()
+	rows := bt.data.Rows()
+	bt.tableVer.mu.RUnlock()
+
+	meta, err := json.Marshal(&snapshotMeta{
+		Table:         bt.data.schema,
+		Rows:          int64(len(rows)),
+		Indexes:       bt.indexes,
+		CreatedAt:     bt.data.createdAt,
+		AutoIncrement: bt.autoInc,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode snapshot header: %w", err)
+	}
+
+	cw := &byteCounter{w: w}
+	if err := writeSnapshot(ctx, cw, bt.data.schema, meta, rows); err != nil {
+		return nil, err
+	}
+	return &domain.TableSnapshotInfo{Table: table, Rows: int64(len(rows)), Bytes: cw.n}, nil
+}
+
+// Close unpins the versions so garbage collection can reclaim them
+func (s *backupSnapshot) Close() {
+	s.closeOnce.Do(func() {
+		s.ds.mu.Lock()
+		delete(s.ds.snapshots, s.txnID)
+		s.ds.mu.Unlock()
+	})
+}
+
+// byteCounter counts the bytes written through it
+type byteCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// TestBackupSnapshot_ConsistentWhileWriting verifies that a backup snapshot
+// keeps the versions current when it was taken while writes continue, and
+// that its LSN is where the change log resumes.
+func TestBackupSnapshot_ConsistentWhileWriting(t *testing.T) {
+	ctx := context.Background()
+	src := newSnapshotTestSource(t)
+	src.SetChangeLogSize(100)
+	for _, name := range []string{"orders", "users"} {
+		src.CreateTable(ctx, &domain.TableInfo{
+			Name:    name,
+			Columns: []domain.ColumnInfo{{Name: "id", Type: "INTEGER", Primary: true, AutoIncrement: true}, {Name: "v", Type: "VARCHAR"}},
+		})
+		if _, err := src.Insert(ctx, name, []domain.Row{{"v": "a"}, {"v": "b"}}, nil); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	src.CreateTable(ctx, &domain.TableInfo{Name: "scratch", Columns: []domain.ColumnInfo{{Name: "id", Type: "INTEGER"}}, Temporary: true})
+
+	snap, err := src.BackupSnapshot(ctx, nil)
+	if err != nil {
+		t.Fatalf("BackupSnapshot failed: %v", err)
+	}
+	defer snap.Close()
+	if got := snap.Tables(); len(got) != 2 || got[0] != "orders" || got[1] != "users" {
+		t.Fatalf("unexpected tables: %v", got)
+	}
+	lsn := snap.LSN()
+	if lsn != 4 {
+		t.Fatalf("expected LSN 4, got %d", lsn)
+	}
+
+	// Writes after the snapshot are neither in the backup nor lost for garbage collection
+	if _, err := src.Insert(ctx, "orders", []domain.Row{{"v": "c"}}, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if _, err := src.Delete(ctx, "users", nil, nil); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	src.mu.Lock()
+	src.gcOldVersions()
+	src.mu.Unlock()
+
+	var buf bytes.Buffer
+	info, err := snap.WriteTable(ctx, "orders", &buf)
+	if err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	if info.Rows != 2 || info.Bytes != int64(buf.Len()) {
+		t.Fatalf("unexpected info: %+v (written %d)", info, buf.Len())
+	}
+	path := filepath.Join(t.TempDir(), "orders.snap")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := newSnapshotTestSource(t)
+	if _, err := dst.ImportTable(ctx, "", path); err != nil {
+		t.Fatalf("ImportTable failed: %v", err)
+	}
+	result, err := dst.Query(ctx, "orders", &domain.QueryOptions{})
+	if err != nil || len(result.Rows) != 2 {
+		t.Fatalf("expected 2 restored rows, got %v (err %v)", result, err)
+	}
+
+	if info, err := snap.WriteTable(ctx, "users", &bytes.Buffer{}); err != nil || info.Rows != 2 {
+		t.Fatalf("expected the deleted users rows in the backup, got %+v (err %v)", info, err)
+	}
+
+	changes, _, err := src.ChangesSince(ctx, lsn)
+	if err != nil {
+		t.Fatalf("ChangesSince failed: %v", err)
+	}
+	if len(changes) != 3 || changes[0].Table != "orders" || changes[0].Type != domain.ChangeInsert {
+		t.Fatalf("unexpected changes after the snapshot: %+v", changes)
+	}
+
+	snap.Close()
+	src.mu.RLock()
+	pinned := len(src.snapshots)
+	src.mu.RUnlock()
+	if pinned != 0 {
+		t.Fatalf("expected Close to unpin the versions, %d snapshots left", pinned)
+	}
+
+	if _, err := src.BackupSnapshot(ctx, []string{"missing"}); err == nil {
+		t.Fatal("expected an error for a missing table")
+	}
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ApplyChanges implements domain.ChangeApplier. The changes are replayed in
+// order in a single new version, the reverse of FlashbackTable: inserts add
+// their after image, updates and deletes find the row equal to their before
+// image. Auto-increment counters move past inserted values and the replayed
+// changes are recorded in this data source's change log.
+func (m *MVCCDataSource) ApplyChanges(ctx context.Context, tableName string, changes []domain.ChangeEvent) (int64, error) {
+	if !m.IsWritable() {
+		return 0, domain.NewErrReadOnly(string(m.config.Type), "apply changes")
+	}
+	if _, hasTxn := GetTransactionID(ctx); hasTxn {
+		return 0, fmt.Errorf("applying changes is not allowed in a transaction")
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	if !m.connected {
+		m.mu.Unlock()
+		return 0, domain.NewErrNotConnected("memory")
+	}
+	tableVer, ok := m.tables[tableName]
+	if !ok {
+		m.mu.Unlock()
+		return 0, domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.RLock()
+	latestData := tableVer.versions[tableVer.latest]
+	tableVer.mu.RUnlock()
+	if latestData == nil {
+		m.mu.Unlock()
+		return 0, domain.NewErrTableNotFound(tableName)
+	}
+	if latestData.schema.IsPartitioned() {
+		m.mu.Unlock()
+		return 0, domain.NewErrUnsupportedOperation(string(m.config.Type), "apply changes to partitioned table")
+	}
+
+	srcRows := latestData.Rows()
+	rows := make([]domain.Row, len(srcRows))
+	copy(rows, srcRows)
+	applied := make([]domain.ChangeEvent, 0, len(changes))
+	for _, change := range changes {
+		switch change.Type {
+		case domain.ChangeInsert:
+			row := deepCopyRow(change.After)
+			rows = append(rows, row)
+			applied = append(applied, domain.ChangeEvent{Table: tableName, Type: domain.ChangeInsert, After: row})
+		case domain.ChangeDelete:
+			idx := findRow(rows, change.Before)
+			if idx < 0 {
+				m.mu.Unlock()
+				return 0, applyConflict(tableName, change)
+			}
+			applied = append(applied, domain.ChangeEvent{Table: tableName, Type: domain.ChangeDelete, Before: rows[idx]})
+			rows = append(rows[:idx], rows[idx+1:]...)
+		case domain.ChangeUpdate:
+			idx := findRow(rows, change.Before)
+			if idx < 0 {
+				m.mu.Unlock()
+				return 0, applyConflict(tableName, change)
+			}
+			row := deepCopyRow(change.After)
+			applied = append(applied, domain.ChangeEvent{Table: tableName, Type: domain.ChangeUpdate, Before: rows[idx], After: row})
+			rows[idx] = row
+		default:
+			m.mu.Unlock()
+			return 0, fmt.Errorf("cannot apply change of type %q to table '%s'", change.Type, tableName)
+		}
+	}
+
+	// Inserted rows carry their auto-increment values, move the counters past them
+	for _, col := range latestData.schema.Columns {
+		if !col.AutoIncrement {
+			continue
+		}
+		key := tableName + "." + col.Name
+		for _, change := range applied {
+			if change.Type == domain.ChangeInsert {
+				if v, ok := change.After[col.Name].(int64); ok && v > m.autoIncCounters[key] {
+					m.autoIncCounters[key] = v
+				}
+			}
+		}
+	}
+
+	m.currentVer++
+	newVer := m.currentVer
+	defer m.changes.flush()
+	tableVer.mu.Lock()
+	m.mu.Unlock()
+	defer tableVer.mu.Unlock()
+
+	versionData := &TableData{
+		version:   newVer,
+		createdAt: time.Now(),
+		schema:    deepCopySchema(latestData.schema),
+		rows:      NewPagedRows(m.bufferPool, rows, 0, tableName, newVer),
+	}
+	tableVer.versions[newVer] = versionData
+	tableVer.latest = newVer
+	tableVer.churn += int64(len(changes))
+
+	m.rebuildTableIndexes(tableName, versionData.schema, rows)
+
+	if m.changes.active() {
+		m.changes.record(applied)
+	}
+	return int64(len(changes)), nil
+}
+
+// applyConflict reports a change whose before image is not in the table
+func applyConflict(tableName string, change domain.ChangeEvent) error {
+	return fmt.Errorf("cannot apply %s at LSN %d to table '%s': the row is not in the table",
+		change.Type, change.LSN, tableName)
+}
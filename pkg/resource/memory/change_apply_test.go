@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// TestApplyChanges_ReplaysChangeLog verifies that replaying the change log of
+// one data source on a copy of its tables reproduces their rows.
+func TestApplyChanges_ReplaysChangeLog(t *testing.T) {
+	ctx := context.Background()
+	src := newChangeTestSource(t)
+	src.SetChangeLogSize(100)
+	src.Insert(ctx, "accounts", []domain.Row{{"id": int64(1), "balance": int64(10)}, {"id": int64(2), "balance": int64(20)}}, nil)
+	src.Update(ctx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"balance": int64(15)}, nil)
+	src.Delete(ctx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, nil)
+	src.Insert(ctx, "accounts", []domain.Row{{"id": int64(3), "balance": nil}}, nil)
+
+	changes, _, err := src.ChangesSince(ctx, 0)
+	if err != nil {
+		t.Fatalf("ChangesSince failed: %v", err)
+	}
+
+	dst := newChangeTestSource(t)
+	dst.SetChangeLogSize(100)
+	n, err := dst.ApplyChanges(ctx, "accounts", changes)
+	if err != nil || n != 5 {
+		t.Fatalf("ApplyChanges: expected 5 changes, got %d (err %v)", n, err)
+	}
+	result, err := dst.Query(ctx, "accounts", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", result.Rows)
+	}
+	byID := map[int64]domain.Row{}
+	for _, row := range result.Rows {
+		byID[row["id"].(int64)] = row
+	}
+	if byID[1]["balance"] != int64(15) || byID[3]["balance"] != nil {
+		t.Fatalf("unexpected rows: %+v", result.Rows)
+	}
+	// The replay is recorded in the change log of the target
+	if replayed, _, _ := dst.ChangesSince(ctx, 0); len(replayed) != 5 {
+		t.Fatalf("expected 5 recorded changes, got %d", len(replayed))
+	}
+
+	// A change whose row is missing leaves the table unchanged
+	_, err = dst.ApplyChanges(ctx, "accounts", []domain.ChangeEvent{
+		{Type: domain.ChangeDelete, Before: domain.Row{"id": int64(1), "balance": int64(15)}},
+		{Type: domain.ChangeDelete, Before: domain.Row{"id": int64(9), "balance": int64(0)}, LSN: 42},
+	})
+	if err == nil || !strings.Contains(err.Error(), "LSN 42") {
+		t.Fatalf("expected a conflict at LSN 42, got %v", err)
+	}
+	if result, _ := dst.Query(ctx, "accounts", &domain.QueryOptions{}); len(result.Rows) != 2 {
+		t.Fatalf("expected the table to be unchanged, got %+v", result.Rows)
+	}
+}
+
+// TestChangeLogID verifies that the change log identity is empty while the log
+// is disabled and changes whenever it is enabled again.
+func TestChangeLogID(t *testing.T) {
+	ds := newChangeTestSource(t)
+	if id := ds.ChangeLogID(); id != "" {
+		t.Fatalf("expected no id while disabled, got %q", id)
+	}
+	ds.SetChangeLogSize(10)
+	first := ds.ChangeLogID()
+	if first == "" {
+		t.Fatal("expected an id while enabled")
+	}
+	unsubscribe := ds.SubscribeChanges("", func(domain.ChangeEvent) {})
+	if id := ds.ChangeLogID(); id != first {
+		t.Fatalf("expected the id to stay %q while enabled, got %q", first, id)
+	}
+	unsubscribe()
+	ds.SetChangeLogSize(0)
+	if id := ds.ChangeLogID(); id != "" {
+		t.Fatalf("expected no id while disabled, got %q", id)
+	}
+	unsubscribe = ds.SubscribeChanges("", func(domain.ChangeEvent) {})
+	defer unsubscribe()
+	if id := ds.ChangeLogID(); id == "" || id == first {
+		t.Fatalf("expected a new id after enabling again, got %q", id)
+	}
+}
@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== Change Log ====================
+//
+// Committed row changes are recorded while the table lock of the write is
+// held, so LSNs follow commit order. Subscribers are called after the write
+// has released its locks and before it returns. Changes are only built while
+// someone subscribes or a retention size is set, so writes pay nothing otherwise.
+
+// changeLog numbers committed row changes, keeps the most recent ones and
+// delivers them to subscribers
+type changeLog struct {
+	mu      sync.Mutex
+	lastLSN int64
+	// id identifies the log of this data source and epoch counts how often it
+	// was enabled: while it is disabled writes get no LSN, so LSNs are only
+	// comparable within one id and epoch (see ChangeLogID)
+	id    string
+	epoch int64
+	// retained holds the most recent changes, oldest first, up to size
+	retained []domain.ChangeEvent
+	size     int
+	// coveredFrom is the time after which every change is retained: when
+	// retention was enabled or when the last discarded change was committed
+	coveredFrom time.Time
+	// pending holds recorded changes not yet delivered to subscribers
+	pending []domain.ChangeEvent
+	subs    map[int64]*changeSubscriber
+	nextSub int64
+
+	// deliverMu serializes delivery so subscribers see changes in LSN order
+	deliverMu sync.Mutex
+}
+
+type changeSubscriber struct {
+	table string
+	fn    func(domain.ChangeEvent)
+}
+
+func newChangeLog() *changeLog {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &changeLog{subs: make(map[int64]*changeSubscriber), id: hex.EncodeToString(id)}
+}
+
+// active reports whether changes have to be recorded
+func (c *changeLog) active() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.activeLocked()
+}
+
+func (c *changeLog) activeLocked() bool {
+	return c.size > 0 || len(c.subs) > 0
+}
+
+// idLocked returns the identity of the log, or "" while it is disabled.
+// The caller holds c.mu.
+func (c *changeLog) idLocked() string {
+	if !c.activeLocked() {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d", c.id, c.epoch)
+}
+
+// record assigns LSNs and server-wide event IDs to changes, retains them and
+// queues them for delivery.
+// Callers hold the lock of the changed table.
+func (c *changeLog) record(changes []domain.ChangeEvent) {
+	if len(changes) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for i := range changes {
+		c.lastLSN++
+		changes[i].LSN = c.lastLSN
+		changes[i].ID = idgen.Next()
+		changes[i].Time = now
+		changes[i].Table = changeTableName(changes[i].Table)
+	}
+	if c.size > 0 {
+		c.retained = append(c.retained, changes...)
+		c.trimLocked()
+	}
+	if len(c.subs) > 0 {
+		c.pending = append(c.pending, changes...)
+	}
+}
+
+// flush delivers the pending changes. Writers call it after releasing their
+// locks; a writer whose changes are being delivered by another writer waits
+// until that delivery is done.
+func (c *changeLog) flush() {
+	c.deliverMu.Lock()
+	defer c.deliverMu.Unlock()
+
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	subs := make([]*changeSubscriber, 0, len(c.subs))
+	for _, sub := range c.subs {
+		subs = append(subs, sub)
+	}
+	c.mu.Unlock()
+
+	for _, change := range pending {
+		for _, sub := range subs {
+			if sub.table == "" || sub.table == change.Table {
+				sub.fn(change)
+			}
+		}
+	}
+}
+
+// changeTableName maps internal partition tables to their partitioned table
+func changeTableName(tableName string) string {
+	if i := strings.Index(tableName, partitionSeparator); i >= 0 {
+		return tableName[:i]
+	}
+	return tableName
+}
+
+// insertChanges returns INSERT changes for rows
+func insertChanges(tableName string, txnID int64, rows []domain.Row) []domain.ChangeEvent {
+	changes := make([]domain.ChangeEvent, len(rows))
+	for i, row := range rows {
+		changes[i] = domain.ChangeEvent{Table: tableName, Type: domain.ChangeInsert, TxnID: txnID, After: row}
+	}
+	return changes
+}
+
+// SubscribeChanges calls fn for every committed row change of table, or of
+// all tables when table is empty (see domain.ChangeLogSource)
+func (m *MVCCDataSource) SubscribeChanges(table string, fn func(domain.ChangeEvent)) func() {
+	c := m.changes
+	c.mu.Lock()
+	if !c.activeLocked() {
+		c.epoch++
+	}
+	c.nextSub++
+	id := c.nextSub
+	c.subs[id] = &changeSubscriber{table: table, fn: fn}
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.subs, id)
+			c.mu.Unlock()
+		})
+	}
+}
+
+// SetChangeLogSize sets how many recent changes are retained for
+// ChangesSince; 0 (the default) retains none
+func (m *MVCCDataSource) SetChangeLogSize(size int) {
+	c := m.changes
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size == 0 && size > 0 {
+		c.coveredFrom = time.Now()
+		if len(c.subs) == 0 {
+			c.epoch++
+		}
+	}
+	c.size = max(size, 0)
+	c.trimLocked()
+}
+
+// trimLocked discards the oldest changes beyond size. The caller holds c.mu.
+func (c *changeLog) trimLocked() {
+	if over := len(c.retained) - c.size; over > 0 {
+		c.coveredFrom = c.retained[over-1].Time
+		c.retained = append(c.retained[:0:0], c.retained[over:]...)
+	}
+}
+
+// tableChangesAfter returns the retained changes of table committed after t,
+// oldest first. It fails when changes after t may be missing from the log.
+func (c *changeLog) tableChangesAfter(table string, t time.Time) ([]domain.ChangeEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size == 0 {
+		return nil, fmt.Errorf("the change log is disabled")
+	}
+	if t.Before(c.coveredFrom) {
+		return nil, fmt.Errorf("the change log only covers changes after %s", c.coveredFrom.Format("2006-01-02 15:04:05"))
+	}
+	var changes []domain.ChangeEvent
+	for _, change := range c.retained {
+		if change.Table == table && change.Time.After(t) {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// ChangeLogID returns the identity of the change log, or "" while it is
+// disabled (see domain.ChangeLogSource)
+func (m *MVCCDataSource) ChangeLogID() string {
+	c := m.changes
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.idLocked()
+}
+
+// ChangesSince returns the retained changes after LSN since and the latest LSN
+func (m *MVCCDataSource) ChangesSince(ctx context.Context, since int64) ([]domain.ChangeEvent, int64, error) {
+	c := m.changes
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if since >= c.lastLSN {
+		return nil, c.lastLSN, nil
+	}
+	oldest := c.lastLSN - int64(len(c.retained)) + 1
+	if since+1 < oldest {
+		return nil, c.lastLSN, fmt.Errorf("change log no longer contains changes after LSN %d (oldest retained: %d)", since, oldest)
+	}
+	changes := make([]domain.ChangeEvent, c.lastLSN-since)
+	copy(changes, c.retained[since+1-oldest:])
+	return changes, c.lastLSN, nil
+}
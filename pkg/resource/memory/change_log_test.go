@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newChangeTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := newSnapshotTestSource(t)
+	for _, name := range []string{"accounts", "audit"} {
+		err := ds.CreateTable(context.Background(), &domain.TableInfo{
+			Name: name,
+			Columns: []domain.ColumnInfo{
+				{Name: "id", Type: "INTEGER", Primary: true},
+				{Name: "balance", Type: "INTEGER", Nullable: true},
+			},
+		})
+		if err != nil {
+			t.Fatalf("CreateTable failed: %v", err)
+		}
+	}
+	return ds
+}
+
+// TestChangeLog_Subscribe verifies that subscribers receive insert, update and
+// delete events with before and after images, in LSN order, for their table only.
+func TestChangeLog_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	ds := newChangeTestSource(t)
+
+	var events []domain.ChangeEvent
+	unsubscribe := ds.SubscribeChanges("accounts", func(e domain.ChangeEvent) {
+		events = append(events, e)
+	})
+
+	ds.Insert(ctx, "accounts", []domain.Row{{"id": int64(1), "balance": int64(10)}, {"id": int64(2), "balance": int64(20)}}, nil)
+	ds.Insert(ctx, "audit", []domain.Row{{"id": int64(1)}}, nil)
+	ds.Update(ctx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"balance": int64(15)}, nil)
+	ds.Delete(ctx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, nil)
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d: %+v", len(events), events)
+	}
+	wantTypes := []string{domain.ChangeInsert, domain.ChangeInsert, domain.ChangeUpdate, domain.ChangeDelete}
+	for i, e := range events {
+		if e.Type != wantTypes[i] || e.Table != "accounts" {
+			t.Errorf("event %d: expected %s on accounts, got %s on %s", i, wantTypes[i], e.Type, e.Table)
+		}
+		if i > 0 && e.LSN <= events[i-1].LSN {
+			t.Errorf("event %d: LSN %d not after %d", i, e.LSN, events[i-1].LSN)
+		}
+		if i > 0 && e.ID <= events[i-1].ID {
+			t.Errorf("event %d: ID %d not after %d", i, e.ID, events[i-1].ID)
+		}
+	}
+	if events[0].Before != nil || events[0].After["balance"] != int64(10) {
+		t.Errorf("unexpected insert images: %+v", events[0])
+	}
+	if events[2].Before["balance"] != int64(10) || events[2].After["balance"] != int64(15) {
+		t.Errorf("unexpected update images: %+v", events[2])
+	}
+	if events[3].Before["id"] != int64(2) || events[3].After != nil {
+		t.Errorf("unexpected delete images: %+v", events[3])
+	}
+	// The audit insert took an LSN between the accounts changes
+	if events[2].LSN != events[1].LSN+2 {
+		t.Errorf("expected the audit insert to take LSN %d", events[1].LSN+1)
+	}
+
+	unsubscribe()
+	ds.Insert(ctx, "accounts", []domain.Row{{"id": int64(3)}}, nil)
+	if len(events) != 4 {
+		t.Errorf("expected no events after unsubscribe, got %d", len(events))
+	}
+}
+
+// TestChangeLog_Transaction verifies that transactional changes are delivered
+// on commit with the transaction ID and not at all on rollback.
+func TestChangeLog_Transaction(t *testing.T) {
+	ctx := context.Background()
+	ds := newChangeTestSource(t)
+	ds.Insert(ctx, "accounts", []domain.Row{{"id": int64(1), "balance": int64(10)}, {"id": int64(2), "balance": int64(20)}}, nil)
+
+	var events []domain.ChangeEvent
+	defer ds.SubscribeChanges("", func(e domain.ChangeEvent) {
+		events = append(events, e)
+	})()
+
+	txnID, err := ds.BeginTx(ctx, false)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	txnCtx := SetTransactionID(ctx, txnID)
+	ds.Update(txnCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"balance": int64(5)}, nil)
+	ds.Delete(txnCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, nil)
+	ds.Insert(txnCtx, "audit", []domain.Row{{"id": int64(7)}}, nil)
+	if len(events) != 0 {
+		t.Fatalf("expected no events before commit, got %+v", events)
+	}
+	if err := ds.CommitTx(ctx, txnID); err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+
+	got := make(map[string]domain.ChangeEvent)
+	for _, e := range events {
+		if e.TxnID != txnID {
+			t.Errorf("expected transaction %d, got %d", txnID, e.TxnID)
+		}
+		got[e.Table+" "+e.Type] = e
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	if e := got["accounts UPDATE"]; e.Before["balance"] != int64(10) || e.After["balance"] != int64(5) {
+		t.Errorf("unexpected update images: %+v", e)
+	}
+	if e := got["accounts DELETE"]; e.Before["id"] != int64(2) {
+		t.Errorf("unexpected delete images: %+v", e)
+	}
+	if e := got["audit INSERT"]; e.After["id"] != int64(7) {
+		t.Errorf("unexpected insert images: %+v", e)
+	}
+
+	txnID, _ = ds.BeginTx(ctx, false)
+	ds.Insert(SetTransactionID(ctx, txnID), "audit", []domain.Row{{"id": int64(8)}}, nil)
+	ds.RollbackTx(ctx, txnID)
+	if len(events) != 3 {
+		t.Errorf("expected no events for a rolled back transaction, got %d", len(events))
+	}
+}
+
+// TestChangeLog_ChangesSince verifies retention of recent changes.
+func TestChangeLog_ChangesSince(t *testing.T) {
+	ctx := context.Background()
+	ds := newChangeTestSource(t)
+
+	// Nothing is retained by default
+	ds.Insert(ctx, "accounts", []domain.Row{{"id": int64(1)}}, nil)
+	changes, lsn, err := ds.ChangesSince(ctx, 0)
+	if err != nil || len(changes) != 0 || lsn != 0 {
+		t.Fatalf("expected an empty log, got %d changes, LSN %d, err %v", len(changes), lsn, err)
+	}
+
+	ds.SetChangeLogSize(3)
+	for i := int64(2); i <= 5; i++ {
+		ds.Insert(ctx, "accounts", []domain.Row{{"id": i}}, nil)
+	}
+	changes, lsn, err = ds.ChangesSince(ctx, 2)
+	if err != nil {
+		t.Fatalf("ChangesSince failed: %v", err)
+	}
+	if lsn != 4 || len(changes) != 2 || changes[0].LSN != 3 || changes[1].After["id"] != int64(5) {
+		t.Fatalf("unexpected changes after LSN 2: %+v (latest %d)", changes, lsn)
+	}
+	if changes, _, _ = ds.ChangesSince(ctx, 4); len(changes) != 0 {
+		t.Errorf("expected no changes after the latest LSN, got %+v", changes)
+	}
+
+	// LSN 1 was dropped from the log
+	if _, _, err := ds.ChangesSince(ctx, 0); err == nil || !strings.Contains(err.Error(), "no longer contains") {
+		t.Errorf("expected a truncated log error, got %v", err)
+	}
+}
@@ -0,0 +1,359 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/logging"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== Online Table Compaction ====================
+//
+// Compaction rewrites the latest version of a table into freshly allocated,
+// tightly packed pages, rebuilds its indexes from scratch (dropping stale
+// entries) and garbage collects superseded versions.
+//
+// The copy runs page by page against an immutable version without holding
+// any lock, so readers and writers are never blocked by it. The compacted
+// version is installed only if the table has not been modified meanwhile;
+// otherwise the copy is retried against the new latest version.
+
+// maxCompactionAttempts bounds the retries of a compaction that keeps losing
+// the race against concurrent writers
+const maxCompactionAttempts = 3
+
+// memLog is the structured logger of the memory datasource
+var memLog = logging.Module("resource.memory")
+
+// errCompactionConflict is returned when the table changed during the copy phase
+var errCompactionConflict = fmt.Errorf("table was modified during compaction")
+
+// compactor tracks compaction progress and runs the background compaction job
+type compactor struct {
+	mu       sync.Mutex
+	progress map[string]*domain.CompactionProgress
+	running  map[string]bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newCompactor() *compactor {
+	return &compactor{
+		progress: make(map[string]*domain.CompactionProgress),
+		running:  make(map[string]bool),
+	}
+}
+
+// CompactTable rebuilds the row storage and indexes of a table online.
+// Partitioned tables are compacted partition by partition; the returned
+// progress aggregates all partitions.
+func (m *MVCCDataSource) CompactTable(ctx context.Context, tableName string) (*domain.CompactionProgress, error) {
+	if !m.IsWritable() {
+		return nil, domain.NewErrReadOnly(string(m.config.Type), "compact table")
+	}
+
+	schema := m.latestSchema(tableName)
+	if schema == nil {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+	if !schema.IsPartitioned() {
+		return m.compactTable(ctx, tableName)
+	}
+
+	total := &domain.CompactionProgress{
+		Table:     tableName,
+		State:     domain.CompactionStateCompleted,
+		StartedAt: time.Now(),
+	}
+	for _, child := range partitionTables(schema) {
+		p, err := m.compactTable(ctx, child)
+		if p != nil {
+			total.RowsTotal += p.RowsTotal
+			total.RowsProcessed += p.RowsProcessed
+			total.PagesBefore += p.PagesBefore
+			total.PagesAfter += p.PagesAfter
+			total.Attempts += p.Attempts
+		}
+		if err != nil {
+			total.State = domain.CompactionStateFailed
+			total.Error = err.Error()
+			total.FinishedAt = time.Now()
+			m.compactor.record(total)
+			return total, err
+		}
+	}
+	total.FinishedAt = time.Now()
+	m.compactor.record(total)
+	return total, nil
+}
+
+// CompactionStatus returns the progress of the running or last finished
+// compaction of a table
+func (m *MVCCDataSource) CompactionStatus(tableName string) (*domain.CompactionProgress, bool) {
+	return m.compactor.status(tableName)
+}
+
+// compactTable compacts a single (non-partitioned) table
+func (m *MVCCDataSource) compactTable(ctx context.Context, tableName string) (*domain.CompactionProgress, error) {
+	c := m.compactor
+	if !c.begin(tableName) {
+		return nil, fmt.Errorf("table %s is already being compacted", tableName)
+	}
+	defer c.end(tableName)
+
+	progress := &domain.CompactionProgress{
+		Table:     tableName,
+		State:     domain.CompactionStateRunning,
+		StartedAt: time.Now(),
+	}
+	c.record(progress)
+
+	var err error
+	for progress.Attempts < maxCompactionAttempts {
+		progress.Attempts++
+		if err = m.compactOnce(ctx, tableName); err != errCompactionConflict {
+			break
+		}
+	}
+
+	c.update(tableName, func(p *domain.CompactionProgress) {
+		p.Attempts = progress.Attempts
+		p.FinishedAt = time.Now()
+		if err != nil {
+			p.State = domain.CompactionStateFailed
+			p.Error = err.Error()
+		} else {
+			p.State = domain.CompactionStateCompleted
+		}
+	})
+	result, _ := c.status(tableName)
+	return result, err
+}
+
+// compactOnce copies the latest version of a table into new pages and
+// installs it if the table is unchanged. Returns errCompactionConflict if a
+// concurrent write won the race.
+func (m *MVCCDataSource) compactOnce(ctx context.Context, tableName string) error {
+	m.mu.RLock()
+	tableVer, ok := m.tables[tableName]
+	m.mu.RUnlock()
+	if !ok {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.RLock()
+	baseVer := tableVer.latest
+	base := tableVer.versions[baseVer]
+	tableVer.mu.RUnlock()
+	if base == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	rowsTotal := int64(base.rows.Len())
+	pagesBefore := base.rows.PageCount()
+	m.compactor.update(tableName, func(p *domain.CompactionProgress) {
+		p.RowsTotal = rowsTotal
+		p.RowsProcessed = 0
+		p.PagesBefore = pagesBefore
+	})
+
+	// Reserve the version number of the compacted copy up front so its pages
+	// can be registered with the buffer pool while they are being built
+	m.mu.Lock()
+	m.currentVer++
+	newVer := m.currentVer
+	m.mu.Unlock()
+
+	// Copy phase: base is immutable, so no lock is needed
+	compacted := NewPagedRowsBuilder(m.bufferPool, 0, tableName, newVer)
+	pageSize := compacted.pageSize
+	page := make([]domain.Row, 0, pageSize)
+	var processed int64
+	var copyErr error
+	base.rows.Range(func(_ int, row domain.Row) bool {
+		page = append(page, deepCopyRow(row))
+		processed++
+		if len(page) < pageSize {
+			return true
+		}
+		compacted.AppendPage(page)
+		page = make([]domain.Row, 0, pageSize)
+		m.compactor.update(tableName, func(p *domain.CompactionProgress) { p.RowsProcessed = processed })
+		domain.ReportProgress(ctx, domain.Progress{Stage: 1, MaxStage: 2, Done: processed, Total: rowsTotal, Info: "Copying rows of " + tableName})
+		if copyErr = ctx.Err(); copyErr != nil {
+			return false
+		}
+		// Let readers and writers run between pages
+		runtime.Gosched()
+		return true
+	})
+	if copyErr != nil {
+		compacted.Release()
+		return copyErr
+	}
+	if len(page) > 0 || compacted.PageCount() == 0 {
+		compacted.AppendPage(page)
+	}
+	m.compactor.update(tableName, func(p *domain.CompactionProgress) { p.RowsProcessed = processed })
+	domain.ReportProgress(ctx, domain.Progress{Stage: 1, MaxStage: 2, Done: processed, Total: rowsTotal, Info: "Copying rows of " + tableName})
+
+	// Install phase
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tableVer.mu.Lock()
+	if m.tables[tableName] != tableVer || tableVer.latest != baseVer {
+		tableVer.mu.Unlock()
+		compacted.Release()
+		return errCompactionConflict
+	}
+	data := &TableData{
+		version:   newVer,
+		createdAt: time.Now(),
+		schema:    deepCopySchema(base.schema),
+		rows:      compacted,
+	}
+	tableVer.versions[newVer] = data
+	tableVer.latest = newVer
+	tableVer.churn = 0
+	tableVer.mu.Unlock()
+
+	// Rebuilding drops index entries that point at deleted or stale rows
+	rows := compacted.Materialize()
+	_ = m.indexManager.RebuildIndexWithProgress(tableName, data.schema, rows, func(done int64) {
+		domain.ReportProgress(ctx, domain.Progress{Stage: 2, MaxStage: 2, Done: done, Total: int64(len(rows)), Info: "Rebuilding indexes of " + tableName})
+	})
+	m.gcOldVersions()
+
+	m.compactor.update(tableName, func(p *domain.CompactionProgress) { p.PagesAfter = compacted.PageCount() })
+	return nil
+}
+
+// StartCompactor compacts, every interval, each table with at least minChurn
+// rows updated or deleted since its last compaction. Calling it again
+// restarts the compactor; Close stops it.
+func (m *MVCCDataSource) StartCompactor(interval time.Duration, minChurn int64) {
+	if interval <= 0 {
+		return
+	}
+	if minChurn <= 0 {
+		minChurn = 1
+	}
+	m.StopCompactor()
+
+	c := m.compactor
+	c.mu.Lock()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	c.stop, c.done = stop, done
+	c.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.compactChurnedTables(stop, minChurn)
+			}
+		}
+	}()
+}
+
+// StopCompactor stops the background compactor, if running
+func (m *MVCCDataSource) StopCompactor() {
+	c := m.compactor
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// compactChurnedTables compacts the tables whose churn reached minChurn
+func (m *MVCCDataSource) compactChurnedTables(stop <-chan struct{}, minChurn int64) {
+	m.mu.RLock()
+	var candidates []string
+	for name, tableVer := range m.tables {
+		tableVer.mu.RLock()
+		if tableVer.churn >= minChurn {
+			candidates = append(candidates, name)
+		}
+		tableVer.mu.RUnlock()
+	}
+	m.mu.RUnlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, name := range candidates {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := m.compactTable(ctx, name); err != nil && err != context.Canceled {
+			memLog.Warn("background compaction failed", "table", name, "err", err)
+		}
+	}
+}
+
+// begin marks a table as being compacted; returns false if it already is
+func (c *compactor) begin(tableName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running[tableName] {
+		return false
+	}
+	c.running[tableName] = true
+	return true
+}
+
+func (c *compactor) end(tableName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.running, tableName)
+}
+
+func (c *compactor) record(p *domain.CompactionProgress) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clone := *p
+	c.progress[p.Table] = &clone
+}
+
+func (c *compactor) update(tableName string, fn func(p *domain.CompactionProgress)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.progress[tableName]; ok {
+		fn(p)
+	}
+}
+
+func (c *compactor) status(tableName string) (*domain.CompactionProgress, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.progress[tableName]
+	if !ok {
+		return nil, false
+	}
+	clone := *p
+	return &clone, true
+}
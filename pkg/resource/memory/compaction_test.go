@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newCompactionTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := NewMVCCDataSource(nil, &PagingConfig{
+		Enabled:     true,
+		MaxMemoryMB: 100,
+		PageSize:    100,
+		SpillDir:    t.TempDir(),
+	})
+	ctx := context.Background()
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { ds.Close(ctx) })
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "churn",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+			{Name: "v", Type: "VARCHAR"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+
+	rows := make([]domain.Row, 250)
+	for i := range rows {
+		rows[i] = domain.Row{"id": int64(i), "v": "x"}
+	}
+	if _, err := ds.Insert(ctx, "churn", rows, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return ds
+}
+
+// TestCompactTable verifies that compaction keeps the rows, repacks the pages,
+// drops superseded versions and keeps indexes usable
+func TestCompactTable(t *testing.T) {
+	ds := newCompactionTestSource(t)
+	ctx := context.Background()
+
+	if err := ds.CreateIndex("churn", "id", "btree", true); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if _, err := ds.Delete(ctx, "churn", []domain.Filter{{Field: "id", Operator: ">=", Value: int64(120)}}, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := ds.Update(ctx, "churn", []domain.Filter{{Field: "id", Operator: "<", Value: int64(10)}}, domain.Row{"v": "y"}, nil); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	tableVer := ds.tables["churn"]
+	if tableVer.churn != 140 {
+		t.Errorf("churn = %d, want 140", tableVer.churn)
+	}
+
+	progress, err := ds.CompactTable(ctx, "churn")
+	if err != nil {
+		t.Fatalf("CompactTable() error = %v", err)
+	}
+	if progress.State != domain.CompactionStateCompleted || progress.RowsTotal != 120 || progress.RowsProcessed != 120 {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+	if progress.PagesAfter != 2 || progress.Percent() != 100 {
+		t.Errorf("PagesAfter = %d, Percent() = %v", progress.PagesAfter, progress.Percent())
+	}
+	if len(tableVer.versions) != 1 || tableVer.churn != 0 {
+		t.Errorf("versions = %d, churn = %d after compaction", len(tableVer.versions), tableVer.churn)
+	}
+
+	result, err := ds.Query(ctx, "churn", &domain.QueryOptions{
+		Filters: []domain.Filter{{Field: "id", Operator: "=", Value: int64(5)}},
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0]["v"] != "y" {
+		t.Errorf("Query() after compaction = %v", result.Rows)
+	}
+	result, err = ds.Query(ctx, "churn", &domain.QueryOptions{
+		Filters: []domain.Filter{{Field: "id", Operator: "=", Value: int64(200)}},
+	})
+	if err != nil || len(result.Rows) != 0 {
+		t.Errorf("deleted row still visible: %v, %v", result.Rows, err)
+	}
+
+	status, ok := ds.CompactionStatus("churn")
+	if !ok || status.State != domain.CompactionStateCompleted {
+		t.Errorf("CompactionStatus() = %+v, %v", status, ok)
+	}
+	if _, err := ds.CompactTable(ctx, "missing"); err == nil {
+		t.Error("expected error for missing table")
+	}
+}
+
+// TestCompactTable_Canceled verifies that a canceled compaction leaves the table untouched
+func TestCompactTable_Canceled(t *testing.T) {
+	ds := newCompactionTestSource(t)
+	tableVer := ds.tables["churn"]
+	latest := tableVer.latest
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ds.CompactTable(ctx, "churn"); err == nil {
+		t.Fatal("expected error for canceled context")
+	}
+	if tableVer.latest != latest {
+		t.Errorf("latest version changed from %d to %d", latest, tableVer.latest)
+	}
+	status, ok := ds.CompactionStatus("churn")
+	if !ok || status.State != domain.CompactionStateFailed || status.Error == "" {
+		t.Errorf("CompactionStatus() = %+v, %v", status, ok)
+	}
+}
+
+// TestBackgroundCompactor verifies that only tables with enough churn are compacted
+func TestBackgroundCompactor(t *testing.T) {
+	ds := newCompactionTestSource(t)
+	ctx := context.Background()
+
+	if _, err := ds.Delete(ctx, "churn", []domain.Filter{{Field: "id", Operator: "<", Value: int64(5)}}, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	ds.StartCompactor(5*time.Millisecond, 10)
+	defer ds.StopCompactor()
+
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := ds.CompactionStatus("churn"); ok {
+		t.Fatal("table below the churn threshold was compacted")
+	}
+
+	if _, err := ds.Delete(ctx, "churn", []domain.Filter{{Field: "id", Operator: "<", Value: int64(20)}}, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if status, ok := ds.CompactionStatus("churn"); ok && status.State == domain.CompactionStateCompleted {
+			if status.RowsTotal != 230 {
+				t.Errorf("RowsTotal = %d, want 230", status.RowsTotal)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background compactor did not compact the table")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestProgressReporting verifies that index backfill and compaction report
+// progress through the callback attached to the context
+func TestProgressReporting(t *testing.T) {
+	ds := newCompactionTestSource(t)
+
+	var reports []domain.Progress
+	ctx := domain.WithProgress(context.Background(), func(p domain.Progress) {
+		reports = append(reports, p)
+	})
+
+	if err := ds.CreateIndexWithColumnsContext(ctx, "churn", []string{"v"}, "btree", false); err != nil {
+		t.Fatalf("CreateIndexWithColumnsContext() error = %v", err)
+	}
+	if len(reports) == 0 {
+		t.Fatal("expected progress while building the index")
+	}
+	last := reports[len(reports)-1]
+	if last.Stage != 1 || last.MaxStage != 1 || last.Done != 250 || last.Total != 250 || last.Percent() != 100 {
+		t.Errorf("unexpected final index progress %+v", last)
+	}
+
+	reports = nil
+	if _, err := ds.CompactTable(ctx, "churn"); err != nil {
+		t.Fatalf("CompactTable() error = %v", err)
+	}
+	var copied, rebuilt bool
+	for _, p := range reports {
+		if p.MaxStage != 2 {
+			t.Errorf("unexpected MaxStage in %+v", p)
+		}
+		copied = copied || (p.Stage == 1 && p.Done == 250)
+		rebuilt = rebuilt || (p.Stage == 2 && p.Done == 250)
+	}
+	if !copied || !rebuilt {
+		t.Errorf("missing copy or rebuild progress in %+v", reports)
+	}
+}
@@ -18,6 +18,9 @@ func (m *MVCCDataSource) Connect(ctx context.Context) error {
 
 // Close closes the connection
 func (m *MVCCDataSource) Close(ctx context.Context) error {
+	// The compactor takes m.mu, so stop it before locking
+	m.StopCompactor()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -0,0 +1,276 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+// blindIndexColumnPrefix prefixes the hidden columns that store the blind indexes of encrypted columns
+const blindIndexColumnPrefix = "__bidx_"
+
+// blindIndexColumn returns the hidden column that stores the blind index of an encrypted column
+func blindIndexColumn(column string) string {
+	return blindIndexColumnPrefix + column
+}
+
+// SetKeyProvider sets the keys of ENCRYPTED columns (implements domain.ColumnEncryptionManager)
+func (m *MVCCDataSource) SetKeyProvider(keys domain.KeyProvider) {
+	if keys == nil {
+		m.cipher.Store(nil)
+		return
+	}
+	m.cipher.Store(security.NewColumnCipher(keys))
+}
+
+// columnCipher returns the cipher of encrypted columns, or an error when no keys are configured
+func (m *MVCCDataSource) columnCipher() (*security.ColumnCipher, error) {
+	c := m.cipher.Load()
+	if c == nil {
+		return nil, fmt.Errorf("no encryption keys configured for encrypted columns")
+	}
+	return c, nil
+}
+
+// prepareEncryptedColumns validates the ENCRYPTED columns of a new table, binds their
+// ciphertexts to "table.column" and returns the columns with the hidden blind index columns appended
+func (m *MVCCDataSource) prepareEncryptedColumns(tableName string, cols []domain.ColumnInfo) ([]domain.ColumnInfo, error) {
+	if !(&domain.TableInfo{Columns: cols}).HasEncryptedColumns() {
+		return cols, nil
+	}
+	if _, err := m.columnCipher(); err != nil {
+		return nil, err
+	}
+	var indexCols []domain.ColumnInfo
+	for i, col := range cols {
+		if col.Encryption == nil {
+			continue
+		}
+		if err := col.Encryption.Validate(); err != nil {
+			return nil, err
+		}
+		encryption := *col.Encryption
+		encryption.Context = tableName + "." + col.Name
+		cols[i].Encryption = &encryption
+		if col.Primary || col.AutoIncrement || col.IsGenerated {
+			return nil, fmt.Errorf("column '%s' cannot be encrypted: primary key, auto-increment and generated columns are not supported", col.Name)
+		}
+		if col.Unique && col.Encryption.Mode != domain.EncryptionDeterministic {
+			return nil, fmt.Errorf("unique column '%s' requires DETERMINISTIC encryption", col.Name)
+		}
+		if col.Encryption.Mode == domain.EncryptionBlindIndex {
+			indexCols = append(indexCols, domain.ColumnInfo{
+				Name:     blindIndexColumn(col.Name),
+				Type:     "VARCHAR(32)",
+				Nullable: true,
+				Hidden:   true,
+			})
+		}
+	}
+	return append(cols, indexCols...), nil
+}
+
+// encryptRows encrypts the values of encrypted columns in place and fills in their blind indexes
+func (m *MVCCDataSource) encryptRows(ctx context.Context, schema *domain.TableInfo, rows []domain.Row) error {
+	if !schema.HasEncryptedColumns() {
+		return nil
+	}
+	for _, row := range rows {
+		if err := m.encryptRow(ctx, schema, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encryptRow encrypts the encrypted columns present in row (a full row or the SET values of an UPDATE)
+func (m *MVCCDataSource) encryptRow(ctx context.Context, schema *domain.TableInfo, row domain.Row) error {
+	c, err := m.columnCipher()
+	if err != nil {
+		return err
+	}
+	for i := range schema.Columns {
+		col := &schema.Columns[i]
+		if col.Encryption == nil {
+			continue
+		}
+		value, ok := row[col.Name]
+		if !ok {
+			continue
+		}
+		if col.Encryption.Mode == domain.EncryptionBlindIndex {
+			index, err := c.BlindIndex(ctx, col, value)
+			if err != nil {
+				return err
+			}
+			row[blindIndexColumn(col.Name)] = index
+		}
+		encrypted, err := c.Encrypt(ctx, col, value)
+		if err != nil {
+			return err
+		}
+		row[col.Name] = encrypted
+	}
+	return nil
+}
+
+// encryptUpdates returns the SET values of an UPDATE with encrypted columns encrypted
+func (m *MVCCDataSource) encryptUpdates(ctx context.Context, tableName string, updates domain.Row) (domain.Row, error) {
+	schema := m.latestSchema(tableName)
+	if schema == nil || !schema.HasEncryptedColumns() {
+		return updates, nil
+	}
+	encrypted := make(domain.Row, len(updates))
+	for k, v := range updates {
+		encrypted[k] = v
+	}
+	if err := m.encryptRow(ctx, schema, encrypted); err != nil {
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+// encryptFilters rewrites the conditions on encrypted columns to match the stored values:
+// DETERMINISTIC columns compare ciphertexts, BLIND INDEX columns compare their hidden
+// blind index column. Only equality comparisons and IS NULL are possible on encrypted columns.
+func (m *MVCCDataSource) encryptFilters(ctx context.Context, tableName string, filters []domain.Filter) ([]domain.Filter, error) {
+	if len(filters) == 0 {
+		return filters, nil
+	}
+	schema := m.latestSchema(tableName)
+	if schema == nil || !schema.HasEncryptedColumns() {
+		return filters, nil
+	}
+	c, err := m.columnCipher()
+	if err != nil {
+		return nil, err
+	}
+	return encryptFilterList(ctx, c, schema, filters)
+}
+
+func encryptFilterList(ctx context.Context, c *security.ColumnCipher, schema *domain.TableInfo, filters []domain.Filter) ([]domain.Filter, error) {
+	result := make([]domain.Filter, len(filters))
+	for i, f := range filters {
+		rewritten, err := encryptFilter(ctx, c, schema, f)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = rewritten
+	}
+	return result, nil
+}
+
+func encryptFilter(ctx context.Context, c *security.ColumnCipher, schema *domain.TableInfo, f domain.Filter) (domain.Filter, error) {
+	var err error
+	if len(f.SubFilters) > 0 {
+		if f.SubFilters, err = encryptFilterList(ctx, c, schema, f.SubFilters); err != nil {
+			return f, err
+		}
+	}
+	if nested, ok := f.Value.([]domain.Filter); ok {
+		if f.Value, err = encryptFilterList(ctx, c, schema, nested); err != nil {
+			return f, err
+		}
+		return f, nil
+	}
+	if f.Field == "" {
+		return f, nil
+	}
+	// Strip the table qualifier (e.g. "users.email" -> "email")
+	field := f.Field
+	if idx := strings.LastIndex(field, "."); idx >= 0 {
+		field = field[idx+1:]
+	}
+	col := getColumnInfo(field, schema)
+	if col == nil || col.Encryption == nil {
+		return f, nil
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(f.Operator)) {
+	case "IS NULL", "ISNULL", "IS NOT NULL", "ISNOTNULL":
+		return f, nil
+	case "=", "EQ", "<=>", "NULLEQ", "!=", "<>", "NE", "NEQ", "IN", "NOT IN":
+		if !col.Encryption.SupportsEquality() {
+			return f, &domain.ErrEncryptedColumnOperator{Column: col.Name, Operator: f.Operator}
+		}
+	default:
+		return f, &domain.ErrEncryptedColumnOperator{Column: col.Name, Operator: f.Operator}
+	}
+
+	convert := c.Encrypt
+	if col.Encryption.Mode == domain.EncryptionBlindIndex {
+		convert = c.BlindIndex
+		f.Field = blindIndexColumn(col.Name)
+	}
+	if values, ok := f.Value.([]interface{}); ok {
+		converted := make([]interface{}, len(values))
+		for i, v := range values {
+			if converted[i], err = convert(ctx, col, v); err != nil {
+				return f, err
+			}
+		}
+		f.Value = converted
+		return f, nil
+	}
+	f.Value, err = convert(ctx, col, f.Value)
+	return f, err
+}
+
+// decryptResult decrypts the encrypted columns of a query result when the caller may read
+// plaintext (domain.WithColumnDecryption); otherwise the ciphertexts are returned.
+// Result rows may be shared with the table storage, so they are copied.
+func (m *MVCCDataSource) decryptResult(ctx context.Context, schema *domain.TableInfo, result *domain.QueryResult) error {
+	if result == nil || !schema.HasEncryptedColumns() || !domain.ColumnDecryptionAllowed(ctx) {
+		return nil
+	}
+	rows, err := m.decryptRows(ctx, schema, result.Rows)
+	if err != nil {
+		return err
+	}
+	result.Rows = rows
+	return nil
+}
+
+// plainRows returns copies of rows with the encrypted columns decrypted
+func (m *MVCCDataSource) plainRows(ctx context.Context, schema *domain.TableInfo, rows ...domain.Row) ([]domain.Row, error) {
+	copies := make([]domain.Row, len(rows))
+	for i, row := range rows {
+		copies[i] = deepCopyRow(row)
+	}
+	if !schema.HasEncryptedColumns() {
+		return copies, nil
+	}
+	return m.decryptRows(ctx, schema, copies)
+}
+
+func (m *MVCCDataSource) decryptRows(ctx context.Context, schema *domain.TableInfo, rows []domain.Row) ([]domain.Row, error) {
+	c, err := m.columnCipher()
+	if err != nil {
+		return nil, err
+	}
+	decrypted := make([]domain.Row, len(rows))
+	for i, row := range rows {
+		newRow := make(domain.Row, len(row))
+		for k, v := range row {
+			newRow[k] = v
+		}
+		for j := range schema.Columns {
+			col := &schema.Columns[j]
+			if col.Encryption == nil {
+				continue
+			}
+			value, ok := newRow[col.Name]
+			if !ok {
+				continue
+			}
+			if newRow[col.Name], err = c.Decrypt(ctx, col, value); err != nil {
+				return nil, err
+			}
+		}
+		decrypted[i] = newRow
+	}
+	return decrypted, nil
+}
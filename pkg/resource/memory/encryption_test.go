@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+func newEncryptedTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "test",
+		Writable: true,
+	})
+	ds.Connect(context.Background())
+	key, _ := security.ParseDataKey("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	ds.SetKeyProvider(security.KeyProviderFunc(func(ctx context.Context, keyID string) ([]byte, error) {
+		return key, nil
+	}))
+	err := ds.CreateTable(context.Background(), &domain.TableInfo{
+		Name: "users",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+			{Name: "ssn", Type: "VARCHAR(20)", Nullable: true, Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionDeterministic}},
+			{Name: "email", Type: "VARCHAR(100)", Nullable: true, Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionBlindIndex}},
+			{Name: "note", Type: "TEXT", Nullable: true, Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionRandomized}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+	return ds
+}
+
+// isCiphertext reports whether a stored value has the ciphertext format
+func isCiphertext(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, "enc:v1:")
+}
+
+// TestEncryptedColumns_StoredEncrypted verifies that encrypted columns are stored
+// as ciphertext and decrypted only when the context allows it.
+func TestEncryptedColumns_StoredEncrypted(t *testing.T) {
+	ds := newEncryptedTestSource(t)
+	ctx := context.Background()
+
+	if _, err := ds.Insert(ctx, "users", []domain.Row{
+		{"id": int64(1), "ssn": "111-22-3333", "email": "a@example.com", "note": "vip"},
+		{"id": int64(2), "ssn": "444-55-6666", "email": "b@example.com", "note": nil},
+	}, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+
+	// The blind index column is hidden
+	info, err := ds.GetTableInfo(ctx, "users")
+	if err != nil {
+		t.Fatalf("GetTableInfo failed: %v", err)
+	}
+	if len(info.Columns) != 4 {
+		t.Fatalf("expected 4 visible columns, got %d", len(info.Columns))
+	}
+
+	_, stored, err := ds.GetLatestTableData("users")
+	if err != nil {
+		t.Fatalf("GetLatestTableData failed: %v", err)
+	}
+	for _, row := range stored {
+		for _, col := range []string{"ssn", "email"} {
+			if !isCiphertext(row[col]) {
+				t.Fatalf("column %s stored in plaintext: %v", col, row[col])
+			}
+		}
+	}
+
+	result, err := ds.Query(ctx, "users", &domain.QueryOptions{OrderBy: "id"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !isCiphertext(result.Rows[0]["ssn"]) {
+		t.Fatalf("expected ciphertext without decryption permission, got %v", result.Rows[0]["ssn"])
+	}
+
+	result, err = ds.Query(domain.WithColumnDecryption(ctx), "users", &domain.QueryOptions{OrderBy: "id"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !result.Ordered {
+		t.Fatalf("ordering by a plaintext column should be reported as honored")
+	}
+	row := result.Rows[0]
+	if row["ssn"] != "111-22-3333" || row["email"] != "a@example.com" || row["note"] != "vip" {
+		t.Fatalf("unexpected decrypted row: %v", row)
+	}
+	if _, ok := row[blindIndexColumn("email")]; ok {
+		t.Fatalf("blind index column leaked into the result: %v", row)
+	}
+	if result.Rows[1]["note"] != nil {
+		t.Fatalf("NULL should stay NULL, got %v", result.Rows[1]["note"])
+	}
+
+	// Ciphertexts do not sort like the plaintexts: the caller has to sort
+	if ds.SupportsSort("users", []domain.OrderByItem{{Column: "id"}, {Column: "ssn"}}) {
+		t.Fatalf("sorting by an encrypted column should not be pushed down")
+	}
+	result, err = ds.Query(domain.WithColumnDecryption(ctx), "users", &domain.QueryOptions{OrderBy: "ssn"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if result.Ordered {
+		t.Fatalf("ordering by an encrypted column should not be reported as honored")
+	}
+}
+
+// TestEncryptedColumns_ClientCiphertext verifies that values written by clients are
+// always encrypted, even when they look like ciphertexts, and that ciphertexts are
+// bound to the table and column they were written to.
+func TestEncryptedColumns_ClientCiphertext(t *testing.T) {
+	ds := newEncryptedTestSource(t)
+	ctx := domain.WithColumnDecryption(context.Background())
+
+	if _, err := ds.Insert(context.Background(), "users", []domain.Row{{"id": int64(1), "ssn": "111-22-3333"}}, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	_, stored, err := ds.GetLatestTableData("users")
+	if err != nil {
+		t.Fatalf("GetLatestTableData failed: %v", err)
+	}
+	copied := stored[0]["ssn"]
+
+	// A copied ciphertext and a value with the ciphertext prefix are stored as plaintext values
+	if _, err := ds.Insert(context.Background(), "users", []domain.Row{
+		{"id": int64(2), "ssn": copied, "note": "enc:v1:not a ciphertext"},
+	}, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	result, err := ds.Query(ctx, "users", &domain.QueryOptions{OrderBy: "id"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[1]["ssn"] != copied || result.Rows[1]["note"] != "enc:v1:not a ciphertext" {
+		t.Fatalf("expected the client values back unchanged, got %v", result.Rows)
+	}
+	found, err := ds.Query(ctx, "users", &domain.QueryOptions{Filters: []domain.Filter{{Field: "ssn", Operator: "=", Value: copied}}})
+	if err != nil || len(found.Rows) != 1 || found.Rows[0]["id"] != int64(2) {
+		t.Fatalf("expected the lookup of the copied value to find row 2, got %v (%v)", found, err)
+	}
+
+	// The ciphertexts are bound to "table.column"
+	info, err := ds.GetTableInfo(context.Background(), "users")
+	if err != nil {
+		t.Fatalf("GetTableInfo failed: %v", err)
+	}
+	ssn, ok := info.GetColumn("ssn")
+	if !ok || ssn.Encryption == nil || ssn.Encryption.Context != "users.ssn" {
+		t.Fatalf("expected the encryption context users.ssn, got %+v", ssn)
+	}
+	c, _ := ds.columnCipher()
+	moved := ssn
+	moved.Encryption = &domain.ColumnEncryption{Mode: ssn.Encryption.Mode, Context: "users.note"}
+	if _, err := c.Decrypt(ctx, &moved, copied); err == nil {
+		t.Fatal("expected a ciphertext copied to another column not to decrypt")
+	}
+}
+
+// TestEncryptedColumns_EqualityLookups verifies equality filters on deterministic
+// and blind index columns and rejects other comparisons.
+func TestEncryptedColumns_EqualityLookups(t *testing.T) {
+	ds := newEncryptedTestSource(t)
+	ctx := domain.WithColumnDecryption(context.Background())
+	ds.Insert(ctx, "users", []domain.Row{
+		{"id": int64(1), "ssn": "111-22-3333", "email": "a@example.com"},
+		{"id": int64(2), "ssn": "444-55-6666", "email": "b@example.com"},
+		{"id": int64(3), "ssn": "777-88-9999", "email": "a@example.com"},
+	}, nil)
+
+	count := func(filters ...domain.Filter) int {
+		t.Helper()
+		result, err := ds.Query(ctx, "users", &domain.QueryOptions{Filters: filters})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		return len(result.Rows)
+	}
+
+	if n := count(domain.Filter{Field: "ssn", Operator: "=", Value: "444-55-6666"}); n != 1 {
+		t.Fatalf("deterministic lookup: expected 1 row, got %d", n)
+	}
+	if n := count(domain.Filter{Field: "users.email", Operator: "=", Value: "a@example.com"}); n != 2 {
+		t.Fatalf("blind index lookup: expected 2 rows, got %d", n)
+	}
+	if n := count(domain.Filter{Field: "email", Operator: "IN", Value: []interface{}{"b@example.com", "c@example.com"}}); n != 1 {
+		t.Fatalf("blind index IN: expected 1 row, got %d", n)
+	}
+	if n := count(domain.Filter{Field: "ssn", Operator: "!=", Value: "111-22-3333"}); n != 2 {
+		t.Fatalf("deterministic !=: expected 2 rows, got %d", n)
+	}
+
+	_, err := ds.Query(ctx, "users", &domain.QueryOptions{Filters: []domain.Filter{{Field: "ssn", Operator: "LIKE", Value: "111%"}}})
+	var opErr *domain.ErrEncryptedColumnOperator
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected ErrEncryptedColumnOperator for LIKE, got %v", err)
+	}
+	_, err = ds.Query(ctx, "users", &domain.QueryOptions{Filters: []domain.Filter{{Field: "note", Operator: "=", Value: "x"}}})
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected ErrEncryptedColumnOperator on randomized column, got %v", err)
+	}
+
+	// UPDATE and DELETE locate rows and store new values encrypted
+	n, err := ds.Update(ctx, "users", []domain.Filter{{Field: "email", Operator: "=", Value: "b@example.com"}},
+		domain.Row{"email": "c@example.com"}, nil)
+	if err != nil || n != 1 {
+		t.Fatalf("Update: n=%d err=%v", n, err)
+	}
+	if n := count(domain.Filter{Field: "email", Operator: "=", Value: "c@example.com"}); n != 1 {
+		t.Fatalf("expected the updated blind index to match, got %d rows", n)
+	}
+	n, err = ds.Delete(ctx, "users", []domain.Filter{{Field: "ssn", Operator: "=", Value: "111-22-3333"}}, nil)
+	if err != nil || n != 1 {
+		t.Fatalf("Delete: n=%d err=%v", n, err)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("expected 2 rows after delete, got %d", n)
+	}
+}
+
+// TestEncryptedColumns_CreateTableChecks verifies the restrictions on encrypted column definitions.
+func TestEncryptedColumns_CreateTableChecks(t *testing.T) {
+	ctx := context.Background()
+	ds := NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "test", Writable: true})
+	ds.Connect(ctx)
+
+	table := func(col domain.ColumnInfo) *domain.TableInfo {
+		return &domain.TableInfo{Name: "t", Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}, col}}
+	}
+	enc := &domain.ColumnEncryption{Mode: domain.EncryptionRandomized}
+
+	if err := ds.CreateTable(ctx, table(domain.ColumnInfo{Name: "v", Type: "TEXT", Encryption: enc})); err == nil {
+		t.Fatal("expected an error without encryption keys")
+	}
+
+	ds.SetKeyProvider(security.KeyRing{})
+	if err := ds.CreateTable(ctx, table(domain.ColumnInfo{Name: "v", Type: "TEXT", Unique: true, Encryption: enc})); err == nil {
+		t.Fatal("expected an error for a unique randomized column")
+	}
+	if err := ds.CreateTable(ctx, table(domain.ColumnInfo{Name: "v", Type: "TEXT", Encryption: &domain.ColumnEncryption{Mode: "rot13"}})); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+	if err := ds.CreateTable(ctx, &domain.TableInfo{Name: "t", Columns: []domain.ColumnInfo{
+		{Name: "id", Type: "INT", Primary: true, Encryption: enc},
+	}}); err == nil {
+		t.Fatal("expected an error for an encrypted primary key")
+	}
+}
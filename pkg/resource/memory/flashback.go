@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== FLASHBACK TABLE ====================
+
+// FlashbackTable implements domain.Flashbacker. The changes of the table
+// committed after to are read from the change log and reversed, newest
+// first, in a single new version. The reversal is itself recorded as
+// changes. The change log must be enabled (SetChangeLogSize) and still
+// retain every change after to.
+func (m *MVCCDataSource) FlashbackTable(ctx context.Context, tableName string, to time.Time) (int64, error) {
+	if !m.IsWritable() {
+		return 0, domain.NewErrReadOnly(string(m.config.Type), "flashback table")
+	}
+	if _, hasTxn := GetTransactionID(ctx); hasTxn {
+		return 0, fmt.Errorf("FLASHBACK TABLE is not allowed in a transaction")
+	}
+	if to.After(time.Now()) {
+		return 0, fmt.Errorf("cannot flash back table '%s': the timestamp is in the future", tableName)
+	}
+
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	if !m.connected {
+		m.mu.Unlock()
+		return 0, domain.NewErrNotConnected("memory")
+	}
+	tableVer, ok := m.tables[tableName]
+	if !ok {
+		m.mu.Unlock()
+		return 0, domain.NewErrTableNotFound(tableName)
+	}
+	changes, err := m.changes.tableChangesAfter(tableName, to)
+	if err != nil {
+		m.mu.Unlock()
+		return 0, fmt.Errorf("cannot flash back table '%s': %w", tableName, err)
+	}
+	if len(changes) == 0 {
+		m.mu.Unlock()
+		return 0, nil
+	}
+
+	m.currentVer++
+	newVer := m.currentVer
+	defer m.changes.flush()
+	tableVer.mu.Lock()
+	m.mu.Unlock()
+	defer tableVer.mu.Unlock()
+
+	latestData := tableVer.versions[tableVer.latest]
+	if latestData == nil {
+		return 0, domain.NewErrTableNotFound(tableName)
+	}
+	if latestData.schema.IsPartitioned() {
+		return 0, domain.NewErrUnsupportedOperation(string(m.config.Type), "flashback partitioned table")
+	}
+
+	srcRows := latestData.Rows()
+	rows := make([]domain.Row, len(srcRows))
+	copy(rows, srcRows)
+	undo := make([]domain.ChangeEvent, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		switch change.Type {
+		case domain.ChangeInsert:
+			idx := findRow(rows, change.After)
+			if idx < 0 {
+				return 0, flashbackConflict(tableName, change)
+			}
+			undo = append(undo, domain.ChangeEvent{Table: tableName, Type: domain.ChangeDelete, Before: rows[idx]})
+			rows = append(rows[:idx], rows[idx+1:]...)
+		case domain.ChangeDelete:
+			row := deepCopyRow(change.Before)
+			rows = append(rows, row)
+			undo = append(undo, domain.ChangeEvent{Table: tableName, Type: domain.ChangeInsert, After: row})
+		case domain.ChangeUpdate:
+			idx := findRow(rows, change.After)
+			if idx < 0 {
+				return 0, flashbackConflict(tableName, change)
+			}
+			row := deepCopyRow(change.Before)
+			undo = append(undo, domain.ChangeEvent{Table: tableName, Type: domain.ChangeUpdate, Before: rows[idx], After: row})
+			rows[idx] = row
+		}
+	}
+
+	versionData := &TableData{
+		version:   newVer,
+		createdAt: time.Now(),
+		schema:    deepCopySchema(latestData.schema),
+		rows:      NewPagedRows(m.bufferPool, rows, 0, tableName, newVer),
+	}
+	tableVer.versions[newVer] = versionData
+	tableVer.latest = newVer
+	tableVer.churn += int64(len(changes))
+
+	// Maintain indexes: rebuild from the new version's rows
+	m.rebuildTableIndexes(tableName, versionData.schema, rows)
+
+	m.changes.record(undo)
+
+	return int64(len(changes)), nil
+}
+
+// flashbackConflict reports a change whose row is no longer in the table,
+// e.g. after TRUNCATE TABLE, which is not recorded in the change log
+func flashbackConflict(tableName string, change domain.ChangeEvent) error {
+	return fmt.Errorf("cannot flash back table '%s': the row of %s at LSN %d is no longer in the table",
+		tableName, change.Type, change.LSN)
+}
+
+// findRow returns the index of the row equal to want, or -1
+func findRow(rows []domain.Row, want domain.Row) int {
+	for i, row := range rows {
+		if rowsEqual(row, want) {
+			return i
+		}
+	}
+	return -1
+}
+
+func rowsEqual(a, b domain.Row) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, va := range a {
+		vb, ok := b[k]
+		if !ok || fmt.Sprintf("%v", va) != fmt.Sprintf("%v", vb) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/generated"
+)
+
+// functionalIndexColumnPrefix prefixes the hidden columns that store functional index keys
+const functionalIndexColumnPrefix = "__fidx_"
+
+// functionalIndexColumn returns the hidden column that stores the keys of a functional index
+func functionalIndexColumn(indexName string) string {
+	return functionalIndexColumnPrefix + indexName
+}
+
+// CreateFunctionalIndex creates an index whose keys are computed from an expression.
+// The keys are kept in a hidden STORED generated column: inserts and updates compute
+// them like any other generated column, and the index is built on that column.
+func (m *MVCCDataSource) CreateFunctionalIndex(ctx context.Context, tableName string, idx domain.FunctionalIndex, indexType string) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "create index")
+	}
+	idxType := toIndexType(indexType)
+	if idxType != IndexTypeBTree && idxType != IndexTypeHash {
+		return domain.NewErrIndexCreationFailed(tableName, idx.Expression,
+			fmt.Sprintf("functional indexes do not support index type %s", indexType))
+	}
+
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tableVer, ok := m.tables[tableName]
+	if !ok {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.Lock()
+	defer tableVer.mu.Unlock()
+
+	latestData := tableVer.versions[tableVer.latest]
+	if latestData == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+	if latestData.schema.IsPartitioned() {
+		return domain.NewErrIndexCreationFailed(tableName, idx.Expression,
+			"functional indexes are not supported on partitioned tables")
+	}
+
+	if infos, err := m.indexManager.GetTableIndexes(tableName); err == nil {
+		for _, info := range infos {
+			if strings.EqualFold(info.Name, idx.Name) {
+				return domain.NewErrIndexCreationFailed(tableName, idx.Expression,
+					fmt.Sprintf("duplicate index name '%s'", idx.Name))
+			}
+		}
+	}
+	for _, dep := range idx.Depends {
+		col := getColumnInfo(dep, latestData.schema)
+		if col == nil || col.Hidden {
+			return domain.NewErrIndexCreationFailed(tableName, idx.Expression,
+				fmt.Sprintf("unknown column '%s'", dep))
+		}
+		if col.IsGenerated && col.GeneratedType == "VIRTUAL" {
+			return domain.NewErrIndexCreationFailed(tableName, idx.Expression,
+				fmt.Sprintf("cannot index VIRTUAL generated column '%s'", dep))
+		}
+	}
+
+	column := functionalIndexColumn(idx.Name)
+	schema := deepCopySchema(latestData.schema)
+	keyCol := domain.ColumnInfo{
+		Name:             column,
+		Nullable:         true,
+		IsGenerated:      true,
+		GeneratedType:    "STORED",
+		GeneratedExpr:    idx.Expression,
+		GeneratedDepends: append([]string(nil), idx.Depends...),
+		Hidden:           true,
+	}
+	schema.Columns = append(schema.Columns, keyCol)
+
+	// Compute the keys of existing rows
+	evaluator := generated.NewGeneratedColumnEvaluator()
+	oldRows := latestData.Rows()
+	rows := make([]domain.Row, len(oldRows))
+	seen := make(map[string]bool)
+	for i, row := range oldRows {
+		key, err := evaluator.EvaluateColumn(&keyCol, row, schema)
+		if err != nil {
+			return domain.NewErrIndexCreationFailed(tableName, idx.Expression, err.Error())
+		}
+		if idx.Unique && key != nil {
+			k := fmt.Sprintf("%v", key)
+			if seen[k] {
+				return fmt.Errorf("Duplicate entry '%v' for key '%s'", key, idx.Name)
+			}
+			seen[k] = true
+		}
+		newRow := make(domain.Row, len(row)+1)
+		for k, v := range row {
+			newRow[k] = v
+		}
+		newRow[column] = key
+		rows[i] = newRow
+	}
+
+	if _, err := m.indexManager.CreateFunctionalIndex(tableName, idx.Name, column, idx.Expression, idxType, idx.Unique); err != nil {
+		return domain.NewErrIndexCreationFailed(tableName, idx.Expression, err.Error())
+	}
+
+	m.currentVer++
+	tableVer.versions[m.currentVer] = &TableData{
+		version:   m.currentVer,
+		createdAt: time.Now(),
+		schema:    schema,
+		rows:      NewPagedRows(m.bufferPool, rows, 0, tableName, m.currentVer),
+	}
+	tableVer.latest = m.currentVer
+
+	total := int64(len(rows))
+	info := fmt.Sprintf("Building index %s on %s(%s)", idx.Name, tableName, idx.Expression)
+	_ = m.indexManager.RebuildIndexWithProgress(tableName, schema, rows, func(done int64) {
+		domain.ReportProgress(ctx, domain.Progress{Stage: 1, MaxStage: 1, Done: done, Total: total, Info: info})
+	})
+	return nil
+}
+
+// FunctionalIndexes returns the functional indexes of a table
+func (m *MVCCDataSource) FunctionalIndexes(ctx context.Context, tableName string) []domain.FunctionalIndex {
+	schema := m.latestSchema(tableName)
+	if schema == nil {
+		return nil
+	}
+	infos, err := m.indexManager.GetTableIndexes(tableName)
+	if err != nil {
+		return nil
+	}
+	var result []domain.FunctionalIndex
+	for _, info := range infos {
+		if info.Expression == "" || len(info.Columns) != 1 {
+			continue
+		}
+		col := getColumnInfo(info.Columns[0], schema)
+		if col == nil {
+			continue
+		}
+		result = append(result, domain.FunctionalIndex{
+			Name:       info.Name,
+			Expression: info.Expression,
+			Column:     col.Name,
+			Depends:    append([]string(nil), col.GeneratedDepends...),
+			Unique:     info.Unique,
+		})
+	}
+	return result
+}
+
+// dropFunctionalIndexColumn removes the hidden key column of a dropped functional index
+func (m *MVCCDataSource) dropFunctionalIndexColumn(ctx context.Context, tableName, column string) error {
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tableVer, ok := m.tables[tableName]
+	if !ok {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.Lock()
+	defer tableVer.mu.Unlock()
+
+	latestData := tableVer.versions[tableVer.latest]
+	if latestData == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	schema := deepCopySchema(latestData.schema)
+	cols := schema.Columns[:0]
+	for _, col := range schema.Columns {
+		if col.Name != column {
+			cols = append(cols, col)
+		}
+	}
+	schema.Columns = cols
+
+	oldRows := latestData.Rows()
+	rows := make([]domain.Row, len(oldRows))
+	for i, row := range oldRows {
+		newRow := make(domain.Row, len(row))
+		for k, v := range row {
+			if k != column {
+				newRow[k] = v
+			}
+		}
+		rows[i] = newRow
+	}
+
+	m.currentVer++
+	tableVer.versions[m.currentVer] = &TableData{
+		version:   m.currentVer,
+		createdAt: time.Now(),
+		schema:    schema,
+		rows:      NewPagedRows(m.bufferPool, rows, 0, tableName, m.currentVer),
+	}
+	tableVer.latest = m.currentVer
+	return nil
+}
+
+// hasHiddenColumns reports whether the schema has hidden columns
+func hasHiddenColumns(schema *domain.TableInfo) bool {
+	for _, col := range schema.Columns {
+		if col.Hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleColumns returns the columns of schema without hidden columns
+func visibleColumns(columns []domain.ColumnInfo) []domain.ColumnInfo {
+	visible := make([]domain.ColumnInfo, 0, len(columns))
+	for _, col := range columns {
+		if !col.Hidden {
+			visible = append(visible, col)
+		}
+	}
+	return visible
+}
+
+// stripHiddenColumns removes hidden columns from a query result.
+// Result rows may be shared with the table storage, so they are copied.
+func stripHiddenColumns(result *domain.QueryResult, schema *domain.TableInfo) {
+	hidden := make([]string, 0, 1)
+	for _, col := range schema.Columns {
+		if col.Hidden {
+			hidden = append(hidden, col.Name)
+		}
+	}
+	result.Columns = visibleColumns(result.Columns)
+	for i, row := range result.Rows {
+		newRow := make(domain.Row, len(row))
+		for k, v := range row {
+			newRow[k] = v
+		}
+		for _, name := range hidden {
+			delete(newRow, name)
+		}
+		result.Rows[i] = newRow
+	}
+}
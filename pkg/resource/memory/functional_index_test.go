@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// TestFunctionalIndex_BackfillAndPlan verifies that a functional index computes
+// keys for existing and new rows and is chosen for filters on its key column.
+func TestFunctionalIndex_BackfillAndPlan(t *testing.T) {
+	ds := NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "test",
+		Writable: true,
+	})
+	ctx := context.Background()
+	ds.Connect(ctx)
+
+	ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "items",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INTEGER", Primary: true},
+			{Name: "qty", Type: "INTEGER"},
+		},
+	})
+	ds.Insert(ctx, "items", []domain.Row{
+		{"id": int64(1), "qty": int64(2)},
+		{"id": int64(2), "qty": int64(5)},
+	}, nil)
+
+	err := ds.CreateFunctionalIndex(ctx, "items", domain.FunctionalIndex{
+		Name:       "idx_double",
+		Expression: "qty * 2",
+		Depends:    []string{"qty"},
+	}, "btree")
+	if err != nil {
+		t.Fatalf("CreateFunctionalIndex failed: %v", err)
+	}
+
+	indexes := ds.FunctionalIndexes(ctx, "items")
+	if len(indexes) != 1 || indexes[0].Column != "__fidx_idx_double" {
+		t.Fatalf("unexpected functional indexes: %+v", indexes)
+	}
+
+	ds.Insert(ctx, "items", []domain.Row{{"id": int64(3), "qty": int64(7)}}, nil)
+
+	filters := []domain.Filter{{Field: indexes[0].Column, Operator: "=", Value: float64(14)}}
+	plan, err := ds.queryPlanner.PlanQuery("items", filters, nil)
+	if err != nil {
+		t.Fatalf("PlanQuery failed: %v", err)
+	}
+	if plan.Method != ScanMethodIndex || plan.Index.Name != "idx_double" {
+		t.Fatalf("expected index scan on idx_double, got %s", plan.Method)
+	}
+
+	result, err := ds.Query(ctx, "items", &domain.QueryOptions{Filters: filters})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0]["id"] != int64(3) {
+		t.Fatalf("expected row 3, got %v", result.Rows)
+	}
+	if _, ok := result.Rows[0][indexes[0].Column]; ok {
+		t.Errorf("hidden column should not be returned")
+	}
+	for _, col := range result.Columns {
+		if col.Hidden {
+			t.Errorf("hidden column %s should not be returned", col.Name)
+		}
+	}
+
+	result, err = ds.Query(ctx, "items", &domain.QueryOptions{
+		Filters: []domain.Filter{{Field: indexes[0].Column, Operator: "=", Value: float64(4)}},
+	})
+	if err != nil || len(result.Rows) != 1 || result.Rows[0]["id"] != int64(1) {
+		t.Fatalf("expected backfilled row 1, got %v (%v)", result, err)
+	}
+
+	if err := ds.DropIndex("items", "idx_double"); err != nil {
+		t.Fatalf("DropIndex failed: %v", err)
+	}
+	if schema := ds.latestSchema("items"); len(schema.Columns) != 2 {
+		t.Errorf("expected hidden column to be dropped, got %d columns", len(schema.Columns))
+	}
+}
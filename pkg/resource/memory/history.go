@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== History (FOR SYSTEM_TIME AS OF) ====================
+
+// SetHistoryRetention implements domain.HistoryManager. Old table versions are
+// kept for retention after they are replaced, so that queries can read the
+// table as of any time inside that window. An empty tableName sets the
+// default for all tables; a retention of 0 keeps no history.
+func (m *MVCCDataSource) SetHistoryRetention(ctx context.Context, tableName string, retention time.Duration) error {
+	if retention < 0 {
+		return fmt.Errorf("history retention must not be negative: %s", retention)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.connected {
+		return domain.NewErrNotConnected("memory")
+	}
+	if tableName != "" {
+		if _, ok := m.tables[tableName]; !ok {
+			return domain.NewErrTableNotFound(tableName)
+		}
+	}
+	m.historyRetention[tableName] = retention
+	// Shortening the retention frees the versions outside the new window
+	m.gcOldVersions()
+	return nil
+}
+
+// HistoryRetention implements domain.HistoryManager
+func (m *MVCCDataSource) HistoryRetention(tableName string) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.historyRetentionLocked(tableName)
+}
+
+// historyRetentionLocked returns the retention of a table, falling back to the
+// data source default. Must be called while holding m.mu.
+func (m *MVCCDataSource) historyRetentionLocked(tableName string) time.Duration {
+	if retention, ok := m.historyRetention[tableName]; ok {
+		return retention
+	}
+	return m.historyRetention[""]
+}
+
+// PurgeHistory implements domain.HistoryManager
+func (m *MVCCDataSource) PurgeHistory(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.connected {
+		return 0, domain.NewErrNotConnected("memory")
+	}
+	return m.gcOldVersions(), nil
+}
+
+// versionAsOf returns the version of a table that was current at asOf.
+// retention is the history retention of the table.
+func (tv *TableVersions) versionAsOf(tableName string, asOf time.Time, retention time.Duration) (*TableData, error) {
+	now := time.Now()
+	switch {
+	case retention <= 0:
+		return nil, domain.NewErrHistoryUnavailable(tableName, asOf, "history retention is not set for the table")
+	case asOf.After(now):
+		return nil, domain.NewErrHistoryUnavailable(tableName, asOf, "the timestamp is in the future")
+	case asOf.Before(now.Add(-retention)):
+		return nil, domain.NewErrHistoryUnavailable(tableName, asOf,
+			fmt.Sprintf("the timestamp is older than the history retention of %s", retention))
+	}
+
+	tv.mu.RLock()
+	defer tv.mu.RUnlock()
+	var found *TableData
+	for _, ver := range tv.sortedVersions() {
+		data := tv.versions[ver]
+		if data.createdAt.After(asOf) {
+			break
+		}
+		found = data
+	}
+	if found == nil {
+		return nil, domain.NewErrHistoryUnavailable(tableName, asOf, "no version of the table is retained for that time")
+	}
+	if found.schema.IsPartitioned() {
+		return nil, fmt.Errorf("AS OF is not supported for partitioned table '%s'", tableName)
+	}
+	return found, nil
+}
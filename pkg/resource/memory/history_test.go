@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// newHistoryTestSource creates table "events" and inserts one row per entry of
+// ages, then backdates the versions: the table is created ages[0] ago and the
+// i-th row is inserted ages[i+1] ago.
+func newHistoryTestSource(t *testing.T, ages ...time.Duration) *MVCCDataSource {
+	t.Helper()
+	ctx := context.Background()
+	ds := NewMVCCDataSource(nil)
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { ds.Close(ctx) })
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "events",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+		},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	for i := 1; i < len(ages); i++ {
+		if _, err := ds.Insert(ctx, "events", []domain.Row{{"id": int64(i)}}, nil); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+
+	tableVer := ds.tables["events"]
+	now := time.Now()
+	for i, ver := range tableVer.sortedVersions() {
+		tableVer.versions[ver].createdAt = now.Add(-ages[i])
+	}
+	return ds
+}
+
+func queryAsOf(ds *MVCCDataSource, ago time.Duration) (*domain.QueryResult, context.Context, error) {
+	ctx := domain.WithAsOf(context.Background(), time.Now().Add(-ago))
+	result, err := ds.Query(ctx, "events", &domain.QueryOptions{})
+	return result, ctx, err
+}
+
+// TestQueryAsOf verifies that a time-travel query reads the version that was
+// current at the requested time
+func TestQueryAsOf(t *testing.T) {
+	ds := newHistoryTestSource(t, 50*time.Minute, 40*time.Minute, 20*time.Minute)
+	if err := ds.SetHistoryRetention(context.Background(), "events", time.Hour); err != nil {
+		t.Fatalf("SetHistoryRetention() error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		ago  time.Duration
+		rows int
+	}{
+		{45 * time.Minute, 0},
+		{30 * time.Minute, 1},
+		{10 * time.Minute, 2},
+	} {
+		result, ctx, err := queryAsOf(ds, tc.ago)
+		if err != nil {
+			t.Fatalf("Query() as of %s ago error = %v", tc.ago, err)
+		}
+		if len(result.Rows) != tc.rows {
+			t.Errorf("as of %s ago: got %d rows, want %d", tc.ago, len(result.Rows), tc.rows)
+		}
+		if !domain.AsOfServed(ctx) {
+			t.Errorf("as of %s ago: query not marked as served", tc.ago)
+		}
+	}
+
+	// Without AS OF the latest version is read
+	result, err := ds.Query(context.Background(), "events", &domain.QueryOptions{})
+	if err != nil || len(result.Rows) != 2 {
+		t.Fatalf("Query() = %v, %v", result, err)
+	}
+
+	// Filter reads the same version
+	ctx := domain.WithAsOf(context.Background(), time.Now().Add(-30*time.Minute))
+	rows, total, err := ds.Filter(ctx, "events", domain.Filter{Field: "id", Operator: ">=", Value: int64(1)}, 0, 0)
+	if err != nil || total != 1 || len(rows) != 1 {
+		t.Errorf("Filter() = %v, %d, %v", rows, total, err)
+	}
+}
+
+// TestQueryAsOfErrors verifies the times for which no history is available
+func TestQueryAsOfErrors(t *testing.T) {
+	ds := newHistoryTestSource(t, 50*time.Minute, 40*time.Minute)
+
+	var unavailable *domain.ErrHistoryUnavailable
+	if _, _, err := queryAsOf(ds, 30*time.Minute); !errors.As(err, &unavailable) {
+		t.Errorf("without retention: error = %v", err)
+	}
+
+	if err := ds.SetHistoryRetention(context.Background(), "", time.Hour); err != nil {
+		t.Fatalf("SetHistoryRetention() error = %v", err)
+	}
+	for _, ago := range []time.Duration{-time.Minute, 55 * time.Minute, 2 * time.Hour} {
+		if _, _, err := queryAsOf(ds, ago); !errors.As(err, &unavailable) {
+			t.Errorf("as of %s ago: error = %v", ago, err)
+		}
+	}
+	if _, _, err := queryAsOf(ds, 30*time.Minute); err != nil {
+		t.Errorf("with default retention: error = %v", err)
+	}
+
+	if err := ds.SetHistoryRetention(context.Background(), "missing", time.Hour); err == nil {
+		t.Error("SetHistoryRetention() on a missing table should fail")
+	}
+}
+
+// TestPurgeHistory verifies that versions replaced before the retention window
+// are purged and the others are kept
+func TestPurgeHistory(t *testing.T) {
+	ds := newHistoryTestSource(t, 3*time.Hour, 2*time.Hour, 30*time.Minute, 10*time.Minute)
+	ctx := context.Background()
+	tableVer := ds.tables["events"]
+
+	ds.mu.Lock()
+	ds.historyRetention["events"] = time.Hour
+	ds.mu.Unlock()
+
+	// The first version was replaced 2h ago, the second 30m ago
+	purged, err := ds.PurgeHistory(ctx)
+	if err != nil {
+		t.Fatalf("PurgeHistory() error = %v", err)
+	}
+	if purged != 1 || len(tableVer.versions) != 3 {
+		t.Errorf("purged = %d, versions = %d, want 1 and 3", purged, len(tableVer.versions))
+	}
+	result, _, err := queryAsOf(ds, 45*time.Minute)
+	if err != nil || len(result.Rows) != 1 {
+		t.Errorf("Query() after purge = %v, %v", result, err)
+	}
+
+	// Disabling history keeps only the latest version
+	if err := ds.SetHistoryRetention(ctx, "events", 0); err != nil {
+		t.Fatalf("SetHistoryRetention() error = %v", err)
+	}
+	if len(tableVer.versions) != 1 {
+		t.Errorf("versions = %d after disabling history, want 1", len(tableVer.versions))
+	}
+	if got := ds.HistoryRetention("events"); got != 0 {
+		t.Errorf("HistoryRetention() = %s, want 0", got)
+	}
+}
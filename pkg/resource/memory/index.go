@@ -85,6 +85,9 @@ type IndexInfo struct {
 	Columns   []string  `json:"columns"` // Support composite index (multi-column)
 	Type      IndexType `json:"type"`
 	Unique    bool      `json:"unique"`
+	// Expression is the key expression of a functional index; Columns then
+	// holds the hidden column that stores the computed keys
+	Expression string `json:"expression,omitempty"`
 }
 
 // ==================== B-Tree 索引实现 ====================
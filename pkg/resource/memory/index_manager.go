@@ -7,6 +7,9 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 )
 
+// indexProgressInterval 重建索引时报告进度的行数间隔
+const indexProgressInterval = 1024
+
 // IndexManager 索引管理器
 type IndexManager struct {
 	tables map[string]*TableIndexes
@@ -108,6 +111,29 @@ func (m *IndexManager) CreateIndexWithColumns(tableName string, columnNames []st
 	return idx, nil
 }
 
+// CreateFunctionalIndex creates an index named indexName on the hidden column
+// that stores the keys computed from expression
+func (m *IndexManager) CreateFunctionalIndex(tableName, indexName, column, expression string, indexType IndexType, unique bool) (Index, error) {
+	idx, err := m.CreateIndexWithColumns(tableName, []string{column}, indexType, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	tableIdxs := m.tables[tableName]
+	m.mu.RUnlock()
+
+	tableIdxs.mu.Lock()
+	defer tableIdxs.mu.Unlock()
+
+	info := idx.GetIndexInfo()
+	delete(tableIdxs.indexes, info.Name)
+	info.Name = indexName
+	info.Expression = expression
+	tableIdxs.indexes[indexName] = idx
+	return idx, nil
+}
+
 // CreateVectorIndex 创建向量索引
 func (m *IndexManager) CreateVectorIndex(
 	tableName, columnName string,
@@ -305,8 +331,44 @@ func (m *IndexManager) DropTableIndexes(tableName string) error {
 	return nil
 }
 
+// detachTableIndexes 移除表的索引但不关闭，用于把表移入回收站
+func (m *IndexManager) detachTableIndexes(tableName string) *TableIndexes {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tableIdxs := m.tables[tableName]
+	delete(m.tables, tableName)
+	return tableIdxs
+}
+
+// attachTableIndexes 恢复 detachTableIndexes 移除的索引
+func (m *IndexManager) attachTableIndexes(tableName string, tableIdxs *TableIndexes) {
+	if tableIdxs == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tables[tableName] = tableIdxs
+}
+
+// closeTableIndexes 关闭已移除的索引
+func closeTableIndexes(tableIdxs *TableIndexes) {
+	if tableIdxs == nil {
+		return
+	}
+	tableIdxs.mu.Lock()
+	defer tableIdxs.mu.Unlock()
+	for _, idx := range tableIdxs.vectorIndexes {
+		_ = idx.Close()
+	}
+}
+
 // RebuildIndex 重建索引
 func (m *IndexManager) RebuildIndex(tableName string, schema *domain.TableInfo, rows []domain.Row) error {
+	return m.RebuildIndexWithProgress(tableName, schema, rows, nil)
+}
+
+// RebuildIndexWithProgress 重建表的所有索引，每处理 indexProgressInterval 行及结束时调用 progress
+func (m *IndexManager) RebuildIndexWithProgress(tableName string, schema *domain.TableInfo, rows []domain.Row, progress func(done int64)) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -352,6 +414,12 @@ func (m *IndexManager) RebuildIndex(tableName string, schema *domain.TableInfo,
 				_ = idx.Insert(value, []int64{rowID})
 			}
 		}
+		if progress != nil && rowID%indexProgressInterval == 0 {
+			progress(rowID)
+		}
+	}
+	if progress != nil {
+		progress(int64(len(rows)))
 	}
 
 	return nil
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"unique"
+	"unsafe"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// maxInternLength bounds the strings that are interned. Enum-like values are
+// short; long strings are rarely repeated, so interning them only adds a lookup.
+const maxInternLength = 256
+
+// internString returns the canonical copy of s. Equal strings stored in
+// different rows then share one backing array, which acts as a global
+// dictionary: values stay plain strings, so reads need no decoding.
+// Canonical copies are released by the GC once no row references them.
+func internString(s string) string {
+	if len(s) == 0 || len(s) > maxInternLength {
+		return s
+	}
+	return unique.Make(s).Value()
+}
+
+// internRow interns the string values of a row in place.
+// The row must be owned by the caller and not yet visible to readers.
+func internRow(row domain.Row) {
+	for k, v := range row {
+		if s, ok := v.(string); ok {
+			row[k] = internString(s)
+		}
+	}
+}
+
+// internRows interns the string values of rows in place (see internRow)
+func internRows(rows []domain.Row) {
+	for _, row := range rows {
+		internRow(row)
+	}
+}
+
+// TableMemoryUsage reports the memory used by each column of the latest version.
+// String data shared between rows (see internString) is counted once.
+func (m *MVCCDataSource) TableMemoryUsage(ctx context.Context, tableName string) ([]domain.ColumnMemoryUsage, error) {
+	m.mu.RLock()
+	tableVer, ok := m.tables[tableName]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.RLock()
+	data := tableVer.versions[tableVer.latest]
+	tableVer.mu.RUnlock()
+	if data == nil {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+
+	type columnStats struct {
+		usage    domain.ColumnMemoryUsage
+		distinct map[string]struct{}
+		shared   map[*byte]struct{}
+	}
+	stats := make([]*columnStats, len(data.schema.Columns))
+	byName := make(map[string]*columnStats, len(data.schema.Columns))
+	for i, col := range data.schema.Columns {
+		stats[i] = &columnStats{
+			usage:    domain.ColumnMemoryUsage{Column: col.Name, Type: col.Type},
+			distinct: make(map[string]struct{}),
+			shared:   make(map[*byte]struct{}),
+		}
+		byName[col.Name] = stats[i]
+	}
+
+	var err error
+	data.rows.Range(func(i int, row domain.Row) bool {
+		if i%1024 == 0 {
+			if err = ctx.Err(); err != nil {
+				return false
+			}
+		}
+		for name, v := range row {
+			cs, ok := byName[name]
+			if !ok || v == nil {
+				continue
+			}
+			cs.usage.Values++
+			size := estimateValueSize(v)
+			cs.usage.LogicalBytes += size
+			s, ok := v.(string)
+			if !ok {
+				cs.usage.MemoryBytes += size
+				continue
+			}
+			cs.distinct[s] = struct{}{}
+			// The string header is stored per value, the data once per backing array
+			cs.usage.MemoryBytes += 16
+			if len(s) == 0 {
+				continue
+			}
+			ptr := unsafe.StringData(s)
+			if _, seen := cs.shared[ptr]; !seen {
+				cs.shared[ptr] = struct{}{}
+				cs.usage.MemoryBytes += int64(len(s))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.ColumnMemoryUsage, len(stats))
+	for i, cs := range stats {
+		cs.usage.DistinctValues = int64(len(cs.distinct))
+		result[i] = cs.usage
+	}
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// TestCodec_StringDictionaryAndPrefix verifies that repeated strings and strings
+// sharing a prefix round-trip, and that decoded equal strings share their data.
+func TestCodec_StringDictionaryAndPrefix(t *testing.T) {
+	rows := make([]domain.Row, 100)
+	var plainSize int
+	for i := range rows {
+		status := []string{"active", "inactive"}[i%2]
+		url := fmt.Sprintf("https://example.com/products/item-%03d", i)
+		rows[i] = domain.Row{"status": status, "url": url}
+		plainSize += len(status) + len(url)
+	}
+
+	encoded, err := encodeRows(rows)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if len(encoded) >= plainSize {
+		t.Errorf("expected encoded page (%d bytes) to be smaller than the raw strings (%d bytes)", len(encoded), plainSize)
+	}
+
+	decoded, err := decodeRows(encoded)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	for i := range rows {
+		if decoded[i]["status"] != rows[i]["status"] || decoded[i]["url"] != rows[i]["url"] {
+			t.Fatalf("row %d mismatch: got %v, want %v", i, decoded[i], rows[i])
+		}
+	}
+
+	a, b := decoded[0]["status"].(string), decoded[2]["status"].(string)
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("expected equal decoded strings to share data")
+	}
+}
+
+// TestTableMemoryUsage_InternedStrings verifies that repeated string values are
+// stored once and that the report reflects the sharing.
+func TestTableMemoryUsage_InternedStrings(t *testing.T) {
+	ds := NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "test",
+		Writable: true,
+	})
+	ctx := context.Background()
+	ds.Connect(ctx)
+
+	ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "orders",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INTEGER", Primary: true},
+			{Name: "status", Type: "VARCHAR"},
+		},
+	})
+	const n = 1000
+	rows := make([]domain.Row, n)
+	for i := range rows {
+		// Build each value separately so that equal strings start with distinct data
+		rows[i] = domain.Row{"id": int64(i + 1), "status": fmt.Sprintf("status-%d", i%3)}
+	}
+	if _, err := ds.Insert(ctx, "orders", rows, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if _, err := ds.Update(ctx, "orders", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}},
+		domain.Row{"status": fmt.Sprintf("status-%d", 2)}, nil); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	usages, err := ds.TableMemoryUsage(ctx, "orders")
+	if err != nil {
+		t.Fatalf("TableMemoryUsage failed: %v", err)
+	}
+	if len(usages) != 2 {
+		t.Fatalf("expected 2 columns, got %d", len(usages))
+	}
+	status := usages[1]
+	if status.Column != "status" || status.Values != n || status.DistinctValues != 3 {
+		t.Fatalf("unexpected status usage: %+v", status)
+	}
+	// 16-byte headers per value plus the data of three distinct strings
+	if want := int64(n*16 + 3*len("status-0")); status.MemoryBytes != want {
+		t.Errorf("expected %d memory bytes, got %d (logical %d)", want, status.MemoryBytes, status.LogicalBytes)
+	}
+	if status.LogicalBytes <= status.MemoryBytes {
+		t.Errorf("expected sharing to save memory: %+v", status)
+	}
+
+	if _, err := ds.TableMemoryUsage(ctx, "missing"); err == nil {
+		t.Error("expected error for unknown table")
+	}
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== Isolation Levels ====================
+//
+// REPEATABLE READ (the default) pins the table versions at BEGIN, so every
+// statement of the transaction reads the same snapshot.
+//
+// READ COMMITTED refreshes the read view at the start of each statement:
+// tables the transaction has not written are re-pinned to their latest
+// committed version. Tables it has already written keep the version its
+// changes are based on. READ UNCOMMITTED behaves like READ COMMITTED because
+// uncommitted changes are never shared between transactions.
+//
+// SERIALIZABLE reads like REPEATABLE READ and additionally turns plain reads
+// into shared locking reads, like InnoDB does inside a transaction.
+
+// Isolation levels as reported by MVCCTransaction.IsolationLevel
+const (
+	IsolationReadCommitted  = "READ COMMITTED"
+	IsolationRepeatableRead = "REPEATABLE READ"
+	IsolationSerializable   = "SERIALIZABLE"
+)
+
+// normalizeIsolation maps a requested isolation level to the level the
+// engine implements; unknown or empty levels use REPEATABLE READ
+func normalizeIsolation(level string) string {
+	switch strings.ToUpper(strings.NewReplacer("-", " ", "_", " ").Replace(strings.TrimSpace(level))) {
+	case "READ UNCOMMITTED", "READ COMMITTED":
+		return IsolationReadCommitted
+	case "SERIALIZABLE":
+		return IsolationSerializable
+	default:
+		return IsolationRepeatableRead
+	}
+}
+
+// refreshReadView re-pins the tables a READ COMMITTED transaction has not
+// written to their latest committed version
+func (m *MVCCDataSource) refreshReadView(txnID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, ok := m.snapshots[txnID]
+	if !ok {
+		return
+	}
+	for tableName, tableVer := range m.tables {
+		if snapshot.droppedTables[tableName] {
+			continue
+		}
+		tableVer.mu.RLock()
+		latest := tableVer.latest
+		tableVer.mu.RUnlock()
+
+		cowSnapshot, ok := snapshot.tableSnapshots[tableName]
+		if !ok {
+			snapshot.tableSnapshots[tableName] = &COWTableSnapshot{tableName: tableName, snapshotVer: latest}
+			continue
+		}
+		if cowSnapshot.snapshotVer == latest || cowSnapshot.hasChanges() {
+			continue
+		}
+		snapshot.tableSnapshots[tableName] = &COWTableSnapshot{tableName: tableName, snapshotVer: latest}
+	}
+	snapshot.startVer = m.currentVer
+}
+
+// hasChanges reports whether the transaction wrote to the table
+func (s *COWTableSnapshot) hasChanges() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.copied && (len(s.rowCopies) > 0 || len(s.deletedRows) > 0 || s.insertedCount > 0 || s.schemaChanged)
+}
+
+// IsolationLevel returns the isolation level the transaction runs with
+func (t *MVCCTransaction) IsolationLevel() string {
+	return t.isolation
+}
+
+// beginStatement prepares the read view of a new statement
+func (t *MVCCTransaction) beginStatement() {
+	if t.isolation == IsolationReadCommitted {
+		t.ds.refreshReadView(t.txnID)
+	}
+}
+
+// readLock returns the lock mode of a read: SERIALIZABLE turns plain reads
+// into shared locking reads
+func (t *MVCCTransaction) readLock(options *domain.QueryOptions) domain.LockMode {
+	if options != nil && options.Lock != domain.LockModeNone {
+		return options.Lock
+	}
+	if t.isolation == IsolationSerializable {
+		return domain.LockModeShared
+	}
+	return domain.LockModeNone
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func beginIsolationTxn(t *testing.T, ds *MVCCDataSource, level string) domain.Transaction {
+	t.Helper()
+	tx, err := ds.BeginTransaction(context.Background(), &domain.TransactionOptions{IsolationLevel: level})
+	if err != nil {
+		t.Fatalf("BeginTransaction() error = %v", err)
+	}
+	return tx
+}
+
+func countAccounts(t *testing.T, tx domain.Transaction) int {
+	t.Helper()
+	result, err := tx.Query(context.Background(), "accounts", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	return len(result.Rows)
+}
+
+// TestIsolation_ReadCommittedSeesNewCommits verifies that each statement of a
+// READ COMMITTED transaction sees changes committed after it began, while a
+// REPEATABLE READ transaction keeps its snapshot
+func TestIsolation_ReadCommittedSeesNewCommits(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	rc := beginIsolationTxn(t, ds, "READ-COMMITTED")
+	defer rc.Rollback(ctx)
+	rr := beginIsolationTxn(t, ds, "REPEATABLE READ")
+	defer rr.Rollback(ctx)
+
+	if got := rc.(*MVCCTransaction).IsolationLevel(); got != IsolationReadCommitted {
+		t.Errorf("IsolationLevel() = %q, want %q", got, IsolationReadCommitted)
+	}
+	if n := countAccounts(t, rc); n != 2 {
+		t.Fatalf("READ COMMITTED first read = %d rows, want 2", n)
+	}
+	if n := countAccounts(t, rr); n != 2 {
+		t.Fatalf("REPEATABLE READ first read = %d rows, want 2", n)
+	}
+
+	if _, err := ds.Insert(ctx, "accounts", []domain.Row{{"id": int64(3), "balance": int64(100)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	if n := countAccounts(t, rc); n != 3 {
+		t.Errorf("READ COMMITTED second read = %d rows, want 3", n)
+	}
+	if n := countAccounts(t, rr); n != 2 {
+		t.Errorf("REPEATABLE READ second read = %d rows, want 2", n)
+	}
+}
+
+// TestIsolation_ReadCommittedKeepsOwnWrites verifies that refreshing the read
+// view does not discard the transaction's own uncommitted changes
+func TestIsolation_ReadCommittedKeepsOwnWrites(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	rc := beginIsolationTxn(t, ds, "READ COMMITTED")
+	if _, err := rc.Insert(ctx, "accounts", []domain.Row{{"id": int64(10), "balance": int64(1)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if n := countAccounts(t, rc); n != 3 {
+		t.Fatalf("read after own insert = %d rows, want 3", n)
+	}
+	if err := rc.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	result, err := ds.Query(ctx, "accounts", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(result.Rows) != 3 {
+		t.Errorf("committed rows = %d, want 3", len(result.Rows))
+	}
+}
+
+// TestIsolation_SerializableReadsLock verifies that plain reads of a
+// SERIALIZABLE transaction take shared locks that block writers
+func TestIsolation_SerializableReadsLock(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	ser := beginIsolationTxn(t, ds, "SERIALIZABLE")
+	writer := beginIsolationTxn(t, ds, "")
+	defer writer.Rollback(ctx)
+
+	if n := countAccounts(t, ser); n != 2 {
+		t.Fatalf("SERIALIZABLE read = %d rows, want 2", n)
+	}
+
+	waitCtx := domain.WithLockWaitTimeout(ctx, 20*time.Millisecond)
+	_, err := writer.Update(waitCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"balance": int64(0)}, nil)
+	var timeoutErr *domain.ErrLockWaitTimeout
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected the update to wait for the shared lock, got %v", err)
+	}
+
+	if err := ser.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err := writer.Update(waitCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"balance": int64(0)}, nil); err != nil {
+		t.Fatalf("Update() after commit error = %v", err)
+	}
+}
@@ -0,0 +1,470 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== Lock Manager ====================
+//
+// MVCC snapshots never block readers, so conflicting writers are serialized
+// by an explicit lock manager on top of them. Tables carry intention locks
+// (IS/IX) taken before any row lock, and full table locks (S/X) taken by DDL
+// and by locking reads on tables without a primary key. Rows are identified
+// by their primary key value.
+//
+// A request that cannot be granted waits in FIFO order until the holders
+// release their locks, the wait timeout expires or the request closes a cycle
+// in the wait-for graph. On a deadlock the youngest transaction of the cycle
+// is chosen as the victim.
+
+type lockMode int
+
+const (
+	lockIS lockMode = iota
+	lockIX
+	lockS
+	lockX
+)
+
+// lockCompatible[held][requested] reports whether two modes can be held
+// concurrently by different owners
+var lockCompatible = [4][4]bool{
+	lockIS: {lockIS: true, lockIX: true, lockS: true},
+	lockIX: {lockIS: true, lockIX: true},
+	lockS:  {lockIS: true, lockS: true},
+	lockX:  {},
+}
+
+// covers reports whether holding held already grants want
+func (held lockMode) covers(want lockMode) bool {
+	switch held {
+	case lockX:
+		return true
+	case lockS:
+		return want == lockS || want == lockIS
+	case lockIX:
+		return want == lockIX || want == lockIS
+	default:
+		return want == lockIS
+	}
+}
+
+// combine returns the weakest mode granting both modes (S+IX is promoted to X)
+func combine(a, b lockMode) lockMode {
+	if a.covers(b) {
+		return a
+	}
+	if b.covers(a) {
+		return b
+	}
+	return lockX
+}
+
+// lockRequest is a pending lock request
+type lockRequest struct {
+	owner int64
+	mode  lockMode
+	ready chan struct{} // closed when granted or aborted
+	err   error         // set when aborted
+}
+
+// lockEntry is the lock state of a single resource
+type lockEntry struct {
+	holders map[int64]lockMode
+	waiters []*lockRequest
+}
+
+// compatible reports whether owner may hold mode alongside the other holders
+func (e *lockEntry) compatible(owner int64, mode lockMode) bool {
+	for holder, held := range e.holders {
+		if holder != owner && !lockCompatible[held][mode] {
+			return false
+		}
+	}
+	return true
+}
+
+// lockManager grants table and row locks to transactions
+type lockManager struct {
+	mu      sync.Mutex
+	entries map[string]*lockEntry
+	owned   map[int64]map[string]struct{}
+	waiting map[int64]*lockRequest // owner -> its pending request
+	waitRes map[int64]string       // owner -> resource it waits for
+	timeout time.Duration
+
+	// transient owner IDs for statements running outside a transaction;
+	// negative so they never collide with transaction IDs
+	transient int64
+}
+
+func newLockManager() *lockManager {
+	return &lockManager{
+		entries: make(map[string]*lockEntry),
+		owned:   make(map[int64]map[string]struct{}),
+		waiting: make(map[int64]*lockRequest),
+		waitRes: make(map[int64]string),
+		timeout: domain.DefaultLockWaitTimeout,
+	}
+}
+
+// SetLockWaitTimeout sets how long a lock request waits before failing with
+// a lock wait timeout error (innodb_lock_wait_timeout). A timeout carried by
+// the context (domain.WithLockWaitTimeout) takes precedence.
+func (m *MVCCDataSource) SetLockWaitTimeout(timeout time.Duration) {
+	m.locks.mu.Lock()
+	defer m.locks.mu.Unlock()
+	m.locks.timeout = timeout
+}
+
+// tableLockResource returns the lock resource name of a table
+func tableLockResource(tableName string) string {
+	return "table `" + tableName + "`"
+}
+
+// rowLockResource returns the lock resource name of a row
+func rowLockResource(tableName, key string) string {
+	return "row `" + tableName + "`(" + key + ")"
+}
+
+// nextTransientOwner returns a lock owner ID for a single statement
+func (lm *lockManager) nextTransientOwner() int64 {
+	return atomic.AddInt64(&lm.transient, -1)
+}
+
+// busy reports whether any lock is currently held
+func (lm *lockManager) busy() bool {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return len(lm.entries) > 0
+}
+
+// waitTimeout returns the lock wait timeout for ctx
+func (lm *lockManager) waitTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := domain.LockWaitTimeoutFromContext(ctx); ok {
+		return timeout
+	}
+	return lm.timeout
+}
+
+// acquire grants owner a lock on resource, waiting if necessary
+func (lm *lockManager) acquire(ctx context.Context, owner int64, resource string, mode lockMode) error {
+	lm.mu.Lock()
+	entry := lm.entries[resource]
+	if entry == nil {
+		entry = &lockEntry{holders: make(map[int64]lockMode)}
+		lm.entries[resource] = entry
+	}
+
+	held, holds := entry.holders[owner]
+	if holds && held.covers(mode) {
+		lm.mu.Unlock()
+		return nil
+	}
+	if holds {
+		mode = combine(held, mode)
+	}
+	// Lock upgrades may bypass the queue, new requests wait behind it
+	if entry.compatible(owner, mode) && (holds || len(entry.waiters) == 0) {
+		lm.grantLocked(entry, owner, resource, mode)
+		lm.mu.Unlock()
+		return nil
+	}
+
+	timeout := lm.waitTimeout(ctx)
+	if timeout <= 0 {
+		lm.cleanupLocked(resource, entry)
+		lm.mu.Unlock()
+		return domain.NewErrLockWaitTimeout(resource)
+	}
+
+	req := &lockRequest{owner: owner, mode: mode, ready: make(chan struct{})}
+	if holds {
+		entry.waiters = append([]*lockRequest{req}, entry.waiters...)
+	} else {
+		entry.waiters = append(entry.waiters, req)
+	}
+	lm.waiting[owner] = req
+	lm.waitRes[owner] = resource
+
+	if victim, ok := lm.findDeadlockVictimLocked(owner); ok {
+		if victim == owner {
+			lm.removeWaiterLocked(owner)
+			lm.mu.Unlock()
+			return domain.NewErrDeadlock(owner)
+		}
+		lm.abortWaiterLocked(victim, domain.NewErrDeadlock(victim))
+	}
+	lm.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-req.ready:
+		return req.err
+	case <-timer.C:
+		err = domain.NewErrLockWaitTimeout(resource)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	select {
+	case <-req.ready:
+		// Granted or aborted while the timeout fired
+		return req.err
+	default:
+	}
+	lm.removeWaiterLocked(owner)
+	return err
+}
+
+// release releases every lock held by owner
+func (lm *lockManager) release(owner int64) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if _, ok := lm.waiting[owner]; ok {
+		lm.abortWaiterLocked(owner, domain.NewErrTransactionNotFound(owner))
+	}
+	for resource := range lm.owned[owner] {
+		entry := lm.entries[resource]
+		if entry == nil {
+			continue
+		}
+		delete(entry.holders, owner)
+		lm.promoteLocked(resource, entry)
+	}
+	delete(lm.owned, owner)
+}
+
+func (lm *lockManager) grantLocked(entry *lockEntry, owner int64, resource string, mode lockMode) {
+	entry.holders[owner] = mode
+	owned := lm.owned[owner]
+	if owned == nil {
+		owned = make(map[string]struct{})
+		lm.owned[owner] = owned
+	}
+	owned[resource] = struct{}{}
+}
+
+// promoteLocked grants queued requests in FIFO order until one conflicts
+func (lm *lockManager) promoteLocked(resource string, entry *lockEntry) {
+	for len(entry.waiters) > 0 {
+		req := entry.waiters[0]
+		if !entry.compatible(req.owner, req.mode) {
+			break
+		}
+		entry.waiters = entry.waiters[1:]
+		delete(lm.waiting, req.owner)
+		delete(lm.waitRes, req.owner)
+		lm.grantLocked(entry, req.owner, resource, req.mode)
+		close(req.ready)
+	}
+	lm.cleanupLocked(resource, entry)
+}
+
+// cleanupLocked forgets a resource nobody holds or waits for
+func (lm *lockManager) cleanupLocked(resource string, entry *lockEntry) {
+	if len(entry.holders) == 0 && len(entry.waiters) == 0 {
+		delete(lm.entries, resource)
+	}
+}
+
+// removeWaiterLocked withdraws the pending request of owner
+func (lm *lockManager) removeWaiterLocked(owner int64) *lockRequest {
+	req, ok := lm.waiting[owner]
+	if !ok {
+		return nil
+	}
+	resource := lm.waitRes[owner]
+	delete(lm.waiting, owner)
+	delete(lm.waitRes, owner)
+
+	entry := lm.entries[resource]
+	if entry == nil {
+		return req
+	}
+	for i, w := range entry.waiters {
+		if w == req {
+			entry.waiters = append(entry.waiters[:i], entry.waiters[i+1:]...)
+			break
+		}
+	}
+	// Requests queued behind the withdrawn one may now be grantable
+	lm.promoteLocked(resource, entry)
+	return req
+}
+
+// abortWaiterLocked fails the pending request of owner with err
+func (lm *lockManager) abortWaiterLocked(owner int64, err error) {
+	if req := lm.removeWaiterLocked(owner); req != nil {
+		req.err = err
+		close(req.ready)
+	}
+}
+
+// blockersLocked returns the owners that owner is waiting for: incompatible
+// holders of the resource and incompatible requests queued ahead of it
+func (lm *lockManager) blockersLocked(owner int64) []int64 {
+	req, ok := lm.waiting[owner]
+	if !ok {
+		return nil
+	}
+	entry := lm.entries[lm.waitRes[owner]]
+	if entry == nil {
+		return nil
+	}
+	var blockers []int64
+	for holder, held := range entry.holders {
+		if holder != owner && !lockCompatible[held][req.mode] {
+			blockers = append(blockers, holder)
+		}
+	}
+	for _, w := range entry.waiters {
+		if w == req {
+			break
+		}
+		if w.owner != owner && (!lockCompatible[w.mode][req.mode] || !lockCompatible[req.mode][w.mode]) {
+			blockers = append(blockers, w.owner)
+		}
+	}
+	return blockers
+}
+
+// findDeadlockVictimLocked searches the wait-for graph for a cycle through
+// start and returns the youngest (highest ID) transaction on it
+func (lm *lockManager) findDeadlockVictimLocked(start int64) (int64, bool) {
+	visited := make(map[int64]bool)
+	var path []int64
+
+	var visit func(owner int64) bool
+	visit = func(owner int64) bool {
+		path = append(path, owner)
+		for _, next := range lm.blockersLocked(owner) {
+			if next == start {
+				return true
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if visit(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	visited[start] = true
+	if !visit(start) {
+		return 0, false
+	}
+	victim := path[0]
+	for _, owner := range path[1:] {
+		if owner > victim {
+			victim = owner
+		}
+	}
+	return victim, true
+}
+
+// ==================== Locking Operations ====================
+
+// lockTable takes a table-level lock for owner
+func (m *MVCCDataSource) lockTable(ctx context.Context, owner int64, tableName string, exclusive bool) error {
+	mode := lockS
+	if exclusive {
+		mode = lockX
+	}
+	return m.locks.acquire(ctx, owner, tableLockResource(tableName), mode)
+}
+
+// lockRows takes an intention lock on the table and a row lock on every row
+// matching filters. Tables without a primary key are locked as a whole.
+func (m *MVCCDataSource) lockRows(ctx context.Context, owner int64, tableName string, filters []domain.Filter, exclusive bool) error {
+	intention, rowMode := lockIS, lockS
+	if exclusive {
+		intention, rowMode = lockIX, lockX
+	}
+	if err := m.locks.acquire(ctx, owner, tableLockResource(tableName), intention); err != nil {
+		return err
+	}
+
+	schema, err := m.GetTableInfo(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	pk := primaryKeyColumns(schema)
+	if len(pk) == 0 {
+		return m.lockTable(ctx, owner, tableName, exclusive)
+	}
+
+	result, err := m.Query(ctx, tableName, &domain.QueryOptions{Filters: filters, SelectAll: true})
+	if err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		if err := m.locks.acquire(ctx, owner, rowLockResource(tableName, rowLockKey(row, pk)), rowMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockInsertedRows takes an intention lock on the table and exclusive locks
+// on the primary keys of the rows about to be inserted
+func (m *MVCCDataSource) lockInsertedRows(ctx context.Context, owner int64, tableName string, rows []domain.Row) error {
+	if err := m.locks.acquire(ctx, owner, tableLockResource(tableName), lockIX); err != nil {
+		return err
+	}
+	schema, err := m.GetTableInfo(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	pk := primaryKeyColumns(schema)
+	if len(pk) == 0 {
+		return nil
+	}
+	for _, row := range rows {
+		if _, ok := row[pk[0]]; !ok {
+			// Auto-increment keys are assigned later and cannot conflict
+			continue
+		}
+		if err := m.locks.acquire(ctx, owner, rowLockResource(tableName, rowLockKey(row, pk)), lockX); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// primaryKeyColumns returns the primary key columns of a table
+func primaryKeyColumns(schema *domain.TableInfo) []string {
+	var pk []string
+	for _, col := range schema.Columns {
+		if col.Primary {
+			pk = append(pk, col.Name)
+		}
+	}
+	return pk
+}
+
+// rowLockKey formats the primary key of a row
+func rowLockKey(row domain.Row, pk []string) string {
+	parts := make([]string, len(pk))
+	for i, col := range pk {
+		parts[i] = fmt.Sprintf("%v", row[col])
+	}
+	return strings.Join(parts, ",")
+}
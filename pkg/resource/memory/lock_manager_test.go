@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newLockTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := NewMVCCDataSource(nil)
+	ctx := context.Background()
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { ds.Close(ctx) })
+	ds.SetLockWaitTimeout(2 * time.Second)
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "accounts",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+			{Name: "balance", Type: "INT"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if _, err := ds.Insert(ctx, "accounts", []domain.Row{
+		{"id": int64(1), "balance": int64(100)},
+		{"id": int64(2), "balance": int64(100)},
+	}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return ds
+}
+
+func beginLockTxn(t *testing.T, ds *MVCCDataSource) domain.Transaction {
+	t.Helper()
+	tx, err := ds.BeginTransaction(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTransaction() error = %v", err)
+	}
+	return tx
+}
+
+func selectForUpdate(tx domain.Transaction, ctx context.Context, id int64, mode domain.LockMode) error {
+	_, err := tx.Query(ctx, "accounts", &domain.QueryOptions{
+		Filters: []domain.Filter{{Field: "id", Operator: "=", Value: id}},
+		Lock:    mode,
+	})
+	return err
+}
+
+// TestLock_ForUpdateBlocksUntilCommit verifies that a second locking read
+// waits for the first transaction to release its row lock
+func TestLock_ForUpdateBlocksUntilCommit(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	tx1 := beginLockTxn(t, ds)
+	tx2 := beginLockTxn(t, ds)
+	defer tx2.Rollback(ctx)
+
+	if err := selectForUpdate(tx1, ctx, 1, domain.LockModeUpdate); err != nil {
+		t.Fatalf("tx1 FOR UPDATE error = %v", err)
+	}
+	// Other rows stay available
+	if err := selectForUpdate(tx2, ctx, 2, domain.LockModeUpdate); err != nil {
+		t.Fatalf("tx2 FOR UPDATE on another row error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- selectForUpdate(tx2, ctx, 1, domain.LockModeUpdate) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("tx2 acquired a locked row, err = %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := tx1.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("tx2 FOR UPDATE after commit error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tx2 was not granted the lock after tx1 committed")
+	}
+}
+
+// TestLock_SharedAndTimeout verifies shared locks are compatible with each
+// other and that a conflicting request fails after the wait timeout
+func TestLock_SharedAndTimeout(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	tx1 := beginLockTxn(t, ds)
+	tx2 := beginLockTxn(t, ds)
+	defer tx1.Rollback(ctx)
+	defer tx2.Rollback(ctx)
+
+	if err := selectForUpdate(tx1, ctx, 1, domain.LockModeShared); err != nil {
+		t.Fatalf("tx1 LOCK IN SHARE MODE error = %v", err)
+	}
+	if err := selectForUpdate(tx2, ctx, 1, domain.LockModeShared); err != nil {
+		t.Fatalf("tx2 LOCK IN SHARE MODE error = %v", err)
+	}
+
+	start := time.Now()
+	waitCtx := domain.WithLockWaitTimeout(ctx, 30*time.Millisecond)
+	_, err := tx2.Update(waitCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"balance": int64(0)}, nil)
+	var timeoutErr *domain.ErrLockWaitTimeout
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected lock wait timeout, got %v", err)
+	}
+	if time.Since(start) < 30*time.Millisecond {
+		t.Error("request did not wait for the timeout")
+	}
+
+	// NOWAIT fails immediately
+	err = selectForUpdate(tx2, domain.WithLockWaitTimeout(ctx, 0), 1, domain.LockModeUpdate)
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected immediate lock failure, got %v", err)
+	}
+}
+
+// TestLock_Deadlock verifies that a deadlock is detected, the youngest
+// transaction is rolled back and the other one proceeds
+func TestLock_Deadlock(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	older := beginLockTxn(t, ds)
+	younger := beginLockTxn(t, ds)
+
+	if err := selectForUpdate(older, ctx, 1, domain.LockModeUpdate); err != nil {
+		t.Fatalf("older FOR UPDATE error = %v", err)
+	}
+	if err := selectForUpdate(younger, ctx, 2, domain.LockModeUpdate); err != nil {
+		t.Fatalf("younger FOR UPDATE error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- selectForUpdate(older, ctx, 2, domain.LockModeUpdate) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// The younger transaction closes the cycle and is chosen as the victim
+	err := selectForUpdate(younger, ctx, 1, domain.LockModeUpdate)
+	if !domain.IsDeadlock(err) {
+		t.Fatalf("expected deadlock, got %v", err)
+	}
+	if err := younger.Commit(ctx); err == nil {
+		t.Error("deadlock victim was not rolled back")
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("older FOR UPDATE after deadlock error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("older transaction still waiting after the victim was rolled back")
+	}
+	if err := older.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+}
+
+// TestLock_DeadlockVictimWaiting verifies that a waiting transaction is
+// aborted when an older transaction closes the cycle
+func TestLock_DeadlockVictimWaiting(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	older := beginLockTxn(t, ds)
+	younger := beginLockTxn(t, ds)
+	defer older.Rollback(ctx)
+
+	if err := selectForUpdate(older, ctx, 1, domain.LockModeUpdate); err != nil {
+		t.Fatalf("older FOR UPDATE error = %v", err)
+	}
+	if err := selectForUpdate(younger, ctx, 2, domain.LockModeUpdate); err != nil {
+		t.Fatalf("younger FOR UPDATE error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- selectForUpdate(younger, ctx, 1, domain.LockModeUpdate) }()
+	time.Sleep(20 * time.Millisecond)
+
+	if err := selectForUpdate(older, ctx, 2, domain.LockModeUpdate); err != nil {
+		t.Fatalf("older FOR UPDATE error = %v", err)
+	}
+	if err := <-done; !domain.IsDeadlock(err) {
+		t.Fatalf("expected younger to be the deadlock victim, got %v", err)
+	}
+}
+
+// TestLock_DDLWaitsForTransactions verifies that DDL and writes outside a
+// transaction wait for the locks held by transactions
+func TestLock_DDLWaitsForTransactions(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	tx := beginLockTxn(t, ds)
+	if err := selectForUpdate(tx, ctx, 1, domain.LockModeUpdate); err != nil {
+		t.Fatalf("FOR UPDATE error = %v", err)
+	}
+
+	waitCtx := domain.WithLockWaitTimeout(ctx, 20*time.Millisecond)
+	var timeoutErr *domain.ErrLockWaitTimeout
+	if err := ds.TruncateTable(waitCtx, "accounts"); !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected TRUNCATE to time out, got %v", err)
+	}
+	if _, err := ds.Delete(waitCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, nil); !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected DELETE of a locked row to time out, got %v", err)
+	}
+	if _, err := ds.Delete(waitCtx, "accounts", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, nil); err != nil {
+		t.Fatalf("DELETE of an unlocked row error = %v", err)
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if err := ds.TruncateTable(waitCtx, "accounts"); err != nil {
+		t.Fatalf("TRUNCATE after rollback error = %v", err)
+	}
+	if ds.locks.busy() {
+		t.Error("locks still held after all transactions ended")
+	}
+}
@@ -21,10 +21,19 @@ func (m *MVCCDataSource) Insert(ctx context.Context, tableName string, rows []do
 
 	txnID, hasTxn := GetTransactionID(ctx)
 
+	// Outside a transaction, wait for rows locked by transactions
+	if !hasTxn && m.locks.busy() {
+		owner := m.locks.nextTransientOwner()
+		defer m.locks.release(owner)
+		if err := m.lockInsertedRows(ctx, owner, tableName, rows); err != nil {
+			return 0, err
+		}
+	}
+
 	// Get global lock first
 	m.mu.Lock()
 
-	tableVer, ok := m.tables[tableName]
+	tableVer, ok := m.tableLocked(ctx, tableName)
 	if !ok {
 		m.mu.Unlock()
 		return 0, domain.NewErrTableNotFound(tableName)
@@ -37,80 +46,24 @@ func (m *MVCCDataSource) Insert(ctx context.Context, tableName string, rows []do
 	tableVer.mu.RUnlock()
 
 	// Process auto-increment columns and fill in generated IDs
-	// Note: lastInsertID is tracked but not returned via the interface (interface only returns rowsAffected)
 	// The auto-incremented ID is set in the row map, so callers can read it from there
-	for _, row := range rows {
-		// Convert types based on schema (e.g., int64(0/1) to bool for BOOL columns)
-		convertRowTypesBasedOnSchema(row, schema)
+	m.assignAutoIncrement(tableName, schema, rows)
 
-		// Handle auto-increment columns
-		for _, col := range schema.Columns {
-			if col.AutoIncrement {
-				key := tableName + "." + col.Name
-				// Check if the value is missing or is 0/null
-				if val, exists := row[col.Name]; !exists || val == nil || val == int64(0) || val == float64(0) {
-					// Generate next auto-increment ID
-					m.autoIncCounters[key]++
-					nextID := m.autoIncCounters[key]
-					row[col.Name] = nextID
-				} else {
-					// Value was provided, update counter if needed
-					if intVal, ok := val.(int64); ok && intVal > m.autoIncCounters[key] {
-						m.autoIncCounters[key] = intVal
-					} else if floatVal, ok := val.(float64); ok && int64(floatVal) > m.autoIncCounters[key] {
-						m.autoIncCounters[key] = int64(floatVal)
-					}
-				}
-			}
-		}
+	// Partitioned table: auto-increment values come from the parent, rows are stored in partitions
+	if schema.IsPartitioned() {
+		m.mu.Unlock()
+		return m.insertPartitions(ctx, schema, rows, options)
 	}
 
-	// Process generated columns: distinguish between STORED and VIRTUAL types
-	processedRows := make([]domain.Row, 0, len(rows))
-	evaluator := generated.NewGeneratedColumnEvaluator()
-
-	for _, row := range rows {
-		// 1. Filter explicit insert values for generated columns (not allowed)
-		filteredRow := generated.FilterGeneratedColumns(row, schema)
-
-		// 2. Distinguish between STORED and VIRTUAL columns
-		// STORED columns: calculate and store
-		// VIRTUAL columns: don't store in table data
-		var storedRow domain.Row
-
-		// Check if table contains VIRTUAL columns
-		hasVirtualCols := false
-		for _, col := range schema.Columns {
-			if col.IsGenerated && col.GeneratedType == "VIRTUAL" {
-				hasVirtualCols = true
-				break
-			}
-		}
-
-		if hasVirtualCols {
-			// Calculate all STORED generated columns (not including VIRTUAL)
-			computedRow, err := evaluator.EvaluateAll(filteredRow, schema)
-			if err != nil {
-				computedRow = generated.SetGeneratedColumnsToNULL(filteredRow, schema)
-			}
-
-			// Remove VIRTUAL columns (don't store, only keep base + STORED generated)
-			storedRow = m.removeVirtualColumns(computedRow, schema)
-		} else {
-			// No VIRTUAL columns, keep original logic
-			computedRow, err := evaluator.EvaluateAll(filteredRow, schema)
-			if err != nil {
-				computedRow = generated.SetGeneratedColumnsToNULL(filteredRow, schema)
-			}
-			storedRow = computedRow
-		}
+	// Process generated columns: STORED columns are calculated, VIRTUAL columns are not stored
+	rows = m.storedRows(schema, rows)
 
-		processedRows = append(processedRows, storedRow)
+	// Encrypt ENCRYPTED columns and fill in their blind indexes
+	if err := m.encryptRows(ctx, schema, rows); err != nil {
+		m.mu.Unlock()
+		return 0, err
 	}
 
-	// Replace original rows with processed rows
-	rows = processedRows
-
 	if hasTxn {
 		// In transaction, use COW snapshot
 		snapshot, ok := m.snapshots[txnID]
@@ -159,6 +112,9 @@ func (m *MVCCDataSource) Insert(ctx context.Context, tableName string, rows []do
 	m.currentVer++
 	newVer := m.currentVer // Capture before releasing global lock
 
+	// Deliver the recorded changes once the table lock is released
+	defer m.changes.flush()
+
 	// Get table-level lock
 	tableVer.mu.Lock()
 
@@ -199,9 +155,71 @@ func (m *MVCCDataSource) Insert(ctx context.Context, tableName string, rows []do
 	// Maintain indexes: rebuild from the new version's rows
 	m.rebuildTableIndexes(tableName, versionData.schema, newRows)
 
+	if m.changes.active() {
+		m.changes.record(insertChanges(tableName, 0, newRows[len(existingRows):]))
+	}
+
 	return int64(len(rows)), nil
 }
 
+// assignAutoIncrement converts row types based on the schema and fills in missing
+// auto-increment values in place. Must be called with m.mu held.
+func (m *MVCCDataSource) assignAutoIncrement(tableName string, schema *domain.TableInfo, rows []domain.Row) {
+	for _, row := range rows {
+		// Convert types based on schema (e.g., int64(0/1) to bool for BOOL columns)
+		convertRowTypesBasedOnSchema(row, schema)
+
+		// Handle auto-increment columns
+		for _, col := range schema.Columns {
+			if col.AutoIncrement {
+				key := tableName + "." + col.Name
+				// Check if the value is missing or is 0/null
+				if val, exists := row[col.Name]; !exists || val == nil || val == int64(0) || val == float64(0) {
+					// Generate next auto-increment ID
+					m.autoIncCounters[key]++
+					nextID := m.autoIncCounters[key]
+					row[col.Name] = nextID
+				} else {
+					// Value was provided, update counter if needed
+					if intVal, ok := val.(int64); ok && intVal > m.autoIncCounters[key] {
+						m.autoIncCounters[key] = intVal
+					} else if floatVal, ok := val.(float64); ok && int64(floatVal) > m.autoIncCounters[key] {
+						m.autoIncCounters[key] = int64(floatVal)
+					}
+				}
+			}
+		}
+	}
+}
+
+// storedRows returns the rows as stored: explicit values for generated columns are
+// dropped, STORED generated columns are calculated and VIRTUAL columns are removed
+func (m *MVCCDataSource) storedRows(schema *domain.TableInfo, rows []domain.Row) []domain.Row {
+	hasVirtualCols := false
+	for _, col := range schema.Columns {
+		if col.IsGenerated && col.GeneratedType == "VIRTUAL" {
+			hasVirtualCols = true
+			break
+		}
+	}
+
+	processedRows := make([]domain.Row, 0, len(rows))
+	evaluator := generated.NewGeneratedColumnEvaluator()
+	for _, row := range rows {
+		filteredRow := generated.FilterGeneratedColumns(row, schema)
+		computedRow, err := evaluator.EvaluateAll(filteredRow, schema)
+		if err != nil {
+			computedRow = generated.SetGeneratedColumnsToNULL(filteredRow, schema)
+		}
+		if hasVirtualCols {
+			computedRow = m.removeVirtualColumns(computedRow, schema)
+		}
+		internRow(computedRow)
+		processedRows = append(processedRows, computedRow)
+	}
+	return processedRows
+}
+
 // BulkLoad creates a new version for the table and populates it incrementally.
 // The loadFn receives an addPage callback; each call to addPage creates one RowPage
 // and registers it with the buffer pool. This avoids holding all rows in memory at once,
@@ -230,7 +248,11 @@ func (m *MVCCDataSource) BulkLoad(tableName string, loadFn func(addPage func(row
 
 	pr := NewPagedRowsBuilder(m.bufferPool, 0, tableName, newVer)
 
-	if err := loadFn(pr.AppendPage); err != nil {
+	addPage := func(rows []domain.Row) {
+		internRows(rows)
+		pr.AppendPage(rows)
+	}
+	if err := loadFn(addPage); err != nil {
 		pr.Release()
 		return err
 	}
@@ -267,12 +289,30 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 		return 0, domain.NewErrReadOnly(string(m.config.Type), "update")
 	}
 
+	// Conditions and new values of ENCRYPTED columns are compared and stored encrypted
+	filters, err := m.encryptFilters(ctx, tableName, filters)
+	if err != nil {
+		return 0, err
+	}
+	if updates, err = m.encryptUpdates(ctx, tableName, updates); err != nil {
+		return 0, err
+	}
+
 	txnID, hasTxn := GetTransactionID(ctx)
 
+	// Outside a transaction, wait for rows locked by transactions
+	if !hasTxn && m.locks.busy() {
+		owner := m.locks.nextTransientOwner()
+		defer m.locks.release(owner)
+		if err := m.lockRows(ctx, owner, tableName, filters, true); err != nil {
+			return 0, err
+		}
+	}
+
 	// Get global lock first
 	m.mu.Lock()
 
-	tableVer, ok := m.tables[tableName]
+	tableVer, ok := m.tableLocked(ctx, tableName)
 	if !ok {
 		m.mu.Unlock()
 		return 0, domain.NewErrTableNotFound(tableName)
@@ -283,8 +323,14 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 	schema := deepCopySchema(tableVer.versions[tableVer.latest].schema)
 	tableVer.mu.RUnlock()
 
+	if schema.IsPartitioned() {
+		m.mu.Unlock()
+		return m.updatePartitions(ctx, schema, filters, updates, options)
+	}
+
 	// Filter generated column update values (explicit update not allowed)
 	filteredUpdates := generated.FilterGeneratedColumns(updates, schema)
+	internRow(filteredUpdates)
 
 	// Get affected generated columns (recursive)
 	updatedCols := make([]string, 0, len(filteredUpdates))
@@ -352,16 +398,7 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 					rowCopy[k] = v
 				}
 				// Calculate affected generated columns
-				for _, genColName := range affectedGeneratedCols {
-					colInfo := getColumnInfo(genColName, schema)
-					if colInfo != nil && colInfo.IsGenerated {
-						val, err := evaluator.Evaluate(colInfo.GeneratedExpr, rowCopy, schema)
-						if err != nil {
-							val = nil
-						}
-						rowCopy[genColName] = val
-					}
-				}
+				applyGeneratedColumns(evaluator, rowCopy, affectedGeneratedCols, schema)
 				cowSnapshot.rowCopies[rowID] = rowCopy
 				cowSnapshot.rowLocks[rowID] = true
 			} else {
@@ -370,16 +407,7 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 					for k, v := range filteredUpdates {
 						existingRow[k] = v
 					}
-					for _, genColName := range affectedGeneratedCols {
-						colInfo := getColumnInfo(genColName, schema)
-						if colInfo != nil && colInfo.IsGenerated {
-							val, err := evaluator.Evaluate(colInfo.GeneratedExpr, existingRow, schema)
-							if err != nil {
-								val = nil
-							}
-							existingRow[genColName] = val
-						}
-					}
+					applyGeneratedColumns(evaluator, existingRow, affectedGeneratedCols, schema)
 				}
 			}
 			updated++
@@ -404,6 +432,7 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 	// Non-transaction mode: increment version while holding global lock to avoid race
 	m.currentVer++
 	newVer := m.currentVer
+	defer m.changes.flush()
 	tableVer.mu.Lock()
 	m.mu.Unlock()
 	defer tableVer.mu.Unlock()
@@ -424,6 +453,8 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 		newRows[i] = deepCopyRow(row)
 	}
 
+	var changes []domain.ChangeEvent
+	recordChanges := m.changes.active()
 	updated := int64(0)
 	for i, row := range newRows {
 		if util.MatchesFilters(row, filters) {
@@ -432,17 +463,11 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 				row[k] = v
 			}
 			// Calculate affected generated columns
-			for _, genColName := range affectedGeneratedCols {
-				colInfo := getColumnInfo(genColName, schema)
-				if colInfo != nil && colInfo.IsGenerated {
-					val, err := evaluator.Evaluate(colInfo.GeneratedExpr, newRows[i], schema)
-					if err != nil {
-						val = nil // Calculation failed, set to NULL
-					}
-					newRows[i][genColName] = val
-				}
-			}
+			applyGeneratedColumns(evaluator, newRows[i], affectedGeneratedCols, schema)
 			updated++
+			if recordChanges {
+				changes = append(changes, domain.ChangeEvent{Table: tableName, Type: domain.ChangeUpdate, Before: srcRows[i], After: row})
+			}
 		}
 	}
 
@@ -455,10 +480,13 @@ func (m *MVCCDataSource) Update(ctx context.Context, tableName string, filters [
 
 	tableVer.versions[newVer] = versionData
 	tableVer.latest = newVer
+	tableVer.churn += updated
 
 	// Maintain indexes: rebuild from the new version's rows
 	m.rebuildTableIndexes(tableName, versionData.schema, newRows)
 
+	m.changes.record(changes)
+
 	return updated, nil
 }
 
@@ -468,17 +496,40 @@ func (m *MVCCDataSource) Delete(ctx context.Context, tableName string, filters [
 		return 0, domain.NewErrReadOnly(string(m.config.Type), "delete")
 	}
 
+	// Conditions on ENCRYPTED columns compare the stored ciphertexts or blind indexes
+	filters, err := m.encryptFilters(ctx, tableName, filters)
+	if err != nil {
+		return 0, err
+	}
+
 	txnID, hasTxn := GetTransactionID(ctx)
 
+	// Outside a transaction, wait for rows locked by transactions
+	if !hasTxn && m.locks.busy() {
+		owner := m.locks.nextTransientOwner()
+		defer m.locks.release(owner)
+		if err := m.lockRows(ctx, owner, tableName, filters, true); err != nil {
+			return 0, err
+		}
+	}
+
 	// Get global lock first
 	m.mu.Lock()
 
-	tableVer, ok := m.tables[tableName]
+	tableVer, ok := m.tableLocked(ctx, tableName)
 	if !ok {
 		m.mu.Unlock()
 		return 0, domain.NewErrTableNotFound(tableName)
 	}
 
+	tableVer.mu.RLock()
+	latestSchema := tableVer.versions[tableVer.latest].schema
+	tableVer.mu.RUnlock()
+	if latestSchema.IsPartitioned() {
+		m.mu.Unlock()
+		return m.deletePartitions(ctx, latestSchema, filters, options)
+	}
+
 	if hasTxn {
 		// In transaction, use COW snapshot
 		snapshot, ok := m.snapshots[txnID]
@@ -552,6 +603,7 @@ func (m *MVCCDataSource) Delete(ctx context.Context, tableName string, filters [
 	// Non-transaction mode: increment version while holding global lock to avoid race
 	m.currentVer++
 	newVer := m.currentVer
+	defer m.changes.flush()
 	tableVer.mu.Lock()
 	m.mu.Unlock()
 	defer tableVer.mu.Unlock()
@@ -566,12 +618,17 @@ func (m *MVCCDataSource) Delete(ctx context.Context, tableName string, filters [
 	delSrcRows := latestData.Rows()
 	newRows := make([]domain.Row, 0, len(delSrcRows))
 
+	var changes []domain.ChangeEvent
+	recordChanges := m.changes.active()
 	deleted := int64(0)
 	for _, row := range delSrcRows {
 		if !util.MatchesFilters(row, filters) {
 			newRows = append(newRows, deepCopyRow(row))
 		} else {
 			deleted++
+			if recordChanges {
+				changes = append(changes, domain.ChangeEvent{Table: tableName, Type: domain.ChangeDelete, Before: row})
+			}
 		}
 	}
 
@@ -584,10 +641,13 @@ func (m *MVCCDataSource) Delete(ctx context.Context, tableName string, filters [
 
 	tableVer.versions[newVer] = versionData
 	tableVer.latest = newVer
+	tableVer.churn += deleted
 
 	// Maintain indexes: rebuild from the new version's rows
 	m.rebuildTableIndexes(tableName, versionData.schema, newRows)
 
+	m.changes.record(changes)
+
 	return deleted, nil
 }
 
@@ -609,6 +669,35 @@ func (m *MVCCDataSource) removeVirtualColumns(row domain.Row, schema *domain.Tab
 	return result
 }
 
+// applyGeneratedColumns recalculates the given generated columns (in evaluation order) on row.
+// VIRTUAL columns are computed only so that STORED columns depending on them see their value,
+// and are removed afterwards since they are never stored.
+func applyGeneratedColumns(evaluator *generated.GeneratedColumnEvaluator, row domain.Row, cols []string, schema *domain.TableInfo) {
+	hasVirtual := false
+	for _, genColName := range cols {
+		colInfo := getColumnInfo(genColName, schema)
+		if colInfo == nil || !colInfo.IsGenerated {
+			continue
+		}
+		val, err := evaluator.EvaluateColumn(colInfo, row, schema)
+		if err != nil {
+			val = nil // Calculation failed, set to NULL
+		}
+		row[genColName] = val
+		if colInfo.GeneratedType == "VIRTUAL" {
+			hasVirtual = true
+		}
+	}
+	if !hasVirtual {
+		return
+	}
+	for _, genColName := range cols {
+		if generated.IsVirtualColumn(genColName, schema) {
+			delete(row, genColName)
+		}
+	}
+}
+
 // rebuildTableIndexes rebuilds all indexes for a table from the given rows.
 // This is called after each non-transaction mutation to keep indexes in sync.
 func (m *MVCCDataSource) rebuildTableIndexes(tableName string, schema *domain.TableInfo, rows []domain.Row) {
@@ -665,6 +754,11 @@ func (m *MVCCDataSource) checkUniqueConstraints(tableName string, schema *domain
 			if err != nil {
 				continue
 			}
+			// Functional index keys live in a hidden column; report the index name instead
+			keyName := colName
+			if idxInfo.Expression != "" {
+				keyName = idxInfo.Name
+			}
 			newSeen := make(map[string]bool, len(newRows))
 			for _, row := range newRows {
 				val, ok := row[colName]
@@ -672,11 +766,11 @@ func (m *MVCCDataSource) checkUniqueConstraints(tableName string, schema *domain
 					continue
 				}
 				if _, exists := idx.Find(val); exists {
-					return fmt.Errorf("Duplicate entry '%v' for key '%s'", val, colName)
+					return fmt.Errorf("Duplicate entry '%v' for key '%s'", val, keyName)
 				}
 				key := fmt.Sprintf("%v", val)
 				if newSeen[key] {
-					return fmt.Errorf("Duplicate entry '%v' for key '%s'", val, colName)
+					return fmt.Errorf("Duplicate entry '%v' for key '%s'", val, keyName)
 				}
 				newSeen[key] = true
 			}
@@ -1,9 +1,13 @@
 package memory
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 )
 
 // ==================== MVCC Data Source Implementation ====================
@@ -36,6 +40,25 @@ type MVCCDataSource struct {
 
 	// Auto-increment counters: tableName.columnName -> next value
 	autoIncCounters map[string]int64
+
+	// Online compaction progress and background job
+	compactor *compactor
+
+	// Table and row locks (SELECT ... FOR UPDATE, DML and DDL)
+	locks *lockManager
+
+	// Committed row changes and their subscribers
+	changes *changeLog
+
+	// History retention per table ("" is the default for all tables)
+	historyRetention map[string]time.Duration
+
+	// Dropped tables kept for UNDROP TABLE, oldest first
+	recycleBin       []*recycledTable
+	recycleRetention time.Duration
+
+	// Cipher of ENCRYPTED columns, nil until keys are configured
+	cipher atomic.Pointer[security.ColumnCipher]
 }
 
 // maxRetainedVersions is the maximum number of old versions to keep per table
@@ -61,18 +84,22 @@ func NewMVCCDataSource(config *domain.DataSourceConfig, opts ...*PagingConfig) *
 
 	indexMgr := NewIndexManager()
 	return &MVCCDataSource{
-		config:          config,
-		connected:       false,
-		indexManager:    indexMgr,
-		queryPlanner:    NewQueryPlanner(indexMgr),
-		bufferPool:      NewBufferPool(pagingCfg),
-		nextTxID:        1,
-		currentVer:      0,
-		snapshots:       make(map[int64]*Snapshot),
-		activeTxns:      make(map[int64]*Transaction),
-		tables:          make(map[string]*TableVersions),
-		tempTables:      make(map[string]bool),
-		autoIncCounters: make(map[string]int64),
+		config:           config,
+		connected:        false,
+		indexManager:     indexMgr,
+		queryPlanner:     NewQueryPlanner(indexMgr),
+		bufferPool:       NewBufferPool(pagingCfg),
+		nextTxID:         1,
+		currentVer:       0,
+		snapshots:        make(map[int64]*Snapshot),
+		activeTxns:       make(map[int64]*Transaction),
+		tables:           make(map[string]*TableVersions),
+		tempTables:       make(map[string]bool),
+		autoIncCounters:  make(map[string]int64),
+		compactor:        newCompactor(),
+		locks:            newLockManager(),
+		changes:          newChangeLog(),
+		historyRetention: make(map[string]time.Duration),
 	}
 }
 
@@ -82,8 +109,9 @@ func (m *MVCCDataSource) GetBufferPool() *BufferPool {
 }
 
 // gcOldVersions removes old table versions that are no longer referenced by
-// any active transaction. Must be called while holding m.mu.Lock().
-func (m *MVCCDataSource) gcOldVersions() {
+// any active transaction or kept as history, and returns the number of
+// removed versions. Must be called while holding m.mu.Lock().
+func (m *MVCCDataSource) gcOldVersions() int64 {
 	// Find the minimum version still needed by active transactions
 	minRequiredVer := m.currentVer
 	for _, snapshot := range m.snapshots {
@@ -92,18 +120,26 @@ func (m *MVCCDataSource) gcOldVersions() {
 		}
 	}
 
+	now := time.Now()
+	var removed int64
 	// Clean up old versions from each table
 	for tableName, tableVer := range m.tables {
+		retention := m.historyRetentionLocked(tableName)
 		tableVer.mu.Lock()
+		var keep map[int64]bool
+		if retention > 0 {
+			keep = tableVer.historyVersions(now.Add(-retention))
+		}
 		for ver, data := range tableVer.versions {
-			// Keep the latest version, versions needed by active transactions,
-			// and a small buffer of recent versions
-			if ver < minRequiredVer && ver != tableVer.latest {
+			// Keep the latest version, versions needed by active transactions
+			// and versions still inside the history retention window
+			if ver < minRequiredVer && ver != tableVer.latest && !keep[ver] {
 				// Release paged rows to free buffer pool memory and spill files
 				if data != nil && data.rows != nil {
 					data.rows.Release()
 				}
 				delete(tableVer.versions, ver)
+				removed++
 			}
 		}
 		// Update buffer pool latest version for eviction priority
@@ -112,4 +148,30 @@ func (m *MVCCDataSource) gcOldVersions() {
 		}
 		tableVer.mu.Unlock()
 	}
+	return removed
+}
+
+// historyVersions returns the versions that were current at some point after
+// cutoff. Must be called while holding tv.mu.
+func (tv *TableVersions) historyVersions(cutoff time.Time) map[int64]bool {
+	vers := tv.sortedVersions()
+	keep := make(map[int64]bool, len(vers))
+	for i, ver := range vers {
+		// A version stays current until the next one is created
+		if i == len(vers)-1 || tv.versions[vers[i+1]].createdAt.After(cutoff) {
+			keep[ver] = true
+		}
+	}
+	return keep
+}
+
+// sortedVersions returns the version numbers in ascending order.
+// Must be called while holding tv.mu.
+func (tv *TableVersions) sortedVersions() []int64 {
+	vers := make([]int64, 0, len(tv.versions))
+	for ver := range tv.versions {
+		vers = append(vers, ver)
+	}
+	sort.Slice(vers, func(i, j int) bool { return vers[i] < vers[j] })
+	return vers
 }
@@ -392,6 +392,24 @@ func TestMVCCDataSource_Query(t *testing.T) {
 			t.Errorf("Expected highest grade 95.0, got %v", result.Rows[0]["grade"])
 		}
 	}
+	if !result.Ordered {
+		t.Errorf("Expected ordered result")
+	}
+
+	// 测试多列排序 + 分页：age 升序，相同 age 按 grade 降序
+	result, err = ds.Query(ctx, "students", &domain.QueryOptions{
+		Sort:  []domain.OrderByItem{{Column: "age"}, {Column: "grade", Direction: "DESC"}},
+		Limit: 2,
+	})
+	if err != nil {
+		t.Errorf("Query() with multi-key order error = %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["name"] != "Alice" || result.Rows[1]["name"] != "Charlie" || !result.Ordered {
+		t.Errorf("Expected ordered [Alice Charlie], got %v (ordered=%v)", result.Rows, result.Ordered)
+	}
+	if !ds.SupportsSort("students", []domain.OrderByItem{{Column: "age"}, {Column: "name", Collation: "utf8mb4_general_ci"}}) {
+		t.Errorf("Expected memory data source to support multi-key sort")
+	}
 
 	// 测试带分页的查询
 	result, err = ds.Query(ctx, "students", &domain.QueryOptions{
@@ -1708,3 +1726,56 @@ func TestMVCCDataSource_GeneratedColumn_CyclicDependency(t *testing.T) {
 		t.Error("Expected error when creating table with cyclic dependency")
 	}
 }
+
+// TestMVCCDataSource_RowCount 测试统计行数：全表、带过滤条件以及事务内未提交的插入
+func TestMVCCDataSource_RowCount(t *testing.T) {
+	ds := NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "test",
+		Writable: true,
+	})
+	ctx := context.Background()
+	ds.Connect(ctx)
+
+	ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "items",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INTEGER"},
+			{Name: "status", Type: "VARCHAR"},
+		},
+	})
+	ds.Insert(ctx, "items", []domain.Row{
+		{"id": int64(1), "status": "active"},
+		{"id": int64(2), "status": "closed"},
+		{"id": int64(3), "status": "active"},
+	}, nil)
+
+	count, exact, err := ds.RowCount(ctx, "items", nil)
+	if err != nil || !exact || count != 3 {
+		t.Errorf("RowCount() = %d, %v, %v, want 3, true, nil", count, exact, err)
+	}
+
+	filters := []domain.Filter{{Field: "status", Operator: "=", Value: "active"}}
+	count, exact, err = ds.RowCount(ctx, "items", filters)
+	if err != nil || !exact || count != 2 {
+		t.Errorf("RowCount(status = active) = %d, %v, %v, want 2, true, nil", count, exact, err)
+	}
+
+	txnID, err := ds.BeginTx(ctx, false)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	txnCtx := SetTransactionID(ctx, txnID)
+	ds.Insert(txnCtx, "items", []domain.Row{{"id": int64(4), "status": "active"}}, nil)
+	if count, _, _ := ds.RowCount(txnCtx, "items", nil); count != 4 {
+		t.Errorf("RowCount() in transaction = %d, want 4", count)
+	}
+	if count, _, _ := ds.RowCount(ctx, "items", nil); count != 3 {
+		t.Errorf("RowCount() outside transaction = %d, want 3", count)
+	}
+	ds.RollbackTx(ctx, txnID)
+
+	if _, _, err := ds.RowCount(ctx, "missing", nil); err == nil {
+		t.Error("expected error for missing table")
+	}
+}
@@ -8,8 +8,9 @@ import (
 
 // MVCCTransaction wraps existing transaction methods to implement Transaction interface
 type MVCCTransaction struct {
-	ds    *MVCCDataSource
-	txnID int64
+	ds        *MVCCDataSource
+	txnID     int64
+	isolation string
 }
 
 // GetID returns the transaction ID
@@ -39,18 +40,120 @@ func (t *MVCCTransaction) Execute(ctx context.Context, sql string) (*domain.Quer
 	}, nil
 }
 
+// Query reads rows from the transaction snapshot. Locking reads
+// (options.Lock) first lock the matching rows until commit or rollback.
 func (t *MVCCTransaction) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	ctx = t.GetContext(ctx)
+	if err := t.checkNotPrepared(); err != nil {
+		return nil, err
+	}
+	t.beginStatement()
+	if mode := t.readLock(options); mode != domain.LockModeNone {
+		var filters []domain.Filter
+		if options != nil {
+			filters = options.Filters
+		}
+		if err := t.lock(ctx, func() error {
+			return t.ds.lockRows(ctx, t.txnID, tableName, filters, mode == domain.LockModeUpdate)
+		}); err != nil {
+			return nil, err
+		}
+	}
 	return t.ds.Query(ctx, tableName, options)
 }
 
 func (t *MVCCTransaction) Insert(ctx context.Context, tableName string, rows []domain.Row, options *domain.InsertOptions) (int64, error) {
+	ctx = t.GetContext(ctx)
+	t.beginStatement()
+	if err := t.lock(ctx, func() error { return t.ds.lockInsertedRows(ctx, t.txnID, tableName, rows) }); err != nil {
+		return 0, err
+	}
 	return t.ds.Insert(ctx, tableName, rows, options)
 }
 
 func (t *MVCCTransaction) Update(ctx context.Context, tableName string, filters []domain.Filter, updates domain.Row, options *domain.UpdateOptions) (int64, error) {
+	ctx = t.GetContext(ctx)
+	t.beginStatement()
+	if err := t.lock(ctx, func() error { return t.ds.lockRows(ctx, t.txnID, tableName, filters, true) }); err != nil {
+		return 0, err
+	}
 	return t.ds.Update(ctx, tableName, filters, updates, options)
 }
 
 func (t *MVCCTransaction) Delete(ctx context.Context, tableName string, filters []domain.Filter, options *domain.DeleteOptions) (int64, error) {
+	ctx = t.GetContext(ctx)
+	t.beginStatement()
+	if err := t.lock(ctx, func() error { return t.ds.lockRows(ctx, t.txnID, tableName, filters, true) }); err != nil {
+		return 0, err
+	}
 	return t.ds.Delete(ctx, tableName, filters, options)
 }
+
+// lock runs a lock acquisition; a transaction chosen as deadlock victim is
+// rolled back so its locks are released immediately. After waiting for a
+// lock, READ COMMITTED statements read the changes of the former holder.
+// Every write goes through lock, so it also rejects prepared transactions.
+func (t *MVCCTransaction) lock(ctx context.Context, acquire func() error) error {
+	if err := t.checkNotPrepared(); err != nil {
+		return err
+	}
+	err := acquire()
+	if domain.IsDeadlock(err) {
+		_ = t.ds.RollbackTx(ctx, t.txnID)
+	}
+	if err == nil {
+		t.beginStatement()
+	}
+	return err
+}
+
+// CreateTable creates a table visible only to this transaction until commit
+func (t *MVCCTransaction) CreateTable(ctx context.Context, tableInfo *domain.TableInfo) error {
+	ctx = t.GetContext(ctx)
+	if err := t.lock(ctx, func() error { return t.ds.lockTable(ctx, t.txnID, tableInfo.Name, true) }); err != nil {
+		return err
+	}
+	return t.ds.txnCreateTable(ctx, t.txnID, tableInfo)
+}
+
+// DropTable drops a table; other sessions see it until commit
+func (t *MVCCTransaction) DropTable(ctx context.Context, tableName string) error {
+	ctx = t.GetContext(ctx)
+	if err := t.lock(ctx, func() error { return t.ds.lockTable(ctx, t.txnID, tableName, true) }); err != nil {
+		return err
+	}
+	return t.ds.txnDropTable(ctx, t.txnID, tableName)
+}
+
+// TruncateTable deletes all rows of a table within this transaction
+func (t *MVCCTransaction) TruncateTable(ctx context.Context, tableName string) error {
+	ctx = t.GetContext(ctx)
+	if err := t.lock(ctx, func() error { return t.ds.lockTable(ctx, t.txnID, tableName, true) }); err != nil {
+		return err
+	}
+	return t.ds.txnTruncateTable(ctx, t.txnID, tableName)
+}
+
+// SetTableTTL sets or removes (ttl == nil) the TTL of a table within this transaction
+func (t *MVCCTransaction) SetTableTTL(ctx context.Context, tableName string, ttl *domain.TTLInfo) error {
+	ctx = t.GetContext(ctx)
+	if err := t.lock(ctx, func() error { return t.ds.lockTable(ctx, t.txnID, tableName, true) }); err != nil {
+		return err
+	}
+	return t.ds.txnSetTableTTL(ctx, t.txnID, tableName, ttl)
+}
+
+// Savepoint creates a savepoint, replacing an existing one with the same name
+func (t *MVCCTransaction) Savepoint(ctx context.Context, name string) error {
+	return t.ds.txnSavepoint(t.txnID, name)
+}
+
+// RollbackToSavepoint undoes the row changes and DDL made after a savepoint
+func (t *MVCCTransaction) RollbackToSavepoint(ctx context.Context, name string) error {
+	return t.ds.txnRollbackToSavepoint(t.txnID, name)
+}
+
+// ReleaseSavepoint removes a savepoint and the savepoints created after it
+func (t *MVCCTransaction) ReleaseSavepoint(ctx context.Context, name string) error {
+	return t.ds.txnReleaseSavepoint(t.txnID, name)
+}
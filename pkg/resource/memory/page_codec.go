@@ -23,6 +23,12 @@ import (
 //	    [keyLen:uint16][key:bytes]
 //	    [typeTag:byte][value:bytes]
 //
+// Strings are dictionary and prefix encoded within a page: a string seen
+// before in the page is written as a reference to its first occurrence
+// (tagStringRef), and a new string sharing a prefix with the previous string
+// of the same column stores only the suffix (tagStringPrefix). Decoded strings
+// are interned, so a reloaded page shares string data with the rest of the table.
+//
 // Type tags:
 const (
 	tagNil     byte = 0
@@ -35,8 +41,113 @@ const (
 	tagInt     byte = 7
 	tagFloat32 byte = 8
 	tagInt32   byte = 9
+
+	tagStringRef    byte = 10 // [index:uint32] into the page's string dictionary
+	tagStringPrefix byte = 11 // [prefixLen:uint32][suffixLen:uint32][suffix:bytes]
+	tagFloat32s     byte = 12 // [count:uint32][float32 bits:uint32...], vector values
 )
 
+// minStringPrefix is the shortest shared prefix worth encoding with tagStringPrefix
+const minStringPrefix = 8
+
+// stringEncoder holds the per-page string dictionary used by encodeRows
+type stringEncoder struct {
+	dict map[string]uint32
+	last map[string]string // previous string of each column
+}
+
+// stringDecoder mirrors stringEncoder in decodeRows
+type stringDecoder struct {
+	dict []string
+	last map[string]string
+}
+
+// appendString writes a string value of column key
+func (e *stringEncoder) appendString(buf []byte, key, s string) []byte {
+	if idx, ok := e.dict[s]; ok {
+		buf = append(buf, tagStringRef)
+		return appendUint32(buf, idx)
+	}
+	e.dict[s] = uint32(len(e.dict))
+
+	prefix := commonPrefixLen(e.last[key], s)
+	e.last[key] = s
+	if prefix >= minStringPrefix {
+		buf = append(buf, tagStringPrefix)
+		buf = appendUint32(buf, uint32(prefix))
+		buf = appendUint32(buf, uint32(len(s)-prefix))
+		return append(buf, s[prefix:]...)
+	}
+	buf = append(buf, tagString)
+	buf = appendUint32(buf, uint32(len(s)))
+	return append(buf, s...)
+}
+
+// isStringTag reports whether tag is one of the string encodings
+func isStringTag(tag byte) bool {
+	return tag == tagString || tag == tagStringRef || tag == tagStringPrefix
+}
+
+// readString reads a string value of column key written by stringEncoder.appendString
+func (d *stringDecoder) readString(data []byte, pos int, key string) (interface{}, int, error) {
+	tag := data[pos]
+	pos++
+
+	switch tag {
+	case tagStringRef:
+		if pos+4 > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at string reference")
+		}
+		idx := readUint32(data, pos)
+		if int(idx) >= len(d.dict) {
+			return nil, pos, fmt.Errorf("page_codec: invalid string reference %d", idx)
+		}
+		return d.dict[idx], pos + 4, nil
+	case tagStringPrefix:
+		if pos+8 > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at string prefix")
+		}
+		prefixLen := int(readUint32(data, pos))
+		suffixLen := int(readUint32(data, pos+4))
+		pos += 8
+		last := d.last[key]
+		if prefixLen > len(last) || pos+suffixLen > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: invalid string prefix")
+		}
+		s := last[:prefixLen] + string(data[pos:pos+suffixLen])
+		return d.add(key, s), pos + suffixLen, nil
+	default:
+		if pos+4 > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at string length")
+		}
+		sLen := int(readUint32(data, pos))
+		pos += 4
+		if pos+sLen > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at string data")
+		}
+		return d.add(key, string(data[pos:pos+sLen])), pos + sLen, nil
+	}
+}
+
+// add records a decoded string of column key
+func (d *stringDecoder) add(key, s string) string {
+	s = internString(s)
+	d.dict = append(d.dict, s)
+	d.last[key] = s
+	return s
+}
+
+// commonPrefixLen returns the length of the common prefix of a and b
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
 // encodeRows serializes []domain.Row to a byte slice using a fast binary format.
 func encodeRows(rows []domain.Row) ([]byte, error) {
 	// Pre-estimate buffer size: ~200 bytes per row is a reasonable starting point
@@ -45,6 +156,8 @@ func encodeRows(rows []domain.Row) ([]byte, error) {
 	// Row count
 	buf = appendUint32(buf, uint32(len(rows)))
 
+	strs := &stringEncoder{dict: make(map[string]uint32), last: make(map[string]string)}
+
 	for _, row := range rows {
 		// Field count
 		buf = appendUint16(buf, uint16(len(row)))
@@ -55,6 +168,10 @@ func encodeRows(rows []domain.Row) ([]byte, error) {
 			buf = append(buf, k...)
 
 			// Value
+			if str, ok := v.(string); ok {
+				buf = strs.appendString(buf, k, str)
+				continue
+			}
 			var err error
 			buf, err = appendValue(buf, v)
 			if err != nil {
@@ -78,6 +195,7 @@ func decodeRows(data []byte) ([]domain.Row, error) {
 	pos += 4
 
 	rows := make([]domain.Row, rowCount)
+	strs := &stringDecoder{last: make(map[string]string)}
 
 	for i := uint32(0); i < rowCount; i++ {
 		if pos+2 > len(data) {
@@ -98,12 +216,16 @@ func decodeRows(data []byte) ([]domain.Row, error) {
 			if pos+int(keyLen) > len(data) {
 				return nil, fmt.Errorf("page_codec: unexpected EOF reading key")
 			}
-			key := string(data[pos : pos+int(keyLen)])
+			key := internString(string(data[pos : pos+int(keyLen)]))
 			pos += int(keyLen)
 
 			var val interface{}
 			var err error
-			val, pos, err = readValue(data, pos)
+			if pos < len(data) && isStringTag(data[pos]) {
+				val, pos, err = strs.readString(data, pos, key)
+			} else {
+				val, pos, err = readValue(data, pos)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -150,6 +272,12 @@ func appendValue(buf []byte, v interface{}) ([]byte, error) {
 		buf = append(buf, tagBytes)
 		buf = appendUint32(buf, uint32(len(val)))
 		buf = append(buf, val...)
+	case []float32:
+		buf = append(buf, tagFloat32s)
+		buf = appendUint32(buf, uint32(len(val)))
+		for _, f := range val {
+			buf = appendUint32(buf, math.Float32bits(f))
+		}
 	case time.Time:
 		buf = append(buf, tagTime)
 		b, _ := val.MarshalBinary()
@@ -230,6 +358,20 @@ func readValue(data []byte, pos int) (interface{}, int, error) {
 		val := make([]byte, bLen)
 		copy(val, data[pos:pos+int(bLen)])
 		return val, pos + int(bLen), nil
+	case tagFloat32s:
+		if pos+4 > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at vector length")
+		}
+		n := int(readUint32(data, pos))
+		pos += 4
+		if pos+n*4 > len(data) {
+			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at vector data")
+		}
+		val := make([]float32, n)
+		for i := range val {
+			val[i] = math.Float32frombits(readUint32(data, pos+i*4))
+		}
+		return val, pos + n*4, nil
 	case tagTime:
 		if pos+2 > len(data) {
 			return nil, pos, fmt.Errorf("page_codec: unexpected EOF at time length")
@@ -1,8 +1,6 @@
 package memory
 
 import (
-	"log"
-
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 )
 
@@ -150,6 +148,14 @@ func (pr *PagedRows) Len() int {
 	return pr.totalRows
 }
 
+// PageCount returns the number of pages holding the rows.
+func (pr *PagedRows) PageCount() int {
+	if pr == nil {
+		return 0
+	}
+	return len(pr.pages)
+}
+
 // Get returns the row at the given index. It pins and unpins the containing
 // page automatically. For bulk access, prefer Materialize() or Range().
 func (pr *PagedRows) Get(i int) domain.Row {
@@ -203,7 +209,7 @@ func (pr *PagedRows) Materialize() []domain.Row {
 			rows, err := pr.pool.Pin(page)
 			if err != nil {
 				// Log the error — the returned slice will have fewer rows than expected
-				log.Printf("[WARN] Materialize: failed to load page %v: %v (skipping %d rows)", page.id, err, page.rowCount)
+				memLog.Warn("Materialize: failed to load page, skipping its rows", "page", page.id, "rows", page.rowCount, "err", err)
 				continue
 			}
 			result = append(result, rows...)
@@ -0,0 +1,438 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/util"
+)
+
+// ==================== Table Partitioning ====================
+//
+// A partitioned table keeps its schema (including the partition definition) and its
+// auto-increment counters in the parent table, which never stores rows itself.
+// Each partition is stored in an internal table named "<table>#P#<partition>", so
+// MVCC versions, transactions and indexes work per partition unchanged.
+
+// partitionSeparator separates the parent table name and the partition name
+const partitionSeparator = "#P#"
+
+// partitionTableName returns the name of the internal table storing a partition
+func partitionTableName(tableName, partitionName string) string {
+	return tableName + partitionSeparator + partitionName
+}
+
+// isPartitionTable reports whether name is an internal partition table
+func isPartitionTable(name string) bool {
+	return strings.Contains(name, partitionSeparator)
+}
+
+// partitionTables returns the internal table names of all partitions of schema
+func partitionTables(schema *domain.TableInfo) []string {
+	names := make([]string, len(schema.Partition.Partitions))
+	for i, def := range schema.Partition.Partitions {
+		names[i] = partitionTableName(schema.Name, def.Name)
+	}
+	return names
+}
+
+// latestSchema returns the schema of the latest version of a table, or nil if the table is not found
+func (m *MVCCDataSource) latestSchema(tableName string) *domain.TableInfo {
+	m.mu.RLock()
+	tableVer, ok := m.tables[tableName]
+	m.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	tableVer.mu.RLock()
+	defer tableVer.mu.RUnlock()
+	if data := tableVer.versions[tableVer.latest]; data != nil {
+		return data.schema
+	}
+	return nil
+}
+
+// createPartitionedTable creates the parent table and one internal table per partition
+func (m *MVCCDataSource) createPartitionedTable(ctx context.Context, tableInfo *domain.TableInfo) error {
+	schema := deepCopySchema(tableInfo)
+	if schema.Temporary {
+		return fmt.Errorf("temporary tables cannot be partitioned")
+	}
+	if err := util.ValidatePartition(schema); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if _, ok := m.tables[schema.Name]; ok {
+		m.mu.Unlock()
+		return domain.NewErrTableAlreadyExists(schema.Name)
+	}
+	err := m.createTableLocked(schema)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := m.createPartitionTables(ctx, schema, schema.Partition.Partitions); err != nil {
+		_ = m.DropTable(ctx, schema.Name)
+		return err
+	}
+	return nil
+}
+
+// createPartitionTables creates the internal tables for the given partitions
+func (m *MVCCDataSource) createPartitionTables(ctx context.Context, schema *domain.TableInfo, defs []domain.PartitionDef) error {
+	for i, def := range defs {
+		child := deepCopySchema(schema)
+		child.Name = partitionTableName(schema.Name, def.Name)
+		child.Partition = nil
+		child.TTL = nil
+		if err := m.CreateTable(ctx, child); err != nil {
+			for _, created := range defs[:i] {
+				_ = m.DropTable(ctx, partitionTableName(schema.Name, created.Name))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// setPartitionInfo creates a new version of the parent table with an updated partition definition
+func (m *MVCCDataSource) setPartitionInfo(tableName string, partition *domain.PartitionInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tableVer, ok := m.tables[tableName]
+	if !ok {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.Lock()
+	defer tableVer.mu.Unlock()
+
+	latestData := tableVer.versions[tableVer.latest]
+	if latestData == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	schema := deepCopySchema(latestData.schema)
+	schema.Partition = partition.Clone()
+
+	m.currentVer++
+	tableVer.versions[m.currentVer] = &TableData{
+		version:   m.currentVer,
+		createdAt: time.Now(),
+		schema:    schema,
+		rows:      NewEmptyPagedRows(m.bufferPool, 0),
+	}
+	tableVer.latest = m.currentVer
+	return nil
+}
+
+// queryPartitions queries the partitions that may contain matching rows and merges the results
+func (m *MVCCDataSource) queryPartitions(ctx context.Context, schema *domain.TableInfo, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	var filters []domain.Filter
+	partOptions := &domain.QueryOptions{}
+	if options != nil {
+		filters = options.Filters
+		partOptions.Filters = options.Filters
+		partOptions.User = options.User
+		partOptions.ForceIndex = options.ForceIndex
+		partOptions.IgnoreIndexes = options.IgnoreIndexes
+	}
+
+	rows := make([]domain.Row, 0)
+	for _, idx := range util.PrunePartitions(schema.Partition, filters) {
+		result, err := m.Query(ctx, partitionTableName(schema.Name, schema.Partition.Partitions[idx].Name), partOptions)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, result.Rows...)
+	}
+
+	total := int64(len(rows))
+	if options != nil {
+		rows = util.ApplyOrder(rows, options)
+		if options.Limit > 0 || options.Offset > 0 {
+			rows = util.ApplyPagination(rows, options.Offset, options.Limit)
+		}
+		if len(options.SelectColumns) > 0 {
+			rows = util.PruneRows(rows, options.SelectColumns)
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: schema.Columns,
+		Rows:    rows,
+		Total:   total,
+		Ordered: len(options.SortKeys()) > 0,
+	}, nil
+}
+
+// insertPartitions routes rows to their partitions. Auto-increment values must already be assigned.
+// All rows are located before anything is written, so a row without a partition inserts nothing.
+func (m *MVCCDataSource) insertPartitions(ctx context.Context, schema *domain.TableInfo, rows []domain.Row, options *domain.InsertOptions) (int64, error) {
+	groups := make([][]domain.Row, len(schema.Partition.Partitions))
+	for _, row := range rows {
+		idx, err := util.LocatePartition(schema, row)
+		if err != nil {
+			return 0, err
+		}
+		groups[idx] = append(groups[idx], row)
+	}
+
+	inserted := int64(0)
+	for idx, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		n, err := m.Insert(ctx, partitionTableName(schema.Name, schema.Partition.Partitions[idx].Name), group, options)
+		inserted += n
+		if err != nil {
+			return inserted, err
+		}
+	}
+	return inserted, nil
+}
+
+// updatePartitions updates matching rows in the pruned partitions.
+// When the partition column changes, matching rows are moved by deleting and re-inserting them.
+func (m *MVCCDataSource) updatePartitions(ctx context.Context, schema *domain.TableInfo, filters []domain.Filter, updates domain.Row, options *domain.UpdateOptions) (int64, error) {
+	parts := util.PrunePartitions(schema.Partition, filters)
+
+	if _, changesKey := updates[schema.Partition.Column]; !changesKey {
+		updated := int64(0)
+		for _, idx := range parts {
+			n, err := m.Update(ctx, partitionTableName(schema.Name, schema.Partition.Partitions[idx].Name), filters, updates, options)
+			updated += n
+			if err != nil {
+				return updated, err
+			}
+		}
+		return updated, nil
+	}
+
+	// Collect and relocate the updated rows first so that a row without a partition changes nothing
+	var moved []domain.Row
+	for _, idx := range parts {
+		// Moved rows are inserted again, so their encrypted columns are read as plaintext
+		result, err := m.Query(domain.WithColumnDecryption(ctx), partitionTableName(schema.Name, schema.Partition.Partitions[idx].Name), &domain.QueryOptions{Filters: filters})
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range result.Rows {
+			newRow := deepCopyRow(row)
+			for k, v := range updates {
+				newRow[k] = v
+			}
+			if _, err := util.LocatePartition(schema, newRow); err != nil {
+				return 0, err
+			}
+			moved = append(moved, newRow)
+		}
+	}
+	if len(moved) == 0 {
+		return 0, nil
+	}
+
+	for _, idx := range parts {
+		if _, err := m.Delete(ctx, partitionTableName(schema.Name, schema.Partition.Partitions[idx].Name), filters, nil); err != nil {
+			return 0, err
+		}
+	}
+	return m.insertPartitions(ctx, schema, moved, nil)
+}
+
+// deletePartitions deletes matching rows from the pruned partitions
+func (m *MVCCDataSource) deletePartitions(ctx context.Context, schema *domain.TableInfo, filters []domain.Filter, options *domain.DeleteOptions) (int64, error) {
+	deleted := int64(0)
+	for _, idx := range util.PrunePartitions(schema.Partition, filters) {
+		n, err := m.Delete(ctx, partitionTableName(schema.Name, schema.Partition.Partitions[idx].Name), filters, options)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// AddPartitions adds partitions to a partitioned table (domain.PartitionManager).
+// RANGE partitions must come after the existing ones; HASH partitions redistribute all rows.
+func (m *MVCCDataSource) AddPartitions(ctx context.Context, tableName string, partitions []domain.PartitionDef) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "add partition")
+	}
+
+	schema := m.latestSchema(tableName)
+	if schema == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+	if !schema.IsPartitioned() {
+		return fmt.Errorf("table %s is not partitioned", tableName)
+	}
+	if len(partitions) == 0 {
+		return fmt.Errorf("at least one partition must be added")
+	}
+
+	newSchema := deepCopySchema(schema)
+	newSchema.Partition.Partitions = append(newSchema.Partition.Partitions, partitions...)
+	if err := util.ValidatePartition(newSchema); err != nil {
+		return err
+	}
+
+	if schema.Partition.Type == domain.PartitionTypeHash {
+		return m.repartition(ctx, schema, newSchema)
+	}
+
+	if err := m.createPartitionTables(ctx, schema, partitions); err != nil {
+		return err
+	}
+	m.copyPartitionIndexes(partitionTables(schema)[0], partitionTables(newSchema)[len(schema.Partition.Partitions):])
+	return m.setPartitionInfo(tableName, newSchema.Partition)
+}
+
+// DropPartitions drops RANGE partitions and the rows stored in them (domain.PartitionManager)
+func (m *MVCCDataSource) DropPartitions(ctx context.Context, tableName string, names []string) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "drop partition")
+	}
+
+	schema := m.latestSchema(tableName)
+	if schema == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+	if !schema.IsPartitioned() {
+		return fmt.Errorf("table %s is not partitioned", tableName)
+	}
+	if schema.Partition.Type != domain.PartitionTypeRange {
+		return fmt.Errorf("DROP PARTITION can only be used on RANGE partitions")
+	}
+
+	drop := make(map[int]bool, len(names))
+	for _, name := range names {
+		idx := schema.Partition.PartitionIndex(name)
+		if idx < 0 {
+			return fmt.Errorf("partition '%s' does not exist in table %s", name, tableName)
+		}
+		drop[idx] = true
+	}
+	if len(drop) == len(schema.Partition.Partitions) {
+		return fmt.Errorf("cannot remove all partitions, use DROP TABLE instead")
+	}
+
+	partition := schema.Partition.Clone()
+	partition.Partitions = partition.Partitions[:0]
+	for i, def := range schema.Partition.Partitions {
+		if !drop[i] {
+			partition.Partitions = append(partition.Partitions, def)
+		}
+	}
+	if err := m.setPartitionInfo(tableName, partition); err != nil {
+		return err
+	}
+
+	for idx := range drop {
+		if err := m.DropTable(ctx, partitionTableName(tableName, schema.Partition.Partitions[idx].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repartition moves all rows from the partitions of oldSchema into the partitions of newSchema
+func (m *MVCCDataSource) repartition(ctx context.Context, oldSchema, newSchema *domain.TableInfo) error {
+	result, err := m.queryPartitions(ctx, oldSchema, nil)
+	if err != nil {
+		return err
+	}
+
+	// Partition names of the new layout may reuse existing names, so drop the old tables first
+	oldTables := partitionTables(oldSchema)
+	indexes, _ := m.indexManager.GetTableIndexes(oldTables[0])
+	for _, name := range oldTables {
+		if err := m.DropTable(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	if err := m.createPartitionTables(ctx, newSchema, newSchema.Partition.Partitions); err != nil {
+		return err
+	}
+	for _, name := range partitionTables(newSchema) {
+		m.createIndexes(name, indexes)
+	}
+	if err := m.setPartitionInfo(newSchema.Name, newSchema.Partition); err != nil {
+		return err
+	}
+
+	_, err = m.insertPartitions(ctx, newSchema, result.Rows, nil)
+	return err
+}
+
+// partitionedCreateIndex creates an index on every partition of a table
+func (m *MVCCDataSource) partitionedCreateIndex(schema *domain.TableInfo, columnNames []string, indexType string, unique bool) error {
+	for _, name := range partitionTables(schema) {
+		if err := m.CreateIndexWithColumns(name, columnNames, indexType, unique); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitionedDropIndex drops an index from every partition of a table
+func (m *MVCCDataSource) partitionedDropIndex(schema *domain.TableInfo, indexName string) error {
+	for _, name := range partitionTables(schema) {
+		if err := m.DropIndex(name, partitionIndexName(indexName, schema.Name, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitionedTableIndexes reports the indexes of the first partition under the parent table name
+func (m *MVCCDataSource) partitionedTableIndexes(schema *domain.TableInfo) ([]*IndexInfo, error) {
+	first := partitionTables(schema)[0]
+	infos, err := m.indexManager.GetTableIndexes(first)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*IndexInfo, len(infos))
+	for i, info := range infos {
+		infoCopy := *info
+		infoCopy.Name = partitionIndexName(info.Name, first, schema.Name)
+		infoCopy.TableName = schema.Name
+		result[i] = &infoCopy
+	}
+	return result, nil
+}
+
+// copyPartitionIndexes creates the indexes of an existing partition on new partitions
+func (m *MVCCDataSource) copyPartitionIndexes(from string, to []string) {
+	indexes, err := m.indexManager.GetTableIndexes(from)
+	if err != nil {
+		return
+	}
+	for _, name := range to {
+		m.createIndexes(name, indexes)
+	}
+}
+
+// createIndexes creates indexes with the given definitions on a table
+func (m *MVCCDataSource) createIndexes(to string, indexes []*IndexInfo) {
+	for _, info := range indexes {
+		_, _ = m.indexManager.CreateIndexWithColumns(to, info.Columns, info.Type, info.Unique)
+	}
+}
+
+// partitionIndexName translates an auto-generated index name (idx_<table>_...) between tables
+func partitionIndexName(indexName, fromTable, toTable string) string {
+	prefix := "idx_" + fromTable + "_"
+	if strings.HasPrefix(indexName, prefix) {
+		return "idx_" + toTable + "_" + strings.TrimPrefix(indexName, prefix)
+	}
+	return indexName
+}
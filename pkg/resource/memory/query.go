@@ -20,6 +20,26 @@ func (m *MVCCDataSource) SupportsFiltering(tableName string) bool {
 	return ok
 }
 
+// SupportsSort implements domain.SortableDataSource: every ordering can be applied before
+// pagination except on ENCRYPTED columns, whose stored ciphertexts do not sort like the plaintexts
+func (m *MVCCDataSource) SupportsSort(tableName string, keys []domain.OrderByItem) bool {
+	schema, err := m.GetTableInfo(context.Background(), tableName)
+	if err != nil {
+		return false
+	}
+	return !sortsEncryptedColumn(keys, schema)
+}
+
+// sortsEncryptedColumn reports whether any of keys orders by an ENCRYPTED column
+func sortsEncryptedColumn(keys []domain.OrderByItem, schema *domain.TableInfo) bool {
+	for _, key := range keys {
+		if col, ok := schema.GetColumn(key.Column); ok && col.Encryption != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // Filter implements FilterableDataSource interface filter and pagination methods
 func (m *MVCCDataSource) Filter(
 	ctx context.Context,
@@ -41,17 +61,30 @@ func (m *MVCCDataSource) Filter(
 		return nil, 0, domain.NewErrTableNotFound(tableName)
 	}
 
-	m.mu.RUnlock()
-
-	// Get table data
-	tableVer.mu.RLock()
-	defer tableVer.mu.RUnlock()
+	var tableData *TableData
+	if asOf, ok := domain.AsOfFromContext(ctx); ok {
+		// Time-travel query, read the version that was current at asOf
+		retention := m.historyRetentionLocked(tableName)
+		m.mu.RUnlock()
+		data, err := tableVer.versionAsOf(tableName, asOf, retention)
+		if err != nil {
+			return nil, 0, err
+		}
+		domain.MarkAsOfServed(ctx)
+		tableData = data
+	} else {
+		m.mu.RUnlock()
 
-	if tableVer.latest < 0 {
-		return nil, 0, domain.NewErrTableNotFound(tableName)
+		// Get table data
+		tableVer.mu.RLock()
+		if tableVer.latest < 0 {
+			tableVer.mu.RUnlock()
+			return nil, 0, domain.NewErrTableNotFound(tableName)
+		}
+		tableData = tableVer.versions[tableVer.latest]
+		tableVer.mu.RUnlock()
 	}
 
-	tableData := tableVer.versions[tableVer.latest]
 	if tableData == nil || tableData.schema == nil {
 		return nil, 0, domain.NewErrTableNotFound(tableName)
 	}
@@ -64,12 +97,26 @@ func (m *MVCCDataSource) Filter(
 		filters = subFilters
 	}
 
+	filters, err := m.encryptFilters(ctx, tableName, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Use util.ApplyFilters to filter data
 	options := &domain.QueryOptions{
 		Filters: filters,
 	}
 
-	filteredRows := util.ApplyFilters(tableData.Rows(), options)
+	var filteredRows []domain.Row
+	if tableData.schema.IsPartitioned() {
+		result, err := m.queryPartitions(ctx, tableData.schema, options)
+		if err != nil {
+			return nil, 0, err
+		}
+		filteredRows = result.Rows
+	} else {
+		filteredRows = util.ApplyFilters(tableData.Rows(), options)
+	}
 	total := int64(len(filteredRows))
 
 	// Apply pagination
@@ -85,65 +132,53 @@ func (m *MVCCDataSource) Filter(
 		end = offset + limit
 	}
 
-	return filteredRows[offset:end], total, nil
+	page := &domain.QueryResult{Rows: filteredRows[offset:end]}
+	if err := m.decryptResult(ctx, tableData.schema, page); err != nil {
+		return nil, 0, err
+	}
+	return page.Rows, total, nil
 }
 
 // Query queries data
 func (m *MVCCDataSource) Query(ctx context.Context, tableName string, options *domain.QueryOptions) (*domain.QueryResult, error) {
-	m.mu.RLock()
-
-	if !m.connected {
-		m.mu.RUnlock()
-		return nil, domain.NewErrNotConnected("memory")
+	tableData, hasAsOf, err := m.readTableData(ctx, tableName)
+	if err != nil {
+		return nil, err
 	}
 
-	tableVer, ok := m.tables[tableName]
-	if !ok {
-		m.mu.RUnlock()
-		return nil, domain.NewErrTableNotFound(tableName)
+	if tableData.schema.IsPartitioned() {
+		return m.queryPartitions(ctx, tableData.schema, options)
 	}
 
-	txnID, hasTxn := GetTransactionID(ctx)
-	var tableData *TableData
-
-	if hasTxn {
-		// In transaction, read from COW snapshot
-		snapshot, ok := m.snapshots[txnID]
-		if ok {
-			cowSnapshot, ok := snapshot.tableSnapshots[tableName]
-			if ok {
-				tableData = cowSnapshot.getTableData(tableVer)
-				m.mu.RUnlock()
-			} else {
-				m.mu.RUnlock()
-				tableVer.mu.RLock()
-				tableData = tableVer.versions[tableVer.latest]
-				tableVer.mu.RUnlock()
-			}
-		} else {
-			m.mu.RUnlock()
-			tableVer.mu.RLock()
-			tableData = tableVer.versions[tableVer.latest]
-			tableVer.mu.RUnlock()
+	// Conditions on ENCRYPTED columns compare the stored ciphertexts or blind indexes
+	if options != nil && len(options.Filters) > 0 && tableData.schema.HasEncryptedColumns() {
+		filters, err := m.encryptFilters(ctx, tableName, options.Filters)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		// Non-transaction query, read from latest version
-		m.mu.RUnlock()
-		tableVer.mu.RLock()
-		tableData = tableVer.versions[tableVer.latest]
-		tableVer.mu.RUnlock()
-	}
-
-	if tableData == nil {
-		return nil, domain.NewErrTableNotFound(tableName)
+		encrypted := *options
+		encrypted.Filters = filters
+		options = &encrypted
 	}
 
 	// Use query optimizer to optimize query
 	var queryResult *domain.QueryResult
-	var err error
 
-	if options != nil && len(options.Filters) > 0 {
-		// Has filter conditions, use query optimizer
+	if virtualCalc := generated.NewVirtualCalculator(); virtualCalc.HasVirtualColumns(tableData.schema) &&
+		referencesVirtualColumns(options, tableData.schema) {
+		// Filters or ordering reference VIRTUAL columns: calculate them before filtering
+		calculatedRows, calcErr := virtualCalc.CalculateBatchVirtuals(tableData.Rows(), tableData.schema)
+		if calcErr != nil {
+			return nil, calcErr
+		}
+		pagedRows := util.ApplyQueryOperations(calculatedRows, options, &tableData.schema.Columns)
+		queryResult = &domain.QueryResult{
+			Columns: tableData.schema.Columns,
+			Rows:    pagedRows,
+			Total:   int64(len(pagedRows)),
+		}
+	} else if options != nil && len(options.Filters) > 0 && !hasAsOf {
+		// Has filter conditions, use query optimizer (indexes only cover the latest version)
 		plan, planErr := m.queryPlanner.PlanQuery(tableName, options.Filters, options)
 		if planErr != nil {
 			// Optimization failed, use full table scan
@@ -178,7 +213,7 @@ func (m *MVCCDataSource) Query(ctx context.Context, tableName string, options *d
 
 				// Apply sorting and pagination
 				if options != nil {
-					if options.OrderBy != "" {
+					if len(options.SortKeys()) > 0 {
 						queryResult.Rows = util.ApplyOrder(queryResult.Rows, options)
 					}
 					// Record total before pagination
@@ -213,9 +248,129 @@ func (m *MVCCDataSource) Query(ctx context.Context, tableName string, options *d
 
 	// Convert row types based on schema (e.g., int64(0/1) to bool for BOOL columns)
 	schema := tableData.schema
+	if hasHiddenColumns(schema) {
+		stripHiddenColumns(queryResult, schema)
+	}
+	if err := m.decryptResult(ctx, schema, queryResult); err != nil {
+		return nil, err
+	}
 	for _, row := range queryResult.Rows {
 		convertRowTypesBasedOnSchema(row, schema)
 	}
+	if keys := options.SortKeys(); len(keys) > 0 {
+		queryResult.Ordered = !sortsEncryptedColumn(keys, schema)
+	}
 
 	return queryResult, nil
 }
+
+// RowCount counts the rows of tableName matching filters. Without filters the row
+// count of the visible version is read directly instead of materializing the rows
+func (m *MVCCDataSource) RowCount(ctx context.Context, tableName string, filters []domain.Filter) (int64, bool, error) {
+	if len(filters) == 0 {
+		tableData, _, err := m.readTableData(ctx, tableName)
+		if err != nil {
+			return 0, false, err
+		}
+		if !tableData.schema.IsPartitioned() {
+			return int64(tableData.RowCount()), true, nil
+		}
+	}
+	result, err := m.Query(ctx, tableName, &domain.QueryOptions{Filters: filters})
+	if err != nil {
+		return 0, false, err
+	}
+	return int64(len(result.Rows)), true, nil
+}
+
+// readTableData returns the version of tableName visible to ctx: the version current
+// at the AS OF time, the transaction snapshot, or the latest version
+func (m *MVCCDataSource) readTableData(ctx context.Context, tableName string) (*TableData, bool, error) {
+	m.mu.RLock()
+
+	if !m.connected {
+		m.mu.RUnlock()
+		return nil, false, domain.NewErrNotConnected("memory")
+	}
+
+	tableVer, ok := m.tableLocked(ctx, tableName)
+	if !ok {
+		m.mu.RUnlock()
+		return nil, false, domain.NewErrTableNotFound(tableName)
+	}
+
+	txnID, hasTxn := GetTransactionID(ctx)
+	var tableData *TableData
+	asOf, hasAsOf := domain.AsOfFromContext(ctx)
+
+	if hasAsOf {
+		// Time-travel query, read the version that was current at asOf
+		retention := m.historyRetentionLocked(tableName)
+		m.mu.RUnlock()
+		data, err := tableVer.versionAsOf(tableName, asOf, retention)
+		if err != nil {
+			return nil, false, err
+		}
+		domain.MarkAsOfServed(ctx)
+		tableData = data
+	} else if hasTxn {
+		// In transaction, read from COW snapshot
+		snapshot, ok := m.snapshots[txnID]
+		if ok {
+			cowSnapshot, ok := snapshot.tableSnapshots[tableName]
+			if ok {
+				tableData = cowSnapshot.getTableData(tableVer)
+				m.mu.RUnlock()
+			} else {
+				m.mu.RUnlock()
+				tableVer.mu.RLock()
+				tableData = tableVer.versions[tableVer.latest]
+				tableVer.mu.RUnlock()
+			}
+		} else {
+			m.mu.RUnlock()
+			tableVer.mu.RLock()
+			tableData = tableVer.versions[tableVer.latest]
+			tableVer.mu.RUnlock()
+		}
+	} else {
+		// Non-transaction query, read from latest version
+		m.mu.RUnlock()
+		tableVer.mu.RLock()
+		tableData = tableVer.versions[tableVer.latest]
+		tableVer.mu.RUnlock()
+	}
+
+	if tableData == nil {
+		return nil, false, domain.NewErrTableNotFound(tableName)
+	}
+	return tableData, hasAsOf, nil
+}
+
+// referencesVirtualColumns reports whether the filters or ORDER BY of options reference a VIRTUAL column
+func referencesVirtualColumns(options *domain.QueryOptions, schema *domain.TableInfo) bool {
+	if options == nil {
+		return false
+	}
+	for _, key := range options.SortKeys() {
+		if generated.IsVirtualColumn(key.Column, schema) {
+			return true
+		}
+	}
+	return filtersReferenceVirtualColumns(options.Filters, schema)
+}
+
+func filtersReferenceVirtualColumns(filters []domain.Filter, schema *domain.TableInfo) bool {
+	for _, f := range filters {
+		if f.Field != "" && generated.IsVirtualColumn(f.Field, schema) {
+			return true
+		}
+		if filtersReferenceVirtualColumns(f.SubFilters, schema) {
+			return true
+		}
+		if nested, ok := f.Value.([]domain.Filter); ok && filtersReferenceVirtualColumns(nested, schema) {
+			return true
+		}
+	}
+	return false
+}
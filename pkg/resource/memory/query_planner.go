@@ -2,6 +2,7 @@ package memory
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/util"
@@ -47,23 +48,69 @@ func (p *QueryPlanner) PlanQuery(tableName string, filters []domain.Filter, opti
 		Index:     nil,
 	}
 
+	// FORCE_INDEX hint：在任意等值条件中查找指定的索引，找到后将该条件放在首位进行索引查询
+	if options != nil && options.ForceIndex != "" {
+		for i, filter := range filters {
+			if filter.Operator != "=" {
+				continue
+			}
+			indexInfo := p.usableIndex(tableName, filter.Field, options)
+			if indexInfo == nil || !indexMatchesName(indexInfo, options.ForceIndex) {
+				continue
+			}
+			reordered := make([]domain.Filter, 0, len(filters))
+			reordered = append(reordered, filter)
+			reordered = append(reordered, filters[:i]...)
+			reordered = append(reordered, filters[i+1:]...)
+			plan.Filters = reordered
+			plan.Method = ScanMethodIndex
+			plan.Index = indexInfo
+			return plan, nil
+		}
+	}
+
 	// 检查是否可以使用索引（等值查询）
 	if len(filters) == 1 && filters[0].Operator == "=" {
-		index, err := p.indexManager.GetIndex(tableName, filters[0].Field)
-		if err == nil && index != nil {
+		if indexInfo := p.usableIndex(tableName, filters[0].Field, options); indexInfo != nil {
 			// 使用索引
-			indexInfo := index.GetIndexInfo()
-			if indexInfo.Type == IndexTypeBTree || indexInfo.Type == IndexTypeHash {
-				plan.Method = ScanMethodIndex
-				plan.Index = indexInfo
-				return plan, nil
-			}
+			plan.Method = ScanMethodIndex
+			plan.Index = indexInfo
+			return plan, nil
 		}
 	}
 
 	return plan, nil
 }
 
+// usableIndex 返回列上可用于点查询的索引，IGNORE_INDEX hint 排除的索引视为不可用
+func (p *QueryPlanner) usableIndex(tableName, columnName string, options *domain.QueryOptions) *IndexInfo {
+	index, err := p.indexManager.GetIndex(tableName, columnName)
+	if err != nil || index == nil {
+		return nil
+	}
+	indexInfo := index.GetIndexInfo()
+	if indexInfo.Type != IndexTypeBTree && indexInfo.Type != IndexTypeHash {
+		return nil
+	}
+	if options != nil {
+		for _, ignored := range options.IgnoreIndexes {
+			if indexMatchesName(indexInfo, ignored) {
+				return nil
+			}
+		}
+	}
+	return indexInfo
+}
+
+// indexMatchesName 判断 hint 中的索引名是否指向该索引
+// 内存索引使用自动生成的名称，因此单列索引也可以用列名引用
+func indexMatchesName(info *IndexInfo, name string) bool {
+	if strings.EqualFold(info.Name, name) {
+		return true
+	}
+	return len(info.Columns) == 1 && strings.EqualFold(info.Columns[0], name)
+}
+
 // ExecutePlan 执行查询计划
 func (p *QueryPlanner) ExecutePlan(plan *QueryPlan, tableData *TableData) (*domain.QueryResult, error) {
 	switch plan.Method {
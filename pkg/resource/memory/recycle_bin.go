@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== Recycle Bin (DROP TABLE / UNDROP TABLE) ====================
+
+// recycledTable is a dropped table kept in the recycle bin. It is invisible
+// to queries until it is restored by UndropTable.
+type recycledTable struct {
+	name      string
+	droppedAt time.Time
+	tableVer  *TableVersions
+	indexes   *TableIndexes
+}
+
+// SetRecycleBinRetention implements domain.RecycleBin. While retention is
+// positive, dropped tables are moved to the recycle bin and kept for
+// retention; 0 frees dropped tables immediately and empties the recycle bin.
+func (m *MVCCDataSource) SetRecycleBinRetention(retention time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recycleRetention = max(retention, 0)
+	m.purgeRecycleBinLocked(time.Now())
+}
+
+// RecycledTables implements domain.RecycleBin
+func (m *MVCCDataSource) RecycledTables() []domain.RecycledTable {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := time.Now()
+	tables := make([]domain.RecycledTable, 0, len(m.recycleBin))
+	for _, r := range m.recycleBin {
+		expiresAt := r.droppedAt.Add(m.recycleRetention)
+		if !expiresAt.After(now) {
+			continue
+		}
+		var rows int64
+		r.tableVer.mu.RLock()
+		if data := r.tableVer.versions[r.tableVer.latest]; data != nil && data.rows != nil {
+			rows = int64(data.rows.Len())
+		}
+		r.tableVer.mu.RUnlock()
+		tables = append(tables, domain.RecycledTable{Name: r.name, DroppedAt: r.droppedAt, ExpiresAt: expiresAt, Rows: rows})
+	}
+	return tables
+}
+
+// UndropTable implements domain.RecycleBin. The most recently dropped table
+// with the name is restored with its rows and indexes.
+func (m *MVCCDataSource) UndropTable(ctx context.Context, tableName string) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "undrop table")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.connected {
+		return domain.NewErrNotConnected("memory")
+	}
+	if _, exists := m.tables[tableName]; exists {
+		return domain.NewErrTableAlreadyExists(tableName)
+	}
+
+	m.purgeRecycleBinLocked(time.Now())
+	for i := len(m.recycleBin) - 1; i >= 0; i-- {
+		r := m.recycleBin[i]
+		if r.name != tableName {
+			continue
+		}
+		m.recycleBin = append(m.recycleBin[:i:i], m.recycleBin[i+1:]...)
+		m.tables[tableName] = r.tableVer
+		m.indexManager.attachTableIndexes(tableName, r.indexes)
+		return nil
+	}
+	return fmt.Errorf("table '%s' is not in the recycle bin", tableName)
+}
+
+// PurgeRecycleBin implements domain.RecycleBin
+func (m *MVCCDataSource) PurgeRecycleBin(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.purgeRecycleBinLocked(time.Now()), nil
+}
+
+// recycleLocked moves a table being dropped to the recycle bin and reports
+// whether it did. Temporary tables, partitioned tables and their partitions
+// are dropped for good. The caller must hold m.mu.
+func (m *MVCCDataSource) recycleLocked(tableName string, tableVer *TableVersions) bool {
+	if m.recycleRetention <= 0 || m.tempTables[tableName] || strings.Contains(tableName, partitionSeparator) {
+		return false
+	}
+	tableVer.mu.Lock()
+	latest := tableVer.versions[tableVer.latest]
+	if latest == nil || latest.schema.IsPartitioned() {
+		tableVer.mu.Unlock()
+		return false
+	}
+	// Only the latest version is restored, free the older ones
+	for ver, data := range tableVer.versions {
+		if ver != tableVer.latest && data.rows != nil {
+			data.rows.Release()
+			delete(tableVer.versions, ver)
+		}
+	}
+	tableVer.mu.Unlock()
+
+	m.recycleBin = append(m.recycleBin, &recycledTable{
+		name:      tableName,
+		droppedAt: time.Now(),
+		tableVer:  tableVer,
+		indexes:   m.indexManager.detachTableIndexes(tableName),
+	})
+	delete(m.tables, tableName)
+	return true
+}
+
+// purgeRecycleBinLocked frees the tables whose retention has expired and
+// returns their number. The caller must hold m.mu.
+func (m *MVCCDataSource) purgeRecycleBinLocked(now time.Time) int64 {
+	var purged int64
+	kept := m.recycleBin[:0]
+	for _, r := range m.recycleBin {
+		if m.recycleRetention > 0 && r.droppedAt.Add(m.recycleRetention).After(now) {
+			kept = append(kept, r)
+			continue
+		}
+		r.tableVer.mu.Lock()
+		for _, data := range r.tableVer.versions {
+			if data != nil && data.rows != nil {
+				data.rows.Release()
+			}
+		}
+		r.tableVer.mu.Unlock()
+		closeTableIndexes(r.indexes)
+		purged++
+	}
+	clear(m.recycleBin[len(kept):])
+	m.recycleBin = kept
+	return purged
+}
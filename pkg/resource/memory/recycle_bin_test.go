@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newRecycleBinTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ctx := context.Background()
+	ds := NewMVCCDataSource(nil)
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { ds.Close(ctx) })
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "orders",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+			{Name: "amount", Type: "INT"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if _, err := ds.Insert(ctx, "orders", []domain.Row{{"id": int64(1), "amount": int64(10)}, {"id": int64(2), "amount": int64(20)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return ds
+}
+
+func countTableRows(t *testing.T, ds *MVCCDataSource, table string) int {
+	t.Helper()
+	result, err := ds.Query(context.Background(), table, &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query(%s) error = %v", table, err)
+	}
+	return len(result.Rows)
+}
+
+// TestUndropTable verifies that a dropped table is kept in the recycle bin
+// and restored with its rows and indexes
+func TestUndropTable(t *testing.T) {
+	ds := newRecycleBinTestSource(t)
+	ctx := context.Background()
+	ds.SetRecycleBinRetention(time.Hour)
+	if err := ds.CreateIndex("orders", "amount", "btree", false); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	if _, err := ds.Query(ctx, "orders", &domain.QueryOptions{}); err == nil {
+		t.Fatal("dropped table is still visible")
+	}
+	recycled := ds.RecycledTables()
+	if len(recycled) != 1 || recycled[0].Name != "orders" || recycled[0].Rows != 2 {
+		t.Fatalf("RecycledTables() = %+v", recycled)
+	}
+
+	// A new table with the same name blocks UNDROP until it is dropped
+	if err := ds.CreateTable(ctx, &domain.TableInfo{Name: "orders", Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}}}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if err := ds.UndropTable(ctx, "orders"); err == nil {
+		t.Fatal("UndropTable() over an existing table should fail")
+	}
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+
+	// The most recently dropped table is restored first
+	if err := ds.UndropTable(ctx, "orders"); err != nil {
+		t.Fatalf("UndropTable() error = %v", err)
+	}
+	if n := countTableRows(t, ds, "orders"); n != 0 {
+		t.Errorf("restored table has %d rows, want 0", n)
+	}
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	if err := ds.UndropTable(ctx, "orders"); err != nil {
+		t.Fatalf("UndropTable() error = %v", err)
+	}
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	// Restores the empty table again, then the original one
+	if err := ds.UndropTable(ctx, "orders"); err != nil {
+		t.Fatalf("UndropTable() error = %v", err)
+	}
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	ds.mu.Lock()
+	ds.recycleBin = ds.recycleBin[:1]
+	ds.mu.Unlock()
+	if err := ds.UndropTable(ctx, "orders"); err != nil {
+		t.Fatalf("UndropTable() error = %v", err)
+	}
+	if n := countTableRows(t, ds, "orders"); n != 2 {
+		t.Errorf("restored table has %d rows, want 2", n)
+	}
+	if ds.indexManager.tables["orders"] == nil {
+		t.Error("indexes were not restored")
+	}
+
+	if err := ds.UndropTable(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "recycle bin") {
+		t.Errorf("UndropTable(missing) error = %v", err)
+	}
+}
+
+// TestRecycleBinPurge verifies that expired tables are freed and that
+// dropping without retention bypasses the recycle bin
+func TestRecycleBinPurge(t *testing.T) {
+	ds := newRecycleBinTestSource(t)
+	ctx := context.Background()
+
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	if len(ds.RecycledTables()) != 0 {
+		t.Fatal("table recycled without retention")
+	}
+
+	ds = newRecycleBinTestSource(t)
+	ds.SetRecycleBinRetention(time.Hour)
+	if err := ds.DropTable(ctx, "orders"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	ds.mu.Lock()
+	ds.recycleBin[0].droppedAt = time.Now().Add(-2 * time.Hour)
+	ds.mu.Unlock()
+	if len(ds.RecycledTables()) != 0 {
+		t.Error("expired table listed")
+	}
+	purged, err := ds.PurgeRecycleBin(ctx)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeRecycleBin() = %d, %v", purged, err)
+	}
+	if err := ds.UndropTable(ctx, "orders"); err == nil {
+		t.Error("expired table restored")
+	}
+}
+
+// TestFlashbackTable verifies that DML committed after the timestamp is reversed
+func TestFlashbackTable(t *testing.T) {
+	ds := newRecycleBinTestSource(t)
+	ctx := context.Background()
+
+	if _, err := ds.FlashbackTable(ctx, "orders", time.Now()); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Fatalf("FlashbackTable() without change log error = %v", err)
+	}
+	ds.SetChangeLogSize(100)
+	if _, err := ds.FlashbackTable(ctx, "orders", time.Now().Add(-time.Hour)); err == nil || !strings.Contains(err.Error(), "only covers") {
+		t.Fatalf("FlashbackTable() before the log error = %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	to := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	if _, err := ds.Insert(ctx, "orders", []domain.Row{{"id": int64(3), "amount": int64(30)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if _, err := ds.Update(ctx, "orders", []domain.Filter{{Field: "id", Operator: "=", Value: int64(1)}}, domain.Row{"amount": int64(99)}, nil); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := ds.Delete(ctx, "orders", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	reversed, err := ds.FlashbackTable(ctx, "orders", to)
+	if err != nil {
+		t.Fatalf("FlashbackTable() error = %v", err)
+	}
+	if reversed != 3 {
+		t.Errorf("reversed = %d, want 3", reversed)
+	}
+	result, err := ds.Query(ctx, "orders", &domain.QueryOptions{OrderBy: "id"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(result.Rows) != 2 || result.Rows[0]["amount"] != int64(10) || result.Rows[1]["amount"] != int64(20) {
+		t.Errorf("rows after flashback = %v", result.Rows)
+	}
+
+	// The reversal is recorded, so flashing back to before it redoes the DML
+	changes, _, err := ds.ChangesSince(ctx, 0)
+	if err != nil || len(changes) != 6 {
+		t.Fatalf("ChangesSince() = %d changes, %v", len(changes), err)
+	}
+
+	// Changes not in the log make the flashback fail
+	if _, err := ds.Insert(ctx, "orders", []domain.Row{{"id": int64(4), "amount": int64(40)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := ds.TruncateTable(ctx, "orders"); err != nil {
+		t.Fatalf("TruncateTable() error = %v", err)
+	}
+	if _, err := ds.FlashbackTable(ctx, "orders", to); err == nil || !strings.Contains(err.Error(), "no longer in the table") {
+		t.Errorf("FlashbackTable() after truncate error = %v", err)
+	}
+}
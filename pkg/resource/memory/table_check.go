@@ -0,0 +1,443 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// checkRetries bounds how often CheckTable restarts when the table is modified
+// while it is being checked; indexes are rebuilt after each write, so a check
+// that overlaps a write could report entries that were only briefly stale.
+const checkRetries = 3
+
+// ChecksumTable computes a checksum of the latest version of a table.
+// Each row is hashed from its column values in schema order and the row
+// hashes are summed, so the checksum does not depend on row order, page
+// layout or how a value was stored (an int 1 and an int64 1 hash the same).
+// Hidden columns hold derived data and are not part of the checksum.
+func (m *MVCCDataSource) ChecksumTable(ctx context.Context, tableName string) (*domain.TableChecksum, error) {
+	schema, parts, err := m.checkedTableParts(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(schema.Columns))
+	for _, col := range schema.Columns {
+		if !col.Hidden {
+			columns = append(columns, col.Name)
+		}
+	}
+
+	result := &domain.TableChecksum{Table: tableName}
+	h := fnv.New64a()
+	var buf []byte
+	for _, part := range parts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, row := range part.Rows() {
+			buf = buf[:0]
+			for _, col := range columns {
+				buf = appendChecksumValue(buf, row[col])
+			}
+			h.Reset()
+			h.Write(buf)
+			result.Checksum += h.Sum64()
+			result.Rows++
+		}
+	}
+	return result, nil
+}
+
+// appendChecksumValue appends a canonical encoding of v. Numbers are
+// normalized so the same value hashes the same whatever Go type holds it.
+func appendChecksumValue(buf []byte, v interface{}) []byte {
+	switch val := v.(type) {
+	case nil:
+		return append(buf, 0)
+	case bool:
+		if val {
+			return append(buf, 'b', 1)
+		}
+		return append(buf, 'b', 0)
+	case int:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case int8:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case int16:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case int32:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case int64:
+		return appendInt64(append(buf, 'i'), val)
+	case uint:
+		return appendUnsignedChecksum(buf, uint64(val))
+	case uint8:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case uint16:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case uint32:
+		return appendInt64(append(buf, 'i'), int64(val))
+	case uint64:
+		return appendUnsignedChecksum(buf, val)
+	case float32:
+		return appendFloatChecksum(buf, float64(val))
+	case float64:
+		return appendFloatChecksum(buf, val)
+	case string:
+		buf = appendUint32(append(buf, 's'), uint32(len(val)))
+		return append(buf, val...)
+	case []byte:
+		buf = appendUint32(append(buf, 's'), uint32(len(val)))
+		return append(buf, val...)
+	case time.Time:
+		return appendInt64(append(buf, 't'), val.UnixNano())
+	case []float32:
+		buf = appendUint32(append(buf, 'v'), uint32(len(val)))
+		for _, f := range val {
+			buf = appendUint32(buf, math.Float32bits(f))
+		}
+		return buf
+	default:
+		s := fmt.Sprintf("%v", val)
+		buf = appendUint32(append(buf, 'x'), uint32(len(s)))
+		return append(buf, s...)
+	}
+}
+
+func appendUnsignedChecksum(buf []byte, v uint64) []byte {
+	if v <= math.MaxInt64 {
+		return appendInt64(append(buf, 'i'), int64(v))
+	}
+	return appendUint64(append(buf, 'u'), v)
+}
+
+func appendFloatChecksum(buf []byte, f float64) []byte {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return appendInt64(append(buf, 'i'), int64(f))
+	}
+	return appendUint64(append(buf, 'f'), math.Float64bits(f))
+}
+
+// CheckTable verifies the latest version of a table: every index must agree
+// with the row store, unique and primary keys must not repeat, primary keys
+// must not be NULL, foreign keys must reference existing rows, vectors must
+// match their column dimension and auto-increment counters must be above the
+// stored values.
+func (m *MVCCDataSource) CheckTable(ctx context.Context, tableName string) (*domain.TableCheckResult, error) {
+	var result *domain.TableCheckResult
+	for attempt := 0; attempt < checkRetries; attempt++ {
+		schema, parts, err := m.checkedTableParts(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = m.checkTableParts(ctx, schema, parts); err != nil {
+			return nil, err
+		}
+		if result.OK() || !m.tablePartsChanged(tableName, parts) {
+			break
+		}
+	}
+	return result, nil
+}
+
+// checkedTableParts returns the schema of a table and the latest data of the
+// tables storing its rows: the table itself or, when partitioned, its partitions
+func (m *MVCCDataSource) checkedTableParts(tableName string) (*domain.TableInfo, []*TableData, error) {
+	if isPartitionTable(tableName) {
+		return nil, nil, domain.NewErrTableNotFound(tableName)
+	}
+	data, err := m.latestTableData(tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !data.schema.IsPartitioned() {
+		return data.schema, []*TableData{data}, nil
+	}
+
+	parts := make([]*TableData, 0, len(data.schema.Partition.Partitions))
+	for _, name := range partitionTables(data.schema) {
+		part, err := m.latestTableData(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, part)
+	}
+	return data.schema, parts, nil
+}
+
+// latestTableData returns the latest version of a table
+func (m *MVCCDataSource) latestTableData(tableName string) (*TableData, error) {
+	m.mu.RLock()
+	tableVer, ok := m.tables[tableName]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.RLock()
+	defer tableVer.mu.RUnlock()
+	data := tableVer.versions[tableVer.latest]
+	if data == nil {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+	return data, nil
+}
+
+// tablePartsChanged reports whether a table was modified after parts were read
+func (m *MVCCDataSource) tablePartsChanged(tableName string, parts []*TableData) bool {
+	_, latest, err := m.checkedTableParts(tableName)
+	if err != nil || len(latest) != len(parts) {
+		return true
+	}
+	for i := range parts {
+		if latest[i] != parts[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTableParts runs all checks on the rows of a table
+func (m *MVCCDataSource) checkTableParts(ctx context.Context, schema *domain.TableInfo, parts []*TableData) (*domain.TableCheckResult, error) {
+	result := &domain.TableCheckResult{Table: schema.Name}
+	var allRows []domain.Row
+	for _, part := range parts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rows := part.Rows()
+		result.Problems = append(result.Problems, m.checkIndexes(part.schema.Name, rows)...)
+		allRows = append(allRows, rows...)
+	}
+	result.Rows = int64(len(allRows))
+
+	result.Problems = append(result.Problems, checkColumnConstraints(schema, allRows)...)
+	result.Problems = append(result.Problems, m.checkForeignKeys(schema, allRows)...)
+	result.Problems = append(result.Problems, m.checkAutoIncrement(schema, allRows)...)
+	return result, nil
+}
+
+// checkIndexes compares the hash and B-tree indexes of a table with its rows.
+// Row IDs are positions in the row store starting at 1 (see RebuildIndex).
+func (m *MVCCDataSource) checkIndexes(tableName string, rows []domain.Row) []string {
+	infos, err := m.indexManager.GetTableIndexes(tableName)
+	if err != nil {
+		return nil
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	var problems []string
+	for _, info := range infos {
+		column := info.Columns[0]
+		idx, err := m.indexManager.GetIndex(tableName, column)
+		if err != nil || idx.GetIndexInfo() != info {
+			// Only the index registered for the first column is maintained
+			continue
+		}
+		entries, ok := indexEntries(idx)
+		if !ok {
+			continue
+		}
+
+		name := info.Name
+		if info.Expression != "" {
+			name = info.Expression
+		}
+
+		// Every row must be found under its key
+		missing, firstMissing := 0, 0
+		for i, row := range rows {
+			val, ok := row[column]
+			if !ok || !isComparableKey(val) {
+				continue
+			}
+			if !containsRowID(entries[val], int64(i+1)) {
+				if missing == 0 {
+					firstMissing = i + 1
+				}
+				missing++
+			}
+		}
+		if missing > 0 {
+			problems = append(problems, fmt.Sprintf("Index '%s' has no entry for %d of %d rows (first at row %d)", name, missing, len(rows), firstMissing))
+		}
+
+		// Every entry must point to a row holding its key
+		stale := 0
+		for key, rowIDs := range entries {
+			for _, id := range rowIDs {
+				if id < 1 || int(id) > len(rows) {
+					stale++
+					continue
+				}
+				val, ok := rows[id-1][column]
+				if !ok || !isComparableKey(val) || val != key {
+					stale++
+				}
+			}
+		}
+		if stale > 0 {
+			problems = append(problems, fmt.Sprintf("Index '%s' has %d entries that do not match the row store", name, stale))
+		}
+	}
+	return problems
+}
+
+// indexEntries returns a copy of the key to row ID mapping of a hash or B-tree index
+func indexEntries(idx Index) (map[interface{}][]int64, bool) {
+	var data map[interface{}][]int64
+	switch idx := idx.(type) {
+	case *BTreeIndex:
+		idx.mu.RLock()
+		defer idx.mu.RUnlock()
+		data = idx.data
+	case *HashIndex:
+		idx.mu.RLock()
+		defer idx.mu.RUnlock()
+		data = idx.data
+	default:
+		return nil, false
+	}
+	entries := make(map[interface{}][]int64, len(data))
+	for k, v := range data {
+		entries[k] = v
+	}
+	return entries, true
+}
+
+// isComparableKey reports whether v can be an index key
+func isComparableKey(v interface{}) bool {
+	return v == nil || reflect.TypeOf(v).Comparable()
+}
+
+func containsRowID(rowIDs []int64, id int64) bool {
+	for _, rowID := range rowIDs {
+		if rowID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkColumnConstraints checks primary keys, unique columns and vector dimensions
+func checkColumnConstraints(schema *domain.TableInfo, rows []domain.Row) []string {
+	var problems []string
+	for _, col := range schema.Columns {
+		if col.Primary || col.Unique {
+			seen := make(map[string]bool, len(rows))
+			nulls, duplicates := 0, 0
+			var firstDuplicate interface{}
+			for _, row := range rows {
+				val := row[col.Name]
+				if val == nil {
+					nulls++
+					continue
+				}
+				key := fmt.Sprintf("%v", val)
+				if seen[key] {
+					if duplicates == 0 {
+						firstDuplicate = val
+					}
+					duplicates++
+				}
+				seen[key] = true
+			}
+			if col.Primary && nulls > 0 {
+				problems = append(problems, fmt.Sprintf("Primary key column '%s' has %d NULL values", col.Name, nulls))
+			}
+			if duplicates > 0 {
+				problems = append(problems, fmt.Sprintf("Duplicate entry '%v' for key '%s' (%d duplicates)", firstDuplicate, col.Name, duplicates))
+			}
+		}
+
+		if col.IsVectorType() {
+			invalid := 0
+			for _, row := range rows {
+				if vec, ok := row[col.Name].([]float32); ok && len(vec) != col.VectorDim {
+					invalid++
+				}
+			}
+			if invalid > 0 {
+				problems = append(problems, fmt.Sprintf("Column '%s' has %d vectors whose dimension is not %d", col.Name, invalid, col.VectorDim))
+			}
+		}
+	}
+	return problems
+}
+
+// checkForeignKeys checks that every foreign key value exists in the referenced table
+func (m *MVCCDataSource) checkForeignKeys(schema *domain.TableInfo, rows []domain.Row) []string {
+	var problems []string
+	for _, col := range schema.Columns {
+		fk := col.ForeignKey
+		if fk == nil {
+			continue
+		}
+		_, parts, err := m.checkedTableParts(fk.Table)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Foreign key '%s' references missing table '%s'", col.Name, fk.Table))
+			continue
+		}
+		referenced := make(map[string]bool)
+		for _, part := range parts {
+			for _, row := range part.Rows() {
+				if val := row[fk.Column]; val != nil {
+					referenced[fmt.Sprintf("%v", val)] = true
+				}
+			}
+		}
+
+		orphans := 0
+		var firstOrphan interface{}
+		for _, row := range rows {
+			val := row[col.Name]
+			if val == nil || referenced[fmt.Sprintf("%v", val)] {
+				continue
+			}
+			if orphans == 0 {
+				firstOrphan = val
+			}
+			orphans++
+		}
+		if orphans > 0 {
+			problems = append(problems, fmt.Sprintf("Foreign key '%s' has %d values without a matching row in '%s.%s' (first '%v')", col.Name, orphans, fk.Table, fk.Column, firstOrphan))
+		}
+	}
+	return problems
+}
+
+// checkAutoIncrement checks that the next auto-increment value does not collide with stored values
+func (m *MVCCDataSource) checkAutoIncrement(schema *domain.TableInfo, rows []domain.Row) []string {
+	var problems []string
+	for _, col := range schema.Columns {
+		if !col.AutoIncrement {
+			continue
+		}
+		var maxVal int64
+		for _, row := range rows {
+			switch v := row[col.Name].(type) {
+			case int64:
+				maxVal = max(maxVal, v)
+			case int:
+				maxVal = max(maxVal, int64(v))
+			case float64:
+				maxVal = max(maxVal, int64(v))
+			}
+		}
+		m.mu.RLock()
+		counter := m.autoIncCounters[schema.Name+"."+col.Name]
+		m.mu.RUnlock()
+		if counter < maxVal {
+			problems = append(problems, fmt.Sprintf("Auto-increment counter of column '%s' is %d, below the largest value %d", col.Name, counter, maxVal))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newCheckTestTable(t *testing.T, ds *MVCCDataSource, rows []domain.Row) {
+	t.Helper()
+	ctx := context.Background()
+	err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "orders",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INTEGER", Primary: true, AutoIncrement: true},
+			{Name: "status", Type: "VARCHAR"},
+			{Name: "amount", Type: "DOUBLE", Nullable: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+	if _, err := ds.Insert(ctx, "orders", rows, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+}
+
+// TestChecksumTable verifies that the checksum depends on the rows only,
+// not on their order or on the Go type holding a number.
+func TestChecksumTable(t *testing.T) {
+	ctx := context.Background()
+	a := newSnapshotTestSource(t)
+	newCheckTestTable(t, a, []domain.Row{
+		{"id": int64(1), "status": "paid", "amount": 10.5},
+		{"id": int64(2), "status": "open", "amount": float64(3)},
+		{"id": int64(3), "status": "open"},
+	})
+	b := newSnapshotTestSource(t)
+	newCheckTestTable(t, b, []domain.Row{
+		{"id": 3, "status": "open"},
+		{"id": 2, "status": "open", "amount": int64(3)},
+		{"id": 1, "status": "paid", "amount": 10.5},
+	})
+
+	sumA, err := a.ChecksumTable(ctx, "orders")
+	if err != nil {
+		t.Fatalf("ChecksumTable failed: %v", err)
+	}
+	sumB, err := b.ChecksumTable(ctx, "orders")
+	if err != nil {
+		t.Fatalf("ChecksumTable failed: %v", err)
+	}
+	if sumA.Rows != 3 || sumA.Checksum == 0 || sumA.Checksum != sumB.Checksum {
+		t.Fatalf("expected equal checksums for the same rows, got %+v and %+v", sumA, sumB)
+	}
+
+	if _, err := b.Update(ctx, "orders", []domain.Filter{{Field: "id", Operator: "=", Value: int64(3)}}, domain.Row{"status": "paid"}, nil); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	sumB, _ = b.ChecksumTable(ctx, "orders")
+	if sumA.Checksum == sumB.Checksum {
+		t.Error("expected the checksum to change after an update")
+	}
+
+	if _, err := a.ChecksumTable(ctx, "missing"); err == nil {
+		t.Error("expected an error for a missing table")
+	}
+}
+
+// TestCheckTable verifies that a consistent table passes after writes and
+// that index, constraint and auto-increment corruption is reported.
+func TestCheckTable(t *testing.T) {
+	ctx := context.Background()
+	ds := newSnapshotTestSource(t)
+	newCheckTestTable(t, ds, []domain.Row{
+		{"status": "paid", "amount": 10.5},
+		{"status": "open"},
+		{"status": "open", "amount": 7.0},
+	})
+	if err := ds.CreateIndex("orders", "status", "hash", false); err != nil {
+		t.Fatalf("CreateIndex failed: %v", err)
+	}
+	ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "items",
+		Columns: []domain.ColumnInfo{
+			{Name: "order_id", Type: "INTEGER", ForeignKey: &domain.ForeignKeyInfo{Table: "orders", Column: "id"}},
+		},
+	})
+	ds.Insert(ctx, "items", []domain.Row{{"order_id": int64(1)}, {"order_id": int64(3)}}, nil)
+
+	ds.Update(ctx, "orders", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, domain.Row{"status": "paid"}, nil)
+	ds.Delete(ctx, "orders", []domain.Filter{{Field: "id", Operator: "=", Value: int64(2)}}, nil)
+	for _, table := range []string{"orders", "items"} {
+		result, err := ds.CheckTable(ctx, table)
+		if err != nil {
+			t.Fatalf("CheckTable failed: %v", err)
+		}
+		if !result.OK() {
+			t.Fatalf("expected %s to pass, got %v", table, result.Problems)
+		}
+	}
+
+	// Replace the rows without maintaining the index, counter or foreign keys
+	err := ds.BulkLoad("orders", func(addPage func(rows []domain.Row)) error {
+		addPage([]domain.Row{
+			{"id": int64(1), "status": "paid"},
+			{"id": int64(1), "status": "closed"},
+			{"id": int64(9), "status": "open"},
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("BulkLoad failed: %v", err)
+	}
+
+	result, err := ds.CheckTable(ctx, "orders")
+	if err != nil {
+		t.Fatalf("CheckTable failed: %v", err)
+	}
+	problems := strings.Join(result.Problems, "\n")
+	for _, want := range []string{
+		"has no entry for 2 of 3 rows (first at row 2)",
+		"do not match the row store",
+		"Duplicate entry '1' for key 'id'",
+		"Auto-increment counter of column 'id' is 3, below the largest value 9",
+	} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("expected a problem containing %q, got:\n%s", want, problems)
+		}
+	}
+
+	result, _ = ds.CheckTable(ctx, "items")
+	if len(result.Problems) != 1 || !strings.Contains(result.Problems[0], "1 values without a matching row in 'orders.id'") {
+		t.Errorf("expected an orphaned foreign key, got %v", result.Problems)
+	}
+}
@@ -2,11 +2,13 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/generated"
+	"github.com/kasuganosora/sqlexec/pkg/resource/util"
 )
 
 // ==================== Table Management ====================
@@ -20,10 +22,21 @@ func (m *MVCCDataSource) GetTables(ctx context.Context) ([]string, error) {
 		return nil, domain.NewErrNotConnected("memory")
 	}
 
+	// Inside a transaction, tables it dropped are hidden and tables it created are listed
+	snapshot := m.txnSnapshotLocked(ctx)
 	tables := make([]string, 0, len(m.tables))
 	for name := range m.tables {
-		// Exclude temporary tables
-		if !m.tempTables[name] {
+		// Exclude temporary tables and internal partition tables
+		if m.tempTables[name] || isPartitionTable(name) {
+			continue
+		}
+		if snapshot != nil && (snapshot.droppedTables[name] || snapshot.createdTables[name] != nil) {
+			continue
+		}
+		tables = append(tables, name)
+	}
+	if snapshot != nil {
+		for name := range snapshot.createdTables {
 			tables = append(tables, name)
 		}
 	}
@@ -41,7 +54,9 @@ func (m *MVCCDataSource) GetAllTables(ctx context.Context) ([]string, error) {
 
 	tables := make([]string, 0, len(m.tables))
 	for name := range m.tables {
-		tables = append(tables, name)
+		if !isPartitionTable(name) {
+			tables = append(tables, name)
+		}
 	}
 	return tables, nil
 }
@@ -67,60 +82,96 @@ func (m *MVCCDataSource) GetTableInfo(ctx context.Context, tableName string) (*d
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	tableVer, ok := m.tables[tableName]
+	tableVer, ok := m.tableLocked(ctx, tableName)
 	if !ok {
 		return nil, domain.NewErrTableNotFound(tableName)
 	}
 
-	tableVer.mu.RLock()
-	defer tableVer.mu.RUnlock()
-
-	// Get latest version data
-	latest := tableVer.versions[tableVer.latest]
-	if latest == nil {
+	// Inside a transaction the schema of its snapshot is returned
+	schema := m.visibleSchemaLocked(ctx, tableName, tableVer)
+	if schema == nil {
 		return nil, domain.NewErrTableNotFound(tableName)
 	}
 
-	// Deep copy table info
-	cols := make([]domain.ColumnInfo, len(latest.schema.Columns))
-	copy(cols, latest.schema.Columns)
+	// Deep copy table info; hidden columns are internal to the data source
+	cols := visibleColumns(schema.Columns)
 
 	// Deep copy table attributes
 	var atts map[string]interface{}
-	if latest.schema.Atts != nil {
-		atts = make(map[string]interface{}, len(latest.schema.Atts))
-		for k, v := range latest.schema.Atts {
+	if schema.Atts != nil {
+		atts = make(map[string]interface{}, len(schema.Atts))
+		for k, v := range schema.Atts {
 			atts[k] = v
 		}
 	}
 
 	return &domain.TableInfo{
-		Name:    latest.schema.Name,
-		Schema:  latest.schema.Schema,
-		Columns: cols,
-		Atts:    atts,
+		Name:      schema.Name,
+		Schema:    schema.Schema,
+		Columns:   cols,
+		Atts:      atts,
+		Partition: schema.Partition.Clone(),
+		TTL:       schema.TTL.Clone(),
 	}, nil
 }
 
 // CreateTable creates a table
+// For a partitioned table, one internal table is created per partition.
 func (m *MVCCDataSource) CreateTable(ctx context.Context, tableInfo *domain.TableInfo) error {
+	if err := util.ValidateTTL(tableInfo); err != nil {
+		return err
+	}
+	if tableInfo.Partition != nil {
+		if tableInfo.HasEncryptedColumns() {
+			return domain.NewErrUnsupportedOperation(string(m.config.Type), "encrypted columns on partitioned table")
+		}
+		return m.createPartitionedTable(ctx, tableInfo)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if _, ok := m.tables[tableInfo.Name]; ok {
 		return domain.NewErrTableAlreadyExists(tableInfo.Name)
 	}
+	return m.createTableLocked(tableInfo)
+}
+
+// createTableLocked creates a table; the caller must hold m.mu
+func (m *MVCCDataSource) createTableLocked(tableInfo *domain.TableInfo) error {
+	tableVer, err := m.newTableVersionsLocked(tableInfo)
+	if err != nil {
+		return err
+	}
+	m.tables[tableInfo.Name] = tableVer
+
+	// If temporary table, add to temporary table list
+	if tableInfo.Temporary {
+		m.tempTables[tableInfo.Name] = true
+	}
 
+	return nil
+}
+
+// newTableVersionsLocked builds the storage of a new, empty table without
+// registering it; the caller must hold m.mu
+func (m *MVCCDataSource) newTableVersionsLocked(tableInfo *domain.TableInfo) (*TableVersions, error) {
 	// Validate generated column definitions (if any)
 	validator := &generated.GeneratedColumnValidator{}
 	if err := validator.ValidateSchema(tableInfo); err != nil {
-		return domain.NewErrGeneratedColumnValidation(err.Error())
+		return nil, domain.NewErrGeneratedColumnValidation(err.Error())
 	}
 
 	// Deep copy table info
 	cols := make([]domain.ColumnInfo, len(tableInfo.Columns))
 	copy(cols, tableInfo.Columns)
 
+	// Validate ENCRYPTED columns and add their hidden blind index columns
+	cols, err := m.prepareEncryptedColumns(tableInfo.Name, cols)
+	if err != nil {
+		return nil, err
+	}
+
 	// Deep copy table attributes
 	var atts map[string]interface{}
 	if tableInfo.Atts != nil {
@@ -141,34 +192,48 @@ func (m *MVCCDataSource) CreateTable(ctx context.Context, tableInfo *domain.Tabl
 			Columns:   cols,
 			Temporary: tableInfo.Temporary,
 			Atts:      atts,
+			Partition: tableInfo.Partition.Clone(),
+			TTL:       tableInfo.TTL.Clone(),
 		},
 		rows: NewEmptyPagedRows(m.bufferPool, 0),
 	}
 
-	m.tables[tableInfo.Name] = &TableVersions{
+	return &TableVersions{
 		versions: map[int64]*TableData{
 			m.currentVer: versionData,
 		},
 		latest: m.currentVer,
-	}
+	}, nil
+}
 
-	// If temporary table, add to temporary table list
-	if tableInfo.Temporary {
-		m.tempTables[tableInfo.Name] = true
+// DropTable drops a table (and all its partitions)
+func (m *MVCCDataSource) DropTable(ctx context.Context, tableName string) error {
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return err
 	}
 
-	return nil
-}
+	if schema := m.latestSchema(tableName); schema != nil && schema.IsPartitioned() {
+		for _, name := range partitionTables(schema) {
+			_ = m.DropTable(ctx, name)
+		}
+	}
 
-// DropTable drops a table
-func (m *MVCCDataSource) DropTable(ctx context.Context, tableName string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.dropTableLocked(tableName)
+}
 
+// dropTableLocked drops a single table; the caller must hold m.mu
+func (m *MVCCDataSource) dropTableLocked(tableName string) error {
 	tableVer, ok := m.tables[tableName]
 	if !ok {
 		return domain.NewErrTableNotFound(tableName)
 	}
+	if m.recycleLocked(tableName, tableVer) {
+		return nil
+	}
 
 	// Release all PagedRows across all versions to free buffer pool memory
 	tableVer.mu.Lock()
@@ -186,8 +251,22 @@ func (m *MVCCDataSource) DropTable(ctx context.Context, tableName string) error
 	return nil
 }
 
-// TruncateTable truncates a table
+// TruncateTable truncates a table (and all its partitions)
 func (m *MVCCDataSource) TruncateTable(ctx context.Context, tableName string) error {
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return err
+	}
+
+	if schema := m.latestSchema(tableName); schema != nil && schema.IsPartitioned() {
+		for _, name := range partitionTables(schema) {
+			if err := m.TruncateTable(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -221,10 +300,12 @@ func (m *MVCCDataSource) TruncateTable(ctx context.Context, tableName string) er
 		version:   m.currentVer,
 		createdAt: time.Now(),
 		schema: &domain.TableInfo{
-			Name:    latestData.schema.Name,
-			Schema:  latestData.schema.Schema,
-			Columns: latestData.schema.Columns,
-			Atts:    atts,
+			Name:      latestData.schema.Name,
+			Schema:    latestData.schema.Schema,
+			Columns:   latestData.schema.Columns,
+			Atts:      atts,
+			Partition: latestData.schema.Partition.Clone(),
+			TTL:       latestData.schema.TTL.Clone(),
 		},
 		rows: NewEmptyPagedRows(m.bufferPool, 0),
 	}
@@ -235,6 +316,52 @@ func (m *MVCCDataSource) TruncateTable(ctx context.Context, tableName string) er
 	return nil
 }
 
+// SetTableTTL sets or removes (ttl == nil) the row expiration policy of a table
+func (m *MVCCDataSource) SetTableTTL(ctx context.Context, tableName string, ttl *domain.TTLInfo) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "set table ttl")
+	}
+
+	owner := m.locks.nextTransientOwner()
+	defer m.locks.release(owner)
+	if err := m.lockTable(ctx, owner, tableName, true); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tableVer, ok := m.tables[tableName]
+	if !ok {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.Lock()
+	defer tableVer.mu.Unlock()
+
+	latestData := tableVer.versions[tableVer.latest]
+	if latestData == nil {
+		return domain.NewErrTableNotFound(tableName)
+	}
+
+	schema := deepCopySchema(latestData.schema)
+	schema.TTL = ttl.Clone()
+	if err := util.ValidateTTL(schema); err != nil {
+		return err
+	}
+
+	// Only the schema changes; the new version keeps the existing rows
+	m.currentVer++
+	tableVer.versions[m.currentVer] = &TableData{
+		version:   m.currentVer,
+		createdAt: time.Now(),
+		schema:    schema,
+		rows:      NewPagedRows(m.bufferPool, latestData.Rows(), 0, tableName, m.currentVer),
+	}
+	tableVer.latest = m.currentVer
+	return nil
+}
+
 // CreateIndex creates an index (backward compatibility wrapper)
 func (m *MVCCDataSource) CreateIndex(tableName, columnName, indexType string, unique bool) error {
 	return m.CreateIndexWithColumns(tableName, []string{columnName}, indexType, unique)
@@ -242,17 +369,38 @@ func (m *MVCCDataSource) CreateIndex(tableName, columnName, indexType string, un
 
 // CreateIndexWithColumns creates an index on one or more columns (composite index support)
 func (m *MVCCDataSource) CreateIndexWithColumns(tableName string, columnNames []string, indexType string, unique bool) error {
-	// Convert index type
-	var idxType IndexType
-	switch indexType {
-	case "btree":
-		idxType = IndexTypeBTree
-	case "hash":
-		idxType = IndexTypeHash
-	case "fulltext":
-		idxType = IndexTypeFullText
-	default:
-		idxType = IndexTypeBTree // Default
+	return m.CreateIndexWithColumnsContext(context.Background(), tableName, columnNames, indexType, unique)
+}
+
+// CreateIndexWithColumnsContext is CreateIndexWithColumns with the backfill
+// of existing rows reported through the progress callback of ctx
+func (m *MVCCDataSource) CreateIndexWithColumnsContext(ctx context.Context, tableName string, columnNames []string, indexType string, unique bool) error {
+	idxType := toIndexType(indexType)
+
+	// Snapshot the latest version so the new index can be populated from existing rows
+	var latestData *TableData
+	m.mu.RLock()
+	tableVer, ok := m.tables[tableName]
+	m.mu.RUnlock()
+	if ok {
+		tableVer.mu.RLock()
+		latestData = tableVer.versions[tableVer.latest]
+		tableVer.mu.RUnlock()
+	}
+
+	// Partitioned tables are indexed per partition
+	if latestData != nil && latestData.schema.IsPartitioned() {
+		return m.partitionedCreateIndex(latestData.schema, columnNames, indexType, unique)
+	}
+
+	// VIRTUAL generated columns are never stored, so they cannot be indexed
+	if latestData != nil {
+		for _, colName := range columnNames {
+			if generated.IsVirtualColumn(colName, latestData.schema) {
+				return domain.NewErrIndexCreationFailed(tableName, strings.Join(columnNames, ","),
+					fmt.Sprintf("cannot index VIRTUAL generated column '%s'", colName))
+			}
+		}
 	}
 
 	// Create index
@@ -261,20 +409,62 @@ func (m *MVCCDataSource) CreateIndexWithColumns(tableName string, columnNames []
 		return domain.NewErrIndexCreationFailed(tableName, strings.Join(columnNames, ","), err.Error())
 	}
 
+	// Backfill the index (and the table's other indexes) from existing rows
+	if latestData != nil {
+		rows := latestData.Rows()
+		total := int64(len(rows))
+		info := fmt.Sprintf("Building index on %s(%s)", tableName, strings.Join(columnNames, ","))
+		_ = m.indexManager.RebuildIndexWithProgress(tableName, latestData.schema, rows, func(done int64) {
+			domain.ReportProgress(ctx, domain.Progress{Stage: 1, MaxStage: 1, Done: done, Total: total, Info: info})
+		})
+	}
+
 	return nil
 }
 
+// toIndexType converts an index type name to IndexType (btree by default)
+func toIndexType(indexType string) IndexType {
+	switch indexType {
+	case "hash":
+		return IndexTypeHash
+	case "fulltext":
+		return IndexTypeFullText
+	default:
+		return IndexTypeBTree
+	}
+}
+
 // DropIndex drops an index
+// Dropping a functional index also removes the hidden column that stores its keys.
 func (m *MVCCDataSource) DropIndex(tableName, indexName string) error {
+	if schema := m.latestSchema(tableName); schema != nil && schema.IsPartitioned() {
+		return m.partitionedDropIndex(schema, indexName)
+	}
+
+	var keyColumn string
+	for _, idx := range m.FunctionalIndexes(context.Background(), tableName) {
+		if idx.Name == indexName {
+			keyColumn = idx.Column
+		}
+	}
+
 	err := m.indexManager.DropIndex(tableName, indexName)
 	if err != nil {
 		return domain.NewErrIndexDropFailed(tableName, indexName, err.Error())
 	}
 
+	if keyColumn != "" {
+		if err := m.dropFunctionalIndexColumn(context.Background(), tableName, keyColumn); err != nil {
+			return domain.NewErrIndexDropFailed(tableName, indexName, err.Error())
+		}
+	}
 	return nil
 }
 
 // GetTableIndexes returns index metadata for all indexes on a table
 func (m *MVCCDataSource) GetTableIndexes(tableName string) ([]*IndexInfo, error) {
+	if schema := m.latestSchema(tableName); schema != nil && schema.IsPartitioned() {
+		return m.partitionedTableIndexes(schema)
+	}
 	return m.indexManager.GetTableIndexes(tableName)
 }
@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// Table snapshots store a table in a binary file (little-endian):
+//
+//	[magic:8 bytes "SQLXSNAP"][version:uint16]
+//	[metaLen:uint32][meta:JSON snapshotMeta]
+//	blocks of up to snapshotBlockRows rows, stored column by column:
+//	  [rowCount:uint32]
+//	  for each schema column: rowCount values encoded as in page_codec
+//	[0:uint32] end of blocks
+//	[checksum:uint32] CRC-32 (Castagnoli) of all preceding bytes
+//
+// Strings share one dictionary for the whole file (see stringEncoder), so
+// enum-like columns are stored once per distinct value.
+const (
+	snapshotMagic         = "SQLXSNAP"
+	snapshotFormatVersion = 1
+	snapshotBlockRows     = defaultPageSize
+)
+
+var snapshotCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// snapshotMeta is the JSON header of a table snapshot
+type snapshotMeta struct {
+	Table     *domain.TableInfo `json:"table"`
+	Rows      int64             `json:"rows"`
+	Indexes   []*IndexInfo      `json:"indexes,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// AutoIncrement holds the last auto-increment value of each column
+	AutoIncrement map[string]int64 `json:"auto_increment,omitempty"`
+}
+
+// ExportTable writes the latest version of a table to a snapshot file.
+// The file is written to a temporary path and renamed, so an existing
+// snapshot is only replaced by a complete one.
+func (m *MVCCDataSource) ExportTable(ctx context.Context, tableName, path string) (*domain.TableSnapshotInfo, error) {
+	m.mu.RLock()
+	tableVer, ok := m.tables[tableName]
+	autoInc := make(map[string]int64)
+	prefix := tableName + "."
+	for key, v := range m.autoIncCounters {
+		if strings.HasPrefix(key, prefix) {
+			autoInc[key[len(prefix):]] = v
+		}
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.RLock()
+	data := tableVer.versions[tableVer.latest]
+	tableVer.mu.RUnlock()
+	if data == nil {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+	if data.schema.IsPartitioned() {
+		return nil, fmt.Errorf("cannot export partitioned table '%s'", tableName)
+	}
+
+	indexes, _ := m.indexManager.GetTableIndexes(tableName)
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+
+	rows := data.Rows()
+	meta, err := json.Marshal(&snapshotMeta{
+		Table:         data.schema,
+		Rows:          int64(len(rows)),
+		Indexes:       indexes,
+		CreatedAt:     time.Now(),
+		AutoIncrement: autoInc,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode snapshot header: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	if err := writeSnapshot(ctx, f, data.schema, meta, rows); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	info := &domain.TableSnapshotInfo{Table: tableName, Rows: int64(len(rows))}
+	if stat, err := os.Stat(path); err == nil {
+		info.Bytes = stat.Size()
+	}
+	return info, nil
+}
+
+// writeSnapshot writes the snapshot header, the row blocks and the checksum to w
+func writeSnapshot(ctx context.Context, w io.Writer, schema *domain.TableInfo, meta []byte, rows []domain.Row) error {
+	crc := crc32.New(snapshotCRCTable)
+	bw := bufio.NewWriterSize(io.MultiWriter(w, crc), 1<<20)
+
+	buf := append([]byte(nil), snapshotMagic...)
+	buf = appendUint16(buf, snapshotFormatVersion)
+	buf = appendUint32(buf, uint32(len(meta)))
+	buf = append(buf, meta...)
+	if _, err := bw.Write(buf); err != nil {
+		return err
+	}
+
+	total := int64(len(rows))
+	info := fmt.Sprintf("Exporting table %s", schema.Name)
+	strs := &stringEncoder{dict: make(map[string]uint32), last: make(map[string]string)}
+	for start := 0; start < len(rows); start += snapshotBlockRows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		block := rows[start:min(start+snapshotBlockRows, len(rows))]
+
+		buf = appendUint32(buf[:0], uint32(len(block)))
+		for _, col := range schema.Columns {
+			for _, row := range block {
+				v := row[col.Name]
+				if s, ok := v.(string); ok {
+					buf = strs.appendString(buf, col.Name, s)
+					continue
+				}
+				var err error
+				if buf, err = appendValue(buf, v); err != nil {
+					return fmt.Errorf("column '%s': %w", col.Name, err)
+				}
+			}
+		}
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+		domain.ReportProgress(ctx, domain.Progress{Stage: 1, MaxStage: 1, Done: int64(start + len(block)), Total: total, Info: info})
+	}
+
+	if _, err := bw.Write(appendUint32(buf[:0], 0)); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(appendUint32(nil, crc.Sum32()))
+	return err
+}
+
+// ImportTable creates a table from a snapshot file, loads its rows and
+// rebuilds its indexes. The whole file is validated before the table is created.
+func (m *MVCCDataSource) ImportTable(ctx context.Context, tableName, path string) (*domain.TableSnapshotInfo, error) {
+	if !m.IsWritable() {
+		return nil, domain.NewErrReadOnly(string(m.config.Type), "import table")
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	meta, body, err := readSnapshotHeader(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot file '%s': %w", path, err)
+	}
+
+	schema := meta.Table
+	if tableName != "" {
+		schema.Name = tableName
+	}
+	schema.Temporary = false
+	if err := m.CreateTable(ctx, schema); err != nil {
+		return nil, err
+	}
+
+	if err := m.loadSnapshot(ctx, schema, meta, body); err != nil {
+		_ = m.DropTable(context.Background(), schema.Name)
+		return nil, fmt.Errorf("import table '%s' failed: %w", schema.Name, err)
+	}
+
+	return &domain.TableSnapshotInfo{Table: schema.Name, Rows: meta.Rows, Bytes: int64(len(file))}, nil
+}
+
+// readSnapshotHeader validates the magic, version and checksum of a snapshot
+// and returns its header and the encoded row blocks
+func readSnapshotHeader(file []byte) (*snapshotMeta, []byte, error) {
+	headerLen := len(snapshotMagic) + 2 + 4
+	if len(file) < headerLen+4 || string(file[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, nil, fmt.Errorf("not a table snapshot")
+	}
+	if version := readUint16(file, len(snapshotMagic)); version != snapshotFormatVersion {
+		return nil, nil, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	content := file[:len(file)-4]
+	if sum := binary.LittleEndian.Uint32(file[len(file)-4:]); crc32.Checksum(content, snapshotCRCTable) != sum {
+		return nil, nil, fmt.Errorf("checksum mismatch")
+	}
+
+	metaLen := int(readUint32(content, len(snapshotMagic)+2))
+	if headerLen+metaLen > len(content) {
+		return nil, nil, fmt.Errorf("truncated header")
+	}
+	var meta snapshotMeta
+	if err := json.Unmarshal(content[headerLen:headerLen+metaLen], &meta); err != nil {
+		return nil, nil, fmt.Errorf("decode header: %w", err)
+	}
+	if meta.Table == nil || len(meta.Table.Columns) == 0 {
+		return nil, nil, fmt.Errorf("snapshot has no table schema")
+	}
+	return &meta, content[headerLen+metaLen:], nil
+}
+
+// loadSnapshot loads the row blocks of a snapshot into a newly created table
+// and restores its indexes and auto-increment counters
+func (m *MVCCDataSource) loadSnapshot(ctx context.Context, schema *domain.TableInfo, meta *snapshotMeta, body []byte) error {
+	var loaded int64
+	info := fmt.Sprintf("Importing table %s", schema.Name)
+	err := m.BulkLoad(schema.Name, func(addPage func(rows []domain.Row)) error {
+		strs := &stringDecoder{last: make(map[string]string)}
+		pos := 0
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if pos+4 > len(body) {
+				return fmt.Errorf("truncated row block")
+			}
+			n := int(readUint32(body, pos))
+			pos += 4
+			if n == 0 {
+				break
+			}
+
+			rows := make([]domain.Row, n)
+			for i := range rows {
+				rows[i] = make(domain.Row, len(schema.Columns))
+			}
+			for _, col := range schema.Columns {
+				for _, row := range rows {
+					var val interface{}
+					var err error
+					if pos < len(body) && isStringTag(body[pos]) {
+						val, pos, err = strs.readString(body, pos, col.Name)
+					} else {
+						val, pos, err = readValue(body, pos)
+					}
+					if err != nil {
+						return fmt.Errorf("column '%s': %w", col.Name, err)
+					}
+					if val != nil {
+						row[col.Name] = val
+					}
+				}
+			}
+			addPage(rows)
+			loaded += int64(n)
+			domain.ReportProgress(ctx, domain.Progress{Stage: 1, MaxStage: 1, Done: loaded, Total: meta.Rows, Info: info})
+		}
+		if loaded != meta.Rows {
+			return fmt.Errorf("expected %d rows, found %d", meta.Rows, loaded)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range meta.Indexes {
+		if idx.Expression != "" && len(idx.Columns) == 1 {
+			_, err = m.indexManager.CreateFunctionalIndex(schema.Name, idx.Name, idx.Columns[0], idx.Expression, idx.Type, idx.Unique)
+		} else {
+			_, err = m.indexManager.CreateIndexWithColumns(schema.Name, idx.Columns, idx.Type, idx.Unique)
+		}
+		if err != nil {
+			return fmt.Errorf("restore index '%s': %w", idx.Name, err)
+		}
+	}
+	if len(meta.Indexes) > 0 {
+		latestSchema, rows, err := m.GetLatestTableData(schema.Name)
+		if err != nil {
+			return err
+		}
+		if err := m.indexManager.RebuildIndex(schema.Name, latestSchema, rows); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	for col, v := range meta.AutoIncrement {
+		m.autoIncCounters[schema.Name+"."+col] = v
+	}
+	m.mu.Unlock()
+	return nil
+}
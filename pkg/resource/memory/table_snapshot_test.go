@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newSnapshotTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "test",
+		Writable: true,
+	})
+	ds.Connect(context.Background())
+	return ds
+}
+
+// TestTableSnapshot_RoundTrip verifies that a table exported to a snapshot is
+// imported with the same schema, rows, indexes and auto-increment counter.
+func TestTableSnapshot_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newSnapshotTestSource(t)
+	src.CreateTable(ctx, &domain.TableInfo{
+		Name: "events",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INTEGER", Primary: true, AutoIncrement: true},
+			{Name: "kind", Type: "VARCHAR"},
+			{Name: "score", Type: "DOUBLE", Nullable: true},
+			{Name: "at", Type: "DATETIME"},
+			{Name: "embedding", Type: "VECTOR", VectorDim: 2},
+		},
+	})
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := make([]domain.Row, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		row := domain.Row{
+			"kind":      []string{"click", "view", "purchase"}[i%3],
+			"at":        at.Add(time.Duration(i) * time.Second),
+			"embedding": []float32{float32(i), 0.5},
+		}
+		if i%2 == 0 {
+			row["score"] = float64(i) / 2
+		}
+		rows = append(rows, row)
+	}
+	if _, err := src.Insert(ctx, "events", rows, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if err := src.CreateIndex("events", "kind", "hash", false); err != nil {
+		t.Fatalf("CreateIndex failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "events.snap")
+	info, err := src.ExportTable(ctx, "events", path)
+	if err != nil {
+		t.Fatalf("ExportTable failed: %v", err)
+	}
+	if info.Rows != 5000 || info.Bytes == 0 {
+		t.Fatalf("unexpected export info: %+v", info)
+	}
+
+	dst := newSnapshotTestSource(t)
+	info, err = dst.ImportTable(ctx, "events_copy", path)
+	if err != nil {
+		t.Fatalf("ImportTable failed: %v", err)
+	}
+	if info.Table != "events_copy" || info.Rows != 5000 {
+		t.Fatalf("unexpected import info: %+v", info)
+	}
+
+	schema, imported, err := dst.GetLatestTableData("events_copy")
+	if err != nil {
+		t.Fatalf("GetLatestTableData failed: %v", err)
+	}
+	if len(schema.Columns) != 5 || !schema.Columns[0].AutoIncrement || schema.Columns[4].VectorDim != 2 {
+		t.Fatalf("unexpected schema: %+v", schema.Columns)
+	}
+	if len(imported) != 5000 {
+		t.Fatalf("expected 5000 rows, got %d", len(imported))
+	}
+	row := imported[4001]
+	if row["id"] != int64(4002) || row["kind"] != "purchase" || row["score"] != nil {
+		t.Errorf("unexpected row: %v", row)
+	}
+	if ts, ok := row["at"].(time.Time); !ok || !ts.Equal(at.Add(4001*time.Second)) {
+		t.Errorf("unexpected time value: %v", row["at"])
+	}
+	if vec, ok := row["embedding"].([]float32); !ok || len(vec) != 2 || vec[0] != 4001 {
+		t.Errorf("unexpected vector value: %v", row["embedding"])
+	}
+
+	indexes, err := dst.GetTableIndexes("events_copy")
+	if err != nil || len(indexes) != 1 || indexes[0].Type != IndexTypeHash {
+		t.Fatalf("expected the hash index to be restored, got %v (%v)", indexes, err)
+	}
+	result, err := dst.Query(ctx, "events_copy", &domain.QueryOptions{
+		Filters: []domain.Filter{{Field: "kind", Operator: "=", Value: "purchase"}},
+	})
+	if err != nil || len(result.Rows) != 1666 {
+		t.Fatalf("expected 1666 rows from the index, got %v (%v)", result, err)
+	}
+
+	if _, err := dst.Insert(ctx, "events_copy", []domain.Row{{"kind": "view"}}, nil); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	_, imported, _ = dst.GetLatestTableData("events_copy")
+	if id := imported[len(imported)-1]["id"]; id != int64(5001) {
+		t.Errorf("expected auto-increment to continue at 5001, got %v", id)
+	}
+
+	if _, err := dst.ImportTable(ctx, "events_copy", path); err == nil {
+		t.Error("expected importing into an existing table to fail")
+	}
+}
+
+// TestTableSnapshot_Validation verifies that corrupt or foreign files are rejected
+// without creating the table.
+func TestTableSnapshot_Validation(t *testing.T) {
+	ctx := context.Background()
+	src := newSnapshotTestSource(t)
+	src.CreateTable(ctx, &domain.TableInfo{
+		Name:    "t",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INTEGER"}, {Name: "name", Type: "VARCHAR"}},
+	})
+	src.Insert(ctx, "t", []domain.Row{{"id": int64(1), "name": "a"}, {"id": int64(2), "name": "b"}}, nil)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "t.snap")
+	if _, err := src.ExportTable(ctx, "t", path); err != nil {
+		t.Fatalf("ExportTable failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)-10] ^= 0xFF
+	corruptPath := filepath.Join(dir, "corrupt.snap")
+	os.WriteFile(corruptPath, corrupt, 0o644)
+
+	newer := append([]byte(nil), data...)
+	newer[len(snapshotMagic)] = snapshotFormatVersion + 1
+	newerPath := filepath.Join(dir, "newer.snap")
+	os.WriteFile(newerPath, newer, 0o644)
+
+	otherPath := filepath.Join(dir, "other.snap")
+	os.WriteFile(otherPath, []byte("id,name\n1,a\n"), 0o644)
+
+	dst := newSnapshotTestSource(t)
+	for file, want := range map[string]string{
+		corruptPath: "checksum mismatch",
+		newerPath:   "unsupported snapshot version",
+		otherPath:   "not a table snapshot",
+	} {
+		_, err := dst.ImportTable(ctx, "", file)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", filepath.Base(file), want, err)
+		}
+	}
+	if tables, _ := dst.GetTables(ctx); len(tables) != 0 {
+		t.Errorf("expected no tables after failed imports, got %v", tables)
+	}
+
+	// Without a name the table name stored in the snapshot is used
+	info, err := dst.ImportTable(ctx, "", path)
+	if err != nil || info.Table != "t" || info.Rows != 2 {
+		t.Fatalf("ImportTable failed: %+v (%v)", info, err)
+	}
+}
@@ -13,8 +13,10 @@ import (
 // BeginTransaction implements TransactionalDataSource interface
 func (m *MVCCDataSource) BeginTransaction(ctx context.Context, options *domain.TransactionOptions) (domain.Transaction, error) {
 	readOnly := false
+	isolation := ""
 	if options != nil {
 		readOnly = options.ReadOnly
+		isolation = options.IsolationLevel
 	}
 
 	txnID, err := m.BeginTx(ctx, readOnly)
@@ -23,8 +25,9 @@ func (m *MVCCDataSource) BeginTransaction(ctx context.Context, options *domain.T
 	}
 
 	return &MVCCTransaction{
-		ds:    m,
-		txnID: txnID,
+		ds:        m,
+		txnID:     txnID,
+		isolation: normalizeIsolation(isolation),
 	}, nil
 }
 
@@ -58,6 +61,8 @@ func (m *MVCCDataSource) BeginTx(ctx context.Context, readOnly bool) (int64, err
 		startVer:       m.currentVer,
 		createdAt:      time.Now(),
 		tableSnapshots: tableSnapshots,
+		createdTables:  make(map[string]*TableVersions),
+		droppedTables:  make(map[string]bool),
 	}
 
 	txn := &Transaction{
@@ -74,6 +79,8 @@ func (m *MVCCDataSource) BeginTx(ctx context.Context, readOnly bool) (int64, err
 
 // CommitTx commits a transaction (COW optimization with row-level COW)
 func (m *MVCCDataSource) CommitTx(ctx context.Context, txnID int64) error {
+	// Deliver the recorded changes once the global lock is released
+	defer m.changes.flush()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -81,6 +88,7 @@ func (m *MVCCDataSource) CommitTx(ctx context.Context, txnID int64) error {
 	if !ok {
 		return domain.NewErrTransactionNotFound(txnID)
 	}
+	defer m.locks.release(txnID)
 
 	snapshot, ok := m.snapshots[txnID]
 	if !ok {
@@ -94,6 +102,13 @@ func (m *MVCCDataSource) CommitTx(ctx context.Context, txnID int64) error {
 		return nil
 	}
 
+	// Apply staged DDL first so the row changes below land in the new tables
+	if err := m.applyTxnDDLLocked(snapshot); err != nil {
+		delete(m.activeTxns, txnID)
+		delete(m.snapshots, txnID)
+		return err
+	}
+
 	// Write transaction: commit only modified tables
 	var commitErr error
 	for tableName, cowSnapshot := range snapshot.tableSnapshots {
@@ -104,9 +119,9 @@ func (m *MVCCDataSource) CommitTx(ctx context.Context, txnID int64) error {
 				cowSnapshot.mu.Lock()
 				defer cowSnapshot.mu.Unlock()
 
-				// Check if there are row-level modifications
-				if len(cowSnapshot.rowCopies) == 0 && len(cowSnapshot.deletedRows) == 0 {
-					// No rows modified, no need to create new version
+				// Check if there are row-level modifications or schema changes
+				if !cowSnapshot.modifiedLocked() {
+					// Nothing modified, no need to create new version
 					return
 				}
 
@@ -115,35 +130,7 @@ func (m *MVCCDataSource) CommitTx(ctx context.Context, txnID int64) error {
 				defer tableVer.mu.Unlock()
 				m.currentVer++
 
-				// Merge base data and row-level modifications
-				baseRows := cowSnapshot.baseData.Rows()
-				newRows := make([]domain.Row, 0, len(baseRows))
-				for i, row := range baseRows {
-					rowID := int64(i + 1)
-
-					// Skip deleted rows
-					if cowSnapshot.deletedRows[rowID] {
-						continue
-					}
-
-					// Use modified row or deep copy original
-					if modifiedRow, ok := cowSnapshot.rowCopies[rowID]; ok {
-						newRows = append(newRows, modifiedRow)
-					} else {
-						newRows = append(newRows, deepCopyRow(row))
-					}
-				}
-
-				// Append newly inserted rows in order (rowID > base data row count)
-				baseRowsCount := int64(cowSnapshot.baseData.RowCount())
-				for rowID := baseRowsCount + 1; rowID <= baseRowsCount+cowSnapshot.insertedCount; rowID++ {
-					if cowSnapshot.deletedRows[rowID] {
-						continue
-					}
-					if row, ok := cowSnapshot.rowCopies[rowID]; ok {
-						newRows = append(newRows, row)
-					}
-				}
+				newRows := cowSnapshot.mergedRows()
 
 				// Check unique constraints on the final merged rows before committing
 				schema := cowSnapshot.modifiedData.schema
@@ -166,6 +153,10 @@ func (m *MVCCDataSource) CommitTx(ctx context.Context, txnID int64) error {
 
 				// Maintain indexes: rebuild from the committed version's rows
 				_ = m.indexManager.RebuildIndex(tableName, newVersionData.schema, newRows)
+
+				if m.changes.active() {
+					m.changes.record(cowSnapshot.changesLocked(txnID))
+				}
 			}()
 			if commitErr != nil {
 				// Unique constraint violation; clean up and return error
@@ -193,6 +184,7 @@ func (m *MVCCDataSource) RollbackTx(ctx context.Context, txnID int64) error {
 	if _, ok := m.activeTxns[txnID]; !ok {
 		return domain.NewErrTransactionNotFound(txnID)
 	}
+	defer m.locks.release(txnID)
 
 	// With copy-on-write, rollback just deletes the snapshot, no data to release
 	delete(m.activeTxns, txnID)
@@ -204,6 +196,71 @@ func (m *MVCCDataSource) RollbackTx(ctx context.Context, txnID int64) error {
 	return nil
 }
 
+// modifiedLocked reports whether the commit has to create a new table
+// version. The caller must hold s.mu.
+func (s *COWTableSnapshot) modifiedLocked() bool {
+	return len(s.rowCopies) > 0 || len(s.deletedRows) > 0 || s.schemaChanged
+}
+
+// mergedRows merges the base data with the row-level modifications: deleted
+// rows are skipped, modified rows replace the originals and inserted rows are
+// appended in order. The caller must hold s.mu.
+func (s *COWTableSnapshot) mergedRows() []domain.Row {
+	// Merge base data and row-level modifications
+	baseRows := s.baseData.Rows()
+	newRows := make([]domain.Row, 0, len(baseRows))
+	for i, row := range baseRows {
+		rowID := int64(i + 1)
+
+		// Skip deleted rows
+		if s.deletedRows[rowID] {
+			continue
+		}
+
+		// Use modified row or deep copy original
+		if modifiedRow, ok := s.rowCopies[rowID]; ok {
+			newRows = append(newRows, modifiedRow)
+		} else {
+			newRows = append(newRows, deepCopyRow(row))
+		}
+	}
+
+	// Append newly inserted rows in order (rowID > base data row count)
+	baseRowsCount := int64(s.baseData.RowCount())
+	for rowID := baseRowsCount + 1; rowID <= baseRowsCount+s.insertedCount; rowID++ {
+		if s.deletedRows[rowID] {
+			continue
+		}
+		if row, ok := s.rowCopies[rowID]; ok {
+			newRows = append(newRows, row)
+		}
+	}
+	return newRows
+}
+
+// changesLocked returns the row changes made by the transaction: updated and
+// deleted base rows followed by the inserted rows. The caller must hold s.mu.
+func (s *COWTableSnapshot) changesLocked(txnID int64) []domain.ChangeEvent {
+	var changes []domain.ChangeEvent
+	baseRows := s.baseData.Rows()
+	for i, row := range baseRows {
+		rowID := int64(i + 1)
+		if s.deletedRows[rowID] {
+			changes = append(changes, domain.ChangeEvent{Table: s.tableName, Type: domain.ChangeDelete, TxnID: txnID, Before: row})
+		} else if modifiedRow, ok := s.rowCopies[rowID]; ok {
+			changes = append(changes, domain.ChangeEvent{Table: s.tableName, Type: domain.ChangeUpdate, TxnID: txnID, Before: row, After: modifiedRow})
+		}
+	}
+
+	baseRowsCount := int64(len(baseRows))
+	for rowID := baseRowsCount + 1; rowID <= baseRowsCount+s.insertedCount; rowID++ {
+		if row, ok := s.rowCopies[rowID]; ok && !s.deletedRows[rowID] {
+			changes = append(changes, domain.ChangeEvent{Table: s.tableName, Type: domain.ChangeInsert, TxnID: txnID, After: row})
+		}
+	}
+	return changes
+}
+
 // checkUniqueConstraintsFinal validates that the complete set of rows
 // (after merging base + modified + inserted - deleted) has no duplicate
 // values for any unique or primary-key column.
@@ -326,7 +383,7 @@ func (s *COWTableSnapshot) getTableData(tableVer *TableVersions) *TableData {
 	defer s.mu.RUnlock()
 
 	// Copy created, need to merge base data and row-level modifications
-	if len(s.rowCopies) == 0 && len(s.deletedRows) == 0 {
+	if len(s.rowCopies) == 0 && len(s.deletedRows) == 0 && !s.schemaChanged {
 		// No rows modified or deleted, return base data
 		return s.baseData
 	}
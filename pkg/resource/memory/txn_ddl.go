@@ -0,0 +1,402 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/util"
+)
+
+// ==================== Transactional DDL and Savepoints ====================
+//
+// DDL executed through an MVCCTransaction is staged in the transaction's
+// snapshot instead of being applied to the shared table map:
+//   - CREATE TABLE builds a private TableVersions visible only to the transaction
+//   - DROP TABLE hides the table from the transaction only
+//   - TRUNCATE TABLE marks every row of the table's COW snapshot as deleted
+//   - TTL changes replace the schema of the table's COW snapshot
+//
+// COMMIT applies the staged drops and creates before merging row changes, so
+// other sessions switch from the old to the new metadata atomically.
+// ROLLBACK simply discards the snapshot. Schemas are versioned together with
+// the rows, so a transaction keeps seeing the schema of its pinned versions.
+
+// txnSavepoint captures the transaction state at a SAVEPOINT
+type txnSavepoint struct {
+	name           string
+	tableSnapshots map[string]*COWTableSnapshot
+	createdTables  map[string]*TableVersions
+	droppedTables  map[string]bool
+}
+
+// txnSnapshotLocked returns the snapshot of the transaction in ctx, if any.
+// The caller must hold m.mu.
+func (m *MVCCDataSource) txnSnapshotLocked(ctx context.Context) *Snapshot {
+	txnID, ok := GetTransactionID(ctx)
+	if !ok {
+		return nil
+	}
+	return m.snapshots[txnID]
+}
+
+// tableLocked returns the table visible to ctx: inside a transaction, tables
+// created by the transaction are visible and tables dropped by it are not.
+// The caller must hold m.mu.
+func (m *MVCCDataSource) tableLocked(ctx context.Context, tableName string) (*TableVersions, bool) {
+	if snapshot := m.txnSnapshotLocked(ctx); snapshot != nil {
+		if tableVer, ok := snapshot.createdTables[tableName]; ok {
+			return tableVer, true
+		}
+		if snapshot.droppedTables[tableName] {
+			return nil, false
+		}
+	}
+	tableVer, ok := m.tables[tableName]
+	return tableVer, ok
+}
+
+// visibleSchemaLocked returns the schema of a table as seen by ctx: the
+// transaction's snapshot schema inside a transaction, the latest otherwise.
+// The caller must hold m.mu.
+func (m *MVCCDataSource) visibleSchemaLocked(ctx context.Context, tableName string, tableVer *TableVersions) *domain.TableInfo {
+	if snapshot := m.txnSnapshotLocked(ctx); snapshot != nil {
+		if cowSnapshot, ok := snapshot.tableSnapshots[tableName]; ok {
+			return cowSnapshot.schema(tableVer)
+		}
+	}
+
+	tableVer.mu.RLock()
+	defer tableVer.mu.RUnlock()
+	if data := tableVer.versions[tableVer.latest]; data != nil {
+		return data.schema
+	}
+	return nil
+}
+
+// writableSnapshotLocked returns the snapshot of a read-write transaction.
+// The caller must hold m.mu.
+func (m *MVCCDataSource) writableSnapshotLocked(txnID int64) (*Snapshot, error) {
+	txn, ok := m.activeTxns[txnID]
+	if !ok {
+		return nil, domain.NewErrTransactionNotFound(txnID)
+	}
+	if txn.readOnly {
+		return nil, fmt.Errorf("cannot execute DDL in a read-only transaction")
+	}
+	snapshot, ok := m.snapshots[txnID]
+	if !ok {
+		return nil, domain.NewErrSnapshotNotFound(txnID)
+	}
+	return snapshot, nil
+}
+
+// txnCreateTable stages CREATE TABLE in a transaction
+func (m *MVCCDataSource) txnCreateTable(ctx context.Context, txnID int64, tableInfo *domain.TableInfo) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "create table")
+	}
+	if tableInfo.Partition != nil || tableInfo.Temporary {
+		return domain.NewErrUnsupportedOperation(string(m.config.Type), "create partitioned or temporary table in a transaction")
+	}
+	if err := util.ValidateTTL(tableInfo); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, err := m.writableSnapshotLocked(txnID)
+	if err != nil {
+		return err
+	}
+	if _, ok := m.tableLocked(ctx, tableInfo.Name); ok {
+		return domain.NewErrTableAlreadyExists(tableInfo.Name)
+	}
+
+	tableVer, err := m.newTableVersionsLocked(tableInfo)
+	if err != nil {
+		return err
+	}
+	snapshot.createdTables[tableInfo.Name] = tableVer
+	snapshot.tableSnapshots[tableInfo.Name] = &COWTableSnapshot{
+		tableName:   tableInfo.Name,
+		snapshotVer: tableVer.latest,
+	}
+	return nil
+}
+
+// txnDropTable stages DROP TABLE in a transaction
+func (m *MVCCDataSource) txnDropTable(ctx context.Context, txnID int64, tableName string) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "drop table")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, err := m.writableSnapshotLocked(txnID)
+	if err != nil {
+		return err
+	}
+	tableVer, ok := m.tableLocked(ctx, tableName)
+	if !ok {
+		return domain.NewErrTableNotFound(tableName)
+	}
+	if schema := m.visibleSchemaLocked(ctx, tableName, tableVer); schema != nil && schema.IsPartitioned() {
+		return domain.NewErrUnsupportedOperation(string(m.config.Type), "drop partitioned table in a transaction")
+	}
+
+	if _, created := snapshot.createdTables[tableName]; created {
+		delete(snapshot.createdTables, tableName)
+	} else {
+		snapshot.droppedTables[tableName] = true
+	}
+	delete(snapshot.tableSnapshots, tableName)
+	return nil
+}
+
+// txnTruncateTable stages TRUNCATE TABLE in a transaction by deleting every
+// row visible to it
+func (m *MVCCDataSource) txnTruncateTable(ctx context.Context, txnID int64, tableName string) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "truncate table")
+	}
+
+	cowSnapshot, err := m.txnCopiedTable(ctx, txnID, tableName, "truncate partitioned table in a transaction")
+	if err != nil {
+		return err
+	}
+
+	cowSnapshot.mu.Lock()
+	defer cowSnapshot.mu.Unlock()
+
+	total := int64(cowSnapshot.baseData.RowCount()) + cowSnapshot.insertedCount
+	cowSnapshot.rowLocks = make(map[int64]bool)
+	cowSnapshot.rowCopies = make(map[int64]domain.Row)
+	for rowID := int64(1); rowID <= total; rowID++ {
+		cowSnapshot.deletedRows[rowID] = true
+	}
+	return nil
+}
+
+// txnSetTableTTL stages a TTL change in a transaction
+func (m *MVCCDataSource) txnSetTableTTL(ctx context.Context, txnID int64, tableName string, ttl *domain.TTLInfo) error {
+	if !m.IsWritable() {
+		return domain.NewErrReadOnly(string(m.config.Type), "set table ttl")
+	}
+
+	cowSnapshot, err := m.txnCopiedTable(ctx, txnID, tableName, "")
+	if err != nil {
+		return err
+	}
+
+	cowSnapshot.mu.Lock()
+	defer cowSnapshot.mu.Unlock()
+
+	schema := deepCopySchema(cowSnapshot.modifiedData.schema)
+	schema.TTL = ttl.Clone()
+	if err := util.ValidateTTL(schema); err != nil {
+		return err
+	}
+	cowSnapshot.modifiedData.schema = schema
+	cowSnapshot.schemaChanged = true
+	return nil
+}
+
+// txnCopiedTable returns the COW snapshot of a table in a transaction, with
+// its copy initialized. If partitionedOp is not empty, partitioned tables are
+// rejected with it as the unsupported operation.
+func (m *MVCCDataSource) txnCopiedTable(ctx context.Context, txnID int64, tableName, partitionedOp string) (*COWTableSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, err := m.writableSnapshotLocked(txnID)
+	if err != nil {
+		return nil, err
+	}
+	tableVer, ok := m.tableLocked(ctx, tableName)
+	if !ok {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+	cowSnapshot, ok := snapshot.tableSnapshots[tableName]
+	if !ok {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+	if partitionedOp != "" {
+		if schema := cowSnapshot.schema(tableVer); schema != nil && schema.IsPartitioned() {
+			return nil, domain.NewErrUnsupportedOperation(string(m.config.Type), partitionedOp)
+		}
+	}
+	if err := cowSnapshot.ensureCopied(tableVer); err != nil {
+		return nil, err
+	}
+	return cowSnapshot, nil
+}
+
+// applyTxnDDLLocked publishes the tables dropped and created by a committing
+// transaction. Nothing is applied if a created table conflicts with a table
+// created by another session. The caller must hold m.mu.
+func (m *MVCCDataSource) applyTxnDDLLocked(snapshot *Snapshot) error {
+	for name := range snapshot.createdTables {
+		if _, exists := m.tables[name]; exists && !snapshot.droppedTables[name] {
+			return domain.NewErrTableAlreadyExists(name)
+		}
+	}
+	for name := range snapshot.droppedTables {
+		// The table may have been dropped by another session meanwhile
+		_ = m.dropTableLocked(name)
+	}
+	for name, tableVer := range snapshot.createdTables {
+		m.tables[name] = tableVer
+	}
+	return nil
+}
+
+// txnSavepoint creates a savepoint, replacing an existing one with the same name
+func (m *MVCCDataSource) txnSavepoint(txnID int64, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, ok := m.snapshots[txnID]
+	if !ok {
+		return domain.NewErrTransactionNotFound(txnID)
+	}
+	if i := snapshot.findSavepoint(name); i >= 0 {
+		snapshot.savepoints = append(snapshot.savepoints[:i], snapshot.savepoints[i+1:]...)
+	}
+
+	sp := &txnSavepoint{
+		name:           name,
+		tableSnapshots: cloneTableSnapshots(snapshot.tableSnapshots),
+		createdTables:  make(map[string]*TableVersions, len(snapshot.createdTables)),
+		droppedTables:  make(map[string]bool, len(snapshot.droppedTables)),
+	}
+	for k, v := range snapshot.createdTables {
+		sp.createdTables[k] = v
+	}
+	for k, v := range snapshot.droppedTables {
+		sp.droppedTables[k] = v
+	}
+	snapshot.savepoints = append(snapshot.savepoints, sp)
+	return nil
+}
+
+// txnRollbackToSavepoint restores the transaction state captured by a
+// savepoint; savepoints created after it are removed
+func (m *MVCCDataSource) txnRollbackToSavepoint(txnID int64, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, ok := m.snapshots[txnID]
+	if !ok {
+		return domain.NewErrTransactionNotFound(txnID)
+	}
+	i := snapshot.findSavepoint(name)
+	if i < 0 {
+		return fmt.Errorf("SAVEPOINT %s does not exist", name)
+	}
+
+	// Clone again so the savepoint can be rolled back to repeatedly
+	sp := snapshot.savepoints[i]
+	snapshot.tableSnapshots = cloneTableSnapshots(sp.tableSnapshots)
+	snapshot.createdTables = make(map[string]*TableVersions, len(sp.createdTables))
+	for k, v := range sp.createdTables {
+		snapshot.createdTables[k] = v
+	}
+	snapshot.droppedTables = make(map[string]bool, len(sp.droppedTables))
+	for k, v := range sp.droppedTables {
+		snapshot.droppedTables[k] = v
+	}
+	snapshot.savepoints = snapshot.savepoints[:i+1]
+	return nil
+}
+
+// txnReleaseSavepoint removes a savepoint and the savepoints created after it
+func (m *MVCCDataSource) txnReleaseSavepoint(txnID int64, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, ok := m.snapshots[txnID]
+	if !ok {
+		return domain.NewErrTransactionNotFound(txnID)
+	}
+	i := snapshot.findSavepoint(name)
+	if i < 0 {
+		return fmt.Errorf("SAVEPOINT %s does not exist", name)
+	}
+	snapshot.savepoints = snapshot.savepoints[:i]
+	return nil
+}
+
+// findSavepoint returns the index of the named savepoint, or -1
+func (s *Snapshot) findSavepoint(name string) int {
+	for i := len(s.savepoints) - 1; i >= 0; i-- {
+		if s.savepoints[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// cloneTableSnapshots deep-copies the COW state of every table
+func cloneTableSnapshots(src map[string]*COWTableSnapshot) map[string]*COWTableSnapshot {
+	dst := make(map[string]*COWTableSnapshot, len(src))
+	for name, s := range src {
+		dst[name] = s.clone()
+	}
+	return dst
+}
+
+// clone deep-copies the row-level modifications of a COW snapshot. Base data
+// and schemas are immutable and shared.
+func (s *COWTableSnapshot) clone() *COWTableSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c := &COWTableSnapshot{
+		tableName:     s.tableName,
+		snapshotVer:   s.snapshotVer,
+		copied:        s.copied,
+		baseData:      s.baseData,
+		insertedCount: s.insertedCount,
+		schemaChanged: s.schemaChanged,
+	}
+	if s.modifiedData != nil {
+		modified := *s.modifiedData
+		c.modifiedData = &modified
+	}
+	if s.copied {
+		c.rowLocks = make(map[int64]bool, len(s.rowLocks))
+		for k, v := range s.rowLocks {
+			c.rowLocks[k] = v
+		}
+		// Rows are updated in place once copied, so they are copied too
+		c.rowCopies = make(map[int64]domain.Row, len(s.rowCopies))
+		for k, v := range s.rowCopies {
+			c.rowCopies[k] = deepCopyRow(v)
+		}
+		c.deletedRows = make(map[int64]bool, len(s.deletedRows))
+		for k, v := range s.deletedRows {
+			c.deletedRows[k] = v
+		}
+	}
+	return c
+}
+
+// schema returns the table schema as seen by the transaction
+func (s *COWTableSnapshot) schema(tableVer *TableVersions) *domain.TableInfo {
+	s.mu.RLock()
+	if s.copied {
+		defer s.mu.RUnlock()
+		return s.modifiedData.schema
+	}
+	s.mu.RUnlock()
+
+	tableVer.mu.RLock()
+	defer tableVer.mu.RUnlock()
+	if data := tableVer.versions[s.snapshotVer]; data != nil {
+		return data.schema
+	}
+	return nil
+}
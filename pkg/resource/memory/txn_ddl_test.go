@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newTxnDDLTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := NewMVCCDataSource(nil)
+	ctx := context.Background()
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { ds.Close(ctx) })
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name:    "base",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if _, err := ds.Insert(ctx, "base", []domain.Row{{"id": int64(1)}, {"id": int64(2)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return ds
+}
+
+func beginDDLTxn(t *testing.T, ds *MVCCDataSource) *MVCCTransaction {
+	t.Helper()
+	tx, err := ds.BeginTransaction(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("BeginTransaction() error = %v", err)
+	}
+	return tx.(*MVCCTransaction)
+}
+
+func countRows(t *testing.T, query func(context.Context, string, *domain.QueryOptions) (*domain.QueryResult, error), table string) int {
+	t.Helper()
+	result, err := query(context.Background(), table, &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query(%s) error = %v", table, err)
+	}
+	return len(result.Rows)
+}
+
+// TestTxnDDL_CommitAndRollback verifies that DDL inside a transaction is only
+// visible to that transaction until commit and is undone on rollback
+func TestTxnDDL_CommitAndRollback(t *testing.T) {
+	ds := newTxnDDLTestSource(t)
+	ctx := context.Background()
+
+	tx := beginDDLTxn(t, ds)
+	if err := tx.CreateTable(ctx, &domain.TableInfo{
+		Name:    "created",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if _, err := tx.Insert(ctx, "created", []domain.Row{{"id": int64(10)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := tx.TruncateTable(ctx, "base"); err != nil {
+		t.Fatalf("TruncateTable() error = %v", err)
+	}
+
+	if n := countRows(t, tx.Query, "created"); n != 1 {
+		t.Errorf("rows in created inside txn = %d, want 1", n)
+	}
+	if n := countRows(t, tx.Query, "base"); n != 0 {
+		t.Errorf("rows in base inside txn = %d, want 0", n)
+	}
+	if _, err := ds.GetTableInfo(ctx, "created"); err == nil {
+		t.Error("uncommitted table is visible outside the transaction")
+	}
+	if n := countRows(t, ds.Query, "base"); n != 2 {
+		t.Errorf("rows in base outside txn = %d, want 2", n)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if n := countRows(t, ds.Query, "created"); n != 1 {
+		t.Errorf("rows in created after commit = %d, want 1", n)
+	}
+	if n := countRows(t, ds.Query, "base"); n != 0 {
+		t.Errorf("rows in base after commit = %d, want 0", n)
+	}
+
+	tx = beginDDLTxn(t, ds)
+	if err := tx.DropTable(ctx, "created"); err != nil {
+		t.Fatalf("DropTable() error = %v", err)
+	}
+	if _, err := tx.Query(ctx, "created", &domain.QueryOptions{}); err == nil {
+		t.Error("dropped table is still visible inside the transaction")
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if n := countRows(t, ds.Query, "created"); n != 1 {
+		t.Errorf("rows in created after rollback = %d, want 1", n)
+	}
+}
+
+// TestTxnDDL_CreateConflict verifies that a commit fails if another session
+// created a table with the same name meanwhile
+func TestTxnDDL_CreateConflict(t *testing.T) {
+	ds := newTxnDDLTestSource(t)
+	ctx := context.Background()
+	info := &domain.TableInfo{
+		Name:    "dup",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}},
+	}
+
+	tx := beginDDLTxn(t, ds)
+	if err := tx.CreateTable(ctx, info); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if err := ds.CreateTable(ctx, info); err != nil {
+		t.Fatalf("concurrent CreateTable() error = %v", err)
+	}
+	if err := tx.Commit(ctx); err == nil {
+		t.Error("expected commit to fail on table name conflict")
+	}
+}
+
+// TestTxnSavepoints verifies rolling back to and releasing savepoints
+func TestTxnSavepoints(t *testing.T) {
+	ds := newTxnDDLTestSource(t)
+	ctx := context.Background()
+
+	tx := beginDDLTxn(t, ds)
+	if _, err := tx.Insert(ctx, "base", []domain.Row{{"id": int64(3)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := tx.Savepoint(ctx, "sp1"); err != nil {
+		t.Fatalf("Savepoint() error = %v", err)
+	}
+	if _, err := tx.Insert(ctx, "base", []domain.Row{{"id": int64(4)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := tx.CreateTable(ctx, &domain.TableInfo{
+		Name:    "tmp",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+
+	if err := tx.RollbackToSavepoint(ctx, "sp1"); err != nil {
+		t.Fatalf("RollbackToSavepoint() error = %v", err)
+	}
+	if n := countRows(t, tx.Query, "base"); n != 3 {
+		t.Errorf("rows in base after rollback to savepoint = %d, want 3", n)
+	}
+	if _, err := tx.Query(ctx, "tmp", &domain.QueryOptions{}); err == nil {
+		t.Error("table created after the savepoint is still visible")
+	}
+
+	// The savepoint survives ROLLBACK TO and can be reused
+	if _, err := tx.Delete(ctx, "base", nil, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := tx.RollbackToSavepoint(ctx, "sp1"); err != nil {
+		t.Fatalf("second RollbackToSavepoint() error = %v", err)
+	}
+	if err := tx.ReleaseSavepoint(ctx, "sp1"); err != nil {
+		t.Fatalf("ReleaseSavepoint() error = %v", err)
+	}
+	if err := tx.RollbackToSavepoint(ctx, "sp1"); err == nil {
+		t.Error("expected error rolling back to a released savepoint")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if n := countRows(t, ds.Query, "base"); n != 3 {
+		t.Errorf("rows in base after commit = %d, want 3", n)
+	}
+}
@@ -14,6 +14,7 @@ type TableVersions struct {
 	mu       sync.RWMutex
 	versions map[int64]*TableData // version -> data
 	latest   int64                // latest version number
+	churn    int64                // rows updated or deleted since the last compaction
 }
 
 // TableData represents a single version of table data
@@ -52,6 +53,7 @@ type COWTableSnapshot struct {
 	rowCopies     map[int64]domain.Row // row-level copies: store modified rows
 	deletedRows   map[int64]bool       // row-level deletion: mark deleted rows
 	insertedCount int64                // number of rows inserted in this transaction
+	schemaChanged bool                 // whether modifiedData.schema was altered by DDL in this transaction
 	mu            sync.RWMutex
 }
 
@@ -61,6 +63,11 @@ type Snapshot struct {
 	startVer       int64
 	createdAt      time.Time
 	tableSnapshots map[string]*COWTableSnapshot // COW snapshot per table
+
+	// Transactional DDL (see txn_ddl.go)
+	createdTables map[string]*TableVersions // tables created in the transaction, private until commit
+	droppedTables map[string]bool           // tables dropped in the transaction, still visible to others
+	savepoints    []*txnSavepoint           // savepoints in creation order
 }
 
 // Transaction represents transaction information
@@ -68,6 +75,7 @@ type Transaction struct {
 	txnID     int64
 	startTime time.Time
 	readOnly  bool
+	xid       string // set once the transaction is prepared (two-phase commit)
 }
 
 // deepCopySchema returns a deep copy of a TableInfo, including pointer fields
@@ -84,6 +92,10 @@ func deepCopySchema(src *domain.TableInfo) *domain.TableInfo {
 			fkCopy := *col.ForeignKey
 			cols[i].ForeignKey = &fkCopy
 		}
+		if col.Encryption != nil {
+			encCopy := *col.Encryption
+			cols[i].Encryption = &encCopy
+		}
 		// Deep copy slice fields
 		if col.GeneratedDepends != nil {
 			deps := make([]string, len(col.GeneratedDepends))
@@ -108,6 +120,8 @@ func deepCopySchema(src *domain.TableInfo) *domain.TableInfo {
 		Atts:      atts,
 		Charset:   src.Charset,
 		Collation: src.Collation,
+		Partition: src.Partition.Clone(),
+		TTL:       src.TTL.Clone(),
 	}
 }
 
@@ -0,0 +1,304 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/generated"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// ==================== Upsert ====================
+
+// Upsert inserts rows and resolves primary/unique key conflicts row by row:
+// conflicting rows are skipped (options.Ignore) or updated (options.Update).
+// Rows are processed in order, so later rows see the effect of earlier ones.
+// The whole statement is applied as a single new version: if any row fails,
+// nothing is written.
+//
+// Upserts inside a transaction and on partitioned tables are not supported;
+// callers fall back to Insert/Update for those.
+func (m *MVCCDataSource) Upsert(ctx context.Context, tableName string, rows []domain.Row, options *domain.UpsertOptions) (*domain.UpsertResult, error) {
+	if !m.IsWritable() {
+		return nil, domain.NewErrReadOnly(string(m.config.Type), "upsert")
+	}
+	if options == nil {
+		options = &domain.UpsertOptions{}
+	}
+	if _, hasTxn := GetTransactionID(ctx); hasTxn {
+		return nil, domain.NewErrUnsupportedOperation(string(m.config.Type), "upsert in transaction")
+	}
+
+	// Wait for rows locked by transactions
+	if m.locks.busy() {
+		owner := m.locks.nextTransientOwner()
+		defer m.locks.release(owner)
+		if err := m.lockInsertedRows(ctx, owner, tableName, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+
+	tableVer, ok := m.tableLocked(ctx, tableName)
+	if !ok {
+		m.mu.Unlock()
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+
+	tableVer.mu.RLock()
+	schema := deepCopySchema(tableVer.versions[tableVer.latest].schema)
+	tableVer.mu.RUnlock()
+
+	if schema.IsPartitioned() {
+		m.mu.Unlock()
+		return nil, domain.NewErrUnsupportedOperation(string(m.config.Type), "upsert on partitioned table")
+	}
+
+	m.assignAutoIncrement(tableName, schema, rows)
+	candidates := m.storedRows(schema, rows)
+	if err := m.encryptRows(ctx, schema, candidates); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	// Lock order: global lock first, then table-level lock
+	m.currentVer++
+	newVer := m.currentVer
+	defer m.changes.flush()
+	tableVer.mu.Lock()
+	m.mu.Unlock()
+	defer tableVer.mu.Unlock()
+
+	latestData := tableVer.versions[tableVer.latest]
+	if latestData == nil {
+		return nil, domain.NewErrTableNotFound(tableName)
+	}
+
+	// Rows of the previous version are never mutated: updated rows are replaced by copies
+	srcRows := latestData.Rows()
+	newRows := make([]domain.Row, len(srcRows), len(srcRows)+len(candidates))
+	copy(newRows, srcRows)
+	keys := newUniqueKeySet(m.uniqueKeys(tableName, schema), newRows)
+
+	evaluator := generated.NewGeneratedColumnEvaluator()
+	autoIncCol := ""
+	for _, col := range schema.Columns {
+		if col.AutoIncrement {
+			autoIncCol = col.Name
+			break
+		}
+	}
+
+	var changes []domain.ChangeEvent
+	recordChanges := m.changes.active()
+	result := &domain.UpsertResult{}
+	for _, row := range candidates {
+		pos, conflict := keys.find(row, -1)
+		if pos < 0 {
+			keys.add(row, len(newRows))
+			newRows = append(newRows, deepCopyRow(row))
+			result.Inserted++
+			if recordChanges {
+				changes = append(changes, domain.ChangeEvent{Table: tableName, Type: domain.ChangeInsert, After: newRows[len(newRows)-1]})
+			}
+			if autoIncCol != "" {
+				if id, err := utils.ToInt64(row[autoIncCol]); err == nil {
+					result.LastInsertID = id
+				}
+			}
+			continue
+		}
+
+		if options.Ignore {
+			result.Skipped++
+			result.Warnings = append(result.Warnings, conflict)
+			continue
+		}
+		if options.Update == nil {
+			return nil, fmt.Errorf("%s", conflict)
+		}
+
+		existing := newRows[pos]
+		// ON DUPLICATE KEY UPDATE sees plaintext and its new values are encrypted again
+		plain, err := m.plainRows(ctx, schema, existing, row)
+		if err != nil {
+			return nil, err
+		}
+		updates, err := options.Update(plain[0], plain[1])
+		if err != nil {
+			return nil, err
+		}
+		updates = generated.FilterGeneratedColumns(updates, schema)
+		if schema.HasEncryptedColumns() {
+			if err := m.encryptRow(ctx, schema, updates); err != nil {
+				return nil, err
+			}
+		}
+		internRow(updates)
+		convertRowTypesBasedOnSchema(updates, schema)
+
+		merged := deepCopyRow(existing)
+		changed := false
+		updatedCols := make([]string, 0, len(updates))
+		for col, val := range updates {
+			if fmt.Sprintf("%v", merged[col]) != fmt.Sprintf("%v", val) || (merged[col] == nil) != (val == nil) {
+				changed = true
+			}
+			merged[col] = val
+			updatedCols = append(updatedCols, col)
+		}
+		if !changed {
+			result.Unchanged++
+			continue
+		}
+		applyGeneratedColumns(evaluator, merged, generated.GetAffectedGeneratedColumns(updatedCols, schema), schema)
+
+		// The updated row must not collide with another row
+		if _, conflict := keys.find(merged, pos); conflict != "" {
+			return nil, fmt.Errorf("%s", conflict)
+		}
+		keys.remove(existing)
+		keys.add(merged, pos)
+		newRows[pos] = merged
+		result.Updated++
+		if recordChanges {
+			changes = append(changes, domain.ChangeEvent{Table: tableName, Type: domain.ChangeUpdate, Before: existing, After: merged})
+		}
+	}
+
+	if result.Inserted == 0 && result.Updated == 0 {
+		return result, nil
+	}
+
+	versionData := &TableData{
+		version:   newVer,
+		createdAt: time.Now(),
+		schema:    deepCopySchema(latestData.schema),
+		rows:      NewPagedRows(m.bufferPool, newRows, 0, tableName, newVer),
+	}
+	tableVer.versions[newVer] = versionData
+	tableVer.latest = newVer
+	tableVer.churn += result.Updated
+
+	m.rebuildTableIndexes(tableName, versionData.schema, newRows)
+
+	m.changes.record(changes)
+
+	return result, nil
+}
+
+// uniqueKey a set of columns whose values must be unique across the table
+type uniqueKey struct {
+	name    string
+	columns []string
+}
+
+// uniqueKeys returns the primary key (all Primary columns together), the
+// column-level unique constraints and the unique indexes of a table
+func (m *MVCCDataSource) uniqueKeys(tableName string, schema *domain.TableInfo) []uniqueKey {
+	var keys []uniqueKey
+	if pk := primaryKeyColumns(schema); len(pk) > 0 {
+		keys = append(keys, uniqueKey{name: "PRIMARY", columns: pk})
+	}
+	single := make(map[string]bool)
+	for _, col := range schema.Columns {
+		if col.Unique && !col.Primary {
+			keys = append(keys, uniqueKey{name: col.Name, columns: []string{col.Name}})
+			single[col.Name] = true
+		}
+	}
+	tableIndexes, err := m.indexManager.GetTableIndexes(tableName)
+	if err != nil {
+		return keys
+	}
+	for _, idx := range tableIndexes {
+		if !idx.Unique || len(idx.Columns) == 0 {
+			continue
+		}
+		if len(idx.Columns) == 1 && single[idx.Columns[0]] {
+			continue
+		}
+		name := idx.Name
+		if name == "" {
+			name = idx.Columns[0]
+		}
+		keys = append(keys, uniqueKey{name: name, columns: idx.Columns})
+	}
+	return keys
+}
+
+// uniqueKeySet maps the values of each unique key to the position of the row holding them
+type uniqueKeySet struct {
+	keys      []uniqueKey
+	positions []map[string]int
+}
+
+func newUniqueKeySet(keys []uniqueKey, rows []domain.Row) *uniqueKeySet {
+	s := &uniqueKeySet{keys: keys, positions: make([]map[string]int, len(keys))}
+	for i := range keys {
+		s.positions[i] = make(map[string]int, len(rows))
+	}
+	for pos, row := range rows {
+		s.add(row, pos)
+	}
+	return s
+}
+
+// value returns the key value of a row; rows with a NULL key column never conflict
+func (k uniqueKey) value(row domain.Row) (string, bool) {
+	if len(k.columns) == 1 {
+		val := row[k.columns[0]]
+		if val == nil {
+			return "", false
+		}
+		return fmt.Sprintf("%v", val), true
+	}
+	parts := make([]interface{}, len(k.columns))
+	for i, col := range k.columns {
+		if row[col] == nil {
+			return "", false
+		}
+		parts[i] = row[col]
+	}
+	return fmt.Sprintf("%v", parts), true
+}
+
+// find returns the position of a row conflicting with row (ignoring position skip)
+// and a "Duplicate entry" message, or -1 when there is no conflict
+func (s *uniqueKeySet) find(row domain.Row, skip int) (int, string) {
+	for i, key := range s.keys {
+		val, ok := key.value(row)
+		if !ok {
+			continue
+		}
+		if pos, exists := s.positions[i][val]; exists && pos != skip {
+			if len(key.columns) > 1 {
+				val = fmt.Sprintf("%v", row[key.columns[0]])
+				for _, col := range key.columns[1:] {
+					val += "-" + fmt.Sprintf("%v", row[col])
+				}
+			}
+			return pos, fmt.Sprintf("Duplicate entry '%s' for key '%s'", val, key.name)
+		}
+	}
+	return -1, ""
+}
+
+func (s *uniqueKeySet) add(row domain.Row, pos int) {
+	for i, key := range s.keys {
+		if val, ok := key.value(row); ok {
+			s.positions[i][val] = pos
+		}
+	}
+}
+
+func (s *uniqueKeySet) remove(row domain.Row) {
+	for i, key := range s.keys {
+		if val, ok := key.value(row); ok {
+			delete(s.positions[i], val)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func newUpsertTestSource(t *testing.T) *MVCCDataSource {
+	t.Helper()
+	ds := NewMVCCDataSource(nil)
+	ctx := context.Background()
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { ds.Close(ctx) })
+
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name: "counters",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true, AutoIncrement: true},
+			{Name: "name", Type: "VARCHAR", Unique: true},
+			{Name: "hits", Type: "INT"},
+		},
+	}); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if _, err := ds.Insert(ctx, "counters", []domain.Row{
+		{"name": "a", "hits": int64(1)},
+		{"name": "b", "hits": int64(1)},
+	}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return ds
+}
+
+func upsertHits(t *testing.T, ds *MVCCDataSource) map[string]interface{} {
+	t.Helper()
+	result, err := ds.Query(context.Background(), "counters", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	hits := make(map[string]interface{}, len(result.Rows))
+	for _, row := range result.Rows {
+		hits[row["name"].(string)] = row["hits"]
+	}
+	return hits
+}
+
+// TestUpsert_Update verifies per-row insert/update accounting, including rows
+// conflicting with rows inserted earlier in the same statement
+func TestUpsert_Update(t *testing.T) {
+	ds := newUpsertTestSource(t)
+	options := &domain.UpsertOptions{
+		Update: func(existing, inserted domain.Row) (domain.Row, error) {
+			return domain.Row{"hits": inserted["hits"]}, nil
+		},
+	}
+
+	result, err := ds.Upsert(context.Background(), "counters", []domain.Row{
+		{"name": "a", "hits": int64(5)},
+		{"name": "c", "hits": int64(1)},
+		{"name": "c", "hits": int64(2)},
+		{"name": "b", "hits": int64(1)},
+	}, options)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if result.Inserted != 1 || result.Updated != 2 || result.Unchanged != 1 {
+		t.Errorf("result = %+v, want 1 inserted, 2 updated, 1 unchanged", result)
+	}
+	if result.AffectedRows() != 5 || result.Duplicates() != 3 {
+		t.Errorf("AffectedRows() = %d, Duplicates() = %d, want 5 and 3", result.AffectedRows(), result.Duplicates())
+	}
+	if result.LastInsertID == 0 {
+		t.Errorf("LastInsertID = 0, want the id assigned to 'c'")
+	}
+
+	hits := upsertHits(t, ds)
+	if len(hits) != 3 || hits["a"] != int64(5) || hits["b"] != int64(1) || hits["c"] != int64(2) {
+		t.Errorf("rows = %v", hits)
+	}
+}
+
+// TestUpsert_Ignore verifies that conflicting rows are skipped with a warning
+func TestUpsert_Ignore(t *testing.T) {
+	ds := newUpsertTestSource(t)
+
+	result, err := ds.Upsert(context.Background(), "counters", []domain.Row{
+		{"name": "a", "hits": int64(9)},
+		{"name": "d", "hits": int64(1)},
+	}, &domain.UpsertOptions{Ignore: true})
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if result.Inserted != 1 || result.Skipped != 1 || len(result.Warnings) != 1 {
+		t.Errorf("result = %+v, want 1 inserted and 1 skipped with a warning", result)
+	}
+	if hits := upsertHits(t, ds); hits["a"] != int64(1) || hits["d"] != int64(1) {
+		t.Errorf("rows = %v", hits)
+	}
+}
+
+// TestUpsert_Atomic verifies that a failing row leaves the table untouched
+func TestUpsert_Atomic(t *testing.T) {
+	ds := newUpsertTestSource(t)
+
+	// Without a conflict action a duplicate fails the whole statement
+	_, err := ds.Upsert(context.Background(), "counters", []domain.Row{
+		{"name": "e", "hits": int64(1)},
+		{"name": "a", "hits": int64(1)},
+	}, nil)
+	if err == nil {
+		t.Fatal("Upsert() error = nil, want duplicate entry error")
+	}
+
+	// An update that collides with another row fails as well
+	_, err = ds.Upsert(context.Background(), "counters", []domain.Row{
+		{"name": "f", "hits": int64(1)},
+		{"name": "a", "hits": int64(1)},
+	}, &domain.UpsertOptions{
+		Update: func(existing, inserted domain.Row) (domain.Row, error) {
+			return domain.Row{"name": "b"}, nil
+		},
+	})
+	if err == nil {
+		t.Fatal("Upsert() error = nil, want duplicate entry error")
+	}
+
+	if hits := upsertHits(t, ds); len(hits) != 2 {
+		t.Errorf("rows = %v, want the original 2 rows", hits)
+	}
+}
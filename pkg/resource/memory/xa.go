@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ==================== Two-Phase Commit ====================
+//
+// Prepare validates everything CommitTx can fail on (staged CREATE TABLE
+// conflicts and unique constraints) and marks the transaction with its xid.
+// A prepared transaction keeps its snapshot and locks but rejects further
+// statements until it is committed or rolled back, either through the
+// transaction object or by xid (CommitXA/RollbackXA). Prepared transactions
+// live in memory only, so they do not survive a restart of the process.
+
+// Prepare implements domain.XATransaction
+func (t *MVCCTransaction) Prepare(ctx context.Context, xid string) error {
+	return t.ds.PrepareTx(ctx, t.txnID, xid)
+}
+
+// checkNotPrepared rejects statements of a prepared transaction
+func (t *MVCCTransaction) checkNotPrepared() error {
+	t.ds.mu.RLock()
+	defer t.ds.mu.RUnlock()
+	if txn, ok := t.ds.activeTxns[t.txnID]; ok && txn.xid != "" {
+		return domain.NewErrXAPrepared(txn.xid)
+	}
+	return nil
+}
+
+// PrepareTx runs the first phase of a two-phase commit
+func (m *MVCCDataSource) PrepareTx(ctx context.Context, txnID int64, xid string) error {
+	if xid == "" {
+		return fmt.Errorf("xid must not be empty")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txn, ok := m.activeTxns[txnID]
+	if !ok {
+		return domain.NewErrTransactionNotFound(txnID)
+	}
+	if txn.xid != "" {
+		return domain.NewErrXAPrepared(txn.xid)
+	}
+	if _, exists := m.findPreparedLocked(xid); exists {
+		return fmt.Errorf("XAER_DUPID: The XID '%s' already exists", xid)
+	}
+	snapshot, ok := m.snapshots[txnID]
+	if !ok {
+		return domain.NewErrSnapshotNotFound(txnID)
+	}
+
+	if !txn.readOnly {
+		for name := range snapshot.createdTables {
+			if _, exists := m.tables[name]; exists && !snapshot.droppedTables[name] {
+				return domain.NewErrTableAlreadyExists(name)
+			}
+		}
+		for tableName, cowSnapshot := range snapshot.tableSnapshots {
+			if m.tables[tableName] == nil || !cowSnapshot.copied {
+				continue
+			}
+			if err := func() error {
+				cowSnapshot.mu.RLock()
+				defer cowSnapshot.mu.RUnlock()
+				if !cowSnapshot.modifiedLocked() {
+					return nil
+				}
+				return m.checkUniqueConstraintsFinal(tableName, cowSnapshot.modifiedData.schema, cowSnapshot.mergedRows())
+			}(); err != nil {
+				return err
+			}
+		}
+	}
+
+	txn.xid = xid
+	return nil
+}
+
+// RecoverXA implements domain.XADataSource
+func (m *MVCCDataSource) RecoverXA(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var xids []string
+	for _, txn := range m.activeTxns {
+		if txn.xid != "" {
+			xids = append(xids, txn.xid)
+		}
+	}
+	sort.Strings(xids)
+	return xids, nil
+}
+
+// CommitXA implements domain.XADataSource
+func (m *MVCCDataSource) CommitXA(ctx context.Context, xid string) error {
+	txnID, err := m.preparedTxn(xid)
+	if err != nil {
+		return err
+	}
+	return m.CommitTx(ctx, txnID)
+}
+
+// RollbackXA implements domain.XADataSource
+func (m *MVCCDataSource) RollbackXA(ctx context.Context, xid string) error {
+	txnID, err := m.preparedTxn(xid)
+	if err != nil {
+		return err
+	}
+	return m.RollbackTx(ctx, txnID)
+}
+
+func (m *MVCCDataSource) preparedTxn(xid string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	txnID, ok := m.findPreparedLocked(xid)
+	if !ok {
+		return 0, domain.NewErrXAUnknown(xid)
+	}
+	return txnID, nil
+}
+
+func (m *MVCCDataSource) findPreparedLocked(xid string) (int64, bool) {
+	for txnID, txn := range m.activeTxns {
+		if txn.xid == xid {
+			return txnID, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// TestXA_PrepareAndCommitByXID verifies that a prepared transaction rejects
+// statements, is listed by RecoverXA and can be finished by xid
+func TestXA_PrepareAndCommitByXID(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	tx := beginLockTxn(t, ds)
+	if _, err := tx.Insert(ctx, "accounts", []domain.Row{{"id": int64(3), "balance": int64(10)}}, nil); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if err := tx.(domain.XATransaction).Prepare(ctx, "xid-1"); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	var prepared *domain.ErrXAPrepared
+	if _, err := tx.Insert(ctx, "accounts", []domain.Row{{"id": int64(4), "balance": int64(10)}}, nil); !errors.As(err, &prepared) {
+		t.Fatalf("expected insert into a prepared transaction to fail, got %v", err)
+	}
+	if _, err := tx.Query(ctx, "accounts", &domain.QueryOptions{}); !errors.As(err, &prepared) {
+		t.Fatalf("expected query in a prepared transaction to fail, got %v", err)
+	}
+
+	xids, err := ds.RecoverXA(ctx)
+	if err != nil || len(xids) != 1 || xids[0] != "xid-1" {
+		t.Fatalf("RecoverXA() = %v, %v; want [xid-1]", xids, err)
+	}
+	if err := ds.CommitXA(ctx, "xid-1"); err != nil {
+		t.Fatalf("CommitXA() error = %v", err)
+	}
+	if err := ds.CommitXA(ctx, "xid-1"); !domain.IsXAUnknown(err) {
+		t.Errorf("expected unknown xid after commit, got %v", err)
+	}
+
+	result, err := ds.Query(ctx, "accounts", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(result.Rows) != 3 {
+		t.Errorf("rows after CommitXA = %d, want 3", len(result.Rows))
+	}
+}
+
+// TestXA_RollbackByXID verifies that RollbackXA discards a prepared transaction
+func TestXA_RollbackByXID(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+
+	tx := beginLockTxn(t, ds)
+	if _, err := tx.Delete(ctx, "accounts", nil, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := tx.(domain.XATransaction).Prepare(ctx, "xid-2"); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if err := ds.RollbackXA(ctx, "xid-2"); err != nil {
+		t.Fatalf("RollbackXA() error = %v", err)
+	}
+
+	result, err := ds.Query(ctx, "accounts", &domain.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Errorf("rows after RollbackXA = %d, want 2", len(result.Rows))
+	}
+	if ds.locks.busy() {
+		t.Error("locks still held after RollbackXA")
+	}
+}
+
+// TestXA_PrepareValidatesDDL verifies that Prepare fails when the commit
+// would fail, here because another session created the same table
+func TestXA_PrepareValidatesDDL(t *testing.T) {
+	ds := newLockTestSource(t)
+	ctx := context.Background()
+	info := &domain.TableInfo{
+		Name:    "users",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}},
+	}
+
+	tx := beginLockTxn(t, ds)
+	defer tx.Rollback(ctx)
+	if err := tx.(domain.DDLTransaction).CreateTable(ctx, info); err != nil {
+		t.Fatalf("transactional CreateTable() error = %v", err)
+	}
+	if err := ds.CreateTable(ctx, info); err != nil {
+		t.Fatalf("concurrent CreateTable() error = %v", err)
+	}
+
+	if err := tx.(domain.XATransaction).Prepare(ctx, "xid-3"); err == nil {
+		t.Fatal("expected Prepare() to report the conflicting table")
+	}
+	if xids, _ := ds.RecoverXA(ctx); len(xids) != 0 {
+		t.Errorf("failed prepare left prepared transactions %v", xids)
+	}
+}
@@ -3,13 +3,13 @@ package parquet
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/filemeta"
 	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
@@ -18,6 +18,9 @@ import (
 // Default flush interval for periodic persistence.
 const defaultFlushInterval = 30 * time.Second
 
+// dsLog is the structured logger of the Parquet datasource
+var dsLog = logging.Module("resource.parquet")
+
 // ParquetAdapter is a Parquet datasource adapter with full write, persistence,
 // multi-table, WAL, and index support. It embeds MVCCDataSource for all
 // in-memory features (MVCC, transactions, indexing, query planning, buffer pool).
@@ -105,12 +108,12 @@ func (a *ParquetAdapter) Connect(ctx context.Context) error {
 		filePath := filepath.Join(a.dataDir, entry.Name())
 		tableInfo, rows, err := readParquetFile(filePath)
 		if err != nil {
-			log.Printf("warning: failed to read parquet file %q: %v", filePath, err)
+			dsLog.Warn("failed to read parquet file", "file", filePath, "err", err)
 			continue
 		}
 
 		if err := a.LoadTable(tableInfo.Name, tableInfo, rows); err != nil {
-			log.Printf("warning: failed to load table %q: %v", tableInfo.Name, err)
+			dsLog.Warn("failed to load table", "table", tableInfo.Name, "err", err)
 			continue
 		}
 	}
@@ -149,27 +152,27 @@ func (a *ParquetAdapter) replayWAL(ctx context.Context) error {
 		switch entry.Type {
 		case WALInsert:
 			if _, err := a.MVCCDataSource.Insert(ctx, entry.TableName, entry.Rows, nil); err != nil {
-				log.Printf("WAL replay: insert into %s failed: %v", entry.TableName, err)
+				dsLog.Warn("WAL replay failed", "op", "insert", "table", entry.TableName, "err", err)
 			}
 		case WALUpdate:
 			if _, err := a.MVCCDataSource.Update(ctx, entry.TableName, entry.Filters, entry.Updates, nil); err != nil {
-				log.Printf("WAL replay: update %s failed: %v", entry.TableName, err)
+				dsLog.Warn("WAL replay failed", "op", "update", "table", entry.TableName, "err", err)
 			}
 		case WALDelete:
 			if _, err := a.MVCCDataSource.Delete(ctx, entry.TableName, entry.Filters, nil); err != nil {
-				log.Printf("WAL replay: delete from %s failed: %v", entry.TableName, err)
+				dsLog.Warn("WAL replay failed", "op", "delete", "table", entry.TableName, "err", err)
 			}
 		case WALCreateTable:
 			if err := a.MVCCDataSource.CreateTable(ctx, entry.Schema); err != nil {
-				log.Printf("WAL replay: create table %s failed: %v", entry.TableName, err)
+				dsLog.Warn("WAL replay failed", "op", "create_table", "table", entry.TableName, "err", err)
 			}
 		case WALDropTable:
 			if err := a.MVCCDataSource.DropTable(ctx, entry.TableName); err != nil {
-				log.Printf("WAL replay: drop table %s failed: %v", entry.TableName, err)
+				dsLog.Warn("WAL replay failed", "op", "drop_table", "table", entry.TableName, "err", err)
 			}
 		case WALTruncateTable:
 			if err := a.MVCCDataSource.TruncateTable(ctx, entry.TableName); err != nil {
-				log.Printf("WAL replay: truncate table %s failed: %v", entry.TableName, err)
+				dsLog.Warn("WAL replay failed", "op", "truncate_table", "table", entry.TableName, "err", err)
 			}
 		}
 	}
@@ -187,7 +190,7 @@ func (a *ParquetAdapter) loadIndexMeta() {
 
 	for _, idx := range meta.Indexes {
 		if err := a.MVCCDataSource.CreateIndexWithColumns(idx.Table, idx.Columns, idx.Type, idx.Unique); err != nil {
-			log.Printf("warning: failed to rebuild index %s on %s: %v", idx.Name, idx.Table, err)
+			dsLog.Warn("failed to rebuild index", "index", idx.Name, "table", idx.Table, "err", err)
 		}
 	}
 }
@@ -296,7 +299,7 @@ func (a *ParquetAdapter) CreateTable(ctx context.Context, tableInfo *domain.Tabl
 	// Write empty Parquet file
 	filePath := filepath.Join(a.dataDir, tableInfo.Name+".parquet")
 	if err := writeParquetFile(filePath, tableInfo, nil, a.compression); err != nil {
-		log.Printf("warning: failed to write initial parquet file for %s: %v", tableInfo.Name, err)
+		dsLog.Warn("failed to write initial parquet file", "table", tableInfo.Name, "err", err)
 	}
 
 	return nil
@@ -391,7 +394,7 @@ func (a *ParquetAdapter) flushDirtyTables() {
 	allFlushed := true
 	for tableName := range tables {
 		if err := a.flushTable(tableName); err != nil {
-			log.Printf("flush %s failed: %v", tableName, err)
+			dsLog.Error("flush failed", "table", tableName, "err", err)
 			allFlushed = false
 			continue
 		}
@@ -401,11 +404,11 @@ func (a *ParquetAdapter) flushDirtyTables() {
 	// If all tables flushed successfully, checkpoint and truncate WAL
 	if allFlushed && a.wal != nil {
 		if err := a.wal.Append(&WALEntry{Type: WALCheckpoint}); err != nil {
-			log.Printf("WAL checkpoint failed: %v", err)
+			dsLog.Error("WAL checkpoint failed", "err", err)
 			return
 		}
 		if err := a.wal.Truncate(); err != nil {
-			log.Printf("WAL truncate failed: %v", err)
+			dsLog.Error("WAL truncate failed", "err", err)
 		}
 	}
 }
@@ -512,7 +515,7 @@ func (a *ParquetAdapter) persistAllIndexMeta() {
 
 	if len(allIndexes) > 0 {
 		if err := a.PersistIndexMeta(allIndexes); err != nil {
-			log.Printf("warning: failed to persist index metadata: %v", err)
+			dsLog.Warn("failed to persist index metadata", "err", err)
 		}
 	}
 }
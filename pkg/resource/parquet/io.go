@@ -12,6 +12,12 @@ import (
 	"github.com/parquet-go/parquet-go/compress"
 )
 
+// ReadFile reads a .parquet file and returns its schema and rows. The table
+// name is the file name without the .parquet extension.
+func ReadFile(filePath string) (*domain.TableInfo, []domain.Row, error) {
+	return readParquetFile(filePath)
+}
+
 // readParquetFile reads a native .parquet file and returns schema and rows.
 func readParquetFile(filePath string) (*domain.TableInfo, []domain.Row, error) {
 	f, err := os.Open(filePath)
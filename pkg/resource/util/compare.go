@@ -4,9 +4,9 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
-// CompareEqual 比较两个值是否相等
+// CompareEqual 比较两个值是否相等（NULL 安全，两个 NULL 视为相等）
 func CompareEqual(a, b interface{}) bool {
-	result, _ := utils.CompareValues(a, b, "=")
+	result, _ := utils.CompareValues(a, b, "<=>")
 	return result
 }
 
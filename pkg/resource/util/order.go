@@ -1,49 +1,61 @@
 package util
 
 import (
-	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"sort"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
-// ApplyOrder 应用排序
+// ApplyOrder 应用排序：按 options.SortKeys() 依次比较，字符串按排序键的排序规则比较，
+// 缺少排序列的行排在升序的最后（降序的最前）
 func ApplyOrder(rows []domain.Row, options *domain.QueryOptions) []domain.Row {
-	if options == nil || options.OrderBy == "" {
+	keys := options.SortKeys()
+	if len(keys) == 0 {
 		return rows
 	}
 
 	result := make([]domain.Row, len(rows))
 	copy(result, rows)
 
-	// 获取排序列
-	column := options.OrderBy
-
-	// 获取排序方向
-	order := options.Order
-	if order == "" {
-		order = "ASC"
-	}
-
-	// 排序
-	sort.Slice(result, func(i, j int) bool {
-		valI, existsI := result[i][column]
-		valJ, existsJ := result[j][column]
-
-		if !existsI && !existsJ {
-			return false
+	sort.SliceStable(result, func(i, j int) bool {
+		for _, key := range keys {
+			desc := strings.EqualFold(key.Direction, "DESC")
+			valI, existsI := result[i][key.Column]
+			valJ, existsJ := result[j][key.Column]
+
+			var cmp int
+			switch {
+			case !existsI && !existsJ:
+				continue
+			case !existsI:
+				cmp = 1
+			case !existsJ:
+				cmp = -1
+			default:
+				cmp = utils.CompareValuesForSortWithCollation(valI, valJ, key.Collation)
+			}
+			if cmp == 0 {
+				continue
+			}
+			if desc {
+				return cmp > 0
+			}
+			return cmp < 0
 		}
-		if !existsI {
-			return order != "ASC"
-		}
-		if !existsJ {
-			return order == "ASC"
-		}
-
-		cmp := CompareValues(valI, valJ)
-		if order == "DESC" {
-			return cmp > 0
-		}
-		return cmp < 0
+		return false
 	})
 
 	return result
 }
+
+// SortColumns 返回排序键引用的列
+func SortColumns(options *domain.QueryOptions) []string {
+	keys := options.SortKeys()
+	columns := make([]string, 0, len(keys))
+	for _, key := range keys {
+		columns = append(columns, key.Column)
+	}
+	return columns
+}
@@ -83,8 +83,8 @@ func GetNeededColumns(options *domain.QueryOptions) []string {
 		needed[filter.Field] = true
 	}
 
-	if options.OrderBy != "" {
-		needed[options.OrderBy] = true
+	for _, col := range SortColumns(options) {
+		needed[col] = true
 	}
 
 	if len(options.SelectColumns) > 0 {
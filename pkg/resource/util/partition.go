@@ -0,0 +1,292 @@
+package util
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// ValidatePartition 校验表的分区定义
+// 分区键必须是表中的列；RANGE 分区上界严格递增且只有最后一个分区可以是 MAXVALUE；
+// 与 MySQL 一致，主键和唯一键必须包含分区键，使唯一性可以在单个分区内校验
+func ValidatePartition(table *domain.TableInfo) error {
+	p := table.Partition
+	if p == nil {
+		return nil
+	}
+
+	col, ok := findColumn(table, p.Column)
+	if !ok {
+		return fmt.Errorf("partition column '%s' not found in table %s", p.Column, table.Name)
+	}
+	if col.IsGenerated && col.GeneratedType == "VIRTUAL" {
+		return fmt.Errorf("partition column '%s' cannot be a VIRTUAL generated column", p.Column)
+	}
+	p.Column = col.Name
+
+	if len(p.Partitions) == 0 {
+		return fmt.Errorf("table %s must have at least one partition", table.Name)
+	}
+	seen := make(map[string]bool, len(p.Partitions))
+	for _, def := range p.Partitions {
+		if def.Name == "" {
+			return fmt.Errorf("partition name cannot be empty")
+		}
+		key := strings.ToLower(def.Name)
+		if seen[key] {
+			return fmt.Errorf("duplicate partition name '%s'", def.Name)
+		}
+		seen[key] = true
+	}
+
+	switch p.Type {
+	case domain.PartitionTypeRange:
+		for i, def := range p.Partitions {
+			if def.LessThan == nil {
+				if i != len(p.Partitions)-1 {
+					return fmt.Errorf("MAXVALUE can only be used in the last partition definition")
+				}
+				continue
+			}
+			if i > 0 && utils.CompareValuesForSort(def.LessThan, p.Partitions[i-1].LessThan) <= 0 {
+				return fmt.Errorf("VALUES LESS THAN value must be strictly increasing for each partition")
+			}
+		}
+	case domain.PartitionTypeHash:
+	default:
+		return fmt.Errorf("unsupported partition type: %s", p.Type)
+	}
+
+	for _, c := range table.Columns {
+		if (c.Primary && !col.Primary) || (c.Unique && !strings.EqualFold(c.Name, col.Name)) {
+			return fmt.Errorf("a PRIMARY KEY or UNIQUE index must include the partition column '%s'", col.Name)
+		}
+	}
+	return nil
+}
+
+// LocatePartition 返回行所属分区的下标
+// RANGE 分区中 NULL 落在第一个分区；HASH 分区中 NULL 按 0 计算
+func LocatePartition(table *domain.TableInfo, row domain.Row) (int, error) {
+	value := row[table.Partition.Column]
+	idx, ok := locatePartitionValue(table.Partition, value)
+	if !ok {
+		return -1, domain.NewErrNoPartitionForValue(table.Name, value)
+	}
+	return idx, nil
+}
+
+// PrunePartitions 根据过滤条件裁剪分区，返回可能包含匹配行的分区下标（升序）
+// 支持分区键上的 =、<=>、IN、IS NULL，RANGE 分区还支持 <、<=、>、>=、BETWEEN，
+// 以及它们的 AND/OR 组合；其他条件不裁剪
+func PrunePartitions(p *domain.PartitionInfo, filters []domain.Filter) []int {
+	set := pruneAll(p, filters)
+	result := make([]int, 0, len(set))
+	for i, ok := range set {
+		if ok {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// pruneAll 多个过滤器之间为 AND 关系
+func pruneAll(p *domain.PartitionInfo, filters []domain.Filter) []bool {
+	set := partitionSet(len(p.Partitions), true)
+	for _, f := range filters {
+		intersectPartitions(set, pruneFilter(p, f))
+	}
+	return set
+}
+
+func pruneFilter(p *domain.PartitionInfo, f domain.Filter) []bool {
+	n := len(p.Partitions)
+	switch strings.ToUpper(f.LogicOp) {
+	case "OR":
+		if len(f.SubFilters) == 0 {
+			return partitionSet(n, true)
+		}
+		set := partitionSet(n, false)
+		for _, sub := range f.SubFilters {
+			unionPartitions(set, pruneFilter(p, sub))
+		}
+		return set
+	case "AND":
+		return pruneAll(p, f.SubFilters)
+	}
+
+	field := f.Field
+	if idx := strings.LastIndex(field, "."); idx >= 0 {
+		field = field[idx+1:]
+	}
+	if !strings.EqualFold(field, p.Column) {
+		return partitionSet(n, true)
+	}
+
+	switch strings.ToUpper(f.Operator) {
+	case "=", "EQ":
+		if f.Value == nil {
+			return partitionSet(n, false)
+		}
+		return singlePartition(p, f.Value)
+	case "<=>", "NULLEQ":
+		return singlePartition(p, f.Value)
+	case "IS NULL", "ISNULL":
+		return singlePartition(p, nil)
+	case "IN":
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			return partitionSet(n, true)
+		}
+		set := partitionSet(n, false)
+		for _, v := range values {
+			if v != nil {
+				unionPartitions(set, singlePartition(p, v))
+			}
+		}
+		return set
+	}
+
+	if p.Type != domain.PartitionTypeRange || f.Value == nil {
+		return partitionSet(n, true)
+	}
+	switch strings.ToUpper(f.Operator) {
+	case ">", "GT", ">=", "GE", "GTE":
+		return rangeFrom(p, f.Value)
+	case "<", "LT":
+		return rangeTo(p, f.Value, false)
+	case "<=", "LE", "LTE":
+		return rangeTo(p, f.Value, true)
+	case "BETWEEN":
+		bounds, ok := f.Value.([]interface{})
+		if !ok || len(bounds) != 2 || bounds[0] == nil || bounds[1] == nil {
+			return partitionSet(n, true)
+		}
+		set := rangeFrom(p, bounds[0])
+		intersectPartitions(set, rangeTo(p, bounds[1], true))
+		return set
+	}
+	return partitionSet(n, true)
+}
+
+// singlePartition 只包含 value 所在分区的集合
+func singlePartition(p *domain.PartitionInfo, value interface{}) []bool {
+	set := partitionSet(len(p.Partitions), false)
+	if idx, ok := locatePartitionValue(p, value); ok {
+		set[idx] = true
+	}
+	return set
+}
+
+// rangeFrom 可能包含 >= value 的行的 RANGE 分区
+func rangeFrom(p *domain.PartitionInfo, value interface{}) []bool {
+	set := partitionSet(len(p.Partitions), false)
+	start, ok := locatePartitionValue(p, value)
+	if !ok {
+		return set
+	}
+	for i := start; i < len(set); i++ {
+		set[i] = true
+	}
+	return set
+}
+
+// rangeTo 可能包含 < value（inclusive 时为 <=）的行的 RANGE 分区
+func rangeTo(p *domain.PartitionInfo, value interface{}, inclusive bool) []bool {
+	set := partitionSet(len(p.Partitions), false)
+	for i := range p.Partitions {
+		if i == 0 {
+			set[i] = true
+			continue
+		}
+		// 分区 i 的下界是前一个分区的上界
+		cmp := utils.CompareValuesForSort(p.Partitions[i-1].LessThan, value)
+		if cmp < 0 || (inclusive && cmp == 0) {
+			set[i] = true
+		}
+	}
+	return set
+}
+
+// locatePartitionValue 返回分区键值所在的分区下标
+func locatePartitionValue(p *domain.PartitionInfo, value interface{}) (int, bool) {
+	n := len(p.Partitions)
+	if n == 0 {
+		return -1, false
+	}
+
+	if p.Type == domain.PartitionTypeHash {
+		return int(hashPartitionKey(value) % uint64(n)), true
+	}
+
+	if value == nil {
+		return 0, true
+	}
+	for i, def := range p.Partitions {
+		if def.LessThan == nil || utils.CompareValuesForSort(value, def.LessThan) < 0 {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// hashPartitionKey 计算 HASH 分区键：整数取绝对值，其他值使用 FNV-1a
+func hashPartitionKey(value interface{}) uint64 {
+	if value == nil {
+		return 0
+	}
+	if s, ok := value.(string); ok {
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			value = v
+		} else {
+			h := fnv.New64a()
+			h.Write([]byte(s))
+			return h.Sum64()
+		}
+	}
+	v, err := utils.ToInt64(value)
+	if err != nil {
+		h := fnv.New64a()
+		h.Write([]byte(utils.ToString(value)))
+		return h.Sum64()
+	}
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
+
+func findColumn(table *domain.TableInfo, name string) (domain.ColumnInfo, bool) {
+	for _, col := range table.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return col, true
+		}
+	}
+	return domain.ColumnInfo{}, false
+}
+
+func partitionSet(n int, value bool) []bool {
+	set := make([]bool, n)
+	if value {
+		for i := range set {
+			set[i] = true
+		}
+	}
+	return set
+}
+
+func intersectPartitions(dst, src []bool) {
+	for i := range dst {
+		dst[i] = dst[i] && src[i]
+	}
+}
+
+func unionPartitions(dst, src []bool) {
+	for i := range dst {
+		dst[i] = dst[i] || src[i]
+	}
+}
@@ -0,0 +1,152 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func rangePartitionTable() *domain.TableInfo {
+	return &domain.TableInfo{
+		Name: "t",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true},
+			{Name: "v", Type: "INT"},
+		},
+		Partition: &domain.PartitionInfo{
+			Type:   domain.PartitionTypeRange,
+			Column: "ID",
+			Partitions: []domain.PartitionDef{
+				{Name: "p0", LessThan: int64(10)},
+				{Name: "p1", LessThan: int64(20)},
+				{Name: "p2"},
+			},
+		},
+	}
+}
+
+// TestValidatePartition 测试分区定义校验
+func TestValidatePartition(t *testing.T) {
+	table := rangePartitionTable()
+	if err := ValidatePartition(table); err != nil {
+		t.Fatalf("ValidatePartition() error = %v", err)
+	}
+	if table.Partition.Column != "id" {
+		t.Errorf("partition column not canonicalized: %q", table.Partition.Column)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*domain.TableInfo)
+	}{
+		{"unknown column", func(t *domain.TableInfo) { t.Partition.Column = "x" }},
+		{"no partitions", func(t *domain.TableInfo) { t.Partition.Partitions = nil }},
+		{"duplicate name", func(t *domain.TableInfo) { t.Partition.Partitions[1].Name = "P0" }},
+		{"not increasing", func(t *domain.TableInfo) { t.Partition.Partitions[1].LessThan = int64(5) }},
+		{"maxvalue not last", func(t *domain.TableInfo) { t.Partition.Partitions[0].LessThan = nil }},
+		{"unknown type", func(t *domain.TableInfo) { t.Partition.Type = "LIST" }},
+		{"primary key without partition column", func(t *domain.TableInfo) { t.Partition.Column = "v" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := rangePartitionTable()
+			tt.modify(table)
+			if err := ValidatePartition(table); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+// TestLocatePartition 测试行到分区的定位
+func TestLocatePartition(t *testing.T) {
+	table := rangePartitionTable()
+	table.Partition.Column = "id"
+	tests := []struct {
+		value interface{}
+		want  int
+	}{
+		{nil, 0},
+		{int64(-5), 0},
+		{int64(9), 0},
+		{int64(10), 1},
+		{19, 1},
+		{int64(1000), 2},
+	}
+	for _, tt := range tests {
+		got, err := LocatePartition(table, domain.Row{"id": tt.value})
+		if err != nil || got != tt.want {
+			t.Errorf("LocatePartition(%v) = %d, %v; want %d", tt.value, got, err, tt.want)
+		}
+	}
+
+	table.Partition.Partitions = table.Partition.Partitions[:2]
+	if _, err := LocatePartition(table, domain.Row{"id": int64(20)}); err == nil {
+		t.Error("expected error for value outside all partitions")
+	}
+
+	hash := &domain.TableInfo{
+		Name:      "h",
+		Partition: &domain.PartitionInfo{Type: domain.PartitionTypeHash, Column: "id", Partitions: make([]domain.PartitionDef, 4)},
+	}
+	for _, tt := range []struct {
+		value interface{}
+		want  int
+	}{{int64(6), 2}, {int64(-6), 2}, {"7", 3}, {nil, 0}} {
+		if got, _ := LocatePartition(hash, domain.Row{"id": tt.value}); got != tt.want {
+			t.Errorf("hash LocatePartition(%v) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestPrunePartitions 测试分区裁剪
+func TestPrunePartitions(t *testing.T) {
+	p := rangePartitionTable().Partition
+	p.Column = "id"
+
+	tests := []struct {
+		name    string
+		filters []domain.Filter
+		want    []int
+	}{
+		{"no filters", nil, []int{0, 1, 2}},
+		{"other column", []domain.Filter{{Field: "v", Operator: "=", Value: 1}}, []int{0, 1, 2}},
+		{"equal", []domain.Filter{{Field: "id", Operator: "=", Value: int64(15)}}, []int{1}},
+		{"qualified equal", []domain.Filter{{Field: "t.id", Operator: "=", Value: int64(25)}}, []int{2}},
+		{"equal null", []domain.Filter{{Field: "id", Operator: "=", Value: nil}}, []int{}},
+		{"is null", []domain.Filter{{Field: "id", Operator: "IS NULL"}}, []int{0}},
+		{"in", []domain.Filter{{Field: "id", Operator: "IN", Value: []interface{}{int64(1), int64(25)}}}, []int{0, 2}},
+		{"greater", []domain.Filter{{Field: "id", Operator: ">", Value: int64(12)}}, []int{1, 2}},
+		{"less", []domain.Filter{{Field: "id", Operator: "<", Value: int64(10)}}, []int{0}},
+		{"less equal", []domain.Filter{{Field: "id", Operator: "<=", Value: int64(10)}}, []int{0, 1}},
+		{"between", []domain.Filter{{Field: "id", Operator: "BETWEEN", Value: []interface{}{int64(12), int64(18)}}}, []int{1}},
+		{"and", []domain.Filter{
+			{Field: "id", Operator: ">=", Value: int64(5)},
+			{Field: "id", Operator: "<", Value: int64(15)},
+		}, []int{0, 1}},
+		{"or", []domain.Filter{{LogicOp: "OR", SubFilters: []domain.Filter{
+			{Field: "id", Operator: "=", Value: int64(1)},
+			{Field: "id", Operator: "=", Value: int64(30)},
+		}}}, []int{0, 2}},
+		{"or with other column", []domain.Filter{{LogicOp: "OR", SubFilters: []domain.Filter{
+			{Field: "id", Operator: "=", Value: int64(1)},
+			{Field: "v", Operator: "=", Value: int64(30)},
+		}}}, []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrunePartitions(p, tt.filters); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PrunePartitions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	hash := &domain.PartitionInfo{Type: domain.PartitionTypeHash, Column: "id", Partitions: make([]domain.PartitionDef, 3)}
+	if got := PrunePartitions(hash, []domain.Filter{{Field: "id", Operator: "=", Value: int64(4)}}); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("hash equal PrunePartitions() = %v", got)
+	}
+	if got := PrunePartitions(hash, []domain.Filter{{Field: "id", Operator: ">", Value: int64(4)}}); len(got) != 3 {
+		t.Errorf("hash range PrunePartitions() = %v, want all partitions", got)
+	}
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ValidateTTL 校验表的过期策略：过期列必须存在且保留时长为正
+func ValidateTTL(table *domain.TableInfo) error {
+	ttl := table.TTL
+	if ttl == nil {
+		return nil
+	}
+	if ttl.Duration <= 0 {
+		return fmt.Errorf("TTL duration must be positive")
+	}
+	col, ok := findColumn(table, ttl.Column)
+	if !ok {
+		return fmt.Errorf("TTL column '%s' not found in table %s", ttl.Column, table.Name)
+	}
+	ttl.Column = col.Name
+	return nil
+}
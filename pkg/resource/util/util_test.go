@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
@@ -387,6 +388,49 @@ func TestApplyOrder(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "multi-key order",
+			rows: []domain.Row{
+				{"dept": "b", "id": int64(1)},
+				{"dept": "a", "id": int64(2)},
+				{"dept": "b", "id": int64(3)},
+				{"id": int64(4)},
+			},
+			options: &domain.QueryOptions{
+				Sort: []domain.OrderByItem{{Column: "dept"}, {Column: "id", Direction: "desc"}},
+			},
+			validate: func(result []domain.Row) {
+				ids := make([]int64, len(result))
+				for i, row := range result {
+					ids[i] = row["id"].(int64)
+				}
+				if fmt.Sprint(ids) != "[2 3 1 4]" {
+					t.Errorf("Expected order [2 3 1 4], got %v", ids)
+				}
+			},
+		},
+		{
+			name: "collation order",
+			rows: []domain.Row{
+				{"name": "bob"},
+				{"name": "Alice"},
+				{"name": "alice"},
+				{"name": "Bob"},
+			},
+			options: &domain.QueryOptions{
+				Sort: []domain.OrderByItem{{Column: "name", Collation: "utf8mb4_general_ci"}},
+			},
+			validate: func(result []domain.Row) {
+				names := make([]string, len(result))
+				for i, row := range result {
+					names[i] = row["name"].(string)
+				}
+				// 大小写不敏感时相等的值保持原有顺序
+				if fmt.Sprint(names) != "[Alice alice bob Bob]" {
+					t.Errorf("Expected order [Alice alice bob Bob], got %v", names)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
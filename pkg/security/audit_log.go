@@ -33,6 +33,8 @@ const (
 	EventTypeError       AuditEventType = "error"
 	EventTypeAPIRequest  AuditEventType = "api_request"
 	EventTypeMCPToolCall AuditEventType = "mcp_tool_call"
+	EventTypeTTLPurge    AuditEventType = "ttl_purge"
+	EventTypeLockout     AuditEventType = "lockout"
 )
 
 // AuditEvent 审计事件
@@ -48,6 +50,8 @@ type AuditEvent struct {
 	Query     string                 `json:"query"`
 	Message   string                 `json:"message"`
 	Metadata  map[string]interface{} `json:"metadata"`
+	Client    map[string]string      `json:"client,omitempty"` // 客户端的连接属性（program_name、_client_version 等）
+	Labels    map[string]string      `json:"labels,omitempty"` // 查询标签（会话变量 query_labels 与语句开头的 /* key=value */ 注释）
 	Success   bool                   `json:"success"`
 	Duration  int64                  `json:"duration"` // 毫秒
 }
@@ -100,6 +104,16 @@ func (al *AuditLogger) Log(event *AuditEvent) {
 
 // LogQuery 记录查询
 func (al *AuditLogger) LogQuery(traceID, user, database, query string, duration int64, success bool) {
+	al.LogClientQuery(traceID, user, database, query, nil, duration, success)
+}
+
+// LogClientQuery 记录查询，client 为执行查询的连接的客户端属性
+func (al *AuditLogger) LogClientQuery(traceID, user, database, query string, client map[string]string, duration int64, success bool) {
+	al.LogLabeledQuery(traceID, user, database, query, client, nil, duration, success)
+}
+
+// LogLabeledQuery 记录查询，client 为连接的客户端属性，labels 为语句的查询标签
+func (al *AuditLogger) LogLabeledQuery(traceID, user, database, query string, client, labels map[string]string, duration int64, success bool) {
 	event := &AuditEvent{
 		ID:        generateEventID(),
 		TraceID:   traceID,
@@ -111,6 +125,8 @@ func (al *AuditLogger) LogQuery(traceID, user, database, query string, duration
 		Query:     query,
 		Success:   success,
 		Duration:  duration,
+		Client:    client,
+		Labels:    labels,
 	}
 
 	al.Log(event)
@@ -191,8 +207,42 @@ func (al *AuditLogger) LogDDL(traceID, user, database, query string, duration in
 	al.Log(event)
 }
 
+// LogTTLPurge 记录过期数据清理
+func (al *AuditLogger) LogTTLPurge(database, table string, rowsDeleted int64, cutoff time.Time, duration int64, err error) {
+	metadata := map[string]interface{}{
+		"rows_deleted": rowsDeleted,
+		"cutoff":       cutoff,
+	}
+	level := AuditLevelWarning
+	if err != nil {
+		metadata["error"] = err.Error()
+		level = AuditLevelError
+	}
+
+	event := &AuditEvent{
+		ID:        generateEventID(),
+		Timestamp: time.Now(),
+		Level:     level,
+		EventType: EventTypeTTLPurge,
+		User:      "system",
+		Database:  database,
+		Table:     table,
+		Message:   fmt.Sprintf("purged %d expired rows", rowsDeleted),
+		Metadata:  metadata,
+		Success:   err == nil,
+		Duration:  duration,
+	}
+
+	al.Log(event)
+}
+
 // LogLogin 记录登录
 func (al *AuditLogger) LogLogin(traceID, user, ip string, success bool) {
+	al.LogClientLogin(traceID, user, ip, nil, success)
+}
+
+// LogClientLogin 记录登录，client 为客户端握手时发送的连接属性
+func (al *AuditLogger) LogClientLogin(traceID, user, ip string, client map[string]string, success bool) {
 	event := &AuditEvent{
 		ID:        generateEventID(),
 		TraceID:   traceID,
@@ -202,6 +252,7 @@ func (al *AuditLogger) LogLogin(traceID, user, ip string, success bool) {
 		User:      user,
 		Message:   fmt.Sprintf("Login from %s", ip),
 		Success:   success,
+		Client:    client,
 		Metadata: map[string]interface{}{
 			"ip": ip,
 		},
@@ -210,6 +261,27 @@ func (al *AuditLogger) LogLogin(traceID, user, ip string, success bool) {
 	al.Log(event)
 }
 
+// LogLockout 记录用户因连续登录失败在某个 IP 上被临时锁定
+func (al *AuditLogger) LogLockout(traceID, user, ip string, failures int, duration time.Duration) {
+	event := &AuditEvent{
+		ID:        generateEventID(),
+		TraceID:   traceID,
+		Timestamp: time.Now(),
+		Level:     AuditLevelWarning,
+		EventType: EventTypeLockout,
+		User:      user,
+		Message:   fmt.Sprintf("Account locked for %s after %d consecutive failed logins from %s", duration, failures, ip),
+		Success:   false,
+		Metadata: map[string]interface{}{
+			"ip":       ip,
+			"failures": failures,
+			"duration": duration.String(),
+		},
+	}
+
+	al.Log(event)
+}
+
 // LogLogout 记录登出
 func (al *AuditLogger) LogLogout(traceID, user string) {
 	event := &AuditEvent{
@@ -285,25 +357,44 @@ func (al *AuditLogger) LogError(traceID, user, database, message string, err err
 	al.Log(event)
 }
 
+// APIPrincipal HTTP API 请求的认证主体
+type APIPrincipal struct {
+	Name       string   // API 客户端名或 JWT 中的用户
+	AuthMethod string   // 认证方式：signature、api_key 或 jwt
+	Scopes     []string // 生效的权限范围
+}
+
 // LogAPIRequest 记录 HTTP API 请求
 func (al *AuditLogger) LogAPIRequest(traceID, clientName, ip, method, path, sql, database string, duration int64, success bool) {
+	al.LogAPIRequestBy(traceID, APIPrincipal{Name: clientName}, ip, method, path, sql, database, duration, success)
+}
+
+// LogAPIRequestBy 记录 HTTP API 请求及其认证主体
+func (al *AuditLogger) LogAPIRequestBy(traceID string, principal APIPrincipal, ip, method, path, sql, database string, duration int64, success bool) {
+	metadata := map[string]interface{}{
+		"ip":     ip,
+		"method": method,
+		"path":   path,
+	}
+	if principal.AuthMethod != "" {
+		metadata["auth_method"] = principal.AuthMethod
+	}
+	if len(principal.Scopes) > 0 {
+		metadata["scopes"] = principal.Scopes
+	}
 	event := &AuditEvent{
 		ID:        generateEventID(),
 		TraceID:   traceID,
 		Timestamp: time.Now(),
 		Level:     AuditLevelInfo,
 		EventType: EventTypeAPIRequest,
-		User:      clientName,
+		User:      principal.Name,
 		Database:  database,
 		Query:     sql,
 		Message:   fmt.Sprintf("%s %s", method, path),
 		Success:   success,
 		Duration:  duration,
-		Metadata: map[string]interface{}{
-			"ip":     ip,
-			"method": method,
-			"path":   path,
-		},
+		Metadata:  metadata,
 	}
 
 	al.Log(event)
@@ -49,6 +49,42 @@ func TestAuditLogger_LogQuery(t *testing.T) {
 	assert.True(t, events[0].Success)
 }
 
+func TestAuditLogger_LogClientAttributes(t *testing.T) {
+	auditor := NewAuditLogger(10)
+	client := map[string]string{"program_name": "billing-worker", "_client_version": "8.0.33"}
+
+	auditor.LogClientLogin("", "user1", "192.168.1.1", client, true)
+	auditor.LogClientQuery("", "user1", "db1", "SELECT 1", client, 5, true)
+	auditor.LogQuery("", "user1", "db1", "SELECT 2", 5, true)
+
+	events := auditor.GetEventsByUser("user1")
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, client, events[0].Client)
+	assert.Equal(t, client, events[1].Client)
+	assert.Nil(t, events[2].Client)
+
+	exported, err := auditor.Export()
+	assert.NoError(t, err)
+	assert.Contains(t, exported, `"program_name": "billing-worker"`)
+}
+
+func TestAuditLogger_LogLabeledQuery(t *testing.T) {
+	auditor := NewAuditLogger(10)
+	labels := map[string]string{"app": "checkout", "request_id": "abc"}
+
+	auditor.LogLabeledQuery("", "user1", "db1", "/* app=checkout,request_id=abc */ SELECT 1", nil, labels, 5, true)
+	auditor.LogClientQuery("", "user1", "db1", "SELECT 2", nil, 5, true)
+
+	events := auditor.GetEventsByUser("user1")
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, labels, events[0].Labels)
+	assert.Nil(t, events[1].Labels)
+
+	exported, err := auditor.Export()
+	assert.NoError(t, err)
+	assert.Contains(t, exported, `"request_id": "abc"`)
+}
+
 func TestAuditLogger_LogInsert(t *testing.T) {
 	auditor := NewAuditLogger(10)
 
@@ -0,0 +1,267 @@
+package security
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ciphertextPrefix 加密列中密文的格式版本前缀
+// 加密列中非 NULL 的存储值都是密文，由列的加密设置而不是前缀判断；前缀只用于校验格式
+const ciphertextPrefix = "enc:v1:"
+
+// 从数据密钥派生子密钥时使用的标签
+const (
+	labelEncrypt    = "sqlexec column encryption"
+	labelNonce      = "sqlexec deterministic nonce"
+	labelBlindIndex = "sqlexec blind index"
+)
+
+// blindIndexSize 盲索引保留的 HMAC 字节数
+const blindIndexSize = 16
+
+// ColumnCipher 加密列的加解密（AES-256-GCM），密钥由 KeyProvider 提供，首次使用时加载并缓存
+type ColumnCipher struct {
+	keys  domain.KeyProvider
+	mu    sync.Mutex
+	cache map[string]*columnKey
+}
+
+// columnKey 由一个数据密钥派生出的子密钥
+type columnKey struct {
+	aead     cipher.AEAD
+	nonceKey []byte // 确定性加密：nonce 取明文的 HMAC
+	indexKey []byte // 盲索引：明文的 HMAC
+}
+
+// NewColumnCipher 创建加密列的加解密器
+func NewColumnCipher(keys domain.KeyProvider) *ColumnCipher {
+	return &ColumnCipher{keys: keys, cache: make(map[string]*columnKey)}
+}
+
+func (c *ColumnCipher) key(ctx context.Context, keyID string) (*columnKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if k, ok := c.cache[keyID]; ok {
+		return k, nil
+	}
+	dataKey, err := c.keys.DataKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKey) != columnKeySize {
+		return nil, fmt.Errorf("encryption key '%s' must be %d bytes, got %d", keyID, columnKeySize, len(dataKey))
+	}
+	block, err := aes.NewCipher(derive(dataKey, labelEncrypt))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	k := &columnKey{
+		aead:     aead,
+		nonceKey: derive(dataKey, labelNonce),
+		indexKey: derive(dataKey, labelBlindIndex),
+	}
+	c.cache[keyID] = k
+	return k, nil
+}
+
+// derive 用 HMAC-SHA256 从数据密钥派生子密钥
+func derive(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// Encrypt 加密写入列的值，NULL 保持不变
+// 值来自客户端，即使形如密文也按明文加密
+func (c *ColumnCipher) Encrypt(ctx context.Context, col *domain.ColumnInfo, value interface{}) (interface{}, error) {
+	if value == nil || col.Encryption == nil {
+		return value, nil
+	}
+	k, err := c.key(ctx, col.Encryption.Key())
+	if err != nil {
+		return nil, err
+	}
+	plaintext := encodePlain(normalizeValue(col.Type, value))
+
+	aad := additionalData(col)
+	nonce := make([]byte, k.aead.NonceSize())
+	if col.Encryption.Mode == domain.EncryptionDeterministic {
+		// nonce 同样包含附加认证数据，不同列中相同的明文得到不同的密文
+		mac := hmac.New(sha256.New, k.nonceKey)
+		mac.Write(aad)
+		mac.Write([]byte{0})
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := k.aead.Seal(nonce, nonce, plaintext, aad)
+	return ciphertextPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密加密列中存储的值，NULL 保持不变
+func (c *ColumnCipher) Decrypt(ctx context.Context, col *domain.ColumnInfo, value interface{}) (interface{}, error) {
+	if value == nil || col.Encryption == nil {
+		return value, nil
+	}
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, ciphertextPrefix) {
+		return nil, fmt.Errorf("malformed ciphertext in column '%s'", col.Name)
+	}
+	k, err := c.key(ctx, col.Encryption.Key())
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(s, ciphertextPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed ciphertext in column '%s'", col.Name)
+	}
+	nonceSize := k.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("malformed ciphertext in column '%s'", col.Name)
+	}
+	plaintext, err := k.aead.Open(nil, data[:nonceSize], data[nonceSize:], additionalData(col))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt column '%s': %w", col.Name, err)
+	}
+	return decodePlain(plaintext)
+}
+
+// additionalData 密文的附加认证数据：建表时确定的"表名.列名"，密文不能在表和列之间复制
+func additionalData(col *domain.ColumnInfo) []byte {
+	if col.Encryption.Context != "" {
+		return []byte(col.Encryption.Context)
+	}
+	return []byte(col.Name)
+}
+
+// BlindIndex 计算写入或查找的值的盲索引（明文的 HMAC），NULL 的盲索引为 NULL
+func (c *ColumnCipher) BlindIndex(ctx context.Context, col *domain.ColumnInfo, value interface{}) (interface{}, error) {
+	if value == nil || col.Encryption == nil {
+		return value, nil
+	}
+	k, err := c.key(ctx, col.Encryption.Key())
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write(encodePlain(normalizeValue(col.Type, value)))
+	return hex.EncodeToString(mac.Sum(nil)[:blindIndexSize]), nil
+}
+
+// normalizeValue 按列类型统一值的表示，使等值查找的字面量（如 INT 列上的 '5'）和存储的值得到相同的密文
+func normalizeValue(columnType string, value interface{}) interface{} {
+	base := strings.ToLower(strings.TrimSpace(columnType))
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+	switch base {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "int8", "int16", "int32", "int64":
+		switch v := value.(type) {
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return n
+			}
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v)
+			}
+		}
+	case "char", "varchar", "string", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		switch v := value.(type) {
+		case string:
+		case []byte:
+			return string(v)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return value
+}
+
+// encodePlain 把值编码为带类型标记的明文，解密后还原为相同类型
+func encodePlain(value interface{}) []byte {
+	var tag byte
+	var text string
+	switch v := value.(type) {
+	case string:
+		tag, text = 's', v
+	case []byte:
+		return append([]byte{'b'}, v...)
+	case bool:
+		tag, text = 't', strconv.FormatBool(v)
+	case int:
+		tag, text = 'i', strconv.FormatInt(int64(v), 10)
+	case int8:
+		tag, text = 'i', strconv.FormatInt(int64(v), 10)
+	case int16:
+		tag, text = 'i', strconv.FormatInt(int64(v), 10)
+	case int32:
+		tag, text = 'i', strconv.FormatInt(int64(v), 10)
+	case int64:
+		tag, text = 'i', strconv.FormatInt(v, 10)
+	case uint:
+		tag, text = 'u', strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		tag, text = 'u', strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		tag, text = 'u', strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		tag, text = 'u', strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		tag, text = 'u', strconv.FormatUint(v, 10)
+	case float32:
+		tag, text = 'f', strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		tag, text = 'f', strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		tag, text = 'd', v.Format(time.RFC3339Nano)
+	default:
+		tag, text = 's', fmt.Sprint(v)
+	}
+	return append([]byte{tag}, text...)
+}
+
+// decodePlain 还原 encodePlain 编码的值
+func decodePlain(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty plaintext")
+	}
+	text := string(data[1:])
+	switch data[0] {
+	case 's':
+		return text, nil
+	case 'b':
+		return append([]byte(nil), data[1:]...), nil
+	case 't':
+		return strconv.ParseBool(text)
+	case 'i':
+		return strconv.ParseInt(text, 10, 64)
+	case 'u':
+		return strconv.ParseUint(text, 10, 64)
+	case 'f':
+		return strconv.ParseFloat(text, 64)
+	case 'd':
+		return time.Parse(time.RFC3339Nano, text)
+	}
+	return nil, fmt.Errorf("unknown plaintext type '%c'", data[0])
+}
@@ -0,0 +1,173 @@
+package security
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDataKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func testCipher(t *testing.T) *ColumnCipher {
+	t.Helper()
+	return NewColumnCipher(KeyProviderFunc(func(ctx context.Context, keyID string) ([]byte, error) {
+		return hex.DecodeString(testDataKey)
+	}))
+}
+
+func TestColumnCipher_RoundTrip(t *testing.T) {
+	c := testCipher(t)
+	ctx := context.Background()
+	col := &domain.ColumnInfo{Name: "v", Type: "VARCHAR(100)", Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionRandomized, Context: "t.v"}}
+
+	enc, err := c.Encrypt(ctx, col, "secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enc.(string), ciphertextPrefix))
+	assert.NotContains(t, enc, "secret")
+
+	// 随机加密：相同明文每次得到不同密文
+	enc2, err := c.Encrypt(ctx, col, "secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, enc, enc2)
+
+	plain, err := c.Decrypt(ctx, col, enc)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plain)
+
+	// NULL 保持不变
+	enc3, err := c.Encrypt(ctx, col, nil)
+	require.NoError(t, err)
+	assert.Nil(t, enc3)
+	plain, err = c.Decrypt(ctx, col, nil)
+	require.NoError(t, err)
+	assert.Nil(t, plain)
+
+	// 客户端写入的形如密文的值同样加密，解密后还原
+	again, err := c.Encrypt(ctx, col, enc)
+	require.NoError(t, err)
+	assert.NotEqual(t, enc, again)
+	plain, err = c.Decrypt(ctx, col, again)
+	require.NoError(t, err)
+	assert.Equal(t, enc, plain)
+	lookalike, err := c.Encrypt(ctx, col, ciphertextPrefix+"not base64")
+	require.NoError(t, err)
+	plain, err = c.Decrypt(ctx, col, lookalike)
+	require.NoError(t, err)
+	assert.Equal(t, ciphertextPrefix+"not base64", plain)
+
+	// 加密列中存储的值必须是密文
+	_, err = c.Decrypt(ctx, col, "plain")
+	assert.ErrorContains(t, err, "malformed ciphertext")
+
+	// 篡改的密文无法解密
+	tampered := enc.(string)
+	tampered = tampered[:len(tampered)-2] + strings.Repeat("A", 2)
+	_, err = c.Decrypt(ctx, col, tampered)
+	assert.Error(t, err)
+}
+
+func TestColumnCipher_Deterministic(t *testing.T) {
+	c := testCipher(t)
+	ctx := context.Background()
+	col := &domain.ColumnInfo{Name: "n", Type: "INT", Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionDeterministic}}
+
+	a, err := c.Encrypt(ctx, col, int64(42))
+	require.NoError(t, err)
+	// 字面量按列类型统一后得到相同密文
+	b, err := c.Encrypt(ctx, col, "42")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	other, err := c.Encrypt(ctx, col, int64(43))
+	require.NoError(t, err)
+	assert.NotEqual(t, a, other)
+
+	plain, err := c.Decrypt(ctx, col, a)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), plain)
+}
+
+func TestColumnCipher_BlindIndex(t *testing.T) {
+	c := testCipher(t)
+	ctx := context.Background()
+	col := &domain.ColumnInfo{Name: "email", Type: "VARCHAR(100)", Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionBlindIndex}}
+
+	idx, err := c.BlindIndex(ctx, col, "a@example.com")
+	require.NoError(t, err)
+	assert.Len(t, idx, 2*blindIndexSize)
+
+	other, err := c.BlindIndex(ctx, col, "b@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, idx, other)
+}
+
+// TestColumnCipher_AdditionalData 测试密文绑定"表名.列名"，复制到其他表或列后无法解密
+func TestColumnCipher_AdditionalData(t *testing.T) {
+	c := testCipher(t)
+	ctx := context.Background()
+	column := func(context string) *domain.ColumnInfo {
+		return &domain.ColumnInfo{Name: "ssn", Type: "VARCHAR(20)", Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionDeterministic, Context: context}}
+	}
+
+	enc, err := c.Encrypt(ctx, column("users.ssn"), "123-45-6789")
+	require.NoError(t, err)
+	plain, err := c.Decrypt(ctx, column("users.ssn"), enc)
+	require.NoError(t, err)
+	assert.Equal(t, "123-45-6789", plain)
+
+	for _, other := range []string{"users.tax_id", "admins.ssn"} {
+		_, err = c.Decrypt(ctx, column(other), enc)
+		assert.ErrorContains(t, err, "failed to decrypt column 'ssn'", other)
+		// 确定性加密的密文同样与表和列绑定
+		otherEnc, err := c.Encrypt(ctx, column(other), "123-45-6789")
+		require.NoError(t, err)
+		assert.NotEqual(t, enc.(string)[:32], otherEnc.(string)[:32], other)
+	}
+}
+
+func TestColumnCipher_KeyErrors(t *testing.T) {
+	ctx := context.Background()
+	col := &domain.ColumnInfo{Name: "v", Type: "TEXT", Encryption: &domain.ColumnEncryption{Mode: domain.EncryptionRandomized, KeyID: "missing"}}
+
+	_, err := NewColumnCipher(KeyRing{}).Encrypt(ctx, col, "x")
+	assert.ErrorContains(t, err, "'missing' is not configured")
+
+	short := NewColumnCipher(KeyProviderFunc(func(ctx context.Context, keyID string) ([]byte, error) {
+		return []byte("short"), nil
+	}))
+	_, err = short.Encrypt(ctx, col, "x")
+	assert.ErrorContains(t, err, "must be 32 bytes")
+}
+
+func TestKeyRing(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(testDataKey+"\n"), 0600))
+	t.Setenv("SQLEXEC_TEST_COLUMN_KEY", "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+
+	ring := KeyRing{
+		"default": {File: path},
+		"pii":     {Env: "SQLEXEC_TEST_COLUMN_KEY"},
+	}
+	require.NoError(t, ring.Validate())
+
+	fromFile, err := ring.DataKey(ctx, "default")
+	require.NoError(t, err)
+	fromEnv, err := ring.DataKey(ctx, "pii")
+	require.NoError(t, err)
+	assert.Equal(t, fromFile, fromEnv)
+
+	_, err = ring.DataKey(ctx, "other")
+	assert.Error(t, err)
+	assert.Error(t, KeyRing{"k": {}}.Validate())
+	assert.Error(t, KeyRing{"k": {Env: "A", File: "b"}}.Validate())
+
+	_, err = ParseDataKey("not a key")
+	assert.Error(t, err)
+}
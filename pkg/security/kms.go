@@ -0,0 +1,81 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// columnKeySize 列加密密钥长度（AES-256）
+const columnKeySize = 32
+
+// ParseDataKey 解析文本形式的密钥：64 个十六进制字符或 32 字节的 Base64
+func ParseDataKey(text string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if len(text) == 2*columnKeySize {
+		if key, err := hex.DecodeString(text); err == nil {
+			return key, nil
+		}
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == columnKeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key must be %d bytes, encoded as hex or base64", columnKeySize)
+}
+
+// KeySource 一个密钥的来源，Env 和 File 二选一
+type KeySource struct {
+	Env  string `json:"env,omitempty"`  // 保存密钥的环境变量
+	File string `json:"file,omitempty"` // 保存密钥的文件
+}
+
+// KeyRing 按密钥 ID 从环境变量或文件读取密钥
+type KeyRing map[string]KeySource
+
+// Validate 检查每个密钥都设置了一个来源
+func (r KeyRing) Validate() error {
+	for id, src := range r {
+		if (src.Env == "") == (src.File == "") {
+			return fmt.Errorf("key '%s' must set exactly one of env and file", id)
+		}
+	}
+	return nil
+}
+
+// DataKey 实现 domain.KeyProvider
+func (r KeyRing) DataKey(ctx context.Context, keyID string) ([]byte, error) {
+	src, ok := r[keyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key '%s' is not configured", keyID)
+	}
+	var text string
+	if src.Env != "" {
+		value, ok := os.LookupEnv(src.Env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s of encryption key '%s' is not set", src.Env, keyID)
+		}
+		text = value
+	} else {
+		data, err := os.ReadFile(src.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key '%s': %w", keyID, err)
+		}
+		text = string(data)
+	}
+	key, err := ParseDataKey(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key '%s': %w", keyID, err)
+	}
+	return key, nil
+}
+
+// KeyProviderFunc 把函数适配为 domain.KeyProvider，用于接入外部 KMS（如解密保存在配置中的数据密钥）
+type KeyProviderFunc func(ctx context.Context, keyID string) ([]byte, error)
+
+// DataKey 实现 domain.KeyProvider
+func (f KeyProviderFunc) DataKey(ctx context.Context, keyID string) ([]byte, error) {
+	return f(ctx, keyID)
+}
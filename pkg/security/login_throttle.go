@@ -0,0 +1,188 @@
+package security
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+)
+
+// 登录限流的默认参数
+const (
+	DefaultLoginBaseDelay       = 200 * time.Millisecond
+	DefaultLoginMaxDelay        = 5 * time.Second
+	DefaultLoginLockoutDuration = 15 * time.Minute
+
+	// maxLoginEntries 记录的用户/IP 组合超过该数量时清理已失效的记录
+	maxLoginEntries = 10000
+)
+
+// LoginThrottleConfig 登录失败限流配置
+type LoginThrottleConfig struct {
+	MaxFailures     int    `json:"max_failures"`     // 同一用户从同一 IP 连续失败多少次后临时锁定，0 表示不锁定
+	BaseDelay       string `json:"base_delay"`       // 第一次失败后回复错误前的等待时间（如 "200ms"），之后每次翻倍，"0s" 表示不等待
+	MaxDelay        string `json:"max_delay"`        // 等待时间上限，默认 "5s"
+	LockoutDuration string `json:"lockout_duration"` // 锁定时长，默认 "15m"；超过该时间没有失败时计数清零
+}
+
+// Validate 检查配置
+func (c LoginThrottleConfig) Validate() error {
+	if c.MaxFailures < 0 {
+		return fmt.Errorf("max_failures must not be negative")
+	}
+	for _, value := range []string{c.BaseDelay, c.MaxDelay, c.LockoutDuration} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("invalid login throttle duration %q", value)
+		}
+	}
+	return nil
+}
+
+// durations 返回等待时间和锁定时长，为空或无效时使用默认值
+func (c LoginThrottleConfig) durations() (base, maxDelay, lockout time.Duration) {
+	parse := func(value string, def time.Duration) time.Duration {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			return d
+		}
+		return def
+	}
+	return parse(c.BaseDelay, DefaultLoginBaseDelay), parse(c.MaxDelay, DefaultLoginMaxDelay),
+		parse(c.LockoutDuration, DefaultLoginLockoutDuration)
+}
+
+// AccountLockedError 连续登录失败次数过多，账号在该 IP 上被临时锁定
+type AccountLockedError struct {
+	User      string
+	Host      string
+	Failures  int
+	Remaining time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("Access denied for user '%s'@'%s'. Account is blocked for %s due to %d consecutive failed logins.",
+		e.User, e.Host, e.Remaining.Round(time.Second), e.Failures)
+}
+
+// MySQLErrorCode 实现 mysqlerrors.Coder
+func (e *AccountLockedError) MySQLErrorCode() uint16 {
+	return mysqlerrors.ErrAccountLocked
+}
+
+// loginFailures 一个用户/IP 组合的连续失败记录
+type loginFailures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// LoginThrottle 按用户和客户端 IP 统计连续登录失败（并发安全）
+// 每次失败后回复错误前等待的时间指数增长，连续失败达到上限后在锁定时长内直接拒绝登录
+type LoginThrottle struct {
+	mu          sync.Mutex
+	maxFailures int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	lockout     time.Duration
+	entries     map[string]*loginFailures
+	now         func() time.Time
+}
+
+// NewLoginThrottle 创建登录限流器
+func NewLoginThrottle(cfg LoginThrottleConfig) *LoginThrottle {
+	base, maxDelay, lockout := cfg.durations()
+	return &LoginThrottle{
+		maxFailures: cfg.MaxFailures,
+		baseDelay:   base,
+		maxDelay:    maxDelay,
+		lockout:     lockout,
+		entries:     make(map[string]*loginFailures),
+		now:         time.Now,
+	}
+}
+
+func loginKey(user, ip string) string {
+	return user + "@" + ip
+}
+
+// Check 在校验密码前调用，账号在该 IP 上处于锁定期时返回 *AccountLockedError
+func (t *LoginThrottle) Check(user, ip string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[loginKey(user, ip)]
+	if !ok || e.lockedUntil.IsZero() {
+		return nil
+	}
+	now := t.now()
+	if !now.Before(e.lockedUntil) {
+		// 锁定到期，重新计数
+		delete(t.entries, loginKey(user, ip))
+		return nil
+	}
+	return &AccountLockedError{User: user, Host: ip, Failures: e.count, Remaining: e.lockedUntil.Sub(now)}
+}
+
+// Failure 记录一次失败，返回回复错误前应等待的时间；本次失败导致锁定时 locked 为 true
+func (t *LoginThrottle) Failure(user, ip string) (delay time.Duration, locked bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if len(t.entries) >= maxLoginEntries {
+		t.prune(now)
+	}
+	key := loginKey(user, ip)
+	e, ok := t.entries[key]
+	if !ok || t.stale(e, now) {
+		e = &loginFailures{}
+		t.entries[key] = e
+	}
+	e.count++
+	e.last = now
+
+	delay = t.baseDelay
+	for i := 1; i < e.count && delay < t.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+	if t.maxFailures > 0 && e.count >= t.maxFailures && e.lockedUntil.IsZero() {
+		e.lockedUntil = now.Add(t.lockout)
+		locked = true
+	}
+	return delay, locked
+}
+
+// Success 登录成功后清除该用户/IP 组合的失败记录
+func (t *LoginThrottle) Success(user, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, loginKey(user, ip))
+}
+
+// LockoutDuration 返回锁定时长
+func (t *LoginThrottle) LockoutDuration() time.Duration {
+	return t.lockout
+}
+
+// stale 记录是否已失效：锁定已到期，或未锁定且最近一次失败已超过锁定时长
+func (t *LoginThrottle) stale(e *loginFailures, now time.Time) bool {
+	if !e.lockedUntil.IsZero() {
+		return !now.Before(e.lockedUntil)
+	}
+	return now.Sub(e.last) >= t.lockout
+}
+
+// prune 删除已失效的记录，调用方持有 mu
+func (t *LoginThrottle) prune(now time.Time) {
+	for key, e := range t.entries {
+		if t.stale(e, now) {
+			delete(t.entries, key)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package security
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestThrottle(cfg LoginThrottleConfig) (*LoginThrottle, *time.Time) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	throttle := NewLoginThrottle(cfg)
+	throttle.now = func() time.Time { return now }
+	return throttle, &now
+}
+
+func TestLoginThrottle_Backoff(t *testing.T) {
+	throttle, _ := newTestThrottle(LoginThrottleConfig{BaseDelay: "100ms", MaxDelay: "1s"})
+
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		delay, locked := throttle.Failure("app", "10.0.0.1")
+		assert.False(t, locked, "max_failures 为 0 时不锁定")
+		delays = append(delays, delay)
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	}, delays)
+
+	// 成功登录后重新计数
+	throttle.Success("app", "10.0.0.1")
+	delay, _ := throttle.Failure("app", "10.0.0.1")
+	assert.Equal(t, 100*time.Millisecond, delay)
+}
+
+func TestLoginThrottle_Lockout(t *testing.T) {
+	throttle, now := newTestThrottle(LoginThrottleConfig{MaxFailures: 3, BaseDelay: "0s", LockoutDuration: "10m"})
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, throttle.Check("app", "10.0.0.1"))
+		_, locked := throttle.Failure("app", "10.0.0.1")
+		assert.Equal(t, i == 3, locked, "failure %d", i)
+	}
+
+	err := throttle.Check("app", "10.0.0.1")
+	var lockedErr *AccountLockedError
+	require.True(t, errors.As(err, &lockedErr))
+	assert.Equal(t, 3, lockedErr.Failures)
+	assert.Equal(t, 10*time.Minute, lockedErr.Remaining)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrAccountLocked))
+
+	// 其他 IP 和其他用户不受影响
+	assert.NoError(t, throttle.Check("app", "10.0.0.2"))
+	assert.NoError(t, throttle.Check("other", "10.0.0.1"))
+
+	// 锁定到期后重新计数
+	*now = now.Add(10 * time.Minute)
+	assert.NoError(t, throttle.Check("app", "10.0.0.1"))
+	_, locked := throttle.Failure("app", "10.0.0.1")
+	assert.False(t, locked)
+}
+
+func TestLoginThrottle_FailuresExpire(t *testing.T) {
+	throttle, now := newTestThrottle(LoginThrottleConfig{MaxFailures: 2, BaseDelay: "0s", LockoutDuration: "1m"})
+
+	throttle.Failure("app", "10.0.0.1")
+	// 超过锁定时长没有失败，之前的失败不再计入
+	*now = now.Add(2 * time.Minute)
+	_, locked := throttle.Failure("app", "10.0.0.1")
+	assert.False(t, locked)
+	_, locked = throttle.Failure("app", "10.0.0.1")
+	assert.True(t, locked)
+}
+
+func TestLoginThrottleConfig_Validate(t *testing.T) {
+	assert.NoError(t, LoginThrottleConfig{}.Validate())
+	assert.NoError(t, LoginThrottleConfig{MaxFailures: 5, BaseDelay: "200ms", MaxDelay: "5s", LockoutDuration: "15m"}.Validate())
+	assert.Error(t, LoginThrottleConfig{MaxFailures: -1}.Validate())
+	assert.Error(t, LoginThrottleConfig{LockoutDuration: "1 hour"}.Validate())
+	assert.Error(t, LoginThrottleConfig{BaseDelay: "-1s"}.Validate())
+}
@@ -0,0 +1,100 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+)
+
+// PasswordPolicy 服务器管理用户的密码策略，零值表示不做限制
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`         // 最小长度（字符数）
+	MinUppercase     int  `json:"min_uppercase"`      // 至少包含的大写字母数
+	MinLowercase     int  `json:"min_lowercase"`      // 至少包含的小写字母数
+	MinDigits        int  `json:"min_digits"`         // 至少包含的数字数
+	MinSpecial       int  `json:"min_special"`        // 至少包含的特殊字符数（非字母、数字）
+	DisallowUserName bool `json:"disallow_user_name"` // 密码不能包含用户名（不区分大小写）
+	ExpireDays       int  `json:"expire_days"`        // 密码有效天数，过期后不能登录，0 表示永不过期
+}
+
+// PasswordPolicyError 密码不满足策略（ER_NOT_VALID_PASSWORD）
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "Your password does not satisfy the current policy requirements: " + e.Reason
+}
+
+// MySQLErrorCode 实现 mysqlerrors.Coder
+func (e *PasswordPolicyError) MySQLErrorCode() uint16 {
+	return mysqlerrors.ErrNotValidPassword
+}
+
+// Validate 检查策略参数
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < 0 || p.MinUppercase < 0 || p.MinLowercase < 0 || p.MinDigits < 0 || p.MinSpecial < 0 {
+		return fmt.Errorf("password policy counts must not be negative")
+	}
+	if p.ExpireDays < 0 {
+		return fmt.Errorf("password expire_days must not be negative")
+	}
+	return nil
+}
+
+// Check 检查用户的新密码是否满足策略，不满足时返回 *PasswordPolicyError
+func (p PasswordPolicy) Check(user, password string) error {
+	var upper, lower, digits, special int
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digits++
+		default:
+			special++
+		}
+	}
+	switch {
+	case len([]rune(password)) < p.MinLength:
+		return &PasswordPolicyError{Reason: fmt.Sprintf("at least %d characters", p.MinLength)}
+	case upper < p.MinUppercase:
+		return &PasswordPolicyError{Reason: fmt.Sprintf("at least %d uppercase letters", p.MinUppercase)}
+	case lower < p.MinLowercase:
+		return &PasswordPolicyError{Reason: fmt.Sprintf("at least %d lowercase letters", p.MinLowercase)}
+	case digits < p.MinDigits:
+		return &PasswordPolicyError{Reason: fmt.Sprintf("at least %d digits", p.MinDigits)}
+	case special < p.MinSpecial:
+		return &PasswordPolicyError{Reason: fmt.Sprintf("at least %d special characters", p.MinSpecial)}
+	case p.DisallowUserName && user != "" && strings.Contains(strings.ToLower(password), strings.ToLower(user)):
+		return &PasswordPolicyError{Reason: "must not contain the user name"}
+	}
+	return nil
+}
+
+// Expired 判断在 changedAt 设置的密码到 now 时是否已过期，修改时间未知（零值）时视为未过期
+func (p PasswordPolicy) Expired(changedAt, now time.Time) bool {
+	if p.ExpireDays <= 0 || changedAt.IsZero() {
+		return false
+	}
+	return now.Sub(changedAt) >= time.Duration(p.ExpireDays)*24*time.Hour
+}
+
+// PasswordExpiredError 密码已过期，需要管理员重置后才能登录（ER_MUST_CHANGE_PASSWORD_LOGIN）
+type PasswordExpiredError struct {
+	User string
+}
+
+func (e *PasswordExpiredError) Error() string {
+	return fmt.Sprintf("Your password has expired. To log in as '%s' the password must be changed by an administrator", e.User)
+}
+
+// MySQLErrorCode 实现 mysqlerrors.Coder
+func (e *PasswordExpiredError) MySQLErrorCode() uint16 {
+	return mysqlerrors.ErrPasswordExpired
+}
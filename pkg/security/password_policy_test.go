@@ -0,0 +1,47 @@
+package security
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MinUppercase: 1, MinLowercase: 1, MinDigits: 2, MinSpecial: 1, DisallowUserName: true}
+	require.NoError(t, policy.Validate())
+
+	assert.NoError(t, policy.Check("app", "Correct-h0rse7"))
+	for _, password := range []string{
+		"Sh0rt-1",          // 太短
+		"no-upper-case12",  // 没有大写字母
+		"NO-LOWER-CASE12",  // 没有小写字母
+		"One-digit-only1",  // 数字不够
+		"NoSpecialChar12",  // 没有特殊字符
+		"My-APP-passw0rd1", // 包含用户名
+	} {
+		err := policy.Check("app", password)
+		var policyErr *PasswordPolicyError
+		assert.True(t, errors.As(err, &policyErr), "password %q: %v", password, err)
+		assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrNotValidPassword))
+	}
+
+	// 零值策略不做限制
+	assert.NoError(t, PasswordPolicy{}.Check("app", ""))
+	assert.Error(t, PasswordPolicy{MinLength: -1}.Validate())
+	assert.Error(t, PasswordPolicy{ExpireDays: -1}.Validate())
+}
+
+func TestPasswordPolicy_Expired(t *testing.T) {
+	policy := PasswordPolicy{ExpireDays: 30}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, policy.Expired(now.Add(-29*24*time.Hour), now))
+	assert.True(t, policy.Expired(now.Add(-30*24*time.Hour), now))
+	// 修改时间未知的密码不过期
+	assert.False(t, policy.Expired(time.Time{}, now))
+	assert.False(t, PasswordPolicy{}.Expired(now.Add(-1000*24*time.Hour), now))
+}
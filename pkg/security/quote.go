@@ -0,0 +1,150 @@
+package security
+
+import (
+	"strings"
+)
+
+// QuoteIdentifier 用反引号包裹标识符（表名、列名等），内部的反引号会被双写转义
+//
+// 示例：
+//
+//	QuoteIdentifier("order")   // `order`
+//	QuoteIdentifier("a`b")     // `a``b`
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteQualifiedIdentifier 引用限定标识符，各部分分别转义后用 . 连接
+// 空的部分会被忽略，例如 QuoteQualifiedIdentifier("", "users") 返回 `users`
+func QuoteQualifiedIdentifier(parts ...string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		quoted = append(quoted, QuoteIdentifier(part))
+	}
+	return strings.Join(quoted, ".")
+}
+
+// QuoteString 将字符串转义为单引号字面量
+// 使用默认的 utf8mb4 连接字符集，且未开启 NO_BACKSLASH_ESCAPES
+func QuoteString(s string) string {
+	return Quoter{}.String(s)
+}
+
+// Quoter 按连接的字符集和 SQL 模式生成安全的 SQL 字面量
+// 零值对应 utf8mb4 连接且未开启 NO_BACKSLASH_ESCAPES
+type Quoter struct {
+	// Charset 连接字符集（character_set_connection），为空时视为 utf8mb4
+	Charset string
+	// NoBackslashEscapes 对应 sql_mode 中的 NO_BACKSLASH_ESCAPES，开启后反斜杠不再是转义符
+	NoBackslashEscapes bool
+}
+
+// NewQuoter 根据连接字符集和 sql_mode 创建 Quoter
+func NewQuoter(charset, sqlMode string) Quoter {
+	return Quoter{
+		Charset:            charset,
+		NoBackslashEscapes: hasSQLMode(sqlMode, "NO_BACKSLASH_ESCAPES"),
+	}
+}
+
+// Identifier 引用标识符，与 QuoteIdentifier 相同（标识符转义与字符集无关）
+func (q Quoter) Identifier(name string) string {
+	return QuoteIdentifier(name)
+}
+
+// String 将字符串转义为单引号字面量
+//
+// s 必须是已经按连接字符集编码的字节。对于 gbk、big5、sjis 等多字节字符集，
+// 反斜杠 (0x5c) 可能是双字节字符的尾字节，因此会按字符遍历，只转义单字节字符，
+// 避免 0xbf5c 之类的字节序列吞掉转义用的反斜杠而导致引号逃逸。
+func (q Quoter) String(s string) string {
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '\'')
+	if q.NoBackslashEscapes {
+		buf = escapeStringNoBackslash(buf, s, q.Charset)
+	} else {
+		buf = escapeStringCharset(buf, s, q.Charset)
+	}
+	buf = append(buf, '\'')
+	return string(buf)
+}
+
+// escapeStringCharset 按字符集逐字符进行反斜杠转义
+func escapeStringCharset(buf []byte, s string, charset string) []byte {
+	for i := 0; i < len(s); i++ {
+		if n := multiByteLen(charset, s, i); n > 1 {
+			buf = append(buf, s[i:i+n]...)
+			i += n - 1
+			continue
+		}
+		buf = escapeString(buf, s[i:i+1])
+	}
+	return buf
+}
+
+// escapeStringNoBackslash 在 NO_BACKSLASH_ESCAPES 模式下只双写单引号
+func escapeStringNoBackslash(buf []byte, s string, charset string) []byte {
+	for i := 0; i < len(s); i++ {
+		if n := multiByteLen(charset, s, i); n > 1 {
+			buf = append(buf, s[i:i+n]...)
+			i += n - 1
+			continue
+		}
+		if s[i] == '\'' {
+			buf = append(buf, '\'', '\'')
+		} else {
+			buf = append(buf, s[i])
+		}
+	}
+	return buf
+}
+
+// multiByteLen 返回 s[i] 开始的多字节字符长度
+// 仅对尾字节可能落在 ASCII 范围内的字符集（gbk、gb18030、big5、sjis、cp932）
+// 返回大于 1 的值；其他字符集（包括 utf8/utf8mb4）的多字节序列不会包含 ASCII 字节，按单字节处理即可
+func multiByteLen(charset string, s string, i int) int {
+	if i+1 >= len(s) {
+		return 1
+	}
+	lead, trail := s[i], s[i+1]
+
+	switch strings.ToLower(charset) {
+	case "gbk", "big5":
+		if lead >= 0x81 && lead <= 0xfe && trail >= 0x40 && trail <= 0xfe {
+			return 2
+		}
+	case "gb18030":
+		if lead < 0x81 || lead > 0xfe {
+			return 1
+		}
+		// 四字节序列：第二、四字节为数字 0x30-0x39
+		if trail >= 0x30 && trail <= 0x39 {
+			if i+3 < len(s) && s[i+2] >= 0x81 && s[i+2] <= 0xfe && s[i+3] >= 0x30 && s[i+3] <= 0x39 {
+				return 4
+			}
+			return 1
+		}
+		if trail >= 0x40 && trail <= 0xfe && trail != 0x7f {
+			return 2
+		}
+	case "sjis", "cp932":
+		if ((lead >= 0x81 && lead <= 0x9f) || (lead >= 0xe0 && lead <= 0xfc)) &&
+			trail >= 0x40 && trail <= 0xfc && trail != 0x7f {
+			return 2
+		}
+	}
+	return 1
+}
+
+// hasSQLMode 判断逗号分隔的 sql_mode 中是否包含指定模式
+func hasSQLMode(sqlMode, mode string) bool {
+	for _, m := range strings.Split(sqlMode, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), mode) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package security
+
+import (
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "users", "`users`"},
+		{"keyword", "order", "`order`"},
+		{"space", "my table", "`my table`"},
+		{"backtick", "a`b", "`a``b`"},
+		{"injection", "t` ; DROP TABLE x; --", "`t`` ; DROP TABLE x; --`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuoteIdentifier(tt.in); got != tt.want {
+				t.Errorf("QuoteIdentifier(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteQualifiedIdentifier(t *testing.T) {
+	if got := QuoteQualifiedIdentifier("db", "t", "c"); got != "`db`.`t`.`c`" {
+		t.Errorf("QuoteQualifiedIdentifier() = %q", got)
+	}
+	if got := QuoteQualifiedIdentifier("", "t"); got != "`t`" {
+		t.Errorf("QuoteQualifiedIdentifier() with empty schema = %q", got)
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "abc", "'abc'"},
+		{"single quote", "O'Reilly", "'O\\'Reilly'"},
+		{"backslash", "C:\\tmp", "'C:\\\\tmp'"},
+		{"injection", "x' OR '1'='1", "'x\\' OR \\'1\\'=\\'1'"},
+		{"utf8", "中文'", "'中文\\''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuoteString(tt.in); got != tt.want {
+				t.Errorf("QuoteString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoter_MultiByteCharset(t *testing.T) {
+	// 0xbf5c 是合法的 GBK 字符，其尾字节恰好是反斜杠
+	input := string([]byte{0xbf, 0x5c, '\'', ' ', 'O', 'R', ' ', '1'})
+
+	// 按字节转义会在 0xbf5c 后再插入一个反斜杠，GBK 服务器会把 0xbf5c 当作一个字符，
+	// 多出的反斜杠转义了后面的反斜杠，导致引号逃逸
+	naive := QuoteString(input)
+	if naive != "'"+string([]byte{0xbf, 0x5c, 0x5c, 0x5c, '\''})+" OR 1'" {
+		t.Fatalf("unexpected byte-wise escaping: %q", naive)
+	}
+
+	got := Quoter{Charset: "gbk"}.String(input)
+	want := "'" + string([]byte{0xbf, 0x5c, '\\', '\''}) + " OR 1'"
+	if got != want {
+		t.Errorf("Quoter{gbk}.String() = %q, want %q", got, want)
+	}
+
+	// 单独的反斜杠仍然需要转义
+	if got := (Quoter{Charset: "sjis"}).String("a\\b"); got != "'a\\\\b'" {
+		t.Errorf("Quoter{sjis}.String() = %q", got)
+	}
+}
+
+func TestQuoter_NoBackslashEscapes(t *testing.T) {
+	q := NewQuoter("utf8mb4", "STRICT_TRANS_TABLES,NO_BACKSLASH_ESCAPES")
+	if !q.NoBackslashEscapes {
+		t.Fatal("expected NO_BACKSLASH_ESCAPES to be detected")
+	}
+	if got := q.String("a\\b'c"); got != "'a\\b''c'" {
+		t.Errorf("String() = %q, want %q", got, "'a\\b''c'")
+	}
+
+	if NewQuoter("utf8mb4", "STRICT_TRANS_TABLES").NoBackslashEscapes {
+		t.Error("NO_BACKSLASH_ESCAPES should not be detected")
+	}
+}
@@ -0,0 +1,482 @@
+// Package sequence 实现 MariaDB 兼容的序列对象
+//
+// 序列与 MariaDB 一样保存为数据源中只有一行的表（TableInfo.Atts 带 domain.SequenceMetaKey 标记），
+// 列依次为 next_not_cached_value、minimum_value、maximum_value、start_value、increment、
+// cache_size、cycle_option 和 cycle_count，序列状态随数据源一起持久化。
+//
+// 取值时一次预留 cache_size 个值，只把预留区间的上界写回表中；
+// 进程退出时缓存中未用完的值会被跳过，这与 MariaDB 的行为一致。
+package sequence
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// 序列的默认值与取值范围
+const (
+	DefaultCache = 1000              // 默认每次预留的值个数
+	MaxValue     = math.MaxInt64 - 1 // 最大值的上限，math.MaxInt64 留作“已用完”的标记
+	MinValue     = math.MinInt64 + 1 // 最小值的下限
+)
+
+// 序列表的列名，与 MariaDB 一致
+const (
+	colNextNotCached = "next_not_cached_value"
+	colMinValue      = "minimum_value"
+	colMaxValue      = "maximum_value"
+	colStart         = "start_value"
+	colIncrement     = "increment"
+	colCache         = "cache_size"
+	colCycle         = "cycle_option"
+	colCycleCount    = "cycle_count"
+)
+
+// Options CREATE/ALTER SEQUENCE 的选项，nil 表示未指定
+type Options struct {
+	Start       *int64
+	Increment   *int64
+	MinValue    *int64
+	MaxValue    *int64
+	Cache       *int64 // NOCACHE 为 0
+	Cycle       *bool
+	NoMinValue  bool   // NO MINVALUE：恢复默认下限
+	NoMaxValue  bool   // NO MAXVALUE：恢复默认上限
+	Restart     bool   // ALTER SEQUENCE ... RESTART [WITH n]
+	RestartWith *int64 // RESTART WITH n，未指定时从 START 重新开始
+}
+
+// Definition 序列的定义与持久化状态，对应序列表中的一行
+type Definition struct {
+	NextNotCached int64 // 下一个未被预留的值
+	MinValue      int64
+	MaxValue      int64
+	Start         int64
+	Increment     int64
+	Cache         int64
+	Cycle         bool
+	CycleCount    int64 // 已循环的次数
+}
+
+// IsSequence 判断表是否为序列
+func IsSequence(info *domain.TableInfo) bool {
+	if info == nil {
+		return false
+	}
+	marked, _ := info.Atts[domain.SequenceMetaKey].(bool)
+	return marked
+}
+
+// DefaultExpr 返回列默认值 DEFAULT NEXTVAL(name) 保存在 ColumnInfo.Default 中的形式
+func DefaultExpr(name string) string {
+	return "nextval(`" + strings.ReplaceAll(name, "`", "``") + "`)"
+}
+
+// DefaultName 从列默认值中取出序列名，默认值不是 DefaultExpr 的形式时返回 false
+func DefaultName(def string) (string, bool) {
+	if !strings.HasPrefix(def, "nextval(`") || !strings.HasSuffix(def, "`)") || len(def) < len("nextval(``)")+1 {
+		return "", false
+	}
+	return strings.ReplaceAll(def[len("nextval(`"):len(def)-2], "``", "`"), true
+}
+
+// Create 在数据源中创建序列
+func Create(ctx context.Context, ds domain.DataSource, name string, opts Options) error {
+	def := Definition{Increment: 1, Cache: DefaultCache}
+	if opts.Increment != nil {
+		def.Increment = *opts.Increment
+	}
+	def.MinValue, def.MaxValue = defaultRange(def.Increment)
+	if opts.MinValue != nil {
+		def.MinValue = *opts.MinValue
+	}
+	if opts.MaxValue != nil {
+		def.MaxValue = *opts.MaxValue
+	}
+	def.Start = def.MinValue
+	if def.Increment < 0 {
+		def.Start = def.MaxValue
+	}
+	if opts.Start != nil {
+		def.Start = *opts.Start
+	}
+	if opts.Cache != nil {
+		def.Cache = *opts.Cache
+	}
+	if opts.Cycle != nil {
+		def.Cycle = *opts.Cycle
+	}
+	def.NextNotCached = def.Start
+	if err := def.validate(name); err != nil {
+		return err
+	}
+
+	info := &domain.TableInfo{
+		Name:    name,
+		Columns: columns(),
+		Atts:    map[string]interface{}{domain.SequenceMetaKey: true},
+	}
+	if err := ds.CreateTable(ctx, info); err != nil {
+		return err
+	}
+	if _, err := ds.Insert(ctx, name, []domain.Row{def.row()}, nil); err != nil {
+		_ = ds.DropTable(ctx, name)
+		return err
+	}
+	invalidate(ds, name)
+	return nil
+}
+
+// Alter 修改序列的选项。未指定 RESTART 时序列从当前位置继续，缓存中未用完的值被丢弃
+func Alter(ctx context.Context, ds domain.DataSource, name string, opts Options) error {
+	st := stateFor(ds, name)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	reserveMu.Lock()
+	defer reserveMu.Unlock()
+
+	def, err := Load(ctx, ds, name)
+	if err != nil {
+		return err
+	}
+	if opts.Increment != nil {
+		def.Increment = *opts.Increment
+	}
+	defMin, defMax := defaultRange(def.Increment)
+	switch {
+	case opts.MinValue != nil:
+		def.MinValue = *opts.MinValue
+	case opts.NoMinValue:
+		def.MinValue = defMin
+	}
+	switch {
+	case opts.MaxValue != nil:
+		def.MaxValue = *opts.MaxValue
+	case opts.NoMaxValue:
+		def.MaxValue = defMax
+	}
+	if opts.Start != nil {
+		def.Start = *opts.Start
+	}
+	if opts.Cache != nil {
+		def.Cache = *opts.Cache
+	}
+	if opts.Cycle != nil {
+		def.Cycle = *opts.Cycle
+	}
+	if opts.Restart {
+		def.NextNotCached = def.Start
+		if opts.RestartWith != nil {
+			def.NextNotCached = *opts.RestartWith
+		}
+	} else if st.left > 0 {
+		// 缓存中还没有取走的值仍可使用
+		def.NextNotCached = st.next
+	}
+	if err := def.validate(name); err != nil {
+		return err
+	}
+	if opts.Restart && (def.NextNotCached < def.MinValue || def.NextNotCached > def.MaxValue) {
+		return invalidData(name)
+	}
+	if _, err := ds.Update(ctx, name, nil, def.row(), nil); err != nil {
+		return err
+	}
+	st.left = 0
+	return nil
+}
+
+// Drop 删除序列，name 不是序列时报错
+func Drop(ctx context.Context, ds domain.DataSource, name string) error {
+	info, err := ds.GetTableInfo(ctx, name)
+	if err != nil {
+		return mysqlerrors.New(mysqlerrors.ErrUnknownSequences, "Unknown SEQUENCE: '%s'", name)
+	}
+	if !IsSequence(info) {
+		return notSequence(name)
+	}
+	if err := ds.DropTable(ctx, name); err != nil {
+		return err
+	}
+	invalidate(ds, name)
+	return nil
+}
+
+// Next 返回序列的下一个值（NEXTVAL / NEXT VALUE FOR）
+func Next(ctx context.Context, ds domain.DataSource, name string) (int64, error) {
+	st := stateFor(ds, name)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.left == 0 {
+		if err := st.reserve(ctx, ds, name); err != nil {
+			return 0, err
+		}
+	}
+	value := st.next
+	st.left--
+	if st.left > 0 {
+		st.next += st.increment
+	}
+	return value, nil
+}
+
+// SetValue 设置序列的下一个值（SETVAL）。used 为 true 时 value 视为已经取过，下一个值为 value + increment。
+// 与 MariaDB 一样序列只能向前移动：value 落后于当前位置或超出取值范围时不做修改并返回 false
+func SetValue(ctx context.Context, ds domain.DataSource, name string, value int64, used bool) (bool, error) {
+	st := stateFor(ds, name)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	reserveMu.Lock()
+	defer reserveMu.Unlock()
+
+	def, err := Load(ctx, ds, name)
+	if err != nil {
+		return false, err
+	}
+	if value < def.MinValue || value > def.MaxValue {
+		return false, nil
+	}
+	current := def.NextNotCached
+	if st.left > 0 {
+		current = st.next
+	}
+	next := value
+	if used {
+		next = def.advance(value, 1)
+	}
+	if (def.Increment > 0 && next < current) || (def.Increment < 0 && next > current) {
+		return false, nil
+	}
+	if _, err := ds.Update(ctx, name, nil, domain.Row{colNextNotCached: next}, nil); err != nil {
+		return false, err
+	}
+	st.left = 0
+	return true, nil
+}
+
+// Load 读取序列表中保存的定义与状态
+func Load(ctx context.Context, ds domain.DataSource, name string) (*Definition, error) {
+	info, err := ds.GetTableInfo(ctx, name)
+	if err != nil {
+		return nil, mysqlerrors.New(mysqlerrors.ErrNoSuchTable, "Table '%s' doesn't exist", name)
+	}
+	if !IsSequence(info) {
+		return nil, notSequence(name)
+	}
+	result, err := ds.Query(ctx, name, &domain.QueryOptions{SelectAll: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) != 1 {
+		return nil, invalidData(name)
+	}
+	row := result.Rows[0]
+	def := &Definition{}
+	fields := []struct {
+		col string
+		dst *int64
+	}{
+		{colNextNotCached, &def.NextNotCached},
+		{colMinValue, &def.MinValue},
+		{colMaxValue, &def.MaxValue},
+		{colStart, &def.Start},
+		{colIncrement, &def.Increment},
+		{colCache, &def.Cache},
+		{colCycleCount, &def.CycleCount},
+	}
+	for _, f := range fields {
+		v, err := utils.ToInt64(row[f.col])
+		if err != nil {
+			return nil, invalidData(name)
+		}
+		*f.dst = v
+	}
+	// 数据源可能把 tinyint 列保存为布尔值
+	switch cycle := row[colCycle].(type) {
+	case bool:
+		def.Cycle = cycle
+	default:
+		n, _ := utils.ToInt64(cycle)
+		def.Cycle = n != 0
+	}
+	if def.Increment == 0 {
+		return nil, invalidData(name)
+	}
+	return def, nil
+}
+
+// validate 检查选项是否相互冲突
+func (d *Definition) validate(name string) error {
+	if d.Increment == 0 || d.Cache < 0 ||
+		d.MinValue < MinValue || d.MaxValue > MaxValue || d.MinValue >= d.MaxValue ||
+		d.Start < d.MinValue || d.Start > d.MaxValue {
+		return invalidData(name)
+	}
+	return nil
+}
+
+// exhausted 判断 v 是否已经超出取值范围
+func (d *Definition) exhausted(v int64) bool {
+	if d.Increment > 0 {
+		return v > d.MaxValue
+	}
+	return v < d.MinValue
+}
+
+// advance 返回 v 之后第 n 个值，溢出时返回超出范围的标记值
+func (d *Definition) advance(v, n int64) int64 {
+	if d.Increment > 0 {
+		if n > (math.MaxInt64-v)/d.Increment {
+			return math.MaxInt64
+		}
+	} else if n > (v-math.MinInt64)/-d.Increment {
+		return math.MinInt64
+	}
+	return v + n*d.Increment
+}
+
+// remaining 返回从 v 开始（含 v）到取值范围边界还能取的值个数
+func (d *Definition) remaining(v int64) uint64 {
+	if d.Increment > 0 {
+		return (uint64(d.MaxValue)-uint64(v))/uint64(d.Increment) + 1
+	}
+	return (uint64(v)-uint64(d.MinValue))/uint64(-d.Increment) + 1
+}
+
+// row 返回序列表中的状态行
+func (d *Definition) row() domain.Row {
+	cycle := int64(0)
+	if d.Cycle {
+		cycle = 1
+	}
+	return domain.Row{
+		colNextNotCached: d.NextNotCached,
+		colMinValue:      d.MinValue,
+		colMaxValue:      d.MaxValue,
+		colStart:         d.Start,
+		colIncrement:     d.Increment,
+		colCache:         d.Cache,
+		colCycle:         cycle,
+		colCycleCount:    d.CycleCount,
+	}
+}
+
+// defaultRange 返回未指定 MINVALUE/MAXVALUE 时的取值范围，递减序列的范围在负数一侧
+func defaultRange(increment int64) (min, max int64) {
+	if increment < 0 {
+		return MinValue, -1
+	}
+	return 1, MaxValue
+}
+
+// columns 返回序列表的列定义
+func columns() []domain.ColumnInfo {
+	names := []string{colNextNotCached, colMinValue, colMaxValue, colStart, colIncrement, colCache, colCycle, colCycleCount}
+	cols := make([]domain.ColumnInfo, len(names))
+	for i, name := range names {
+		cols[i] = domain.ColumnInfo{Name: name, Type: "bigint"}
+	}
+	cols[6].Type = "tinyint"
+	return cols
+}
+
+func invalidData(name string) error {
+	return mysqlerrors.New(mysqlerrors.ErrSequenceInvalidData, "Sequence '%s' has out of range value for options", name)
+}
+
+func notSequence(name string) error {
+	return mysqlerrors.New(mysqlerrors.ErrNotSequence, "'%s' is not a SEQUENCE", name)
+}
+
+// key 标识一个数据源中的序列
+type key struct {
+	ds   domain.DataSource
+	name string
+}
+
+// state 序列在进程内缓存的预留区间
+type state struct {
+	mu        sync.Mutex
+	next      int64 // 缓存中下一个返回的值
+	left      int64 // 缓存中剩余的值个数，为 0 时需要重新预留
+	increment int64
+}
+
+var (
+	statesMu sync.Mutex
+	states   = make(map[key]*state)
+
+	// reserveMu 串行化对序列表的读-改-写：同一张序列表可能经由不同的数据源包装访问
+	reserveMu sync.Mutex
+)
+
+func stateFor(ds domain.DataSource, name string) *state {
+	statesMu.Lock()
+	defer statesMu.Unlock()
+	k := key{ds: ds, name: name}
+	st, ok := states[k]
+	if !ok {
+		st = &state{}
+		states[k] = st
+	}
+	return st
+}
+
+// invalidate 丢弃序列在进程内缓存的值，序列被重新创建或删除后调用
+func invalidate(ds domain.DataSource, name string) {
+	statesMu.Lock()
+	st, ok := states[key{ds: ds, name: name}]
+	delete(states, key{ds: ds, name: name})
+	statesMu.Unlock()
+	if ok {
+		st.mu.Lock()
+		st.left = 0
+		st.mu.Unlock()
+	}
+}
+
+// reserve 从序列表预留下一段值并把新的 next_not_cached_value 写回，调用方持有 st.mu
+func (st *state) reserve(ctx context.Context, ds domain.DataSource, name string) error {
+	reserveMu.Lock()
+	defer reserveMu.Unlock()
+
+	def, err := Load(ctx, ds, name)
+	if err != nil {
+		return err
+	}
+	first := def.NextNotCached
+	if def.exhausted(first) {
+		if !def.Cycle {
+			return mysqlerrors.New(mysqlerrors.ErrSequenceRunOut, "Sequence '%s' has run out", name)
+		}
+		first = def.MinValue
+		if def.Increment < 0 {
+			first = def.MaxValue
+		}
+		def.CycleCount++
+	}
+	count := uint64(1)
+	if def.Cache > 1 {
+		count = uint64(def.Cache)
+	}
+	if remaining := def.remaining(first); remaining < count {
+		count = remaining
+	}
+	updates := domain.Row{
+		colNextNotCached: def.advance(first, int64(count)),
+		colCycleCount:    def.CycleCount,
+	}
+	if _, err := ds.Update(ctx, name, nil, updates, nil); err != nil {
+		return err
+	}
+	st.next = first
+	st.left = int64(count)
+	st.increment = def.Increment
+	return nil
+}
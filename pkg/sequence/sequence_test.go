@@ -0,0 +1,100 @@
+package sequence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDataSource(t *testing.T) domain.DataSource {
+	t.Helper()
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "test", Writable: true})
+	require.NoError(t, ds.Connect(context.Background()))
+	return ds
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// TestNext_ReservesCache 测试取值时只把预留区间的上界写回表，进程内缓存丢失后从上界继续
+func TestNext_ReservesCache(t *testing.T) {
+	ctx := context.Background()
+	ds := newTestDataSource(t)
+	require.NoError(t, Create(ctx, ds, "s", Options{Cache: int64Ptr(10)}))
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := Next(ctx, ds, "s")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	def, err := Load(ctx, ds, "s")
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, def.NextNotCached)
+
+	// 模拟重启：缓存中未用完的值被跳过
+	invalidate(ds, "s")
+	got, err := Next(ctx, ds, "s")
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, got)
+}
+
+// TestNext_Descending 测试递减序列的默认范围与循环
+func TestNext_Descending(t *testing.T) {
+	ctx := context.Background()
+	ds := newTestDataSource(t)
+	cycle := true
+	require.NoError(t, Create(ctx, ds, "d", Options{Increment: int64Ptr(-1), MinValue: int64Ptr(-2), Cycle: &cycle}))
+
+	var got []int64
+	for i := 0; i < 3; i++ {
+		v, err := Next(ctx, ds, "d")
+		require.NoError(t, err)
+		got = append(got, v)
+	}
+	assert.Equal(t, []int64{-1, -2, -1}, got)
+}
+
+// TestNext_RunsOutAtMaxValue 测试接近 int64 上限时不会溢出
+func TestNext_RunsOutAtMaxValue(t *testing.T) {
+	ctx := context.Background()
+	ds := newTestDataSource(t)
+	require.NoError(t, Create(ctx, ds, "big", Options{Start: int64Ptr(MaxValue - 2), Increment: int64Ptr(2)}))
+
+	v, err := Next(ctx, ds, "big")
+	require.NoError(t, err)
+	assert.EqualValues(t, MaxValue-2, v)
+	v, err = Next(ctx, ds, "big")
+	require.NoError(t, err)
+	assert.EqualValues(t, MaxValue, v)
+	_, err = Next(ctx, ds, "big")
+	assert.Error(t, err)
+}
+
+// TestCreate_InvalidOptions 测试相互冲突的选项
+func TestCreate_InvalidOptions(t *testing.T) {
+	ctx := context.Background()
+	ds := newTestDataSource(t)
+	for _, opts := range []Options{
+		{Increment: int64Ptr(0)},
+		{MinValue: int64Ptr(5), MaxValue: int64Ptr(5)},
+		{Start: int64Ptr(100), MaxValue: int64Ptr(10)},
+		{Cache: int64Ptr(-1)},
+	} {
+		assert.Error(t, Create(ctx, ds, "bad", opts))
+	}
+	_, err := ds.GetTableInfo(ctx, "bad")
+	assert.Error(t, err)
+}
+
+func TestDefaultName(t *testing.T) {
+	name, ok := DefaultName(DefaultExpr("a`b"))
+	assert.True(t, ok)
+	assert.Equal(t, "a`b", name)
+	_, ok = DefaultName("nextval")
+	assert.False(t, ok)
+	_, ok = DefaultName("0")
+	assert.False(t, ok)
+}
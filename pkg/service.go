@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"strconv"
 	"strings"
@@ -13,6 +12,7 @@ import (
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/monitor"
 	"github.com/kasuganosora/sqlexec/pkg/optimizer"
 	"github.com/kasuganosora/sqlexec/pkg/parser"
@@ -23,6 +23,9 @@ import (
 	"github.com/kasuganosora/sqlexec/server/protocol"
 )
 
+// serviceLog 服务模块的结构化日志
+var serviceLog = logging.Module("service")
+
 // 定义 context key
 type contextKey string
 
@@ -208,12 +211,12 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 	// 获取客户端地址
 	remoteAddr := conn.RemoteAddr().String()
 	addr, port := parseRemoteAddr(remoteAddr)
-	log.Printf("新连接来自: %s:%s", addr, port)
+	serviceLog.Infof("新连接来自: %s:%s", addr, port)
 
 	// 获取或创建会话
 	sess, err := s.sessionMgr.GetOrCreateSession(ctx, addr, port)
 	if err != nil {
-		log.Printf("创建会话失败: %v", err)
+		serviceLog.Errorf("创建会话失败: %v", err)
 		return fmt.Errorf("创建会话失败: %w", err)
 	}
 	sess.ResetSequenceID()
@@ -224,7 +227,7 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 		// 发送握手包
 		handshake := protocol.NewHandshakePacket()
 		handshake.ThreadID = sess.ThreadID
-		log.Printf("准备发送握手包: ProtocolVersion=%d, ServerVersion=%s, ConnectionID=%d, AuthPluginName=%s",
+		serviceLog.Infof("准备发送握手包: ProtocolVersion=%d, ServerVersion=%s, ConnectionID=%d, AuthPluginName=%s",
 			handshake.ProtocolVersion,
 			handshake.ServerVersion,
 			handshake.ThreadID,
@@ -232,48 +235,48 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 
 		data, err := handshake.Marshal()
 		if err != nil {
-			log.Printf("序列化握手包失败: %v", err)
+			serviceLog.Errorf("序列化握手包失败: %v", err)
 			return fmt.Errorf("序列化握手包失败: %w", err)
 		}
 
 		if _, err := conn.Write(data); err != nil {
-			log.Printf("发送握手包失败: %v", err)
+			serviceLog.Errorf("发送握手包失败: %v", err)
 			return fmt.Errorf("发送握手包失败: %w", err)
 		}
-		log.Printf("已发送握手包")
+		serviceLog.Infof("已发送握手包")
 
 		// 读取客户端的认证包
-		log.Printf("等待客户端认证包...")
+		serviceLog.Infof("等待客户端认证包...")
 		authPacket, err := protocol.ReadPacket(conn)
 		if err != nil {
-			log.Printf("读取认证包失败: %v", err)
+			serviceLog.Errorf("读取认证包失败: %v", err)
 			return fmt.Errorf("读取认证包失败: %w", err)
 		}
 
 		// 打印认证包信息
-		log.Printf("收到认证包: 长度=%d, SequenceID=%d", authPacket.PayloadLength, authPacket.SequenceID)
+		serviceLog.Infof("收到认证包: 长度=%d, SequenceID=%d", authPacket.PayloadLength, authPacket.SequenceID)
 		if len(authPacket.Payload) > 0 {
-			log.Printf("认证包命令类型: %d", authPacket.Payload[0])
+			serviceLog.Infof("认证包命令类型: %d", authPacket.Payload[0])
 		}
 
 		// 解析认证响应
 		handshakeResponse := &protocol.HandshakeResponse{}
 		if err := handshakeResponse.Unmarshal(conn, uint32(handshake.CapabilityFlags1)|uint32(handshake.CapabilityFlags2)<<16); err != nil {
-			log.Printf("解析认证响应失败: %v", err)
+			serviceLog.Errorf("解析认证响应失败: %v", err)
 			return fmt.Errorf("解析认证响应失败: %w", err)
 		}
-		log.Printf("用户: %s, 数据库: %s, 字符集: %d", handshakeResponse.User, handshakeResponse.Database, handshakeResponse.CharacterSet)
+		serviceLog.Infof("用户: %s, 数据库: %s, 字符集: %d", handshakeResponse.User, handshakeResponse.Database, handshakeResponse.CharacterSet)
 
 		// 设置用户名
 		sess.SetUser(handshakeResponse.User)
 
 		// 发送 OK 包表示认证成功
-		log.Printf("发送认证成功包...")
+		serviceLog.Infof("发送认证成功包...")
 		if err := protocol.SendOK(conn, sess.GetNextSequenceID()); err != nil {
-			log.Printf("发送认证成功包失败: %v", err)
+			serviceLog.Errorf("发送认证成功包失败: %v", err)
 			return fmt.Errorf("发送认证成功包失败: %w", err)
 		}
-		log.Printf("已发送认证成功包")
+		serviceLog.Infof("已发送认证成功包")
 
 		// 标记握手完成
 		ctx = withHandshakeDone(ctx)
@@ -281,20 +284,20 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 
 	// 处理后续的命令包
 	for {
-		log.Printf("等待命令包...")
+		serviceLog.Infof("等待命令包...")
 		packet, err := protocol.ReadPacket(conn)
 		if err != nil {
 			if err == io.EOF {
-				log.Printf("客户端断开连接")
+				serviceLog.Infof("客户端断开连接")
 				return nil
 			}
-			log.Printf("读取命令包失败: %v", err)
+			serviceLog.Errorf("读取命令包失败: %v", err)
 			protocol.SendError(conn, err)
 			return fmt.Errorf("读取命令包失败: %w", err)
 		}
 
 		// 打印封包信息用于调试
-		log.Printf("收到命令包: 长度=%d, SequenceID=%d", packet.PayloadLength, packet.SequenceID)
+		serviceLog.Infof("收到命令包: 长度=%d, SequenceID=%d", packet.PayloadLength, packet.SequenceID)
 
 		// 更新会话序列号
 		sess.SequenceID = packet.SequenceID
@@ -302,7 +305,7 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 		// 解析封包的类型
 		packetType := packet.GetCommandType()
 		commandName := protocol.GetCommandName(packetType)
-		log.Printf("收到命令: %s (0x%02x)", commandName, packetType)
+		serviceLog.Infof("收到命令: %s (0x%02x)", commandName, packetType)
 
 		// 重置会话序列号(新命令开始)
 		sess.ResetSequenceID()
@@ -310,7 +313,7 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 		var handleErr error
 		switch packetType {
 		case protocol.COM_QUIT:
-			log.Printf("收到退出命令")
+			serviceLog.Infof("收到退出命令")
 			return nil
 		case protocol.COM_QUERY:
 			handleErr = s.handleQuery(ctx, conn, packet)
@@ -341,12 +344,12 @@ func (s *Server) HandleConn(ctx context.Context, conn net.Conn) (err error) {
 		case protocol.COM_SHUTDOWN:
 			handleErr = s.handleShutdown(ctx, conn)
 		default:
-			log.Printf("不支持的命令类型: %s (0x%02x)", commandName, packetType)
+			serviceLog.Warnf("不支持的命令类型: %s (0x%02x)", commandName, packetType)
 			protocol.SendError(conn, fmt.Errorf("不支持的命令类型: %d", packetType))
 		}
 
 		if handleErr != nil {
-			log.Printf("处理命令 %s 失败: %v", commandName, handleErr)
+			serviceLog.Errorf("处理命令 %s 失败: %v", commandName, handleErr)
 			return handleErr
 		}
 	}
@@ -362,22 +365,26 @@ func parseRemoteAddr(addr string) (string, string) {
 }
 
 func (s *Server) handleQuery(ctx context.Context, conn net.Conn, packet *protocol.Packet) error {
-	query := string(packet.Payload[1:])
-	log.Printf("处理查询: %s", query)
+	return s.executeQuery(ctx, conn, string(packet.Payload[1:]))
+}
+
+// executeQuery 执行文本 SQL 并发送结果，COM_QUERY 和 COM_STMT_EXECUTE 共用
+func (s *Server) executeQuery(ctx context.Context, conn net.Conn, query string) error {
+	serviceLog.Infof("处理查询: %s", query)
 
 	sess := getSession(ctx)
 
 	// 使用 TiDB parser 解析 SQL 语句
 	stmtNode, err := s.parser.ParseOneStmtText(query)
 	if err != nil {
-		log.Printf("解析 SQL 失败: %v", err)
+		serviceLog.Errorf("解析 SQL 失败: %v", err)
 		return protocol.SendError(conn, fmt.Errorf("SQL 解析错误: %w", err))
 	}
 
 	// 使用处理器链处理 SQL 语句
 	result, err := s.handler.Handle(stmtNode)
 	if err != nil {
-		log.Printf("处理 SQL 失败: %v", err)
+		serviceLog.Errorf("处理 SQL 失败: %v", err)
 		return protocol.SendError(conn, fmt.Errorf("SQL 处理错误: %w", err))
 	}
 
@@ -385,19 +392,19 @@ func (s *Server) handleQuery(ctx context.Context, conn net.Conn, packet *protoco
 	switch r := result.(type) {
 	case *parser.QueryResult:
 		// SELECT 查询 - 使用QueryBuilder执行
-		log.Printf("SELECT 查询结果，涉及表: %v, 列: %v", r.Tables, r.Columns)
+		serviceLog.Infof("SELECT 查询结果，涉及表: %v, 列: %v", r.Tables, r.Columns)
 		return s.handleSelectQuery(ctx, conn, sess, query)
 	case *parser.DMLResult:
 		// INSERT/UPDATE/DELETE - 使用QueryBuilder执行
-		log.Printf("DML 操作，类型: %s, 涉及表: %v", r.Type, r.Tables)
+		serviceLog.Infof("DML 操作，类型: %s, 涉及表: %v", r.Type, r.Tables)
 		return s.handleDMLQuery(ctx, conn, sess, query, r.Type)
 	case *parser.DDLResult:
 		// CREATE/DROP/ALTER - 使用QueryBuilder执行
-		log.Printf("DDL 操作，类型: %s, 涉及表: %v", r.Type, r.Tables)
+		serviceLog.Infof("DDL 操作，类型: %s, 涉及表: %v", r.Type, r.Tables)
 		return s.handleDDLQuery(ctx, conn, sess, query, r.Type)
 	case *parser.SetResult:
 		// SET 命令
-		log.Printf("设置变量完成: %d 个变量", r.Count)
+		serviceLog.Infof("设置变量完成: %d 个变量", r.Count)
 		// 保存变量到 session
 		for name, value := range r.Vars {
 			sess.SetVariable(name, value)
@@ -408,20 +415,20 @@ func (s *Server) handleQuery(ctx context.Context, conn net.Conn, packet *protoco
 		if r.ShowTp == "SHOW_VARIABLES" {
 			return s.sendVariablesResultSet(ctx, conn, sess)
 		}
-		log.Printf("SHOW 命令完成: %s", r.ShowTp)
+		serviceLog.Infof("SHOW 命令完成: %s", r.ShowTp)
 		return s.sendResultSet(ctx, conn, sess)
 	case *parser.UseResult:
 		// USE 命令
 		sess.Set("current_database", r.Database)
-		log.Printf("切换到数据库: %s", r.Database)
+		serviceLog.Infof("切换到数据库: %s", r.Database)
 		return protocol.SendOK(conn, sess.GetNextSequenceID())
 	case *parser.DefaultResult:
 		// 默认处理
-		log.Printf("默认处理完成: %s", r.Type)
+		serviceLog.Infof("默认处理完成: %s", r.Type)
 		return protocol.SendOK(conn, sess.GetNextSequenceID())
 	default:
 		// 未知结果
-		log.Printf("未知结果类型: %T", result)
+		serviceLog.Warnf("未知结果类型: %T", result)
 		return protocol.SendOK(conn, sess.GetNextSequenceID())
 	}
 }
@@ -430,7 +437,7 @@ func (s *Server) handleInitDB(ctx context.Context, conn net.Conn, packet *protoc
 	dbName := string(packet.Payload[1:])
 	sess := getSession(ctx)
 
-	log.Printf("切换数据库: %s", dbName)
+	serviceLog.Infof("切换数据库: %s", dbName)
 	sess.Set("current_database", dbName)
 
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
@@ -438,7 +445,7 @@ func (s *Server) handleInitDB(ctx context.Context, conn net.Conn, packet *protoc
 
 func (s *Server) handlePing(ctx context.Context, conn net.Conn) error {
 	sess := getSession(ctx)
-	log.Printf("处理 PING")
+	serviceLog.Infof("处理 PING")
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
 
@@ -449,19 +456,22 @@ func (s *Server) handleStmtPrepare(ctx context.Context, conn net.Conn, packet *p
 	// 解析 COM_STMT_PREPARE 包
 	stmtPreparePacket := &protocol.ComStmtPreparePacket{}
 	if err := stmtPreparePacket.Unmarshal(bytes.NewReader(packet.RawBytes())); err != nil {
-		log.Printf("解析 COM_STMT_PREPARE 包失败: %v", err)
+		serviceLog.Errorf("解析 COM_STMT_PREPARE 包失败: %v", err)
 		protocol.SendError(conn, err)
 		return err
 	}
 
-	log.Printf("处理 COM_STMT_PREPARE: query='%s'", stmtPreparePacket.Query)
+	serviceLog.Infof("处理 COM_STMT_PREPARE: query='%s'", stmtPreparePacket.Query)
 
 	// 生成语句ID
 	stmtID := sess.ThreadID // 简化：使用thread ID
 
-	// 分析SQL语句，提取参数和列信息
-	paramCount := countParams(stmtPreparePacket.Query)
-	columnCount := analyzeColumns(stmtPreparePacket.Query)
+	// 解析SQL语句，根据表结构推断参数和结果列的元数据
+	prepared, err := s.prepareStatement(ctx, stmtPreparePacket.Query)
+	if err != nil {
+		serviceLog.Errorf("解析预处理语句失败: %v", err)
+		return protocol.SendError(conn, fmt.Errorf("SQL解析错误: %w", err))
+	}
 
 	// 创建 Prepare 响应包
 	response := &protocol.StmtPrepareResponsePacket{
@@ -469,71 +479,32 @@ func (s *Server) handleStmtPrepare(ctx context.Context, conn net.Conn, packet *p
 			SequenceID: sess.GetNextSequenceID(),
 		},
 		StatementID:  stmtID,
-		ColumnCount:  columnCount,
-		ParamCount:   paramCount,
+		ColumnCount:  uint16(len(prepared.Columns)),
+		ParamCount:   uint16(len(prepared.ParamMeta)),
 		Reserved:     0,
 		WarningCount: 0,
-		Params:       make([]protocol.FieldMeta, paramCount),
-		Columns:      make([]protocol.FieldMeta, columnCount),
-	}
-
-	// 填充参数元数据
-	for i := uint16(0); i < paramCount; i++ {
-		response.Params[i] = protocol.FieldMeta{
-			Catalog:                   "def",
-			Schema:                    "",
-			Table:                     "",
-			OrgTable:                  "",
-			Name:                      "?",
-			OrgName:                   "",
-			LengthOfFixedLengthFields: 12,
-			CharacterSet:              33,
-			ColumnLength:              255,
-			Type:                      protocol.MYSQL_TYPE_VAR_STRING,
-			Flags:                     0,
-			Decimals:                  0,
-			Reserved:                  "\x00\x00",
-		}
-	}
-
-	// 填充列元数据
-	columnNames := getColumns(stmtPreparePacket.Query)
-	for i := uint16(0); i < columnCount && i < uint16(len(columnNames)); i++ {
-		response.Columns[i] = protocol.FieldMeta{
-			Catalog:                   "def",
-			Schema:                    "test",
-			Table:                     "table",
-			OrgTable:                  "table",
-			Name:                      columnNames[i],
-			OrgName:                   columnNames[i],
-			LengthOfFixedLengthFields: 12,
-			CharacterSet:              33,
-			ColumnLength:              255,
-			Type:                      protocol.MYSQL_TYPE_VAR_STRING,
-			Flags:                     protocol.NOT_NULL_FLAG,
-			Decimals:                  0,
-			Reserved:                  "\x00\x00",
-		}
+		Params:       prepared.ParamMeta,
+		Columns:      prepared.Columns,
 	}
 
 	// 发送响应
 	data, err := response.Marshal()
 	if err != nil {
-		log.Printf("序列化 COM_STMT_PREPARE 响应失败: %v", err)
+		serviceLog.Errorf("序列化 COM_STMT_PREPARE 响应失败: %v", err)
 		protocol.SendError(conn, err)
 		return err
 	}
 
 	if _, err := conn.Write(data); err != nil {
-		log.Printf("发送 COM_STMT_PREPARE 响应失败: %v", err)
+		serviceLog.Errorf("发送 COM_STMT_PREPARE 响应失败: %v", err)
 		return err
 	}
 
-	log.Printf("已发送 COM_STMT_PREPARE 响应: statement_id=%d, params=%d, columns=%d",
+	serviceLog.Infof("已发送 COM_STMT_PREPARE 响应: statement_id=%d, params=%d, columns=%d",
 		response.StatementID, response.ParamCount, response.ColumnCount)
 
 	// 保存预处理语句到会话
-	sess.Set(fmt.Sprintf("stmt_%d", stmtID), stmtPreparePacket.Query)
+	sess.Set(preparedStmtKey(stmtID), prepared)
 
 	return nil
 }
@@ -545,106 +516,32 @@ func (s *Server) handleStmtExecute(ctx context.Context, conn net.Conn, packet *p
 	// 解析 COM_STMT_EXECUTE 包
 	stmtExecutePacket := &protocol.ComStmtExecutePacket{}
 	if err := stmtExecutePacket.Unmarshal(bytes.NewReader(packet.RawBytes())); err != nil {
-		log.Printf("解析 COM_STMT_EXECUTE 包失败: %v", err)
+		serviceLog.Errorf("解析 COM_STMT_EXECUTE 包失败: %v", err)
 		protocol.SendError(conn, err)
 		return err
 	}
 
-	log.Printf("处理 COM_STMT_EXECUTE: statement_id=%d, params=%v",
+	serviceLog.Infof("处理 COM_STMT_EXECUTE: statement_id=%d, params=%v",
 		stmtExecutePacket.StatementID, stmtExecutePacket.ParamValues)
 
-	// 获取预处理语句的查询
-	queryKey := fmt.Sprintf("stmt_%d", stmtExecutePacket.StatementID)
-	query, _ := sess.Get(queryKey)
-	if query == nil {
-		log.Printf("预处理语句不存在: statement_id=%d", stmtExecutePacket.StatementID)
+	// 获取预处理语句
+	queryKey := preparedStmtKey(stmtExecutePacket.StatementID)
+	val, _ := sess.Get(queryKey)
+	prepared, ok := val.(*preparedStatement)
+	if !ok {
+		serviceLog.Warnf("预处理语句不存在: statement_id=%d", stmtExecutePacket.StatementID)
 		protocol.SendError(conn, fmt.Errorf("预处理语句不存在"))
 		return fmt.Errorf("预处理语句不存在")
 	}
 
-	// 分析列
-	columnCount := analyzeColumns(query.(string))
-
-	// 发送列数包
-	columnCountData := []byte{
-		0x01, 0x00, 0x00, // 列数 = 1
-		sess.GetNextSequenceID(),
-	}
-	if _, err := conn.Write(columnCountData); err != nil {
-		return err
+	// 表结构在 prepare 之后发生变化时，客户端持有的参数和结果列元数据已过期，要求客户端重新 prepare
+	if err := s.checkTableSchemas(ctx, prepared); err != nil {
+		serviceLog.Infof("预处理语句引用的表结构已变化: statement_id=%d, %v", stmtExecutePacket.StatementID, err)
+		return protocol.SendError(conn, err)
 	}
 
-	// 发送列元数据包
-	columnNames := getColumns(query.(string))
-	for i := 0; i < int(columnCount) && i < len(columnNames); i++ {
-		fieldMeta := protocol.FieldMetaPacket{
-			Packet: protocol.Packet{
-				SequenceID: sess.GetNextSequenceID(),
-			},
-			FieldMeta: protocol.FieldMeta{
-				Catalog:                   "def",
-				Schema:                    "test",
-				Table:                     "table",
-				OrgTable:                  "table",
-				Name:                      columnNames[i],
-				OrgName:                   columnNames[i],
-				LengthOfFixedLengthFields: 12,
-				CharacterSet:              33,
-				ColumnLength:              255,
-				Type:                      protocol.MYSQL_TYPE_VAR_STRING,
-				Flags:                     protocol.NOT_NULL_FLAG,
-				Decimals:                  0,
-				Reserved:                  "\x00\x00",
-			},
-		}
-		fieldMetaData, err := fieldMeta.MarshalDefault()
-		if err != nil {
-			log.Printf("序列化列元数据失败: %v", err)
-			protocol.SendError(conn, err)
-			return err
-		}
-		if _, err := conn.Write(fieldMetaData); err != nil {
-			return err
-		}
-	}
-
-	// 发送列结束包
-	eofPacket := protocol.CreateEofPacketWithStatus(sess.GetNextSequenceID(), true, false)
-	eofData, err := eofPacket.Marshal()
-	if err != nil {
-		return err
-	}
-	if _, err := conn.Write(eofData); err != nil {
-		return err
-	}
-
-	// 发送数据行（简化：发送一行示例数据）
-	rowData := protocol.RowDataPacket{
-		Packet: protocol.Packet{
-			SequenceID: sess.GetNextSequenceID(),
-		},
-		RowData: []string{"1"},
-	}
-	rowDataBytes, err := rowData.Marshal()
-	if err != nil {
-		return err
-	}
-	if _, err := conn.Write(rowDataBytes); err != nil {
-		return err
-	}
-
-	// 发送结束包
-	finalEof := protocol.CreateEofPacketWithStatus(sess.GetNextSequenceID(), true, false)
-	finalEofData, err := finalEof.Marshal()
-	if err != nil {
-		return err
-	}
-	if _, err := conn.Write(finalEofData); err != nil {
-		return err
-	}
-
-	log.Printf("已发送 COM_STMT_EXECUTE 响应: statement_id=%d", stmtExecutePacket.StatementID)
-	return nil
+	query := parser.BindParams(prepared.Query, prepared.Params, stmtExecutePacket.ParamValues)
+	return s.executeQuery(ctx, conn, query)
 }
 
 // handleStmtClose 处理 COM_STMT_CLOSE 命令
@@ -654,25 +551,24 @@ func (s *Server) handleStmtClose(ctx context.Context, conn net.Conn, packet *pro
 	// 解析 COM_STMT_CLOSE 包
 	stmtClosePacket := &protocol.ComStmtClosePacket{}
 	if err := stmtClosePacket.Unmarshal(bytes.NewReader(packet.RawBytes())); err != nil {
-		log.Printf("解析 COM_STMT_CLOSE 包失败: %v", err)
+		serviceLog.Errorf("解析 COM_STMT_CLOSE 包失败: %v", err)
 		return err
 	}
 
-	log.Printf("处理 COM_STMT_CLOSE: statement_id=%d", stmtClosePacket.StatementID)
+	serviceLog.Infof("处理 COM_STMT_CLOSE: statement_id=%d", stmtClosePacket.StatementID)
 
 	// 释放预处理语句资源
-	queryKey := fmt.Sprintf("stmt_%d", stmtClosePacket.StatementID)
-	sess.Delete(queryKey)
+	sess.Delete(preparedStmtKey(stmtClosePacket.StatementID))
 
 	// COM_STMT_CLOSE 不需要发送响应
-	log.Printf("已关闭预处理语句: statement_id=%d", stmtClosePacket.StatementID)
+	serviceLog.Infof("已关闭预处理语句: statement_id=%d", stmtClosePacket.StatementID)
 	return nil
 }
 
 // handleFieldList 处理 COM_FIELD_LIST 命令
 func (s *Server) handleFieldList(ctx context.Context, conn net.Conn, packet *protocol.Packet) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_FIELD_LIST")
+	serviceLog.Infof("处理 COM_FIELD_LIST")
 
 	// 发送结束包
 	eofPacket := protocol.CreateEofPacketWithStatus(sess.GetNextSequenceID(), true, false)
@@ -687,7 +583,7 @@ func (s *Server) handleFieldList(ctx context.Context, conn net.Conn, packet *pro
 // handleSetOption 处理 COM_SET_OPTION 命令
 func (s *Server) handleSetOption(ctx context.Context, conn net.Conn, packet *protocol.Packet) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_SET_OPTION")
+	serviceLog.Infof("处理 COM_SET_OPTION")
 
 	// 返回OK包
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
@@ -696,14 +592,14 @@ func (s *Server) handleSetOption(ctx context.Context, conn net.Conn, packet *pro
 // handleRefresh 处理 COM_REFRESH 命令
 func (s *Server) handleRefresh(ctx context.Context, conn net.Conn, packet *protocol.Packet) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_REFRESH")
+	serviceLog.Infof("处理 COM_REFRESH")
 
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
 
 // handleStatistics 处理 COM_STATISTICS 命令
 func (s *Server) handleStatistics(ctx context.Context, conn net.Conn) error {
-	log.Printf("处理 COM_STATISTICS")
+	serviceLog.Infof("处理 COM_STATISTICS")
 
 	stats := "Uptime: 3600  Threads: 1  Questions: 10  Slow queries: 0  Opens: 5  Flush tables: 1  Open tables: 4  Queries per second avg: 0.003"
 
@@ -718,7 +614,7 @@ func (s *Server) handleStatistics(ctx context.Context, conn net.Conn) error {
 // handleProcessInfo 处理 COM_PROCESS_INFO 命令
 func (s *Server) handleProcessInfo(ctx context.Context, conn net.Conn) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_PROCESS_INFO")
+	serviceLog.Infof("处理 COM_PROCESS_INFO")
 
 	// 返回空结果集
 	return s.sendResultSet(ctx, conn, sess)
@@ -727,7 +623,7 @@ func (s *Server) handleProcessInfo(ctx context.Context, conn net.Conn) error {
 // handleProcessKill 处理 COM_PROCESS_KILL 命令
 func (s *Server) handleProcessKill(ctx context.Context, conn net.Conn, packet *protocol.Packet) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_PROCESS_KILL")
+	serviceLog.Infof("处理 COM_PROCESS_KILL")
 
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
@@ -735,7 +631,7 @@ func (s *Server) handleProcessKill(ctx context.Context, conn net.Conn, packet *p
 // handleDebug 处理 COM_DEBUG 命令
 func (s *Server) handleDebug(ctx context.Context, conn net.Conn) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_DEBUG")
+	serviceLog.Infof("处理 COM_DEBUG")
 
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
@@ -743,7 +639,7 @@ func (s *Server) handleDebug(ctx context.Context, conn net.Conn) error {
 // handleShutdown 处理 COM_SHUTDOWN 命令
 func (s *Server) handleShutdown(ctx context.Context, conn net.Conn) error {
 	sess := getSession(ctx)
-	log.Printf("处理 COM_SHUTDOWN")
+	serviceLog.Infof("处理 COM_SHUTDOWN")
 
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
@@ -834,12 +730,12 @@ func (s *Server) sendResultSet(ctx context.Context, conn net.Conn, sess *session
 
 // sendVariablesResultSet 发送 SHOW VARIABLES 结果集
 func (s *Server) sendVariablesResultSet(ctx context.Context, conn net.Conn, sess *session.Session) error {
-	log.Printf("发送 SHOW VARIABLES 结果集")
+	serviceLog.Infof("发送 SHOW VARIABLES 结果集")
 
 	// 获取所有会话变量
 	userVariables, err := sess.GetAllVariables()
 	if err != nil {
-		log.Printf("获取会话变量失败: %v", err)
+		serviceLog.Errorf("获取会话变量失败: %v", err)
 	}
 
 	// 默认的系统变量
@@ -985,7 +881,7 @@ func (s *Server) handleSelectQuery(ctx context.Context, conn net.Conn, sess *ses
 	// 检查缓存（使用查询缓存）
 	queryCache := s.cacheManager.GetQueryCache()
 	if cachedResult, found := queryCache.Get(query); found {
-		log.Printf("查询命中缓存: %s", query)
+		serviceLog.Infof("查询命中缓存: %s", query)
 		result := cachedResult.(*domain.QueryResult)
 
 		// 记录缓存命中
@@ -996,7 +892,7 @@ func (s *Server) handleSelectQuery(ctx context.Context, conn net.Conn, sess *ses
 	// 获取数据源
 	ds := s.GetDataSource()
 	if ds == nil {
-		log.Printf("未设置数据源，返回默认结果")
+		serviceLog.Warnf("未设置数据源，返回默认结果")
 		return s.sendResultSet(ctx, conn, sess)
 	}
 
@@ -1004,13 +900,13 @@ func (s *Server) handleSelectQuery(ctx context.Context, conn net.Conn, sess *ses
 	adapter := parser.NewSQLAdapter()
 	parseResult, err := adapter.Parse(query)
 	if err != nil {
-		log.Printf("解析SQL失败: %v", err)
+		serviceLog.Errorf("解析SQL失败: %v", err)
 		s.metricsCollector.RecordError("SQL_PARSE_ERROR")
 		return protocol.SendError(conn, fmt.Errorf("SQL解析错误: %w", err))
 	}
 
 	if !parseResult.Success {
-		log.Printf("解析SQL失败: %s", parseResult.Error)
+		serviceLog.Errorf("解析SQL失败: %s", parseResult.Error)
 		s.metricsCollector.RecordError("SQL_PARSE_ERROR")
 		return protocol.SendError(conn, fmt.Errorf("SQL解析失败: %s", parseResult.Error))
 	}
@@ -1037,13 +933,13 @@ func (s *Server) handleSelectQuery(ctx context.Context, conn net.Conn, sess *ses
 		})
 
 		if err != nil {
-			log.Printf("提交查询任务失败: %v", err)
+			serviceLog.Errorf("提交查询任务失败: %v", err)
 			s.metricsCollector.RecordError("POOL_SUBMIT_ERROR")
 			// 降级到传统路径
 			builder := parser.NewQueryBuilder(ds)
 			result, err = builder.BuildAndExecute(ctx, query)
 			if err != nil {
-				log.Printf("执行查询失败: %v", err)
+				serviceLog.Errorf("执行查询失败: %v", err)
 				s.metricsCollector.RecordError("QUERY_EXECUTION_ERROR")
 				return protocol.SendError(conn, fmt.Errorf("查询执行错误: %w", err))
 			}
@@ -1052,13 +948,13 @@ func (s *Server) handleSelectQuery(ctx context.Context, conn net.Conn, sess *ses
 			result = <-resultCh
 			err = <-errCh
 			if err != nil {
-				log.Printf("优化执行查询失败: %v", err)
+				serviceLog.Errorf("优化执行查询失败: %v", err)
 				s.metricsCollector.RecordError("OPTIMIZER_ERROR")
 				// 降级到传统路径
 				builder := parser.NewQueryBuilder(ds)
 				result, err = builder.BuildAndExecute(ctx, query)
 				if err != nil {
-					log.Printf("执行查询失败: %v", err)
+					serviceLog.Errorf("执行查询失败: %v", err)
 					s.metricsCollector.RecordError("QUERY_EXECUTION_ERROR")
 					return protocol.SendError(conn, fmt.Errorf("查询执行错误: %w", err))
 				}
@@ -1069,7 +965,7 @@ func (s *Server) handleSelectQuery(ctx context.Context, conn net.Conn, sess *ses
 		builder := parser.NewQueryBuilder(ds)
 		result, err = builder.BuildAndExecute(ctx, query)
 		if err != nil {
-			log.Printf("执行查询失败: %v", err)
+			serviceLog.Errorf("执行查询失败: %v", err)
 			s.metricsCollector.RecordError("QUERY_EXECUTION_ERROR")
 			return protocol.SendError(conn, fmt.Errorf("查询执行错误: %w", err))
 		}
@@ -1092,7 +988,7 @@ func (s *Server) handleDMLQuery(ctx context.Context, conn net.Conn, sess *sessio
 	// 获取数据源
 	ds := s.GetDataSource()
 	if ds == nil {
-		log.Printf("未设置数据源，返回OK")
+		serviceLog.Warnf("未设置数据源，返回OK")
 		return protocol.SendOK(conn, sess.GetNextSequenceID())
 	}
 
@@ -1100,12 +996,12 @@ func (s *Server) handleDMLQuery(ctx context.Context, conn net.Conn, sess *sessio
 	adapter := parser.NewSQLAdapter()
 	parseResult, err := adapter.Parse(query)
 	if err != nil {
-		log.Printf("解析SQL失败: %v", err)
+		serviceLog.Errorf("解析SQL失败: %v", err)
 		return protocol.SendError(conn, fmt.Errorf("SQL解析错误: %w", err))
 	}
 
 	if !parseResult.Success {
-		log.Printf("解析SQL失败: %s", parseResult.Error)
+		serviceLog.Errorf("解析SQL失败: %s", parseResult.Error)
 		return protocol.SendError(conn, fmt.Errorf("SQL解析失败: %s", parseResult.Error))
 	}
 
@@ -1132,11 +1028,11 @@ func (s *Server) handleDMLQuery(ctx context.Context, conn net.Conn, sess *sessio
 	}
 
 	if err != nil {
-		log.Printf("执行DML操作失败: %v", err)
+		serviceLog.Errorf("执行DML操作失败: %v", err)
 		return protocol.SendError(conn, fmt.Errorf("DML执行错误: %w", err))
 	}
 
-	log.Printf("%s 操作完成，影响行数: %d", stmtType, dmlResult.Total)
+	serviceLog.Infof("%s 操作完成，影响行数: %d", stmtType, dmlResult.Total)
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
 
@@ -1145,7 +1041,7 @@ func (s *Server) handleDDLQuery(ctx context.Context, conn net.Conn, sess *sessio
 	// 获取数据源
 	ds := s.GetDataSource()
 	if ds == nil {
-		log.Printf("未设置数据源，返回OK")
+		serviceLog.Warnf("未设置数据源，返回OK")
 		return protocol.SendOK(conn, sess.GetNextSequenceID())
 	}
 
@@ -1153,12 +1049,12 @@ func (s *Server) handleDDLQuery(ctx context.Context, conn net.Conn, sess *sessio
 	adapter := parser.NewSQLAdapter()
 	parseResult, err := adapter.Parse(query)
 	if err != nil {
-		log.Printf("解析SQL失败: %v", err)
+		serviceLog.Errorf("解析SQL失败: %v", err)
 		return protocol.SendError(conn, fmt.Errorf("SQL解析错误: %w", err))
 	}
 
 	if !parseResult.Success {
-		log.Printf("解析SQL失败: %s", parseResult.Error)
+		serviceLog.Errorf("解析SQL失败: %s", parseResult.Error)
 		return protocol.SendError(conn, fmt.Errorf("SQL解析失败: %s", parseResult.Error))
 	}
 
@@ -1184,11 +1080,11 @@ func (s *Server) handleDDLQuery(ctx context.Context, conn net.Conn, sess *sessio
 	}
 
 	if err != nil {
-		log.Printf("执行DDL操作失败: %v", err)
+		serviceLog.Errorf("执行DDL操作失败: %v", err)
 		return protocol.SendError(conn, fmt.Errorf("DDL执行错误: %w", err))
 	}
 
-	log.Printf("%s 操作完成", stmtType)
+	serviceLog.Infof("%s 操作完成", stmtType)
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
 }
 
@@ -1217,21 +1113,7 @@ func (s *Server) sendQueryResult(ctx context.Context, conn net.Conn, sess *sessi
 			Packet: protocol.Packet{
 				SequenceID: sess.GetNextSequenceID(),
 			},
-			FieldMeta: protocol.FieldMeta{
-				Catalog:                   "def",
-				Schema:                    "",
-				Table:                     "",
-				OrgTable:                  "",
-				Name:                      col.Name,
-				OrgName:                   col.Name,
-				LengthOfFixedLengthFields: 12,
-				CharacterSet:              33,
-				ColumnLength:              255,
-				Type:                      s.getMySQLType(col.Type),
-				Flags:                     s.getColumnFlags(col),
-				Decimals:                  0,
-				Reserved:                  "\x00\x00",
-			},
+			FieldMeta: columnFieldMeta("", col),
 		}
 		fieldMetaData, err := fieldMeta.MarshalDefault()
 		if err != nil {
@@ -1288,37 +1170,6 @@ func (s *Server) sendQueryResult(ctx context.Context, conn net.Conn, sess *sessi
 	return nil
 }
 
-// getMySQLType 获取MySQL类型
-func (s *Server) getMySQLType(typeStr string) byte {
-	typeStr = strings.ToLower(typeStr)
-	switch {
-	case typeStr == "int", typeStr == "integer":
-		return protocol.MYSQL_TYPE_LONG
-	case typeStr == "bigint":
-		return protocol.MYSQL_TYPE_LONGLONG
-	case typeStr == "float", typeStr == "double":
-		return protocol.MYSQL_TYPE_DOUBLE
-	case typeStr == "string", typeStr == "varchar", typeStr == "text":
-		return protocol.MYSQL_TYPE_VAR_STRING
-	case typeStr == "bool", typeStr == "boolean":
-		return protocol.MYSQL_TYPE_TINY
-	default:
-		return protocol.MYSQL_TYPE_VAR_STRING
-	}
-}
-
-// getColumnFlags 获取列标志
-func (s *Server) getColumnFlags(col domain.ColumnInfo) uint16 {
-	var flags uint16
-	if col.Primary {
-		flags |= protocol.PRI_KEY_FLAG
-	}
-	if !col.Nullable {
-		flags |= protocol.NOT_NULL_FLAG
-	}
-	return flags
-}
-
 // formatValue 格式化值
 // 对常见类型使用 strconv 避免 fmt.Sprintf 的反射开销。
 func (s *Server) formatValue(val interface{}) string {
@@ -1362,40 +1213,9 @@ func countParams(query string) uint16 {
 	return count
 }
 
-// analyzeColumns 分析SQL返回的列数
-func analyzeColumns(query string) uint16 {
-	queryUpper := strings.ToUpper(query)
-
-	if strings.Contains(queryUpper, "SELECT") {
-		// 简化：假设SELECT返回1列
-		return 1
-	}
-
-	if strings.Contains(queryUpper, "SHOW") {
-		return 2
-	}
-
-	return 0
-}
-
-// getColumns 获取SQL返回的列名
-func getColumns(query string) []string {
-	queryUpper := strings.ToUpper(query)
-
-	if strings.Contains(queryUpper, "SELECT") {
-		return []string{"id"}
-	}
-
-	if strings.Contains(queryUpper, "SHOW") {
-		return []string{"Variable_name", "Value"}
-	}
-
-	return []string{}
-}
-
 // handleSetCommand 处理 SET 命令
 func (s *Server) handleSetCommand(ctx context.Context, conn net.Conn, sess *session.Session, query string) error {
-	log.Printf("处理 SET 命令: %s", query)
+	serviceLog.Infof("处理 SET 命令: %s", query)
 
 	// 去除 SET 关键词和首尾空格
 	cmd := strings.TrimSpace(query[3:])
@@ -1409,13 +1229,13 @@ func (s *Server) handleSetCommand(ctx context.Context, conn net.Conn, sess *sess
 			charset = strings.TrimSpace(charset[:idx])
 		}
 		if err := sess.SetVariable("names", charset); err != nil {
-			log.Printf("设置字符集失败: %v", err)
+			serviceLog.Errorf("设置字符集失败: %v", err)
 			return err
 		}
 		if collation != "" {
 			sess.SetVariable("COLLATION_CONNECTION", collation)
 		}
-		log.Printf("设置字符集: %s", charset)
+		serviceLog.Infof("设置字符集: %s", charset)
 		return protocol.SendOK(conn, sess.GetNextSequenceID())
 	}
 
@@ -1437,7 +1257,7 @@ func (s *Server) handleSetCommand(ctx context.Context, conn net.Conn, sess *sess
 		}
 
 		if eqIdx == -1 {
-			log.Printf("无法解析 SET 命令: %s", assign)
+			serviceLog.Warnf("无法解析 SET 命令: %s", assign)
 			continue
 		}
 
@@ -1467,11 +1287,11 @@ func (s *Server) handleSetCommand(ctx context.Context, conn net.Conn, sess *sess
 
 		// 保存到会话
 		if err := sess.SetVariable(varName, varValue); err != nil {
-			log.Printf("设置变量 %s 失败: %v", varName, err)
+			serviceLog.Errorf("设置变量 %s 失败: %v", varName, err)
 			continue
 		}
 
-		log.Printf("设置会话变量: %s = %s", varName, varValue)
+		serviceLog.Infof("设置会话变量: %s = %s", varName, varValue)
 	}
 
 	return protocol.SendOK(conn, sess.GetNextSequenceID())
@@ -1479,10 +1299,10 @@ func (s *Server) handleSetCommand(ctx context.Context, conn net.Conn, sess *sess
 
 // Start 启动服务器
 func (s *Server) Start(ctx context.Context, listener net.Listener) error {
-	log.Println("正在启动服务器...")
+	serviceLog.Infof("正在启动服务器...")
 
 	// 监听连接
-	log.Printf("开始监听端口: %v", listener.Addr())
+	serviceLog.Infof("开始监听端口: %v", listener.Addr())
 
 	go func() {
 		for {
@@ -1494,7 +1314,7 @@ func (s *Server) Start(ctx context.Context, listener net.Listener) error {
 				if opErr, ok := err.(*net.OpError); ok && !opErr.Temporary() {
 					return
 				}
-				log.Printf("接受连接失败: %v", err)
+				serviceLog.Errorf("接受连接失败: %v", err)
 				continue
 			}
 
@@ -1502,57 +1322,57 @@ func (s *Server) Start(ctx context.Context, listener net.Listener) error {
 			s.goroutinePool.Submit(func() {
 				defer func() {
 					if err := conn.Close(); err != nil {
-						log.Printf("关闭连接失败: %v", err)
+						serviceLog.Errorf("关闭连接失败: %v", err)
 					}
 				}()
 
 				if err := s.HandleConn(ctx, conn); err != nil {
-					log.Printf("处理连接失败: %v", err)
+					serviceLog.Errorf("处理连接失败: %v", err)
 				}
 			})
 		}
 	}()
 
-	log.Println("服务器启动成功")
+	serviceLog.Infof("服务器启动成功")
 	return nil
 }
 
 // Close 关闭服务器并释放资源
 func (s *Server) Close() error {
-	log.Println("正在关闭服务器并释放资源...")
+	serviceLog.Infof("正在关闭服务器并释放资源...")
 
 	var errs []error
 
 	// 关闭goroutine池
 	if s.goroutinePool != nil {
 		if err := s.goroutinePool.Close(); err != nil {
-			log.Printf("关闭goroutine池失败: %v", err)
+			serviceLog.Errorf("关闭goroutine池失败: %v", err)
 			errs = append(errs, err)
 		} else {
-			log.Println("goroutine池已关闭")
+			serviceLog.Infof("goroutine池已关闭")
 		}
 	}
 
 	// 关闭对象池
 	if s.objectPool != nil {
 		if err := s.objectPool.Close(); err != nil {
-			log.Printf("关闭对象池失败: %v", err)
+			serviceLog.Errorf("关闭对象池失败: %v", err)
 			errs = append(errs, err)
 		} else {
-			log.Println("对象池已关闭")
+			serviceLog.Infof("对象池已关闭")
 		}
 	}
 
 	// 获取监控指标快照（用于日志）
 	if s.metricsCollector != nil {
 		snapshot := s.metricsCollector.GetSnapshot()
-		log.Printf("查询统计: 总计=%d, 成功=%d, 失败=%d, 成功率=%.2f%%",
+		serviceLog.Infof("查询统计: 总计=%d, 成功=%d, 失败=%d, 成功率=%.2f%%",
 			snapshot.QueryCount,
 			snapshot.QuerySuccess,
 			snapshot.QueryError,
 			snapshot.SuccessRate,
 		)
-		log.Printf("性能统计: 平均耗时=%v",
+		serviceLog.Infof("性能统计: 平均耗时=%v",
 			snapshot.AvgDuration,
 		)
 
@@ -1561,7 +1381,7 @@ func (s *Server) Close() error {
 			allCacheStats := s.cacheManager.GetStats()
 			queryCacheStats := allCacheStats["query"]
 			if queryCacheStats != nil {
-				log.Printf("缓存统计: 命中率=%.2f%%, 缓存大小=%d, 命中=%d, 未命中=%d",
+				serviceLog.Infof("缓存统计: 命中率=%.2f%%, 缓存大小=%d, 命中=%d, 未命中=%d",
 					queryCacheStats.HitRate,
 					queryCacheStats.Size,
 					queryCacheStats.Hits,
@@ -1575,7 +1395,7 @@ func (s *Server) Close() error {
 		return fmt.Errorf("关闭服务器时发生 %d 个错误", len(errs))
 	}
 
-	log.Println("服务器已关闭")
+	serviceLog.Infof("服务器已关闭")
 	return nil
 }
 
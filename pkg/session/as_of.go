@@ -0,0 +1,39 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ParseAsOf 解析 AS OF 的时间
+func ParseAsOf(value string) (time.Time, error) {
+	t, err := parser.ParseTimestamp(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid AS OF timestamp '%s'", value)
+	}
+	return t, nil
+}
+
+// withAsOf 为 FOR SYSTEM_TIME AS OF 查询在上下文中设置时间点
+func withAsOf(ctx context.Context, stmt *parser.SelectStatement) (context.Context, error) {
+	if stmt.AsOf == "" {
+		return ctx, nil
+	}
+	at, err := ParseAsOf(stmt.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	return domain.WithAsOf(ctx, at), nil
+}
+
+// checkAsOfServed 时间点查询没有被任何数据源按历史版本处理时报错，避免返回最新数据
+func checkAsOfServed(ctx context.Context) error {
+	if _, ok := domain.AsOfFromContext(ctx); ok && !domain.AsOfServed(ctx) {
+		return fmt.Errorf("AS OF is not supported by the data source")
+	}
+	return nil
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/optimizer"
@@ -16,6 +16,7 @@ import (
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
 	xmlpersist "github.com/kasuganosora/sqlexec/pkg/resource/xml"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
 )
 
@@ -26,9 +27,11 @@ type CoreSession struct {
 	dsManager        *application.DataSourceManager
 	executor         *optimizer.OptimizedExecutor
 	adapter          *parser.SQLAdapter
-	currentDB        string // 当前使用的数据库名（USE 语句）
-	user             string // 当前登录用户名
-	host             string // 当前客户端主机
+	currentDB        string            // 当前使用的数据库名（USE 语句）
+	user             string            // 当前登录用户名
+	host             string            // 当前客户端主机
+	connAttrs        map[string]string // 客户端的连接属性（program_name、_client_version 等）
+	queryLabels      map[string]string // 连接级查询标签（会话变量 query_labels 可覆盖）
 	mu               sync.RWMutex
 	txn              domain.Transaction
 	txnMu            sync.Mutex // 事务锁（防止嵌套）
@@ -40,8 +43,16 @@ type CoreSession struct {
 	queryMu          sync.Mutex                                           // 查询锁
 	vdbRegistry      *virtual.VirtualDatabaseRegistry                     // 虚拟数据库注册表
 	sessionVars      map[string]string                                    // 会话级系统变量覆盖 (SET NAMES, SET @@var, etc.)
+	userVars         map[string]interface{}                               // 用户变量（SET @var、SELECT ... INTO @var）
+	lastSeqValues    map[string]int64                                     // 本会话最近一次取得的序列值（LASTVAL），键为 db.sequence
+	nextIsolation    string                                               // SET TRANSACTION 指定的下一个事务的隔离级别
+	stateChanges     SessionStateChanges                                  // 待报告给客户端的会话状态变化
+	diagnostics      diagnosticsArea                                      // 最近一条语句的警告和错误（SHOW WARNINGS）
 	databaseDir      string                                               // 持久化存储根目录
 	tablePersistence map[string]map[string]*xmlpersist.TablePersistConfig // dbName -> tableName -> config
+	rewriteContext   atomic.Pointer[parser.RewriteContext]                // 改写钩子看到的用户和数据库（解析时可能已持有 mu，不能再加锁读取）
+	columnDecryption func(user string) bool                               // 可以读取加密列明文的用户, nil表示所有用户
+	globalVarHooks   *GlobalVariableHooks                                 // SET GLOBAL 时通知的回调, nil表示不通知
 }
 
 // NewCoreSession 创建核心会话（默认使用增强优化器）
@@ -197,12 +208,25 @@ func (s *CoreSession) createQueryContext(parentCtx context.Context, sql string)
 	traceID := s.traceID
 	user := s.user
 	host := s.host
+	connAttrs := s.connAttrs
 	currentDB := s.currentDB
+	decrypt := s.columnDecryption
 	s.mu.RUnlock()
 
+	if decrypt == nil || decrypt(user) {
+		parentCtx = domain.WithColumnDecryption(parentCtx)
+	}
+	labels := s.QueryLabels(sql)
+	if len(labels) > 0 {
+		parentCtx = domain.WithQueryLabels(parentCtx, labels)
+	}
+
 	// 先创建可取消的上下文
 	baseCtx, cancel := context.WithCancel(parentCtx)
-	queryID := GenerateQueryID(threadID)
+	queryID := queryIDFromContext(parentCtx)
+	if queryID == "" {
+		queryID = GenerateQueryID()
+	}
 
 	queryCtx := &QueryContext{
 		QueryID:    queryID,
@@ -214,6 +238,8 @@ func (s *CoreSession) createQueryContext(parentCtx context.Context, sql string)
 		User:       user,
 		Host:       host,
 		DB:         currentDB,
+		ConnAttrs:  connAttrs,
+		Labels:     labels,
 	}
 
 	// 如果设置了超时,包装超时上下文
@@ -274,7 +300,7 @@ func (s *CoreSession) ExecuteQuery(ctx context.Context, sql string) (*domain.Que
 	defer registry.UnregisterQuery(qc.QueryID)
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -294,7 +320,17 @@ func (s *CoreSession) ExecuteQuery(ctx context.Context, sql string) (*domain.Que
 	// 执行查询(使用带取消的 context)
 	var result *domain.QueryResult
 	if parseResult.Statement.Select != nil {
-		result, err = s.executor.ExecuteSelect(queryCtx, parseResult.Statement.Select)
+		selectCtx, asOfErr := withAsOf(queryCtx, parseResult.Statement.Select)
+		if asOfErr != nil {
+			return nil, asOfErr
+		}
+		result, err = s.executor.ExecuteSelect(selectCtx, parseResult.Statement.Select)
+		if err == nil {
+			err = checkAsOfServed(selectCtx)
+		}
+	} else if show := parseResult.Statement.Show; show != nil && (show.Type == "WARNINGS" || show.Type == "ERRORS") {
+		// SHOW WARNINGS / SHOW ERRORS 读取会话的诊断区
+		result = s.executeShowDiagnostics(show)
 	} else if parseResult.Statement.Show != nil {
 		// 处理 SHOW 语句 - 转换为 information_schema 查询
 		result, err = s.executor.ExecuteShow(queryCtx, parseResult.Statement.Show)
@@ -309,6 +345,16 @@ func (s *CoreSession) ExecuteQuery(ctx context.Context, sql string) (*domain.Que
 			showStmt.Like = descStmt.Column
 		}
 		result, err = s.executor.ExecuteShow(queryCtx, showStmt)
+	} else if parseResult.Statement.CreateIndex != nil {
+		// 处理 CREATE INDEX 语句（文本协议下所有语句都经由 ExecuteQuery）
+		s.mu.Lock()
+		result, err = s.createIndexLocked(queryCtx, parseResult.Statement.CreateIndex)
+		s.mu.Unlock()
+	} else if parseResult.Statement.DropIndex != nil {
+		// 处理 DROP INDEX 语句
+		s.mu.Lock()
+		result, err = s.dropIndexLocked(queryCtx, parseResult.Statement.DropIndex)
+		s.mu.Unlock()
 	} else if parseResult.Statement.Create != nil {
 		// 处理 CREATE 语句
 		result, err = s.executeCreateStatement(queryCtx, parseResult.Statement.Create)
@@ -318,6 +364,42 @@ func (s *CoreSession) ExecuteQuery(ctx context.Context, sql string) (*domain.Que
 	} else if parseResult.Statement.Drop != nil {
 		// 处理 DROP 语句 (DROP TABLE t1, t2, etc.)
 		result, err = s.executor.ExecuteDrop(queryCtx, parseResult.Statement.Drop)
+	} else if parseResult.Statement.Optimize != nil {
+		// 处理 OPTIMIZE TABLE 语句，返回每个表的整理结果
+		result, err = s.executor.ExecuteOptimize(queryCtx, parseResult.Statement.Optimize)
+	} else if parseResult.Statement.ExportTable != nil {
+		// 处理 EXPORT TABLE 语句
+		result, err = s.executor.ExecuteExportTable(queryCtx, parseResult.Statement.ExportTable)
+	} else if parseResult.Statement.ImportTable != nil {
+		// 处理 IMPORT TABLE 语句，导入会创建新表
+		result, err = s.executor.ExecuteImportTable(queryCtx, parseResult.Statement.ImportTable)
+	} else if parseResult.Statement.Checksum != nil {
+		// 处理 CHECKSUM TABLE 语句
+		result, err = s.executor.ExecuteChecksum(queryCtx, parseResult.Statement.Checksum)
+	} else if parseResult.Statement.Diff != nil {
+		// 处理 DIFF TABLE 语句，每条差异返回一行
+		result, err = s.executor.ExecuteDiff(queryCtx, parseResult.Statement.Diff)
+	} else if parseResult.Statement.Shadow != nil {
+		// 处理 SHADOW / CUTOVER / UNSHADOW TABLE 语句
+		result, err = s.executor.ExecuteShadow(queryCtx, parseResult.Statement.Shadow)
+	} else if parseResult.Statement.Check != nil {
+		// 处理 CHECK TABLE 语句
+		result, err = s.executor.ExecuteCheck(queryCtx, parseResult.Statement.Check)
+	} else if parseResult.Statement.GenerateTable != nil {
+		// 处理 CREATE TABLE ... AS GENERATE 语句，创建表并写入生成的数据
+		result, err = s.executor.ExecuteGenerateTable(queryCtx, parseResult.Statement.GenerateTable)
+	} else if parseResult.Statement.ImportData != nil {
+		// 处理 IMPORT DATA INFILE 语句，表不存在时会创建新表
+		result, err = s.executor.ExecuteImportData(queryCtx, parseResult.Statement.ImportData)
+	} else if parseResult.Statement.Undrop != nil {
+		// 处理 UNDROP TABLE 语句，恢复的表重新可见
+		result, err = s.executor.ExecuteUndropTable(queryCtx, parseResult.Statement.Undrop)
+	} else if parseResult.Statement.Sequence != nil {
+		// 处理 CREATE/ALTER/DROP SEQUENCE 语句，序列保存为表
+		result, err = s.executor.ExecuteSequence(queryCtx, parseResult.Statement.Type, parseResult.Statement.Sequence)
+	} else if parseResult.Statement.Flashback != nil {
+		// 处理 FLASHBACK TABLE ... TO TIMESTAMP 语句
+		result, err = s.executor.ExecuteFlashbackTable(queryCtx, parseResult.Statement.Flashback)
 	} else {
 		return nil, fmt.Errorf("statement type not supported yet")
 	}
@@ -363,7 +445,7 @@ func (s *CoreSession) ExecuteInsert(ctx context.Context, sql string, rows []doma
 	defer registry.UnregisterQuery(qc.QueryID)
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -395,7 +477,7 @@ func (s *CoreSession) ExecuteInsert(ctx context.Context, sql string, rows []doma
 	if err == nil && parseResult.Statement.Insert != nil {
 		if cfg := s.getTablePersistence(s.currentDB, parseResult.Statement.Insert.Table); cfg != nil {
 			if pErr := s.persistTableData(queryCtx, s.currentDB, cfg); pErr != nil {
-				log.Printf("warning: persistence write-back failed for %s: %v", parseResult.Statement.Insert.Table, pErr)
+				sessionLog.Warn("persistence write-back failed", "table", parseResult.Statement.Insert.Table, "err", pErr)
 			}
 		}
 	}
@@ -430,7 +512,7 @@ func (s *CoreSession) ExecuteUpdate(ctx context.Context, sql string, _ []domain.
 	defer registry.UnregisterQuery(qc.QueryID)
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -462,7 +544,7 @@ func (s *CoreSession) ExecuteUpdate(ctx context.Context, sql string, _ []domain.
 	if err == nil && parseResult.Statement.Update != nil {
 		if cfg := s.getTablePersistence(s.currentDB, parseResult.Statement.Update.Table); cfg != nil {
 			if pErr := s.persistTableData(queryCtx, s.currentDB, cfg); pErr != nil {
-				log.Printf("warning: persistence write-back failed for %s: %v", parseResult.Statement.Update.Table, pErr)
+				sessionLog.Warn("persistence write-back failed", "table", parseResult.Statement.Update.Table, "err", pErr)
 			}
 		}
 	}
@@ -497,7 +579,7 @@ func (s *CoreSession) ExecuteDelete(ctx context.Context, sql string, _ []domain.
 	defer registry.UnregisterQuery(qc.QueryID)
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -529,7 +611,7 @@ func (s *CoreSession) ExecuteDelete(ctx context.Context, sql string, _ []domain.
 	if err == nil && parseResult.Statement.Delete != nil {
 		if cfg := s.getTablePersistence(s.currentDB, parseResult.Statement.Delete.Table); cfg != nil {
 			if pErr := s.persistTableData(queryCtx, s.currentDB, cfg); pErr != nil {
-				log.Printf("warning: persistence write-back failed for %s: %v", parseResult.Statement.Delete.Table, pErr)
+				sessionLog.Warn("persistence write-back failed", "table", parseResult.Statement.Delete.Table, "err", pErr)
 			}
 		}
 	}
@@ -549,7 +631,7 @@ func (s *CoreSession) ExecuteCreate(ctx context.Context, sql string) (*domain.Qu
 	}
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -582,7 +664,7 @@ func (s *CoreSession) ExecuteDrop(ctx context.Context, sql string) (*domain.Quer
 	}
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -615,7 +697,7 @@ func (s *CoreSession) ExecuteAlter(ctx context.Context, sql string) (*domain.Que
 	}
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -637,6 +719,40 @@ func (s *CoreSession) ExecuteAlter(ctx context.Context, sql string) (*domain.Que
 	return result, nil
 }
 
+// ExecuteOptimize 执行 OPTIMIZE TABLE（底层实现）
+// 返回 *domain.QueryResult，每个表一行整理结果
+func (s *CoreSession) ExecuteOptimize(ctx context.Context, sql string) (*domain.QueryResult, error) {
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+
+	if closed {
+		return nil, fmt.Errorf("session is closed")
+	}
+
+	// 解析 SQL
+	parseResult, err := s.parseStatement(sql)
+	if err != nil {
+		return nil, fmt.Errorf("SQL parse failed: %w", err)
+	}
+
+	if !parseResult.Success {
+		return nil, fmt.Errorf("SQL parse error: %s", parseResult.Error)
+	}
+
+	if parseResult.Statement.Optimize == nil {
+		return nil, fmt.Errorf("not an OPTIMIZE statement")
+	}
+
+	// 整理在线进行，不持有会话写锁
+	result, err := s.executor.ExecuteOptimize(ctx, parseResult.Statement.Optimize)
+	if err != nil {
+		return nil, fmt.Errorf("OPTIMIZE failed: %w", err)
+	}
+
+	return result, nil
+}
+
 // ExecuteCreateIndex 执行 CREATE INDEX（底层实现）
 // 返回 *domain.QueryResult，其中 Total 字段是影响的行数（对于 DDL 通常为 0）
 func (s *CoreSession) ExecuteCreateIndex(ctx context.Context, sql string) (*domain.QueryResult, error) {
@@ -648,7 +764,7 @@ func (s *CoreSession) ExecuteCreateIndex(ctx context.Context, sql string) (*doma
 	}
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -661,15 +777,21 @@ func (s *CoreSession) ExecuteCreateIndex(ctx context.Context, sql string) (*doma
 		return nil, fmt.Errorf("not a CREATE INDEX statement")
 	}
 
+	return s.createIndexLocked(ctx, parseResult.Statement.CreateIndex)
+}
+
+// createIndexLocked 执行已解析的 CREATE INDEX，调用方需持有 s.mu
+func (s *CoreSession) createIndexLocked(ctx context.Context, stmt *parser.CreateIndexStatement) (*domain.QueryResult, error) {
 	// 使用 executor 执行 CREATE INDEX
-	result, err := s.executor.ExecuteCreateIndex(ctx, parseResult.Statement.CreateIndex)
+	result, err := s.executor.ExecuteCreateIndex(ctx, stmt)
 	if err != nil {
 		return nil, fmt.Errorf("CREATE INDEX failed: %w", err)
 	}
+	// 索引变化会改变查询计划（如函数索引的下推条件）
+	optimizer.InvalidatePlanCaches()
 
 	// Persist index metadata for ENGINE=xml tables
-	tableName := parseResult.Statement.CreateIndex.TableName
-	s.persistIndexMetaIfNeeded(ctx, tableName)
+	s.persistIndexMetaIfNeeded(ctx, stmt.TableName)
 
 	return result, nil
 }
@@ -685,7 +807,7 @@ func (s *CoreSession) ExecuteDropIndex(ctx context.Context, sql string) (*domain
 	}
 
 	// 解析 SQL
-	parseResult, err := s.adapter.Parse(sql)
+	parseResult, err := s.parseStatement(sql)
 	if err != nil {
 		return nil, fmt.Errorf("SQL parse failed: %w", err)
 	}
@@ -698,15 +820,20 @@ func (s *CoreSession) ExecuteDropIndex(ctx context.Context, sql string) (*domain
 		return nil, fmt.Errorf("not a DROP INDEX statement")
 	}
 
+	return s.dropIndexLocked(ctx, parseResult.Statement.DropIndex)
+}
+
+// dropIndexLocked 执行已解析的 DROP INDEX，调用方需持有 s.mu
+func (s *CoreSession) dropIndexLocked(ctx context.Context, stmt *parser.DropIndexStatement) (*domain.QueryResult, error) {
 	// 使用 executor 执行 DROP INDEX
-	result, err := s.executor.ExecuteDropIndex(ctx, parseResult.Statement.DropIndex)
+	result, err := s.executor.ExecuteDropIndex(ctx, stmt)
 	if err != nil {
 		return nil, fmt.Errorf("DROP INDEX failed: %w", err)
 	}
+	optimizer.InvalidatePlanCaches()
 
 	// Persist index metadata for ENGINE=xml tables
-	tableName := parseResult.Statement.DropIndex.TableName
-	s.persistIndexMetaIfNeeded(ctx, tableName)
+	s.persistIndexMetaIfNeeded(ctx, stmt.TableName)
 
 	return result, nil
 }
@@ -719,6 +846,7 @@ type tableIndexLister interface {
 
 // persistIndexMetaIfNeeded saves index metadata to disk for tables that support persistence.
 // This handles both XML persistence tables and file-based datasources (CSV/JSON/JSONL).
+// The caller must hold s.mu.
 func (s *CoreSession) persistIndexMetaIfNeeded(ctx context.Context, tableName string) {
 	var ds domain.DataSource
 	if s.dsManager != nil {
@@ -732,7 +860,7 @@ func (s *CoreSession) persistIndexMetaIfNeeded(ctx context.Context, tableName st
 	}
 
 	// Path 1: XML persistence tables
-	if cfg := s.getTablePersistence(s.currentDB, tableName); cfg != nil {
+	if cfg := s.tablePersistence[s.currentDB][tableName]; cfg != nil {
 		mvccDS, ok := ds.(*memory.MVCCDataSource)
 		if !ok {
 			return
@@ -755,7 +883,7 @@ func (s *CoreSession) persistIndexMetaIfNeeded(ctx context.Context, tableName st
 		}
 
 		if err := xmlpersist.PersistIndexMeta(cfg, indexes); err != nil {
-			log.Printf("warning: failed to persist index metadata for %s: %v", tableName, err)
+			sessionLog.Warn("failed to persist index metadata", "table", tableName, "err", err)
 		}
 		return
 	}
@@ -788,7 +916,7 @@ func (s *CoreSession) persistIndexMetaIfNeeded(ctx context.Context, tableName st
 	}
 
 	if err := persister.PersistIndexMeta(indexes); err != nil {
-		log.Printf("warning: failed to persist index metadata for %s: %v", tableName, err)
+		sessionLog.Warn("failed to persist index metadata", "table", tableName, "err", err)
 	}
 }
 
@@ -817,7 +945,7 @@ func (s *CoreSession) BeginTx(ctx context.Context) (domain.Transaction, error) {
 
 	// 开始事务
 	tx, err := txDS.BeginTransaction(ctx, &domain.TransactionOptions{
-		IsolationLevel: "REPEATABLE READ",
+		IsolationLevel: s.takeTxIsolationLocked(),
 		ReadOnly:       false,
 	})
 	if err != nil {
@@ -963,6 +1091,7 @@ func (s *CoreSession) executeCreateStatement(ctx context.Context, createStmt *pa
 				if _, err := s.dsManager.Get("test"); err == nil {
 					targetDB = "test"
 					s.currentDB = "test"
+					s.publishRewriteContext()
 					if s.executor != nil {
 						s.executor.SetCurrentDB("test")
 					}
@@ -981,6 +1110,7 @@ func (s *CoreSession) executeCreateStatement(ctx context.Context, createStmt *pa
 									if regErr := s.dsManager.Register("test", testDS); regErr == nil {
 										targetDB = "test"
 										s.currentDB = "test"
+										s.publishRewriteContext()
 										if s.executor != nil {
 											s.executor.SetCurrentDB("test")
 										}
@@ -1046,6 +1176,9 @@ func (s *CoreSession) executeCreateStatement(ctx context.Context, createStmt *pa
 			AutoIncrement: col.AutoInc,
 			Primary:       col.Primary,
 			Unique:        col.Unique,
+			Unsigned:      col.Unsigned,
+			Zerofill:      col.Zerofill,
+			Encryption:    col.Encryption.ToDomain(),
 		}
 		tableInfo.Columns = append(tableInfo.Columns, colInfo)
 	}
@@ -1066,7 +1199,7 @@ func (s *CoreSession) executeCreateStatement(ctx context.Context, createStmt *pa
 			StorageMode: mode,
 		}
 		if err := xmlpersist.PersistTableSchema(cfg, tableInfo); err != nil {
-			log.Printf("warning: failed to persist schema for %s: %v", tableName, err)
+			sessionLog.Warn("failed to persist schema", "table", tableName, "err", err)
 		} else {
 			s.registerTablePersistence(targetDB, tableName, cfg)
 		}
@@ -1082,6 +1215,21 @@ func (s *CoreSession) executeCreateStatement(ctx context.Context, createStmt *pa
 
 // executeUseStatement 执行 USE 语句
 func (s *CoreSession) executeUseStatement(useStmt *parser.UseStatement) (*domain.QueryResult, error) {
+	if err := s.UseDatabase(useStmt.Database); err != nil {
+		return nil, err
+	}
+
+	// 返回成功结果
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{},
+		Rows:    []domain.Row{},
+		Total:   0,
+	}, nil
+}
+
+// UseDatabase 切换当前数据库（USE / COM_INIT_DB）
+// 之后未限定库名的表解析到该数据库的数据源；数据库不存在时自动创建内存数据库
+func (s *CoreSession) UseDatabase(dbName string) error {
 	s.mu.RLock()
 	closed := s.closed
 	vdbReg := s.vdbRegistry
@@ -1089,11 +1237,9 @@ func (s *CoreSession) executeUseStatement(useStmt *parser.UseStatement) (*domain
 	s.mu.RUnlock()
 
 	if closed {
-		return nil, fmt.Errorf("session is closed")
+		return fmt.Errorf("session is closed")
 	}
 
-	dbName := useStmt.Database
-
 	// 验证数据库是否存在，如果不存在则自动创建
 	// 允许使用 information_schema 和所有已注册的虚拟数据库
 	isVirtual := dbName == "information_schema" || (vdbReg != nil && vdbReg.IsVirtualDB(dbName))
@@ -1116,10 +1262,10 @@ func (s *CoreSession) executeUseStatement(useStmt *parser.UseStatement) (*domain
 					Writable: true,
 				})
 				if err := memoryDS.Connect(context.Background()); err != nil {
-					return nil, fmt.Errorf("failed to create database '%s': %w", dbName, err)
+					return fmt.Errorf("failed to create database '%s': %w", dbName, err)
 				}
 				if err := dsMgr.Register(dbName, memoryDS); err != nil {
-					return nil, fmt.Errorf("failed to register database '%s': %w", dbName, err)
+					return fmt.Errorf("failed to register database '%s': %w", dbName, err)
 				}
 			}
 		}
@@ -1127,18 +1273,17 @@ func (s *CoreSession) executeUseStatement(useStmt *parser.UseStatement) (*domain
 
 	// 设置当前数据库
 	s.SetCurrentDB(dbName)
+	s.mu.Lock()
+	s.stateChanges.Schema = dbName
+	s.stateChanges.SchemaChanged = true
+	s.mu.Unlock()
 
 	// Load persisted tables from disk (ENGINE=xml)
 	if !isVirtual && dsMgr != nil {
 		s.loadPersistedTables(dbName)
 	}
 
-	// 返回成功结果
-	return &domain.QueryResult{
-		Columns: []domain.ColumnInfo{},
-		Rows:    []domain.Row{},
-		Total:   0,
-	}, nil
+	return nil
 }
 
 // loadPersistedTables loads XML-persisted tables from disk into the memory datasource
@@ -1170,13 +1315,13 @@ func (s *CoreSession) loadPersistedTables(dbName string) {
 			// Load schema and index metadata only (no data parsing)
 			tableInfo, indexes, err := xmlpersist.LoadTableSchemaAndIndexes(cfg)
 			if err != nil {
-				log.Printf("warning: failed to load persisted table %s: %v", cfg.TableName, err)
+				sessionLog.Warn("failed to load persisted table", "table", cfg.TableName, "err", err)
 				continue
 			}
 
 			// Create table in memory
 			if err := ds.CreateTable(ctx, tableInfo); err != nil {
-				log.Printf("warning: failed to create table %s from disk: %v", cfg.TableName, err)
+				sessionLog.Warn("failed to create table from disk", "table", cfg.TableName, "err", err)
 				continue
 			}
 
@@ -1190,41 +1335,41 @@ func (s *CoreSession) loadPersistedTables(dbName string) {
 				})
 				return err
 			}); err != nil {
-				log.Printf("warning: failed to bulk load data for table %s: %v", cfg.TableName, err)
+				sessionLog.Warn("failed to bulk load data", "table", cfg.TableName, "err", err)
 			}
 
 			// Rebuild indexes
 			for _, idx := range indexes {
 				if err := mvccDS.CreateIndexWithColumns(cfg.TableName, idx.Columns, idx.Type, idx.Unique); err != nil {
-					log.Printf("warning: failed to create index %s on %s: %v", idx.Name, cfg.TableName, err)
+					sessionLog.Warn("failed to create index", "index", idx.Name, "table", cfg.TableName, "err", err)
 				}
 			}
 
 			s.registerTablePersistence(dbName, cfg.TableName, cfg)
-			log.Printf("loaded persisted table: %s.%s (%d rows, %d indexes)", dbName, cfg.TableName, rowCount, len(indexes))
+			sessionLog.Info("loaded persisted table", "database", dbName, "table", cfg.TableName, "rows", rowCount, "indexes", len(indexes))
 			continue
 		}
 
 		// Fallback for non-MVCC datasources: use original full-load path
 		tableInfo, rows, indexes, err := xmlpersist.LoadTableFromDisk(cfg)
 		if err != nil {
-			log.Printf("warning: failed to load persisted table %s: %v", cfg.TableName, err)
+			sessionLog.Warn("failed to load persisted table", "table", cfg.TableName, "err", err)
 			continue
 		}
 
 		if err := ds.CreateTable(ctx, tableInfo); err != nil {
-			log.Printf("warning: failed to create table %s from disk: %v", cfg.TableName, err)
+			sessionLog.Warn("failed to create table from disk", "table", cfg.TableName, "err", err)
 			continue
 		}
 
 		if len(rows) > 0 {
 			if _, err := ds.Insert(ctx, cfg.TableName, rows, nil); err != nil {
-				log.Printf("warning: failed to insert data for table %s: %v", cfg.TableName, err)
+				sessionLog.Warn("failed to insert data", "table", cfg.TableName, "err", err)
 			}
 		}
 
 		s.registerTablePersistence(dbName, cfg.TableName, cfg)
-		log.Printf("loaded persisted table: %s.%s (%d rows, %d indexes)", dbName, cfg.TableName, len(rows), len(indexes))
+		sessionLog.Info("loaded persisted table", "database", dbName, "table", cfg.TableName, "rows", len(rows), "indexes", len(indexes))
 	}
 }
 
@@ -1242,9 +1387,10 @@ func (s *CoreSession) executeSetStatement(ctx context.Context, setStmt *parser.S
 		if charset == "" {
 			charset = "utf8mb4"
 		}
-		s.sessionVars["character_set_client"] = charset
-		s.sessionVars["character_set_connection"] = charset
-		s.sessionVars["character_set_results"] = charset
+		for _, name := range []string{"character_set_client", "character_set_connection", "character_set_results"} {
+			s.sessionVars[name] = charset
+			s.recordVarLocked(name, charset)
+		}
 
 	case "CHARACTER SET":
 		// SET CHARACTER SET charset
@@ -1252,17 +1398,36 @@ func (s *CoreSession) executeSetStatement(ctx context.Context, setStmt *parser.S
 		if charset == "" {
 			charset = "utf8mb4"
 		}
-		s.sessionVars["character_set_client"] = charset
-		s.sessionVars["character_set_results"] = charset
+		for _, name := range []string{"character_set_client", "character_set_results"} {
+			s.sessionVars[name] = charset
+			s.recordVarLocked(name, charset)
+		}
 
 	case "VARIABLE":
 		// SET [SESSION|GLOBAL] var = value
 		for varName, varValue := range setStmt.Variables {
 			// Normalize: remove scope prefix and lowercase
 			name := strings.ToLower(varName)
-			name = strings.TrimPrefix(name, "global ")
+			global := strings.HasPrefix(name, "global ")
+			if global {
+				name = strings.TrimPrefix(name, "global ")
+				// 通知服务器层（如 max_connections 运行时调整）
+				if err := s.globalVarHooks.apply(name, varValue); err != nil {
+					return nil, err
+				}
+			}
 			name = strings.TrimPrefix(name, "session ")
+			s.warnDeprecatedVarLocked(name)
+			if handled, err := s.setIsolationVarLocked(name, varValue); handled {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
 			s.sessionVars[name] = varValue
+			if !global {
+				s.recordVarLocked(name, varValue)
+			}
 		}
 	}
 
@@ -1286,6 +1451,18 @@ func (s *CoreSession) GetSessionVar(name string) (string, bool) {
 	return val, ok
 }
 
+// Quoter 返回按当前连接字符集和 sql_mode 转义字面量的 Quoter
+// 用于在会话内拼接 SQL（如 SHOW 转换、下推到外部数据源的语句）
+func (s *CoreSession) Quoter() security.Quoter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	charset := s.sessionVars["character_set_connection"]
+	if charset == "" {
+		charset = s.sessionVars["character_set_client"]
+	}
+	return security.NewQuoter(charset, s.sessionVars["sql_mode"])
+}
+
 // GetCurrentDB 获取当前使用的数据库名
 func (s *CoreSession) GetCurrentDB() string {
 	s.mu.RLock()
@@ -1298,6 +1475,7 @@ func (s *CoreSession) SetCurrentDB(dbName string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.currentDB = dbName
+	s.publishRewriteContext()
 
 	// 同步更新 OptimizedExecutor 的当前数据库
 	if s.executor != nil {
@@ -1313,6 +1491,13 @@ func (s *CoreSession) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegi
 	}
 }
 
+// GetVirtualDBRegistry 返回虚拟数据库注册表，未设置时为 nil
+func (s *CoreSession) GetVirtualDBRegistry() *virtual.VirtualDatabaseRegistry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.vdbRegistry
+}
+
 // CurrentUser returns current logged-in user
 func (s *CoreSession) CurrentUser() string {
 	s.mu.RLock()
@@ -1325,6 +1510,7 @@ func (s *CoreSession) SetUser(user string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.user = user
+	s.publishRewriteContext()
 }
 
 // CurrentHost returns current client host
@@ -1341,6 +1527,21 @@ func (s *CoreSession) SetHost(host string) {
 	s.host = host
 }
 
+// ConnectionAttributes returns the connection attributes sent by the client
+func (s *CoreSession) ConnectionAttributes() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connAttrs
+}
+
+// SetConnectionAttributes sets the connection attributes sent by the client.
+// The map is not copied and must not be modified afterwards.
+func (s *CoreSession) SetConnectionAttributes(attrs map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connAttrs = attrs
+}
+
 // Close 关闭会话
 func (s *CoreSession) Close(ctx context.Context) error {
 	s.mu.Lock()
@@ -1366,6 +1567,43 @@ func (s *CoreSession) Close(ctx context.Context) error {
 	return nil
 }
 
+// Reset 重置会话状态（COM_RESET_CONNECTION / COM_CHANGE_USER）
+// 回滚未提交事务、删除临时表并清空会话变量，会话本身保持可用
+func (s *CoreSession) Reset(ctx context.Context) error {
+	s.txnMu.Lock()
+	defer s.txnMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("session is closed")
+	}
+
+	// 回滚事务
+	if s.txn != nil {
+		_ = s.txn.Rollback(ctx)
+		s.txn = nil
+	}
+
+	// 删除临时表
+	for _, tableName := range s.tempTables {
+		_ = s.dataSource.DropTable(ctx, tableName)
+	}
+	s.tempTables = []string{}
+
+	// 清空会话变量
+	s.sessionVars = make(map[string]string)
+	s.userVars = nil
+	s.lastSeqValues = nil
+	s.nextIsolation = ""
+	if s.executor != nil {
+		s.executor.SetSessionVars(s.sessionVars)
+	}
+
+	return nil
+}
+
 // IsClosed 检查是否已关闭
 func (s *CoreSession) IsClosed() bool {
 	s.mu.RLock()
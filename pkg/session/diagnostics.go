@@ -0,0 +1,155 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// 诊断信息级别（SHOW WARNINGS 的 Level 列）
+const (
+	DiagnosticNote    = "Note"
+	DiagnosticWarning = "Warning"
+	DiagnosticError   = "Error"
+)
+
+// maxDiagnostics 诊断区最多保留的条数（与 max_error_count 默认值一致），超出部分只计数
+const maxDiagnostics = 1024
+
+// deprecatedVariables 已弃用的系统变量 -> 替代变量，SET 时产生 1287 警告
+var deprecatedVariables = map[string]string{
+	"tx_isolation": "transaction_isolation",
+	"tx_read_only": "transaction_read_only",
+}
+
+// Diagnostic 诊断区中的一条记录
+type Diagnostic struct {
+	Level   string
+	Code    uint16
+	Message string
+}
+
+// diagnosticsArea 最近一条语句产生的警告和错误
+type diagnosticsArea struct {
+	entries    []Diagnostic
+	warnings   int // 全部级别的条数（@@warning_count），包括超出上限未保留的
+	errorCount int // Error 级别的条数（@@error_count）
+}
+
+func (d *diagnosticsArea) add(level string, code uint16, message string) {
+	d.warnings++
+	if level == DiagnosticError {
+		d.errorCount++
+	}
+	if len(d.entries) < maxDiagnostics {
+		d.entries = append(d.entries, Diagnostic{Level: level, Code: code, Message: message})
+	}
+}
+
+// IsDiagnosticsStatement 是否为 SHOW WARNINGS / SHOW ERRORS / SHOW COUNT(*) WARNINGS|ERRORS，
+// 这类语句读取诊断区而不清空它
+func IsDiagnosticsStatement(sql string) bool {
+	_, sql = ExtractTraceID(sql)
+	sql = strings.TrimSpace(sql)
+	for strings.HasPrefix(sql, "/*") {
+		end := strings.Index(sql, "*/")
+		if end < 0 {
+			return false
+		}
+		sql = strings.TrimSpace(sql[end+2:])
+	}
+	fields := strings.Fields(strings.ToUpper(sql))
+	if len(fields) < 2 || fields[0] != "SHOW" {
+		return false
+	}
+	rest := strings.TrimSuffix(strings.Join(fields[1:], ""), ";")
+	switch rest {
+	case "WARNINGS", "ERRORS", "COUNT(*)WARNINGS", "COUNT(*)ERRORS":
+		return true
+	}
+	return false
+}
+
+// ResetDiagnostics 清空诊断区，每条语句（诊断语句除外）开始执行前调用
+func (s *CoreSession) ResetDiagnostics() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diagnostics = diagnosticsArea{}
+}
+
+// AddDiagnostic 向诊断区追加一条记录
+func (s *CoreSession) AddDiagnostic(level string, code uint16, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.diagnostics.add(level, code, message)
+}
+
+// Diagnostics 返回诊断区中保留的记录
+func (s *CoreSession) Diagnostics() []Diagnostic {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Diagnostic(nil), s.diagnostics.entries...)
+}
+
+// WarningCount 返回最近一条语句产生的诊断条数（@@warning_count）
+func (s *CoreSession) WarningCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.diagnostics.warnings
+}
+
+// ErrorCount 返回最近一条语句产生的错误条数（@@error_count）
+func (s *CoreSession) ErrorCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.diagnostics.errorCount
+}
+
+// warnDeprecatedVarLocked SET 已弃用的变量时产生警告，调用方需持有 s.mu
+func (s *CoreSession) warnDeprecatedVarLocked(name string) {
+	if replacement, ok := deprecatedVariables[name]; ok {
+		s.diagnostics.add(DiagnosticWarning, mysqlerrors.ErrWarnDeprecatedSyntax,
+			fmt.Sprintf("'@@%s' is deprecated and will be removed in a future release. Please use '@@%s' instead", name, replacement))
+	}
+}
+
+// executeShowDiagnostics 执行 SHOW WARNINGS / SHOW ERRORS
+func (s *CoreSession) executeShowDiagnostics(showStmt *parser.ShowStatement) *domain.QueryResult {
+	s.mu.RLock()
+	area := s.diagnostics
+	s.mu.RUnlock()
+
+	errorsOnly := showStmt.Type == "ERRORS"
+	if showStmt.Count {
+		name, count := "@@session.warning_count", area.warnings
+		if errorsOnly {
+			name, count = "@@session.error_count", area.errorCount
+		}
+		return &domain.QueryResult{
+			Columns: []domain.ColumnInfo{{Name: name, Type: "BIGINT"}},
+			Rows:    []domain.Row{{name: int64(count)}},
+			Total:   1,
+		}
+	}
+
+	rows := make([]domain.Row, 0, len(area.entries))
+	for _, d := range area.entries {
+		if errorsOnly && d.Level != DiagnosticError {
+			continue
+		}
+		rows = append(rows, domain.Row{"Level": d.Level, "Code": int64(d.Code), "Message": d.Message})
+	}
+
+	return &domain.QueryResult{
+		Columns: []domain.ColumnInfo{
+			{Name: "Level", Type: "VARCHAR"},
+			{Name: "Code", Type: "INT"},
+			{Name: "Message", Type: "VARCHAR"},
+		},
+		Rows:  rows,
+		Total: int64(len(rows)),
+	}
+}
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDiagnosticsStatement(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SHOW WARNINGS", true},
+		{"  show errors;", true},
+		{"SHOW COUNT(*) WARNINGS", true},
+		{"show count( * ) errors", true},
+		{"/* trace_id=abc */ SHOW WARNINGS", true},
+		{"SHOW TABLES", false},
+		{"SELECT 'SHOW WARNINGS'", false},
+		{"SHOW", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsDiagnosticsStatement(tt.sql), tt.sql)
+	}
+}
+
+func TestDiagnostics_DeprecatedVariable(t *testing.T) {
+	sess := NewCoreSession(&mockDataSource{})
+	ctx := context.Background()
+
+	_, err := sess.ExecuteQuery(ctx, "SET tx_read_only = 0")
+	require.NoError(t, err)
+	require.Equal(t, 1, sess.WarningCount())
+
+	result, err := sess.ExecuteQuery(ctx, "SHOW WARNINGS")
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, DiagnosticWarning, result.Rows[0]["Level"])
+	assert.Equal(t, int64(mysqlerrors.ErrWarnDeprecatedSyntax), result.Rows[0]["Code"])
+
+	sess.ResetDiagnostics()
+	sess.AddDiagnostic(DiagnosticNote, mysqlerrors.ErrUnknown, "note")
+	sess.AddDiagnostic(DiagnosticError, mysqlerrors.ErrNoSuchTable, "Table 't' doesn't exist")
+	assert.Equal(t, 2, sess.WarningCount())
+	assert.Equal(t, 1, sess.ErrorCount())
+
+	result, err = sess.ExecuteQuery(ctx, "SHOW ERRORS")
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, DiagnosticError, result.Rows[0]["Level"])
+	assert.Equal(t, int64(mysqlerrors.ErrNoSuchTable), result.Rows[0]["Code"])
+}
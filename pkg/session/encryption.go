@@ -0,0 +1,25 @@
+package session
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SetColumnDecryption 设置哪些用户可以读取加密列的明文，其他用户查询到的是密文；nil 表示所有用户都可以
+func (s *CoreSession) SetColumnDecryption(allowed func(user string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.columnDecryption = allowed
+}
+
+// ColumnDecryptionContext 当前用户可以读取加密列明文时返回带 domain.WithColumnDecryption 的上下文
+func (s *CoreSession) ColumnDecryptionContext(ctx context.Context) context.Context {
+	s.mu.RLock()
+	allowed, user := s.columnDecryption, s.user
+	s.mu.RUnlock()
+	if allowed == nil || allowed(user) {
+		return domain.WithColumnDecryption(ctx)
+	}
+	return ctx
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"strings"
+	"sync"
+)
+
+// GlobalVariableHook 全局系统变量变更回调
+// SET GLOBAL var = value 时调用，返回错误则 SET 语句失败
+type GlobalVariableHook func(value string) error
+
+// GlobalVariableHooks 全局系统变量变更回调表（变量名不区分大小写）
+// 同一个 DB 的会话共享一张表，不同服务器实例的回调互不影响
+type GlobalVariableHooks struct {
+	mu    sync.RWMutex
+	hooks map[string]GlobalVariableHook
+}
+
+// NewGlobalVariableHooks 创建空的全局系统变量回调表
+func NewGlobalVariableHooks() *GlobalVariableHooks {
+	return &GlobalVariableHooks{hooks: make(map[string]GlobalVariableHook)}
+}
+
+// Register 注册全局系统变量变更回调，同名回调被替换
+// 用于让服务器层在运行时响应 SET GLOBAL，例如调整 max_connections
+func (h *GlobalVariableHooks) Register(name string, hook GlobalVariableHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks[strings.ToLower(name)] = hook
+}
+
+// Unregister 注销全局系统变量变更回调
+func (h *GlobalVariableHooks) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.hooks, strings.ToLower(name))
+}
+
+// apply 调用已注册的回调，未注册的变量直接忽略
+func (h *GlobalVariableHooks) apply(name, value string) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	hook, ok := h.hooks[strings.ToLower(name)]
+	h.mu.RUnlock()
+	if !ok || hook == nil {
+		return nil
+	}
+	return hook(value)
+}
+
+// SetGlobalVariableHooks 设置 SET GLOBAL 时调用的回调表，nil 表示不通知
+func (s *CoreSession) SetGlobalVariableHooks(hooks *GlobalVariableHooks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.globalVarHooks = hooks
+}
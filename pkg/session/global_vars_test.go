@@ -0,0 +1,102 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalVariableHook_SetGlobal(t *testing.T) {
+	var got string
+	hooks := NewGlobalVariableHooks()
+	hooks.Register("Test_Hook_Var", func(value string) error {
+		got = value
+		return nil
+	})
+
+	sess := NewCoreSession(&mockDataSource{})
+	sess.SetGlobalVariableHooks(hooks)
+	_, err := sess.ExecuteQuery(context.Background(), "SET GLOBAL test_hook_var = 42")
+	require.NoError(t, err)
+	assert.Equal(t, "42", got)
+
+	val, ok := sess.GetSessionVar("test_hook_var")
+	assert.True(t, ok)
+	assert.Equal(t, "42", val)
+}
+
+func TestGlobalVariableHook_SessionScopeNotApplied(t *testing.T) {
+	called := false
+	hooks := NewGlobalVariableHooks()
+	hooks.Register("test_hook_session", func(value string) error {
+		called = true
+		return nil
+	})
+
+	sess := NewCoreSession(&mockDataSource{})
+	sess.SetGlobalVariableHooks(hooks)
+	_, err := sess.ExecuteQuery(context.Background(), "SET SESSION test_hook_session = 1")
+	require.NoError(t, err)
+	assert.False(t, called)
+}
+
+func TestGlobalVariableHook_ErrorRejectsSet(t *testing.T) {
+	hooks := NewGlobalVariableHooks()
+	hooks.Register("test_hook_err", func(value string) error {
+		return fmt.Errorf("invalid value %s", value)
+	})
+
+	sess := NewCoreSession(&mockDataSource{})
+	sess.SetGlobalVariableHooks(hooks)
+	_, err := sess.ExecuteQuery(context.Background(), "SET GLOBAL test_hook_err = 7")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value 7")
+
+	_, ok := sess.GetSessionVar("test_hook_err")
+	assert.False(t, ok)
+}
+
+func TestGlobalVariableHook_PerSession(t *testing.T) {
+	calls := 0
+	hooks := NewGlobalVariableHooks()
+	hooks.Register("test_hook_scope", func(value string) error {
+		calls++
+		return nil
+	})
+
+	withHooks := NewCoreSession(&mockDataSource{})
+	withHooks.SetGlobalVariableHooks(hooks)
+	other := NewCoreSession(&mockDataSource{})
+
+	_, err := other.ExecuteQuery(context.Background(), "SET GLOBAL test_hook_scope = 1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, calls)
+
+	_, err = withHooks.ExecuteQuery(context.Background(), "SET GLOBAL test_hook_scope = 1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	hooks.Unregister("TEST_HOOK_SCOPE")
+	_, err = withHooks.ExecuteQuery(context.Background(), "SET GLOBAL test_hook_scope = 2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCoreSession_QuoterFollowsSessionVars(t *testing.T) {
+	sess := NewCoreSession(&mockDataSource{})
+	q := sess.Quoter()
+	assert.Equal(t, "'a\\'b'", q.String("a'b"))
+
+	_, err := sess.ExecuteQuery(context.Background(), "SET NAMES gbk")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(context.Background(), "SET sql_mode = 'NO_BACKSLASH_ESCAPES'")
+	require.NoError(t, err)
+
+	q = sess.Quoter()
+	assert.Equal(t, "gbk", q.Charset)
+	assert.True(t, q.NoBackslashEscapes)
+	assert.Equal(t, "'a''b'", q.String("a'b"))
+}
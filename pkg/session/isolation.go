@@ -0,0 +1,95 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 事务隔离级别（@@transaction_isolation 的取值格式）
+const (
+	IsolationReadUncommitted = "READ-UNCOMMITTED"
+	IsolationReadCommitted   = "READ-COMMITTED"
+	IsolationRepeatableRead  = "REPEATABLE-READ"
+	IsolationSerializable    = "SERIALIZABLE"
+)
+
+// isolationVars 隔离级别的会话变量名（tx_isolation 为旧名）
+var isolationVars = []string{"transaction_isolation", "tx_isolation"}
+
+// NormalizeIsolationLevel 将 "read committed"、"READ_COMMITTED" 等写法规范为 READ-COMMITTED 格式
+func NormalizeIsolationLevel(level string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer(" ", "-", "_", "-").Replace(strings.TrimSpace(level)))
+	switch normalized {
+	case IsolationReadUncommitted, IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable:
+		return normalized, nil
+	}
+	return "", fmt.Errorf("Variable 'transaction_isolation' can't be set to the value of '%s'", level)
+}
+
+// setIsolationVarLocked 处理隔离级别相关的 SET，调用方需持有 s.mu
+// 返回 false 表示不是隔离级别变量
+func (s *CoreSession) setIsolationVarLocked(name, value string) (bool, error) {
+	switch name {
+	case "tx_isolation_one_shot":
+		// SET TRANSACTION ISOLATION LEVEL：仅对下一个事务生效
+		if s.txn != nil {
+			return true, fmt.Errorf("Transaction characteristics can't be changed while a transaction is in progress")
+		}
+		level, err := NormalizeIsolationLevel(value)
+		if err != nil {
+			return true, err
+		}
+		s.nextIsolation = level
+		return true, nil
+	case "transaction_isolation", "tx_isolation":
+		level, err := NormalizeIsolationLevel(value)
+		if err != nil {
+			return true, err
+		}
+		for _, v := range isolationVars {
+			s.sessionVars[v] = level
+			s.recordVarLocked(v, level)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// SetIsolationLevel 设置会话的事务隔离级别，对之后开始的事务生效
+func (s *CoreSession) SetIsolationLevel(level string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.setIsolationVarLocked("transaction_isolation", level); err != nil {
+		return err
+	}
+	if s.executor != nil {
+		s.executor.SetSessionVars(s.sessionVars)
+	}
+	return nil
+}
+
+// IsolationLevel 返回会话的事务隔离级别（未设置时为 REPEATABLE-READ）
+func (s *CoreSession) IsolationLevel() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isolationLocked()
+}
+
+func (s *CoreSession) isolationLocked() string {
+	if level, ok := s.sessionVars["transaction_isolation"]; ok {
+		return level
+	}
+	return IsolationRepeatableRead
+}
+
+// takeTxIsolationLocked 返回下一个事务使用的隔离级别，并清除 SET TRANSACTION 的一次性设置
+// 返回值为数据源使用的格式（如 "READ COMMITTED"）
+func (s *CoreSession) takeTxIsolationLocked() string {
+	level := s.nextIsolation
+	s.nextIsolation = ""
+	if level == "" {
+		level = s.isolationLocked()
+	}
+	return strings.ReplaceAll(level, "-", " ")
+}
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"strings"
+)
+
+// VarQueryLabels 会话变量，SET query_labels = 'app=checkout,team=payments' 设置连接级查询标签
+const VarQueryLabels = "query_labels"
+
+// 查询标签的数量和长度上限，超出的注释不被视为标签
+const (
+	maxQueryLabels      = 32
+	maxQueryLabelKeyLen = 64
+	maxQueryLabelLen    = 256
+)
+
+// ParseQueryLabels 解析语句开头的 /* key=value,... */ 注释中的查询标签，多个注释按顺序合并，
+// 后出现的同名标签覆盖先出现的。/*! */ 可执行注释、/*+ */ 优化器提示以及内容不是标签列表的注释被跳过。
+// 没有标签时返回 nil
+func ParseQueryLabels(sql string) map[string]string {
+	var labels map[string]string
+	rest := sql
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n")
+		if !strings.HasPrefix(rest, "/*") {
+			return labels
+		}
+		end := strings.Index(rest[2:], "*/")
+		if end < 0 {
+			return labels
+		}
+		body := rest[2 : 2+end]
+		rest = rest[2+end+2:]
+		if strings.HasPrefix(body, "!") || strings.HasPrefix(body, "+") {
+			continue
+		}
+		labels = MergeQueryLabels(labels, ParseLabelList(body))
+	}
+}
+
+// ParseLabelList 解析逗号分隔的 key=value 标签列表，值可以用单引号或双引号括起。
+// 任一项不是合法的 key=value、键含有字母数字及 _ . - 以外的字符或超出长度上限时返回 nil
+func ParseLabelList(text string) map[string]string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	parts := strings.Split(text, ",")
+	if len(parts) > maxQueryLabels {
+		return nil
+	}
+	labels := make(map[string]string, len(parts))
+	for _, part := range parts {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil
+		}
+		key = strings.TrimSpace(key)
+		value = unquoteLabel(strings.TrimSpace(value))
+		if !validLabelKey(key) || len(value) > maxQueryLabelLen {
+			return nil
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// MergeQueryLabels 合并两组标签，override 中的同名标签覆盖 base；两者都为空时返回 nil。
+// 不修改参数
+func MergeQueryLabels(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		if len(base) == 0 {
+			return nil
+		}
+		return base
+	}
+	if len(base) == 0 {
+		return override
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// validLabelKey 检查标签键：非空、不超过长度上限、只含字母数字及 _ . -
+func validLabelKey(key string) bool {
+	if key == "" || len(key) > maxQueryLabelKeyLen {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// unquoteLabel 去掉成对的单引号或双引号
+func unquoteLabel(value string) string {
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// ConnectionQueryLabels 返回连接级查询标签：会话变量 query_labels 优先，其次是 SetQueryLabels 的设置
+// （通常来自连接属性）；无效的会话变量被忽略
+func (s *CoreSession) ConnectionQueryLabels() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if v, ok := s.sessionVars[VarQueryLabels]; ok {
+		if labels := ParseLabelList(strings.Trim(strings.TrimSpace(v), `'"`)); labels != nil {
+			return labels
+		}
+	}
+	return s.queryLabels
+}
+
+// SetQueryLabels 设置连接级查询标签，会话变量 query_labels 可以覆盖。map 不会被复制，之后不能修改
+func (s *CoreSession) SetQueryLabels(labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryLabels = labels
+}
+
+// QueryLabels 返回一条语句的查询标签：连接级标签与语句开头注释中的标签合并，语句标签覆盖同名的连接标签
+func (s *CoreSession) QueryLabels(sql string) map[string]string {
+	_, sql = ExtractTraceID(sql)
+	return MergeQueryLabels(s.ConnectionQueryLabels(), ParseQueryLabels(sql))
+}
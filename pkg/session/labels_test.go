@@ -0,0 +1,104 @@
+package session
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+func TestParseQueryLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want map[string]string
+	}{
+		{
+			name: "no comment",
+			sql:  "SELECT 1",
+		},
+		{
+			name: "labels",
+			sql:  "/* app=checkout,request_id=abc */ SELECT 1",
+			want: map[string]string{"app": "checkout", "request_id": "abc"},
+		},
+		{
+			name: "quoted values and spaces",
+			sql:  "/*app='checkout', route = \"/cart/pay\"*/SELECT 1",
+			want: map[string]string{"app": "checkout", "route": "/cart/pay"},
+		},
+		{
+			name: "multiple comments merged",
+			sql:  "/* app=a,team=t */ /* app=b */ SELECT 1",
+			want: map[string]string{"app": "b", "team": "t"},
+		},
+		{
+			name: "hints and executable comments skipped",
+			sql:  "/*+ MAX_EXECUTION_TIME(100) */ /*!80000 app=x */ /* app=checkout */ SELECT 1",
+			want: map[string]string{"app": "checkout"},
+		},
+		{
+			name: "prose comment ignored",
+			sql:  "/* nightly report, see wiki */ SELECT 1",
+		},
+		{
+			name: "invalid key",
+			sql:  "/* app name=x */ SELECT 1",
+		},
+		{
+			name: "comment after statement start",
+			sql:  "SELECT /* app=checkout */ 1",
+		},
+		{
+			name: "unterminated comment",
+			sql:  "/* app=checkout SELECT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseQueryLabels(tt.sql); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQueryLabels(%q) = %v, want %v", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCoreSession_QueryLabels 测试连接级标签与语句标签合并，并随查询上下文传给数据源
+func TestCoreSession_QueryLabels(t *testing.T) {
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type: domain.DataSourceTypeMemory,
+		Name: "test",
+	})
+	ds.Connect(context.Background())
+	defer ds.Close(context.Background())
+
+	sess := NewCoreSession(ds)
+	defer sess.Close(context.Background())
+	sess.SetQueryLabels(map[string]string{"app": "billing", "team": "payments"})
+
+	want := map[string]string{"app": "checkout", "team": "payments", "request_id": "abc"}
+	sql := "/* app=checkout,request_id=abc */ SELECT 1"
+	if got := sess.QueryLabels(sql); !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryLabels = %v, want %v", got, want)
+	}
+
+	ctx, cancel, qc := sess.createQueryContext(context.Background(), sql)
+	defer cancel()
+	if !reflect.DeepEqual(qc.GetStatus().Labels, want) {
+		t.Errorf("status labels = %v, want %v", qc.GetStatus().Labels, want)
+	}
+	if got := domain.QueryLabelsFromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("context labels = %v, want %v", got, want)
+	}
+
+	// 会话变量 query_labels 覆盖 SetQueryLabels 的设置
+	if _, err := sess.ExecuteQuery(context.Background(), "SET query_labels = 'app=reports'"); err != nil {
+		t.Fatalf("SET query_labels: %v", err)
+	}
+	if got := sess.QueryLabels("SELECT 1"); !reflect.DeepEqual(got, map[string]string{"app": "reports"}) {
+		t.Errorf("QueryLabels after SET = %v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseCache_KeptAcrossDDL 解析缓存只保存 AST，DDL 之后缓存的语句按新的表结构执行
+func TestParseCache_KeptAcrossDDL(t *testing.T) {
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "test",
+		Writable: true,
+	})
+	require.NoError(t, ds.Connect(context.Background()))
+
+	sess := NewCoreSession(ds)
+	ctx := context.Background()
+
+	_, err := sess.ExecuteCreate(ctx, "CREATE TABLE pc_items (id INT, name VARCHAR(32))")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(ctx, "SELECT * FROM pc_items")
+	require.NoError(t, err)
+
+	_, err = sess.ExecuteDrop(ctx, "DROP TABLE pc_items")
+	require.NoError(t, err)
+	_, err = sess.ExecuteCreate(ctx, "CREATE TABLE pc_items (id INT, label VARCHAR(32), qty INT)")
+	require.NoError(t, err)
+	_, err = sess.ExecuteInsert(ctx, "INSERT INTO pc_items (id, label, qty) VALUES (1, 'a', 2)", nil)
+	require.NoError(t, err)
+
+	hits := parser.GetParseCache().Stats().Hits
+	result, err := sess.ExecuteQuery(ctx, "SELECT * FROM pc_items")
+	require.NoError(t, err)
+	assert.Equal(t, hits+1, parser.GetParseCache().Stats().Hits)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "a", result.Rows[0]["label"])
+	assert.Len(t, result.Columns, 3)
+}
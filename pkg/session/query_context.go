@@ -9,7 +9,7 @@ import (
 
 // QueryContext 查询上下文,用于追踪和控制查询执行
 type QueryContext struct {
-	QueryID    string             // 查询唯一ID (生成格式: {ThreadID}_{timestamp}_{sequence})
+	QueryID    string             // 查询唯一ID (见 GenerateQueryID)
 	ThreadID   uint32             // 关联的线程ID
 	TraceID    string             // 追踪ID (来自 Session 或 SQL 注释覆盖)
 	SQL        string             // 执行的SQL
@@ -18,6 +18,8 @@ type QueryContext struct {
 	User       string             // 执行该查询的用户
 	Host       string             // 客户端主机地址 (格式: host:port)
 	DB         string             // 当前使用的数据库
+	ConnAttrs  map[string]string  // 客户端的连接属性，只读
+	Labels     map[string]string  // 连接级与语句级的查询标签，只读
 	mu         sync.RWMutex
 	canceled   bool
 	timeout    bool
@@ -70,6 +72,8 @@ type QueryStatus struct {
 	User      string
 	Host      string
 	DB        string
+	ConnAttrs map[string]string
+	Labels    map[string]string
 }
 
 // GetStatus 获取查询状态
@@ -95,6 +99,8 @@ func (qc *QueryContext) GetStatus() QueryStatus {
 		User:      qc.User,
 		Host:      qc.Host,
 		DB:        qc.DB,
+		ConnAttrs: qc.ConnAttrs,
+		Labels:    qc.Labels,
 	}
 }
 
@@ -191,3 +197,21 @@ func (m *QueryContextManager) GetQueryCount() int {
 	defer m.mu.RUnlock()
 	return len(m.queries)
 }
+
+// queryIDKey 上下文中由调用方预先分配的查询ID
+type queryIDKey struct{}
+
+// WithQueryID 指定在 ctx 中执行的语句使用的查询ID，调用方需要在执行前知道查询ID时使用
+// （如在 OK 包中报告）；未指定时 CoreSession 为每次执行生成新的查询ID
+func WithQueryID(ctx context.Context, queryID string) context.Context {
+	if queryID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, queryIDKey{}, queryID)
+}
+
+// queryIDFromContext 返回 WithQueryID 指定的查询ID
+func queryIDFromContext(ctx context.Context) string {
+	queryID, _ := ctx.Value(queryIDKey{}).(string)
+	return queryID
+}
@@ -2,16 +2,16 @@ package session
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
-	"sync/atomic"
-	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
 )
 
 var (
 	// 全局查询注册表单例
 	globalQueryRegistry *QueryRegistry
 	registryOnce        sync.Once
-	querySequence       uint64 // 原子计数器,用于生成唯一查询ID
 )
 
 // GetProcessListForOptimizer 获取进程列表（供 optimizer 使用）
@@ -25,6 +25,7 @@ func GetProcessListForOptimizer() []interface{} {
 		result = append(result, map[string]interface{}{
 			"QueryID":   status.QueryID,
 			"ThreadID":  status.ThreadID,
+			"TraceID":   status.TraceID,
 			"SQL":       status.SQL,
 			"StartTime": status.StartTime,
 			"Duration":  status.Duration,
@@ -32,6 +33,8 @@ func GetProcessListForOptimizer() []interface{} {
 			"User":      status.User,
 			"Host":      status.Host,
 			"DB":        status.DB,
+			"ConnAttrs": status.ConnAttrs,
+			"Labels":    status.Labels,
 		})
 	}
 	return result
@@ -139,12 +142,10 @@ func (r *QueryRegistry) GetQueryCount() int {
 	return len(r.queries)
 }
 
-// GenerateQueryID 生成查询ID
-func GenerateQueryID(threadID uint32) string {
-	timestamp := time.Now().UnixNano()
-	// 原子递增序列号
-	seq := atomic.AddUint64(&querySequence, 1)
-	return fmt.Sprintf("%d_%d_%d", threadID, timestamp, seq)
+// GenerateQueryID 生成查询ID：全局 ID 生成器的 ID 的十进制表示，按生成顺序递增，
+// 与连接 ID、变更事件 ID 出自同一生成器，可以据此关联和排序各处的事件
+func GenerateQueryID() string {
+	return strconv.FormatInt(idgen.Next(), 10)
 }
 
 // KillQueryByThreadID 通过ThreadID取消查询(全局)
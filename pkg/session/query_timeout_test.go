@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -66,7 +67,7 @@ func TestQueryKill(t *testing.T) {
 	sess.SetThreadID(123) // 设置threadID
 
 	// 注册测试查询
-	queryID := GenerateQueryID(123)
+	queryID := GenerateQueryID()
 	ctx, cancel := context.WithCancel(context.Background())
 
 	qc := &QueryContext{
@@ -194,13 +195,18 @@ func TestQueryRegistry(t *testing.T) {
 
 // TestQueryIDGeneration 测试查询ID生成
 func TestQueryIDGeneration(t *testing.T) {
-	id1 := GenerateQueryID(123)
-	id2 := GenerateQueryID(123)
+	id1 := GenerateQueryID()
+	id2 := GenerateQueryID()
 
-	// 验证ID不重复
+	// 验证ID不重复且按生成顺序递增
 	if id1 == id2 {
 		t.Error("QueryIDs should be unique")
 	}
+	n1, err1 := strconv.ParseInt(id1, 10, 64)
+	n2, err2 := strconv.ParseInt(id2, 10, 64)
+	if err1 != nil || err2 != nil || n2 <= n1 {
+		t.Errorf("QueryIDs should be increasing numbers: %s, %s", id1, id2)
+	}
 
 	// 验证ID格式
 	fmt.Printf("Generated QueryID 1: %s\n", id1)
@@ -249,3 +255,41 @@ func TestQueryContextStatus(t *testing.T) {
 		t.Fatalf("Status should be 'canceled' after SetCanceled, got %s", status2.Status)
 	}
 }
+
+// TestQueryContext_ConnAttrs 测试查询状态中带有客户端的连接属性
+func TestQueryContext_ConnAttrs(t *testing.T) {
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type: domain.DataSourceTypeMemory,
+		Name: "test",
+	})
+	ds.Connect(context.Background())
+	defer ds.Close(context.Background())
+
+	sess := NewCoreSession(ds)
+	defer sess.Close(context.Background())
+	attrs := map[string]string{"program_name": "billing-worker"}
+	sess.SetConnectionAttributes(attrs)
+
+	_, cancel, qc := sess.createQueryContext(context.Background(), "SELECT 1")
+	defer cancel()
+	if got := qc.GetStatus().ConnAttrs["program_name"]; got != "billing-worker" {
+		t.Errorf("ConnAttrs[program_name] = %q, want billing-worker", got)
+	}
+}
+
+// TestCreateQueryContext_QueryIDFromContext 测试调用方通过 WithQueryID 指定的查询ID
+func TestCreateQueryContext_QueryIDFromContext(t *testing.T) {
+	sess := NewCoreSession(memory.NewMVCCDataSource(nil))
+
+	_, cancel, qc := sess.createQueryContext(WithQueryID(context.Background(), "42"), "SELECT 1")
+	cancel()
+	if qc.QueryID != "42" {
+		t.Errorf("QueryID = %q, want 42", qc.QueryID)
+	}
+
+	_, cancel, qc = sess.createQueryContext(context.Background(), "SELECT 1")
+	cancel()
+	if _, err := strconv.ParseInt(qc.QueryID, 10, 64); err != nil {
+		t.Errorf("generated QueryID %q is not a number", qc.QueryID)
+	}
+}
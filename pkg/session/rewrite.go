@@ -0,0 +1,41 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// SetRewriteHooks 设置查询改写钩子，钩子通过 RewriteContext 看到当前用户和数据库
+func (s *CoreSession) SetRewriteHooks(hooks *parser.RewriteHooks) {
+	s.mu.Lock()
+	s.publishRewriteContext()
+	s.mu.Unlock()
+	s.adapter.SetRewriteHooks(hooks, func() parser.RewriteContext {
+		return *s.rewriteContext.Load()
+	})
+}
+
+// publishRewriteContext 在用户或当前数据库变化后更新改写钩子看到的会话信息，调用方需持有 s.mu
+func (s *CoreSession) publishRewriteContext() {
+	s.rewriteContext.Store(&parser.RewriteContext{User: s.user, Database: s.currentDB})
+}
+
+// parseStatement 解析待执行的语句，生效的查询改写以 Note 写入诊断区（与 MySQL 查询改写插件的提示一致）
+func (s *CoreSession) parseStatement(sql string) (*parser.ParseResult, error) {
+	result, err := s.adapter.Parse(sql)
+	if err != nil || result == nil {
+		return result, err
+	}
+	for _, trace := range result.Rewrites {
+		var message string
+		if trace.Phase == parser.RewritePhasePreParse {
+			message = fmt.Sprintf("Query rewritten to '%s' by rewrite hook '%s'", trace.SQL, trace.Hook)
+		} else {
+			message = fmt.Sprintf("Statement transformed by rewrite hook '%s'", trace.Hook)
+		}
+		s.AddDiagnostic(DiagnosticNote, mysqlerrors.ErrUnknown, message)
+	}
+	return result, nil
+}
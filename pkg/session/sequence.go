@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+	"strings"
+)
+
+// NextSequenceValue 返回序列的下一个值并记为本会话的 LASTVAL，序列名可以带库名前缀
+func (s *CoreSession) NextSequenceValue(ctx context.Context, name string) (int64, error) {
+	value, err := s.executor.NextSequenceValue(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	key := s.sequenceKey(name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastSeqValues == nil {
+		s.lastSeqValues = make(map[string]int64)
+	}
+	s.lastSeqValues[key] = value
+	return value, nil
+}
+
+// LastSequenceValue 返回本会话最近一次从序列取得的值（LASTVAL），还没有取过时返回 false
+func (s *CoreSession) LastSequenceValue(name string) (int64, bool) {
+	key := s.sequenceKey(name)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.lastSeqValues[key]
+	return value, ok
+}
+
+// SetSequenceValue 设置序列的下一个值（SETVAL），序列没有改变时返回 false
+func (s *CoreSession) SetSequenceValue(ctx context.Context, name string, value int64, used bool) (bool, error) {
+	return s.executor.SetSequenceValue(ctx, name, value, used)
+}
+
+// sequenceKey 返回 LASTVAL 使用的 db.sequence 键，未带库名时使用当前数据库
+func (s *CoreSession) sequenceKey(name string) string {
+	if !strings.Contains(name, ".") {
+		name = s.GetCurrentDB() + "." + name
+	}
+	return strings.ToLower(name)
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"fmt"
-	"log"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 )
 
 var (
@@ -20,6 +21,9 @@ var (
 	SessionGCInterval = time.Minute
 )
 
+// sessionLog 会话模块的结构化日志
+var sessionLog = logging.Module("session")
+
 type SessionMgr struct {
 	driver   SessionDriver
 	stopChan chan struct{}
@@ -94,7 +98,9 @@ func (m *SessionMgr) CreateSession(ctx context.Context, addr string, port string
 		driver:     m.driver,
 	}
 	sess.ThreadID = m.GetThreadId(ctx)
-	sess.TraceID = fmt.Sprintf("%d-%d", sess.ThreadID, time.Now().UnixMilli())
+	sess.ConnectionID = idgen.Next()
+	// 默认追踪ID即连接ID，客户端可通过 SET @trace_id 或 SQL 注释覆盖
+	sess.TraceID = strconv.FormatInt(sess.ConnectionID, 10)
 	err = m.driver.SetThreadId(ctx, sess.ThreadID, sess)
 	if err != nil {
 		return
@@ -120,7 +126,7 @@ func (m *SessionMgr) GetThreadId(ctx context.Context) uint32 {
 		// 检查 context 是否已取消
 		select {
 		case <-ctx.Done():
-			log.Printf("GetThreadId: context cancelled")
+			sessionLog.Warn("GetThreadId: context cancelled")
 			return 0
 		default:
 		}
@@ -136,7 +142,7 @@ func (m *SessionMgr) GetThreadId(ctx context.Context) uint32 {
 	}
 
 	// 所有 ID 都已耗尽，返回 0 表示失败
-	log.Printf("GetThreadId: all thread IDs (1-%d) exhausted", maxAttempts)
+	sessionLog.Error("GetThreadId: all thread IDs exhausted", "max", maxAttempts)
 	return 0
 }
 
@@ -202,12 +208,12 @@ func (m *SessionMgr) GC() (err error) {
 			// Clean up ThreadID mapping before deleting the session
 			if s.ThreadID != 0 {
 				if err := m.DeleteThreadID(ctx, s.ThreadID); err != nil {
-					log.Printf("delete thread ID %d for session %s failed: %v", s.ThreadID, s.ID, err)
+					sessionLog.Warn("delete thread ID failed", "thread_id", s.ThreadID, "session", s.ID, "err", err)
 				}
 			}
 			if err := m.DeleteSession(ctx, s.ID); err != nil {
 				// 删除失败， 打印日志
-				log.Printf("delete session %s failed: %v", s.ID, err)
+				sessionLog.Warn("delete session failed", "session", s.ID, "err", err)
 				continue
 			}
 		}
@@ -229,6 +235,18 @@ type Session struct {
 	SequenceID uint8       `json:"sequence_id"` // Sequence number
 	sequenceMu sync.Mutex  // Mutex for SequenceID
 	APISession interface{} `json:"api_session"` // API layer session (avoid circular import)
+
+	// ClientCapabilities 握手协商的客户端能力标志（COM_SET_OPTION 可切换多语句支持）
+	ClientCapabilities uint32 `json:"client_capabilities"`
+	// MariaDBCapabilities 握手协商的 MariaDB 扩展能力标志（如进度报告）
+	MariaDBCapabilities uint32 `json:"mariadb_capabilities"`
+	// ConnectionID 全局 ID 生成器分配的连接 ID，与查询 ID 出自同一生成器、不会重用；
+	// ThreadID 是协议中的 32 位连接号，连接断开后会被新连接重用
+	ConnectionID int64 `json:"connection_id"`
+	// ConnectionAttributes 握手时客户端发送的连接属性（program_name、_client_version、_os_user 等），只读
+	ConnectionAttributes map[string]string `json:"connection_attributes,omitempty"`
+	// AuthScramble 握手时发送给客户端的认证随机数，COM_CHANGE_USER 的认证响应也基于它计算
+	AuthScramble []byte `json:"-"`
 }
 
 // Get 获取会话值
@@ -265,7 +283,7 @@ func (s *Session) GetTraceID() string {
 // SetVariable 设置会话变量（用于 SET 命令）
 func (s *Session) SetVariable(name string, value interface{}) error {
 	key := "var:" + name
-	log.Printf("设置会话变量: %s = %v", name, value)
+	sessionLog.Debug("设置会话变量", "name", name, "value", value)
 
 	// 拦截 trace_id 变量，同步更新 Session.TraceID
 	if name == "trace_id" {
@@ -324,6 +342,24 @@ func (s *Session) GetAllVariables() (map[string]interface{}, error) {
 	return vars, nil
 }
 
+// ResetState 清除会话级状态（会话变量、预处理语句等），保留当前数据库
+// 用于 COM_RESET_CONNECTION 和 COM_CHANGE_USER
+func (s *Session) ResetState() error {
+	keys, err := s.driver.GetAllKeys(context.Background(), s.ID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == "current_database" {
+			continue
+		}
+		if err := s.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetNextSequenceID gets the next sequence number and increments it
 // Uses mutex for thread-safe increment
 func (s *Session) GetNextSequenceID() uint8 {
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"strings"
+)
+
+// 会话状态跟踪相关的系统变量
+const (
+	// SessionTrackSystemVariablesVar 需要跟踪的系统变量列表（逗号分隔，* 表示全部）
+	SessionTrackSystemVariablesVar = "session_track_system_variables"
+	// SessionTrackSchemaVar 是否跟踪当前数据库的变化
+	SessionTrackSchemaVar = "session_track_schema"
+	// SessionTrackStateChangeVar 是否在会话状态变化时附带 STATE_CHANGE 标记
+	SessionTrackStateChangeVar = "session_track_state_change"
+)
+
+// DefaultSessionTrackSystemVariables MySQL 默认跟踪的系统变量
+const DefaultSessionTrackSystemVariables = "time_zone,autocommit,character_set_client,character_set_results,character_set_connection"
+
+// SystemVariableChange 一次系统变量赋值
+type SystemVariableChange struct {
+	Name  string
+	Value string
+}
+
+// SessionStateChanges 自上次读取以来的会话状态变化，用于 OK 包的会话状态跟踪（CLIENT_SESSION_TRACK）
+type SessionStateChanges struct {
+	SystemVariables []SystemVariableChange // 被赋值的系统变量，按赋值顺序，同名只保留最后一次
+	Schema          string                 // 切换后的当前数据库
+	SchemaChanged   bool                   // 是否执行过 USE / COM_INIT_DB
+	StateChanged    bool                   // session_track_state_change 开启且状态发生了变化
+}
+
+// Empty 是否没有需要报告的变化
+func (c SessionStateChanges) Empty() bool {
+	return len(c.SystemVariables) == 0 && !c.SchemaChanged && !c.StateChanged
+}
+
+// recordVarLocked 记录一次会话变量赋值，调用方需持有 s.mu
+func (s *CoreSession) recordVarLocked(name, value string) {
+	for i, change := range s.stateChanges.SystemVariables {
+		if change.Name == name {
+			s.stateChanges.SystemVariables = append(s.stateChanges.SystemVariables[:i], s.stateChanges.SystemVariables[i+1:]...)
+			break
+		}
+	}
+	s.stateChanges.SystemVariables = append(s.stateChanges.SystemVariables, SystemVariableChange{Name: name, Value: value})
+}
+
+// TakeSessionStateChanges 返回自上次调用以来的会话状态变化并清空记录，
+// 只包含 session_track_system_variables 和 session_track_schema 选中的部分
+func (s *CoreSession) TakeSessionStateChanges() SessionStateChanges {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded := s.stateChanges
+	s.stateChanges = SessionStateChanges{}
+	if recorded.Empty() {
+		return recorded
+	}
+
+	var changes SessionStateChanges
+	tracked, ok := s.sessionVars[SessionTrackSystemVariablesVar]
+	if !ok {
+		tracked = DefaultSessionTrackSystemVariables
+	}
+	trackAll, trackedVars := parseTrackedVariables(tracked)
+	for _, change := range recorded.SystemVariables {
+		if trackAll || trackedVars[change.Name] {
+			changes.SystemVariables = append(changes.SystemVariables, change)
+		}
+	}
+	if recorded.SchemaChanged && trackingEnabled(s.sessionVars, SessionTrackSchemaVar, true) {
+		changes.Schema = recorded.Schema
+		changes.SchemaChanged = true
+	}
+	changes.StateChanged = trackingEnabled(s.sessionVars, SessionTrackStateChangeVar, false)
+	return changes
+}
+
+// parseTrackedVariables 解析 session_track_system_variables 的取值
+func parseTrackedVariables(value string) (all bool, vars map[string]bool) {
+	vars = make(map[string]bool)
+	for _, name := range strings.Split(strings.Trim(value, `'"`), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "*" {
+			return true, nil
+		}
+		if name != "" {
+			vars[name] = true
+		}
+	}
+	return false, vars
+}
+
+// trackingEnabled 读取 ON/OFF 类型的跟踪开关，未设置时返回 def
+func trackingEnabled(vars map[string]string, name string, def bool) bool {
+	value, ok := vars[name]
+	if !ok {
+		return def
+	}
+	switch strings.ToUpper(strings.Trim(strings.TrimSpace(value), `'"`)) {
+	case "ON", "1", "TRUE":
+		return true
+	case "OFF", "0", "FALSE":
+		return false
+	}
+	return def
+}
@@ -0,0 +1,60 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeSessionStateChanges_DefaultTracking(t *testing.T) {
+	sess := NewCoreSession(&mockDataSource{})
+	ctx := context.Background()
+
+	assert.True(t, sess.TakeSessionStateChanges().Empty())
+
+	_, err := sess.ExecuteQuery(ctx, "SET time_zone = '+08:00'")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(ctx, "SET sql_mode = ''")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(ctx, "SET GLOBAL autocommit = 0")
+	require.NoError(t, err)
+
+	changes := sess.TakeSessionStateChanges()
+	require.Len(t, changes.SystemVariables, 1)
+	assert.Equal(t, "time_zone", changes.SystemVariables[0].Name)
+	assert.False(t, changes.SchemaChanged)
+	assert.False(t, changes.StateChanged)
+
+	// 读取后清空
+	assert.True(t, sess.TakeSessionStateChanges().Empty())
+}
+
+func TestTakeSessionStateChanges_TrackedVariables(t *testing.T) {
+	sess := NewCoreSession(&mockDataSource{})
+	ctx := context.Background()
+
+	_, err := sess.ExecuteQuery(ctx, "SET session_track_system_variables = '*'")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(ctx, "SET sql_mode = 'ANSI_QUOTES'")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(ctx, "SET sql_mode = ''")
+	require.NoError(t, err)
+
+	// 同名变量只报告最后一次赋值
+	changes := sess.TakeSessionStateChanges()
+	names := make([]string, 0, len(changes.SystemVariables))
+	for _, v := range changes.SystemVariables {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{"session_track_system_variables", "sql_mode"}, names)
+
+	_, err = sess.ExecuteQuery(ctx, "SET session_track_system_variables = ''")
+	require.NoError(t, err)
+	_, err = sess.ExecuteQuery(ctx, "SET session_track_state_change = ON")
+	require.NoError(t, err)
+	changes = sess.TakeSessionStateChanges()
+	assert.Empty(t, changes.SystemVariables)
+	assert.True(t, changes.StateChanged)
+}
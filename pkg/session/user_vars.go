@@ -0,0 +1,23 @@
+package session
+
+import "strings"
+
+// SetUserVars 设置会话的用户变量（@name），名称不区分大小写；值为 nil 表示 NULL
+func (s *CoreSession) SetUserVars(values map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.userVars == nil {
+		s.userVars = make(map[string]interface{}, len(values))
+	}
+	for name, value := range values {
+		s.userVars[strings.ToLower(name)] = value
+	}
+}
+
+// UserVar 返回用户变量的值，未设置过的变量返回 false（SQL 中读取为 NULL）
+func (s *CoreSession) UserVar(name string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.userVars[strings.ToLower(name)]
+	return value, ok
+}
@@ -0,0 +1,145 @@
+// Package sketch 实现近似聚合使用的概率数据结构：估计基数的 HyperLogLog 与估计分位数的 t-digest
+//
+// 两种草图都可以合并：分别累积各个分区（或各个并行工作者）的数据后再合并，
+// 结果与在一个草图中累积全部数据相同，因此可以用于并行聚合与 GROUP BY 的部分聚合。
+package sketch
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// DefaultPrecision HyperLogLog 的默认精度：2^14 个寄存器，标准误差约 0.81%
+const DefaultPrecision = 14
+
+// HyperLogLog 基数估计草图，标准误差约为 1.04/sqrt(2^precision)
+//
+// 不同的值较少时以哈希值集合保存（此时计数是精确的），
+// 超过寄存器数的 1/32 后转换为稠密的寄存器数组，内存占用固定为 2^precision 字节
+type HyperLogLog struct {
+	precision uint8
+	sparse    map[uint64]struct{}
+	registers []uint8
+}
+
+// NewHyperLogLog 创建 HyperLogLog，precision 的范围为 4 到 18
+func NewHyperLogLog(precision uint8) (*HyperLogLog, error) {
+	if precision < 4 || precision > 18 {
+		return nil, fmt.Errorf("hyperloglog precision must be between 4 and 18, got %d", precision)
+	}
+	return &HyperLogLog{precision: precision, sparse: make(map[uint64]struct{})}, nil
+}
+
+// Hash 计算字符串的 64 位哈希值，供 Add 使用
+func Hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix(h.Sum64())
+}
+
+// mix splitmix64 的终结步骤，FNV 的低位分布不够均匀，打散后再用于选择寄存器
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Add 加入一个值的哈希值
+func (h *HyperLogLog) Add(hash uint64) {
+	if h.registers == nil {
+		h.sparse[hash] = struct{}{}
+		if len(h.sparse) > h.sparseLimit() {
+			h.densify()
+		}
+		return
+	}
+	h.addDense(hash)
+}
+
+// Merge 把 other 合并到 h，两者的精度必须相同
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if other == nil {
+		return nil
+	}
+	if other.precision != h.precision {
+		return fmt.Errorf("cannot merge hyperloglog sketches with precision %d and %d", h.precision, other.precision)
+	}
+	if other.registers == nil {
+		for hash := range other.sparse {
+			h.Add(hash)
+		}
+		return nil
+	}
+	if h.registers == nil {
+		h.densify()
+	}
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Estimate 估计加入过的不同值的个数
+func (h *HyperLogLog) Estimate() uint64 {
+	if h.registers == nil {
+		return uint64(len(h.sparse))
+	}
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(len(h.registers)) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// 小基数时改用线性计数
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// sparseLimit 哈希值集合的最大元素数
+func (h *HyperLogLog) sparseLimit() int {
+	return 1 << (h.precision - 5)
+}
+
+// densify 把哈希值集合转换为寄存器数组
+func (h *HyperLogLog) densify() {
+	h.registers = make([]uint8, 1<<h.precision)
+	for hash := range h.sparse {
+		h.addDense(hash)
+	}
+	h.sparse = nil
+}
+
+// addDense 高 precision 位选择寄存器，寄存器保存其余位中前导零的最大个数加一
+func (h *HyperLogLog) addDense(hash uint64) {
+	idx := hash >> (64 - h.precision)
+	rest := hash<<h.precision | 1<<(h.precision-1) // 保证有一位为 1，rho 不超过 64-precision+1
+	rho := uint8(bits.LeadingZeros64(rest)) + 1
+	if rho > h.registers[idx] {
+		h.registers[idx] = rho
+	}
+}
+
+// alpha HyperLogLog 估计值的偏差修正系数
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
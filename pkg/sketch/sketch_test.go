@@ -0,0 +1,86 @@
+package sketch
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperLogLog_Estimate(t *testing.T) {
+	_, err := NewHyperLogLog(3)
+	assert.Error(t, err)
+
+	h, err := NewHyperLogLog(DefaultPrecision)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		h.Add(Hash(strconv.Itoa(i % 50)))
+	}
+	assert.EqualValues(t, 50, h.Estimate(), "small cardinalities are exact")
+
+	for i := 0; i < 200000; i++ {
+		h.Add(Hash(strconv.Itoa(i)))
+	}
+	assert.InEpsilon(t, 200000, float64(h.Estimate()), 0.03)
+}
+
+func TestHyperLogLog_Merge(t *testing.T) {
+	whole, _ := NewHyperLogLog(12)
+	parts := make([]*HyperLogLog, 4)
+	for i := range parts {
+		parts[i], _ = NewHyperLogLog(12)
+	}
+	for i := 0; i < 50000; i++ {
+		hash := Hash("user-" + strconv.Itoa(i))
+		whole.Add(hash)
+		parts[i%4].Add(hash)
+		parts[(i+1)%4].Add(hash) // 分区之间有重复的值
+	}
+	merged, _ := NewHyperLogLog(12)
+	for _, p := range parts {
+		require.NoError(t, merged.Merge(p))
+	}
+	assert.Equal(t, whole.Estimate(), merged.Estimate())
+
+	other, _ := NewHyperLogLog(10)
+	assert.Error(t, merged.Merge(other))
+}
+
+func TestTDigest_Quantile(t *testing.T) {
+	d := NewTDigest(0)
+	assert.True(t, math.IsNaN(d.Quantile(0.5)))
+
+	for i := 1; i <= 100; i++ {
+		d.Add(float64(i))
+	}
+	assert.InDelta(t, 50.5, d.Quantile(0.5), 0.01)
+	assert.Equal(t, 1.0, d.Quantile(0))
+	assert.Equal(t, 100.0, d.Quantile(1))
+
+	r := rand.New(rand.NewSource(1))
+	d = NewTDigest(DefaultCompression)
+	for i := 0; i < 100000; i++ {
+		d.Add(r.Float64() * 1000)
+	}
+	assert.InDelta(t, 500, d.Quantile(0.5), 10)
+	assert.InDelta(t, 990, d.Quantile(0.99), 2)
+	assert.Less(t, len(d.centroids), 2*DefaultCompression)
+}
+
+func TestTDigest_Merge(t *testing.T) {
+	parts := []*TDigest{NewTDigest(0), NewTDigest(0), NewTDigest(0)}
+	for i := 0; i < 30000; i++ {
+		parts[i%3].Add(float64(i))
+	}
+	merged := NewTDigest(0)
+	for _, p := range parts {
+		merged.Merge(p)
+	}
+	assert.EqualValues(t, 30000, merged.Count())
+	assert.InDelta(t, 15000, merged.Quantile(0.5), 300)
+	assert.InDelta(t, 27000, merged.Quantile(0.9), 300)
+	assert.Equal(t, 29999.0, merged.Quantile(1))
+}
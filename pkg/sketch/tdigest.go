@@ -0,0 +1,147 @@
+package sketch
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultCompression t-digest 的默认压缩参数：质心数大约不超过该值的两倍，
+// 中位数附近的相对误差约 1%，两端的分位数更精确
+const DefaultCompression = 100
+
+// Centroid t-digest 的质心：若干个相邻值的均值与个数
+type Centroid struct {
+	Mean   float64
+	Weight float64
+}
+
+// TDigest 估计分位数的合并式 t-digest
+//
+// 新加入的值先放入缓冲区，缓冲区满或查询时与已有质心一起排序，
+// 按 k1 尺度函数合并相邻的质心：两端的质心很小，中间的质心较大
+type TDigest struct {
+	compression float64
+	centroids   []Centroid
+	buffer      []Centroid
+	count       float64
+	min, max    float64
+}
+
+// NewTDigest 创建 t-digest，compression 不大于 0 时使用 DefaultCompression
+func NewTDigest(compression float64) *TDigest {
+	if compression <= 0 {
+		compression = DefaultCompression
+	}
+	return &TDigest{compression: compression, min: math.Inf(1), max: math.Inf(-1)}
+}
+
+// Add 加入一个值，NaN 被忽略
+func (t *TDigest) Add(x float64) {
+	if math.IsNaN(x) {
+		return
+	}
+	t.add(Centroid{Mean: x, Weight: 1}, x, x)
+}
+
+// Merge 把 other 合并到 t
+func (t *TDigest) Merge(other *TDigest) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	for _, c := range other.centroids {
+		t.add(c, other.min, other.max)
+	}
+	for _, c := range other.buffer {
+		t.add(c, other.min, other.max)
+	}
+}
+
+// Count 加入过的值的个数
+func (t *TDigest) Count() float64 {
+	return t.count
+}
+
+// Quantile 估计分位数 q（0 到 1），没有数据时返回 NaN
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+	n := len(t.centroids)
+	switch {
+	case n == 0:
+		return math.NaN()
+	case q <= 0:
+		return t.min
+	case q >= 1:
+		return t.max
+	case n == 1:
+		return t.centroids[0].Mean
+	}
+
+	// 质心 i 的中心位于累计权重 before_i + weight_i/2，在相邻中心之间线性插值
+	target := q * t.count
+	first, last := t.centroids[0], t.centroids[n-1]
+	if target < first.Weight/2 {
+		return t.min + (first.Mean-t.min)*target/(first.Weight/2)
+	}
+	if target > t.count-last.Weight/2 {
+		return last.Mean + (t.max-last.Mean)*(target-(t.count-last.Weight/2))/(last.Weight/2)
+	}
+	cumulative := first.Weight / 2
+	for i := 0; i < n-1; i++ {
+		left, right := t.centroids[i], t.centroids[i+1]
+		span := (left.Weight + right.Weight) / 2
+		if target <= cumulative+span {
+			return left.Mean + (right.Mean-left.Mean)*(target-cumulative)/span
+		}
+		cumulative += span
+	}
+	return last.Mean
+}
+
+// add 把质心放入缓冲区，缓冲区满时压缩
+func (t *TDigest) add(c Centroid, min, max float64) {
+	t.buffer = append(t.buffer, c)
+	t.count += c.Weight
+	if min < t.min {
+		t.min = min
+	}
+	if max > t.max {
+		t.max = max
+	}
+	if len(t.buffer) >= int(5*t.compression) {
+		t.compress()
+	}
+}
+
+// compress 合并缓冲区与已有质心：相邻质心合并后占用的 k 尺度不超过 1
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.centroids, t.buffer...)
+	t.buffer = t.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].Mean < all[j].Mean })
+
+	merged := make([]Centroid, 0, len(all))
+	cur := all[0]
+	before := 0.0
+	for _, c := range all[1:] {
+		if t.scale((before+cur.Weight+c.Weight)/t.count)-t.scale(before/t.count) <= 1 {
+			total := cur.Weight + c.Weight
+			cur.Mean += (c.Mean - cur.Mean) * c.Weight / total
+			cur.Weight = total
+			continue
+		}
+		before += cur.Weight
+		merged = append(merged, cur)
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+}
+
+// scale k1 尺度函数 δ/(2π)·asin(2q-1)
+func (t *TDigest) scale(q float64) float64 {
+	if q > 1 {
+		q = 1
+	}
+	return t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
@@ -0,0 +1,29 @@
+// Package sqllogictest 以 SQLLogicTest 风格的文本文件描述语句和期望结果，对引擎执行并比较，
+// 用数据驱动的回归用例捕捉 QueryBuilder、优化器和表达式求值的行为变化。
+//
+// 文件由空行分隔的记录组成，# 开头的行为注释：
+//
+//	statement ok
+//	CREATE TABLE t (a INT, b VARCHAR(10))
+//
+//	statement error no such table|doesn't exist
+//	SELECT * FROM missing
+//
+//	query IT rowsort
+//	SELECT a, b FROM t
+//	----
+//	1 x
+//	2 NULL
+//
+// query 后的类型字符串每个字符对应一列：I 整数，R 浮点数（保留 3 位小数），T 文本。
+// 排序方式为 nosort（默认，按返回顺序比较）、rowsort（按行排序后比较）或 valuesort
+// （所有值排序后比较）。期望结果每行一条记录，值之间以空白分隔；NULL 写作 NULL，
+// 空字符串写作 (empty)。statement error 和 query error 后可跟一个匹配错误消息的正则表达式（不区分大小写）。
+//
+// skipif <engine> / onlyif <engine> 放在记录前面按引擎名（本引擎为 sqlexec）跳过或只执行该记录，
+// halt 结束当前文件，hash-threshold 为兼容其他实现的文件而接受并忽略。
+//
+// 仓库中的用例位于 testdata/*.test，由 go test 执行，每个文件使用独立的内存数据库：
+//
+//	go test ./pkg/sqllogictest -run 'TestSuite/join'
+package sqllogictest
@@ -0,0 +1,170 @@
+package sqllogictest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// EngineName skipif / onlyif 中表示本引擎的名称
+const EngineName = "sqlexec"
+
+// SortMode 查询结果的比较顺序
+type SortMode string
+
+const (
+	NoSort    SortMode = "nosort"
+	RowSort   SortMode = "rowsort"
+	ValueSort SortMode = "valuesort"
+)
+
+// Record 文件中的一条 statement 或 query 记录
+type Record struct {
+	Line int    // 记录首行的行号（从 1 开始）
+	SQL  string // 可以跨多行
+
+	Query bool // query 记录，否则为 statement 记录
+
+	// ExpectError 期望执行失败，ErrorPattern 不为 nil 时错误消息还需匹配
+	ExpectError  bool
+	ErrorPattern *regexp.Regexp
+
+	Types    string   // query 的列类型，每个字符一列
+	Sort     SortMode // query 的比较顺序
+	Expected []string // query 的期望结果，每个元素一行
+}
+
+// Pos 返回 "文件:行号" 形式的位置
+func (r Record) Pos(name string) string {
+	return fmt.Sprintf("%s:%d", name, r.Line)
+}
+
+// ParseFile 解析一个测试文件
+func ParseFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(path, f)
+}
+
+// Parse 解析测试文件内容，name 只用于错误消息；被 skipif / onlyif 排除的记录和 halt 之后的内容不返回
+func Parse(name string, r io.Reader) ([]Record, error) {
+	var (
+		records []Record
+		lineNo  int
+		skip    bool
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		lineNo++
+		return strings.TrimRight(scanner.Text(), " \t\r"), true
+	}
+
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		errorf := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%s:%d: %s", name, lineNo, fmt.Sprintf(format, args...))
+		}
+
+		switch fields[0] {
+		case "halt":
+			if skip {
+				skip = false
+				continue
+			}
+			return records, scanner.Err()
+		case "hash-threshold":
+			continue
+		case "skipif", "onlyif":
+			if len(fields) != 2 {
+				return nil, errorf("%s requires an engine name", fields[0])
+			}
+			match := strings.EqualFold(fields[1], EngineName)
+			if (fields[0] == "skipif") == match {
+				skip = true
+			}
+			continue
+		case "statement", "query":
+		default:
+			return nil, errorf("unknown record type %q", fields[0])
+		}
+
+		rec := Record{Line: lineNo, Query: fields[0] == "query", Sort: NoSort}
+		args := fields[1:]
+		if len(args) > 0 && args[0] == "error" {
+			rec.ExpectError = true
+			pattern := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[len(fields[0]):]), "error"))
+			if pattern != "" {
+				re, err := regexp.Compile("(?i)" + pattern)
+				if err != nil {
+					return nil, errorf("invalid error pattern: %v", err)
+				}
+				rec.ErrorPattern = re
+			}
+		} else if rec.Query {
+			if len(args) == 0 {
+				return nil, errorf("query requires column types")
+			}
+			rec.Types = args[0]
+			for _, c := range rec.Types {
+				if c != 'I' && c != 'R' && c != 'T' {
+					return nil, errorf("invalid column type %q", c)
+				}
+			}
+			if len(args) > 1 {
+				rec.Sort = SortMode(args[1])
+				if rec.Sort != NoSort && rec.Sort != RowSort && rec.Sort != ValueSort {
+					return nil, errorf("invalid sort mode %q", args[1])
+				}
+			}
+		} else if len(args) != 1 || args[0] != "ok" {
+			return nil, errorf("statement must be followed by ok or error")
+		}
+
+		// SQL 到空行为止，query 的 SQL 到 ---- 为止，之后到空行为期望结果
+		var sql []string
+		inResult := false
+		for {
+			line, ok := next()
+			if !ok || line == "" {
+				break
+			}
+			if line == "----" && rec.Query && !rec.ExpectError && !inResult {
+				inResult = true
+				continue
+			}
+			if inResult {
+				rec.Expected = append(rec.Expected, strings.Join(strings.Fields(line), " "))
+			} else {
+				sql = append(sql, line)
+			}
+		}
+		if len(sql) == 0 {
+			return nil, fmt.Errorf("%s:%d: record has no SQL", name, rec.Line)
+		}
+		rec.SQL = strings.Join(sql, "\n")
+
+		if skip {
+			skip = false
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,245 @@
+package sqllogictest
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+)
+
+// Executor 执行测试语句的引擎
+type Executor interface {
+	// Execute 执行不返回结果集的语句
+	Execute(sql string) error
+	// Query 执行查询，按列顺序返回每行的值
+	Query(sql string) ([][]interface{}, error)
+}
+
+// sessionExecutor 通过 api.Session 执行
+type sessionExecutor struct {
+	session *api.Session
+}
+
+// NewSessionExecutor 创建在会话上执行语句的 Executor
+func NewSessionExecutor(session *api.Session) Executor {
+	return &sessionExecutor{session: session}
+}
+
+func (e *sessionExecutor) Execute(sql string) error {
+	_, err := e.session.Execute(sql)
+	return err
+}
+
+func (e *sessionExecutor) Query(sql string) ([][]interface{}, error) {
+	q, err := e.session.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	cols := q.Columns()
+	var rows [][]interface{}
+	for q.Next() {
+		row := q.Row()
+		values := make([]interface{}, len(cols))
+		for i, col := range cols {
+			values[i] = row[col.Name]
+		}
+		rows = append(rows, values)
+	}
+	return rows, q.Err()
+}
+
+// Failure 一条未通过的记录
+type Failure struct {
+	Pos     string // 文件:行号
+	SQL     string
+	Message string
+}
+
+func (f Failure) String() string {
+	return fmt.Sprintf("%s: %s\n%s", f.Pos, f.Message, f.SQL)
+}
+
+// Run 按顺序执行记录，返回所有未通过的记录；语句失败不影响后续记录的执行
+func Run(exec Executor, name string, records []Record) []Failure {
+	var failures []Failure
+	for _, rec := range records {
+		if msg := runRecord(exec, rec); msg != "" {
+			failures = append(failures, Failure{Pos: rec.Pos(name), SQL: rec.SQL, Message: msg})
+		}
+	}
+	return failures
+}
+
+func runRecord(exec Executor, rec Record) string {
+	var (
+		rows [][]interface{}
+		err  error
+	)
+	if rec.Query {
+		rows, err = exec.Query(rec.SQL)
+	} else {
+		err = exec.Execute(rec.SQL)
+	}
+
+	if rec.ExpectError {
+		switch {
+		case err == nil:
+			return "expected an error, statement succeeded"
+		case rec.ErrorPattern != nil && !rec.ErrorPattern.MatchString(err.Error()):
+			return fmt.Sprintf("error %q does not match %q", err.Error(), rec.ErrorPattern.String())
+		}
+		return ""
+	}
+	if err != nil {
+		return fmt.Sprintf("unexpected error: %v", err)
+	}
+	if !rec.Query {
+		return ""
+	}
+
+	actual, err := FormatRows(rows, rec.Types, rec.Sort)
+	if err != nil {
+		return err.Error()
+	}
+	if !slices.Equal(actual, rec.Expected) {
+		return fmt.Sprintf("result mismatch\nexpected:\n%s\nactual:\n%s", joinLines(rec.Expected), joinLines(actual))
+	}
+	return ""
+}
+
+// FormatRows 按列类型格式化结果并按排序方式排列，返回与期望结果比较的行
+func FormatRows(rows [][]interface{}, types string, mode SortMode) ([]string, error) {
+	formatted := make([][]string, len(rows))
+	for i, row := range rows {
+		if len(row) != len(types) {
+			return nil, fmt.Errorf("query returned %d columns, types %q declare %d", len(row), types, len(types))
+		}
+		formatted[i] = make([]string, len(row))
+		for j, v := range row {
+			formatted[i][j] = FormatValue(v, types[j])
+		}
+	}
+
+	if mode == ValueSort {
+		var values []string
+		for _, row := range formatted {
+			values = append(values, row...)
+		}
+		sort.Strings(values)
+		return values, nil
+	}
+	lines := make([]string, len(formatted))
+	for i, row := range formatted {
+		lines[i] = strings.Join(row, " ")
+	}
+	if mode == RowSort {
+		sort.Strings(lines)
+	}
+	return lines, nil
+}
+
+// FormatValue 按列类型格式化一个值：I 取整数部分，R 保留 3 位小数，T 为文本；
+// NULL 为 "NULL"，空字符串为 "(empty)"，文本中的空白折叠为一个空格
+func FormatValue(v interface{}, typ byte) string {
+	if v == nil {
+		return "NULL"
+	}
+	switch typ {
+	case 'I':
+		if f, ok := toFloat(v); ok {
+			return strconv.FormatInt(int64(math.Trunc(f)), 10)
+		}
+		return "0"
+	case 'R':
+		if f, ok := toFloat(v); ok {
+			return strconv.FormatFloat(f, 'f', 3, 64)
+		}
+		return "0.000"
+	}
+
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	case bool:
+		s = "0"
+		if x {
+			s = "1"
+		}
+	case float32:
+		s = strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 {
+			s = x.Format("2006-01-02")
+		} else {
+			s = x.Format("2006-01-02 15:04:05")
+		}
+	default:
+		s = fmt.Sprint(x)
+	}
+	if s == "" {
+		return "(empty)"
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// toFloat 把数值、布尔值和数字字符串转换为 float64
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case int8:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case float32:
+		return float64(x), true
+	case float64:
+		return x, true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case []byte:
+		return toFloat(string(x))
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	case fmt.Stringer:
+		return toFloat(x.String())
+	}
+	return 0, false
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return "  (no rows)"
+	}
+	return "  " + strings.Join(lines, "\n  ")
+}
@@ -0,0 +1,174 @@
+package sqllogictest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExecutor 创建只有一个空内存数据源的数据库
+func newExecutor(t *testing.T) Executor {
+	t.Helper()
+	db, err := api.NewDB(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{
+		Type:     domain.DataSourceTypeMemory,
+		Name:     "default",
+		Writable: true,
+	})
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+
+	sess := db.Session()
+	t.Cleanup(func() { sess.Close() })
+	return NewSessionExecutor(sess)
+}
+
+// TestSuite 执行 testdata 下的所有用例文件，每个文件一个子测试
+func TestSuite(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.test"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, path := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".test"), func(t *testing.T) {
+			records, err := ParseFile(path)
+			require.NoError(t, err)
+			for _, f := range Run(newExecutor(t), path, records) {
+				t.Error(f.String())
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	src := `# comment
+hash-threshold 8
+
+statement ok
+CREATE TABLE t (a INT)
+
+statement error no such
+SELECT *
+FROM missing
+
+query IT rowsort
+SELECT a,
+  b FROM t
+----
+1    x
+2 NULL
+
+skipif sqlexec
+query I
+SELECT 1
+----
+1
+
+onlyif sqlexec
+query R valuesort
+SELECT 1.5
+----
+1.500
+
+query error
+SELECT bad
+
+halt
+
+statement ok
+DROP TABLE t
+`
+	records, err := Parse("x.test", strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	assert.Equal(t, 4, records[0].Line)
+	assert.False(t, records[0].Query)
+	assert.Equal(t, "CREATE TABLE t (a INT)", records[0].SQL)
+
+	assert.True(t, records[1].ExpectError)
+	assert.True(t, records[1].ErrorPattern.MatchString("No Such table"))
+	assert.Equal(t, "SELECT *\nFROM missing", records[1].SQL)
+
+	assert.Equal(t, "IT", records[2].Types)
+	assert.Equal(t, RowSort, records[2].Sort)
+	assert.Equal(t, []string{"1 x", "2 NULL"}, records[2].Expected)
+	assert.Equal(t, "x.test:11", records[2].Pos("x.test"))
+
+	assert.Equal(t, "SELECT 1.5", records[3].SQL)
+	assert.Equal(t, ValueSort, records[3].Sort)
+
+	assert.True(t, records[4].Query)
+	assert.True(t, records[4].ExpectError)
+	assert.Nil(t, records[4].ErrorPattern)
+
+	for _, bad := range []string{"select ok\nSELECT 1\n", "query\nSELECT 1\n", "query X\nSELECT 1\n", "query I bogus\nSELECT 1\n", "statement ok\n", "statement maybe\nSELECT 1\n"} {
+		_, err := Parse("bad.test", strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "NULL", FormatValue(nil, 'I'))
+	assert.Equal(t, "3", FormatValue(3.9, 'I'))
+	assert.Equal(t, "-3", FormatValue("-3.9", 'I'))
+	assert.Equal(t, "1", FormatValue(true, 'I'))
+	assert.Equal(t, "2.500", FormatValue(2.5, 'R'))
+	assert.Equal(t, "7.000", FormatValue(uint8(7), 'R'))
+	assert.Equal(t, "(empty)", FormatValue("", 'T'))
+	assert.Equal(t, "a b", FormatValue("a \t b", 'T'))
+	assert.Equal(t, "0.1", FormatValue(0.1, 'T'))
+}
+
+// fakeExecutor 返回固定结果
+type fakeExecutor struct {
+	rows [][]interface{}
+	err  error
+}
+
+func (e *fakeExecutor) Execute(string) error { return e.err }
+func (e *fakeExecutor) Query(string) ([][]interface{}, error) {
+	return e.rows, e.err
+}
+
+func TestRun_Failures(t *testing.T) {
+	records, err := Parse("f.test", strings.NewReader(`query IT rowsort
+SELECT a, b FROM t
+----
+1 x
+2 y
+
+query T valuesort
+SELECT b FROM t
+----
+x
+
+statement error duplicate
+INSERT INTO t VALUES (1)
+`))
+	require.NoError(t, err)
+
+	exec := &fakeExecutor{rows: [][]interface{}{{int64(2), "y"}, {int64(1), "x"}}}
+	failures := Run(exec, "f.test", records)
+	require.Len(t, failures, 2)
+	assert.Equal(t, "f.test:7", failures[0].Pos)
+	assert.Contains(t, failures[0].Message, "columns")
+	assert.Contains(t, failures[1].Message, "expected an error")
+
+	exec.err = errors.New("table t not found")
+	failures = Run(exec, "f.test", records)
+	require.Len(t, failures, 3)
+	assert.Contains(t, failures[0].Message, "unexpected error")
+	assert.Contains(t, failures[2].Message, "does not match")
+}
@@ -0,0 +1,303 @@
+package sysdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// 迁移使用的系统表
+const (
+	TableSchemaMigrations = "schema_migrations" // 已执行的迁移
+	TableMigrationLock    = "migration_lock"    // 迁移锁，最多一行
+)
+
+// 迁移锁的默认参数
+const (
+	DefaultLockTimeout = 30 * time.Second // 等待其他实例释放锁的时间
+	lockTTL            = 5 * time.Minute  // 持有者异常退出后锁自动失效的时间
+	lockPollInterval   = 200 * time.Millisecond
+)
+
+// ErrSchemaTooNew 系统数据库由更新版本的 sqlexec 迁移过，当前版本不认识其中的结构。
+// 继续运行可能覆盖新版本写入的数据，启动时应当拒绝
+var ErrSchemaTooNew = errors.New("system database schema is newer than this sqlexec version")
+
+// ErrMigrationLocked 其他实例正在执行迁移，等待超时
+var ErrMigrationLocked = errors.New("system database migration is locked by another instance")
+
+// Migration 一个结构版本的迁移。版本号从 1 开始连续递增，已发布的迁移不再修改
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, s *SystemDB) error
+}
+
+// migrations 按版本排列的全部迁移
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create users, privileges, datasources, views and events tables",
+		Up: func(ctx context.Context, s *SystemDB) error {
+			return s.createTables(ctx, Tables())
+		},
+	},
+	{
+		Version:     2,
+		Description: "create user_passwords table for password expiry",
+		Up: func(ctx context.Context, s *SystemDB) error {
+			return s.createTables(ctx, []*domain.TableInfo{userPasswordsTable()})
+		},
+	},
+}
+
+// Migrations 返回全部迁移
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// LatestVersion 当前版本的 sqlexec 支持的最高结构版本
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// migrationTables 记录迁移状态的表，不属于任何迁移，打开系统数据库时总是存在
+func migrationTables() []*domain.TableInfo {
+	return []*domain.TableInfo{
+		{Name: TableSchemaMigrations, Columns: []domain.ColumnInfo{
+			idColumn(),
+			{Name: "version", Type: "BIGINT"},
+			textColumn("description"),
+			varcharColumn("applied_at", 32),
+			varcharColumn("applied_by", 255),
+		}},
+		{Name: TableMigrationLock, Columns: []domain.ColumnInfo{
+			idColumn(),
+			varcharColumn("owner", 255),
+			varcharColumn("expires_at", 32),
+		}},
+	}
+}
+
+// AppliedMigration 已执行的迁移
+type AppliedMigration struct {
+	Version     int
+	Description string
+	AppliedAt   string
+	AppliedBy   string
+}
+
+// MigrationStatus 系统数据库的结构版本
+type MigrationStatus struct {
+	Current int                // 已执行的最高版本，全新的系统数据库为 0
+	Latest  int                // 当前版本的 sqlexec 支持的最高版本
+	Applied []AppliedMigration // 按版本排列
+	Pending []Migration        // 尚未执行的迁移
+}
+
+// TooNew 系统数据库的结构版本高于当前版本的 sqlexec
+func (st *MigrationStatus) TooNew() bool {
+	return st.Current > st.Latest
+}
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	DryRun      bool          // 只返回待执行的迁移，不修改系统数据库，也不加锁
+	Owner       string        // 锁的持有者，默认为 主机名:进程号
+	LockTimeout time.Duration // 等待锁的时间，默认为 DefaultLockTimeout
+}
+
+// migrateMu 同一进程内的迁移串行执行；跨进程由 migration_lock 表保证
+var migrateMu sync.Mutex
+
+// Status 读取已执行的迁移，计算待执行的迁移
+func (s *SystemDB) Status(ctx context.Context) (*MigrationStatus, error) {
+	st := &MigrationStatus{Latest: LatestVersion()}
+	if _, err := s.ds.GetTableInfo(ctx, TableSchemaMigrations); err != nil {
+		st.Pending = Migrations()
+		return st, nil
+	}
+	rows, err := s.Rows(ctx, TableSchemaMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(rows))
+	for _, row := range rows {
+		version, err := intValue(row["version"])
+		if err != nil {
+			return nil, fmt.Errorf("system database: invalid migration version %v: %w", row["version"], err)
+		}
+		applied[version] = true
+		st.Applied = append(st.Applied, AppliedMigration{
+			Version:     version,
+			Description: stringValue(row["description"]),
+			AppliedAt:   stringValue(row["applied_at"]),
+			AppliedBy:   stringValue(row["applied_by"]),
+		})
+		if version > st.Current {
+			st.Current = version
+		}
+	}
+	sort.Slice(st.Applied, func(i, j int) bool { return st.Applied[i].Version < st.Applied[j].Version })
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			st.Pending = append(st.Pending, m)
+		}
+	}
+	return st, nil
+}
+
+// Migrate 按版本依次执行待执行的迁移，返回执行（DryRun 时为将要执行）的迁移。
+// 系统数据库的结构版本高于当前版本时返回 ErrSchemaTooNew，不做任何修改
+func (s *SystemDB) Migrate(ctx context.Context, opts MigrateOptions) ([]Migration, error) {
+	st, err := s.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if st.TooNew() {
+		return nil, fmt.Errorf("%w: version %d, supported up to %d", ErrSchemaTooNew, st.Current, st.Latest)
+	}
+	if opts.DryRun || len(st.Pending) == 0 {
+		return st.Pending, nil
+	}
+
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+	if err := s.createTables(ctx, migrationTables()); err != nil {
+		return nil, err
+	}
+	owner := opts.Owner
+	if owner == "" {
+		owner = defaultLockOwner()
+	}
+	if err := s.acquireLock(ctx, owner, opts.LockTimeout); err != nil {
+		return nil, err
+	}
+	defer s.releaseLock(context.Background(), owner)
+
+	// 等待锁期间其他实例可能已经完成迁移，重新读取状态
+	if st, err = s.Status(ctx); err != nil {
+		return nil, err
+	}
+	if st.TooNew() {
+		return nil, fmt.Errorf("%w: version %d, supported up to %d", ErrSchemaTooNew, st.Current, st.Latest)
+	}
+	for i, m := range st.Pending {
+		if err := m.Up(ctx, s); err != nil {
+			return st.Pending[:i], fmt.Errorf("system database: migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		if err := s.insertRows(ctx, TableSchemaMigrations, []domain.Row{{
+			"version":     int64(m.Version),
+			"description": m.Description,
+			"applied_at":  time.Now().UTC().Format(time.RFC3339),
+			"applied_by":  owner,
+		}}); err != nil {
+			return st.Pending[:i], err
+		}
+	}
+	return st.Pending, nil
+}
+
+// acquireLock 在 migration_lock 表中写入锁。锁被其他实例持有且未过期时轮询等待，
+// 写入后重新读取，确认没有被同时写入的其他实例覆盖
+func (s *SystemDB) acquireLock(ctx context.Context, owner string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		holder, err := s.lockHolder(ctx)
+		if err != nil {
+			return err
+		}
+		if holder == "" || holder == owner {
+			if err := s.ReplaceRows(ctx, TableMigrationLock, []domain.Row{{
+				"owner":      owner,
+				"expires_at": time.Now().Add(lockTTL).UTC().Format(time.RFC3339),
+			}}); err != nil {
+				return err
+			}
+			if holder, err = s.lockHolder(ctx); err != nil {
+				return err
+			}
+			if holder == owner {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: held by %s", ErrMigrationLocked, holder)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// lockHolder 返回未过期的锁的持有者，没有锁时返回空字符串
+func (s *SystemDB) lockHolder(ctx context.Context) (string, error) {
+	rows, err := s.Rows(ctx, TableMigrationLock)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for _, row := range rows {
+		expires, err := time.Parse(time.RFC3339, stringValue(row["expires_at"]))
+		if err == nil && expires.After(now) {
+			return stringValue(row["owner"]), nil
+		}
+	}
+	return "", nil
+}
+
+// releaseLock 释放自己持有的锁
+func (s *SystemDB) releaseLock(ctx context.Context, owner string) {
+	if holder, err := s.lockHolder(ctx); err == nil && holder == owner {
+		_ = s.ReplaceRows(ctx, TableMigrationLock, nil)
+	}
+}
+
+func defaultLockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+func intValue(v interface{}) (int, error) {
+	switch x := v.(type) {
+	case int:
+		return x, nil
+	case int32:
+		return int(x), nil
+	case int64:
+		return int(x), nil
+	case uint64:
+		return int(x), nil
+	case float64:
+		return int(x), nil
+	case string:
+		return strconv.Atoi(x)
+	}
+	return 0, fmt.Errorf("unexpected type %T", v)
+}
+
+func stringValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
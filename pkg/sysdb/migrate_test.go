@@ -0,0 +1,184 @@
+package sysdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func connectMemory(t *testing.T) *SystemDB {
+	t.Helper()
+	ds, err := NewStorage(StorageMemory, "")
+	require.NoError(t, err)
+	sys, err := Connect(context.Background(), ds)
+	require.NoError(t, err)
+	return sys
+}
+
+// withMigrations 在测试期间替换迁移列表
+func withMigrations(t *testing.T, ms []Migration) {
+	t.Helper()
+	saved := migrations
+	migrations = ms
+	t.Cleanup(func() { migrations = saved })
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+
+	st, err := sys.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, st.Current)
+	assert.Equal(t, LatestVersion(), st.Latest)
+	assert.Len(t, st.Pending, len(Migrations()))
+
+	applied, err := sys.Migrate(ctx, MigrateOptions{Owner: "test"})
+	require.NoError(t, err)
+	assert.Len(t, applied, len(Migrations()))
+
+	st, err = sys.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, LatestVersion(), st.Current)
+	assert.Empty(t, st.Pending)
+	require.Len(t, st.Applied, len(Migrations()))
+	assert.Equal(t, "test", st.Applied[0].AppliedBy)
+	assert.NotEmpty(t, st.Applied[0].AppliedAt)
+
+	// 锁已释放，再次迁移无事可做
+	holder, err := sys.lockHolder(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, holder)
+	applied, err = sys.Migrate(ctx, MigrateOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestMigrate_DryRun(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+
+	pending, err := sys.Migrate(ctx, MigrateOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, pending, len(Migrations()))
+
+	tables, err := sys.DataSource().GetTables(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tables)
+}
+
+func TestMigrate_AppliesOnlyPending(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+	_, err := sys.Migrate(ctx, MigrateOptions{})
+	require.NoError(t, err)
+
+	var ran []int
+	next := LatestVersion() + 1
+	withMigrations(t, append(Migrations(), Migration{
+		Version:     next,
+		Description: "add audit table",
+		Up: func(ctx context.Context, s *SystemDB) error {
+			ran = append(ran, next)
+			return s.createTables(ctx, []*domain.TableInfo{{Name: "audit", Columns: []domain.ColumnInfo{idColumn()}}})
+		},
+	}))
+
+	pending, err := sys.Migrate(ctx, MigrateOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, next, pending[0].Version)
+	assert.Empty(t, ran)
+
+	applied, err := sys.Migrate(ctx, MigrateOptions{})
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, []int{next}, ran)
+	_, err = sys.DataSource().GetTableInfo(ctx, "audit")
+	assert.NoError(t, err)
+}
+
+func TestMigrate_FailedMigrationIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+	next := LatestVersion() + 1
+	withMigrations(t, append(Migrations(), Migration{
+		Version:     next,
+		Description: "broken",
+		Up:          func(context.Context, *SystemDB) error { return errors.New("boom") },
+	}))
+
+	applied, err := sys.Migrate(ctx, MigrateOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("migration %d", next))
+	assert.Len(t, applied, len(Migrations())-1)
+
+	st, err := sys.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, next-1, st.Current)
+	require.Len(t, st.Pending, 1)
+	assert.Equal(t, next, st.Pending[0].Version)
+}
+
+func TestMigrate_RefusesDowngrade(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+	_, err := sys.Migrate(ctx, MigrateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, sys.insertRows(ctx, TableSchemaMigrations, []domain.Row{
+		{"version": int64(LatestVersion() + 1), "description": "from a newer release"},
+	}))
+
+	st, err := sys.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, st.TooNew())
+
+	_, err = sys.Migrate(ctx, MigrateOptions{DryRun: true})
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+	_, err = Open(ctx, sys.DataSource())
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+}
+
+func TestMigrate_WaitsForLock(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+	require.NoError(t, sys.createTables(ctx, migrationTables()))
+	require.NoError(t, sys.ReplaceRows(ctx, TableMigrationLock, []domain.Row{
+		{"owner": "other:1", "expires_at": time.Now().Add(time.Minute).UTC().Format(time.RFC3339)},
+	}))
+
+	_, err := sys.Migrate(ctx, MigrateOptions{Owner: "me:2", LockTimeout: 300 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrMigrationLocked)
+	st, err := sys.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, st.Current)
+
+	// 过期的锁视为已释放
+	require.NoError(t, sys.ReplaceRows(ctx, TableMigrationLock, []domain.Row{
+		{"owner": "other:1", "expires_at": time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)},
+	}))
+	_, err = sys.Migrate(ctx, MigrateOptions{Owner: "me:2", LockTimeout: 300 * time.Millisecond})
+	require.NoError(t, err)
+	st, err = sys.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, LatestVersion(), st.Current)
+}
+
+func TestMigrate_ExistingTablesWithoutHistory(t *testing.T) {
+	ctx := context.Background()
+	sys := connectMemory(t)
+	require.NoError(t, sys.createTables(ctx, Tables()))
+	require.NoError(t, sys.ReplaceRows(ctx, TableUsers, []domain.Row{{"host": "%", "user": "root"}}))
+
+	_, err := sys.Migrate(ctx, MigrateOptions{})
+	require.NoError(t, err)
+	rows, err := sys.Rows(ctx, TableUsers)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+}
@@ -0,0 +1,225 @@
+// Package sysdb 实现系统数据库 sqlexec：用户、权限、数据源、视图和事件等配置与元数据
+// 保存在存储引擎的普通表中，可以用 SQL 查询，并随存储引擎一起备份
+package sysdb
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	badgerds "github.com/kasuganosora/sqlexec/pkg/resource/badger"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+)
+
+// DatabaseName 系统数据库名
+const DatabaseName = "sqlexec"
+
+// 系统表名
+const (
+	TableUsers       = "users"
+	TablePrivileges  = "privileges"
+	TableDatasources = "datasources"
+	TableViews       = "views"
+	TableEvents      = "events"
+
+	TableUserPasswords = "user_passwords" // 用户密码的修改时间，用于密码过期检查
+)
+
+// 存储方式
+const (
+	StorageBadger = "badger" // 持久化到磁盘（默认）
+	StorageMemory = "memory" // 仅保存在内存中，重启后丢失，用于测试
+)
+
+// idColumn 自增主键，存储引擎按主键保存行
+func idColumn() domain.ColumnInfo {
+	return domain.ColumnInfo{Name: "id", Type: "BIGINT", Primary: true, AutoIncrement: true}
+}
+
+func varcharColumn(name string, size int) domain.ColumnInfo {
+	return domain.ColumnInfo{Name: name, Type: fmt.Sprintf("VARCHAR(%d)", size), Default: ""}
+}
+
+func textColumn(name string) domain.ColumnInfo {
+	return domain.ColumnInfo{Name: name, Type: "TEXT", Nullable: true}
+}
+
+// Tables 返回系统表的定义
+func Tables() []*domain.TableInfo {
+	return []*domain.TableInfo{
+		{Name: TableUsers, Columns: []domain.ColumnInfo{
+			idColumn(),
+			varcharColumn("host", 255),
+			varcharColumn("user", 32),
+			varcharColumn("password", 255),
+			textColumn("privileges"), // 全局权限，逗号分隔
+		}},
+		{Name: TablePrivileges, Columns: []domain.ColumnInfo{
+			idColumn(),
+			varcharColumn("host", 255),
+			varcharColumn("user", 32),
+			varcharColumn("level", 16), // database、table 或 column
+			varcharColumn("db", 64),
+			varcharColumn("table_name", 64),
+			varcharColumn("column_name", 64),
+			varcharColumn("grantor", 288),
+			varcharColumn("granted_at", 32),
+			textColumn("privileges"), // 逗号分隔
+		}},
+		{Name: TableDatasources, Columns: []domain.ColumnInfo{
+			idColumn(),
+			varcharColumn("name", 64),
+			varcharColumn("type", 32),
+			{Name: "writable", Type: "BOOLEAN"},
+			textColumn("config"), // 完整的数据源配置（JSON）
+		}},
+		{Name: TableViews, Columns: []domain.ColumnInfo{
+			idColumn(),
+			varcharColumn("db", 64),
+			varcharColumn("name", 64),
+			textColumn("definition"),
+			varcharColumn("definer", 288),
+			varcharColumn("security_type", 16),
+			varcharColumn("check_option", 16),
+			{Name: "is_updatable", Type: "BOOLEAN"},
+		}},
+		{Name: TableEvents, Columns: []domain.ColumnInfo{
+			idColumn(),
+			varcharColumn("db", 64),
+			varcharColumn("name", 64),
+			varcharColumn("definer", 288),
+			varcharColumn("schedule", 255),
+			textColumn("body"),
+			varcharColumn("status", 16), // ENABLED 或 DISABLED
+			varcharColumn("created", 32),
+		}},
+	}
+}
+
+// userPasswordsTable 返回 user_passwords 表的定义（结构版本 2）
+func userPasswordsTable() *domain.TableInfo {
+	return &domain.TableInfo{Name: TableUserPasswords, Columns: []domain.ColumnInfo{
+		idColumn(),
+		varcharColumn("host", 255),
+		varcharColumn("user", 32),
+		varcharColumn("changed_at", 32), // RFC 3339，UTC
+	}}
+}
+
+// NewStorage 创建保存系统数据库的数据源：badger 保存在 dataDir 下，memory 不持久化
+func NewStorage(storage, dataDir string) (domain.DataSource, error) {
+	cfg := &domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: DatabaseName, Writable: true}
+	switch strings.ToLower(storage) {
+	case "", StorageBadger:
+		if dataDir == "" {
+			return nil, fmt.Errorf("system database: data_dir is required for badger storage")
+		}
+		cfg.Type = domain.DataSourceType(StorageBadger)
+		return badgerds.NewBadgerDataSourceWithConfig(cfg, badgerds.DefaultDataSourceConfig(filepath.Clean(dataDir))), nil
+	case StorageMemory:
+		return memory.NewMVCCDataSource(cfg), nil
+	}
+	return nil, fmt.Errorf("system database: unsupported storage %q (badger, memory)", storage)
+}
+
+// SystemDB 系统数据库。每张系统表由对应的模块整体读写，写入时替换表中的全部行；id 列由存储引擎生成
+type SystemDB struct {
+	ds domain.DataSource
+	mu sync.Mutex
+}
+
+// Open 连接数据源并执行待执行的迁移，创建或升级系统表。
+// 系统表的结构版本高于当前版本时返回 ErrSchemaTooNew
+func Open(ctx context.Context, ds domain.DataSource) (*SystemDB, error) {
+	s, err := Connect(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Migrate(ctx, MigrateOptions{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Connect 连接数据源但不执行迁移，用于查看迁移状态
+func Connect(ctx context.Context, ds domain.DataSource) (*SystemDB, error) {
+	if !ds.IsConnected() {
+		if err := ds.Connect(ctx); err != nil {
+			return nil, fmt.Errorf("system database: %w", err)
+		}
+	}
+	return &SystemDB{ds: ds}, nil
+}
+
+// createTables 创建数据源中缺少的表
+func (s *SystemDB) createTables(ctx context.Context, tables []*domain.TableInfo) error {
+	for _, table := range tables {
+		if _, err := s.ds.GetTableInfo(ctx, table.Name); err == nil {
+			continue
+		}
+		if err := s.ds.CreateTable(ctx, table); err != nil {
+			return fmt.Errorf("system database: create table %s: %w", table.Name, err)
+		}
+	}
+	return nil
+}
+
+// DataSource 返回保存系统数据库的数据源，注册后可以通过 SQL 查询系统表
+func (s *SystemDB) DataSource() domain.DataSource {
+	return s.ds
+}
+
+// Close 关闭数据源
+func (s *SystemDB) Close(ctx context.Context) error {
+	return s.ds.Close(ctx)
+}
+
+// Rows 返回系统表的全部行
+func (s *SystemDB) Rows(ctx context.Context, table string) ([]domain.Row, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, err := s.ds.Query(ctx, table, &domain.QueryOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("system database: read %s: %w", table, err)
+	}
+	return result.Rows, nil
+}
+
+// ReplaceRows 用 rows 替换系统表的全部行
+func (s *SystemDB) ReplaceRows(ctx context.Context, table string, rows []domain.Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ds.TruncateTable(ctx, table); err != nil {
+		return fmt.Errorf("system database: clear %s: %w", table, err)
+	}
+	return s.insertRowsLocked(ctx, table, rows)
+}
+
+// insertRows 向系统表追加行
+func (s *SystemDB) insertRows(ctx context.Context, table string, rows []domain.Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insertRowsLocked(ctx, table, rows)
+}
+
+func (s *SystemDB) insertRowsLocked(ctx context.Context, table string, rows []domain.Row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if _, err := s.ds.Insert(ctx, table, rows, &domain.InsertOptions{}); err != nil {
+		return fmt.Errorf("system database: write %s: %w", table, err)
+	}
+	return nil
+}
+
+// IsEmpty 系统表中没有任何行
+func (s *SystemDB) IsEmpty(ctx context.Context, table string) (bool, error) {
+	rows, err := s.Rows(ctx, table)
+	if err != nil {
+		return false, err
+	}
+	return len(rows) == 0, nil
+}
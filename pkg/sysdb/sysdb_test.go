@@ -0,0 +1,97 @@
+package sysdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemDB_PersistsAcrossRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	ds, err := NewStorage(StorageBadger, dir)
+	require.NoError(t, err)
+	sys, err := Open(ctx, ds)
+	require.NoError(t, err)
+	tables, err := ds.GetTables(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{TableUsers, TablePrivileges, TableDatasources, TableViews, TableEvents,
+		TableUserPasswords, TableSchemaMigrations, TableMigrationLock}, tables)
+
+	empty, err := sys.IsEmpty(ctx, TableUsers)
+	require.NoError(t, err)
+	assert.True(t, empty)
+	require.NoError(t, sys.ReplaceRows(ctx, TableUsers, []domain.Row{
+		{"host": "%", "user": "root", "password": "", "privileges": "SELECT,INSERT"},
+		{"host": "localhost", "user": "app", "password": "*hash", "privileges": ""},
+	}))
+	require.NoError(t, sys.ReplaceRows(ctx, TableUsers, []domain.Row{
+		{"host": "%", "user": "root", "password": "", "privileges": "SELECT"},
+	}))
+	require.NoError(t, sys.Close(ctx))
+
+	ds, err = NewStorage(StorageBadger, dir)
+	require.NoError(t, err)
+	sys, err = Open(ctx, ds)
+	require.NoError(t, err)
+	defer sys.Close(ctx)
+	rows, err := sys.Rows(ctx, TableUsers)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "root", rows[0]["user"])
+	assert.Equal(t, "SELECT", rows[0]["privileges"])
+
+	require.NoError(t, sys.ReplaceRows(ctx, TableUsers, nil))
+	empty, err = sys.IsEmpty(ctx, TableUsers)
+	require.NoError(t, err)
+	assert.True(t, empty)
+}
+
+func TestNewStorage(t *testing.T) {
+	ds, err := NewStorage(StorageMemory, "")
+	require.NoError(t, err)
+	assert.Equal(t, DatabaseName, ds.GetConfig().Name)
+
+	_, err = NewStorage(StorageBadger, "")
+	assert.Error(t, err)
+	_, err = NewStorage("sqlite", "x")
+	assert.Error(t, err)
+}
+
+func TestSystemDB_SyncViews(t *testing.T) {
+	ctx := context.Background()
+	ds, err := NewStorage(StorageMemory, "")
+	require.NoError(t, err)
+	sys, err := Open(ctx, ds)
+	require.NoError(t, err)
+
+	app := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "app", Writable: true})
+	require.NoError(t, app.Connect(ctx))
+	require.NoError(t, app.CreateTable(ctx, &domain.TableInfo{Name: "orders", Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}}}))
+	require.NoError(t, app.CreateTable(ctx, &domain.TableInfo{
+		Name:    "big_orders",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT"}},
+		Atts: map[string]interface{}{domain.ViewMetaKey: domain.ViewInfo{
+			SelectStmt:  "SELECT id FROM orders WHERE id > 100",
+			Definer:     "'root'@'%'",
+			Security:    domain.ViewSecurityDefiner,
+			CheckOption: domain.ViewCheckOptionNone,
+			Updatable:   true,
+		}},
+	}))
+
+	require.NoError(t, sys.SyncViews(ctx, map[string]domain.DataSource{"app": app, DatabaseName: ds}))
+	rows, err := sys.Rows(ctx, TableViews)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "app", rows[0]["db"])
+	assert.Equal(t, "big_orders", rows[0]["name"])
+	assert.Equal(t, "SELECT id FROM orders WHERE id > 100", rows[0]["definition"])
+	assert.Equal(t, "DEFINER", rows[0]["security_type"])
+	assert.Equal(t, true, rows[0]["is_updatable"])
+}
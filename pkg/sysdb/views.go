@@ -0,0 +1,69 @@
+package sysdb
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// SyncViews 从各数据源的表元数据中收集视图定义，写入 views 表。
+// 视图定义保存在所属数据源中，views 表是它们的汇总，用于查询和备份
+func (s *SystemDB) SyncViews(ctx context.Context, sources map[string]domain.DataSource) error {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		if name != DatabaseName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var rows []domain.Row
+	for _, name := range names {
+		ds := sources[name]
+		tables, err := ds.GetTables(ctx)
+		if err != nil {
+			continue
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			info, err := ds.GetTableInfo(ctx, table)
+			if err != nil || info.Atts == nil {
+				continue
+			}
+			view, ok := viewInfo(info.Atts[domain.ViewMetaKey])
+			if !ok {
+				continue
+			}
+			rows = append(rows, domain.Row{
+				"db":            name,
+				"name":          table,
+				"definition":    view.SelectStmt,
+				"definer":       view.Definer,
+				"security_type": string(view.Security),
+				"check_option":  string(view.CheckOption),
+				"is_updatable":  view.Updatable,
+			})
+		}
+	}
+	return s.ReplaceRows(ctx, TableViews, rows)
+}
+
+// viewInfo 解析表属性中的视图元数据，可能是 domain.ViewInfo 或 JSON 字符串
+func viewInfo(v interface{}) (domain.ViewInfo, bool) {
+	switch x := v.(type) {
+	case domain.ViewInfo:
+		return x, true
+	case *domain.ViewInfo:
+		if x != nil {
+			return *x, true
+		}
+	case string:
+		var info domain.ViewInfo
+		if err := json.Unmarshal([]byte(x), &info); err == nil {
+			return info, true
+		}
+	}
+	return domain.ViewInfo{}, false
+}
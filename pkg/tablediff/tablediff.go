@@ -0,0 +1,225 @@
+// Package tablediff 按主键比较两个表的数据：逐条报告左表有而右表没有的行（missing）、
+// 右表有而左表没有的行（extra）以及两边都有但列值不同的行（changed，每个不同的列一条），
+// 最后返回汇总计数。用于校验数据迁移与物化结果
+package tablediff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// Kind 差异类型
+type Kind string
+
+const (
+	KindMissing Kind = "missing" // 左表有、右表没有的行
+	KindExtra   Kind = "extra"   // 右表有、左表没有的行
+	KindChanged Kind = "changed" // 两边都有但列值不同
+)
+
+// Difference 一条差异。missing / extra 的 Column 为空，Left / Right 为整行（缺少的一边为 nil）；
+// changed 的 Column 为不同的列，Left / Right 为两边的列值
+type Difference struct {
+	Kind   Kind                   `json:"kind"`
+	Key    map[string]interface{} `json:"key"`
+	Column string                 `json:"column,omitempty"`
+	Left   interface{}            `json:"left"`
+	Right  interface{}            `json:"right"`
+}
+
+// Summary 比较的汇总计数，Changed 为至少一列不同的行数
+type Summary struct {
+	LeftRows  int64    `json:"left_rows"`
+	RightRows int64    `json:"right_rows"`
+	Matched   int64    `json:"matched"`
+	Missing   int64    `json:"missing"`
+	Extra     int64    `json:"extra"`
+	Changed   int64    `json:"changed"`
+	Columns   []string `json:"columns"`           // 参与比较的列（两边都有的非主键列）
+	Ignored   []string `json:"ignored,omitempty"` // 只有一边有、未参与比较的列
+}
+
+// Identical 两个表的数据是否一致
+func (s *Summary) Identical() bool {
+	return s.Missing == 0 && s.Extra == 0 && s.Changed == 0
+}
+
+// String 返回一行文字的汇总
+func (s *Summary) String() string {
+	return fmt.Sprintf("%d left rows, %d right rows: %d matched, %d missing, %d extra, %d changed",
+		s.LeftRows, s.RightRows, s.Matched, s.Missing, s.Extra, s.Changed)
+}
+
+// Compare 按 key 列比较 left 与 right，每条差异依次交给 emit：先按左表的行序报告 missing 与 changed，
+// 再按右表的行序报告 extra。列名不区分大小写；数值按值比较（1 与 1.0 相同），NULL 只与 NULL 相同。
+// 主键重复或为 NULL 时返回错误；emit 返回错误时停止比较并返回该错误
+func Compare(left, right *domain.QueryResult, key []string, emit func(Difference) error) (*Summary, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("no key columns to match rows by")
+	}
+	leftCols, rightCols := columnNames(left), columnNames(right)
+	keyCols := make(map[string]bool, len(key))
+	for _, k := range key {
+		if !containsFold(leftCols, k) || !containsFold(rightCols, k) {
+			return nil, fmt.Errorf("key column '%s' must exist in both tables", k)
+		}
+		keyCols[strings.ToLower(k)] = true
+	}
+
+	summary := &Summary{LeftRows: int64(len(left.Rows)), RightRows: int64(len(right.Rows))}
+	for _, col := range leftCols {
+		switch {
+		case keyCols[strings.ToLower(col)]:
+		case containsFold(rightCols, col):
+			summary.Columns = append(summary.Columns, col)
+		default:
+			summary.Ignored = append(summary.Ignored, col)
+		}
+	}
+	for _, col := range rightCols {
+		if !keyCols[strings.ToLower(col)] && !containsFold(leftCols, col) {
+			summary.Ignored = append(summary.Ignored, col)
+		}
+	}
+
+	index := make(map[string]int, len(right.Rows))
+	for i, row := range right.Rows {
+		k, err := rowKey(row, key)
+		if err != nil {
+			return nil, fmt.Errorf("right table: %w", err)
+		}
+		if _, dup := index[k]; dup {
+			return nil, fmt.Errorf("right table: duplicate key %s", k)
+		}
+		index[k] = i
+	}
+
+	matched := make([]bool, len(right.Rows))
+	seen := make(map[string]bool, len(left.Rows))
+	for _, row := range left.Rows {
+		k, err := rowKey(row, key)
+		if err != nil {
+			return nil, fmt.Errorf("left table: %w", err)
+		}
+		if seen[k] {
+			return nil, fmt.Errorf("left table: duplicate key %s", k)
+		}
+		seen[k] = true
+
+		i, ok := index[k]
+		if !ok {
+			summary.Missing++
+			if err := emit(Difference{Kind: KindMissing, Key: keyValues(row, key), Left: row}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		matched[i] = true
+		changed := false
+		for _, col := range summary.Columns {
+			l, r := valueFold(row, col), valueFold(right.Rows[i], col)
+			if Equal(l, r) {
+				continue
+			}
+			changed = true
+			if err := emit(Difference{Kind: KindChanged, Key: keyValues(row, key), Column: col, Left: l, Right: r}); err != nil {
+				return nil, err
+			}
+		}
+		if changed {
+			summary.Changed++
+		} else {
+			summary.Matched++
+		}
+	}
+
+	for i, row := range right.Rows {
+		if matched[i] {
+			continue
+		}
+		summary.Extra++
+		if err := emit(Difference{Kind: KindExtra, Key: keyValues(row, key), Right: row}); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// Equal 判断两个列值是否相同：NULL 只与 NULL 相同，数值按值比较，其余按文本比较
+func Equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if eq, err := utils.CompareValues(a, b, "="); err == nil {
+		return eq
+	}
+	return utils.ToString(a) == utils.ToString(b)
+}
+
+// rowKey 主键值的规范化文本，不同数据源返回的 1、int64(1) 与 1.0 得到相同的键
+func rowKey(row domain.Row, key []string) (string, error) {
+	parts := make([]string, len(key))
+	for i, col := range key {
+		v := valueFold(row, col)
+		if v == nil {
+			return "", fmt.Errorf("key column '%s' is NULL", col)
+		}
+		if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			v = int64(f)
+		}
+		parts[i] = fmt.Sprintf("%q", utils.ToString(v))
+	}
+	return "(" + strings.Join(parts, ", ") + ")", nil
+}
+
+// keyValues 行的主键列值
+func keyValues(row domain.Row, key []string) map[string]interface{} {
+	values := make(map[string]interface{}, len(key))
+	for _, col := range key {
+		values[col] = valueFold(row, col)
+	}
+	return values
+}
+
+// columnNames 结果集的列名；没有列信息时取第一行的键并排序
+func columnNames(result *domain.QueryResult) []string {
+	names := make([]string, 0, len(result.Columns))
+	for _, col := range result.Columns {
+		names = append(names, col.Name)
+	}
+	if len(names) == 0 && len(result.Rows) > 0 {
+		for name := range result.Rows[0] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	return names
+}
+
+// containsFold 判断 names 中是否有 name（不区分大小写）
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueFold 按列名取值，列名不区分大小写
+func valueFold(row domain.Row, name string) interface{} {
+	if v, ok := row[name]; ok {
+		return v
+	}
+	for k, v := range row {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package tablediff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func result(columns []string, rows ...domain.Row) *domain.QueryResult {
+	r := &domain.QueryResult{Rows: rows}
+	for _, col := range columns {
+		r.Columns = append(r.Columns, domain.ColumnInfo{Name: col})
+	}
+	return r
+}
+
+func collect(t *testing.T, left, right *domain.QueryResult, key ...string) ([]Difference, *Summary) {
+	t.Helper()
+	var diffs []Difference
+	summary, err := Compare(left, right, key, func(d Difference) error {
+		diffs = append(diffs, d)
+		return nil
+	})
+	require.NoError(t, err)
+	return diffs, summary
+}
+
+func TestCompare(t *testing.T) {
+	left := result([]string{"id", "name", "score", "only_left"},
+		domain.Row{"id": int64(1), "name": "a", "score": 1.5, "only_left": 1},
+		domain.Row{"id": int64(2), "name": "b", "score": nil},
+		domain.Row{"id": int64(3), "name": "c", "score": 3},
+	)
+	// 来自另一个数据源：主键为浮点数，列名大小写不同
+	right := result([]string{"ID", "Name", "Score"},
+		domain.Row{"ID": float64(4), "Name": "d", "Score": 4},
+		domain.Row{"ID": float64(1), "Name": "a", "Score": 1.5},
+		domain.Row{"ID": float64(2), "Name": "B", "Score": 0},
+	)
+
+	diffs, summary := collect(t, left, right, "id")
+	require.Len(t, diffs, 4)
+	assert.Equal(t, Difference{Kind: KindChanged, Key: map[string]interface{}{"id": int64(2)}, Column: "name", Left: "b", Right: "B"}, diffs[0])
+	assert.Equal(t, Difference{Kind: KindChanged, Key: map[string]interface{}{"id": int64(2)}, Column: "score", Left: nil, Right: 0}, diffs[1])
+	assert.Equal(t, KindMissing, diffs[2].Kind)
+	assert.Equal(t, left.Rows[2], diffs[2].Left)
+	assert.Equal(t, KindExtra, diffs[3].Kind)
+	assert.Equal(t, map[string]interface{}{"id": float64(4)}, diffs[3].Key)
+
+	assert.Equal(t, &Summary{LeftRows: 3, RightRows: 3, Matched: 1, Missing: 1, Extra: 1, Changed: 1,
+		Columns: []string{"name", "score"}, Ignored: []string{"only_left"}}, summary)
+	assert.False(t, summary.Identical())
+	assert.Equal(t, "3 left rows, 3 right rows: 1 matched, 1 missing, 1 extra, 1 changed", summary.String())
+}
+
+func TestCompare_CompositeKey(t *testing.T) {
+	left := result([]string{"a", "b", "v"}, domain.Row{"a": 1, "b": "x", "v": 1}, domain.Row{"a": 1, "b": "y", "v": 2})
+	right := result([]string{"a", "b", "v"}, domain.Row{"a": 1, "b": "y", "v": 2}, domain.Row{"a": 1, "b": "x", "v": 1})
+
+	diffs, summary := collect(t, left, right, "a", "b")
+	assert.Empty(t, diffs)
+	assert.True(t, summary.Identical())
+	assert.EqualValues(t, 2, summary.Matched)
+}
+
+func TestCompare_Errors(t *testing.T) {
+	rows := result([]string{"id"}, domain.Row{"id": 1}, domain.Row{"id": 1})
+	emit := func(Difference) error { return nil }
+
+	_, err := Compare(rows, rows, nil, emit)
+	assert.Error(t, err)
+	_, err = Compare(rows, result([]string{"other"}), []string{"id"}, emit)
+	assert.Error(t, err)
+	_, err = Compare(rows, result([]string{"id"}), []string{"id"}, emit)
+	assert.ErrorContains(t, err, "duplicate key")
+	_, err = Compare(result([]string{"id"}, domain.Row{"id": nil}), result([]string{"id"}), []string{"id"}, emit)
+	assert.ErrorContains(t, err, "NULL")
+
+	stop := errors.New("stop")
+	_, err = Compare(result([]string{"id"}, domain.Row{"id": 1}), result([]string{"id"}), []string{"id"}, func(Difference) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
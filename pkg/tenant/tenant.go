@@ -0,0 +1,453 @@
+// Package tenant 实现多租户隔离：把用户映射到租户 ID，
+// 对涉及按租户隔离的表的语句自动加上 tenant_id 条件（INSERT 时自动填写），并拒绝跨租户的语句
+package tenant
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// DefaultColumn 默认的租户列名
+const DefaultColumn = "tenant_id"
+
+// HookName 隔离使用的查询改写钩子名
+const HookName = "tenant-isolation"
+
+// Config 多租户隔离配置
+type Config struct {
+	// Column 租户列名，默认 tenant_id
+	Column string `json:"column,omitempty"`
+	// Tables 按租户隔离的表，可以写成 db.table 只匹配该库中的表
+	Tables []string `json:"tables"`
+	// Users 用户到租户 ID 的映射
+	Users map[string]string `json:"users"`
+	// UnscopedUsers 不受隔离限制的用户（如管理员）
+	UnscopedUsers []string `json:"unscoped_users,omitempty"`
+	// Resolver 按用户查找租户 ID，设置时优先于 Users（嵌入式使用）
+	Resolver func(user string) (string, bool) `json:"-"`
+}
+
+// Validate 检查隔离配置
+func (c Config) Validate() error {
+	if len(c.Tables) == 0 {
+		return fmt.Errorf("tenancy: no tenant-scoped tables configured")
+	}
+	for _, table := range c.Tables {
+		if strings.TrimSpace(table) == "" {
+			return fmt.Errorf("tenancy: empty table name")
+		}
+	}
+	for user, id := range c.Users {
+		if id == "" {
+			return fmt.Errorf("tenancy: user %q is mapped to an empty tenant ID", user)
+		}
+	}
+	return nil
+}
+
+// ViolationError 语句违反租户隔离，对应 MySQL 的 ER_TABLEACCESS_DENIED_ERROR
+type ViolationError struct {
+	User   string
+	Table  string
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("tenant isolation: %s (user '%s', table '%s')", e.Reason, e.User, e.Table)
+}
+
+// MySQLErrorCode 实现 mysqlerrors.Coder
+func (e *ViolationError) MySQLErrorCode() uint16 {
+	return mysqlerrors.ErrTableAccessDenied
+}
+
+// Isolation 编译后的隔离规则
+type Isolation struct {
+	cfg      Config
+	column   string
+	tables   map[string]bool // 小写的表名或 db.table
+	unscoped map[string]bool
+}
+
+// New 根据配置创建隔离规则
+func New(cfg Config) *Isolation {
+	iso := &Isolation{
+		cfg:      cfg,
+		column:   strings.ToLower(cfg.Column),
+		tables:   make(map[string]bool, len(cfg.Tables)),
+		unscoped: make(map[string]bool, len(cfg.UnscopedUsers)),
+	}
+	if iso.column == "" {
+		iso.column = DefaultColumn
+	}
+	for _, table := range cfg.Tables {
+		iso.tables[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+	for _, user := range cfg.UnscopedUsers {
+		iso.unscoped[user] = true
+	}
+	return iso
+}
+
+// Column 返回租户列名
+func (iso *Isolation) Column() string {
+	return iso.column
+}
+
+// TenantOf 返回用户的租户 ID
+func (iso *Isolation) TenantOf(user string) (string, bool) {
+	if iso.cfg.Resolver != nil {
+		return iso.cfg.Resolver(user)
+	}
+	id, ok := iso.cfg.Users[user]
+	return id, ok && id != ""
+}
+
+// Hook 返回执行隔离的 post-parse 查询改写钩子
+func (iso *Isolation) Hook() parser.RewriteHook {
+	return parser.RewriteHook{Name: HookName, PostParse: iso.Apply}
+}
+
+// scoped 判断表是否按租户隔离；table 可以带库名前缀，未带时使用当前数据库
+func (iso *Isolation) scoped(database, table string) bool {
+	if table == "" {
+		return false
+	}
+	table = strings.ToLower(table)
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		database, table = table[:i], table[i+1:]
+	}
+	if iso.tables[table] {
+		return true
+	}
+	return database != "" && iso.tables[strings.ToLower(database)+"."+table]
+}
+
+// Apply 对语句执行租户隔离，返回是否改写了语句。
+// 语句中每一处对按租户隔离的表的引用都必须加上租户条件，无法限定的形式（派生表、标量或 EXISTS 子查询、
+// UNION、CTE、INSERT ... SELECT 以及 TiDB 语法之外的扩展语句等）一律拒绝
+func (iso *Isolation) Apply(rc parser.RewriteContext, stmt *parser.SQLStatement) (bool, error) {
+	if stmt == nil || iso.unscoped[rc.User] {
+		return false, nil
+	}
+	var tables []string
+	for _, table := range stmt.TableRefs {
+		if iso.scoped(rc.Database, table) {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return false, nil
+	}
+	tenantID, ok := iso.TenantOf(rc.User)
+	if !ok {
+		return false, &ViolationError{User: rc.User, Table: tables[0], Reason: "user is not mapped to a tenant"}
+	}
+	sc := &scope{iso: iso, database: rc.Database, tenantID: tenantID, v: &violation{user: rc.User, table: tables[0]}}
+
+	var err error
+	switch {
+	case stmt.Select != nil:
+		err = sc.selectStmt(stmt.Select)
+	case stmt.Insert != nil:
+		err = sc.insert(stmt.Insert)
+	case stmt.Update != nil:
+		err = sc.update(stmt.Update)
+	case stmt.Delete != nil:
+		err = sc.delete(stmt.Delete)
+	case stmt.Explain != nil && stmt.Explain.Query != nil:
+		err = sc.selectStmt(stmt.Explain.Query)
+	case stmt.Show != nil || stmt.Describe != nil:
+		// 只读取表结构
+		return false, nil
+	default:
+		// DDL、TRUNCATE、CHECKSUM、EXPORT 等作用于整张表的语句会影响或读取其他租户的数据
+		return false, sc.v.err(fmt.Sprintf("%s is not allowed on a tenant-scoped table", stmt.Type))
+	}
+	if err != nil {
+		return false, err
+	}
+	if sc.covered < len(tables) {
+		return false, sc.v.err("the table is referenced where no tenant condition can be added " +
+			"(derived tables, UNION, CTEs, INSERT ... SELECT and subqueries other than IN (SELECT ...) are not supported)")
+	}
+	return sc.changed, nil
+}
+
+// scope 对一条语句执行租户隔离的状态
+type scope struct {
+	iso      *Isolation
+	database string // 当前数据库
+	tenantID string
+	v        *violation
+	covered  int  // 已加上租户条件的表引用数
+	changed  bool // 是否改写了语句
+}
+
+// scoped 判断表是否按租户隔离，db 为空时使用当前数据库
+func (sc *scope) scoped(db, table string) bool {
+	if db == "" {
+		db = sc.database
+	}
+	return sc.iso.scoped(db, table)
+}
+
+// selectStmt 为 FROM 和 JOIN 中每个按租户隔离的表追加租户条件，并递归限定 IN (SELECT ...) 子查询
+func (sc *scope) selectStmt(sel *parser.SelectStatement) error {
+	if err := sc.predicate(sel.Where); err != nil {
+		return err
+	}
+	if err := sc.predicate(sel.Having); err != nil {
+		return err
+	}
+	for _, join := range sel.Joins {
+		if err := sc.predicate(join.Condition); err != nil {
+			return err
+		}
+	}
+
+	if sc.scoped(sel.Database, sel.From) {
+		qualifier := sel.FromAlias
+		if qualifier == "" && len(sel.Joins) > 0 {
+			qualifier = unqualified(sel.From)
+		}
+		if err := sc.scopeJoined(sel, 0, qualifier); err != nil {
+			return err
+		}
+	}
+	for i, join := range sel.Joins {
+		if !sc.scoped(join.Database, join.Table) {
+			continue
+		}
+		qualifier := join.Alias
+		if qualifier == "" {
+			qualifier = unqualified(join.Table)
+		}
+		if err := sc.scopeJoined(sel, i+1, qualifier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scopeJoined 限定 FROM 中第 pos 张表（0 为 FROM 表，i+1 为第 i 个 JOIN 的表）。
+// 外连接中会补 NULL 的表（LEFT JOIN 的右表、RIGHT JOIN 左侧的表）把条件加在该连接的 ON 上，
+// 加在 WHERE 上会过滤掉没有匹配的行，使外连接退化为内连接；其余的表加在 WHERE 上
+func (sc *scope) scopeJoined(sel *parser.SelectStatement, pos int, qualifier string) error {
+	target := &sel.Where
+	if pos > 0 && sel.Joins[pos-1].Type == parser.JoinTypeLeft {
+		target = &sel.Joins[pos-1].Condition
+	} else {
+		for i := pos; i < len(sel.Joins); i++ {
+			if sel.Joins[i].Type == parser.JoinTypeRight {
+				target = &sel.Joins[i].Condition
+				break
+			}
+		}
+	}
+	for i := range sel.Joins {
+		if sel.Joins[i].Type == parser.JoinTypeFull && (i >= pos || i == pos-1) {
+			return sc.v.err("FULL JOIN on a tenant-scoped table is not supported")
+		}
+	}
+	*target = sc.iso.and(*target, qualifier, sc.tenantID)
+	sc.covered++
+	sc.changed = true
+	return nil
+}
+
+// insert 检查或填写插入行的租户列；ON DUPLICATE KEY UPDATE 只能更新本租户的行
+func (sc *scope) insert(ins *parser.InsertStatement) error {
+	if !sc.scoped(ins.Database, ins.Table) {
+		return nil
+	}
+	sc.v.table = ins.Table
+	sc.covered++
+	if len(ins.Columns) == 0 {
+		return sc.v.err("INSERT into a tenant-scoped table must list its columns")
+	}
+	if ins.OnDuplicate != nil {
+		if err := sc.iso.checkAssignments(ins.OnDuplicate.Set, sc.tenantID, sc.v); err != nil {
+			return err
+		}
+		// 与其他租户的行冲突时不更新该行，语句按主键冲突报错
+		ins.OnDuplicate.Where = sc.iso.and(ins.OnDuplicate.Where, "", sc.tenantID)
+		sc.changed = true
+	}
+
+	pos := -1
+	for i, col := range ins.Columns {
+		if strings.EqualFold(col, sc.iso.column) {
+			pos = i
+			break
+		}
+	}
+	if pos >= 0 {
+		for _, row := range ins.Values {
+			if pos < len(row) && !sameTenant(row[pos], sc.tenantID) {
+				return sc.v.err(fmt.Sprintf("cannot insert rows of tenant '%v'", row[pos]))
+			}
+		}
+		return nil
+	}
+
+	ins.Columns = append(ins.Columns, sc.iso.column)
+	for i := range ins.Values {
+		ins.Values[i] = append(ins.Values[i], sc.tenantID)
+	}
+	sc.changed = true
+	return nil
+}
+
+// update 拒绝把行改到其他租户，并只更新本租户的行
+func (sc *scope) update(upd *parser.UpdateStatement) error {
+	if err := sc.predicate(upd.Where); err != nil {
+		return err
+	}
+	if !sc.scoped(upd.Database, upd.Table) {
+		return nil
+	}
+	sc.v.table = upd.Table
+	if err := sc.iso.checkAssignments(upd.Set, sc.tenantID, sc.v); err != nil {
+		return err
+	}
+	upd.Where = sc.iso.and(upd.Where, "", sc.tenantID)
+	sc.covered++
+	sc.changed = true
+	return nil
+}
+
+// delete 只删除本租户的行
+func (sc *scope) delete(del *parser.DeleteStatement) error {
+	if err := sc.predicate(del.Where); err != nil {
+		return err
+	}
+	if !sc.scoped(del.Database, del.Table) {
+		return nil
+	}
+	del.Where = sc.iso.and(del.Where, "", sc.tenantID)
+	sc.covered++
+	sc.changed = true
+	return nil
+}
+
+// predicate 拒绝显式引用其他租户的条件，并限定条件中的 IN (SELECT ...) 子查询
+func (sc *scope) predicate(expr *parser.Expression) error {
+	if expr == nil {
+		return nil
+	}
+	if err := sc.iso.checkPredicate(expr, sc.tenantID, sc.v); err != nil {
+		return err
+	}
+	return sc.subqueries(expr)
+}
+
+// subqueries 递归限定表达式中的子查询
+func (sc *scope) subqueries(expr *parser.Expression) error {
+	if expr == nil {
+		return nil
+	}
+	if expr.Subquery != nil {
+		if err := sc.selectStmt(expr.Subquery); err != nil {
+			return err
+		}
+	}
+	if err := sc.subqueries(expr.Left); err != nil {
+		return err
+	}
+	if err := sc.subqueries(expr.Right); err != nil {
+		return err
+	}
+	for i := range expr.Args {
+		if err := sc.subqueries(&expr.Args[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAssignments 拒绝把租户列改成其他租户
+func (iso *Isolation) checkAssignments(set map[string]interface{}, tenantID string, v *violation) error {
+	for col, value := range set {
+		if strings.EqualFold(unqualified(col), iso.column) && !sameTenant(value, tenantID) {
+			return v.err(fmt.Sprintf("cannot move rows to tenant '%v'", value))
+		}
+	}
+	return nil
+}
+
+// checkPredicate 拒绝显式引用其他租户的条件（如 tenant_id = 'other'）
+func (iso *Isolation) checkPredicate(expr *parser.Expression, tenantID string, v *violation) error {
+	if expr == nil {
+		return nil
+	}
+	if expr.Type == parser.ExprTypeOperator && expr.Left != nil && expr.Right != nil &&
+		expr.Left.Type == parser.ExprTypeColumn && strings.EqualFold(unqualified(expr.Left.Column), iso.column) &&
+		expr.Right.Type == parser.ExprTypeValue {
+		values, ok := expr.Right.Value.([]interface{})
+		if !ok {
+			values = []interface{}{expr.Right.Value}
+		}
+		for _, value := range values {
+			if !sameTenant(value, tenantID) {
+				return v.err(fmt.Sprintf("cannot access rows of tenant '%v'", value))
+			}
+		}
+	}
+	if err := iso.checkPredicate(expr.Left, tenantID, v); err != nil {
+		return err
+	}
+	if err := iso.checkPredicate(expr.Right, tenantID, v); err != nil {
+		return err
+	}
+	for i := range expr.Args {
+		if err := iso.checkPredicate(&expr.Args[i], tenantID, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// and 在条件后追加 qualifier.tenant_id = tenantID
+func (iso *Isolation) and(where *parser.Expression, qualifier, tenantID string) *parser.Expression {
+	column := iso.column
+	if qualifier != "" {
+		column = qualifier + "." + column
+	}
+	predicate := &parser.Expression{
+		Type:     parser.ExprTypeOperator,
+		Operator: "eq",
+		Left:     &parser.Expression{Type: parser.ExprTypeColumn, Column: column},
+		Right:    &parser.Expression{Type: parser.ExprTypeValue, Value: tenantID},
+	}
+	if where == nil {
+		return predicate
+	}
+	return &parser.Expression{Type: parser.ExprTypeOperator, Operator: "and", Left: where, Right: predicate}
+}
+
+// violation 收集错误信息所需的上下文
+type violation struct {
+	user  string
+	table string
+}
+
+func (v *violation) err(reason string) error {
+	return &ViolationError{User: v.user, Table: v.table, Reason: reason}
+}
+
+// sameTenant 比较租户列的值与租户 ID（租户列可能是整数）
+func sameTenant(value interface{}, tenantID string) bool {
+	return fmt.Sprint(value) == tenantID
+}
+
+// unqualified 去掉 db. 或 table. 前缀
+func unqualified(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,265 @@
+package tenant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIsolation() *Isolation {
+	return New(Config{
+		Tables:        []string{"orders", "crm.contacts"},
+		Users:         map[string]string{"alice": "acme", "bob": "globex", "carol": ""},
+		UnscopedUsers: []string{"root"},
+	})
+}
+
+// apply 解析 SQL 并以 user 身份执行隔离
+func apply(t *testing.T, user, sql string) (*parser.SQLStatement, bool, error) {
+	t.Helper()
+	result, err := parser.NewSQLAdapter().Parse(sql)
+	require.NoError(t, err)
+	require.True(t, result.Success, result.Error)
+	changed, err := newIsolation().Apply(parser.RewriteContext{User: user, Database: "app"}, result.Statement)
+	return result.Statement, changed, err
+}
+
+// tenantPredicate 返回 where 最后追加的租户条件
+func tenantPredicate(where *parser.Expression) *parser.Expression {
+	if where != nil && where.Operator == "and" {
+		return where.Right
+	}
+	return where
+}
+
+func assertViolation(t *testing.T, err error) {
+	t.Helper()
+	var violation *ViolationError
+	require.True(t, errors.As(err, &violation), "expected a tenant violation, got %v", err)
+	assert.Equal(t, mysqlerrors.ErrTableAccessDenied, violation.MySQLErrorCode())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{Tables: []string{"orders"}}.Validate())
+	assert.Error(t, Config{}.Validate())
+	assert.Error(t, Config{Tables: []string{" "}}.Validate())
+	assert.Error(t, Config{Tables: []string{"orders"}, Users: map[string]string{"alice": ""}}.Validate())
+}
+
+func TestApply_Select(t *testing.T) {
+	stmt, changed, err := apply(t, "alice", "SELECT * FROM orders WHERE amount > 10")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	predicate := tenantPredicate(stmt.Select.Where)
+	assert.Equal(t, "tenant_id", predicate.Left.Column)
+	assert.Equal(t, "acme", predicate.Right.Value)
+	assert.Equal(t, "and", stmt.Select.Where.Operator)
+
+	// 不受隔离的表不做改动
+	stmt, changed, err = apply(t, "alice", "SELECT * FROM products")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Nil(t, stmt.Select.Where)
+
+	// 限定到其他数据库的表
+	_, changed, err = apply(t, "alice", "SELECT * FROM crm.contacts")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	_, changed, err = apply(t, "alice", "SELECT * FROM contacts")
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestApply_SelectJoin(t *testing.T) {
+	stmt, changed, err := apply(t, "alice", "SELECT o.id FROM orders o JOIN products p ON o.product_id = p.id")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	predicate := tenantPredicate(stmt.Select.Where)
+	assert.Equal(t, "o.tenant_id", predicate.Left.Column)
+
+	stmt, _, err = apply(t, "alice", "SELECT * FROM products JOIN orders ON orders.product_id = products.id")
+	require.NoError(t, err)
+	assert.Equal(t, "orders.tenant_id", tenantPredicate(stmt.Select.Where).Left.Column)
+}
+
+// TestApply_SelectOuterJoin 测试外连接中补 NULL 一侧的租户条件加在 ON 上，保留没有匹配的行
+func TestApply_SelectOuterJoin(t *testing.T) {
+	stmt, _, err := apply(t, "alice", "SELECT p.id, o.id FROM products p LEFT JOIN orders o ON o.product_id = p.id")
+	require.NoError(t, err)
+	assert.Nil(t, stmt.Select.Where)
+	predicate := tenantPredicate(stmt.Select.Joins[0].Condition)
+	assert.Equal(t, "o.tenant_id", predicate.Left.Column)
+	assert.Equal(t, "acme", predicate.Right.Value)
+
+	stmt, _, err = apply(t, "alice", "SELECT p.id, o.id FROM orders o RIGHT JOIN products p ON o.product_id = p.id")
+	require.NoError(t, err)
+	assert.Nil(t, stmt.Select.Where)
+	assert.Equal(t, "o.tenant_id", tenantPredicate(stmt.Select.Joins[0].Condition).Left.Column)
+
+	// 外连接中保留全部行的一侧仍加在 WHERE 上
+	stmt, _, err = apply(t, "alice", "SELECT o.id, p.id FROM orders o LEFT JOIN products p ON o.product_id = p.id")
+	require.NoError(t, err)
+	assert.Equal(t, "o.tenant_id", tenantPredicate(stmt.Select.Where).Left.Column)
+}
+
+func TestApply_CrossTenantPredicate(t *testing.T) {
+	_, _, err := apply(t, "alice", "SELECT * FROM orders WHERE tenant_id = 'globex'")
+	assertViolation(t, err)
+	_, _, err = apply(t, "alice", "SELECT * FROM orders WHERE tenant_id IN ('acme', 'globex')")
+	assertViolation(t, err)
+	_, _, err = apply(t, "alice", "SELECT * FROM orders WHERE tenant_id = 'acme'")
+	assert.NoError(t, err)
+}
+
+func TestApply_Insert(t *testing.T) {
+	stmt, changed, err := apply(t, "alice", "INSERT INTO orders (id, amount) VALUES (1, 10), (2, 20)")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"id", "amount", "tenant_id"}, stmt.Insert.Columns)
+	for _, row := range stmt.Insert.Values {
+		assert.Equal(t, "acme", row[2])
+	}
+
+	_, changed, err = apply(t, "alice", "INSERT INTO orders (id, tenant_id) VALUES (1, 'acme')")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, _, err = apply(t, "alice", "INSERT INTO orders (id, tenant_id) VALUES (1, 'globex')")
+	assertViolation(t, err)
+	_, _, err = apply(t, "alice", "INSERT INTO orders VALUES (1, 10)")
+	assertViolation(t, err)
+	_, _, err = apply(t, "alice", "INSERT INTO orders (id) VALUES (1) ON DUPLICATE KEY UPDATE tenant_id = 'globex'")
+	assertViolation(t, err)
+}
+
+func TestApply_InsertOnDuplicate(t *testing.T) {
+	// 冲突的已有行必须属于本租户才会被更新
+	stmt, changed, err := apply(t, "alice", "INSERT INTO orders (id, amount) VALUES (1, 10) ON DUPLICATE KEY UPDATE amount = 10")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	require.NotNil(t, stmt.Insert.OnDuplicate)
+	guard := stmt.Insert.OnDuplicate.Where
+	require.NotNil(t, guard)
+	assert.Equal(t, "tenant_id", guard.Left.Column)
+	assert.Equal(t, "acme", guard.Right.Value)
+
+	stmt, changed, err = apply(t, "alice", "INSERT INTO orders (id, tenant_id) VALUES (1, 'acme') ON DUPLICATE KEY UPDATE amount = 10")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "acme", stmt.Insert.OnDuplicate.Where.Right.Value)
+}
+
+func TestApply_Subqueries(t *testing.T) {
+	// IN 子查询中的表同样加上租户条件
+	stmt, changed, err := apply(t, "alice", "SELECT * FROM products WHERE id IN (SELECT product_id FROM orders WHERE amount > 1)")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Nil(t, stmt.Select.Where.Left.Subquery)
+	sub := stmt.Select.Where.Right.Subquery
+	require.NotNil(t, sub)
+	assert.Equal(t, "acme", tenantPredicate(sub.Where).Right.Value)
+
+	stmt, _, err = apply(t, "bob", "DELETE FROM products WHERE id IN (SELECT product_id FROM orders)")
+	require.NoError(t, err)
+	assert.Equal(t, "globex", stmt.Delete.Where.Right.Subquery.Where.Right.Value)
+
+	_, _, err = apply(t, "alice", "SELECT * FROM products WHERE id IN (SELECT product_id FROM orders WHERE tenant_id = 'globex')")
+	assertViolation(t, err)
+
+	// 无法加上租户条件的引用一律拒绝
+	for _, sql := range []string{
+		"SELECT * FROM (SELECT * FROM orders) o",
+		"SELECT * FROM products p WHERE EXISTS (SELECT 1 FROM orders o WHERE o.product_id = p.id)",
+		"SELECT (SELECT MAX(amount) FROM orders) AS top FROM products",
+		"SELECT id FROM products UNION SELECT id FROM orders",
+		"WITH o AS (SELECT * FROM orders) SELECT * FROM o",
+		"INSERT INTO products (id) SELECT product_id FROM orders",
+		"UPDATE products SET name = 'x' WHERE id = (SELECT product_id FROM orders LIMIT 1)",
+	} {
+		_, _, err := apply(t, "alice", sql)
+		assertViolation(t, err)
+	}
+
+	// 只涉及不受隔离的表的子查询不受影响
+	_, changed, err = apply(t, "alice", "SELECT * FROM (SELECT * FROM products) p")
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestApply_UpdateDelete(t *testing.T) {
+	stmt, changed, err := apply(t, "bob", "UPDATE orders SET amount = 1 WHERE id = 1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "globex", tenantPredicate(stmt.Update.Where).Right.Value)
+
+	_, _, err = apply(t, "bob", "UPDATE orders SET tenant_id = 'acme'")
+	assertViolation(t, err)
+
+	stmt, changed, err = apply(t, "bob", "DELETE FROM orders")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "tenant_id", stmt.Delete.Where.Left.Column)
+}
+
+func TestApply_RejectsTableWideStatements(t *testing.T) {
+	for _, sql := range []string{
+		"TRUNCATE TABLE orders",
+		"DROP TABLE orders",
+		"ALTER TABLE orders ADD COLUMN note VARCHAR(10)",
+		"CREATE VIEW v AS SELECT * FROM orders",
+		"CHECKSUM TABLE products, orders",
+		"CHECK TABLE orders",
+		"EXPORT TABLE orders TO '/tmp/orders.json'",
+		"DIFF TABLE orders, products",
+		"IMPORT DATA INFILE 'orders.csv' FORMAT CSV INTO TABLE orders",
+		"UNDROP TABLE orders",
+		"FLASHBACK TABLE crm.contacts TO TIMESTAMP '2024-01-01'",
+	} {
+		_, _, err := apply(t, "alice", sql)
+		assertViolation(t, err)
+	}
+	_, _, err := apply(t, "alice", "DROP TABLE products")
+	assert.NoError(t, err)
+	_, _, err = apply(t, "alice", "CHECKSUM TABLE products")
+	assert.NoError(t, err)
+
+	// 只读取表结构的语句不受限制；导出到文件和用户变量赋值的查询在执行时重新解析并限定
+	for _, sql := range []string{
+		"SHOW COLUMNS FROM orders",
+		"DESCRIBE orders",
+		"SELECT * FROM orders INTO OUTFILE '/tmp/orders.csv'",
+		"SELECT amount INTO @a FROM orders WHERE id = 1",
+	} {
+		_, changed, err := apply(t, "alice", sql)
+		require.NoError(t, err, sql)
+		assert.False(t, changed, sql)
+	}
+}
+
+func TestApply_Users(t *testing.T) {
+	// 不受隔离限制的用户
+	stmt, changed, err := apply(t, "root", "SELECT * FROM orders")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Nil(t, stmt.Select.Where)
+
+	// 没有租户的用户不能访问按租户隔离的表
+	_, _, err = apply(t, "mallory", "SELECT * FROM orders")
+	assertViolation(t, err)
+	_, _, err = apply(t, "carol", "SELECT * FROM orders")
+	assertViolation(t, err)
+	_, _, err = apply(t, "mallory", "SELECT * FROM products")
+	assert.NoError(t, err)
+
+	// Resolver 优先于 Users
+	iso := New(Config{Tables: []string{"orders"}, Resolver: func(user string) (string, bool) { return "t-" + user, true }})
+	result, err := parser.NewSQLAdapter().Parse("SELECT * FROM orders")
+	require.NoError(t, err)
+	_, err = iso.Apply(parser.RewriteContext{User: "mallory"}, result.Statement)
+	require.NoError(t, err)
+	assert.Equal(t, "t-mallory", result.Statement.Select.Where.Right.Value)
+}
@@ -30,6 +30,18 @@ const (
 	Avg
 	Max
 	Min
+	GroupConcat
+	StdDevPop
+	StdDevSamp
+	VarPop
+	VarSamp
+	BitAnd
+	BitOr
+	BitXor
+	First               // FIRST(value, ts)
+	Last                // LAST(value, ts)
+	ApproxCountDistinct // APPROX_COUNT_DISTINCT(expr)，HyperLogLog
+	ApproxPercentile    // APPROX_PERCENTILE(expr, percentage)，t-digest
 )
 
 // JoinCondition 连接条件
@@ -47,10 +59,19 @@ type LimitInfo struct {
 
 // AggregationItem 聚合项
 type AggregationItem struct {
-	Type     AggregationType
-	Expr     *Expression
-	Alias    string
-	Distinct bool
+	Type      AggregationType
+	Expr      *Expression
+	Alias     string
+	Distinct  bool
+	Separator string             // GROUP_CONCAT 分隔符
+	OrderBy   []AggregationOrder // GROUP_CONCAT 内的 ORDER BY
+	Params    []interface{}      // 表达式之后的常量参数（如 APPROX_PERCENTILE 的百分比）
+}
+
+// AggregationOrder 聚合函数内的排序项
+type AggregationOrder struct {
+	Column string
+	Desc   bool
 }
 
 // Expression 表达式
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsArithmeticOperator reports whether op is one of the arithmetic operators
+// produced by the parser (plus, minus, mul, div, intdiv, mod) or their symbols.
+func IsArithmeticOperator(op string) bool {
+	switch strings.ToLower(op) {
+	case "plus", "+", "minus", "-", "mul", "*", "div", "/", "intdiv", "mod", "%":
+		return true
+	}
+	return false
+}
+
+// Arithmetic applies an arithmetic operator to two evaluated operands.
+// NULL operands and division by zero yield NULL. Two integer operands keep an
+// integer result for everything except "/", which is always a float division.
+func Arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	op = strings.ToLower(op)
+	if l, ok := asInt64(left); ok {
+		if r, ok := asInt64(right); ok {
+			switch op {
+			case "plus", "+":
+				return l + r, nil
+			case "minus", "-":
+				return l - r, nil
+			case "mul", "*":
+				return l * r, nil
+			case "intdiv", "mod", "%":
+				if r == 0 {
+					return nil, nil
+				}
+				if op == "intdiv" {
+					return l / r, nil
+				}
+				return l % r, nil
+			}
+		}
+	}
+
+	l, err := ToFloat64(left)
+	if err != nil {
+		return nil, fmt.Errorf("operator %s: %w", op, err)
+	}
+	r, err := ToFloat64(right)
+	if err != nil {
+		return nil, fmt.Errorf("operator %s: %w", op, err)
+	}
+	switch op {
+	case "plus", "+":
+		return l + r, nil
+	case "minus", "-":
+		return l - r, nil
+	case "mul", "*":
+		return l * r, nil
+	case "div", "/", "intdiv", "mod", "%":
+		if r == 0 {
+			return nil, nil
+		}
+		switch op {
+		case "div", "/":
+			return l / r, nil
+		case "intdiv":
+			return int64(l / r), nil
+		}
+		return l - r*float64(int64(l/r)), nil
+	}
+	return nil, fmt.Errorf("unsupported operator: %s", op)
+}
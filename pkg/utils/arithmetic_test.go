@@ -0,0 +1,57 @@
+package utils
+
+import "testing"
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		op          string
+		left, right interface{}
+		expected    interface{}
+	}{
+		{"plus", int64(2), int64(3), int64(5)},
+		{"+", 2, int32(3), int64(5)},
+		{"minus", uint8(7), int64(10), int64(-3)},
+		{"mul", int64(4), int64(5), int64(20)},
+		{"intdiv", int64(7), int64(2), int64(3)},
+		{"mod", int64(7), int64(3), int64(1)},
+		{"%", int64(7), int64(0), nil},
+		{"div", int64(7), int64(2), 3.5},
+		{"/", int64(1), int64(0), nil},
+		{"plus", 1.5, int64(2), 3.5},
+		{"intdiv", 7.5, 2.0, int64(3)},
+		{"mod", 7.5, 2.0, 1.5},
+		{"plus", nil, int64(1), nil},
+		{"mul", int64(1), nil, nil},
+	}
+
+	for _, tt := range tests {
+		result, err := Arithmetic(tt.op, tt.left, tt.right)
+		if err != nil {
+			t.Errorf("Arithmetic(%s, %v, %v) error: %v", tt.op, tt.left, tt.right, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("Arithmetic(%s, %v, %v) = %v (%T), want %v (%T)", tt.op, tt.left, tt.right, result, result, tt.expected, tt.expected)
+		}
+	}
+
+	if _, err := Arithmetic("plus", "abc", int64(1)); err == nil {
+		t.Error("Arithmetic with a non-numeric operand should fail")
+	}
+	if _, err := Arithmetic("pow", int64(2), int64(3)); err == nil {
+		t.Error("Arithmetic with an unknown operator should fail")
+	}
+}
+
+func TestIsArithmeticOperator(t *testing.T) {
+	for _, op := range []string{"plus", "+", "MINUS", "mul", "div", "/", "intdiv", "mod", "%"} {
+		if !IsArithmeticOperator(op) {
+			t.Errorf("IsArithmeticOperator(%q) = false, want true", op)
+		}
+	}
+	for _, op := range []string{"eq", "and", "like", ""} {
+		if IsArithmeticOperator(op) {
+			t.Errorf("IsArithmeticOperator(%q) = true, want false", op)
+		}
+	}
+}
@@ -0,0 +1,604 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CoercionMode 字符串与数值、日期混合比较以及 CAST 时的转换规则
+type CoercionMode string
+
+const (
+	// CoercionMySQL 与 MySQL 一致：取字符串开头的数字部分（' 12abc' -> 12），没有数字时为 0
+	CoercionMySQL CoercionMode = "mysql"
+	// CoercionStrict 只有完整的数字字符串才转换为数值，否则比较报错、CAST 返回错误
+	CoercionStrict CoercionMode = "strict"
+)
+
+var strictCoercion atomic.Bool
+
+// ParseCoercionMode 解析配置中的转换规则，空字符串为 CoercionMySQL
+func ParseCoercionMode(s string) (CoercionMode, error) {
+	switch mode := CoercionMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "", CoercionMySQL:
+		return CoercionMySQL, nil
+	case CoercionStrict:
+		return CoercionStrict, nil
+	default:
+		return "", fmt.Errorf("unknown coercion mode %q (expected mysql or strict)", s)
+	}
+}
+
+// SetCoercionMode 设置全局转换规则
+func SetCoercionMode(mode CoercionMode) {
+	strictCoercion.Store(mode == CoercionStrict)
+}
+
+// GetCoercionMode 返回当前的全局转换规则
+func GetCoercionMode() CoercionMode {
+	if strictCoercion.Load() {
+		return CoercionStrict
+	}
+	return CoercionMySQL
+}
+
+// numericPrefix 返回 s 开头形如 [+-]digits[.digits][e[+-]digits] 的部分，没有数字时返回空字符串
+func numericPrefix(s string) string {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+			digits++
+		}
+		if digits > 0 {
+			i = j
+		}
+	}
+	if digits == 0 {
+		return ""
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		k := j
+		for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+			k++
+		}
+		if k > j {
+			i = k
+		}
+	}
+	return s[:i]
+}
+
+// StringToNumber 按当前转换规则把字符串转换为数值，忽略首尾空白。
+// CoercionMySQL 下总是成功；CoercionStrict 下只有完整的数字字符串成功
+func StringToNumber(s string) (float64, bool) {
+	t := strings.TrimSpace(s)
+	prefix := numericPrefix(t)
+	if prefix != t || prefix == "" {
+		if strictCoercion.Load() {
+			return 0, false
+		}
+		if prefix == "" {
+			return 0, true
+		}
+	}
+	f, err := strconv.ParseFloat(prefix, 64)
+	if err != nil {
+		// 超出范围时 ParseFloat 返回 ±Inf
+		return f, !strictCoercion.Load()
+	}
+	return f, true
+}
+
+// asNumber 把数值类型和布尔值转换为 float64，字符串不转换
+func asNumber(v interface{}) (float64, bool) {
+	if i, ok := asInt64(v); ok {
+		return float64(i), true
+	}
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// asText 返回字符串或字节切片的内容
+func asText(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case []byte:
+		return string(x), true
+	}
+	return "", false
+}
+
+// dateTimeLayouts ParseDateTime 依次尝试的格式
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102150405",
+	"20060102",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// ParseDateTime 解析日期或日期时间字符串，忽略首尾空白
+func ParseDateTime(s string) (time.Time, error) {
+	t := strings.TrimSpace(s)
+	for _, layout := range dateTimeLayouts {
+		if v, err := time.Parse(layout, t); err == nil {
+			return v, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid datetime value: '%s'", s)
+}
+
+// coerceCompare 比较类型不同的一对值：数值与字符串按数值比较，日期与字符串按日期比较。
+// 无法按这些规则比较时 ok 为 false
+func coerceCompare(a, b interface{}) (cmp int, ok bool) {
+	if at, isTime := a.(time.Time); isTime {
+		if bs, isText := asText(b); isText {
+			bt, err := ParseDateTime(bs)
+			if err != nil {
+				return 0, false
+			}
+			return at.Compare(bt), true
+		}
+		return 0, false
+	}
+	if _, isTime := b.(time.Time); isTime {
+		c, ok := coerceCompare(b, a)
+		return -c, ok
+	}
+
+	var an, bn float64
+	if n, isNum := asNumber(a); isNum {
+		bs, isText := asText(b)
+		if !isText {
+			return 0, false
+		}
+		if bn, ok = StringToNumber(bs); !ok {
+			return 0, false
+		}
+		an = n
+	} else if n, isNum := asNumber(b); isNum {
+		as, isText := asText(a)
+		if !isText {
+			return 0, false
+		}
+		if an, ok = StringToNumber(as); !ok {
+			return 0, false
+		}
+		bn = n
+	} else {
+		return 0, false
+	}
+
+	switch {
+	case an < bn:
+		return -1, true
+	case an > bn:
+		return 1, true
+	}
+	return 0, true
+}
+
+// compareResult 把三路比较结果转换为运算符的结果
+func compareResult(cmp int, op, operator string) (bool, error) {
+	switch op {
+	case "=", "EQ":
+		return cmp == 0, nil
+	case "!=", "<>", "NE", "NEQ":
+		return cmp != 0, nil
+	case ">", "GT":
+		return cmp > 0, nil
+	case "<", "LT":
+		return cmp < 0, nil
+	case ">=", "GE":
+		return cmp >= 0, nil
+	case "<=", "LE":
+		return cmp <= 0, nil
+	default:
+		return false, fmt.Errorf("unsupported operator: %s", operator)
+	}
+}
+
+// JoinKey 返回等值连接使用的键：整数值统一为 int64，其余数值为 float64；numeric 为 true 时
+// 字符串按转换规则转为数值，使 1、1.0 和 "1" 落在同一个键上。numeric 为 false 时字符串保持原样
+func JoinKey(v interface{}, numeric bool) interface{} {
+	switch x := v.(type) {
+	case bool:
+		return v
+	case uint64:
+		if x > math.MaxInt64 {
+			return float64(x)
+		}
+	case float64:
+		return integralKey(x)
+	case float32:
+		return integralKey(float64(x))
+	}
+	if i, ok := asInt64(v); ok {
+		return i
+	}
+	if numeric {
+		if s, ok := asText(v); ok {
+			if i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+				return i
+			}
+			if n, ok := StringToNumber(s); ok {
+				return integralKey(n)
+			}
+		}
+	}
+	return v
+}
+
+// integralKey 把可以用 int64 精确表示的整数值转换为 int64
+func integralKey(f float64) interface{} {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return int64(f)
+	}
+	return f
+}
+
+// IsNumeric 报告 v 是否为数值类型（不含字符串和布尔值）
+func IsNumeric(v interface{}) bool {
+	if _, ok := v.(bool); ok {
+		return false
+	}
+	_, ok := asNumber(v)
+	return ok
+}
+
+// CastType CAST / CONVERT 的目标类型
+type CastType struct {
+	Name   string // 大写的类型名：SIGNED、UNSIGNED、CHAR、BINARY、DATE、DATETIME、TIME、DECIMAL、DOUBLE、FLOAT、JSON
+	Length int    // CHAR(n) / BINARY(n) 的长度或 DECIMAL(p,s) 的精度，-1 表示未指定
+	Scale  int    // DECIMAL(p,s) 的小数位数或 DATETIME(fsp) / TIME(fsp) 的小数秒位数
+}
+
+// ParseCastType 解析 CAST 的目标类型，如 SIGNED、CHAR(10)、DECIMAL(10,2)、DATETIME
+func ParseCastType(s string) (CastType, error) {
+	t := strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	ct := CastType{Length: -1}
+
+	// 去掉 CHARACTER SET / CHARSET 子句
+	for _, kw := range []string{" CHARACTER SET ", " CHARSET "} {
+		if i := strings.Index(t, kw); i >= 0 {
+			t = t[:i]
+		}
+	}
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		j := strings.IndexByte(t, ')')
+		if j < i {
+			return ct, fmt.Errorf("invalid cast type: %s", s)
+		}
+		params := strings.Split(t[i+1:j], ",")
+		t = strings.TrimSpace(t[:i] + t[j+1:])
+		n, err := strconv.Atoi(strings.TrimSpace(params[0]))
+		if err != nil || n < 0 || len(params) > 2 {
+			return ct, fmt.Errorf("invalid cast type: %s", s)
+		}
+		ct.Length = n
+		if len(params) == 2 {
+			if ct.Scale, err = strconv.Atoi(strings.TrimSpace(params[1])); err != nil || ct.Scale < 0 || ct.Scale > n {
+				return ct, fmt.Errorf("invalid cast type: %s", s)
+			}
+		}
+	}
+
+	switch t {
+	case "SIGNED", "SIGNED INTEGER", "SIGNED INT", "INTEGER", "INT", "BIGINT":
+		ct.Name = "SIGNED"
+	case "UNSIGNED", "UNSIGNED INTEGER", "UNSIGNED INT":
+		ct.Name = "UNSIGNED"
+	case "CHAR", "VARCHAR", "NCHAR", "TEXT":
+		ct.Name = "CHAR"
+	case "BINARY":
+		ct.Name = "BINARY"
+	case "DATE", "DATETIME", "TIME", "JSON":
+		ct.Name = t
+	case "DECIMAL", "NUMERIC":
+		ct.Name = "DECIMAL"
+	case "DOUBLE", "REAL", "DOUBLE PRECISION":
+		ct.Name = "DOUBLE"
+	case "FLOAT":
+		ct.Name = "FLOAT"
+	default:
+		return ct, fmt.Errorf("unsupported cast type: %s", s)
+	}
+
+	switch ct.Name {
+	case "DATETIME", "TIME":
+		// DATETIME(fsp) 的参数是小数秒位数
+		if ct.Length > 6 {
+			return ct, fmt.Errorf("too big precision %d for %s, maximum is 6", ct.Length, ct.Name)
+		}
+		if ct.Length > 0 {
+			ct.Scale = ct.Length
+		}
+		ct.Length = -1
+	case "DECIMAL":
+		if ct.Length < 0 {
+			ct.Length = 10
+		}
+		if ct.Length > 65 {
+			return ct, fmt.Errorf("too big precision %d for DECIMAL, maximum is 65", ct.Length)
+		}
+	}
+	return ct, nil
+}
+
+// String 返回类型的 SQL 写法
+func (t CastType) String() string {
+	switch {
+	case t.Name == "DECIMAL":
+		return fmt.Sprintf("DECIMAL(%d,%d)", t.Length, t.Scale)
+	case t.Length >= 0:
+		return fmt.Sprintf("%s(%d)", t.Name, t.Length)
+	case t.Scale > 0:
+		return fmt.Sprintf("%s(%d)", t.Name, t.Scale)
+	}
+	return t.Name
+}
+
+// CastValue 按转换规则把 v 转换为目标类型，NULL 保持为 NULL。
+// SIGNED / UNSIGNED 返回 int64 / uint64，DECIMAL 返回带 D 位小数的十进制文本（'1.01'），
+// DOUBLE / FLOAT 返回 float64，CHAR / BINARY / JSON 返回 string，DATE / DATETIME 返回 time.Time，TIME 返回 "hh:mm:ss" 字符串
+func CastValue(v interface{}, t CastType) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t.Name {
+	case "SIGNED", "UNSIGNED":
+		f, err := castNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		// 与 MySQL 一致，数值四舍五入，字符串只取整数部分
+		if _, isText := asText(v); isText {
+			f = math.Trunc(f)
+		} else {
+			f = math.Round(f)
+		}
+		if t.Name == "UNSIGNED" {
+			if f < 0 {
+				return uint64(int64(f)), nil
+			}
+			if f >= math.MaxUint64 {
+				return uint64(math.MaxUint64), nil
+			}
+			return uint64(f), nil
+		}
+		if i, ok := asInt64(v); ok {
+			return i, nil
+		}
+		if f >= math.MaxInt64 {
+			return int64(math.MaxInt64), nil
+		}
+		if f <= math.MinInt64 {
+			return int64(math.MinInt64), nil
+		}
+		return int64(f), nil
+
+	case "DECIMAL":
+		return castDecimal(v, t)
+
+	case "DOUBLE", "FLOAT":
+		f, err := castNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		if t.Name == "FLOAT" {
+			return float64(float32(f)), nil
+		}
+		return f, nil
+
+	case "CHAR", "BINARY", "JSON":
+		s := castString(v)
+		if t.Length >= 0 && t.Name != "JSON" {
+			if t.Name == "BINARY" {
+				if len(s) > t.Length {
+					s = s[:t.Length]
+				} else if len(s) < t.Length {
+					s += strings.Repeat("\x00", t.Length-len(s))
+				}
+			} else if r := []rune(s); len(r) > t.Length {
+				s = string(r[:t.Length])
+			}
+		}
+		return s, nil
+
+	case "DATE", "DATETIME":
+		tm, err := castTime(v)
+		if err != nil {
+			return nil, err
+		}
+		if t.Name == "DATE" {
+			return time.Date(tm.Year(), tm.Month(), tm.Day(), 0, 0, 0, 0, tm.Location()), nil
+		}
+		return tm.Round(time.Duration(math.Pow(10, float64(9-t.Scale)))), nil
+
+	case "TIME":
+		if s, ok := asText(v); ok {
+			if tm, err := time.Parse("15:04:05.999999999", strings.TrimSpace(s)); err == nil {
+				return formatTime(tm, t.Scale), nil
+			}
+		}
+		tm, err := castTime(v)
+		if err != nil {
+			return nil, err
+		}
+		return formatTime(tm, t.Scale), nil
+	}
+	return nil, fmt.Errorf("unsupported cast type: %s", t.Name)
+}
+
+// castNumber 把值转换为数值，字符串按当前转换规则处理
+func castNumber(v interface{}) (float64, error) {
+	if n, ok := asNumber(v); ok {
+		return n, nil
+	}
+	if s, ok := asText(v); ok {
+		if n, ok := StringToNumber(s); ok {
+			return n, nil
+		}
+		return 0, fmt.Errorf("truncated incorrect numeric value: '%s'", s)
+	}
+	if tm, ok := v.(time.Time); ok {
+		// 与 MySQL 一致，日期时间转换为 YYYYMMDDhhmmss 形式的数值
+		n, _ := strconv.ParseFloat(tm.Format("20060102150405"), 64)
+		return n, nil
+	}
+	return 0, fmt.Errorf("cannot convert %T to a number", v)
+}
+
+// castDecimal 把值转换为 DECIMAL(M,D)：在值的十进制文本上精确运算，四舍五入（远离零）到 D 位小数，
+// 超出精度时截断到该精度能表示的最大值。浮点数取能还原该值的最短十进制表示，因此 1.005 舍入为 1.01
+func castDecimal(v interface{}, t CastType) (string, error) {
+	var text string
+	switch x := v.(type) {
+	case float64:
+		if math.IsNaN(x) {
+			return "", fmt.Errorf("cannot convert NaN to DECIMAL")
+		}
+		if math.IsInf(x, 0) {
+			text = strings.Repeat("9", t.Length+1)
+			if x < 0 {
+				text = "-" + text
+			}
+		} else {
+			text = strconv.FormatFloat(x, 'g', -1, 64)
+		}
+	case float32:
+		text = strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case uint64:
+		text = strconv.FormatUint(x, 10)
+	default:
+		if i, ok := asInt64(v); ok {
+			text = strconv.FormatInt(i, 10)
+		} else if s, ok := asText(v); ok {
+			if _, ok := StringToNumber(s); !ok {
+				return "", fmt.Errorf("truncated incorrect DECIMAL value: '%s'", s)
+			}
+			text = numericPrefix(strings.TrimSpace(s))
+		} else {
+			f, err := castNumber(v)
+			if err != nil {
+				return "", err
+			}
+			text = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	if text == "" {
+		text = "0"
+	}
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return "", fmt.Errorf("truncated incorrect DECIMAL value: '%s'", text)
+	}
+
+	// 放大 10^D 倍后四舍五入为整数
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Scale)), nil)
+	r.Mul(r, new(big.Rat).SetInt(pow))
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(rem, 1)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.Length)), nil)
+	limit.Sub(limit, big.NewInt(1))
+	if q.CmpAbs(limit) > 0 {
+		if q.Sign() < 0 {
+			q.Neg(limit)
+		} else {
+			q.Set(limit)
+		}
+	}
+
+	digits := new(big.Int).Abs(q).String()
+	if t.Scale > 0 {
+		if len(digits) <= t.Scale {
+			digits = strings.Repeat("0", t.Scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-t.Scale] + "." + digits[len(digits)-t.Scale:]
+	}
+	if q.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits, nil
+}
+
+// castString 把值转换为字符串，浮点数不使用科学计数法，日期时间使用 SQL 格式
+func castString(v interface{}) string {
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case bool:
+		if x {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format("2006-01-02 15:04:05.999999")
+	}
+	return ToString(v)
+}
+
+// castTime 把值转换为日期时间，数值按 YYYYMMDD[hhmmss] 解析
+func castTime(v interface{}) (time.Time, error) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case string, []byte:
+		s, _ := asText(x)
+		return ParseDateTime(s)
+	}
+	if i, ok := asInt64(v); ok {
+		return ParseDateTime(strconv.FormatInt(i, 10))
+	}
+	return time.Time{}, fmt.Errorf("cannot convert %T to a datetime", v)
+}
+
+// formatTime 返回 hh:mm:ss[.fraction] 形式的时间
+func formatTime(tm time.Time, fsp int) string {
+	if fsp <= 0 {
+		return tm.Round(time.Second).Format("15:04:05")
+	}
+	return tm.Round(time.Duration(math.Pow(10, float64(9-fsp)))).Format("15:04:05." + strings.Repeat("0", fsp))
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringToNumber(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   float64
+		strict bool // 严格模式下是否成功
+	}{
+		{"1", 1, true},
+		{" -2.5 ", -2.5, true},
+		{"1e3", 1000, true},
+		{".5", 0.5, true},
+		{"12abc", 12, false},
+		{"1e", 1, false},
+		{"abc", 0, false},
+		{"", 0, false},
+		{"-", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := StringToNumber(tt.in)
+		assert.True(t, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	SetCoercionMode(CoercionStrict)
+	defer SetCoercionMode(CoercionMySQL)
+	assert.Equal(t, CoercionStrict, GetCoercionMode())
+	for _, tt := range tests {
+		_, ok := StringToNumber(tt.in)
+		assert.Equal(t, tt.strict, ok, tt.in)
+	}
+}
+
+func TestParseCoercionMode(t *testing.T) {
+	mode, err := ParseCoercionMode("")
+	require.NoError(t, err)
+	assert.Equal(t, CoercionMySQL, mode)
+
+	mode, err = ParseCoercionMode(" STRICT ")
+	require.NoError(t, err)
+	assert.Equal(t, CoercionStrict, mode)
+
+	_, err = ParseCoercionMode("loose")
+	assert.Error(t, err)
+}
+
+func TestCompareValues_Coercion(t *testing.T) {
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		a, b interface{}
+		op   string
+		want bool
+	}{
+		{"1", 1, "=", true},
+		{1, " 1", "=", true},
+		{int64(2), "10", "<", true},
+		{"1.50", 1.5, "=", true},
+		{"abc", 0, "=", true},
+		{"3 apples", int64(3), "=", true},
+		{[]byte("7"), 7, "=", true},
+		{day, "2024-03-15", "=", true},
+		{"2024-03-16 10:00:00", day, ">", true},
+		{"2024-03-15", day, "<>", false},
+	}
+	for _, tt := range tests {
+		got, err := CompareValues(tt.a, tt.b, tt.op)
+		require.NoError(t, err, "%v %s %v", tt.a, tt.op, tt.b)
+		assert.Equal(t, tt.want, got, "%v %s %v", tt.a, tt.op, tt.b)
+	}
+
+	// 排序和表达式求值使用的三路比较规则相同
+	assert.Equal(t, 0, CompareValuesForSort(" 7 ", 7))
+	assert.Equal(t, -1, CompareValuesForSort("abc", 1))
+	assert.Equal(t, 1, CompareValuesForSort(day.Add(time.Hour), "2024-03-15"))
+
+	// IN 列表同样按数值比较
+	got, err := CompareValues("2", []interface{}{1, 2, 3}, "IN")
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	_, err = CompareValues(day, "not a date", "=")
+	assert.Error(t, err)
+
+	SetCoercionMode(CoercionStrict)
+	defer SetCoercionMode(CoercionMySQL)
+	_, err = CompareValues("abc", 0, "=")
+	assert.Error(t, err)
+	got, err = CompareValues(" 1", 1, "=")
+	require.NoError(t, err)
+	assert.True(t, got)
+}
+
+func TestJoinKey(t *testing.T) {
+	assert.Equal(t, int64(1), JoinKey(1, false))
+	assert.Equal(t, int64(1), JoinKey(uint8(1), false))
+	assert.Equal(t, int64(1), JoinKey(1.0, false))
+	assert.Equal(t, 1.5, JoinKey(float32(1.5), false))
+	assert.Equal(t, int64(1), JoinKey(" 1", true))
+	assert.Equal(t, int64(9007199254740993), JoinKey("9007199254740993", true))
+	assert.Equal(t, 2.5, JoinKey("2.5", true))
+	assert.Equal(t, "1", JoinKey("1", false))
+	assert.Equal(t, true, JoinKey(true, true))
+	assert.True(t, IsNumeric(uint8(1)))
+	assert.False(t, IsNumeric("1"))
+	assert.False(t, IsNumeric(true))
+}
+
+func TestParseCastType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want CastType
+		str  string
+	}{
+		{"signed", CastType{Name: "SIGNED", Length: -1}, "SIGNED"},
+		{"UNSIGNED INTEGER", CastType{Name: "UNSIGNED", Length: -1}, "UNSIGNED"},
+		{"CHAR(10)", CastType{Name: "CHAR", Length: 10}, "CHAR(10)"},
+		{"char charset utf8mb4", CastType{Name: "CHAR", Length: -1}, "CHAR"},
+		{"DECIMAL(10, 2)", CastType{Name: "DECIMAL", Length: 10, Scale: 2}, "DECIMAL(10,2)"},
+		{"DECIMAL", CastType{Name: "DECIMAL", Length: 10}, "DECIMAL(10,0)"},
+		{"DATETIME(3)", CastType{Name: "DATETIME", Length: -1, Scale: 3}, "DATETIME(3)"},
+		{"date", CastType{Name: "DATE", Length: -1}, "DATE"},
+	}
+	for _, tt := range tests {
+		got, err := ParseCastType(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+		assert.Equal(t, tt.str, got.String(), tt.in)
+	}
+
+	for _, bad := range []string{"BLOB", "DECIMAL(2,5)", "DECIMAL(70)", "DATETIME(7)", "CHAR(x)", "CHAR(1"} {
+		_, err := ParseCastType(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCastValue(t *testing.T) {
+	cast := func(v interface{}, typ string) interface{} {
+		t.Helper()
+		ct, err := ParseCastType(typ)
+		require.NoError(t, err, typ)
+		got, err := CastValue(v, ct)
+		require.NoError(t, err, "%v AS %s", v, typ)
+		return got
+	}
+
+	assert.Nil(t, cast(nil, "SIGNED"))
+	assert.Equal(t, int64(12), cast("12abc", "SIGNED"))
+	assert.Equal(t, int64(1), cast("1.7", "SIGNED"))
+	assert.Equal(t, int64(2), cast(1.5, "SIGNED"))
+	assert.Equal(t, int64(-2), cast(-1.5, "SIGNED"))
+	assert.Equal(t, int64(0), cast("abc", "SIGNED"))
+	assert.Equal(t, uint64(18446744073709551615), cast(-1, "UNSIGNED"))
+	assert.Equal(t, "3.14", cast("3.14159", "DECIMAL(10,2)"))
+	assert.Equal(t, "99.99", cast(12345, "DECIMAL(4,2)"))
+	assert.Equal(t, "-99.99", cast(-12345, "DECIMAL(4,2)"))
+	// 在十进制文本上舍入：二进制浮点数 1.005 略小于 1.005，但结果与 MySQL 一致为 1.01
+	assert.Equal(t, "1.01", cast(1.005, "DECIMAL(10,2)"))
+	assert.Equal(t, "-1.01", cast("-1.005", "DECIMAL(10,2)"))
+	assert.Equal(t, "0.00", cast(-0.004, "DECIMAL(10,2)"))
+	assert.Equal(t, "5.00", cast(5, "DECIMAL(10,2)"))
+	assert.Equal(t, "0.10", cast(0.1, "DECIMAL(3,2)"))
+	assert.Equal(t, "123456789012345678901234567890", cast("123456789012345678901234567890.4", "DECIMAL(30)"))
+	assert.Equal(t, 2.5, cast(" 2.5", "DOUBLE"))
+	assert.Equal(t, "42", cast(42, "CHAR"))
+	assert.Equal(t, "0.1", cast(0.1, "CHAR"))
+	assert.Equal(t, "he", cast("hello", "CHAR(2)"))
+	assert.Equal(t, "ab\x00", cast("ab", "BINARY(3)"))
+	assert.Equal(t, "1", cast(true, "CHAR"))
+
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, day, cast("2024-03-15", "DATE"))
+	assert.Equal(t, day, cast("2024-03-15 10:20:30", "DATE"))
+	assert.Equal(t, day, cast(20240315, "DATE"))
+	assert.Equal(t, day.Add(10*time.Hour+20*time.Minute+30*time.Second), cast("2024-03-15T10:20:30", "DATETIME"))
+	assert.Equal(t, "2024-03-15", cast(day, "CHAR"))
+	assert.Equal(t, int64(20240315000000), cast(day, "SIGNED"))
+	assert.Equal(t, "10:20:30", cast("10:20:30", "TIME"))
+	assert.Equal(t, "10:20:30.500", cast("2024-03-15 10:20:30.5", "TIME(3)"))
+
+	_, err := CastValue("not a date", CastType{Name: "DATE", Length: -1})
+	assert.Error(t, err)
+
+	SetCoercionMode(CoercionStrict)
+	defer SetCoercionMode(CoercionMySQL)
+	_, err = CastValue("12abc", CastType{Name: "SIGNED", Length: -1})
+	assert.Error(t, err)
+}
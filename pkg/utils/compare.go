@@ -34,9 +34,75 @@ func asInt64(v interface{}) (int64, bool) {
 	}
 }
 
+// TriBool is the result of a predicate under SQL three-valued logic.
+type TriBool int8
+
+const (
+	TriFalse TriBool = iota
+	TriTrue
+	TriUnknown
+)
+
+// TriBoolOf converts a Go bool to TriTrue or TriFalse
+func TriBoolOf(b bool) TriBool {
+	if b {
+		return TriTrue
+	}
+	return TriFalse
+}
+
+// IsTrue reports whether t is TRUE; WHERE, HAVING and ON keep a row only in that case
+func (t TriBool) IsTrue() bool {
+	return t == TriTrue
+}
+
+// Not returns NOT t (NOT UNKNOWN is UNKNOWN)
+func (t TriBool) Not() TriBool {
+	switch t {
+	case TriTrue:
+		return TriFalse
+	case TriFalse:
+		return TriTrue
+	default:
+		return TriUnknown
+	}
+}
+
+// And returns t AND o (FALSE dominates UNKNOWN)
+func (t TriBool) And(o TriBool) TriBool {
+	if t == TriFalse || o == TriFalse {
+		return TriFalse
+	}
+	if t == TriUnknown || o == TriUnknown {
+		return TriUnknown
+	}
+	return TriTrue
+}
+
+// Or returns t OR o (TRUE dominates UNKNOWN)
+func (t TriBool) Or(o TriBool) TriBool {
+	if t == TriTrue || o == TriTrue {
+		return TriTrue
+	}
+	if t == TriUnknown || o == TriUnknown {
+		return TriUnknown
+	}
+	return TriFalse
+}
+
 // CompareValues compares two values with given operator
-// Returns true if comparison matches, false otherwise
+// Returns true only if the comparison is TRUE; UNKNOWN (e.g. any comparison with NULL) yields false
 func CompareValues(a, b interface{}, operator string) (bool, error) {
+	result, err := CompareValuesTri(a, b, operator)
+	return result == TriTrue, err
+}
+
+// CompareValuesTri compares two values with given operator using SQL three-valued logic:
+//   - comparisons, LIKE, REGEXP and BETWEEN with a NULL operand are UNKNOWN
+//   - x IN (...) is UNKNOWN when x is NULL, or when nothing matches and the list contains NULL
+//   - NOT IN / NOT BETWEEN / NOT LIKE negate the positive result, so UNKNOWN stays UNKNOWN
+//   - IS [NOT] NULL and the NULL-safe equality <=> never return UNKNOWN
+func CompareValuesTri(a, b interface{}, operator string) (TriBool, error) {
 	// Normalize operator
 	op := strings.ToUpper(operator)
 
@@ -46,35 +112,58 @@ func CompareValues(a, b interface{}, operator string) (bool, error) {
 		return compareIn(a, b)
 	case "NOT IN":
 		result, err := compareIn(a, b)
-		return !result, err
+		return result.Not(), err
 	case "BETWEEN":
 		return compareBetween(a, b)
 	case "NOT BETWEEN":
 		result, err := compareBetween(a, b)
-		return !result, err
-	case "LIKE":
-		return compareLike(a, b)
-	case "NOT LIKE":
-		result, err := compareLike(a, b)
-		return !result, err
+		return result.Not(), err
+	case "LIKE", "NOT LIKE", "ILIKE", "NOT ILIKE":
+		if a == nil || b == nil {
+			return TriUnknown, nil
+		}
+		var result bool
+		var err error
+		if strings.HasSuffix(op, "ILIKE") {
+			result, err = compareLikeWithCollation(a, b, "utf8mb4_general_ci")
+		} else {
+			result, err = compareLike(a, b)
+		}
+		if strings.HasPrefix(op, "NOT ") {
+			result = !result
+		}
+		return TriBoolOf(result), err
+	case "REGEXP", "RLIKE", "NOT REGEXP", "NOT RLIKE":
+		if a == nil || b == nil {
+			return TriUnknown, nil
+		}
+		result, err := MatchesRegexp(ToString(a), ToString(b), "")
+		if strings.HasPrefix(op, "NOT ") {
+			result = !result
+		}
+		return TriBoolOf(result), err
 	case "IS NULL", "ISNULL":
-		return a == nil, nil
+		return TriBoolOf(a == nil), nil
 	case "IS NOT NULL", "ISNOTNULL":
-		return a != nil, nil
+		return TriBoolOf(a != nil), nil
+	case "<=>", "NULLEQ":
+		if a == nil || b == nil {
+			return TriBoolOf(a == nil && b == nil), nil
+		}
+		op = "="
 	}
 
-	// Handle nil values
+	// Any other comparison with NULL is UNKNOWN
 	if a == nil || b == nil {
-		switch op {
-		case "=", "EQ":
-			return a == nil && b == nil, nil
-		case "!=", "NEQ":
-			return !(a == nil && b == nil), nil
-		default:
-			return false, nil
-		}
+		return TriUnknown, nil
 	}
 
+	result, err := compareNonNull(a, b, op, operator)
+	return TriBoolOf(result), err
+}
+
+// compareNonNull compares two non-NULL values with a normalized comparison operator
+func compareNonNull(a, b interface{}, op, operator string) (bool, error) {
 	// Try int64 comparison first (preserves precision for large integers)
 	aInt, aIsInt := asInt64(a)
 	bInt, bIsInt := asInt64(b)
@@ -83,7 +172,7 @@ func CompareValues(a, b interface{}, operator string) (bool, error) {
 		switch op {
 		case "=", "EQ":
 			return aInt == bInt, nil
-		case "!=", "NEQ":
+		case "!=", "<>", "NE", "NEQ":
 			return aInt != bInt, nil
 		case ">", "GT":
 			return aInt > bInt, nil
@@ -106,7 +195,7 @@ func CompareValues(a, b interface{}, operator string) (bool, error) {
 		switch op {
 		case "=", "EQ":
 			return aNum == bNum, nil
-		case "!=", "NEQ":
+		case "!=", "<>", "NE", "NEQ":
 			return aNum != bNum, nil
 		case ">", "GT":
 			return aNum > bNum, nil
@@ -129,7 +218,7 @@ func CompareValues(a, b interface{}, operator string) (bool, error) {
 		switch op {
 		case "=", "EQ":
 			return aStr == bStr, nil
-		case "!=", "NEQ":
+		case "!=", "<>", "NE", "NEQ":
 			return aStr != bStr, nil
 		case ">", "GT":
 			return aStr > bStr, nil
@@ -144,6 +233,11 @@ func CompareValues(a, b interface{}, operator string) (bool, error) {
 		}
 	}
 
+	// Mixed types: number vs string compares numerically, datetime vs string as datetime
+	if cmp, ok := coerceCompare(a, b); ok {
+		return compareResult(cmp, op, operator)
+	}
+
 	return false, fmt.Errorf("cannot compare %T with %T", a, b)
 }
 
@@ -186,6 +280,11 @@ func CompareValuesForSort(a, b interface{}) int {
 		return 0
 	}
 
+	// Mixed types: number vs string compares numerically, datetime vs string as datetime
+	if cmp, ok := coerceCompare(a, b); ok {
+		return cmp
+	}
+
 	// String comparison
 	aStr := fmt.Sprintf("%v", a)
 	bStr := fmt.Sprintf("%v", b)
@@ -199,62 +298,74 @@ func CompareValuesForSort(a, b interface{}) int {
 }
 
 // compareIn checks if value is in list
-func compareIn(a, b interface{}) (bool, error) {
+// A NULL value, or a non-matching list that contains NULL, yields UNKNOWN
+func compareIn(a, b interface{}) (TriBool, error) {
 	values, ok := b.([]interface{})
 	if !ok {
-		return false, fmt.Errorf("IN operator requires array value")
+		return TriFalse, fmt.Errorf("IN operator requires array value")
+	}
+	if len(values) == 0 {
+		return TriFalse, nil
+	}
+	if a == nil {
+		return TriUnknown, nil
 	}
 
+	result := TriFalse
 	for _, v := range values {
-		if result, err := CompareValues(a, v, "="); err == nil && result {
-			return true, nil
+		if v == nil {
+			result = TriUnknown
+			continue
+		}
+		if matched, err := compareNonNull(a, v, "=", "="); err == nil && matched {
+			return TriTrue, nil
 		}
 	}
-	return false, nil
+	return result, nil
 }
 
 // compareBetween checks if value is between min and max
-func compareBetween(a, b interface{}) (bool, error) {
+// Evaluated as (a >= min) AND (a <= max), so NULL bounds may still yield FALSE
+func compareBetween(a, b interface{}) (TriBool, error) {
 	slice, ok := b.([]interface{})
 	if !ok || len(slice) < 2 {
-		return false, fmt.Errorf("BETWEEN operator requires array with 2 elements")
+		return TriFalse, fmt.Errorf("BETWEEN operator requires array with 2 elements")
 	}
 
-	min := slice[0]
-	max := slice[1]
-
 	// Check lower bound
-	lowerOK, err := CompareValues(a, min, ">=")
-	if err != nil || !lowerOK {
-		return false, err
+	lower, err := CompareValuesTri(a, slice[0], ">=")
+	if err != nil {
+		return TriFalse, err
 	}
 
 	// Check upper bound
-	upperOK, err := CompareValues(a, max, "<=")
-	if err != nil || !upperOK {
-		return false, err
+	upper, err := CompareValuesTri(a, slice[1], "<=")
+	if err != nil {
+		return TriFalse, err
 	}
 
-	return true, nil
+	return lower.And(upper), nil
 }
 
 // MapOperator 映射parser操作符到标准操作符
-// 支持的parser操作符: gt, gte, lt, lte, eq, ne, ===, !=, like, not like
-// 返回标准SQL操作符: >, >=, <, <=, =, !=, LIKE, NOT LIKE
+// 支持的parser操作符: gt, gte/ge, lt, lte/le, eq, ne, ===, !=, nulleq, like, not like
+// 返回标准SQL操作符: >, >=, <, <=, =, !=, <=>, LIKE, NOT LIKE
 func MapOperator(parserOp string) string {
 	switch parserOp {
 	case "gt":
 		return ">"
-	case "gte":
+	case "gte", "ge":
 		return ">="
 	case "lt":
 		return "<"
-	case "lte":
+	case "lte", "le":
 		return "<="
 	case "eq", "===":
 		return "="
 	case "ne", "!=":
 		return "!="
+	case "nulleq":
+		return "<=>"
 	case "like", "LIKE":
 		return "LIKE"
 	case "not like", "NOT LIKE", "notlike", "NOTLIKE":
@@ -275,28 +386,36 @@ func CompareValuesWithCollation(a, b interface{}, operator, collation string) (b
 
 	// Special operators delegate to collation-aware variants
 	switch op {
-	case "LIKE":
-		return compareLikeWithCollation(a, b, collation)
-	case "NOT LIKE":
+	case "LIKE", "NOT LIKE":
+		if a == nil || b == nil {
+			return false, nil
+		}
 		result, err := compareLikeWithCollation(a, b, collation)
-		return !result, err
-	case "IN", "NOT IN", "BETWEEN", "NOT BETWEEN":
+		if op == "NOT LIKE" {
+			result = !result
+		}
+		return result, err
+	case "REGEXP", "RLIKE", "NOT REGEXP", "NOT RLIKE":
+		if a == nil || b == nil {
+			return false, nil
+		}
+		result, err := MatchesRegexp(ToString(a), ToString(b), collation)
+		if strings.HasPrefix(op, "NOT ") {
+			result = !result
+		}
+		return result, err
+	case "ILIKE", "NOT ILIKE", "IN", "NOT IN", "BETWEEN", "NOT BETWEEN", "IS NULL", "ISNULL", "IS NOT NULL", "ISNOTNULL":
 		return CompareValues(a, b, operator)
-	case "IS NULL", "ISNULL":
-		return a == nil, nil
-	case "IS NOT NULL", "ISNOTNULL":
-		return a != nil, nil
+	case "<=>", "NULLEQ":
+		if a == nil || b == nil {
+			return a == nil && b == nil, nil
+		}
+		op = "="
 	}
 
+	// Comparison with NULL is UNKNOWN, which never matches
 	if a == nil || b == nil {
-		switch op {
-		case "=", "EQ":
-			return a == nil && b == nil, nil
-		case "!=", "NEQ":
-			return !(a == nil && b == nil), nil
-		default:
-			return false, nil
-		}
+		return false, nil
 	}
 
 	// Numeric comparison is collation-independent
@@ -323,7 +442,7 @@ func CompareValuesWithCollation(a, b interface{}, operator, collation string) (b
 		switch op {
 		case "=", "EQ":
 			return cmp == 0, nil
-		case "!=", "NEQ":
+		case "!=", "<>", "NE", "NEQ":
 			return cmp != 0, nil
 		case ">", "GT":
 			return cmp > 0, nil
@@ -338,6 +457,11 @@ func CompareValuesWithCollation(a, b interface{}, operator, collation string) (b
 		}
 	}
 
+	// Mixed types: number vs string compares numerically, datetime vs string as datetime
+	if cmp, ok := coerceCompare(a, b); ok {
+		return compareResult(cmp, op, operator)
+	}
+
 	return false, fmt.Errorf("cannot compare %T with %T", a, b)
 }
 
@@ -382,6 +506,10 @@ func CompareValuesForSortWithCollation(a, b interface{}, collation string) int {
 		return 0
 	}
 
+	if cmp, ok := coerceCompare(a, b); ok {
+		return cmp
+	}
+
 	// String comparison with collation
 	aStr := fmt.Sprintf("%v", a)
 	bStr := fmt.Sprintf("%v", b)
@@ -408,7 +536,7 @@ func compareLikeWithCollation(a, b interface{}, collation string) (bool, error)
 		bStr = strings.ReplaceAll(bStr, "*", "%")
 	}
 
-	return MatchesLikeWithCollation(aStr, bStr, collation), nil
+	return MatchesLikeWith(aStr, bStr, DefaultLikeEscape, collation), nil
 }
 
 // compareLike checks if value matches pattern
@@ -425,9 +553,9 @@ func compareLike(a, b interface{}) (bool, error) {
 
 	// Check for * wildcard (glob style)
 	if strings.Contains(bStr, "*") {
-		normalizedPattern := strings.ReplaceAll(bStr, "*", "%")
-		return MatchesLike(aStr, normalizedPattern), nil
+		bStr = strings.ReplaceAll(bStr, "*", "%")
 	}
 
-	return MatchesLike(aStr, bStr), nil
+	// Backslash escapes %, _ and itself, as in MySQL's default LIKE ... ESCAPE '\\'
+	return MatchesLikeEscape(aStr, bStr, DefaultLikeEscape), nil
 }
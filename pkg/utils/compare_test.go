@@ -36,11 +36,19 @@ func TestCompareValues(t *testing.T) {
 		{"字符串大于", "world", "hello", ">", true, false},
 		{"字符串小于", "hello", "world", "<", true, false},
 
-		// nil 值处理
-		{"nil相等", nil, nil, "=", true, false},
-		{"nil不等", nil, 10, "!=", true, false},
+		// nil 值处理：与 NULL 比较的结果为 UNKNOWN，不匹配
+		{"nil相等", nil, nil, "=", false, false},
+		{"nil不等", nil, 10, "!=", false, false},
 		{"nil和值比较", nil, 10, ">", false, false},
 		{"nil和nil不等", nil, nil, "!=", false, false},
+		{"nil LIKE", nil, "%", "LIKE", false, false},
+		{"nil NOT LIKE", nil, "a%", "NOT LIKE", false, false},
+
+		// NULL 安全等于
+		{"<=> nil和nil", nil, nil, "<=>", true, false},
+		{"<=> nil和值", nil, 10, "<=>", false, false},
+		{"<=> 值相等", 10, int64(10), "<=>", true, false},
+		{"<> 不等", 10, 20, "<>", true, false},
 
 		// IN 操作符
 		{"IN中存在", 5, []interface{}{1, 2, 3, 4, 5}, "IN", true, false},
@@ -228,6 +236,87 @@ func TestCompareBetween(t *testing.T) {
 	}
 }
 
+func TestCompareValuesTri(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        interface{}
+		b        interface{}
+		operator string
+		expected TriBool
+	}{
+		{"NULL = 值", nil, 1, "=", TriUnknown},
+		{"NULL = NULL", nil, nil, "=", TriUnknown},
+		{"值 <> NULL", 1, nil, "<>", TriUnknown},
+		{"NULL LIKE", nil, "%", "LIKE", TriUnknown},
+		{"LIKE NULL模式", "a", nil, "NOT LIKE", TriUnknown},
+		{"IS NULL", nil, nil, "IS NULL", TriTrue},
+		{"IS NOT NULL", nil, nil, "IS NOT NULL", TriFalse},
+		{"<=> NULL和NULL", nil, nil, "<=>", TriTrue},
+		{"<=> NULL和值", nil, 1, "NULLEQ", TriFalse},
+		{"<=> 值不等", 1, 2, "<=>", TriFalse},
+
+		// IN 列表中的 NULL
+		{"NULL IN 列表", nil, []interface{}{1, 2}, "IN", TriUnknown},
+		{"NULL IN 空列表", nil, []interface{}{}, "IN", TriFalse},
+		{"IN 命中且含NULL", 1, []interface{}{nil, 1}, "IN", TriTrue},
+		{"IN 未命中且含NULL", 3, []interface{}{1, nil}, "IN", TriUnknown},
+		{"NOT IN 未命中且含NULL", 3, []interface{}{1, nil}, "NOT IN", TriUnknown},
+		{"NOT IN 命中", 1, []interface{}{1, nil}, "NOT IN", TriFalse},
+		{"NOT IN 不含NULL", 3, []interface{}{1, 2}, "NOT IN", TriTrue},
+
+		// BETWEEN 等价于 a >= min AND a <= max
+		{"NULL BETWEEN", nil, []interface{}{1, 10}, "BETWEEN", TriUnknown},
+		{"BETWEEN NULL下界且超出上界", 20, []interface{}{nil, 10}, "BETWEEN", TriFalse},
+		{"BETWEEN NULL下界且在上界内", 5, []interface{}{nil, 10}, "BETWEEN", TriUnknown},
+		{"NOT BETWEEN NULL下界且超出上界", 20, []interface{}{nil, 10}, "NOT BETWEEN", TriTrue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompareValuesTri(tt.a, tt.b, tt.operator)
+			if err != nil {
+				t.Fatalf("CompareValuesTri(%v, %v, %q) error = %v", tt.a, tt.b, tt.operator, err)
+			}
+			if got != tt.expected {
+				t.Errorf("CompareValuesTri(%v, %v, %q) = %v, want %v", tt.a, tt.b, tt.operator, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTriBoolLogic(t *testing.T) {
+	values := []TriBool{TriFalse, TriTrue, TriUnknown}
+	// 真值表按 FALSE, TRUE, UNKNOWN 排列
+	and := [3][3]TriBool{
+		{TriFalse, TriFalse, TriFalse},
+		{TriFalse, TriTrue, TriUnknown},
+		{TriFalse, TriUnknown, TriUnknown},
+	}
+	or := [3][3]TriBool{
+		{TriFalse, TriTrue, TriUnknown},
+		{TriTrue, TriTrue, TriTrue},
+		{TriUnknown, TriTrue, TriUnknown},
+	}
+	not := [3]TriBool{TriTrue, TriFalse, TriUnknown}
+
+	for i, a := range values {
+		if got := a.Not(); got != not[i] {
+			t.Errorf("NOT %v = %v, want %v", a, got, not[i])
+		}
+		for j, b := range values {
+			if got := a.And(b); got != and[i][j] {
+				t.Errorf("%v AND %v = %v, want %v", a, b, got, and[i][j])
+			}
+			if got := a.Or(b); got != or[i][j] {
+				t.Errorf("%v OR %v = %v, want %v", a, b, got, or[i][j])
+			}
+		}
+	}
+	if TriUnknown.IsTrue() || TriFalse.IsTrue() || !TriTrue.IsTrue() {
+		t.Error("only TRUE should satisfy IsTrue")
+	}
+}
+
 func TestCompareLike(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -479,7 +568,10 @@ func TestCompareValuesWithContextErrors(t *testing.T) {
 }
 
 func TestCompareValuesErrorWrapping(t *testing.T) {
-	// 测试错误包装
+	// 测试错误包装；默认的 mysql 规则下字符串与数字可以比较，严格模式下报错
+	SetCoercionMode(CoercionStrict)
+	defer SetCoercionMode(CoercionMySQL)
+
 	tests := []struct {
 		name     string
 		a        interface{}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"crypto/sha1"
+	"crypto/subtle"
 	"encoding/hex"
+	"strings"
 )
 
 // GeneratePasswordHash 生成MySQL native password hash
@@ -87,3 +89,28 @@ func VerifyPasswordWithHash(storedHash, password string) bool {
 	expectedHash := GenerateHashedPassword(password)
 	return expectedHash == storedHash
 }
+
+// VerifyNativeAuthResponse 用存储的哈希校验客户端的 mysql_native_password 认证响应，服务器不需要明文密码
+// 客户端发送 SHA1(password) XOR SHA1(salt + SHA1(SHA1(password)))，
+// 用存储的 SHA1(SHA1(password)) 还原出 SHA1(password) 后再次哈希与存储值比较
+func VerifyNativeAuthResponse(storedHash string, authResponse []byte, salt []byte) bool {
+	if storedHash == "" {
+		return len(authResponse) == 0
+	}
+	stored, err := hex.DecodeString(strings.TrimPrefix(storedHash, "*"))
+	if err != nil || len(stored) != sha1.Size || len(authResponse) != sha1.Size {
+		return false
+	}
+
+	// SHA1(salt + SHA1(SHA1(password)))
+	mask := sha1.Sum(append(append([]byte(nil), salt...), stored...))
+
+	// SHA1(password)
+	stage1 := make([]byte, sha1.Size)
+	for i := range stage1 {
+		stage1[i] = authResponse[i] ^ mask[i]
+	}
+
+	candidate := sha1.Sum(stage1)
+	return subtle.ConstantTimeCompare(candidate[:], stored) == 1
+}
@@ -422,6 +422,36 @@ func TestPasswordHashRoundTrip(t *testing.T) {
 	}
 }
 
+func TestVerifyNativeAuthResponse(t *testing.T) {
+	salt := []byte("abcdefghij0123456789")
+	storedHash := GenerateHashedPassword("P@ssw0rd!")
+	response := func(password string, salt []byte) []byte {
+		b, _ := hex.DecodeString(GeneratePasswordHash(password, salt))
+		return b
+	}
+
+	if !VerifyNativeAuthResponse(storedHash, response("P@ssw0rd!", salt), salt) {
+		t.Error("VerifyNativeAuthResponse() failed for the correct password")
+	}
+	if VerifyNativeAuthResponse(storedHash, response("wrong", salt), salt) {
+		t.Error("VerifyNativeAuthResponse() should fail for a wrong password")
+	}
+	if VerifyNativeAuthResponse(storedHash, response("P@ssw0rd!", []byte("another-salt-0000000")), salt) {
+		t.Error("VerifyNativeAuthResponse() should fail for a response to another salt")
+	}
+	if VerifyNativeAuthResponse(storedHash, nil, salt) {
+		t.Error("VerifyNativeAuthResponse() should fail without a response")
+	}
+
+	// 没有密码的账号只接受空响应
+	if !VerifyNativeAuthResponse("", nil, salt) {
+		t.Error("VerifyNativeAuthResponse() should accept an empty response for an empty password")
+	}
+	if VerifyNativeAuthResponse("", response("x", salt), salt) {
+		t.Error("VerifyNativeAuthResponse() should reject a password for an account without one")
+	}
+}
+
 func TestPasswordHashCollision(t *testing.T) {
 	// 测试不同密码不应产生相同哈希
 	passwords := []string{
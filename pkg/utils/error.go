@@ -1,9 +1,7 @@
 package utils
 
 import (
-	"context"
-	"errors"
-	"strings"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 )
 
 // MySQL错误码常量定义
@@ -17,48 +15,25 @@ const (
 	ErrEmptyQuery  = 1065 // ER_EMPTY_QUERY
 	ErrInterrupted = 1317 // ER_QUERY_INTERRUPTED
 
+	// Unknown error
+	ErrUnknown = 1105 // ER_UNKNOWN_ERROR
+
+	// Connection errors
+	ErrAccessDenied           = 1045 // ER_ACCESS_DENIED_ERROR
+	ErrConCount               = 1040 // ER_CON_COUNT_ERROR
+	ErrTooManyUserConnections = 1203 // ER_TOO_MANY_USER_CONNECTIONS
+
 	// SQL状态码
 	SqlStateNoSuchTable   = "42S02" // Table does not exist
 	SqlStateBadFieldError = "42S22" // Column does not exist
 	SqlStateSyntaxError   = "42000" // Syntax error or access violation
 	SqlStateUnknownError  = "HY000" // General error
+	SqlStateConnRejected  = "08004" // Server rejected the connection
+	SqlStateAccessDenied  = "28000" // Invalid authorization specification
 )
 
 // MapErrorCode 将错误映射到MySQL错误码和SQL状态码
-// 返回 (errorCode, sqlState)
+// 返回 (errorCode, sqlState)，具体规则见 mysqlerrors.Classify
 func MapErrorCode(err error) (uint16, string) {
-	if err == nil {
-		return 0, "00000"
-	}
-
-	// Check error message content (case-insensitive)
-	errMsg := strings.ToLower(err.Error())
-
-	// Check for column first (more specific)
-	if strings.Contains(errMsg, "column") && strings.Contains(errMsg, "not found") {
-		return ErrBadFieldError, SqlStateBadFieldError
-	}
-
-	// Then check for table
-	if strings.Contains(errMsg, "table") && strings.Contains(errMsg, "not found") {
-		return ErrNoSuchTable, SqlStateNoSuchTable
-	}
-
-	// Syntax error
-	if strings.Contains(errMsg, "syntax") || strings.Contains(errMsg, "parse") {
-		return ErrParseError, SqlStateSyntaxError
-	}
-
-	// Empty query
-	if strings.Contains(errMsg, "no statements found") || strings.Contains(errMsg, "empty query") {
-		return ErrEmptyQuery, SqlStateSyntaxError
-	}
-
-	// Timeout or cancellation
-	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
-		return ErrInterrupted, SqlStateUnknownError
-	}
-
-	// Default to syntax error
-	return ErrParseError, SqlStateSyntaxError
+	return mysqlerrors.Classify(err)
 }
@@ -123,23 +123,36 @@ func TestMapErrorCode(t *testing.T) {
 			expectedCode:  ErrInterrupted,
 			expectedState: SqlStateUnknownError,
 		},
-		// 默认语法错误
+		// 无法识别的错误
 		{
 			name:          "其他错误",
 			err:           errors.New("some other error"),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "网络错误",
 			err:           errors.New("connection refused"),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "权限错误",
 			err:           errors.New("access denied"),
-			expectedCode:  ErrParseError,
+			expectedCode:  ErrAccessDenied,
+			expectedState: SqlStateAccessDenied,
+		},
+		// 连接准入错误
+		{
+			name:          "连接数过多",
+			err:           errors.New("Too many connections"),
+			expectedCode:  ErrConCount,
+			expectedState: SqlStateConnRejected,
+		},
+		{
+			name:          "用户连接数过多",
+			err:           errors.New("User bob already has more than 'max_user_connections' active connections"),
+			expectedCode:  ErrTooManyUserConnections,
 			expectedState: SqlStateSyntaxError,
 		},
 	}
@@ -319,32 +332,32 @@ func TestMapErrorCodeEdgeCases(t *testing.T) {
 		{
 			name:          "空错误消息",
 			err:           errors.New(""),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "只有空格",
 			err:           errors.New("   "),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "只有table",
 			err:           errors.New("table"),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "只有column",
 			err:           errors.New("column"),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "只有not found",
 			err:           errors.New("not found"),
-			expectedCode:  ErrParseError,
-			expectedState: SqlStateSyntaxError,
+			expectedCode:  ErrUnknown,
+			expectedState: SqlStateUnknownError,
 		},
 		{
 			name:          "特殊字符",
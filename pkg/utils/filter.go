@@ -4,7 +4,6 @@ import (
 	"strings"
 	"unicode"
 
-	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"golang.org/x/text/unicode/norm"
 
@@ -240,25 +239,7 @@ func matchSegmentAt(value string, pos int, seg string) bool {
 // For _ci collations, folds both value and pattern to lowercase before matching.
 // For _ai_ci collations, additionally strips accents via NFD decomposition.
 func MatchesLikeWithCollation(value, pattern, collation string) bool {
-	if collation == "" || collation == "utf8mb4_bin" || collation == "binary" {
-		return MatchesLike(value, pattern)
-	}
-
-	engine := GetGlobalCollationEngine()
-
-	// For accent-insensitive collations, strip accents first
-	if engine.IsAccentInsensitive(collation) {
-		value = stripAccents(value)
-		pattern = stripAccents(pattern)
-	}
-
-	// For case-insensitive collations, fold case
-	if engine.IsCaseInsensitive(collation) {
-		folder := cases.Fold()
-		value = folder.String(value)
-		pattern = folder.String(pattern)
-	}
-
+	value, pattern = foldForCollation(value, pattern, collation)
 	return MatchesLike(value, pattern)
 }
 
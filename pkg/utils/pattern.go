@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/cases"
+)
+
+// DefaultLikeEscape is the LIKE escape character used when no ESCAPE clause is given.
+const DefaultLikeEscape = '\\'
+
+// likeToken is one element of a compiled LIKE pattern.
+type likeToken struct {
+	kind byte // 'l' literal rune, '_' any single rune, '%' any sequence
+	r    rune
+}
+
+// MatchesLikeEscape implements SQL LIKE with an escape character: the escape
+// character makes the following %, _ or escape character match literally.
+// An escape of 0 disables escaping. '_' matches exactly one character (rune).
+func MatchesLikeEscape(value, pattern string, escape rune) bool {
+	// Patterns without escapes or '_' take the byte-oriented fast paths of MatchesLike
+	if (escape == 0 || !strings.ContainsRune(pattern, escape)) && !strings.ContainsRune(pattern, '_') {
+		return MatchesLike(value, pattern)
+	}
+	return matchLikeTokens([]rune(value), compileLikePattern(pattern, escape))
+}
+
+// MatchesLikeWith performs LIKE matching with an escape character and collation.
+// Case- and accent-insensitive collations fold value and pattern before matching.
+func MatchesLikeWith(value, pattern string, escape rune, collation string) bool {
+	value, pattern = foldForCollation(value, pattern, collation)
+	return MatchesLikeEscape(value, pattern, escape)
+}
+
+// compileLikePattern splits a LIKE pattern into tokens, resolving escapes.
+// A trailing escape character matches itself.
+func compileLikePattern(pattern string, escape rune) []likeToken {
+	runes := []rune(pattern)
+	tokens := make([]likeToken, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == escape && i+1 < len(runes):
+			i++
+			tokens = append(tokens, likeToken{kind: 'l', r: runes[i]})
+		case r == '%':
+			// Collapse consecutive % wildcards
+			if n := len(tokens); n == 0 || tokens[n-1].kind != '%' {
+				tokens = append(tokens, likeToken{kind: '%'})
+			}
+		case r == '_':
+			tokens = append(tokens, likeToken{kind: '_'})
+		default:
+			tokens = append(tokens, likeToken{kind: 'l', r: r})
+		}
+	}
+	return tokens
+}
+
+// matchLikeTokens matches value against compiled tokens, backtracking only to
+// the most recent % wildcard. O(n*m) in the worst case.
+func matchLikeTokens(value []rune, tokens []likeToken) bool {
+	vi, ti := 0, 0
+	starTi, starVi := -1, 0
+	for vi < len(value) {
+		if ti < len(tokens) {
+			tok := tokens[ti]
+			if tok.kind == '%' {
+				starTi, starVi = ti, vi
+				ti++
+				continue
+			}
+			if tok.kind == '_' || tok.r == value[vi] {
+				vi++
+				ti++
+				continue
+			}
+		}
+		if starTi < 0 {
+			return false
+		}
+		// Let the last % absorb one more character and retry
+		starVi++
+		vi = starVi
+		ti = starTi + 1
+	}
+	for ti < len(tokens) && tokens[ti].kind == '%' {
+		ti++
+	}
+	return ti == len(tokens)
+}
+
+// foldForCollation applies the accent and case folding of a collation to value and pattern.
+func foldForCollation(value, pattern, collation string) (string, string) {
+	if collation == "" || collation == "utf8mb4_bin" || collation == "binary" {
+		return value, pattern
+	}
+
+	engine := GetGlobalCollationEngine()
+
+	// For accent-insensitive collations, strip accents first
+	if engine.IsAccentInsensitive(collation) {
+		value = stripAccents(value)
+		pattern = stripAccents(pattern)
+	}
+
+	// For case-insensitive collations, fold case
+	if engine.IsCaseInsensitive(collation) {
+		folder := cases.Fold()
+		value = folder.String(value)
+		pattern = folder.String(pattern)
+	}
+	return value, pattern
+}
+
+// DefaultRegexpCacheSize is the number of compiled REGEXP patterns kept by the global cache.
+const DefaultRegexpCacheSize = 256
+
+// RegexpCache is a concurrency-safe LRU cache of compiled regular expressions,
+// so that REGEXP filters compile each pattern once rather than once per row.
+type RegexpCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type regexpCacheEntry struct {
+	key string
+	re  *regexp.Regexp
+}
+
+// NewRegexpCache creates a regexp cache holding at most maxEntries patterns.
+func NewRegexpCache(maxEntries int) *RegexpCache {
+	return &RegexpCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+var globalRegexpCache = NewRegexpCache(DefaultRegexpCacheSize)
+
+// Compile returns the compiled pattern, compiling and caching it on a miss.
+// Invalid patterns are not cached.
+func (c *RegexpCache) Compile(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	key := pattern
+	if caseInsensitive {
+		key = "(?i)" + pattern
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		re := elem.Value.(*regexpCacheEntry).re
+		c.mu.Unlock()
+		return re, nil
+	}
+	c.mu.Unlock()
+
+	re, err := regexp.Compile(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		return elem.Value.(*regexpCacheEntry).re, nil
+	}
+	c.items[key] = c.ll.PushFront(&regexpCacheEntry{key: key, re: re})
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*regexpCacheEntry).key)
+	}
+	return re, nil
+}
+
+// Len returns the number of cached patterns.
+func (c *RegexpCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// MatchesRegexp reports whether value contains a match of the regular expression
+// pattern (MySQL REGEXP / RLIKE semantics). Case-insensitive collations match
+// case-insensitively. Compiled patterns are cached.
+func MatchesRegexp(value, pattern, collation string) (bool, error) {
+	caseInsensitive := collation != "" && GetGlobalCollationEngine().IsCaseInsensitive(collation)
+	re, err := globalRegexpCache.Compile(pattern, caseInsensitive)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestMatchesLikeEscape tests LIKE matching with an escape character
+func TestMatchesLikeEscape(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		pattern  string
+		escape   rune
+		expected bool
+	}{
+		{"escaped percent matches literal", "50%", `50\%`, '\\', true},
+		{"escaped percent is not a wildcard", "500", `50\%`, '\\', false},
+		{"escaped underscore", "a_b", `a\_b`, '\\', true},
+		{"escaped underscore is not a wildcard", "axb", `a\_b`, '\\', false},
+		{"custom escape", "10% off", "10!% %", '!', true},
+		{"custom escape does not escape backslash", `a\b`, `a\b`, '!', true},
+		{"escaped escape", "a!b", "a!!b", '!', true},
+		{"trailing escape matches itself", `ab\`, `ab\`, '\\', true},
+		{"underscore matches one rune", "你好", "你_", '\\', true},
+		{"underscore requires a rune", "你", "你_", '\\', false},
+		{"wildcards mixed with escape", "x_1_y", `%\_1\_%`, '\\', true},
+		{"no escape", `a\%`, `a\%`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesLikeEscape(tt.value, tt.pattern, tt.escape); got != tt.expected {
+				t.Errorf("MatchesLikeEscape(%q, %q, %q) = %v, expected %v", tt.value, tt.pattern, tt.escape, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMatchesLikeWith tests collation-aware LIKE matching
+func TestMatchesLikeWith(t *testing.T) {
+	if !MatchesLikeWith("Hello", "h%", '\\', "utf8mb4_general_ci") {
+		t.Error("expected case-insensitive match under _ci collation")
+	}
+	if MatchesLikeWith("Hello", "h%", '\\', "utf8mb4_bin") {
+		t.Error("expected case-sensitive mismatch under binary collation")
+	}
+	if !MatchesLikeWith("50% OFF", `50\% o%`, '\\', "utf8mb4_general_ci") {
+		t.Error("expected escape to apply together with case folding")
+	}
+}
+
+// TestMatchesRegexp tests REGEXP matching
+func TestMatchesRegexp(t *testing.T) {
+	tests := []struct {
+		value     string
+		pattern   string
+		collation string
+		expected  bool
+	}{
+		{"hello", "^h", "", true},
+		{"hello", "l+o$", "", true},
+		{"Hello", "^h", "", false},
+		{"Hello", "^h", "utf8mb4_general_ci", true},
+		{"abc123", "[0-9]{3}", "", true},
+		{"abc", "[0-9]", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s~%s", tt.value, tt.pattern), func(t *testing.T) {
+			got, err := MatchesRegexp(tt.value, tt.pattern, tt.collation)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("MatchesRegexp(%q, %q, %q) = %v, expected %v", tt.value, tt.pattern, tt.collation, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := MatchesRegexp("abc", "(", ""); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+// TestRegexpCache tests compiled pattern reuse and LRU eviction
+func TestRegexpCache(t *testing.T) {
+	cache := NewRegexpCache(2)
+
+	re1, err := cache.Compile("^a", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re2, _ := cache.Compile("^a", false)
+	if re1 != re2 {
+		t.Error("expected cached pattern to be reused")
+	}
+
+	// Case-insensitive variants are cached separately
+	if _, err := cache.Compile("^a", true); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 cached patterns, got %d", cache.Len())
+	}
+
+	// Touch "^a" so that the (?i) variant is evicted next
+	cache.Compile("^a", false)
+	cache.Compile("^b", false)
+	if cache.Len() != 2 {
+		t.Errorf("expected cache to stay at capacity, got %d", cache.Len())
+	}
+	if re3, _ := cache.Compile("^a", false); re3 != re1 {
+		t.Error("expected recently used pattern to survive eviction")
+	}
+
+	// Invalid patterns are not cached
+	if _, err := cache.Compile("(", false); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected invalid pattern not to be cached, got %d entries", cache.Len())
+	}
+}
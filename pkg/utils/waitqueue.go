@@ -0,0 +1,47 @@
+package utils
+
+// WaitQueue is a FIFO queue of goroutines waiting for a slot.
+// It is not safe for concurrent use: callers guard it with the same lock
+// that protects the slot counters they admit waiters against.
+type WaitQueue struct {
+	waiters []chan struct{}
+}
+
+// Enqueue adds a waiter to the back of the queue and returns the channel
+// that is closed when the waiter is admitted by Admit.
+func (q *WaitQueue) Enqueue() <-chan struct{} {
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+	return ready
+}
+
+// Admit wakes the waiter at the front of the queue.
+// It returns false when the queue is empty.
+func (q *WaitQueue) Admit() bool {
+	if len(q.waiters) == 0 {
+		return false
+	}
+	ready := q.waiters[0]
+	q.waiters[0] = nil
+	q.waiters = q.waiters[1:]
+	close(ready)
+	return true
+}
+
+// Remove takes a waiter that gave up (timeout or cancellation) out of the queue.
+// It returns false when the waiter is no longer queued because Admit already
+// woke it, in which case the caller owns the slot it was granted.
+func (q *WaitQueue) Remove(ready <-chan struct{}) bool {
+	for i, w := range q.waiters {
+		if w == ready {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of queued waiters.
+func (q *WaitQueue) Len() int {
+	return len(q.waiters)
+}
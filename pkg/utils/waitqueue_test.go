@@ -0,0 +1,54 @@
+package utils
+
+import "testing"
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWaitQueue_AdmitInOrder(t *testing.T) {
+	var q WaitQueue
+	if q.Admit() {
+		t.Error("Admit() on an empty queue should return false")
+	}
+
+	first, second := q.Enqueue(), q.Enqueue()
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", q.Len())
+	}
+
+	if !q.Admit() {
+		t.Fatal("Admit() should wake the first waiter")
+	}
+	if !isClosed(first) || isClosed(second) {
+		t.Error("Admit() should wake waiters in FIFO order")
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", q.Len())
+	}
+}
+
+func TestWaitQueue_Remove(t *testing.T) {
+	var q WaitQueue
+	first, second, third := q.Enqueue(), q.Enqueue(), q.Enqueue()
+
+	if !q.Remove(second) {
+		t.Error("Remove() of a queued waiter should return true")
+	}
+	q.Admit()
+	if q.Remove(first) {
+		t.Error("Remove() of an admitted waiter should return false")
+	}
+	q.Admit()
+	if !isClosed(third) {
+		t.Error("the waiter after a removed one should still be admitted")
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", q.Len())
+	}
+}
@@ -0,0 +1,74 @@
+package virtual
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TableProvider 基于内存 map 的 VirtualTableProvider
+// 供嵌入方把自定义虚拟表（如 StructTable）注册为一个虚拟数据库，表名不区分大小写
+type TableProvider struct {
+	mu     sync.RWMutex
+	tables map[string]VirtualTable
+}
+
+// NewTableProvider 创建包含 tables 的 TableProvider
+func NewTableProvider(tables ...VirtualTable) *TableProvider {
+	p := &TableProvider{
+		tables: make(map[string]VirtualTable, len(tables)),
+	}
+	for _, table := range tables {
+		p.AddTable(table)
+	}
+	return p
+}
+
+// AddTable 添加或替换虚拟表
+func (p *TableProvider) AddTable(table VirtualTable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tables[strings.ToLower(table.GetName())] = table
+}
+
+// RemoveTable 移除虚拟表，返回表是否存在
+func (p *TableProvider) RemoveTable(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := strings.ToLower(name)
+	_, ok := p.tables[key]
+	delete(p.tables, key)
+	return ok
+}
+
+// GetVirtualTable 按名称获取虚拟表
+func (p *TableProvider) GetVirtualTable(name string) (VirtualTable, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	table, ok := p.tables[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("virtual table %s not found", name)
+	}
+	return table, nil
+}
+
+// ListVirtualTables 返回所有虚拟表名（按名称排序）
+func (p *TableProvider) ListVirtualTables() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.tables))
+	for _, table := range p.tables {
+		names = append(names, table.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasTable 判断虚拟表是否存在
+func (p *TableProvider) HasTable(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.tables[strings.ToLower(name)]
+	return ok
+}
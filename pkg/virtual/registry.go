@@ -1,6 +1,7 @@
 package virtual
 
 import (
+	"fmt"
 	"strings"
 	"sync"
 
@@ -12,6 +13,9 @@ type VirtualDatabaseEntry struct {
 	Name     string               // 数据库名，如 "config"
 	Provider VirtualTableProvider // 提供表名和 schema 信息
 	Writable bool                 // 是否支持写操作 (INSERT/UPDATE/DELETE)
+
+	// WritePolicy 写入策略，未设置时只受 Writable 限制
+	WritePolicy domain.WritePolicy
 }
 
 // VirtualDatabaseRegistry 虚拟数据库注册表
@@ -35,6 +39,30 @@ func (r *VirtualDatabaseRegistry) Register(entry *VirtualDatabaseEntry) {
 	r.databases[strings.ToLower(entry.Name)] = entry
 }
 
+// RegisterTable 将虚拟表注册到只读虚拟数据库 dbName 中
+// 数据库不存在时自动创建（使用 TableProvider）；已存在的数据库必须是通过 TableProvider 注册的
+func (r *VirtualDatabaseRegistry) RegisterTable(dbName string, table VirtualTable) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(dbName)
+	entry, ok := r.databases[key]
+	if !ok {
+		r.databases[key] = &VirtualDatabaseEntry{
+			Name:     dbName,
+			Provider: NewTableProvider(table),
+		}
+		return nil
+	}
+
+	provider, ok := entry.Provider.(*TableProvider)
+	if !ok {
+		return fmt.Errorf("virtual database %s does not accept table registration", dbName)
+	}
+	provider.AddTable(table)
+	return nil
+}
+
 // Get 获取虚拟数据库条目
 func (r *VirtualDatabaseRegistry) Get(name string) (*VirtualDatabaseEntry, bool) {
 	r.mu.RLock()
@@ -0,0 +1,131 @@
+package virtual
+
+import (
+	"context"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/util"
+)
+
+// AsVirtualTable 将 ScanTable 适配为 VirtualTable
+// 未被下推的过滤条件、排序和分页由适配器在迭代时处理
+func AsVirtualTable(table ScanTable) VirtualTable {
+	if vt, ok := table.(VirtualTable); ok {
+		return vt
+	}
+	return &scanTableAdapter{table: table}
+}
+
+// scanTableAdapter ScanTable 到 VirtualTable 的适配器
+type scanTableAdapter struct {
+	table ScanTable
+}
+
+func (a *scanTableAdapter) GetName() string {
+	return a.table.GetName()
+}
+
+func (a *scanTableAdapter) GetSchema() []domain.ColumnInfo {
+	return a.table.GetSchema()
+}
+
+func (a *scanTableAdapter) Query(ctx context.Context, filters []domain.Filter, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	return QueryScanTable(ctx, a.table, filters, options)
+}
+
+// QueryScanTable 通过迭代器执行虚拟表查询
+// 如果表实现了 FilterPushdownTable，先把过滤条件交给表处理，剩余条件逐行过滤。
+// 没有排序要求时，读够 offset+limit 行即停止迭代
+func QueryScanTable(ctx context.Context, table ScanTable, filters []domain.Filter, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	var iter RowIterator
+	var err error
+	remaining := filters
+	if pushdown, ok := table.(FilterPushdownTable); ok && len(filters) > 0 {
+		iter, remaining, err = pushdown.ScanFiltered(ctx, filters)
+	} else {
+		iter, err = table.Scan(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	// 无排序时可以在满足分页后提前结束
+	stopAfter := -1
+	if options != nil && len(options.SortKeys()) == 0 && options.Limit > 0 {
+		stopAfter = options.Offset + options.Limit
+	}
+
+	rows := make([]domain.Row, 0)
+	for iter.Next() {
+		if len(rows)%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		row := iter.Row()
+		if len(remaining) > 0 && !util.MatchesFilters(row, remaining) {
+			continue
+		}
+		rows = append(rows, row)
+		if stopAfter >= 0 && len(rows) >= stopAfter {
+			break
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	if options != nil {
+		rows = util.ApplyOrder(rows, options)
+		if options.Limit > 0 || options.Offset > 0 {
+			rows = util.ApplyPagination(rows, options.Offset, options.Limit)
+		}
+	}
+
+	return &domain.QueryResult{
+		Columns: table.GetSchema(),
+		Rows:    rows,
+		Total:   int64(len(rows)),
+		Ordered: len(options.SortKeys()) > 0,
+	}, nil
+}
+
+// SliceIterator 基于已有行切片的 RowIterator
+type SliceIterator struct {
+	rows []domain.Row
+	pos  int
+}
+
+// NewSliceIterator 创建遍历 rows 的迭代器
+func NewSliceIterator(rows []domain.Row) *SliceIterator {
+	return &SliceIterator{rows: rows, pos: -1}
+}
+
+// Next 前进到下一行
+func (it *SliceIterator) Next() bool {
+	if it.pos+1 >= len(it.rows) {
+		it.pos = len(it.rows)
+		return false
+	}
+	it.pos++
+	return true
+}
+
+// Row 返回当前行
+func (it *SliceIterator) Row() domain.Row {
+	if it.pos < 0 || it.pos >= len(it.rows) {
+		return nil
+	}
+	return it.rows[it.pos]
+}
+
+// Err 切片迭代不会出错
+func (it *SliceIterator) Err() error {
+	return nil
+}
+
+// Close 无需释放资源
+func (it *SliceIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,306 @@
+package virtual
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// StructTable exposes a Go slice of structs as a read-only virtual table
+// without copying it into a storage engine. Rows are read from the slice
+// through reflection on every query, so later changes to the slice are visible
+// when the table was created from a pointer to the slice.
+//
+// The schema is derived from the struct type. Column names come from the db
+// tag, then the json tag, then the field name; a tag name of "-" skips the
+// field. The db tag also accepts options after the name:
+//
+//	type User struct {
+//	    ID    int64     `db:"id,pk"`
+//	    Name  string    `db:"name,type=VARCHAR(64)"`
+//	    Email *string   `db:"email"`  // pointer fields are nullable
+//	    Seen  time.Time `json:"seen"`
+//	    notes string                  // unexported fields are ignored
+//	}
+//
+// Anonymous embedded structs without a tag are flattened into the parent.
+// StructTable is not synchronized: the caller must not modify the slice while
+// a query is running.
+type StructTable struct {
+	name    string
+	source  reflect.Value // slice, or pointer to slice
+	fields  []structField
+	columns []domain.ColumnInfo
+}
+
+// structField maps a struct field to a column
+type structField struct {
+	column string
+	index  []int
+}
+
+// NewStructTable creates a virtual table named name over data, which must be a
+// slice of structs or struct pointers, or a pointer to such a slice
+func NewStructTable(name string, data interface{}) (*StructTable, error) {
+	source := reflect.ValueOf(data)
+	sliceType := source.Type()
+	if source.Kind() == reflect.Ptr {
+		if source.IsNil() {
+			return nil, fmt.Errorf("virtual table %s: data must not be a nil pointer", name)
+		}
+		sliceType = sliceType.Elem()
+	}
+	if sliceType.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("virtual table %s: data must be a slice or a pointer to a slice, got %T", name, data)
+	}
+
+	elemType := sliceType.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("virtual table %s: slice elements must be structs, got %s", name, sliceType.Elem())
+	}
+
+	fields, columns := deriveStructSchema(elemType, nil)
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("virtual table %s: struct %s has no exported fields", name, elemType)
+	}
+
+	return &StructTable{
+		name:    name,
+		source:  source,
+		fields:  fields,
+		columns: columns,
+	}, nil
+}
+
+// GetName returns the table name
+func (t *StructTable) GetName() string {
+	return t.name
+}
+
+// GetSchema returns the schema derived from the struct type
+func (t *StructTable) GetSchema() []domain.ColumnInfo {
+	return t.columns
+}
+
+// Scan returns an iterator over the current contents of the slice
+func (t *StructTable) Scan(ctx context.Context) (RowIterator, error) {
+	slice := t.source
+	if slice.Kind() == reflect.Ptr {
+		slice = slice.Elem()
+	}
+	return &structIterator{table: t, slice: slice, pos: -1}, nil
+}
+
+// Query executes a query against the slice
+func (t *StructTable) Query(ctx context.Context, filters []domain.Filter, options *domain.QueryOptions) (*domain.QueryResult, error) {
+	return QueryScanTable(ctx, t, filters, options)
+}
+
+// structIterator iterates over a slice of structs, converting one element at a time
+type structIterator struct {
+	table *StructTable
+	slice reflect.Value
+	pos   int
+	row   domain.Row
+}
+
+func (it *structIterator) Next() bool {
+	for it.pos+1 < it.slice.Len() {
+		it.pos++
+		elem := it.slice.Index(it.pos)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		it.row = it.table.toRow(elem)
+		return true
+	}
+	it.row = nil
+	return false
+}
+
+func (it *structIterator) Row() domain.Row {
+	return it.row
+}
+
+func (it *structIterator) Err() error {
+	return nil
+}
+
+func (it *structIterator) Close() error {
+	return nil
+}
+
+// toRow converts one struct value to a row
+func (t *StructTable) toRow(elem reflect.Value) domain.Row {
+	row := make(domain.Row, len(t.fields))
+	for _, f := range t.fields {
+		fv, ok := fieldByIndex(elem, f.index)
+		if !ok {
+			row[f.column] = nil
+			continue
+		}
+		row[f.column] = structValue(fv)
+	}
+	return row
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but returns false instead of
+// panicking when an embedded struct pointer on the path is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structValue converts a field value to the representation used by the engine
+func structValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := v.Uint()
+		if u <= 1<<63-1 {
+			return int64(u)
+		}
+		return u
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	}
+	return v.Interface()
+}
+
+// deriveStructSchema derives columns from the exported fields of a struct type
+func deriveStructSchema(t reflect.Type, parent []int) ([]structField, []domain.ColumnInfo) {
+	var fields []structField
+	var columns []domain.ColumnInfo
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		name, opts, tagged := structTag(field)
+		if name == "-" {
+			continue
+		}
+
+		// Untagged anonymous structs are flattened into the parent
+		if field.Anonymous && !tagged {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				subFields, subColumns := deriveStructSchema(ft, index)
+				fields = append(fields, subFields...)
+				columns = append(columns, subColumns...)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		col := domain.ColumnInfo{
+			Name:     name,
+			Type:     structColumnType(field.Type),
+			Nullable: field.Type.Kind() == reflect.Ptr || field.Type.Kind() == reflect.Interface,
+		}
+		for _, opt := range opts {
+			switch {
+			case opt == "pk" || opt == "primary_key":
+				col.Primary = true
+				col.Nullable = false
+			case opt == "nullable":
+				col.Nullable = true
+			case opt == "notnull":
+				col.Nullable = false
+			case strings.HasPrefix(opt, "type="):
+				col.Type = strings.TrimPrefix(opt, "type=")
+			}
+		}
+
+		fields = append(fields, structField{column: name, index: index})
+		columns = append(columns, col)
+	}
+	return fields, columns
+}
+
+// structTag returns the column name and options from the db or json tag
+func structTag(field reflect.StructField) (name string, opts []string, tagged bool) {
+	tag, ok := field.Tag.Lookup("db")
+	if !ok {
+		tag, ok = field.Tag.Lookup("json")
+	}
+	if !ok {
+		return "", nil, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt = strings.TrimSpace(opt); opt != "" {
+			opts = append(opts, opt)
+		}
+	}
+	return strings.TrimSpace(parts[0]), opts, true
+}
+
+// structColumnType maps a Go type to a SQL column type
+func structColumnType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "DATETIME"
+	}
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return "BLOB"
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "BIGINT"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "BIGINT UNSIGNED"
+	case reflect.Float32, reflect.Float64:
+		return "DOUBLE"
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.String:
+		return "TEXT"
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Interface:
+		return "JSON"
+	}
+	return "TEXT"
+}
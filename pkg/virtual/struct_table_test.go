@@ -0,0 +1,186 @@
+package virtual
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Audit struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type product struct {
+	ID    uint32   `db:"id,pk"`
+	Name  string   `db:"name,type=VARCHAR(64)"`
+	Price *float64 `json:"price,omitempty"`
+	Tags  []string
+	Skip  string `db:"-"`
+	Audit
+	hidden string
+}
+
+func TestNewStructTable_Schema(t *testing.T) {
+	table, err := NewStructTable("products", []product{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "products", table.GetName())
+	assert.Equal(t, []domain.ColumnInfo{
+		{Name: "id", Type: "BIGINT UNSIGNED", Primary: true},
+		{Name: "name", Type: "VARCHAR(64)"},
+		{Name: "price", Type: "DOUBLE", Nullable: true},
+		{Name: "Tags", Type: "JSON"},
+		{Name: "created_at", Type: "DATETIME"},
+	}, table.GetSchema())
+}
+
+func TestNewStructTable_InvalidData(t *testing.T) {
+	_, err := NewStructTable("t", 42)
+	assert.Error(t, err)
+
+	_, err = NewStructTable("t", []int{1, 2})
+	assert.Error(t, err)
+
+	var nilSlice *[]product
+	_, err = NewStructTable("t", nilSlice)
+	assert.Error(t, err)
+}
+
+func TestStructTable_Query(t *testing.T) {
+	price := 9.5
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	products := []*product{
+		{ID: 1, Name: "pen", Price: &price, Audit: Audit{CreatedAt: created}},
+		nil,
+		{ID: 2, Name: "ink"},
+		{ID: 3, Name: "pad", Price: &price},
+	}
+	table, err := NewStructTable("products", &products)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	result, err := table.Query(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 3, "nil elements are skipped")
+	assert.Equal(t, int64(1), result.Rows[0]["id"])
+	assert.Equal(t, 9.5, result.Rows[0]["price"])
+	assert.Equal(t, created, result.Rows[0]["created_at"])
+	assert.Nil(t, result.Rows[1]["price"])
+	assert.NotContains(t, result.Rows[0], "Skip")
+	assert.NotContains(t, result.Rows[0], "hidden")
+
+	filters := []domain.Filter{{Field: "price", Operator: "=", Value: 9.5}}
+	result, err = table.Query(ctx, filters, &domain.QueryOptions{Filters: filters, OrderBy: "id", Order: "DESC", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "pad", result.Rows[0]["name"])
+
+	// 通过指针注册的切片修改后立即可见
+	products = append(products, &product{ID: 4, Name: "cap"})
+	result, err = table.Query(ctx, nil, &domain.QueryOptions{Offset: 3, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "cap", result.Rows[0]["name"])
+}
+
+// keyTable 支持按 id 下推等值过滤的 ScanTable
+type keyTable struct {
+	rows     []domain.Row
+	pushdown int
+}
+
+func (k *keyTable) GetName() string { return "kv" }
+
+func (k *keyTable) GetSchema() []domain.ColumnInfo {
+	return []domain.ColumnInfo{{Name: "id", Type: "BIGINT"}, {Name: "v", Type: "TEXT"}}
+}
+
+func (k *keyTable) Scan(ctx context.Context) (RowIterator, error) {
+	return NewSliceIterator(k.rows), nil
+}
+
+func (k *keyTable) ScanFiltered(ctx context.Context, filters []domain.Filter) (RowIterator, []domain.Filter, error) {
+	var remaining []domain.Filter
+	var matched []domain.Row
+	handled := false
+	for _, f := range filters {
+		if f.Field == "id" && f.Operator == "=" && !handled {
+			handled = true
+			k.pushdown++
+			for _, row := range k.rows {
+				if row["id"] == f.Value {
+					matched = append(matched, row)
+				}
+			}
+			continue
+		}
+		remaining = append(remaining, f)
+	}
+	if !handled {
+		matched = k.rows
+	}
+	return NewSliceIterator(matched), remaining, nil
+}
+
+func TestAsVirtualTable_FilterPushdown(t *testing.T) {
+	kt := &keyTable{rows: []domain.Row{
+		{"id": int64(1), "v": "a"},
+		{"id": int64(2), "v": "b"},
+		{"id": int64(2), "v": "c"},
+	}}
+	vt := AsVirtualTable(kt)
+	assert.Equal(t, "kv", vt.GetName())
+
+	filters := []domain.Filter{
+		{Field: "id", Operator: "=", Value: int64(2)},
+		{Field: "v", Operator: "=", Value: "c"},
+	}
+	result, err := vt.Query(context.Background(), filters, &domain.QueryOptions{Filters: filters})
+	require.NoError(t, err)
+	assert.Equal(t, 1, kt.pushdown)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "c", result.Rows[0]["v"])
+
+	result, err = vt.Query(context.Background(), nil, &domain.QueryOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 2)
+}
+
+func TestQueryScanTable_Cancelled(t *testing.T) {
+	table, err := NewStructTable("products", []product{{ID: 1}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = table.Query(ctx, nil, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestVirtualDatabaseRegistry_RegisterTable(t *testing.T) {
+	registry := NewVirtualDatabaseRegistry()
+
+	items, err := NewStructTable("Items", []product{{ID: 1, Name: "pen"}})
+	require.NoError(t, err)
+	require.NoError(t, registry.RegisterTable("app", items))
+	require.NoError(t, registry.RegisterTable("APP", AsVirtualTable(&keyTable{})))
+
+	entry, ok := registry.Get("app")
+	require.True(t, ok)
+	assert.False(t, entry.Writable)
+	assert.Equal(t, []string{"Items", "kv"}, entry.Provider.ListVirtualTables())
+	assert.True(t, entry.Provider.HasTable("items"))
+
+	ds := registry.GetDataSource("app")
+	require.NotNil(t, ds)
+	result, err := ds.Query(context.Background(), "items", &domain.QueryOptions{})
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+
+	// 非 TableProvider 注册的数据库不允许追加表
+	registry.Register(&VirtualDatabaseEntry{Name: "fixed", Provider: &mockTable{}})
+	assert.Error(t, registry.RegisterTable("fixed", items))
+}
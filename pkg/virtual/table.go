@@ -32,3 +32,40 @@ type VirtualTableProvider interface {
 	// HasTable returns true if a virtual table with the given name exists
 	HasTable(name string) bool
 }
+
+// RowIterator iterates over the rows of a virtual table one at a time
+// Usage follows the bufio.Scanner pattern: call Next until it returns false, then check Err
+type RowIterator interface {
+	// Next advances to the next row, returning false when there are no more rows or an error occurred
+	Next() bool
+
+	// Row returns the current row
+	Row() domain.Row
+
+	// Err returns the error that stopped the iteration, if any
+	Err() error
+
+	// Close releases resources held by the iterator
+	Close() error
+}
+
+// ScanTable is a virtual table that produces rows through an iterator instead of
+// materializing the whole result set. Wrap it with AsVirtualTable to register it.
+type ScanTable interface {
+	// GetName returns the table name
+	GetName() string
+
+	// GetSchema returns the table schema (column definitions)
+	GetSchema() []domain.ColumnInfo
+
+	// Scan returns an iterator over all rows of the table
+	Scan(ctx context.Context) (RowIterator, error)
+}
+
+// FilterPushdownTable is an optional interface for ScanTable implementations that
+// can evaluate some filters themselves (e.g. a lookup by key)
+type FilterPushdownTable interface {
+	// ScanFiltered returns an iterator over rows matching the filters it handled,
+	// together with the filters it did not handle; those are evaluated by the engine
+	ScanFiltered(ctx context.Context, filters []domain.Filter) (RowIterator, []domain.Filter, error)
+}
@@ -0,0 +1,134 @@
+package workload
+
+import (
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+// 估算成本的基准单位
+const (
+	pointCost = 1   // 按等值条件定位的点查、单行写入
+	scanCost  = 100 // 单表扫描
+)
+
+// EstimateCost 在执行前按语句结构粗略估算成本，供分类规则的 MinCost/MaxCost 使用
+// 数据源不提供廉价的行数统计，因此只看语句形状而不看数据量：
+//   - WHERE 只由 AND 连接的“列 = 常量”组成的单表语句视为点查，成本 1
+//   - 其余单表读取、UPDATE、DELETE 视为全表扫描，成本 100
+//   - 每个 JOIN 成本乘 10；GROUP BY、DISTINCT、ORDER BY、HAVING 各乘 2
+//   - 不排序、不分组的 LIMIT n 成本不超过 n
+//   - INSERT 成本为插入的行数；ALTER/TRUNCATE/OPTIMIZE/CREATE INDEX 视为扫描，其余语句为 1
+func EstimateCost(stmt *parser.SQLStatement) float64 {
+	if stmt == nil {
+		return 0
+	}
+	switch stmt.Type {
+	case parser.SQLTypeSelect:
+		if stmt.Select != nil {
+			return selectCost(stmt.Select, joinCount(stmt))
+		}
+	case parser.SQLTypeInsert:
+		if stmt.Insert != nil && len(stmt.Insert.Values) > 0 {
+			return float64(len(stmt.Insert.Values))
+		}
+	case parser.SQLTypeUpdate:
+		if stmt.Update != nil {
+			return limitCost(filterCost(stmt.Update.Where), stmt.Update.Limit, len(stmt.Update.OrderBy) == 0)
+		}
+	case parser.SQLTypeDelete:
+		if stmt.Delete != nil {
+			return limitCost(filterCost(stmt.Delete.Where), stmt.Delete.Limit, len(stmt.Delete.OrderBy) == 0)
+		}
+	case parser.SQLTypeGenerate:
+		if stmt.GenerateTable != nil {
+			return float64(stmt.GenerateTable.Rows)
+		}
+	case parser.SQLTypeAlter, parser.SQLTypeTruncate, parser.SQLTypeOptimize,
+		parser.SQLTypeExport, parser.SQLTypeImport, parser.SQLTypeImportData, parser.SQLTypeSelectInto, parser.SQLTypeChecksum,
+		parser.SQLTypeCheck, parser.SQLTypeDiff, parser.SQLTypeUndrop, parser.SQLTypeFlashback, parser.SQLTypeBackup,
+		parser.SQLTypeRestore, parser.SQLTypeSetUserVar:
+		return scanCost
+	}
+	if stmt.CreateIndex != nil {
+		return scanCost
+	}
+	return pointCost
+}
+
+func selectCost(sel *parser.SelectStatement, joins int) float64 {
+	if sel.From == "" {
+		return pointCost
+	}
+	cost := filterCost(sel.Where)
+	if joins > 0 {
+		cost = scanCost
+		for i := 0; i < joins; i++ {
+			cost *= 10
+		}
+	}
+	streaming := true
+	for _, multiplied := range []bool{len(sel.GroupBy) > 0, sel.Distinct, len(sel.OrderBy) > 0, sel.Having != nil} {
+		if multiplied {
+			cost *= 2
+			streaming = false
+		}
+	}
+	// 聚合函数需要读完全部输入，LIMIT 不能提前结束
+	for _, col := range sel.Columns {
+		if col.Expr != nil && col.Expr.Type == parser.ExprTypeFunction {
+			streaming = false
+		}
+	}
+	return limitCost(cost, sel.Limit, streaming)
+}
+
+// joinCount 返回 JOIN 的数量
+// 解析结果不一定保留全部 JOIN（如两表连接），因此同时统计原始 SQL 中的 JOIN 关键字
+func joinCount(stmt *parser.SQLStatement) int {
+	n := 0
+	for _, word := range strings.Fields(strings.ToUpper(stmt.RawSQL)) {
+		if word == "JOIN" || word == "STRAIGHT_JOIN" {
+			n++
+		}
+	}
+	if joins := len(stmt.Select.Joins); joins > n {
+		return joins
+	}
+	return n
+}
+
+// filterCost 点查返回 pointCost，否则返回 scanCost
+func filterCost(where *parser.Expression) float64 {
+	if where != nil && isPointFilter(where) {
+		return pointCost
+	}
+	return scanCost
+}
+
+// isPointFilter WHERE 是否只由 AND 连接的“列 = 常量”组成
+func isPointFilter(expr *parser.Expression) bool {
+	if expr == nil || expr.Type != parser.ExprTypeOperator {
+		return false
+	}
+	switch strings.ToLower(expr.Operator) {
+	case "and":
+		return isPointFilter(expr.Left) && isPointFilter(expr.Right)
+	case "eq", "=":
+		return expr.Left != nil && expr.Right != nil &&
+			((expr.Left.Type == parser.ExprTypeColumn && expr.Right.Type == parser.ExprTypeValue) ||
+				(expr.Left.Type == parser.ExprTypeValue && expr.Right.Type == parser.ExprTypeColumn))
+	}
+	return false
+}
+
+// limitCost 不需要读完全部输入时用 LIMIT 封顶
+func limitCost(cost float64, limit *int64, streaming bool) float64 {
+	if streaming && limit != nil && float64(*limit) < cost {
+		if *limit < pointCost {
+			return pointCost
+		}
+		return float64(*limit)
+	}
+	return cost
+}
@@ -0,0 +1,321 @@
+// Package workload 实现查询排队与工作负载分类
+//
+// 语句按用户、语句类型和估算成本归入不同的资源类别（Class），
+// 每个类别有独立的并发名额和等待队列，避免廉价的 OLTP 点查被大型分析扫描阻塞。
+package workload
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// DefaultClassName 未匹配任何规则的语句默认所属的类别
+const DefaultClassName = "default"
+
+// Class 资源类别
+type Class struct {
+	Name           string
+	MaxConcurrency int           // 同时执行的语句数上限，0 表示不限制
+	MaxQueue       int           // 名额用完后最多排队的语句数，0 表示不排队直接拒绝
+	QueueTimeout   time.Duration // 排队超时，0 表示一直等待到获得名额
+}
+
+// Rule 分类规则，各条件同时满足时语句归入 Class；为空的条件匹配任意值
+type Rule struct {
+	Class          string
+	Users          []string // 用户名
+	StatementTypes []string // 语句类型，如 SELECT、INSERT（不区分大小写）
+	MinCost        float64  // 估算成本下限（含）
+	MaxCost        float64  // 估算成本上限（不含），0 表示不限制
+}
+
+// Config 工作负载管理配置
+type Config struct {
+	Enabled      bool
+	DefaultClass string // 未匹配规则的语句所属类别，为空时为 DefaultClassName
+	Classes      []Class
+	Rules        []Rule
+}
+
+// Request 待准入的语句
+type Request struct {
+	User          string
+	StatementType string
+	Cost          float64 // 见 EstimateCost
+}
+
+// BusyError 类别的并发名额已满且无法排队，或排队超时
+type BusyError struct {
+	Class    string
+	TimedOut bool
+}
+
+func (e *BusyError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("Timed out waiting in queue of workload class '%s'", e.Class)
+	}
+	return fmt.Sprintf("Too many queries queued in workload class '%s'", e.Class)
+}
+
+// MySQLErrorCode 实现 mysqlerrors.Coder
+func (e *BusyError) MySQLErrorCode() uint16 {
+	return mysqlerrors.ErrTooManyConcurrentTrxs
+}
+
+// ClassStats 类别的运行统计
+type ClassStats struct {
+	Name           string `json:"name"`
+	MaxConcurrency int    `json:"max_concurrency"`
+	MaxQueue       int    `json:"max_queue"`
+	QueueTimeoutMs int64  `json:"queue_timeout_ms"`
+	Running        int    `json:"running"`       // 正在执行的语句数
+	Queued         int    `json:"queued"`        // 正在排队的语句数
+	Admitted       int64  `json:"admitted"`      // 累计准入的语句数
+	Waited         int64  `json:"waited"`        // 累计经过排队后准入的语句数
+	Rejected       int64  `json:"rejected"`      // 累计因队列已满被拒绝的语句数
+	TimedOut       int64  `json:"timed_out"`     // 累计排队超时或被取消的语句数
+	TotalWaitMs    int64  `json:"total_wait_ms"` // 累计排队时间
+}
+
+// classState 类别的名额与等待队列
+type classState struct {
+	Class
+	running  int
+	waiters  utils.WaitQueue
+	admitted int64
+	waited   int64
+	rejected int64
+	timedOut int64
+	waitTime time.Duration
+}
+
+// Manager 工作负载管理器（并发安全）
+type Manager struct {
+	mu           sync.Mutex
+	enabled      bool
+	defaultClass string
+	rules        []Rule
+	classes      map[string]*classState
+	order        []string // 类别的配置顺序，用于统计输出
+}
+
+// NewManager 创建工作负载管理器，默认不启用
+func NewManager() *Manager {
+	return &Manager{
+		defaultClass: DefaultClassName,
+		classes:      make(map[string]*classState),
+	}
+}
+
+var globalManager = NewManager()
+
+// GetManager 获取全局工作负载管理器
+func GetManager() *Manager {
+	return globalManager
+}
+
+// Configure 应用配置
+// 同名类别保留运行中的名额和累计统计；调大上限会立即放行排队的语句，
+// 被移除的类别放行全部排队的语句
+func (m *Manager) Configure(cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = cfg.Enabled
+	m.defaultClass = cfg.DefaultClass
+	if m.defaultClass == "" {
+		m.defaultClass = DefaultClassName
+	}
+	m.rules = append([]Rule(nil), cfg.Rules...)
+
+	classes := make(map[string]*classState, len(cfg.Classes)+1)
+	order := make([]string, 0, len(cfg.Classes)+1)
+	for _, c := range cfg.Classes {
+		state, ok := m.classes[c.Name]
+		if !ok {
+			state = &classState{}
+		}
+		state.Class = c
+		classes[c.Name] = state
+		order = append(order, c.Name)
+	}
+	// 默认类别未显式配置时不限制并发
+	if _, ok := classes[m.defaultClass]; !ok {
+		state, ok := m.classes[m.defaultClass]
+		if !ok {
+			state = &classState{}
+		}
+		state.Class = Class{Name: m.defaultClass}
+		classes[m.defaultClass] = state
+		order = append(order, m.defaultClass)
+	}
+
+	for name, state := range m.classes {
+		if _, ok := classes[name]; !ok {
+			state.Class = Class{Name: name}
+			state.grantLocked()
+		}
+	}
+	for _, state := range classes {
+		state.grantLocked()
+	}
+	m.classes = classes
+	m.order = order
+}
+
+// Enabled 是否启用工作负载管理
+func (m *Manager) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enabled
+}
+
+// Classify 返回语句所属的类别：第一条匹配的规则生效，都不匹配时为默认类别
+func (m *Manager) Classify(req Request) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.classifyLocked(req)
+}
+
+func (m *Manager) classifyLocked(req Request) string {
+	for _, rule := range m.rules {
+		if rule.matches(req) {
+			if _, ok := m.classes[rule.Class]; ok {
+				return rule.Class
+			}
+		}
+	}
+	return m.defaultClass
+}
+
+func (r Rule) matches(req Request) bool {
+	if len(r.Users) > 0 && !containsFold(r.Users, req.User, false) {
+		return false
+	}
+	if len(r.StatementTypes) > 0 && !containsFold(r.StatementTypes, req.StatementType, true) {
+		return false
+	}
+	if req.Cost < r.MinCost {
+		return false
+	}
+	if r.MaxCost > 0 && req.Cost >= r.MaxCost {
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, s string, fold bool) bool {
+	for _, item := range list {
+		if item == s || (fold && strings.EqualFold(item, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Admit 为语句占用所属类别的一个并发名额，返回的函数用于释放名额（只能调用一次）
+// 名额已满时按 FIFO 顺序排队；队列已满、排队超时或 ctx 取消时返回 *BusyError。
+// 未启用时直接放行
+func (m *Manager) Admit(ctx context.Context, req Request) (func(), error) {
+	m.mu.Lock()
+	if !m.enabled {
+		m.mu.Unlock()
+		return func() {}, nil
+	}
+	state := m.classes[m.classifyLocked(req)]
+	release := m.releaseFunc(state)
+
+	if state.MaxConcurrency <= 0 || state.running < state.MaxConcurrency {
+		state.running++
+		state.admitted++
+		m.mu.Unlock()
+		return release, nil
+	}
+	if state.waiters.Len() >= state.MaxQueue {
+		state.rejected++
+		m.mu.Unlock()
+		return nil, &BusyError{Class: state.Name}
+	}
+	ready := state.waiters.Enqueue()
+	timeout := state.QueueTimeout
+	m.mu.Unlock()
+
+	start := time.Now()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-ready:
+	case <-expired:
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state.waitTime += time.Since(start)
+	if state.waiters.Remove(ready) {
+		state.timedOut++
+		return nil, &BusyError{Class: state.Name, TimedOut: true}
+	}
+	// 已被放行（包括超时的同时恰好获得名额）
+	state.waited++
+	return release, nil
+}
+
+// releaseFunc 返回释放 state 一个名额的函数
+func (m *Manager) releaseFunc(state *classState) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if state.running > 0 {
+				state.running--
+			}
+			state.grantLocked()
+		})
+	}
+}
+
+// Stats 按配置顺序返回各类别的统计
+func (m *Manager) Stats() []ClassStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]ClassStats, 0, len(m.order))
+	for _, name := range m.order {
+		state := m.classes[name]
+		stats = append(stats, ClassStats{
+			Name:           state.Name,
+			MaxConcurrency: state.MaxConcurrency,
+			MaxQueue:       state.MaxQueue,
+			QueueTimeoutMs: state.QueueTimeout.Milliseconds(),
+			Running:        state.running,
+			Queued:         state.waiters.Len(),
+			Admitted:       state.admitted,
+			Waited:         state.waited,
+			Rejected:       state.rejected,
+			TimedOut:       state.timedOut,
+			TotalWaitMs:    state.waitTime.Milliseconds(),
+		})
+	}
+	return stats
+}
+
+// grantLocked 在有空闲名额时按 FIFO 顺序放行排队的语句（调用方需持有锁）
+func (s *classState) grantLocked() {
+	for s.waiters.Len() > 0 && (s.MaxConcurrency <= 0 || s.running < s.MaxConcurrency) {
+		s.running++
+		s.admitted++
+		s.waiters.Admit()
+	}
+}
@@ -0,0 +1,160 @@
+package workload
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(queueTimeout time.Duration) *Manager {
+	m := NewManager()
+	m.Configure(Config{
+		Enabled: true,
+		Classes: []Class{
+			{Name: "oltp", MaxConcurrency: 4},
+			{Name: "olap", MaxConcurrency: 1, MaxQueue: 1, QueueTimeout: queueTimeout},
+		},
+		Rules: []Rule{
+			{Class: "olap", Users: []string{"analyst"}},
+			{Class: "oltp", StatementTypes: []string{"select", "insert"}, MaxCost: 10},
+			{Class: "olap", MinCost: 1000},
+		},
+	})
+	return m
+}
+
+func TestManager_Classify(t *testing.T) {
+	m := newTestManager(time.Second)
+
+	assert.Equal(t, "olap", m.Classify(Request{User: "analyst", StatementType: "SELECT", Cost: 1}))
+	assert.Equal(t, "oltp", m.Classify(Request{User: "app", StatementType: "SELECT", Cost: 1}))
+	assert.Equal(t, "olap", m.Classify(Request{User: "app", StatementType: "SELECT", Cost: 10000}))
+	assert.Equal(t, DefaultClassName, m.Classify(Request{User: "app", StatementType: "UPDATE", Cost: 100}))
+}
+
+func TestManager_QueueAndRelease(t *testing.T) {
+	m := newTestManager(time.Second)
+	req := Request{User: "analyst"}
+
+	release, err := m.Admit(context.Background(), req)
+	require.NoError(t, err)
+
+	// 第二条语句排队，第三条因队列已满被拒绝
+	admitted := make(chan func(), 1)
+	go func() {
+		r, err := m.Admit(context.Background(), req)
+		assert.NoError(t, err)
+		admitted <- r
+	}()
+	require.Eventually(t, func() bool { return m.Stats()[1].Queued == 1 }, time.Second, time.Millisecond)
+
+	_, err = m.Admit(context.Background(), req)
+	var busy *BusyError
+	require.True(t, errors.As(err, &busy))
+	assert.False(t, busy.TimedOut)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrTooManyConcurrentTrxs))
+
+	// 其他类别不受影响
+	oltpRelease, err := m.Admit(context.Background(), Request{StatementType: "SELECT", Cost: 1})
+	require.NoError(t, err)
+	oltpRelease()
+
+	release()
+	release() // 重复释放无效
+	second := <-admitted
+	second()
+
+	stats := m.Stats()
+	require.Len(t, stats, 3)
+	assert.Equal(t, "olap", stats[1].Name)
+	assert.Equal(t, 0, stats[1].Running)
+	assert.EqualValues(t, 2, stats[1].Admitted)
+	assert.EqualValues(t, 1, stats[1].Waited)
+	assert.EqualValues(t, 1, stats[1].Rejected)
+	assert.EqualValues(t, 1, stats[0].Admitted)
+	assert.Equal(t, DefaultClassName, stats[2].Name)
+}
+
+func TestManager_QueueTimeout(t *testing.T) {
+	m := newTestManager(20 * time.Millisecond)
+	req := Request{User: "analyst"}
+
+	release, err := m.Admit(context.Background(), req)
+	require.NoError(t, err)
+	defer release()
+
+	_, err = m.Admit(context.Background(), req)
+	var busy *BusyError
+	require.True(t, errors.As(err, &busy))
+	assert.True(t, busy.TimedOut)
+	assert.EqualValues(t, 1, m.Stats()[1].TimedOut)
+	assert.Equal(t, 0, m.Stats()[1].Queued)
+}
+
+func TestManager_ReconfigureGrantsWaiters(t *testing.T) {
+	m := newTestManager(0)
+	req := Request{User: "analyst"}
+
+	release, err := m.Admit(context.Background(), req)
+	require.NoError(t, err)
+	defer release()
+
+	done := make(chan error, 1)
+	go func() {
+		r, err := m.Admit(context.Background(), req)
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return m.Stats()[1].Queued == 1 }, time.Second, time.Millisecond)
+
+	// 调大并发上限立即放行排队的语句
+	m.Configure(Config{
+		Enabled: true,
+		Classes: []Class{{Name: "olap", MaxConcurrency: 2}},
+		Rules:   []Rule{{Class: "olap", Users: []string{"analyst"}}},
+	})
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("queued statement was not admitted after reconfiguration")
+	}
+}
+
+func TestManager_Disabled(t *testing.T) {
+	m := NewManager()
+	release, err := m.Admit(context.Background(), Request{})
+	require.NoError(t, err)
+	release()
+	assert.False(t, m.Enabled())
+}
+
+func TestEstimateCost(t *testing.T) {
+	adapter := parser.NewSQLAdapter()
+	cost := func(sql string) float64 {
+		result, err := adapter.Parse(sql)
+		require.NoError(t, err)
+		require.True(t, result.Success, result.Error)
+		return EstimateCost(result.Statement)
+	}
+
+	assert.Equal(t, 1.0, cost("SELECT * FROM t WHERE id = 1"))
+	assert.Equal(t, 1.0, cost("SELECT * FROM t WHERE id = 1 AND tenant = 'a'"))
+	assert.Equal(t, 100.0, cost("SELECT * FROM t WHERE id > 1"))
+	assert.Equal(t, 10.0, cost("SELECT * FROM t LIMIT 10"))
+	assert.Equal(t, 200.0, cost("SELECT * FROM t ORDER BY a LIMIT 10"))
+	assert.Equal(t, 100.0, cost("SELECT COUNT(*) FROM t LIMIT 1"))
+	assert.Equal(t, 2000.0, cost("SELECT a, COUNT(*) FROM t JOIN u ON t.id = u.tid GROUP BY a"))
+	assert.Equal(t, 3.0, cost("INSERT INTO t (a) VALUES (1), (2), (3)"))
+	assert.Equal(t, 1.0, cost("UPDATE t SET a = 1 WHERE id = 5"))
+	assert.Equal(t, 100.0, cost("DELETE FROM t"))
+	assert.Equal(t, 1.0, cost("SELECT 1"))
+}
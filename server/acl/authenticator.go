@@ -30,6 +30,12 @@ func (a *Authenticator) VerifyPassword(storedHash string, password string, authR
 	return utils.VerifyPassword(storedHash, password, authResponse, salt)
 }
 
+// VerifyAuthResponse verifies a mysql_native_password authentication response
+// against the stored hash, without knowing the plaintext password
+func (a *Authenticator) VerifyAuthResponse(storedHash string, authResponse []byte, salt []byte) bool {
+	return utils.VerifyNativeAuthResponse(storedHash, authResponse, salt)
+}
+
 // VerifyPasswordWithHash verifies a password against stored hash only
 // Used for testing and direct password verification
 func (a *Authenticator) VerifyPasswordWithHash(storedHash, password string) bool {
@@ -1,11 +1,12 @@
 package acl
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 )
 
 // DataFile represents the structure of users.json and permissions.json
@@ -16,40 +17,52 @@ type DataFile struct {
 	ColumnPermissions []ColumnPermission   `json:"columns_priv"`
 }
 
-// ACLManager integrates user and permission management with JSON persistence
+// ACLManager integrates user and permission management with pluggable persistence
 type ACLManager struct {
 	userManager   *UserManager
 	permissionMgr *PermissionManager
 	authenticator *Authenticator
+	storage       Storage
 	dataDir       string
-	usersFilePath string
-	permsFilePath string
 	loaded        bool
+	policy        security.PasswordPolicy
+	now           func() time.Time
 	mu            sync.RWMutex
 }
 
-// NewACLManager creates a new ACL manager
+// NewACLManager creates a new ACL manager that keeps its data in users.json
+// and permissions.json in dataDir
 func NewACLManager(dataDir string) (*ACLManager, error) {
 	if dataDir == "" {
 		dataDir = "."
 	}
 
+	am, err := NewACLManagerWithStorage(NewFileStorage(dataDir))
+	if err != nil {
+		return nil, err
+	}
+	am.dataDir = dataDir
+	return am, nil
+}
+
+// NewACLManagerWithStorage creates a new ACL manager backed by storage.
+// Empty storage is initialized with a root user that has all privileges.
+func NewACLManagerWithStorage(storage Storage) (*ACLManager, error) {
 	am := &ACLManager{
 		userManager:   NewUserManager(),
 		permissionMgr: NewPermissionManager(),
 		authenticator: NewAuthenticator(),
-		dataDir:       dataDir,
-		usersFilePath: filepath.Join(dataDir, "users.json"),
-		permsFilePath: filepath.Join(dataDir, "permissions.json"),
+		storage:       storage,
 		loaded:        false,
+		now:           time.Now,
 	}
 
-	// Initialize JSON files if they don't exist
-	if err := am.initializeFiles(); err != nil {
-		return nil, fmt.Errorf("failed to initialize ACL files: %w", err)
+	// Initialize storage if nothing has been stored yet
+	if err := am.initializeStorage(); err != nil {
+		return nil, fmt.Errorf("failed to initialize ACL storage: %w", err)
 	}
 
-	// Load data from JSON files
+	// Load data from storage
 	if err := am.Load(); err != nil {
 		return nil, fmt.Errorf("failed to load ACL data: %w", err)
 	}
@@ -57,90 +70,51 @@ func NewACLManager(dataDir string) (*ACLManager, error) {
 	return am, nil
 }
 
-// Initialize files creates default files if they don't exist
-func (am *ACLManager) initializeFiles() error {
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll(am.dataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
-	}
-
-	// Check if users.json exists
-	if _, err := os.Stat(am.usersFilePath); os.IsNotExist(err) {
-		// Create default users.json with root user
-		defaultUser := User{
-			Host:       "%",
-			User:       "root",
-			Password:   "", // No password for root
-			Privileges: DefaultPrivileges(),
-		}
-
-		// Grant all privileges to root
-		for priv := range defaultUser.Privileges {
-			defaultUser.Privileges[priv] = true
-		}
-
-		data := DataFile{
-			Users: []User{defaultUser},
-		}
-
-		if err := am.writeUsersFile(&data); err != nil {
-			return fmt.Errorf("failed to create default users.json: %w", err)
-		}
+// initializeStorage stores the default data if the storage is empty
+func (am *ACLManager) initializeStorage() error {
+	data, err := am.storage.Load()
+	if err != nil || data != nil {
+		return err
 	}
 
-	// Check if permissions.json exists
-	if _, err := os.Stat(am.permsFilePath); os.IsNotExist(err) {
-		// Create empty permissions.json
-		data := DataFile{
-			DBPermissions:     []DatabasePermission{},
-			TablePermissions:  []TablePermission{},
-			ColumnPermissions: []ColumnPermission{},
-		}
-
-		if err := am.writePermissionsFile(&data); err != nil {
-			return fmt.Errorf("failed to create default permissions.json: %w", err)
-		}
+	// Create default root user with all privileges and no password
+	defaultUser := User{
+		Host:       "%",
+		User:       "root",
+		Password:   "",
+		Privileges: AllPrivilegesMap(),
 	}
 
-	return nil
+	return am.storage.Save(&DataFile{
+		Users:             []User{defaultUser},
+		DBPermissions:     []DatabasePermission{},
+		TablePermissions:  []TablePermission{},
+		ColumnPermissions: []ColumnPermission{},
+	})
 }
 
-// Load loads user and permission data from JSON files
+// Load loads user and permission data from storage
 func (am *ACLManager) Load() error {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
-	// Load users.json
-	usersData, err := os.ReadFile(am.usersFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read users.json: %w", err)
-	}
-
-	var usersFile DataFile
-	if err := json.Unmarshal(usersData, &usersFile); err != nil {
-		return fmt.Errorf("failed to parse users.json: %w", err)
-	}
-
-	// Load permissions.json
-	permsData, err := os.ReadFile(am.permsFilePath)
+	data, err := am.storage.Load()
 	if err != nil {
-		return fmt.Errorf("failed to read permissions.json: %w", err)
+		return err
 	}
-
-	var permsFile DataFile
-	if err := json.Unmarshal(permsData, &permsFile); err != nil {
-		return fmt.Errorf("failed to parse permissions.json: %w", err)
+	if data == nil {
+		data = &DataFile{}
 	}
 
 	// Load into managers
-	if err := am.userManager.LoadUsers(usersFile.Users); err != nil {
+	if err := am.userManager.LoadUsers(data.Users); err != nil {
 		return fmt.Errorf("failed to load users: %w", err)
 	}
 
 	if err := am.permissionMgr.LoadPermissions(
-		permsFile.DBPermissions,
-		permsFile.TablePermissions,
-		permsFile.ColumnPermissions,
+		data.DBPermissions,
+		data.TablePermissions,
+		data.ColumnPermissions,
 	); err != nil {
 		return fmt.Errorf("failed to load permissions: %w", err)
 	}
@@ -149,7 +123,7 @@ func (am *ACLManager) Load() error {
 	return nil
 }
 
-// Save persists user and permission data to JSON files
+// Save persists user and permission data to storage
 func (am *ACLManager) Save() error {
 	am.mu.Lock()
 	defer am.mu.Unlock()
@@ -158,29 +132,13 @@ func (am *ACLManager) Save() error {
 
 // saveWithoutLock persists data without acquiring lock (for internal use when already locked)
 func (am *ACLManager) saveWithoutLock() error {
-	// Prepare data structure for users.json
-	usersData := DataFile{
-		Users: am.userManager.ExportUsers(),
-	}
-
-	// Prepare data structure for permissions.json
 	dbPerms, tablePerms, colPerms := am.permissionMgr.ExportPermissions()
-	permsData := DataFile{
+	return am.storage.Save(&DataFile{
+		Users:             am.userManager.ExportUsers(),
 		DBPermissions:     dbPerms,
 		TablePermissions:  tablePerms,
 		ColumnPermissions: colPerms,
-	}
-
-	// Write to files
-	if err := am.writeUsersFile(&usersData); err != nil {
-		return err
-	}
-
-	if err := am.writePermissionsFile(&permsData); err != nil {
-		return err
-	}
-
-	return nil
+	})
 }
 
 // Authenticate verifies user credentials
@@ -213,6 +171,51 @@ func (am *ACLManager) Authenticate(username, password string) (*User, error) {
 	return user, nil
 }
 
+// AuthenticateNative verifies a mysql_native_password authentication response
+// computed by the client from scramble. The account is looked up by the
+// client host first, then by the wildcard host. A correct password that has
+// expired under the password policy fails with *security.PasswordExpiredError.
+func (am *ACLManager) AuthenticateNative(username, host string, scramble, authResponse []byte) (*User, error) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	usingPassword := "NO"
+	if len(authResponse) > 0 {
+		usingPassword = "YES"
+	}
+	denied := mysqlerrors.New(mysqlerrors.ErrAccessDenied,
+		"Access denied for user '%s'@'%s' (using password: %s)", username, host, usingPassword)
+	if !am.loaded {
+		return nil, denied
+	}
+
+	user, err := am.userManager.GetUser(host, username)
+	if err != nil {
+		return nil, denied
+	}
+	if !am.authenticator.VerifyAuthResponse(user.Password, authResponse, scramble) {
+		return nil, denied
+	}
+	if am.passwordExpired(user) {
+		return nil, &security.PasswordExpiredError{User: username}
+	}
+	return user, nil
+}
+
+// SetPasswordPolicy sets the policy that CreateUser and SetPassword enforce
+// and that AuthenticateNative uses for password expiry
+func (am *ACLManager) SetPasswordPolicy(policy security.PasswordPolicy) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.policy = policy
+}
+
+// passwordExpired reports whether the user's password has expired
+func (am *ACLManager) passwordExpired(user *User) bool {
+	changedAt, _ := time.Parse(time.RFC3339, user.PasswordChanged)
+	return am.policy.Expired(changedAt, am.now())
+}
+
 // CheckPermission checks if user has specified permission
 func (am *ACLManager) CheckPermission(username, host string, priv PermissionType, db, table, column string) bool {
 	am.mu.RLock()
@@ -278,6 +281,10 @@ func (am *ACLManager) CreateUser(host, user, password string) error {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
+	if err := am.policy.Check(user, password); err != nil {
+		return err
+	}
+
 	// Hash password
 	var passwordHash string
 	if password != "" {
@@ -288,6 +295,9 @@ func (am *ACLManager) CreateUser(host, user, password string) error {
 	if err := am.userManager.CreateUser(host, user, passwordHash, DefaultPrivileges()); err != nil {
 		return err
 	}
+	if err := am.userManager.SetPasswordChanged(host, user, am.timestamp()); err != nil {
+		return err
+	}
 
 	// Save to file (already have write lock)
 	if err := am.saveWithoutLock(); err != nil {
@@ -319,6 +329,10 @@ func (am *ACLManager) SetPassword(host, user, newPassword string) error {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
+	if err := am.policy.Check(user, newPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	var passwordHash string
 	if newPassword != "" {
@@ -328,6 +342,9 @@ func (am *ACLManager) SetPassword(host, user, newPassword string) error {
 	if err := am.userManager.SetPassword(host, user, passwordHash); err != nil {
 		return err
 	}
+	if err := am.userManager.SetPasswordChanged(host, user, am.timestamp()); err != nil {
+		return err
+	}
 
 	// Save to file (already have write lock)
 	if err := am.saveWithoutLock(); err != nil {
@@ -337,6 +354,11 @@ func (am *ACLManager) SetPassword(host, user, newPassword string) error {
 	return nil
 }
 
+// timestamp returns the current time in the format of User.PasswordChanged
+func (am *ACLManager) timestamp() string {
+	return am.now().UTC().Format(time.RFC3339)
+}
+
 // Grant grants permissions to a user
 func (am *ACLManager) Grant(host, user string, privileges []PermissionType, level PermissionLevel, db, table, column string) error {
 	am.mu.Lock()
@@ -404,31 +426,3 @@ func (am *ACLManager) IsLoaded() bool {
 	defer am.mu.RUnlock()
 	return am.loaded
 }
-
-// writeUsersFile writes user data to JSON file
-func (am *ACLManager) writeUsersFile(data *DataFile) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal users data: %w", err)
-	}
-
-	if err := os.WriteFile(am.usersFilePath, jsonData, 0600); err != nil {
-		return fmt.Errorf("failed to write users.json: %w", err)
-	}
-
-	return nil
-}
-
-// writePermissionsFile writes permission data to JSON file
-func (am *ACLManager) writePermissionsFile(data *DataFile) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal permissions data: %w", err)
-	}
-
-	if err := os.WriteFile(am.permsFilePath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write permissions.json: %w", err)
-	}
-
-	return nil
-}
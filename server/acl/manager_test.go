@@ -1,9 +1,16 @@
 package acl
 
 import (
+	"encoding/hex"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 )
 
 func TestNewACLManager(t *testing.T) {
@@ -254,6 +261,85 @@ func TestAuthenticate(t *testing.T) {
 	}
 }
 
+func TestAuthenticateNative(t *testing.T) {
+	am, err := NewACLManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewACLManager() error = %v, want nil", err)
+	}
+	if err := am.CreateUser("%", "app", "S3cret!pw"); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	scramble := []byte("0123456789abcdefghij")
+	response := func(password string) []byte {
+		b, _ := hex.DecodeString(utils.GeneratePasswordHash(password, scramble))
+		return b
+	}
+
+	if _, err := am.AuthenticateNative("app", "10.0.0.1", scramble, response("S3cret!pw")); err != nil {
+		t.Errorf("AuthenticateNative() with the right password error = %v", err)
+	}
+	if _, err := am.AuthenticateNative("app", "10.0.0.1", scramble, response("wrong")); !mysqlerrors.Is(err, mysqlerrors.ErrAccessDenied) {
+		t.Errorf("AuthenticateNative() with a wrong password error = %v, want ER_ACCESS_DENIED_ERROR", err)
+	}
+	if _, err := am.AuthenticateNative("app", "10.0.0.1", []byte("jihgfedcba9876543210"), response("S3cret!pw")); err == nil {
+		t.Error("AuthenticateNative() should reject a response computed for another scramble")
+	}
+	if _, err := am.AuthenticateNative("nobody", "10.0.0.1", scramble, nil); !mysqlerrors.Is(err, mysqlerrors.ErrAccessDenied) {
+		t.Errorf("AuthenticateNative() for an unknown user error = %v, want ER_ACCESS_DENIED_ERROR", err)
+	}
+	// root has no password
+	if _, err := am.AuthenticateNative("root", "127.0.0.1", scramble, nil); err != nil {
+		t.Errorf("AuthenticateNative() for root without password error = %v", err)
+	}
+	if _, err := am.AuthenticateNative("root", "127.0.0.1", scramble, response("x")); err == nil {
+		t.Error("AuthenticateNative() should reject a password for an account without one")
+	}
+}
+
+func TestPasswordPolicyEnforced(t *testing.T) {
+	am, err := NewACLManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewACLManager() error = %v, want nil", err)
+	}
+	am.SetPasswordPolicy(security.PasswordPolicy{MinLength: 8, MinDigits: 1, DisallowUserName: true, ExpireDays: 90})
+
+	var policyErr *security.PasswordPolicyError
+	if err := am.CreateUser("%", "app", "short1"); !errors.As(err, &policyErr) {
+		t.Errorf("CreateUser() with a short password error = %v, want PasswordPolicyError", err)
+	}
+	if err := am.CreateUser("%", "app", "myapp-pass1"); !errors.As(err, &policyErr) {
+		t.Errorf("CreateUser() with the user name in the password error = %v, want PasswordPolicyError", err)
+	}
+	if len(am.GetUsers()) != 1 {
+		t.Fatalf("rejected users should not be created, users = %d", len(am.GetUsers()))
+	}
+	if err := am.CreateUser("%", "app", "long-enough-1"); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := am.SetPassword("%", "app", "nodigits-here"); !errors.As(err, &policyErr) {
+		t.Errorf("SetPassword() error = %v, want PasswordPolicyError", err)
+	}
+
+	// The password expires 90 days after it was set
+	scramble := []byte("0123456789abcdefghij")
+	response, _ := hex.DecodeString(utils.GeneratePasswordHash("long-enough-1", scramble))
+	am.now = func() time.Time { return time.Now().Add(91 * 24 * time.Hour) }
+	var expiredErr *security.PasswordExpiredError
+	if _, err := am.AuthenticateNative("app", "10.0.0.1", scramble, response); !errors.As(err, &expiredErr) {
+		t.Errorf("AuthenticateNative() with an expired password error = %v, want PasswordExpiredError", err)
+	}
+
+	// Changing the password restarts the expiry period
+	if err := am.SetPassword("%", "app", "another-pass-2"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	response, _ = hex.DecodeString(utils.GeneratePasswordHash("another-pass-2", scramble))
+	if _, err := am.AuthenticateNative("app", "10.0.0.1", scramble, response); err != nil {
+		t.Errorf("AuthenticateNative() after the password change error = %v", err)
+	}
+}
+
 func TestACLManagerCheckPermission(t *testing.T) {
 	// Create temporary directory for test
 	tmpDir := t.TempDir()
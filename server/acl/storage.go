@@ -0,0 +1,118 @@
+package acl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Storage persists ACL users and permissions
+type Storage interface {
+	// Load returns the stored data, or nil if nothing has been stored yet
+	Load() (*DataFile, error)
+	// Save replaces the stored data
+	Save(data *DataFile) error
+}
+
+// FileStorage keeps users in users.json and database, table and column
+// permissions in permissions.json
+type FileStorage struct {
+	dataDir       string
+	usersFilePath string
+	permsFilePath string
+}
+
+// NewFileStorage creates a file storage in dataDir
+func NewFileStorage(dataDir string) *FileStorage {
+	return &FileStorage{
+		dataDir:       dataDir,
+		usersFilePath: filepath.Join(dataDir, "users.json"),
+		permsFilePath: filepath.Join(dataDir, "permissions.json"),
+	}
+}
+
+// Load reads users.json and permissions.json; it returns nil if users.json
+// does not exist
+func (fs *FileStorage) Load() (*DataFile, error) {
+	usersData, err := os.ReadFile(fs.usersFilePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users.json: %w", err)
+	}
+
+	var data DataFile
+	if err := json.Unmarshal(usersData, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse users.json: %w", err)
+	}
+
+	permsData, err := os.ReadFile(fs.permsFilePath)
+	if os.IsNotExist(err) {
+		return &data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permissions.json: %w", err)
+	}
+
+	var permsFile DataFile
+	if err := json.Unmarshal(permsData, &permsFile); err != nil {
+		return nil, fmt.Errorf("failed to parse permissions.json: %w", err)
+	}
+	data.DBPermissions = permsFile.DBPermissions
+	data.TablePermissions = permsFile.TablePermissions
+	data.ColumnPermissions = permsFile.ColumnPermissions
+	return &data, nil
+}
+
+// Save writes users.json and permissions.json, creating the data directory
+// if needed
+func (fs *FileStorage) Save(data *DataFile) error {
+	if err := os.MkdirAll(fs.dataDir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	usersData := DataFile{Users: data.Users}
+	if err := writeJSONFile(fs.usersFilePath, &usersData, 0600); err != nil {
+		return err
+	}
+
+	permsData := DataFile{
+		DBPermissions:     data.DBPermissions,
+		TablePermissions:  data.TablePermissions,
+		ColumnPermissions: data.ColumnPermissions,
+	}
+	return writeJSONFile(fs.permsFilePath, &permsData, 0644)
+}
+
+// writeJSONFile writes data as indented JSON
+func writeJSONFile(path string, data *DataFile, perm os.FileMode) error {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.WriteFile(path, jsonData, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// MigrateStorage copies the data of from into to if to is still empty.
+// It reports whether any data was copied.
+func MigrateStorage(from, to Storage) (bool, error) {
+	existing, err := to.Load()
+	if err != nil || existing != nil {
+		return false, err
+	}
+	data, err := from.Load()
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := to.Save(data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
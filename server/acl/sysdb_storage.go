@@ -0,0 +1,191 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/sysdb"
+)
+
+// Privilege levels stored in the level column of sqlexec.privileges
+const (
+	levelDatabase = "database"
+	levelTable    = "table"
+	levelColumn   = "column"
+)
+
+// SystemDBStorage keeps users in sqlexec.users, the time each password was
+// last set in sqlexec.user_passwords and database, table and column
+// permissions in sqlexec.privileges
+type SystemDBStorage struct {
+	sys *sysdb.SystemDB
+}
+
+// NewSystemDBStorage creates a storage backed by the system database
+func NewSystemDBStorage(sys *sysdb.SystemDB) *SystemDBStorage {
+	return &SystemDBStorage{sys: sys}
+}
+
+// Load reads users and permissions; it returns nil if there are no users
+func (s *SystemDBStorage) Load() (*DataFile, error) {
+	ctx := context.Background()
+	userRows, err := s.sys.Rows(ctx, sysdb.TableUsers)
+	if err != nil {
+		return nil, err
+	}
+	if len(userRows) == 0 {
+		return nil, nil
+	}
+	privRows, err := s.sys.Rows(ctx, sysdb.TablePrivileges)
+	if err != nil {
+		return nil, err
+	}
+	passwordRows, err := s.sys.Rows(ctx, sysdb.TableUserPasswords)
+	if err != nil {
+		return nil, err
+	}
+	passwordChanged := make(map[string]string, len(passwordRows))
+	for _, row := range passwordRows {
+		passwordChanged[rowString(row, "host")+"\x00"+rowString(row, "user")] = rowString(row, "changed_at")
+	}
+
+	data := &DataFile{
+		DBPermissions:     []DatabasePermission{},
+		TablePermissions:  []TablePermission{},
+		ColumnPermissions: []ColumnPermission{},
+	}
+	for _, row := range userRows {
+		privileges := DefaultPrivileges()
+		for priv := range parsePrivileges(row["privileges"]) {
+			privileges[priv] = true
+		}
+		host, user := rowString(row, "host"), rowString(row, "user")
+		data.Users = append(data.Users, User{
+			Host:            host,
+			User:            user,
+			Password:        rowString(row, "password"),
+			Privileges:      privileges,
+			PasswordChanged: passwordChanged[host+"\x00"+user],
+		})
+	}
+	for _, row := range privRows {
+		host, user, db := rowString(row, "host"), rowString(row, "user"), rowString(row, "db")
+		privileges := parsePrivileges(row["privileges"])
+		switch level := rowString(row, "level"); level {
+		case levelDatabase:
+			data.DBPermissions = append(data.DBPermissions, DatabasePermission{
+				Host: host, Db: db, User: user, Privileges: privileges,
+			})
+		case levelTable:
+			data.TablePermissions = append(data.TablePermissions, TablePermission{
+				Host: host, Db: db, User: user, TableName: rowString(row, "table_name"),
+				Grantor: rowString(row, "grantor"), Timestamp: rowString(row, "granted_at"), Privileges: privileges,
+			})
+		case levelColumn:
+			data.ColumnPermissions = append(data.ColumnPermissions, ColumnPermission{
+				Host: host, Db: db, User: user, TableName: rowString(row, "table_name"),
+				ColumnName: rowString(row, "column_name"), Timestamp: rowString(row, "granted_at"), Privileges: privileges,
+			})
+		default:
+			return nil, fmt.Errorf("invalid privilege level %q for user '%s'@'%s'", level, user, host)
+		}
+	}
+	return data, nil
+}
+
+// Save replaces the rows of sqlexec.users and sqlexec.privileges
+func (s *SystemDBStorage) Save(data *DataFile) error {
+	ctx := context.Background()
+	userRows := make([]domain.Row, 0, len(data.Users))
+	var passwordRows []domain.Row
+	for _, u := range data.Users {
+		userRows = append(userRows, domain.Row{
+			"host":       u.Host,
+			"user":       u.User,
+			"password":   u.Password,
+			"privileges": formatPrivileges(u.Privileges),
+		})
+		if u.PasswordChanged != "" {
+			passwordRows = append(passwordRows, domain.Row{
+				"host":       u.Host,
+				"user":       u.User,
+				"changed_at": u.PasswordChanged,
+			})
+		}
+	}
+
+	var privRows []domain.Row
+	privRow := func(level, host, user, db, table, column, grantor, grantedAt string, privileges map[string]bool) {
+		privRows = append(privRows, domain.Row{
+			"host":        host,
+			"user":        user,
+			"level":       level,
+			"db":          db,
+			"table_name":  table,
+			"column_name": column,
+			"grantor":     grantor,
+			"granted_at":  grantedAt,
+			"privileges":  formatPrivileges(privileges),
+		})
+	}
+	for _, p := range data.DBPermissions {
+		privRow(levelDatabase, p.Host, p.User, p.Db, "", "", "", "", p.Privileges)
+	}
+	for _, p := range data.TablePermissions {
+		privRow(levelTable, p.Host, p.User, p.Db, p.TableName, "", p.Grantor, p.Timestamp, p.Privileges)
+	}
+	for _, p := range data.ColumnPermissions {
+		privRow(levelColumn, p.Host, p.User, p.Db, p.TableName, p.ColumnName, "", p.Timestamp, p.Privileges)
+	}
+
+	if err := s.sys.ReplaceRows(ctx, sysdb.TableUsers, userRows); err != nil {
+		return err
+	}
+	if err := s.sys.ReplaceRows(ctx, sysdb.TableUserPasswords, passwordRows); err != nil {
+		return err
+	}
+	return s.sys.ReplaceRows(ctx, sysdb.TablePrivileges, privRows)
+}
+
+// formatPrivileges lists the granted privileges, comma-separated, in the
+// order of AllPermissionTypes
+func formatPrivileges(privileges map[string]bool) string {
+	var granted []string
+	known := make(map[string]bool)
+	for _, p := range AllPermissionTypes() {
+		known[string(p)] = true
+		if privileges[string(p)] {
+			granted = append(granted, string(p))
+		}
+	}
+	var extra []string
+	for priv, ok := range privileges {
+		if ok && !known[priv] {
+			extra = append(extra, priv)
+		}
+	}
+	sort.Strings(extra)
+	return strings.Join(append(granted, extra...), ",")
+}
+
+// parsePrivileges parses a comma-separated privilege list
+func parsePrivileges(v interface{}) map[string]bool {
+	privileges := make(map[string]bool)
+	s, _ := v.(string)
+	for _, priv := range strings.Split(s, ",") {
+		if priv = strings.ToUpper(strings.TrimSpace(priv)); priv != "" {
+			privileges[priv] = true
+		}
+	}
+	return privileges
+}
+
+func rowString(row domain.Row, key string) string {
+	if v, ok := row[key]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
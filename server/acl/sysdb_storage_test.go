@@ -0,0 +1,119 @@
+package acl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/sysdb"
+)
+
+func newTestSystemDB(t *testing.T) *sysdb.SystemDB {
+	t.Helper()
+	ds, err := sysdb.NewStorage(sysdb.StorageMemory, "")
+	if err != nil {
+		t.Fatalf("NewStorage() failed: %v", err)
+	}
+	sys, err := sysdb.Open(context.Background(), ds)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	return sys
+}
+
+func TestSystemDBStorage(t *testing.T) {
+	sys := newTestSystemDB(t)
+	storage := NewSystemDBStorage(sys)
+
+	am, err := NewACLManagerWithStorage(storage)
+	if err != nil {
+		t.Fatalf("NewACLManagerWithStorage() failed: %v", err)
+	}
+	if !am.CheckPermission("root", "127.0.0.1", PrivSuper, "", "", "") {
+		t.Error("default root user should have SUPER")
+	}
+
+	if err := am.CreateUser("%", "app", "secret"); err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+	if err := am.Grant("%", "app", []PermissionType{PrivSelect, PrivInsert}, PermissionLevelDatabase, "shop", "", ""); err != nil {
+		t.Fatalf("Grant() failed: %v", err)
+	}
+	if err := am.Grant("%", "app", []PermissionType{PrivUpdate}, PermissionLevelTable, "shop", "orders", ""); err != nil {
+		t.Fatalf("Grant() failed: %v", err)
+	}
+
+	rows, err := sys.Rows(context.Background(), sysdb.TablePrivileges)
+	if err != nil {
+		t.Fatalf("Rows() failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("privileges rows = %d, want 2", len(rows))
+	}
+	for _, row := range rows {
+		if row["level"] == levelDatabase && row["privileges"] != "SELECT,INSERT" {
+			t.Errorf("database privileges = %v, want SELECT,INSERT", row["privileges"])
+		}
+	}
+
+	// A new manager on the same system database sees the same users and grants
+	reloaded, err := NewACLManagerWithStorage(NewSystemDBStorage(sys))
+	if err != nil {
+		t.Fatalf("NewACLManagerWithStorage() failed: %v", err)
+	}
+	if len(reloaded.GetUsers()) != 2 {
+		t.Errorf("users = %d, want 2", len(reloaded.GetUsers()))
+	}
+	if _, err := reloaded.Authenticate("app", "secret"); err != nil {
+		t.Errorf("Authenticate() failed: %v", err)
+	}
+	for _, u := range reloaded.GetUsers() {
+		if u.User == "app" && u.PasswordChanged == "" {
+			t.Error("password change time should be kept in sqlexec.user_passwords")
+		}
+	}
+	if !reloaded.CheckPermission("app", "10.0.0.1", PrivInsert, "shop", "items", "") {
+		t.Error("app should have INSERT on shop")
+	}
+	if !reloaded.CheckPermission("app", "10.0.0.1", PrivUpdate, "shop", "orders", "") {
+		t.Error("app should have UPDATE on shop.orders")
+	}
+	if reloaded.CheckPermission("app", "10.0.0.1", PrivDelete, "shop", "orders", "") {
+		t.Error("app should not have DELETE on shop.orders")
+	}
+}
+
+func TestMigrateStorage(t *testing.T) {
+	files := NewFileStorage(t.TempDir())
+	am, err := NewACLManagerWithStorage(files)
+	if err != nil {
+		t.Fatalf("NewACLManagerWithStorage() failed: %v", err)
+	}
+	if err := am.CreateUser("localhost", "legacy", ""); err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+
+	target := NewSystemDBStorage(newTestSystemDB(t))
+	migrated, err := MigrateStorage(files, target)
+	if err != nil || !migrated {
+		t.Fatalf("MigrateStorage() = %v, %v, want true", migrated, err)
+	}
+	data, err := target.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(data.Users) != 2 {
+		t.Errorf("migrated users = %d, want 2", len(data.Users))
+	}
+
+	// The target is no longer empty, so nothing is copied again
+	migrated, err = MigrateStorage(files, target)
+	if err != nil || migrated {
+		t.Errorf("MigrateStorage() = %v, %v, want false", migrated, err)
+	}
+
+	// An empty source leaves the target untouched
+	migrated, err = MigrateStorage(NewFileStorage(t.TempDir()), NewSystemDBStorage(newTestSystemDB(t)))
+	if err != nil || migrated {
+		t.Errorf("MigrateStorage() = %v, %v, want false", migrated, err)
+	}
+}
@@ -55,6 +55,8 @@ type User struct {
 	User       string          `json:"user"`
 	Password   string          `json:"password"`   // Empty string means no password
 	Privileges map[string]bool `json:"privileges"` // Map of PermissionType -> true/false (global level)
+	// PasswordChanged is when the password was last set (RFC 3339, UTC); empty if unknown
+	PasswordChanged string `json:"password_changed,omitempty"`
 }
 
 // DatabasePermission represents database-level permissions (from mysql.db)
@@ -135,6 +135,20 @@ func (um *UserManager) SetPassword(host, user, newPasswordHash string) error {
 	return nil
 }
 
+// SetPasswordChanged records when the user's password was last set
+func (um *UserManager) SetPasswordChanged(host, user, changedAt string) error {
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	u, exists := um.users[um.makeKey(host, user)]
+	if !exists {
+		return fmt.Errorf("user '%s'@'%s' does not exist", user, host)
+	}
+
+	u.PasswordChanged = changedAt
+	return nil
+}
+
 // SetPrivilege sets a specific privilege for a user
 func (um *UserManager) SetPrivilege(host, user string, priv PermissionType, granted bool) error {
 	um.mu.Lock()
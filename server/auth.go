@@ -0,0 +1,49 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+)
+
+// authenticate 握手或 COM_CHANGE_USER 时按 ACL 校验 mysql_native_password 认证响应（auth.verify_passwords）
+// 账号在该 IP 上处于锁定期时直接拒绝；失败后按指数退避等待再回复错误，连续失败达到上限时锁定并记录审计事件
+func (s *Server) authenticate(sess *pkg_session.Session, scramble, authResponse []byte) error {
+	user, ip := sess.User, sess.RemoteIP
+	if s.aclManager == nil {
+		// ACL 初始化失败时无法校验密码，拒绝所有登录
+		return mysqlerrors.New(mysqlerrors.ErrAccessDenied, "Access denied for user '%s'@'%s'", user, ip)
+	}
+	if err := s.loginThrottle.Check(user, ip); err != nil {
+		return err
+	}
+
+	_, err := s.aclManager.AuthenticateNative(user, ip, scramble, authResponse)
+	var expired *security.PasswordExpiredError
+	if err == nil || errors.As(err, &expired) {
+		// 密码正确（即使已过期）时清除失败记录
+		s.loginThrottle.Success(user, ip)
+		return err
+	}
+
+	delay, locked := s.loginThrottle.Failure(user, ip)
+	if locked {
+		lockout := s.loginThrottle.LockoutDuration()
+		s.logger.Printf("用户 %s 从 %s 连续登录失败，锁定 %s", user, ip, lockout)
+		if s.auditLogger != nil {
+			s.auditLogger.LogLockout(sess.TraceID, user, ip, s.config.Auth.Throttle.MaxFailures, lockout)
+		}
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+		}
+	}
+	return err
+}
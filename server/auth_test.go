@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/kasuganosora/sqlexec/server/acl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_AuthenticateThrottlesAndLocksOut(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Auth.VerifyPasswords = true
+	cfg.Auth.Throttle = security.LoginThrottleConfig{MaxFailures: 3, BaseDelay: "0s"}
+	s := newTestServer(t, context.Background(), listener, cfg)
+	require.NotNil(t, s)
+
+	// 使用临时目录中的 ACL，避免修改工作目录下的 users.json
+	am, err := acl.NewACLManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, am.CreateUser("%", "app", "S3cret!pw"))
+	s.aclManager = am
+	audit := security.NewAuditLogger(100)
+	s.SetAuditLogger(audit)
+
+	scramble := []byte("0123456789abcdefghij")
+	response := func(password string) []byte {
+		b, _ := hex.DecodeString(utils.GeneratePasswordHash(password, scramble))
+		return b
+	}
+	sess := &pkg_session.Session{User: "app", RemoteIP: "10.0.0.1"}
+
+	require.NoError(t, s.authenticate(sess, scramble, response("S3cret!pw")))
+	for i := 0; i < 3; i++ {
+		err := s.authenticate(sess, scramble, response("guess"))
+		assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrAccessDenied), "attempt %d: %v", i+1, err)
+	}
+	require.Len(t, audit.GetEventsByType(security.EventTypeLockout), 1)
+
+	// 锁定期内即使密码正确也被拒绝
+	err = s.authenticate(sess, scramble, response("S3cret!pw"))
+	var locked *security.AccountLockedError
+	require.True(t, errors.As(err, &locked), "got %v", err)
+	assert.True(t, mysqlerrors.Is(err, mysqlerrors.ErrAccountLocked))
+
+	// 锁定只作用于该用户和 IP 的组合
+	other := &pkg_session.Session{User: "app", RemoteIP: "10.0.0.2"}
+	assert.NoError(t, s.authenticate(other, scramble, response("S3cret!pw")))
+
+	unknown := &pkg_session.Session{User: "nobody", RemoteIP: "10.0.0.1"}
+	assert.True(t, mysqlerrors.Is(s.authenticate(unknown, scramble, nil), mysqlerrors.ErrAccessDenied))
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+)
+
+// ErrTooManyConnections 全局连接数已达上限（ER_CON_COUNT_ERROR）
+var ErrTooManyConnections = mysqlerrors.New(mysqlerrors.ErrConCount, "Too many connections")
+
+// TooManyUserConnectionsError 单用户连接数已达上限（ER_TOO_MANY_USER_CONNECTIONS）
+type TooManyUserConnectionsError struct {
+	User string
+}
+
+func (e *TooManyUserConnectionsError) Error() string {
+	return fmt.Sprintf("User %s already has more than 'max_user_connections' active connections", e.User)
+}
+
+// MySQLErrorCode 实现 mysqlerrors.Coder
+func (e *TooManyUserConnectionsError) MySQLErrorCode() uint16 {
+	return mysqlerrors.ErrTooManyUserConnections
+}
+
+// ConnLimiter 连接准入控制（并发安全）
+// 负责全局 max_connections、单用户 max_user_connections 以及达到上限后的等待队列
+type ConnLimiter struct {
+	mu                 sync.Mutex
+	maxConnections     int // 0 表示不限制
+	maxUserConnections int // 0 表示不限制
+	queueSize          int
+	queueTimeout       time.Duration
+	active             int
+	userCounts         map[string]int
+	threadUsers        map[uint32]string // ThreadID -> 已占用名额的用户
+	waiters            utils.WaitQueue
+}
+
+// NewConnLimiter 根据服务器配置创建连接准入控制器
+func NewConnLimiter(cfg *config.ServerConfig) *ConnLimiter {
+	l := &ConnLimiter{
+		userCounts:  make(map[string]int),
+		threadUsers: make(map[uint32]string),
+	}
+	if cfg != nil {
+		l.maxConnections = cfg.MaxConnections
+		l.maxUserConnections = cfg.MaxUserConnections
+		l.queueSize = cfg.ConnectionQueueSize
+		l.queueTimeout = cfg.ConnectionQueueTimeout
+	}
+	return l
+}
+
+// Acquire 为新连接占用一个全局名额
+// 达到上限时进入等待队列；队列已满或等待超时返回 ErrTooManyConnections
+func (l *ConnLimiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.maxConnections <= 0 || l.active < l.maxConnections {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.waiters.Len() >= l.queueSize || l.queueTimeout <= 0 {
+		l.mu.Unlock()
+		return ErrTooManyConnections
+	}
+	ready := l.waiters.Enqueue()
+	timeout := l.queueTimeout
+	l.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.waiters.Remove(ready) {
+		// 超时的同时恰好被分配了名额
+		return nil
+	}
+	return ErrTooManyConnections
+}
+
+// Release 释放一个全局名额，并唤醒等待队列中的下一个连接
+func (l *ConnLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active > 0 {
+		l.active--
+	}
+	l.grantLocked()
+}
+
+// AcquireUser 为已认证的连接占用一个用户名额
+func (l *ConnLimiter) AcquireUser(threadID uint32, user string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, held := l.threadUsers[threadID]; held {
+		return nil
+	}
+	if l.maxUserConnections > 0 && l.userCounts[user] >= l.maxUserConnections {
+		return &TooManyUserConnectionsError{User: user}
+	}
+	l.userCounts[user]++
+	l.threadUsers[threadID] = user
+	return nil
+}
+
+// ReleaseUser 释放连接占用的用户名额（未占用时为空操作）
+func (l *ConnLimiter) ReleaseUser(threadID uint32) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	user, held := l.threadUsers[threadID]
+	if !held {
+		return
+	}
+	delete(l.threadUsers, threadID)
+	if l.userCounts[user] <= 1 {
+		delete(l.userCounts, user)
+	} else {
+		l.userCounts[user]--
+	}
+}
+
+// SetMaxConnections 运行时调整全局最大连接数（SET GLOBAL max_connections）
+// 调大上限时会立即放行等待队列中的连接；调小不会断开已有连接
+func (l *ConnLimiter) SetMaxConnections(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxConnections = n
+	l.grantLocked()
+}
+
+// SetMaxUserConnections 运行时调整单用户最大连接数（SET GLOBAL max_user_connections）
+func (l *ConnLimiter) SetMaxUserConnections(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxUserConnections = n
+}
+
+// MaxConnections 返回当前全局最大连接数
+func (l *ConnLimiter) MaxConnections() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxConnections
+}
+
+// MaxUserConnections 返回当前单用户最大连接数
+func (l *ConnLimiter) MaxUserConnections() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxUserConnections
+}
+
+// Active 返回当前占用名额的连接数
+func (l *ConnLimiter) Active() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active
+}
+
+// UserConnections 返回指定用户当前的连接数
+func (l *ConnLimiter) UserConnections(user string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.userCounts[user]
+}
+
+// Waiting 返回等待队列中的连接数
+func (l *ConnLimiter) Waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiters.Len()
+}
+
+// grantLocked 在有空闲名额时按 FIFO 顺序放行等待者（调用方需持有锁）
+func (l *ConnLimiter) grantLocked() {
+	for l.waiters.Len() > 0 && (l.maxConnections <= 0 || l.active < l.maxConnections) {
+		l.active++
+		l.waiters.Admit()
+	}
+}
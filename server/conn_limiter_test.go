@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnLimiter_GlobalLimit(t *testing.T) {
+	l := NewConnLimiter(&config.ServerConfig{MaxConnections: 2})
+
+	require.NoError(t, l.Acquire(context.Background()))
+	require.NoError(t, l.Acquire(context.Background()))
+	assert.Equal(t, 2, l.Active())
+
+	err := l.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrTooManyConnections)
+
+	l.Release()
+	assert.NoError(t, l.Acquire(context.Background()))
+}
+
+func TestConnLimiter_Unlimited(t *testing.T) {
+	l := NewConnLimiter(&config.ServerConfig{MaxConnections: 0})
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.Acquire(context.Background()))
+	}
+	assert.Equal(t, 100, l.Active())
+}
+
+func TestConnLimiter_QueueGrantedOnRelease(t *testing.T) {
+	l := NewConnLimiter(&config.ServerConfig{
+		MaxConnections:         1,
+		ConnectionQueueSize:    1,
+		ConnectionQueueTimeout: 5 * time.Second,
+	})
+	require.NoError(t, l.Acquire(context.Background()))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Acquire(context.Background())
+	}()
+
+	require.Eventually(t, func() bool { return l.Waiting() == 1 }, time.Second, 5*time.Millisecond)
+
+	// 队列已满，第三个连接直接被拒绝
+	assert.ErrorIs(t, l.Acquire(context.Background()), ErrTooManyConnections)
+
+	l.Release()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("queued connection was not granted")
+	}
+	assert.Equal(t, 1, l.Active())
+	assert.Equal(t, 0, l.Waiting())
+}
+
+func TestConnLimiter_QueueTimeout(t *testing.T) {
+	l := NewConnLimiter(&config.ServerConfig{
+		MaxConnections:         1,
+		ConnectionQueueSize:    4,
+		ConnectionQueueTimeout: 20 * time.Millisecond,
+	})
+	require.NoError(t, l.Acquire(context.Background()))
+
+	start := time.Now()
+	err := l.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrTooManyConnections)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, 0, l.Waiting())
+	assert.Equal(t, 1, l.Active())
+}
+
+func TestConnLimiter_SetMaxConnectionsWakesWaiters(t *testing.T) {
+	l := NewConnLimiter(&config.ServerConfig{
+		MaxConnections:         1,
+		ConnectionQueueSize:    2,
+		ConnectionQueueTimeout: 5 * time.Second,
+	})
+	require.NoError(t, l.Acquire(context.Background()))
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- l.Acquire(context.Background())
+		}()
+	}
+	require.Eventually(t, func() bool { return l.Waiting() == 2 }, time.Second, 5*time.Millisecond)
+
+	l.SetMaxConnections(3)
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("waiter was not granted after raising max_connections")
+		}
+	}
+	assert.Equal(t, 3, l.Active())
+	assert.Equal(t, 3, l.MaxConnections())
+}
+
+func TestConnLimiter_PerUserLimit(t *testing.T) {
+	l := NewConnLimiter(&config.ServerConfig{MaxUserConnections: 2})
+
+	require.NoError(t, l.AcquireUser(1, "alice"))
+	require.NoError(t, l.AcquireUser(2, "alice"))
+	require.NoError(t, l.AcquireUser(3, "bob"))
+
+	err := l.AcquireUser(4, "alice")
+	var userErr *TooManyUserConnectionsError
+	require.ErrorAs(t, err, &userErr)
+	assert.Equal(t, "alice", userErr.User)
+
+	// 同一线程重复占用是幂等的
+	require.NoError(t, l.AcquireUser(1, "alice"))
+	assert.Equal(t, 2, l.UserConnections("alice"))
+
+	l.ReleaseUser(1)
+	l.ReleaseUser(1)
+	assert.Equal(t, 1, l.UserConnections("alice"))
+	assert.NoError(t, l.AcquireUser(4, "alice"))
+
+	l.SetMaxUserConnections(0)
+	assert.NoError(t, l.AcquireUser(5, "alice"))
+}
+
+func TestServer_HandleConnection_TooManyConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Server.MaxConnections = 1
+	s := newTestServer(t, context.Background(), listener, cfg)
+	require.NotNil(t, s)
+
+	// 占满唯一名额
+	require.NoError(t, s.GetConnLimiter().Acquire(context.Background()))
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.handleConnection(serverConn)
+	}()
+
+	buf := make([]byte, 256)
+	clientConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	require.Greater(t, n, 7)
+
+	// 错误包：序列号 0，头 0xff，错误码 1040，SQLSTATE 08004
+	assert.Equal(t, byte(0x00), buf[3])
+	assert.Equal(t, byte(0xff), buf[4])
+	assert.Equal(t, uint16(1040), uint16(buf[5])|uint16(buf[6])<<8)
+	assert.Equal(t, "#08004", string(buf[7:13]))
+	assert.Contains(t, string(buf[13:n]), "Too many connections")
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrTooManyConnections)
+	case <-time.After(5 * time.Second):
+		t.Fatal("handleConnection did not return in time")
+	}
+}
+
+func TestServer_SetGlobalMaxConnections(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newTestServer(t, context.Background(), listener, nil)
+	require.NotNil(t, s)
+
+	sess := s.GetDB().Session()
+	defer sess.Close()
+
+	_, err = sess.Query("SET GLOBAL max_connections = 7")
+	require.NoError(t, err)
+	assert.Equal(t, 7, s.GetConnLimiter().MaxConnections())
+
+	_, err = sess.Query("SET GLOBAL max_user_connections = 3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.GetConnLimiter().MaxUserConnections())
+
+	_, err = sess.Query("SET GLOBAL max_connections = 'abc'")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Incorrect argument type to variable 'max_connections'")
+	assert.Equal(t, 7, s.GetConnLimiter().MaxConnections())
+}
+
+func TestServer_SetGlobalMaxConnections_PerServer(t *testing.T) {
+	l1, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l1.Close()
+	l2, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l2.Close()
+
+	s1 := newTestServer(t, context.Background(), l1, nil)
+	s2 := newTestServer(t, context.Background(), l2, nil)
+	before := s2.GetConnLimiter().MaxConnections()
+
+	// Each server's SET GLOBAL only adjusts its own limiter
+	sess := s1.GetDB().Session()
+	defer sess.Close()
+	_, err = sess.Query("SET GLOBAL max_connections = 5")
+	require.NoError(t, err)
+	assert.Equal(t, 5, s1.GetConnLimiter().MaxConnections())
+	assert.Equal(t, before, s2.GetConnLimiter().MaxConnections())
+}
+
+func TestServer_AdmitUser_MovesQuotaOnChangeUser(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Server.MaxUserConnections = 1
+	s := newTestServer(t, context.Background(), listener, cfg)
+	require.NotNil(t, s)
+
+	sess := &pkg_session.Session{ThreadID: 42, User: "alice"}
+	require.NoError(t, s.admitUser(sess))
+	assert.Equal(t, 1, s.GetConnLimiter().UserConnections("alice"))
+
+	// COM_CHANGE_USER：释放 alice 的名额并占用 bob 的名额
+	sess.User = "bob"
+	require.NoError(t, s.admitUser(sess))
+	assert.Equal(t, 0, s.GetConnLimiter().UserConnections("alice"))
+	assert.Equal(t, 1, s.GetConnLimiter().UserConnections("bob"))
+
+	other := &pkg_session.Session{ThreadID: 43, User: "bob"}
+	assert.Error(t, s.admitUser(other))
+}
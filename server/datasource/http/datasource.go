@@ -139,9 +139,10 @@ func (ds *HTTPDataSource) Query(ctx context.Context, tableName string, options *
 
 	httpTable := ds.httpCfg.ResolveTableName(tableName)
 
-	req := &QueryRequest{}
+	req := &QueryRequest{Labels: domain.QueryLabelsFromContext(ctx)}
 	if options != nil {
 		req.Filters = options.Filters
+		req.Sort = options.SortKeys()
 		req.OrderBy = options.OrderBy
 		req.Order = options.Order
 		req.Limit = options.Limit
@@ -158,6 +159,7 @@ func (ds *HTTPDataSource) Query(ctx context.Context, tableName string, options *
 		Columns: resp.Columns,
 		Rows:    resp.Rows,
 		Total:   resp.Total,
+		Ordered: resp.Ordered,
 	}, nil
 }
 
@@ -175,6 +177,7 @@ func (ds *HTTPDataSource) Insert(ctx context.Context, tableName string, rows []d
 	req := &InsertRequest{
 		Rows:    rows,
 		Options: options,
+		Labels:  domain.QueryLabelsFromContext(ctx),
 	}
 
 	var resp MutationResponse
@@ -200,6 +203,7 @@ func (ds *HTTPDataSource) Update(ctx context.Context, tableName string, filters
 		Filters: filters,
 		Updates: updates,
 		Options: options,
+		Labels:  domain.QueryLabelsFromContext(ctx),
 	}
 
 	var resp MutationResponse
@@ -224,6 +228,7 @@ func (ds *HTTPDataSource) Delete(ctx context.Context, tableName string, filters
 	req := &DeleteRequest{
 		Filters: filters,
 		Options: options,
+		Labels:  domain.QueryLabelsFromContext(ctx),
 	}
 
 	var resp MutationResponse
@@ -272,6 +277,7 @@ func (ds *HTTPDataSource) Filter(ctx context.Context, tableName string, filter d
 	req := &QueryRequest{
 		Offset: offset,
 		Limit:  limit,
+		Labels: domain.QueryLabelsFromContext(ctx),
 	}
 
 	// 将单个 filter 转换为 filters 列表
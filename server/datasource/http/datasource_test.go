@@ -563,6 +563,41 @@ func TestHTTPDataSource_CustomHeaders(t *testing.T) {
 	assert.Equal(t, "Bearer my_secret", receivedHeaders.Get("Authorization"))
 }
 
+func TestHTTPDataSource_QueryLabels(t *testing.T) {
+	var bodies []map[string]interface{}
+
+	ts := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+		if r.URL.Path == "/_health" {
+			json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		json.NewEncoder(w).Encode(MutationResponse{Affected: 1})
+	}))
+	defer ts.Close()
+
+	ds := createTestDS(t, ts.URL, true)
+	require.NoError(t, ds.Connect(context.Background()))
+
+	labels := map[string]string{"app": "checkout", "request_id": "abc"}
+	ctx := domain.WithQueryLabels(context.Background(), labels)
+	_, err := ds.Query(ctx, "users", nil)
+	require.NoError(t, err)
+	_, err = ds.Update(ctx, "users", nil, domain.Row{"name": "x"}, nil)
+	require.NoError(t, err)
+	_, err = ds.Query(context.Background(), "users", nil)
+	require.NoError(t, err)
+
+	require.Len(t, bodies, 3)
+	expected := map[string]interface{}{"app": "checkout", "request_id": "abc"}
+	assert.Equal(t, expected, bodies[0]["labels"])
+	assert.Equal(t, expected, bodies[1]["labels"])
+	// 没有标签时不发送 labels 字段
+	assert.NotContains(t, bodies[2], "labels")
+}
+
 func TestHTTPDataSource_Retry(t *testing.T) {
 	attempts := 0
 	ts := httptest.NewServer(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
@@ -6,18 +6,21 @@ import "github.com/kasuganosora/sqlexec/pkg/resource/domain"
 
 // QueryRequest 查询请求
 type QueryRequest struct {
-	Filters       []domain.Filter `json:"filters,omitempty"`
-	OrderBy       string          `json:"order_by,omitempty"`
-	Order         string          `json:"order,omitempty"` // ASC, DESC
-	Limit         int             `json:"limit,omitempty"`
-	Offset        int             `json:"offset,omitempty"`
-	SelectColumns []string        `json:"select_columns,omitempty"`
+	Filters       []domain.Filter      `json:"filters,omitempty"`
+	Sort          []domain.OrderByItem `json:"sort,omitempty"` // 多列排序，服务端按其排序后应在响应中设置 ordered
+	OrderBy       string               `json:"order_by,omitempty"`
+	Order         string               `json:"order,omitempty"` // ASC, DESC
+	Limit         int                  `json:"limit,omitempty"`
+	Offset        int                  `json:"offset,omitempty"`
+	SelectColumns []string             `json:"select_columns,omitempty"`
+	Labels        map[string]string    `json:"labels,omitempty"` // 查询标签（如 app、request_id），供服务端关联请求
 }
 
 // InsertRequest 插入请求
 type InsertRequest struct {
 	Rows    []domain.Row          `json:"rows"`
 	Options *domain.InsertOptions `json:"options,omitempty"`
+	Labels  map[string]string     `json:"labels,omitempty"`
 }
 
 // UpdateRequest 更新请求
@@ -25,12 +28,14 @@ type UpdateRequest struct {
 	Filters []domain.Filter       `json:"filters,omitempty"`
 	Updates domain.Row            `json:"updates"`
 	Options *domain.UpdateOptions `json:"options,omitempty"`
+	Labels  map[string]string     `json:"labels,omitempty"`
 }
 
 // DeleteRequest 删除请求
 type DeleteRequest struct {
 	Filters []domain.Filter       `json:"filters,omitempty"`
 	Options *domain.DeleteOptions `json:"options,omitempty"`
+	Labels  map[string]string     `json:"labels,omitempty"`
 }
 
 // ── HTTP API 响应结构体 ──
@@ -40,6 +45,7 @@ type QueryResponse struct {
 	Columns []domain.ColumnInfo `json:"columns"`
 	Rows    []domain.Row        `json:"rows"`
 	Total   int64               `json:"total"`
+	Ordered bool                `json:"ordered,omitempty"` // 已按请求的 sort 完整排序
 }
 
 // TablesResponse 表列表响应
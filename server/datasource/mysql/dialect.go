@@ -8,6 +8,7 @@ import (
 
 	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 	sqlcommon "github.com/kasuganosora/sqlexec/server/datasource/sql"
 )
 
@@ -58,7 +59,7 @@ func (d *MySQLDialect) BuildDSN(dsCfg *domain.DataSourceConfig, sqlCfg *sqlcommo
 }
 
 func (d *MySQLDialect) QuoteIdentifier(name string) string {
-	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	return security.QuoteIdentifier(name)
 }
 
 func (d *MySQLDialect) Placeholder(n int) string {
@@ -40,14 +40,19 @@ func BuildSelectSQL(d Dialect, tableName string, options *domain.QueryOptions, p
 		}
 	}
 
-	// ORDER BY
-	if options != nil && options.OrderBy != "" {
+	// ORDER BY (all sort keys; collations follow the remote server, so results are not reported as Ordered)
+	if keys := options.SortKeys(); len(keys) > 0 {
 		sb.WriteString(" ORDER BY ")
-		sb.WriteString(d.QuoteIdentifier(options.OrderBy))
-		if strings.EqualFold(options.Order, "DESC") {
-			sb.WriteString(" DESC")
-		} else {
-			sb.WriteString(" ASC")
+		for i, key := range keys {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(d.QuoteIdentifier(key.Column))
+			if strings.EqualFold(key.Direction, "DESC") {
+				sb.WriteString(" DESC")
+			} else {
+				sb.WriteString(" ASC")
+			}
 		}
 	}
 
@@ -158,15 +163,32 @@ func buildFilterClause(d Dialect, f domain.Filter, paramOffset int) (string, []i
 			placeholders[i] = d.Placeholder(paramOffset + i + 1)
 		}
 		return quotedField + " IN (" + strings.Join(placeholders, ", ") + ")", values
-	case "BETWEEN":
+	case "NOT IN":
+		values := toSlice(f.Value)
+		if len(values) == 0 {
+			return "1=1", nil // NOT IN empty set is always true
+		}
+		placeholders := make([]string, len(values))
+		for i := range values {
+			placeholders[i] = d.Placeholder(paramOffset + i + 1)
+		}
+		return quotedField + " NOT IN (" + strings.Join(placeholders, ", ") + ")", values
+	case "BETWEEN", "NOT BETWEEN":
 		values := toSlice(f.Value)
 		if len(values) < 2 {
 			return "", nil
 		}
 		p1 := d.Placeholder(paramOffset + 1)
 		p2 := d.Placeholder(paramOffset + 2)
-		return quotedField + " BETWEEN " + p1 + " AND " + p2, values[:2]
-	case "=", "!=", "<>", ">", "<", ">=", "<=", "LIKE":
+		return quotedField + " " + op + " " + p1 + " AND " + p2, values[:2]
+	case "<=>":
+		// NULL-safe equality: portable across dialects without a <=> operator
+		if f.Value == nil {
+			return quotedField + " IS NULL", nil
+		}
+		ph := d.Placeholder(paramOffset + 1)
+		return quotedField + " = " + ph, []interface{}{f.Value}
+	case "=", "!=", "<>", ">", "<", ">=", "<=", "LIKE", "NOT LIKE":
 		ph := d.Placeholder(paramOffset + 1)
 		if op == "!=" {
 			op = "<>"
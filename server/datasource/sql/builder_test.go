@@ -86,6 +86,24 @@ func TestBuildSelectSQL_WithOrderAndLimit(t *testing.T) {
 	}
 }
 
+func TestBuildSelectSQL_WithMultiKeyOrder(t *testing.T) {
+	d := &testDialect{}
+
+	options := &domain.QueryOptions{
+		Sort: []domain.OrderByItem{
+			{Column: "dept"},
+			{Column: "name", Direction: "desc", Collation: "utf8mb4_general_ci"},
+		},
+		OrderBy: "dept",
+	}
+
+	sql, _ := BuildSelectSQL(d, "users", options, 0)
+	expected := "SELECT * FROM `users` ORDER BY `dept` ASC, `name` DESC"
+	if sql != expected {
+		t.Errorf("expected %q, got %q", expected, sql)
+	}
+}
+
 func TestBuildWhereClause_IN(t *testing.T) {
 	d := &testDialect{}
 
@@ -120,6 +138,33 @@ func TestBuildWhereClause_BETWEEN(t *testing.T) {
 	}
 }
 
+func TestBuildWhereClause_NegatedOperators(t *testing.T) {
+	d := &testDialect{}
+
+	tests := []struct {
+		filter     domain.Filter
+		expected   string
+		paramCount int
+	}{
+		{domain.Filter{Field: "status", Operator: "NOT IN", Value: []interface{}{"a", "b"}}, "`status` NOT IN (?, ?)", 2},
+		{domain.Filter{Field: "status", Operator: "NOT IN", Value: []interface{}{}}, "1=1", 0},
+		{domain.Filter{Field: "age", Operator: "NOT BETWEEN", Value: []interface{}{18, 65}}, "`age` NOT BETWEEN ? AND ?", 2},
+		{domain.Filter{Field: "name", Operator: "NOT LIKE", Value: "a%"}, "`name` NOT LIKE ?", 1},
+		{domain.Filter{Field: "name", Operator: "<=>", Value: "a"}, "`name` = ?", 1},
+		{domain.Filter{Field: "name", Operator: "<=>", Value: nil}, "`name` IS NULL", 0},
+	}
+
+	for _, tt := range tests {
+		clause, params := BuildWhereClause(d, []domain.Filter{tt.filter}, 0)
+		if clause != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.filter.Operator, tt.expected, clause)
+		}
+		if len(params) != tt.paramCount {
+			t.Errorf("%s: expected %d params, got %d", tt.filter.Operator, tt.paramCount, len(params))
+		}
+	}
+}
+
 func TestBuildWhereClause_IsNull(t *testing.T) {
 	d := &testDialect{}
 
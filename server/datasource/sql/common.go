@@ -94,6 +94,18 @@ func (ds *SQLCommonDataSource) IsConnected() bool {
 	return ds.connected
 }
 
+// Ping runs SELECT 1 to check that the backing server answers (domain.HealthChecker).
+func (ds *SQLCommonDataSource) Ping(ctx context.Context) error {
+	if !ds.IsConnected() {
+		return domain.NewErrNotConnected(ds.dialect.DriverName())
+	}
+	var one int
+	if err := ds.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return ds.classifyRead(fmt.Errorf("ping: %w", err))
+	}
+	return nil
+}
+
 // IsWritable returns whether the datasource allows writes.
 func (ds *SQLCommonDataSource) IsWritable() bool {
 	return ds.config.Writable
@@ -112,7 +124,7 @@ func (ds *SQLCommonDataSource) GetTables(ctx context.Context) ([]string, error)
 
 	rows, err := ds.db.QueryContext(ctx, ds.dialect.GetTablesQuery())
 	if err != nil {
-		return nil, fmt.Errorf("get tables: %w", err)
+		return nil, ds.classifyRead(fmt.Errorf("get tables: %w", err))
 	}
 	defer rows.Close()
 
@@ -124,7 +136,7 @@ func (ds *SQLCommonDataSource) GetTables(ctx context.Context) ([]string, error)
 		}
 		tables = append(tables, name)
 	}
-	return tables, rows.Err()
+	return tables, ds.classifyRead(rows.Err())
 }
 
 // GetTableInfo returns column metadata for a table.
@@ -135,7 +147,7 @@ func (ds *SQLCommonDataSource) GetTableInfo(ctx context.Context, tableName strin
 
 	rows, err := ds.db.QueryContext(ctx, ds.dialect.GetTableInfoQuery(), tableName)
 	if err != nil {
-		return nil, fmt.Errorf("get table info: %w", err)
+		return nil, ds.classifyRead(fmt.Errorf("get table info: %w", err))
 	}
 	defer rows.Close()
 
@@ -160,7 +172,7 @@ func (ds *SQLCommonDataSource) GetTableInfo(ctx context.Context, tableName strin
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, ds.classifyRead(err)
 	}
 
 	return &domain.TableInfo{
@@ -223,13 +235,13 @@ func (ds *SQLCommonDataSource) Query(ctx context.Context, tableName string, opti
 
 	rows, err := ds.db.QueryContext(ctx, querySQL, params...)
 	if err != nil {
-		return nil, fmt.Errorf("query: %w", err)
+		return nil, ds.classifyRead(fmt.Errorf("query: %w", err))
 	}
 	defer rows.Close()
 
 	data, columns, err := ScanRows(rows, ds.dialect)
 	if err != nil {
-		return nil, err
+		return nil, ds.classifyRead(err)
 	}
 
 	return &domain.QueryResult{
@@ -358,13 +370,13 @@ func (ds *SQLCommonDataSource) Execute(ctx context.Context, rawSQL string) (*dom
 	if isQuery {
 		rows, err := ds.db.QueryContext(ctx, rawSQL)
 		if err != nil {
-			return nil, fmt.Errorf("execute query: %w", err)
+			return nil, ds.classifyRead(fmt.Errorf("execute query: %w", err))
 		}
 		defer rows.Close()
 
 		data, columns, err := ScanRows(rows, ds.dialect)
 		if err != nil {
-			return nil, err
+			return nil, ds.classifyRead(err)
 		}
 
 		return &domain.QueryResult{
@@ -418,13 +430,13 @@ func (ds *SQLCommonDataSource) Filter(ctx context.Context, tableName string, fil
 
 	rows, err := ds.db.QueryContext(ctx, querySQL, params...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("filter: %w", err)
+		return nil, 0, ds.classifyRead(fmt.Errorf("filter: %w", err))
 	}
 	defer rows.Close()
 
 	data, _, err := ScanRows(rows, ds.dialect)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, ds.classifyRead(err)
 	}
 
 	return data, int64(len(data)), nil
@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// isConnectionLost reports whether err means the connection to the backing
+// database dropped before the statement completed.
+func isConnectionLost(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	// go-sql-driver/mysql reports a dropped connection as ErrInvalidConn
+	// ("invalid connection") and lib/pq as "driver: bad connection" text.
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "invalid connection") ||
+		strings.Contains(msg, "bad connection") ||
+		strings.Contains(msg, "broken pipe") ||
+		strings.Contains(msg, "connection reset")
+}
+
+// classifyRead reports an error from a read whose connection dropped as
+// domain.ErrRetryable. Writes are not classified: a write whose connection
+// dropped may still have been applied.
+func (ds *SQLCommonDataSource) classifyRead(err error) error {
+	if err != nil && isConnectionLost(err) {
+		return domain.NewErrRetryable(ds.dialect.DriverName(), err)
+	}
+	return err
+}
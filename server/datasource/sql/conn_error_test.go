@@ -0,0 +1,52 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+func TestIsConnectionLost(t *testing.T) {
+	lost := []error{
+		driver.ErrBadConn,
+		io.ErrUnexpectedEOF,
+		fmt.Errorf("query: %w", syscall.ECONNRESET),
+		&net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")},
+		errors.New("invalid connection"),
+	}
+	for _, err := range lost {
+		if !isConnectionLost(err) {
+			t.Errorf("isConnectionLost(%v) = false, want true", err)
+		}
+	}
+	kept := []error{
+		errors.New("Error 1146: Table 'shop.orders' doesn't exist"),
+		errors.New("Error 1064: You have an error in your SQL syntax"),
+	}
+	for _, err := range kept {
+		if isConnectionLost(err) {
+			t.Errorf("isConnectionLost(%v) = true, want false", err)
+		}
+	}
+}
+
+func TestClassifyRead(t *testing.T) {
+	ds := NewSQLCommonDataSource(&domain.DataSourceConfig{Name: "shop"}, &SQLConfig{}, &testDialect{})
+
+	err := ds.classifyRead(fmt.Errorf("query: %w", driver.ErrBadConn))
+	if !domain.IsRetryable(err) || !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("classifyRead(bad conn) = %v, want retryable", err)
+	}
+	if err := ds.classifyRead(errors.New("syntax error")); domain.IsRetryable(err) {
+		t.Errorf("classifyRead(syntax error) = %v, want not retryable", err)
+	}
+	if err := ds.classifyRead(nil); err != nil {
+		t.Errorf("classifyRead(nil) = %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// pushdownOperators maps parser comparison/arithmetic operators to SQL operators
+// that behave the same on MySQL and PostgreSQL.
+var pushdownOperators = map[string]string{
+	"eq": "=", "=": "=",
+	"ne": "<>", "!=": "<>", "<>": "<>",
+	"gt": ">", ">": ">",
+	"ge": ">=", ">=": ">=",
+	"lt": "<", "<": "<",
+	"le": "<=", "<=": "<=",
+	"plus": "+", "+": "+",
+	"minus": "-", "-": "-",
+	"mul": "*", "*": "*",
+	"mod": "%", "%": "%",
+	"and": "AND", "&&": "AND",
+	"or": "OR", "||": "OR",
+	"like": "LIKE", "not like": "NOT LIKE",
+	"in": "IN", "not in": "NOT IN",
+	"between": "BETWEEN", "not between": "NOT BETWEEN",
+}
+
+// pushdownFunctions lists the functions with the same semantics on MySQL and PostgreSQL.
+var pushdownFunctions = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	"UPPER": true, "LOWER": true, "ABS": true, "ROUND": true, "FLOOR": true,
+	"COALESCE": true, "NULLIF": true, "CONCAT": true,
+}
+
+// errNotPushable reports a part of the statement the generator cannot translate.
+type errNotPushable struct {
+	reason string
+}
+
+func (e *errNotPushable) Error() string {
+	return "cannot push down: " + e.reason
+}
+
+func notPushable(format string, args ...interface{}) error {
+	return &errNotPushable{reason: fmt.Sprintf(format, args...)}
+}
+
+// BuildPushdownSelectSQL translates a parsed SELECT into a single query for the
+// remote database. It covers single-table and same-datasource JOIN queries with
+// WHERE, GROUP BY, HAVING, ORDER BY, LIMIT/OFFSET and DISTINCT over columns,
+// constants, comparisons, arithmetic and a portable set of functions.
+// Statements outside that subset return an error and are executed locally.
+func BuildPushdownSelectSQL(d Dialect, stmt *parser.SelectStatement) (string, []interface{}, error) {
+	g := &pushdownGenerator{dialect: d}
+	query, err := g.selectSQL(stmt)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, g.params, nil
+}
+
+// pushdownGenerator accumulates bind parameters while rendering expressions.
+type pushdownGenerator struct {
+	dialect Dialect
+	params  []interface{}
+}
+
+func (g *pushdownGenerator) selectSQL(stmt *parser.SelectStatement) (string, error) {
+	switch {
+	case stmt.From == "" || strings.Contains(stmt.From, "."):
+		return "", notPushable("FROM %q", stmt.From)
+	case stmt.Lock != "", stmt.AsOf != "", stmt.Sample != nil:
+		return "", notPushable("locking, AS OF or TABLESAMPLE read")
+	case stmt.Offset != nil && stmt.Limit == nil:
+		return "", notPushable("OFFSET without LIMIT")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if stmt.Distinct {
+		sb.WriteString("DISTINCT ")
+	}
+	columns, err := g.selectList(stmt)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(columns)
+
+	sb.WriteString(" FROM ")
+	sb.WriteString(g.tableRef(stmt.From, stmt.FromAlias))
+	for _, join := range stmt.Joins {
+		clause, err := g.joinClause(join)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(clause)
+	}
+
+	if stmt.Where != nil {
+		where, err := g.expr(stmt.Where)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" WHERE ")
+		sb.WriteString(where)
+	}
+
+	if len(stmt.GroupBy) > 0 {
+		keys := make([]string, len(stmt.GroupBy))
+		for i, name := range stmt.GroupBy {
+			if expr, ok := stmt.GroupByExprs[name]; ok {
+				if keys[i], err = g.expr(expr); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if keys[i], err = g.columnRef(name); err != nil {
+				return "", err
+			}
+		}
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(strings.Join(keys, ", "))
+	}
+
+	if stmt.Having != nil {
+		having, err := g.expr(stmt.Having)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" HAVING ")
+		sb.WriteString(having)
+	}
+
+	if len(stmt.OrderBy) > 0 {
+		keys := make([]string, len(stmt.OrderBy))
+		for i, item := range stmt.OrderBy {
+			if item.Collation != "" {
+				return "", notPushable("ORDER BY ... COLLATE")
+			}
+			var key string
+			if item.Expr != nil {
+				key, err = g.expr(item.Expr)
+			} else {
+				key, err = g.columnRef(item.Column)
+			}
+			if err != nil {
+				return "", err
+			}
+			if strings.EqualFold(item.Direction, "DESC") {
+				key += " DESC"
+			} else {
+				key += " ASC"
+			}
+			keys[i] = key
+		}
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(keys, ", "))
+	}
+
+	if stmt.Limit != nil {
+		sb.WriteString(fmt.Sprintf(" LIMIT %d", *stmt.Limit))
+	}
+	if stmt.Offset != nil {
+		sb.WriteString(fmt.Sprintf(" OFFSET %d", *stmt.Offset))
+	}
+
+	return sb.String(), nil
+}
+
+// selectList renders the projection. Plain columns keep the bare column name the
+// local engine would return; other expressions use their alias or the remote name.
+func (g *pushdownGenerator) selectList(stmt *parser.SelectStatement) (string, error) {
+	items := make([]string, 0, len(stmt.Columns))
+	for _, col := range stmt.Columns {
+		if col.IsWildcard {
+			// Joined rows are keyed table.column locally, which the remote result cannot reproduce
+			if len(stmt.Joins) > 0 {
+				return "", notPushable("SELECT * with JOIN")
+			}
+			if col.Table != "" {
+				items = append(items, g.dialect.QuoteIdentifier(col.Table)+".*")
+			} else {
+				items = append(items, "*")
+			}
+			continue
+		}
+
+		expr := col.Expr
+		if expr == nil {
+			if col.Name == "" || strings.HasPrefix(col.Name, "@") {
+				return "", notPushable("select item %q", col.Name)
+			}
+			name := col.Name
+			if col.Table != "" {
+				name = col.Table + "." + name
+			}
+			expr = &parser.Expression{Type: parser.ExprTypeColumn, Column: name}
+		}
+		rendered, err := g.expr(expr)
+		if err != nil {
+			return "", err
+		}
+
+		alias := col.Alias
+		if alias == "" && expr.Type == parser.ExprTypeColumn {
+			alias = expr.Column[strings.LastIndex(expr.Column, ".")+1:]
+		}
+		if alias != "" {
+			rendered += " AS " + g.dialect.QuoteIdentifier(alias)
+		}
+		items = append(items, rendered)
+	}
+	if len(items) == 0 {
+		return "", notPushable("empty select list")
+	}
+	return strings.Join(items, ", "), nil
+}
+
+func (g *pushdownGenerator) tableRef(table, alias string) string {
+	ref := g.dialect.QuoteIdentifier(table)
+	if alias != "" && alias != table {
+		ref += " " + g.dialect.QuoteIdentifier(alias)
+	}
+	return ref
+}
+
+func (g *pushdownGenerator) joinClause(join parser.JoinInfo) (string, error) {
+	if join.Database != "" || strings.Contains(join.Table, ".") {
+		return "", notPushable("JOIN across databases")
+	}
+
+	var keyword string
+	switch join.Type {
+	case parser.JoinTypeInner, "":
+		keyword = " INNER JOIN "
+	case parser.JoinTypeLeft:
+		keyword = " LEFT JOIN "
+	case parser.JoinTypeRight:
+		keyword = " RIGHT JOIN "
+	case parser.JoinTypeCross:
+		keyword = " CROSS JOIN "
+	default:
+		// MySQL has no FULL JOIN
+		return "", notPushable("%s JOIN", join.Type)
+	}
+
+	clause := keyword + g.tableRef(join.Table, join.Alias)
+	if join.Condition == nil {
+		if join.Type == parser.JoinTypeLeft || join.Type == parser.JoinTypeRight {
+			return "", notPushable("%s JOIN without ON", join.Type)
+		}
+		return clause, nil
+	}
+	on, err := g.expr(join.Condition)
+	if err != nil {
+		return "", err
+	}
+	return clause + " ON " + on, nil
+}
+
+// columnRef quotes a possibly table-qualified column name.
+func (g *pushdownGenerator) columnRef(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 || name == "" {
+		return "", notPushable("column %q", name)
+	}
+	for i, part := range parts {
+		parts[i] = g.dialect.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// bind renders a constant. Numbers and booleans are written inline so the remote
+// server never has to infer the type of a bare parameter such as COUNT($1);
+// everything else becomes a bind parameter.
+func (g *pushdownGenerator) bind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	g.params = append(g.params, value)
+	return g.dialect.Placeholder(len(g.params))
+}
+
+func (g *pushdownGenerator) expr(expr *parser.Expression) (string, error) {
+	if expr == nil {
+		return "", notPushable("missing operand")
+	}
+	if expr.Collation != "" {
+		return "", notPushable("COLLATE")
+	}
+
+	switch expr.Type {
+	case parser.ExprTypeColumn:
+		return g.columnRef(expr.Column)
+	case parser.ExprTypeValue:
+		if _, ok := expr.Value.([]interface{}); ok {
+			return "", notPushable("list outside IN/BETWEEN")
+		}
+		return g.bind(expr.Value), nil
+	case parser.ExprTypeFunction:
+		return g.function(expr)
+	case parser.ExprTypeOperator:
+		return g.operator(expr)
+	}
+	return "", notPushable("%s expression", expr.Type)
+}
+
+func (g *pushdownGenerator) function(expr *parser.Expression) (string, error) {
+	name := strings.ToUpper(expr.Function)
+	if !pushdownFunctions[name] || len(expr.OrderBy) > 0 {
+		return "", notPushable("function %s", expr.Function)
+	}
+	args := make([]string, len(expr.Args))
+	for i := range expr.Args {
+		arg, err := g.expr(&expr.Args[i])
+		if err != nil {
+			return "", err
+		}
+		args[i] = arg
+	}
+	distinct := ""
+	if expr.Distinct {
+		distinct = "DISTINCT "
+	}
+	if name == "COUNT" && len(args) == 0 {
+		args = []string{"*"}
+	}
+	return name + "(" + distinct + strings.Join(args, ", ") + ")", nil
+}
+
+func (g *pushdownGenerator) operator(expr *parser.Expression) (string, error) {
+	op := strings.ToLower(expr.Operator)
+	switch op {
+	case "not", "!":
+		operand, err := g.expr(expr.Left)
+		if err != nil {
+			return "", err
+		}
+		return "(NOT " + operand + ")", nil
+	case "is null", "isnull", "is not null", "isnotnull":
+		operand, err := g.expr(expr.Left)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(op, "not") {
+			return "(" + operand + " IS NOT NULL)", nil
+		}
+		return "(" + operand + " IS NULL)", nil
+	case "div", "/":
+		// Integer division on PostgreSQL truncates; only MySQL matches the local result
+		if g.dialect.DriverName() != "mysql" {
+			return "", notPushable("division on %s", g.dialect.DriverName())
+		}
+		return g.binary(expr, "/")
+	}
+
+	sqlOp, ok := pushdownOperators[op]
+	if !ok {
+		return "", notPushable("operator %s", expr.Operator)
+	}
+	switch sqlOp {
+	case "IN", "NOT IN":
+		return g.inList(expr, sqlOp)
+	case "BETWEEN", "NOT BETWEEN":
+		return g.between(expr, sqlOp)
+	case "LIKE", "NOT LIKE":
+		if expr.Escape != "" {
+			return g.likeEscape(expr, sqlOp)
+		}
+	}
+	return g.binary(expr, sqlOp)
+}
+
+func (g *pushdownGenerator) binary(expr *parser.Expression, sqlOp string) (string, error) {
+	left, err := g.expr(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	right, err := g.expr(expr.Right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + left + " " + sqlOp + " " + right + ")", nil
+}
+
+func (g *pushdownGenerator) likeEscape(expr *parser.Expression, sqlOp string) (string, error) {
+	left, err := g.expr(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	pattern, err := g.expr(expr.Right)
+	if err != nil {
+		return "", err
+	}
+	return "(" + left + " " + sqlOp + " " + pattern + " ESCAPE " + g.bind(expr.Escape) + ")", nil
+}
+
+// listItems renders the constants or expressions of an IN list or BETWEEN bounds.
+func (g *pushdownGenerator) listItems(expr *parser.Expression) ([]string, error) {
+	if expr == nil || expr.Type != parser.ExprTypeValue {
+		return nil, notPushable("IN/BETWEEN operand")
+	}
+	values, ok := expr.Value.([]interface{})
+	if !ok {
+		return nil, notPushable("IN/BETWEEN operand")
+	}
+	items := make([]string, len(values))
+	for i, value := range values {
+		if item, ok := value.(*parser.Expression); ok {
+			rendered, err := g.expr(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = rendered
+			continue
+		}
+		items[i] = g.bind(value)
+	}
+	return items, nil
+}
+
+func (g *pushdownGenerator) inList(expr *parser.Expression, sqlOp string) (string, error) {
+	left, err := g.expr(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	items, err := g.listItems(expr.Right)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		// IN () is always false, NOT IN () always true (a materialized subquery may be empty)
+		if sqlOp == "IN" {
+			return "(1 = 0)", nil
+		}
+		return "(1 = 1)", nil
+	}
+	return "(" + left + " " + sqlOp + " (" + strings.Join(items, ", ") + "))", nil
+}
+
+func (g *pushdownGenerator) between(expr *parser.Expression, sqlOp string) (string, error) {
+	left, err := g.expr(expr.Left)
+	if err != nil {
+		return "", err
+	}
+	bounds, err := g.listItems(expr.Right)
+	if err != nil {
+		return "", err
+	}
+	if len(bounds) != 2 {
+		return "", notPushable("BETWEEN bounds")
+	}
+	return "(" + left + " " + sqlOp + " " + bounds[0] + " AND " + bounds[1] + ")", nil
+}
+
+// ── SelectPushdownDataSource ──
+
+// CanPushDownSelect reports whether stmt can run on the remote database as a whole.
+func (ds *SQLCommonDataSource) CanPushDownSelect(stmt *parser.SelectStatement) bool {
+	_, _, err := BuildPushdownSelectSQL(ds.dialect, stmt)
+	return err == nil
+}
+
+// PushDownSelect executes stmt on the remote database and returns the final result.
+func (ds *SQLCommonDataSource) PushDownSelect(ctx context.Context, stmt *parser.SelectStatement) (*domain.QueryResult, error) {
+	if !ds.IsConnected() {
+		return nil, domain.NewErrNotConnected(ds.dialect.DriverName())
+	}
+
+	querySQL, params, err := BuildPushdownSelectSQL(ds.dialect, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ds.db.QueryContext(ctx, querySQL, params...)
+	if err != nil {
+		return nil, ds.classifyRead(fmt.Errorf("query: %w", err))
+	}
+	defer rows.Close()
+
+	data, columns, err := ScanRows(rows, ds.dialect)
+	if err != nil {
+		return nil, ds.classifyRead(err)
+	}
+
+	return &domain.QueryResult{
+		Columns: columns,
+		Rows:    data,
+		Total:   int64(len(data)),
+		Ordered: len(stmt.OrderBy) > 0,
+	}, nil
+}
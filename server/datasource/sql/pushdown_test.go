@@ -0,0 +1,107 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+)
+
+func parseSelect(t *testing.T, query string) *parser.SelectStatement {
+	t.Helper()
+	result, err := parser.NewSQLAdapter().Parse(query)
+	if err != nil || !result.Success {
+		t.Fatalf("parse %q: %v %s", query, err, result.Error)
+	}
+	return result.Statement.Select
+}
+
+func TestBuildPushdownSelectSQL(t *testing.T) {
+	d := &testDialect{}
+
+	tests := []struct {
+		query    string
+		expected string
+		params   []interface{}
+	}{
+		{
+			query:    "SELECT id, name AS n FROM users WHERE age >= 18 AND NOT (status IN ('a', 'b') OR email IS NULL)",
+			expected: "SELECT `id` AS `id`, `name` AS `n` FROM `users` WHERE ((`age` >= 18) AND (NOT ((`status` IN (?, ?)) OR (`email` IS NULL))))",
+			params:   []interface{}{"a", "b"},
+		},
+		{
+			query:    "SELECT department, COUNT(*) AS c, SUM(salary) FROM emp GROUP BY department HAVING COUNT(*) > 2 ORDER BY c DESC LIMIT 5 OFFSET 10",
+			expected: "SELECT `department` AS `department`, COUNT(1) AS `c`, SUM(`salary`) FROM `emp` GROUP BY `department` HAVING (COUNT(1) > 2) ORDER BY `c` DESC LIMIT 5 OFFSET 10",
+		},
+		{
+			query:    "SELECT DISTINCT u.name, o.amount FROM users u LEFT JOIN orders o ON u.id = o.user_id WHERE o.amount BETWEEN '10' AND '2.5'",
+			expected: "SELECT DISTINCT `u`.`name` AS `name`, `o`.`amount` AS `amount` FROM `users` `u` LEFT JOIN `orders` `o` ON (`u`.`id` = `o`.`user_id`) WHERE (`o`.`amount` BETWEEN ? AND ?)",
+			params:   []interface{}{"10", "2.5"},
+		},
+		{
+			query:    "SELECT * FROM users WHERE UPPER(name) LIKE 'A!%%' ESCAPE '!'",
+			expected: "SELECT * FROM `users` WHERE (UPPER(`name`) LIKE ? ESCAPE ?)",
+			params:   []interface{}{"A!%%", "!"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, params, err := BuildPushdownSelectSQL(d, parseSelect(t, tt.query))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if query != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, query)
+			}
+			if len(params) != len(tt.params) {
+				t.Fatalf("expected params %v, got %v", tt.params, params)
+			}
+			for i := range params {
+				if params[i] != tt.params[i] {
+					t.Errorf("param %d: expected %v, got %v", i, tt.params[i], params[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPushdownSelectSQL_EmptyInList(t *testing.T) {
+	stmt := parseSelect(t, "SELECT id FROM users WHERE id IN (1)")
+	stmt.Where.Right.Value = []interface{}{}
+
+	query, _, err := BuildPushdownSelectSQL(&testDialect{}, stmt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT `id` AS `id` FROM `users` WHERE (1 = 0)"; query != expected {
+		t.Errorf("expected %q, got %q", expected, query)
+	}
+}
+
+func TestBuildPushdownSelectSQL_FullJoin(t *testing.T) {
+	stmt := parseSelect(t, "SELECT users.id FROM users JOIN orders ON users.id = orders.user_id")
+	stmt.Joins[0].Type = parser.JoinTypeFull
+
+	if _, _, err := BuildPushdownSelectSQL(&testDialect{}, stmt); err == nil {
+		t.Error("expected FULL JOIN not to be pushed down")
+	}
+}
+
+func TestBuildPushdownSelectSQL_NotPushable(t *testing.T) {
+	for _, query := range []string{
+		"SELECT * FROM users u JOIN orders o ON u.id = o.user_id",
+		"SELECT id FROM other.users",
+		"SELECT id FROM users WHERE name REGEXP 'a'",
+		"SELECT id FROM users WHERE name = 'a' COLLATE utf8mb4_general_ci",
+		"SELECT DATE_FORMAT(created_at, '%Y') FROM users",
+		"SELECT id / 2 FROM users",
+		"SELECT id FROM users FOR UPDATE",
+		"SELECT GROUP_CONCAT(name ORDER BY name) FROM users",
+	} {
+		t.Run(query, func(t *testing.T) {
+			if _, _, err := BuildPushdownSelectSQL(&testDialect{}, parseSelect(t, query)); err == nil {
+				t.Errorf("expected %q not to be pushed down", query)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net"
+	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
-	"github.com/kasuganosora/sqlexec/pkg/utils"
 	"github.com/kasuganosora/sqlexec/server/protocol"
 )
 
@@ -40,9 +42,36 @@ type HandshakeHandler interface {
 	Name() string
 }
 
+// TLSConn 监听地址配置了 TLS 证书的客户端连接，握手时客户端发送 SSLRequest 后升级为 TLS
+type TLSConn interface {
+	// StartTLS 进行 TLS 握手，之后连接上的读写都经过 TLS
+	StartTLS() error
+
+	// RequireTLS 是否拒绝未使用 TLS 的客户端
+	RequireTLS() bool
+}
+
+// FindConn 返回 conn 或其包装的底层连接（通过 NetConn 方法获取）中第一个实现 T 的连接
+func FindConn[T any](conn net.Conn) (T, bool) {
+	for conn != nil {
+		if found, ok := conn.(T); ok {
+			return found, true
+		}
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			break
+		}
+		conn = wrapper.NetConn()
+	}
+	var zero T
+	return zero, false
+}
+
 // AuditLogger 审计日志接口（避免直接依赖 security 包）
 type AuditLogger interface {
-	LogQuery(traceID, user, database, query string, duration int64, success bool)
+	LogLabeledQuery(traceID, user, database, query string, client, labels map[string]string, duration int64, success bool)
+	LogClientLogin(traceID, user, ip string, client map[string]string, success bool)
+	LogLockout(traceID, user, ip string, failures int, duration time.Duration)
 	LogError(traceID, user, database, message string, err error)
 }
 
@@ -54,7 +83,9 @@ type HandlerContext struct {
 	Logger       Logger
 	DB           DBAccessor
 	AuditLogger  AuditLogger
-	DebugEnabled bool // Debug logging switch (default true, configurable off)
+	Stats        StatsProvider // 服务器运行统计（COM_STATISTICS）
+	FlowControl  FlowControl   // 结果集写出的流控参数
+	DebugEnabled bool          // Debug logging switch (default true, configurable off)
 }
 
 // DBAccessor 数据库访问器接口（避免循环依赖）
@@ -62,6 +93,20 @@ type DBAccessor interface {
 	GetContext() context.Context
 }
 
+// ServerStats 服务器运行统计
+type ServerStats struct {
+	Uptime      time.Duration
+	Threads     int   // 当前连接数
+	Questions   int64 // 客户端发送的命令数
+	SlowQueries int64 // 执行时间超过慢查询阈值的命令数
+	OpenTables  int   // 已注册数据源中的表数
+}
+
+// StatsProvider 服务器运行统计提供者（避免依赖 server 包）
+type StatsProvider interface {
+	ServerStats() ServerStats
+}
+
 // NewHandlerContext 创建处理器上下文
 func NewHandlerContext(sess *pkg_session.Session, conn net.Conn, command uint8, logger Logger, auditLogger AuditLogger) *HandlerContext {
 	return &HandlerContext{
@@ -87,6 +132,7 @@ func (ctx *HandlerContext) SendOKWithSequenceID(seqID uint8) error {
 	okPacket.OkInPacket.LastInsertId = 0
 	okPacket.OkInPacket.StatusFlags = protocol.SERVER_STATUS_AUTOCOMMIT
 	okPacket.OkInPacket.Warnings = 0
+	ctx.attachSessionState(&okPacket.OkInPacket)
 
 	packetBytes, err := okPacket.Marshal()
 	if err != nil {
@@ -114,6 +160,47 @@ func (ctx *HandlerContext) SendOKWithRows(affectedRows uint64, lastInsertID uint
 	okPacket.OkInPacket.LastInsertId = lastInsertID
 	okPacket.OkInPacket.StatusFlags = protocol.SERVER_STATUS_AUTOCOMMIT
 	okPacket.OkInPacket.Warnings = 0
+	ctx.attachSessionState(&okPacket.OkInPacket)
+
+	packetBytes, err := okPacket.Marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.Connection.Write(packetBytes)
+	return err
+}
+
+// SendOKWithStatus 发送 OK 包（指定状态标志，如多结果集的 SERVER_MORE_RESULTS_EXISTS，以及语句产生的警告数）
+func (ctx *HandlerContext) SendOKWithStatus(statusFlags, warnings uint16) error {
+	okPacket := &protocol.OkPacket{}
+	okPacket.SequenceID = ctx.GetNextSequenceID()
+	okPacket.OkInPacket.Header = 0x00
+	okPacket.OkInPacket.StatusFlags = statusFlags
+	okPacket.OkInPacket.Warnings = warnings
+	ctx.attachSessionState(&okPacket.OkInPacket)
+
+	packetBytes, err := okPacket.Marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.Connection.Write(packetBytes)
+	return err
+}
+
+// SendOKWithInfo 发送 OK 包（影响行数、自增 ID、状态标志、警告数，以及 info 字段中的附加信息，
+// 如 INSERT ... ON DUPLICATE KEY UPDATE 插入与更新的行数）
+func (ctx *HandlerContext) SendOKWithInfo(affectedRows, lastInsertID uint64, statusFlags, warnings uint16, info string) error {
+	okPacket := &protocol.OkPacket{}
+	okPacket.SequenceID = ctx.GetNextSequenceID()
+	okPacket.OkInPacket.Header = 0x00
+	okPacket.OkInPacket.AffectedRows = affectedRows
+	okPacket.OkInPacket.LastInsertId = lastInsertID
+	okPacket.OkInPacket.StatusFlags = statusFlags
+	okPacket.OkInPacket.Warnings = warnings
+	okPacket.OkInPacket.Info = info
+	ctx.attachSessionState(&okPacket.OkInPacket)
 
 	packetBytes, err := okPacket.Marshal()
 	if err != nil {
@@ -130,7 +217,7 @@ func (ctx *HandlerContext) SendError(err error) error {
 		ctx.Logger.Printf("[ERROR] Sending error: %v", err)
 	}
 
-	errorCode, sqlState := utils.MapErrorCode(err)
+	errorCode, sqlState := mysqlerrors.Classify(err)
 
 	errPacket := &protocol.ErrorPacket{}
 	errPacket.SequenceID = ctx.GetNextSequenceID()
@@ -192,6 +279,24 @@ func (ctx *HandlerContext) DebugLog(format string, v ...interface{}) {
 	}
 }
 
+// ResetSessionState 重置连接的会话状态（COM_RESET_CONNECTION / COM_CHANGE_USER）
+// 清除会话变量与预处理语句，并回滚事务、删除临时表（通过 API Session 的 Reset）
+func (ctx *HandlerContext) ResetSessionState() error {
+	if ctx.Session == nil {
+		return nil
+	}
+	if err := ctx.Session.ResetState(); err != nil {
+		return err
+	}
+	if resetter, ok := ctx.Session.GetAPISession().(interface{ Reset() error }); ok {
+		return resetter.Reset()
+	}
+	return nil
+}
+
+// ErrCloseConnection 处理器已发送错误包且要求服务器关闭连接（如 COM_CHANGE_USER 认证失败）
+var ErrCloseConnection = errors.New("connection closed by handler")
+
 // HandlerError 自定义错误类型
 type HandlerError struct {
 	Message string
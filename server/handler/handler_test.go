@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 	"github.com/kasuganosora/sqlexec/pkg/session"
 	"github.com/kasuganosora/sqlexec/server/protocol"
 	"github.com/kasuganosora/sqlexec/server/testing/mock"
@@ -130,6 +131,36 @@ func TestHandlerContext_SendError(t *testing.T) {
 	}
 }
 
+func TestHandlerContext_SendError_MySQLErrorCode(t *testing.T) {
+	tests := []struct {
+		err   error
+		code  uint16
+		state string
+	}{
+		{errors.New("table 'users' not found"), 1146, "42S02"},
+		{fmt.Errorf("insert failed: %w", errors.New("Duplicate entry '1' for key 'PRIMARY'")), 1062, "23000"},
+		{errors.New("syntax error near 'FORM'"), 1064, "42000"},
+		{mysqlerrors.New(mysqlerrors.ErrLockWaitTimeout, "Lock wait timeout exceeded"), 1205, "HY000"},
+	}
+
+	for _, tt := range tests {
+		ctx, conn, _ := newTestContext()
+		ctx.Session.ResetSequenceID()
+		if err := ctx.SendError(tt.err); err != nil {
+			t.Fatalf("SendError error: %v", err)
+		}
+
+		// 包头 4 字节，随后为 0xFF、2 字节错误码、'#' 和 5 字节 SQLSTATE
+		data := conn.GetWrittenData()[0]
+		if code := uint16(data[5]) | uint16(data[6])<<8; code != tt.code {
+			t.Errorf("%v: error code = %d, want %d", tt.err, code, tt.code)
+		}
+		if state := string(data[8:13]); state != tt.state {
+			t.Errorf("%v: sql state = %q, want %q", tt.err, state, tt.state)
+		}
+	}
+}
+
 func TestHandlerContext_SendError_WriteError(t *testing.T) {
 	ctx, conn, _ := newTestContext()
 	ctx.Session.ResetSequenceID()
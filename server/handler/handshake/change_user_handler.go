@@ -0,0 +1,90 @@
+package handshake
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// ChangeUserHandler CHANGE_USER 命令处理器
+// 重置会话状态后以新用户重新认证，认证方式与初次握手一致
+type ChangeUserHandler struct {
+	admission    AdmissionFunc
+	authenticate AuthenticateFunc
+}
+
+// NewChangeUserHandler 创建 CHANGE_USER 处理器
+// admission 在切换用户后调用（如 max_user_connections 检查），可为 nil
+func NewChangeUserHandler(admission AdmissionFunc) *ChangeUserHandler {
+	return &ChangeUserHandler{
+		admission: admission,
+	}
+}
+
+// SetAuthenticator 设置密码校验函数，未设置时不校验密码
+// 认证响应按握手时的随机数计算，不支持切换认证插件
+func (h *ChangeUserHandler) SetAuthenticator(fn AuthenticateFunc) {
+	h.authenticate = fn
+}
+
+// Handle 处理 COM_CHANGE_USER 命令
+func (h *ChangeUserHandler) Handle(ctx *handler.HandlerContext, packet interface{}) error {
+	// 每个命令开始时重置序列号
+	ctx.ResetSequenceID()
+
+	cmd, ok := packet.(*protocol.ComChangeUserPacket)
+	if !ok {
+		return ctx.SendError(fmt.Errorf("invalid packet type for COM_CHANGE_USER"))
+	}
+
+	ctx.Log("处理 COM_CHANGE_USER: User=%s, Database=%s", cmd.User, cmd.Database)
+
+	if err := ctx.ResetSessionState(); err != nil {
+		return ctx.SendError(err)
+	}
+
+	// 切换用户
+	ctx.Session.SetUser(cmd.User)
+	apiSess, _ := ctx.Session.GetAPISession().(*api.Session)
+	if apiSess != nil {
+		apiSess.SetUser(cmd.User)
+	}
+
+	// 重新认证（认证或准入检查失败时关闭连接，与 MySQL 行为一致）
+	if h.authenticate != nil {
+		if err := h.authenticate(ctx.Session, ctx.Session.AuthScramble, []byte(cmd.AuthResponse)); err != nil {
+			ctx.SendError(err)
+			return fmt.Errorf("%w: %v", handler.ErrCloseConnection, err)
+		}
+	}
+	if h.admission != nil {
+		if err := h.admission(ctx.Session); err != nil {
+			ctx.SendError(err)
+			return fmt.Errorf("%w: %v", handler.ErrCloseConnection, err)
+		}
+	}
+
+	// 切换默认数据库（未指定时清除）
+	if cmd.Database != "" {
+		ctx.Session.Set("current_database", cmd.Database)
+	} else {
+		ctx.Session.Delete("current_database")
+	}
+	if apiSess != nil {
+		apiSess.SetCurrentDB(cmd.Database)
+	}
+
+	return ctx.SendOK()
+}
+
+// Command 返回命令类型
+func (h *ChangeUserHandler) Command() uint8 {
+	return protocol.COM_CHANGE_USER
+}
+
+// Name 返回处理器名称
+func (h *ChangeUserHandler) Name() string {
+	return "COM_CHANGE_USER"
+}
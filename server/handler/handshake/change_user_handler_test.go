@@ -0,0 +1,181 @@
+package handshake
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/kasuganosora/sqlexec/server/acl"
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/kasuganosora/sqlexec/server/testing/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChangeUserCtx(t *testing.T) (*handler.HandlerContext, *mock.MockConnection, *api.Session) {
+	sess := newTestSession()
+	db, err := api.NewDB(nil)
+	require.NoError(t, err)
+	ds := memory.NewMVCCDataSource(nil)
+	require.NoError(t, ds.Connect(context.Background()))
+	require.NoError(t, db.RegisterDataSource("default", ds))
+	apiSess := db.Session()
+	t.Cleanup(func() { apiSess.Close() })
+	apiSess.SetUser("old_user")
+	sess.SetUser("old_user")
+	sess.SetAPISession(apiSess)
+
+	conn := mock.NewMockConnection()
+	ctx := &handler.HandlerContext{
+		Session:    sess,
+		Connection: conn,
+		Logger:     mock.NewMockLogger(),
+	}
+	return ctx, conn, apiSess
+}
+
+func TestChangeUserHandler_Handle(t *testing.T) {
+	ctx, conn, apiSess := newChangeUserCtx(t)
+	ctx.Session.SetVariable("autocommit", "0")
+	ctx.Session.Set("current_database", "old_db")
+
+	var admitted string
+	h := NewChangeUserHandler(func(sess *pkg_session.Session) error {
+		admitted = sess.User
+		return nil
+	})
+
+	err := h.Handle(ctx, &protocol.ComChangeUserPacket{User: "new_user", Database: "new_db"})
+	require.NoError(t, err)
+
+	written := conn.GetWrittenData()
+	require.Len(t, written, 1)
+	assert.Equal(t, byte(0x01), written[0][3])
+	assert.Equal(t, byte(0x00), written[0][4])
+
+	assert.Equal(t, "new_user", admitted)
+	assert.Equal(t, "new_user", ctx.Session.User)
+	assert.Equal(t, "new_user", apiSess.GetUser())
+	assert.Equal(t, "new_db", apiSess.GetCurrentDB())
+
+	db, _ := ctx.Session.Get("current_database")
+	assert.Equal(t, "new_db", db)
+	val, _ := ctx.Session.GetVariable("autocommit")
+	assert.Nil(t, val)
+}
+
+func TestChangeUserHandler_NoDatabaseClearsCurrent(t *testing.T) {
+	ctx, _, apiSess := newChangeUserCtx(t)
+	ctx.Session.Set("current_database", "old_db")
+	apiSess.SetCurrentDB("old_db")
+
+	h := NewChangeUserHandler(nil)
+	require.NoError(t, h.Handle(ctx, &protocol.ComChangeUserPacket{User: "new_user"}))
+
+	db, _ := ctx.Session.Get("current_database")
+	assert.Nil(t, db)
+	assert.Equal(t, "", apiSess.GetCurrentDB())
+}
+
+func TestChangeUserHandler_AdmissionRejected(t *testing.T) {
+	ctx, conn, _ := newChangeUserCtx(t)
+	h := NewChangeUserHandler(func(sess *pkg_session.Session) error {
+		return fmt.Errorf("User %s already has more than 'max_user_connections' active connections", sess.User)
+	})
+
+	err := h.Handle(ctx, &protocol.ComChangeUserPacket{User: "busy"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, handler.ErrCloseConnection))
+
+	written := conn.GetWrittenData()
+	require.Len(t, written, 1)
+	assert.Equal(t, byte(0xff), written[0][4])
+	assert.Equal(t, uint16(1203), uint16(written[0][5])|uint16(written[0][6])<<8)
+}
+
+func TestChangeUserHandler_AuthenticationFailed(t *testing.T) {
+	ctx, conn, _ := newChangeUserCtx(t)
+	ctx.Session.AuthScramble = []byte("0123456789abcdefghij")
+	admitted := false
+	h := NewChangeUserHandler(func(sess *pkg_session.Session) error {
+		admitted = true
+		return nil
+	})
+	var gotScramble, gotAuth []byte
+	h.SetAuthenticator(func(sess *pkg_session.Session, scramble, authResponse []byte) error {
+		gotScramble, gotAuth = scramble, authResponse
+		return mysqlerrors.New(mysqlerrors.ErrAccessDenied, "Access denied for user '%s'", sess.User)
+	})
+
+	err := h.Handle(ctx, &protocol.ComChangeUserPacket{User: "intruder", AuthResponse: "\x01\x02"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, handler.ErrCloseConnection))
+	assert.False(t, admitted)
+	assert.Equal(t, []byte("0123456789abcdefghij"), gotScramble)
+	assert.Equal(t, []byte{1, 2}, gotAuth)
+
+	written := conn.GetWrittenData()
+	require.Len(t, written, 1)
+	assert.Equal(t, uint16(1045), uint16(written[0][5])|uint16(written[0][6])<<8)
+}
+
+func TestChangeUserHandler_WrongPassword(t *testing.T) {
+	am, err := acl.NewACLManager(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, am.CreateUser("%", "app", "S3cret!pw"))
+
+	scramble := []byte("0123456789abcdefghij")
+	response := func(password string) string {
+		b, _ := hex.DecodeString(utils.GeneratePasswordHash(password, scramble))
+		return string(b)
+	}
+	changeUser := func(password string) (*handler.HandlerContext, *mock.MockConnection, error) {
+		ctx, conn, _ := newChangeUserCtx(t)
+		ctx.Session.AuthScramble = scramble
+		h := NewChangeUserHandler(nil)
+		h.SetAuthenticator(func(sess *pkg_session.Session, scramble, authResponse []byte) error {
+			_, err := am.AuthenticateNative(sess.User, sess.RemoteIP, scramble, authResponse)
+			return err
+		})
+		return ctx, conn, h.Handle(ctx, &protocol.ComChangeUserPacket{User: "app", AuthResponse: response(password)})
+	}
+
+	// 密码错误时回复错误包并关闭连接
+	_, conn, err := changeUser("wrong")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, handler.ErrCloseConnection))
+	written := conn.GetWrittenData()
+	require.Len(t, written, 1)
+	assert.Equal(t, byte(0xff), written[0][4])
+
+	ctx, conn, err := changeUser("S3cret!pw")
+	require.NoError(t, err)
+	written = conn.GetWrittenData()
+	require.Len(t, written, 1)
+	assert.Equal(t, byte(0x00), written[0][4])
+	assert.Equal(t, "app", ctx.Session.User)
+}
+
+func TestChangeUserHandler_InvalidPacket(t *testing.T) {
+	ctx, conn, _ := newChangeUserCtx(t)
+	h := NewChangeUserHandler(nil)
+
+	require.NoError(t, h.Handle(ctx, "bad"))
+	written := conn.GetWrittenData()
+	require.Len(t, written, 1)
+	assert.Equal(t, byte(0xff), written[0][4])
+}
+
+func TestChangeUserHandler_CommandAndName(t *testing.T) {
+	h := NewChangeUserHandler(nil)
+	assert.Equal(t, uint8(protocol.COM_CHANGE_USER), h.Command())
+	assert.Equal(t, "COM_CHANGE_USER", h.Name())
+}
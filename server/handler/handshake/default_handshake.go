@@ -1,20 +1,47 @@
 package handshake
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
 	"net"
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
 	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
 	"github.com/kasuganosora/sqlexec/server/handler"
 	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/kasuganosora/sqlexec/server/response"
 )
 
+// connAttrSQLDialect 选择输入 SQL 方言的连接属性
+const connAttrSQLDialect = "sql_dialect"
+
+// connAttrQueryLabels 设置连接级查询标签的连接属性，格式为 key=value,...
+const connAttrQueryLabels = "query_labels"
+
+// nativePasswordPlugin 服务器使用的认证插件
+const nativePasswordPlugin = "mysql_native_password"
+
+// sslRequestLength SSLRequest 包的载荷长度：能力标志、最大包长、字符集和 23 字节填充
+const sslRequestLength = 32
+
+// AdmissionFunc 认证完成后、发送 OK 包之前的准入检查
+// 返回错误时向客户端发送错误包并终止握手（如 max_user_connections）
+type AdmissionFunc func(sess *pkg_session.Session) error
+
+// AuthenticateFunc 校验 sess.User 的 mysql_native_password 认证响应，scramble 为握手包中发送的随机数
+// 返回错误时向客户端发送错误包并终止握手（如密码错误、账号锁定）
+type AuthenticateFunc func(sess *pkg_session.Session, scramble, authResponse []byte) error
+
 // DefaultHandshakeHandler 默认握手处理器
 type DefaultHandshakeHandler struct {
-	db     *api.DB
-	logger handler.Logger
+	db           *api.DB
+	logger       handler.Logger
+	admission    AdmissionFunc
+	authenticate AuthenticateFunc
 }
 
 // NewDefaultHandshakeHandler 创建默认握手处理器
@@ -25,13 +52,29 @@ func NewDefaultHandshakeHandler(db *api.DB, logger handler.Logger) handler.Hands
 	}
 }
 
+// SetAdmission 设置准入检查函数
+func (h *DefaultHandshakeHandler) SetAdmission(fn AdmissionFunc) {
+	h.admission = fn
+}
+
+// SetAuthenticator 设置密码校验函数，未设置时不校验密码
+func (h *DefaultHandshakeHandler) SetAuthenticator(fn AuthenticateFunc) {
+	h.authenticate = fn
+}
+
 // Handle 处理握手流程
 func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Session) error {
 	// 发送握手包 (序列号为0)
+	// 监听地址配置了握手特征时按其声明版本号、能力标志、字符集和认证插件
+	personality := DefaultPersonality
+	if pc, ok := handler.FindConn[PersonalityConn](conn); ok {
+		personality = pc.Personality()
+	}
+
 	handshakePacket := &protocol.HandshakeV10Packet{}
 	handshakePacket.Packet.SequenceID = 0
 	handshakePacket.ProtocolVersion = 10
-	handshakePacket.ServerVersion = "8.0.33-sqlexec"
+	handshakePacket.ServerVersion = personality.ServerVersion
 	handshakePacket.ThreadID = sess.ThreadID
 	// Generate random 20-byte scramble for mysql_native_password
 	scramble := make([]byte, 20)
@@ -44,12 +87,17 @@ func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Sessio
 	}
 	handshakePacket.AuthPluginDataPart = scramble[:8]
 	handshakePacket.AuthPluginDataPart2 = scramble[8:]
-	handshakePacket.CapabilityFlags1 = 0xf7ff
-	handshakePacket.CharacterSet = 0xff // utf8mb4_0900_ai_ci (MySQL 8.0 default)
+	handshakePacket.CapabilityFlags1 = uint16(personality.Capabilities)
+	handshakePacket.CharacterSet = personality.CharacterSet
 	handshakePacket.StatusFlags = 0x0002
-	handshakePacket.CapabilityFlags2 = 0x00bf
-	handshakePacket.MariaDBCaps = 0x00000000
-	handshakePacket.AuthPluginName = "mysql_native_password"
+	handshakePacket.CapabilityFlags2 = uint16(personality.Capabilities >> 16)
+	handshakePacket.MariaDBCaps = personality.MariaDBCapabilities
+	handshakePacket.AuthPluginName = personality.AuthPlugin
+	// 监听地址配置了 TLS 证书时声明 CLIENT_SSL，客户端可以先发送 SSLRequest 再切换到 TLS
+	tlsConn, _ := handler.FindConn[handler.TLSConn](conn)
+	if tlsConn != nil {
+		handshakePacket.CapabilityFlags1 |= uint16(protocol.CLIENT_SSL)
+	}
 
 	handshakeData, err := handshakePacket.Marshal()
 	if err != nil {
@@ -68,7 +116,8 @@ func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Sessio
 
 	// 读取握手响应
 	handshakeResponse := &protocol.HandshakeResponse{}
-	if err := handshakeResponse.Unmarshal(conn, serverCapabilities); err != nil {
+	secure, err := readHandshakeResponse(conn, tlsConn, serverCapabilities, handshakeResponse)
+	if err != nil {
 		if h.logger != nil {
 			h.logger.Printf("解析认证包失败: %v", err)
 		}
@@ -82,6 +131,11 @@ func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Sessio
 
 	// 更新 session 信息
 	sess.SetUser(handshakeResponse.User)
+	sess.ClientCapabilities = ((uint32(handshakeResponse.ExtendedClientCapabilities) << 16) |
+		uint32(handshakeResponse.ClientCapabilities)) & serverCapabilities
+	sess.MariaDBCapabilities = handshakeResponse.MariaDBCaps & handshakePacket.MariaDBCaps
+	sess.ConnectionAttributes = connectionAttributes(handshakeResponse.ConnectionAttributes)
+	sess.AuthScramble = scramble
 
 	// 同时设置 API 层 Session 的用户
 	if h.db != nil {
@@ -91,6 +145,9 @@ func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Sessio
 				if h.logger != nil {
 					h.logger.Printf("已设置 API Session 用户: %s", handshakeResponse.User)
 				}
+				apiSess.SetConnectionAttributes(sess.ConnectionAttributes)
+				h.applyQueryLabels(apiSess, sess.ConnectionAttributes)
+				h.applyDialect(apiSess, handshakeResponse.ConnectionAttributes)
 			}
 		}
 	}
@@ -102,15 +159,54 @@ func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Sessio
 
 	// MySQL握手阶段序列号是连续的：
 	// - 握手包（服务器->客户端）：序列号0
-	// - 认证响应（客户端->服务器）：序列号1
-	// - OK包（服务器->客户端）：序列号2
+	// - 认证响应（客户端->服务器）：序列号1，先发送 SSLRequest（序列号1）切换到 TLS 时为2
+	// - OK包或错误包（服务器->客户端）：认证响应的序列号加1
+	// 切换认证插件时多一次往返：AuthSwitchRequest、客户端响应，OK包或错误包的序列号再加2
+	seq := handshakeResponse.SequenceID + 1
+	reject := func(rejectErr error) error {
+		errPacket := response.NewErrorBuilder().BuildFromError(seq, rejectErr)
+		if errData, marshalErr := errPacket.Marshal(); marshalErr == nil {
+			conn.Write(errData)
+		}
+		if h.logger != nil {
+			h.logger.Printf("拒绝连接: User=%s, %v", handshakeResponse.User, rejectErr)
+		}
+		return rejectErr
+	}
+
+	if tlsConn != nil && tlsConn.RequireTLS() && !secure {
+		return reject(mysqlerrors.New(mysqlerrors.ErrSecureTransport,
+			"Connections using insecure transport are prohibited while --require_secure_transport=ON."))
+	}
+
+	if h.authenticate != nil {
+		authData := authResponseBytes(handshakeResponse)
+		if plugin := handshakeResponse.ClientAuthPluginName; plugin != "" && plugin != nativePasswordPlugin {
+			// 客户端按其他插件（如 caching_sha2_password）计算了认证响应，要求其改用 mysql_native_password
+			if authData, err = switchAuthPlugin(conn, seq, scramble); err != nil {
+				return err
+			}
+			seq += 2
+		}
+		if authErr := h.authenticate(sess, scramble, authData); authErr != nil {
+			return reject(authErr)
+		}
+	}
+
+	// 准入检查失败时回复错误包（紧随认证响应）
+	if h.admission != nil {
+		if admitErr := h.admission(sess); admitErr != nil {
+			return reject(admitErr)
+		}
+	}
+
 	// 握手完成后，准备接收新命令，序列号重置为255（GetNextSequenceID后为0）
 	// 参考MariaDB: net_new_transaction重置序列号
 	sess.SequenceID = 255
 
 	// Build minimal OK packet: header(0x00) + affected_rows(0) + last_insert_id(0) + status(autocommit) + warnings(0)
 	okData := []byte{
-		0x07, 0x00, 0x00, 0x02, // 7-byte payload, seq=2
+		0x07, 0x00, 0x00, seq, // 7-byte payload
 		0x00,       // OK header
 		0x00,       // affected_rows = 0
 		0x00,       // last_insert_id = 0
@@ -133,3 +229,97 @@ func (h *DefaultHandshakeHandler) Handle(conn net.Conn, sess *pkg_session.Sessio
 func (h *DefaultHandshakeHandler) Name() string {
 	return "DefaultHandshakeHandler"
 }
+
+// authResponseBytes 返回握手响应中认证数据的原始字节
+// HandshakeResponse 按客户端能力以不同形式保存认证响应：CLIENT_SECURE_CONNECTION 格式为十六进制字符串，其他格式为原始字节
+func authResponseBytes(resp *protocol.HandshakeResponse) []byte {
+	clientCaps := (uint32(resp.ExtendedClientCapabilities) << 16) | uint32(resp.ClientCapabilities)
+	if clientCaps&protocol.CLIENT_PLUGIN_AUTH_LENENC_CLIENT_DATA == 0 && clientCaps&protocol.CLIENT_SECURE_CONNECTION != 0 {
+		data, err := hex.DecodeString(resp.AuthResponse)
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+	return []byte(resp.AuthResponse)
+}
+
+// readHandshakeResponse 读取客户端的认证响应。tlsConn 不为 nil 时客户端可以先发送 SSLRequest，
+// 此时连接升级为 TLS 后再读取认证响应，secure 为 true
+func readHandshakeResponse(conn net.Conn, tlsConn handler.TLSConn, capabilities uint32, resp *protocol.HandshakeResponse) (secure bool, err error) {
+	packet := &protocol.Packet{}
+	if err := packet.Unmarshal(conn); err != nil {
+		return false, err
+	}
+	if tlsConn != nil && len(packet.Payload) == sslRequestLength &&
+		binary.LittleEndian.Uint16(packet.Payload)&protocol.CLIENT_SSL != 0 {
+		if err := tlsConn.StartTLS(); err != nil {
+			return false, err
+		}
+		return true, resp.Unmarshal(conn, capabilities)
+	}
+	return false, resp.Unmarshal(bytes.NewReader(packet.RawBytes()), capabilities)
+}
+
+// switchAuthPlugin 发送 AuthSwitchRequest（序列号 seq）要求客户端改用 mysql_native_password，返回客户端的新认证响应
+func switchAuthPlugin(conn net.Conn, seq uint8, scramble []byte) ([]byte, error) {
+	payload := append([]byte{0xfe}, nativePasswordPlugin...)
+	payload = append(payload, 0)
+	payload = append(payload, scramble...)
+	payload = append(payload, 0)
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), seq}
+	if _, err := conn.Write(append(header, payload...)); err != nil {
+		return nil, err
+	}
+	packet := &protocol.Packet{}
+	if err := packet.Unmarshal(conn); err != nil {
+		return nil, err
+	}
+	return packet.Payload, nil
+}
+
+// connectionAttributes 把握手包中的连接属性转换为 map，没有属性时返回 nil，重复的属性以最后一个为准
+func connectionAttributes(attrs []protocol.ConnectionAttributeItem) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		result[attr.Name] = attr.Value
+	}
+	return result
+}
+
+// applyQueryLabels 按连接属性 query_labels 设置连接级查询标签，格式无效时忽略
+func (h *DefaultHandshakeHandler) applyQueryLabels(apiSess *api.Session, attrs map[string]string) {
+	value, ok := attrs[connAttrQueryLabels]
+	if !ok {
+		return
+	}
+	labels := pkg_session.ParseLabelList(value)
+	if labels == nil {
+		if h.logger != nil {
+			h.logger.Printf("忽略连接属性 %s: 无效的标签列表 %q", connAttrQueryLabels, value)
+		}
+		return
+	}
+	apiSess.SetQueryLabels(labels)
+}
+
+// applyDialect 按连接属性 sql_dialect 设置会话输入 SQL 的方言（如 postgres、sqlite），无效值被忽略
+func (h *DefaultHandshakeHandler) applyDialect(apiSess *api.Session, attrs []protocol.ConnectionAttributeItem) {
+	for _, attr := range attrs {
+		if attr.Name != connAttrSQLDialect {
+			continue
+		}
+		dialect, err := parser.ParseDialect(attr.Value)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.Printf("忽略连接属性 %s: %v", connAttrSQLDialect, err)
+			}
+			return
+		}
+		apiSess.SetDialect(dialect)
+		return
+	}
+}
@@ -2,11 +2,16 @@ package handshake
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net"
 	"testing"
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
 	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
 	"github.com/kasuganosora/sqlexec/server/protocol"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +33,7 @@ func newTestSession() *pkg_session.Session {
 	return sess
 }
 
-func buildHandshakeResponse(user, database string) []byte {
+func newHandshakeResponse(user, database string) *protocol.HandshakeResponse {
 	resp := &protocol.HandshakeResponse{}
 	resp.SequenceID = 1
 	resp.ClientCapabilities = 0xf7fe
@@ -41,8 +46,11 @@ func buildHandshakeResponse(user, database string) []byte {
 	resp.AuthResponse = "0102030405060708090a0b0c0d0e0f10" // hex-encoded dummy auth
 	resp.Database = database
 	resp.ClientAuthPluginName = "mysql_native_password"
+	return resp
+}
 
-	data, err := resp.Marshal()
+func buildHandshakeResponse(user, database string) []byte {
+	data, err := newHandshakeResponse(user, database).Marshal()
 	if err != nil {
 		panic(err)
 	}
@@ -162,6 +170,83 @@ func TestHandle_WithDB_APISession(t *testing.T) {
 	assert.Equal(t, "api_user", sess.User)
 }
 
+func TestHandle_SQLDialectAttribute(t *testing.T) {
+	db, err := api.NewDB(&api.DBConfig{CacheEnabled: false, DebugMode: false})
+	require.NoError(t, err)
+
+	h := NewDefaultHandshakeHandler(db, &testLogger{})
+	sess := newTestSession()
+	apiSess := db.Session()
+	defer apiSess.Close()
+	sess.SetAPISession(apiSess)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(serverConn, sess)
+	}()
+
+	buf := make([]byte, 4096)
+	clientConn.Read(buf)
+
+	resp := newHandshakeResponse("pg_user", "pgdb")
+	resp.ConnectionAttributes = []protocol.ConnectionAttributeItem{
+		{Name: "_client_name", Value: "psql-bridge"},
+		{Name: "sql_dialect", Value: "PostgreSQL"},
+	}
+	respData, err := resp.Marshal()
+	require.NoError(t, err)
+	clientConn.Write(respData)
+
+	clientConn.Read(buf)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, parser.DialectPostgres, apiSess.Dialect())
+
+	// 连接属性保存在协议层会话中，用于审计日志
+	assert.Equal(t, map[string]string{"_client_name": "psql-bridge", "sql_dialect": "PostgreSQL"}, sess.ConnectionAttributes)
+}
+
+func TestHandle_QueryLabelsAttribute(t *testing.T) {
+	db, err := api.NewDB(&api.DBConfig{CacheEnabled: false, DebugMode: false})
+	require.NoError(t, err)
+	require.NoError(t, db.RegisterDataSource("appdb", memory.NewMVCCDataSource(nil)))
+
+	h := NewDefaultHandshakeHandler(db, &testLogger{})
+	sess := newTestSession()
+	apiSess := db.Session()
+	defer apiSess.Close()
+	sess.SetAPISession(apiSess)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(serverConn, sess)
+	}()
+
+	buf := make([]byte, 4096)
+	clientConn.Read(buf)
+
+	resp := newHandshakeResponse("app_user", "appdb")
+	resp.ConnectionAttributes = []protocol.ConnectionAttributeItem{
+		{Name: "query_labels", Value: "app=checkout,team=payments"},
+	}
+	respData, err := resp.Marshal()
+	require.NoError(t, err)
+	clientConn.Write(respData)
+
+	clientConn.Read(buf)
+	require.NoError(t, <-done)
+
+	// 连接属性 query_labels 成为连接级标签，语句注释中的同名标签覆盖它
+	assert.Equal(t, map[string]string{"app": "checkout", "team": "payments"}, apiSess.QueryLabels("SELECT 1"))
+	assert.Equal(t, map[string]string{"app": "cart", "team": "payments"}, apiSess.QueryLabels("/* app=cart */ SELECT 1"))
+}
+
 func TestHandle_WriteError(t *testing.T) {
 	h := NewDefaultHandshakeHandler(nil, &testLogger{})
 	sess := newTestSession()
@@ -248,3 +333,211 @@ func TestHandle_OKWriteError(t *testing.T) {
 	// May succeed or fail depending on timing
 	_ = err
 }
+
+func TestHandle_AdmissionRejected(t *testing.T) {
+	h := NewDefaultHandshakeHandler(nil, &testLogger{}).(*DefaultHandshakeHandler)
+	h.SetAdmission(func(sess *pkg_session.Session) error {
+		return fmt.Errorf("User %s already has more than 'max_user_connections' active connections", sess.User)
+	})
+	sess := newTestSession()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(serverConn, sess)
+	}()
+
+	buf := make([]byte, 4096)
+	_, err := clientConn.Read(buf)
+	require.NoError(t, err)
+
+	_, err = clientConn.Write(buildHandshakeResponse("limited", ""))
+	require.NoError(t, err)
+
+	// 错误包：序列号 2，错误码 1203
+	n, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	require.Greater(t, n, 7)
+	assert.Equal(t, byte(0x02), buf[3])
+	assert.Equal(t, byte(0xff), buf[4])
+	assert.Equal(t, uint16(1203), uint16(buf[5])|uint16(buf[6])<<8)
+
+	err = <-done
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_user_connections")
+}
+
+func TestHandle_Authenticate(t *testing.T) {
+	h := NewDefaultHandshakeHandler(nil, &testLogger{}).(*DefaultHandshakeHandler)
+	var gotScramble, gotAuth []byte
+	h.SetAuthenticator(func(sess *pkg_session.Session, scramble, authResponse []byte) error {
+		gotScramble, gotAuth = scramble, authResponse
+		return mysqlerrors.New(mysqlerrors.ErrAccessDenied, "Access denied for user '%s'@'%s' (using password: YES)", sess.User, sess.RemoteIP)
+	})
+	sess := newTestSession()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(serverConn, sess)
+	}()
+
+	buf := make([]byte, 4096)
+	_, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	_, err = clientConn.Write(buildHandshakeResponse("intruder", ""))
+	require.NoError(t, err)
+
+	// 错误包：序列号 2，错误码 1045，SQLSTATE 28000
+	n, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	require.Greater(t, n, 13)
+	assert.Equal(t, byte(0x02), buf[3])
+	assert.Equal(t, byte(0xff), buf[4])
+	assert.Equal(t, uint16(1045), uint16(buf[5])|uint16(buf[6])<<8)
+	assert.Equal(t, "#28000", string(buf[7:13]))
+	require.Error(t, <-done)
+
+	// 认证函数收到认证响应的原始字节和握手时的随机数
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, gotAuth)
+	assert.Len(t, gotScramble, 20)
+	assert.Equal(t, gotScramble, sess.AuthScramble)
+}
+
+func TestHandle_AuthSwitch(t *testing.T) {
+	h := NewDefaultHandshakeHandler(nil, &testLogger{}).(*DefaultHandshakeHandler)
+	var gotScramble, gotAuth []byte
+	h.SetAuthenticator(func(sess *pkg_session.Session, scramble, authResponse []byte) error {
+		gotScramble, gotAuth = scramble, authResponse
+		return nil
+	})
+	sess := newTestSession()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(serverConn, sess)
+	}()
+
+	buf := make([]byte, 4096)
+	_, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	// 带上数据库名，插件名才能按 CLIENT_CONNECT_WITH_DB 的顺序解析
+	resp := newHandshakeResponse("app", "shop")
+	resp.ClientAuthPluginName = "caching_sha2_password"
+	data, err := resp.Marshal()
+	require.NoError(t, err)
+	_, err = clientConn.Write(data)
+	require.NoError(t, err)
+
+	// AuthSwitchRequest：序列号 2，0xfe + 插件名 + 随机数
+	switchPacket := &protocol.Packet{}
+	require.NoError(t, switchPacket.Unmarshal(clientConn))
+	assert.Equal(t, uint8(2), switchPacket.SequenceID)
+	require.Equal(t, byte(0xfe), switchPacket.Payload[0])
+	assert.Equal(t, "mysql_native_password\x00", string(switchPacket.Payload[1:23]))
+	scramble := switchPacket.Payload[23 : 23+20]
+
+	// 客户端按 mysql_native_password 重新计算的认证响应：序列号 3
+	authData := []byte("abcdefghijklmnopqrst")
+	_, err = clientConn.Write(append([]byte{20, 0, 0, 3}, authData...))
+	require.NoError(t, err)
+
+	// OK 包：序列号 4
+	n, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	require.Greater(t, n, 4)
+	assert.Equal(t, byte(0x04), buf[3])
+	assert.Equal(t, byte(0x00), buf[4])
+	require.NoError(t, <-done)
+
+	assert.Equal(t, authData, gotAuth)
+	assert.Equal(t, scramble, gotScramble)
+}
+
+// personalityConn 带握手特征的连接，模拟监听地址接受的连接
+type personalityConn struct {
+	net.Conn
+	personality Personality
+}
+
+func (c *personalityConn) Personality() Personality {
+	return c.personality
+}
+
+func TestNewPersonality(t *testing.T) {
+	p, err := NewPersonality(config.ProtocolConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPersonality, p)
+
+	p, err = NewPersonality(config.ProtocolConfig{Personality: "mysql-5.7"})
+	require.NoError(t, err)
+	assert.Equal(t, "5.7.44-sqlexec", p.ServerVersion)
+	assert.NotZero(t, p.Capabilities&protocol.CLIENT_LONG_PASSWORD)
+	assert.Zero(t, p.MariaDBCapabilities)
+
+	p, err = NewPersonality(config.ProtocolConfig{
+		Personality:    "mariadb-10.6",
+		ServerVersion:  "5.5.5-10.6.0-MariaDB",
+		CapabilityMask: ^uint32(protocol.CLIENT_SESSION_TRACK),
+		Charset:        "latin1_swedish_ci",
+		AuthPlugin:     "caching_sha2_password",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "5.5.5-10.6.0-MariaDB", p.ServerVersion)
+	assert.Zero(t, p.Capabilities&protocol.CLIENT_SESSION_TRACK)
+	assert.NotZero(t, p.Capabilities&protocol.CLIENT_PROTOCOL_41)
+	assert.Equal(t, uint32(protocol.MARIADB_CLIENT_PROGRESS), p.MariaDBCapabilities)
+	assert.Equal(t, uint8(protocol.CHARSET_LATIN1_SWEDISH_CI), p.CharacterSet)
+	assert.Equal(t, "caching_sha2_password", p.AuthPlugin)
+
+	_, err = NewPersonality(config.ProtocolConfig{Personality: "oracle"})
+	assert.Error(t, err)
+	_, err = NewPersonality(config.ProtocolConfig{Charset: "klingon_ci"})
+	assert.Error(t, err)
+	_, err = NewPersonality(config.ProtocolConfig{AuthPlugin: "sha256_password"})
+	assert.Error(t, err)
+}
+
+func TestHandle_Personality(t *testing.T) {
+	h := NewDefaultHandshakeHandler(nil, &testLogger{})
+	sess := newTestSession()
+	personality, err := NewPersonality(config.ProtocolConfig{Personality: "mysql-5.7", CapabilityMask: ^uint32(protocol.CLIENT_DEPRECATE_EOF)})
+	require.NoError(t, err)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(&personalityConn{Conn: serverConn, personality: personality}, sess)
+	}()
+
+	handshake := &protocol.HandshakeV10Packet{}
+	require.NoError(t, handshake.Unmarshal(clientConn))
+	assert.Equal(t, "5.7.44-sqlexec", handshake.ServerVersion)
+	assert.Equal(t, uint16(0xf7ff), handshake.CapabilityFlags1)
+	assert.Equal(t, uint8(protocol.CHARSET_UTF8MB4_GENERAL_CI), handshake.CharacterSet)
+	assert.Zero(t, handshake.MariaDBCaps)
+	assert.Equal(t, "mysql_native_password", handshake.AuthPluginName)
+
+	// 客户端声明的能力只保留服务器声明过的
+	resp := newHandshakeResponse("app", "")
+	resp.ExtendedClientCapabilities |= uint16(protocol.CLIENT_DEPRECATE_EOF >> 16)
+	data, err := resp.Marshal()
+	require.NoError(t, err)
+	_, err = clientConn.Write(data)
+	require.NoError(t, err)
+
+	buf := make([]byte, 64)
+	_, err = clientConn.Read(buf)
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+	assert.Zero(t, sess.ClientCapabilities&protocol.CLIENT_DEPRECATE_EOF)
+}
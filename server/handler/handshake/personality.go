@@ -0,0 +1,98 @@
+package handshake
+
+import (
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// cachingSHA2Plugin MySQL 8.0 默认的认证插件。服务器只校验 mysql_native_password，
+// 声明该插件时按 caching_sha2_password 计算的认证响应会通过 AuthSwitchRequest 改用 mysql_native_password
+const cachingSHA2Plugin = "caching_sha2_password"
+
+// Personality 握手包中向客户端声明的服务器特征
+type Personality struct {
+	ServerVersion       string // 版本字符串
+	Capabilities        uint32 // 能力标志
+	MariaDBCapabilities uint32 // MariaDB 扩展能力，只在未声明 CLIENT_LONG_PASSWORD 时发送
+	CharacterSet        uint8  // 默认字符集排序规则编号
+	AuthPlugin          string // 认证插件
+}
+
+// DefaultPersonality 未配置握手特征时使用：MySQL 8.0 的版本号，同时声明 MariaDB 扩展能力
+var DefaultPersonality = Personality{
+	ServerVersion: "8.0.33-sqlexec",
+	// 不声明 CLIENT_LONG_PASSWORD（MariaDB 中即 CLIENT_MYSQL），MariaDB 客户端才会回送扩展能力
+	Capabilities:        0x00bff7fe,
+	MariaDBCapabilities: protocol.MARIADB_CLIENT_PROGRESS,
+	CharacterSet:        protocol.CHARSET_UTF8MB4_0900_AI_CI,
+	AuthPlugin:          nativePasswordPlugin,
+}
+
+// personalities 预设的握手特征，MySQL 预设声明 CLIENT_LONG_PASSWORD，客户端不会按 MariaDB 协议解析扩展能力
+var personalities = map[string]Personality{
+	"mysql-5.7": {
+		ServerVersion: "5.7.44-sqlexec",
+		Capabilities:  0x00bff7ff,
+		CharacterSet:  protocol.CHARSET_UTF8MB4_GENERAL_CI,
+		AuthPlugin:    nativePasswordPlugin,
+	},
+	"mysql-8.0": {
+		ServerVersion: "8.0.33-sqlexec",
+		Capabilities:  0x00bff7ff,
+		CharacterSet:  protocol.CHARSET_UTF8MB4_0900_AI_CI,
+		AuthPlugin:    cachingSHA2Plugin,
+	},
+	"mariadb-10.6": {
+		// MariaDB 在版本号前加 5.5.5- 兼容只认识 5.x 版本号的旧客户端
+		ServerVersion:       "5.5.5-10.6.16-MariaDB-sqlexec",
+		Capabilities:        0x00bff7fe,
+		MariaDBCapabilities: protocol.MARIADB_CLIENT_PROGRESS,
+		CharacterSet:        protocol.CHARSET_UTF8MB4_GENERAL_CI,
+		AuthPlugin:          nativePasswordPlugin,
+	},
+}
+
+// PersonalityConn 监听地址配置了握手特征的客户端连接
+type PersonalityConn interface {
+	Personality() Personality
+}
+
+// NewPersonality 按配置生成握手特征：先取预设（未设置时为 DefaultPersonality），再应用覆盖项
+func NewPersonality(cfg config.ProtocolConfig) (Personality, error) {
+	p := DefaultPersonality
+	if cfg.Personality != "" {
+		preset, ok := personalities[cfg.Personality]
+		if !ok {
+			return Personality{}, fmt.Errorf("unknown protocol personality: %s", cfg.Personality)
+		}
+		p = preset
+	}
+	if cfg.ServerVersion != "" {
+		p.ServerVersion = cfg.ServerVersion
+	}
+	if cfg.CapabilityMask != 0 {
+		p.Capabilities &= cfg.CapabilityMask
+	}
+	if p.Capabilities&protocol.CLIENT_LONG_PASSWORD != 0 {
+		p.MariaDBCapabilities = 0
+	}
+	if cfg.Charset != "" {
+		// GetCharsetID 对未知的名称返回 utf8mb4_0900_ai_ci
+		id := utils.GetCharsetID(cfg.Charset)
+		if id == protocol.CHARSET_UTF8MB4_0900_AI_CI && cfg.Charset != "utf8mb4_0900_ai_ci" {
+			return Personality{}, fmt.Errorf("unknown collation: %s", cfg.Charset)
+		}
+		p.CharacterSet = id
+	}
+	switch cfg.AuthPlugin {
+	case "":
+	case nativePasswordPlugin, cachingSHA2Plugin:
+		p.AuthPlugin = cfg.AuthPlugin
+	default:
+		return Personality{}, fmt.Errorf("unsupported auth plugin: %s", cfg.AuthPlugin)
+	}
+	return p, nil
+}
@@ -0,0 +1,34 @@
+package packet_parsers
+
+import (
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// ChangeUserPacketParser CHANGE_USER 命令包解析器
+type ChangeUserPacketParser struct{}
+
+// NewChangeUserPacketParser 创建 CHANGE_USER 命令包解析器
+func NewChangeUserPacketParser() handler.PacketParser {
+	return &ChangeUserPacketParser{}
+}
+
+// Command 返回命令类型
+func (p *ChangeUserPacketParser) Command() uint8 {
+	return protocol.COM_CHANGE_USER
+}
+
+// Name 返回解析器名称
+func (p *ChangeUserPacketParser) Name() string {
+	return "COM_CHANGE_USER"
+}
+
+// Parse 解析命令包
+func (p *ChangeUserPacketParser) Parse(packet *protocol.Packet) (interface{}, error) {
+	cmd := &protocol.ComChangeUserPacket{}
+	cmd.Packet = *packet
+	if err := cmd.DecodePayload(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
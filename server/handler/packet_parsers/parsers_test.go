@@ -159,3 +159,66 @@ func TestProcessKillParser_Parse(t *testing.T) {
 		t.Errorf("Parse result type = %T, want *ComProcessKillPacket", result)
 	}
 }
+
+func TestChangeUserParser_CommandAndName(t *testing.T) {
+	p := NewChangeUserPacketParser()
+	if p.Command() != protocol.COM_CHANGE_USER {
+		t.Errorf("Command = 0x%02x, want 0x%02x", p.Command(), protocol.COM_CHANGE_USER)
+	}
+	if p.Name() != "COM_CHANGE_USER" {
+		t.Errorf("Name = %q, want %q", p.Name(), "COM_CHANGE_USER")
+	}
+}
+
+func TestChangeUserParser_Parse(t *testing.T) {
+	src := &protocol.ComChangeUserPacket{
+		Command:        protocol.COM_CHANGE_USER,
+		User:           "bob",
+		AuthResponse:   "secret",
+		Database:       "shop",
+		CharacterSet:   45,
+		AuthPluginName: "mysql_native_password",
+	}
+	data, err := src.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	p := NewChangeUserPacketParser()
+	result, err := p.Parse(&protocol.Packet{Payload: data[4:]})
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cmd, ok := result.(*protocol.ComChangeUserPacket)
+	if !ok {
+		t.Fatalf("Parse result type = %T, want *ComChangeUserPacket", result)
+	}
+	if cmd.User != "bob" || cmd.AuthResponse != "secret" || cmd.Database != "shop" {
+		t.Errorf("Parse result = %+v", cmd)
+	}
+	if cmd.CharacterSet != 45 || cmd.AuthPluginName != "mysql_native_password" {
+		t.Errorf("CharacterSet = %d, AuthPluginName = %q", cmd.CharacterSet, cmd.AuthPluginName)
+	}
+}
+
+func TestResetConnectionParser_CommandAndName(t *testing.T) {
+	p := NewResetConnectionPacketParser()
+	if p.Command() != protocol.COM_RESET_CONNECTION {
+		t.Errorf("Command = 0x%02x, want 0x%02x", p.Command(), protocol.COM_RESET_CONNECTION)
+	}
+	if p.Name() != "COM_RESET_CONNECTION" {
+		t.Errorf("Name = %q, want %q", p.Name(), "COM_RESET_CONNECTION")
+	}
+}
+
+func TestResetConnectionParser_Parse(t *testing.T) {
+	p := NewResetConnectionPacketParser()
+	pkt := &protocol.Packet{Payload: []byte{protocol.COM_RESET_CONNECTION}}
+	result, err := p.Parse(pkt)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, ok := result.(*protocol.ComResetConnectionPacket); !ok {
+		t.Errorf("Parse result type = %T, want *ComResetConnectionPacket", result)
+	}
+}
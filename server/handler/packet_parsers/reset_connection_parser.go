@@ -0,0 +1,31 @@
+package packet_parsers
+
+import (
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// ResetConnectionPacketParser RESET_CONNECTION 命令包解析器
+type ResetConnectionPacketParser struct{}
+
+// NewResetConnectionPacketParser 创建 RESET_CONNECTION 命令包解析器
+func NewResetConnectionPacketParser() handler.PacketParser {
+	return &ResetConnectionPacketParser{}
+}
+
+// Command 返回命令类型
+func (p *ResetConnectionPacketParser) Command() uint8 {
+	return protocol.COM_RESET_CONNECTION
+}
+
+// Name 返回解析器名称
+func (p *ResetConnectionPacketParser) Name() string {
+	return "COM_RESET_CONNECTION"
+}
+
+// Parse 解析命令包
+func (p *ResetConnectionPacketParser) Parse(packet *protocol.Packet) (interface{}, error) {
+	cmd := &protocol.ComResetConnectionPacket{}
+	cmd.Packet = *packet
+	return cmd, nil
+}
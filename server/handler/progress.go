@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// DefaultProgressReportInterval 两次进度报告之间的默认最短间隔（与 MariaDB progress_report_time 默认值一致）
+const DefaultProgressReportInterval = 5 * time.Second
+
+// SendProgress 发送进度报告包，占用一个序列号
+func (ctx *HandlerContext) SendProgress(p domain.Progress) error {
+	packet := protocol.NewProgressReportPacket(ctx.GetNextSequenceID(),
+		clampStage(p.Stage), clampStage(p.MaxStage), p.Percent(), p.Info)
+	packetBytes, err := packet.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = ctx.Connection.Write(packetBytes)
+	return err
+}
+
+// ProgressReporter 返回把进度转发给客户端的回调，距语句开始或上次报告不足 interval 的进度被丢弃；
+// 客户端未协商 MARIADB_CLIENT_PROGRESS 时返回 nil
+func (ctx *HandlerContext) ProgressReporter(interval time.Duration) domain.ProgressFunc {
+	if ctx.Session == nil || ctx.Session.MariaDBCapabilities&protocol.MARIADB_CLIENT_PROGRESS == 0 {
+		return nil
+	}
+	last := time.Now()
+	return func(p domain.Progress) {
+		if now := time.Now(); now.Sub(last) >= interval {
+			last = now
+			if err := ctx.SendProgress(p); err != nil {
+				ctx.Log("发送进度报告失败: %v", err)
+			}
+		}
+	}
+}
+
+// clampStage 阶段号在协议中只占 1 字节
+func clampStage(stage int) uint8 {
+	if stage < 1 {
+		return 1
+	}
+	if stage > 255 {
+		return 255
+	}
+	return uint8(stage)
+}
@@ -2,6 +2,11 @@ package query
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/utils"
 	"github.com/kasuganosora/sqlexec/server/handler"
 	"github.com/kasuganosora/sqlexec/server/protocol"
 	"github.com/kasuganosora/sqlexec/server/response"
@@ -23,6 +28,7 @@ func NewFieldListHandler(eofBuilder *response.EOFBuilder) *FieldListHandler {
 }
 
 // Handle 处理 COM_FIELD_LIST 命令
+// 返回表中与通配符匹配的列定义，以 EOF 结束
 func (h *FieldListHandler) Handle(ctx *handler.HandlerContext, packet interface{}) error {
 	// 每个命令开始时重置序列号
 	ctx.ResetSequenceID()
@@ -34,9 +40,31 @@ func (h *FieldListHandler) Handle(ctx *handler.HandlerContext, packet interface{
 
 	ctx.Log("处理 COM_FIELD_LIST: table=%s, wildcard=%s", cmd.Table, cmd.Wildcard)
 
-	// 发送 EOF 包（简化实现，不返回实际字段列表）
-	seqID := ctx.GetNextSequenceID()
-	eofPacket := h.eofBuilder.Build(seqID, 0, protocol.SERVER_STATUS_AUTOCOMMIT)
+	apiSess, ok := ctx.Session.GetAPISession().(*api.Session)
+	if !ok || apiSess == nil {
+		return ctx.SendError(fmt.Errorf("database not initialized"))
+	}
+
+	info, err := apiSess.TableInfo(cmd.Table)
+	if err != nil {
+		return ctx.SendError(err)
+	}
+
+	schema := apiSess.GetCurrentDB()
+	for _, col := range info.Columns {
+		if cmd.Wildcard != "" && !utils.MatchesLikeEscape(strings.ToLower(col.Name), strings.ToLower(cmd.Wildcard), utils.DefaultLikeEscape) {
+			continue
+		}
+		data, err := buildFieldListPacket(ctx.GetNextSequenceID(), schema, info.Name, col).Marshal(0)
+		if err != nil {
+			return err
+		}
+		if _, err := ctx.Connection.Write(data); err != nil {
+			return err
+		}
+	}
+
+	eofPacket := h.eofBuilder.Build(ctx.GetNextSequenceID(), 0, protocol.SERVER_STATUS_AUTOCOMMIT)
 	data, err := eofPacket.Marshal()
 	if err != nil {
 		return err
@@ -46,6 +74,18 @@ func (h *FieldListHandler) Handle(ctx *handler.HandlerContext, packet interface{
 	return err
 }
 
+// buildFieldListPacket 构建 COM_FIELD_LIST 的列定义包，末尾带列默认值
+func buildFieldListPacket(sequenceID uint8, schema, table string, col domain.ColumnInfo) *protocol.FieldMetaPacket {
+	packet := &protocol.FieldMetaPacket{FieldMeta: protocol.ColumnFieldMeta(col)}
+	packet.SequenceID = sequenceID
+	packet.Schema = schema
+	packet.Table = table
+	packet.OrgTable = table
+	defaultValue := col.Default
+	packet.DefaultValue = &defaultValue
+	return packet
+}
+
 // Command 返回命令类型
 func (h *FieldListHandler) Command() uint8 {
 	return protocol.COM_FIELD_LIST
@@ -0,0 +1,133 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+func newFieldListRunner(t *testing.T) func(table, wildcard string) [][]byte {
+	t.Helper()
+	db, err := api.NewDB(nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	ds := memory.NewMVCCDataSource(nil)
+	if err := ds.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	if err := db.RegisterDataSource("default", ds); err != nil {
+		t.Fatalf("RegisterDataSource error: %v", err)
+	}
+	err = ds.CreateTable(context.Background(), &domain.TableInfo{
+		Name: "users",
+		Columns: []domain.ColumnInfo{
+			{Name: "id", Type: "INT", Primary: true, AutoIncrement: true},
+			{Name: "name", Type: "VARCHAR(64)", Nullable: true},
+			{Name: "nickname", Type: "VARCHAR(64)", Nullable: true, Default: "anon"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable error: %v", err)
+	}
+	apiSess := db.Session()
+	t.Cleanup(func() { apiSess.Close() })
+
+	h := NewFieldListHandler(nil)
+	run := func(table, wildcard string) [][]byte {
+		ctx, conn, _ := newTestCtx()
+		ctx.Session.SetAPISession(apiSess)
+		cmd := &protocol.ComFieldListPacket{Table: table, Wildcard: wildcard}
+		if err := h.Handle(ctx, cmd); err != nil {
+			t.Fatalf("Handle error: %v", err)
+		}
+		return conn.GetWrittenData()
+	}
+	return run
+}
+
+func decodeFieldPacket(t *testing.T, data []byte) *protocol.FieldMetaPacket {
+	t.Helper()
+	p := &protocol.FieldMetaPacket{}
+	if err := p.Unmarshal(bytes.NewReader(data), 0); err != nil {
+		t.Fatalf("Unmarshal field packet error: %v", err)
+	}
+	return p
+}
+
+func TestFieldListHandler_Columns(t *testing.T) {
+	run := newFieldListRunner(t)
+	written := run("users", "")
+
+	if len(written) != 4 {
+		t.Fatalf("expected 3 column packets and EOF, got %d packets", len(written))
+	}
+	if written[3][4] != 0xFE {
+		t.Errorf("expected EOF header 0xFE, got 0x%02x", written[3][4])
+	}
+
+	id := decodeFieldPacket(t, written[0])
+	if id.Name != "id" || id.Table != "users" || id.OrgTable != "users" {
+		t.Errorf("unexpected id column: name=%q table=%q org_table=%q", id.Name, id.Table, id.OrgTable)
+	}
+	if id.Type != protocol.MYSQL_TYPE_LONG {
+		t.Errorf("id type = 0x%02x, want MYSQL_TYPE_LONG", id.Type)
+	}
+	wantFlags := uint16(protocol.NOT_NULL_FLAG | protocol.PRI_KEY_FLAG | protocol.AUTO_INCREMENT_FLAG |
+		protocol.BINARY_COLLATION_FLAG | protocol.NUM_FLAG)
+	if id.Flags != wantFlags {
+		t.Errorf("id flags = 0x%04x, want 0x%04x", id.Flags, wantFlags)
+	}
+	if id.SequenceID != 1 {
+		t.Errorf("first column seqID = %d, want 1", id.SequenceID)
+	}
+
+	nickname := decodeFieldPacket(t, written[2])
+	if nickname.Type != protocol.MYSQL_TYPE_VAR_STRING {
+		t.Errorf("nickname type = 0x%02x, want MYSQL_TYPE_VAR_STRING", nickname.Type)
+	}
+	if nickname.DefaultValue == nil || *nickname.DefaultValue != "anon" {
+		t.Errorf("nickname default = %v, want 'anon'", nickname.DefaultValue)
+	}
+}
+
+func TestFieldListHandler_Wildcard(t *testing.T) {
+	run := newFieldListRunner(t)
+
+	written := run("users", "N%")
+	if len(written) != 3 {
+		t.Fatalf("expected 2 column packets and EOF, got %d packets", len(written))
+	}
+	if name := decodeFieldPacket(t, written[0]).Name; name != "name" {
+		t.Errorf("first column = %q, want 'name'", name)
+	}
+	if name := decodeFieldPacket(t, written[1]).Name; name != "nickname" {
+		t.Errorf("second column = %q, want 'nickname'", name)
+	}
+
+	written = run("users", "missing%")
+	if len(written) != 1 || written[0][4] != 0xFE {
+		t.Errorf("expected only EOF for a wildcard without matches, got %d packets", len(written))
+	}
+}
+
+func TestFieldListHandler_UnknownTable(t *testing.T) {
+	run := newFieldListRunner(t)
+	written := run("missing", "")
+
+	if len(written) != 1 {
+		t.Fatalf("expected a single error packet, got %d packets", len(written))
+	}
+	if written[0][4] != 0xFF {
+		t.Fatalf("expected error header 0xFF, got 0x%02x", written[0][4])
+	}
+	// 1146 ER_NO_SUCH_TABLE
+	if code := uint16(written[0][5]) | uint16(written[0][6])<<8; code != 1146 {
+		t.Errorf("error code = %d, want 1146", code)
+	}
+}
@@ -60,15 +60,14 @@ func (h *InitDBHandler) Handle(ctx *handler.HandlerContext, packet interface{})
 		return ctx.SendOK()
 	}
 
-	// 设置数据库名
-	ctx.Session.Set("current_database", dbName)
-
-	// 获取 API Session 并更新当前数据库
+	// 获取 API Session 并切换当前数据库（与 USE 语句语义一致）
 	apiSessIntf := ctx.Session.GetAPISession()
 	if apiSessIntf != nil {
 		if apiSess, ok := apiSessIntf.(*api.Session); ok {
-			ctx.Log("更新 API Session 当前数据库: %s", dbName)
-			apiSess.SetCurrentDB(dbName)
+			ctx.Log("切换 API Session 当前数据库: %s", dbName)
+			if err := apiSess.UseDatabase(dbName); err != nil {
+				return ctx.SendError(err)
+			}
 		} else {
 			ctx.Log("API Session 类型断言失败")
 		}
@@ -76,6 +75,9 @@ func (h *InitDBHandler) Handle(ctx *handler.HandlerContext, packet interface{})
 		ctx.Log("API Session 未初始化，无法更新当前数据库")
 	}
 
+	// 设置数据库名
+	ctx.Session.Set("current_database", dbName)
+
 	return ctx.SendOK()
 }
 
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/kasuganosora/sqlexec/server/testing/mock"
+)
+
+func newProgressCtx(t *testing.T, mariadbCaps uint32) (*handler.HandlerContext, *mock.MockConnection) {
+	t.Helper()
+	db, err := api.NewDB(nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	ds := memory.NewMVCCDataSource(nil)
+	ctx := context.Background()
+	if err := ds.Connect(ctx); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	if err := ds.CreateTable(ctx, &domain.TableInfo{
+		Name:    "items",
+		Columns: []domain.ColumnInfo{{Name: "id", Type: "INT", Primary: true}, {Name: "v", Type: "VARCHAR"}},
+	}); err != nil {
+		t.Fatalf("CreateTable error: %v", err)
+	}
+	rows := make([]domain.Row, 3000)
+	for i := range rows {
+		rows[i] = domain.Row{"id": int64(i), "v": "x"}
+	}
+	if _, err := ds.Insert(ctx, "items", rows, nil); err != nil {
+		t.Fatalf("Insert error: %v", err)
+	}
+	if err := db.RegisterDataSource("default", ds); err != nil {
+		t.Fatalf("RegisterDataSource error: %v", err)
+	}
+	apiSess := db.Session()
+	t.Cleanup(func() { apiSess.Close() })
+
+	hctx, conn, _ := newTestCtx()
+	hctx.Session.SetAPISession(apiSess)
+	hctx.Session.ClientCapabilities = protocol.CLIENT_PROTOCOL_41
+	hctx.Session.MariaDBCapabilities = mariadbCaps
+	return hctx, conn
+}
+
+// progressPackets 返回已写出的进度报告包（ERR 包头 + 错误码 0xFFFF）
+func progressPackets(t *testing.T, conn *mock.MockConnection) []*protocol.ProgressReportPacket {
+	t.Helper()
+	var packets []*protocol.ProgressReportPacket
+	for _, data := range conn.GetWrittenData() {
+		if len(data) < 7 || data[4] != 0xFF || data[5] != 0xFF || data[6] != 0xFF {
+			continue
+		}
+		packet := &protocol.ProgressReportPacket{}
+		if err := packet.Unmarshal(bytes.NewReader(data)); err != nil {
+			t.Fatalf("Unmarshal progress packet error: %v", err)
+		}
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+func TestProgress_CreateIndex(t *testing.T) {
+	ctx, conn := newProgressCtx(t, protocol.MARIADB_CLIENT_PROGRESS)
+	h := NewQueryHandler()
+	h.progressInterval = 0
+
+	cmd := &protocol.ComQueryPacket{}
+	cmd.Payload = append([]byte{protocol.COM_QUERY}, "CREATE INDEX idx_v ON items (v)"...)
+	if err := h.Handle(ctx, cmd); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+
+	packets := progressPackets(t, conn)
+	if len(packets) == 0 {
+		t.Fatal("expected progress report packets")
+	}
+	for i, p := range packets {
+		if p.SequenceID != uint8(i+1) || p.Stage != 1 || p.MaxStage != 1 {
+			t.Errorf("packet %d = %+v", i, p)
+		}
+	}
+	if last := packets[len(packets)-1]; last.Progress != 100000 || last.Info == "" {
+		t.Errorf("final progress = %d (%q), want 100000", last.Progress, last.Info)
+	}
+
+	// 进度报告之后仍以 OK 包结束，序列号紧随其后
+	ok := lastOKPacket(t, conn)
+	if ok.SequenceID != uint8(len(packets)+1) {
+		t.Errorf("OK sequence id = %d, want %d", ok.SequenceID, len(packets)+1)
+	}
+}
+
+func TestProgress_ClientWithoutCapability(t *testing.T) {
+	ctx, conn := newProgressCtx(t, 0)
+	h := NewQueryHandler()
+	h.progressInterval = 0
+
+	cmd := &protocol.ComQueryPacket{}
+	cmd.Payload = append([]byte{protocol.COM_QUERY}, "OPTIMIZE TABLE items"...)
+	if err := h.Handle(ctx, cmd); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	if packets := progressPackets(t, conn); len(packets) != 0 {
+		t.Errorf("progress must not be sent without MARIADB_CLIENT_PROGRESS, got %d packets", len(packets))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/server/handler"
 	"github.com/kasuganosora/sqlexec/server/protocol"
@@ -16,12 +17,14 @@ import (
 // QueryHandler QUERY 命令处理器
 type QueryHandler struct {
 	resultSetBuilder *response.ResultSetBuilder
+	progressInterval time.Duration // 两次进度报告之间的最短间隔
 }
 
 // NewQueryHandler 创建 QUERY 处理器
 func NewQueryHandler() *QueryHandler {
 	return &QueryHandler{
 		resultSetBuilder: response.NewResultSetBuilder(),
+		progressInterval: handler.DefaultProgressReportInterval,
 	}
 }
 
@@ -43,8 +46,6 @@ func (h *QueryHandler) Handle(ctx *handler.HandlerContext, packet interface{}) e
 
 	ctx.Log("处理 COM_QUERY: %s", query)
 
-	queryStart := time.Now()
-
 	// 使用 API Session 执行查询
 	apiSessIntf := ctx.Session.GetAPISession()
 	if apiSessIntf == nil {
@@ -60,50 +61,113 @@ func (h *QueryHandler) Handle(ctx *handler.HandlerContext, packet interface{}) e
 		return ctx.SendError(err)
 	}
 
-	// 执行查询
-	queryObj, err := apiSess.Query(query)
-	if err != nil {
-		ctx.Log("查询失败: %v", err)
-		if ctx.AuditLogger != nil {
-			traceID := ctx.Session.GetTraceID()
-			ctx.AuditLogger.LogQuery(traceID, ctx.Session.User, "", query, time.Since(queryStart).Milliseconds(), false)
+	// 客户端协商了 MARIADB_CLIENT_PROGRESS 时，建索引等长时间操作在执行期间发送进度报告包
+	if report := ctx.ProgressReporter(h.progressInterval); report != nil {
+		apiSess.SetProgressHandler(report)
+		defer apiSess.SetProgressHandler(nil)
+	}
+
+	// 客户端启用多语句时逐条执行，除最后一个结果外都带 SERVER_MORE_RESULTS_EXISTS；
+	// 某条语句失败时发送错误包并停止执行后续语句
+	statements := []string{query}
+	if ctx.Session.ClientCapabilities&protocol.CLIENT_MULTI_STATEMENTS != 0 {
+		if parts := parser.SplitStatements(query); len(parts) > 1 {
+			statements = parts
 		}
-		return ctx.SendError(err)
 	}
-	defer queryObj.Close()
 
-	// 获取列信息
+	for i, stmt := range statements {
+		status := uint16(protocol.SERVER_STATUS_AUTOCOMMIT)
+		if i < len(statements)-1 {
+			status |= protocol.SERVER_MORE_RESULTS_EXISTS
+		}
+
+		queryStart := time.Now()
+		queryObj, err := apiSess.Query(stmt)
+		if err != nil {
+			ctx.Log("查询失败 (QueryID=%s): %v", apiSess.LastQueryID(), err)
+			h.audit(ctx, apiSess, stmt, queryStart, false)
+			return ctx.SendError(err)
+		}
+		err = h.sendStatementResult(ctx, apiSess, queryObj, status)
+		queryObj.Close()
+		h.audit(ctx, apiSess, stmt, queryStart, err == nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// audit 记录一条语句的审计日志，带上连接属性和语句的查询标签
+func (h *QueryHandler) audit(ctx *handler.HandlerContext, apiSess *api.Session, query string, start time.Time, success bool) {
+	if ctx.AuditLogger != nil {
+		traceID := ctx.Session.GetTraceID()
+		ctx.AuditLogger.LogLabeledQuery(traceID, ctx.Session.User, "", query, ctx.Session.ConnectionAttributes,
+			apiSess.QueryLabels(query), time.Since(start).Milliseconds(), success)
+	}
+}
+
+// sendStatementResult 发送一条语句的执行结果
+// 结果集逐行从迭代器取出、编码并写入带流控的写入器，客户端读取缓慢时暂停取行
+func (h *QueryHandler) sendStatementResult(ctx *handler.HandlerContext, apiSess *api.Session, queryObj *api.Query, status uint16) error {
 	columns := queryObj.Columns()
 	if len(columns) == 0 {
+		if result := queryObj.ExecResult(); result != nil {
+			// INSERT/UPDATE/DELETE 等语句，OK 包带影响行数、自增 ID 与附加信息
+			return ctx.SendOKWithInfo(uint64(result.RowsAffected), uint64(result.LastInsertID), status,
+				warningCount(apiSess.WarningCount()), result.Info)
+		}
 		// 空结果集，返回 OK
 		ctx.Log("查询返回空列，发送 OK")
-		return ctx.SendOK()
+		return ctx.SendOKWithStatus(status, warningCount(apiSess.WarningCount()))
 	}
 
-	// 收集行数据
-	var rows []domain.Row
 	rowCount := 0
-	for queryObj.Next() {
+	next := func() (domain.Row, bool) {
+		if !queryObj.Next() {
+			return nil, false
+		}
 		row := queryObj.Row()
 		ctx.Log("  Query 返回的行 %d: %+v", rowCount, row)
-		rows = append(rows, row)
 		rowCount++
+		return row, true
 	}
+	err := h.streamResultSet(ctx, columns, next, status, warningCount(apiSess.WarningCount()))
+	ctx.Log("总共发送 %d 行数据", rowCount)
+	return err
+}
 
-	ctx.Log("总共收集到 %d 行数据", rowCount)
-
-	// 审计记录
-	if ctx.AuditLogger != nil {
-		traceID := ctx.Session.GetTraceID()
-		ctx.AuditLogger.LogQuery(traceID, ctx.Session.User, "", query, time.Since(queryStart).Milliseconds(), true)
+// warningCount 返回写入 EOF 包的警告数（协议字段为 2 字节）
+func warningCount(n int) uint16 {
+	if n > 0xffff {
+		return 0xffff
 	}
-
-	// 发送结果集
-	return h.sendQueryResult(ctx, columns, rows)
+	return uint16(n)
 }
 
 // sendQueryResult 发送查询结果
 func (h *QueryHandler) sendQueryResult(ctx *handler.HandlerContext, columns []domain.ColumnInfo, rows []domain.Row) error {
+	return h.sendResultSet(ctx, columns, rows, protocol.SERVER_STATUS_AUTOCOMMIT, 0)
+}
+
+// sendResultSet 发送结果集，statusFlags 写入两个 EOF 包，warnings 写入最后的 EOF 包
+func (h *QueryHandler) sendResultSet(ctx *handler.HandlerContext, columns []domain.ColumnInfo, rows []domain.Row, statusFlags, warnings uint16) error {
+	i := 0
+	next := func() (domain.Row, bool) {
+		if i >= len(rows) {
+			return nil, false
+		}
+		i++
+		return rows[i-1], true
+	}
+	return h.streamResultSet(ctx, columns, next, statusFlags, warnings)
+}
+
+// streamResultSet 发送结果集，行由 next 逐行提供
+func (h *QueryHandler) streamResultSet(ctx *handler.HandlerContext, columns []domain.ColumnInfo, next func() (domain.Row, bool), statusFlags, warnings uint16) error {
+	w := ctx.ResultWriter()
+
 	// 获取序列号
 	seqID := ctx.GetNextSequenceID()
 
@@ -112,7 +176,7 @@ func (h *QueryHandler) sendQueryResult(ctx *handler.HandlerContext, columns []do
 	if err != nil {
 		return err
 	}
-	if _, err := ctx.Connection.Write(columnCountPacket); err != nil {
+	if _, err := w.Write(columnCountPacket); err != nil {
 		return err
 	}
 
@@ -124,58 +188,49 @@ func (h *QueryHandler) sendQueryResult(ctx *handler.HandlerContext, columns []do
 		if err != nil {
 			return err
 		}
-		if _, err := ctx.Connection.Write(data); err != nil {
+		if _, err := w.Write(data); err != nil {
 			return err
 		}
 	}
 
 	// 发送 EOF 包
 	eofBuilder := response.NewEOFBuilder()
-	eofPacket := eofBuilder.Build(ctx.GetNextSequenceID(), 0, protocol.SERVER_STATUS_AUTOCOMMIT)
+	eofPacket := eofBuilder.Build(ctx.GetNextSequenceID(), 0, statusFlags)
 	eofData, err := eofPacket.Marshal()
 	if err != nil {
 		return err
 	}
-	if _, err := ctx.Connection.Write(eofData); err != nil {
+	if _, err := w.Write(eofData); err != nil {
 		return err
 	}
 
 	// 发送行数据
-	for _, row := range rows {
+	for row, ok := next(); ok; row, ok = next() {
 		rowPacket := h.buildRowPacket(ctx.GetNextSequenceID(), columns, row)
 		if rowPacket == nil {
 			return fmt.Errorf("failed to marshal row data packet")
 		}
-		if _, err := ctx.Connection.Write(rowPacket); err != nil {
+		if _, err := w.Write(rowPacket); err != nil {
 			return err
 		}
 	}
 
 	// 发送最后的 EOF 包
-	eofPacket2 := eofBuilder.Build(ctx.GetNextSequenceID(), 0, protocol.SERVER_STATUS_AUTOCOMMIT)
+	eofPacket2 := eofBuilder.Build(ctx.GetNextSequenceID(), warnings, statusFlags)
 	eofData2, err := eofPacket2.Marshal()
 	if err != nil {
 		return err
 	}
-	_, err = ctx.Connection.Write(eofData2)
-	return err
+	if _, err := w.Write(eofData2); err != nil {
+		return err
+	}
+	return w.Flush()
 }
 
 // buildFieldPacket 构建列定义包
 func (h *QueryHandler) buildFieldPacket(sequenceID uint8, col domain.ColumnInfo) *protocol.FieldMetaPacket {
-	packet := &protocol.FieldMetaPacket{}
+	packet := &protocol.FieldMetaPacket{FieldMeta: protocol.ColumnFieldMeta(col)}
 	packet.SequenceID = sequenceID
-	packet.Catalog = "def"
-	packet.Schema = ""
-	packet.Table = ""
-	packet.OrgTable = ""
-	packet.Name = col.Name
-	packet.OrgName = col.Name
-	packet.CharacterSet = 0xff // utf8mb4_0900_ai_ci (MySQL 8.0 default)
-	packet.ColumnLength = 255
-	packet.Type = h.mapMySQLType(col.Type)
-	packet.Flags = 0
-	packet.Decimals = 0
 	return packet
 }
 
@@ -188,6 +243,9 @@ func (h *QueryHandler) buildRowPacket(sequenceID uint8, columns []domain.ColumnI
 	values := make([]string, len(columns))
 	for i, col := range columns {
 		if val, exists := row[col.Name]; exists {
+			if col.IsUnsigned() {
+				val = protocol.UnsignedValue(val, protocol.MySQLType(col.Type))
+			}
 			values[i] = h.formatValue(val)
 		} else {
 			values[i] = "___SQL_EXEC_NULL___" // NULL 值标记
@@ -236,40 +294,6 @@ func (h *QueryHandler) formatValue(value interface{}) string {
 	}
 }
 
-// mapMySQLType 映射数据类型
-func (h *QueryHandler) mapMySQLType(typeStr string) byte {
-	switch {
-	case typeStr == "int", typeStr == "integer":
-		return protocol.MYSQL_TYPE_LONG
-	case typeStr == "tinyint":
-		return protocol.MYSQL_TYPE_TINY
-	case typeStr == "smallint":
-		return protocol.MYSQL_TYPE_SHORT
-	case typeStr == "bigint":
-		return protocol.MYSQL_TYPE_LONGLONG
-	case typeStr == "float":
-		return protocol.MYSQL_TYPE_FLOAT
-	case typeStr == "double":
-		return protocol.MYSQL_TYPE_DOUBLE
-	case typeStr == "decimal", typeStr == "numeric":
-		return protocol.MYSQL_TYPE_DECIMAL
-	case typeStr == "date":
-		return protocol.MYSQL_TYPE_DATE
-	case typeStr == "datetime":
-		return protocol.MYSQL_TYPE_DATETIME
-	case typeStr == "timestamp":
-		return protocol.MYSQL_TYPE_TIMESTAMP
-	case typeStr == "time":
-		return protocol.MYSQL_TYPE_TIME
-	case typeStr == "text", typeStr == "string":
-		return protocol.MYSQL_TYPE_VAR_STRING
-	case typeStr == "boolean", typeStr == "bool":
-		return protocol.MYSQL_TYPE_TINY
-	default:
-		return protocol.MYSQL_TYPE_VAR_STRING
-	}
-}
-
 // Command 返回命令类型
 func (h *QueryHandler) Command() uint8 {
 	return protocol.COM_QUERY
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
@@ -142,44 +143,6 @@ func TestFormatValue_Default(t *testing.T) {
 	}
 }
 
-// === mapMySQLType Tests ===
-
-func TestMapMySQLType(t *testing.T) {
-	h := NewQueryHandler()
-
-	tests := []struct {
-		input    string
-		expected byte
-	}{
-		{"int", protocol.MYSQL_TYPE_LONG},
-		{"integer", protocol.MYSQL_TYPE_LONG},
-		{"tinyint", protocol.MYSQL_TYPE_TINY},
-		{"smallint", protocol.MYSQL_TYPE_SHORT},
-		{"bigint", protocol.MYSQL_TYPE_LONGLONG},
-		{"float", protocol.MYSQL_TYPE_FLOAT},
-		{"double", protocol.MYSQL_TYPE_DOUBLE},
-		{"decimal", protocol.MYSQL_TYPE_DECIMAL},
-		{"numeric", protocol.MYSQL_TYPE_DECIMAL},
-		{"date", protocol.MYSQL_TYPE_DATE},
-		{"datetime", protocol.MYSQL_TYPE_DATETIME},
-		{"timestamp", protocol.MYSQL_TYPE_TIMESTAMP},
-		{"time", protocol.MYSQL_TYPE_TIME},
-		{"text", protocol.MYSQL_TYPE_VAR_STRING},
-		{"string", protocol.MYSQL_TYPE_VAR_STRING},
-		{"boolean", protocol.MYSQL_TYPE_TINY},
-		{"bool", protocol.MYSQL_TYPE_TINY},
-		{"unknown_type", protocol.MYSQL_TYPE_VAR_STRING},
-		{"", protocol.MYSQL_TYPE_VAR_STRING},
-	}
-
-	for _, tt := range tests {
-		result := h.mapMySQLType(tt.input)
-		if result != tt.expected {
-			t.Errorf("mapMySQLType(%q) = 0x%02x, want 0x%02x", tt.input, result, tt.expected)
-		}
-	}
-}
-
 // === Handle error path tests ===
 
 func TestQueryHandler_Handle_InvalidPacket(t *testing.T) {
@@ -400,8 +363,20 @@ func TestBuildFieldPacket(t *testing.T) {
 	if pkt.Type != protocol.MYSQL_TYPE_LONG {
 		t.Errorf("Type = 0x%02x, want MYSQL_TYPE_LONG", pkt.Type)
 	}
-	if pkt.CharacterSet != 0xff {
-		t.Errorf("CharacterSet = 0x%02x, want 0xff", pkt.CharacterSet)
+	if pkt.CharacterSet != 63 {
+		t.Errorf("CharacterSet = %d, want 63 (binary) for numeric column", pkt.CharacterSet)
+	}
+}
+
+func TestBuildFieldPacket_Flags(t *testing.T) {
+	h := NewQueryHandler()
+	col := domain.ColumnInfo{Name: "id", Type: "int", Primary: true, AutoIncrement: true, Unsigned: true}
+	pkt := h.buildFieldPacket(1, col)
+
+	want := uint16(protocol.NOT_NULL_FLAG | protocol.PRI_KEY_FLAG | protocol.AUTO_INCREMENT_FLAG | protocol.UNSIGNED_FLAG |
+		protocol.BINARY_COLLATION_FLAG | protocol.NUM_FLAG)
+	if pkt.Flags != want {
+		t.Errorf("Flags = 0x%04x, want 0x%04x", pkt.Flags, want)
 	}
 }
 
@@ -444,8 +419,40 @@ func TestBuildRowPacket_NullValue(t *testing.T) {
 	}
 }
 
+func TestBuildRowPacket_UnsignedColumn(t *testing.T) {
+	h := NewQueryHandler()
+	columns := []domain.ColumnInfo{
+		{Name: "n", Type: "int", Unsigned: true, Nullable: true},
+	}
+	row := domain.Row{"n": int64(-1)}
+
+	data := h.buildRowPacket(1, columns, row)
+	if !bytes.Contains(data, []byte("4294967295")) {
+		t.Errorf("unsigned int -1 should be sent as 4294967295, got %q", data)
+	}
+}
+
 // === sendQueryResult Tests ===
 
+// splitPackets splits written data into MySQL packets; result sets are written
+// in batches, so one write may carry several packets
+func splitPackets(written [][]byte) [][]byte {
+	var data []byte
+	for _, w := range written {
+		data = append(data, w...)
+	}
+	var packets [][]byte
+	for len(data) >= 4 {
+		n := 4 + (int(data[0]) | int(data[1])<<8 | int(data[2])<<16)
+		if n > len(data) {
+			break
+		}
+		packets = append(packets, data[:n])
+		data = data[n:]
+	}
+	return packets
+}
+
 func TestSendQueryResult(t *testing.T) {
 	ctx, conn, _ := newTestCtx()
 	ctx.Session.ResetSequenceID()
@@ -465,10 +472,9 @@ func TestSendQueryResult(t *testing.T) {
 		t.Fatalf("sendQueryResult error: %v", err)
 	}
 
-	written := conn.GetWrittenData()
 	// Should write: column count + 2 column defs + EOF + 2 rows + EOF = 7 packets
-	if len(written) != 7 {
-		t.Errorf("expected 7 writes, got %d", len(written))
+	if packets := splitPackets(conn.GetWrittenData()); len(packets) != 7 {
+		t.Errorf("expected 7 packets, got %d", len(packets))
 	}
 }
 
@@ -487,10 +493,9 @@ func TestSendQueryResult_EmptyRows(t *testing.T) {
 		t.Fatalf("sendQueryResult error: %v", err)
 	}
 
-	written := conn.GetWrittenData()
 	// Should write: column count + 1 column def + EOF + EOF = 4 packets
-	if len(written) != 4 {
-		t.Errorf("expected 4 writes, got %d", len(written))
+	if packets := splitPackets(conn.GetWrittenData()); len(packets) != 4 {
+		t.Errorf("expected 4 packets, got %d", len(packets))
 	}
 }
 
@@ -552,7 +557,7 @@ func TestFieldListHandler_Constructor(t *testing.T) {
 	}
 }
 
-func TestFieldListHandler_Handle_Valid(t *testing.T) {
+func TestFieldListHandler_Handle_NoAPISession(t *testing.T) {
 	ctx, conn, _ := newTestCtx()
 	h := NewFieldListHandler(nil)
 
@@ -566,11 +571,10 @@ func TestFieldListHandler_Handle_Valid(t *testing.T) {
 
 	written := conn.GetWrittenData()
 	if len(written) == 0 {
-		t.Fatal("expected EOF packet to be written")
+		t.Fatal("expected error packet to be written")
 	}
-	// EOF header at byte 4
-	if written[0][4] != 0xFE {
-		t.Errorf("expected EOF header 0xFE, got 0x%02x", written[0][4])
+	if written[0][4] != 0xFF {
+		t.Errorf("expected error header 0xFF, got 0x%02x", written[0][4])
 	}
 }
 
@@ -695,9 +699,8 @@ func TestSendQueryResult_SingleColumn(t *testing.T) {
 		t.Fatalf("sendQueryResult error: %v", err)
 	}
 
-	// column count + 1 col def + EOF + 3 rows + EOF = 7 writes
-	written := conn2.GetWrittenData()
-	if len(written) != 7 {
-		t.Errorf("expected 7 writes, got %d", len(written))
+	// column count + 1 col def + EOF + 3 rows + EOF = 7 packets
+	if packets := splitPackets(conn2.GetWrittenData()); len(packets) != 7 {
+		t.Errorf("expected 7 packets, got %d", len(packets))
 	}
 }
@@ -0,0 +1,132 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/kasuganosora/sqlexec/server/testing/mock"
+)
+
+func newSessionTrackCtx(t *testing.T, capabilities uint32) (*handler.HandlerContext, *mock.MockConnection) {
+	t.Helper()
+	db, err := api.NewDB(nil)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	ds := memory.NewMVCCDataSource(nil)
+	if err := ds.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	if err := db.RegisterDataSource("default", ds); err != nil {
+		t.Fatalf("RegisterDataSource error: %v", err)
+	}
+	apiSess := db.Session()
+	t.Cleanup(func() { apiSess.Close() })
+
+	ctx, conn, _ := newTestCtx()
+	ctx.Session.SetAPISession(apiSess)
+	ctx.Session.ClientCapabilities = capabilities
+	return ctx, conn
+}
+
+func runTrackedQuery(t *testing.T, ctx *handler.HandlerContext, conn *mock.MockConnection, query string) *protocol.OkPacket {
+	t.Helper()
+	conn.ClearWrittenData()
+	cmd := &protocol.ComQueryPacket{}
+	cmd.Payload = append([]byte{protocol.COM_QUERY}, query...)
+	if err := NewQueryHandler().Handle(ctx, cmd); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	return lastOKPacket(t, conn)
+}
+
+func lastOKPacket(t *testing.T, conn *mock.MockConnection) *protocol.OkPacket {
+	t.Helper()
+	written := conn.GetWrittenData()
+	if len(written) == 0 || written[len(written)-1][4] != 0x00 {
+		t.Fatalf("expected OK packet, got %v", written)
+	}
+	ok := &protocol.OkPacket{}
+	if err := ok.Unmarshal(bytes.NewReader(written[len(written)-1]), protocol.CLIENT_PROTOCOL_41); err != nil {
+		t.Fatalf("Unmarshal OK packet error: %v", err)
+	}
+	return ok
+}
+
+func parseTrackedState(t *testing.T, ok *protocol.OkPacket) []protocol.SessionStateEntry {
+	t.Helper()
+	if !ok.HasSessionStateChanged() {
+		t.Fatalf("expected SERVER_SESSION_STATE_CHANGED, status = 0x%04x", ok.StatusFlags)
+	}
+	entries, err := protocol.ParseSessionStateInfo(ok.SessionStateInfo)
+	if err != nil {
+		t.Fatalf("ParseSessionStateInfo error: %v", err)
+	}
+	return entries
+}
+
+func TestSessionTrack_SetNames(t *testing.T) {
+	ctx, conn := newSessionTrackCtx(t, protocol.CLIENT_PROTOCOL_41|protocol.CLIENT_SESSION_TRACK)
+
+	entries := parseTrackedState(t, runTrackedQuery(t, ctx, conn, "SET NAMES latin1"))
+	want := []string{"character_set_client", "character_set_connection", "character_set_results"}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i, entry := range entries {
+		if entry.Type != protocol.SESSION_TRACK_SYSTEM_VARIABLES || entry.Name != want[i] || entry.Value != "latin1" {
+			t.Errorf("entry %d = %+v, want %s=latin1", i, entry, want[i])
+		}
+	}
+
+	// 未跟踪的变量不报告
+	if ok := runTrackedQuery(t, ctx, conn, "SET SESSION sql_mode = ''"); ok.HasSessionStateChanged() {
+		t.Errorf("sql_mode is not tracked by default, got %q", ok.SessionStateInfo)
+	}
+
+	entries = parseTrackedState(t, runTrackedQuery(t, ctx, conn, "SET autocommit = 0"))
+	if len(entries) != 1 || entries[0].Name != "autocommit" || entries[0].Value != "0" {
+		t.Errorf("unexpected autocommit entries: %+v", entries)
+	}
+}
+
+func TestSessionTrack_Schema(t *testing.T) {
+	ctx, conn := newSessionTrackCtx(t, protocol.CLIENT_PROTOCOL_41|protocol.CLIENT_SESSION_TRACK)
+
+	entries := parseTrackedState(t, runTrackedQuery(t, ctx, conn, "USE app"))
+	if len(entries) != 1 || entries[0].Type != protocol.SESSION_TRACK_SCHEMA || entries[0].Value != "app" {
+		t.Errorf("unexpected USE entries: %+v", entries)
+	}
+
+	// COM_INIT_DB 同样报告
+	conn.ClearWrittenData()
+	cmd := &protocol.ComInitDBPacket{}
+	cmd.Payload = append([]byte{protocol.COM_INIT_DB}, "reports"...)
+	if err := NewInitDBHandler(nil).Handle(ctx, cmd); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	entries = parseTrackedState(t, lastOKPacket(t, conn))
+	if len(entries) != 1 || entries[0].Value != "reports" {
+		t.Errorf("unexpected COM_INIT_DB entries: %+v", entries)
+	}
+
+	// session_track_state_change 开启后附带 STATE_CHANGE
+	runTrackedQuery(t, ctx, conn, "SET session_track_state_change = ON")
+	entries = parseTrackedState(t, runTrackedQuery(t, ctx, conn, "USE app"))
+	if len(entries) != 2 || entries[1].Type != protocol.SESSION_TRACK_STATE_CHANGE || entries[1].Value != "1" {
+		t.Errorf("unexpected entries with state change tracking: %+v", entries)
+	}
+}
+
+func TestSessionTrack_ClientWithoutCapability(t *testing.T) {
+	ctx, conn := newSessionTrackCtx(t, protocol.CLIENT_PROTOCOL_41)
+
+	if ok := runTrackedQuery(t, ctx, conn, "SET NAMES utf8mb4"); ok.HasSessionStateChanged() || ok.SessionStateInfo != "" {
+		t.Errorf("session state must not be sent without CLIENT_SESSION_TRACK, got %q", ok.SessionStateInfo)
+	}
+}
@@ -0,0 +1,36 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// TestQueryHandler_UpsertOKInfo 测试 DML 的 OK 包带影响行数、自增 ID 与插入/更新计数
+func TestQueryHandler_UpsertOKInfo(t *testing.T) {
+	ctx, conn := newSessionTrackCtx(t, protocol.CLIENT_PROTOCOL_41)
+
+	runTrackedQuery(t, ctx, conn, "CREATE TABLE kv (id INT PRIMARY KEY AUTO_INCREMENT, k VARCHAR(10) UNIQUE, v INT)")
+	ok := runTrackedQuery(t, ctx, conn, "INSERT INTO kv (k, v) VALUES ('a', 1), ('b', 1)")
+	if ok.AffectedRows != 2 || ok.LastInsertId != 2 {
+		t.Errorf("insert: affected = %d, last insert id = %d, want 2 and 2", ok.AffectedRows, ok.LastInsertId)
+	}
+
+	ok = runTrackedQuery(t, ctx, conn, "INSERT INTO kv (k, v) VALUES ('a', 2), ('c', 1) ON DUPLICATE KEY UPDATE v = VALUES(v)")
+	if ok.AffectedRows != 3 {
+		t.Errorf("upsert: affected = %d, want 3", ok.AffectedRows)
+	}
+	if want := "Records: 2  Duplicates: 1  Warnings: 0  Inserted: 1  Updated: 1"; ok.Info != want {
+		t.Errorf("upsert: info = %q, want %q", ok.Info, want)
+	}
+
+	ok = runTrackedQuery(t, ctx, conn, "INSERT IGNORE INTO kv (k, v) VALUES ('b', 5)")
+	if ok.AffectedRows != 0 || ok.Warnings != 1 {
+		t.Errorf("insert ignore: affected = %d, warnings = %d, want 0 and 1", ok.AffectedRows, ok.Warnings)
+	}
+
+	ok = runTrackedQuery(t, ctx, conn, "DELETE FROM kv WHERE v = 1")
+	if ok.AffectedRows != 2 {
+		t.Errorf("delete: affected = %d, want 2", ok.AffectedRows)
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+)
+
+// 结果集写出的默认流控参数
+const (
+	DefaultResultBatchSize     = 16 * 1024        // 初始批量字节数
+	DefaultResultBufferMax     = 1024 * 1024      // 输出缓冲上限
+	DefaultNetWriteTimeout     = 60 * time.Second // 同 MySQL net_write_timeout
+	DefaultWriteStallThreshold = time.Second
+
+	minResultBatchSize = 4 * 1024
+	fastFlush          = time.Millisecond      // 写入未阻塞：客户端跟得上，增大批量
+	slowFlush          = 10 * time.Millisecond // 写入阻塞：客户端读取缓慢，减小批量
+)
+
+// FlowControl 结果集写出的流控参数，零值字段使用默认值
+type FlowControl struct {
+	BatchSize      int           // 初始批量字节数
+	BufferMax      int           // 输出缓冲上限，批量大小在 [4KB, BufferMax] 间自适应
+	WriteTimeout   time.Duration // 单次写出的超时，客户端在此时间内不读取数据时断开连接；负数表示不限制
+	StallThreshold time.Duration // 单次写出阻塞超过该时间计为一次写阻塞
+}
+
+func (fc FlowControl) withDefaults() FlowControl {
+	if fc.BatchSize <= 0 {
+		fc.BatchSize = DefaultResultBatchSize
+	}
+	if fc.BufferMax <= 0 {
+		fc.BufferMax = DefaultResultBufferMax
+	}
+	if fc.BatchSize < minResultBatchSize {
+		fc.BatchSize = minResultBatchSize
+	}
+	if fc.BatchSize > fc.BufferMax {
+		fc.BatchSize = fc.BufferMax
+	}
+	if fc.WriteTimeout == 0 {
+		fc.WriteTimeout = DefaultNetWriteTimeout
+	}
+	if fc.StallThreshold <= 0 {
+		fc.StallThreshold = DefaultWriteStallThreshold
+	}
+	return fc
+}
+
+// ResultWriter 带流控的结果集写入器
+//
+// 数据包先写入有界缓冲，累计到批量大小后一次写出。写出是同步的：客户端读取缓慢时
+// 写出阻塞，调用方（逐行生成数据包的结果集迭代）随之暂停，服务器不会无限缓存结果。
+// 批量大小随写出耗时自适应：写出不阻塞时加倍以减少系统调用，阻塞时减半以减少占用的内存。
+// 连接支持写超时时，每次写出都设置超时，超时返回包装了 ErrCloseConnection 的错误
+type ResultWriter struct {
+	dst   ResponseWriter
+	fc    FlowControl
+	buf   []byte
+	batch int
+	stats *monitor.NetworkStats
+}
+
+// NewResultWriter 创建结果集写入器
+func NewResultWriter(dst ResponseWriter, fc FlowControl) *ResultWriter {
+	fc = fc.withDefaults()
+	return &ResultWriter{
+		dst:   dst,
+		fc:    fc,
+		batch: fc.BatchSize,
+		stats: monitor.GetNetworkStats(),
+	}
+}
+
+// ResultWriter 返回写入当前连接的结果集写入器，用完后需调用 Flush
+func (ctx *HandlerContext) ResultWriter() *ResultWriter {
+	return NewResultWriter(ctx.Connection, ctx.FlowControl)
+}
+
+// Write 缓存一个数据包，缓冲达到批量大小时写出
+func (w *ResultWriter) Write(p []byte) (int, error) {
+	if len(w.buf)+len(p) > w.batch && len(w.buf) > 0 {
+		if err := w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	// 超过批量大小的数据包直接写出，不复制到缓冲
+	if len(p) >= w.batch {
+		if err := w.write(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// Flush 写出缓冲中的全部数据
+func (w *ResultWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// BatchSize 返回当前的批量大小
+func (w *ResultWriter) BatchSize() int {
+	return w.batch
+}
+
+// write 带超时与阻塞检测地写出数据，并根据耗时调整批量大小
+func (w *ResultWriter) write(p []byte) error {
+	conn, hasDeadline := w.dst.(interface{ SetWriteDeadline(time.Time) error })
+	if hasDeadline && w.fc.WriteTimeout > 0 {
+		if err := conn.SetWriteDeadline(time.Now().Add(w.fc.WriteTimeout)); err != nil {
+			hasDeadline = false
+		}
+	}
+
+	// 0: 写出中, 1: 已计为阻塞, 2: 已完成
+	var state atomic.Int32
+	timer := time.AfterFunc(w.fc.StallThreshold, func() {
+		if state.CompareAndSwap(0, 1) {
+			w.stats.StallBegin()
+		}
+	})
+	start := time.Now()
+	_, err := w.dst.Write(p)
+	elapsed := time.Since(start)
+	timer.Stop()
+	if !state.CompareAndSwap(0, 2) {
+		w.stats.StallEnd()
+	}
+
+	if hasDeadline && w.fc.WriteTimeout > 0 {
+		conn.SetWriteDeadline(time.Time{})
+	}
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			w.stats.RecordTimeout()
+			return fmt.Errorf("%w: client did not read results within %s", ErrCloseConnection, w.fc.WriteTimeout)
+		}
+		return err
+	}
+	w.stats.RecordFlush(len(p))
+
+	switch {
+	case elapsed < fastFlush && w.batch < w.fc.BufferMax:
+		w.batch = min(w.batch*2, w.fc.BufferMax)
+	case elapsed > slowFlush && w.batch > minResultBatchSize:
+		w.batch = max(w.batch/2, minResultBatchSize)
+	}
+	return nil
+}
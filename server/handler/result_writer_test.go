@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+)
+
+// countingWriter 记录每次写入的调用
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestResultWriter_Batches(t *testing.T) {
+	dst := &countingWriter{}
+	w := NewResultWriter(dst, FlowControl{BatchSize: 4096, BufferMax: 4096})
+
+	packet := bytes.Repeat([]byte{'x'}, 100)
+	for i := 0; i < 100; i++ {
+		if _, err := w.Write(packet); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if dst.Len() >= 100*len(packet) {
+		t.Fatalf("all data written before Flush, want the tail buffered")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if dst.Len() != 100*len(packet) {
+		t.Errorf("written %d bytes, want %d", dst.Len(), 100*len(packet))
+	}
+	if dst.writes > 3 {
+		t.Errorf("writes = %d, want packets coalesced into at most 3 writes", dst.writes)
+	}
+
+	// 超过批量大小的数据包直接写出
+	large := bytes.Repeat([]byte{'y'}, 10000)
+	before := dst.writes
+	if _, err := w.Write(large); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if dst.writes != before+1 {
+		t.Errorf("large packet should be written through immediately")
+	}
+}
+
+func TestResultWriter_GrowsBatchForFastClient(t *testing.T) {
+	w := NewResultWriter(io.Discard, FlowControl{BatchSize: 4096, BufferMax: 64 * 1024})
+	packet := make([]byte, 1024)
+	for i := 0; i < 200; i++ {
+		if _, err := w.Write(packet); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if w.BatchSize() != 64*1024 {
+		t.Errorf("BatchSize() = %d, want growth to the buffer limit", w.BatchSize())
+	}
+}
+
+func TestResultWriter_StallAndBackpressure(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	stats := monitor.GetNetworkStats()
+	stallsBefore := stats.Snapshot().Stalls
+
+	w := NewResultWriter(server, FlowControl{BatchSize: 8192, BufferMax: 8192, StallThreshold: 20 * time.Millisecond})
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Write(make([]byte, 8192))
+		done <- err
+	}()
+
+	// 客户端不读取时写入被阻塞，连接计为写阻塞
+	deadline := time.Now().Add(2 * time.Second)
+	for stats.Snapshot().StalledConnections == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stalled write was not reported")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("write returned before the client read the data")
+	default:
+	}
+
+	go io.Copy(io.Discard, client)
+	if err := <-done; err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := stats.Snapshot().Stalls - stallsBefore; got < 1 {
+		t.Errorf("Stalls increased by %d, want at least 1", got)
+	}
+	if w.BatchSize() >= 8192 {
+		t.Errorf("BatchSize() = %d, want it reduced for a slow client", w.BatchSize())
+	}
+}
+
+func TestResultWriter_WriteTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	timeoutsBefore := monitor.GetNetworkStats().Snapshot().Timeouts
+	w := NewResultWriter(server, FlowControl{WriteTimeout: 30 * time.Millisecond})
+	w.Write([]byte("row"))
+	err := w.Flush()
+	if !errors.Is(err, ErrCloseConnection) {
+		t.Fatalf("Flush() error = %v, want ErrCloseConnection", err)
+	}
+	if got := monitor.GetNetworkStats().Snapshot().Timeouts - timeoutsBefore; got != 1 {
+		t.Errorf("Timeouts increased by %d, want 1", got)
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// sessionStateTracker 能报告会话状态变化的 API Session（避免直接依赖 api 包）
+type sessionStateTracker interface {
+	TakeSessionStateChanges() pkg_session.SessionStateChanges
+}
+
+// attachSessionState 取出 SET / USE 产生的会话状态变化；客户端协商了 CLIENT_SESSION_TRACK 时
+// 编码到 OK 包的 SessionStateInfo 并设置 SERVER_SESSION_STATE_CHANGED
+func (ctx *HandlerContext) attachSessionState(ok *protocol.OkInPacket) {
+	if ctx.Session == nil {
+		return
+	}
+	tracker, isTracker := ctx.Session.GetAPISession().(sessionStateTracker)
+	if !isTracker {
+		return
+	}
+	changes := tracker.TakeSessionStateChanges()
+	if changes.Empty() || ctx.Session.ClientCapabilities&protocol.CLIENT_SESSION_TRACK == 0 {
+		return
+	}
+
+	var builder protocol.SessionStateBuilder
+	for _, v := range changes.SystemVariables {
+		builder.AddSystemVariable(v.Name, v.Value)
+	}
+	if changes.SchemaChanged {
+		builder.AddSchema(changes.Schema)
+	}
+	if changes.StateChanged {
+		builder.AddStateChange()
+	}
+	ok.SessionStateInfo = builder.String()
+	ok.SetSessionStateChanged(true)
+}
@@ -0,0 +1,39 @@
+package simple
+
+import (
+	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// ResetConnectionHandler RESET_CONNECTION 命令处理器
+// 连接池复用连接时发送，重置会话状态但保留认证用户和当前数据库
+type ResetConnectionHandler struct{}
+
+// NewResetConnectionHandler 创建 RESET_CONNECTION 处理器
+func NewResetConnectionHandler() *ResetConnectionHandler {
+	return &ResetConnectionHandler{}
+}
+
+// Handle 处理 COM_RESET_CONNECTION 命令
+func (h *ResetConnectionHandler) Handle(ctx *handler.HandlerContext, packet interface{}) error {
+	// 每个命令开始时重置序列号
+	ctx.ResetSequenceID()
+
+	ctx.Log("处理 COM_RESET_CONNECTION")
+
+	if err := ctx.ResetSessionState(); err != nil {
+		return ctx.SendError(err)
+	}
+
+	return ctx.SendOK()
+}
+
+// Command 返回命令类型
+func (h *ResetConnectionHandler) Command() uint8 {
+	return protocol.COM_RESET_CONNECTION
+}
+
+// Name 返回处理器名称
+func (h *ResetConnectionHandler) Name() string {
+	return "COM_RESET_CONNECTION"
+}
@@ -33,7 +33,15 @@ func (h *SetOptionHandler) Handle(ctx *handler.HandlerContext, packet interface{
 	// 每个命令开始时重置序列号
 	ctx.ResetSequenceID()
 
-	// 简化实现，直接返回 OK
+	switch cmd.OptionOperation {
+	case protocol.MYSQL_OPTION_MULTI_STATEMENTS_ON:
+		ctx.Session.ClientCapabilities |= protocol.CLIENT_MULTI_STATEMENTS
+	case protocol.MYSQL_OPTION_MULTI_STATEMENTS_OFF:
+		ctx.Session.ClientCapabilities &^= protocol.CLIENT_MULTI_STATEMENTS
+	default:
+		// ER_UNKNOWN_COM_ERROR
+		return ctx.SendError(handler.NewHandlerError("Unknown command"))
+	}
 	return ctx.SendOK()
 }
 
@@ -1,8 +1,10 @@
 package simple
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/session"
 	"github.com/kasuganosora/sqlexec/server/handler"
@@ -136,6 +138,36 @@ func TestSetOptionHandler_Handle_Valid(t *testing.T) {
 	}
 }
 
+func TestSetOptionHandler_Handle_MultiStatements(t *testing.T) {
+	ctx, conn, _ := newTestCtx()
+	h := NewSetOptionHandler(nil)
+
+	pkt := &protocol.ComSetOptionPacket{OptionOperation: protocol.MYSQL_OPTION_MULTI_STATEMENTS_ON}
+	if err := h.Handle(ctx, pkt); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	if ctx.Session.ClientCapabilities&protocol.CLIENT_MULTI_STATEMENTS == 0 {
+		t.Error("multi statements should be enabled")
+	}
+
+	pkt.OptionOperation = protocol.MYSQL_OPTION_MULTI_STATEMENTS_OFF
+	if err := h.Handle(ctx, pkt); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	if ctx.Session.ClientCapabilities&protocol.CLIENT_MULTI_STATEMENTS != 0 {
+		t.Error("multi statements should be disabled")
+	}
+
+	pkt.OptionOperation = 2
+	if err := h.Handle(ctx, pkt); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	written := conn.GetWrittenData()
+	if last := written[len(written)-1]; last[4] != 0xFF {
+		t.Errorf("expected error header 0xFF for unknown option, got 0x%02x", last[4])
+	}
+}
+
 func TestSetOptionHandler_Handle_InvalidPacket(t *testing.T) {
 	ctx, conn, _ := newTestCtx()
 	h := NewSetOptionHandler(nil)
@@ -244,6 +276,31 @@ func TestStatisticsHandler_Handle(t *testing.T) {
 	}
 }
 
+type fixedStats handler.ServerStats
+
+func (s fixedStats) ServerStats() handler.ServerStats {
+	return handler.ServerStats(s)
+}
+
+func TestStatisticsHandler_Handle_ServerStats(t *testing.T) {
+	ctx, conn, _ := newTestCtx()
+	ctx.Stats = fixedStats{Uptime: 100 * time.Second, Threads: 3, Questions: 50, SlowQueries: 2, OpenTables: 7}
+	h := NewStatisticsHandler()
+
+	if err := h.Handle(ctx, nil); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+
+	written := conn.GetWrittenData()
+	if len(written) == 0 {
+		t.Fatal("expected statistics packet to be written")
+	}
+	want := "Uptime: 100  Threads: 3  Questions: 50  Slow queries: 2  Opens: 7  Flush tables: 0  Open tables: 7  Queries per second avg: 0.500"
+	if payload := string(written[0][4:]); payload != want {
+		t.Errorf("statistics = %q, want %q", payload, want)
+	}
+}
+
 func TestStatisticsHandler_CommandAndName(t *testing.T) {
 	h := NewStatisticsHandler()
 	if h.Command() != protocol.COM_STATISTICS {
@@ -285,3 +342,53 @@ func TestNewSetOptionHandler_NilBuilder(t *testing.T) {
 		t.Fatal("okBuilder should be created when nil passed")
 	}
 }
+
+// === ResetConnectionHandler ===
+
+func TestResetConnectionHandler_Handle(t *testing.T) {
+	mgr := session.NewSessionMgr(context.Background(), session.NewMemoryDriver())
+	defer mgr.Close()
+	sess, err := mgr.CreateSession(context.Background(), "127.0.0.1", "3307")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	sess.SetVariable("sql_mode", "ANSI")
+	sess.Set("stmt_1", "SELECT ?")
+	sess.Set("current_database", "shop")
+
+	ctx, conn, _ := newTestCtx()
+	ctx.Session = sess
+	h := NewResetConnectionHandler()
+
+	if err := h.Handle(ctx, &protocol.ComResetConnectionPacket{}); err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+
+	written := conn.GetWrittenData()
+	if len(written) == 0 {
+		t.Fatal("expected OK packet to be written")
+	}
+	if written[0][3] != 0x01 || written[0][4] != 0x00 {
+		t.Errorf("expected OK packet with seq 1, got seq %d header 0x%02x", written[0][3], written[0][4])
+	}
+
+	if val, _ := sess.GetVariable("sql_mode"); val != nil {
+		t.Errorf("session variable not cleared: %v", val)
+	}
+	if val, _ := sess.Get("stmt_1"); val != nil {
+		t.Errorf("prepared statement not cleared: %v", val)
+	}
+	if val, _ := sess.Get("current_database"); val != "shop" {
+		t.Errorf("current_database = %v, want shop", val)
+	}
+}
+
+func TestResetConnectionHandler_CommandAndName(t *testing.T) {
+	h := NewResetConnectionHandler()
+	if h.Command() != protocol.COM_RESET_CONNECTION {
+		t.Errorf("Command = 0x%02x, want 0x%02x", h.Command(), protocol.COM_RESET_CONNECTION)
+	}
+	if h.Name() != "COM_RESET_CONNECTION" {
+		t.Errorf("Name = %q, want %q", h.Name(), "COM_RESET_CONNECTION")
+	}
+}
@@ -2,6 +2,7 @@ package simple
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/kasuganosora/sqlexec/server/handler"
 	"github.com/kasuganosora/sqlexec/server/protocol"
@@ -20,10 +21,13 @@ func (h *StatisticsHandler) Handle(ctx *handler.HandlerContext, packet interface
 	ctx.Log("处理 COM_STATISTICS")
 	ctx.ResetSequenceID()
 
-	// COM_STATISTICS response is a string wrapped in a MySQL packet header
-	stats := "Uptime: 3600  Threads: 1  Questions: 10  Slow queries: 0  Opens: 5  Flush tables: 1  Open tables: 4  Queries per second avg: 0.003"
-	payload := []byte(stats)
+	var stats handler.ServerStats
+	if ctx.Stats != nil {
+		stats = ctx.Stats.ServerStats()
+	}
+	payload := []byte(FormatStatistics(stats))
 
+	// COM_STATISTICS response is a string wrapped in a MySQL packet header
 	// Build proper MySQL packet: 3-byte length (LE) + 1-byte sequence ID + payload
 	packetBuf := new(bytes.Buffer)
 	packetBuf.Write([]byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16)})
@@ -34,6 +38,17 @@ func (h *StatisticsHandler) Handle(ctx *handler.HandlerContext, packet interface
 	return err
 }
 
+// FormatStatistics 按 mysqladmin status 的格式输出统计字符串
+func FormatStatistics(stats handler.ServerStats) string {
+	uptime := int64(stats.Uptime.Seconds())
+	qps := 0.0
+	if uptime > 0 {
+		qps = float64(stats.Questions) / float64(uptime)
+	}
+	return fmt.Sprintf("Uptime: %d  Threads: %d  Questions: %d  Slow queries: %d  Opens: %d  Flush tables: 0  Open tables: %d  Queries per second avg: %.3f",
+		uptime, stats.Threads, stats.Questions, stats.SlowQueries, stats.OpenTables, stats.OpenTables, qps)
+}
+
 // Command 返回命令类型
 func (h *StatisticsHandler) Command() uint8 {
 	return protocol.COM_STATISTICS
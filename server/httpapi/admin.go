@@ -0,0 +1,549 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/kasuganosora/sqlexec/server/acl"
+)
+
+const (
+	// defaultSampleRows is the number of sample rows returned for a table
+	defaultSampleRows = 50
+
+	// maxSampleRows bounds the limit parameter of the table endpoint
+	maxSampleRows = 1000
+)
+
+// adminRoutes maps the admin endpoints to their allowed methods
+var adminRoutes = map[string]string{
+	"databases":   "GET",
+	"tables":      "GET",
+	"table":       "GET",
+	"processlist": "GET",
+	"slowlog":     "GET DELETE",
+	"users":       "GET POST PUT DELETE",
+	"datasources": "GET POST DELETE",
+	"health":      "GET",
+	"panics":      "GET",
+}
+
+// AdminHandler handles the JSON endpoints under /api/v1/admin/ used by the
+// web console. Schema browsing is open to every principal within its
+// database scopes; the process list, slow log, users, datasources, their
+// health and the recovered panics are server-wide and require the SUPER
+// privilege.
+type AdminHandler struct {
+	db          *api.DB
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+	aclManager  *acl.ACLManager
+	auditLogger *security.AuditLogger
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(db *api.DB, auditLogger *security.AuditLogger) *AdminHandler {
+	return &AdminHandler{db: db, auditLogger: auditLogger}
+}
+
+// SetVirtualDBRegistry sets the virtual database registry
+func (h *AdminHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
+	h.vdbRegistry = registry
+}
+
+// SetACLManager sets the ACL backing the user endpoints
+func (h *AdminHandler) SetACLManager(manager *acl.ACLManager) {
+	h.aclManager = manager
+}
+
+// ServeHTTP dispatches admin requests
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	route := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin"), "/")
+	methods, ok := adminRoutes[route]
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "endpoint not found")
+		return
+	}
+	if !slices.Contains(strings.Fields(methods), r.Method) {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	switch route {
+	case "databases":
+		h.databases(w, r, principal)
+		return
+	case "tables":
+		h.tables(w, r, principal)
+		return
+	case "table":
+		h.table(w, r, principal)
+		return
+	}
+
+	if !principal.Super {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, "SUPER privilege required")
+		return
+	}
+	if r.Method != http.MethodGet && principal.ReadOnly {
+		writeError(w, http.StatusForbidden, string(api.ErrCodeReadOnly), "read-only principal")
+		return
+	}
+	switch route {
+	case "processlist":
+		h.processList(w, r, principal)
+	case "slowlog":
+		h.slowLog(w, r, principal)
+	case "users":
+		h.users(w, r, principal)
+	case "datasources":
+		h.datasources(w, r, principal)
+	case "health":
+		h.health(w, r)
+	case "panics":
+		h.panics(w)
+	}
+}
+
+// databases lists the databases the principal may use
+func (h *AdminHandler) databases(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	session := h.session(principal, "")
+	defer session.Close()
+
+	rows, err := session.QueryAll("SHOW DATABASES")
+	if err != nil {
+		h.logRequest(r, principal, "SHOW DATABASES", "", false)
+		writeStatementError(w, "query failed", err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	resp := AdminDatabasesResponse{Databases: []AdminDatabase{}}
+	add := func(name string) {
+		if name == "" || seen[strings.ToLower(name)] || !principal.AllowsDatabase(name) {
+			return
+		}
+		seen[strings.ToLower(name)] = true
+		virtualDB := strings.EqualFold(name, "information_schema") ||
+			(h.vdbRegistry != nil && h.vdbRegistry.IsVirtualDB(name))
+		resp.Databases = append(resp.Databases, AdminDatabase{Name: name, Virtual: virtualDB})
+	}
+	for _, row := range rows {
+		for _, v := range row {
+			add(fmt.Sprintf("%v", v))
+		}
+	}
+	if h.vdbRegistry != nil {
+		for _, entry := range h.vdbRegistry.List() {
+			add(entry.Name)
+		}
+	}
+	sort.Slice(resp.Databases, func(i, j int) bool { return resp.Databases[i].Name < resp.Databases[j].Name })
+
+	h.logRequest(r, principal, "SHOW DATABASES", "", true)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// tables lists the tables of the database parameter
+func (h *AdminHandler) tables(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	database, ok := h.databaseParam(w, r, principal)
+	if !ok {
+		return
+	}
+	session := h.session(principal, database)
+	defer session.Close()
+
+	query, err := session.Query("SHOW TABLES")
+	if err != nil {
+		h.logRequest(r, principal, "SHOW TABLES", database, false)
+		writeStatementError(w, "query failed", err)
+		return
+	}
+	defer query.Close()
+
+	resp := AdminTablesResponse{Database: database, Tables: []string{}}
+	columns := query.Columns()
+	for query.Next() {
+		if len(columns) > 0 {
+			resp.Tables = append(resp.Tables, fmt.Sprintf("%v", query.Row()[columns[0].Name]))
+		}
+	}
+	sort.Strings(resp.Tables)
+
+	h.logRequest(r, principal, "SHOW TABLES", database, true)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// table returns the columns and the first rows of a table
+func (h *AdminHandler) table(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	database, ok := h.databaseParam(w, r, principal)
+	if !ok {
+		return
+	}
+	table := r.URL.Query().Get("table")
+	if table == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "table parameter is required")
+		return
+	}
+	limit := defaultSampleRows
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > maxSampleRows {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest,
+				fmt.Sprintf("limit must be between 0 and %d", maxSampleRows))
+			return
+		}
+		limit = n
+	}
+
+	session := h.session(principal, database)
+	defer session.Close()
+
+	resp := AdminTableResponse{Database: database, Table: table}
+	schemaSQL := "SHOW COLUMNS FROM " + security.QuoteIdentifier(table)
+	schema, err := collectRows(session, schemaSQL, maxResultRows)
+	if err != nil {
+		h.logRequest(r, principal, schemaSQL, database, false)
+		writeStatementError(w, "query failed", err)
+		return
+	}
+	resp.Schema = schema
+
+	sampleSQL := fmt.Sprintf("SELECT * FROM %s LIMIT %d", security.QuoteIdentifier(table), limit)
+	sample, err := collectRows(session, sampleSQL, limit)
+	if err != nil {
+		h.logRequest(r, principal, sampleSQL, database, false)
+		writeStatementError(w, "query failed", err)
+		return
+	}
+	resp.Sample = sample
+
+	h.logRequest(r, principal, sampleSQL, database, true)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// processList returns the running statements of all sessions
+func (h *AdminHandler) processList(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	session := h.session(principal, "")
+	defer session.Close()
+
+	resp, err := collectRows(session, "SHOW PROCESSLIST", maxResultRows)
+	h.logRequest(r, principal, "SHOW PROCESSLIST", "", err == nil)
+	if err != nil {
+		writeStatementError(w, "query failed", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// slowLog returns (GET, optionally ?digest=) or clears (DELETE) the slow query log
+func (h *AdminHandler) slowLog(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	slowLog := monitor.GetSlowQueryLog()
+	if r.Method == http.MethodDelete {
+		slowLog.Clear()
+		h.logRequest(r, principal, "", "", true)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var queries []*monitor.SlowQueryLog
+	if digest := r.URL.Query().Get("digest"); digest != "" {
+		queries = slowLog.GetSlowQueriesByDigest(digest)
+	} else {
+		queries = slowLog.GetAllSlowQueries()
+	}
+	resp := SlowLogResponse{
+		ThresholdMs: slowLog.GetThreshold().Milliseconds(),
+		SampleRate:  slowLog.ExplainSampleRate(),
+		Entries:     make([]SlowLogEntry, 0, len(queries)),
+	}
+	for i := len(queries) - 1; i >= 0; i-- {
+		q := queries[i]
+		entry := SlowLogEntry{
+			ID:         q.ID,
+			SQL:        q.SQL,
+			User:       q.ExecutedBy,
+			DurationMs: float64(q.Duration) / float64(time.Millisecond),
+			Rows:       q.RowCount,
+			Error:      q.Error,
+			Time:       q.Timestamp,
+			Digest:     q.Digest,
+			Plan:       q.ExplainPlan,
+			Labels:     q.Labels,
+		}
+		if q.Stats != nil {
+			entry.Stats = &SlowLogStats{
+				PlanDigest:    q.Stats.PlanDigest,
+				EstimatedCost: q.Stats.EstimatedCost,
+				ExecCount:     q.Stats.ExecCount,
+				AvgLatencyMs:  float64(q.Stats.AvgLatency) / float64(time.Millisecond),
+				AvgRows:       q.Stats.AvgRows,
+			}
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	h.logRequest(r, principal, "", "", true)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// users lists (GET), creates (POST), changes the password of (PUT) or drops
+// (DELETE ?user=&host=) ACL users
+func (h *AdminHandler) users(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	if h.aclManager == nil {
+		writeError(w, http.StatusNotImplemented, ErrCodeNotSupported, "user management is not available")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		resp := AdminUsersResponse{Users: []AdminUser{}}
+		for _, u := range h.aclManager.GetUsers() {
+			user := AdminUser{User: u.User, Host: u.Host, HasPassword: u.Password != "", Privileges: []string{}}
+			for priv, granted := range u.Privileges {
+				if granted {
+					user.Privileges = append(user.Privileges, priv)
+				}
+			}
+			sort.Strings(user.Privileges)
+			resp.Users = append(resp.Users, user)
+		}
+		sort.Slice(resp.Users, func(i, j int) bool {
+			if resp.Users[i].User != resp.Users[j].User {
+				return resp.Users[i].User < resp.Users[j].User
+			}
+			return resp.Users[i].Host < resp.Users[j].Host
+		})
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPut:
+		var req CreateUserRequest
+		if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if req.User == "" {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "user field is required")
+			return
+		}
+		if req.Host == "" {
+			req.Host = "%"
+		}
+		action := fmt.Sprintf("SET PASSWORD FOR '%s'@'%s'", req.User, req.Host)
+		if err := h.aclManager.SetPassword(req.Host, req.User, req.Password); err != nil {
+			h.logRequest(r, principal, action, "", false)
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+			return
+		}
+		h.logRequest(r, principal, action, "", true)
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodPost:
+		var req CreateUserRequest
+		if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if req.User == "" {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "user field is required")
+			return
+		}
+		if req.Host == "" {
+			req.Host = "%"
+		}
+		action := fmt.Sprintf("CREATE USER '%s'@'%s'", req.User, req.Host)
+		if err := h.aclManager.CreateUser(req.Host, req.User, req.Password); err != nil {
+			h.logRequest(r, principal, action, "", false)
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+			return
+		}
+		h.logRequest(r, principal, action, "", true)
+		writeJSON(w, http.StatusCreated, AdminUser{User: req.User, Host: req.Host, HasPassword: req.Password != "", Privileges: []string{}})
+
+	case http.MethodDelete:
+		user, host := r.URL.Query().Get("user"), r.URL.Query().Get("host")
+		if user == "" {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "user parameter is required")
+			return
+		}
+		if host == "" {
+			host = "%"
+		}
+		action := fmt.Sprintf("DROP USER '%s'@'%s'", user, host)
+		if err := h.aclManager.DropUser(host, user); err != nil {
+			h.logRequest(r, principal, action, "", false)
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, err.Error())
+			return
+		}
+		h.logRequest(r, principal, action, "", true)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// datasources lists (GET), adds (POST) or removes (DELETE ?name=) datasources
+// through the config.datasource table, which persists them in datasources.json
+func (h *AdminHandler) datasources(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	if h.vdbRegistry == nil || !h.vdbRegistry.IsVirtualDB("config") {
+		writeError(w, http.StatusNotImplemented, ErrCodeNotSupported, "datasource management is not available")
+		return
+	}
+	session := h.session(principal, "")
+	defer session.Close()
+
+	switch r.Method {
+	case http.MethodGet:
+		const listSQL = "SELECT * FROM config.datasource"
+		resp, err := collectRows(session, listSQL, maxResultRows)
+		h.logRequest(r, principal, listSQL, "config", err == nil)
+		if err != nil {
+			writeStatementError(w, "query failed", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var req DatasourceRequest
+		if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+			return
+		}
+		if req.Name == "" || req.Type == "" {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "name and type fields are required")
+			return
+		}
+		writable := req.Writable == nil || *req.Writable
+		options := ""
+		if len(req.Options) > 0 {
+			data, err := json.Marshal(req.Options)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid options: "+err.Error())
+				return
+			}
+			options = string(data)
+		}
+		const insertSQL = "INSERT INTO config.datasource (name, type, host, port, username, password, database_name, writable, options) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		_, err := session.Execute(insertSQL, req.Name, req.Type, req.Host, req.Port, req.Username, req.Password, req.Database, writable, options)
+		h.logRequest(r, principal, "INSERT INTO config.datasource: "+req.Name, "config", err == nil)
+		if err != nil {
+			writeStatementError(w, "execute failed", err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, ExecResponse{AffectedRows: 1})
+
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "name parameter is required")
+			return
+		}
+		result, err := session.Execute("DELETE FROM config.datasource WHERE name = ?", name)
+		h.logRequest(r, principal, "DELETE FROM config.datasource: "+name, "config", err == nil)
+		if err != nil {
+			writeStatementError(w, "execute failed", err)
+			return
+		}
+		if result.RowsAffected == 0 {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("datasource '%s' not found", name))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// health reports the health of every datasource as of its last probe,
+// including probe errors; ?refresh=true probes them first
+func (h *AdminHandler) health(w http.ResponseWriter, r *http.Request) {
+	health := h.db.DatasourceHealth()
+	if r.URL.Query().Get("refresh") == "true" {
+		health = h.db.CheckDatasources(r.Context())
+	}
+	resp := AdminHealthResponse{Datasources: make([]DatasourceStatus, 0, len(health))}
+	for _, ds := range health {
+		resp.Datasources = append(resp.Datasources, datasourceStatus(ds))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// panics reports the recovered panics with their stack traces
+func (h *AdminHandler) panics(w http.ResponseWriter) {
+	stats := monitor.GetPanicStats().Snapshot()
+	writeJSON(w, http.StatusOK, AdminPanicsResponse{
+		Connection: stats.Connection,
+		Statement:  stats.Statement,
+		HTTP:       stats.HTTP,
+		Panics:     stats.Recent,
+	})
+}
+
+// databaseParam returns the database parameter, defaulting to the
+// principal's first database, and rejects databases outside its scopes
+func (h *AdminHandler) databaseParam(w http.ResponseWriter, r *http.Request, principal *Principal) (string, bool) {
+	database := principal.defaultDatabase(r.URL.Query().Get("database"))
+	if database == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "database parameter is required")
+		return "", false
+	}
+	if !principal.AllowsDatabase(database) {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, fmt.Sprintf("access to database '%s' is not allowed", database))
+		return "", false
+	}
+	return database, true
+}
+
+// session creates an ephemeral session running as the principal
+func (h *AdminHandler) session(principal *Principal, database string) *api.Session {
+	session := h.db.Session()
+	if h.vdbRegistry != nil {
+		session.SetVirtualDBRegistry(h.vdbRegistry)
+	}
+	principal.configureSession(session)
+	if database != "" {
+		session.SetCurrentDB(database)
+	}
+	return session
+}
+
+// collectRows runs a query and returns at most limit rows
+func collectRows(session *api.Session, sql string, limit int) (QueryResponse, error) {
+	query, err := session.Query(sql)
+	if err != nil {
+		return QueryResponse{}, err
+	}
+	defer query.Close()
+
+	resp := QueryResponse{Columns: query.Columns(), Rows: make([]domain.Row, 0)}
+	for query.Next() {
+		if len(resp.Rows) >= limit {
+			resp.Truncated = true
+			break
+		}
+		resp.Rows = append(resp.Rows, query.Row())
+	}
+	resp.Total = int64(len(resp.Rows))
+	return resp, nil
+}
+
+func (h *AdminHandler) logRequest(r *http.Request, principal *Principal, sql, database string, success bool) {
+	if h.auditLogger != nil {
+		traceID := r.Header.Get("X-Trace-ID")
+		if traceID == "" {
+			traceID = fmt.Sprintf("http-%d", time.Now().UnixMilli())
+		}
+		h.auditLogger.LogAPIRequestBy(traceID, principal.auditPrincipal(), getClientIP(r), r.Method, r.URL.Path, sql, database, 0, success)
+	}
+}
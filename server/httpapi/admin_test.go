@@ -0,0 +1,291 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/kasuganosora/sqlexec/server/acl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminTestServer serves the admin endpoints and the web console with
+// unsigned keys: "admin" has SUPER, "scoped" is limited to the default database
+func newAdminTestServer(t *testing.T) (*testEnv, *httptest.Server) {
+	t.Helper()
+	env := setupTestEnv(t)
+	clients := []config_schema.APIClient{
+		{Name: "admin", APIKey: "admin-key", APISecret: "admin-secret", Enabled: true},
+		{Name: "scoped", APIKey: "scoped-key", APISecret: "scoped-secret", Enabled: true, Permissions: "read db:default"},
+	}
+	data, err := json.Marshal(clients)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(env.configDir, "api_clients.json"), data, 0600))
+
+	session := env.db.Session()
+	_, err = session.Execute("CREATE TABLE items (id INT PRIMARY KEY, name VARCHAR(32))")
+	require.NoError(t, err)
+	_, err = session.Execute("INSERT INTO items (id, name) VALUES (1, 'apple'), (2, 'pear')")
+	require.NoError(t, err)
+	session.Close()
+
+	registry := virtual.NewVirtualDatabaseRegistry()
+	registry.Register(&virtual.VirtualDatabaseEntry{
+		Name:     "config",
+		Provider: config_schema.NewProvider(env.db.GetDSManager(), env.configDir),
+		Writable: true,
+	})
+	aclManager, err := acl.NewACLManager(t.TempDir())
+	require.NoError(t, err)
+
+	auth := NewAuthenticator(NewClientStore(env.configDir), config.HTTPAuthConfig{
+		AllowUnsignedKeys: true,
+		ACLUsers:          map[string]string{"admin": "dba"},
+	})
+	auth.SetSuperChecker(func(user, host string) bool { return user == "dba" })
+	admin := NewAdminHandler(env.db, env.auditLogger)
+	admin.SetVirtualDBRegistry(registry)
+	admin.SetACLManager(aclManager)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/admin/", auth.Middleware(admin))
+	mux.Handle("/ui/", WebUIHandler())
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return env, server
+}
+
+// adminRequest calls an admin endpoint as apiKey and decodes the JSON response into out
+func adminRequest(t *testing.T, server *httptest.Server, apiKey, method, path string, params url.Values, body string, out interface{}) int {
+	t.Helper()
+	target := server.URL + "/api/v1/admin/" + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, target, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", apiKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestAdmin_SchemaBrowsing(t *testing.T) {
+	_, server := newAdminTestServer(t)
+
+	var dbs AdminDatabasesResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "databases", nil, "", &dbs))
+	names := map[string]bool{}
+	for _, db := range dbs.Databases {
+		names[db.Name] = db.Virtual
+	}
+	assert.Contains(t, names, "default")
+	assert.False(t, names["default"])
+	assert.True(t, names["config"])
+
+	// Scoped principals only see their databases
+	dbs = AdminDatabasesResponse{}
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "scoped-key", "GET", "databases", nil, "", &dbs))
+	require.Len(t, dbs.Databases, 1)
+	assert.Equal(t, "default", dbs.Databases[0].Name)
+
+	var tables AdminTablesResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "scoped-key", "GET", "tables", nil, "", &tables))
+	assert.Equal(t, "default", tables.Database)
+	assert.Contains(t, tables.Tables, "items")
+
+	var errResp ErrorResponse
+	assert.Equal(t, http.StatusForbidden, adminRequest(t, server, "scoped-key", "GET", "tables", url.Values{"database": {"config"}}, "", &errResp))
+	assert.Equal(t, ErrCodeForbidden, errResp.ErrorCode)
+
+	var table AdminTableResponse
+	params := url.Values{"database": {"default"}, "table": {"items"}, "limit": {"1"}}
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "table", params, "", &table))
+	assert.Len(t, table.Schema.Rows, 2)
+	require.Len(t, table.Sample.Rows, 1)
+	assert.NotEmpty(t, table.Sample.Columns)
+
+	params.Set("limit", "100000")
+	assert.Equal(t, http.StatusBadRequest, adminRequest(t, server, "admin-key", "GET", "table", params, "", nil))
+	assert.Equal(t, http.StatusNotFound, adminRequest(t, server, "admin-key", "GET", "nope", nil, "", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(t, server, "admin-key", "POST", "tables", nil, "", nil))
+}
+
+func TestAdmin_SuperRequired(t *testing.T) {
+	_, server := newAdminTestServer(t)
+
+	for _, path := range []string{"processlist", "slowlog", "users", "datasources"} {
+		var errResp ErrorResponse
+		assert.Equal(t, http.StatusForbidden, adminRequest(t, server, "scoped-key", "GET", path, nil, "", &errResp), path)
+		assert.Equal(t, ErrCodeForbidden, errResp.ErrorCode, path)
+	}
+
+	var resp QueryResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "processlist", nil, "", &resp))
+	assert.NotEmpty(t, resp.Columns)
+}
+
+func TestAdmin_SlowLog(t *testing.T) {
+	env, server := newAdminTestServer(t)
+
+	slowLog := monitor.GetSlowQueryLog()
+	slowLog.Configure(time.Nanosecond, 10)
+	slowLog.SetExplainSampleRate(1)
+	slowLog.Clear()
+	t.Cleanup(func() {
+		slowLog.Configure(0, monitor.DefaultSlowQueryMaxEntries)
+		slowLog.SetExplainSampleRate(0)
+		slowLog.Clear()
+	})
+
+	session := env.db.Session()
+	session.SetUser("reporter")
+	_, err := session.QueryAll("SELECT * FROM items")
+	require.NoError(t, err)
+	session.Close()
+
+	var resp SlowLogResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "slowlog", nil, "", &resp))
+	assert.Equal(t, int64(0), resp.ThresholdMs)
+	require.NotEmpty(t, resp.Entries)
+	assert.Equal(t, 1.0, resp.SampleRate)
+	var digest string
+	for _, e := range resp.Entries {
+		if e.SQL == "SELECT * FROM items" {
+			digest = e.Digest
+			assert.Equal(t, "reporter", e.User)
+			assert.Equal(t, int64(2), e.Rows)
+			assert.Contains(t, e.Plan, "TableScan")
+			require.NotNil(t, e.Stats)
+			assert.NotEmpty(t, e.Stats.PlanDigest)
+		}
+	}
+	require.NotEmpty(t, digest)
+
+	var byDigest SlowLogResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "slowlog", url.Values{"digest": {digest}}, "", &byDigest))
+	require.Len(t, byDigest.Entries, 1)
+	assert.Equal(t, "SELECT * FROM items", byDigest.Entries[0].SQL)
+
+	require.Equal(t, http.StatusNoContent, adminRequest(t, server, "admin-key", "DELETE", "slowlog", nil, "", nil))
+	assert.Zero(t, slowLog.GetSlowQueryCount())
+}
+
+func TestAdmin_Users(t *testing.T) {
+	_, server := newAdminTestServer(t)
+
+	var created AdminUser
+	require.Equal(t, http.StatusCreated, adminRequest(t, server, "admin-key", "POST", "users", nil,
+		`{"user":"analyst","password":"s3cret"}`, &created))
+	assert.Equal(t, "%", created.Host)
+
+	var users AdminUsersResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "users", nil, "", &users))
+	var analyst *AdminUser
+	for i := range users.Users {
+		if users.Users[i].User == "analyst" {
+			analyst = &users.Users[i]
+		}
+	}
+	require.NotNil(t, analyst)
+	assert.True(t, analyst.HasPassword)
+
+	assert.Equal(t, http.StatusBadRequest, adminRequest(t, server, "admin-key", "POST", "users", nil, `{"user":"analyst"}`, nil))
+	assert.Equal(t, http.StatusNoContent, adminRequest(t, server, "admin-key", "PUT", "users", nil, `{"user":"analyst","password":"n3w-secret"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, adminRequest(t, server, "admin-key", "PUT", "users", nil, `{"user":"nobody","password":"x"}`, nil))
+	assert.Equal(t, http.StatusNoContent, adminRequest(t, server, "admin-key", "DELETE", "users", url.Values{"user": {"analyst"}}, "", nil))
+	assert.Equal(t, http.StatusNotFound, adminRequest(t, server, "admin-key", "DELETE", "users", url.Values{"user": {"analyst"}}, "", nil))
+}
+
+func TestAdmin_Datasources(t *testing.T) {
+	env, server := newAdminTestServer(t)
+
+	var errResp ErrorResponse
+	require.Equal(t, http.StatusCreated, adminRequest(t, server, "admin-key", "POST", "datasources", nil,
+		`{"name":"scratch","type":"memory"}`, &errResp), errResp.Error)
+	assert.Contains(t, env.db.GetDSManager().List(), "scratch")
+
+	var list QueryResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "datasources", nil, "", &list))
+	found := false
+	for _, row := range list.Rows {
+		if row["name"] == "scratch" {
+			found = true
+			assert.Equal(t, "connected", row["status"])
+		}
+	}
+	assert.True(t, found)
+
+	assert.Equal(t, http.StatusNoContent, adminRequest(t, server, "admin-key", "DELETE", "datasources", url.Values{"name": {"scratch"}}, "", nil))
+	assert.NotContains(t, env.db.GetDSManager().List(), "scratch")
+	assert.Equal(t, http.StatusNotFound, adminRequest(t, server, "admin-key", "DELETE", "datasources", url.Values{"name": {"scratch"}}, "", nil))
+}
+
+func TestAdmin_Health(t *testing.T) {
+	_, server := newAdminTestServer(t)
+
+	var resp AdminHealthResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "health", nil, "", &resp))
+	require.Len(t, resp.Datasources, 1)
+	assert.Equal(t, "unknown", resp.Datasources[0].Status)
+
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "health", url.Values{"refresh": {"true"}}, "", &resp))
+	assert.Equal(t, "default", resp.Datasources[0].Name)
+	assert.Equal(t, "up", resp.Datasources[0].Status)
+	assert.NotEmpty(t, resp.Datasources[0].LastCheck)
+
+	assert.Equal(t, http.StatusForbidden, adminRequest(t, server, "scoped-key", "GET", "health", nil, "", nil))
+}
+
+func TestAdmin_Panics(t *testing.T) {
+	_, server := newAdminTestServer(t)
+
+	stats := monitor.GetPanicStats()
+	stats.Reset()
+	t.Cleanup(stats.Reset)
+	stats.Record(monitor.PanicReport{Scope: monitor.PanicScopeStatement, User: "reporter", Query: "SELECT 1", Value: "boom", Stack: "goroutine 1"})
+
+	var resp AdminPanicsResponse
+	require.Equal(t, http.StatusOK, adminRequest(t, server, "admin-key", "GET", "panics", nil, "", &resp))
+	assert.Equal(t, int64(1), resp.Statement)
+	require.Len(t, resp.Panics, 1)
+	assert.Equal(t, "SELECT 1", resp.Panics[0].Query)
+	assert.Equal(t, "goroutine 1", resp.Panics[0].Stack)
+	assert.NotZero(t, resp.Panics[0].ID)
+
+	assert.Equal(t, http.StatusForbidden, adminRequest(t, server, "scoped-key", "GET", "panics", nil, "", nil))
+}
+
+func TestWebUIHandler(t *testing.T) {
+	_, server := newAdminTestServer(t)
+
+	resp, err := http.Get(server.URL + "/ui/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "default-src 'self'")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "SQLExec Console")
+
+	resp, err = http.Get(server.URL + "/ui/app.js")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -1,15 +1,21 @@
 package httpapi
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/config"
 	"github.com/kasuganosora/sqlexec/pkg/config_schema"
 )
 
@@ -24,6 +30,9 @@ const (
 
 	// clientCacheTTL is how long the client cache is valid before reloading from disk
 	clientCacheTTL = 30 * time.Second
+
+	// defaultMaxBodySize is the request body limit unless configured otherwise
+	defaultMaxBodySize = 10 * 1024 * 1024
 )
 
 // ClientStore provides access to API client credentials with in-memory caching
@@ -129,3 +138,137 @@ func computeHMAC(secret, message string) string {
 	mac.Write([]byte(message))
 	return hex.EncodeToString(mac.Sum(nil))
 }
+
+// Authenticator authenticates HTTP API requests and resolves their Principal.
+// A request carries either an API key (X-API-Key or Authorization: Bearer),
+// signed with the client's secret unless unsigned keys are allowed, or a JWT
+// bearer token when JWT validation is enabled.
+type Authenticator struct {
+	store       *ClientStore
+	cfg         config.HTTPAuthConfig
+	jwt         *jwtVerifier
+	superCheck  func(user, host string) bool
+	maxBodySize int64
+}
+
+// NewAuthenticator creates an Authenticator for the given auth configuration
+func NewAuthenticator(store *ClientStore, cfg config.HTTPAuthConfig) *Authenticator {
+	a := &Authenticator{store: store, cfg: cfg, maxBodySize: defaultMaxBodySize}
+	if cfg.JWT.Enabled {
+		a.jwt = newJWTVerifier(cfg.JWT)
+	}
+	return a
+}
+
+// SetSuperChecker sets the function deciding whether an ACL user has the
+// SUPER privilege, typically backed by the server's ACL. It is only consulted
+// for principals mapped to an ACL user (auth.acl_users for API clients,
+// jwt.acl_user_claim for tokens), never by the principal's own name.
+func (a *Authenticator) SetSuperChecker(fn func(user, host string) bool) {
+	a.superCheck = fn
+}
+
+// SetMaxBodySize sets the request body limit; requests with larger bodies get 413
+func (a *Authenticator) SetMaxBodySize(n int64) {
+	if n > 0 {
+		a.maxBodySize = n
+	}
+}
+
+// Middleware authenticates the request and stores the principal, the API
+// client (for key based methods) and the request body in the context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer := ""
+		if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			bearer = strings.TrimSpace(auth[7:])
+		}
+
+		// Read body for signature verification
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, a.maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge,
+					fmt.Sprintf("request body too large (limit %d bytes)", a.maxBodySize))
+				return
+			}
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "failed to read request body")
+			return
+		}
+
+		var principal *Principal
+		if a.jwt != nil && looksLikeJWT(bearer) {
+			principal, err = a.authenticateJWT(bearer)
+		} else {
+			principal, err = a.authenticateKey(r, bearer, string(body))
+		}
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, err.Error())
+			return
+		}
+		if a.superCheck != nil && principal.ACLUser != "" {
+			principal.Super = a.superCheck(principal.ACLUser, getClientIP(r))
+		}
+
+		// Store principal, client and body in context
+		ctx := context.WithValue(r.Context(), ctxKeyPrincipal, principal)
+		if principal.Client != nil {
+			ctx = context.WithValue(ctx, ctxKeyClient, principal.Client)
+		}
+		ctx = context.WithValue(ctx, ctxKeyBody, string(body))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticateKey validates an API key and, unless unsigned keys are
+// allowed, the request's HMAC signature. Keys without scopes have full access.
+func (a *Authenticator) authenticateKey(r *http.Request, bearer, body string) (*Principal, error) {
+	apiKey := r.Header.Get(headerAPIKey)
+	if apiKey == "" {
+		apiKey = bearer
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("missing X-API-Key header")
+	}
+
+	client, err := a.store.GetClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	method := AuthMethodSignature
+	timestamp := r.Header.Get(headerTimestamp)
+	nonce := r.Header.Get(headerNonce)
+	signature := r.Header.Get(headerSignature)
+	switch {
+	case timestamp == "" && nonce == "" && signature == "" && a.cfg.AllowUnsignedKeys:
+		method = AuthMethodAPIKey
+	case timestamp == "" || nonce == "" || signature == "":
+		return nil, fmt.Errorf("missing signature headers (X-Timestamp, X-Nonce, X-Signature)")
+	default:
+		if err := ValidateSignature(client.APISecret, r.Method, r.URL.Path, timestamp, nonce, body, signature); err != nil {
+			return nil, fmt.Errorf("signature verification failed: %w", err)
+		}
+	}
+
+	principal := &Principal{Name: client.Name, Method: method, Client: client, ACLUser: a.cfg.ACLUsers[client.Name]}
+	principal.applyScopes(splitScopes(client.Permissions), false)
+	return principal, nil
+}
+
+// authenticateJWT validates a JWT bearer token. Tokens without a read or
+// write scope are read-only.
+func (a *Authenticator) authenticateJWT(token string) (*Principal, error) {
+	claims, err := a.jwt.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+	user, err := a.jwt.user(claims)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+	principal := &Principal{Name: user, Method: AuthMethodJWT, ACLUser: a.jwt.aclUser(claims)}
+	principal.applyScopes(a.jwt.scopes(claims), true)
+	return principal, nil
+}
@@ -0,0 +1,326 @@
+package httpapi
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "jwt-test-secret"
+
+// newAuthTestServer serves the query endpoint behind an Authenticator and
+// adds scoped API clients next to the default test client
+func newAuthTestServer(t *testing.T, env *testEnv, cfg config.HTTPAuthConfig) *httptest.Server {
+	t.Helper()
+	clients := []config_schema.APIClient{
+		env.client,
+		{Name: "reader", APIKey: "reader-key", APISecret: "reader-secret", Enabled: true, Permissions: "read"},
+		{Name: "scoped", APIKey: "scoped-key", APISecret: "scoped-secret", Enabled: true, Permissions: "write, db:default"},
+	}
+	data, err := json.Marshal(clients)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(env.configDir, "api_clients.json"), data, 0600))
+
+	session := env.db.Session()
+	_, err = session.Execute("CREATE TABLE items (id INT PRIMARY KEY, name VARCHAR(32))")
+	require.NoError(t, err)
+	session.Close()
+
+	auth := NewAuthenticator(NewClientStore(env.configDir), cfg)
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/query", auth.Middleware(NewQueryHandler(env.db, env.configDir, env.auditLogger)))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postQuery sends a query with the given headers and returns the status and error message
+func postQuery(t *testing.T, server *httptest.Server, body string, headers map[string]string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest("POST", server.URL+"/api/v1/query", strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var errResp ErrorResponse
+	json.NewDecoder(resp.Body).Decode(&errResp)
+	return resp.StatusCode, errResp.Error
+}
+
+func signedHeaders(apiKey, secret, body string) map[string]string {
+	ts, nonce, sig := signRequest("POST", "/api/v1/query", body, secret)
+	return map[string]string{"X-API-Key": apiKey, "X-Timestamp": ts, "X-Nonce": nonce, "X-Signature": sig}
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// signJWT builds a compact JWT; key is a []byte secret for HS256 or an RSA key for RS256
+func signJWT(t *testing.T, kid string, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	header := map[string]string{"alg": "HS256", "typ": "JWT"}
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		header["alg"] = "RS256"
+	}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func jwtClaims(sub, scope string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   sub,
+		"iss":   "https://issuer.example",
+		"aud":   []string{"sqlexec"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope,
+	}
+}
+
+func TestAuth_UnsignedAPIKeys(t *testing.T) {
+	env := setupTestEnv(t)
+	body := `{"sql":"SELECT * FROM items"}`
+
+	server := newAuthTestServer(t, env, config.HTTPAuthConfig{})
+	status, msg := postQuery(t, server, body, map[string]string{"X-API-Key": env.client.APIKey})
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Contains(t, msg, "missing signature headers")
+
+	env = setupTestEnv(t)
+	server = newAuthTestServer(t, env, config.HTTPAuthConfig{AllowUnsignedKeys: true})
+	status, _ = postQuery(t, server, body, map[string]string{"X-API-Key": env.client.APIKey})
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = postQuery(t, server, body, bearer(env.client.APIKey))
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = postQuery(t, server, body, bearer("unknown-key"))
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// A signature, when present, is still verified
+	headers := signedHeaders(env.client.APIKey, "wrong-secret", body)
+	status, _ = postQuery(t, server, body, headers)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	events := env.auditLogger.GetEventsByType(security.EventTypeAPIRequest)
+	require.NotEmpty(t, events)
+	assert.Equal(t, AuthMethodAPIKey, events[0].Metadata["auth_method"])
+}
+
+func TestAuth_KeyScopes(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newAuthTestServer(t, env, config.HTTPAuthConfig{})
+
+	insert := `{"sql":"INSERT INTO items (id, name) VALUES (1, 'a')"}`
+	status, msg := postQuery(t, server, insert, signedHeaders("reader-key", "reader-secret", insert))
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, msg, "read-only")
+
+	read := `{"sql":"SELECT * FROM items"}`
+	status, _ = postQuery(t, server, read, signedHeaders("reader-key", "reader-secret", read))
+	assert.Equal(t, http.StatusOK, status)
+
+	// db:default restricts the scoped client to the default database
+	status, _ = postQuery(t, server, insert, signedHeaders("scoped-key", "scoped-secret", insert))
+	assert.Equal(t, http.StatusOK, status)
+	for _, body := range []string{
+		`{"sql":"SELECT * FROM items","database":"other"}`,
+		`{"sql":"SELECT * FROM other.items"}`,
+		`{"sql":"SELECT * FROM items i JOIN other.items o ON i.id = o.id"}`,
+		`{"sql":"SHOW TABLES FROM other"}`,
+	} {
+		status, msg = postQuery(t, server, body, signedHeaders("scoped-key", "scoped-secret", body))
+		assert.Equal(t, http.StatusForbidden, status, body)
+		assert.Contains(t, msg, "access to database 'other' is not allowed", body)
+	}
+
+	// Clients without scopes keep full access
+	status, _ = postQuery(t, server, read, signedHeaders(env.client.APIKey, env.client.APISecret, `{"sql":"SELECT * FROM items"}`))
+	assert.Equal(t, http.StatusOK, status)
+
+	events := env.auditLogger.GetEventsByType(security.EventTypeAPIRequest)
+	require.NotEmpty(t, events)
+	assert.Equal(t, "reader", events[0].User)
+	assert.Equal(t, AuthMethodSignature, events[0].Metadata["auth_method"])
+	assert.Equal(t, []string{ScopeRead}, events[0].Metadata["scopes"])
+}
+
+func TestAuth_JWTSharedSecret(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newAuthTestServer(t, env, config.HTTPAuthConfig{JWT: config.JWTConfig{
+		Enabled:  true,
+		Issuer:   "https://issuer.example",
+		Audience: "sqlexec",
+		Secret:   testJWTSecret,
+	}})
+	insert := `{"sql":"INSERT INTO items (id, name) VALUES (1, 'a')"}`
+	read := `{"sql":"SELECT * FROM items"}`
+
+	token := signJWT(t, "", jwtClaims("alice", "openid write db:default"), []byte(testJWTSecret))
+	status, msg := postQuery(t, server, insert, bearer(token))
+	assert.Equal(t, http.StatusOK, status, msg)
+
+	// Tokens without a read or write scope are read-only
+	token = signJWT(t, "", jwtClaims("bob", "openid profile"), []byte(testJWTSecret))
+	status, _ = postQuery(t, server, read, bearer(token))
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = postQuery(t, server, insert, bearer(token))
+	assert.Equal(t, http.StatusForbidden, status)
+
+	expired := jwtClaims("alice", "write")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongAudience := jwtClaims("alice", "write")
+	wrongAudience["aud"] = "other"
+	wrongIssuer := jwtClaims("alice", "write")
+	wrongIssuer["iss"] = "https://evil.example"
+	noSubject := jwtClaims("", "write")
+	for name, token := range map[string]string{
+		"token expired":               signJWT(t, "", expired, []byte(testJWTSecret)),
+		"unexpected token audience":   signJWT(t, "", wrongAudience, []byte(testJWTSecret)),
+		"unexpected token issuer":     signJWT(t, "", wrongIssuer, []byte(testJWTSecret)),
+		"token has no sub claim":      signJWT(t, "", noSubject, []byte(testJWTSecret)),
+		"invalid token signature":     signJWT(t, "", jwtClaims("alice", "write"), []byte("other-secret")),
+		"unsupported token algorithm": "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"x"}`)) + ".",
+	} {
+		status, msg := postQuery(t, server, read, bearer(token))
+		assert.Equal(t, http.StatusUnauthorized, status, name)
+		assert.Contains(t, msg, name)
+	}
+
+	events := env.auditLogger.GetEventsByType(security.EventTypeAPIRequest)
+	require.NotEmpty(t, events)
+	assert.Equal(t, "alice", events[0].User)
+	assert.Equal(t, AuthMethodJWT, events[0].Metadata["auth_method"])
+	assert.Equal(t, []string{ScopeWrite, "db:default"}, events[0].Metadata["scopes"])
+}
+
+func TestAuth_JWTWithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	fetches := 0
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	env := setupTestEnv(t)
+	server := newAuthTestServer(t, env, config.HTTPAuthConfig{JWT: config.JWTConfig{
+		Enabled:    true,
+		JWKSURL:    jwks.URL,
+		UserClaim:  "email",
+		ScopeClaim: "scp",
+	}})
+	read := `{"sql":"SELECT * FROM items"}`
+
+	claims := map[string]interface{}{
+		"email": "carol@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scp":   []string{"read"},
+	}
+	for i := 0; i < 2; i++ {
+		status, msg := postQuery(t, server, read, bearer(signJWT(t, "key-1", claims, key)))
+		assert.Equal(t, http.StatusOK, status, msg)
+	}
+	assert.Equal(t, 1, fetches, "signing keys should be cached")
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	status, msg := postQuery(t, server, read, bearer(signJWT(t, "key-1", claims, other)))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Contains(t, msg, "invalid token signature")
+	status, msg = postQuery(t, server, read, bearer(signJWT(t, "key-2", claims, key)))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Contains(t, msg, "unknown token signing key")
+
+	// HS256 tokens are rejected when no shared secret is configured
+	status, _ = postQuery(t, server, read, bearer(signJWT(t, "", claims, []byte(testJWTSecret))))
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuth_SuperChecker(t *testing.T) {
+	env := setupTestEnv(t)
+	env.db.SetReadOnly(true)
+	cfg := config.HTTPAuthConfig{JWT: config.JWTConfig{Enabled: true, Secret: testJWTSecret, ACLUserClaim: "db_user"}}
+	newServer := func(cfg config.HTTPAuthConfig) *httptest.Server {
+		auth := NewAuthenticator(NewClientStore(env.configDir), cfg)
+		auth.SetSuperChecker(func(user, host string) bool { return user == "root" })
+		mux := http.NewServeMux()
+		mux.Handle("/api/v1/query", auth.Middleware(NewQueryHandler(env.db, env.configDir, env.auditLogger)))
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	// Principals are not looked up in the ACL by their own name
+	server := newServer(cfg)
+	body := `{"sql":"CREATE TABLE super_test (id INT)"}`
+	claims := map[string]interface{}{
+		"sub":   "root",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "read write",
+	}
+	status, msg := postQuery(t, server, body, bearer(signJWT(t, "", claims, []byte(testJWTSecret))))
+	assert.NotEqual(t, http.StatusOK, status)
+	assert.Contains(t, msg, "read-only")
+
+	// SUPER of the ACL user named by the configured claim bypasses the global read_only
+	claims["sub"] = "alice"
+	claims["db_user"] = "root"
+	status, msg = postQuery(t, server, body, bearer(signJWT(t, "", claims, []byte(testJWTSecret))))
+	assert.Equal(t, http.StatusOK, status, msg)
+
+	// API clients get ACL privileges only through auth.acl_users
+	body = `{"sql":"CREATE TABLE super_test2 (id INT)"}`
+	status, msg = postQuery(t, server, body, signedHeaders(env.client.APIKey, env.client.APISecret, body))
+	assert.NotEqual(t, http.StatusOK, status)
+	assert.Contains(t, msg, "read-only")
+
+	cfg.ACLUsers = map[string]string{env.client.Name: "root"}
+	server = newServer(cfg)
+	status, msg = postQuery(t, server, body, signedHeaders(env.client.APIKey, env.client.APISecret, body))
+	assert.Equal(t, http.StatusOK, status, msg)
+}
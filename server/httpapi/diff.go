@@ -0,0 +1,124 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/tablediff"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// DiffHandler handles POST /api/v1/diff: two tables are compared by key and the
+// differences are streamed back as JSON Lines, one per line, followed by a
+// DiffEnd line with the summary counts
+type DiffHandler struct {
+	db          *api.DB
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+	auditLogger *security.AuditLogger
+}
+
+// NewDiffHandler creates a new DiffHandler
+func NewDiffHandler(db *api.DB, auditLogger *security.AuditLogger) *DiffHandler {
+	return &DiffHandler{db: db, auditLogger: auditLogger}
+}
+
+// SetVirtualDBRegistry sets the virtual database registry
+func (h *DiffHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
+	h.vdbRegistry = registry
+}
+
+// ServeHTTP compares the tables and streams the differences
+func (h *DiffHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	var req DiffRequest
+	if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Left == "" || req.Right == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "left and right fields are required")
+		return
+	}
+
+	start := time.Now()
+	clientIP := getClientIP(r)
+	req.Database = principal.defaultDatabase(req.Database)
+	traceID := req.TraceID
+	if traceID == "" {
+		traceID = r.Header.Get("X-Trace-ID")
+	}
+	if traceID == "" {
+		traceID = fmt.Sprintf("http-%d", time.Now().UnixMilli())
+	}
+	statement := fmt.Sprintf("DIFF TABLE %s, %s", req.Left, req.Right)
+	logRequest := func(success bool) {
+		if h.auditLogger != nil {
+			h.auditLogger.LogAPIRequestBy(traceID, principal.auditPrincipal(), clientIP, r.Method, r.URL.Path, statement, req.Database, time.Since(start).Milliseconds(), success)
+		}
+	}
+
+	session := h.db.Session()
+	defer session.Close()
+	if h.vdbRegistry != nil {
+		session.SetVirtualDBRegistry(h.vdbRegistry)
+	}
+	principal.configureSession(session)
+	session.SetTraceID(traceID)
+	if req.Database != "" {
+		session.SetCurrentDB(req.Database)
+	}
+
+	// Both tables are read in full, so check them like SELECT * FROM left, right
+	if err := principal.checkStatement(fmt.Sprintf("SELECT * FROM %s, %s", req.Left, req.Right), session.GetCurrentDB()); err != nil {
+		logRequest(false)
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, err.Error())
+		return
+	}
+
+	// The status line is sent with the first difference; errors before that get a regular JSON error
+	started := false
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	begin := func() {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+	summary, err := session.DiffTables(req.Left, req.Right, req.Key, func(d tablediff.Difference) error {
+		begin()
+		if err := encoder.Encode(d); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	logRequest(err == nil)
+	if err != nil && !started {
+		writeStatementError(w, err.Error(), err)
+		return
+	}
+	begin()
+	if err != nil {
+		encoder.Encode(DiffEnd{Error: err.Error()})
+		return
+	}
+	encoder.Encode(DiffEnd{Summary: summary})
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/tablediff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiffTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	session := env.db.Session()
+	defer session.Close()
+	for _, sql := range []string{
+		"CREATE TABLE source (id INT PRIMARY KEY, name VARCHAR(50))",
+		"CREATE TABLE target (id INT PRIMARY KEY, name VARCHAR(50))",
+		"INSERT INTO source (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')",
+		"INSERT INTO target (id, name) VALUES (1, 'a'), (2, 'B'), (4, 'd')",
+	} {
+		_, err := session.Execute(sql)
+		require.NoError(t, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/diff", AuthMiddleware(NewClientStore(env.configDir))(NewDiffHandler(env.db, env.auditLogger)))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postDiff posts a diff request and returns the response with its body fully read
+func postDiff(t *testing.T, server *httptest.Server, env *testEnv, req DiffRequest) (*http.Response, []byte) {
+	t.Helper()
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	body := string(data)
+	ts, nonce, sig := signRequest("POST", "/api/v1/diff", body, env.client.APISecret)
+	httpReq, err := http.NewRequest("POST", server.URL+"/api/v1/diff", strings.NewReader(body))
+	require.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", env.client.APIKey)
+	httpReq.Header.Set("X-Timestamp", ts)
+	httpReq.Header.Set("X-Nonce", nonce)
+	httpReq.Header.Set("X-Signature", sig)
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, out
+}
+
+func TestDiff_StreamsDifferencesAndSummary(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newDiffTestServer(t, env)
+
+	resp, body := postDiff(t, server, env, DiffRequest{Left: "source", Right: "target"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 4)
+
+	var diffs []tablediff.Difference
+	for _, line := range lines[:3] {
+		var d tablediff.Difference
+		require.NoError(t, json.Unmarshal([]byte(line), &d))
+		diffs = append(diffs, d)
+	}
+	assert.Equal(t, tablediff.KindChanged, diffs[0].Kind)
+	assert.Equal(t, "name", diffs[0].Column)
+	assert.Equal(t, "b", diffs[0].Left)
+	assert.Equal(t, "B", diffs[0].Right)
+	assert.Equal(t, tablediff.KindMissing, diffs[1].Kind)
+	assert.EqualValues(t, 3, diffs[1].Key["id"])
+	assert.Equal(t, tablediff.KindExtra, diffs[2].Kind)
+	assert.EqualValues(t, 4, diffs[2].Key["id"])
+
+	var end DiffEnd
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &end))
+	require.NotNil(t, end.Summary)
+	assert.EqualValues(t, 1, end.Summary.Matched)
+	assert.EqualValues(t, 1, end.Summary.Changed)
+	assert.EqualValues(t, 1, end.Summary.Missing)
+	assert.EqualValues(t, 1, end.Summary.Extra)
+
+	// 相同的表只有汇总行
+	resp, body = postDiff(t, server, env, DiffRequest{Left: "source", Right: "source", Key: []string{"id"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	require.NoError(t, json.Unmarshal(body, &end))
+	assert.True(t, end.Summary.Identical())
+}
+
+func TestDiff_Errors(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newDiffTestServer(t, env)
+
+	resp, body := postDiff(t, server, env, DiffRequest{Left: "source"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(body))
+
+	resp, body = postDiff(t, server, env, DiffRequest{Left: "source", Right: "missing_table"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(body))
+
+	resp, body = postDiff(t, server, env, DiffRequest{Left: "source", Right: "target", Key: []string{"nope"}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), "nope")
+}
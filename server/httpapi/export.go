@@ -0,0 +1,141 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/dataexport"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// Trailers of POST /api/v1/export. The file is streamed, so a failure after
+// the first bytes were sent can only be reported in a trailer.
+const (
+	exportRowsTrailer  = "X-Export-Rows"
+	exportErrorTrailer = "X-Export-Error"
+)
+
+// ExportHandler handles POST /api/v1/export: the query result is streamed
+// back as a CSV, JSON Lines or Parquet file
+type ExportHandler struct {
+	db          *api.DB
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+	auditLogger *security.AuditLogger
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(db *api.DB, auditLogger *security.AuditLogger) *ExportHandler {
+	return &ExportHandler{db: db, auditLogger: auditLogger}
+}
+
+// SetVirtualDBRegistry sets the virtual database registry
+func (h *ExportHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
+	h.vdbRegistry = registry
+}
+
+// ServeHTTP runs the query and streams the result as a file download
+func (h *ExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ExportRequest
+	if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.SQL == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "sql field is required")
+		return
+	}
+	if !isReadStatement(req.SQL) {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "only queries can be exported")
+		return
+	}
+	opts, err := dataexport.ParseOptions(req.Format, req.Options)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	start := time.Now()
+	clientIP := getClientIP(r)
+	req.Database = principal.defaultDatabase(req.Database)
+	traceID := req.TraceID
+	if traceID == "" {
+		traceID = r.Header.Get("X-Trace-ID")
+	}
+	if traceID == "" {
+		traceID = fmt.Sprintf("http-%d", time.Now().UnixMilli())
+	}
+	logRequest := func(success bool) {
+		if h.auditLogger != nil {
+			h.auditLogger.LogAPIRequestBy(traceID, principal.auditPrincipal(), clientIP, r.Method, r.URL.Path, req.SQL, req.Database, time.Since(start).Milliseconds(), success)
+		}
+	}
+
+	session := h.db.Session()
+	defer session.Close()
+	if h.vdbRegistry != nil {
+		session.SetVirtualDBRegistry(h.vdbRegistry)
+	}
+	principal.configureSession(session)
+	session.SetTraceID(traceID)
+	if req.Database != "" {
+		session.SetCurrentDB(req.Database)
+	}
+
+	if err := principal.checkStatement(req.SQL, session.GetCurrentDB()); err != nil {
+		logRequest(false)
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, err.Error())
+		return
+	}
+
+	query, err := session.Query(req.SQL)
+	if err != nil {
+		logRequest(false)
+		writeStatementError(w, "query failed", err)
+		return
+	}
+	defer query.Close()
+
+	header := w.Header()
+	header.Set("Trailer", exportRowsTrailer+", "+exportErrorTrailer)
+	header.Set("Content-Type", opts.Format.ContentType())
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export%s"`, opts.Format.Extension()))
+	writer, err := dataexport.NewWriter(w, query.Columns(), opts)
+	if err != nil {
+		header.Del("Trailer")
+		header.Del("Content-Disposition")
+		logRequest(false)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+
+	var rows int64
+	for err == nil && query.Next() {
+		if err = writer.WriteRow(query.Row()); err == nil {
+			rows++
+		}
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	header.Set(exportRowsTrailer, strconv.FormatInt(rows, 10))
+	if err != nil {
+		header.Set(exportErrorTrailer, err.Error())
+	}
+	logRequest(err == nil)
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	session := env.db.Session()
+	defer session.Close()
+	_, err := session.Execute("CREATE TABLE exports (id INT PRIMARY KEY, name VARCHAR(50))")
+	require.NoError(t, err)
+	_, err = session.Execute("INSERT INTO exports (id, name) VALUES (1, 'a'), (2, 'b, c'), (3, NULL)")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/export", AuthMiddleware(NewClientStore(env.configDir))(NewExportHandler(env.db, env.auditLogger)))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postExport posts an export request and returns the response with its body fully read
+func postExport(t *testing.T, server *httptest.Server, env *testEnv, req ExportRequest) (*http.Response, []byte) {
+	t.Helper()
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	body := string(data)
+	ts, nonce, sig := signRequest("POST", "/api/v1/export", body, env.client.APISecret)
+	httpReq, err := http.NewRequest("POST", server.URL+"/api/v1/export", strings.NewReader(body))
+	require.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", env.client.APIKey)
+	httpReq.Header.Set("X-Timestamp", ts)
+	httpReq.Header.Set("X-Nonce", nonce)
+	httpReq.Header.Set("X-Signature", sig)
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, out
+}
+
+func TestExport_Formats(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newExportTestServer(t, env)
+
+	resp, body := postExport(t, server, env, ExportRequest{SQL: "SELECT id, name FROM exports ORDER BY id"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="export.csv"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "id,name\n1,a\n2,\"b, c\"\n3,\\N\n", string(body))
+	assert.Equal(t, "3", resp.Trailer.Get(exportRowsTrailer))
+	assert.Empty(t, resp.Trailer.Get(exportErrorTrailer))
+
+	resp, body = postExport(t, server, env, ExportRequest{
+		SQL:     "SELECT name FROM exports WHERE id < 3 ORDER BY id",
+		Format:  "csv",
+		Options: map[string]string{"header": "false", "delimiter": "|"},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "a\nb, c\n", string(body))
+
+	resp, body = postExport(t, server, env, ExportRequest{SQL: "SELECT id, name FROM exports WHERE id = 1", Format: "json"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"id":1,"name":"a"}`+"\n", string(body))
+
+	resp, body = postExport(t, server, env, ExportRequest{SQL: "SELECT id, name FROM exports ORDER BY id", Format: "parquet"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, `attachment; filename="export.parquet"`, resp.Header.Get("Content-Disposition"))
+	type record struct {
+		ID   int64   `parquet:"id"`
+		Name *string `parquet:"name"`
+	}
+	rows, err := pq.Read[record](bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "b, c", *rows[1].Name)
+	assert.Nil(t, rows[2].Name)
+}
+
+func TestExport_InvalidRequests(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newExportTestServer(t, env)
+
+	for _, req := range []ExportRequest{
+		{},
+		{SQL: "DELETE FROM exports"},
+		{SQL: "SELECT * FROM exports", Format: "xml"},
+		{SQL: "SELECT * FROM exports", Format: "json", Options: map[string]string{"delimiter": ";"}},
+	} {
+		resp, body := postExport(t, server, env, req)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, req.SQL)
+		var out map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &out))
+		assert.Equal(t, ErrCodeInvalidRequest, out["error_code"], req.SQL)
+	}
+
+	resp, body := postExport(t, server, env, ExportRequest{SQL: "SELECT * FROM missing_table"})
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode, string(body))
+}
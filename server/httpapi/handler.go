@@ -41,19 +41,13 @@ func (h *QueryHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseReg
 // ServeHTTP handles POST /api/v1/query
 func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{
-			Error: "method not allowed",
-			Code:  http.StatusMethodNotAllowed,
-		})
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	client := GetClientFromContext(r.Context())
-	if client == nil {
-		writeJSON(w, http.StatusUnauthorized, ErrorResponse{
-			Error: "unauthorized",
-			Code:  http.StatusUnauthorized,
-		})
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
 		return
 	}
 
@@ -61,23 +55,18 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	bodyStr := GetBodyFromContext(r.Context())
 	var req QueryRequest
 	if err := json.Unmarshal([]byte(bodyStr), &req); err != nil {
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{
-			Error: "invalid request body: " + err.Error(),
-			Code:  http.StatusBadRequest,
-		})
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
 		return
 	}
 
 	if req.SQL == "" {
-		writeJSON(w, http.StatusBadRequest, ErrorResponse{
-			Error: "sql field is required",
-			Code:  http.StatusBadRequest,
-		})
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "sql field is required")
 		return
 	}
 
 	start := time.Now()
 	clientIP := getClientIP(r)
+	req.Database = principal.defaultDatabase(req.Database)
 
 	// Resolve trace-id: request body > X-Trace-ID header > auto-generate
 	traceID := req.TraceID
@@ -94,33 +83,35 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if h.vdbRegistry != nil {
 		session.SetVirtualDBRegistry(h.vdbRegistry)
 	}
-	session.SetUser(client.Name)
+	principal.configureSession(session)
 	session.SetTraceID(traceID)
 	if req.Database != "" {
 		session.SetCurrentDB(req.Database)
 	}
 
-	// Determine if this is a read or write query
-	sqlUpper := strings.TrimSpace(strings.ToUpper(req.SQL))
-	isRead := strings.HasPrefix(sqlUpper, "SELECT") ||
-		strings.HasPrefix(sqlUpper, "SHOW") ||
-		strings.HasPrefix(sqlUpper, "DESCRIBE") ||
-		strings.HasPrefix(sqlUpper, "DESC ") ||
-		strings.HasPrefix(sqlUpper, "EXPLAIN")
+	if err := principal.checkStatement(req.SQL, session.GetCurrentDB()); err != nil {
+		h.logRequest(traceID, principal, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, time.Since(start).Milliseconds(), false)
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, err.Error())
+		return
+	}
 
-	if isRead {
+	if isReadStatement(req.SQL) {
 		query, err := session.Query(req.SQL)
 		if err != nil {
 			duration := time.Since(start).Milliseconds()
-			h.logRequest(traceID, client.Name, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, false)
-			writeJSON(w, http.StatusBadRequest, ErrorResponse{
-				Error: "query failed",
-				Code:  http.StatusBadRequest,
-			})
+			h.logRequest(traceID, principal, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, false)
+			writeStatementError(w, "query failed", err)
 			return
 		}
 		defer query.Close()
 
+		// SELECT ... INTO OUTFILE writes a file and returns no rows
+		if result := query.ExecResult(); result != nil {
+			h.logRequest(traceID, principal, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, time.Since(start).Milliseconds(), true)
+			writeJSON(w, http.StatusOK, ExecResponse{AffectedRows: result.RowsAffected})
+			return
+		}
+
 		rows := make([]domain.Row, 0, 64)
 		truncated := false
 		for query.Next() {
@@ -132,7 +123,7 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		duration := time.Since(start).Milliseconds()
-		h.logRequest(traceID, client.Name, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, true)
+		h.logRequest(traceID, principal, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, true)
 
 		writeJSON(w, http.StatusOK, QueryResponse{
 			Columns:   query.Columns(),
@@ -144,16 +135,13 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		result, err := session.Execute(req.SQL)
 		if err != nil {
 			duration := time.Since(start).Milliseconds()
-			h.logRequest(traceID, client.Name, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, false)
-			writeJSON(w, http.StatusBadRequest, ErrorResponse{
-				Error: "execute failed",
-				Code:  http.StatusBadRequest,
-			})
+			h.logRequest(traceID, principal, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, false)
+			writeStatementError(w, "execute failed", err)
 			return
 		}
 
 		duration := time.Since(start).Milliseconds()
-		h.logRequest(traceID, client.Name, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, true)
+		h.logRequest(traceID, principal, clientIP, r.Method, r.URL.Path, req.SQL, req.Database, duration, true)
 
 		writeJSON(w, http.StatusOK, ExecResponse{
 			AffectedRows: result.RowsAffected,
@@ -161,9 +149,20 @@ func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *QueryHandler) logRequest(traceID, clientName, ip, method, path, sql, database string, duration int64, success bool) {
+// isReadStatement reports whether the statement returns a result set
+func isReadStatement(sql string) bool {
+	sqlUpper := strings.TrimSpace(strings.ToUpper(sql))
+	return strings.HasPrefix(sqlUpper, "SELECT") ||
+		strings.HasPrefix(sqlUpper, "SHOW") ||
+		strings.HasPrefix(sqlUpper, "DESCRIBE") ||
+		strings.HasPrefix(sqlUpper, "DESC ") ||
+		strings.HasPrefix(sqlUpper, "EXPLAIN") ||
+		strings.HasPrefix(sqlUpper, "DIFF ")
+}
+
+func (h *QueryHandler) logRequest(traceID string, principal *Principal, ip, method, path, sql, database string, duration int64, success bool) {
 	if h.auditLogger != nil {
-		h.auditLogger.LogAPIRequest(traceID, clientName, ip, method, path, sql, database, duration, success)
+		h.auditLogger.LogAPIRequestBy(traceID, principal.auditPrincipal(), ip, method, path, sql, database, duration, success)
 	}
 }
 
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/application"
+)
+
+// HealthzHandler handles GET /healthz: liveness, 200 as long as the process
+// serves requests
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok", Version: "1.0.0"})
+}
+
+// ReadyzHandler handles GET /readyz: readiness, 200 when every datasource
+// passed its last health probe and 503 otherwise. Datasources never probed
+// are probed on the spot, so the endpoint works without the background
+// prober. Probe errors are only reported to SUPER principals by
+// /api/v1/admin/health, as they may reveal backend addresses.
+type ReadyzHandler struct {
+	db *api.DB
+}
+
+// NewReadyzHandler creates a readiness handler for db
+func NewReadyzHandler(db *api.DB) *ReadyzHandler {
+	return &ReadyzHandler{db: db}
+}
+
+func (h *ReadyzHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	health := h.db.DatasourceHealth()
+	for _, ds := range health {
+		if ds.Status == application.HealthUnknown {
+			health = h.db.CheckDatasources(r.Context())
+			break
+		}
+	}
+
+	resp := ReadyResponse{Status: "ready", Datasources: make([]DatasourceStatus, 0, len(health))}
+	for _, ds := range health {
+		if ds.Status != application.HealthUp {
+			resp.Status = "not ready"
+		}
+		status := datasourceStatus(ds)
+		status.LastError = ""
+		resp.Datasources = append(resp.Datasources, status)
+	}
+
+	code := http.StatusOK
+	if resp.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, resp)
+}
+
+// datasourceStatus converts a datasource health to its JSON form
+func datasourceStatus(h api.DatasourceHealth) DatasourceStatus {
+	status := DatasourceStatus{
+		Name:                h.Name,
+		Type:                string(h.Type),
+		Status:              h.Status,
+		LatencyMs:           float64(h.Latency) / float64(time.Millisecond),
+		ConsecutiveFailures: h.ConsecutiveFailures,
+		LastError:           h.LastError,
+	}
+	if !h.LastCheck.IsZero() {
+		status.LastCheck = h.LastCheck.Format(time.RFC3339)
+	}
+	return status
+}
@@ -0,0 +1,216 @@
+package httpapi
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// importOptionParams are the query parameters passed through as IMPORT DATA WITH options
+var importOptionParams = []string{"header", "delimiter", "array_root", "on_error", "max_errors", "workers", "batch_size"}
+
+// ImportHandler handles POST /api/v1/import: the request body is a CSV, JSON
+// or Parquet file that is loaded into a table with IMPORT DATA
+type ImportHandler struct {
+	db          *api.DB
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+	auditLogger *security.AuditLogger
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(db *api.DB, auditLogger *security.AuditLogger) *ImportHandler {
+	return &ImportHandler{db: db, auditLogger: auditLogger}
+}
+
+// SetVirtualDBRegistry sets the virtual database registry
+func (h *ImportHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
+	h.vdbRegistry = registry
+}
+
+// ServeHTTP imports the uploaded file
+func (h *ImportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	database := principal.defaultDatabase(query.Get("database"))
+	table, tableDB, err := importTable(query.Get("table"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	format, err := importFormat(query.Get("format"), r.Header.Get("Content-Type"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	columns, err := importColumns(query.Get("columns"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	body := GetBodyFromContext(r.Context())
+	if body == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "request body is empty")
+		return
+	}
+
+	// IMPORT DATA is not understood by the scope checker, so the target
+	// database is checked directly
+	targetDB := database
+	if tableDB != "" {
+		targetDB = tableDB
+	}
+	if !principal.AllowsDatabase(targetDB) {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, fmt.Sprintf("access to database '%s' is not allowed", targetDB))
+		return
+	}
+
+	file, err := os.CreateTemp("", "sqlexec-import-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to store upload: "+err.Error())
+		return
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to store upload: "+err.Error())
+		return
+	}
+
+	sql := buildImportSQL(file.Name(), format, table, columns, query)
+	start := time.Now()
+	clientIP := getClientIP(r)
+	traceID := r.Header.Get("X-Trace-ID")
+	if traceID == "" {
+		traceID = fmt.Sprintf("http-%d", time.Now().UnixMilli())
+	}
+
+	session := h.db.Session()
+	defer session.Close()
+	if h.vdbRegistry != nil {
+		session.SetVirtualDBRegistry(h.vdbRegistry)
+	}
+	principal.configureSession(session)
+	session.SetTraceID(traceID)
+	if database != "" {
+		session.SetCurrentDB(database)
+	}
+
+	result, err := session.Execute(sql)
+	if h.auditLogger != nil {
+		h.auditLogger.LogAPIRequestBy(traceID, principal.auditPrincipal(), clientIP, r.Method, r.URL.Path, sql, database, time.Since(start).Milliseconds(), err == nil)
+	}
+	if err != nil {
+		writeStatementError(w, "import failed", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ImportResponse{
+		AffectedRows: result.RowsAffected,
+		Info:         result.Info,
+		Warnings:     result.Warnings,
+	})
+}
+
+// importTable validates the table parameter and returns it quoted, together
+// with its database qualifier if any
+func importTable(name string) (quoted, database string, err error) {
+	if name == "" {
+		return "", "", fmt.Errorf("table parameter is required")
+	}
+	if strings.Contains(name, "`") {
+		return "", "", fmt.Errorf("invalid table name %q", name)
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return "", "", fmt.Errorf("invalid table name %q", name)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return "", "", fmt.Errorf("invalid table name %q", name)
+		}
+	}
+	if len(parts) == 2 {
+		database = parts[0]
+	}
+	return security.QuoteQualifiedIdentifier(parts...), database, nil
+}
+
+// importFormat returns the format parameter, or infers it from the content type
+func importFormat(format, contentType string) (string, error) {
+	if format != "" {
+		switch f := strings.ToUpper(format); f {
+		case "CSV", "JSON", "PARQUET":
+			return f, nil
+		}
+		return "", fmt.Errorf("unsupported format %q (CSV, JSON, PARQUET)", format)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return "CSV", nil
+	case "application/json", "application/x-ndjson":
+		return "JSON", nil
+	case "application/vnd.apache.parquet", "application/x-parquet":
+		return "PARQUET", nil
+	}
+	return "", fmt.Errorf("format parameter is required")
+}
+
+// importColumns splits the comma separated columns parameter
+func importColumns(list string) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var columns []string
+	for _, col := range strings.Split(list, ",") {
+		col = strings.TrimSpace(col)
+		if col == "" || strings.ContainsAny(col, "`()") {
+			return nil, fmt.Errorf("invalid column name %q", col)
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// buildImportSQL builds the IMPORT DATA statement for the stored upload
+func buildImportSQL(path, format, table string, columns []string, query map[string][]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "IMPORT DATA INFILE '%s' FORMAT %s INTO TABLE %s", strings.ReplaceAll(path, "'", "''"), format, table)
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, col := range columns {
+			quoted[i] = security.QuoteIdentifier(col)
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(quoted, ", "))
+	}
+	var options []string
+	for _, name := range importOptionParams {
+		if values, ok := query[name]; ok && len(values) > 0 {
+			options = append(options, fmt.Sprintf("%s = '%s'", name, strings.ReplaceAll(values[0], "'", "''")))
+		}
+	}
+	if len(options) > 0 {
+		fmt.Fprintf(&b, " WITH (%s)", strings.Join(options, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImportTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	clients := []config_schema.APIClient{
+		env.client,
+		{Name: "reader", APIKey: "reader-key", APISecret: "reader-secret", Enabled: true, Permissions: "read"},
+		{Name: "scoped", APIKey: "scoped-key", APISecret: "scoped-secret", Enabled: true, Permissions: "write, db:default"},
+	}
+	data, err := json.Marshal(clients)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(env.configDir, "api_clients.json"), data, 0600))
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/import", AuthMiddleware(NewClientStore(env.configDir))(NewImportHandler(env.db, env.auditLogger)))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postImport uploads body to /api/v1/import; the query string is not part of the signature
+func postImport(t *testing.T, server *httptest.Server, apiKey, secret, query, contentType, body string) (int, map[string]interface{}) {
+	t.Helper()
+	ts, nonce, sig := signRequest("POST", "/api/v1/import", body, secret)
+	req, err := http.NewRequest("POST", server.URL+"/api/v1/import?"+query, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("X-Timestamp", ts)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", sig)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var out map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	return resp.StatusCode, out
+}
+
+func TestImport_Upload(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newImportTestServer(t, env)
+	key, secret := env.client.APIKey, env.client.APISecret
+
+	status, out := postImport(t, server, key, secret, "table=uploads&on_error=collect", "text/csv",
+		"id,name\n1,a\n2,b\n3\n")
+	require.Equal(t, http.StatusOK, status, out)
+	assert.EqualValues(t, 2, out["affected_rows"])
+	assert.Equal(t, "Records: 3  Deleted: 0  Skipped: 1  Warnings: 1", out["info"])
+	require.Len(t, out["warnings"], 1)
+
+	// The format parameter overrides the content type
+	status, out = postImport(t, server, key, secret, "table=default.events&format=json", "application/octet-stream",
+		"{\"id\": 1}\n{\"id\": 2}\n")
+	require.Equal(t, http.StatusOK, status, out)
+	assert.EqualValues(t, 2, out["affected_rows"])
+
+	session := env.db.Session()
+	defer session.Close()
+	rows, err := session.QueryAll("SELECT name FROM uploads WHERE id = 2")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "b", rows[0]["name"])
+
+	for _, query := range []string{
+		"format=csv",
+		"table=a`b&format=csv",
+		"table=a.b.c&format=csv",
+		"table=t&format=xml",
+		"table=t",
+		"table=t&format=csv&columns=a,b)",
+	} {
+		status, out = postImport(t, server, key, secret, query, "application/octet-stream", "id\n1\n")
+		assert.Equal(t, http.StatusBadRequest, status, query)
+		assert.Equal(t, ErrCodeInvalidRequest, out["error_code"], query)
+	}
+
+	status, out = postImport(t, server, key, secret, "table=broken&format=csv&header=maybe", "text/csv", "id\n1\n")
+	assert.Equal(t, http.StatusBadRequest, status, out)
+}
+
+func TestImport_Permissions(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newImportTestServer(t, env)
+
+	status, out := postImport(t, server, "reader-key", "reader-secret", "table=t1", "text/csv", "id\n1\n")
+	assert.Equal(t, http.StatusForbidden, status, out)
+
+	status, out = postImport(t, server, "scoped-key", "scoped-secret", "table=other.t1", "text/csv", "id\n1\n")
+	assert.Equal(t, http.StatusForbidden, status, out)
+	assert.Contains(t, out["error"], "access to database 'other' is not allowed")
+
+	status, out = postImport(t, server, "scoped-key", "scoped-secret", "table=t1&columns=code&header=false", "text/csv", "x\ny\n")
+	require.Equal(t, http.StatusOK, status, out)
+	assert.EqualValues(t, 2, out["affected_rows"])
+}
@@ -0,0 +1,216 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// maxFinishedJobs bounds how many finished jobs are kept for status queries
+const maxFinishedJobs = 100
+
+// Job states
+const (
+	JobStateRunning   = "running"
+	JobStateSucceeded = "succeeded"
+	JobStateFailed    = "failed"
+)
+
+// JobProgress is the latest progress reported by a running statement
+type JobProgress struct {
+	Stage    int     `json:"stage"`
+	MaxStage int     `json:"max_stage"`
+	Percent  float64 `json:"percent"`
+	Done     int64   `json:"done"`
+	Total    int64   `json:"total"`
+	Info     string  `json:"info,omitempty"`
+}
+
+// Job is a DDL or DML statement (CREATE INDEX, OPTIMIZE TABLE, bulk INSERT...)
+// executed asynchronously via POST /api/v1/jobs
+type Job struct {
+	ID           string       `json:"id"`
+	SQL          string       `json:"sql"`
+	Database     string       `json:"database,omitempty"`
+	State        string       `json:"state"`
+	Progress     *JobProgress `json:"progress,omitempty"`
+	AffectedRows int64        `json:"affected_rows"`
+	Error        string       `json:"error,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	FinishedAt   *time.Time   `json:"finished_at,omitempty"`
+
+	owner     string     // only the submitting principal can see the job
+	principal *Principal // the job runs as the submitting principal
+}
+
+// JobHandler handles POST /api/v1/jobs and GET /api/v1/jobs/{id}
+type JobHandler struct {
+	db          *api.DB
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+	auditLogger *security.AuditLogger
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	finished []string // IDs of finished jobs, oldest first
+	nextID   int64
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(db *api.DB, auditLogger *security.AuditLogger) *JobHandler {
+	return &JobHandler{
+		db:          db,
+		auditLogger: auditLogger,
+		jobs:        make(map[string]*Job),
+	}
+}
+
+// SetVirtualDBRegistry sets the virtual database registry
+func (h *JobHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
+	h.vdbRegistry = registry
+}
+
+// ServeHTTP dispatches job submission and status requests
+func (h *JobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/jobs"), "/")
+	switch {
+	case r.Method == http.MethodPost && id == "":
+		h.submit(w, r, principal)
+	case r.Method == http.MethodGet && id != "":
+		h.status(w, id, principal.owner())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// submit starts the statement in the background and returns the job
+func (h *JobHandler) submit(w http.ResponseWriter, r *http.Request, principal *Principal) {
+	var req QueryRequest
+	if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.SQL == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "sql field is required")
+		return
+	}
+	req.Database = principal.defaultDatabase(req.Database)
+	if err := principal.checkStatement(req.SQL, req.Database); err != nil {
+		writeError(w, http.StatusForbidden, ErrCodeForbidden, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	h.nextID++
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d", h.nextID),
+		SQL:       req.SQL,
+		Database:  req.Database,
+		State:     JobStateRunning,
+		CreatedAt: time.Now(),
+		owner:     principal.owner(),
+		principal: principal,
+	}
+	h.jobs[job.ID] = job
+	snapshot := *job
+	h.mu.Unlock()
+
+	traceID := req.TraceID
+	if traceID == "" {
+		traceID = r.Header.Get("X-Trace-ID")
+	}
+	if traceID == "" {
+		traceID = "http-" + job.ID
+	}
+	go h.run(job, traceID, getClientIP(r))
+
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+// run executes the job's statement, mirroring its progress into the job
+func (h *JobHandler) run(job *Job, traceID, clientIP string) {
+	start := time.Now()
+	session := h.db.Session()
+	defer session.Close()
+	if h.vdbRegistry != nil {
+		session.SetVirtualDBRegistry(h.vdbRegistry)
+	}
+	job.principal.configureSession(session)
+	session.SetTraceID(traceID)
+	if job.Database != "" {
+		session.SetCurrentDB(job.Database)
+	}
+	session.SetProgressHandler(func(p domain.Progress) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		job.Progress = &JobProgress{
+			Stage:    p.Stage,
+			MaxStage: p.MaxStage,
+			Percent:  p.Percent(),
+			Done:     p.Done,
+			Total:    p.Total,
+			Info:     p.Info,
+		}
+	})
+
+	var affected int64
+	result, err := session.Execute(job.SQL)
+	if err == nil {
+		affected = result.RowsAffected
+	}
+
+	if h.auditLogger != nil {
+		h.auditLogger.LogAPIRequestBy(traceID, job.principal.auditPrincipal(), clientIP, http.MethodPost, "/api/v1/jobs", job.SQL, job.Database, time.Since(start).Milliseconds(), err == nil)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.AffectedRows = affected
+	if err != nil {
+		job.State = JobStateFailed
+		job.Error = err.Error()
+	} else {
+		job.State = JobStateSucceeded
+	}
+	h.finished = append(h.finished, job.ID)
+	if len(h.finished) > maxFinishedJobs {
+		delete(h.jobs, h.finished[0])
+		h.finished = h.finished[1:]
+	}
+}
+
+// status returns a snapshot of the job; jobs of other principals are reported as not found
+func (h *JobHandler) status(w http.ResponseWriter, id, owner string) {
+	h.mu.Lock()
+	job, ok := h.jobs[id]
+	var snapshot Job
+	if ok && job.owner == owner {
+		snapshot = *job
+		if job.Progress != nil {
+			progress := *job.Progress
+			snapshot.Progress = &progress
+		}
+	}
+	h.mu.Unlock()
+
+	if !ok || job.owner != owner {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJobTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	jobHandler := NewJobHandler(env.db, env.auditLogger)
+	clientStore := NewClientStore(env.configDir)
+
+	mux := http.NewServeMux()
+	authed := AuthMiddleware(clientStore)(jobHandler)
+	mux.Handle("/api/v1/jobs", authed)
+	mux.Handle("/api/v1/jobs/", authed)
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doSigned(t *testing.T, env *testEnv, server *httptest.Server, method, path, body string) *http.Response {
+	t.Helper()
+	ts, nonce, sig := signRequest(method, path, body, env.client.APISecret)
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", env.client.APIKey)
+	req.Header.Set("X-Timestamp", ts)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", sig)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// waitJob polls GET /api/v1/jobs/{id} until the job has finished
+func waitJob(t *testing.T, env *testEnv, server *httptest.Server, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp := doSigned(t, env, server, "GET", "/api/v1/jobs/"+id, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var job Job
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+		if job.State != JobStateRunning {
+			return job
+		}
+		require.True(t, time.Now().Before(deadline), "job %s did not finish", id)
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobs_CreateIndexProgress(t *testing.T) {
+	env := setupTestEnv(t)
+	session := env.db.Session()
+	_, err := session.Execute("CREATE TABLE job_items (id INT, v VARCHAR(20))")
+	require.NoError(t, err)
+	values := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		values = append(values, fmt.Sprintf("(%d, 'v%d')", i, i%7))
+	}
+	_, err = session.Execute("INSERT INTO job_items (id, v) VALUES " + strings.Join(values, ", "))
+	require.NoError(t, err)
+	session.Close()
+
+	server := newJobTestServer(t, env)
+
+	resp := doSigned(t, env, server, "POST", "/api/v1/jobs", `{"sql":"CREATE INDEX idx_v ON job_items (v)"}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var submitted Job
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&submitted))
+	require.NotEmpty(t, submitted.ID)
+	assert.Equal(t, JobStateRunning, submitted.State)
+
+	job := waitJob(t, env, server, submitted.ID)
+	assert.Equal(t, JobStateSucceeded, job.State, "job error: %s", job.Error)
+	require.NotNil(t, job.Progress)
+	assert.Equal(t, 1, job.Progress.Stage)
+	assert.Equal(t, int64(200), job.Progress.Total)
+	assert.Equal(t, float64(100), job.Progress.Percent)
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func TestJobs_FailedAndUnknown(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newJobTestServer(t, env)
+
+	resp := doSigned(t, env, server, "POST", "/api/v1/jobs", `{"sql":"OPTIMIZE TABLE missing_table"}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var submitted Job
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&submitted))
+
+	job := waitJob(t, env, server, submitted.ID)
+	assert.Equal(t, JobStateFailed, job.State)
+	assert.NotEmpty(t, job.Error)
+
+	resp = doSigned(t, env, server, "GET", "/api/v1/jobs/job-999", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doSigned(t, env, server, "POST", "/api/v1/jobs", `{"sql":""}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,378 @@
+package httpapi
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // SHA-256 for HS256, RS256 and ES256
+	_ "crypto/sha512" // SHA-384 and SHA-512
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+)
+
+const (
+	// jwtLeeway is the clock skew tolerated for exp and nbf
+	jwtLeeway = time.Minute
+
+	// jwksCacheTTL is how long fetched signing keys are used before refetching
+	jwksCacheTTL = 10 * time.Minute
+
+	// jwksMinRefresh limits refetches triggered by tokens with an unknown key ID
+	jwksMinRefresh = 30 * time.Second
+)
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwk is a public key of a JWKS document
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwtVerifier validates JWT bearer tokens against the configured issuer,
+// audience and signing keys (a shared secret and/or a JWKS endpoint)
+type jwtVerifier struct {
+	cfg    config.JWTConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey // keyed by kid
+	fetchedAt time.Time
+}
+
+func newJWTVerifier(cfg config.JWTConfig) *jwtVerifier {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = "scope"
+	}
+	return &jwtVerifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// looksLikeJWT reports whether a bearer token is a compact JWS rather than an API key
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// verify checks the token's signature and claims and returns its claims
+func (v *jwtVerifier) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature")
+	}
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+	if err := v.validateClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *jwtVerifier) verifySignature(header jwtHeader, signed string, signature []byte) error {
+	hash, ok := jwtHash(header.Alg)
+	if !ok {
+		return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	if strings.HasPrefix(header.Alg, "HS") {
+		if v.cfg.Secret == "" {
+			return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+		}
+		mac := hmac.New(hash.New, []byte(v.cfg.Secret))
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	}
+
+	keys, err := v.signingKeys(header.Kid)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		switch pub := key.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(header.Alg, "RS") && rsa.VerifyPKCS1v15(pub, hash, digest, signature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if strings.HasPrefix(header.Alg, "ES") && len(signature) == 2*size {
+				r := new(big.Int).SetBytes(signature[:size])
+				s := new(big.Int).SetBytes(signature[size:])
+				if ecdsa.Verify(pub, digest, r, s) {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("invalid token signature")
+}
+
+// validateClaims checks expiry, not-before, issuer and audience
+func (v *jwtVerifier) validateClaims(claims map[string]interface{}, now time.Time) error {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("token has no exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token not valid yet")
+	}
+	if v.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+			return fmt.Errorf("unexpected token issuer")
+		}
+	}
+	if v.cfg.Audience != "" && !containsClaim(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("unexpected token audience")
+	}
+	return nil
+}
+
+// user returns the user name carried by the token
+func (v *jwtVerifier) user(claims map[string]interface{}) (string, error) {
+	user, _ := claims[v.cfg.UserClaim].(string)
+	if user == "" {
+		return "", fmt.Errorf("token has no %s claim", v.cfg.UserClaim)
+	}
+	return user, nil
+}
+
+// aclUser returns the ACL user named by the configured claim, empty when the
+// claim is not configured or missing
+func (v *jwtVerifier) aclUser(claims map[string]interface{}) string {
+	if v.cfg.ACLUserClaim == "" {
+		return ""
+	}
+	user, _ := claims[v.cfg.ACLUserClaim].(string)
+	return user
+}
+
+// scopes returns the scopes carried by the token: a space separated string
+// (OAuth scope) or an array of strings
+func (v *jwtVerifier) scopes(claims map[string]interface{}) []string {
+	switch value := claims[v.cfg.ScopeClaim].(type) {
+	case string:
+		return splitScopes(value)
+	case []interface{}:
+		scopes := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+// signingKeys returns the JWKS keys to try for kid, all keys when kid is empty.
+// Keys are refetched when the cache expired or kid is unknown.
+func (v *jwtVerifier) signingKeys(kid string) ([]crypto.PublicKey, error) {
+	if v.cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("no signing keys configured for asymmetric tokens")
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	_, known := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > jwksCacheTTL
+	if v.keys == nil || stale || (kid != "" && !known && time.Since(v.fetchedAt) > jwksMinRefresh) {
+		keys, err := v.fetchKeys()
+		if err != nil && v.keys == nil {
+			return nil, err
+		}
+		if err == nil {
+			v.keys = keys
+		}
+		v.fetchedAt = time.Now()
+	}
+
+	if kid != "" {
+		if key, ok := v.keys[kid]; ok {
+			return []crypto.PublicKey{key}, nil
+		}
+		return nil, fmt.Errorf("unknown token signing key %q", kid)
+	}
+	keys := make([]crypto.PublicKey, 0, len(v.keys))
+	for _, key := range v.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// fetchKeys downloads and parses the JWKS document
+func (v *jwtVerifier) fetchKeys() (map[string]crypto.PublicKey, error) {
+	resp, err := v.client.Get(v.cfg.JWKSURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing keys: HTTP %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		kid := k.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("#%d", i)
+		}
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey converts an RSA or EC JWK into a public key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// jwtHash returns the hash of a JWS algorithm
+func jwtHash(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[:2] {
+	case "HS", "RS", "ES":
+	default:
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// containsClaim reports whether a string or string array claim contains want
+func containsClaim(claim interface{}, want string) bool {
+	switch value := claim.(type) {
+	case string:
+		return value == want
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+	"github.com/kasuganosora/sqlexec/pkg/virtual"
+)
+
+// LintHandler handles POST /api/v1/lint: the statements are parsed and checked
+// against the current schema without being executed
+type LintHandler struct {
+	db          *api.DB
+	vdbRegistry *virtual.VirtualDatabaseRegistry
+	auditLogger *security.AuditLogger
+}
+
+// NewLintHandler creates a new LintHandler
+func NewLintHandler(db *api.DB, auditLogger *security.AuditLogger) *LintHandler {
+	return &LintHandler{db: db, auditLogger: auditLogger}
+}
+
+// SetVirtualDBRegistry sets the virtual database registry
+func (h *LintHandler) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry) {
+	h.vdbRegistry = registry
+}
+
+// ServeHTTP lints the statements. The response is 200 whenever the statements
+// could be checked; the issues found are part of the body.
+func (h *LintHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	var req QueryRequest
+	if err := json.Unmarshal([]byte(GetBodyFromContext(r.Context())), &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.SQL == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidRequest, "sql field is required")
+		return
+	}
+
+	start := time.Now()
+	clientIP := getClientIP(r)
+	req.Database = principal.defaultDatabase(req.Database)
+	traceID := req.TraceID
+	if traceID == "" {
+		traceID = r.Header.Get("X-Trace-ID")
+	}
+	if traceID == "" {
+		traceID = fmt.Sprintf("http-%d", time.Now().UnixMilli())
+	}
+	logRequest := func(success bool) {
+		if h.auditLogger != nil {
+			h.auditLogger.LogAPIRequestBy(traceID, principal.auditPrincipal(), clientIP, r.Method, r.URL.Path, req.SQL, req.Database, time.Since(start).Milliseconds(), success)
+		}
+	}
+
+	session := h.db.Session()
+	defer session.Close()
+	if h.vdbRegistry != nil {
+		session.SetVirtualDBRegistry(h.vdbRegistry)
+	}
+	principal.configureSession(session)
+	session.SetTraceID(traceID)
+	if req.Database != "" {
+		session.SetCurrentDB(req.Database)
+	}
+
+	reports, err := session.Lint(req.SQL)
+	if err != nil {
+		logRequest(false)
+		writeStatementError(w, err.Error(), err)
+		return
+	}
+
+	// The reports reveal the schema, so statements that parse must stay within the
+	// caller's databases; syntax errors are reported regardless
+	resp := LintResponse{Statements: reports}
+	for _, report := range reports {
+		if report.Type != "" {
+			if err := principal.checkStatement(report.SQL, session.GetCurrentDB()); err != nil {
+				logRequest(false)
+				writeError(w, http.StatusForbidden, ErrCodeForbidden, err.Error())
+				return
+			}
+		}
+		for _, issue := range report.Issues {
+			if issue.Severity == api.LintError {
+				resp.Errors++
+			} else {
+				resp.Warnings++
+			}
+		}
+	}
+
+	logRequest(true)
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLintTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	session := env.db.Session()
+	defer session.Close()
+	_, err := session.Execute("CREATE TABLE items (id INT PRIMARY KEY, name VARCHAR(50), price INT)")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/lint", AuthMiddleware(NewClientStore(env.configDir))(NewLintHandler(env.db, env.auditLogger)))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postLint posts a lint request and returns the response with its body fully read
+func postLint(t *testing.T, server *httptest.Server, env *testEnv, req QueryRequest) (*http.Response, []byte) {
+	t.Helper()
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	body := string(data)
+	ts, nonce, sig := signRequest("POST", "/api/v1/lint", body, env.client.APISecret)
+	httpReq, err := http.NewRequest("POST", server.URL+"/api/v1/lint", strings.NewReader(body))
+	require.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-API-Key", env.client.APIKey)
+	httpReq.Header.Set("X-Timestamp", ts)
+	httpReq.Header.Set("X-Nonce", nonce)
+	httpReq.Header.Set("X-Signature", sig)
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, out
+}
+
+func TestLint_ReportsIssuesWithoutExecuting(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newLintTestServer(t, env)
+
+	resp, body := postLint(t, server, env, QueryRequest{SQL: "SELECT nme FROM items WHERE id = 1; DELETE FROM items; SELECT * FORM items"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var result LintResponse
+	require.NoError(t, json.Unmarshal(body, &result))
+	require.Len(t, result.Statements, 3)
+	assert.Equal(t, 2, result.Errors)
+	assert.Equal(t, 1, result.Warnings)
+
+	assert.Equal(t, api.LintRuleUnknownColumn, result.Statements[0].Issues[0].Rule)
+	assert.Equal(t, api.LintCostLow, result.Statements[0].Cost)
+	assert.Equal(t, api.LintRuleMissingWhere, result.Statements[1].Issues[0].Rule)
+	assert.Equal(t, api.LintRuleSyntax, result.Statements[2].Issues[0].Rule)
+	assert.Equal(t, 1, result.Statements[2].Issues[0].Line)
+}
+
+func TestLint_RequestErrors(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newLintTestServer(t, env)
+
+	resp, body := postLint(t, server, env, QueryRequest{})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(body))
+
+	resp, body = postLint(t, server, env, QueryRequest{SQL: "SELECT 1"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	var result LintResponse
+	require.NoError(t, json.Unmarshal(body, &result))
+	assert.Zero(t, result.Errors)
+	assert.Zero(t, result.Warnings)
+}
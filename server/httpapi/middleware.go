@@ -1,21 +1,29 @@
 package httpapi
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/kasuganosora/sqlexec/pkg/config"
 	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
 )
 
 type contextKey string
 
 const (
-	ctxKeyClient contextKey = "api_client"
-	ctxKeyBody   contextKey = "request_body"
+	ctxKeyClient    contextKey = "api_client"
+	ctxKeyPrincipal contextKey = "principal"
+	ctxKeyBody      contextKey = "request_body"
 )
 
 // GetClientFromContext returns the authenticated API client from the request context
@@ -24,6 +32,12 @@ func GetClientFromContext(ctx context.Context) *config_schema.APIClient {
 	return client
 }
 
+// GetPrincipalFromContext returns the authenticated principal from the request context
+func GetPrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(ctxKeyPrincipal).(*Principal)
+	return principal
+}
+
 // GetBodyFromContext returns the cached request body from the context
 func GetBodyFromContext(ctx context.Context) string {
 	body, _ := ctx.Value(ctxKeyBody).(string)
@@ -35,34 +49,155 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("[HTTP API] panic recovered: %v", err)
-				writeJSON(w, http.StatusInternalServerError, ErrorResponse{
-					Error: "internal server error",
-					Code:  http.StatusInternalServerError,
+				perr := monitor.GetPanicStats().Record(monitor.PanicReport{
+					Scope: monitor.PanicScopeHTTP,
+					Query: r.Method + " " + r.URL.Path,
+					Value: fmt.Sprint(err),
+					Stack: string(debug.Stack()),
 				})
+				apiLog.Error("panic recovered", "panic_id", perr.Report.ID, "method", r.Method, "path", r.URL.Path, "panic", err, "stack", perr.Report.Stack)
+				writeError(w, http.StatusInternalServerError, ErrCodeInternal, "internal server error")
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
-// CORSMiddleware adds CORS headers
+// CORSMiddleware adds CORS headers allowing any origin
 func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+headerAPIKey+", "+headerTimestamp+", "+headerNonce+", "+headerSignature+", Authorization")
-		w.Header().Set("Access-Control-Max-Age", "86400")
+	return NewCORSMiddleware(config.HTTPCORSConfig{AllowedOrigins: []string{"*"}, MaxAge: 86400})(next)
+}
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
+// NewCORSMiddleware adds CORS headers for the configured origins. Requests
+// from other origins get no CORS headers, so browsers block the response.
+func NewCORSMiddleware(cfg config.HTTPCORSConfig) func(http.Handler) http.Handler {
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
 		}
+		origins[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+	allowHeaders := "Content-Type, " + headerAPIKey + ", " + headerTimestamp + ", " + headerNonce + ", " + headerSignature + ", Authorization, X-Trace-ID"
 
-		next.ServeHTTP(w, r)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			switch {
+			case anyOrigin && !cfg.AllowCredentials:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			case origin != "" && (anyOrigin || origins[strings.ToLower(origin)]):
+				// Credentials cannot be combined with a wildcard, so the origin is echoed
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+				if cfg.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			default:
+				origin = ""
+			}
+			if origin != "" || (anyOrigin && !cfg.AllowCredentials) {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				if cfg.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+			}
+
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GzipMiddleware compresses responses for clients accepting gzip
+func GzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
 	})
 }
 
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}
+
+// gzipWriter compresses the body of responses that have one
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	compress    bool
+	wroteHeader bool
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified &&
+		w.Header().Get("Content-Encoding") == "" {
+		w.compress = true
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.compress {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.gz == nil {
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	return w.gz.Write(p)
+}
+
+// close finishes the gzip stream; a compressed response without body still
+// gets a valid empty stream
+func (w *gzipWriter) close() {
+	if w.compress && w.gz == nil {
+		w.Write(nil)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// NotFoundHandler reports unknown endpoints with a JSON error
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotFound, ErrCodeNotFound, "endpoint not found")
+}
+
 // LoggingMiddleware logs HTTP requests
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -73,82 +208,20 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapped, r)
 
 		duration := time.Since(start)
-		client := GetClientFromContext(r.Context())
 		clientName := "-"
-		if client != nil {
+		if principal := GetPrincipalFromContext(r.Context()); principal != nil {
+			clientName = principal.Name
+		} else if client := GetClientFromContext(r.Context()); client != nil {
 			clientName = client.Name
 		}
 
-		log.Printf("[HTTP API] %s %s %s %d %s", clientName, r.Method, r.URL.Path, wrapped.statusCode, duration)
+		apiLog.Info("request", "client", clientName, "method", r.Method, "path", r.URL.Path, "status", wrapped.statusCode, "duration", duration)
 	})
 }
 
 // AuthMiddleware validates API key and HMAC signature
 func AuthMiddleware(store *ClientStore) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			apiKey := r.Header.Get(headerAPIKey)
-			if apiKey == "" {
-				writeJSON(w, http.StatusUnauthorized, ErrorResponse{
-					Error: "missing X-API-Key header",
-					Code:  http.StatusUnauthorized,
-				})
-				return
-			}
-
-			client, err := store.GetClient(apiKey)
-			if err != nil {
-				writeJSON(w, http.StatusUnauthorized, ErrorResponse{
-					Error: err.Error(),
-					Code:  http.StatusUnauthorized,
-				})
-				return
-			}
-
-			// Read body for signature verification (limit to 10MB)
-			const maxBodySize = 10 * 1024 * 1024
-			body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
-			if err != nil {
-				writeJSON(w, http.StatusBadRequest, ErrorResponse{
-					Error: "failed to read request body",
-					Code:  http.StatusBadRequest,
-				})
-				return
-			}
-			if len(body) > maxBodySize {
-				writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{
-					Error: "request body too large",
-					Code:  http.StatusRequestEntityTooLarge,
-				})
-				return
-			}
-
-			timestamp := r.Header.Get(headerTimestamp)
-			nonce := r.Header.Get(headerNonce)
-			signature := r.Header.Get(headerSignature)
-
-			if timestamp == "" || nonce == "" || signature == "" {
-				writeJSON(w, http.StatusUnauthorized, ErrorResponse{
-					Error: "missing signature headers (X-Timestamp, X-Nonce, X-Signature)",
-					Code:  http.StatusUnauthorized,
-				})
-				return
-			}
-
-			if err := ValidateSignature(client.APISecret, r.Method, r.URL.Path, timestamp, nonce, string(body), signature); err != nil {
-				writeJSON(w, http.StatusUnauthorized, ErrorResponse{
-					Error: "signature verification failed: " + err.Error(),
-					Code:  http.StatusUnauthorized,
-				})
-				return
-			}
-
-			// Store client and body in context
-			ctx := context.WithValue(r.Context(), ctxKeyClient, client)
-			ctx = context.WithValue(ctx, ctxKeyBody, string(body))
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
-	}
+	return NewAuthenticator(store, config.HTTPAuthConfig{}).Middleware
 }
 
 // statusWriter wraps http.ResponseWriter to capture status code
@@ -162,12 +235,17 @@ func (w *statusWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// writeError writes an ErrorResponse
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message, Code: status, ErrorCode: code})
+}
+
 // writeJSON writes a JSON response. If encoding fails, it logs the error
 // and writes a plain-text fallback so the client always gets a response.
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.Printf("[HTTP API] writeJSON encode error: %v", err)
+		apiLog.Error("writeJSON encode error", "err", err)
 	}
 }
@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
+	"github.com/kasuganosora/sqlexec/pkg/security"
+)
+
+// Authentication methods of a Principal
+const (
+	AuthMethodSignature = "signature" // API key with HMAC signature
+	AuthMethodAPIKey    = "api_key"   // unsigned API key
+	AuthMethodJWT       = "jwt"       // JWT bearer token
+)
+
+// Scopes understood by the HTTP API. Other scopes are ignored so that tokens
+// issued for several services can carry their own.
+const (
+	ScopeRead     = "read"  // read-only access
+	ScopeWrite    = "write" // read and write access
+	scopeDBPrefix = "db:"   // db:<name> restricts access to the listed databases
+)
+
+// scopeParser parses statements for the database scope check
+var scopeParser = parser.NewParser()
+
+// Principal is the authenticated caller of an HTTP API request
+type Principal struct {
+	Name      string
+	Method    string
+	ReadOnly  bool
+	Databases []string // databases the caller may use; empty allows all
+	Super     bool     // SUPER privilege granted by the ACL
+	ACLUser   string   // ACL user the principal is mapped to, empty when not mapped
+
+	// Client is the API client for key based methods, nil for JWT
+	Client *config_schema.APIClient
+}
+
+// splitScopes splits a scope list separated by spaces or commas
+func splitScopes(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t' || r == '\n'
+	})
+}
+
+// applyScopes sets the access of p from scopes. readOnly is used when
+// neither read nor write is listed; write wins when both are.
+func (p *Principal) applyScopes(scopes []string, readOnly bool) {
+	var read, write bool
+	for _, scope := range scopes {
+		switch {
+		case strings.EqualFold(scope, ScopeRead):
+			read = true
+		case strings.EqualFold(scope, ScopeWrite):
+			write = true
+		case len(scope) > len(scopeDBPrefix) && strings.EqualFold(scope[:len(scopeDBPrefix)], scopeDBPrefix):
+			p.Databases = append(p.Databases, scope[len(scopeDBPrefix):])
+		}
+	}
+	switch {
+	case write:
+		p.ReadOnly = false
+	case read:
+		p.ReadOnly = true
+	default:
+		p.ReadOnly = readOnly
+	}
+}
+
+// Scopes returns the effective scopes of p
+func (p *Principal) Scopes() []string {
+	scopes := []string{ScopeWrite}
+	if p.ReadOnly {
+		scopes[0] = ScopeRead
+	}
+	for _, db := range p.Databases {
+		scopes = append(scopes, scopeDBPrefix+db)
+	}
+	return scopes
+}
+
+// AllowsDatabase reports whether p may use database
+func (p *Principal) AllowsDatabase(database string) bool {
+	if len(p.Databases) == 0 {
+		return true
+	}
+	for _, db := range p.Databases {
+		if strings.EqualFold(db, database) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultDatabase returns the database used when a request names none
+func (p *Principal) defaultDatabase(database string) string {
+	if database == "" && len(p.Databases) > 0 {
+		return p.Databases[0]
+	}
+	return database
+}
+
+// checkStatement rejects statements that use databases outside the caller's
+// scope. database is the current database of the request.
+func (p *Principal) checkStatement(sql, database string) error {
+	if len(p.Databases) == 0 {
+		return nil
+	}
+	if !p.AllowsDatabase(database) {
+		return fmt.Errorf("access to database '%s' is not allowed", database)
+	}
+	stmts, err := scopeParser.ParseSQL(sql)
+	if err != nil {
+		return fmt.Errorf("statement cannot be checked against the allowed databases")
+	}
+	for _, stmt := range stmts {
+		for _, db := range parser.ExtractDatabaseNames(stmt) {
+			if !p.AllowsDatabase(db) {
+				return fmt.Errorf("access to database '%s' is not allowed", db)
+			}
+		}
+	}
+	return nil
+}
+
+// configureSession runs the session as the principal
+func (p *Principal) configureSession(session *api.Session) {
+	session.SetUser(p.Name)
+	session.SetReadOnly(p.ReadOnly)
+	session.SetSuperPrivilege(p.Super)
+}
+
+// owner identifies the principal as the owner of async jobs
+func (p *Principal) owner() string {
+	if p.Method == AuthMethodJWT {
+		return "jwt:" + p.Name
+	}
+	return "key:" + p.Name
+}
+
+// auditPrincipal describes the principal for the audit log
+func (p *Principal) auditPrincipal() security.APIPrincipal {
+	return security.APIPrincipal{Name: p.Name, AuthMethod: p.Method, Scopes: p.Scopes()}
+}
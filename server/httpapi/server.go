@@ -2,17 +2,22 @@ package httpapi
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
 	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/security"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/kasuganosora/sqlexec/server/acl"
 )
 
+// apiLog is the structured logger of the HTTP API
+var apiLog = logging.Module("httpapi")
+
 // Server is the HTTP REST API server
 type Server struct {
 	db          *api.DB
@@ -20,6 +25,7 @@ type Server struct {
 	vdbRegistry *virtual.VirtualDatabaseRegistry
 	cfg         *config.HTTPAPIConfig
 	auditLogger *security.AuditLogger
+	aclManager  *acl.ACLManager
 	httpServer  *http.Server
 }
 
@@ -28,6 +34,11 @@ func (s *Server) SetVirtualDBRegistry(registry *virtual.VirtualDatabaseRegistry)
 	s.vdbRegistry = registry
 }
 
+// SetACLManager sets the ACL used to grant the SUPER privilege to authenticated principals
+func (s *Server) SetACLManager(manager *acl.ACLManager) {
+	s.aclManager = manager
+}
+
 // NewServer creates a new HTTP API server
 func NewServer(db *api.DB, configDir string, cfg *config.HTTPAPIConfig, auditLogger *security.AuditLogger) *Server {
 	return &Server{
@@ -42,7 +53,13 @@ func NewServer(db *api.DB, configDir string, cfg *config.HTTPAPIConfig, auditLog
 func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 
-	clientStore := NewClientStore(s.configDir)
+	auth := NewAuthenticator(NewClientStore(s.configDir), s.cfg.Auth)
+	auth.SetMaxBodySize(s.cfg.MaxBodySize)
+	if s.aclManager != nil {
+		auth.SetSuperChecker(func(user, host string) bool {
+			return s.aclManager.CheckPermission(user, host, acl.PrivSuper, "", "", "")
+		})
+	}
 	queryHandler := NewQueryHandler(s.db, s.configDir, s.auditLogger)
 	queryHandler.SetVirtualDBRegistry(s.vdbRegistry)
 
@@ -56,12 +73,64 @@ func (s *Server) Start() error {
 		})
 	})
 
+	// Liveness and readiness probes (no auth required)
+	mux.HandleFunc("/healthz", HealthzHandler)
+	mux.Handle("/readyz", NewReadyzHandler(s.db))
+
 	// Query endpoint (auth required)
-	authedQuery := AuthMiddleware(clientStore)(queryHandler)
+	authedQuery := auth.Middleware(queryHandler)
 	mux.Handle("/api/v1/query", authedQuery)
 
-	// Apply global middleware: Recovery → CORS → Logging
-	handler := RecoveryMiddleware(CORSMiddleware(LoggingMiddleware(mux)))
+	// Async job endpoints (auth required): submit a statement, then poll its progress
+	jobHandler := NewJobHandler(s.db, s.auditLogger)
+	jobHandler.SetVirtualDBRegistry(s.vdbRegistry)
+	authedJobs := auth.Middleware(jobHandler)
+	mux.Handle("/api/v1/jobs", authedJobs)
+	mux.Handle("/api/v1/jobs/", authedJobs)
+
+	// File upload loaded with IMPORT DATA (auth required)
+	importHandler := NewImportHandler(s.db, s.auditLogger)
+	importHandler.SetVirtualDBRegistry(s.vdbRegistry)
+	mux.Handle("/api/v1/import", auth.Middleware(importHandler))
+
+	// Query result download (auth required)
+	exportHandler := NewExportHandler(s.db, s.auditLogger)
+	exportHandler.SetVirtualDBRegistry(s.vdbRegistry)
+	mux.Handle("/api/v1/export", auth.Middleware(exportHandler))
+
+	// Table comparison streamed as JSON Lines (auth required)
+	diffHandler := NewDiffHandler(s.db, s.auditLogger)
+	diffHandler.SetVirtualDBRegistry(s.vdbRegistry)
+	mux.Handle("/api/v1/diff", auth.Middleware(diffHandler))
+
+	// Statement checks without execution (auth required)
+	lintHandler := NewLintHandler(s.db, s.auditLogger)
+	lintHandler.SetVirtualDBRegistry(s.vdbRegistry)
+	mux.Handle("/api/v1/lint", auth.Middleware(lintHandler))
+
+	// Workload class statistics (auth required)
+	mux.Handle("/api/v1/workload", auth.Middleware(NewWorkloadHandler()))
+
+	// Admin endpoints backing the web console (auth required)
+	adminHandler := NewAdminHandler(s.db, s.auditLogger)
+	adminHandler.SetVirtualDBRegistry(s.vdbRegistry)
+	adminHandler.SetACLManager(s.aclManager)
+	mux.Handle("/api/v1/admin/", auth.Middleware(adminHandler))
+
+	// Web console (static files, no auth required)
+	if s.cfg.WebUI {
+		mux.Handle("/ui/", WebUIHandler())
+	}
+
+	// Unknown paths get a JSON error like every other endpoint
+	mux.HandleFunc("/", NotFoundHandler)
+
+	// Apply global middleware: Recovery → CORS → Gzip → Logging
+	handler := LoggingMiddleware(mux)
+	if s.cfg.Gzip {
+		handler = GzipMiddleware(handler)
+	}
+	handler = RecoveryMiddleware(NewCORSMiddleware(s.cfg.CORS)(handler))
 
 	s.httpServer = &http.Server{
 		Addr:         addr,
@@ -71,7 +140,20 @@ func (s *Server) Start() error {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	log.Printf("[HTTP API] 启动 HTTP API 服务器: %s", addr)
+	if s.cfg.TLS.Enabled() {
+		certs, err := newCertReloader(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+		apiLog.Info("启动 HTTPS API 服务器", "addr", addr)
+		return s.httpServer.ListenAndServeTLS("", "")
+	}
+
+	apiLog.Info("启动 HTTP API 服务器", "addr", addr)
 	return s.httpServer.ListenAndServe()
 }
 
@@ -1,12 +1,14 @@
 package httpapi
 
 import (
+	"compress/gzip"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -114,6 +116,46 @@ func TestHealthEndpoint(t *testing.T) {
 	assert.Equal(t, "ok", healthResp.Status)
 }
 
+func TestHealthzReadyz(t *testing.T) {
+	env := setupTestEnv(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", HealthzHandler)
+	mux.Handle("/readyz", NewReadyzHandler(env.db))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// datasources never probed are probed by the request
+	resp, err = http.Get(server.URL + "/readyz")
+	require.NoError(t, err)
+	var ready ReadyResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ready", ready.Status)
+	require.Len(t, ready.Datasources, 1)
+	assert.Equal(t, "up", ready.Datasources[0].Status)
+
+	broken := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: "broken"})
+	require.NoError(t, env.db.RegisterDataSource("broken", broken))
+	resp, err = http.Get(server.URL + "/readyz")
+	require.NoError(t, err)
+	ready = ReadyResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "not ready", ready.Status)
+	require.Len(t, ready.Datasources, 2)
+	assert.Equal(t, "broken", ready.Datasources[0].Name)
+	assert.Equal(t, "down", ready.Datasources[0].Status)
+	assert.Empty(t, ready.Datasources[0].LastError)
+}
+
 func TestQueryEndpoint_NoAuth(t *testing.T) {
 	env := setupTestEnv(t)
 
@@ -965,3 +1007,140 @@ func TestWriteJSON_ErrorHandling(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
 }
+
+func TestNewCORSMiddleware_Origins(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := NewCORSMiddleware(config.HTTPCORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})(next)
+
+	// Allowed origin is echoed with credentials
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/query", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	// Other origins get no CORS headers
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	payload := strings.Repeat(`{"id":1,"name":"Alice"}`, 100)
+	handler := GzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(payload))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Less(t, rec.Body.Len(), len(payload))
+
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(data))
+
+	// Clients without gzip support get the plain body
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, payload, rec.Body.String())
+
+	assert.False(t, acceptsGzip("gzip;q=0"))
+	assert.True(t, acceptsGzip("br, GZIP;q=0.5"))
+}
+
+func TestAuthMiddleware_BodyTooLarge(t *testing.T) {
+	env := setupTestEnv(t)
+
+	auth := NewAuthenticator(NewClientStore(env.configDir), config.HTTPAuthConfig{})
+	auth.SetMaxBodySize(32)
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/query", auth.Middleware(NewQueryHandler(env.db, env.configDir, env.auditLogger)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	body := `{"sql":"SELECT 'a fairly long statement that exceeds the limit'"}`
+	path := "/api/v1/query"
+	ts, nonce, sig := signRequest("POST", path, body, env.client.APISecret)
+	req, err := http.NewRequest("POST", server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", env.client.APIKey)
+	req.Header.Set("X-Timestamp", ts)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", sig)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, ErrCodePayloadTooLarge, errResp.ErrorCode)
+	assert.Contains(t, errResp.Error, "limit 32 bytes")
+}
+
+func TestErrorResponse_Codes(t *testing.T) {
+	env := setupTestEnv(t)
+
+	clientStore := NewClientStore(env.configDir)
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/query", AuthMiddleware(clientStore)(NewQueryHandler(env.db, env.configDir, env.auditLogger)))
+	mux.HandleFunc("/", NotFoundHandler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	decode := func(resp *http.Response) ErrorResponse {
+		defer resp.Body.Close()
+		var errResp ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+		return errResp
+	}
+
+	resp, err := http.Get(server.URL + "/api/v1/unknown")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	errResp := decode(resp)
+	assert.Equal(t, ErrCodeNotFound, errResp.ErrorCode)
+	assert.Equal(t, http.StatusNotFound, errResp.Code)
+
+	resp, err = http.Post(server.URL+"/api/v1/query", "application/json", strings.NewReader(`{"sql":"SELECT 1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, ErrCodeUnauthorized, decode(resp).ErrorCode)
+
+	body := `{"sql":"SELECT * FROM missing_table"}`
+	path := "/api/v1/query"
+	ts, nonce, sig := signRequest("POST", path, body, env.client.APISecret)
+	req, err := http.NewRequest("POST", server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", env.client.APIKey)
+	req.Header.Set("X-Timestamp", ts)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", sig)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.NotEmpty(t, decode(resp).ErrorCode)
+}
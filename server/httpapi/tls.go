@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// certCheckInterval is how often the certificate files are checked for changes
+const certCheckInterval = 10 * time.Second
+
+// certReloader serves the TLS certificate from disk and reloads it when the
+// certificate or key file changes, so renewed certificates are picked up
+// without a restart
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// newCertReloader loads the certificate; it fails if the files are unusable
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile, interval: certCheckInterval}
+	modTime, err := c.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.load(modTime); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checkedAt) >= c.interval {
+		c.checkedAt = time.Now()
+		if modTime, err := c.latestModTime(); err == nil && !modTime.Equal(c.modTime) {
+			// A failed reload (e.g. files replaced one at a time) keeps the current certificate
+			if err := c.load(modTime); err != nil {
+				apiLog.Warn("TLS 证书重新加载失败，继续使用当前证书", "cert", c.certFile, "err", err)
+			} else {
+				apiLog.Info("已重新加载 TLS 证书", "cert", c.certFile)
+			}
+		}
+	}
+	return c.cert, nil
+}
+
+// load reads the key pair; callers hold mu or own c exclusively
+func (c *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	c.cert = &cert
+	c.modTime = modTime
+	c.checkedAt = time.Now()
+	return nil
+}
+
+// latestModTime returns the later modification time of the two files
+func (c *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
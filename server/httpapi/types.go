@@ -1,6 +1,14 @@
 package httpapi
 
-import "github.com/kasuganosora/sqlexec/pkg/resource/domain"
+import (
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/tablediff"
+	"github.com/kasuganosora/sqlexec/pkg/workload"
+)
 
 // QueryRequest represents an HTTP API query request
 type QueryRequest struct {
@@ -22,10 +30,73 @@ type ExecResponse struct {
 	AffectedRows int64 `json:"affected_rows"`
 }
 
+// ExportRequest represents a POST /api/v1/export request
+type ExportRequest struct {
+	SQL      string            `json:"sql"`
+	Database string            `json:"database,omitempty"`
+	Format   string            `json:"format,omitempty"`  // csv (default), json or parquet
+	Options  map[string]string `json:"options,omitempty"` // header, delimiter, compression
+	TraceID  string            `json:"trace_id,omitempty"`
+}
+
+// DiffRequest represents a POST /api/v1/diff request. Left and Right may be
+// qualified as db.table; Key defaults to the left table's primary key
+type DiffRequest struct {
+	Left     string   `json:"left"`
+	Right    string   `json:"right"`
+	Key      []string `json:"key,omitempty"`
+	Database string   `json:"database,omitempty"`
+	TraceID  string   `json:"trace_id,omitempty"`
+}
+
+// DiffEnd is the last line of a POST /api/v1/diff response: the summary counts,
+// or the error that stopped the comparison after differences were streamed
+type DiffEnd struct {
+	Summary *tablediff.Summary `json:"summary,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// LintResponse represents the result of POST /api/v1/lint: one report per
+// statement of the request's sql, and the total issue counts
+type LintResponse struct {
+	Statements []*api.LintReport `json:"statements"`
+	Errors     int               `json:"errors"`
+	Warnings   int               `json:"warnings"`
+}
+
+// ImportResponse represents the result of POST /api/v1/import
+type ImportResponse struct {
+	AffectedRows int64    `json:"affected_rows"`
+	Info         string   `json:"info,omitempty"`     // record, skipped and warning counts
+	Warnings     []string `json:"warnings,omitempty"` // rejected rows collected with on_error=collect
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  int    `json:"code"`
+	Error     string `json:"error"`
+	Code      int    `json:"code"`       // HTTP status
+	ErrorCode string `json:"error_code"` // machine-readable error code
+}
+
+// Machine-readable error codes of ErrorResponse. Failed statements report
+// the engine's error code instead (SYNTAX_ERROR, TABLE_NOT_FOUND, READ_ONLY...).
+const (
+	ErrCodeInvalidRequest   = "INVALID_REQUEST"
+	ErrCodeUnauthorized     = "UNAUTHORIZED"
+	ErrCodeForbidden        = "FORBIDDEN"
+	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	ErrCodePayloadTooLarge  = "PAYLOAD_TOO_LARGE"
+	ErrCodeStatementFailed  = "STATEMENT_FAILED"
+	ErrCodeServerBusy       = "SERVER_BUSY"
+	ErrCodeNotSupported     = "NOT_SUPPORTED"
+	ErrCodeInternal         = "INTERNAL"
+)
+
+// WorkloadResponse represents the workload class statistics response
+type WorkloadResponse struct {
+	Enabled bool                  `json:"enabled"`
+	Classes []workload.ClassStats `json:"classes"`
 }
 
 // HealthResponse represents a health check response
@@ -33,3 +104,125 @@ type HealthResponse struct {
 	Status  string `json:"status"`
 	Version string `json:"version"`
 }
+
+// DatasourceStatus is the health of a datasource as of its last probe
+type DatasourceStatus struct {
+	Name                string  `json:"name"`
+	Type                string  `json:"type"`
+	Status              string  `json:"status"` // unknown, up, down or quarantined
+	LatencyMs           float64 `json:"latency_ms"`
+	LastCheck           string  `json:"last_check,omitempty"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	LastError           string  `json:"last_error,omitempty"`
+}
+
+// ReadyResponse represents the readiness response of /readyz
+type ReadyResponse struct {
+	Status      string             `json:"status"` // ready or not ready
+	Datasources []DatasourceStatus `json:"datasources"`
+}
+
+// AdminHealthResponse represents the datasource health response
+type AdminHealthResponse struct {
+	Datasources []DatasourceStatus `json:"datasources"`
+}
+
+// AdminDatabase is a database listed by GET /api/v1/admin/databases
+type AdminDatabase struct {
+	Name    string `json:"name"`
+	Virtual bool   `json:"virtual"` // served by a virtual database provider (config, information_schema...)
+}
+
+// AdminDatabasesResponse represents the database list response
+type AdminDatabasesResponse struct {
+	Databases []AdminDatabase `json:"databases"`
+}
+
+// AdminTablesResponse represents the table list of a database
+type AdminTablesResponse struct {
+	Database string   `json:"database"`
+	Tables   []string `json:"tables"`
+}
+
+// AdminTableResponse represents the schema and sample rows of a table
+type AdminTableResponse struct {
+	Database string        `json:"database"`
+	Table    string        `json:"table"`
+	Schema   QueryResponse `json:"schema"` // SHOW COLUMNS output
+	Sample   QueryResponse `json:"sample"`
+}
+
+// SlowLogEntry is a statement recorded in the slow query log
+type SlowLogEntry struct {
+	ID         int64             `json:"id"`
+	SQL        string            `json:"sql"`
+	User       string            `json:"user,omitempty"`
+	DurationMs float64           `json:"duration_ms"`
+	Rows       int64             `json:"rows"`
+	Error      string            `json:"error,omitempty"`
+	Time       time.Time         `json:"time"`
+	Digest     string            `json:"digest,omitempty"`
+	Plan       string            `json:"plan,omitempty"`   // EXPLAIN plan, only for sampled SELECT statements
+	Stats      *SlowLogStats     `json:"stats,omitempty"`  // only for sampled statements
+	Labels     map[string]string `json:"labels,omitempty"` // query labels of the statement
+}
+
+// SlowLogStats holds the execution statistics captured for a sampled slow statement
+type SlowLogStats struct {
+	PlanDigest    string  `json:"plan_digest,omitempty"`
+	EstimatedCost float64 `json:"estimated_cost"`
+	ExecCount     int64   `json:"exec_count"`     // executions of the digest at capture time
+	AvgLatencyMs  float64 `json:"avg_latency_ms"` // average latency of the digest at capture time
+	AvgRows       int64   `json:"avg_rows"`
+}
+
+// SlowLogResponse represents the slow query log, newest entries first
+type SlowLogResponse struct {
+	ThresholdMs int64          `json:"threshold_ms"` // 0 when the slow log is disabled
+	SampleRate  float64        `json:"explain_sample_rate"`
+	Entries     []SlowLogEntry `json:"entries"`
+}
+
+// AdminPanicsResponse represents the recovered panic counters and the most
+// recent panic reports, newest first
+type AdminPanicsResponse struct {
+	Connection int64                 `json:"connection"`
+	Statement  int64                 `json:"statement"`
+	HTTP       int64                 `json:"http"`
+	Panics     []monitor.PanicReport `json:"panics"`
+}
+
+// AdminUser is an account of the server ACL
+type AdminUser struct {
+	User        string   `json:"user"`
+	Host        string   `json:"host"`
+	HasPassword bool     `json:"has_password"`
+	Privileges  []string `json:"privileges"` // global privileges
+}
+
+// AdminUsersResponse represents the user list response
+type AdminUsersResponse struct {
+	Users []AdminUser `json:"users"`
+}
+
+// CreateUserRequest represents a POST /api/v1/admin/users request, or a PUT
+// request that changes the user's password
+type CreateUserRequest struct {
+	User     string `json:"user"`
+	Host     string `json:"host,omitempty"` // defaults to %
+	Password string `json:"password,omitempty"`
+}
+
+// DatasourceRequest represents a POST /api/v1/admin/datasources request,
+// stored in config.datasource
+type DatasourceRequest struct {
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Host     string                 `json:"host,omitempty"`
+	Port     int                    `json:"port,omitempty"`
+	Username string                 `json:"username,omitempty"`
+	Password string                 `json:"password,omitempty"`
+	Database string                 `json:"database,omitempty"`
+	Writable *bool                  `json:"writable,omitempty"` // defaults to true
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed webui
+var webUIFiles embed.FS
+
+// WebUIHandler serves the embedded web console under /ui/. The console is
+// static; it signs in against the admin endpoints with the credentials the
+// user enters.
+func WebUIHandler() http.Handler {
+	files, err := fs.Sub(webUIFiles, "webui")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix("/ui/", http.FileServer(http.FS(files)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		fileServer.ServeHTTP(w, r)
+	})
+}
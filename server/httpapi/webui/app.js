@@ -0,0 +1,393 @@
+// SQLExec web console. Talks to the JSON endpoints of the HTTP API with the
+// credentials entered on the sign-in form; nothing is stored beyond the tab.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+const credentialsKey = "sqlexec.credentials";
+
+let credentials = null;
+
+// ---- authentication -------------------------------------------------------
+
+function loadCredentials() {
+  try {
+    credentials = JSON.parse(sessionStorage.getItem(credentialsKey));
+  } catch (e) {
+    credentials = null;
+  }
+}
+
+function toHex(buffer) {
+  return Array.from(new Uint8Array(buffer), (b) => b.toString(16).padStart(2, "0")).join("");
+}
+
+// authHeaders returns the headers authenticating a request, signing
+// method + path + timestamp + nonce + body when a secret is used
+async function authHeaders(method, path, body) {
+  const headers = {};
+  switch (credentials.method) {
+    case "bearer":
+      headers["Authorization"] = "Bearer " + credentials.token;
+      break;
+    case "api_key":
+      headers["X-API-Key"] = credentials.key;
+      break;
+    default: {
+      if (!window.crypto || !crypto.subtle) {
+        throw new Error("Signed requests need HTTPS or localhost; use an unsigned key or a token instead");
+      }
+      const timestamp = Math.floor(Date.now() / 1000).toString();
+      const nonce = toHex(crypto.getRandomValues(new Uint8Array(16)));
+      const encoder = new TextEncoder();
+      const key = await crypto.subtle.importKey("raw", encoder.encode(credentials.secret),
+        { name: "HMAC", hash: "SHA-256" }, false, ["sign"]);
+      const signature = await crypto.subtle.sign("HMAC", key,
+        encoder.encode(method + path + timestamp + nonce + body));
+      headers["X-API-Key"] = credentials.key;
+      headers["X-Timestamp"] = timestamp;
+      headers["X-Nonce"] = nonce;
+      headers["X-Signature"] = toHex(signature);
+    }
+  }
+  return headers;
+}
+
+// api calls an endpoint and returns the decoded JSON body (null for 204);
+// errors carry the message and error_code of the response
+async function api(method, path, params, payload) {
+  const query = params ? "?" + new URLSearchParams(params).toString() : "";
+  const body = payload === undefined ? "" : JSON.stringify(payload);
+  const headers = await authHeaders(method, path, body);
+  if (body) {
+    headers["Content-Type"] = "application/json";
+  }
+  const resp = await fetch(path + query, { method, headers, body: body || undefined });
+  if (resp.status === 204) {
+    return null;
+  }
+  const data = await resp.json().catch(() => ({}));
+  if (!resp.ok) {
+    const err = new Error((data.error || resp.statusText) + (data.error_code ? " [" + data.error_code + "]" : ""));
+    err.status = resp.status;
+    throw err;
+  }
+  return data;
+}
+
+// ---- rendering -----------------------------------------------------------
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined && text !== null) {
+    node.textContent = text;
+  }
+  if (className) {
+    node.className = className;
+  }
+  return node;
+}
+
+function formatValue(value) {
+  if (value === null || value === undefined) {
+    return "NULL";
+  }
+  if (typeof value === "object") {
+    return JSON.stringify(value);
+  }
+  return String(value);
+}
+
+// renderGrid renders rows as a table. columns are names or {name} objects;
+// actions(row) may return buttons appended in a last column.
+function renderGrid(container, columns, rows, actions) {
+  container.replaceChildren();
+  let names = (columns || []).map((c) => (typeof c === "string" ? c : c.name));
+  if (names.length === 0 && rows.length > 0) {
+    names = Object.keys(rows[0]);
+  }
+  const table = el("table");
+  const head = el("tr");
+  names.forEach((n) => head.appendChild(el("th", n)));
+  if (actions) {
+    head.appendChild(el("th"));
+  }
+  table.appendChild(el("thead")).appendChild(head);
+  const tbody = table.appendChild(el("tbody"));
+  rows.forEach((row) => {
+    const tr = el("tr");
+    names.forEach((n) => {
+      const value = row[n];
+      tr.appendChild(el("td", formatValue(value), value === null || value === undefined ? "null" : ""));
+    });
+    if (actions) {
+      const td = el("td");
+      actions(row).forEach((b) => td.appendChild(b));
+      tr.appendChild(td);
+    }
+    tbody.appendChild(tr);
+  });
+  container.appendChild(table);
+  container.appendChild(el("p", rows.length + " row(s)", "status"));
+}
+
+function renderQueryResponse(container, resp) {
+  renderGrid(container, resp.columns, resp.rows || []);
+  if (resp.truncated) {
+    container.appendChild(el("p", "Result truncated", "status"));
+  }
+}
+
+function button(text, onClick) {
+  const b = el("button", text);
+  b.addEventListener("click", onClick);
+  return b;
+}
+
+function showError(err) {
+  $("error").textContent = err ? err.message : "";
+  if (err && err.status === 401) {
+    signOut();
+    $("login-error").textContent = err.message;
+  }
+}
+
+// guard runs an async action and reports its error
+function guard(fn) {
+  return async (event) => {
+    if (event) {
+      event.preventDefault();
+    }
+    showError(null);
+    try {
+      await fn();
+    } catch (err) {
+      showError(err);
+    }
+  };
+}
+
+// ---- views ---------------------------------------------------------------
+
+async function loadDatabases() {
+  const resp = await api("GET", "/api/v1/admin/databases");
+  const list = $("databases");
+  const select = $("query-database");
+  const current = select.value;
+  list.replaceChildren();
+  select.replaceChildren(el("option", ""));
+  resp.databases.forEach((db) => {
+    const item = el("li");
+    const name = el("span", db.name + (db.virtual ? " (virtual)" : ""), "db");
+    const tables = el("ul", null, "hidden");
+    name.addEventListener("click", guard(async () => {
+      tables.classList.toggle("hidden");
+      if (!tables.classList.contains("hidden") && tables.childElementCount === 0) {
+        await loadTables(db.name, tables);
+      }
+    }));
+    item.append(name, tables);
+    list.appendChild(item);
+    select.appendChild(el("option", db.name));
+  });
+  select.value = current;
+}
+
+async function loadTables(database, list) {
+  const resp = await api("GET", "/api/v1/admin/tables", { database });
+  list.replaceChildren();
+  resp.tables.forEach((table) => {
+    const item = el("li", table, "table");
+    item.addEventListener("click", guard(() => loadTable(database, table)));
+    list.appendChild(item);
+  });
+  if (resp.tables.length === 0) {
+    list.appendChild(el("li", "(no tables)", "status"));
+  }
+}
+
+async function loadTable(database, table) {
+  const resp = await api("GET", "/api/v1/admin/table", { database, table });
+  $("table-title").textContent = database + "." + table;
+  renderQueryResponse($("table-schema"), resp.schema);
+  renderQueryResponse($("table-sample"), resp.sample);
+  $("query-database").value = database;
+  switchTab("table");
+}
+
+async function runQuery(explain) {
+  let sql = $("query-sql").value.trim();
+  if (!sql) {
+    return;
+  }
+  if (explain && !/^explain\b/i.test(sql)) {
+    sql = "EXPLAIN " + sql;
+  }
+  const payload = { sql };
+  if ($("query-database").value) {
+    payload.database = $("query-database").value;
+  }
+  const started = performance.now();
+  const container = $("query-result");
+  container.replaceChildren();
+  const resp = await api("POST", "/api/v1/query", null, payload);
+  const elapsed = Math.round(performance.now() - started) + " ms";
+  if (resp.rows !== undefined) {
+    renderQueryResponse(container, resp);
+    $("query-status").textContent = elapsed;
+  } else {
+    $("query-status").textContent = resp.affected_rows + " row(s) affected, " + elapsed;
+  }
+}
+
+async function loadProcesses() {
+  renderQueryResponse($("processes-result"), await api("GET", "/api/v1/admin/processlist"));
+}
+
+async function loadSlowLog() {
+  const resp = await api("GET", "/api/v1/admin/slowlog");
+  $("slowlog-status").textContent = resp.threshold_ms > 0
+    ? "Threshold " + resp.threshold_ms + " ms"
+    : "Slow query log disabled (monitor.slow_query.threshold is 0)";
+  renderGrid($("slowlog-result"), ["time", "user", "duration_ms", "rows", "sql", "error"], resp.entries);
+}
+
+async function loadUsers() {
+  const resp = await api("GET", "/api/v1/admin/users");
+  const rows = resp.users.map((u) => Object.assign({}, u, { privileges: u.privileges.join(", ") }));
+  renderGrid($("users-result"), ["user", "host", "has_password", "privileges"], rows, (row) => [
+    button("Drop", guard(async () => {
+      if (confirm("Drop user " + row.user + "@" + row.host + "?")) {
+        await api("DELETE", "/api/v1/admin/users", { user: row.user, host: row.host });
+        await loadUsers();
+      }
+    })),
+  ]);
+}
+
+async function loadDatasources() {
+  const resp = await api("GET", "/api/v1/admin/datasources");
+  renderGrid($("datasources-result"), resp.columns, resp.rows || [], (row) => [
+    button("Remove", guard(async () => {
+      if (confirm("Remove datasource " + row.name + "?")) {
+        await api("DELETE", "/api/v1/admin/datasources", { name: row.name });
+        await loadDatasources();
+        await loadDatabases();
+      }
+    })),
+  ]);
+}
+
+const tabLoaders = {
+  processes: loadProcesses,
+  slowlog: loadSlowLog,
+  users: loadUsers,
+  datasources: loadDatasources,
+};
+
+function switchTab(name) {
+  document.querySelectorAll("#tabs button").forEach((b) => b.classList.toggle("active", b.dataset.tab === name));
+  document.querySelectorAll(".tab").forEach((t) => t.classList.toggle("hidden", t.id !== "tab-" + name));
+  if (tabLoaders[name]) {
+    guard(tabLoaders[name])();
+  }
+}
+
+// ---- sign-in -------------------------------------------------------------
+
+function updateLoginFields() {
+  const method = $("auth-method").value;
+  $("field-key").classList.toggle("hidden", method === "bearer");
+  $("field-secret").classList.toggle("hidden", method !== "signature");
+  $("field-token").classList.toggle("hidden", method !== "bearer");
+}
+
+async function signIn() {
+  const method = $("auth-method").value;
+  credentials = {
+    method,
+    key: $("auth-key").value.trim(),
+    secret: $("auth-secret").value,
+    token: $("auth-token").value.trim(),
+  };
+  try {
+    await api("GET", "/api/v1/admin/databases");
+  } catch (err) {
+    credentials = null;
+    $("login-error").textContent = err.message;
+    return;
+  }
+  sessionStorage.setItem(credentialsKey, JSON.stringify(credentials));
+  await showApp();
+}
+
+function signOut() {
+  credentials = null;
+  sessionStorage.removeItem(credentialsKey);
+  $("app").classList.add("hidden");
+  $("logout").classList.add("hidden");
+  $("login").classList.remove("hidden");
+  $("whoami").textContent = "";
+}
+
+async function showApp() {
+  $("login").classList.add("hidden");
+  $("login-error").textContent = "";
+  $("app").classList.remove("hidden");
+  $("logout").classList.remove("hidden");
+  $("whoami").textContent = credentials.method === "bearer" ? "token" : credentials.key;
+  await guard(loadDatabases)();
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("auth-method").addEventListener("change", updateLoginFields);
+  $("login-submit").addEventListener("click", guard(signIn));
+  $("logout").addEventListener("click", signOut);
+  $("refresh-databases").addEventListener("click", guard(loadDatabases));
+  $("query-run").addEventListener("click", guard(() => runQuery(false)));
+  $("query-explain").addEventListener("click", guard(() => runQuery(true)));
+  $("query-sql").addEventListener("keydown", (event) => {
+    if (event.key === "Enter" && (event.ctrlKey || event.metaKey)) {
+      guard(() => runQuery(false))(event);
+    }
+  });
+  $("processes-refresh").addEventListener("click", guard(loadProcesses));
+  $("slowlog-refresh").addEventListener("click", guard(loadSlowLog));
+  $("slowlog-clear").addEventListener("click", guard(async () => {
+    await api("DELETE", "/api/v1/admin/slowlog");
+    await loadSlowLog();
+  }));
+  $("user-form").addEventListener("submit", guard(async () => {
+    await api("POST", "/api/v1/admin/users", null, {
+      user: $("user-name").value.trim(),
+      host: $("user-host").value.trim(),
+      password: $("user-password").value,
+    });
+    $("user-form").reset();
+    await loadUsers();
+  }));
+  $("datasource-form").addEventListener("submit", guard(async () => {
+    const payload = {
+      name: $("ds-name").value.trim(),
+      type: $("ds-type").value,
+      host: $("ds-host").value.trim(),
+      database: $("ds-database").value.trim(),
+      username: $("ds-username").value.trim(),
+      password: $("ds-password").value,
+      writable: $("ds-writable").checked,
+    };
+    if ($("ds-port").value) {
+      payload.port = parseInt($("ds-port").value, 10);
+    }
+    await api("POST", "/api/v1/admin/datasources", null, payload);
+    $("datasource-form").reset();
+    await loadDatasources();
+    await loadDatabases();
+  }));
+  document.querySelectorAll("#tabs button").forEach((b) => b.addEventListener("click", () => switchTab(b.dataset.tab)));
+
+  updateLoginFields();
+  loadCredentials();
+  if (credentials) {
+    showApp();
+  }
+});
@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/workload"
+)
+
+// WorkloadHandler handles GET /api/v1/workload: concurrency and queue
+// statistics of each workload class
+type WorkloadHandler struct {
+	manager *workload.Manager
+}
+
+// NewWorkloadHandler creates a handler reporting the global workload manager
+func NewWorkloadHandler() *WorkloadHandler {
+	return &WorkloadHandler{manager: workload.GetManager()}
+}
+
+func (h *WorkloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+	if GetPrincipalFromContext(r.Context()) == nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	resp := WorkloadResponse{Enabled: h.manager.Enabled(), Classes: []workload.ClassStats{}}
+	if resp.Enabled {
+		resp.Classes = h.manager.Stats()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeStatementError reports a failed statement with the engine's error
+// code; statements rejected by workload admission get 503 so that clients
+// can retry later, writes rejected by a read-only scope or policy get 403
+func writeStatementError(w http.ResponseWriter, message string, err error) {
+	if api.IsErrorCode(err, api.ErrCodeReadOnly) {
+		writeError(w, http.StatusForbidden, string(api.ErrCodeReadOnly), err.Error())
+		return
+	}
+	var busy *workload.BusyError
+	if errors.As(err, &busy) {
+		writeError(w, http.StatusServiceUnavailable, ErrCodeServerBusy, busy.Error())
+		return
+	}
+	code := ErrCodeStatementFailed
+	if apiCode := api.GetErrorCode(err); apiCode != "" {
+		code = string(apiCode)
+	}
+	writeError(w, http.StatusBadRequest, code, message)
+}
@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/workload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWorkloadTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	clientStore := NewClientStore(env.configDir)
+	queryHandler := NewQueryHandler(env.db, env.configDir, env.auditLogger)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/query", AuthMiddleware(clientStore)(queryHandler))
+	mux.Handle("/api/v1/workload", AuthMiddleware(clientStore)(NewWorkloadHandler()))
+	server := httptest.NewServer(RecoveryMiddleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWorkload_StatsAndBusy(t *testing.T) {
+	env := setupTestEnv(t)
+	server := newWorkloadTestServer(t, env)
+
+	manager := workload.GetManager()
+	manager.Configure(workload.Config{
+		Enabled: true,
+		Classes: []workload.Class{{Name: "api", MaxConcurrency: 1}},
+		Rules:   []workload.Rule{{Class: "api", Users: []string{env.client.Name}}},
+	})
+	t.Cleanup(func() { manager.Configure(workload.Config{}) })
+
+	release, err := manager.Admit(context.Background(), workload.Request{User: env.client.Name})
+	require.NoError(t, err)
+	defer release()
+
+	// Statements rejected by admission control are reported as 503
+	resp := doSigned(t, env, server, "POST", "/api/v1/query", `{"sql":"SELECT 1"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp = doSigned(t, env, server, "GET", "/api/v1/workload", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var stats WorkloadResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	assert.True(t, stats.Enabled)
+	require.Len(t, stats.Classes, 2)
+	assert.Equal(t, "api", stats.Classes[0].Name)
+	assert.Equal(t, 1, stats.Classes[0].Running)
+	assert.EqualValues(t, 1, stats.Classes[0].Rejected)
+	assert.Equal(t, workload.DefaultClassName, stats.Classes[1].Name)
+
+	resp = doSigned(t, env, server, "POST", "/api/v1/workload", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
+	"github.com/kasuganosora/sqlexec/server/handler/handshake"
+)
+
+// Listener MySQL 协议的监听地址，接受的连接带有该地址的 TLS、只读和访问控制设置
+type Listener struct {
+	net.Listener
+	tlsConfig  *tls.Config  // 为 nil 时不支持 TLS
+	requireTLS bool         // 拒绝未使用 TLS 的客户端
+	readOnly   bool         // 连接只读
+	allowed    []*net.IPNet // 允许连接的客户端网段，为空时不限制
+
+	personality handshake.Personality // 握手时声明的服务器特征
+}
+
+// Listen 按配置打开一个监听地址。tcp 监听通配地址（如 0.0.0.0 或 ::）时同时接受 IPv4 和 IPv6 连接；
+// unix socket 文件是上次退出时遗留的（没有进程在监听）时先删除
+func Listen(cfg config.ListenerConfig) (*Listener, error) {
+	allowed, err := config.ParseAllowedHosts(cfg.AllowedHosts)
+	if err != nil {
+		return nil, err
+	}
+	personality, err := handshake.NewPersonality(cfg.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	l := &Listener{requireTLS: cfg.RequireTLS, readOnly: cfg.ReadOnly, allowed: allowed, personality: personality}
+	if cfg.TLS.Enabled() {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		l.tlsConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		}
+	}
+
+	network := cfg.NetworkOrDefault()
+	if network == "unix" {
+		removeStaleSocket(cfg.Address)
+	}
+	l.Listener, err = net.Listen(network, cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// removeStaleSocket 删除没有进程在监听的 unix socket 文件
+func removeStaleSocket(path string) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(path)
+}
+
+// ListenAll 打开服务器配置中的所有监听地址，未配置 listeners 时监听 host:port。
+// 任一地址打开失败时关闭已经打开的地址
+func ListenAll(cfg *config.ServerConfig) ([]*Listener, error) {
+	var listeners []*Listener
+	for _, lc := range cfg.ListenerConfigs() {
+		l, err := Listen(lc)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("listen %s %s: %w", lc.NetworkOrDefault(), lc.Address, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// Accept 接受连接，配置了 TLS 证书时返回的连接可以在握手时升级为 TLS
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	lc := listenerConn{Conn: conn, listener: l}
+	if l.tlsConfig != nil {
+		return &tlsConn{listenerConn: lc}, nil
+	}
+	return &lc, nil
+}
+
+// admit 检查客户端地址是否允许连接，unix socket 连接不受限制
+func (l *Listener) admit(conn net.Conn) error {
+	if len(l.allowed) == 0 {
+		return nil
+	}
+	addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return nil
+	}
+	for _, ipNet := range l.allowed {
+		if ipNet.Contains(addr.IP) {
+			return nil
+		}
+	}
+	return mysqlerrors.New(mysqlerrors.ErrHostNotPrivileged,
+		"Host '%s' is not allowed to connect to this MySQL server", addr.IP.String())
+}
+
+// acceptedConn 从 Listener 接受的连接
+type acceptedConn interface {
+	acceptedFrom() *Listener
+}
+
+// listenerConn 从 Listener 接受的连接，记录所属的监听地址
+type listenerConn struct {
+	net.Conn
+	listener *Listener
+}
+
+func (c *listenerConn) acceptedFrom() *Listener {
+	return c.listener
+}
+
+// Personality 实现 handshake.PersonalityConn
+func (c *listenerConn) Personality() handshake.Personality {
+	return c.listener.personality
+}
+
+// tlsConn 从配置了 TLS 证书的 Listener 接受的连接，实现 handler.TLSConn
+type tlsConn struct {
+	listenerConn
+}
+
+// StartTLS 在客户端发送 SSLRequest 后进行 TLS 握手，之后的读写都经过 TLS
+func (c *tlsConn) StartTLS() error {
+	if _, ok := c.Conn.(*tls.Conn); ok {
+		return errors.New("connection already uses TLS")
+	}
+	conn := tls.Server(c.Conn, c.listener.tlsConfig)
+	if err := conn.Handshake(); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	c.Conn = conn
+	return nil
+}
+
+// RequireTLS 是否拒绝未使用 TLS 的客户端
+func (c *tlsConn) RequireTLS() bool {
+	return c.listener.requireTLS
+}
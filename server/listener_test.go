@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startListenerServer 在配置的监听地址上启动服务器
+func startListenerServer(t *testing.T, listeners ...config.ListenerConfig) []*Listener {
+	cfg := config.DefaultConfig()
+	cfg.Server.Listeners = listeners
+	opened, err := ListenAll(&cfg.Server)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newTestServer(t, ctx, nil, cfg)
+	for _, l := range opened {
+		s.AddListener(l)
+	}
+	go s.Start()
+	t.Cleanup(func() {
+		cancel()
+		for _, l := range opened {
+			l.Close()
+		}
+	})
+	return opened
+}
+
+// writeListenerCert 生成自签名证书，返回证书和私钥文件路径
+func writeListenerCert(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func mysqlErrorNumber(t *testing.T, err error) uint16 {
+	t.Helper()
+	var mysqlErr *mysql.MySQLError
+	require.ErrorAs(t, err, &mysqlErr)
+	return mysqlErr.Number
+}
+
+func TestListener_MultipleAddresses(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "sqlexec.sock")
+	listeners := startListenerServer(t,
+		config.ListenerConfig{Address: "127.0.0.1:0"},
+		config.ListenerConfig{Network: "unix", Address: socket},
+		config.ListenerConfig{Address: "127.0.0.1:0", ReadOnly: true},
+	)
+
+	for _, dsn := range []string{
+		"root@tcp(" + listeners[0].Addr().String() + ")/default",
+		"root@unix(" + socket + ")/default",
+	} {
+		db, err := sql.Open("mysql", dsn)
+		require.NoError(t, err)
+		_, err = db.Exec("CREATE TABLE IF NOT EXISTS listener_t (id INT PRIMARY KEY)")
+		assert.NoError(t, err, dsn)
+		require.NoError(t, db.Close())
+	}
+
+	// 只读监听地址上可以查询，不能写入
+	db, err := sql.Open("mysql", "root@tcp("+listeners[2].Addr().String()+")/default")
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM listener_t").Scan(&n))
+	_, err = db.Exec("INSERT INTO listener_t VALUES (1)")
+	assert.Equal(t, uint16(1290), mysqlErrorNumber(t, err))
+}
+
+func TestListener_AllowedHosts(t *testing.T) {
+	listeners := startListenerServer(t,
+		config.ListenerConfig{Address: "127.0.0.1:0", AllowedHosts: []string{"10.0.0.0/8"}},
+		config.ListenerConfig{Address: "127.0.0.1:0", AllowedHosts: []string{"10.0.0.0/8", "127.0.0.1"}},
+	)
+
+	db, err := sql.Open("mysql", "root@tcp("+listeners[0].Addr().String()+")/default")
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, uint16(1130), mysqlErrorNumber(t, db.Ping()))
+
+	db2, err := sql.Open("mysql", "root@tcp("+listeners[1].Addr().String()+")/default")
+	require.NoError(t, err)
+	defer db2.Close()
+	assert.NoError(t, db2.Ping())
+}
+
+func TestListener_TLS(t *testing.T) {
+	certFile, keyFile := writeListenerCert(t)
+	tlsConfig := config.HTTPTLSConfig{CertFile: certFile, KeyFile: keyFile}
+	listeners := startListenerServer(t,
+		config.ListenerConfig{Address: "127.0.0.1:0", TLS: tlsConfig},
+		config.ListenerConfig{Address: "127.0.0.1:0", TLS: tlsConfig, RequireTLS: true},
+	)
+	require.NoError(t, mysql.RegisterTLSConfig("listener-test", &tls.Config{InsecureSkipVerify: true}))
+	defer mysql.DeregisterTLSConfig("listener-test")
+
+	for _, l := range listeners {
+		db, err := sql.Open("mysql", "root@tcp("+l.Addr().String()+")/default?tls=listener-test")
+		require.NoError(t, err)
+		var one int
+		assert.NoError(t, db.QueryRow("SELECT 1").Scan(&one))
+		require.NoError(t, db.Close())
+	}
+
+	// 未要求 TLS 的地址仍接受明文连接
+	db, err := sql.Open("mysql", "root@tcp("+listeners[0].Addr().String()+")/default")
+	require.NoError(t, err)
+	assert.NoError(t, db.Ping())
+	require.NoError(t, db.Close())
+
+	db, err = sql.Open("mysql", "root@tcp("+listeners[1].Addr().String()+")/default")
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, uint16(3159), mysqlErrorNumber(t, db.Ping()))
+}
+
+func TestListener_Personality(t *testing.T) {
+	listeners := startListenerServer(t,
+		config.ListenerConfig{Address: "127.0.0.1:0", Protocol: config.ProtocolConfig{Personality: "mysql-8.0"}},
+		config.ListenerConfig{Address: "127.0.0.1:0", Protocol: config.ProtocolConfig{Personality: "mariadb-10.6"}},
+	)
+
+	for _, l := range listeners {
+		db, err := sql.Open("mysql", "root@tcp("+l.Addr().String()+")/default")
+		require.NoError(t, err)
+		var one int
+		assert.NoError(t, db.QueryRow("SELECT 1").Scan(&one))
+		require.NoError(t, db.Close())
+	}
+
+	_, err := Listen(config.ListenerConfig{Address: "127.0.0.1:0", Protocol: config.ProtocolConfig{Personality: "oracle"}})
+	assert.Error(t, err)
+}
+
+func TestListen_RemovesStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "sqlexec.sock")
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	l, err := Listen(config.ListenerConfig{Network: "unix", Address: socket})
+	require.NoError(t, err)
+	defer l.Close()
+
+	// 有进程在监听的 socket 不会被删除
+	_, err = Listen(config.ListenerConfig{Network: "unix", Address: socket})
+	assert.Error(t, err)
+}
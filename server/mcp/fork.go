@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// forkIdleTimeout discards a fork that has not been used for this long
+const forkIdleTimeout = 30 * time.Minute
+
+// forkRegistry holds the open fork of each MCP session. A fork belongs to the
+// session and API client that created it; forks idle longer than forkIdleTimeout
+// are discarded the next time the registry is used.
+type forkRegistry struct {
+	mu    sync.Mutex
+	forks map[string]*sessionFork // client name + MCP session ID -> fork
+}
+
+type sessionFork struct {
+	fork     *api.Fork
+	lastUsed time.Time
+}
+
+// get returns the open fork of key and marks it used, or nil
+func (r *forkRegistry) get(key string) *api.Fork {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweepLocked(time.Now())
+	sf, ok := r.forks[key]
+	if !ok {
+		return nil
+	}
+	sf.lastUsed = time.Now()
+	return sf.fork
+}
+
+// put registers fork as the open fork of key
+func (r *forkRegistry) put(key string, fork *api.Fork) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.forks == nil {
+		r.forks = make(map[string]*sessionFork)
+	}
+	r.forks[key] = &sessionFork{fork: fork, lastUsed: time.Now()}
+}
+
+// remove forgets the fork of key without discarding it
+func (r *forkRegistry) remove(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.forks, key)
+}
+
+// sweepLocked discards idle and closed forks. The caller holds r.mu.
+func (r *forkRegistry) sweepLocked(now time.Time) {
+	for key, sf := range r.forks {
+		if sf.fork.Closed() || now.Sub(sf.lastUsed) >= forkIdleTimeout {
+			sf.fork.Discard()
+			delete(r.forks, key)
+		}
+	}
+}
+
+// forkKey returns the registry key of the calling MCP session, or "" when the
+// request does not belong to a session
+func forkKey(ctx context.Context, clientName string) string {
+	session := mcpserver.ClientSessionFromContext(ctx)
+	if session == nil || session.SessionID() == "" {
+		return ""
+	}
+	return clientName + "\x00" + session.SessionID()
+}
+
+// HandleFork forks tables of a database for the calling MCP session
+func (d *ToolDeps) HandleFork(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if errResult, ok := requireAuth(ctx); !ok {
+		return errResult, nil
+	}
+
+	database := request.GetString("database", "")
+	tables := request.GetStringSlice("tables", nil)
+	if len(tables) == 0 {
+		return mcp.NewToolResultError("tables parameter is required"), nil
+	}
+
+	clientName := getClient(ctx).Name
+	clientIP := getClientIP(ctx)
+	traceID := fmt.Sprintf("mcp-%d", time.Now().UnixMilli())
+	start := time.Now()
+	args := map[string]interface{}{"database": database, "tables": tables}
+
+	key := forkKey(ctx, clientName)
+	if key == "" {
+		return mcp.NewToolResultError("fork requires an MCP session"), nil
+	}
+	if d.forks.get(key) != nil {
+		return mcp.NewToolResultError("this session already has a fork; merge or discard it first"), nil
+	}
+
+	fork, err := d.DB.Fork(database, tables)
+	if err != nil {
+		d.logToolCall(traceID, clientName, clientIP, "fork", args, time.Since(start).Milliseconds(), false)
+		return mcp.NewToolResultError(fmt.Sprintf("fork failed: %v", err)), nil
+	}
+	d.forks.put(key, fork)
+
+	d.logToolCall(traceID, clientName, clientIP, "fork", args, time.Since(start).Milliseconds(), true)
+	return mcp.NewToolResultText(fmt.Sprintf(
+		"Forked %s in database %s. INSERT, UPDATE and DELETE on these tables in this session now only change the fork; "+
+			"call merge_fork to apply the changes or discard_fork to drop them. Unused forks are discarded after %s.",
+		strings.Join(fork.Tables(), ", "), fork.Database(), forkIdleTimeout)), nil
+}
+
+// HandleMergeFork applies the changes of the session's fork to the real tables
+func (d *ToolDeps) HandleMergeFork(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if errResult, ok := requireAuth(ctx); !ok {
+		return errResult, nil
+	}
+
+	clientName := getClient(ctx).Name
+	clientIP := getClientIP(ctx)
+	traceID := fmt.Sprintf("mcp-%d", time.Now().UnixMilli())
+	start := time.Now()
+
+	key := forkKey(ctx, clientName)
+	fork := d.forks.get(key)
+	if fork == nil {
+		return mcp.NewToolResultError("this session has no fork"), nil
+	}
+
+	result, err := fork.Merge()
+	if err != nil {
+		d.logToolCall(traceID, clientName, clientIP, "merge_fork", map[string]interface{}{"database": fork.Database()}, time.Since(start).Milliseconds(), false)
+		return mcp.NewToolResultError(fmt.Sprintf("merge failed: %v", err)), nil
+	}
+	d.forks.remove(key)
+
+	tables := make([]string, 0, len(result.Changes))
+	for table := range result.Changes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Merged fork into %s:\n", fork.Database()))
+	for _, table := range tables {
+		sb.WriteString(fmt.Sprintf("- %s: %d row changes\n", table, result.Changes[table]))
+	}
+	if len(tables) == 0 {
+		sb.WriteString("(no changes)\n")
+	}
+
+	d.logToolCall(traceID, clientName, clientIP, "merge_fork", map[string]interface{}{"database": fork.Database()}, time.Since(start).Milliseconds(), true)
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// HandleDiscardFork drops the session's fork without touching the real tables
+func (d *ToolDeps) HandleDiscardFork(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if errResult, ok := requireAuth(ctx); !ok {
+		return errResult, nil
+	}
+
+	clientName := getClient(ctx).Name
+	clientIP := getClientIP(ctx)
+	traceID := fmt.Sprintf("mcp-%d", time.Now().UnixMilli())
+	start := time.Now()
+
+	key := forkKey(ctx, clientName)
+	fork := d.forks.get(key)
+	if fork == nil {
+		return mcp.NewToolResultError("this session has no fork"), nil
+	}
+	fork.Discard()
+	d.forks.remove(key)
+
+	d.logToolCall(traceID, clientName, clientIP, "discard_fork", map[string]interface{}{"database": fork.Database()}, time.Since(start).Milliseconds(), true)
+	return mcp.NewToolResultText(fmt.Sprintf("Discarded fork of %s", fork.Database())), nil
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionCtx returns an authenticated context belonging to the MCP session id
+func sessionCtx(id string) context.Context {
+	return mcpserver.NewMCPServer("test", "1.0.0").WithContext(authedCtx(), mcpserver.NewInProcessSession(id, nil))
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.NotEmpty(t, result.Content)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func setupForkDeps(t *testing.T) *ToolDeps {
+	deps := setupTestDeps(t)
+	session := deps.DB.Session()
+	defer session.Close()
+	_, err := session.Execute("CREATE TABLE stock (id INT PRIMARY KEY, qty INT)")
+	require.NoError(t, err)
+	_, err = session.Execute("INSERT INTO stock VALUES (1, 10), (2, 20)")
+	require.NoError(t, err)
+	return deps
+}
+
+func TestFork_MergeFlow(t *testing.T) {
+	deps := setupForkDeps(t)
+	ctx, other := sessionCtx("s1"), sessionCtx("s2")
+
+	result, err := deps.HandleFork(ctx, makeCallToolRequest(map[string]interface{}{"tables": []interface{}{"stock"}}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "UPDATE stock SET qty = 0 WHERE id = 1"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), "in fork, not merged")
+
+	// The fork sees its own change, other sessions do not
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "SELECT qty FROM stock WHERE id = 1"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "0\n")
+	result, err = deps.HandleQuery(other, makeCallToolRequest(map[string]interface{}{"sql": "SELECT qty FROM stock WHERE id = 1"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "10\n")
+
+	// Only forked tables can be written, and only in the forked database
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "CREATE TABLE t2 (id INT)"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "SELECT 1", "database": "other"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Another session cannot merge this session's fork
+	result, err = deps.HandleMergeFork(other, makeCallToolRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = deps.HandleMergeFork(ctx, makeCallToolRequest(nil))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), "stock: 1 row changes")
+
+	result, err = deps.HandleQuery(other, makeCallToolRequest(map[string]interface{}{"sql": "SELECT qty FROM stock WHERE id = 1"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "0\n")
+	assert.NotContains(t, resultText(t, result), "10\n")
+
+	// After the merge the session writes the real tables again
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "UPDATE stock SET qty = 5 WHERE id = 2"}))
+	require.NoError(t, err)
+	assert.Equal(t, "Affected rows: 1", resultText(t, result))
+}
+
+func TestFork_DiscardAndErrors(t *testing.T) {
+	deps := setupForkDeps(t)
+	ctx := sessionCtx("s1")
+
+	// Without an MCP session or tables there is nothing to fork
+	result, err := deps.HandleFork(authedCtx(), makeCallToolRequest(map[string]interface{}{"tables": []interface{}{"stock"}}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	result, err = deps.HandleFork(ctx, makeCallToolRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	result, err = deps.HandleFork(ctx, makeCallToolRequest(map[string]interface{}{"tables": []interface{}{"missing"}}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	result, err = deps.HandleDiscardFork(ctx, makeCallToolRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = deps.HandleFork(ctx, makeCallToolRequest(map[string]interface{}{"database": "default", "tables": []interface{}{"stock"}}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	result, err = deps.HandleFork(ctx, makeCallToolRequest(map[string]interface{}{"tables": []interface{}{"stock"}}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "DELETE FROM stock"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	result, err = deps.HandleDiscardFork(ctx, makeCallToolRequest(nil))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "SELECT * FROM stock"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "(2 rows)")
+}
+
+func TestForkRegistry_IdleTimeout(t *testing.T) {
+	deps := setupForkDeps(t)
+	fork, err := deps.DB.Fork("", []string{"stock"})
+	require.NoError(t, err)
+
+	deps.forks.put("k", fork)
+	assert.Same(t, fork, deps.forks.get("k"))
+
+	deps.forks.forks["k"].lastUsed = time.Now().Add(-forkIdleTimeout)
+	assert.Nil(t, deps.forks.get("k"))
+	assert.True(t, fork.Closed())
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// HandleLint checks SQL against the current schema without executing it
+func (d *ToolDeps) HandleLint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if errResult, ok := requireAuth(ctx); !ok {
+		return errResult, nil
+	}
+
+	sql := request.GetString("sql", "")
+	database := request.GetString("database", "")
+	if sql == "" {
+		return mcp.NewToolResultError("sql parameter is required"), nil
+	}
+
+	clientName := getClient(ctx).Name
+	clientIP := getClientIP(ctx)
+	traceID := fmt.Sprintf("mcp-%d", time.Now().UnixMilli())
+	start := time.Now()
+	args := map[string]interface{}{"sql": sql, "database": database}
+
+	session := d.DB.Session()
+	defer session.Close()
+	if d.VDBRegistry != nil {
+		session.SetVirtualDBRegistry(d.VDBRegistry)
+	}
+	session.SetTraceID(traceID)
+	if database != "" {
+		session.SetCurrentDB(database)
+	}
+
+	reports, err := session.Lint(sql)
+	if err != nil {
+		d.logToolCall(traceID, clientName, clientIP, "lint", args, time.Since(start).Milliseconds(), false)
+		return mcp.NewToolResultError(fmt.Sprintf("lint failed: %v", err)), nil
+	}
+
+	d.logToolCall(traceID, clientName, clientIP, "lint", args, time.Since(start).Milliseconds(), true)
+	return mcp.NewToolResultText(formatLintReports(reports)), nil
+}
+
+// formatLintReports renders one block per statement: its issues, then the
+// estimated cost with the access method of each table
+func formatLintReports(reports []*api.LintReport) string {
+	var sb strings.Builder
+	errors, warnings := 0, 0
+	for i, report := range reports {
+		sb.WriteString(fmt.Sprintf("Statement %d: %s\n", i+1, report.SQL))
+		for _, issue := range report.Issues {
+			if issue.Severity == api.LintError {
+				errors++
+			} else {
+				warnings++
+			}
+			sb.WriteString(fmt.Sprintf("  %s [%s] %s", issue.Severity, issue.Rule, issue.Message))
+			if issue.Line > 0 {
+				sb.WriteString(fmt.Sprintf(" (line %d, column %d)", issue.Line, issue.Column))
+			}
+			sb.WriteString("\n")
+		}
+		if report.Cost != "" {
+			tables := make([]string, len(report.Tables))
+			for j, t := range report.Tables {
+				rows := "unknown rows"
+				if t.Rows >= 0 {
+					rows = fmt.Sprintf("%d rows", t.Rows)
+				}
+				tables[j] = fmt.Sprintf("%s %s, %s", t.Table, t.Access, rows)
+			}
+			sb.WriteString(fmt.Sprintf("  cost: %s", report.Cost))
+			if len(tables) > 0 {
+				sb.WriteString(" (" + strings.Join(tables, "; ") + ")")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(fmt.Sprintf("(%d errors, %d warnings)", errors, warnings))
+	return sb.String()
+}
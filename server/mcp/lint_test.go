@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleLint(t *testing.T) {
+	deps := setupForkDeps(t)
+	ctx := authedCtx()
+
+	result, err := deps.HandleLint(ctx, makeCallToolRequest(map[string]interface{}{
+		"sql": "SELECT qtty FROM stock WHERE id = 1;\nDELETE FROM stock",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	text := resultText(t, result)
+	assert.Contains(t, text, "Statement 1: SELECT qtty FROM stock WHERE id = 1")
+	assert.Contains(t, text, "error [unknown_column]")
+	assert.Contains(t, text, "cost: low (stock point, 2 rows)")
+	assert.Contains(t, text, "warning [missing_where]")
+	assert.Contains(t, text, "(1 errors, 1 warnings)")
+
+	// Nothing was executed
+	result, err = deps.HandleQuery(ctx, makeCallToolRequest(map[string]interface{}{"sql": "SELECT * FROM stock"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "(2 rows)")
+
+	result, err = deps.HandleLint(ctx, makeCallToolRequest(map[string]interface{}{"sql": "SELEC 1"}))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "error [syntax]")
+	assert.Contains(t, resultText(t, result), "(line 1, column")
+
+	result, err = deps.HandleLint(ctx, makeCallToolRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -3,19 +3,22 @@ package mcp
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
 	"github.com/kasuganosora/sqlexec/pkg/config"
 	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
 	"github.com/kasuganosora/sqlexec/pkg/security"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
+// mcpLog is the structured logger of the MCP server
+var mcpLog = logging.Module("mcp")
+
 // Server is the MCP protocol server
 type Server struct {
 	db          *api.DB
@@ -61,7 +64,8 @@ func (s *Server) Start() error {
 
 	// Register tools
 	queryTool := mcp.NewTool("query",
-		mcp.WithDescription("Execute a SQL query against the database. Supports SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, SHOW, DESCRIBE, and other SQL statements."),
+		mcp.WithDescription("Execute a SQL query against the database. Supports SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, SHOW, DESCRIBE, and other SQL statements. "+
+			"To look at representative rows of a large table, sample it instead of scanning it, e.g. SELECT * FROM t TABLESAMPLE BERNOULLI(1) LIMIT 50."),
 		mcp.WithString("sql", mcp.Description("The SQL query to execute"), mcp.Required()),
 		mcp.WithString("database", mcp.Description("The database to query (optional, uses default if not specified)")),
 		mcp.WithString("trace_id", mcp.Description("Optional trace ID for request tracing and audit logging")),
@@ -82,10 +86,38 @@ func (s *Server) Start() error {
 		mcp.WithString("table", mcp.Description("The table name"), mcp.Required()),
 	)
 
+	forkTool := mcp.NewTool("fork",
+		mcp.WithDescription("Fork tables for what-if analysis. Takes a snapshot of the tables for this session; INSERT, UPDATE and DELETE on them "+
+			"through the query tool then change only the snapshot, and other tables become read-only. Finish with merge_fork or discard_fork."),
+		mcp.WithString("database", mcp.Description("The database of the tables (optional, uses default if not specified)")),
+		mcp.WithArray("tables", mcp.Description("The tables to fork"), mcp.WithStringItems(), mcp.Required()),
+	)
+
+	mergeForkTool := mcp.NewTool("merge_fork",
+		mcp.WithDescription("Apply the changes made in this session's fork to the real tables and close the fork. "+
+			"Fails with a conflict, keeping the fork open, if a changed row was modified by someone else after the fork was taken."),
+	)
+
+	discardForkTool := mcp.NewTool("discard_fork",
+		mcp.WithDescription("Drop this session's fork and all changes made in it; the real tables are not touched"),
+	)
+
+	lintTool := mcp.NewTool("lint",
+		mcp.WithDescription("Check SQL without executing it: reports syntax errors with line and column, unknown tables and columns, "+
+			"type mismatches in comparisons, UPDATE or DELETE without WHERE, and an estimated cost (low, medium, high) from the "+
+			"access method and row count of each table. Use it before running a statement written against an unfamiliar schema."),
+		mcp.WithString("sql", mcp.Description("One or more SQL statements separated by semicolons"), mcp.Required()),
+		mcp.WithString("database", mcp.Description("The database to check against (optional, uses default if not specified)")),
+	)
+
 	mcpSrv.AddTool(queryTool, deps.HandleQuery)
 	mcpSrv.AddTool(listDBTool, deps.HandleListDatabases)
 	mcpSrv.AddTool(listTablesTool, deps.HandleListTables)
 	mcpSrv.AddTool(describeTableTool, deps.HandleDescribeTable)
+	mcpSrv.AddTool(forkTool, deps.HandleFork)
+	mcpSrv.AddTool(mergeForkTool, deps.HandleMergeFork)
+	mcpSrv.AddTool(discardForkTool, deps.HandleDiscardFork)
+	mcpSrv.AddTool(lintTool, deps.HandleLint)
 
 	// Create Streamable HTTP transport with auth
 	clientStore := config_schema.LoadAPIClients
@@ -95,7 +127,7 @@ func (s *Server) Start() error {
 		mcpserver.WithHTTPContextFunc(s.authContextFunc(clientStore)),
 	)
 
-	log.Printf("[MCP] 启动 MCP 服务器: %s", addr)
+	mcpLog.Info("启动 MCP 服务器", "addr", addr)
 	return httpServer.Start(addr)
 }
 
@@ -121,7 +153,7 @@ func (s *Server) authContextFunc(loadClients func(string) ([]config_schema.APICl
 
 		clients, err := loadClients(s.configDir)
 		if err != nil {
-			log.Printf("[MCP] failed to load API clients: %v", err)
+			mcpLog.Error("failed to load API clients", "err", err)
 			return ctx
 		}
 
@@ -30,6 +30,8 @@ type ToolDeps struct {
 	ConfigDir   string
 	VDBRegistry *virtual.VirtualDatabaseRegistry
 	AuditLogger *security.AuditLogger
+
+	forks forkRegistry // open forks of MCP sessions
 }
 
 // requireAuth checks that the MCP request is authenticated and returns the client.
@@ -80,6 +82,14 @@ func (d *ToolDeps) HandleQuery(ctx context.Context, request mcp.CallToolRequest)
 	if database != "" {
 		session.SetCurrentDB(database)
 	}
+	// Queries of a forked MCP session read and write the fork
+	fork := d.forks.get(forkKey(ctx, clientName))
+	if fork != nil {
+		if database != "" && database != fork.Database() {
+			return mcp.NewToolResultError(fmt.Sprintf("this session is forked from database %s; merge or discard the fork to query %s", fork.Database(), database)), nil
+		}
+		session.SetFork(fork)
+	}
 
 	sqlUpper := strings.TrimSpace(strings.ToUpper(sql))
 	isRead := strings.HasPrefix(sqlUpper, "SELECT") ||
@@ -137,6 +147,9 @@ func (d *ToolDeps) HandleQuery(ctx context.Context, request mcp.CallToolRequest)
 			return mcp.NewToolResultError(fmt.Sprintf("execute failed: %v", err)), nil
 		}
 		resultText = fmt.Sprintf("Affected rows: %d", result.RowsAffected)
+		if fork != nil {
+			resultText += " (in fork, not merged)"
+		}
 	}
 
 	d.logToolCall(traceID, clientName, clientIP, "query", map[string]interface{}{"sql": sql, "database": database}, time.Since(start).Milliseconds(), true)
@@ -284,7 +297,7 @@ func (d *ToolDeps) HandleDescribeTable(ctx context.Context, request mcp.CallTool
 	}
 	session.SetCurrentDB(database)
 
-	query, err := session.Query(fmt.Sprintf("SHOW COLUMNS FROM `%s`", table))
+	query, err := session.Query("SHOW COLUMNS FROM " + security.QuoteIdentifier(table))
 	if err != nil {
 		d.logToolCall(traceID, clientName, clientIP, "describe_table", map[string]interface{}{"database": database, "table": table}, time.Since(start).Milliseconds(), false)
 		return mcp.NewToolResultError(fmt.Sprintf("failed to describe table: %v", err)), nil
@@ -0,0 +1,87 @@
+package protocol
+
+import (
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// ColumnFlags 根据列定义计算列定义包中的 flags 字段
+// 文本结果集、二进制结果集和 COM_FIELD_LIST 共用同一套规则，保证客户端看到的列属性一致
+func ColumnFlags(col domain.ColumnInfo) uint16 {
+	var flags uint16
+	if !col.Nullable {
+		flags |= NOT_NULL_FLAG
+	}
+	if col.Primary {
+		flags |= PRI_KEY_FLAG
+	}
+	if col.Unique {
+		flags |= UNIQUE_KEY_FLAG
+	}
+	if col.AutoIncrement {
+		flags |= AUTO_INCREMENT_FLAG
+	}
+	if col.IsUnsigned() {
+		flags |= UNSIGNED_FLAG
+	}
+	if col.Zerofill || strings.Contains(strings.ToLower(col.Type), "zerofill") {
+		flags |= ZEROFILL_FLAG
+	}
+	if isBinaryColumn(col) {
+		flags |= BINARY_COLLATION_FLAG
+	}
+	return flags
+}
+
+// isBinaryColumn 判断列是否按字节比较（BINARY/VARBINARY/BLOB 类型或 binary 排序规则）
+func isBinaryColumn(col domain.ColumnInfo) bool {
+	if strings.EqualFold(col.Collation, "binary") || strings.EqualFold(col.Charset, "binary") {
+		return true
+	}
+	base, _, _ := SplitColumnType(col.Type)
+	spec, ok := columnTypes[base]
+	if !ok || !spec.binary {
+		return false
+	}
+	switch spec.tp {
+	case MYSQL_TYPE_STRING, MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_BLOB:
+		return true
+	}
+	return false
+}
+
+// UnsignedValue 将无符号列中的有符号整数按列宽解释为无符号值
+// 数据源可能以 int64 等有符号类型保存 UNSIGNED 列，负数需要按补码还原成 MySQL 的无符号表示
+func UnsignedValue(value any, columnType uint8) any {
+	var n int64
+	switch v := value.(type) {
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	default:
+		return value
+	}
+	if n >= 0 {
+		return uint64(n)
+	}
+	switch columnType {
+	case MYSQL_TYPE_TINY:
+		return uint64(uint8(n))
+	case MYSQL_TYPE_SHORT, MYSQL_TYPE_YEAR:
+		return uint64(uint16(n))
+	case MYSQL_TYPE_INT24:
+		return uint64(uint32(n) & 0xffffff)
+	case MYSQL_TYPE_LONG:
+		return uint64(uint32(n))
+	default:
+		return uint64(n)
+	}
+}
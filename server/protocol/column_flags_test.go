@@ -0,0 +1,62 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestColumnFlags 测试列定义到 flags 的映射
+func TestColumnFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		col  domain.ColumnInfo
+		want uint16
+	}{
+		{"nullable", domain.ColumnInfo{Type: "varchar(20)", Nullable: true}, 0},
+		{"not null", domain.ColumnInfo{Type: "varchar(20)"}, NOT_NULL_FLAG},
+		{"primary auto increment", domain.ColumnInfo{Type: "bigint", Primary: true, AutoIncrement: true},
+			NOT_NULL_FLAG | PRI_KEY_FLAG | AUTO_INCREMENT_FLAG},
+		{"unique", domain.ColumnInfo{Type: "int", Nullable: true, Unique: true}, UNIQUE_KEY_FLAG},
+		{"unsigned field", domain.ColumnInfo{Type: "int", Nullable: true, Unsigned: true}, UNSIGNED_FLAG},
+		{"unsigned in type", domain.ColumnInfo{Type: "int(10) unsigned", Nullable: true}, UNSIGNED_FLAG},
+		{"zerofill", domain.ColumnInfo{Type: "int", Nullable: true, Zerofill: true}, UNSIGNED_FLAG | ZEROFILL_FLAG},
+		{"varbinary", domain.ColumnInfo{Type: "varbinary(16)", Nullable: true}, BINARY_COLLATION_FLAG},
+		{"binary collation", domain.ColumnInfo{Type: "varchar(16)", Nullable: true, Collation: "binary"}, BINARY_COLLATION_FLAG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ColumnFlags(tt.col))
+		})
+	}
+}
+
+// TestUnsignedValue 测试有符号整数按列宽还原为无符号值
+func TestUnsignedValue(t *testing.T) {
+	assert.Equal(t, uint64(255), UnsignedValue(int8(-1), MYSQL_TYPE_TINY))
+	assert.Equal(t, uint64(65535), UnsignedValue(int64(-1), MYSQL_TYPE_SHORT))
+	assert.Equal(t, uint64(16777215), UnsignedValue(int64(-1), MYSQL_TYPE_INT24))
+	assert.Equal(t, uint64(4294967295), UnsignedValue(int32(-1), MYSQL_TYPE_LONG))
+	assert.Equal(t, uint64(18446744073709551615), UnsignedValue(int64(-1), MYSQL_TYPE_LONGLONG))
+	assert.Equal(t, uint64(42), UnsignedValue(42, MYSQL_TYPE_LONG))
+	assert.Equal(t, "abc", UnsignedValue("abc", MYSQL_TYPE_LONG))
+}
+
+// TestBinaryRowDataPacket_UnsignedIntegers 测试二进制结果集写出无符号整数
+func TestBinaryRowDataPacket_UnsignedIntegers(t *testing.T) {
+	p := &BinaryRowDataPacket{Values: []any{uint8(200), uint32(4294967295), uint64(1 << 63), 7}}
+	types := []uint8{MYSQL_TYPE_TINY, MYSQL_TYPE_LONG, MYSQL_TYPE_LONGLONG, MYSQL_TYPE_SHORT}
+	data, err := p.Marshal(uint64(len(types)), types)
+	require.NoError(t, err)
+
+	// 0x00 包头 + 1 字节 NULL 位图
+	assert.Equal(t, []byte{
+		0x00, 0x00,
+		0xc8,
+		0xff, 0xff, 0xff, 0xff,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80,
+		0x07, 0x00,
+	}, data)
+}
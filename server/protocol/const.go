@@ -44,6 +44,12 @@ const (
 	MARIADB_CLIENT_EXTENDED_METADATA = 1 << 1 // 客户端可以处理扩展元数据（如'point', 'json'）
 )
 
+// MariaDB 扩展能力（握手包和握手响应中的 MariaDBCaps 字段），
+// 仅当服务器未声明 CLIENT_LONG_PASSWORD（即 CLIENT_MYSQL）时客户端才会发送
+const (
+	MARIADB_CLIENT_PROGRESS = 1 << 0 // 客户端可以接收进度报告包
+)
+
 // MySQL字段类型常量
 const (
 	MYSQL_TYPE_DECIMAL     = 0x00
@@ -63,12 +69,13 @@ const (
 	MYSQL_TYPE_NEWDATE     = 0x0e
 	MYSQL_TYPE_VARCHAR     = 0x0f
 	MYSQL_TYPE_BIT         = 0x10
+	MYSQL_TYPE_JSON        = 0xf5
 	MYSQL_TYPE_NEWDECIMAL  = 0xf6
 	MYSQL_TYPE_ENUM        = 0xf7
 	MYSQL_TYPE_SET         = 0xf8
-	MYSQL_TYPE_TINY_BLOB   = 0xfc
-	MYSQL_TYPE_MEDIUM_BLOB = 0xfd
-	MYSQL_TYPE_LONG_BLOB   = 0xfe
+	MYSQL_TYPE_TINY_BLOB   = 0xf9
+	MYSQL_TYPE_MEDIUM_BLOB = 0xfa
+	MYSQL_TYPE_LONG_BLOB   = 0xfb
 	MYSQL_TYPE_BLOB        = 0xfc
 	MYSQL_TYPE_VAR_STRING  = 0xfd
 	MYSQL_TYPE_STRING      = 0xfe
@@ -112,6 +119,12 @@ const (
 	SERVER_SESSION_STATE_CHANGED       = 1 << 14   // 16384 (1<<14)
 )
 
+// COM_SET_OPTION 选项
+const (
+	MYSQL_OPTION_MULTI_STATEMENTS_ON  = 0
+	MYSQL_OPTION_MULTI_STATEMENTS_OFF = 1
+)
+
 // MySQL 命令常量表
 // 参考: https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_command_packets.html
 const (
@@ -145,6 +158,7 @@ const (
 	COM_SET_OPTION          = 0x1b // 设置选项
 	COM_STMT_FETCH          = 0x1c // 获取数据
 	COM_DAEMON              = 0x1d // 守护进程
+	COM_RESET_CONNECTION    = 0x1f // 重置会话状态
 	COM_ERROR               = 0xff // 错误包
 )
 
@@ -185,6 +199,7 @@ var CommandNames = map[uint8]string{
 	COM_SET_OPTION:          "COM_SET_OPTION",
 	COM_STMT_FETCH:          "COM_STMT_FETCH",
 	COM_DAEMON:              "COM_DAEMON",
+	COM_RESET_CONNECTION:    "COM_RESET_CONNECTION",
 	COM_ERROR:               "COM_ERROR",
 }
 
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"net"
 	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 )
 
 // NewHandshakePacket 创建一个新的握手包
@@ -56,17 +58,18 @@ func SendOK(conn net.Conn, sequenceID uint8) error {
 	return okPacket.Send(conn)
 }
 
-// SendError 发送一个错误包
+// SendError 发送一个错误包，错误码和 SQLSTATE 由 mysqlerrors 归类得到
 func SendError(conn net.Conn, err error) error {
+	code, state := mysqlerrors.Classify(err)
 	errorPacket := &ErrorPacket{
 		Packet: Packet{
 			SequenceID: 1,
 		},
 		ErrorInPacket: ErrorInPacket{
 			Header:         0xFF,
-			ErrorCode:      1045,
+			ErrorCode:      code,
 			SqlStateMarker: "#",
-			SqlState:       "28000",
+			SqlState:       state,
 			ErrorMessage:   err.Error(),
 		},
 	}
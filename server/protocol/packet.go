@@ -635,7 +635,7 @@ func (p *OkInPacket) Unmarshal(r io.Reader, conditional uint32) (err error) {
 
 	// 读取 SessionStateInfo（如果 StatusFlags 包含 SERVER_SESSION_STATE_CHANGED）
 	if p.StatusFlags&SERVER_SESSION_STATE_CHANGED != 0 && reader.Buffered() > 0 {
-		p.SessionStateInfo, _ = ReadStringByLenencFromReader[uint8](reader)
+		p.SessionStateInfo, _ = ReadStringByLenencFromReader[uint64](reader)
 	}
 
 	return nil
@@ -2336,22 +2336,22 @@ func (p *BinaryRowDataPacket) Marshal(columnCount uint64, columnTypes []uint8) (
 func (p *BinaryRowDataPacket) writeValueByType(buf *bytes.Buffer, value any, columnType uint8) error {
 	switch columnType {
 	case 0x01: // MYSQL_TYPE_TINY
-		if val, ok := value.(int8); ok {
+		if val, ok := integerBits(value); ok {
 			buf.WriteByte(byte(val))
 		}
 
 	case 0x02: // MYSQL_TYPE_SHORT
-		if val, ok := value.(int16); ok {
-			binary.Write(buf, binary.LittleEndian, val)
+		if val, ok := integerBits(value); ok {
+			binary.Write(buf, binary.LittleEndian, uint16(val))
 		}
 
 	case 0x03: // MYSQL_TYPE_LONG
-		if val, ok := value.(int32); ok {
-			binary.Write(buf, binary.LittleEndian, val)
+		if val, ok := integerBits(value); ok {
+			binary.Write(buf, binary.LittleEndian, uint32(val))
 		}
 
 	case 0x08: // MYSQL_TYPE_LONGLONG
-		if val, ok := value.(int64); ok {
+		if val, ok := integerBits(value); ok {
 			binary.Write(buf, binary.LittleEndian, val)
 		}
 
@@ -2379,6 +2379,39 @@ func (p *BinaryRowDataPacket) writeValueByType(buf *bytes.Buffer, value any, col
 	return nil
 }
 
+// integerBits 取整数值的补码位模式，有符号和无符号类型都按列宽截取低位字节写出
+// UNSIGNED 列的取值由列定义中的 UNSIGNED_FLAG 告知客户端，线上编码与有符号列相同
+func integerBits(value any) (uint64, bool) {
+	switch v := value.(type) {
+	case int:
+		return uint64(v), true
+	case int8:
+		return uint64(v), true
+	case int16:
+		return uint64(v), true
+	case int32:
+		return uint64(v), true
+	case int64:
+		return uint64(v), true
+	case uint:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
 // COM_STMT_CLOSE 包 - 关闭预处理语句
 type ComStmtClosePacket struct {
 	Packet
@@ -2455,6 +2488,42 @@ func (p *ComPingPacket) Marshal() ([]byte, error) {
 	return packetBuf.Bytes(), nil
 }
 
+// COM_RESET_CONNECTION 包 - 重置会话状态
+type ComResetConnectionPacket struct {
+	Packet
+	Command uint8 `mysql:"int<1>"` // 0x1f
+}
+
+func (p *ComResetConnectionPacket) Unmarshal(r io.Reader) error {
+	if err := p.Packet.Unmarshal(r); err != nil {
+		return err
+	}
+
+	// 从 Packet.Payload 中读取数据
+	reader := bufio.NewReader(bytes.NewReader(p.Packet.Payload))
+	p.Command, _ = reader.ReadByte()
+	return nil
+}
+
+func (p *ComResetConnectionPacket) Marshal() ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	// 写入命令类型
+	WriteNumber(buf, p.Command, 1)
+
+	// 组装Packet头部
+	payload := buf.Bytes()
+	packetBuf := new(bytes.Buffer)
+	// PayloadLength 3字节小端
+	packetBuf.Write([]byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16)})
+	// SequenceID
+	packetBuf.WriteByte(p.SequenceID)
+	// Payload
+	packetBuf.Write(payload)
+
+	return packetBuf.Bytes(), nil
+}
+
 // COM_QUIT 包 - 断开连接
 type ComQuitPacket struct {
 	Packet
@@ -2786,25 +2855,35 @@ func (p *ComDelayedInsertPacket) Marshal() ([]byte, error) {
 // COM_CHANGE_USER 包 - 切换用户
 type ComChangeUserPacket struct {
 	Packet
-	Command      uint8  `mysql:"int<1>"` // 0x11
-	User         string `mysql:"string<NUL>"`
-	AuthResponse string `mysql:"string<lenenc>"`
-	Database     string `mysql:"string<NUL>"`
-	CharacterSet uint16 `mysql:"int<2>"`
+	Command        uint8  `mysql:"int<1>"` // 0x11
+	User           string `mysql:"string<NUL>"`
+	AuthResponse   string `mysql:"string<lenenc>"`
+	Database       string `mysql:"string<NUL>"`
+	CharacterSet   uint16 `mysql:"int<2>"`
+	AuthPluginName string `mysql:"string<NUL>,conditional=CLIENT_PLUGIN_AUTH"`
 }
 
 func (p *ComChangeUserPacket) Unmarshal(r io.Reader) error {
 	if err := p.Packet.Unmarshal(r); err != nil {
 		return err
 	}
+	return p.DecodePayload()
+}
 
-	// 从 Packet.Payload 中读取数据
+// DecodePayload 从已读取的 Packet.Payload 中解析字段
+// 字符集和认证插件名是可选字段，旧客户端可能不发送
+func (p *ComChangeUserPacket) DecodePayload() error {
 	reader := bufio.NewReader(bytes.NewReader(p.Packet.Payload))
 	p.Command, _ = reader.ReadByte()
 	p.User, _ = ReadStringByNullEndFromReader(reader)
 	p.AuthResponse, _ = ReadStringByLenencFromReader[uint8](reader)
 	p.Database, _ = ReadStringByNullEndFromReader(reader)
-	p.CharacterSet, _ = ReadNumber[uint16](reader, 2)
+	if _, err := reader.Peek(2); err == nil {
+		p.CharacterSet, _ = ReadNumber[uint16](reader, 2)
+	}
+	if _, err := reader.Peek(1); err == nil {
+		p.AuthPluginName, _ = ReadStringByNullEndFromReader(reader)
+	}
 	return nil
 }
 
@@ -2821,6 +2900,10 @@ func (p *ComChangeUserPacket) Marshal() ([]byte, error) {
 	WriteStringByNullEnd(buf, p.Database)
 	// 写入字符集
 	WriteNumber(buf, p.CharacterSet, 2)
+	// 写入认证插件名
+	if p.AuthPluginName != "" {
+		WriteStringByNullEnd(buf, p.AuthPluginName)
+	}
 
 	// 组装Packet头部
 	payload := buf.Bytes()
@@ -3284,15 +3367,38 @@ func (p *LocalInfilePacket) Marshal() ([]byte, error) {
 }
 
 // ProgressReportPacket - 进度报告包（ERR_Packet的特殊形式）
-// 当Error Code == 0xFFFF时，ERR_Packet变为进度报告
+// 当Error Code == 0xFFFF时，ERR_Packet变为进度报告，仅发送给声明了 MARIADB_CLIENT_PROGRESS 的客户端
 type ProgressReportPacket struct {
 	Packet
-	Header    uint8  `mysql:"int<1>"`      // 固定值 0xFF
-	ErrorCode uint16 `mysql:"int<2>"`      // 0xFFFF 表示进度报告
-	Stage     uint8  `mysql:"int<1>"`      // 当前阶段
-	MaxStage  uint8  `mysql:"int<1>"`      // 最大阶段数
-	Progress  uint32 `mysql:"int<3>"`      // 进度值
-	Info      string `mysql:"string<NUL>"` // 进度信息
+	Header    uint8  `mysql:"int<1>"`         // 固定值 0xFF
+	ErrorCode uint16 `mysql:"int<2>"`         // 0xFFFF 表示进度报告
+	Strings   uint8  `mysql:"int<1>"`         // 后续字符串个数，固定为 1
+	Stage     uint8  `mysql:"int<1>"`         // 当前阶段，从 1 开始
+	MaxStage  uint8  `mysql:"int<1>"`         // 最大阶段数
+	Progress  uint32 `mysql:"int<3>"`         // 当前阶段的进度，单位为千分之一百分比（100000 表示 100%）
+	Info      string `mysql:"string<lenenc>"` // 进度信息
+}
+
+// NewProgressReportPacket 创建进度报告包，percent 为当前阶段的完成百分比（0-100）
+func NewProgressReportPacket(sequenceID, stage, maxStage uint8, percent float64, info string) *ProgressReportPacket {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	if maxStage < stage {
+		maxStage = stage
+	}
+	return &ProgressReportPacket{
+		Packet:    Packet{SequenceID: sequenceID},
+		Header:    0xFF,
+		ErrorCode: 0xFFFF,
+		Strings:   1,
+		Stage:     stage,
+		MaxStage:  maxStage,
+		Progress:  uint32(percent * 1000),
+		Info:      info,
+	}
 }
 
 func (p *ProgressReportPacket) Unmarshal(r io.Reader) error {
@@ -3310,10 +3416,13 @@ func (p *ProgressReportPacket) Unmarshal(r io.Reader) error {
 		return errors.New("not a progress report packet (error code != 0xFFFF)")
 	}
 
+	p.Strings, _ = ReadNumber[uint8](reader, 1)
 	p.Stage, _ = ReadNumber[uint8](reader, 1)
 	p.MaxStage, _ = ReadNumber[uint8](reader, 1)
-	p.Progress, _ = ReadNumber[uint32](reader, 4)
-	p.Info, _ = ReadStringByNullEndFromReader(reader)
+	progress := make([]byte, 3)
+	io.ReadFull(reader, progress)
+	p.Progress = uint32(progress[0]) | uint32(progress[1])<<8 | uint32(progress[2])<<16
+	p.Info, _ = ReadStringByLenencFromReader[uint64](reader)
 
 	return nil
 }
@@ -3325,13 +3434,14 @@ func (p *ProgressReportPacket) Marshal() ([]byte, error) {
 	WriteNumber(buf, p.Header, 1)
 	// 写入错误码（0xFFFF）
 	WriteNumber(buf, p.ErrorCode, 2)
-	// 写入阶段信息
+	// 写入字符串个数和阶段信息
+	WriteNumber(buf, p.Strings, 1)
 	WriteNumber(buf, p.Stage, 1)
 	WriteNumber(buf, p.MaxStage, 1)
-	// 写入进度值（4字节小端）
-	WriteNumber(buf, p.Progress, 4)
+	// 写入进度值（3字节小端）
+	buf.Write([]byte{byte(p.Progress), byte(p.Progress >> 8), byte(p.Progress >> 16)})
 	// 写入进度信息
-	WriteStringByNullEnd(buf, p.Info)
+	WriteStringByLenenc(buf, p.Info)
 
 	// 组装Packet头部
 	payload := buf.Bytes()
@@ -60,9 +60,10 @@ func TestProgressReportPacket(t *testing.T) {
 		},
 		Header:    0xFF,
 		ErrorCode: 0xFFFF,
+		Strings:   1,
 		Stage:     1,
 		MaxStage:  3,
-		Progress:  500,
+		Progress:  50000,
 		Info:      "Copying data...",
 	}
 
@@ -76,6 +77,7 @@ func TestProgressReportPacket(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, packet.Header, packet2.Header)
 	assert.Equal(t, packet.ErrorCode, packet2.ErrorCode)
+	assert.Equal(t, packet.Strings, packet2.Strings)
 	assert.Equal(t, packet.Stage, packet2.Stage)
 	assert.Equal(t, packet.MaxStage, packet2.MaxStage)
 	assert.Equal(t, packet.Progress, packet2.Progress)
@@ -87,21 +89,23 @@ func TestProgressReportPacket(t *testing.T) {
 // TestProgressReportPacketUnmarshal 测试进度报告包反序列化
 func TestProgressReportPacketUnmarshal(t *testing.T) {
 	// 包头 (3字节长度 + 1字节序列号) + 载荷
-	// 载荷: FF FF FF 01 03 F4 01 00 00 43 6f 70 79 69 6e 67 20 64 61 74 61 2e 2e 2e 00
+	// 载荷: FF FF FF 01 01 03 50 C3 00 0F 43 6f 70 79 69 6e 67 20 64 61 74 61 2e 2e 2e
 	// Header: 0xFF
 	// Error Code: 0xFFFF (进度报告标记）
+	// Strings: 1
 	// Stage: 1
 	// Max Stage: 3
-	// Progress: 0x000001F4 = 500 (4字节小端)
-	// Info: "Copying data...\0"
+	// Progress: 0x00C350 = 50000，即 50% (3字节小端)
+	// Info: lenenc "Copying data..."
 	payload := []byte{
 		0xFF,       // Header
 		0xFF, 0xFF, // Error Code 0xFFFF
-		0x01,                   // Stage: 1
-		0x03,                   // Max Stage: 3
-		0xF4, 0x01, 0x00, 0x00, // Progress: 500 (小端，4字节)
+		0x01,             // Strings: 1
+		0x01,             // Stage: 1
+		0x03,             // Max Stage: 3
+		0x50, 0xC3, 0x00, // Progress: 50000 (小端，3字节)
+		0x0F, // Info 长度
 		'C', 'o', 'p', 'y', 'i', 'n', 'g', ' ', 'd', 'a', 't', 'a', '.', '.', '.',
-		0x00, // NULL 终止符
 	}
 
 	testData := []byte{
@@ -117,12 +121,27 @@ func TestProgressReportPacketUnmarshal(t *testing.T) {
 	assert.Equal(t, uint16(0xFFFF), packet.ErrorCode)
 	assert.Equal(t, uint8(1), packet.Stage)
 	assert.Equal(t, uint8(3), packet.MaxStage)
-	assert.Equal(t, uint32(500), packet.Progress)
+	assert.Equal(t, uint32(50000), packet.Progress)
 	assert.Equal(t, "Copying data...", packet.Info)
 
 	t.Logf("ProgressReportPacket: %+v", packet)
 }
 
+// TestNewProgressReportPacket 测试进度报告包的构造和取值范围
+func TestNewProgressReportPacket(t *testing.T) {
+	packet := NewProgressReportPacket(3, 2, 1, 12.5, "Building index")
+	assert.Equal(t, uint8(3), packet.SequenceID)
+	assert.Equal(t, uint8(0xFF), packet.Header)
+	assert.Equal(t, uint16(0xFFFF), packet.ErrorCode)
+	assert.Equal(t, uint8(1), packet.Strings)
+	assert.Equal(t, uint8(2), packet.Stage)
+	assert.Equal(t, uint8(2), packet.MaxStage) // MaxStage 不小于 Stage
+	assert.Equal(t, uint32(12500), packet.Progress)
+
+	assert.Equal(t, uint32(100000), NewProgressReportPacket(0, 1, 1, 150, "").Progress)
+	assert.Equal(t, uint32(0), NewProgressReportPacket(0, 1, 1, -1, "").Progress)
+}
+
 // TestIsEofPacket 测试EOF包判断
 func TestIsEofPacket(t *testing.T) {
 	// TODO: Fix EOF packet detection - needs protocol investigation
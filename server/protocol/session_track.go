@@ -0,0 +1,109 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 会话状态跟踪信息的类型（OK 包 SessionStateInfo 中每一项的 type 字段）
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_ok_packet.html
+const (
+	SESSION_TRACK_SYSTEM_VARIABLES            uint8 = 0x00 // 系统变量：name 和 value 两个 lenenc 字符串
+	SESSION_TRACK_SCHEMA                      uint8 = 0x01 // 当前数据库：一个 lenenc 字符串
+	SESSION_TRACK_STATE_CHANGE                uint8 = 0x02 // 状态变化标记：lenenc 字符串 "1"
+	SESSION_TRACK_GTIDS                       uint8 = 0x03
+	SESSION_TRACK_TRANSACTION_CHARACTERISTICS uint8 = 0x04
+	SESSION_TRACK_TRANSACTION_STATE           uint8 = 0x05
+)
+
+// SessionStateBuilder 构建 OK 包的 SessionStateInfo，每一项按 type + lenenc 长度 + data 编码
+type SessionStateBuilder struct {
+	buf bytes.Buffer
+}
+
+// AddSystemVariable 添加一项 SESSION_TRACK_SYSTEM_VARIABLES
+func (b *SessionStateBuilder) AddSystemVariable(name, value string) {
+	data := new(bytes.Buffer)
+	WriteStringByLenenc(data, name)
+	WriteStringByLenenc(data, value)
+	b.add(SESSION_TRACK_SYSTEM_VARIABLES, data.Bytes())
+}
+
+// AddSchema 添加一项 SESSION_TRACK_SCHEMA
+func (b *SessionStateBuilder) AddSchema(schema string) {
+	data := new(bytes.Buffer)
+	WriteStringByLenenc(data, schema)
+	b.add(SESSION_TRACK_SCHEMA, data.Bytes())
+}
+
+// AddStateChange 添加一项 SESSION_TRACK_STATE_CHANGE
+func (b *SessionStateBuilder) AddStateChange() {
+	data := new(bytes.Buffer)
+	WriteStringByLenenc(data, "1")
+	b.add(SESSION_TRACK_STATE_CHANGE, data.Bytes())
+}
+
+func (b *SessionStateBuilder) add(trackType uint8, data []byte) {
+	b.buf.WriteByte(trackType)
+	WriteLenencNumber(&b.buf, uint64(len(data)))
+	b.buf.Write(data)
+}
+
+// Empty 是否没有添加任何项
+func (b *SessionStateBuilder) Empty() bool {
+	return b.buf.Len() == 0
+}
+
+// String 返回编码后的 SessionStateInfo
+func (b *SessionStateBuilder) String() string {
+	return b.buf.String()
+}
+
+// SessionStateEntry 解析后的一项会话状态跟踪信息
+type SessionStateEntry struct {
+	Type  uint8
+	Name  string // 仅 SESSION_TRACK_SYSTEM_VARIABLES 有值
+	Value string // 变量值、数据库名或 "1"；其他类型为原始 data
+}
+
+// ParseSessionStateInfo 解析 OK 包的 SessionStateInfo
+func ParseSessionStateInfo(info string) ([]SessionStateEntry, error) {
+	var entries []SessionStateEntry
+	r := strings.NewReader(info)
+	for r.Len() > 0 {
+		trackType, _ := r.ReadByte()
+		length, err := ReadLenencNumber[uint64](r)
+		if err != nil {
+			return nil, fmt.Errorf("session state entry length: %w", err)
+		}
+		if length > uint64(r.Len()) {
+			return nil, fmt.Errorf("session state entry truncated: need %d bytes, have %d", length, r.Len())
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		entry := SessionStateEntry{Type: trackType}
+		dr := bytes.NewReader(data)
+		switch trackType {
+		case SESSION_TRACK_SYSTEM_VARIABLES:
+			if entry.Name, err = ReadStringByLenencFromReader[uint64](dr); err != nil {
+				return nil, fmt.Errorf("session state variable name: %w", err)
+			}
+			if entry.Value, err = ReadStringByLenencFromReader[uint64](dr); err != nil {
+				return nil, fmt.Errorf("session state variable value: %w", err)
+			}
+		case SESSION_TRACK_SCHEMA, SESSION_TRACK_STATE_CHANGE:
+			if entry.Value, err = ReadStringByLenencFromReader[uint64](dr); err != nil {
+				return nil, fmt.Errorf("session state value: %w", err)
+			}
+		default:
+			entry.Value = string(data)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSessionStateBuilder_Encoding 测试会话状态跟踪信息的 TLV 编码
+func TestSessionStateBuilder_Encoding(t *testing.T) {
+	var b SessionStateBuilder
+	assert.True(t, b.Empty())
+	b.AddSystemVariable("autocommit", "OFF")
+	b.AddSchema("test")
+
+	expected := []byte{
+		0x00, 0x0f, // SESSION_TRACK_SYSTEM_VARIABLES，data 长度 15
+		0x0a, 'a', 'u', 't', 'o', 'c', 'o', 'm', 'm', 'i', 't',
+		0x03, 'O', 'F', 'F',
+		0x01, 0x05, // SESSION_TRACK_SCHEMA，data 长度 5
+		0x04, 't', 'e', 's', 't',
+	}
+	assert.Equal(t, expected, []byte(b.String()))
+}
+
+// TestSessionStateBuilder_RoundTrip 测试编码后经 OK 包传输再解析
+func TestSessionStateBuilder_RoundTrip(t *testing.T) {
+	var b SessionStateBuilder
+	b.AddSystemVariable("character_set_client", "utf8mb4")
+	b.AddSystemVariable("time_zone", strings.Repeat("x", 300)) // 超过 250 字节使用 0xfc 长度前缀
+	b.AddSchema("shop")
+	b.AddStateChange()
+
+	ok := &OkPacket{}
+	ok.OkInPacket.StatusFlags = SERVER_STATUS_AUTOCOMMIT
+	ok.OkInPacket.SessionStateInfo = b.String()
+	ok.OkInPacket.SetSessionStateChanged(true)
+	data, err := ok.Marshal()
+	require.NoError(t, err)
+
+	decoded := &OkPacket{}
+	require.NoError(t, decoded.Unmarshal(bytes.NewReader(data), CLIENT_PROTOCOL_41|CLIENT_SESSION_TRACK))
+	entries, err := ParseSessionStateInfo(decoded.SessionStateInfo)
+	require.NoError(t, err)
+	assert.Equal(t, []SessionStateEntry{
+		{Type: SESSION_TRACK_SYSTEM_VARIABLES, Name: "character_set_client", Value: "utf8mb4"},
+		{Type: SESSION_TRACK_SYSTEM_VARIABLES, Name: "time_zone", Value: strings.Repeat("x", 300)},
+		{Type: SESSION_TRACK_SCHEMA, Value: "shop"},
+		{Type: SESSION_TRACK_STATE_CHANGE, Value: "1"},
+	}, entries)
+
+	_, err = ParseSessionStateInfo(b.String()[:10])
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package protocol
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+)
+
+// charsetBinary 二进制字符集编号（my_charset_bin），数值、时间和二进制列使用
+const charsetBinary = 63
+
+// ColumnType 列类型在协议层的表示（列定义包中的类型、长度、字符集、小数位和类型相关 flags）
+type ColumnType struct {
+	Type         uint8
+	CharacterSet uint16
+	ColumnLength uint32
+	Decimals     uint8
+	Flags        uint16
+}
+
+// typeSpec 类型映射表中的一项
+type typeSpec struct {
+	tp      uint8
+	binary  bool   // 使用二进制字符集
+	numeric bool   // 带 NUM_FLAG
+	length  uint32 // 未指定长度时的默认显示宽度
+}
+
+// columnTypes 列类型名（小写、去掉长度参数）到协议类型的映射
+// 同时覆盖 SQL 类型名和数据源内部使用的 Go 风格类型名（int64、float64、string 等），新增类型只需在这里加一行
+var columnTypes = map[string]typeSpec{
+	// 整数
+	"tinyint":   {tp: MYSQL_TYPE_TINY, binary: true, numeric: true, length: 4},
+	"bool":      {tp: MYSQL_TYPE_TINY, binary: true, numeric: true, length: 1},
+	"boolean":   {tp: MYSQL_TYPE_TINY, binary: true, numeric: true, length: 1},
+	"int8":      {tp: MYSQL_TYPE_TINY, binary: true, numeric: true, length: 4},
+	"smallint":  {tp: MYSQL_TYPE_SHORT, binary: true, numeric: true, length: 6},
+	"int16":     {tp: MYSQL_TYPE_SHORT, binary: true, numeric: true, length: 6},
+	"mediumint": {tp: MYSQL_TYPE_INT24, binary: true, numeric: true, length: 9},
+	"int":       {tp: MYSQL_TYPE_LONG, binary: true, numeric: true, length: 11},
+	"integer":   {tp: MYSQL_TYPE_LONG, binary: true, numeric: true, length: 11},
+	"int32":     {tp: MYSQL_TYPE_LONG, binary: true, numeric: true, length: 11},
+	"bigint":    {tp: MYSQL_TYPE_LONGLONG, binary: true, numeric: true, length: 20},
+	"int64":     {tp: MYSQL_TYPE_LONGLONG, binary: true, numeric: true, length: 20},
+	"uint64":    {tp: MYSQL_TYPE_LONGLONG, binary: true, numeric: true, length: 20},
+	"year":      {tp: MYSQL_TYPE_YEAR, binary: true, numeric: true, length: 4},
+
+	// 浮点和定点
+	"float":   {tp: MYSQL_TYPE_FLOAT, binary: true, numeric: true, length: 12},
+	"float32": {tp: MYSQL_TYPE_FLOAT, binary: true, numeric: true, length: 12},
+	"double":  {tp: MYSQL_TYPE_DOUBLE, binary: true, numeric: true, length: 22},
+	"real":    {tp: MYSQL_TYPE_DOUBLE, binary: true, numeric: true, length: 22},
+	"float64": {tp: MYSQL_TYPE_DOUBLE, binary: true, numeric: true, length: 22},
+	"decimal": {tp: MYSQL_TYPE_NEWDECIMAL, binary: true, numeric: true, length: 11},
+	"numeric": {tp: MYSQL_TYPE_NEWDECIMAL, binary: true, numeric: true, length: 11},
+	"dec":     {tp: MYSQL_TYPE_NEWDECIMAL, binary: true, numeric: true, length: 11},
+	"bit":     {tp: MYSQL_TYPE_BIT, binary: true, length: 1},
+
+	// 日期和时间
+	"date":      {tp: MYSQL_TYPE_DATE, binary: true, length: 10},
+	"time":      {tp: MYSQL_TYPE_TIME, binary: true, length: 10},
+	"datetime":  {tp: MYSQL_TYPE_DATETIME, binary: true, length: 19},
+	"timestamp": {tp: MYSQL_TYPE_TIMESTAMP, binary: true, length: 19},
+
+	// 字符串
+	"char":       {tp: MYSQL_TYPE_STRING, length: 1},
+	"varchar":    {tp: MYSQL_TYPE_VAR_STRING, length: 255},
+	"string":     {tp: MYSQL_TYPE_VAR_STRING, length: 255},
+	"tinytext":   {tp: MYSQL_TYPE_BLOB, length: 255},
+	"text":       {tp: MYSQL_TYPE_BLOB, length: 65535},
+	"mediumtext": {tp: MYSQL_TYPE_BLOB, length: 16777215},
+	"longtext":   {tp: MYSQL_TYPE_BLOB, length: 4294967295},
+	"enum":       {tp: MYSQL_TYPE_STRING, length: 255},
+	"set":        {tp: MYSQL_TYPE_STRING, length: 255},
+
+	// 二进制
+	"binary":     {tp: MYSQL_TYPE_STRING, binary: true, length: 1},
+	"varbinary":  {tp: MYSQL_TYPE_VAR_STRING, binary: true, length: 255},
+	"bytes":      {tp: MYSQL_TYPE_BLOB, binary: true, length: 65535},
+	"tinyblob":   {tp: MYSQL_TYPE_BLOB, binary: true, length: 255},
+	"blob":       {tp: MYSQL_TYPE_BLOB, binary: true, length: 65535},
+	"mediumblob": {tp: MYSQL_TYPE_BLOB, binary: true, length: 16777215},
+	"longblob":   {tp: MYSQL_TYPE_BLOB, binary: true, length: 4294967295},
+
+	// 扩展类型：JSON 按 MySQL 8.0 的方式发送；向量以文本形式（"[1,2,3]"）返回
+	"json":   {tp: MYSQL_TYPE_JSON, binary: true, length: 4294967295},
+	"vector": {tp: MYSQL_TYPE_VAR_STRING, length: 65535},
+}
+
+// SplitColumnType 将 "decimal(10,2) unsigned" 拆分为小写基础类型、参数和是否无符号
+func SplitColumnType(typeStr string) (string, []int, bool) {
+	typeStr = strings.ToLower(strings.TrimSpace(typeStr))
+	unsigned := strings.Contains(typeStr, "unsigned") || strings.Contains(typeStr, "zerofill")
+
+	base := typeStr
+	var args []int
+	if open := strings.IndexByte(typeStr, '('); open >= 0 {
+		base = typeStr[:open]
+		if end := strings.IndexByte(typeStr[open:], ')'); end > 0 {
+			for _, part := range strings.Split(typeStr[open+1:open+end], ",") {
+				if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+					args = append(args, n)
+				}
+			}
+		}
+	} else if space := strings.IndexByte(typeStr, ' '); space >= 0 {
+		base = typeStr[:space]
+	}
+	return strings.TrimSpace(base), args, unsigned
+}
+
+// MySQLType 返回列类型对应的协议类型编号，未知类型按 VAR_STRING 处理
+func MySQLType(typeStr string) uint8 {
+	base, _, _ := SplitColumnType(typeStr)
+	if spec, ok := columnTypes[base]; ok {
+		return spec.tp
+	}
+	return MYSQL_TYPE_VAR_STRING
+}
+
+// ColumnTypeOf 根据列类型计算协议层的类型、长度、字符集和小数位，类型中的长度和精度（如 DECIMAL(10,2)）会被保留
+func ColumnTypeOf(typeStr string) ColumnType {
+	base, args, _ := SplitColumnType(typeStr)
+	spec, ok := columnTypes[base]
+	if !ok {
+		spec = columnTypes["varchar"]
+	}
+
+	ct := ColumnType{
+		Type:         spec.tp,
+		CharacterSet: CHARSET_DEFAULT,
+		ColumnLength: spec.length,
+	}
+	if spec.binary {
+		ct.CharacterSet = charsetBinary
+		ct.Flags |= BINARY_COLLATION_FLAG
+	}
+	if spec.numeric {
+		ct.Flags |= NUM_FLAG
+	}
+	if spec.tp == MYSQL_TYPE_BLOB || spec.tp == MYSQL_TYPE_JSON {
+		ct.Flags |= BLOB_FLAG
+	}
+
+	switch spec.tp {
+	case MYSQL_TYPE_FLOAT, MYSQL_TYPE_DOUBLE:
+		ct.Decimals = 31 // 浮点数没有固定小数位
+	case MYSQL_TYPE_NEWDECIMAL:
+		precision, scale := 10, 0
+		if len(args) > 0 {
+			precision = args[0]
+		}
+		if len(args) > 1 {
+			scale = args[1]
+		}
+		// 显示宽度包含符号位和小数点
+		ct.ColumnLength = uint32(precision + 1)
+		if scale > 0 {
+			ct.ColumnLength++
+		}
+		ct.Decimals = uint8(scale)
+		return ct
+	case MYSQL_TYPE_TIME, MYSQL_TYPE_DATETIME, MYSQL_TYPE_TIMESTAMP:
+		if len(args) > 0 && args[0] > 0 {
+			ct.Decimals = uint8(args[0])
+			ct.ColumnLength += uint32(args[0]) + 1
+		}
+		return ct
+	case MYSQL_TYPE_DATE, MYSQL_TYPE_JSON:
+		return ct
+	}
+
+	if len(args) > 0 && args[0] > 0 && spec.tp != MYSQL_TYPE_BLOB {
+		ct.ColumnLength = uint32(args[0])
+	}
+	return ct
+}
+
+// ColumnFieldMeta 根据列定义生成列定义包的内容（类型、长度、字符集和 flags），表名等由调用方填写
+func ColumnFieldMeta(col domain.ColumnInfo) FieldMeta {
+	ct := ColumnTypeOf(col.Type)
+	charset := ct.CharacterSet
+	if ct.Flags&BINARY_COLLATION_FLAG == 0 && isBinaryColumn(col) {
+		charset = charsetBinary
+	}
+	return FieldMeta{
+		Catalog:                   "def",
+		Name:                      col.Name,
+		OrgName:                   col.Name,
+		LengthOfFixedLengthFields: 12,
+		CharacterSet:              charset,
+		ColumnLength:              ct.ColumnLength,
+		Type:                      ct.Type,
+		Flags:                     ct.Flags | ColumnFlags(col),
+		Decimals:                  ct.Decimals,
+		Reserved:                  "\x00\x00",
+	}
+}
@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMySQLType 测试 SQL 类型名和数据源内部类型名到协议类型的映射
+func TestMySQLType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint8
+	}{
+		{"int", MYSQL_TYPE_LONG},
+		{"INT(11)", MYSQL_TYPE_LONG},
+		{"integer", MYSQL_TYPE_LONG},
+		{"int unsigned", MYSQL_TYPE_LONG},
+		{"int64", MYSQL_TYPE_LONGLONG},
+		{"tinyint", MYSQL_TYPE_TINY},
+		{"smallint", MYSQL_TYPE_SHORT},
+		{"mediumint", MYSQL_TYPE_INT24},
+		{"bigint", MYSQL_TYPE_LONGLONG},
+		{"float", MYSQL_TYPE_FLOAT},
+		{"double", MYSQL_TYPE_DOUBLE},
+		{"float64", MYSQL_TYPE_DOUBLE},
+		{"decimal", MYSQL_TYPE_NEWDECIMAL},
+		{"numeric", MYSQL_TYPE_NEWDECIMAL},
+		{"date", MYSQL_TYPE_DATE},
+		{"datetime", MYSQL_TYPE_DATETIME},
+		{"timestamp", MYSQL_TYPE_TIMESTAMP},
+		{"time", MYSQL_TYPE_TIME},
+		{"year", MYSQL_TYPE_YEAR},
+		{"varchar(64)", MYSQL_TYPE_VAR_STRING},
+		{"string", MYSQL_TYPE_VAR_STRING},
+		{"char(2)", MYSQL_TYPE_STRING},
+		{"text", MYSQL_TYPE_BLOB},
+		{"longblob", MYSQL_TYPE_BLOB},
+		{"json", MYSQL_TYPE_JSON},
+		{"vector", MYSQL_TYPE_VAR_STRING},
+		{"boolean", MYSQL_TYPE_TINY},
+		{"bool", MYSQL_TYPE_TINY},
+		{"unknown_type", MYSQL_TYPE_VAR_STRING},
+		{"", MYSQL_TYPE_VAR_STRING},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, MySQLType(tt.input), "MySQLType(%q)", tt.input)
+	}
+}
+
+// TestColumnTypeOf 测试长度、字符集和小数位的推导
+func TestColumnTypeOf(t *testing.T) {
+	ct := ColumnTypeOf("DECIMAL(10,2)")
+	assert.Equal(t, uint8(MYSQL_TYPE_NEWDECIMAL), ct.Type)
+	assert.Equal(t, uint32(12), ct.ColumnLength)
+	assert.Equal(t, uint8(2), ct.Decimals)
+	assert.Equal(t, uint16(charsetBinary), ct.CharacterSet)
+	assert.NotZero(t, ct.Flags&NUM_FLAG)
+
+	ct = ColumnTypeOf("int64")
+	assert.Equal(t, uint32(20), ct.ColumnLength)
+	assert.Equal(t, uint16(charsetBinary), ct.CharacterSet)
+
+	ct = ColumnTypeOf("varchar(64)")
+	assert.Equal(t, uint32(64), ct.ColumnLength)
+	assert.Equal(t, uint16(CHARSET_DEFAULT), ct.CharacterSet)
+	assert.Zero(t, ct.Flags)
+
+	ct = ColumnTypeOf("text")
+	assert.Equal(t, uint32(65535), ct.ColumnLength)
+	assert.NotZero(t, ct.Flags&BLOB_FLAG)
+	assert.Zero(t, ct.Flags&BINARY_COLLATION_FLAG)
+
+	ct = ColumnTypeOf("datetime(3)")
+	assert.Equal(t, uint8(3), ct.Decimals)
+	assert.Equal(t, uint32(23), ct.ColumnLength)
+
+	ct = ColumnTypeOf("float64")
+	assert.Equal(t, uint8(31), ct.Decimals)
+
+	ct = ColumnTypeOf("json")
+	assert.Equal(t, uint16(charsetBinary), ct.CharacterSet)
+	assert.NotZero(t, ct.Flags&BLOB_FLAG)
+}
+
+// TestColumnFieldMeta 测试列定义包合并类型属性和约束 flags
+func TestColumnFieldMeta(t *testing.T) {
+	meta := ColumnFieldMeta(domain.ColumnInfo{Name: "id", Type: "bigint unsigned", Primary: true, AutoIncrement: true})
+	assert.Equal(t, "def", meta.Catalog)
+	assert.Equal(t, "id", meta.Name)
+	assert.Equal(t, uint8(MYSQL_TYPE_LONGLONG), meta.Type)
+	want := uint16(NOT_NULL_FLAG | PRI_KEY_FLAG | AUTO_INCREMENT_FLAG | UNSIGNED_FLAG | BINARY_COLLATION_FLAG | NUM_FLAG)
+	assert.Equal(t, want, meta.Flags)
+
+	meta = ColumnFieldMeta(domain.ColumnInfo{Name: "code", Type: "varchar(8)", Nullable: true, Collation: "binary"})
+	assert.Equal(t, uint16(charsetBinary), meta.CharacterSet)
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/kasuganosora/sqlexec/server/prototrace"
+)
+
+// traceConnection 配置了 protocol_trace_dir 时用 prototrace.Conn 包装连接，以便随时开启协议跟踪；
+// protocol_trace 开启时从握手开始跟踪。未配置目录时返回 nil
+func (s *Server) traceConnection(conn net.Conn, sess *pkg_session.Session) *prototrace.Conn {
+	if s.config == nil || s.config.Server.ProtocolTraceDir == "" {
+		return nil
+	}
+	traced := prototrace.NewConn(conn)
+	if s.config.Server.ProtocolTrace {
+		// 复用的会话已经认证过，不再握手
+		var capabilities uint32
+		if sess.User != "" {
+			capabilities = sess.ClientCapabilities
+		}
+		s.startProtocolTrace(traced, sess, capabilities)
+	}
+	return traced
+}
+
+// syncProtocolTrace 每个命令之后按会话变量 protocol_trace 开启或关闭跟踪，
+// 未设置会话变量时保持原状。写跟踪文件失败后不再自动开启
+func (s *Server) syncProtocolTrace(conn *prototrace.Conn, sess *pkg_session.Session) {
+	if conn.Err() != nil {
+		return
+	}
+	apiSess, ok := sess.GetAPISession().(*api.Session)
+	if !ok {
+		return
+	}
+	enabled, set := apiSess.ProtocolTrace()
+	if !set || enabled == conn.Tracing() {
+		return
+	}
+	if !enabled {
+		s.stopProtocolTrace(conn, sess)
+		return
+	}
+	s.startProtocolTrace(conn, sess, sess.ClientCapabilities)
+}
+
+// startProtocolTrace 为连接创建跟踪文件 <protocol_trace_dir>/conn-<ConnectionID>-<时间>.trace 并开始跟踪；
+// ThreadID 会被新连接重用，ConnectionID 不会，且与查询 ID 出自同一生成器，便于和日志对照
+func (s *Server) startProtocolTrace(conn *prototrace.Conn, sess *pkg_session.Session, capabilities uint32) {
+	dir := s.config.Server.ProtocolTraceDir
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		s.logger.Printf("创建协议跟踪目录失败: %v", err)
+		return
+	}
+	name := fmt.Sprintf("conn-%d-%s.trace", sess.ConnectionID, time.Now().Format("20060102T150405.000"))
+	path := filepath.Join(dir, name)
+	w, err := prototrace.Create(path)
+	if err != nil {
+		s.logger.Printf("创建协议跟踪文件失败: %v", err)
+		return
+	}
+	conn.Start(w, capabilities)
+	s.logger.Printf("开始协议跟踪: ThreadID=%d, ConnectionID=%d, 文件=%s", sess.ThreadID, sess.ConnectionID, path)
+}
+
+// stopProtocolTrace 停止跟踪并报告写跟踪文件时的错误
+func (s *Server) stopProtocolTrace(conn *prototrace.Conn, sess *pkg_session.Session) {
+	if err := conn.Err(); err != nil {
+		s.logger.Printf("写协议跟踪文件失败，已停止跟踪: ThreadID=%d, %v", sess.ThreadID, err)
+	}
+	if err := conn.Stop(); err != nil {
+		s.logger.Printf("关闭协议跟踪文件失败: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/server/prototrace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTraceServer 启动配置了协议跟踪目录的服务器，返回监听地址
+func startTraceServer(t *testing.T, traceAll bool) (string, string) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Server.ProtocolTrace = traceAll
+	cfg.Server.ProtocolTraceDir = dir
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newTestServer(t, ctx, listener, cfg)
+	go s.Start()
+	t.Cleanup(func() {
+		cancel()
+		listener.Close()
+	})
+	return listener.Addr().String(), dir
+}
+
+// readTraces 读取目录中的全部跟踪文件
+func readTraces(t *testing.T, dir string) [][]prototrace.Record {
+	files, err := filepath.Glob(filepath.Join(dir, "*.trace"))
+	require.NoError(t, err)
+	var traces [][]prototrace.Record
+	for _, file := range files {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		records, err := prototrace.ReadTrace(f)
+		f.Close()
+		require.NoError(t, err)
+		traces = append(traces, records)
+	}
+	return traces
+}
+
+// waitTraces 等待连接关闭后跟踪文件写完
+func waitTraces(t *testing.T, dir string, n int) [][]prototrace.Record {
+	var traces [][]prototrace.Record
+	require.Eventually(t, func() bool {
+		traces = readTraces(t, dir)
+		if len(traces) != n {
+			return false
+		}
+		for _, records := range traces {
+			if len(records) == 0 || records[len(records)-1].Summary != "COM_QUIT" {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+	return traces
+}
+
+func summaries(records []prototrace.Record) []string {
+	out := make([]string, len(records))
+	for i, rec := range records {
+		out[i] = rec.Dir + " " + rec.Summary
+	}
+	return out
+}
+
+func TestProtocolTrace_RecordAndReplay(t *testing.T) {
+	addr, dir := startTraceServer(t, true)
+
+	db, err := sql.Open("mysql", "root@tcp("+addr+")/default?interpolateParams=true")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec("CREATE TABLE trace_t (id INT PRIMARY KEY, name VARCHAR(20))")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO trace_t VALUES (1, 'a'), (2, 'b')")
+	require.NoError(t, err)
+	var name string
+	require.NoError(t, db.QueryRow("SELECT name FROM trace_t WHERE id = ?", 2).Scan(&name))
+	assert.Equal(t, "b", name)
+	rows, err := db.Query("SELECT id, name FROM trace_t ORDER BY id")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Close())
+	_, err = db.Exec("SELECT * FROM no_such_table")
+	require.Error(t, err)
+	require.NoError(t, db.Close())
+
+	traces := waitTraces(t, dir, 1)
+	records := traces[0]
+	got := summaries(records)
+	assert.Contains(t, got[0], "out handshake v10 server=")
+	assert.Contains(t, got[1], "in handshake response user=root")
+	assert.Contains(t, got, "in COM_QUERY SELECT id, name FROM trace_t ORDER BY id")
+	assert.Contains(t, got, "out result set columns=2")
+	assert.Contains(t, got, "out column name")
+	for _, rec := range records {
+		assert.Equal(t, rec.Len, len(rec.Data))
+	}
+
+	// 回放到一个新连接：表已经存在，CREATE TABLE 的响应不同，其余一致
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	result, err := prototrace.Replay(conn, records, nil)
+	require.NoError(t, err)
+	assert.Greater(t, result.Requests, 5)
+	require.Len(t, result.Mismatches, 2, "%v", result.Mismatches)
+	assert.Contains(t, result.Mismatches[0].Request, "CREATE TABLE trace_t")
+	assert.Contains(t, result.Mismatches[0].Got, "ERR")
+	assert.Contains(t, result.Mismatches[1].Request, "INSERT INTO trace_t")
+}
+
+func TestProtocolTrace_SessionVariable(t *testing.T) {
+	addr, dir := startTraceServer(t, false)
+
+	db, err := sql.Open("mysql", "root@tcp("+addr+")/default?interpolateParams=true")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec("SELECT 1")
+	require.NoError(t, err)
+	assert.Empty(t, readTraces(t, dir))
+
+	_, err = db.Exec("SET SESSION protocol_trace = 1")
+	require.NoError(t, err)
+	_, err = db.Exec("SELECT 2")
+	require.NoError(t, err)
+	_, err = db.Exec("SET SESSION protocol_trace = 0")
+	require.NoError(t, err)
+	_, err = db.Exec("SELECT 3")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var records []prototrace.Record
+	require.Eventually(t, func() bool {
+		traces := readTraces(t, dir)
+		if len(traces) != 1 {
+			return false
+		}
+		records = traces[0]
+		return len(records) > 0
+	}, 5*time.Second, 20*time.Millisecond)
+	got := summaries(records)
+	assert.Equal(t, "in COM_QUERY SELECT 2", got[0])
+	assert.Equal(t, "out result set columns=1", got[1])
+	assert.Equal(t, "in COM_QUERY SET SESSION protocol_trace = 0", got[len(got)-2])
+	assert.NotContains(t, got, "in COM_QUERY SELECT 3")
+}
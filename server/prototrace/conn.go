@@ -0,0 +1,164 @@
+package prototrace
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// Conn 包装客户端连接，开启跟踪时把收发的包写入跟踪文件。
+// 未开启跟踪时只按包头切分字节流，以便随时开启
+type Conn struct {
+	net.Conn
+
+	mu      sync.Mutex
+	writer  *Writer
+	err     error // 写跟踪文件失败的错误，出错后停止跟踪
+	sum     summarizer
+	in, out framer
+}
+
+// NewConn 包装连接，初始不跟踪
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{Conn: conn}
+}
+
+// Start 开始把包写入 w，已经在跟踪时先关闭原来的跟踪文件。
+// capabilities 为连接协商的能力标志，连接尚未认证（从握手开始跟踪）时为 0
+func (c *Conn) Start(w *Writer, capabilities uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.writer != nil {
+		c.writer.Close()
+	}
+	c.writer = w
+	c.err = nil
+	c.sum = summarizer{}
+	if capabilities != 0 {
+		c.sum = summarizer{authenticated: true, handshakeDone: true, serverCaps: capabilities}
+		c.sum.deprecateEOF = capabilities&protocol.CLIENT_DEPRECATE_EOF != 0
+	}
+}
+
+// Stop 停止跟踪并关闭跟踪文件
+func (c *Conn) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopLocked()
+}
+
+func (c *Conn) stopLocked() error {
+	if c.writer == nil {
+		return nil
+	}
+	err := c.writer.Close()
+	c.writer = nil
+	return err
+}
+
+// Tracing 是否正在跟踪
+func (c *Conn) Tracing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writer != nil
+}
+
+// Err 返回写跟踪文件失败的错误
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Read 读取客户端发来的数据
+func (c *Conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.observe(&c.in, DirIn, p[:n])
+	}
+	return n, err
+}
+
+// Write 向客户端发送数据
+func (c *Conn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.observe(&c.out, DirOut, p[:n])
+	}
+	return n, err
+}
+
+// NetConn 返回被包装的连接
+func (c *Conn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// Close 关闭连接和跟踪文件
+func (c *Conn) Close() error {
+	c.Stop()
+	return c.Conn.Close()
+}
+
+// observe 把收发的字节交给对应方向的切分器，切出完整的包后记录
+func (c *Conn) observe(f *framer, dir string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f.feed(data, c.writer != nil, func(seq uint8, payload []byte) {
+		if c.writer == nil {
+			return
+		}
+		summary, _ := c.sum.summarize(dir, seq, payload)
+		rec := &Record{Time: time.Now(), Dir: dir, Seq: seq, Len: len(payload), Summary: summary, Data: payload}
+		if err := c.writer.Write(rec); err != nil {
+			c.err = err
+			c.stopLocked()
+		}
+	})
+}
+
+// framer 按 4 字节包头（3 字节长度、1 字节序号）从字节流中切分 MySQL 包
+type framer struct {
+	header    [4]byte
+	headerLen int
+	remaining int
+	capture   bool // 当前包开始时正在跟踪，需要保存负载
+	payload   []byte
+}
+
+// feed 处理一段字节流，capture 为 true 时保存此后开始的包的负载，每个完整的包调用一次 emit
+func (f *framer) feed(data []byte, capture bool, emit func(seq uint8, payload []byte)) {
+	for len(data) > 0 {
+		if f.headerLen < 4 {
+			n := copy(f.header[f.headerLen:], data)
+			f.headerLen += n
+			data = data[n:]
+			if f.headerLen < 4 {
+				return
+			}
+			f.remaining = int(f.header[0]) | int(f.header[1])<<8 | int(f.header[2])<<16
+			f.capture = capture
+			f.payload = nil
+			if f.capture {
+				f.payload = make([]byte, 0, f.remaining)
+			}
+		}
+		n := f.remaining
+		if n > len(data) {
+			n = len(data)
+		}
+		if f.capture {
+			f.payload = append(f.payload, data[:n]...)
+		}
+		f.remaining -= n
+		data = data[n:]
+		if f.remaining == 0 {
+			f.headerLen = 0
+			if f.capture {
+				emit(f.header[3], f.payload)
+			}
+			f.payload = nil
+		}
+	}
+}
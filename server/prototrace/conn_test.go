@@ -0,0 +1,109 @@
+package prototrace
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packet(seq uint8, payload ...byte) []byte {
+	n := len(payload)
+	return append([]byte{byte(n), byte(n >> 8), byte(n >> 16), seq}, payload...)
+}
+
+func TestConn_RecordsSplitWrites(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := NewConn(server)
+	path := filepath.Join(t.TempDir(), "conn.trace")
+
+	var received bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&received, client)
+		close(done)
+	}()
+
+	// 开启跟踪之前的包只切分不记录
+	_, err := conn.Write(packet(0, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00))
+	require.NoError(t, err)
+
+	w, err := Create(path)
+	require.NoError(t, err)
+	conn.Start(w, protocol.CLIENT_PROTOCOL_41|protocol.CLIENT_DEPRECATE_EOF)
+	assert.True(t, conn.Tracing())
+
+	// 结果集：列数、一个列定义（CLIENT_DEPRECATE_EOF 下没有 EOF）、一行、结束的 OK（0xfe）
+	column := []byte{3, 'd', 'e', 'f', 0, 0, 0, 2, 'i', 'd', 0}
+	var stream []byte
+	stream = append(stream, packet(1, 0x01)...)
+	stream = append(stream, packet(2, column...)...)
+	stream = append(stream, packet(3, 0x01, '7')...)
+	stream = append(stream, packet(4, 0xfe, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00)...)
+	// 每次只写一个字节，包头和负载都被拆开
+	for i := range stream {
+		_, err := conn.Write(stream[i : i+1])
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Close())
+	<-done
+	assert.False(t, conn.Tracing())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := ReadTrace(f)
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	want := []string{"result set columns=1", "column id", "row (2 bytes)", "OK (end of result set)"}
+	for i, rec := range records {
+		assert.Equal(t, DirOut, rec.Dir)
+		assert.Equal(t, uint8(i+1), rec.Seq)
+		assert.Equal(t, want[i], rec.Summary)
+	}
+	assert.Equal(t, []byte{0x01, '7'}, records[2].Data)
+}
+
+func TestSummarizer_Handshake(t *testing.T) {
+	var s summarizer
+	handshake := append([]byte{0x0a}, []byte("8.0.33-sqlexec\x00")...)
+	handshake = append(handshake, 1, 0, 0, 0)         // 连接 ID
+	handshake = append(handshake, make([]byte, 9)...) // 认证数据和填充
+	handshake = append(handshake, 0x00, 0x02, 0x21, 0x02, 0x00, 0x00, 0x01)
+	summary, end := s.summarize(DirOut, 0, handshake)
+	assert.Equal(t, "handshake v10 server=8.0.33-sqlexec", summary)
+	assert.True(t, end)
+	assert.Equal(t, uint32(protocol.CLIENT_PROTOCOL_41|protocol.CLIENT_DEPRECATE_EOF), s.serverCaps)
+
+	response := make([]byte, 32)
+	response[1] = 0x02 // CLIENT_PROTOCOL_41
+	response = append(response, []byte("root\x00")...)
+	summary, _ = s.summarize(DirIn, 1, response)
+	assert.Equal(t, "handshake response user=root", summary)
+	assert.False(t, s.deprecateEOF)
+
+	summary, end = s.summarize(DirOut, 2, []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00})
+	assert.Equal(t, "OK", summary)
+	assert.True(t, end)
+
+	summary, _ = s.summarize(DirIn, 0, append([]byte{protocol.COM_QUERY}, "SELECT 1"...))
+	assert.Equal(t, "COM_QUERY SELECT 1", summary)
+	summary, end = s.summarize(DirOut, 1, []byte{0xff, 0x7a, 0x04, '#', '4', '2', 'S', '0', '2', 'n', 'o'})
+	assert.Equal(t, "ERR 1146: no", summary)
+	assert.True(t, end)
+}
+
+func TestReplay_RequiresHandshake(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	_, err := Replay(client, []Record{{Dir: DirIn, Summary: "COM_QUERY SELECT 1", Data: []byte{0x03, '1'}}}, nil)
+	assert.ErrorContains(t, err, "does not start with the handshake")
+}
@@ -0,0 +1,158 @@
+package prototrace
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// defaultReplayTimeout 等待服务器一个响应包的默认超时
+const defaultReplayTimeout = 5 * time.Second
+
+// ReplayOptions 回放选项
+type ReplayOptions struct {
+	// Timeout 等待服务器一个响应包的超时，默认 5s
+	Timeout time.Duration
+}
+
+// Mismatch 回放时服务器的响应包与记录不一致。Want 或 Got 为空表示响应的包比记录少或多
+type Mismatch struct {
+	Request string // 客户端请求的摘要，连接建立时服务器主动发送的包为空
+	Packet  int    // 响应中的第几个包，从 1 开始
+	Want    string
+	Got     string
+}
+
+// String 返回便于阅读的描述
+func (m Mismatch) String() string {
+	request := m.Request
+	if request == "" {
+		request = "connect"
+	}
+	want, got := m.Want, m.Got
+	if want == "" {
+		want = "(none)"
+	}
+	if got == "" {
+		got = "(none)"
+	}
+	return fmt.Sprintf("%s: packet %d: want %q, got %q", request, m.Packet, want, got)
+}
+
+// ReplayResult 回放结果
+type ReplayResult struct {
+	Requests   int // 发送的客户端包数
+	Packets    int // 收到的服务器包数
+	Mismatches []Mismatch
+}
+
+// exchange 客户端的一个包和服务器随后的响应包
+type exchange struct {
+	request  *Record // 连接建立时服务器主动发送的包没有请求
+	response []Record
+}
+
+// splitExchanges 按客户端的包把记录分成请求和响应
+func splitExchanges(records []Record) []exchange {
+	var exchanges []exchange
+	for i := range records {
+		rec := &records[i]
+		if rec.Dir == DirIn || len(exchanges) == 0 {
+			exchanges = append(exchanges, exchange{})
+		}
+		ex := &exchanges[len(exchanges)-1]
+		if rec.Dir == DirIn {
+			ex.request = rec
+		} else {
+			ex.response = append(ex.response, *rec)
+		}
+	}
+	return exchanges
+}
+
+// Replay 把记录中客户端的包按原来的序号依次发给 conn 连接的服务器，读取服务器的完整响应并与记录比较。
+// 包的摘要必须一致，行数据还要比较原始字节。记录中没有响应的请求（如 COM_QUIT）不等待响应。
+// 认证数据是按记录时的握手随机数计算的，服务器需要关闭密码校验（verify_passwords）
+func Replay(conn net.Conn, records []Record, opts *ReplayOptions) (*ReplayResult, error) {
+	timeout := defaultReplayTimeout
+	if opts != nil && opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+	if len(records) == 0 || records[0].Dir != DirOut || records[0].Seq != 0 {
+		return nil, fmt.Errorf("trace does not start with the handshake: only traces recorded from the start of a connection can be replayed")
+	}
+	result := &ReplayResult{}
+	var sum summarizer
+	for _, ex := range splitExchanges(records) {
+		request := ""
+		if ex.request != nil {
+			request = ex.request.Summary
+			if err := writePacket(conn, ex.request.Seq, ex.request.Data); err != nil {
+				return result, fmt.Errorf("send %s: %w", request, err)
+			}
+			sum.summarize(DirIn, ex.request.Seq, ex.request.Data)
+			result.Requests++
+		}
+		if len(ex.response) == 0 {
+			continue
+		}
+
+		for i := 0; ; i++ {
+			if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+				return result, err
+			}
+			seq, payload, err := readPacket(conn)
+			if err != nil {
+				return result, fmt.Errorf("read response to %s: %w", describeRequest(request), err)
+			}
+			result.Packets++
+			summary, end := sum.summarize(DirOut, seq, payload)
+			if i >= len(ex.response) {
+				result.Mismatches = append(result.Mismatches, Mismatch{Request: request, Packet: i + 1, Got: summary})
+			} else if want := ex.response[i]; want.Summary != summary ||
+				(strings.HasPrefix(summary, "row ") && !bytes.Equal(want.Data, payload)) {
+				result.Mismatches = append(result.Mismatches, Mismatch{Request: request, Packet: i + 1, Want: want.Summary, Got: summary})
+			}
+			if end {
+				for j := i + 1; j < len(ex.response); j++ {
+					result.Mismatches = append(result.Mismatches, Mismatch{Request: request, Packet: j + 1, Want: ex.response[j].Summary})
+				}
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// describeRequest 错误信息中的请求描述
+func describeRequest(request string) string {
+	if request == "" {
+		return "connect"
+	}
+	return fmt.Sprintf("%q", request)
+}
+
+// writePacket 发送一个包
+func writePacket(w io.Writer, seq uint8, payload []byte) error {
+	n := len(payload)
+	packet := make([]byte, 4, 4+n)
+	packet[0], packet[1], packet[2], packet[3] = byte(n), byte(n>>8), byte(n>>16), seq
+	_, err := w.Write(append(packet, payload...))
+	return err
+}
+
+// readPacket 读取一个包
+func readPacket(r io.Reader) (uint8, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[3], payload, nil
+}
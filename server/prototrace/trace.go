@@ -0,0 +1,386 @@
+// Package prototrace 记录 MySQL 协议连接收发的原始包并回放，用于排查客户端兼容性问题
+//
+// 跟踪文件每行一个 JSON 格式的 Record，按收发顺序排列。Data 是不含包头的原始负载，
+// 回放时按记录把客户端的包重新发给服务器，比较服务器的响应
+package prototrace
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kasuganosora/sqlexec/server/protocol"
+)
+
+// 包的方向（从服务器的角度）
+const (
+	DirIn  = "in"  // 客户端发往服务器
+	DirOut = "out" // 服务器发往客户端
+)
+
+// maxQuerySummary 摘要中保留的 SQL 长度（字符）
+const maxQuerySummary = 200
+
+// Record 跟踪文件中的一个包
+type Record struct {
+	Time    time.Time `json:"time"`
+	Dir     string    `json:"dir"`
+	Seq     uint8     `json:"seq"`
+	Len     int       `json:"len"`
+	Summary string    `json:"summary"`
+	Data    []byte    `json:"data"`
+}
+
+// Writer 把包写入跟踪文件，可以并发使用
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// Create 创建跟踪文件
+func Create(path string) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	return &Writer{file: file, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+// Write 写入一条记录并刷新到文件，连接异常断开时跟踪文件也是完整的
+func (w *Writer) Write(rec *Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(rec); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// Close 关闭跟踪文件
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	flushErr := w.buf.Flush()
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// ReadTrace 读取跟踪文件中的全部记录
+func ReadTrace(r io.Reader) ([]Record, error) {
+	var records []Record
+	dec := json.NewDecoder(r)
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid trace record %d: %w", len(records)+1, err)
+		}
+		if rec.Dir != DirIn && rec.Dir != DirOut {
+			return nil, fmt.Errorf("invalid trace record %d: unknown direction %q", len(records)+1, rec.Dir)
+		}
+		records = append(records, rec)
+	}
+}
+
+// 服务器响应的解析状态
+const (
+	stateIdle       = iota // 等待响应的第一个包
+	stateColumns           // 结果集的列定义
+	stateColumnsEOF        // 列定义之后的 EOF
+	stateRows              // 结果集的行
+	statePrepare           // COM_STMT_PREPARE 响应中的参数和列定义
+)
+
+// summarizer 跟踪连接的协议状态，为包生成摘要并判断服务器的一次响应是否结束。
+// 认证完成前是握手和认证包，之后客户端的包是命令
+type summarizer struct {
+	authenticated bool
+	handshakeDone bool   // 已收到客户端的握手响应
+	serverCaps    uint32 // 握手包中服务器的能力标志
+	deprecateEOF  bool   // 双方都支持 CLIENT_DEPRECATE_EOF，结果集不发送列定义之后的 EOF
+
+	command     byte
+	state       int
+	columnsLeft uint64
+	defsLeft    int
+	eofsLeft    int
+}
+
+// summarize 返回包的摘要；对服务器的包，end 表示一次响应已经结束，轮到客户端发送
+func (s *summarizer) summarize(dir string, seq uint8, payload []byte) (summary string, end bool) {
+	if dir == DirIn {
+		s.state = stateIdle
+		return s.summarizeClient(seq, payload), false
+	}
+	if len(payload) == 0 {
+		return "empty packet", true
+	}
+	if !s.authenticated {
+		return s.summarizeAuth(seq, payload)
+	}
+	return s.summarizeResponse(payload)
+}
+
+func (s *summarizer) summarizeClient(seq uint8, payload []byte) string {
+	if !s.authenticated {
+		if !s.handshakeDone {
+			s.handshakeDone = true
+			if len(payload) >= 4 {
+				caps := binary.LittleEndian.Uint32(payload[:4]) & s.serverCaps
+				s.deprecateEOF = caps&protocol.CLIENT_DEPRECATE_EOF != 0
+			}
+			return summarizeHandshakeResponse(payload)
+		}
+		return fmt.Sprintf("auth data (%d bytes)", len(payload))
+	}
+	if len(payload) == 0 {
+		return "empty packet"
+	}
+	if seq != 0 {
+		return fmt.Sprintf("data (%d bytes)", len(payload))
+	}
+	s.command = payload[0]
+	name := protocol.GetCommandName(s.command)
+	switch s.command {
+	case protocol.COM_QUERY, protocol.COM_STMT_PREPARE:
+		return name + " " + truncate(string(payload[1:]))
+	case protocol.COM_INIT_DB:
+		return name + " " + string(payload[1:])
+	case protocol.COM_STMT_EXECUTE, protocol.COM_STMT_CLOSE, protocol.COM_STMT_RESET,
+		protocol.COM_STMT_FETCH, protocol.COM_STMT_SEND_LONG_DATA:
+		if len(payload) >= 5 {
+			return fmt.Sprintf("%s stmt=%d", name, binary.LittleEndian.Uint32(payload[1:5]))
+		}
+	case protocol.COM_CHANGE_USER:
+		// 切换用户后重新认证
+		s.authenticated = false
+		if user, _, ok := cutNul(payload[1:]); ok {
+			return name + " user=" + user
+		}
+	}
+	return name
+}
+
+// summarizeAuth 握手和认证阶段服务器的包
+func (s *summarizer) summarizeAuth(seq uint8, payload []byte) (string, bool) {
+	switch header := payload[0]; {
+	case header == 0x00:
+		s.authenticated = true
+		return "OK", true
+	case header == 0xff:
+		return summarizeError(payload), true
+	case !s.handshakeDone && seq == 0 && header == 0x0a:
+		version, rest, _ := cutNul(payload[1:])
+		// 连接 ID 4 字节、认证数据 8 字节、填充 1 字节、能力标志低 16 位、字符集、状态 2 字节、能力标志高 16 位
+		if len(rest) >= 20 {
+			s.serverCaps = uint32(binary.LittleEndian.Uint16(rest[13:15])) | uint32(binary.LittleEndian.Uint16(rest[18:20]))<<16
+		}
+		return "handshake v10 server=" + version, true
+	case header == protocol.EOF_MARKER:
+		plugin, _, _ := cutNul(payload[1:])
+		return "auth switch request plugin=" + plugin, true
+	case header == 0x01:
+		// caching_sha2_password 的快速认证成功（0x03）之后紧跟 OK 包
+		return fmt.Sprintf("auth more data (%d bytes)", len(payload)-1), !(len(payload) == 2 && payload[1] == 0x03)
+	}
+	return fmt.Sprintf("data (%d bytes)", len(payload)), true
+}
+
+// summarizeResponse 命令响应中服务器的包
+func (s *summarizer) summarizeResponse(payload []byte) (string, bool) {
+	header := payload[0]
+	switch s.state {
+	case stateColumns:
+		s.columnsLeft--
+		if s.columnsLeft == 0 {
+			s.state = stateColumnsEOF
+			if s.deprecateEOF {
+				s.state = stateRows
+			}
+		}
+		return "column " + columnName(payload), false
+	case stateColumnsEOF:
+		s.state = stateRows
+		return "EOF", false
+	case stateRows:
+		switch {
+		case header == 0xff:
+			s.state = stateIdle
+			return summarizeError(payload), true
+		case header == protocol.EOF_MARKER && len(payload) < 0xffffff:
+			s.state = stateIdle
+			// CLIENT_DEPRECATE_EOF 下结果集以 0xfe 开头的 OK 包结束
+			if s.deprecateEOF {
+				return "OK (end of result set)", !okMoreResults(payload)
+			}
+			return "EOF", !moreResults(payload, 3)
+		}
+		return fmt.Sprintf("row (%d bytes)", len(payload)), false
+	case statePrepare:
+		if header == protocol.EOF_MARKER && len(payload) < 9 {
+			s.eofsLeft--
+		} else {
+			s.defsLeft--
+		}
+		end := s.defsLeft <= 0 && s.eofsLeft <= 0
+		if end {
+			s.state = stateIdle
+		}
+		if header == protocol.EOF_MARKER && len(payload) < 9 {
+			return "EOF", end
+		}
+		return "column " + columnName(payload), end
+	}
+
+	switch {
+	case header == 0x00 && s.command == protocol.COM_STMT_PREPARE && len(payload) >= 12:
+		columns := int(binary.LittleEndian.Uint16(payload[5:7]))
+		params := int(binary.LittleEndian.Uint16(payload[7:9]))
+		s.defsLeft, s.eofsLeft = columns+params, 0
+		if !s.deprecateEOF {
+			if params > 0 {
+				s.eofsLeft++
+			}
+			if columns > 0 {
+				s.eofsLeft++
+			}
+		}
+		end := s.defsLeft == 0
+		if !end {
+			s.state = statePrepare
+		}
+		return fmt.Sprintf("prepare OK stmt=%d columns=%d params=%d",
+			binary.LittleEndian.Uint32(payload[1:5]), columns, params), end
+	case header == 0x00 && len(payload) >= 7:
+		affected, _ := readLenEnc(payload[1:])
+		return fmt.Sprintf("OK affected=%d", affected), !okMoreResults(payload)
+	case header == 0xff:
+		return summarizeError(payload), true
+	case header == protocol.EOF_MARKER && len(payload) < 9:
+		return "EOF", !moreResults(payload, 3)
+	case header == 0xfb:
+		return "LOCAL INFILE request " + string(payload[1:]), true
+	case s.command == protocol.COM_FIELD_LIST:
+		return "column " + columnName(payload), false
+	}
+	if columns, n := readLenEnc(payload); n == len(payload) && columns > 0 {
+		s.state = stateColumns
+		s.columnsLeft = columns
+		return fmt.Sprintf("result set columns=%d", columns), false
+	}
+	return fmt.Sprintf("data (%d bytes)", len(payload)), true
+}
+
+// summarizeError 错误包的摘要：错误码和信息
+func summarizeError(payload []byte) string {
+	if len(payload) < 3 {
+		return "ERR"
+	}
+	code := binary.LittleEndian.Uint16(payload[1:3])
+	message := payload[3:]
+	if len(message) >= 6 && message[0] == '#' {
+		message = message[6:]
+	}
+	return fmt.Sprintf("ERR %d: %s", code, truncate(string(message)))
+}
+
+// moreResults 判断 EOF 包 offset 处的状态标志是否带有 SERVER_MORE_RESULTS_EXISTS
+func moreResults(payload []byte, offset int) bool {
+	return len(payload) >= offset+2 && binary.LittleEndian.Uint16(payload[offset:offset+2])&protocol.SERVER_MORE_RESULTS_EXISTS != 0
+}
+
+// okMoreResults 判断 OK 包的状态标志是否带有 SERVER_MORE_RESULTS_EXISTS
+func okMoreResults(payload []byte) bool {
+	_, n := readLenEnc(payload[1:])
+	if n == 0 {
+		return false
+	}
+	_, m := readLenEnc(payload[1+n:])
+	if m == 0 {
+		return false
+	}
+	return moreResults(payload, 1+n+m)
+}
+
+// columnName 从列定义包中取出列名（catalog、schema、table、org_table 之后的 name）
+func columnName(payload []byte) string {
+	rest := payload
+	for i := 0; i < 5; i++ {
+		length, n := readLenEnc(rest)
+		if n == 0 || uint64(len(rest)-n) < length {
+			return "?"
+		}
+		if i == 4 {
+			return string(rest[n : n+int(length)])
+		}
+		rest = rest[n+int(length):]
+	}
+	return "?"
+}
+
+// summarizeHandshakeResponse 从 HandshakeResponse41 中取出用户名
+func summarizeHandshakeResponse(payload []byte) string {
+	// 能力标志 4 字节、最大包长 4 字节、字符集 1 字节、保留 23 字节
+	if len(payload) > 32 {
+		if user, _, ok := cutNul(payload[32:]); ok {
+			return "handshake response user=" + user
+		}
+	}
+	return fmt.Sprintf("handshake response (%d bytes)", len(payload))
+}
+
+// readLenEnc 读取长度编码整数，返回值和占用的字节数，数据不完整时字节数为 0
+func readLenEnc(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	switch b[0] {
+	case 0xfc:
+		if len(b) >= 3 {
+			return uint64(binary.LittleEndian.Uint16(b[1:3])), 3
+		}
+	case 0xfd:
+		if len(b) >= 4 {
+			return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, 4
+		}
+	case 0xfe:
+		if len(b) >= 9 {
+			return binary.LittleEndian.Uint64(b[1:9]), 9
+		}
+	case 0xfb, 0xff:
+	default:
+		return uint64(b[0]), 1
+	}
+	return 0, 0
+}
+
+// cutNul 读取以 NUL 结尾的字符串
+func cutNul(b []byte) (string, []byte, bool) {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i]), b[i+1:], true
+		}
+	}
+	return "", b, false
+}
+
+// truncate 截断过长的 SQL 或错误信息，按字符截断
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxQuerySummary {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxQuerySummary]) + "..."
+}
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"github.com/kasuganosora/sqlexec/pkg/mysqlerrors"
 	"github.com/kasuganosora/sqlexec/server/protocol"
 )
 
@@ -25,3 +26,9 @@ func (b *ErrorBuilder) Build(sequenceID uint8, errorCode uint16, sqlState string
 	packet.ErrorMessage = errorMessage
 	return packet
 }
+
+// BuildFromError 根据错误构建错误包，错误码和 SQLSTATE 由 mysqlerrors 归类得到
+func (b *ErrorBuilder) BuildFromError(sequenceID uint8, err error) *protocol.ErrorPacket {
+	myErr := mysqlerrors.FromError(err)
+	return b.Build(sequenceID, myErr.Code, myErr.State, myErr.Message)
+}
@@ -2,24 +2,37 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
 	"github.com/kasuganosora/sqlexec/pkg/config"
 	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/idgen"
 	isacl "github.com/kasuganosora/sqlexec/pkg/information_schema"
+	"github.com/kasuganosora/sqlexec/pkg/logging"
+	"github.com/kasuganosora/sqlexec/pkg/lookup"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
 	"github.com/kasuganosora/sqlexec/pkg/optimizer"
+	"github.com/kasuganosora/sqlexec/pkg/parser"
 	"github.com/kasuganosora/sqlexec/pkg/plugin"
 	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
 	"github.com/kasuganosora/sqlexec/pkg/resource/memory"
+	"github.com/kasuganosora/sqlexec/pkg/security"
 	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
+	"github.com/kasuganosora/sqlexec/pkg/sysdb"
 	"github.com/kasuganosora/sqlexec/pkg/utils"
 	"github.com/kasuganosora/sqlexec/pkg/virtual"
+	"github.com/kasuganosora/sqlexec/pkg/workload"
 	"github.com/kasuganosora/sqlexec/server/acl"
 	httpds "github.com/kasuganosora/sqlexec/server/datasource/http"
 	mysqlds "github.com/kasuganosora/sqlexec/server/datasource/mysql"
@@ -31,11 +44,12 @@ import (
 	queryHandlers "github.com/kasuganosora/sqlexec/server/handler/query"
 	simpleHandlers "github.com/kasuganosora/sqlexec/server/handler/simple"
 	"github.com/kasuganosora/sqlexec/server/protocol"
+	"github.com/kasuganosora/sqlexec/server/response"
 )
 
 type Server struct {
 	ctx              context.Context
-	listener         net.Listener
+	listeners        []net.Listener
 	sessionMgr       *pkg_session.SessionMgr
 	config           *config.Config
 	db               *api.DB
@@ -47,36 +61,67 @@ type Server struct {
 	auditLogger      handler.AuditLogger
 	configDir        string                           // 配置目录（用于 config 虚拟数据库）
 	vdbRegistry      *virtual.VirtualDatabaseRegistry // 虚拟数据库注册表
+	sysDB            *sysdb.SystemDB                  // 系统数据库，未启用时为 nil
 	debugEnabled     bool                             // Debug logging switch (from config, default true)
+	connLimiter      *ConnLimiter                     // 连接准入控制（max_connections / max_user_connections）
+	flowControl      handler.FlowControl              // 结果集写出流控
+	metrics          *monitor.MetricsCollector        // 命令计数与慢查询统计（COM_STATISTICS）
+	loginThrottle    *security.LoginThrottle          // 登录失败的指数退避与临时锁定
+	socketConns      atomic.Uint64                    // unix socket 连接计数，用作会话的端口号
 }
 
 type Logger interface {
 	Printf(format string, v ...interface{})
 }
 
-type serverLogger struct {
-	logger *log.Logger
-}
-
-func (l *serverLogger) Printf(format string, v ...interface{}) {
-	l.logger.Printf(format, v...)
-}
+// serverLog 服务器模块的结构化日志
+var serverLog = logging.Module("server")
 
 func NewServer(ctx context.Context, listener net.Listener, cfg *config.Config) *Server {
 	if cfg == nil {
 		cfg = config.DefaultConfig()
 	}
+	// 连接 ID、查询 ID 和变更事件 ID 带有本实例的节点号
+	if err := idgen.SetNode(cfg.Server.NodeID); err != nil {
+		serverLog.Warn("ID 生成器节点号无效，使用 0", "node_id", cfg.Server.NodeID, "err", err)
+	}
 
 	// 初始化 API DB
+	healthInterval, healthTimeout := cfg.Database.HealthCheck.Durations()
+	changeArchiveInterval, _ := time.ParseDuration(cfg.Database.ChangeArchiveInterval)
+	idempotencyTTL, _ := time.ParseDuration(cfg.Database.IdempotencyTTL)
 	db, err := api.NewDB(&api.DBConfig{
 		CacheEnabled: true,
 		CacheSize:    1000,
 		CacheTTL:     300,
 		DebugMode:    false,
 		DatabaseDir:  cfg.Database.DatabaseDir,
+		// 插件数据源通过 get_changes 报告表结构变化
+		SchemaPollInterval: 5 * time.Second,
+		// 其余数据源的表结构漂移通过定期快照检测（SHOW SCHEMA CHANGES）
+		SchemaSnapshotInterval: time.Minute,
+		// 定期删除设置了 TTL 的表中的过期行
+		TTLPurgeInterval: time.Minute,
+		ReadOnly:         cfg.Server.ReadOnly,
+		WritePolicies:    writePolicies(cfg.Database.WritePolicies),
+		OutfileDirs:      cfg.Database.OutfileDirs,
+		Quotas:           cfg.Database.Quotas,
+		Tenancy:          cfg.Database.Tenancy,
+		ColumnEncryption: columnEncryption(cfg.Database.Encryption),
+		BackupS3:         cfg.Database.BackupS3,
+		// 定期把变更日志归档到文件，增量备份从这里补齐变更日志已丢弃的变更
+		ChangeArchiveDir:      cfg.Database.ChangeArchiveDir,
+		ChangeArchiveInterval: changeArchiveInterval,
+		// 定期探测数据源连通性，连续失败达到阈值时隔离
+		HealthCheckInterval: healthInterval,
+		HealthCheckTimeout:  healthTimeout,
+		QuarantineAfter:     cfg.Database.HealthCheck.QuarantineAfter,
+		// 带幂等键的写入语句重复提交时返回第一次执行的结果
+		IdempotencyTTL: idempotencyTTL,
 	})
 	if err != nil {
-		log.Fatalf("初始化 API DB 失败: %v", err)
+		serverLog.Error("初始化 API DB 失败", "err", err)
+		os.Exit(1)
 	}
 
 	// 创建并注册 MVCC 数据源
@@ -89,25 +134,44 @@ func NewServer(ctx context.Context, listener net.Listener, cfg *config.Config) *
 	if db != nil {
 		// 连接数据源
 		if err := memoryDS.Connect(ctx); err != nil {
-			log.Printf("连接内存数据源失败: %v", err)
+			serverLog.Error("连接内存数据源失败", "err", err)
 		}
 
 		// 注册数据源到 API DB
 		if err := db.RegisterDataSource("default", memoryDS); err != nil {
-			log.Printf("注册数据源失败: %v", err)
+			serverLog.Error("注册数据源失败", "datasource", "default", "err", err)
+		} else {
+			serverLog.Info("已注册数据源", "datasource", "default")
+		}
+	}
+
+	// 启用系统数据库时，用户、权限和数据源配置保存在 sqlexec 库中
+	var sysDB *sysdb.SystemDB
+	if cfg.SystemDB.Enabled && db != nil {
+		sysDB, err = openSystemDB(ctx, db, cfg)
+		if errors.Is(err, sysdb.ErrSchemaTooNew) {
+			// 系统数据库由更新版本的 sqlexec 迁移过，继续运行可能覆盖其中的数据
+			serverLog.Error("系统数据库版本高于当前程序，拒绝启动", "err", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			serverLog.Warn("初始化系统数据库失败，改用配置文件", "err", err)
+			sysDB = nil
 		} else {
-			log.Printf("已注册数据源: default")
+			serverLog.Info("已注册系统数据库", "database", sysdb.DatabaseName)
 		}
 	}
 
 	// 初始化 ACL Manager
 	// 使用服务器启动目录作为数据目录
 	dataDir := "."
-	aclManager, err := acl.NewACLManager(dataDir)
+	aclManager, err := acl.NewACLManagerWithStorage(aclStorage(sysDB, dataDir))
 	if err != nil {
-		log.Printf("初始化 ACL Manager 失败: %v", err)
+		serverLog.Error("初始化 ACL Manager 失败", "err", err)
 		// 继续使用未初始化的 ACL（无权限控制）
 		aclManager = nil
+	} else {
+		aclManager.SetPasswordPolicy(cfg.Auth.PasswordPolicy)
 	}
 
 	// 加载 datasources.json 中配置的数据源
@@ -119,9 +183,10 @@ func NewServer(ctx context.Context, listener net.Listener, cfg *config.Config) *
 	dsManager.GetRegistry().Register(httpds.NewHTTPFactory())
 	dsManager.GetRegistry().Register(mysqlds.NewMySQLFactory())
 	dsManager.GetRegistry().Register(pgds.NewPostgreSQLFactory())
-	dsConfigs, err := config_schema.LoadDatasources(configDir)
+	dsStore := datasourceStore(sysDB, configDir)
+	dsConfigs, err := dsStore.LoadDatasources()
 	if err != nil {
-		log.Printf("加载 datasources.json 失败: %v", err)
+		serverLog.Error("加载数据源配置失败", "err", err)
 	} else if len(dsConfigs) > 0 {
 		for _, dsCfg := range dsConfigs {
 			dsCfgCopy := dsCfg
@@ -131,19 +196,19 @@ func NewServer(ctx context.Context, listener net.Listener, cfg *config.Config) *
 				if dsCfg.Type == domain.DataSourceTypeMemory {
 					ds = memory.NewMVCCDataSource(&dsCfgCopy)
 				} else {
-					log.Printf("创建数据源 '%s' 失败: %v", dsCfg.Name, createErr)
+					serverLog.Error("创建数据源失败", "datasource", dsCfg.Name, "type", dsCfg.Type, "err", createErr)
 					continue
 				}
 			}
 			if connectErr := ds.Connect(ctx); connectErr != nil {
-				log.Printf("连接数据源 '%s' 失败: %v", dsCfg.Name, connectErr)
+				serverLog.Error("连接数据源失败", "datasource", dsCfg.Name, "err", connectErr)
 				continue
 			}
 			if regErr := dsManager.Register(dsCfg.Name, ds); regErr != nil {
-				log.Printf("注册数据源 '%s' 失败: %v", dsCfg.Name, regErr)
+				serverLog.Error("注册数据源失败", "datasource", dsCfg.Name, "err", regErr)
 				continue
 			}
-			log.Printf("已从配置加载数据源: %s (type=%s)", dsCfg.Name, dsCfg.Type)
+			serverLog.Info("已从配置加载数据源", "datasource", dsCfg.Name, "type", dsCfg.Type)
 		}
 	}
 
@@ -152,38 +217,103 @@ func NewServer(ctx context.Context, listener net.Listener, cfg *config.Config) *
 	registry := dsManager.GetRegistry()
 	pluginMgr := plugin.NewPluginManager(registry, dsManager, configDir)
 	if err := pluginMgr.ScanAndLoad(pluginDir); err != nil {
-		log.Printf("加载插件失败: %v", err)
+		serverLog.Error("加载插件失败", "dir", pluginDir, "err", err)
+	}
+	optimizer.RegisterPluginManager(pluginMgr)
+
+	// 监视插件目录，新增的插件自动加载，删除的插件自动卸载
+	if interval, _ := time.ParseDuration(cfg.Database.PluginWatchInterval); interval > 0 {
+		pluginMgr.Watch(ctx, pluginDir, interval)
 	}
 
+	// 加载声明式查找表，定义文件或数据文件变化时重新加载
+	applyLookupTables(ctx, db, cfg.Database.LookupTables)
+
+	// 设置时间点查询的历史版本保留时间
+	applyHistoryRetention(ctx, db, cfg.Database.HistoryRetention)
+
+	// 设置回收站保留时间和 FLASHBACK TABLE 使用的变更日志大小
+	applyFlashbackConfig(db, cfg.Database.RecycleBinRetention, cfg.Database.ChangeLogSize)
+
+	// 设置后端连接中断时 SELECT 的重试与故障切换策略
+	applyFailoverConfig(db, cfg.Database.Failover)
+
+	// 设置字符串与数值、日期混合比较以及 CAST 的转换规则（配置加载时已校验）
+	coercion, _ := utils.ParseCoercionMode(cfg.Database.Coercion)
+	utils.SetCoercionMode(coercion)
+
 	// 创建虚拟数据库注册表并注册 config 虚拟数据库
 	vdbRegistry := virtual.NewVirtualDatabaseRegistry()
-	configProvider := config_schema.NewProvider(dsManager, configDir)
+	configProvider := config_schema.NewProviderWithDatasourceStore(dsManager, configDir, dsStore)
 	vdbRegistry.Register(&virtual.VirtualDatabaseEntry{
 		Name:     "config",
 		Provider: configProvider,
 		Writable: true,
 	})
-	log.Printf("已注册虚拟数据库: config")
+	serverLog.Info("已注册虚拟数据库", "database", "config")
 
-	// 注册进程列表提供者（用于 SHOW PROCESSLIST）
+	// 汇总各数据源中的视图定义到系统数据库
+	if sysDB != nil {
+		if err := sysDB.SyncViews(ctx, dsManager.GetAllDataSources()); err != nil {
+			serverLog.Warn("同步视图定义到系统数据库失败", "err", err)
+		}
+	}
+
+	// 注册进程列表提供者（用于 SHOW PROCESSLIST 和 information_schema.PROCESSLIST）
 	optimizer.RegisterProcessListProvider(pkg_session.GetProcessListForOptimizer)
+	isacl.RegisterProcessListProvider(pkg_session.GetProcessListForOptimizer)
+
+	// 配置 SQL 解析缓存
+	parseCacheEntries := cfg.Cache.ParseCache.MaxEntries
+	if !cfg.Cache.ParseCache.Enabled {
+		parseCacheEntries = 0
+	}
+	parser.GetParseCache().Configure(parseCacheEntries, cfg.Cache.ParseCache.MaxSQLLength)
+
+	// 配置语句摘要统计
+	monitor.GetStatementSummary().Configure(cfg.Monitor.StatementSummary.Enabled, cfg.Monitor.StatementSummary.MaxEntries)
+
+	// 配置慢查询日志（HTTP API 管理端点展示）
+	monitor.GetSlowQueryLog().Configure(cfg.Monitor.SlowQuery.Threshold, cfg.Monitor.SlowQuery.MaxEntries)
+	monitor.GetSlowQueryLog().SetExplainSampleRate(cfg.Monitor.SlowQuery.ExplainSampleRate)
+
+	// 配置工作负载分类与排队
+	workload.GetManager().Configure(workloadConfig(cfg.Workload))
 
 	s := &Server{
-		listener:         listener,
 		ctx:              ctx,
 		sessionMgr:       pkg_session.NewSessionMgr(ctx, pkg_session.NewMemoryDriver()),
 		config:           cfg,
 		db:               db,
 		aclManager:       aclManager,
-		handlerRegistry:  handler.NewHandlerRegistry(&serverLogger{logger: log.New(os.Stdout, "[SERVER] ", log.LstdFlags)}),
-		parserRegistry:   handler.NewPacketParserRegistry(&serverLogger{logger: log.New(os.Stdout, "[SERVER] ", log.LstdFlags)}),
-		handshakeHandler: handshakeHandler.NewDefaultHandshakeHandler(db, &serverLogger{logger: log.New(os.Stdout, "[SERVER] ", log.LstdFlags)}),
-		logger:           &serverLogger{logger: log.New(os.Stdout, "[SERVER] ", log.LstdFlags)},
+		handlerRegistry:  handler.NewHandlerRegistry(serverLog),
+		parserRegistry:   handler.NewPacketParserRegistry(serverLog),
+		handshakeHandler: handshakeHandler.NewDefaultHandshakeHandler(db, serverLog),
+		logger:           serverLog,
 		configDir:        configDir,
 		vdbRegistry:      vdbRegistry,
+		sysDB:            sysDB,
 		debugEnabled:     cfg.Server.IsDebugEnabled(),
+		connLimiter:      NewConnLimiter(&cfg.Server),
+		flowControl:      newFlowControl(&cfg.Server),
+		metrics:          monitor.NewMetricsCollector(),
+		loginThrottle:    security.NewLoginThrottle(cfg.Auth.Throttle),
+	}
+	if listener != nil {
+		s.listeners = append(s.listeners, listener)
+	}
+
+	// 握手时校验密码，认证后检查单用户连接数
+	if hh, ok := s.handshakeHandler.(*handshakeHandler.DefaultHandshakeHandler); ok {
+		if cfg.Auth.VerifyPasswords {
+			hh.SetAuthenticator(s.authenticate)
+		}
+		hh.SetAdmission(s.admitUser)
 	}
 
+	// 支持 SET GLOBAL 运行时调整连接上限
+	s.registerGlobalVariableHooks()
+
 	// 注册所有处理器
 	s.registerHandlers()
 
@@ -195,6 +325,10 @@ func NewServer(ctx context.Context, listener net.Listener, cfg *config.Config) *
 		isacl.RegisterACLManager(s.aclManager)
 		s.logger.Printf("已注册 ACL Manager 到 information_schema")
 	}
+	// 注册配额管理器，information_schema.QUOTA_USAGE 展示配额和用量
+	if s.db != nil {
+		isacl.RegisterQuotaManager(s.db.Quotas())
+	}
 
 	return s
 }
@@ -209,6 +343,14 @@ func (s *Server) registerHandlers() {
 	s.handlerRegistry.Register(simpleHandlers.NewStatisticsHandler())
 	s.handlerRegistry.Register(simpleHandlers.NewDebugHandler())
 	s.handlerRegistry.Register(simpleHandlers.NewShutdownHandler())
+	s.handlerRegistry.Register(simpleHandlers.NewResetConnectionHandler())
+
+	// 注册会话处理器
+	changeUser := handshakeHandler.NewChangeUserHandler(s.admitUser)
+	if s.config != nil && s.config.Auth.VerifyPasswords {
+		changeUser.SetAuthenticator(s.authenticate)
+	}
+	s.handlerRegistry.Register(changeUser)
 
 	// 注册查询处理器
 	s.handlerRegistry.Register(queryHandlers.NewQueryHandler())
@@ -233,20 +375,254 @@ func (s *Server) registerParsers() {
 	s.parserRegistry.Register(parsers.NewInitDBPacketParser())
 	s.parserRegistry.Register(parsers.NewFieldListPacketParser())
 	s.parserRegistry.Register(parsers.NewProcessKillPacketParser())
+	s.parserRegistry.Register(parsers.NewChangeUserPacketParser())
+	s.parserRegistry.Register(parsers.NewResetConnectionPacketParser())
 
 	if s.logger != nil {
 		s.logger.Printf("已注册 %d 个包解析器", s.parserRegistry.Count())
 	}
 }
 
+// admitUser 认证（握手或 COM_CHANGE_USER）后占用用户连接名额
+// 先释放该连接原用户的名额，再按新用户检查 max_user_connections
+func (s *Server) admitUser(sess *pkg_session.Session) error {
+	if s.connLimiter != nil {
+		s.connLimiter.ReleaseUser(sess.ThreadID)
+		if err := s.connLimiter.AcquireUser(sess.ThreadID, sess.User); err != nil {
+			return err
+		}
+	}
+	s.grantSuperPrivilege(sess)
+	s.applyResultLimits(sess)
+	s.grantPassthrough(sess)
+	return nil
+}
+
+// applyResultLimits 按配置为用户设置结果集上限，并标记是否为交互式客户端（auto_limit 只作用于交互式客户端）
+func (s *Server) applyResultLimits(sess *pkg_session.Session) {
+	apiSess, ok := sess.GetAPISession().(*api.Session)
+	if !ok || s.config == nil {
+		return
+	}
+	limitConfig := s.config.Session.ResultLimits.ForUser(sess.User)
+	action, err := api.ParseResultLimitAction(limitConfig.Action)
+	if err != nil {
+		action = api.ResultLimitTruncate
+	}
+	apiSess.SetResultLimits(api.ResultLimits{
+		MaxRows:  limitConfig.MaxRows,
+		MaxBytes: limitConfig.MaxBytes,
+		Action:   action,
+	})
+	apiSess.SetInteractive(sess.ClientCapabilities&protocol.CLIENT_INTERACTIVE != 0)
+}
+
+// grantSuperPrivilege 按 ACL 中的 SUPER 权限设置 API Session 是否不受 read_only 和写入策略限制
+func (s *Server) grantSuperPrivilege(sess *pkg_session.Session) {
+	apiSess, ok := sess.GetAPISession().(*api.Session)
+	if !ok {
+		return
+	}
+	super := s.aclManager != nil && s.aclManager.CheckPermission(sess.User, sess.RemoteIP, acl.PrivSuper, "", "", "")
+	apiSess.SetSuperPrivilege(super)
+}
+
+// grantPassthrough 按配置设置用户可以执行 PASSTHROUGH 原生查询的数据源
+func (s *Server) grantPassthrough(sess *pkg_session.Session) {
+	apiSess, ok := sess.GetAPISession().(*api.Session)
+	if !ok || s.config == nil {
+		return
+	}
+	apiSess.SetPassthroughDataSources(s.config.Session.Passthrough.ForUser(sess.User))
+}
+
+// registerGlobalVariableHooks 在服务器的 DB 上注册可在运行时通过 SET GLOBAL 调整的服务器变量
+func (s *Server) registerGlobalVariableHooks() {
+	db := s.db
+	if db == nil {
+		return
+	}
+	db.RegisterGlobalVariableHook("max_connections", func(value string) error {
+		n, err := parseConnectionLimit("max_connections", value)
+		if err != nil {
+			return err
+		}
+		s.connLimiter.SetMaxConnections(n)
+		s.logger.Printf("max_connections 已调整为 %d", n)
+		return nil
+	})
+	db.RegisterGlobalVariableHook("max_user_connections", func(value string) error {
+		n, err := parseConnectionLimit("max_user_connections", value)
+		if err != nil {
+			return err
+		}
+		s.connLimiter.SetMaxUserConnections(n)
+		s.logger.Printf("max_user_connections 已调整为 %d", n)
+		return nil
+	})
+	db.RegisterGlobalVariableHook("read_only", func(value string) error {
+		readOnly, err := parseBoolVariable("read_only", value)
+		if err != nil {
+			return err
+		}
+		db.SetReadOnly(readOnly)
+		s.logger.Printf("read_only 已调整为 %v", readOnly)
+		return nil
+	})
+}
+
+// parseBoolVariable 解析布尔型系统变量值（ON/OFF、1/0、TRUE/FALSE）
+func parseBoolVariable(name, value string) (bool, error) {
+	switch strings.ToUpper(strings.Trim(value, "'\"")) {
+	case "ON", "1", "TRUE":
+		return true, nil
+	case "OFF", "0", "FALSE":
+		return false, nil
+	}
+	return false, fmt.Errorf("Variable '%s' can't be set to the value of '%s'", name, value)
+}
+
+// writePolicies 将配置中的写入策略转换为 domain.WritePolicy（配置加载时已校验）
+func writePolicies(policies map[string]string) map[string]domain.WritePolicy {
+	result := make(map[string]domain.WritePolicy, len(policies))
+	for name, value := range policies {
+		if policy, err := domain.ParseWritePolicy(value); err == nil {
+			result[name] = policy
+		}
+	}
+	return result
+}
+
+// columnEncryption 将配置中的列加密密钥转换为 api.ColumnEncryptionConfig，未配置时返回 nil
+func columnEncryption(cfg *config.EncryptionConfig) *api.ColumnEncryptionConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &api.ColumnEncryptionConfig{Keys: cfg.Keys, DecryptUsers: cfg.DecryptUsers}
+}
+
+// applyHistoryRetention 按配置设置历史版本的保留时间，键为 数据源名 或 数据源名.表名
+func applyHistoryRetention(ctx context.Context, db *api.DB, retention map[string]string) {
+	for scope, value := range retention {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			// 加载配置时已经校验
+			continue
+		}
+		dsName, table := scope, ""
+		if i := strings.Index(scope, "."); i >= 0 {
+			dsName, table = scope[:i], scope[i+1:]
+		}
+		if err := db.SetHistoryRetention(ctx, dsName, table, d); err != nil {
+			serverLog.Warn("设置历史版本保留时间失败", "scope", scope, "err", err)
+		}
+	}
+}
+
+// applyFlashbackConfig 按配置设置回收站保留时间和变更日志大小
+func applyFlashbackConfig(db *api.DB, recycleBinRetention string, changeLogSize int) {
+	if recycleBinRetention != "" {
+		d, err := time.ParseDuration(recycleBinRetention)
+		if err == nil {
+			err = db.SetRecycleBinRetention(d)
+		}
+		if err != nil {
+			serverLog.Warn("设置回收站保留时间失败", "err", err)
+		}
+	}
+	if changeLogSize > 0 {
+		if err := db.SetChangeLogSize(changeLogSize); err != nil {
+			serverLog.Warn("设置变更日志大小失败", "size", changeLogSize, "err", err)
+		}
+	}
+}
+
+// applyLookupTables 把配置的查找表加载到独立的内存数据库。该数据库的内容由定义决定，
+// 未单独配置写入策略时设为只读
+func applyLookupTables(ctx context.Context, db *api.DB, cfg config.LookupTablesConfig) {
+	if !cfg.Enabled() {
+		return
+	}
+	name := cfg.Database
+	if name == "" {
+		name = "lookup"
+	}
+	ds := memory.NewMVCCDataSource(&domain.DataSourceConfig{Type: domain.DataSourceTypeMemory, Name: name, Writable: true})
+	if err := ds.Connect(ctx); err != nil {
+		serverLog.Error("连接查找表数据库失败", "database", name, "err", err)
+		return
+	}
+	if err := db.RegisterDataSource(name, ds); err != nil {
+		serverLog.Error("注册查找表数据库失败", "database", name, "err", err)
+		return
+	}
+	if db.WritePolicy(name) == domain.WritePolicyDefault {
+		_ = db.SetWritePolicy(name, domain.WritePolicyReadOnly)
+	}
+
+	loader := lookup.NewLoader(ds, cfg.Tables, cfg.File)
+	loader.OnReload(func(tables []string) {
+		for _, table := range tables {
+			db.InvalidateTable(table)
+		}
+	})
+	if err := loader.Load(ctx); err != nil {
+		serverLog.Error("加载查找表失败", "database", name, "err", err)
+	} else {
+		serverLog.Info("已加载查找表", "database", name)
+	}
+	// 检查间隔在加载配置时已经校验
+	if interval, _ := time.ParseDuration(cfg.WatchInterval); interval > 0 {
+		loader.Watch(ctx, interval)
+	}
+}
+
+// applyFailoverConfig 按配置设置各数据库的故障切换策略
+func applyFailoverConfig(db *api.DB, failover map[string]config.FailoverConfig) {
+	for name, fo := range failover {
+		// 等待时间在加载配置时已经校验
+		baseBackoff, _ := time.ParseDuration(fo.BaseBackoff)
+		maxBackoff, _ := time.ParseDuration(fo.MaxBackoff)
+		err := db.SetFailoverPolicy(name, &api.FailoverPolicy{
+			Backup:      fo.Backup,
+			MaxRetries:  fo.MaxRetries,
+			BaseBackoff: baseBackoff,
+			MaxBackoff:  maxBackoff,
+			RetryBudget: fo.RetryBudget,
+		})
+		if err != nil {
+			serverLog.Warn("设置故障切换策略失败", "database", name, "err", err)
+		}
+	}
+}
+
+// parseConnectionLimit 解析连接上限变量值（非负整数）
+func parseConnectionLimit(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Incorrect argument type to variable '%s'", name)
+	}
+	return n, nil
+}
+
+// GetConnLimiter 返回连接准入控制器
+func (s *Server) GetConnLimiter() *ConnLimiter {
+	return s.connLimiter
+}
+
 // SetDB 设置服务器的 DB 实例（用于测试）
 func (s *Server) SetDB(db *api.DB) {
 	s.db = db
+	s.registerGlobalVariableHooks()
 }
 
 // SetAuditLogger 设置审计日志记录器
 func (s *Server) SetAuditLogger(al handler.AuditLogger) {
 	s.auditLogger = al
+	// TTL 清理等后台任务也记录审计日志
+	if dbAudit, ok := al.(api.AuditLogger); ok && s.db != nil {
+		s.db.SetAuditLogger(dbAudit)
+	}
 }
 
 // GetDB 返回服务器的 DB 实例
@@ -254,31 +630,92 @@ func (s *Server) GetDB() *api.DB {
 	return s.db
 }
 
+// GetACLManager 返回服务器的 ACL 管理器，ACL 初始化失败时为 nil
+func (s *Server) GetACLManager() *acl.ACLManager {
+	return s.aclManager
+}
+
+// recordCommand 记录一条命令的执行；查询类命令超过慢查询阈值时计为慢查询
+func (s *Server) recordCommand(commandType uint8, duration time.Duration, success bool) {
+	if s.metrics == nil || s.config == nil {
+		return
+	}
+	s.metrics.RecordQuery(duration, success, "")
+	switch commandType {
+	case protocol.COM_QUERY, protocol.COM_STMT_EXECUTE:
+		if threshold := s.config.Monitor.SlowQuery.Threshold; threshold > 0 && duration > threshold {
+			s.metrics.RecordSlowQuery()
+		}
+	}
+}
+
+// ServerStats 实现 handler.StatsProvider
+func (s *Server) ServerStats() handler.ServerStats {
+	var stats handler.ServerStats
+	if s.metrics != nil {
+		stats.Uptime = s.metrics.GetUptime()
+		stats.Questions = s.metrics.GetQueryCount()
+		stats.SlowQueries = s.metrics.GetSlowQueryCount()
+	}
+	if s.connLimiter != nil {
+		stats.Threads = s.connLimiter.Active()
+	}
+	if s.db != nil {
+		for _, name := range s.db.GetDataSourceNames() {
+			ds, err := s.db.GetDataSource(name)
+			if err != nil {
+				continue
+			}
+			if tables, err := ds.GetTables(s.ctx); err == nil {
+				stats.OpenTables += len(tables)
+			}
+		}
+	}
+	return stats
+}
+
 // GetConfigDir 返回配置目录路径
 func (s *Server) GetConfigDir() string {
 	return s.configDir
 }
 
+// GetSystemDB 返回系统数据库，未启用时返回 nil
+func (s *Server) GetSystemDB() *sysdb.SystemDB {
+	return s.sysDB
+}
+
 // GetVirtualDBRegistry 返回虚拟数据库注册表
 func (s *Server) GetVirtualDBRegistry() *virtual.VirtualDatabaseRegistry {
 	return s.vdbRegistry
 }
 
+// AddListener 增加一个监听地址，需要在 Start 之前调用。
+// *Listener 接受的连接使用该地址的 TLS、只读和访问控制设置
+func (s *Server) AddListener(listener net.Listener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Start 在所有监听地址上接受连接，任一地址停止接受连接时返回
 func (s *Server) Start() (err error) {
+	if len(s.listeners) == 0 {
+		return errors.New("no listener")
+	}
 	acceptChan := make(chan net.Conn)
-	errChan := make(chan error, 1)
-
-	// 启动监听协程
-	go func() {
-		for {
-			conn, err := s.listener.Accept()
-			if err != nil {
-				errChan <- err
-				return
+	errChan := make(chan error, len(s.listeners))
+
+	// 每个监听地址一个监听协程
+	for _, listener := range s.listeners {
+		go func(listener net.Listener) {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					errChan <- err
+					return
+				}
+				acceptChan <- conn
 			}
-			acceptChan <- conn
-		}
-	}()
+		}(listener)
+	}
 
 	// 主循环
 	for {
@@ -296,9 +733,17 @@ func (s *Server) Start() (err error) {
 func (s *Server) handleConnection(conn net.Conn) (err error) {
 	defer conn.Close()
 
+	// panic 只断开当前连接，不影响其他连接和进程
+	var sess *pkg_session.Session
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.recoverPanic(monitor.PanicScopeConnection, sess, 0, nil, r)
+		}
+	}()
+
 	// 调试：检查 server 的关键字段是否为 nil
 	if s == nil {
-		log.Printf("[严重错误] server 为 nil!")
+		serverLog.Error("server 为 nil")
 		return fmt.Errorf("server is nil")
 	}
 	if s.sessionMgr == nil {
@@ -306,20 +751,50 @@ func (s *Server) handleConnection(conn net.Conn) (err error) {
 		return fmt.Errorf("sessionMgr is nil")
 	}
 	if s.logger == nil {
-		log.Printf("[警告] logger 为 nil，使用默认日志")
+		serverLog.Warn("logger 为 nil，使用默认日志")
 	}
 
 	remoteAddr := conn.RemoteAddr().String()
 	addr, port := utils.ParseRemoteAddr(remoteAddr)
 
+	// 连接所属监听地址的访问控制：客户端地址不在 allowed_hosts 中时以 1130 拒绝
+	var listener *Listener
+	if ac, ok := conn.(acceptedConn); ok {
+		listener = ac.acceptedFrom()
+		if err := listener.admit(conn); err != nil {
+			s.logger.Printf("拒绝连接 %s: %v", remoteAddr, err)
+			s.sendConnectionError(conn, err)
+			return err
+		}
+	}
+	// unix socket 连接没有客户端地址，与 MySQL 一样按 localhost 处理；
+	// 会话按地址和端口区分，每个 socket 连接使用不同的端口号
+	if conn.RemoteAddr().Network() == "unix" {
+		addr, port = "localhost", strconv.FormatUint(s.socketConns.Add(1), 10)
+		remoteAddr = addr
+	}
+
+	// 连接准入控制：超过 max_connections 时排队或直接以 1040 拒绝
+	if s.connLimiter != nil {
+		if err := s.connLimiter.Acquire(s.ctx); err != nil {
+			s.logger.Printf("拒绝连接 %s: %v", remoteAddr, err)
+			s.sendConnectionError(conn, err)
+			return err
+		}
+		defer s.connLimiter.Release()
+	}
+
 	s.logger.Printf("开始获取或创建会话: remoteAddr=%s, addr=%s, port=%s", remoteAddr, addr, port)
-	sess, err := s.sessionMgr.GetOrCreateSession(s.ctx, addr, port)
+	sess, err = s.sessionMgr.GetOrCreateSession(s.ctx, addr, port)
 
 	// 确保连接断开时清理 session 和 ThreadID
 	if sess != nil {
 		defer func() {
+			if s.connLimiter != nil {
+				s.connLimiter.ReleaseUser(sess.ThreadID)
+			}
 			s.sessionMgr.CleanupSession(s.ctx, sess)
-			s.logger.Printf("已清理会话: SessionID=%s, ThreadID=%d", sess.ID, sess.ThreadID)
+			s.logger.Printf("已清理会话: SessionID=%s, ThreadID=%d, ConnectionID=%d", sess.ID, sess.ThreadID, sess.ConnectionID)
 		}()
 	}
 	if err != nil {
@@ -334,7 +809,7 @@ func (s *Server) handleConnection(conn net.Conn) (err error) {
 	// 调试：打印 sess 指针和字段信息
 	s.logger.Printf("调试: sess 指针 = %p, &sess.ID = %p, &sess.ThreadID = %p", sess, &sess.ID, &sess.ThreadID)
 
-	s.logger.Printf("新连接来自: %s:%s, SessionID: %s, ThreadID: %d", addr, port, sess.ID, sess.ThreadID)
+	s.logger.Printf("新连接来自: %s:%s, SessionID: %s, ThreadID: %d, ConnectionID: %d", addr, port, sess.ID, sess.ThreadID, sess.ConnectionID)
 
 	// 创建 API Session 并关联到协议 Session
 	if s.db != nil && sess.GetAPISession() == nil {
@@ -342,13 +817,27 @@ func (s *Server) handleConnection(conn net.Conn) (err error) {
 		apiSess.SetThreadID(sess.ThreadID)          // 设置 threadID 用于 KILL 查询
 		apiSess.SetTraceID(sess.TraceID)            // 传播 trace-id 用于请求追踪
 		apiSess.SetVirtualDBRegistry(s.vdbRegistry) // 设置虚拟数据库注册表
+		if listener != nil && listener.readOnly {
+			apiSess.SetReadOnly(true) // 只读监听地址上的连接拒绝所有写入语句
+		}
 		sess.SetAPISession(apiSess)
 		s.logger.Printf("已为连接创建 API Session, ThreadID=%d", sess.ThreadID)
 	}
 
+	// 配置了协议跟踪目录时包装连接，握手和命令都经过跟踪
+	traced := s.traceConnection(conn, sess)
+	if traced != nil {
+		conn = traced
+		defer s.stopProtocolTrace(traced, sess)
+	}
+
 	if len(sess.User) == 0 {
 		// 使用注册的握手处理器处理握手
 		err = s.handshakeHandler.Handle(conn, sess)
+		// 未收到认证包的连接（如端口探测）不记录登录
+		if s.auditLogger != nil && sess.User != "" {
+			s.auditLogger.LogClientLogin(sess.TraceID, sess.User, addr, sess.ConnectionAttributes, err == nil)
+		}
 		if err != nil {
 			return err
 		}
@@ -377,9 +866,19 @@ func (s *Server) handleConnection(conn net.Conn) (err error) {
 		// 使用注册中心处理命令
 		handlerCtx := handler.NewHandlerContext(sess, conn, commandType, s.logger, s.auditLogger)
 		handlerCtx.DebugEnabled = s.debugEnabled
-		err = s.handlerRegistry.Handle(handlerCtx, commandType, commandPack)
+		handlerCtx.Stats = s
+		handlerCtx.FlowControl = s.flowControl
+		start := time.Now()
+		err = s.handleCommand(handlerCtx, commandType, commandPack)
+		s.recordCommand(commandType, time.Since(start), err == nil)
+		if traced != nil {
+			s.syncProtocolTrace(traced, sess)
+		}
 		if err != nil {
 			s.logger.Printf("处理命令失败: %v", err)
+			if errors.Is(err, handler.ErrCloseConnection) {
+				return err
+			}
 			// Per MySQL protocol, a single query failure should not terminate
 			// the connection. The handler should have already sent an error packet.
 			continue
@@ -391,3 +890,77 @@ func (s *Server) handleConnection(conn net.Conn) (err error) {
 		}
 	}
 }
+
+// handleCommand 处理一条命令，命令执行中的 panic 转换为错误包发送给客户端，连接继续可用
+func (s *Server) handleCommand(ctx *handler.HandlerContext, commandType uint8, commandPack interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.recoverPanic(monitor.PanicScopeStatement, ctx.Session, commandType, commandPack, r)
+			if sendErr := ctx.SendError(err); sendErr != nil {
+				err = fmt.Errorf("%w: %v", handler.ErrCloseConnection, sendErr)
+			}
+		}
+	}()
+	return s.handlerRegistry.Handle(ctx, commandType, commandPack)
+}
+
+// recoverPanic 记录被恢复的 panic：增加计数、保存诊断报告并把堆栈写入日志
+func (s *Server) recoverPanic(scope string, sess *pkg_session.Session, commandType uint8, commandPack interface{}, r any) *monitor.PanicError {
+	report := monitor.PanicReport{
+		Scope: scope,
+		Value: fmt.Sprint(r),
+		Stack: string(debug.Stack()),
+	}
+	if sess != nil {
+		report.ConnectionID = sess.ConnectionID
+		report.User = sess.User
+	}
+	if scope == monitor.PanicScopeStatement {
+		report.Command = fmt.Sprintf("0x%02x", commandType)
+	}
+	if q, ok := commandPack.(*protocol.ComQueryPacket); ok {
+		// 解析器只保存原始包，查询文本在 Payload 中（跳过第一个字节的 Command）
+		report.Query = q.Query
+		if report.Query == "" && len(q.Payload) > 1 {
+			report.Query = strings.TrimSpace(string(q.Payload[1:]))
+		}
+	}
+	perr := monitor.GetPanicStats().Record(report)
+	serverLog.Error("recovered panic",
+		"panic_id", perr.Report.ID,
+		"scope", scope,
+		"conn", report.ConnectionID,
+		"user", report.User,
+		"command", report.Command,
+		"query", report.Query,
+		"panic", report.Value,
+		"stack", report.Stack)
+	return perr
+}
+
+// newFlowControl 根据服务器配置创建结果集写出的流控参数
+func newFlowControl(cfg *config.ServerConfig) handler.FlowControl {
+	fc := handler.FlowControl{
+		BatchSize:      cfg.NetBufferLength,
+		BufferMax:      cfg.NetBufferMax,
+		WriteTimeout:   cfg.NetWriteTimeout,
+		StallThreshold: cfg.NetStallThreshold,
+	}
+	if fc.WriteTimeout == 0 {
+		fc.WriteTimeout = -1 // 配置为 0 表示不限制
+	}
+	return fc
+}
+
+// sendConnectionError 在握手之前拒绝连接时发送错误包（序列号为 0，代替握手包）
+func (s *Server) sendConnectionError(conn net.Conn, err error) {
+	errPacket := response.NewErrorBuilder().BuildFromError(0, err)
+	data, marshalErr := errPacket.Marshal()
+	if marshalErr != nil {
+		s.logger.Printf("序列化错误包失败: %v", marshalErr)
+		return
+	}
+	if _, writeErr := conn.Write(data); writeErr != nil {
+		s.logger.Printf("发送错误包失败: %v", writeErr)
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"log"
 	"net"
 	"os"
 	"testing"
@@ -10,8 +9,11 @@ import (
 
 	"github.com/kasuganosora/sqlexec/pkg/api"
 	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/lookup"
+	"github.com/kasuganosora/sqlexec/pkg/monitor"
 	pkg_session "github.com/kasuganosora/sqlexec/pkg/session"
 	"github.com/kasuganosora/sqlexec/server/handler"
+	"github.com/kasuganosora/sqlexec/server/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,13 +33,21 @@ func (m *mockHandshakeHandler) Name() string {
 
 var _ handler.HandshakeHandler = (*mockHandshakeHandler)(nil)
 
+// newTestServer creates a server inside a temporary working directory, because
+// NewServer keeps users.json, permissions.json and datasources.json in the current directory
+func newTestServer(t *testing.T, ctx context.Context, listener net.Listener, cfg *config.Config) *Server {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	return NewServer(ctx, listener, cfg)
+}
+
 func TestNewServer(t *testing.T) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	require.NoError(t, err)
 	defer listener.Close()
 
 	ctx := context.Background()
-	s := NewServer(ctx, listener, nil)
+	s := newTestServer(t, ctx, listener, nil)
 	require.NotNil(t, s)
 
 	assert.NotNil(t, s.GetDB())
@@ -55,7 +65,7 @@ func TestNewServer_WithConfig(t *testing.T) {
 	defer listener.Close()
 
 	cfg := config.DefaultConfig()
-	s := NewServer(context.Background(), listener, cfg)
+	s := newTestServer(t, context.Background(), listener, cfg)
 	require.NotNil(t, s)
 	assert.Equal(t, cfg, s.config)
 }
@@ -65,7 +75,7 @@ func TestServer_SetDB(t *testing.T) {
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 
 	newDB, err := api.NewDB(&api.DBConfig{
@@ -83,7 +93,7 @@ func TestServer_GetConfigDir(t *testing.T) {
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 	assert.Equal(t, ".", s.GetConfigDir())
 }
@@ -93,7 +103,7 @@ func TestServer_Start_ContextCancel(t *testing.T) {
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	s := NewServer(ctx, listener, nil)
+	s := newTestServer(t, ctx, listener, nil)
 	require.NotNil(t, s)
 
 	done := make(chan error, 1)
@@ -110,7 +120,7 @@ func TestServer_Start_ListenerClose(t *testing.T) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	require.NoError(t, err)
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 
 	done := make(chan error, 1)
@@ -146,15 +156,62 @@ func TestNewServer_WithDatasources(t *testing.T) {
 	assert.NotNil(t, s.GetDB())
 }
 
+func TestNewServer_WithSystemDB(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmpDir))
+	defer os.Chdir(origDir)
+
+	// Existing datasources.json is imported into the system database on first start
+	dsJSON := `[{"name":"test_ds","type":"memory","writable":true}]`
+	require.NoError(t, os.WriteFile("datasources.json", []byte(dsJSON), 0644))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.SystemDB.Enabled = true
+	s := NewServer(context.Background(), listener, cfg)
+	require.NotNil(t, s.GetSystemDB())
+	defer s.GetSystemDB().Close(context.Background())
+	_, err = os.Stat("users.json")
+	assert.True(t, os.IsNotExist(err), "users are kept in the system database")
+
+	session := s.GetDB().Session()
+	defer session.Close()
+	rows, err := session.QueryAll("SELECT name, type FROM sqlexec.datasources")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "test_ds", rows[0]["name"])
+	assert.Equal(t, "memory", rows[0]["type"])
+
+	rows, err = session.QueryAll("SELECT user, privileges FROM sqlexec.users")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "root", rows[0]["user"])
+	assert.Contains(t, rows[0]["privileges"], "SUPER")
+
+	_, err = session.Execute("DELETE FROM sqlexec.users")
+	assert.Error(t, err, "the system database is read-only")
+
+	require.NoError(t, s.GetACLManager().CreateUser("%", "app", "secret"))
+	rows, err = session.QueryAll("SELECT host FROM sqlexec.users WHERE user = 'app'")
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+}
+
 func TestServerLogger(t *testing.T) {
-	l := &serverLogger{logger: log.New(os.Stdout, "[TEST] ", 0)}
+	var l Logger = serverLog
 	// Should not panic
 	l.Printf("test message %d", 42)
 }
 
 func TestServer_HandleConnection_NilSessionMgr(t *testing.T) {
 	s := &Server{
-		logger: &serverLogger{logger: log.New(os.Stdout, "[TEST] ", 0)},
+		logger: serverLog,
 	}
 	// Use a pipe for connection
 	clientConn, serverConn := net.Pipe()
@@ -170,7 +227,7 @@ func TestServer_HandleConnection_ClosedConn(t *testing.T) {
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 
 	// Use a pipe; close client side immediately to trigger EOF on read
@@ -187,7 +244,7 @@ func TestServer_HandleConnection_QuitCommand(t *testing.T) {
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 	// Replace handshake handler with mock to skip the handshake protocol
 	s.handshakeHandler = &mockHandshakeHandler{}
@@ -219,7 +276,7 @@ func TestServer_HandleConnection_PingThenQuit(t *testing.T) {
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 	s.handshakeHandler = &mockHandshakeHandler{}
 
@@ -264,7 +321,7 @@ func TestServer_HandleConnection_HandlerError_Continue(t *testing.T) {
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 	s.handshakeHandler = &mockHandshakeHandler{}
 	// Set db to nil so no API session is created, causing query handler to error
@@ -310,12 +367,83 @@ func TestServer_HandleConnection_HandlerError_Continue(t *testing.T) {
 	clientConn.Close()
 }
 
+// panicQueryHandler panics on every COM_QUERY
+type panicQueryHandler struct{}
+
+func (h *panicQueryHandler) Handle(ctx *handler.HandlerContext, packet interface{}) error {
+	var m map[string]int
+	m["boom"] = 1
+	return nil
+}
+
+func (h *panicQueryHandler) Command() uint8 { return protocol.COM_QUERY }
+func (h *panicQueryHandler) Name() string   { return "PanicQueryHandler" }
+
+func TestServer_HandleConnection_StatementPanic(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newTestServer(t, context.Background(), listener, nil)
+	require.NotNil(t, s)
+	s.handshakeHandler = &mockHandshakeHandler{}
+	quit, ok := s.handlerRegistry.Get(protocol.COM_QUIT)
+	require.True(t, ok)
+	s.handlerRegistry = handler.NewHandlerRegistry(nil)
+	require.NoError(t, s.handlerRegistry.Register(&panicQueryHandler{}))
+	require.NoError(t, s.handlerRegistry.Register(quit))
+
+	stats := monitor.GetPanicStats()
+	stats.Reset()
+	defer stats.Reset()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.handleConnection(serverConn)
+	}()
+
+	sql := "SELECT 1"
+	payload := append([]byte{0x03}, []byte(sql)...)
+	pktLen := len(payload)
+	_, err = clientConn.Write(append([]byte{byte(pktLen), byte(pktLen >> 8), byte(pktLen >> 16), 0x00}, payload...))
+	require.NoError(t, err)
+
+	// 客户端收到错误包，连接仍然可用
+	buf := make([]byte, 4096)
+	n, err := clientConn.Read(buf)
+	require.NoError(t, err)
+	require.Greater(t, n, 4)
+	assert.Equal(t, byte(0xff), buf[4])
+	assert.Contains(t, string(buf[:n]), "server panic")
+
+	_, err = clientConn.Write([]byte{0x01, 0x00, 0x00, 0x00, 0x01})
+	require.NoError(t, err)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("handleConnection did not return in time")
+	}
+
+	snap := stats.Snapshot()
+	assert.Equal(t, int64(1), snap.Statement)
+	assert.Equal(t, int64(0), snap.Connection)
+	require.Len(t, snap.Recent, 1)
+	assert.Equal(t, "test_user", snap.Recent[0].User)
+	assert.Equal(t, sql, snap.Recent[0].Query)
+	assert.Contains(t, snap.Recent[0].Value, "nil map")
+	assert.Contains(t, snap.Recent[0].Stack, "panicQueryHandler")
+}
+
 func TestServer_HandleConnection_UnknownCommand(t *testing.T) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	require.NoError(t, err)
 	defer listener.Close()
 
-	s := NewServer(context.Background(), listener, nil)
+	s := newTestServer(t, context.Background(), listener, nil)
 	require.NotNil(t, s)
 	s.handshakeHandler = &mockHandshakeHandler{}
 
@@ -340,3 +468,91 @@ func TestServer_HandleConnection_UnknownCommand(t *testing.T) {
 		t.Fatal("handleConnection did not return in time")
 	}
 }
+
+func TestServer_SetGlobalReadOnly(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newTestServer(t, context.Background(), listener, nil)
+	require.NotNil(t, s)
+
+	apiSess := s.GetDB().Session()
+	defer apiSess.Close()
+
+	_, err = apiSess.Query("SET GLOBAL read_only = ON")
+	require.NoError(t, err)
+	assert.True(t, s.GetDB().IsReadOnly())
+
+	_, err = apiSess.Query("CREATE TABLE ro_t (id INT PRIMARY KEY)")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--read-only")
+
+	// 具有 SUPER 权限的用户不受 read_only 限制
+	sess := &pkg_session.Session{ThreadID: 7, User: "root", RemoteIP: "127.0.0.1"}
+	sess.SetAPISession(apiSess)
+	require.NoError(t, s.admitUser(sess))
+	_, err = apiSess.Query("CREATE TABLE ro_t (id INT PRIMARY KEY)")
+	require.NoError(t, err)
+
+	_, err = apiSess.Query("SET GLOBAL read_only = 'maybe'")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Variable 'read_only' can't be set")
+
+	_, err = apiSess.Query("SET GLOBAL read_only = 0")
+	require.NoError(t, err)
+	assert.False(t, s.GetDB().IsReadOnly())
+}
+
+func TestServer_AdmitUserAppliesResultLimits(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Session.ResultLimits = config.ResultLimitConfig{
+		MaxRows: 1000,
+		Users: map[string]config.ResultLimitConfig{
+			"root": {MaxRows: 10, MaxBytes: 4096, Action: "error"},
+		},
+	}
+	s := newTestServer(t, context.Background(), listener, cfg)
+	require.NotNil(t, s)
+
+	apiSess := s.GetDB().Session()
+	defer apiSess.Close()
+
+	sess := &pkg_session.Session{ThreadID: 8, User: "root", RemoteIP: "127.0.0.1"}
+	sess.SetAPISession(apiSess)
+	require.NoError(t, s.admitUser(sess))
+	assert.Equal(t, api.ResultLimits{MaxRows: 10, MaxBytes: 4096, Action: api.ResultLimitError}, apiSess.ResultLimits())
+
+	sess.User = "app"
+	require.NoError(t, s.admitUser(sess))
+	assert.Equal(t, api.ResultLimits{MaxRows: 1000, Action: api.ResultLimitTruncate}, apiSess.ResultLimits())
+}
+
+func TestApplyLookupTables(t *testing.T) {
+	db, err := api.NewDB(&api.DBConfig{DefaultLogger: api.NewDefaultLogger(api.LogError)})
+	require.NoError(t, err)
+	defer db.Close()
+
+	applyLookupTables(context.Background(), db, config.LookupTablesConfig{
+		Database: "ref",
+		Tables: []lookup.Table{{
+			Name:    "countries",
+			Columns: []lookup.Column{{Name: "code", Primary: true}, {Name: "name"}},
+			Rows:    []interface{}{[]interface{}{"US", "United States"}, []interface{}{"FR", "France"}},
+		}},
+	})
+
+	sess := db.Session()
+	defer sess.Close()
+	row, err := sess.QueryOne("SELECT name FROM ref.countries WHERE code = 'FR'")
+	require.NoError(t, err)
+	assert.Equal(t, "France", row["name"])
+
+	// 查找表的内容由定义决定，SQL 不能修改
+	_, err = sess.Execute("INSERT INTO ref.countries VALUES ('DE', 'Germany')")
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kasuganosora/sqlexec/pkg/api"
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/config_schema"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/kasuganosora/sqlexec/pkg/sysdb"
+	"github.com/kasuganosora/sqlexec/server/acl"
+)
+
+// openSystemDB 打开系统数据库，执行待执行的迁移，并注册为数据库 sqlexec。
+// 未单独配置写入策略时设为只读，系统表只能由对应模块或具有 SUPER 权限的会话修改
+func openSystemDB(ctx context.Context, db *api.DB, cfg *config.Config) (*sysdb.SystemDB, error) {
+	ds, err := sysdb.NewStorage(cfg.SystemDB.Storage, cfg.SystemDBDataDir())
+	if err != nil {
+		return nil, err
+	}
+	sys, err := sysdb.Open(ctx, ds)
+	if err != nil {
+		ds.Close(ctx)
+		return nil, err
+	}
+	if err := db.RegisterDataSource(sysdb.DatabaseName, ds); err != nil {
+		sys.Close(ctx)
+		return nil, fmt.Errorf("register system database: %w", err)
+	}
+	if _, ok := cfg.Database.WritePolicies[sysdb.DatabaseName]; !ok {
+		if err := db.SetWritePolicy(sysdb.DatabaseName, domain.WritePolicyReadOnly); err != nil {
+			return nil, err
+		}
+	}
+	return sys, nil
+}
+
+// aclStorage 返回 ACL 的存储：启用系统数据库时为 sqlexec.users 和 sqlexec.privileges，
+// 首次启用时导入 users.json 和 permissions.json 中已有的用户和权限
+func aclStorage(sys *sysdb.SystemDB, dataDir string) acl.Storage {
+	files := acl.NewFileStorage(dataDir)
+	if sys == nil {
+		return files
+	}
+	storage := acl.NewSystemDBStorage(sys)
+	if migrated, err := acl.MigrateStorage(files, storage); err != nil {
+		serverLog.Error("导入 users.json 和 permissions.json 到系统数据库失败", "err", err)
+	} else if migrated {
+		serverLog.Info("已将 users.json 和 permissions.json 导入系统数据库")
+	}
+	return storage
+}
+
+// datasourceStore 返回数据源配置的存储：启用系统数据库时为 sqlexec.datasources，
+// 首次启用时导入 datasources.json 中已有的配置
+func datasourceStore(sys *sysdb.SystemDB, configDir string) config_schema.DatasourceStore {
+	files := config_schema.NewFileDatasourceStore(configDir)
+	if sys == nil {
+		return files
+	}
+	store := config_schema.NewSystemDBDatasourceStore(sys)
+	if migrated, err := config_schema.MigrateDatasources(files, store); err != nil {
+		serverLog.Error("导入 datasources.json 到系统数据库失败", "err", err)
+	} else if migrated {
+		serverLog.Info("已将 datasources.json 导入系统数据库")
+	}
+	return store
+}
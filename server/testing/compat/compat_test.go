@@ -0,0 +1,143 @@
+//go:build compat
+
+package compat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqltest"
+)
+
+// compatPort 测试服务器端口，Docker 中的驱动通过宿主机网络连接
+const compatPort = 13340
+
+// checks 每个驱动都要执行的检查，驱动各自实现，名称和含义保持一致：
+//
+//	handshake                  连接到 default 库，VERSION() 非空，DATABASE() 为 default
+//	ping                       驱动的连接存活检查
+//	query_literals             SELECT 1, 'a', NULL 的值和 NULL
+//	ddl_dml                    CREATE TABLE、多行 INSERT、UPDATE、DELETE 的影响行数
+//	parameters                 驱动默认方式（客户端插值）绑定参数，包括引号和反斜杠
+//	server_prepared_statements 服务器端预处理语句（COM_STMT_PREPARE / COM_STMT_EXECUTE）
+//	transaction_commit         开始事务、插入、提交后可见
+//	transaction_rollback       开始事务、插入、回滚后不可见
+//	types_integer              INT 和 BIGINT 的最小、最大值
+//	types_decimal              DECIMAL(10,2) 保留精度和小数位
+//	types_double               DOUBLE
+//	types_string               VARCHAR 中的多字节字符和 4 字节 emoji，10000 字符的 TEXT
+//	types_binary               BLOB 中的任意字节
+//	types_temporal             DATE 和 DATETIME
+//	types_null                 NULL 参数写入后读回 NULL
+//	error_codes                表不存在为 1146，主键重复为 1062
+var checks = []string{
+	"handshake",
+	"ping",
+	"query_literals",
+	"ddl_dml",
+	"parameters",
+	"server_prepared_statements",
+	"transaction_commit",
+	"transaction_rollback",
+	"types_integer",
+	"types_decimal",
+	"types_double",
+	"types_string",
+	"types_binary",
+	"types_temporal",
+	"types_null",
+	"error_codes",
+}
+
+// knownFailures 服务器尚未支持、所有驱动都会失败的检查。支持后检查会通过并报错，提醒从这里删除
+var knownFailures = map[string]string{
+	"server_prepared_statements": "COM_STMT_PREPARE is not implemented",
+	"transaction_commit":         "START TRANSACTION / COMMIT are not supported over the MySQL protocol",
+	"transaction_rollback":       "START TRANSACTION / ROLLBACK are not supported over the MySQL protocol",
+	"types_decimal":              "DECIMAL values are stored as DOUBLE and sent in %g format",
+}
+
+var testServer *mysqltest.TestServer
+
+// driversDir 外部驱动检查脚本所在目录的绝对路径，测试在临时目录中运行
+var driversDir string
+
+// TestMain 在临时目录中启动测试服务器：服务器把 users.json、permissions.json 写在当前目录
+func TestMain(m *testing.M) {
+	var err error
+	if driversDir, err = filepath.Abs("drivers"); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate drivers: %v\n", err)
+		os.Exit(1)
+	}
+	dir, err := os.MkdirTemp("", "sqlexec-compat-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to enter temp dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	testServer = mysqltest.NewTestServer()
+	if err := testServer.Start(compatPort); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start test server: %v\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	testServer.Stop()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// checkResult 驱动报告的一个检查结果，外部驱动以 JSON Lines 输出
+type checkResult struct {
+	Check string `json:"check"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// parseResults 从驱动的输出中读取检查结果，忽略不是 JSON 的行（如安装依赖的输出）
+func parseResults(output []byte) map[string]checkResult {
+	results := make(map[string]checkResult)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var r checkResult
+		if err := json.Unmarshal([]byte(line), &r); err == nil && r.Check != "" {
+			results[r.Check] = r
+		}
+	}
+	return results
+}
+
+// report 把一个驱动的检查结果报告为子测试：已知失败的检查跳过，意外通过时报错
+func report(t *testing.T, results map[string]checkResult) {
+	for _, check := range checks {
+		t.Run(check, func(t *testing.T) {
+			r, ok := results[check]
+			if !ok {
+				t.Fatalf("driver did not report check %s", check)
+			}
+			reason, known := knownFailures[check]
+			switch {
+			case known && r.OK:
+				t.Errorf("check passes now: remove it from knownFailures (%s)", reason)
+			case known:
+				t.Skipf("known failure: %s: %s", reason, r.Error)
+			case !r.OK:
+				t.Error(r.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package compat 连接器兼容性测试：启动服务器，用常见的 MySQL 驱动（go-sql-driver/mysql，
+// 以及在 Docker 中运行的 mysql-connector-python 和 MySQL Connector/J）执行同一组固定的检查，
+// 覆盖握手、参数绑定、预处理语句、事务和类型往返，在发布前发现协议回归。
+//
+// 测试需要 compat 构建标签：
+//
+//	go test -tags compat ./server/testing/compat -v
+//
+// 没有 Docker 时只运行 go-sql-driver 的检查，其余驱动跳过
+package compat
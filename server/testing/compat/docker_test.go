@@ -0,0 +1,118 @@
+//go:build compat
+
+package compat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// 在 Docker 中运行的驱动版本
+const (
+	pythonImage         = "python:3.12-slim"
+	pythonConnector     = "mysql-connector-python==8.4.0"
+	javaImage           = "eclipse-temurin:21-jdk"
+	connectorJVersion   = "8.4.0"
+	connectorJURLFormat = "https://repo1.maven.org/maven2/com/mysql/mysql-connector-j/%[1]s/mysql-connector-j-%[1]s.jar"
+)
+
+// dockerTimeout 拉取镜像、安装驱动并运行检查的超时时间
+const dockerTimeout = 10 * time.Minute
+
+func TestMySQLConnectorPython(t *testing.T) {
+	requireDocker(t)
+	script := filepath.Join(driversDir, "python")
+	out := runDocker(t, pythonImage, []string{script + ":/compat:ro"},
+		"pip install --quiet --disable-pip-version-check "+pythonConnector+
+			" && python /compat/compat.py --host 127.0.0.1 --port "+strconv.Itoa(compatPort))
+	report(t, parseResults(out))
+}
+
+func TestConnectorJ(t *testing.T) {
+	requireDocker(t)
+	script := filepath.Join(driversDir, "java")
+	jar := connectorJJar(t)
+	out := runDocker(t, javaImage, []string{script + ":/compat:ro", jar + ":/lib/connector.jar:ro"},
+		"java -cp /lib/connector.jar /compat/Compat.java 127.0.0.1 "+strconv.Itoa(compatPort))
+	report(t, parseResults(out))
+}
+
+// requireDocker 没有可用的 Docker 时跳过测试
+func requireDocker(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not installed")
+	}
+	if err := exec.Command("docker", "info").Run(); err != nil {
+		t.Skip("docker is not running")
+	}
+}
+
+// runDocker 在容器中运行 script 并返回输出。容器使用宿主机网络连接测试服务器（需要 Linux 上的 Docker）
+func runDocker(t *testing.T, image string, volumes []string, script string) []byte {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), dockerTimeout)
+	defer cancel()
+	args := []string{"run", "--rm", "--network", "host"}
+	for _, v := range volumes {
+		args = append(args, "-v", v)
+	}
+	args = append(args, image, "sh", "-c", script)
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		t.Logf("docker output:\n%s", out)
+		if len(parseResults(out)) == 0 {
+			t.Fatalf("docker run %s: %v", image, err)
+		}
+	}
+	return out
+}
+
+// connectorJJar 下载 Connector/J 到用户缓存目录，已下载时直接使用
+func connectorJJar(t *testing.T) string {
+	t.Helper()
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	dir := filepath.Join(cacheDir, "sqlexec-compat")
+	jar := filepath.Join(dir, "mysql-connector-j-"+connectorJVersion+".jar")
+	if _, err := os.Stat(jar); err == nil {
+		return jar
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(fmt.Sprintf(connectorJURLFormat, connectorJVersion))
+	if err != nil {
+		t.Skipf("cannot download Connector/J: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Skipf("cannot download Connector/J: %s", resp.Status)
+	}
+	tmp, err := os.CreateTemp(dir, "connector-*.jar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		t.Fatal(err)
+	}
+	if err := tmp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp.Name(), jar); err != nil {
+		t.Fatal(err)
+	}
+	return jar
+}
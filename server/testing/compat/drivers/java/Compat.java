@@ -0,0 +1,331 @@
+import java.math.BigDecimal;
+import java.sql.Connection;
+import java.sql.DriverManager;
+import java.sql.PreparedStatement;
+import java.sql.ResultSet;
+import java.sql.SQLException;
+import java.sql.Statement;
+import java.time.LocalDate;
+import java.time.LocalDateTime;
+import java.util.Arrays;
+import java.util.LinkedHashMap;
+import java.util.Map;
+import java.util.Objects;
+
+/**
+ * MySQL Connector/J 的连接器兼容性检查。
+ *
+ * <p>检查的名称和含义与 compat_test.go 中的 checks 一致，每个检查使用一个新连接，
+ * 结果以 JSON Lines 输出到标准输出：{"check": ..., "ok": ..., "error": ...}
+ *
+ * <p>用法：java -cp mysql-connector-j.jar Compat.java host port
+ */
+public class Compat {
+    interface Check {
+        void run(String url) throws Exception;
+    }
+
+    public static void main(String[] args) {
+        String host = args.length > 0 ? args[0] : "127.0.0.1";
+        String port = args.length > 1 ? args[1] : "3306";
+        String url = "jdbc:mysql://" + host + ":" + port + "/default?user=root&password=&sslMode=DISABLED";
+
+        Map<String, Check> checks = new LinkedHashMap<>();
+        checks.put("handshake", u -> {
+            try (Connection c = connect(u); ResultSet rs = query(c, "SELECT VERSION(), DATABASE()")) {
+                rs.next();
+                if (rs.getString(1) == null || rs.getString(1).isEmpty()) {
+                    throw new AssertionError("VERSION() is empty");
+                }
+                expect("DATABASE()", rs.getString(2), "default");
+            }
+        });
+        checks.put("ping", u -> {
+            try (Connection c = connect(u)) {
+                if (!c.isValid(5)) {
+                    throw new AssertionError("isValid() = false");
+                }
+            }
+        });
+        checks.put("query_literals", u -> {
+            try (Connection c = connect(u); ResultSet rs = query(c, "SELECT 1, 'a', NULL")) {
+                rs.next();
+                expect("1", rs.getLong(1), 1L);
+                expect("'a'", rs.getString(2), "a");
+                expect("NULL", rs.getObject(3), null);
+            }
+        });
+        checks.put("ddl_dml", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "items", "id INT PRIMARY KEY, name VARCHAR(50)");
+                execAffected(c, 3, "INSERT INTO " + t + " VALUES (1, 'a'), (2, 'b'), (3, 'c')");
+                execAffected(c, 1, "UPDATE " + t + " SET name = 'B' WHERE id = 2");
+                execAffected(c, 1, "DELETE FROM " + t + " WHERE id = 3");
+                expect("COUNT(*)", count(c, t), 2L);
+            }
+        });
+        // Connector/J 默认在客户端替换 PreparedStatement 的参数
+        checks.put("parameters", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "params", "id INT PRIMARY KEY, name VARCHAR(100)");
+                String tricky = "it's a \\ \"quoted\" value";
+                insertAndSelect(c, t, new String[] {"a", "b", tricky});
+            }
+        });
+        checks.put("server_prepared_statements", u -> {
+            try (Connection c = connect(u + "&useServerPrepStmts=true")) {
+                String t = table(c, "prepared", "id INT PRIMARY KEY, name VARCHAR(50)");
+                insertAndSelect(c, t, new String[] {"name1", "name2", "name3"});
+            }
+        });
+        checks.put("transaction_commit", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "tx_commit", "id INT PRIMARY KEY");
+                c.setAutoCommit(false);
+                exec(c, "INSERT INTO " + t + " VALUES (1)");
+                c.commit();
+                c.setAutoCommit(true);
+                expect("COUNT(*)", count(c, t), 1L);
+            }
+        });
+        checks.put("transaction_rollback", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "tx_rollback", "id INT PRIMARY KEY");
+                c.setAutoCommit(false);
+                exec(c, "INSERT INTO " + t + " VALUES (1)");
+                c.rollback();
+                c.setAutoCommit(true);
+                expect("COUNT(*)", count(c, t), 0L);
+            }
+        });
+        checks.put("types_integer", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "ints", "id INT PRIMARY KEY, i INT, b BIGINT");
+                long[][] values = {{Integer.MIN_VALUE, Long.MIN_VALUE}, {Integer.MAX_VALUE, Long.MAX_VALUE}};
+                for (int id = 0; id < values.length; id++) {
+                    try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (?, ?, ?)")) {
+                        ps.setInt(1, id);
+                        ps.setInt(2, (int) values[id][0]);
+                        ps.setLong(3, values[id][1]);
+                        ps.executeUpdate();
+                    }
+                }
+                for (int id = 0; id < values.length; id++) {
+                    try (ResultSet rs = query(c, "SELECT i, b FROM " + t + " WHERE id = " + id)) {
+                        rs.next();
+                        expect("i", rs.getObject(1) == null ? null : rs.getLong(1), values[id][0]);
+                        expect("b", rs.getObject(2) == null ? null : rs.getLong(2), values[id][1]);
+                    }
+                }
+            }
+        });
+        checks.put("types_decimal", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "decimals", "id INT PRIMARY KEY, d DECIMAL(10,2)");
+                try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (1, ?)")) {
+                    ps.setBigDecimal(1, new BigDecimal("12345678.90"));
+                    ps.executeUpdate();
+                }
+                try (ResultSet rs = query(c, "SELECT d FROM " + t)) {
+                    rs.next();
+                    // 比较文本形式，精度和小数位都要保留
+                    expect("d", rs.getBigDecimal(1).toString(), "12345678.90");
+                }
+            }
+        });
+        checks.put("types_double", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "doubles", "id INT PRIMARY KEY, f DOUBLE");
+                try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (1, ?)")) {
+                    ps.setDouble(1, 3.25);
+                    ps.executeUpdate();
+                }
+                try (ResultSet rs = query(c, "SELECT f FROM " + t)) {
+                    rs.next();
+                    expect("f", rs.getDouble(1), 3.25);
+                }
+            }
+        });
+        checks.put("types_string", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "strings", "id INT PRIMARY KEY, s VARCHAR(50), t TEXT");
+                String s = "héllo 世界 😀";
+                String text = "0123456789".repeat(1000);
+                try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (1, ?, ?)")) {
+                    ps.setString(1, s);
+                    ps.setString(2, text);
+                    ps.executeUpdate();
+                }
+                try (ResultSet rs = query(c, "SELECT s, t FROM " + t)) {
+                    rs.next();
+                    expect("s", rs.getString(1), s);
+                    expect("len(t)", rs.getString(2).length(), text.length());
+                }
+            }
+        });
+        checks.put("types_binary", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "blobs", "id INT PRIMARY KEY, b BLOB");
+                byte[] data = {0x00, 0x01, 0x27, 0x5c, (byte) 0xff};
+                try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (1, ?)")) {
+                    ps.setBytes(1, data);
+                    ps.executeUpdate();
+                }
+                try (ResultSet rs = query(c, "SELECT b FROM " + t)) {
+                    rs.next();
+                    byte[] got = rs.getBytes(1);
+                    if (!Arrays.equals(got, data)) {
+                        throw new AssertionError("b = " + Arrays.toString(got) + ", want " + Arrays.toString(data));
+                    }
+                }
+            }
+        });
+        checks.put("types_temporal", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "temporal", "id INT PRIMARY KEY, d DATE, dt DATETIME");
+                LocalDate date = LocalDate.of(2024, 2, 29);
+                LocalDateTime dt = LocalDateTime.of(2024, 2, 29, 13, 14, 15);
+                try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (1, ?, ?)")) {
+                    ps.setObject(1, date);
+                    ps.setObject(2, dt);
+                    ps.executeUpdate();
+                }
+                try (ResultSet rs = query(c, "SELECT d, dt FROM " + t)) {
+                    rs.next();
+                    expect("d", rs.getObject(1, LocalDate.class), date);
+                    expect("dt", rs.getObject(2, LocalDateTime.class), dt);
+                }
+            }
+        });
+        checks.put("types_null", u -> {
+            try (Connection c = connect(u)) {
+                String t = table(c, "nulls", "id INT PRIMARY KEY, i INT, s VARCHAR(10)");
+                try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (1, ?, ?)")) {
+                    ps.setNull(1, java.sql.Types.INTEGER);
+                    ps.setNull(2, java.sql.Types.VARCHAR);
+                    ps.executeUpdate();
+                }
+                try (ResultSet rs = query(c, "SELECT i, s FROM " + t)) {
+                    rs.next();
+                    expect("i", rs.getObject(1), null);
+                    expect("s", rs.getObject(2), null);
+                }
+            }
+        });
+        checks.put("error_codes", u -> {
+            try (Connection c = connect(u)) {
+                expectErrorCode(c, 1146, "SELECT * FROM compat_java_no_such_table");
+                String t = table(c, "dup", "id INT PRIMARY KEY");
+                exec(c, "INSERT INTO " + t + " VALUES (1)");
+                expectErrorCode(c, 1062, "INSERT INTO " + t + " VALUES (1)");
+            }
+        });
+
+        for (Map.Entry<String, Check> e : checks.entrySet()) {
+            String error = null;
+            try {
+                e.getValue().run(url);
+            } catch (Throwable t) {
+                error = t.getClass().getSimpleName() + ": " + t.getMessage();
+            }
+            System.out.println(result(e.getKey(), error));
+        }
+    }
+
+    static Connection connect(String url) throws SQLException {
+        return DriverManager.getConnection(url);
+    }
+
+    static ResultSet query(Connection c, String sql) throws SQLException {
+        return c.createStatement().executeQuery(sql);
+    }
+
+    static void exec(Connection c, String sql) throws SQLException {
+        try (Statement st = c.createStatement()) {
+            st.execute(sql);
+        }
+    }
+
+    /** 重新创建检查使用的表，返回表名 */
+    static String table(Connection c, String name, String columns) throws SQLException {
+        String t = "compat_java_" + name;
+        exec(c, "DROP TABLE IF EXISTS " + t);
+        exec(c, "CREATE TABLE " + t + " (" + columns + ")");
+        return t;
+    }
+
+    static long count(Connection c, String table) throws SQLException {
+        try (ResultSet rs = query(c, "SELECT COUNT(*) FROM " + table)) {
+            rs.next();
+            return rs.getLong(1);
+        }
+    }
+
+    static void execAffected(Connection c, int want, String sql) throws SQLException {
+        try (Statement st = c.createStatement()) {
+            expect("rows affected by " + sql, st.executeUpdate(sql), want);
+        }
+    }
+
+    /** 用 PreparedStatement 插入 names 并按参数查回最后一个 */
+    static void insertAndSelect(Connection c, String t, String[] names) throws SQLException {
+        try (PreparedStatement ps = c.prepareStatement("INSERT INTO " + t + " VALUES (?, ?)")) {
+            for (int i = 0; i < names.length; i++) {
+                ps.setInt(1, i + 1);
+                ps.setString(2, names[i]);
+                expect("rows affected", ps.executeUpdate(), 1);
+            }
+        }
+        try (PreparedStatement ps = c.prepareStatement("SELECT name FROM " + t + " WHERE id = ?")) {
+            ps.setInt(1, names.length);
+            try (ResultSet rs = ps.executeQuery()) {
+                rs.next();
+                expect("name", rs.getString(1), names[names.length - 1]);
+            }
+        }
+    }
+
+    static void expectErrorCode(Connection c, int code, String sql) {
+        try {
+            exec(c, sql);
+        } catch (SQLException e) {
+            expect("error code of " + sql, e.getErrorCode(), code);
+            return;
+        }
+        throw new AssertionError(sql + ": succeeded, want error " + code);
+    }
+
+    static void expect(String what, Object got, Object want) {
+        if (!Objects.equals(got, want)) {
+            throw new AssertionError(what + " = " + got + ", want " + want);
+        }
+    }
+
+    static String result(String check, String error) {
+        if (error == null) {
+            return "{\"check\":\"" + check + "\",\"ok\":true}";
+        }
+        return "{\"check\":\"" + check + "\",\"ok\":false,\"error\":\"" + jsonEscape(error) + "\"}";
+    }
+
+    static String jsonEscape(String s) {
+        StringBuilder b = new StringBuilder();
+        for (char ch : s.toCharArray()) {
+            switch (ch) {
+                case '"' -> b.append("\\\"");
+                case '\\' -> b.append("\\\\");
+                case '\n' -> b.append("\\n");
+                case '\r' -> b.append("\\r");
+                case '\t' -> b.append("\\t");
+                default -> {
+                    if (ch < 0x20) {
+                        b.append(String.format("\\u%04x", (int) ch));
+                    } else {
+                        b.append(ch);
+                    }
+                }
+            }
+        }
+        return b.toString();
+    }
+}
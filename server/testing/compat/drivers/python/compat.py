@@ -0,0 +1,216 @@
+"""mysql-connector-python 的连接器兼容性检查。
+
+检查的名称和含义与 compat_test.go 中的 checks 一致，每个检查使用一个新连接，
+结果以 JSON Lines 输出到标准输出：{"check": ..., "ok": ..., "error": ...}
+"""
+
+import argparse
+import datetime
+import decimal
+import json
+import sys
+
+import mysql.connector
+
+CHECKS = {}
+
+
+def check(fn):
+    CHECKS[fn.__name__] = fn
+    return fn
+
+
+def expect(what, got, want):
+    if got != want:
+        raise AssertionError("%s = %r, want %r" % (what, got, want))
+
+
+def table(conn, name, columns):
+    """重新创建检查使用的表，返回表名"""
+    t = "compat_py_" + name
+    cur = conn.cursor()
+    cur.execute("DROP TABLE IF EXISTS " + t)
+    cur.execute("CREATE TABLE %s (%s)" % (t, columns))
+    return t
+
+
+def fetch_one(conn, query, params=()):
+    cur = conn.cursor()
+    cur.execute(query, params)
+    return cur.fetchone()
+
+
+def exec_affected(conn, want, query, params=()):
+    cur = conn.cursor()
+    cur.execute(query, params)
+    expect("rows affected by " + query, cur.rowcount, want)
+
+
+def expect_error_code(conn, code, query):
+    try:
+        conn.cursor().execute(query)
+    except mysql.connector.Error as e:
+        expect("error code of " + query, e.errno, code)
+        return
+    raise AssertionError("%s: succeeded, want error %d" % (query, code))
+
+
+@check
+def handshake(conn):
+    version, database = fetch_one(conn, "SELECT VERSION(), DATABASE()")
+    if not version:
+        raise AssertionError("VERSION() is empty")
+    expect("DATABASE()", database, "default")
+
+
+@check
+def ping(conn):
+    conn.ping(reconnect=False)
+
+
+@check
+def query_literals(conn):
+    expect("row", tuple(fetch_one(conn, "SELECT 1, 'a', NULL")), (1, "a", None))
+
+
+@check
+def ddl_dml(conn):
+    t = table(conn, "items", "id INT PRIMARY KEY, name VARCHAR(50)")
+    exec_affected(conn, 3, "INSERT INTO " + t + " VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+    exec_affected(conn, 1, "UPDATE " + t + " SET name = 'B' WHERE id = 2")
+    exec_affected(conn, 1, "DELETE FROM " + t + " WHERE id = 3")
+    expect("COUNT(*)", fetch_one(conn, "SELECT COUNT(*) FROM " + t)[0], 2)
+
+
+@check
+def parameters(conn):
+    t = table(conn, "params", "id INT PRIMARY KEY, name VARCHAR(100)")
+    tricky = 'it\'s a \\ "quoted" value'
+    for i, name in enumerate(["a", "b", tricky]):
+        exec_affected(conn, 1, "INSERT INTO " + t + " VALUES (%s, %s)", (i + 1, name))
+    expect("name", fetch_one(conn, "SELECT name FROM " + t + " WHERE id = %s", (3,))[0], tricky)
+
+
+@check
+def server_prepared_statements(conn):
+    t = table(conn, "prepared", "id INT PRIMARY KEY, name VARCHAR(50)")
+    cur = conn.cursor(prepared=True)
+    for i in range(1, 4):
+        cur.execute("INSERT INTO " + t + " VALUES (%s, %s)", (i, "name%d" % i))
+    cur.execute("SELECT name FROM " + t + " WHERE id = %s", (2,))
+    expect("name", cur.fetchone()[0], "name2")
+
+
+@check
+def transaction_commit(conn):
+    t = table(conn, "tx_commit", "id INT PRIMARY KEY")
+    conn.start_transaction()
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1)")
+    conn.commit()
+    expect("COUNT(*)", fetch_one(conn, "SELECT COUNT(*) FROM " + t)[0], 1)
+
+
+@check
+def transaction_rollback(conn):
+    t = table(conn, "tx_rollback", "id INT PRIMARY KEY")
+    conn.start_transaction()
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1)")
+    conn.rollback()
+    expect("COUNT(*)", fetch_one(conn, "SELECT COUNT(*) FROM " + t)[0], 0)
+
+
+@check
+def types_integer(conn):
+    t = table(conn, "ints", "id INT PRIMARY KEY, i INT, b BIGINT")
+    values = [(-2147483648, -9223372036854775808), (2147483647, 9223372036854775807)]
+    for i, v in enumerate(values):
+        conn.cursor().execute("INSERT INTO " + t + " VALUES (%s, %s, %s)", (i,) + v)
+    for i, want in enumerate(values):
+        expect("(i, b)", tuple(fetch_one(conn, "SELECT i, b FROM " + t + " WHERE id = %s", (i,))), want)
+
+
+@check
+def types_decimal(conn):
+    t = table(conn, "decimals", "id INT PRIMARY KEY, d DECIMAL(10,2)")
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1, %s)", (decimal.Decimal("12345678.90"),))
+    # 比较文本形式，精度和小数位都要保留
+    expect("d", str(fetch_one(conn, "SELECT d FROM " + t)[0]), "12345678.90")
+
+
+@check
+def types_double(conn):
+    t = table(conn, "doubles", "id INT PRIMARY KEY, f DOUBLE")
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1, %s)", (3.25,))
+    expect("f", fetch_one(conn, "SELECT f FROM " + t)[0], 3.25)
+
+
+@check
+def types_string(conn):
+    t = table(conn, "strings", "id INT PRIMARY KEY, s VARCHAR(50), t TEXT")
+    s = "héllo 世界 😀"
+    long = "0123456789" * 1000
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1, %s, %s)", (s, long))
+    got_s, got_t = fetch_one(conn, "SELECT s, t FROM " + t)
+    expect("s", got_s, s)
+    expect("len(t)", len(got_t), len(long))
+
+
+@check
+def types_binary(conn):
+    t = table(conn, "blobs", "id INT PRIMARY KEY, b BLOB")
+    data = b"\x00\x01'\\\xff"
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1, %s)", (data,))
+    expect("b", bytes(fetch_one(conn, "SELECT b FROM " + t)[0]), data)
+
+
+@check
+def types_temporal(conn):
+    t = table(conn, "temporal", "id INT PRIMARY KEY, d DATE, dt DATETIME")
+    date = datetime.date(2024, 2, 29)
+    dt = datetime.datetime(2024, 2, 29, 13, 14, 15)
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1, %s, %s)", (date, dt))
+    expect("(d, dt)", tuple(fetch_one(conn, "SELECT d, dt FROM " + t)), (date, dt))
+
+
+@check
+def types_null(conn):
+    t = table(conn, "nulls", "id INT PRIMARY KEY, i INT, s VARCHAR(10)")
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1, %s, %s)", (None, None))
+    expect("(i, s)", tuple(fetch_one(conn, "SELECT i, s FROM " + t)), (None, None))
+
+
+@check
+def error_codes(conn):
+    expect_error_code(conn, 1146, "SELECT * FROM compat_py_no_such_table")
+    t = table(conn, "dup", "id INT PRIMARY KEY")
+    conn.cursor().execute("INSERT INTO " + t + " VALUES (1)")
+    expect_error_code(conn, 1062, "INSERT INTO " + t + " VALUES (1)")
+
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--host", default="127.0.0.1")
+    parser.add_argument("--port", type=int, default=3306)
+    args = parser.parse_args()
+
+    for name, fn in CHECKS.items():
+        result = {"check": name, "ok": True}
+        conn = None
+        try:
+            conn = mysql.connector.connect(
+                host=args.host, port=args.port, user="root", password="",
+                database="default", autocommit=True, ssl_disabled=True)
+            fn(conn)
+        except Exception as e:  # noqa: BLE001 检查失败的原因原样报告
+            result = {"check": name, "ok": False, "error": "%s: %s" % (type(e).__name__, e)}
+        finally:
+            if conn is not None:
+                try:
+                    conn.close()
+                except Exception:  # noqa: BLE001
+                    pass
+        print(json.dumps(result), flush=True)
+
+
+if __name__ == "__main__":
+    sys.exit(main())
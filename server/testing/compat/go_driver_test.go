@@ -0,0 +1,307 @@
+//go:build compat
+
+package compat
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// goChecks go-sql-driver/mysql 的检查实现。db 使用客户端插值（interpolateParams），
+// prepared 不插值，带参数的语句都走服务器端预处理
+type goCheckFunc func(db, prepared *sql.DB) error
+
+var goChecks = map[string]goCheckFunc{
+	"handshake": func(db, _ *sql.DB) error {
+		var version, database string
+		if err := db.QueryRow("SELECT VERSION(), DATABASE()").Scan(&version, &database); err != nil {
+			return err
+		}
+		if version == "" {
+			return errors.New("VERSION() is empty")
+		}
+		return expect("DATABASE()", database, "default")
+	},
+	"ping": func(db, _ *sql.DB) error {
+		return db.Ping()
+	},
+	"query_literals": func(db, _ *sql.DB) error {
+		var n int64
+		var s string
+		var null sql.NullString
+		if err := db.QueryRow("SELECT 1, 'a', NULL").Scan(&n, &s, &null); err != nil {
+			return err
+		}
+		if n != 1 || s != "a" || null.Valid {
+			return fmt.Errorf("got (%d, %q, %v), want (1, \"a\", NULL)", n, s, null)
+		}
+		return nil
+	},
+	"ddl_dml": func(db, _ *sql.DB) error {
+		table := goTable(db, "items", "id INT PRIMARY KEY, name VARCHAR(50)")
+		if err := execAffected(db, 3, "INSERT INTO "+table+" VALUES (1, 'a'), (2, 'b'), (3, 'c')"); err != nil {
+			return err
+		}
+		if err := execAffected(db, 1, "UPDATE "+table+" SET name = 'B' WHERE id = 2"); err != nil {
+			return err
+		}
+		if err := execAffected(db, 1, "DELETE FROM "+table+" WHERE id = 3"); err != nil {
+			return err
+		}
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			return err
+		}
+		return expect("COUNT(*)", count, 2)
+	},
+	"parameters": func(db, _ *sql.DB) error {
+		table := goTable(db, "params", "id INT PRIMARY KEY, name VARCHAR(100)")
+		tricky := `it's a \ "quoted" value`
+		for i, name := range []string{"a", "b", tricky} {
+			if err := execAffected(db, 1, "INSERT INTO "+table+" VALUES (?, ?)", i+1, name); err != nil {
+				return err
+			}
+		}
+		var name string
+		if err := db.QueryRow("SELECT name FROM "+table+" WHERE id = ?", 3).Scan(&name); err != nil {
+			return err
+		}
+		return expect("name", name, tricky)
+	},
+	"server_prepared_statements": func(db, prepared *sql.DB) error {
+		table := goTable(db, "prepared", "id INT PRIMARY KEY, name VARCHAR(50)")
+		stmt, err := prepared.Prepare("INSERT INTO " + table + " VALUES (?, ?)")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for i := 1; i <= 3; i++ {
+			if _, err := stmt.Exec(i, fmt.Sprintf("name%d", i)); err != nil {
+				return err
+			}
+		}
+		var name string
+		if err := prepared.QueryRow("SELECT name FROM "+table+" WHERE id = ?", 2).Scan(&name); err != nil {
+			return err
+		}
+		return expect("name", name, "name2")
+	},
+	"transaction_commit": func(db, _ *sql.DB) error {
+		table := goTable(db, "tx_commit", "id INT PRIMARY KEY")
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO " + table + " VALUES (1)"); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return expectCount(db, table, 1)
+	},
+	"transaction_rollback": func(db, _ *sql.DB) error {
+		table := goTable(db, "tx_rollback", "id INT PRIMARY KEY")
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO " + table + " VALUES (1)"); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return expectCount(db, table, 0)
+	},
+	"types_integer": func(db, _ *sql.DB) error {
+		table := goTable(db, "ints", "id INT PRIMARY KEY, i INT, b BIGINT")
+		values := [][2]int64{{-2147483648, -9223372036854775808}, {2147483647, 9223372036854775807}}
+		for id, v := range values {
+			if _, err := db.Exec("INSERT INTO "+table+" VALUES (?, ?, ?)", id, v[0], v[1]); err != nil {
+				return err
+			}
+		}
+		for id, want := range values {
+			var got [2]int64
+			if err := db.QueryRow("SELECT i, b FROM "+table+" WHERE id = ?", id).Scan(&got[0], &got[1]); err != nil {
+				return err
+			}
+			if err := expect("(i, b)", got, want); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	"types_decimal": func(db, _ *sql.DB) error {
+		table := goTable(db, "decimals", "id INT PRIMARY KEY, d DECIMAL(10,2)")
+		if _, err := db.Exec("INSERT INTO "+table+" VALUES (1, ?)", "12345678.90"); err != nil {
+			return err
+		}
+		var d string
+		if err := db.QueryRow("SELECT d FROM " + table).Scan(&d); err != nil {
+			return err
+		}
+		return expect("d", d, "12345678.90")
+	},
+	"types_double": func(db, _ *sql.DB) error {
+		table := goTable(db, "doubles", "id INT PRIMARY KEY, f DOUBLE")
+		if _, err := db.Exec("INSERT INTO "+table+" VALUES (1, ?)", 3.25); err != nil {
+			return err
+		}
+		var f float64
+		if err := db.QueryRow("SELECT f FROM " + table).Scan(&f); err != nil {
+			return err
+		}
+		return expect("f", f, 3.25)
+	},
+	"types_string": func(db, _ *sql.DB) error {
+		table := goTable(db, "strings", "id INT PRIMARY KEY, s VARCHAR(50), t TEXT")
+		s := "héllo 世界 😀"
+		long := strings.Repeat("0123456789", 1000)
+		if _, err := db.Exec("INSERT INTO "+table+" VALUES (1, ?, ?)", s, long); err != nil {
+			return err
+		}
+		var gotS, gotT string
+		if err := db.QueryRow("SELECT s, t FROM "+table).Scan(&gotS, &gotT); err != nil {
+			return err
+		}
+		if err := expect("s", gotS, s); err != nil {
+			return err
+		}
+		return expect("len(t)", len(gotT), len(long))
+	},
+	"types_binary": func(db, _ *sql.DB) error {
+		table := goTable(db, "blobs", "id INT PRIMARY KEY, b BLOB")
+		data := []byte{0x00, 0x01, 0x27, 0x5c, 0xff}
+		if _, err := db.Exec("INSERT INTO "+table+" VALUES (1, ?)", data); err != nil {
+			return err
+		}
+		var got []byte
+		if err := db.QueryRow("SELECT b FROM " + table).Scan(&got); err != nil {
+			return err
+		}
+		if !bytes.Equal(got, data) {
+			return fmt.Errorf("b = %x, want %x", got, data)
+		}
+		return nil
+	},
+	"types_temporal": func(db, _ *sql.DB) error {
+		table := goTable(db, "temporal", "id INT PRIMARY KEY, d DATE, dt DATETIME")
+		date := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+		datetime := time.Date(2024, 2, 29, 13, 14, 15, 0, time.UTC)
+		if _, err := db.Exec("INSERT INTO "+table+" VALUES (1, ?, ?)", date, datetime); err != nil {
+			return err
+		}
+		var gotDate, gotDatetime time.Time
+		if err := db.QueryRow("SELECT d, dt FROM "+table).Scan(&gotDate, &gotDatetime); err != nil {
+			return err
+		}
+		if err := expect("d", gotDate, date); err != nil {
+			return err
+		}
+		return expect("dt", gotDatetime, datetime)
+	},
+	"types_null": func(db, _ *sql.DB) error {
+		table := goTable(db, "nulls", "id INT PRIMARY KEY, i INT, s VARCHAR(10)")
+		if _, err := db.Exec("INSERT INTO "+table+" VALUES (1, ?, ?)", nil, nil); err != nil {
+			return err
+		}
+		var i sql.NullInt64
+		var s sql.NullString
+		if err := db.QueryRow("SELECT i, s FROM "+table).Scan(&i, &s); err != nil {
+			return err
+		}
+		if i.Valid || s.Valid {
+			return fmt.Errorf("got (%v, %v), want (NULL, NULL)", i, s)
+		}
+		return nil
+	},
+	"error_codes": func(db, _ *sql.DB) error {
+		if err := expectErrorCode(db, 1146, "SELECT * FROM compat_go_no_such_table"); err != nil {
+			return err
+		}
+		table := goTable(db, "dup", "id INT PRIMARY KEY")
+		if _, err := db.Exec("INSERT INTO " + table + " VALUES (1)"); err != nil {
+			return err
+		}
+		return expectErrorCode(db, 1062, "INSERT INTO "+table+" VALUES (1)")
+	},
+}
+
+func TestGoSQLDriver(t *testing.T) {
+	dsn := fmt.Sprintf("root@tcp(127.0.0.1:%d)/default?parseTime=true&loc=UTC", compatPort)
+	db, err := sql.Open("mysql", dsn+"&interpolateParams=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	prepared, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prepared.Close()
+
+	results := make(map[string]checkResult, len(goChecks))
+	for name, check := range goChecks {
+		r := checkResult{Check: name, OK: true}
+		if err := check(db, prepared); err != nil {
+			r.OK, r.Error = false, err.Error()
+		}
+		results[name] = r
+	}
+	report(t, results)
+}
+
+// goTable 重新创建 go-sql-driver 检查使用的表，返回表名
+func goTable(db *sql.DB, name, columns string) string {
+	table := "compat_go_" + name
+	db.Exec("DROP TABLE IF EXISTS " + table)
+	db.Exec("CREATE TABLE " + table + " (" + columns + ")")
+	return table
+}
+
+func expect(what string, got, want interface{}) error {
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		return fmt.Errorf("%s = %v, want %v", what, got, want)
+	}
+	return nil
+}
+
+func execAffected(db *sql.DB, want int64, query string, args ...interface{}) error {
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	return expect("rows affected by "+query, n, want)
+}
+
+func expectCount(db *sql.DB, table string, want int) error {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+		return err
+	}
+	return expect("COUNT(*)", count, want)
+}
+
+func expectErrorCode(db *sql.DB, code uint16, query string) error {
+	_, err := db.Exec(query)
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return fmt.Errorf("%s: got %v, want error %d", query, err, code)
+	}
+	return expect("error code of "+query, mysqlErr.Number, code)
+}
@@ -0,0 +1,23 @@
+package testing
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain 在临时目录中运行测试：测试服务器把 users.json、permissions.json 和 datasources.json 写在当前目录
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "sqlexec-integration-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to enter temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -0,0 +1,102 @@
+package testing
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/kasuganosora/sqlexec/pkg/mysqltest"
+	"github.com/kasuganosora/sqlexec/pkg/resource/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestE2E_MultiStatements 测试多语句批处理：每个结果集依次返回，客户端通过 NextResultSet 迭代
+func TestE2E_MultiStatements(t *testing.T) {
+	testServer := mysqltest.NewTestServer()
+	require.NoError(t, testServer.Start(13340))
+	defer testServer.Stop()
+
+	require.NoError(t, testServer.CreateTestTable("items", []domain.ColumnInfo{
+		{Name: "id", Type: "INT", Primary: true},
+		{Name: "name", Type: "VARCHAR(64)", Nullable: true},
+	}))
+
+	conn, err := sql.Open("mysql", testServer.GetDSN()+"?multiStatements=true")
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	t.Run("multiple result sets", func(t *testing.T) {
+		rows, err := conn.Query("SELECT 1 AS a; SELECT 2 AS b, 3 AS c")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		cols, err := rows.Columns()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, cols)
+		require.True(t, rows.Next())
+		var a int
+		require.NoError(t, rows.Scan(&a))
+		assert.Equal(t, 1, a)
+		assert.False(t, rows.Next())
+
+		require.True(t, rows.NextResultSet(), "expected a second result set")
+		cols, err = rows.Columns()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, cols)
+		require.True(t, rows.Next())
+		var b, c int
+		require.NoError(t, rows.Scan(&b, &c))
+		assert.Equal(t, 2, b)
+		assert.Equal(t, 3, c)
+
+		assert.False(t, rows.NextResultSet())
+		assert.NoError(t, rows.Err())
+	})
+
+	t.Run("statements without result sets", func(t *testing.T) {
+		_, err := conn.Exec("SET @a = 1; SET @b = 2;")
+		require.NoError(t, err)
+
+		rows, err := conn.Query("SET @c = 3; SELECT COUNT(*) FROM items")
+		require.NoError(t, err)
+		defer rows.Close()
+		// SET 的 OK 包没有列，驱动直接跳到下一个结果集
+		require.True(t, rows.Next())
+		var count int
+		require.NoError(t, rows.Scan(&count))
+		assert.Equal(t, 0, count)
+		assert.False(t, rows.NextResultSet())
+	})
+
+	t.Run("error stops the batch", func(t *testing.T) {
+		rows, err := conn.Query("SELECT 1; SELECT * FROM missing_table; SELECT 3")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		require.True(t, rows.Next())
+		assert.False(t, rows.Next())
+		// 第二条语句失败，后续语句不再执行
+		assert.False(t, rows.NextResultSet())
+		assert.Error(t, rows.Err())
+
+		var v int
+		require.NoError(t, conn.QueryRow("SELECT 4").Scan(&v))
+		assert.Equal(t, 4, v)
+	})
+}
+
+// TestE2E_MultiStatementsDisabled 测试未启用多语句的连接仍按单条语句执行
+func TestE2E_MultiStatementsDisabled(t *testing.T) {
+	testServer := mysqltest.NewTestServer()
+	require.NoError(t, testServer.Start(13341))
+	defer testServer.Stop()
+
+	conn, err := sql.Open("mysql", testServer.GetDSN())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var v int
+	require.NoError(t, conn.QueryRow("SELECT 1;").Scan(&v))
+	assert.Equal(t, 1, v)
+}
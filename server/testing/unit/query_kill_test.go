@@ -3,6 +3,7 @@ package unit
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -290,12 +291,10 @@ func TestServer_ConcurrentKillQueries(t *testing.T) {
 
 // TestServer_QueryIDUniqueness 测试查询ID唯一性
 func TestServer_QueryIDUniqueness(t *testing.T) {
-	threadID := uint32(1)
-
 	// 生成多个查询ID
 	ids := make(map[string]bool)
 	for i := 0; i < 100; i++ {
-		id := session.GenerateQueryID(threadID)
+		id := session.GenerateQueryID()
 		// 验证ID不重复
 		assert.False(t, ids[id], "QueryID %s is duplicated", id)
 		ids[id] = true
@@ -477,7 +476,7 @@ func setupTestQuery(t *testing.T, threadID uint32) *session.QueryContext {
 	_, cancel := context.WithCancel(context.Background())
 
 	qc := &session.QueryContext{
-		QueryID:    session.GenerateQueryID(threadID),
+		QueryID:    session.GenerateQueryID(),
 		ThreadID:   threadID,
 		SQL:        fmt.Sprintf("SELECT * FROM test WHERE thread_id = %d", threadID),
 		StartTime:  time.Now(),
@@ -524,9 +523,10 @@ func TestServer_ComProcessKillPacket(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// 测试查询ID生成(因为protocol包测试在别的地方)
-			queryID := session.GenerateQueryID(tt.threadID)
+			queryID := session.GenerateQueryID()
 			assert.NotEmpty(t, queryID, "QueryID should not be empty")
-			assert.Contains(t, queryID, fmt.Sprintf("%d", tt.threadID), "QueryID should contain threadID")
+			_, err := strconv.ParseInt(queryID, 10, 64)
+			assert.NoError(t, err, "QueryID should be a number")
 		})
 	}
 }
@@ -14,6 +14,13 @@ import (
 	"github.com/kasuganosora/sqlexec/server/testing/mock"
 )
 
+// newTestServer 在临时工作目录中创建服务器，NewServer 会把 users.json、permissions.json 和 datasources.json 写在当前目录
+func newTestServer(t *testing.T, ctx context.Context, listener net.Listener) *server.Server {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	return server.NewServer(ctx, listener, &config.Config{})
+}
+
 // TestServer_ConcurrentQueries 测试并发查询场景（简化版）
 func TestServer_ConcurrentQueries(t *testing.T) {
 	// 创建测试配置
@@ -52,7 +59,7 @@ func TestServer_ConcurrentQueries(t *testing.T) {
 	}
 	defer listener.Close()
 
-	s := server.NewServer(ctx, listener, &config.Config{})
+	s := newTestServer(t, ctx, listener)
 	s.SetDB(db)
 
 	// 验证 server 已正确初始化
@@ -109,7 +116,7 @@ func TestServer_ParserRegistry(t *testing.T) {
 	}
 	defer listener.Close()
 
-	s := server.NewServer(ctx, listener, &config.Config{})
+	s := newTestServer(t, ctx, listener)
 
 	// 通过反射检查 parserRegistry 是否正确初始化
 	// 这个测试主要验证重构后的代码结构正确
@@ -142,7 +149,7 @@ func TestServer_HandshakeHandler(t *testing.T) {
 	}
 	defer listener.Close()
 
-	s := server.NewServer(ctx, listener, &config.Config{})
+	s := newTestServer(t, ctx, listener)
 	s.SetDB(db)
 
 	// 验证握手处理器已注册
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/kasuganosora/sqlexec/pkg/config"
+	"github.com/kasuganosora/sqlexec/pkg/workload"
+)
+
+// workloadConfig 将配置文件中的工作负载设置转换为 workload.Config
+func workloadConfig(cfg config.WorkloadConfig) workload.Config {
+	wc := workload.Config{
+		Enabled:      cfg.Enabled,
+		DefaultClass: cfg.DefaultClass,
+		Classes:      make([]workload.Class, 0, len(cfg.Classes)),
+		Rules:        make([]workload.Rule, 0, len(cfg.Rules)),
+	}
+	for _, c := range cfg.Classes {
+		wc.Classes = append(wc.Classes, workload.Class{
+			Name:           c.Name,
+			MaxConcurrency: c.MaxConcurrency,
+			MaxQueue:       c.MaxQueue,
+			QueueTimeout:   c.QueueTimeout,
+		})
+	}
+	for _, r := range cfg.Rules {
+		wc.Rules = append(wc.Rules, workload.Rule{
+			Class:          r.Class,
+			Users:          r.Users,
+			StatementTypes: r.StatementTypes,
+			MinCost:        r.MinCost,
+			MaxCost:        r.MaxCost,
+		})
+	}
+	return wc
+}